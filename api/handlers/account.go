@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"nudgebot-api/internal/account"
+	"nudgebot-api/internal/common"
+	"nudgebot-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccountHandler merges user accounts and undoes merges. Every change names
+// who asked for it, for the audit trail.
+type AccountHandler struct {
+	mergeService account.MergeService
+	logger       *logger.Logger
+}
+
+// NewAccountHandler creates a new AccountHandler instance
+func NewAccountHandler(mergeService account.MergeService, logger *logger.Logger) *AccountHandler {
+	return &AccountHandler{
+		mergeService: mergeService,
+		logger:       logger,
+	}
+}
+
+// MergeAccountsRequest is the body for merging one user into another
+type MergeAccountsRequest struct {
+	FromUserID  string `json:"from_user_id" binding:"required"`
+	IntoUserID  string `json:"into_user_id" binding:"required"`
+	RequestedBy string `json:"requested_by" binding:"required"`
+}
+
+// UndoMergeRequest is the body for undoing a merge
+type UndoMergeRequest struct {
+	RequestedBy string `json:"requested_by" binding:"required"`
+}
+
+// MergeAccounts moves one user's tasks, reminders, settings and Telegram accounts to another user
+func (h *AccountHandler) MergeAccounts(c *gin.Context) {
+	var req MergeAccountsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	merge, err := h.mergeService.Merge(common.UserID(req.FromUserID), common.UserID(req.IntoUserID), req.RequestedBy)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, merge)
+}
+
+// UndoMerge moves the merged rows back while the undo window is open
+func (h *AccountHandler) UndoMerge(c *gin.Context) {
+	var req UndoMergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	merge, err := h.mergeService.Undo(c.Param("mergeID"), req.RequestedBy)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, merge)
+}
+
+// ListMerges returns the merges involving a user
+func (h *AccountHandler) ListMerges(c *gin.Context) {
+	userID := common.UserID(c.Param("userID"))
+
+	merges, err := h.mergeService.ListMerges(userID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user_id": userID,
+		"merges":  merges,
+	})
+}
+
+func (h *AccountHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, account.ErrInvalidMerge):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, account.ErrMergeNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Account merge not found"})
+	case errors.Is(err, account.ErrUndoExpired), errors.Is(err, account.ErrAlreadyUndone):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error("Account merge request failed", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Account merge request failed"})
+	}
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"nudgebot-api/internal/debugcapture"
+	"nudgebot-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultCaptureLimit caps how many captures are returned when no limit is given
+const defaultCaptureLimit = 100
+
+// DebugCaptureHandler exposes the sampled Telegram updates and Bot API calls
+type DebugCaptureHandler struct {
+	recorder *debugcapture.Recorder
+	logger   *logger.Logger
+}
+
+// NewDebugCaptureHandler creates a new DebugCaptureHandler instance
+func NewDebugCaptureHandler(recorder *debugcapture.Recorder, logger *logger.Logger) *DebugCaptureHandler {
+	return &DebugCaptureHandler{
+		recorder: recorder,
+		logger:   logger,
+	}
+}
+
+// ListCaptures returns captures newest first. The chat_id, kind and limit
+// query parameters narrow the result.
+func (h *DebugCaptureHandler) ListCaptures(c *gin.Context) {
+	filter := debugcapture.Filter{
+		Kind:  debugcapture.Kind(c.Query("kind")),
+		Limit: defaultCaptureLimit,
+	}
+
+	if chatID := c.Query("chat_id"); chatID != "" {
+		parsed, err := strconv.ParseInt(chatID, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "chat_id must be a Telegram chat ID"})
+			return
+		}
+		filter.ChatID = parsed
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive number"})
+			return
+		}
+		filter.Limit = parsed
+	}
+
+	switch filter.Kind {
+	case "", debugcapture.KindUpdate, debugcapture.KindCall:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kind must be update or call"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"captures": h.recorder.List(filter),
+	})
+}
+
+// ClearCaptures drops every capture
+func (h *DebugCaptureHandler) ClearCaptures(c *gin.Context) {
+	h.recorder.Clear()
+	h.logger.Info("Debug captures cleared", "client_ip", c.ClientIP())
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"nudgebot-api/internal/experiment"
+	"nudgebot-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExperimentHandler exposes experiment definitions and reports to admins
+type ExperimentHandler struct {
+	experimentService experiment.ExperimentService
+	logger            *logger.Logger
+}
+
+// NewExperimentHandler creates a new ExperimentHandler instance
+func NewExperimentHandler(experimentService experiment.ExperimentService, logger *logger.Logger) *ExperimentHandler {
+	return &ExperimentHandler{
+		experimentService: experimentService,
+		logger:            logger,
+	}
+}
+
+// ListExperiments returns all configured experiments
+func (h *ExperimentHandler) ListExperiments(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"experiments": h.experimentService.ListExperiments(),
+	})
+}
+
+// GetReport returns per-variant performance for an experiment
+func (h *ExperimentHandler) GetReport(c *gin.Context) {
+	name := c.Param("name")
+
+	report, err := h.experimentService.GetReport(name)
+	if err != nil {
+		if errors.Is(err, experiment.ErrExperimentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Experiment not found"})
+			return
+		}
+
+		h.logger.Error("Failed to build experiment report", "experiment", name, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build experiment report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/featureflags"
+	"nudgebot-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FeatureFlagHandler lets admins inspect feature flags and override them at runtime
+type FeatureFlagHandler struct {
+	flagService featureflags.FlagService
+	logger      *logger.Logger
+}
+
+// NewFeatureFlagHandler creates a new FeatureFlagHandler instance
+func NewFeatureFlagHandler(flagService featureflags.FlagService, logger *logger.Logger) *FeatureFlagHandler {
+	return &FeatureFlagHandler{
+		flagService: flagService,
+		logger:      logger,
+	}
+}
+
+// SetOverrideRequest is the body for overriding a flag. An empty user ID
+// overrides the flag for everyone.
+type SetOverrideRequest struct {
+	UserID  string `json:"user_id"`
+	Enabled *bool  `json:"enabled" binding:"required"`
+}
+
+// ListFlags returns all configured flags with their overrides
+func (h *FeatureFlagHandler) ListFlags(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"feature_flags": h.flagService.ListFlags(),
+	})
+}
+
+// SetOverride forces a flag on or off
+func (h *FeatureFlagHandler) SetOverride(c *gin.Context) {
+	name := c.Param("name")
+
+	var req SetOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if err := h.flagService.SetOverride(name, common.UserID(req.UserID), *req.Enabled); err != nil {
+		h.respondError(c, name, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"flag":    name,
+		"user_id": req.UserID,
+		"enabled": *req.Enabled,
+	})
+}
+
+// ClearOverride removes an override; the user_id query parameter selects a user override
+func (h *FeatureFlagHandler) ClearOverride(c *gin.Context) {
+	name := c.Param("name")
+	userID := c.Query("user_id")
+
+	if err := h.flagService.ClearOverride(name, common.UserID(userID)); err != nil {
+		h.respondError(c, name, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *FeatureFlagHandler) respondError(c *gin.Context, name string, err error) {
+	switch {
+	case errors.Is(err, featureflags.ErrFlagNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Feature flag not found"})
+	case errors.Is(err, featureflags.ErrOverridesDisabled):
+		c.JSON(http.StatusConflict, gin.H{"error": "Feature flag overrides are not persisted"})
+	default:
+		h.logger.Error("Failed to update feature flag override", "flag", name, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update feature flag override"})
+	}
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"nudgebot-api/internal/config"
+	"nudgebot-api/internal/maintenance"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultAccentColor is used when the configured accent color is not a hex color
+const defaultAccentColor = "#2481cc"
+
+var (
+	hexColorPattern = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+	usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_]{5,32}$`)
+)
+
+// LandingHandler renders the public landing page
+type LandingHandler struct {
+	branding          config.LandingConfig
+	startedAt         time.Time
+	maintenanceSwitch *maintenance.Switch
+	now               func() time.Time
+}
+
+// NewLandingHandler creates a new LandingHandler instance. Uptime is counted
+// from startedAt; a nil maintenance switch always shows the service as up.
+func NewLandingHandler(branding config.LandingConfig, startedAt time.Time, maintenanceSwitch *maintenance.Switch) *LandingHandler {
+	return &LandingHandler{
+		branding:          branding,
+		startedAt:         startedAt,
+		maintenanceSwitch: maintenanceSwitch,
+		now:               time.Now,
+	}
+}
+
+// landingPage is the data the landing template renders
+type landingPage struct {
+	Name               string
+	Tagline            string
+	Username           string
+	ChatURL            string
+	AccentColor        string
+	LogoURL            string
+	Uptime             string
+	Maintenance        bool
+	MaintenanceMessage string
+}
+
+// Show renders the landing page
+func (h *LandingHandler) Show(c *gin.Context) {
+	page := landingPage{
+		Name:        h.branding.BotName,
+		Tagline:     h.branding.Tagline,
+		AccentColor: h.branding.AccentColor,
+		LogoURL:     h.branding.LogoURL,
+		Uptime:      formatUptime(h.now().Sub(h.startedAt)),
+	}
+	if page.Name == "" {
+		page.Name = "NudgeBot"
+	}
+	if !hexColorPattern.MatchString(page.AccentColor) {
+		page.AccentColor = defaultAccentColor
+	}
+	if username := strings.TrimPrefix(h.branding.BotUsername, "@"); usernamePattern.MatchString(username) {
+		page.Username = username
+		page.ChatURL = "https://t.me/" + username
+	}
+	if h.maintenanceSwitch.Enabled() {
+		page.Maintenance = true
+		page.MaintenanceMessage = h.maintenanceSwitch.Status().Message
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.HTML(http.StatusOK, "index.html.tmpl", page)
+}
+
+// formatUptime renders an uptime in its two largest units, such as "3d 4h" or "12m"
+func formatUptime(uptime time.Duration) string {
+	if uptime < time.Minute {
+		return "less than a minute"
+	}
+
+	days := int(uptime / (24 * time.Hour))
+	hours := int(uptime % (24 * time.Hour) / time.Hour)
+	minutes := int(uptime % time.Hour / time.Minute)
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
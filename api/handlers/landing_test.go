@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"nudgebot-api/api/landing"
+	"nudgebot-api/internal/config"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/maintenance"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestLandingHandler_Show(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	maintenanceSwitch := maintenance.NewSwitch(events.NewMockEventBus(), zap.NewNop(), config.MaintenanceConfig{})
+
+	show := func(branding config.LandingConfig) string {
+		handler := NewLandingHandler(branding, now.Add(-(50*time.Hour + 20*time.Minute)), maintenanceSwitch)
+		handler.now = func() time.Time { return now }
+		router := gin.New()
+		router.SetHTMLTemplate(landing.Template)
+		router.GET("/", handler.Show)
+
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+		require.Equal(t, http.StatusOK, recorder.Code)
+		return recorder.Body.String()
+	}
+
+	page := show(config.LandingConfig{BotName: "Acme Tasks", BotUsername: "@acme_tasks_bot", Tagline: "Never forget", AccentColor: "#ff6600"})
+	assert.Contains(t, page, "<h1>Acme Tasks</h1>")
+	assert.Contains(t, page, "Never forget")
+	assert.Contains(t, page, `href="https://t.me/acme_tasks_bot"`)
+	assert.Contains(t, page, "--accent: #ff6600")
+	assert.Contains(t, page, "Up for 2d 2h")
+	assert.Contains(t, page, "All systems operational")
+
+	// Unsafe branding falls back rather than reaching the page
+	page = show(config.LandingConfig{BotUsername: "not a bot", AccentColor: "red;}body{display:none"})
+	assert.Contains(t, page, "<h1>NudgeBot</h1>")
+	assert.NotContains(t, page, "Start chatting")
+	assert.Contains(t, page, "--accent: #2481cc")
+
+	maintenanceSwitch.Set(true, "Back at noon")
+	page = show(config.LandingConfig{BotName: "Acme Tasks"})
+	assert.Contains(t, page, "Down for maintenance")
+	assert.Contains(t, page, "Back at noon")
+}
+
+func TestFormatUptime(t *testing.T) {
+	assert.Equal(t, "less than a minute", formatUptime(30*time.Second))
+	assert.Equal(t, "12m", formatUptime(12*time.Minute))
+	assert.Equal(t, "3h 5m", formatUptime(3*time.Hour+5*time.Minute))
+	assert.Equal(t, "1d 0h", formatUptime(24*time.Hour+59*time.Minute))
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"net/http"
+
+	"nudgebot-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LogLevelHandler lets admins change log levels without a restart
+type LogLevelHandler struct {
+	levels *logger.Levels
+	logger *logger.Logger
+}
+
+// NewLogLevelHandler creates a new LogLevelHandler instance
+func NewLogLevelHandler(levels *logger.Levels, logger *logger.Logger) *LogLevelHandler {
+	return &LogLevelHandler{
+		levels: levels,
+		logger: logger,
+	}
+}
+
+// SetLogLevelsRequest is the body for changing log levels. An empty level
+// keeps the global level; an empty module level removes that module's override.
+type SetLogLevelsRequest struct {
+	Level   string            `json:"level"`
+	Modules map[string]string `json:"modules"`
+}
+
+// GetLevels returns the global level and the module overrides
+func (h *LogLevelHandler) GetLevels(c *gin.Context) {
+	c.JSON(http.StatusOK, h.levels.Snapshot())
+}
+
+// SetLevels changes the global level and module overrides
+func (h *LogLevelHandler) SetLevels(c *gin.Context) {
+	var req SetLogLevelsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if err := h.levels.Update(req.Level, req.Modules); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	snapshot := h.levels.Snapshot()
+	h.logger.Info("Log levels changed",
+		"level", snapshot.Level,
+		"modules", snapshot.Modules,
+		"client_ip", c.ClientIP())
+	c.JSON(http.StatusOK, snapshot)
+}
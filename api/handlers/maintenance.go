@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+
+	"nudgebot-api/internal/maintenance"
+	"nudgebot-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaintenanceHandler lets admins take the service down for maintenance and
+// bring it back without a restart
+type MaintenanceHandler struct {
+	maintenance *maintenance.Switch
+	logger      *logger.Logger
+}
+
+// NewMaintenanceHandler creates a new MaintenanceHandler instance
+func NewMaintenanceHandler(maintenanceSwitch *maintenance.Switch, logger *logger.Logger) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		maintenance: maintenanceSwitch,
+		logger:      logger,
+	}
+}
+
+// SetMaintenanceRequest is the body for toggling maintenance mode. An empty
+// message shows the default notice.
+type SetMaintenanceRequest struct {
+	Enabled *bool  `json:"enabled" binding:"required"`
+	Message string `json:"message"`
+}
+
+// GetMaintenance returns whether maintenance mode is on and its notice
+func (h *MaintenanceHandler) GetMaintenance(c *gin.Context) {
+	c.JSON(http.StatusOK, h.maintenance.Status())
+}
+
+// SetMaintenance turns maintenance mode on or off
+func (h *MaintenanceHandler) SetMaintenance(c *gin.Context) {
+	var req SetMaintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	status := h.maintenance.Set(*req.Enabled, req.Message)
+	h.logger.Info("Maintenance mode changed",
+		"enabled", status.Enabled,
+		"client_ip", c.ClientIP())
+	c.JSON(http.StatusOK, status)
+}
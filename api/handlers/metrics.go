@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"net/http"
+
+	"nudgebot-api/internal/database"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/governor"
+	"nudgebot-api/internal/httpclient"
+	"nudgebot-api/internal/llm"
+	"nudgebot-api/internal/nudge"
+	"nudgebot-api/internal/probe"
+	"nudgebot-api/internal/scheduler"
+	"nudgebot-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MetricsHandler exposes runtime metrics collected by the services
+type MetricsHandler struct {
+	repositoryMetrics *nudge.RepositoryMetrics
+	scheduler         scheduler.Scheduler
+	jobScheduler      scheduler.JobScheduler
+	governor          governor.Governor
+	prober            probe.Prober
+	pool              *database.Pool
+	eventMetrics      events.MetricsReporter
+	llmQueue          *llm.FairQueue
+	httpMetrics       *httpclient.Metrics
+	logger            *logger.Logger
+}
+
+// NewMetricsHandler creates a new MetricsHandler instance. The schedulers may be
+// nil when reminder scheduling is disabled, the prober when the synthetic
+// probe is, the pool when no database is connected, the LLM queue when
+// provider calls are not queued and the HTTP metrics when outbound calls are
+// not counted. Event metrics are reported when the bus
+// implements events.MetricsReporter.
+func NewMetricsHandler(repositoryMetrics *nudge.RepositoryMetrics, reminderScheduler scheduler.Scheduler, jobScheduler scheduler.JobScheduler, loadGovernor governor.Governor, prober probe.Prober, pool *database.Pool, eventBus events.EventBus, llmQueue *llm.FairQueue, httpMetrics *httpclient.Metrics, logger *logger.Logger) *MetricsHandler {
+	h := &MetricsHandler{
+		repositoryMetrics: repositoryMetrics,
+		scheduler:         reminderScheduler,
+		jobScheduler:      jobScheduler,
+		governor:          loadGovernor,
+		prober:            prober,
+		pool:              pool,
+		llmQueue:          llmQueue,
+		httpMetrics:       httpMetrics,
+		logger:            logger,
+	}
+	if eventMetrics, ok := eventBus.(events.MetricsReporter); ok {
+		h.eventMetrics = eventMetrics
+	}
+	return h
+}
+
+// GetMetrics returns repository latency, connection pool, scheduler, periodic
+// job, load, synthetic probe, per-topic event, LLM queue and outbound HTTP
+// metrics
+func (h *MetricsHandler) GetMetrics(c *gin.Context) {
+	response := gin.H{}
+
+	if h.repositoryMetrics != nil {
+		response["repository"] = h.repositoryMetrics.Snapshot()
+	}
+
+	if h.pool != nil {
+		response["database"] = h.pool.Stats()
+	}
+
+	if h.scheduler != nil {
+		response["scheduler"] = h.scheduler.GetMetrics().GetMetricsSummary()
+	}
+
+	if h.jobScheduler != nil {
+		response["jobs"] = h.jobScheduler.GetJobStatuses()
+	}
+
+	if h.governor != nil {
+		response["load"] = h.governor.Status()
+	}
+
+	if h.prober != nil {
+		response["probe"] = h.prober.Status()
+	}
+
+	if h.eventMetrics != nil {
+		response["events"] = h.eventMetrics.TopicMetrics()
+	}
+
+	if h.llmQueue != nil {
+		response["llm_queue"] = h.llmQueue.Metrics()
+	}
+
+	if h.httpMetrics != nil {
+		response["http_clients"] = h.httpMetrics.Snapshot()
+	}
+
+	c.JSON(http.StatusOK, response)
+}
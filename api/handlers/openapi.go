@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OpenAPIHandler serves the OpenAPI document of the REST API
+type OpenAPIHandler struct {
+	spec []byte
+}
+
+// NewOpenAPIHandler creates a new OpenAPIHandler instance
+func NewOpenAPIHandler(spec []byte) *OpenAPIHandler {
+	return &OpenAPIHandler{spec: spec}
+}
+
+// GetSpec returns the OpenAPI document as YAML
+func (h *OpenAPIHandler) GetSpec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/yaml; charset=utf-8", h.spec)
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+
+	"nudgebot-api/internal/llm"
+	"nudgebot-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PromptHandler lets admins reload the LLM prompt templates without a restart
+type PromptHandler struct {
+	prompts *llm.PromptStore
+	logger  *logger.Logger
+}
+
+// NewPromptHandler creates a new PromptHandler instance
+func NewPromptHandler(prompts *llm.PromptStore, logger *logger.Logger) *PromptHandler {
+	return &PromptHandler{
+		prompts: prompts,
+		logger:  logger,
+	}
+}
+
+// ReloadPrompts reads the prompt templates again and returns the loaded
+// templates. Invalid templates are reported and the previous ones kept.
+func (h *PromptHandler) ReloadPrompts(c *gin.Context) {
+	if err := h.prompts.Reload(); err != nil {
+		h.logger.Warn("Prompt reload failed", "error", err, "client_ip", c.ClientIP())
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	templates := h.prompts.Templates()
+	h.logger.Info("Prompt templates reloaded",
+		"templates", len(templates),
+		"client_ip", c.ClientIP())
+	c.JSON(http.StatusOK, gin.H{
+		"templates": templates,
+	})
+}
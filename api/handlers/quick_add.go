@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"nudgebot-api/api/middleware"
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/llm"
+	"nudgebot-api/internal/nudge"
+	"nudgebot-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QuickAddHandler creates tasks sent by browser extensions and shortcuts
+type QuickAddHandler struct {
+	nudgeService nudge.NudgeService
+	llmService   llm.LLMService
+	logger       *logger.Logger
+}
+
+// NewQuickAddHandler creates a new QuickAddHandler instance. A nil LLM service
+// stores titles as sent.
+func NewQuickAddHandler(nudgeService nudge.NudgeService, llmService llm.LLMService, logger *logger.Logger) *QuickAddHandler {
+	return &QuickAddHandler{
+		nudgeService: nudgeService,
+		llmService:   llmService,
+		logger:       logger,
+	}
+}
+
+// QuickAddRequest is the body for adding a task. Title is free text such as
+// "read this by friday"; at least one of title and URL is required.
+type QuickAddRequest struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+	Notes string `json:"notes"`
+}
+
+// QuickAdd creates a task for the token's user and returns it
+func (h *QuickAddHandler) QuickAdd(c *gin.Context) {
+	token := c.MustGet(middleware.APITokenKey).(*nudge.APIToken)
+
+	var req QuickAddRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	req.Title = strings.TrimSpace(req.Title)
+	req.URL = strings.TrimSpace(req.URL)
+	if req.Title == "" && req.URL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "title or url is required"})
+		return
+	}
+
+	task := h.buildTask(token, req)
+	if err := h.nudgeService.CreateTask(task); err != nil {
+		if nudge.IsValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("Quick add failed", "user_id", token.UserID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create task"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, task)
+}
+
+// buildTask turns a request into a task, parsing the free-text title for a due
+// date, priority and habit period. The URL is kept in the description so it becomes a task link.
+func (h *QuickAddHandler) buildTask(token *nudge.APIToken, req QuickAddRequest) *nudge.Task {
+	task := &nudge.Task{
+		ID:          common.TaskID(common.NewID()),
+		UserID:      token.UserID,
+		ChatID:      token.ChatID,
+		Title:       req.Title,
+		Description: strings.TrimSpace(req.Notes),
+		Priority:    common.PriorityMedium,
+		Status:      common.TaskStatusActive,
+	}
+
+	if req.Title != "" && h.llmService != nil {
+		parsed, err := h.llmService.ParseTask(req.Title, token.UserID)
+		if err != nil {
+			h.logger.Warn("Quick add title could not be parsed, storing it as sent", "user_id", token.UserID, "error", err)
+		} else {
+			if parsed.ParsedTask.Title != "" {
+				task.Title = parsed.ParsedTask.Title
+			}
+			if parsed.ParsedTask.Priority.IsValid() {
+				task.Priority = parsed.ParsedTask.Priority
+			}
+			task.DueDate = parsed.ParsedTask.DueDate
+			task.Habit = nudge.HabitPeriod(parsed.ParsedTask.Habit)
+		}
+	}
+
+	switch {
+	case req.URL == "":
+	case task.Title == "":
+		task.Title = req.URL
+	default:
+		task.Description = strings.TrimSpace(task.Description + "\n" + req.URL)
+	}
+
+	return task
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"nudgebot-api/api/middleware"
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/nudge"
+	"nudgebot-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestQuickAddHandler_QuickAdd(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	zapLogger := zaptest.NewLogger(t)
+	eventBus := events.NewMockEventBus()
+	nudgeService, err := nudge.NewNudgeService(eventBus, zapLogger, nudge.NewMemoryNudgeRepository(zapLogger))
+	require.NoError(t, err)
+	tokenService := nudge.NewAPITokenService(eventBus, zapLogger, nudge.NewMemoryAPITokenRepository())
+
+	userID := common.UserID(common.NewID())
+	token, err := tokenService.IssueToken(userID, "12345")
+	require.NoError(t, err)
+
+	log := logger.New()
+	router := gin.New()
+	router.POST("/api/v1/quick-add", middleware.APITokenAuth(tokenService, log), NewQuickAddHandler(nudgeService, nil, log).QuickAdd)
+
+	post := func(authorization string, body QuickAddRequest) *httptest.ResponseRecorder {
+		payload, err := json.Marshal(body)
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/quick-add", bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", authorization)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	t.Run("creates a task with the page link", func(t *testing.T) {
+		recorder := post("Bearer "+token, QuickAddRequest{Title: "Read later", URL: "https://example.com/post", Notes: "from the browser"})
+		require.Equal(t, http.StatusCreated, recorder.Code)
+
+		var task nudge.Task
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &task))
+		assert.Equal(t, userID, task.UserID)
+		assert.Equal(t, common.ChatID("12345"), task.ChatID)
+		assert.Equal(t, "Read later", task.Title)
+		require.Len(t, task.Links, 1)
+		assert.Equal(t, "https://example.com/post", task.Links[0].URL)
+	})
+
+	t.Run("requires a title or url", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, post("Bearer "+token, QuickAddRequest{Notes: "just notes"}).Code)
+	})
+
+	t.Run("rejects unknown tokens", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, post("Bearer nb_unknown", QuickAddRequest{Title: "Sneaky"}).Code)
+		assert.Equal(t, http.StatusUnauthorized, post("", QuickAddRequest{Title: "Sneaky"}).Code)
+	})
+}
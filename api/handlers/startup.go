@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+
+	"nudgebot-api/internal/startup"
+	"nudgebot-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StartupHandler reports dependency progress while the service is starting
+type StartupHandler struct {
+	orchestrator *startup.Orchestrator
+	logger       *logger.Logger
+}
+
+// NewStartupHandler creates a new StartupHandler instance
+func NewStartupHandler(orchestrator *startup.Orchestrator, logger *logger.Logger) *StartupHandler {
+	return &StartupHandler{
+		orchestrator: orchestrator,
+		logger:       logger,
+	}
+}
+
+// Check returns 503 with per-dependency progress until every dependency is ready
+func (h *StartupHandler) Check(c *gin.Context) {
+	status := "ok"
+	statusCode := http.StatusOK
+	if !h.orchestrator.Ready() {
+		status = "starting"
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	c.JSON(statusCode, gin.H{
+		"status":       status,
+		"service":      "nudgebot-api",
+		"dependencies": h.orchestrator.Statuses(),
+	})
+}
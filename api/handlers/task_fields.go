@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/nudge"
+	"nudgebot-api/internal/tenant"
+	"nudgebot-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TaskFieldHandler sets and clears custom fields on tasks and lists a user's
+// tasks filtered by them
+type TaskFieldHandler struct {
+	nudgeService nudge.NudgeService
+	logger       *logger.Logger
+}
+
+// NewTaskFieldHandler creates a new TaskFieldHandler instance
+func NewTaskFieldHandler(nudgeService nudge.NudgeService, logger *logger.Logger) *TaskFieldHandler {
+	return &TaskFieldHandler{
+		nudgeService: nudgeService,
+		logger:       logger,
+	}
+}
+
+// SetFieldRequest is the body for setting a custom field
+type SetFieldRequest struct {
+	Type    string   `json:"type" binding:"required"` // text, number, date, enum
+	Value   string   `json:"value" binding:"required"`
+	Options []string `json:"options,omitempty"` // allowed values of an enum field
+}
+
+// SetField sets a typed custom field on a task
+func (h *TaskFieldHandler) SetField(c *gin.Context) {
+	taskID, ok := h.taskID(c)
+	if !ok {
+		return
+	}
+
+	var req SetFieldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	field, err := nudge.NewCustomField(nudge.CustomFieldType(req.Type), req.Value, req.Options)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	task, err := h.serviceFor(c).SetCustomField(taskID, c.Param("key"), field)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// ClearField removes a custom field from a task
+func (h *TaskFieldHandler) ClearField(c *gin.Context) {
+	taskID, ok := h.taskID(c)
+	if !ok {
+		return
+	}
+
+	task, err := h.serviceFor(c).RemoveCustomField(taskID, c.Param("key"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, task)
+}
+
+// ListTasks returns a user's tasks. Tasks can be filtered by status and by
+// custom field values given as field[key]=value query parameters.
+func (h *TaskFieldHandler) ListTasks(c *gin.Context) {
+	userID := common.UserID(c.Param("userID"))
+	filter := nudge.TaskFilter{
+		UserID:       userID,
+		CustomFields: c.QueryMap("field"),
+	}
+	for _, status := range c.QueryArray("status") {
+		filter.Statuses = append(filter.Statuses, common.TaskStatus(status))
+	}
+
+	tasks, err := h.nudgeService.GetTasks(userID, filter)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user_id": userID,
+		"tasks":   tasks,
+	})
+}
+
+// taskID reads and validates the task ID path parameter
+func (h *TaskFieldHandler) taskID(c *gin.Context) (common.TaskID, bool) {
+	taskID := common.TaskID(c.Param("taskID"))
+	if !common.ID(taskID).IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "taskID must be a valid UUID"})
+		return "", false
+	}
+	return taskID, true
+}
+
+// serviceFor returns the nudge service acting in the tenant named by the
+// tenant query parameter, the default tenant when it is absent
+func (h *TaskFieldHandler) serviceFor(c *gin.Context) nudge.NudgeService {
+	return h.nudgeService.WithContext(tenant.WithID(c.Request.Context(), c.DefaultQuery("tenant", tenant.DefaultID)))
+}
+
+func (h *TaskFieldHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case nudge.IsValidationError(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, nudge.ErrTaskNotFound), nudge.IsNotFoundError(err):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+	default:
+		h.logger.Error("Task field request failed", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Task field request failed"})
+	}
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/nudge"
+	"nudgebot-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TaskHistoryHandler exposes the history timeline of tasks
+type TaskHistoryHandler struct {
+	historyService nudge.HistoryService
+	logger         *logger.Logger
+}
+
+// NewTaskHistoryHandler creates a new TaskHistoryHandler instance
+func NewTaskHistoryHandler(historyService nudge.HistoryService, logger *logger.Logger) *TaskHistoryHandler {
+	return &TaskHistoryHandler{
+		historyService: historyService,
+		logger:         logger,
+	}
+}
+
+// GetHistory returns a task's status transitions, snoozes, nudges and edits, oldest first
+func (h *TaskHistoryHandler) GetHistory(c *gin.Context) {
+	taskID := common.TaskID(c.Param("taskID"))
+	if !common.ID(taskID).IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "taskID must be a valid UUID"})
+		return
+	}
+
+	history, err := h.historyService.GetHistory(taskID)
+	if err != nil {
+		h.logger.Error("Failed to load task history", "task_id", taskID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load task history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"task_id": taskID,
+		"history": history,
+	})
+}
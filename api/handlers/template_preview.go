@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"nudgebot-api/internal/chatbot"
+	"nudgebot-api/internal/common"
+	"nudgebot-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TemplatePreviewHandler renders the bot's message templates with sample data
+// so admins can check copy changes before deploying them, optionally sending
+// the preview to an admin's chat
+type TemplatePreviewHandler struct {
+	chatbotService chatbot.ChatbotService
+	adminIDs       map[int64]bool
+	logger         *logger.Logger
+	now            func() time.Time
+}
+
+// NewTemplatePreviewHandler creates a new TemplatePreviewHandler instance.
+// Previews may be sent to the private chats of adminIDs; a nil chatbot
+// service only renders them.
+func NewTemplatePreviewHandler(chatbotService chatbot.ChatbotService, adminIDs []int64, logger *logger.Logger) *TemplatePreviewHandler {
+	admins := make(map[int64]bool, len(adminIDs))
+	for _, id := range adminIDs {
+		admins[id] = true
+	}
+	return &TemplatePreviewHandler{
+		chatbotService: chatbotService,
+		adminIDs:       admins,
+		logger:         logger,
+		now:            time.Now,
+	}
+}
+
+// PreviewTemplateRequest is the optional body of a preview. ChatID is the
+// Telegram ID of an admin whose private chat the preview is sent to.
+type PreviewTemplateRequest struct {
+	ChatID int64 `json:"chat_id"`
+}
+
+// ListTemplates returns the names of the templates that can be previewed
+func (h *TemplatePreviewHandler) ListTemplates(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"templates": chatbot.TemplateNames()})
+}
+
+// PreviewTemplate renders the template named by the name path parameter and
+// sends it to the admin chat given in the body, if any
+func (h *TemplatePreviewHandler) PreviewTemplate(c *gin.Context) {
+	var req PreviewTemplateRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+	}
+
+	name := c.Param("name")
+	text, err := chatbot.PreviewTemplate(name, h.now())
+	if errors.Is(err, chatbot.ErrUnknownTemplate) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown template", "templates": chatbot.TemplateNames()})
+		return
+	}
+	if err != nil {
+		h.logger.Error("Failed to render template preview", "template", name, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render template"})
+		return
+	}
+
+	if req.ChatID == 0 {
+		c.JSON(http.StatusOK, gin.H{"template": name, "text": text, "sent": false})
+		return
+	}
+	if !h.adminIDs[req.ChatID] {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Previews can only be sent to an admin's chat"})
+		return
+	}
+	if h.chatbotService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "The bot is not running"})
+		return
+	}
+
+	// Numeric chat IDs are Telegram chat IDs, and an admin's private chat shares their ID
+	if err := h.chatbotService.SendMessage(common.ChatID(strconv.FormatInt(req.ChatID, 10)), text); err != nil {
+		h.logger.Error("Failed to send template preview", "template", name, "chat_id", req.ChatID, "error", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to send the preview"})
+		return
+	}
+
+	h.logger.Info("Template preview sent", "template", name, "chat_id", req.ChatID, "client_ip", c.ClientIP())
+	c.JSON(http.StatusOK, gin.H{"template": name, "text": text, "sent": true})
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sentMessageRecorder records the messages sent through the chatbot service
+type sentMessageRecorder struct {
+	mockChatbotService
+	chats []common.ChatID
+	texts []string
+}
+
+func (r *sentMessageRecorder) SendMessage(chatID common.ChatID, text string) error {
+	r.chats = append(r.chats, chatID)
+	r.texts = append(r.texts, text)
+	return r.mockChatbotService.SendMessage(chatID, text)
+}
+
+func TestTemplatePreviewHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	chatbotService := &sentMessageRecorder{}
+	handler := NewTemplatePreviewHandler(chatbotService, []int64{42}, logger.New())
+	router := gin.New()
+	router.GET("/templates", handler.ListTemplates)
+	router.POST("/templates/:name/preview", handler.PreviewTemplate)
+
+	preview := func(name string, body interface{}) *httptest.ResponseRecorder {
+		var payload []byte
+		if body != nil {
+			var err error
+			payload, err = json.Marshal(body)
+			require.NoError(t, err)
+		}
+		req := httptest.NewRequest(http.MethodPost, "/templates/"+name+"/preview", bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	t.Run("lists templates", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/templates", nil))
+		require.Equal(t, http.StatusOK, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), `"digest"`)
+	})
+
+	t.Run("renders without sending", func(t *testing.T) {
+		recorder := preview("reminder", nil)
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var body struct {
+			Text string `json:"text"`
+			Sent bool   `json:"sent"`
+		}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+		assert.Contains(t, body.Text, "Task Reminder!")
+		assert.False(t, body.Sent)
+		assert.Empty(t, chatbotService.texts)
+	})
+
+	t.Run("sends to an admin's chat", func(t *testing.T) {
+		recorder := preview("digest", PreviewTemplateRequest{ChatID: 42})
+		require.Equal(t, http.StatusOK, recorder.Code)
+		require.Len(t, chatbotService.chats, 1)
+		assert.Equal(t, common.ChatID("42"), chatbotService.chats[0])
+		assert.Contains(t, chatbotService.texts[0], "Your daily digest")
+	})
+
+	t.Run("refuses other chats", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, preview("digest", PreviewTemplateRequest{ChatID: 7}).Code)
+		assert.Len(t, chatbotService.chats, 1)
+	})
+
+	t.Run("unknown template", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, preview("farewell", nil).Code)
+	})
+
+	t.Run("send failure", func(t *testing.T) {
+		chatbotService.shouldFail = true
+		defer func() { chatbotService.shouldFail = false }()
+		assert.Equal(t, http.StatusBadGateway, preview("reminder", PreviewTemplateRequest{ChatID: 42}).Code)
+	})
+}
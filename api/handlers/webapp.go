@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"time"
+
+	"nudgebot-api/api/middleware"
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/nudge"
+	"nudgebot-api/internal/tenant"
+	"nudgebot-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebAppHandler serves the task endpoints of the Telegram Web App. Every
+// request acts for the user the Web App was opened by.
+type WebAppHandler struct {
+	nudgeService nudge.NudgeService
+	logger       *logger.Logger
+}
+
+// NewWebAppHandler creates a new WebAppHandler instance
+func NewWebAppHandler(nudgeService nudge.NudgeService, logger *logger.Logger) *WebAppHandler {
+	return &WebAppHandler{
+		nudgeService: nudgeService,
+		logger:       logger,
+	}
+}
+
+// UpdateWebAppTaskRequest is the body for editing a task; omitted fields are
+// left as they are
+type UpdateWebAppTaskRequest struct {
+	Title        *string    `json:"title,omitempty"`
+	Description  *string    `json:"description,omitempty"`
+	Priority     *string    `json:"priority,omitempty"` // low, medium, high, urgent
+	DueDate      *time.Time `json:"due_date,omitempty"`
+	ClearDueDate bool       `json:"clear_due_date,omitempty"`
+}
+
+// ListTasks returns the user's open and snoozed tasks, or those in the
+// statuses given as status query parameters
+func (h *WebAppHandler) ListTasks(c *gin.Context) {
+	session := c.MustGet(middleware.WebAppUserKey).(*middleware.WebAppSession)
+
+	filter := nudge.TaskFilter{UserID: session.UserID, Statuses: append(common.OpenTaskStatuses(), common.TaskStatusSnoozed)}
+	if statuses := c.QueryArray("status"); len(statuses) > 0 {
+		filter.Statuses = nil
+		for _, status := range statuses {
+			filter.Statuses = append(filter.Statuses, common.TaskStatus(status))
+		}
+	}
+
+	tasks, err := h.serviceFor(c, session).GetTasks(session.UserID, filter)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tasks": tasks})
+}
+
+// UpdateTask edits the title, description, priority or due date of one of the user's tasks
+func (h *WebAppHandler) UpdateTask(c *gin.Context) {
+	session := c.MustGet(middleware.WebAppUserKey).(*middleware.WebAppSession)
+	task, ok := h.ownTask(c, session)
+	if !ok {
+		return
+	}
+
+	var req UpdateWebAppTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	edit := nudge.TaskEdit{
+		Title:        req.Title,
+		Description:  req.Description,
+		DueDate:      req.DueDate,
+		ClearDueDate: req.ClearDueDate,
+	}
+	if req.Priority != nil {
+		priority := common.Priority(*req.Priority)
+		if !priority.IsValid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "priority must be low, medium, high or urgent"})
+			return
+		}
+		edit.Priority = &priority
+	}
+
+	edited, err := h.serviceFor(c, session).EditTask(task.ID, edit)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, edited)
+}
+
+// CompleteTask marks one of the user's tasks as done and returns it
+func (h *WebAppHandler) CompleteTask(c *gin.Context) {
+	session := c.MustGet(middleware.WebAppUserKey).(*middleware.WebAppSession)
+	task, ok := h.ownTask(c, session)
+	if !ok {
+		return
+	}
+
+	if err := h.serviceFor(c, session).UpdateTaskStatus(task.ID, common.TaskStatusCompleted); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	completed, err := h.serviceFor(c, session).GetTask(session.UserID, task.ID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, completed)
+}
+
+// ownTask loads the task named by the taskID path parameter if it belongs to
+// the session's user; other users' tasks are reported as not found
+func (h *WebAppHandler) ownTask(c *gin.Context, session *middleware.WebAppSession) (*nudge.Task, bool) {
+	taskID := common.TaskID(c.Param("taskID"))
+	if !common.ID(taskID).IsValid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "taskID must be a valid UUID"})
+		return nil, false
+	}
+
+	task, err := h.serviceFor(c, session).GetTask(session.UserID, taskID)
+	if err != nil {
+		h.respondError(c, err)
+		return nil, false
+	}
+	return task, true
+}
+
+// serviceFor returns the nudge service acting for the session's user, so
+// tasks are looked up in the user's tenant
+func (h *WebAppHandler) serviceFor(c *gin.Context, session *middleware.WebAppSession) nudge.NudgeService {
+	return h.nudgeService.WithContext(tenant.WithUser(c.Request.Context(), session.UserID))
+}
+
+func (h *WebAppHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case nudge.IsValidationError(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, nudge.ErrTaskNotFound), nudge.IsNotFoundError(err):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+	default:
+		h.logger.Error("Web App request failed", "path", c.Request.URL.Path, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Web App request failed"})
+	}
+}
+
+// WebAppPageHandler serves the static files of the Telegram Web App
+type WebAppPageHandler struct {
+	files http.Handler
+}
+
+// NewWebAppPageHandler creates a handler serving the files of assets
+func NewWebAppPageHandler(assets fs.FS) *WebAppPageHandler {
+	return &WebAppPageHandler{files: http.FileServer(http.FS(assets))}
+}
+
+// Serve returns the file named by the filepath path parameter, index.html for the root
+func (h *WebAppPageHandler) Serve(c *gin.Context) {
+	// The page is opened inside Telegram and must not be framed elsewhere
+	c.Header("Content-Security-Policy", "frame-ancestors https://web.telegram.org https://*.telegram.org")
+	c.Header("Cache-Control", "no-cache")
+
+	req := c.Request.Clone(c.Request.Context())
+	req.URL.Path = c.Param("filepath")
+	h.files.ServeHTTP(c.Writer, req)
+}
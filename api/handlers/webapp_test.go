@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"nudgebot-api/api/middleware"
+	"nudgebot-api/internal/chatbot"
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/nudge"
+	"nudgebot-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestWebAppHandler(t *testing.T) {
+	const botToken = "123456:test-token"
+	gin.SetMode(gin.TestMode)
+	zapLogger := zaptest.NewLogger(t)
+	repo := nudge.NewMemoryNudgeRepository(zapLogger)
+	nudgeService, err := nudge.NewNudgeService(events.NewMockEventBus(), zapLogger, repo)
+	require.NoError(t, err)
+
+	identities := chatbot.NewMemoryIdentityMap()
+	userID, err := identities.Resolve("", 42)
+	require.NoError(t, err)
+	task := &nudge.Task{ID: common.TaskID(common.NewID()), UserID: common.UserID(userID), ChatID: common.ChatID(userID),
+		Title: "Buy milk", Priority: common.PriorityMedium, Status: common.TaskStatusActive}
+	require.NoError(t, repo.CreateTask(task))
+	other := &nudge.Task{ID: common.TaskID(common.NewID()), UserID: common.UserID(common.NewID()), ChatID: "12345",
+		Title: "Someone else's task", Priority: common.PriorityMedium, Status: common.TaskStatusActive}
+	require.NoError(t, repo.CreateTask(other))
+
+	log := logger.New()
+	handler := NewWebAppHandler(nudgeService, log)
+	router := gin.New()
+	router.GET("/webapp/*filepath", NewWebAppPageHandler(fstest.MapFS{"index.html": {Data: []byte("<h1>My tasks</h1>")}}).Serve)
+	tasks := router.Group("/api/v1/webapp/tasks", middleware.WebAppAuth(botToken, time.Hour, identities, log))
+	tasks.GET("", handler.ListTasks)
+	tasks.PATCH("/:taskID", handler.UpdateTask)
+	tasks.POST("/:taskID/complete", handler.CompleteTask)
+
+	initData := chatbot.SignWebAppInitData(chatbot.WebAppUser{ID: 42, FirstName: "Ada"}, time.Now(), botToken)
+	send := func(method, path, initData string, body interface{}) *httptest.ResponseRecorder {
+		var payload []byte
+		if body != nil {
+			payload, err = json.Marshal(body)
+			require.NoError(t, err)
+		}
+		req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "tma "+initData)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	t.Run("lists the user's tasks", func(t *testing.T) {
+		recorder := send(http.MethodGet, "/api/v1/webapp/tasks", initData, nil)
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var body struct {
+			Tasks []nudge.Task `json:"tasks"`
+		}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+		require.Len(t, body.Tasks, 1)
+		assert.Equal(t, "Buy milk", body.Tasks[0].Title)
+	})
+
+	t.Run("edits a task", func(t *testing.T) {
+		recorder := send(http.MethodPatch, "/api/v1/webapp/tasks/"+string(task.ID), initData, map[string]string{"title": "Buy oat milk", "priority": "high"})
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var edited nudge.Task
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &edited))
+		assert.Equal(t, "Buy oat milk", edited.Title)
+		assert.Equal(t, common.PriorityHigh, edited.Priority)
+
+		assert.Equal(t, http.StatusBadRequest, send(http.MethodPatch, "/api/v1/webapp/tasks/"+string(task.ID), initData, map[string]string{"priority": "someday"}).Code)
+	})
+
+	t.Run("completes a task", func(t *testing.T) {
+		recorder := send(http.MethodPost, "/api/v1/webapp/tasks/"+string(task.ID)+"/complete", initData, nil)
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		stored, err := repo.GetTaskByID(task.ID)
+		require.NoError(t, err)
+		assert.Equal(t, common.TaskStatusCompleted, stored.Status)
+	})
+
+	t.Run("hides other users' tasks", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, send(http.MethodPost, "/api/v1/webapp/tasks/"+string(other.ID)+"/complete", initData, nil).Code)
+		assert.Equal(t, http.StatusNotFound, send(http.MethodPatch, "/api/v1/webapp/tasks/"+string(other.ID), initData, map[string]string{"title": "Mine now"}).Code)
+	})
+
+	t.Run("rejects unsigned requests", func(t *testing.T) {
+		forged := chatbot.SignWebAppInitData(chatbot.WebAppUser{ID: 42}, time.Now(), "654321:other-token")
+		assert.Equal(t, http.StatusUnauthorized, send(http.MethodGet, "/api/v1/webapp/tasks", forged, nil).Code)
+		assert.Equal(t, http.StatusUnauthorized, send(http.MethodGet, "/api/v1/webapp/tasks", "", nil).Code)
+	})
+
+	t.Run("serves the page", func(t *testing.T) {
+		recorder := send(http.MethodGet, "/webapp/", "", nil)
+		require.Equal(t, http.StatusOK, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "My tasks")
+	})
+}
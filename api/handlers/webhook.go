@@ -1,11 +1,13 @@
 package handlers
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 
 	"nudgebot-api/internal/chatbot"
+	"nudgebot-api/internal/events"
 	"nudgebot-api/pkg/logger"
 
 	"github.com/gin-gonic/gin"
@@ -14,13 +16,32 @@ import (
 // WebhookHandler handles Telegram webhook requests
 type WebhookHandler struct {
 	chatbotService chatbot.ChatbotService
+	eventBus       events.EventBus
+	bot            string
 	logger         *logger.Logger
 }
 
-// NewWebhookHandler creates a new WebhookHandler instance
+// NewWebhookHandler creates a WebhookHandler that processes updates before responding
 func NewWebhookHandler(chatbotService chatbot.ChatbotService, logger *logger.Logger) *WebhookHandler {
+	return NewWebhookHandlerWithEventBus(chatbotService, nil, logger)
+}
+
+// NewWebhookHandlerWithEventBus creates a WebhookHandler that queues validated
+// updates on the event bus and responds immediately, so slow processing cannot
+// make Telegram time out and resend the update. A nil event bus processes
+// updates before responding.
+func NewWebhookHandlerWithEventBus(chatbotService chatbot.ChatbotService, eventBus events.EventBus, logger *logger.Logger) *WebhookHandler {
+	return NewWebhookHandlerForBot(chatbotService, eventBus, "", logger)
+}
+
+// NewWebhookHandlerForBot creates a WebhookHandler for the named bot in a
+// deployment running several bots. Queued updates carry the bot name so only
+// that bot's chatbot service processes them.
+func NewWebhookHandlerForBot(chatbotService chatbot.ChatbotService, eventBus events.EventBus, bot string, logger *logger.Logger) *WebhookHandler {
 	return &WebhookHandler{
 		chatbotService: chatbotService,
+		eventBus:       eventBus,
+		bot:            bot,
 		logger:         logger,
 	}
 }
@@ -63,6 +84,12 @@ func (h *WebhookHandler) HandleTelegramWebhook(c *gin.Context) {
 			"content_type", contentType)
 	}
 
+	if h.eventBus != nil {
+		h.enqueueUpdate(body, correlationID)
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+		return
+	}
+
 	// Process the webhook through the chatbot service
 	err = h.chatbotService.HandleWebhook(body)
 	if err != nil {
@@ -83,11 +110,53 @@ func (h *WebhookHandler) HandleTelegramWebhook(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"ok": true})
 }
 
+// enqueueUpdate validates the update envelope and publishes it for the chatbot
+// to process. Updates that cannot be published are processed inline.
+func (h *WebhookHandler) enqueueUpdate(body []byte, correlationID string) {
+	var envelope struct {
+		UpdateID *int `json:"update_id"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil || envelope.UpdateID == nil {
+		h.logger.Warn("Dropping invalid webhook update",
+			"correlation_id", correlationID,
+			"body_size", len(body),
+			"error", err)
+		return
+	}
+
+	event := events.UpdateReceived{
+		Event:    events.NewEvent(),
+		UpdateID: *envelope.UpdateID,
+		Bot:      h.bot,
+		Payload:  body,
+	}
+	if err := h.eventBus.Publish(events.TopicUpdateReceived, event); err != nil {
+		h.logger.Error("Failed to queue webhook update, processing inline",
+			"correlation_id", correlationID,
+			"update_id", event.UpdateID,
+			"error", err)
+		if err := h.chatbotService.HandleWebhook(body); err != nil {
+			h.logger.Error("Failed to process webhook",
+				"correlation_id", correlationID,
+				"error", err,
+				"body_size", len(body))
+		}
+		return
+	}
+
+	h.logger.Debug("Webhook update queued",
+		"correlation_id", correlationID,
+		"update_id", event.UpdateID)
+}
+
+// SetupWebhookRequest is the body for configuring the webhook URL
+type SetupWebhookRequest struct {
+	WebhookURL string `json:"webhook_url" binding:"required"`
+}
+
 // SetupWebhook configures the webhook URL with Telegram (for development)
 func (h *WebhookHandler) SetupWebhook(c *gin.Context) {
-	var request struct {
-		WebhookURL string `json:"webhook_url" binding:"required"`
-	}
+	var request SetupWebhookRequest
 
 	if err := c.ShouldBindJSON(&request); err != nil {
 		h.logger.Error("Invalid webhook setup request", "error", err)
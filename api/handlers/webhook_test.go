@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -10,10 +11,12 @@ import (
 
 	"nudgebot-api/internal/chatbot"
 	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
 	"nudgebot-api/pkg/logger"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 )
 
@@ -21,6 +24,7 @@ import (
 type mockChatbotService struct {
 	shouldFail         bool
 	handleWebhookError error
+	webhookCalls       int
 }
 
 func (m *mockChatbotService) SendMessage(chatID common.ChatID, text string) error {
@@ -38,6 +42,7 @@ func (m *mockChatbotService) SendMessageWithKeyboard(chatID common.ChatID, text
 }
 
 func (m *mockChatbotService) HandleWebhook(webhookData []byte) error {
+	m.webhookCalls++
 	if m.handleWebhookError != nil {
 		return m.handleWebhookError
 	}
@@ -51,6 +56,24 @@ func (m *mockChatbotService) ProcessCommand(command chatbot.Command, userID comm
 	return nil
 }
 
+func (m *mockChatbotService) Ready() <-chan struct{} {
+	ready := make(chan struct{})
+	close(ready)
+	return ready
+}
+
+func (m *mockChatbotService) Start(ctx context.Context) error {
+	return nil
+}
+
+func (m *mockChatbotService) Stop(ctx context.Context) error {
+	return nil
+}
+
+func (m *mockChatbotService) Health() error {
+	return nil
+}
+
 // Mock database
 type mockDB struct {
 	gorm.DB
@@ -408,3 +431,75 @@ func TestWebhookHandler_LargeRequestHandling(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, true, response["ok"])
 }
+
+func TestWebhookHandler_QueuesUpdatesOnEventBus(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    string
+		expectedQueued int
+	}{
+		{
+			name:           "valid update is queued",
+			requestBody:    `{"update_id": 42, "message": {"message_id": 1, "text": "hello"}}`,
+			expectedQueued: 1,
+		},
+		{
+			name:           "invalid JSON is dropped",
+			requestBody:    `{"update_id":`,
+			expectedQueued: 0,
+		},
+		{
+			name:           "missing update_id is dropped",
+			requestBody:    `{"message": {"message_id": 1}}`,
+			expectedQueued: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupTest()
+			mockService := &mockChatbotService{}
+			eventBus := events.NewMockEventBus()
+
+			handler := NewWebhookHandlerWithEventBus(mockService, eventBus, logger.New())
+			router.POST("/webhook", handler.HandleTelegramWebhook)
+
+			req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Zero(t, mockService.webhookCalls, "updates must not be processed in the request")
+
+			queued := eventBus.GetPublishedEvents(events.TopicUpdateReceived)
+			assert.Len(t, queued, tt.expectedQueued)
+			if tt.expectedQueued > 0 {
+				update := queued[0].(events.UpdateReceived)
+				assert.Equal(t, 42, update.UpdateID)
+				assert.JSONEq(t, tt.requestBody, string(update.Payload))
+			}
+		})
+	}
+}
+
+func TestWebhookHandler_QueuedUpdatesCarryBotName(t *testing.T) {
+	router := setupTest()
+	mockService := &mockChatbotService{}
+	eventBus := events.NewMockEventBus()
+
+	handler := NewWebhookHandlerForBot(mockService, eventBus, "staging", logger.New())
+	router.POST("/webhook/staging", handler.HandleTelegramWebhook)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook/staging", bytes.NewBufferString(`{"update_id": 7}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	queued := eventBus.GetPublishedEvents(events.TopicUpdateReceived)
+	require.Len(t, queued, 1)
+	assert.Equal(t, "staging", queued[0].(events.UpdateReceived).Bot)
+}
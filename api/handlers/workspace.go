@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/nudge"
+	"nudgebot-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WorkspaceHandler manages workspace members and invites. Every change names
+// the acting user, whose workspace role must allow it.
+type WorkspaceHandler struct {
+	workspaceService nudge.WorkspaceService
+	logger           *logger.Logger
+}
+
+// NewWorkspaceHandler creates a new WorkspaceHandler instance
+func NewWorkspaceHandler(workspaceService nudge.WorkspaceService, logger *logger.Logger) *WorkspaceHandler {
+	return &WorkspaceHandler{
+		workspaceService: workspaceService,
+		logger:           logger,
+	}
+}
+
+// SetRoleRequest is the body for changing a member's role
+type SetRoleRequest struct {
+	ActorID string `json:"actor_id" binding:"required"`
+	Role    string `json:"role" binding:"required"`
+}
+
+// CreateInviteRequest is the body for creating an invite
+type CreateInviteRequest struct {
+	ActorID string `json:"actor_id" binding:"required"`
+	Role    string `json:"role" binding:"required"`
+}
+
+// ListMembers returns the members of a workspace
+func (h *WorkspaceHandler) ListMembers(c *gin.Context) {
+	chatID := common.ChatID(c.Param("chatID"))
+
+	members, err := h.workspaceService.ListMembers(chatID)
+	if err != nil {
+		h.respondError(c, chatID, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"chat_id": chatID,
+		"members": members,
+	})
+}
+
+// SetRole adds a member or changes their role
+func (h *WorkspaceHandler) SetRole(c *gin.Context) {
+	chatID := common.ChatID(c.Param("chatID"))
+	userID := common.UserID(c.Param("userID"))
+
+	var req SetRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if err := h.workspaceService.SetRole(chatID, common.UserID(req.ActorID), userID, nudge.Role(req.Role)); err != nil {
+		h.respondError(c, chatID, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"chat_id": chatID,
+		"user_id": userID,
+		"role":    req.Role,
+	})
+}
+
+// RemoveMember removes a member; the actor_id query parameter names the acting user
+func (h *WorkspaceHandler) RemoveMember(c *gin.Context) {
+	chatID := common.ChatID(c.Param("chatID"))
+	userID := common.UserID(c.Param("userID"))
+	actorID := c.Query("actor_id")
+	if actorID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "actor_id is required"})
+		return
+	}
+
+	if err := h.workspaceService.RemoveMember(chatID, common.UserID(actorID), userID); err != nil {
+		h.respondError(c, chatID, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// CreateInvite creates an invite code for the workspace
+func (h *WorkspaceHandler) CreateInvite(c *gin.Context) {
+	chatID := common.ChatID(c.Param("chatID"))
+
+	var req CreateInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	invite, err := h.workspaceService.CreateInvite(chatID, common.UserID(req.ActorID), nudge.Role(req.Role))
+	if err != nil {
+		h.respondError(c, chatID, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"code":       invite.Code,
+		"role":       invite.Role,
+		"expires_at": invite.ExpiresAt.Format(time.RFC3339),
+	})
+}
+
+func (h *WorkspaceHandler) respondError(c *gin.Context, chatID common.ChatID, err error) {
+	var permissionErr nudge.PermissionError
+	var validationErr nudge.TaskValidationError
+	var ruleErr nudge.BusinessRuleError
+	switch {
+	case errors.As(err, &permissionErr):
+		c.JSON(http.StatusForbidden, gin.H{"error": permissionErr.Message()})
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Message()})
+	case errors.As(err, &ruleErr):
+		c.JSON(http.StatusConflict, gin.H{"error": ruleErr.Message()})
+	default:
+		h.logger.Error("Workspace request failed", "chat_id", chatID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Workspace request failed"})
+	}
+}
@@ -0,0 +1,28 @@
+// Package landing holds the template and static files of the public landing
+// page served at the site root
+package landing
+
+import (
+	"embed"
+	"html/template"
+	"io/fs"
+)
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+//go:embed static
+var static embed.FS
+
+// Template renders the landing page as "index.html.tmpl"
+var Template = template.Must(template.ParseFS(templates, "templates/*.tmpl"))
+
+// Static are the files the page links to below /static
+var Static fs.FS
+
+func init() {
+	var err error
+	if Static, err = fs.Sub(static, "static"); err != nil {
+		panic(err)
+	}
+}
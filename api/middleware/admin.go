@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"nudgebot-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminAuth protects admin endpoints with a static bearer token.
+// When no token is configured every request is rejected.
+func AdminAuth(token string, logger *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			logger.Warn("Admin endpoint called but no admin token is configured", "path", c.Request.URL.Path)
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Admin endpoints are disabled"})
+			return
+		}
+
+		provided := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			logger.Warn("Rejected admin request", "path", c.Request.URL.Path, "client_ip", c.ClientIP())
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"nudgebot-api/internal/nudge"
+	"nudgebot-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APITokenKey is the context key under which APITokenAuth stores the *nudge.APIToken
+const APITokenKey = "api_token"
+
+// APITokenAuth protects user endpoints with a personal API token sent as a
+// bearer token. The authenticated token is stored under APITokenKey.
+func APITokenAuth(tokens nudge.APITokenService, logger *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+
+		token, err := tokens.Authenticate(provided)
+		if err != nil {
+			if !errors.Is(err, nudge.ErrAPITokenNotFound) {
+				logger.Error("API token lookup failed", "path", c.Request.URL.Path, "error", err)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Authentication failed"})
+				return
+			}
+			logger.Warn("Rejected API token request", "path", c.Request.URL.Path, "client_ip", c.ClientIP())
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		c.Set(APITokenKey, token)
+		c.Next()
+	}
+}
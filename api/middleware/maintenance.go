@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"nudgebot-api/internal/maintenance"
+	"nudgebot-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Maintenance answers 503 with the maintenance notice while the service is
+// down for maintenance. Requests for the exempt paths, and for anything
+// below them, are served as usual.
+func Maintenance(maintenanceSwitch *maintenance.Switch, logger *logger.Logger, exempt ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !maintenanceSwitch.Enabled() || isExemptPath(c.Request.URL.Path, exempt) {
+			c.Next()
+			return
+		}
+
+		logger.Debug("Refused request during maintenance", "path", c.Request.URL.Path)
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"error":       maintenanceSwitch.Status().Message,
+			"maintenance": true,
+		})
+	}
+}
+
+// isExemptPath reports whether path is one of the exempt paths or below one
+func isExemptPath(path string, exempt []string) bool {
+	for _, prefix := range exempt {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"nudgebot-api/internal/chatbot"
+	"nudgebot-api/internal/common"
+	"nudgebot-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebAppUserKey is the context key under which WebAppAuth stores the *WebAppSession
+const WebAppUserKey = "webapp_user"
+
+// WebAppSession is the user a Telegram Web App request was made by, with the
+// internal IDs the bot knows them by. The Web App opens from the private chat,
+// whose ID Telegram gives the user, so both map to one internal ID.
+type WebAppSession struct {
+	User   chatbot.WebAppUser
+	UserID common.UserID
+	ChatID common.ChatID
+}
+
+// WebAppAuth protects the Web App endpoints with the Telegram.WebApp.initData
+// the page was opened with, sent as "Authorization: tma <initData>" and signed
+// with the default bot's token. Init data older than maxAge is refused. A nil
+// identity map derives internal IDs without recording them.
+func WebAppAuth(botToken string, maxAge time.Duration, identities chatbot.IdentityMap, logger *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		initData := strings.TrimPrefix(c.GetHeader("Authorization"), "tma ")
+
+		data, err := chatbot.ValidateWebAppInitData(initData, botToken, maxAge, time.Now())
+		if err != nil {
+			logger.Warn("Rejected Web App request", "path", c.Request.URL.Path, "client_ip", c.ClientIP(), "error", err)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		id := chatbot.DeriveInternalID("", data.User.ID)
+		if identities != nil {
+			if id, err = identities.Resolve("", data.User.ID); err != nil {
+				logger.Error("Web App identity lookup failed", "path", c.Request.URL.Path, "error", err)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Authentication failed"})
+				return
+			}
+		}
+
+		c.Set(WebAppUserKey, &WebAppSession{User: data.User, UserID: common.UserID(id), ChatID: common.ChatID(id)})
+		c.Next()
+	}
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"nudgebot-api/internal/config"
+	"nudgebot-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookAllowlist refuses webhook requests that don't come from the
+// configured ranges, normally Telegram's published webhook ranges. When the
+// allowlist is disabled every request passes.
+func WebhookAllowlist(cfg config.WebhookAllowlistConfig, logger *logger.Logger) (gin.HandlerFunc, error) {
+	if !cfg.Enabled {
+		return func(c *gin.Context) { c.Next() }, nil
+	}
+
+	allowed, err := parsePrefixes(cfg.Ranges)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook allowlist range: %w", err)
+	}
+	if len(allowed) == 0 {
+		return nil, fmt.Errorf("the webhook allowlist is enabled without any ranges")
+	}
+	proxies, err := parsePrefixes(cfg.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook allowlist trusted proxy: %w", err)
+	}
+
+	return func(c *gin.Context) {
+		addr, ok := webhookSender(c, proxies)
+		if !ok || !containsAddr(allowed, addr) {
+			logger.Warn("Rejected webhook request from outside the allowlist",
+				"path", c.Request.URL.Path,
+				"remote_addr", c.Request.RemoteAddr,
+				"forwarded_for", c.GetHeader("X-Forwarded-For"))
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+			return
+		}
+		c.Next()
+	}, nil
+}
+
+// webhookSender returns the address that sent the request. Through trusted
+// proxies it is the last X-Forwarded-For entry not added by one of them.
+func webhookSender(c *gin.Context, proxies []netip.Prefix) (netip.Addr, bool) {
+	addr, err := netip.ParseAddr(c.RemoteIP())
+	if err != nil {
+		return netip.Addr{}, false
+	}
+
+	forwarded := strings.Split(c.GetHeader("X-Forwarded-For"), ",")
+	for i := len(forwarded) - 1; i >= 0 && containsAddr(proxies, addr); i-- {
+		hop := strings.TrimSpace(forwarded[i])
+		if hop == "" {
+			continue
+		}
+		if addr, err = netip.ParseAddr(hop); err != nil {
+			return netip.Addr{}, false
+		}
+	}
+	return addr.Unmap(), true
+}
+
+func parsePrefixes(ranges []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(ranges))
+	for _, r := range ranges {
+		r = strings.TrimSpace(r)
+		if !strings.Contains(r, "/") {
+			addr, err := netip.ParseAddr(r)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(r)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
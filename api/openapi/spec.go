@@ -0,0 +1,16 @@
+// Package openapi holds the OpenAPI document describing the versioned REST API
+package openapi
+
+import _ "embed"
+
+// Version is the API version served under BasePath
+const Version = "v1"
+
+// BasePath is the prefix every versioned endpoint is registered under
+const BasePath = "/api/" + Version
+
+// Spec is the OpenAPI 3 document for the endpoints under BasePath. Handlers
+// and this document are kept in step by the contract tests in api/routes.
+//
+//go:embed openapi.yaml
+var Spec []byte
@@ -0,0 +1,229 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"nudgebot-api/api/handlers"
+	"nudgebot-api/api/openapi"
+	"nudgebot-api/internal/account"
+	"nudgebot-api/internal/chatbot"
+	"nudgebot-api/internal/config"
+	"nudgebot-api/internal/debugcapture"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/experiment"
+	"nudgebot-api/internal/featureflags"
+	"nudgebot-api/internal/llm"
+	"nudgebot-api/internal/maintenance"
+	"nudgebot-api/internal/nudge"
+	"nudgebot-api/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+// The stubs only need to be non-nil so that every optional route group is
+// registered; the contract tests never call them.
+type (
+	stubExperimentService struct{ experiment.ExperimentService }
+	stubFlagService       struct{ featureflags.FlagService }
+	stubWorkspaceService  struct{ nudge.WorkspaceService }
+	stubMergeService      struct{ account.MergeService }
+	stubHistoryService    struct{ nudge.HistoryService }
+	stubNudgeService      struct{ nudge.NudgeService }
+)
+
+type specDocument struct {
+	Servers []struct {
+		URL string `yaml:"url"`
+	} `yaml:"servers"`
+	Paths      map[string]map[string]specOperation `yaml:"paths"`
+	Components struct {
+		Schemas map[string]specSchema `yaml:"schemas"`
+	} `yaml:"components"`
+}
+
+type specOperation struct {
+	OperationID string `yaml:"operationId"`
+	RequestBody *struct {
+		Content map[string]struct {
+			Schema specSchema `yaml:"schema"`
+		} `yaml:"content"`
+	} `yaml:"requestBody"`
+}
+
+type specSchema struct {
+	Ref        string                `yaml:"$ref"`
+	Required   []string              `yaml:"required"`
+	Properties map[string]specSchema `yaml:"properties"`
+}
+
+// requestBodies maps each request schema in the spec to the struct its handler binds
+var requestBodies = map[string]interface{}{
+	"QuickAddRequest":         handlers.QuickAddRequest{},
+	"SetupWebhookRequest":     handlers.SetupWebhookRequest{},
+	"SetOverrideRequest":      handlers.SetOverrideRequest{},
+	"SetRoleRequest":          handlers.SetRoleRequest{},
+	"CreateInviteRequest":     handlers.CreateInviteRequest{},
+	"MergeAccountsRequest":    handlers.MergeAccountsRequest{},
+	"UndoMergeRequest":        handlers.UndoMergeRequest{},
+	"SetFieldRequest":         handlers.SetFieldRequest{},
+	"SetLogLevelsRequest":     handlers.SetLogLevelsRequest{},
+	"SetMaintenanceRequest":   handlers.SetMaintenanceRequest{},
+	"UpdateWebAppTaskRequest": handlers.UpdateWebAppTaskRequest{},
+	"PreviewTemplateRequest":  handlers.PreviewTemplateRequest{},
+}
+
+func loadSpec(t *testing.T) specDocument {
+	t.Helper()
+	var spec specDocument
+	require.NoError(t, yaml.Unmarshal(openapi.Spec, &spec))
+	return spec
+}
+
+// createFullRouter registers every route the server can expose
+func createFullRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	log := logger.New()
+	levels, _ := logger.NewLevels("info", nil)
+	prompts, _ := llm.NewPromptStore("", zap.NewNop())
+	maintenanceSwitch := maintenance.NewSwitch(events.NewMockEventBus(), zap.NewNop(), config.MaintenanceConfig{})
+
+	router := gin.New()
+	SetupRoutes(router, &gorm.DB{}, log, &mockChatbotService{}, nil, maintenanceSwitch)
+	// Bot names become path segments; a parameter stands in for any configured name
+	SetupBotRoutes(router, log, nil, map[string]chatbot.ChatbotService{":bot": &mockChatbotService{}})
+	SetupAdminRoutes(router, log, "token", &stubExperimentService{}, &stubFlagService{}, &stubWorkspaceService{},
+		&stubMergeService{}, &stubHistoryService{}, &stubNudgeService{}, debugcapture.NewRecorder(10, 0, nil, true), levels, prompts, maintenanceSwitch, &mockChatbotService{}, []int64{42})
+	SetupQuickAddRoutes(router, log, nil, nil, nil)
+	SetupWebAppRoutes(router, log, "token", time.Hour, nil, nil)
+	SetupMetricsRoutes(router, log, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	return router
+}
+
+// specPath converts a gin route path into the OpenAPI path relative to the server URL
+func specPath(route string) string {
+	segments := strings.Split(strings.TrimPrefix(route, openapi.BasePath), "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + strings.TrimPrefix(segment, ":") + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func TestOpenAPISpec_MatchesRoutes(t *testing.T) {
+	spec := loadSpec(t)
+	require.Len(t, spec.Servers, 1)
+	assert.Equal(t, openapi.BasePath, spec.Servers[0].URL)
+
+	registered := map[string]bool{}
+	for _, route := range createFullRouter().Routes() {
+		if !strings.HasPrefix(route.Path, "/api/") {
+			continue // unversioned probes such as the root /health
+		}
+		assert.Truef(t, strings.HasPrefix(route.Path, openapi.BasePath+"/"), "route %s %s is not under %s", route.Method, route.Path, openapi.BasePath)
+		registered[route.Method+" "+specPath(route.Path)] = true
+	}
+
+	documented := map[string]bool{}
+	for path, operations := range spec.Paths {
+		for method, operation := range operations {
+			assert.NotEmptyf(t, operation.OperationID, "%s %s has no operationId", method, path)
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	for route := range registered {
+		assert.Truef(t, documented[route], "route %s is registered but missing from openapi.yaml", route)
+	}
+	for route := range documented {
+		assert.Truef(t, registered[route], "route %s is documented in openapi.yaml but not registered", route)
+	}
+}
+
+func TestOpenAPISpec_RequestBodiesMatchHandlers(t *testing.T) {
+	spec := loadSpec(t)
+
+	for path, operations := range spec.Paths {
+		for method, operation := range operations {
+			if operation.RequestBody == nil {
+				continue
+			}
+			ref := operation.RequestBody.Content["application/json"].Schema.Ref
+			if ref == "" {
+				continue // free-form bodies such as Telegram updates
+			}
+
+			name := strings.TrimPrefix(ref, "#/components/schemas/")
+			body, ok := requestBodies[name]
+			if !assert.Truef(t, ok, "%s %s uses %s, which is not mapped to a handler request type", method, path, name) {
+				continue
+			}
+			schema, ok := spec.Components.Schemas[name]
+			require.Truef(t, ok, "schema %s is not defined", name)
+
+			fields, required := jsonFields(reflect.TypeOf(body))
+			assert.Equalf(t, fields, sortedKeys(schema.Properties), "properties of %s", name)
+			assert.ElementsMatchf(t, required, schema.Required, "required properties of %s", name)
+		}
+	}
+}
+
+func TestOpenAPISpec_TaskSchemaMatchesModel(t *testing.T) {
+	spec := loadSpec(t)
+
+	fields, _ := jsonFields(reflect.TypeOf(nudge.Task{}))
+	assert.Equal(t, fields, sortedKeys(spec.Components.Schemas["Task"].Properties))
+}
+
+func TestOpenAPISpec_Served(t *testing.T) {
+	router := createTestRouter()
+
+	req := httptest.NewRequest(http.MethodGet, openapi.BasePath+"/openapi.yaml", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, openapi.Spec, w.Body.Bytes())
+}
+
+// jsonFields returns the sorted JSON names of a struct's fields and the names
+// gin's binding requires
+func jsonFields(structType reflect.Type) ([]string, []string) {
+	var fields, required []string
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, name)
+		if strings.Contains(field.Tag.Get("binding"), "required") {
+			required = append(required, name)
+		}
+	}
+	sort.Strings(fields)
+	return fields, required
+}
+
+func sortedKeys(properties map[string]specSchema) []string {
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,31 +1,71 @@
 package routes
 
 import (
+	"net/http"
+	"time"
+
 	"nudgebot-api/api/handlers"
+	"nudgebot-api/api/landing"
 	"nudgebot-api/api/middleware"
+	"nudgebot-api/api/openapi"
+	"nudgebot-api/api/webapp"
+	"nudgebot-api/internal/account"
 	"nudgebot-api/internal/chatbot"
+	"nudgebot-api/internal/config"
+	"nudgebot-api/internal/database"
+	"nudgebot-api/internal/debugcapture"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/experiment"
+	"nudgebot-api/internal/featureflags"
+	"nudgebot-api/internal/governor"
+	"nudgebot-api/internal/httpclient"
+	"nudgebot-api/internal/llm"
+	"nudgebot-api/internal/maintenance"
+	"nudgebot-api/internal/nudge"
+	"nudgebot-api/internal/probe"
+	"nudgebot-api/internal/scheduler"
+	"nudgebot-api/internal/startup"
 	"nudgebot-api/pkg/logger"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-func SetupRoutes(router *gin.Engine, db *gorm.DB, logger *logger.Logger, chatbotService chatbot.ChatbotService) {
+// SetupRoutes registers the middleware and core endpoints. It must run before
+// the other Setup functions, since gin applies middleware only to routes
+// registered after it. A nil maintenance switch never refuses requests.
+// webhookMiddleware, such as the webhook allowlist, guards only the endpoint
+// Telegram delivers updates to.
+func SetupRoutes(router *gin.Engine, db *gorm.DB, logger *logger.Logger, chatbotService chatbot.ChatbotService, eventBus events.EventBus, maintenanceSwitch *maintenance.Switch, webhookMiddleware ...gin.HandlerFunc) {
 	// Add middleware
 	router.Use(middleware.RequestLogging(logger))
 	router.Use(gin.Recovery())
+	// Health, metrics and admin stay up during maintenance, as does the landing
+	// page announcing it, and the bots answer Telegram updates with the
+	// maintenance notice themselves
+	router.Use(middleware.Maintenance(maintenanceSwitch, logger,
+		"/",
+		"/static",
+		"/health",
+		openapi.BasePath+"/health",
+		openapi.BasePath+"/metrics",
+		openapi.BasePath+"/admin",
+		openapi.BasePath+"/telegram/webhook",
+	))
 
 	// Initialize handlers
 	healthHandler := handlers.NewHealthHandler(db, logger)
-	webhookHandler := handlers.NewWebhookHandler(chatbotService, logger)
+	webhookHandler := handlers.NewWebhookHandlerWithEventBus(chatbotService, eventBus, logger)
+	openAPIHandler := handlers.NewOpenAPIHandler(openapi.Spec)
 
 	// Setup routes
-	v1 := router.Group("/api/v1")
+	v1 := router.Group(openapi.BasePath)
 	{
 		v1.GET("/health", healthHandler.Check)
+		v1.GET("/openapi.yaml", openAPIHandler.GetSpec)
 
 		// Telegram webhook endpoints
-		v1.POST("/telegram/webhook", webhookHandler.HandleTelegramWebhook)
+		v1.Group("/telegram/webhook", webhookMiddleware...).POST("", webhookHandler.HandleTelegramWebhook)
 		v1.POST("/telegram/setup-webhook", webhookHandler.SetupWebhook)
 		v1.GET("/telegram/webhook-info", webhookHandler.GetWebhookInfo)
 	}
@@ -33,3 +73,141 @@ func SetupRoutes(router *gin.Engine, db *gorm.DB, logger *logger.Logger, chatbot
 	// Root health check
 	router.GET("/health", healthHandler.Check)
 }
+
+// SetupBotRoutes registers a webhook endpoint for each additional bot at
+// /api/v1/telegram/webhook/<name>, guarded by webhookMiddleware
+func SetupBotRoutes(router *gin.Engine, logger *logger.Logger, eventBus events.EventBus, bots map[string]chatbot.ChatbotService, webhookMiddleware ...gin.HandlerFunc) {
+	v1 := router.Group(openapi.BasePath, webhookMiddleware...)
+	for name, chatbotService := range bots {
+		webhookHandler := handlers.NewWebhookHandlerForBot(chatbotService, eventBus, name, logger)
+		v1.POST("/telegram/webhook/"+name, webhookHandler.HandleTelegramWebhook)
+	}
+}
+
+// SetupStartupRoutes registers the health endpoints served while dependencies
+// are still starting; every other route answers 503
+func SetupStartupRoutes(router *gin.Engine, logger *logger.Logger, orchestrator *startup.Orchestrator) {
+	router.Use(gin.Recovery())
+
+	startupHandler := handlers.NewStartupHandler(orchestrator, logger)
+	router.GET("/health", startupHandler.Check)
+	router.GET(openapi.BasePath+"/health", startupHandler.Check)
+	router.NoRoute(func(c *gin.Context) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Service is starting"})
+	})
+}
+
+// SetupAdminRoutes registers admin-only endpoints guarded by the admin token.
+// Template previews are sent through chatbotService to the chats of adminIDs.
+func SetupAdminRoutes(router *gin.Engine, logger *logger.Logger, adminToken string, experimentService experiment.ExperimentService, flagService featureflags.FlagService, workspaceService nudge.WorkspaceService, mergeService account.MergeService, historyService nudge.HistoryService, nudgeService nudge.NudgeService, captureRecorder *debugcapture.Recorder, logLevels *logger.Levels, prompts *llm.PromptStore, maintenanceSwitch *maintenance.Switch, chatbotService chatbot.ChatbotService, adminIDs []int64) {
+	admin := router.Group(openapi.BasePath+"/admin", middleware.AdminAuth(adminToken, logger))
+
+	if experimentService != nil {
+		experimentHandler := handlers.NewExperimentHandler(experimentService, logger)
+		admin.GET("/experiments", experimentHandler.ListExperiments)
+		admin.GET("/experiments/:name/report", experimentHandler.GetReport)
+	}
+
+	if flagService != nil {
+		flagHandler := handlers.NewFeatureFlagHandler(flagService, logger)
+		admin.GET("/feature-flags", flagHandler.ListFlags)
+		admin.PUT("/feature-flags/:name/override", flagHandler.SetOverride)
+		admin.DELETE("/feature-flags/:name/override", flagHandler.ClearOverride)
+	}
+
+	if workspaceService != nil {
+		workspaceHandler := handlers.NewWorkspaceHandler(workspaceService, logger)
+		admin.GET("/workspaces/:chatID/members", workspaceHandler.ListMembers)
+		admin.PUT("/workspaces/:chatID/members/:userID", workspaceHandler.SetRole)
+		admin.DELETE("/workspaces/:chatID/members/:userID", workspaceHandler.RemoveMember)
+		admin.POST("/workspaces/:chatID/invites", workspaceHandler.CreateInvite)
+	}
+
+	if mergeService != nil {
+		accountHandler := handlers.NewAccountHandler(mergeService, logger)
+		admin.POST("/accounts/merges", accountHandler.MergeAccounts)
+		admin.POST("/accounts/merges/:mergeID/undo", accountHandler.UndoMerge)
+		admin.GET("/accounts/:userID/merges", accountHandler.ListMerges)
+	}
+
+	if historyService != nil {
+		historyHandler := handlers.NewTaskHistoryHandler(historyService, logger)
+		admin.GET("/tasks/:taskID/history", historyHandler.GetHistory)
+	}
+
+	if nudgeService != nil {
+		fieldHandler := handlers.NewTaskFieldHandler(nudgeService, logger)
+		admin.GET("/users/:userID/tasks", fieldHandler.ListTasks)
+		admin.PUT("/tasks/:taskID/fields/:key", fieldHandler.SetField)
+		admin.DELETE("/tasks/:taskID/fields/:key", fieldHandler.ClearField)
+	}
+
+	if captureRecorder != nil {
+		captureHandler := handlers.NewDebugCaptureHandler(captureRecorder, logger)
+		admin.GET("/debug/captures", captureHandler.ListCaptures)
+		admin.DELETE("/debug/captures", captureHandler.ClearCaptures)
+	}
+
+	if logLevels != nil {
+		logLevelHandler := handlers.NewLogLevelHandler(logLevels, logger)
+		admin.GET("/log-levels", logLevelHandler.GetLevels)
+		admin.PUT("/log-levels", logLevelHandler.SetLevels)
+	}
+
+	if prompts != nil {
+		promptHandler := handlers.NewPromptHandler(prompts, logger)
+		admin.POST("/prompts/reload", promptHandler.ReloadPrompts)
+	}
+
+	if maintenanceSwitch != nil {
+		maintenanceHandler := handlers.NewMaintenanceHandler(maintenanceSwitch, logger)
+		admin.GET("/maintenance", maintenanceHandler.GetMaintenance)
+		admin.PUT("/maintenance", maintenanceHandler.SetMaintenance)
+	}
+
+	templateHandler := handlers.NewTemplatePreviewHandler(chatbotService, adminIDs, logger)
+	admin.GET("/templates", templateHandler.ListTemplates)
+	admin.POST("/templates/:name/preview", templateHandler.PreviewTemplate)
+}
+
+// SetupQuickAddRoutes registers the endpoint browser extensions and shortcuts
+// use to add tasks, guarded by the user's personal API token
+func SetupQuickAddRoutes(router *gin.Engine, logger *logger.Logger, tokenService nudge.APITokenService, nudgeService nudge.NudgeService, llmService llm.LLMService) {
+	quickAddHandler := handlers.NewQuickAddHandler(nudgeService, llmService, logger)
+
+	router.POST(openapi.BasePath+"/quick-add", middleware.APITokenAuth(tokenService, logger), quickAddHandler.QuickAdd)
+}
+
+// SetupWebAppRoutes registers the Telegram Web App page at /webapp/ and the
+// task endpoints it calls, authenticated with the init data Telegram signs
+// with botToken
+func SetupWebAppRoutes(router *gin.Engine, logger *logger.Logger, botToken string, maxAge time.Duration, identities chatbot.IdentityMap, nudgeService nudge.NudgeService) {
+	pageHandler := handlers.NewWebAppPageHandler(webapp.Assets)
+	webAppHandler := handlers.NewWebAppHandler(nudgeService, logger)
+
+	router.GET("/webapp/*filepath", pageHandler.Serve)
+
+	tasks := router.Group(openapi.BasePath+"/webapp/tasks", middleware.WebAppAuth(botToken, maxAge, identities, logger))
+	{
+		tasks.GET("", webAppHandler.ListTasks)
+		tasks.PATCH("/:taskID", webAppHandler.UpdateTask)
+		tasks.POST("/:taskID/complete", webAppHandler.CompleteTask)
+	}
+}
+
+// SetupLandingRoutes registers the public landing page at the site root, with
+// its stylesheet below /static. Uptime is counted from startedAt.
+func SetupLandingRoutes(router *gin.Engine, branding config.LandingConfig, startedAt time.Time, maintenanceSwitch *maintenance.Switch) {
+	landingHandler := handlers.NewLandingHandler(branding, startedAt, maintenanceSwitch)
+
+	router.SetHTMLTemplate(landing.Template)
+	router.StaticFS("/static", http.FS(landing.Static))
+	router.GET("/", landingHandler.Show)
+}
+
+// SetupMetricsRoutes registers the metrics endpoint
+func SetupMetricsRoutes(router *gin.Engine, logger *logger.Logger, repositoryMetrics *nudge.RepositoryMetrics, reminderScheduler scheduler.Scheduler, jobScheduler scheduler.JobScheduler, loadGovernor governor.Governor, prober probe.Prober, pool *database.Pool, eventBus events.EventBus, llmQueue *llm.FairQueue, httpMetrics *httpclient.Metrics) {
+	metricsHandler := handlers.NewMetricsHandler(repositoryMetrics, reminderScheduler, jobScheduler, loadGovernor, prober, pool, eventBus, llmQueue, httpMetrics, logger)
+
+	router.GET(openapi.BasePath+"/metrics", metricsHandler.GetMetrics)
+}
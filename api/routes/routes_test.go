@@ -1,16 +1,25 @@
 package routes
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"nudgebot-api/api/middleware"
 	"nudgebot-api/internal/chatbot"
 	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/config"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/maintenance"
 	"nudgebot-api/pkg/logger"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
@@ -33,6 +42,24 @@ func (m *mockChatbotService) ProcessCommand(command chatbot.Command, userID comm
 	return nil
 }
 
+func (m *mockChatbotService) Ready() <-chan struct{} {
+	ready := make(chan struct{})
+	close(ready)
+	return ready
+}
+
+func (m *mockChatbotService) Start(ctx context.Context) error {
+	return nil
+}
+
+func (m *mockChatbotService) Stop(ctx context.Context) error {
+	return nil
+}
+
+func (m *mockChatbotService) Health() error {
+	return nil
+}
+
 func createTestRouter() *gin.Engine {
 	gin.SetMode(gin.TestMode)
 
@@ -41,7 +68,7 @@ func createTestRouter() *gin.Engine {
 	logger := logger.New()
 
 	router := gin.New()
-	SetupRoutes(router, mockDB, logger, mockChatbot)
+	SetupRoutes(router, mockDB, logger, mockChatbot, nil, nil)
 	return router
 }
 
@@ -147,7 +174,7 @@ func TestSetupRoutes_DependencyInjection(t *testing.T) {
 	// This should not panic if dependencies are properly injected
 	assert.NotPanics(t, func() {
 		router := gin.New()
-		SetupRoutes(router, mockDB, logger, mockChatbot)
+		SetupRoutes(router, mockDB, logger, mockChatbot, nil, nil)
 		assert.NotNil(t, router)
 	})
 }
@@ -232,3 +259,93 @@ func TestSetupRoutes_AllEndpointsAccessible(t *testing.T) {
 		})
 	}
 }
+
+func TestSetupRoutes_MaintenanceMode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := logger.New()
+	maintenanceSwitch := maintenance.NewSwitch(events.NewMockEventBus(), zap.NewNop(), config.MaintenanceConfig{})
+
+	router := gin.New()
+	SetupRoutes(router, &gorm.DB{}, log, &mockChatbotService{}, nil, maintenanceSwitch)
+	SetupAdminRoutes(router, log, "token", nil, nil, nil, nil, nil, nil, nil, nil, nil, maintenanceSwitch, nil, nil)
+	SetupLandingRoutes(router, config.LandingConfig{BotName: "NudgeBot"}, time.Now(), maintenanceSwitch)
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer token")
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/api/v1/telegram/webhook-info", "").Code)
+
+	w := serve(http.MethodPut, "/api/v1/admin/maintenance", `{"enabled": true, "message": "Back at noon"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"enabled":true`)
+
+	w = serve(http.MethodGet, "/api/v1/telegram/webhook-info", "")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "Back at noon")
+
+	// Telegram updates still reach the bot, which answers with the notice
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/api/v1/telegram/webhook", `{"update_id": 1}`).Code)
+	assert.NotContains(t, serve(http.MethodGet, "/health", "").Body.String(), "Back at noon")
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/api/v1/admin/maintenance", "").Code)
+	// The landing page stays up to announce it
+	w = serve(http.MethodGet, "/", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Back at noon")
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/static/landing.css", "").Code)
+
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPut, "/api/v1/admin/maintenance", `{"message": "no flag"}`).Code)
+	require.Equal(t, http.StatusOK, serve(http.MethodPut, "/api/v1/admin/maintenance", `{"enabled": false}`).Code)
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/api/v1/telegram/webhook-info", "").Code)
+}
+
+func TestSetupRoutes_WebhookAllowlist(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log := logger.New()
+	allowlist, err := middleware.WebhookAllowlist(config.WebhookAllowlistConfig{
+		Enabled:        true,
+		Ranges:         []string{"149.154.160.0/20", "91.108.4.0/22"},
+		TrustedProxies: []string{"10.0.0.1"},
+	}, log)
+	require.NoError(t, err)
+
+	router := gin.New()
+	SetupRoutes(router, &gorm.DB{}, log, &mockChatbotService{}, nil, nil, allowlist)
+	SetupBotRoutes(router, log, nil, map[string]chatbot.ChatbotService{"staging": &mockChatbotService{}}, allowlist)
+
+	serve := func(path, remoteAddr, forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"update_id": 1}`))
+		req.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("/api/v1/telegram/webhook", "149.154.167.220:443", ""))
+	assert.Equal(t, http.StatusOK, serve("/api/v1/telegram/webhook/staging", "91.108.6.1:443", ""))
+	assert.Equal(t, http.StatusForbidden, serve("/api/v1/telegram/webhook", "203.0.113.9:443", ""))
+	assert.Equal(t, http.StatusForbidden, serve("/api/v1/telegram/webhook/staging", "203.0.113.9:443", ""))
+
+	// X-Forwarded-For is believed only from a trusted proxy
+	assert.Equal(t, http.StatusForbidden, serve("/api/v1/telegram/webhook", "203.0.113.9:443", "149.154.167.220"))
+	assert.Equal(t, http.StatusOK, serve("/api/v1/telegram/webhook", "10.0.0.1:443", "149.154.167.220"))
+	assert.Equal(t, http.StatusForbidden, serve("/api/v1/telegram/webhook", "10.0.0.1:443", "149.154.167.220, 203.0.113.9"))
+
+	// Only the endpoints Telegram delivers to are guarded
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/telegram/webhook-info", nil)
+	req.RemoteAddr = "203.0.113.9:443"
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	_, err = middleware.WebhookAllowlist(config.WebhookAllowlistConfig{Enabled: true, Ranges: []string{"telegram"}}, log)
+	assert.Error(t, err)
+}
@@ -0,0 +1,151 @@
+// NudgeBot Web App: lists the user's open tasks and lets them edit and
+// complete them. Requests are authenticated with the init data Telegram
+// opened the page with, which the server checks against the bot token.
+(function () {
+  "use strict";
+
+  const API = "/api/v1/webapp/tasks";
+  const tg = window.Telegram && window.Telegram.WebApp;
+
+  const list = document.getElementById("tasks");
+  const empty = document.getElementById("empty");
+  const status = document.getElementById("status");
+  const editor = document.getElementById("editor");
+  const form = document.getElementById("edit-form");
+  // Read through elements: form.title would be the form's own title attribute
+  const fields = form.elements;
+  const rowTemplate = document.getElementById("task-row");
+
+  let editing = null;
+
+  function setStatus(text) {
+    status.textContent = text || "";
+  }
+
+  async function request(path, options) {
+    const response = await fetch(API + path, Object.assign({}, options, {
+      headers: {
+        "Authorization": "tma " + (tg ? tg.initData : ""),
+        "Content-Type": "application/json",
+      },
+    }));
+    const body = await response.json().catch(() => ({}));
+    if (!response.ok) {
+      throw new Error(body.error || response.statusText);
+    }
+    return body;
+  }
+
+  function formatDue(task) {
+    if (!task.due_date) {
+      return "";
+    }
+    const due = new Date(task.due_date);
+    return "Due " + due.toLocaleString(undefined, { dateStyle: "medium", timeStyle: "short" });
+  }
+
+  function describe(task) {
+    const parts = [];
+    if (task.code) parts.push("T-" + task.code);
+    if (task.priority && task.priority !== "medium") parts.push(task.priority);
+    const due = formatDue(task);
+    if (due) parts.push(due);
+    if (task.status === "snoozed") parts.push("snoozed");
+    return parts.join(" · ");
+  }
+
+  function render(tasks) {
+    list.replaceChildren();
+    empty.hidden = tasks.length > 0;
+    for (const task of tasks) {
+      const row = rowTemplate.content.firstElementChild.cloneNode(true);
+      row.querySelector(".title").textContent = task.title;
+      row.querySelector(".meta").textContent = describe(task);
+      if (task.due_date && new Date(task.due_date) < new Date()) {
+        row.classList.add("overdue");
+      }
+      row.querySelector(".complete").addEventListener("click", () => complete(task, row));
+      row.querySelector(".edit").addEventListener("click", () => openEditor(task));
+      list.appendChild(row);
+    }
+  }
+
+  async function load() {
+    setStatus("Loading…");
+    try {
+      const body = await request("", { method: "GET" });
+      render(body.tasks || []);
+      setStatus("");
+    } catch (err) {
+      setStatus("Could not load your tasks: " + err.message);
+    }
+  }
+
+  async function complete(task, row) {
+    try {
+      await request("/" + task.id + "/complete", { method: "POST" });
+      row.classList.add("done");
+      if (tg && tg.HapticFeedback) tg.HapticFeedback.notificationOccurred("success");
+      setTimeout(load, 600);
+    } catch (err) {
+      setStatus("Could not complete the task: " + err.message);
+    }
+  }
+
+  // toLocalInput formats a date for a datetime-local input in the device's time zone
+  function toLocalInput(value) {
+    if (!value) return "";
+    const date = new Date(value);
+    const offset = date.getTimezoneOffset() * 60000;
+    return new Date(date.getTime() - offset).toISOString().slice(0, 16);
+  }
+
+  function openEditor(task) {
+    editing = task;
+    fields.title.value = task.title;
+    fields.description.value = task.description || "";
+    fields.priority.value = task.priority || "medium";
+    fields.due_date.value = toLocalInput(task.due_date);
+    editor.showModal();
+  }
+
+  form.addEventListener("submit", async (event) => {
+    event.preventDefault();
+    if (!editing) return;
+
+    const update = {
+      title: fields.title.value,
+      description: fields.description.value,
+      priority: fields.priority.value,
+    };
+    if (fields.due_date.value) {
+      update.due_date = new Date(fields.due_date.value).toISOString();
+    } else if (editing.due_date) {
+      update.clear_due_date = true;
+    }
+
+    try {
+      await request("/" + editing.id, { method: "PATCH", body: JSON.stringify(update) });
+      editor.close();
+      editing = null;
+      load();
+    } catch (err) {
+      setStatus("Could not save the task: " + err.message);
+    }
+  });
+
+  document.getElementById("cancel").addEventListener("click", () => {
+    editor.close();
+    editing = null;
+  });
+
+  if (tg) {
+    tg.ready();
+    tg.expand();
+  }
+  if (!tg || !tg.initData) {
+    setStatus("Open this page from the bot in Telegram.");
+    return;
+  }
+  load();
+})();
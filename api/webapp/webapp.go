@@ -0,0 +1,21 @@
+// Package webapp holds the static files of the Telegram Web App, a small page
+// opened from the bot's menu button for listing, editing and completing tasks
+package webapp
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed static
+var static embed.FS
+
+// Assets are the files of the Web App, with index.html at the root
+var Assets fs.FS
+
+func init() {
+	var err error
+	if Assets, err = fs.Sub(static, "static"); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,166 @@
+// Command llm-eval scores the task parser against a labeled dataset, so that
+// prompt and provider changes can be checked before they are deployed.
+//
+//	go run ./cmd/llm-eval -dataset internal/llm/testdata/eval_dataset.jsonl -provider heuristic
+//
+// The gemma and fallback providers read the LLM settings the server uses from
+// configs/config.yaml and the environment.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/config"
+	"nudgebot-api/internal/llm"
+
+	"go.uber.org/zap"
+)
+
+// defaultNow is the time the sample dataset is labeled against
+const defaultNow = "2024-01-10T10:00:00Z"
+
+func main() {
+	dataset := flag.String("dataset", "internal/llm/testdata/eval_dataset.jsonl", "labeled dataset, one JSON case per line")
+	providerName := flag.String("provider", "heuristic", "parser to evaluate: heuristic, gemma, or fallback (gemma falling back to heuristic, as served)")
+	promptDir := flag.String("prompt-dir", "", "prompt template directory, overriding llm.prompt_dir")
+	nowFlag := flag.String("now", defaultNow, "reference time relative dates are labeled against (RFC 3339)")
+	timeout := flag.Duration("timeout", 30*time.Second, "timeout for each parse")
+	format := flag.String("format", "text", "report format: text or json")
+	minAccuracy := flag.Float64("min-accuracy", 0, "exit with status 1 when overall accuracy is below this (0-1)")
+	flag.Parse()
+
+	now, err := time.Parse(time.RFC3339, *nowFlag)
+	if err != nil {
+		log.Fatalf("Invalid -now: %v", err)
+	}
+
+	file, err := os.Open(*dataset)
+	if err != nil {
+		log.Fatalf("Failed to open dataset: %v", err)
+	}
+	cases, err := llm.LoadEvalCases(file)
+	file.Close()
+	if err != nil {
+		log.Fatalf("Invalid dataset %s: %v", *dataset, err)
+	}
+
+	provider, err := newProvider(*providerName, *promptDir, now)
+	if err != nil {
+		log.Fatalf("Failed to create provider: %v", err)
+	}
+
+	report := llm.Evaluate(context.Background(), provider, cases, *timeout)
+	if report.Provider != *providerName {
+		report.Provider = *providerName + " (" + report.Provider + ")"
+	}
+
+	switch *format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			log.Fatalf("Failed to write report: %v", err)
+		}
+	case "text":
+		writeTextReport(os.Stdout, report)
+	default:
+		log.Fatalf("Unknown -format %q", *format)
+	}
+
+	if report.Accuracy < *minAccuracy {
+		fmt.Fprintf(os.Stderr, "accuracy %.1f%% is below the minimum of %.1f%%\n", report.Accuracy*100, *minAccuracy*100)
+		os.Exit(1)
+	}
+}
+
+// newProvider creates the parser to evaluate. The heuristic parser resolves
+// relative dates against now; the model is only as aware of the date as its
+// prompt makes it.
+func newProvider(name, promptDir string, now time.Time) (llm.LLMProvider, error) {
+	heuristic := llm.NewHeuristicProvider(common.NewMockClock(now))
+	if name == "heuristic" {
+		return heuristic, nil
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	if promptDir != "" {
+		cfg.LLM.PromptDir = promptDir
+	}
+	prompts, err := llm.NewPromptStore(cfg.LLM.PromptDir, zap.NewNop())
+	if err != nil {
+		return nil, err
+	}
+	gemma := llm.NewGemmaProviderWithPrompts(cfg.LLM, prompts, zap.NewNop())
+
+	switch name {
+	case "gemma":
+		return gemma, nil
+	case "fallback":
+		return llm.NewFallbackProvider(gemma, heuristic, nil), nil
+	default:
+		return nil, fmt.Errorf("unknown provider %q", name)
+	}
+}
+
+// writeTextReport prints the scores followed by each case that was missed
+func writeTextReport(w io.Writer, report *llm.EvalReport) {
+	fmt.Fprintf(w, "Provider:  %s\n", report.Provider)
+	fmt.Fprintf(w, "Cases:     %d (%d failed to parse)\n", report.Cases, report.Failures)
+	fmt.Fprintf(w, "Title:     %5.1f%%\n", report.TitleAccuracy*100)
+	fmt.Fprintf(w, "Due date:  %5.1f%%\n", report.DueAccuracy*100)
+	fmt.Fprintf(w, "Priority:  %5.1f%%\n", report.PriorityAccuracy*100)
+	fmt.Fprintf(w, "Overall:   %5.1f%%\n", report.Accuracy*100)
+	fmt.Fprintf(w, "Latency:   %s mean\n", report.MeanLatency.Round(time.Microsecond))
+
+	misses := report.Misses()
+	if len(misses) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "\nMisses:\n")
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "ID\tFIELD\tEXPECTED\tACTUAL")
+	for _, miss := range misses {
+		if miss.Error != "" {
+			fmt.Fprintf(table, "%s\terror\t-\t%s\n", miss.Case.ID, miss.Error)
+			continue
+		}
+		actual := miss.Actual
+		if !miss.TitleMatch {
+			fmt.Fprintf(table, "%s\ttitle\t%s\t%s\n", miss.Case.ID, miss.Case.Expected.Title, actual.Title)
+		}
+		if !miss.DueMatch {
+			fmt.Fprintf(table, "%s\tdue\t%s\t%s\n", miss.Case.ID, orNone(miss.Case.Expected.Due), formatDue(actual.DueDate))
+		}
+		if !miss.PriorityMatch {
+			fmt.Fprintf(table, "%s\tpriority\t%s\t%s\n", miss.Case.ID, miss.Case.Expected.Priority, actual.Priority)
+		}
+	}
+	table.Flush()
+}
+
+func formatDue(due *time.Time) string {
+	if due == nil {
+		return "none"
+	}
+	return due.Format(time.RFC3339)
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
@@ -0,0 +1,142 @@
+// Command nudgectl runs maintenance tasks against a deployment's database.
+//
+//	go run ./cmd/nudgectl backup -o nudgebot-backup.json
+//	go run ./cmd/nudgectl restore -i nudgebot-backup.json
+//
+// Backups are JSON snapshots of users, tasks, reminders and nudge settings,
+// tagged with the schema version of the build that took them. Restoring runs
+// the migrations first, so a backup from an older build loads into a newer
+// one. The database settings are read like the server's, from
+// configs/config.yaml and the environment.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+
+	"nudgebot-api/internal/backup"
+	"nudgebot-api/internal/config"
+	"nudgebot-api/internal/database"
+	"nudgebot-api/internal/nudge"
+
+	"gorm.io/gorm"
+)
+
+const usage = `Usage: nudgectl <command> [flags]
+
+Commands:
+  backup   write a snapshot of users, tasks, reminders and settings
+  restore  load a snapshot, migrating the database first`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "backup":
+		runBackup(os.Args[2:])
+	case "restore":
+		runRestore(os.Args[2:])
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+// runBackup writes a snapshot to a file, or to stdout
+func runBackup(args []string) {
+	flags := flag.NewFlagSet("backup", flag.ExitOnError)
+	output := flags.String("o", "-", "file to write the backup to, - for stdout")
+	flags.Parse(args)
+
+	db := connect()
+	snapshot, err := backup.Dump(context.Background(), db)
+	if err != nil {
+		log.Fatalf("Backup failed: %v", err)
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "-" {
+		file, err := os.Create(*output)
+		if err != nil {
+			log.Fatalf("Failed to create %s: %v", *output, err)
+		}
+		defer file.Close()
+		w = file
+	}
+	if err := backup.Write(w, snapshot); err != nil {
+		log.Fatalf("Failed to write backup: %v", err)
+	}
+
+	printCounts("Backed up", snapshot)
+}
+
+// runRestore loads a snapshot from a file, or from stdin
+func runRestore(args []string) {
+	flags := flag.NewFlagSet("restore", flag.ExitOnError)
+	input := flags.String("i", "-", "file to read the backup from, - for stdin")
+	flags.Parse(args)
+
+	var r io.Reader = os.Stdin
+	if *input != "-" {
+		file, err := os.Open(*input)
+		if err != nil {
+			log.Fatalf("Failed to open %s: %v", *input, err)
+		}
+		defer file.Close()
+		r = file
+	}
+	snapshot, err := backup.Read(r)
+	if err != nil {
+		log.Fatalf("Invalid backup: %v", err)
+	}
+	if err := snapshot.CheckVersion(nudge.SchemaVersion); err != nil {
+		log.Fatalf("Cannot restore: %v", err)
+	}
+	if snapshot.SchemaVersion < nudge.SchemaVersion {
+		fmt.Fprintf(os.Stderr, "Migrating backup from schema version %d to %d\n", snapshot.SchemaVersion, nudge.SchemaVersion)
+	}
+
+	db := connect()
+	if err := backup.Restore(context.Background(), db, snapshot, nudge.RunMigrations); err != nil {
+		log.Fatalf("Restore failed: %v", err)
+	}
+
+	printCounts("Restored", snapshot)
+}
+
+// connect opens the database configured for the server
+func connect() *gorm.DB {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	db, err := database.NewPostgresConnection(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to the database: %v", err)
+	}
+	return db
+}
+
+// printCounts reports the rows of each table to stderr, keeping stdout for
+// the backup itself
+func printCounts(verb string, snapshot *backup.Snapshot) {
+	counts := snapshot.Counts()
+	tables := make([]string, 0, len(counts))
+	for table := range counts {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	fmt.Fprintf(os.Stderr, "%s schema version %d:\n", verb, snapshot.SchemaVersion)
+	for _, table := range tables {
+		fmt.Fprintf(os.Stderr, "  %-18s %d\n", table, counts[table])
+	}
+}
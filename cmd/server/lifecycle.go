@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/pkg/logger"
+)
+
+// managedService is a registered service and the services it depends on
+type managedService struct {
+	name      string
+	service   common.Service
+	dependsOn []string
+}
+
+// lifecycleManager starts services after their dependencies and stops them in
+// the reverse order
+type lifecycleManager struct {
+	logger   *logger.Logger
+	services []*managedService
+	started  []*managedService
+}
+
+func newLifecycleManager(logger *logger.Logger) *lifecycleManager {
+	return &lifecycleManager{logger: logger}
+}
+
+// Register adds a service that starts after every service named in dependsOn
+func (m *lifecycleManager) Register(name string, service common.Service, dependsOn ...string) {
+	m.services = append(m.services, &managedService{
+		name:      name,
+		service:   service,
+		dependsOn: dependsOn,
+	})
+}
+
+// StartAll starts every service in dependency order. When a service fails to
+// start, the services already started are stopped again.
+func (m *lifecycleManager) StartAll(ctx context.Context) error {
+	ordered, err := m.order()
+	if err != nil {
+		return err
+	}
+
+	for _, s := range ordered {
+		if err := s.service.Start(ctx); err != nil {
+			startErr := fmt.Errorf("failed to start %s: %w", s.name, err)
+			if stopErr := m.StopAll(ctx); stopErr != nil {
+				return errors.Join(startErr, stopErr)
+			}
+			return startErr
+		}
+		m.started = append(m.started, s)
+		m.logger.Info("Service started", "service", s.name)
+	}
+	return nil
+}
+
+// StopAll stops the started services in reverse dependency order. Every
+// service is asked to stop even when an earlier one fails.
+func (m *lifecycleManager) StopAll(ctx context.Context) error {
+	var errs []error
+	for i := len(m.started) - 1; i >= 0; i-- {
+		s := m.started[i]
+		if err := s.service.Stop(ctx); err != nil {
+			m.logger.Error("Failed to stop service", "service", s.name, "error", err)
+			errs = append(errs, fmt.Errorf("failed to stop %s: %w", s.name, err))
+			continue
+		}
+		m.logger.Info("Service stopped", "service", s.name)
+	}
+	m.started = nil
+	return errors.Join(errs...)
+}
+
+// NotifyServing tells the started services that implement common.ServingHook
+// that the server is serving every route
+func (m *lifecycleManager) NotifyServing(ctx context.Context) {
+	for _, s := range m.started {
+		if hook, ok := s.service.(common.ServingHook); ok {
+			hook.Serving(ctx)
+			m.logger.Debug("Service notified that the server is serving", "service", s.name)
+		}
+	}
+}
+
+// Health returns the health of every registered service keyed by name
+func (m *lifecycleManager) Health() map[string]error {
+	health := make(map[string]error, len(m.services))
+	for _, s := range m.services {
+		health[s.name] = s.service.Health()
+	}
+	return health
+}
+
+// order sorts the services so each comes after its dependencies, keeping
+// registration order otherwise
+func (m *lifecycleManager) order() ([]*managedService, error) {
+	byName := make(map[string]*managedService, len(m.services))
+	for _, s := range m.services {
+		if _, exists := byName[s.name]; exists {
+			return nil, fmt.Errorf("service %s registered twice", s.name)
+		}
+		byName[s.name] = s
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int, len(m.services))
+	ordered := make([]*managedService, 0, len(m.services))
+
+	var visit func(s *managedService) error
+	visit = func(s *managedService) error {
+		switch state[s.name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle at service %s", s.name)
+		}
+
+		state[s.name] = visiting
+		for _, dep := range s.dependsOn {
+			next, ok := byName[dep]
+			if !ok {
+				return fmt.Errorf("service %s depends on unknown service %s", s.name, dep)
+			}
+			if err := visit(next); err != nil {
+				return err
+			}
+		}
+		state[s.name] = visited
+		ordered = append(ordered, s)
+		return nil
+	}
+
+	for _, s := range m.services {
+		if err := visit(s); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
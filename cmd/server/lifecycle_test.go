@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"nudgebot-api/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingService appends its lifecycle calls to a shared log
+type recordingService struct {
+	name     string
+	log      *[]string
+	startErr error
+	stopErr  error
+}
+
+func (s *recordingService) Start(ctx context.Context) error {
+	*s.log = append(*s.log, "start "+s.name)
+	return s.startErr
+}
+
+func (s *recordingService) Stop(ctx context.Context) error {
+	*s.log = append(*s.log, "stop "+s.name)
+	return s.stopErr
+}
+
+func (s *recordingService) Health() error {
+	return nil
+}
+
+func TestLifecycleManager_DependencyOrder(t *testing.T) {
+	var calls []string
+	manager := newLifecycleManager(logger.New())
+	manager.Register("scheduler", &recordingService{name: "scheduler", log: &calls}, "chatbot", "nudge")
+	manager.Register("chatbot", &recordingService{name: "chatbot", log: &calls}, "llm")
+	manager.Register("llm", &recordingService{name: "llm", log: &calls}, "nudge")
+	manager.Register("nudge", &recordingService{name: "nudge", log: &calls})
+
+	require.NoError(t, manager.StartAll(context.Background()))
+	require.NoError(t, manager.StopAll(context.Background()))
+
+	assert.Equal(t, []string{
+		"start nudge", "start llm", "start chatbot", "start scheduler",
+		"stop scheduler", "stop chatbot", "stop llm", "stop nudge",
+	}, calls)
+}
+
+func TestLifecycleManager_StartFailureStopsStarted(t *testing.T) {
+	var calls []string
+	manager := newLifecycleManager(logger.New())
+	manager.Register("nudge", &recordingService{name: "nudge", log: &calls})
+	manager.Register("scheduler", &recordingService{name: "scheduler", log: &calls, startErr: errors.New("boom")}, "nudge")
+
+	err := manager.StartAll(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "scheduler")
+	assert.Equal(t, []string{"start nudge", "start scheduler", "stop nudge"}, calls)
+}
+
+func TestLifecycleManager_StopContinuesAfterError(t *testing.T) {
+	var calls []string
+	manager := newLifecycleManager(logger.New())
+	manager.Register("nudge", &recordingService{name: "nudge", log: &calls})
+	manager.Register("chatbot", &recordingService{name: "chatbot", log: &calls, stopErr: errors.New("boom")}, "nudge")
+
+	require.NoError(t, manager.StartAll(context.Background()))
+	assert.Error(t, manager.StopAll(context.Background()))
+	assert.Equal(t, []string{"start nudge", "start chatbot", "stop chatbot", "stop nudge"}, calls)
+}
+
+func TestLifecycleManager_InvalidDependencies(t *testing.T) {
+	var calls []string
+
+	unknown := newLifecycleManager(logger.New())
+	unknown.Register("chatbot", &recordingService{name: "chatbot", log: &calls}, "llm")
+	assert.Error(t, unknown.StartAll(context.Background()))
+
+	cycle := newLifecycleManager(logger.New())
+	cycle.Register("chatbot", &recordingService{name: "chatbot", log: &calls}, "llm")
+	cycle.Register("llm", &recordingService{name: "llm", log: &calls}, "chatbot")
+	assert.Error(t, cycle.StartAll(context.Background()))
+
+	assert.Empty(t, calls)
+}
+
+// servingService records when it is told the server is serving
+type servingService struct {
+	recordingService
+}
+
+func (s *servingService) Serving(ctx context.Context) {
+	*s.log = append(*s.log, "serving "+s.name)
+}
+
+func TestLifecycleManager_NotifyServing(t *testing.T) {
+	var calls []string
+	manager := newLifecycleManager(logger.New())
+	manager.Register("nudge", &recordingService{name: "nudge", log: &calls})
+	manager.Register("chatbot", &servingService{recordingService{name: "chatbot", log: &calls}}, "nudge")
+
+	manager.NotifyServing(context.Background())
+	assert.Empty(t, calls, "services that have not started are not notified")
+
+	require.NoError(t, manager.StartAll(context.Background()))
+	manager.NotifyServing(context.Background())
+	assert.Equal(t, []string{"start nudge", "start chatbot", "serving chatbot"}, calls)
+}
@@ -4,34 +4,52 @@ import (
 	_ "github.com/joho/godotenv/autoload" // Load .env file automatically
 
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
-	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"nudgebot-api/api/middleware"
 	"nudgebot-api/api/routes"
+	"nudgebot-api/internal/account"
+	"nudgebot-api/internal/chaos"
 	"nudgebot-api/internal/chatbot"
+	"nudgebot-api/internal/common"
 	"nudgebot-api/internal/config"
 	"nudgebot-api/internal/database"
+	"nudgebot-api/internal/debugcapture"
 	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/experiment"
+	"nudgebot-api/internal/featureflags"
+	"nudgebot-api/internal/geocode"
+	"nudgebot-api/internal/governor"
+	"nudgebot-api/internal/holiday"
+	"nudgebot-api/internal/httpclient"
 	"nudgebot-api/internal/llm"
+	"nudgebot-api/internal/maintenance"
+	"nudgebot-api/internal/moderation"
+	"nudgebot-api/internal/notify"
 	"nudgebot-api/internal/nudge"
+	"nudgebot-api/internal/probe"
 	"nudgebot-api/internal/scheduler"
+	"nudgebot-api/internal/startup"
+	"nudgebot-api/internal/tenant"
+	"nudgebot-api/internal/user"
 	"nudgebot-api/pkg/logger"
 
+	"github.com/cenkalti/backoff/v4"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
-func main() {
-	// Initialize logger
-	logger := logger.New()
-	defer logger.Sync()
+// telegramAPIRoot is requested to confirm Telegram is reachable through a proxy
+const telegramAPIRoot = "https://api.telegram.org"
 
-	// Get the underlying zap logger for services
-	zapLogger := logger.SugaredLogger.Desugar()
+func main() {
+	startedAt := time.Now()
 
 	// Load configuration
 	cfg, err := config.Load()
@@ -39,62 +57,541 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	// Initialize database
-	db, err := database.NewPostgresConnection(cfg.Database)
+	// Initialize logger with the configured sinks and levels
+	logger, err := logger.NewFromConfig(cfg.Logging)
+	if err != nil {
+		log.Fatalf("Failed to create logger: %v", err)
+	}
+	defer logger.Sync()
+
+	// Get the underlying zap logger for services
+	zapLogger := logger.SugaredLogger.Desugar()
+
+	if cfg.Profile != "" {
+		logger.Info("Configuration profile applied", "profile", cfg.Profile)
+	}
+
+	// Fault injection is only ever enabled for resilience testing
+	chaosInjector, err := chaos.NewInjectorFromConfig(cfg.Chaos, cfg.Server.Environment)
 	if err != nil {
-		logger.Fatal("Failed to connect to database", "error", err)
+		logger.Fatal("Invalid chaos configuration", "error", err)
+	}
+	webhookAllowlist, err := middleware.WebhookAllowlist(cfg.Chatbot.WebhookAllowlist, logger)
+	if err != nil {
+		logger.Fatal("Invalid webhook allowlist", "error", err)
+	}
+
+	if chaosInjector != nil {
+		logger.Warn("Chaos mode enabled, failures will be injected",
+			"telegram_rate_limit_rate", cfg.Chaos.TelegramRateLimitRate,
+			"telegram_server_error_rate", cfg.Chaos.TelegramServerErrorRate,
+			"database_timeout_rate", cfg.Chaos.DatabaseTimeoutRate,
+			"llm_error_rate", cfg.Chaos.LLMErrorRate)
+	}
+
+	// Debug capture keeps a redacted sample of Telegram traffic in memory
+	captureRecorder := debugcapture.NewRecorderFromConfig(cfg.DebugCapture)
+	if captureRecorder != nil {
+		logger.Warn("Debug capture enabled, sampled Telegram traffic is kept in memory",
+			"sample_rate", cfg.DebugCapture.SampleRate,
+			"chats", len(cfg.DebugCapture.Chats),
+			"redact_text", cfg.DebugCapture.RedactText)
+	}
+
+	if cfg.Server.Environment == "production" {
+		gin.SetMode(gin.ReleaseMode)
 	}
 
-	// Run nudge module migrations
-	if err := nudge.RunMigrations(db); err != nil {
-		logger.Fatal("Failed to run nudge migrations", "error", err)
+	// Shutdown signals also abort startup retries
+	shutdownCtx, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
+
+	// Serve health checks while dependencies start so the service reports
+	// "starting" instead of refusing connections
+	orchestrator := startup.NewOrchestrator(cfg.Startup, zapLogger)
+	startupRouter := gin.New()
+	routes.SetupStartupRoutes(startupRouter, logger, orchestrator)
+	handler := startup.NewSwappableHandler(startupRouter)
+
+	// Create HTTP server
+	srv := &http.Server{
+		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
+		Handler:      handler,
+		ReadTimeout:  time.Duration(cfg.Server.ReadTimeout) * time.Second,
+		WriteTimeout: time.Duration(cfg.Server.WriteTimeout) * time.Second,
 	}
 
+	// Start server in goroutine
+	go func() {
+		logger.Info("Starting server", "port", cfg.Server.Port)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Fatal("Failed to start server", "error", err)
+		}
+	}()
+
 	// Initialize event bus
 	eventBus := events.NewEventBus(zapLogger)
 
+	// External dependencies start in order and are retried with backoff
+	var db *gorm.DB
+	orchestrator.Add("postgres", func(ctx context.Context) error {
+		var err error
+		db, err = database.NewPostgresConnection(cfg.Database)
+		if err != nil {
+			return err
+		}
+		// Rows are tagged and queried by tenant when the context carries one
+		return db.Use(tenant.NewPlugin())
+	})
+	// Modules other than nudge migrate first; nudge migrates and validates the
+	// schema last, running the validators registered here
+	migrateModules := func(db *gorm.DB) error {
+		if err := experiment.RunMigrations(db); err != nil {
+			return err
+		}
+		if err := chatbot.RunMigrations(db); err != nil {
+			return err
+		}
+		if err := account.RunMigrations(db); err != nil {
+			return err
+		}
+		if err := llm.RunMigrations(db); err != nil {
+			return err
+		}
+		if err := notify.RunMigrations(db); err != nil {
+			return err
+		}
+		return featureflags.RunMigrations(db)
+	}
+	migrate := func(db *gorm.DB) error {
+		if err := migrateModules(db); err != nil {
+			return err
+		}
+		return nudge.RunMigrations(db)
+	}
+	migrationValidators := []nudge.MigrationValidator{
+		database.RequireTables(&experiment.Exposure{}, &chatbot.BotUser{}, &chatbot.TelegramIdentity{}, &account.AccountMerge{},
+			&llm.ParseAudit{}, &notify.DigestEntry{}, &featureflags.Override{}),
+	}
+	orchestrator.Add("migrations", func(ctx context.Context) error {
+		// Production runs blue/green, so the previous release keeps using the
+		// schema while this one starts; drops and type changes need opting in
+		if cfg.Server.Environment == "production" {
+			changes, err := database.CheckMigrations(db, migrate, cfg.Database.AllowDestructiveMigrations)
+			for _, change := range changes {
+				logger.Warn("Destructive migration", "reason", change.Reason, "statement", change.Statement)
+			}
+			if errors.Is(err, database.ErrDestructiveMigration) {
+				return backoff.Permanent(err)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if err := migrateModules(db); err != nil {
+			return err
+		}
+		return nudge.MigrateWithValidation(db, migrationValidators...)
+	})
+
+	// Feature flags gate the bots, the LLM and the digest, so they load first
+	var flagService featureflags.FlagService
+	orchestrator.Add("feature-flags", func(ctx context.Context) error {
+		if flagService == nil {
+			flagService = featureflags.NewFlagService(zapLogger, featureflags.NewGormOverrideRepository(db, zapLogger), cfg.FeatureFlags, cfg.Server.Environment)
+			logger.Info("Feature flags loaded",
+				"environment", cfg.Server.Environment,
+				"count", len(cfg.FeatureFlags.Flags))
+		}
+		return nil
+	})
+
+	// Additional bots share the nudge core; the directory keeps each user's
+	// events on the bot they talk to
+	tenantBots, err := tenant.BotConfigs(cfg.Tenants)
+	if err != nil {
+		logger.Fatal("Invalid tenant configuration", "error", err)
+	}
+	cfg.Chatbot.Bots = append(cfg.Chatbot.Bots, tenantBots...)
+	botConfigs, err := chatbot.BotConfigs(cfg.Chatbot)
+	if err != nil {
+		logger.Fatal("Invalid bot configuration", "error", err)
+	}
+	// Outbound calls to Telegram and the LLM share one retry policy. Long polls
+	// must finish within the Telegram timeout.
+	httpMetrics := httpclient.NewMetrics()
+	telegramTimeout := time.Duration(max(cfg.Chatbot.Timeout, cfg.Chatbot.PollTimeout+5)) * time.Second
+	telegramHTTPOptions := httpclient.OptionsFromConfig(cfg.HTTPClient, telegramTimeout)
+	if telegramHTTPOptions.Proxy, err = httpclient.ParseProxy(cfg.Chatbot.Proxy); err != nil {
+		logger.Fatal("Invalid Telegram proxy", "error", err)
+	}
+	telegramHTTP := httpclient.New("telegram", telegramHTTPOptions, httpMetrics, zapLogger)
+	llmHTTPOptions := httpclient.OptionsFromConfig(cfg.HTTPClient, time.Duration(cfg.LLM.Timeout)*time.Second)
+	llmHTTPOptions.MaxRetries = cfg.LLM.MaxRetries
+	if llmHTTPOptions.Proxy, err = httpclient.ParseProxy(cfg.LLM.Proxy); err != nil {
+		logger.Fatal("Invalid LLM proxy", "error", err)
+	}
+	llmHTTP := httpclient.New("llm", llmHTTPOptions, httpMetrics, zapLogger)
+
+	// Public holidays of the users' countries, for "next business day" and
+	// moving digest tasks off holidays
+	holidayHTTP := httpclient.New("holidays", httpclient.OptionsFromConfig(cfg.HTTPClient, time.Duration(cfg.Holidays.Timeout)*time.Second), httpMetrics, zapLogger)
+	holidayCalendar, err := holiday.NewCalendarFromSource(cfg.Holidays.Source, cfg.Holidays.URL, holidayHTTP, zapLogger)
+	if err != nil {
+		logger.Fatal("Invalid holiday calendar", "error", err)
+	}
+
+	// Proxies are checked before the services relying on them start. Telegram
+	// is unusable without its proxy; parsing falls back to the heuristic parser
+	// without the LLM's.
+	if proxy := telegramHTTP.Proxy(); proxy != "" {
+		orchestrator.Add("telegram-proxy", func(ctx context.Context) error {
+			if err := telegramHTTP.Check(ctx, telegramAPIRoot); err != nil {
+				return err
+			}
+			logger.Info("Reaching Telegram through proxy", "proxy", proxy)
+			return nil
+		})
+	}
+	if proxy := llmHTTP.Proxy(); proxy != "" {
+		orchestrator.Add("llm-proxy", func(ctx context.Context) error {
+			if err := llmHTTP.Check(ctx, cfg.LLM.APIEndpoint); err != nil {
+				logger.Warn("LLM unreachable through proxy, parsing may fall back to heuristics", "error", err)
+				return nil
+			}
+			logger.Info("Reaching the LLM through proxy", "proxy", proxy)
+			return nil
+		})
+	}
+
+	var chatbotService chatbot.ChatbotService
+	botServices := make(map[string]chatbot.ChatbotService, len(botConfigs))
+	var directory chatbot.BotDirectory
+	var userProvisioner user.Provisioner
+	var identities chatbot.IdentityMap
+	var datePrefs chatbot.PrefsResolver
+	var taskCodes chatbot.TaskCodeResolver
+	orchestrator.Add("telegram", func(ctx context.Context) error {
+		if directory == nil && len(botConfigs) > 0 {
+			directory = chatbot.NewGormBotDirectory(db, zapLogger)
+		}
+		// Telegram IDs are mapped to internal IDs on ingestion and back when sending
+		if identities == nil {
+			identities = chatbot.NewGormIdentityMap(db, zapLogger)
+		}
+		// Senders of incoming updates are registered as users on first contact
+		if userProvisioner == nil {
+			userProvisioner = user.NewProvisionerWithTenants(eventBus, zapLogger, user.NewGormRepository(db, zapLogger), tenant.IDs(cfg.Tenants))
+		}
+
+		// Dates are shown in the time zone and language users chose in /settings.
+		// Settings are read by user ID, which is unique across tenants.
+		if datePrefs == nil {
+			settings := nudge.NewGormNudgeRepository(db, zapLogger)
+			datePrefs = chatbot.PrefsResolverFunc(func(userID common.UserID) (chatbot.UserPrefs, error) {
+				userSettings, err := settings.GetNudgeSettingsByUserID(userID)
+				if err != nil {
+					return chatbot.UserPrefs{}, err
+				}
+				return chatbot.UserPrefs{Timezone: userSettings.Timezone, Language: userSettings.Language}, nil
+			})
+		}
+		// Task codes are per user, so they are looked up by user ID the same way
+		if taskCodes == nil {
+			tasks := nudge.NewGormNudgeRepository(db, zapLogger)
+			taskCodes = chatbot.TaskCodeResolverFunc(func(userID common.UserID, code int) (string, error) {
+				found, err := tasks.GetTasksByUserID(userID, nudge.TaskFilter{Code: code, Limit: 1})
+				if err != nil || len(found) == 0 {
+					return "", err
+				}
+				return string(found[0].ID), nil
+			})
+		}
+
+		// Bots created by an earlier attempt are kept; they already subscribed
+		if chatbotService == nil {
+			var err error
+			chatbotService, err = chatbot.NewChatbotServiceWithTaskCodes(eventBus, zapLogger, cfg.Chatbot, chaosInjector, directory, userProvisioner, identities, captureRecorder, telegramHTTP, datePrefs, flagService, taskCodes)
+			if err != nil {
+				return err
+			}
+		}
+		for _, botConfig := range botConfigs {
+			if _, ok := botServices[botConfig.Name]; ok {
+				continue
+			}
+			botService, err := chatbot.NewChatbotServiceWithTaskCodes(eventBus, zapLogger, botConfig, chaosInjector, directory, userProvisioner, identities, captureRecorder, telegramHTTP, datePrefs, flagService, taskCodes)
+			if err != nil {
+				return fmt.Errorf("bot %s: %w", botConfig.Name, err)
+			}
+			botServices[botConfig.Name] = botService
+		}
+		return nil
+	})
+
+	if err := orchestrator.Run(shutdownCtx); err != nil {
+		shutdownServer(srv, logger)
+		if shutdownCtx.Err() != nil {
+			logger.Info("Shutdown requested during startup", "error", err)
+			return
+		}
+		logger.Fatal("Startup failed", "error", err)
+	}
+
+	// Tenants are the users of each tenant's bot; their rows and LLM calls are kept apart
+	var tenantResolver tenant.Resolver
+	storage := nudge.NewGormNudgeRepository(db, zapLogger)
+	if len(cfg.Tenants) > 0 {
+		tenantResolver = tenant.NewBotResolver(directory.BotFor, cfg.Tenants)
+		storage = nudge.NewTenantNudgeRepository(db, zapLogger, tenantResolver, tenant.IDs(cfg.Tenants))
+		// Rows written without a tenant go to the tenant of the user they belong to
+		if err := db.Use(tenant.NewOwnerPlugin(tenantResolver)); err != nil {
+			logger.Fatal("Failed to install tenant owner plugin", "error", err)
+		}
+		logger.Info("Tenant isolation enabled", "tenants", len(cfg.Tenants))
+	}
+
 	// Initialize services
-	chatbotService, err := chatbot.NewChatbotService(eventBus, zapLogger, cfg.Chatbot)
+	repositoryMetrics := nudge.NewRepositoryMetrics()
+	// Calls that lost their connection, as PgBouncer closes idle server connections, are retried
+	nudgeRepository := nudge.NewInstrumentedNudgeRepository(
+		nudge.NewRetryingNudgeRepository(
+			nudge.NewChaosNudgeRepository(storage, chaosInjector),
+			cfg.Database.RetryAttempts,
+			time.Duration(cfg.Database.RetryBackoffMs)*time.Millisecond,
+			repositoryMetrics,
+			zapLogger,
+		),
+		repositoryMetrics,
+		time.Duration(cfg.Database.SlowQueryMs)*time.Millisecond,
+		zapLogger,
+	)
+
+	// Users who set their own confidence thresholds confirm more or fewer parses
+	if _, err := llm.NewConfidenceThresholds(cfg.LLM); err != nil {
+		logger.Fatal("Invalid confidence thresholds", "error", err)
+	}
+	thresholdOverrides := llm.ThresholdResolverFunc(func(userID common.UserID) (llm.ThresholdOverride, error) {
+		settings, err := nudgeRepository.GetNudgeSettingsByUserID(userID)
+		if err != nil {
+			return llm.ThresholdOverride{}, err
+		}
+		return llm.ThresholdOverride{
+			High:   settings.ConfidenceHigh,
+			Medium: settings.ConfidenceMedium,
+			Low:    settings.ConfidenceLow,
+		}, nil
+	})
+	// Parse audits keep prompts and raw responses for diagnosing bad parses
+	var parseAudits llm.AuditRepository
+	if cfg.LLM.Audit.Enabled {
+		parseAudits = llm.NewGormAuditRepository(db, zapLogger)
+		logger.Info("LLM parse audit enabled", "retention_days", cfg.LLM.Audit.RetentionDays)
+	}
+
+	// Prompts are rendered from versioned templates that admins can reload
+	promptStore, err := llm.NewPromptStore(cfg.LLM.PromptDir, zapLogger)
 	if err != nil {
-		logger.Fatal("Failed to initialize chatbot service", "error", err)
+		logger.Fatal("Invalid prompt templates", "error", err)
+	}
+	// Provider calls share a capped number of slots fairly between users
+	llmQueue := llm.NewFairQueue(cfg.LLM.MaxConcurrent, time.Duration(cfg.LLM.QueueAging)*time.Second)
+	// Users who chose a language in /settings are parsed in it
+	userPrefs := llm.PrefsResolverFunc(func(userID common.UserID) (llm.UserPrefs, error) {
+		settings, err := nudgeRepository.GetNudgeSettingsByUserID(userID)
+		if err != nil {
+			return llm.UserPrefs{}, err
+		}
+		return llm.UserPrefs{TimeZone: settings.Timezone, Language: settings.Language, WeekStart: settings.WeekStart, WorkingDays: settings.WorkingDays, Country: settings.Country}, nil
+	})
+	llmService := llm.NewLLMServiceWithFlags(eventBus, zapLogger, cfg.LLM, chaosInjector, cfg.Tenants, tenantResolver, thresholdOverrides, parseAudits, promptStore, llmQueue, userPrefs, llmHTTP, holidayCalendar, flagService)
+
+	// The health governor switches the service to degraded mode under overload
+	loadGovernor := governor.NewGovernor(eventBus, zapLogger, cfg.LoadShedding, repositoryMetrics)
+
+	// Maintenance mode answers users with a notice and pauses the scheduler
+	maintenanceSwitch := maintenance.NewSwitch(eventBus, zapLogger, cfg.Maintenance)
+
+	// Workspace roles decide who may act on tasks shared in a chat
+	workspaceService := nudge.NewWorkspaceService(eventBus, zapLogger, nudge.NewGormWorkspaceRepository(db, zapLogger), time.Duration(cfg.Nudge.InviteTTL)*time.Hour)
+
+	// Shared lists let family members or teams see and complete each other's tasks
+	listService := nudge.NewSharedListService(eventBus, zapLogger, nudge.NewGormSharedListRepository(db, zapLogger))
+
+	// "Send me X at 5pm" stores X to be delivered by the scheduled message job
+	scheduledMessages := nudge.NewGormScheduledMessageRepository(db, zapLogger)
+	nudge.NewScheduledMessageService(eventBus, zapLogger, scheduledMessages)
+
+	// Countdowns edit a message counting down to the due time of tasks due within hours
+	countdowns := nudge.NewGormCountdownRepository(db, zapLogger)
+	nudge.NewCountdownService(eventBus, zapLogger, countdowns, nudgeRepository)
+
+	// Day plans schedule the rest of today's tasks with the LLM and set reminders once accepted
+	nudge.NewPlanService(eventBus, zapLogger, nudgeRepository)
+
+	// Account merges move a user's data to their new account and can be undone for a while
+	mergeService := account.NewMergeService(eventBus, zapLogger, account.NewGormMergeRepository(db, zapLogger), time.Duration(cfg.Nudge.MergeUndoWindow)*time.Hour)
+
+	// Task history is recorded from task events and shown with the History button;
+	// the daily rollups behind the /stats burndown are cached in the stats table
+	historyService := nudge.NewHistoryServiceWithStats(eventBus, zapLogger, nudge.NewGormHistoryRepository(db, zapLogger), nudgeRepository, workspaceService,
+		nudge.NewGormStatsRepository(db, zapLogger))
+
+	// Recent user activity in a chat holds reminders back for a few minutes
+	activityTracker := nudge.NewActivityTracker(eventBus, zapLogger, nudge.NewGormActivityRepository(db, zapLogger))
+
+	// Reminders are routed by priority to email and the daily digest as well as Telegram
+	digestRepository := notify.NewGormDigestRepository(db, zapLogger)
+	reminderSenders := map[string]notify.Sender{
+		events.ReminderChannelDigest: notify.NewDigestSender(digestRepository),
+	}
+	if cfg.Notify.Email.Host != "" {
+		mailer, err := notify.NewSMTPMailer(cfg.Notify.Email)
+		if err != nil {
+			logger.Fatal("Invalid email configuration", "error", err)
+		}
+		reminderSenders[events.ReminderChannelEmail] = notify.NewEmailSender(mailer, notify.AddressBookFunc(func(userID common.UserID) (string, error) {
+			settings, err := nudgeRepository.GetNudgeSettingsByUserID(userID)
+			if err != nil {
+				return "", err
+			}
+			return settings.Email, nil
+		}))
+	}
+	reminderRouter := notify.NewRouter(eventBus, zapLogger, reminderSenders)
+
+	// Personal API tokens let browser extensions and shortcuts add tasks
+	apiTokenService := nudge.NewAPITokenService(eventBus, zapLogger, nudge.NewGormAPITokenRepository(db, zapLogger))
+	// Admins see deployment-wide counts with /globalstats
+	globalStatsService := nudge.NewGlobalStatsService(eventBus, zapLogger, nudge.NewGormGlobalStatsReader(db))
+
+	moderationPolicy := moderation.NewPolicyFromConfig(cfg.Chatbot.Moderation, zapLogger)
+	// Places tasks mention are looked up when geocoding is on
+	var geocoder geocode.Geocoder
+	if cfg.Geocoding.Enabled {
+		geocodeHTTP := httpclient.New("geocoding", httpclient.OptionsFromConfig(cfg.HTTPClient, time.Duration(cfg.Geocoding.Timeout)*time.Second), httpMetrics, zapLogger)
+		geocoder = geocode.NewNominatimGeocoder(cfg.Geocoding.URL, cfg.Geocoding.UserAgent, geocodeHTTP)
 	}
-	llmService := llm.NewLLMService(eventBus, zapLogger, cfg.LLM)
-	nudgeRepository := nudge.NewGormNudgeRepository(db, zapLogger)
-	nudgeService, err := nudge.NewNudgeService(eventBus, zapLogger, nudgeRepository)
+	// Tasks are handed off to users found by their Telegram username, and
+	// their detail view summarizes the recorded history
+	nudgeService, err := nudge.NewNudgeServiceWithHistory(eventBus, zapLogger, nudgeRepository, moderationPolicy, workspaceService, listService, user.NewGormRepository(db, zapLogger),
+		time.Duration(cfg.Nudge.ConflictTolerance)*time.Minute, geocoder, historyService)
 	if err != nil {
 		logger.Fatal("Failed to initialize nudge service", "error", err)
 	}
 
+	// Initialize A/B experiments for reminder copy
+	var experimentService experiment.ExperimentService
+	var reminderVariants scheduler.ReminderVariantSelector
+	if cfg.Experiments.Enabled {
+		exposureRepository := experiment.NewGormExposureRepository(db, zapLogger)
+		experimentService = experiment.NewExperimentService(eventBus, zapLogger, exposureRepository, cfg.Experiments)
+		reminderVariants = experimentService
+		logger.Info("Experiments enabled", "count", len(cfg.Experiments.Definitions))
+	}
+
 	// Initialize scheduler
 	var reminderScheduler scheduler.Scheduler
+	var jobScheduler scheduler.JobScheduler
+	var prober probe.Prober
 	if cfg.Scheduler.Enabled {
 		var err error
-		reminderScheduler, err = scheduler.NewScheduler(cfg.Scheduler, nudgeRepository, eventBus, zapLogger)
+		reminderScheduler, err = scheduler.NewSchedulerWithBots(cfg.Scheduler, nudgeRepository, eventBus, zapLogger, reminderVariants, activityTracker, maintenanceSwitch, directory)
 		if err != nil {
 			logger.Error("Failed to create scheduler", "error", err)
 			log.Fatal("Failed to create scheduler: ", err)
 		}
 
-		// Start scheduler in background
-		go func() {
-			if err := reminderScheduler.Start(context.Background()); err != nil {
-				logger.Error("Scheduler failed to start", "error", err)
-			}
-		}()
-
-		logger.Info("Reminder scheduler started",
+		logger.Info("Reminder scheduler configured",
 			"poll_interval", cfg.Scheduler.PollInterval,
 			"nudge_delay", cfg.Scheduler.NudgeDelay,
 			"worker_count", cfg.Scheduler.WorkerCount)
+
+		// Periodic jobs register on the job scheduler before it starts
+		jobScheduler = scheduler.NewJobSchedulerWithMaintenance(cfg.Scheduler, zapLogger, loadGovernor, maintenanceSwitch)
+		if err := jobScheduler.Register("feature_flags_refresh", "* * * * *", flagService.Refresh); err != nil {
+			logger.Error("Failed to register feature flag refresh job", "error", err)
+		}
+
+		if cfg.Probe.Enabled {
+			prober, err = probe.NewProber(eventBus, zapLogger, cfg.Probe)
+			if err != nil {
+				logger.Fatal("Failed to create synthetic probe", "error", err)
+			}
+			if err := jobScheduler.Register(probe.JobName, probe.DefaultSchedule, prober.Run); err != nil {
+				logger.Error("Failed to register synthetic probe job", "error", err)
+			}
+		}
+
+		if cfg.Nudge.ArchiveAfterMonths > 0 {
+			archiver := nudge.NewArchiver(db, zapLogger, cfg.Nudge.ArchiveAfterMonths, cfg.Nudge.ArchiveBatchSize)
+			if err := jobScheduler.Register(nudge.ArchiveJobName, nudge.DefaultArchiveSchedule, archiver.Run); err != nil {
+				logger.Error("Failed to register archive job", "error", err)
+			}
+		}
+
+		// Completed tasks are deleted once older than the retention each user chose
+		retentionPurger := nudge.NewRetentionPurger(db, zapLogger, cfg.Nudge.ArchiveBatchSize)
+		if err := jobScheduler.Register(nudge.RetentionPurgeJobName, nudge.DefaultRetentionPurgeSchedule, retentionPurger.Run); err != nil {
+			logger.Error("Failed to register retention purge job", "error", err)
+		}
+
+		overdueDetector := scheduler.NewOverdueDetectorWithBots(nudgeRepository, directory, eventBus, zapLogger)
+		if err := jobScheduler.Register(scheduler.OverdueJobName, scheduler.DefaultOverdueSchedule, overdueDetector.Run); err != nil {
+			logger.Error("Failed to register overdue detection job", "error", err)
+		}
+
+		messageSender := scheduler.NewScheduledMessageSender(scheduledMessages, eventBus, zapLogger)
+		if err := jobScheduler.Register(scheduler.ScheduledMessageJobName, scheduler.DefaultScheduledMessageSchedule, messageSender.Run); err != nil {
+			logger.Error("Failed to register scheduled message job", "error", err)
+		}
+
+		countdownUpdater := scheduler.NewCountdownUpdater(countdowns, nudgeRepository, eventBus, zapLogger)
+		if err := jobScheduler.Register(scheduler.CountdownJobName, scheduler.DefaultCountdownSchedule, countdownUpdater.Run); err != nil {
+			logger.Error("Failed to register countdown job", "error", err)
+		}
+
+		conflictChecker := nudge.NewConflictChecker(nudgeRepository, time.Duration(cfg.Nudge.ConflictTolerance)*time.Minute)
+		digestTasks := notify.TaskLookupFunc(func(ctx context.Context, id common.TaskID) (*nudge.Task, error) {
+			return nudgeRepository.WithContext(ctx).GetTaskByID(id)
+		})
+		digester := notify.NewDigesterWithFlags(digestRepository, digestTasks, conflictChecker, nudgeRepository, holidayCalendar, nudgeService, flagService, eventBus, zapLogger)
+		if err := jobScheduler.Register(notify.DigestJobName, notify.DefaultDigestSchedule, digester.Run); err != nil {
+			logger.Error("Failed to register reminder digest job", "error", err)
+		}
+
+		if parseAudits != nil && cfg.LLM.Audit.RetentionDays > 0 {
+			purger := llm.NewAuditPurger(parseAudits, zapLogger, cfg.LLM.Audit.RetentionDays)
+			if err := jobScheduler.Register(llm.AuditPurgeJobName, llm.DefaultAuditPurgeSchedule, purger.Run); err != nil {
+				logger.Error("Failed to register parse audit purge job", "error", err)
+			}
+		}
 	} else {
 		logger.Info("Reminder scheduler disabled")
+		if cfg.Probe.Enabled {
+			logger.Warn("Synthetic probe is enabled but needs the scheduler; it will not run")
+		}
 	}
 
-	// Log that services are initialized (to avoid unused variable warnings)
-	logger.Info("Services initialized",
-		"chatbot", chatbotService != nil,
-		"llm", llmService != nil,
-		"nudge", nudgeService != nil)
+	// Services start after the services whose events they consume and stop
+	// in the reverse order
+	lifecycle := newLifecycleManager(logger)
+	lifecycle.Register("governor", loadGovernor)
+	lifecycle.Register("maintenance", maintenanceSwitch)
+	lifecycle.Register("nudge", nudgeService)
+	lifecycle.Register("llm", llmService, "nudge")
+	lifecycle.Register("chatbot", chatbotService, "llm")
+	for name, botService := range botServices {
+		lifecycle.Register("chatbot:"+name, botService, "llm")
+	}
+	if reminderScheduler != nil {
+		lifecycle.Register("scheduler", reminderScheduler, "nudge", "chatbot")
+		lifecycle.Register("jobs", jobScheduler, "governor")
+	}
+	if err := lifecycle.StartAll(shutdownCtx); err != nil {
+		shutdownServer(srv, logger)
+		logger.Fatal("Failed to start services", "error", err)
+	}
 
 	// Validate event bus subscriptions
 	logger.Info("Validating event bus subscriptions...")
@@ -110,49 +607,59 @@ func main() {
 		"llm_subscriptions", "MessageReceived",
 		"nudge_subscriptions", "TaskParsed, TaskListRequested, TaskActionRequested")
 
-	// Allow services to complete initialization
-	time.Sleep(100 * time.Millisecond)
+	// Wait until every service has registered its subscriptions
+	readyServices := []common.ReadySignaler{chatbotService, llmService, nudgeService, workspaceService, listService, historyService, activityTracker, apiTokenService, globalStatsService, reminderRouter, maintenanceSwitch}
+	for _, botService := range botServices {
+		readyServices = append(readyServices, botService)
+	}
+	if experimentService != nil {
+		readyServices = append(readyServices, experimentService)
+	}
+	if reminderScheduler != nil {
+		readyServices = append(readyServices, reminderScheduler)
+	}
+	readyCtx, readyCancel := context.WithTimeout(shutdownCtx, 10*time.Second)
+	err = common.WaitForReady(readyCtx, readyServices...)
+	readyCancel()
+	if err != nil {
+		logger.Warn("Services did not report ready", "error", err)
+	}
 
-	// Setup Gin router
-	if cfg.Server.Environment == "production" {
-		gin.SetMode(gin.ReleaseMode)
+	dbPool, err := database.NewPool(db)
+	if err != nil {
+		logger.Warn("Connection pool metrics are unavailable", "error", err)
 	}
 
+	// Replace the startup routes with the full router
 	router := gin.New()
-	routes.SetupRoutes(router, db, logger, chatbotService)
-
-	// Create HTTP server
-	srv := &http.Server{
-		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
-		Handler:      router,
-		ReadTimeout:  time.Duration(cfg.Server.ReadTimeout) * time.Second,
-		WriteTimeout: time.Duration(cfg.Server.WriteTimeout) * time.Second,
+	routes.SetupRoutes(router, db, logger, chatbotService, eventBus, maintenanceSwitch, webhookAllowlist)
+	routes.SetupBotRoutes(router, logger, eventBus, botServices, webhookAllowlist)
+	routes.SetupMetricsRoutes(router, logger, repositoryMetrics, reminderScheduler, jobScheduler, loadGovernor, prober, dbPool, eventBus, llmQueue, httpMetrics)
+	routes.SetupQuickAddRoutes(router, logger, apiTokenService, nudgeService, llmService)
+	if cfg.Landing.Enabled {
+		routes.SetupLandingRoutes(router, cfg.Landing, startedAt, maintenanceSwitch)
 	}
+	if cfg.WebApp.Enabled {
+		routes.SetupWebAppRoutes(router, logger, cfg.Chatbot.Token, time.Duration(cfg.WebApp.MaxAge)*time.Second, identities, nudgeService)
+	}
+	routes.SetupAdminRoutes(router, logger, cfg.Server.AdminToken, experimentService, flagService, workspaceService, mergeService, historyService, nudgeService, captureRecorder, logger.Levels(), promptStore, maintenanceSwitch, chatbotService, cfg.Chatbot.Authorization.AdminIDs)
+	handler.Swap(router)
+	logger.Info("Server ready", "port", cfg.Server.Port)
 
-	// Start server in goroutine
-	go func() {
-		logger.Info("Starting server", "port", cfg.Server.Port)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Fatal("Failed to start server", "error", err)
-		}
-	}()
+	// Webhooks are registered only now, so Telegram's first delivery finds the route
+	lifecycle.NotifyServing(shutdownCtx)
 
 	// Wait for interrupt signal for graceful shutdown
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	<-shutdownCtx.Done()
 
 	logger.Info("Shutting down server...")
 
-	// Stop scheduler first
-	if cfg.Scheduler.Enabled && reminderScheduler != nil {
-		logger.Info("Stopping reminder scheduler...")
-		if err := reminderScheduler.Stop(); err != nil {
-			logger.Error("Failed to stop scheduler gracefully", "error", err)
-		} else {
-			logger.Info("Reminder scheduler stopped successfully")
-		}
+	// Stop services, producers first, before closing the event bus
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	if err := lifecycle.StopAll(stopCtx); err != nil {
+		logger.Error("Failed to stop services gracefully", "error", err)
 	}
+	stopCancel()
 
 	// Stop accepting new events
 	logger.Info("Stopping event processing...")
@@ -174,12 +681,17 @@ func main() {
 		logger.Info("Event bus closed successfully")
 	}
 
+	shutdownServer(srv, logger)
+
+	logger.Info("Server exited")
+}
+
+// shutdownServer stops the HTTP server, waiting for in-flight requests
+func shutdownServer(srv *http.Server, logger *logger.Logger) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
 	if err := srv.Shutdown(ctx); err != nil {
 		logger.Fatal("Server forced to shutdown", "error", err)
 	}
-
-	logger.Info("Server exited")
 }
@@ -0,0 +1,65 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"nudgebot-api/internal/chaos"
+	"nudgebot-api/internal/chatbot"
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/llm"
+	"nudgebot-api/internal/mocks"
+	"nudgebot-api/internal/nudge"
+)
+
+func TestChaos_TelegramRateLimit(t *testing.T) {
+	injector := chaos.NewInjector(map[chaos.Fault]float64{chaos.FaultTelegramRateLimit: 1}, 1)
+	mockProvider := mocks.NewMockTelegramProvider()
+	provider := chatbot.NewChaosTelegramProvider(mockProvider, injector)
+
+	err := provider.SendMessage(42, 0, "hello")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, chaos.ErrInjected)
+
+	var apiErr *tgbotapi.Error
+	require.True(t, errors.As(err, &apiErr), "injected errors look like Bot API errors")
+	assert.Equal(t, 429, apiErr.Code)
+	assert.Equal(t, 1, apiErr.RetryAfter)
+	assert.Empty(t, mockProvider.GetSentMessages(), "the failed call never reaches Telegram")
+}
+
+func TestChaos_DatabaseTimeout(t *testing.T) {
+	injector := chaos.NewInjector(map[chaos.Fault]float64{chaos.FaultDatabaseTimeout: 1}, 1)
+	repository := nudge.NewChaosNudgeRepository(nudge.NewMemoryNudgeRepository(zaptest.NewLogger(t)), injector)
+
+	_, err := repository.GetTasksByUserID(common.UserID(common.NewID()), nudge.TaskFilter{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorIs(t, err, chaos.ErrInjected)
+
+	err = repository.WithTransaction(func(tx nudge.NudgeRepository) error { return nil })
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int64(2), injector.Injected()[chaos.FaultDatabaseTimeout])
+}
+
+func TestChaos_LLMErrorsUseFallback(t *testing.T) {
+	injector := chaos.NewInjector(map[chaos.Fault]float64{chaos.FaultLLMError: 1}, 1)
+
+	var primaryErr error
+	provider := llm.NewFallbackProvider(
+		llm.NewChaosProvider(llm.NewHeuristicProvider(nil), injector),
+		llm.NewHeuristicProvider(nil),
+		func(err error) { primaryErr = err },
+	)
+
+	response, err := provider.ParseTask(context.Background(), llm.ParseRequest{Text: "buy milk tomorrow", UserID: "user"})
+	require.NoError(t, err)
+	require.NotNil(t, response)
+	require.Error(t, primaryErr, "the injected failure reaches the fallback path")
+	assert.True(t, llm.IsRetryable(primaryErr))
+}
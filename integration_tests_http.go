@@ -30,7 +30,7 @@ func setupIntegrationTest(t *testing.T) (*gin.Engine, func()) {
 
 	// Create router
 	router := gin.New()
-	routes.SetupRoutes(router, mockDB, logger, mockChatbot)
+	routes.SetupRoutes(router, mockDB, logger, mockChatbot, nil, nil)
 
 	cleanup := func() {
 		// Any cleanup if needed
@@ -111,6 +111,24 @@ func (m *MockChatbotService) ProcessCommand(command chatbot.Command, userID comm
 	return nil
 }
 
+func (m *MockChatbotService) Ready() <-chan struct{} {
+	ready := make(chan struct{})
+	close(ready)
+	return ready
+}
+
+func (m *MockChatbotService) Start(ctx context.Context) error {
+	return nil
+}
+
+func (m *MockChatbotService) Stop(ctx context.Context) error {
+	return nil
+}
+
+func (m *MockChatbotService) Health() error {
+	return nil
+}
+
 func (m *MockChatbotService) SetError(err error) {
 	m.errors = append(m.errors, err)
 }
@@ -0,0 +1,57 @@
+package account
+
+import (
+	"errors"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/nudge"
+)
+
+// DefaultUndoWindow is how long a merge can be undone
+const DefaultUndoWindow = 72 * time.Hour
+
+// Account merge errors
+var (
+	ErrInvalidMerge  = errors.New("invalid account merge")
+	ErrMergeNotFound = errors.New("account merge not found")
+	ErrUndoExpired   = errors.New("account merge can no longer be undone")
+	ErrAlreadyUndone = errors.New("account merge was already undone")
+)
+
+// AccountMerge is the audit record of moving one user's data to another user
+type AccountMerge struct {
+	ID            string        `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	FromUserID    common.UserID `json:"from_user_id" gorm:"type:varchar(36);not null;index"`
+	IntoUserID    common.UserID `json:"into_user_id" gorm:"type:varchar(36);not null;index"`
+	RequestedBy   string        `json:"requested_by" gorm:"type:varchar(255);not null"`
+	Changes       MergeChanges  `json:"changes" gorm:"type:jsonb;serializer:json"`
+	CreatedAt     time.Time     `json:"created_at" gorm:"type:timestamp;not null"`
+	UndoExpiresAt time.Time     `json:"undo_expires_at" gorm:"type:timestamp;not null"`
+	UndoneAt      *time.Time    `json:"undone_at,omitempty" gorm:"type:timestamp"`
+	UndoneBy      string        `json:"undone_by,omitempty" gorm:"type:varchar(255)"`
+}
+
+// TableName returns the table name for the AccountMerge model
+func (AccountMerge) TableName() string {
+	return "account_merges"
+}
+
+// MergeChanges records the rows a merge moved, so it can be undone. Rows the
+// merged user creates afterwards stay with them on undo.
+type MergeChanges struct {
+	TaskIDs         []string                 `json:"task_ids,omitempty"`
+	TaskChatIDs     []string                 `json:"task_chat_ids,omitempty"` // tasks in the user's private chat
+	ReminderIDs     []string                 `json:"reminder_ids,omitempty"`
+	ReminderChatIDs []string                 `json:"reminder_chat_ids,omitempty"`
+	Accounts        []events.TelegramAccount `json:"accounts,omitempty"`
+	SettingsMoved   bool                     `json:"settings_moved,omitempty"`
+	// DroppedSettings holds the merged user's settings when the target kept its own
+	DroppedSettings *nudge.NudgeSettings `json:"dropped_settings,omitempty"`
+}
+
+// Undoable reports whether the merge can still be undone at the given time
+func (m *AccountMerge) Undoable(now time.Time) bool {
+	return m.UndoneAt == nil && now.Before(m.UndoExpiresAt)
+}
@@ -0,0 +1,235 @@
+package account
+
+import (
+	"errors"
+	"fmt"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/nudge"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// identitiesTable maps Telegram accounts to users; it is owned by the chatbot
+const identitiesTable = "telegram_identities"
+
+// MergeRepository moves user data between users and keeps the audit trail
+type MergeRepository interface {
+	// Merge moves the data of merge.FromUserID to merge.IntoUserID, records
+	// what moved in merge.Changes and stores the merge, all atomically
+	Merge(merge *AccountMerge) error
+	// Undo moves the recorded rows back and marks the merge undone
+	Undo(merge *AccountMerge) error
+	GetMerge(id string) (*AccountMerge, error)
+	ListMerges(userID common.UserID) ([]*AccountMerge, error)
+}
+
+// telegramAccountRow is a row of the identities table
+type telegramAccountRow struct {
+	Bot        string
+	TelegramID int64
+}
+
+// gormMergeRepository implements MergeRepository using GORM
+type gormMergeRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewGormMergeRepository creates a GORM-based merge repository
+func NewGormMergeRepository(db *gorm.DB, logger *zap.Logger) MergeRepository {
+	return &gormMergeRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Merge moves one user's data to another user within a transaction
+func (r *gormMergeRepository) Merge(merge *AccountMerge) error {
+	from, into := merge.FromUserID, merge.IntoUserID
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		changes := MergeChanges{}
+
+		if err := moveColumn(tx, &nudge.Task{}, "user_id", from, into, &changes.TaskIDs); err != nil {
+			return err
+		}
+		// Telegram gives a private chat its user's ID, so that chat moves too
+		if err := moveColumn(tx, &nudge.Task{}, "chat_id", from, into, &changes.TaskChatIDs); err != nil {
+			return err
+		}
+		if err := moveColumn(tx, &nudge.Reminder{}, "user_id", from, into, &changes.ReminderIDs); err != nil {
+			return err
+		}
+		if err := moveColumn(tx, &nudge.Reminder{}, "chat_id", from, into, &changes.ReminderChatIDs); err != nil {
+			return err
+		}
+
+		// The target keeps its own settings when it has any
+		var settings []*nudge.NudgeSettings
+		if err := tx.Where("user_id IN ?", []common.UserID{from, into}).Find(&settings).Error; err != nil {
+			return fmt.Errorf("failed to load nudge settings: %w", err)
+		}
+		var fromSettings *nudge.NudgeSettings
+		intoHasSettings := false
+		for _, s := range settings {
+			if s.UserID == from {
+				fromSettings = s
+			} else {
+				intoHasSettings = true
+			}
+		}
+		if fromSettings != nil && intoHasSettings {
+			if err := tx.Where("user_id = ?", from).Delete(&nudge.NudgeSettings{}).Error; err != nil {
+				return fmt.Errorf("failed to drop nudge settings: %w", err)
+			}
+			changes.DroppedSettings = fromSettings
+		} else if fromSettings != nil {
+			if err := tx.Model(&nudge.NudgeSettings{}).Where("user_id = ?", from).Update("user_id", into).Error; err != nil {
+				return fmt.Errorf("failed to move nudge settings: %w", err)
+			}
+			changes.SettingsMoved = true
+		}
+
+		// The merged user's Telegram accounts now resolve to the target
+		var accounts []telegramAccountRow
+		if err := tx.Table(identitiesTable).Select("bot, telegram_id").Where("id = ?", from).Find(&accounts).Error; err != nil {
+			return fmt.Errorf("failed to load telegram identities: %w", err)
+		}
+		if err := tx.Table(identitiesTable).Where("id = ?", from).Update("id", into).Error; err != nil {
+			return fmt.Errorf("failed to move telegram identities: %w", err)
+		}
+		for _, account := range accounts {
+			changes.Accounts = append(changes.Accounts, events.TelegramAccount{Bot: account.Bot, TelegramID: account.TelegramID})
+		}
+
+		merge.Changes = changes
+		if err := tx.Create(merge).Error; err != nil {
+			return fmt.Errorf("failed to record account merge: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	r.logger.Info("Accounts merged",
+		zap.String("mergeID", merge.ID),
+		zap.String("fromUserID", string(from)),
+		zap.String("intoUserID", string(into)),
+		zap.Int("tasks", len(merge.Changes.TaskIDs)),
+		zap.Int("reminders", len(merge.Changes.ReminderIDs)))
+	return nil
+}
+
+// Undo moves the recorded rows back within a transaction
+func (r *gormMergeRepository) Undo(merge *AccountMerge) error {
+	from, into := merge.FromUserID, merge.IntoUserID
+	changes := merge.Changes
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Claiming the merge first keeps concurrent undos from both applying
+		result := tx.Model(&AccountMerge{}).
+			Where("id = ? AND undone_at IS NULL", merge.ID).
+			Updates(map[string]interface{}{"undone_at": merge.UndoneAt, "undone_by": merge.UndoneBy})
+		if result.Error != nil {
+			return fmt.Errorf("failed to mark account merge undone: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrAlreadyUndone
+		}
+
+		if err := restoreColumn(tx, &nudge.Task{}, "user_id", from, changes.TaskIDs); err != nil {
+			return err
+		}
+		if err := restoreColumn(tx, &nudge.Task{}, "chat_id", from, changes.TaskChatIDs); err != nil {
+			return err
+		}
+		if err := restoreColumn(tx, &nudge.Reminder{}, "user_id", from, changes.ReminderIDs); err != nil {
+			return err
+		}
+		if err := restoreColumn(tx, &nudge.Reminder{}, "chat_id", from, changes.ReminderChatIDs); err != nil {
+			return err
+		}
+
+		if changes.SettingsMoved {
+			if err := tx.Model(&nudge.NudgeSettings{}).Where("user_id = ?", into).Update("user_id", from).Error; err != nil {
+				return fmt.Errorf("failed to restore nudge settings: %w", err)
+			}
+		}
+		if changes.DroppedSettings != nil {
+			if err := tx.Create(changes.DroppedSettings).Error; err != nil {
+				return fmt.Errorf("failed to restore nudge settings: %w", err)
+			}
+		}
+
+		for _, account := range changes.Accounts {
+			err := tx.Table(identitiesTable).
+				Where("bot = ? AND telegram_id = ?", account.Bot, account.TelegramID).
+				Update("id", from).Error
+			if err != nil {
+				return fmt.Errorf("failed to restore telegram identity: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetMerge retrieves a merge by its ID
+func (r *gormMergeRepository) GetMerge(id string) (*AccountMerge, error) {
+	var merge AccountMerge
+	if err := r.db.Where("id = ?", id).First(&merge).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMergeNotFound
+		}
+		return nil, fmt.Errorf("failed to get account merge: %w", err)
+	}
+	return &merge, nil
+}
+
+// ListMerges returns the merges involving a user, newest first
+func (r *gormMergeRepository) ListMerges(userID common.UserID) ([]*AccountMerge, error) {
+	var merges []*AccountMerge
+	err := r.db.Where("from_user_id = ? OR into_user_id = ?", userID, userID).
+		Order("created_at DESC").
+		Find(&merges).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list account merges: %w", err)
+	}
+	return merges, nil
+}
+
+// moveColumn sets column from one user to another, collecting the moved row IDs
+func moveColumn(tx *gorm.DB, model interface{}, column string, from, into common.UserID, ids *[]string) error {
+	if err := tx.Model(model).Where(column+" = ?", from).Pluck("id", ids).Error; err != nil {
+		return fmt.Errorf("failed to find rows to merge: %w", err)
+	}
+	if len(*ids) == 0 {
+		return nil
+	}
+	if err := tx.Model(model).Where("id IN ?", *ids).Update(column, into).Error; err != nil {
+		return fmt.Errorf("failed to merge %s: %w", column, err)
+	}
+	return nil
+}
+
+// restoreColumn sets column back to the merged user on the recorded rows
+func restoreColumn(tx *gorm.DB, model interface{}, column string, from common.UserID, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := tx.Model(model).Where("id IN ?", ids).Update(column, from).Error; err != nil {
+		return fmt.Errorf("failed to restore %s: %w", column, err)
+	}
+	return nil
+}
+
+// RunMigrations creates the account tables
+func RunMigrations(db *gorm.DB) error {
+	if err := db.AutoMigrate(&AccountMerge{}); err != nil {
+		return fmt.Errorf("failed to auto-migrate account tables: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package account
+
+import (
+	"fmt"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// MergeService merges user accounts, for example after someone moves to a
+// new Telegram account, and undoes merges within the undo window
+type MergeService interface {
+	Merge(fromUserID, intoUserID common.UserID, requestedBy string) (*AccountMerge, error)
+	Undo(mergeID string, requestedBy string) (*AccountMerge, error)
+	ListMerges(userID common.UserID) ([]*AccountMerge, error)
+}
+
+// mergeService implements the MergeService interface
+type mergeService struct {
+	eventBus   events.EventBus
+	logger     *zap.Logger
+	repository MergeRepository
+	undoWindow time.Duration
+	clock      common.Clock
+}
+
+// NewMergeService creates a MergeService whose merges can be undone for
+// undoWindow. A non-positive window uses DefaultUndoWindow.
+func NewMergeService(eventBus events.EventBus, logger *zap.Logger, repository MergeRepository, undoWindow time.Duration) MergeService {
+	if undoWindow <= 0 {
+		undoWindow = DefaultUndoWindow
+	}
+
+	return &mergeService{
+		eventBus:   eventBus,
+		logger:     logger,
+		repository: repository,
+		undoWindow: undoWindow,
+		clock:      common.NewRealClock(),
+	}
+}
+
+// Merge moves the tasks, reminders, settings and Telegram accounts of one user to another
+func (s *mergeService) Merge(fromUserID, intoUserID common.UserID, requestedBy string) (*AccountMerge, error) {
+	if !common.ID(fromUserID).IsValid() || !common.ID(intoUserID).IsValid() {
+		return nil, fmt.Errorf("%w: user IDs must be UUIDs", ErrInvalidMerge)
+	}
+	if fromUserID == intoUserID {
+		return nil, fmt.Errorf("%w: cannot merge a user into itself", ErrInvalidMerge)
+	}
+	if requestedBy == "" {
+		return nil, fmt.Errorf("%w: requested_by is required for the audit trail", ErrInvalidMerge)
+	}
+
+	now := s.clock.Now()
+	merge := &AccountMerge{
+		ID:            string(common.NewID()),
+		FromUserID:    fromUserID,
+		IntoUserID:    intoUserID,
+		RequestedBy:   requestedBy,
+		CreatedAt:     now,
+		UndoExpiresAt: now.Add(s.undoWindow),
+	}
+	if err := s.repository.Merge(merge); err != nil {
+		return nil, err
+	}
+
+	event := events.AccountMerged{
+		Event:      events.NewEvent(),
+		MergeID:    merge.ID,
+		FromUserID: string(fromUserID),
+		IntoUserID: string(intoUserID),
+		Accounts:   merge.Changes.Accounts,
+	}
+	if err := s.eventBus.Publish(events.TopicAccountMerged, event); err != nil {
+		s.logger.Error("Failed to publish AccountMerged event", zap.Error(err))
+	}
+	return merge, nil
+}
+
+// Undo reverts a merge that is still within its undo window
+func (s *mergeService) Undo(mergeID string, requestedBy string) (*AccountMerge, error) {
+	if requestedBy == "" {
+		return nil, fmt.Errorf("%w: requested_by is required for the audit trail", ErrInvalidMerge)
+	}
+
+	merge, err := s.repository.GetMerge(mergeID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.clock.Now()
+	if merge.UndoneAt != nil {
+		return nil, ErrAlreadyUndone
+	}
+	if !merge.Undoable(now) {
+		return nil, ErrUndoExpired
+	}
+
+	merge.UndoneAt = &now
+	merge.UndoneBy = requestedBy
+	if err := s.repository.Undo(merge); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Account merge undone",
+		zap.String("mergeID", merge.ID),
+		zap.String("undoneBy", requestedBy))
+
+	event := events.AccountMergeUndone{
+		Event:      events.NewEvent(),
+		MergeID:    merge.ID,
+		FromUserID: string(merge.FromUserID),
+		IntoUserID: string(merge.IntoUserID),
+		Accounts:   merge.Changes.Accounts,
+	}
+	if err := s.eventBus.Publish(events.TopicAccountMergeUndone, event); err != nil {
+		s.logger.Error("Failed to publish AccountMergeUndone event", zap.Error(err))
+	}
+	return merge, nil
+}
+
+// ListMerges returns the merges involving a user, newest first
+func (s *mergeService) ListMerges(userID common.UserID) ([]*AccountMerge, error) {
+	return s.repository.ListMerges(userID)
+}
@@ -0,0 +1,134 @@
+package account
+
+import (
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeMergeRepository records merges in memory
+type fakeMergeRepository struct {
+	merges map[string]*AccountMerge
+	undone []string
+}
+
+func newFakeMergeRepository() *fakeMergeRepository {
+	return &fakeMergeRepository{merges: make(map[string]*AccountMerge)}
+}
+
+func (r *fakeMergeRepository) Merge(merge *AccountMerge) error {
+	merge.Changes = MergeChanges{
+		TaskIDs:  []string{"task-1"},
+		Accounts: []events.TelegramAccount{{TelegramID: 42}},
+	}
+	r.merges[merge.ID] = merge
+	return nil
+}
+
+func (r *fakeMergeRepository) Undo(merge *AccountMerge) error {
+	r.undone = append(r.undone, merge.ID)
+	return nil
+}
+
+func (r *fakeMergeRepository) GetMerge(id string) (*AccountMerge, error) {
+	merge, ok := r.merges[id]
+	if !ok {
+		return nil, ErrMergeNotFound
+	}
+	return merge, nil
+}
+
+func (r *fakeMergeRepository) ListMerges(userID common.UserID) ([]*AccountMerge, error) {
+	return nil, nil
+}
+
+func newTestMergeService(t *testing.T) (*mergeService, *fakeMergeRepository, *events.MockEventBus, *common.MockClock) {
+	t.Helper()
+	repository := newFakeMergeRepository()
+	bus := events.NewMockEventBus()
+	clock := common.NewMockClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+
+	service := NewMergeService(bus, zap.NewNop(), repository, time.Hour).(*mergeService)
+	service.clock = clock
+	return service, repository, bus, clock
+}
+
+func TestMergeService_Merge(t *testing.T) {
+	from := common.UserID(common.NewID())
+	into := common.UserID(common.NewID())
+
+	t.Run("merge is recorded and announced", func(t *testing.T) {
+		service, repository, bus, clock := newTestMergeService(t)
+
+		merge, err := service.Merge(from, into, "admin")
+		require.NoError(t, err)
+
+		assert.Contains(t, repository.merges, merge.ID)
+		assert.Equal(t, clock.Now().Add(time.Hour), merge.UndoExpiresAt)
+
+		published := bus.GetPublishedEvents(events.TopicAccountMerged)
+		require.Len(t, published, 1)
+		event := published[0].(events.AccountMerged)
+		assert.Equal(t, string(into), event.IntoUserID)
+		assert.Equal(t, []events.TelegramAccount{{TelegramID: 42}}, event.Accounts)
+	})
+
+	t.Run("invalid merges are rejected", func(t *testing.T) {
+		service, _, _, _ := newTestMergeService(t)
+
+		_, err := service.Merge(from, from, "admin")
+		assert.ErrorIs(t, err, ErrInvalidMerge)
+
+		_, err = service.Merge("not-a-uuid", into, "admin")
+		assert.ErrorIs(t, err, ErrInvalidMerge)
+
+		_, err = service.Merge(from, into, "")
+		assert.ErrorIs(t, err, ErrInvalidMerge)
+	})
+}
+
+func TestMergeService_Undo(t *testing.T) {
+	from := common.UserID(common.NewID())
+	into := common.UserID(common.NewID())
+
+	t.Run("undo within the window", func(t *testing.T) {
+		service, repository, bus, clock := newTestMergeService(t)
+		merge, err := service.Merge(from, into, "admin")
+		require.NoError(t, err)
+
+		clock.Advance(30 * time.Minute)
+		undone, err := service.Undo(merge.ID, "admin")
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{merge.ID}, repository.undone)
+		require.NotNil(t, undone.UndoneAt)
+		assert.Equal(t, "admin", undone.UndoneBy)
+		assert.Len(t, bus.GetPublishedEvents(events.TopicAccountMergeUndone), 1)
+
+		_, err = service.Undo(merge.ID, "admin")
+		assert.ErrorIs(t, err, ErrAlreadyUndone)
+	})
+
+	t.Run("undo after the window", func(t *testing.T) {
+		service, repository, _, clock := newTestMergeService(t)
+		merge, err := service.Merge(from, into, "admin")
+		require.NoError(t, err)
+
+		clock.Advance(2 * time.Hour)
+		_, err = service.Undo(merge.ID, "admin")
+		assert.ErrorIs(t, err, ErrUndoExpired)
+		assert.Empty(t, repository.undone)
+	})
+
+	t.Run("unknown merge", func(t *testing.T) {
+		service, _, _, _ := newTestMergeService(t)
+		_, err := service.Undo("missing", "admin")
+		assert.ErrorIs(t, err, ErrMergeNotFound)
+	})
+}
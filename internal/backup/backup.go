@@ -0,0 +1,154 @@
+// Package backup dumps users, tasks, reminders and nudge settings to a JSON
+// snapshot and loads them back, for moving a deployment or recovering it.
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"nudgebot-api/internal/nudge"
+	"nudgebot-api/internal/user"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Format identifies nudgebot snapshots
+const Format = "nudgebot-backup"
+
+// restoreBatchSize is how many rows a restore inserts per statement
+const restoreBatchSize = 500
+
+// Snapshot is a consistent copy of the data a deployment needs to resume:
+// users, their tasks and reminders, live and archived, and their settings.
+// SchemaVersion is the nudge.SchemaVersion of the code that took it.
+type Snapshot struct {
+	Format            string                `json:"format"`
+	SchemaVersion     int                   `json:"schema_version"`
+	CreatedAt         time.Time             `json:"created_at"`
+	Users             []user.User           `json:"users"`
+	Tasks             []nudge.Task          `json:"tasks"`
+	ArchivedTasks     []nudge.Task          `json:"archived_tasks,omitempty"`
+	Reminders         []nudge.Reminder      `json:"reminders"`
+	ArchivedReminders []nudge.Reminder      `json:"archived_reminders,omitempty"`
+	Settings          []nudge.NudgeSettings `json:"settings"`
+}
+
+// Counts returns how many rows of each table the snapshot holds
+func (s *Snapshot) Counts() map[string]int {
+	return map[string]int{
+		"users":                    len(s.Users),
+		"tasks":                    len(s.Tasks),
+		nudge.TaskArchiveTable:     len(s.ArchivedTasks),
+		"reminders":                len(s.Reminders),
+		nudge.ReminderArchiveTable: len(s.ArchivedReminders),
+		"nudge_settings":           len(s.Settings),
+	}
+}
+
+// CheckVersion returns an error unless the snapshot can be restored by code
+// at schemaVersion. Older snapshots are migrated as they are restored; newer
+// ones may hold data this code would drop.
+func (s *Snapshot) CheckVersion(schemaVersion int) error {
+	if s.Format != Format {
+		return fmt.Errorf("not a nudgebot backup (format %q)", s.Format)
+	}
+	if s.SchemaVersion > schemaVersion {
+		return fmt.Errorf("backup has schema version %d, newer than this build's %d; restore it with a newer nudgectl", s.SchemaVersion, schemaVersion)
+	}
+	return nil
+}
+
+// Write encodes the snapshot as JSON
+func Write(w io.Writer, snapshot *Snapshot) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(snapshot)
+}
+
+// Read decodes a snapshot written by Write
+func Read(r io.Reader) (*Snapshot, error) {
+	var snapshot Snapshot
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode backup: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// Dump reads every table of the snapshot in one read-only repeatable read
+// transaction, so that the tables agree with each other
+func Dump(ctx context.Context, db *gorm.DB) (*Snapshot, error) {
+	snapshot := &Snapshot{
+		Format:        Format,
+		SchemaVersion: nudge.SchemaVersion,
+		CreatedAt:     time.Now().UTC(),
+	}
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		reads := []struct {
+			table string
+			rows  interface{}
+		}{
+			{"users", &snapshot.Users},
+			{"tasks", &snapshot.Tasks},
+			{nudge.TaskArchiveTable, &snapshot.ArchivedTasks},
+			{"reminders", &snapshot.Reminders},
+			{nudge.ReminderArchiveTable, &snapshot.ArchivedReminders},
+			{"nudge_settings", &snapshot.Settings},
+		}
+		for _, read := range reads {
+			if err := tx.Table(read.table).Order("id").Find(read.rows).Error; err != nil {
+				return fmt.Errorf("failed to read %s: %w", read.table, err)
+			}
+		}
+		return nil
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// Restore runs migrate, bringing the schema up to this build, then loads the
+// snapshot in one transaction. Rows already in the database are overwritten
+// by their copy in the snapshot; other rows are kept.
+func Restore(ctx context.Context, db *gorm.DB, snapshot *Snapshot, migrate func(db *gorm.DB) error) error {
+	if err := snapshot.CheckVersion(nudge.SchemaVersion); err != nil {
+		return err
+	}
+	if err := migrate(db); err != nil {
+		return fmt.Errorf("failed to migrate before restoring: %w", err)
+	}
+
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Users go first, as tasks, reminders and settings belong to them
+		writes := []struct {
+			table string
+			rows  interface{}
+			count int
+		}{
+			{"users", &snapshot.Users, len(snapshot.Users)},
+			{"nudge_settings", &snapshot.Settings, len(snapshot.Settings)},
+			{"tasks", &snapshot.Tasks, len(snapshot.Tasks)},
+			{nudge.TaskArchiveTable, &snapshot.ArchivedTasks, len(snapshot.ArchivedTasks)},
+			{"reminders", &snapshot.Reminders, len(snapshot.Reminders)},
+			{nudge.ReminderArchiveTable, &snapshot.ArchivedReminders, len(snapshot.ArchivedReminders)},
+		}
+		for _, write := range writes {
+			if write.count == 0 {
+				continue
+			}
+			err := tx.Table(write.table).
+				Clauses(clause.OnConflict{UpdateAll: true}).
+				CreateInBatches(write.rows, restoreBatchSize).Error
+			if err != nil {
+				return fmt.Errorf("failed to restore %s: %w", write.table, err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,68 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/nudge"
+	"nudgebot-api/internal/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestSnapshot_RoundTrips(t *testing.T) {
+	due := time.Date(2024, 6, 3, 15, 0, 0, 0, time.UTC)
+	snapshot := &Snapshot{
+		Format:        Format,
+		SchemaVersion: nudge.SchemaVersion,
+		CreatedAt:     time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC),
+		Users:         []user.User{{ID: "user-1", TelegramID: 42, Username: "ada"}},
+		Tasks: []nudge.Task{{
+			ID: "task-1", UserID: "user-1", Title: "Dentist", DueDate: &due, Status: common.TaskStatusActive,
+			Tags:         []string{"health"},
+			CustomFields: nudge.CustomFields{"estimate": {Type: nudge.CustomFieldNumber, Value: "30"}},
+			OriginalText: "Book the dentist",
+		}},
+		Settings: []nudge.NudgeSettings{{UserID: "user-1", Timezone: "Asia/Ho_Chi_Minh", RetentionDays: 90}},
+	}
+
+	var buffer bytes.Buffer
+	require.NoError(t, Write(&buffer, snapshot))
+	restored, err := Read(&buffer)
+	require.NoError(t, err)
+
+	assert.Equal(t, snapshot.Users, restored.Users)
+	assert.Equal(t, snapshot.Tasks, restored.Tasks)
+	assert.Equal(t, 90, restored.Settings[0].RetentionDays)
+	assert.Equal(t, 1, restored.Counts()["tasks"])
+	assert.Equal(t, 0, restored.Counts()[nudge.ReminderArchiveTable])
+}
+
+func TestSnapshot_CheckVersion(t *testing.T) {
+	assert.NoError(t, (&Snapshot{Format: Format, SchemaVersion: 1}).CheckVersion(2), "older backups are migrated")
+	assert.NoError(t, (&Snapshot{Format: Format, SchemaVersion: 2}).CheckVersion(2))
+	assert.ErrorContains(t, (&Snapshot{Format: Format, SchemaVersion: 3}).CheckVersion(2), "newer than this build's 2")
+	assert.ErrorContains(t, (&Snapshot{Format: "other"}).CheckVersion(2), "not a nudgebot backup")
+}
+
+func TestRestore_RefusesNewerBackupsBeforeMigrating(t *testing.T) {
+	migrated := false
+	migrate := func(db *gorm.DB) error {
+		migrated = true
+		return errors.New("no database")
+	}
+
+	err := Restore(context.Background(), nil, &Snapshot{Format: Format, SchemaVersion: nudge.SchemaVersion + 1}, migrate)
+	assert.ErrorContains(t, err, "newer than this build's")
+	assert.False(t, migrated)
+
+	err = Restore(context.Background(), nil, &Snapshot{Format: Format, SchemaVersion: nudge.SchemaVersion}, migrate)
+	assert.ErrorContains(t, err, "failed to migrate before restoring")
+	assert.True(t, migrated)
+}
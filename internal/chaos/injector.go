@@ -0,0 +1,125 @@
+// Package chaos injects random failures into outbound dependencies so retry
+// and fallback paths can be exercised in integration tests.
+package chaos
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"nudgebot-api/internal/config"
+)
+
+// Fault identifies a kind of injected failure
+type Fault string
+
+// Faults that can be injected
+const (
+	FaultTelegramRateLimit   Fault = "telegram_rate_limit"
+	FaultTelegramServerError Fault = "telegram_server_error"
+	FaultDatabaseTimeout     Fault = "database_timeout"
+	FaultLLMError            Fault = "llm_error"
+)
+
+// ErrInjected marks errors produced by fault injection
+var ErrInjected = errors.New("chaos: injected fault")
+
+// ErrProductionChaos is returned when chaos mode is enabled in production
+var ErrProductionChaos = errors.New("chaos mode cannot be enabled in production")
+
+// Injector decides at random whether a call should fail. A nil Injector never
+// injects anything, so decorators can be wired unconditionally.
+type Injector struct {
+	mu       sync.Mutex
+	rng      *rand.Rand
+	rates    map[Fault]float64
+	injected map[Fault]int64
+}
+
+// NewInjector creates an injector with the given fault rates
+func NewInjector(rates map[Fault]float64, seed int64) *Injector {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	clamped := make(map[Fault]float64, len(rates))
+	for fault, rate := range rates {
+		clamped[fault] = clampRate(rate)
+	}
+
+	return &Injector{
+		rng:      rand.New(rand.NewSource(seed)),
+		rates:    clamped,
+		injected: make(map[Fault]int64),
+	}
+}
+
+// NewInjectorFromConfig builds an injector from configuration. It returns nil
+// when chaos mode is disabled and refuses to run in production.
+func NewInjectorFromConfig(cfg config.ChaosConfig, environment string) (*Injector, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if strings.EqualFold(environment, "production") {
+		return nil, ErrProductionChaos
+	}
+
+	return NewInjector(map[Fault]float64{
+		FaultTelegramRateLimit:   cfg.TelegramRateLimitRate,
+		FaultTelegramServerError: cfg.TelegramServerErrorRate,
+		FaultDatabaseTimeout:     cfg.DatabaseTimeoutRate,
+		FaultLLMError:            cfg.LLMErrorRate,
+	}, cfg.Seed), nil
+}
+
+// Should reports whether the fault should be injected into the current call
+func (i *Injector) Should(fault Fault) bool {
+	if i == nil {
+		return false
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	rate := i.rates[fault]
+	if rate <= 0 || i.rng.Float64() >= rate {
+		return false
+	}
+
+	i.injected[fault]++
+	return true
+}
+
+// Error returns an error for the fault that wraps ErrInjected
+func (i *Injector) Error(fault Fault, operation string) error {
+	return fmt.Errorf("%w: %s during %s", ErrInjected, fault, operation)
+}
+
+// Injected returns how many times each fault has been injected
+func (i *Injector) Injected() map[Fault]int64 {
+	if i == nil {
+		return map[Fault]int64{}
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	counts := make(map[Fault]int64, len(i.injected))
+	for fault, count := range i.injected {
+		counts[fault] = count
+	}
+	return counts
+}
+
+func clampRate(rate float64) float64 {
+	if rate < 0 {
+		return 0
+	}
+	if rate > 1 {
+		return 1
+	}
+	return rate
+}
@@ -0,0 +1,55 @@
+package chaos
+
+import (
+	"errors"
+	"testing"
+
+	"nudgebot-api/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInjector_Rates(t *testing.T) {
+	injector := NewInjector(map[Fault]float64{
+		FaultDatabaseTimeout: 0.25,
+		FaultLLMError:        1,
+	}, 42)
+
+	hits := 0
+	for i := 0; i < 2000; i++ {
+		if injector.Should(FaultDatabaseTimeout) {
+			hits++
+		}
+		assert.True(t, injector.Should(FaultLLMError))
+		assert.False(t, injector.Should(FaultTelegramRateLimit), "faults without a rate never fire")
+	}
+
+	assert.InDelta(t, 500, hits, 75)
+	assert.Equal(t, int64(hits), injector.Injected()[FaultDatabaseTimeout])
+	assert.Equal(t, int64(2000), injector.Injected()[FaultLLMError])
+}
+
+func TestInjector_SeedReplaysRun(t *testing.T) {
+	first := NewInjector(map[Fault]float64{FaultTelegramServerError: 0.5}, 7)
+	second := NewInjector(map[Fault]float64{FaultTelegramServerError: 0.5}, 7)
+
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, first.Should(FaultTelegramServerError), second.Should(FaultTelegramServerError))
+	}
+}
+
+func TestNewInjectorFromConfig(t *testing.T) {
+	injector, err := NewInjectorFromConfig(config.ChaosConfig{}, "development")
+	require.NoError(t, err)
+	assert.Nil(t, injector)
+	assert.False(t, injector.Should(FaultLLMError), "a nil injector never injects")
+
+	_, err = NewInjectorFromConfig(config.ChaosConfig{Enabled: true, LLMErrorRate: 1}, "production")
+	assert.ErrorIs(t, err, ErrProductionChaos)
+
+	injector, err = NewInjectorFromConfig(config.ChaosConfig{Enabled: true, LLMErrorRate: 1}, "test")
+	require.NoError(t, err)
+	assert.True(t, injector.Should(FaultLLMError))
+	assert.True(t, errors.Is(injector.Error(FaultLLMError, "ParseTask"), ErrInjected))
+}
@@ -0,0 +1,144 @@
+package chatbot
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// broadcastInterval paces a broadcast below Telegram's limit of about 30
+// messages a second
+const broadcastInterval = 40 * time.Millisecond
+
+// senderTelegramID returns the Telegram user who sent a message, 0 for
+// messages without a sender such as channel posts
+func senderTelegramID(message *tgbotapi.Message) int64 {
+	if message == nil || message.From == nil {
+		return 0
+	}
+	return message.From.ID
+}
+
+// ProcessBroadcastCommand handles the /broadcast command, sending the text
+// after it to every user of the admin's bot
+func (cp *CommandProcessor) ProcessBroadcastCommand(userID, chatID, bot, text string) (string, error) {
+	cp.logger.Info("Processing broadcast command",
+		zap.String("user_id", userID),
+		zap.String("chat_id", chatID))
+
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "Usage: /broadcast [message] - Send a message to everyone using this bot", nil
+	}
+
+	broadcastEvent := events.BroadcastRequested{
+		Event:  events.NewEvent(),
+		UserID: userID,
+		ChatID: chatID,
+		Bot:    bot,
+		Text:   text,
+	}
+	if err := cp.eventBus.Publish(events.TopicBroadcastRequested, broadcastEvent); err != nil {
+		return "", err
+	}
+	return "📣 Sending your broadcast…", nil
+}
+
+// ProcessGlobalStatsCommand handles the /globalstats command
+func (cp *CommandProcessor) ProcessGlobalStatsCommand(userID, chatID string) (string, error) {
+	cp.logger.Info("Processing global stats command",
+		zap.String("user_id", userID),
+		zap.String("chat_id", chatID))
+
+	statsEvent := events.GlobalStatsRequested{
+		Event:  events.NewEvent(),
+		UserID: userID,
+		ChatID: chatID,
+	}
+	if err := cp.eventBus.Publish(events.TopicGlobalStatsRequested, statsEvent); err != nil {
+		return "", err
+	}
+	return "", nil // The counts are sent once they are ready
+}
+
+// handleBroadcastReady sends a broadcast to its recipients in the background
+// and tells the admin how it went
+func (s *chatbotService) handleBroadcastReady(event events.BroadcastReady) {
+	if !s.ownsUser(event.UserID) {
+		return
+	}
+
+	if !event.Success {
+		if err := s.SendMessage(common.ChatID(event.ChatID), "❌ "+html.EscapeString(event.Message)); err != nil {
+			s.logger.Error("Failed to report broadcast failure", zap.Error(err))
+		}
+		return
+	}
+	go s.deliverBroadcast(event, broadcastInterval)
+}
+
+// deliverBroadcast sends a broadcast to each recipient in turn, one every
+// interval. Recipients who blocked the bot are counted and skipped.
+func (s *chatbotService) deliverBroadcast(event events.BroadcastReady, interval time.Duration) {
+	text := "📣 " + html.EscapeString(event.Text)
+
+	delivered := 0
+	for i, telegramID := range event.TelegramIDs {
+		if i > 0 && interval > 0 {
+			time.Sleep(interval)
+		}
+		// Private chats share their user's ID
+		if err := s.provider.SendMessage(telegramID, 0, text); err != nil {
+			s.logger.Debug("Broadcast not delivered",
+				zap.Int64("telegram_id", telegramID),
+				zap.Error(err))
+			continue
+		}
+		delivered++
+	}
+
+	s.logger.Info("Broadcast sent",
+		zap.String("correlation_id", event.CorrelationID),
+		zap.Int("delivered", delivered),
+		zap.Int("recipients", len(event.TelegramIDs)))
+
+	summary := fmt.Sprintf("📣 Broadcast delivered to %d of %d users.", delivered, len(event.TelegramIDs))
+	if err := s.SendMessage(common.ChatID(event.ChatID), summary); err != nil {
+		s.logger.Error("Failed to report broadcast", zap.Error(err))
+	}
+}
+
+// formatGlobalStats renders the deployment-wide counts for admins
+func formatGlobalStats(stats events.GlobalStats) string {
+	return fmt.Sprintf("📊 <b>Global Stats</b>\n\n"+
+		"👥 Users: %d (%d new this week)\n"+
+		"📋 Open tasks: %d\n"+
+		"➕ Tasks created this week: %d\n"+
+		"✅ Tasks completed this week: %d\n"+
+		"🔔 Reminders sent in the last 24 hours: %d",
+		stats.Users, stats.NewUsers, stats.OpenTasks, stats.TasksCreated, stats.TasksCompleted, stats.RemindersSent)
+}
+
+// handleGlobalStatsResponse sends the deployment-wide counts to the admin
+func (s *chatbotService) handleGlobalStatsResponse(event events.GlobalStatsResponse) {
+	if !s.ownsUser(event.UserID) {
+		return
+	}
+
+	text := formatGlobalStats(event.Stats)
+	if !event.Success {
+		text = "❌ " + html.EscapeString(event.Message)
+	}
+	if err := s.SendMessage(common.ChatID(event.ChatID), text); err != nil {
+		s.logger.Error("Failed to send global stats",
+			zap.String("correlation_id", event.CorrelationID),
+			zap.Error(err))
+	}
+}
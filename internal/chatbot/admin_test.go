@@ -0,0 +1,91 @@
+package chatbot
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"nudgebot-api/internal/config"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// broadcastProvider records the chats messages go to, failing for blocked ones
+type broadcastProvider struct {
+	listRecordingProvider
+	chats   []int64
+	blocked map[int64]bool
+}
+
+func (p *broadcastProvider) SendMessage(chatID int64, threadID int, text string) error {
+	if p.blocked[chatID] {
+		return errors.New("Forbidden: bot was blocked by the user")
+	}
+	p.chats = append(p.chats, chatID)
+	p.messages = append(p.messages, text)
+	return nil
+}
+
+func TestChatbotService_AdminCommandsAreAuthorized(t *testing.T) {
+	eventBus := events.NewMockEventBus()
+	eventBus.SetSynchronousMode(true)
+	chatbot, _ := newBenchService(eventBus, zaptest.NewLogger(t))
+	provider := &broadcastProvider{}
+	chatbot.provider = provider
+	authorizer, err := NewCommandAuthorizer(config.AuthorizationConfig{AdminIDs: []int64{4242}})
+	require.NoError(t, err)
+	chatbot.authorizer = authorizer
+
+	command := func(from int64) []byte {
+		return []byte(fmt.Sprintf(`{"update_id":1,"message":{"message_id":5,"from":{"id":%[1]d,"first_name":"Ann"},"chat":{"id":%[1]d,"type":"private"},`+
+			`"date":1,"text":"/globalstats","entities":[{"offset":0,"length":12,"type":"bot_command"}]}}`, from))
+	}
+
+	require.NoError(t, chatbot.HandleWebhook(command(5151)))
+	assert.Empty(t, eventBus.GetPublishedEvents(events.TopicGlobalStatsRequested))
+	require.NotEmpty(t, provider.messages)
+	assert.Equal(t, "⛔ You are not allowed to use /globalstats.", provider.messages[len(provider.messages)-1])
+
+	require.NoError(t, chatbot.HandleWebhook(command(4242)))
+	assert.Len(t, eventBus.GetPublishedEvents(events.TopicGlobalStatsRequested), 1)
+}
+
+func TestCommandProcessor_ProcessBroadcastCommand(t *testing.T) {
+	eventBus := events.NewMockEventBus()
+	processor := NewCommandProcessor(eventBus, zaptest.NewLogger(t))
+
+	response, err := processor.ProcessBroadcastCommand("admin", "42", "", "  ")
+	require.NoError(t, err)
+	assert.Contains(t, response, "Usage: /broadcast")
+	assert.Empty(t, eventBus.GetPublishedEvents(events.TopicBroadcastRequested))
+
+	_, err = processor.ProcessBroadcastCommand("admin", "42", "staging", "Maintenance tonight\nat 22:00")
+	require.NoError(t, err)
+	requests := eventBus.GetPublishedEvents(events.TopicBroadcastRequested)
+	require.Len(t, requests, 1)
+	request := requests[0].(events.BroadcastRequested)
+	assert.Equal(t, "staging", request.Bot)
+	assert.Equal(t, "Maintenance tonight\nat 22:00", request.Text)
+}
+
+func TestChatbotService_DeliverBroadcast(t *testing.T) {
+	chatbot, _ := newListTestService(t)
+	provider := &broadcastProvider{blocked: map[int64]bool{2: true}}
+	chatbot.provider = provider
+
+	chatbot.deliverBroadcast(events.BroadcastReady{UserID: "admin", ChatID: "1", Text: "New <features>", TelegramIDs: []int64{1, 2, 3}, Success: true}, 0)
+
+	assert.Equal(t, []int64{1, 3, 1}, provider.chats)
+	assert.Equal(t, "📣 New &lt;features&gt;", provider.messages[0])
+	assert.Equal(t, "📣 Broadcast delivered to 2 of 3 users.", provider.messages[2])
+}
+
+func TestFormatGlobalStats(t *testing.T) {
+	text := formatGlobalStats(events.GlobalStats{Users: 120, NewUsers: 8, OpenTasks: 431, TasksCreated: 95, TasksCompleted: 77, RemindersSent: 212})
+	assert.Contains(t, text, "👥 Users: 120 (8 new this week)")
+	assert.Contains(t, text, "📋 Open tasks: 431")
+	assert.Contains(t, text, "🔔 Reminders sent in the last 24 hours: 212")
+}
@@ -0,0 +1,43 @@
+package chatbot
+
+import (
+	"fmt"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// handleAPITokenResponse sends the token issued for /apitoken, or confirms revocation
+func (s *chatbotService) handleAPITokenResponse(event events.APITokenResponse) {
+	if !s.ownsUser(event.UserID) {
+		return
+	}
+
+	s.logger.Info("Handling APITokenResponse event",
+		zap.String("correlation_id", event.CorrelationID),
+		zap.String("user_id", event.UserID),
+		zap.Bool("revoke", event.Revoke),
+		zap.Bool("success", event.Success))
+
+	var text string
+	switch {
+	case !event.Success:
+		text = fmt.Sprintf("❌ <b>API Token Failed</b>\n\n%s", event.Message)
+	case event.Revoke && event.Revoked == 0:
+		text = "You have no API token to revoke."
+	case event.Revoke:
+		text = "🔒 Your API token was revoked. Extensions using it can no longer add tasks."
+	default:
+		text = fmt.Sprintf("🔑 <b>Your API Token</b>\n\n<code>%s</code>\n\n"+
+			"Use it as a Bearer token for POST /api/v1/quick-add. It replaces any earlier token "+
+			"and is shown only once; send /apitoken revoke if it leaks.", event.Token)
+	}
+
+	if err := s.SendMessage(common.ChatID(event.ChatID), text); err != nil {
+		s.logger.Error("Failed to send api token response",
+			zap.String("correlation_id", event.CorrelationID),
+			zap.Error(err))
+	}
+}
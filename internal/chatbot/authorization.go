@@ -0,0 +1,108 @@
+package chatbot
+
+import (
+	"fmt"
+	"strings"
+
+	"nudgebot-api/internal/config"
+)
+
+// RoleAdmin is the role of the users in the configured admin IDs. Admins may
+// run every restricted command.
+const RoleAdmin = "admin"
+
+// adminCommands are restricted to admins unless configured otherwise
+var adminCommands = []Command{CommandBroadcast, CommandGlobalStats, CommandMaintenance}
+
+// CommandAuthorizer decides which users may run restricted commands. Commands
+// that are not restricted are open to everyone.
+type CommandAuthorizer struct {
+	roles    map[int64]map[string]bool // Telegram user ID to roles
+	commands map[Command][]string      // restricted command to the roles allowed
+}
+
+// NewCommandAuthorizer builds the authorizer from configuration. The admin
+// commands stay restricted to admins unless the configuration names other
+// roles for them.
+func NewCommandAuthorizer(cfg config.AuthorizationConfig) (*CommandAuthorizer, error) {
+	authorizer := &CommandAuthorizer{
+		roles:    make(map[int64]map[string]bool),
+		commands: make(map[Command][]string),
+	}
+
+	grant := func(role string, telegramIDs []int64) {
+		for _, telegramID := range telegramIDs {
+			if authorizer.roles[telegramID] == nil {
+				authorizer.roles[telegramID] = make(map[string]bool)
+			}
+			authorizer.roles[telegramID][role] = true
+		}
+	}
+	grant(RoleAdmin, cfg.AdminIDs)
+	for role, telegramIDs := range cfg.Roles {
+		grant(strings.ToLower(role), telegramIDs)
+	}
+
+	for _, command := range adminCommands {
+		authorizer.commands[command] = []string{RoleAdmin}
+	}
+	for name, roles := range cfg.Commands {
+		command := Command("/" + strings.TrimPrefix(strings.ToLower(name), "/"))
+		if !command.IsValid() {
+			return nil, fmt.Errorf("authorization names unknown command %q", name)
+		}
+		allowed := make([]string, 0, len(roles))
+		for _, role := range roles {
+			role = strings.ToLower(role)
+			if _, known := cfg.Roles[role]; !known && role != RoleAdmin {
+				return nil, fmt.Errorf("authorization for %s names unknown role %q", command, role)
+			}
+			allowed = append(allowed, role)
+		}
+		authorizer.commands[command] = allowed
+	}
+
+	return authorizer, nil
+}
+
+// Restricted reports whether only some users may run the command
+func (a *CommandAuthorizer) Restricted(command Command) bool {
+	if a == nil {
+		return isAdminCommand(command)
+	}
+	_, restricted := a.commands[command]
+	return restricted
+}
+
+// Allowed reports whether the Telegram user may run the command. Without an
+// authorizer, admin commands are refused to everyone.
+func (a *CommandAuthorizer) Allowed(command Command, telegramID int64) bool {
+	if a == nil {
+		return !isAdminCommand(command)
+	}
+
+	allowed, restricted := a.commands[command]
+	if !restricted {
+		return true
+	}
+	roles := a.roles[telegramID]
+	if roles[RoleAdmin] {
+		return true
+	}
+	for _, role := range allowed {
+		if roles[role] {
+			return true
+		}
+	}
+	return false
+}
+
+// isAdminCommand reports whether the command is one of the admin commands
+func isAdminCommand(command Command) bool {
+	for _, admin := range adminCommands {
+		if command == admin {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package chatbot
+
+import (
+	"testing"
+
+	"nudgebot-api/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandAuthorizer_AdminCommands(t *testing.T) {
+	authorizer, err := NewCommandAuthorizer(config.AuthorizationConfig{AdminIDs: []int64{1}})
+	require.NoError(t, err)
+
+	assert.True(t, authorizer.Restricted(CommandBroadcast))
+	assert.True(t, authorizer.Allowed(CommandBroadcast, 1))
+	assert.False(t, authorizer.Allowed(CommandBroadcast, 2))
+	assert.False(t, authorizer.Allowed(CommandGlobalStats, 0), "messages without a sender are never admins")
+
+	assert.False(t, authorizer.Restricted(CommandList))
+	assert.True(t, authorizer.Allowed(CommandList, 2))
+}
+
+func TestCommandAuthorizer_ConfiguredRoles(t *testing.T) {
+	authorizer, err := NewCommandAuthorizer(config.AuthorizationConfig{
+		AdminIDs: []int64{1},
+		Roles:    map[string][]int64{"support": {2}},
+		Commands: map[string][]string{"globalstats": {"admin", "Support"}, "/plan": {"support"}},
+	})
+	require.NoError(t, err)
+
+	assert.True(t, authorizer.Allowed(CommandGlobalStats, 2))
+	assert.False(t, authorizer.Allowed(CommandBroadcast, 2), "broadcasts stay with admins")
+	assert.True(t, authorizer.Allowed(CommandPlan, 2))
+	assert.True(t, authorizer.Allowed(CommandPlan, 1), "admins may run every restricted command")
+	assert.False(t, authorizer.Allowed(CommandPlan, 3))
+}
+
+func TestNewCommandAuthorizer_RejectsUnknownNames(t *testing.T) {
+	_, err := NewCommandAuthorizer(config.AuthorizationConfig{Commands: map[string][]string{"launch": {"admin"}}})
+	assert.ErrorContains(t, err, `unknown command "launch"`)
+
+	_, err = NewCommandAuthorizer(config.AuthorizationConfig{Commands: map[string][]string{"plan": {"staff"}}})
+	assert.ErrorContains(t, err, `unknown role "staff"`)
+}
+
+func TestCommandAuthorizer_NilRefusesAdminCommands(t *testing.T) {
+	var authorizer *CommandAuthorizer
+	assert.False(t, authorizer.Allowed(CommandBroadcast, 1))
+	assert.True(t, authorizer.Allowed(CommandList, 1))
+}
@@ -0,0 +1,109 @@
+package chatbot
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// handleMyChatMember handles a change of the bot's membership in a chat. In a
+// private chat the user blocking the bot kicks it, and unblocking makes it a
+// member again; changes in groups are ignored.
+func (s *chatbotService) handleMyChatMember(member *tgbotapi.ChatMemberUpdated, correlationID string) error {
+	if !member.Chat.IsPrivate() {
+		return nil
+	}
+
+	blocked := member.NewChatMember.WasKicked()
+	if !blocked && !member.OldChatMember.WasKicked() {
+		return nil // e.g. the bot being started, which messages announce
+	}
+
+	userID, err := s.identities.Resolve(s.config.Name, member.From.ID)
+	if err != nil {
+		return WrapParsingError(err, "user_id")
+	}
+	chatID, err := s.identities.Resolve(s.config.Name, member.Chat.ID)
+	if err != nil {
+		return WrapParsingError(err, "chat_id")
+	}
+
+	if blocked {
+		err = s.eventBus.Publish(events.TopicBotBlocked, events.BotBlocked{
+			Event:     events.NewEvent(),
+			UserID:    userID,
+			ChatID:    chatID,
+			Bot:       s.config.Name,
+			BlockedAt: time.Unix(int64(member.Date), 0),
+		})
+	} else {
+		err = s.eventBus.Publish(events.TopicBotUnblocked, events.BotUnblocked{
+			Event:  events.NewEvent(),
+			UserID: userID,
+			ChatID: chatID,
+			Bot:    s.config.Name,
+		})
+	}
+	if err != nil {
+		s.logger.Error("Failed to publish bot block change",
+			zap.String("correlation_id", correlationID),
+			zap.Bool("blocked", blocked),
+			zap.Error(err))
+		return err
+	}
+
+	s.logger.Info("User changed whether the bot is blocked",
+		zap.String("correlation_id", correlationID),
+		zap.String("user_id", userID),
+		zap.Bool("blocked", blocked))
+	return nil
+}
+
+// formatWelcomeBack summarizes what accumulated while the user had the bot blocked
+func formatWelcomeBack(event events.WelcomeBack, dates DateFormat) string {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("👋 <b>Welcome back!</b> You were away for %s.", formatSpan(dates.now.Sub(event.AwaySince))))
+
+	if event.CameDueCount == 0 {
+		text.WriteString("\n\nNothing came due while you were away.")
+	} else {
+		text.WriteString(fmt.Sprintf("\n\n📅 <b>%s came due meanwhile</b>", pluralUnit(event.CameDueCount, "task")))
+		for _, task := range event.CameDue {
+			text.WriteString(fmt.Sprintf("\n• %s — %s", html.EscapeString(task.Title), dates.DateTime(*task.DueDate)))
+		}
+		if more := event.CameDueCount - len(event.CameDue); more > 0 {
+			text.WriteString(fmt.Sprintf("\n…and %d more", more))
+		}
+	}
+
+	if event.Added > 0 {
+		text.WriteString(fmt.Sprintf("\n\n➕ %s added for you", pluralUnit(event.Added, "task")))
+	}
+	text.WriteString(fmt.Sprintf("\n\n📋 %s open", pluralUnit(event.Open, "task")))
+	if event.Overdue > 0 {
+		text.WriteString(fmt.Sprintf(", %d overdue", event.Overdue))
+	}
+	text.WriteString(". Your reminders are back on — see everything with /list.")
+	return text.String()
+}
+
+// handleWelcomeBack sends the summary of what the user missed to their private chat
+func (s *chatbotService) handleWelcomeBack(event events.WelcomeBack) {
+	if !s.ownsUser(event.UserID) {
+		return
+	}
+
+	text := formatWelcomeBack(event, s.dateFormat(event.UserID))
+	if err := s.SendMessage(common.ChatID(event.ChatID), text); err != nil {
+		s.logger.Error("Failed to send welcome back summary",
+			zap.String("correlation_id", event.CorrelationID),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,68 @@
+package chatbot
+
+import (
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestChatbotService_PublishesBotBlockChanges(t *testing.T) {
+	eventBus := events.NewMockEventBus()
+	eventBus.SetSynchronousMode(true)
+	chatbot, _ := newBenchService(eventBus, zaptest.NewLogger(t))
+
+	userID, err := chatbot.identities.Resolve("", 4242)
+	require.NoError(t, err)
+
+	const blocked = `{"update_id":1,"my_chat_member":{"chat":{"id":4242,"type":"private"},"from":{"id":4242,"first_name":"Ann"},"date":1700000000,` +
+		`"old_chat_member":{"user":{"id":1,"is_bot":true,"first_name":"Nudge"},"status":"member"},"new_chat_member":{"user":{"id":1,"is_bot":true,"first_name":"Nudge"},"status":"kicked","until_date":0}}}`
+	require.NoError(t, chatbot.HandleWebhook([]byte(blocked)))
+
+	published := eventBus.GetPublishedEvents(events.TopicBotBlocked)
+	require.Len(t, published, 1)
+	event := published[0].(events.BotBlocked)
+	assert.Equal(t, userID, event.UserID)
+	assert.Equal(t, userID, event.ChatID, "private chats share their user's ID")
+	assert.Equal(t, time.Unix(1700000000, 0), event.BlockedAt)
+
+	const unblocked = `{"update_id":2,"my_chat_member":{"chat":{"id":4242,"type":"private"},"from":{"id":4242,"first_name":"Ann"},"date":1700090000,` +
+		`"old_chat_member":{"user":{"id":1,"is_bot":true,"first_name":"Nudge"},"status":"kicked","until_date":0},"new_chat_member":{"user":{"id":1,"is_bot":true,"first_name":"Nudge"},"status":"member"}}}`
+	require.NoError(t, chatbot.HandleWebhook([]byte(unblocked)))
+	require.Len(t, eventBus.GetPublishedEvents(events.TopicBotUnblocked), 1)
+
+	// The bot being removed from a group is not a user blocking it
+	const removed = `{"update_id":3,"my_chat_member":{"chat":{"id":-100123,"type":"supergroup"},"from":{"id":4242,"first_name":"Ann"},"date":1700090000,` +
+		`"old_chat_member":{"user":{"id":1,"is_bot":true,"first_name":"Nudge"},"status":"member"},"new_chat_member":{"user":{"id":1,"is_bot":true,"first_name":"Nudge"},"status":"kicked","until_date":0}}}`
+	require.NoError(t, chatbot.HandleWebhook([]byte(removed)))
+	assert.Len(t, eventBus.GetPublishedEvents(events.TopicBotBlocked), 1)
+}
+
+func TestFormatWelcomeBack(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	dates := NewDateFormat("UTC", "en", now)
+	due := time.Date(2024, 3, 9, 9, 0, 0, 0, time.UTC)
+
+	text := formatWelcomeBack(events.WelcomeBack{
+		AwaySince:    now.Add(-72 * time.Hour),
+		CameDue:      []events.TaskSummary{{Title: "Pay <rent>", DueDate: &due}},
+		CameDueCount: 3,
+		Added:        1,
+		Open:         5,
+		Overdue:      2,
+	}, dates)
+	assert.Contains(t, text, "You were away for 3 days")
+	assert.Contains(t, text, "3 tasks came due meanwhile")
+	assert.Contains(t, text, "Pay &lt;rent&gt;")
+	assert.Contains(t, text, "…and 2 more")
+	assert.Contains(t, text, "1 task added for you")
+	assert.Contains(t, text, "5 tasks open, 2 overdue")
+
+	quiet := formatWelcomeBack(events.WelcomeBack{AwaySince: now.Add(-2 * time.Hour), Open: 1}, dates)
+	assert.Contains(t, quiet, "Nothing came due while you were away")
+	assert.NotContains(t, quiet, "overdue")
+}
@@ -0,0 +1,168 @@
+package chatbot
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/config"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// botNamePattern keeps bot names usable as webhook path segments
+var botNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// BotConfigs returns the chatbot configuration of every additional bot. Each
+// bot gets its own token, webhook URL and receive mode and shares the rest of
+// the default bot's settings.
+func BotConfigs(cfg config.ChatbotConfig) ([]config.ChatbotConfig, error) {
+	seen := make(map[string]bool, len(cfg.Bots))
+	configs := make([]config.ChatbotConfig, 0, len(cfg.Bots))
+
+	for _, bot := range cfg.Bots {
+		if !botNamePattern.MatchString(bot.Name) {
+			return nil, fmt.Errorf("invalid bot name %q: use lowercase letters, digits, '-' and '_'", bot.Name)
+		}
+		if seen[bot.Name] {
+			return nil, fmt.Errorf("duplicate bot name %q", bot.Name)
+		}
+		if bot.Token == "" {
+			return nil, fmt.Errorf("bot %q has no token", bot.Name)
+		}
+		seen[bot.Name] = true
+
+		botCfg := cfg
+		botCfg.Name = bot.Name
+		botCfg.Token = bot.Token
+		botCfg.Bots = nil
+		if bot.WebhookURL != "" {
+			botCfg.WebhookURL = bot.WebhookURL
+		}
+		if bot.Mode != "" {
+			botCfg.Mode = bot.Mode
+		}
+		configs = append(configs, botCfg)
+	}
+
+	return configs, nil
+}
+
+// BotUser records which bot a user belongs to
+type BotUser struct {
+	UserID    common.UserID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Bot       string        `gorm:"not null;index" json:"bot"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// TableName returns the table name for the BotUser model
+func (BotUser) TableName() string {
+	return "bot_users"
+}
+
+// BotDirectory tracks which bot each user talks to, so events about a user
+// are delivered by that user's bot. Users the directory has never seen belong
+// to the default bot, named "".
+type BotDirectory interface {
+	Remember(userID common.UserID, bot string) error
+	BotFor(userID common.UserID) (string, error)
+}
+
+// memoryBotDirectory implements BotDirectory in memory
+type memoryBotDirectory struct {
+	mu    sync.RWMutex
+	users map[common.UserID]string
+}
+
+// NewMemoryBotDirectory creates a BotDirectory that is not persisted
+func NewMemoryBotDirectory() BotDirectory {
+	return &memoryBotDirectory{
+		users: make(map[common.UserID]string),
+	}
+}
+
+// Remember records the bot a user talks to
+func (d *memoryBotDirectory) Remember(userID common.UserID, bot string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.users[userID] = bot
+	return nil
+}
+
+// BotFor returns the bot a user talks to
+func (d *memoryBotDirectory) BotFor(userID common.UserID) (string, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.users[userID], nil
+}
+
+// gormBotDirectory implements BotDirectory using GORM, caching lookups
+type gormBotDirectory struct {
+	db     *gorm.DB
+	logger *zap.Logger
+	cache  *memoryBotDirectory
+}
+
+// NewGormBotDirectory creates a GORM-based bot directory
+func NewGormBotDirectory(db *gorm.DB, logger *zap.Logger) BotDirectory {
+	return &gormBotDirectory{
+		db:     db,
+		logger: logger,
+		cache:  NewMemoryBotDirectory().(*memoryBotDirectory),
+	}
+}
+
+// Remember records the bot a user talks to
+func (d *gormBotDirectory) Remember(userID common.UserID, bot string) error {
+	d.cache.mu.RLock()
+	known, ok := d.cache.users[userID]
+	d.cache.mu.RUnlock()
+	if ok && known == bot {
+		return nil
+	}
+
+	botUser := &BotUser{UserID: userID, Bot: bot}
+	err := d.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"bot"}),
+	}).Create(botUser).Error
+	if err != nil {
+		return fmt.Errorf("failed to remember bot user: %w", err)
+	}
+
+	d.logger.Debug("Bot user recorded",
+		zap.String("user_id", string(userID)),
+		zap.String("bot", bot))
+	return d.cache.Remember(userID, bot)
+}
+
+// BotFor returns the bot a user talks to
+func (d *gormBotDirectory) BotFor(userID common.UserID) (string, error) {
+	d.cache.mu.RLock()
+	bot, ok := d.cache.users[userID]
+	d.cache.mu.RUnlock()
+	if ok {
+		return bot, nil
+	}
+
+	var botUser BotUser
+	err := d.db.Where("user_id = ?", userID).Limit(1).Find(&botUser).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to look up bot user: %w", err)
+	}
+
+	// Unknown users are cached too; they belong to the default bot
+	return botUser.Bot, d.cache.Remember(userID, botUser.Bot)
+}
+
+// RunMigrations creates the chatbot tables
+func RunMigrations(db *gorm.DB) error {
+	if err := db.AutoMigrate(&BotUser{}, &TelegramIdentity{}); err != nil {
+		return fmt.Errorf("failed to auto-migrate chatbot tables: %w", err)
+	}
+	return nil
+}
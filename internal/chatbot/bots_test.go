@@ -0,0 +1,112 @@
+package chatbot
+
+import (
+	"testing"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/config"
+	"nudgebot-api/internal/events"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestBotConfigs(t *testing.T) {
+	base := config.ChatbotConfig{
+		Token:       "default-token",
+		WebhookURL:  "https://bot.example.com/api/v1/telegram/webhook",
+		TaskPreview: true,
+		Bots: []config.BotConfig{
+			{Name: "staging", Token: "staging-token", Mode: ReceiveModePolling},
+			{Name: "acme", Token: "acme-token", WebhookURL: "https://acme.example.com/api/v1/telegram/webhook/acme"},
+		},
+	}
+
+	configs, err := BotConfigs(base)
+	require.NoError(t, err)
+	require.Len(t, configs, 2)
+
+	assert.Equal(t, "staging", configs[0].Name)
+	assert.Equal(t, "staging-token", configs[0].Token)
+	assert.Equal(t, ReceiveModePolling, configs[0].Mode)
+	assert.Equal(t, base.WebhookURL, configs[0].WebhookURL)
+	assert.True(t, configs[0].TaskPreview)
+	assert.Nil(t, configs[0].Bots)
+
+	assert.Equal(t, "acme", configs[1].Name)
+	assert.Equal(t, "https://acme.example.com/api/v1/telegram/webhook/acme", configs[1].WebhookURL)
+
+	invalid := []config.BotConfig{
+		{Name: "", Token: "token"},
+		{Name: "Staging/1", Token: "token"},
+		{Name: "staging"},
+	}
+	for _, bot := range invalid {
+		_, err := BotConfigs(config.ChatbotConfig{Bots: []config.BotConfig{bot}})
+		assert.Error(t, err, "bot %+v", bot)
+	}
+
+	_, err = BotConfigs(config.ChatbotConfig{Bots: []config.BotConfig{
+		{Name: "staging", Token: "one"},
+		{Name: "staging", Token: "two"},
+	}})
+	assert.Error(t, err)
+}
+
+func TestWebhookParser_ScopesUsersPerBot(t *testing.T) {
+	update := &tgbotapi.Update{
+		UpdateID: 1,
+		Message: &tgbotapi.Message{
+			From: &tgbotapi.User{ID: 12345},
+			Chat: &tgbotapi.Chat{ID: 12345},
+		},
+	}
+
+	defaultID, err := NewWebhookParser().GetUserID(update)
+	require.NoError(t, err)
+	unnamedID, err := NewWebhookParserForBot("").GetUserID(update)
+	require.NoError(t, err)
+	stagingID, err := NewWebhookParserForBot("staging").GetUserID(update)
+	require.NoError(t, err)
+	acmeID, err := NewWebhookParserForBot("acme").GetUserID(update)
+	require.NoError(t, err)
+
+	assert.Equal(t, defaultID, unnamedID, "the default bot keeps existing user IDs")
+	assert.NotEqual(t, defaultID, stagingID)
+	assert.NotEqual(t, stagingID, acmeID)
+	assert.True(t, common.ID(stagingID).IsValid())
+}
+
+func TestChatbotService_DeliversOnlyOwnBotsEvents(t *testing.T) {
+	directory := NewMemoryBotDirectory()
+	require.NoError(t, directory.Remember("staging-user", "staging"))
+
+	defaultBot := &chatbotService{logger: zaptest.NewLogger(t), directory: directory}
+	stagingBot := &chatbotService{logger: zaptest.NewLogger(t), directory: directory, config: config.ChatbotConfig{Name: "staging"}}
+
+	assert.True(t, stagingBot.ownsUser("staging-user"))
+	assert.False(t, defaultBot.ownsUser("staging-user"))
+
+	// Users the directory has not seen belong to the default bot
+	assert.True(t, defaultBot.ownsUser("legacy-user"))
+	assert.False(t, stagingBot.ownsUser("legacy-user"))
+
+	// Without a directory every event is delivered
+	assert.True(t, (&chatbotService{config: config.ChatbotConfig{Name: "staging"}}).ownsUser("legacy-user"))
+}
+
+func TestChatbotService_IgnoresOtherBotsUpdates(t *testing.T) {
+	recorder := &updateRecorder{}
+	service := &chatbotService{
+		config:  config.ChatbotConfig{Name: "staging"},
+		updates: NewUpdateQueue(10, zaptest.NewLogger(t), recorder.process),
+	}
+
+	service.handleUpdateReceived(events.UpdateReceived{UpdateID: 1})
+	service.handleUpdateReceived(events.UpdateReceived{UpdateID: 2, Bot: "acme"})
+	service.handleUpdateReceived(events.UpdateReceived{UpdateID: 3, Bot: "staging"})
+
+	assert.Equal(t, 1, service.updates.Len())
+}
@@ -0,0 +1,40 @@
+package chatbot
+
+import (
+	"net/url"
+	"time"
+)
+
+// calendarEventLength is the length of the calendar event created for a task
+const calendarEventLength = 30 * time.Minute
+
+// maxCalendarTitleLength keeps calendar links well inside Telegram's URL limits
+const maxCalendarTitleLength = 100
+
+// calendarTimeFormat is the UTC timestamp format of calendar template links
+const calendarTimeFormat = "20060102T150405Z"
+
+// hasConcreteTime reports whether a due date names a time of day. Tasks due on
+// a day without a time are stored at midnight and get no calendar link.
+func hasConcreteTime(due *time.Time) bool {
+	if due == nil {
+		return false
+	}
+	return due.Hour() != 0 || due.Minute() != 0
+}
+
+// calendarLink returns an "Add to calendar" link that opens a prefilled event
+// in Google Calendar, or "" when the task has no concrete due time
+func calendarLink(title string, due *time.Time) string {
+	if title == "" || !hasConcreteTime(due) {
+		return ""
+	}
+
+	start := due.UTC()
+	query := url.Values{}
+	query.Set("action", "TEMPLATE")
+	query.Set("text", truncateText(title, maxCalendarTitleLength))
+	query.Set("dates", start.Format(calendarTimeFormat)+"/"+start.Add(calendarEventLength).Format(calendarTimeFormat))
+
+	return "https://calendar.google.com/calendar/render?" + query.Encode()
+}
@@ -0,0 +1,40 @@
+package chatbot
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalendarLink(t *testing.T) {
+	due := time.Date(2024, 3, 4, 15, 30, 0, 0, time.FixedZone("CET", 3600))
+
+	link := calendarLink("Dentist & checkup", &due)
+	parsed, err := url.Parse(link)
+	require.NoError(t, err)
+	assert.Equal(t, "calendar.google.com", parsed.Host)
+	assert.Equal(t, "Dentist & checkup", parsed.Query().Get("text"))
+	assert.Equal(t, "20240304T143000Z/20240304T150000Z", parsed.Query().Get("dates"))
+
+	midnight := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	assert.Empty(t, calendarLink("Dentist", &midnight), "date-only tasks get no link")
+	assert.Empty(t, calendarLink("Dentist", nil))
+}
+
+func TestBuildTaskActionKeyboardWithCalendar(t *testing.T) {
+	kb := NewKeyboardBuilder()
+
+	plain := kb.BuildTaskActionKeyboard("task-1")
+	withCalendar := kb.BuildTaskActionKeyboardWithCalendar("task-1", "https://calendar.google.com/calendar/render")
+	require.Len(t, withCalendar.InlineKeyboard, len(plain.InlineKeyboard)+1)
+
+	last := withCalendar.InlineKeyboard[len(withCalendar.InlineKeyboard)-1]
+	require.Len(t, last, 1)
+	require.NotNil(t, last[0].URL)
+	assert.Equal(t, "https://calendar.google.com/calendar/render", *last[0].URL)
+
+	assert.Equal(t, plain, kb.BuildTaskActionKeyboardWithCalendar("task-1", ""))
+}
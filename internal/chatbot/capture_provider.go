@@ -0,0 +1,93 @@
+package chatbot
+
+import (
+	"context"
+	"time"
+
+	"nudgebot-api/internal/debugcapture"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// captureTelegramProvider decorates a TelegramProvider by recording the Bot API
+// message calls made for sampled chats. Chat actions, webhook management and
+// polling are not recorded; polled updates are captured when they are handled.
+type captureTelegramProvider struct {
+	next     TelegramProvider
+	recorder *debugcapture.Recorder
+	bot      string
+}
+
+// NewCaptureTelegramProvider wraps a provider with debug capture. A nil
+// recorder returns the provider unchanged.
+func NewCaptureTelegramProvider(next TelegramProvider, recorder *debugcapture.Recorder, bot string) TelegramProvider {
+	if recorder == nil {
+		return next
+	}
+	return &captureTelegramProvider{next: next, recorder: recorder, bot: bot}
+}
+
+// messageParams mirrors the Bot API parameters of the message calls
+type messageParams struct {
+	ChatID      int64                          `json:"chat_id"`
+	ThreadID    int                            `json:"message_thread_id,omitempty"`
+	MessageID   int                            `json:"message_id,omitempty"`
+	Text        string                         `json:"text"`
+	ReplyMarkup *tgbotapi.InlineKeyboardMarkup `json:"reply_markup,omitempty"`
+}
+
+func (p *captureTelegramProvider) record(method string, chatID int64, params interface{}, started time.Time, err error) {
+	p.recorder.RecordCall(p.bot, method, chatID, params, err, time.Since(started))
+}
+
+func (p *captureTelegramProvider) SendMessage(chatID int64, threadID int, text string) error {
+	started := time.Now()
+	err := p.next.SendMessage(chatID, threadID, text)
+	p.record("sendMessage", chatID, messageParams{ChatID: chatID, ThreadID: threadID, Text: text}, started, err)
+	return err
+}
+
+func (p *captureTelegramProvider) SendMessageWithKeyboard(chatID int64, threadID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
+	started := time.Now()
+	err := p.next.SendMessageWithKeyboard(chatID, threadID, text, keyboard)
+	p.record("sendMessage", chatID, messageParams{ChatID: chatID, ThreadID: threadID, Text: text, ReplyMarkup: &keyboard}, started, err)
+	return err
+}
+
+func (p *captureTelegramProvider) SendTrackedMessage(chatID int64, threadID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) (int, error) {
+	started := time.Now()
+	messageID, err := p.next.SendTrackedMessage(chatID, threadID, text, keyboard)
+	p.record("sendMessage", chatID, messageParams{ChatID: chatID, ThreadID: threadID, MessageID: messageID, Text: text, ReplyMarkup: &keyboard}, started, err)
+	return messageID, err
+}
+
+func (p *captureTelegramProvider) EditMessageWithKeyboard(chatID int64, messageID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
+	started := time.Now()
+	err := p.next.EditMessageWithKeyboard(chatID, messageID, text, keyboard)
+	p.record("editMessageText", chatID, messageParams{ChatID: chatID, MessageID: messageID, Text: text, ReplyMarkup: &keyboard}, started, err)
+	return err
+}
+
+func (p *captureTelegramProvider) SendChatAction(chatID int64, action string) error {
+	return p.next.SendChatAction(chatID, action)
+}
+
+func (p *captureTelegramProvider) SetWebhook(webhookURL string) error {
+	return p.next.SetWebhook(webhookURL)
+}
+
+func (p *captureTelegramProvider) DeleteWebhook() error {
+	return p.next.DeleteWebhook()
+}
+
+func (p *captureTelegramProvider) GetWebhookInfo() (tgbotapi.WebhookInfo, error) {
+	return p.next.GetWebhookInfo()
+}
+
+func (p *captureTelegramProvider) GetMe() (*tgbotapi.User, error) {
+	return p.next.GetMe()
+}
+
+func (p *captureTelegramProvider) GetUpdates(ctx context.Context, offset, timeout int) ([]RawUpdate, error) {
+	return p.next.GetUpdates(ctx, offset, timeout)
+}
@@ -0,0 +1,115 @@
+package chatbot
+
+import (
+	"context"
+	"fmt"
+
+	"nudgebot-api/internal/chaos"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// chaosTelegramProvider decorates a TelegramProvider with injected rate limit
+// and server errors shaped like the ones the Bot API returns
+type chaosTelegramProvider struct {
+	next     TelegramProvider
+	injector *chaos.Injector
+}
+
+// NewChaosTelegramProvider wraps a provider with fault injection. A nil
+// injector returns the provider unchanged.
+func NewChaosTelegramProvider(next TelegramProvider, injector *chaos.Injector) TelegramProvider {
+	if injector == nil {
+		return next
+	}
+	return &chaosTelegramProvider{next: next, injector: injector}
+}
+
+// fault returns an injected Bot API error for the operation, if any
+func (p *chaosTelegramProvider) fault(operation string) error {
+	if p.injector.Should(chaos.FaultTelegramRateLimit) {
+		apiErr := &tgbotapi.Error{
+			Code:    429,
+			Message: "Too Many Requests: retry after 1",
+			ResponseParameters: tgbotapi.ResponseParameters{
+				RetryAfter: 1,
+			},
+		}
+		return fmt.Errorf("%w: %w", p.injector.Error(chaos.FaultTelegramRateLimit, operation), apiErr)
+	}
+	if p.injector.Should(chaos.FaultTelegramServerError) {
+		apiErr := &tgbotapi.Error{Code: 502, Message: "Bad Gateway"}
+		return fmt.Errorf("%w: %w", p.injector.Error(chaos.FaultTelegramServerError, operation), apiErr)
+	}
+	return nil
+}
+
+func (p *chaosTelegramProvider) SendMessage(chatID int64, threadID int, text string) error {
+	if err := p.fault("SendMessage"); err != nil {
+		return err
+	}
+	return p.next.SendMessage(chatID, threadID, text)
+}
+
+func (p *chaosTelegramProvider) SendMessageWithKeyboard(chatID int64, threadID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
+	if err := p.fault("SendMessageWithKeyboard"); err != nil {
+		return err
+	}
+	return p.next.SendMessageWithKeyboard(chatID, threadID, text, keyboard)
+}
+
+func (p *chaosTelegramProvider) SendTrackedMessage(chatID int64, threadID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) (int, error) {
+	if err := p.fault("SendTrackedMessage"); err != nil {
+		return 0, err
+	}
+	return p.next.SendTrackedMessage(chatID, threadID, text, keyboard)
+}
+
+func (p *chaosTelegramProvider) EditMessageWithKeyboard(chatID int64, messageID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
+	if err := p.fault("EditMessageWithKeyboard"); err != nil {
+		return err
+	}
+	return p.next.EditMessageWithKeyboard(chatID, messageID, text, keyboard)
+}
+
+func (p *chaosTelegramProvider) SendChatAction(chatID int64, action string) error {
+	if err := p.fault("SendChatAction"); err != nil {
+		return err
+	}
+	return p.next.SendChatAction(chatID, action)
+}
+
+func (p *chaosTelegramProvider) SetWebhook(webhookURL string) error {
+	if err := p.fault("SetWebhook"); err != nil {
+		return err
+	}
+	return p.next.SetWebhook(webhookURL)
+}
+
+func (p *chaosTelegramProvider) DeleteWebhook() error {
+	if err := p.fault("DeleteWebhook"); err != nil {
+		return err
+	}
+	return p.next.DeleteWebhook()
+}
+
+func (p *chaosTelegramProvider) GetWebhookInfo() (tgbotapi.WebhookInfo, error) {
+	if err := p.fault("GetWebhookInfo"); err != nil {
+		return tgbotapi.WebhookInfo{}, err
+	}
+	return p.next.GetWebhookInfo()
+}
+
+func (p *chaosTelegramProvider) GetMe() (*tgbotapi.User, error) {
+	if err := p.fault("GetMe"); err != nil {
+		return nil, err
+	}
+	return p.next.GetMe()
+}
+
+func (p *chaosTelegramProvider) GetUpdates(ctx context.Context, offset, timeout int) ([]RawUpdate, error) {
+	if err := p.fault("GetUpdates"); err != nil {
+		return nil, err
+	}
+	return p.next.GetUpdates(ctx, offset, timeout)
+}
@@ -0,0 +1,70 @@
+package chatbot
+
+import (
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// handleChatMigration handles the service messages Telegram sends when a
+// group is upgraded to a supergroup, which gets a new chat ID: the old group
+// receives migrate_to_chat_id and the supergroup migrate_from_chat_id. Either
+// moves the group's tasks, reminders and sessions to the supergroup; moving
+// twice is harmless. It reports whether the update was such a message.
+func (s *chatbotService) handleChatMigration(update *tgbotapi.Update, correlationID string) (bool, error) {
+	message := update.Message
+	if message == nil || message.Chat == nil {
+		return false, nil
+	}
+
+	from, to := message.Chat.ID, message.MigrateToChatID
+	if to == 0 {
+		if message.MigrateFromChatID == 0 {
+			return false, nil
+		}
+		from, to = message.MigrateFromChatID, message.Chat.ID
+	}
+
+	fromChatID, err := s.identities.Resolve(s.config.Name, from)
+	if err != nil {
+		return true, WrapParsingError(err, "chat_id")
+	}
+	toChatID, err := s.identities.Resolve(s.config.Name, to)
+	if err != nil {
+		return true, WrapParsingError(err, "chat_id")
+	}
+
+	s.moveChat(fromChatID, toChatID)
+
+	migrated := events.ChatMigrated{
+		Event:              events.NewEvent(),
+		Bot:                s.config.Name,
+		FromChatID:         fromChatID,
+		ToChatID:           toChatID,
+		FromTelegramChatID: from,
+		ToTelegramChatID:   to,
+	}
+	if err := s.eventBus.Publish(events.TopicChatMigrated, migrated); err != nil {
+		s.logger.Error("Failed to publish ChatMigrated event",
+			zap.String("correlation_id", correlationID),
+			zap.Error(err))
+		return true, err
+	}
+
+	s.logger.Info("Group migrated to a supergroup",
+		zap.String("correlation_id", correlationID),
+		zap.Int64("from_telegram_chat_id", from),
+		zap.Int64("to_telegram_chat_id", to))
+	return true, nil
+}
+
+// moveChat points the sessions of a migrated chat at its new chat and forgets
+// the messages tracked in the old one, which can no longer be edited
+func (s *chatbotService) moveChat(from, to string) {
+	s.commandProcessor.sessionManager.MoveChat(common.ChatID(from), common.ChatID(to))
+	s.listMessages.Delete(from)
+	s.reminderMessages.Forget(from)
+	s.threads.Set(from, 0)
+}
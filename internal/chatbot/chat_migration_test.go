@@ -0,0 +1,64 @@
+package chatbot
+
+import (
+	"testing"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestChatbotService_HandlesSupergroupMigration(t *testing.T) {
+	tests := []struct {
+		name   string
+		update string
+	}{
+		{"old group", `{"update_id":1,"message":{"message_id":9,"from":{"id":4242,"first_name":"Ann"},"chat":{"id":-123,"type":"group"},"date":1,"migrate_to_chat_id":-100123}}`},
+		{"new supergroup", `{"update_id":2,"message":{"message_id":1,"chat":{"id":-100123,"type":"supergroup"},"date":1,"migrate_from_chat_id":-123}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eventBus := events.NewMockEventBus()
+			eventBus.SetSynchronousMode(true)
+			chatbot, _ := newBenchService(eventBus, zaptest.NewLogger(t))
+
+			group, err := chatbot.identities.Resolve("", -123)
+			require.NoError(t, err)
+			supergroup, err := chatbot.identities.Resolve("", -100123)
+			require.NoError(t, err)
+
+			sessions := chatbot.commandProcessor.sessionManager
+			sessions.UpdateSession("user-1", group, func(session *ChatSession) { session.State = SessionStateIdle })
+			chatbot.listMessages.Set(group, listMessage{MessageID: 5})
+			chatbot.reminderMessages.Track(group, 6, "task-1")
+			chatbot.threads.Set(group, 3)
+
+			require.NoError(t, chatbot.HandleWebhook([]byte(tt.update)))
+
+			published := eventBus.GetPublishedEvents(events.TopicChatMigrated)
+			require.Len(t, published, 1)
+			migrated := published[0].(events.ChatMigrated)
+			assert.Equal(t, group, migrated.FromChatID)
+			assert.Equal(t, supergroup, migrated.ToChatID)
+			assert.Equal(t, int64(-123), migrated.FromTelegramChatID)
+			assert.Equal(t, int64(-100123), migrated.ToTelegramChatID)
+
+			session, ok := sessions.GetSession("user-1")
+			require.True(t, ok)
+			assert.Equal(t, common.ChatID(supergroup), session.ChatID)
+
+			// Messages in the old group can no longer be edited or reacted to
+			_, ok = chatbot.listMessages.Get(group)
+			assert.False(t, ok)
+			_, ok = chatbot.reminderMessages.TaskFor(group, 6)
+			assert.False(t, ok)
+			assert.Equal(t, 0, chatbot.threads.Get(group))
+
+			assert.Empty(t, eventBus.GetPublishedEvents(events.TopicMessageReceived), "the service message is not a task")
+		})
+	}
+}
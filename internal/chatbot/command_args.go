@@ -0,0 +1,154 @@
+package chatbot
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"nudgebot-api/internal/common"
+)
+
+// usageError is a problem with a command's arguments. It is shown to the
+// user as the reply, followed by the command's usage.
+type usageError struct {
+	problem string
+	usage   string
+}
+
+func (e usageError) Error() string {
+	if e.usage == "" {
+		return e.problem
+	}
+	return e.problem + "\n" + e.usage
+}
+
+// usageReply turns a usage error into the reply for the user; other errors
+// are returned as they are
+func usageReply(err error) (string, error) {
+	var usage usageError
+	if errors.As(err, &usage) {
+		return usage.Error(), nil
+	}
+	return "", err
+}
+
+// isQuote reports whether r opens or closes a quoted argument; phones often
+// type curly quotes
+func isQuote(r rune) bool {
+	return r == '"' || r == '“' || r == '”'
+}
+
+// splitArgs splits a command's arguments on spaces, keeping text in double
+// quotes together, so that /done "Buy milk" names one task
+func splitArgs(raw string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		quoted  bool
+		started bool
+	)
+	for _, r := range raw {
+		switch {
+		case isQuote(r):
+			quoted = !quoted
+			started = true
+		case unicode.IsSpace(r) && !quoted:
+			if started {
+				args = append(args, current.String())
+				current.Reset()
+				started = false
+			}
+		default:
+			current.WriteRune(r)
+			started = true
+		}
+	}
+	if quoted {
+		return nil, usageError{problem: "A quote is missing its closing \"."}
+	}
+	if started {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// commandArgs reads a command's arguments in order, reporting missing or
+// malformed ones as usage errors
+type commandArgs struct {
+	usage string
+	args  []string
+}
+
+// newCommandArgs reads args, explaining problems with usage
+func newCommandArgs(usage string, args []string) *commandArgs {
+	return &commandArgs{usage: usage, args: args}
+}
+
+// empty reports whether every argument has been read
+func (a *commandArgs) empty() bool {
+	return len(a.args) == 0
+}
+
+// fail returns a usage error
+func (a *commandArgs) fail(format string, values ...interface{}) error {
+	return usageError{problem: fmt.Sprintf(format, values...), usage: a.usage}
+}
+
+// next reads one argument, named in the error when it is missing
+func (a *commandArgs) next(name string) (string, error) {
+	if a.empty() {
+		return "", a.fail("Please add the %s.", name)
+	}
+	arg := a.args[0]
+	a.args = a.args[1:]
+	return arg, nil
+}
+
+// task reads a task reference: a code such as T-3, a quoted title or an ID
+func (a *commandArgs) task() (string, error) {
+	return a.next("task, such as T-3 or \"Buy milk\"")
+}
+
+// duration reads a duration such as 30m, 2h or 1d
+func (a *commandArgs) duration() (time.Duration, error) {
+	arg, err := a.next("duration, such as 2h")
+	if err != nil {
+		return 0, err
+	}
+	duration, err := common.ParseDuration(arg)
+	if err != nil {
+		return 0, a.fail("%q is not a duration; use one such as 30m, 2h or 1d.", arg)
+	}
+	return duration, nil
+}
+
+// when reads the rest of the arguments as a duration or a time, such as 2h or
+// tomorrow 9am. The expression is returned as typed, for the nudge service
+// to resolve in the user's timezone; only its form is checked here.
+func (a *commandArgs) when() (string, error) {
+	expression := a.rest()
+	if expression == "" {
+		return "", a.fail("Please add when, such as 2h or tomorrow 9am.")
+	}
+	if _, err := common.ParseWhen(expression, time.Now()); err != nil && !errors.Is(err, common.ErrWhenPassed) {
+		return "", a.fail("I can't tell when %q is; use %s.", expression, common.WhenUsage)
+	}
+	return expression, nil
+}
+
+// rest reads the remaining arguments joined by spaces
+func (a *commandArgs) rest() string {
+	rest := strings.Join(a.args, " ")
+	a.args = nil
+	return rest
+}
+
+// done returns a usage error when arguments are left over
+func (a *commandArgs) done() error {
+	if a.empty() {
+		return nil
+	}
+	return a.fail("I didn't expect %q. Put titles with spaces in quotes.", strings.Join(a.args, " "))
+}
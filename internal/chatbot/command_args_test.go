@@ -0,0 +1,60 @@
+package chatbot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitArgs(t *testing.T) {
+	args, err := splitArgs(`  "Buy milk"  tomorrow 9am `)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Buy milk", "tomorrow", "9am"}, args)
+
+	args, err = splitArgs("“Call mom” T-3 \"\"")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Call mom", "T-3", ""}, args)
+
+	args, err = splitArgs("")
+	require.NoError(t, err)
+	assert.Empty(t, args)
+
+	_, err = splitArgs(`"Buy milk`)
+	assert.ErrorContains(t, err, "closing")
+}
+
+func TestCommandArgs_UsageErrors(t *testing.T) {
+	_, err := newCommandArgs(doneUsage, nil).task()
+	reply, replyErr := usageReply(err)
+	require.NoError(t, replyErr)
+	assert.Contains(t, reply, "Please add the task")
+	assert.Contains(t, reply, "Usage: /done")
+
+	parsed := newCommandArgs(doneUsage, []string{"Buy", "milk"})
+	_, err = parsed.task()
+	require.NoError(t, err)
+	assert.ErrorContains(t, parsed.done(), `I didn't expect "milk"`)
+
+	_, err = newCommandArgs("", []string{"soon"}).duration()
+	assert.ErrorContains(t, err, `"soon" is not a duration`)
+
+	duration, err := newCommandArgs("", []string{"90m"}).duration()
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Minute, duration)
+}
+
+func TestCommandArgs_When(t *testing.T) {
+	when, err := newCommandArgs(snoozeUsage, []string{"tomorrow", "9am"}).when()
+	require.NoError(t, err)
+	assert.Equal(t, "tomorrow 9am", when)
+
+	// Whether a time has passed depends on the user's timezone, checked later
+	when, err = newCommandArgs(snoozeUsage, []string{"today", "0:01"}).when()
+	require.NoError(t, err)
+	assert.Equal(t, "today 0:01", when)
+
+	_, err = newCommandArgs(snoozeUsage, []string{"whenever"}).when()
+	assert.ErrorContains(t, err, `I can't tell when "whenever" is`)
+}
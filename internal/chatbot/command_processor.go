@@ -18,14 +18,25 @@ type CommandProcessor struct {
 	eventBus       events.EventBus
 	logger         *zap.Logger
 	sessionManager *SessionManager
+	taskCodes      *taskCodes
+	codeResolver   TaskCodeResolver
 }
 
 // NewCommandProcessor creates a new CommandProcessor instance
 func NewCommandProcessor(eventBus events.EventBus, logger *zap.Logger) *CommandProcessor {
+	return NewCommandProcessorWithTaskCodes(eventBus, logger, nil)
+}
+
+// NewCommandProcessorWithTaskCodes creates a CommandProcessor that looks up
+// task codes it has not shown with codes. A nil resolver only knows the codes
+// shown since the process started.
+func NewCommandProcessorWithTaskCodes(eventBus events.EventBus, logger *zap.Logger, codes TaskCodeResolver) *CommandProcessor {
 	return &CommandProcessor{
 		eventBus:       eventBus,
 		logger:         logger,
 		sessionManager: NewSessionManager(),
+		taskCodes:      newTaskCodes(),
+		codeResolver:   codes,
 	}
 }
 
@@ -89,18 +100,38 @@ func (cp *CommandProcessor) ProcessHelpCommand(userID, chatID string) (string, e
 /start - Start or restart the bot
 /help - Show this help message
 /list - Show your active tasks
-/done [task] - Mark a task as complete
-/delete [task] - Delete a task
+/task [T-3] - Show a task with its reminders and history
+/done [T-3] - Mark a task as complete, by the code shown in /list
+/delete [T-3] - Delete a task
+/snooze [T-3] [2h|tomorrow 9am] - Snooze a task, for an hour unless you say until when
+/testreminder [task] - Send a test reminder for a task
+/invite [editor|viewer|owner] - Invite people to this chat's shared tasks
+/field [task] [key] [type] [value] - Set a custom field (text, number, date, enum:a|b|c) or clear it with "clear"
+/snoozeall [2h] - Push back all of today's remaining tasks
+/moveto [tomorrow|monday|2024-06-01] - Move today's remaining tasks to another day
+/apitoken [revoke] - Get a token for the quick-add browser extension, or revoke it
+/stats - See your week, including the tasks you keep postponing
+/newlist [name] - Create a list to share with family or your team
+/lists - Show your shared lists and their invite links
+/addto [list]: [task] - Add a task to a shared list
+/delegate [task] @username - Hand a task off to someone else once they accept
+/plan - Get a schedule for the rest of today's tasks
+/retention [forever|90d|30d] - Choose how long completed tasks are kept
+/settings - Change your reminder interval, quiet hours, timezone and language
 
 <b>How to use:</b>
 • Send any message to create a new task
 • Use the inline buttons to manage your tasks
+• React 👍 to a reminder to complete the task, or 💤 to snooze it
+• Say "send me ..." with a time to get a message then instead of a task
+• Tap ⏳ Countdown on a task due within hours to watch the time left
 • Tasks are automatically parsed from your messages
 
 <b>Examples:</b>
 "Meeting with John tomorrow at 3pm"
 "Finish project report by Friday"
 "Buy milk and bread"
+"Send me the gate code at 5pm"
 
 The bot will extract the task details and ask for confirmation before adding them to your list.`
 
@@ -113,16 +144,52 @@ func (cp *CommandProcessor) ProcessListCommand(userID, chatID string) error {
 		zap.String("user_id", userID),
 		zap.String("chat_id", chatID))
 
-	// Publish task list requested event
+	cp.requestTaskList(userID, chatID, 0, 0)
+	return nil
+}
+
+// requestTaskList asks the nudge service for a fresh task list to show at
+// page, updating the list message messageID (0 for the chat's tracked one)
+func (cp *CommandProcessor) requestTaskList(userID, chatID string, page, messageID int) {
 	listEvent := events.TaskListRequested{
-		Event:  events.NewEvent(),
-		UserID: userID,
-		ChatID: chatID,
+		Event:     events.NewEvent(),
+		UserID:    userID,
+		ChatID:    chatID,
+		Page:      page,
+		MessageID: messageID,
 	}
 
 	cp.eventBus.Publish(events.TopicTaskListRequested, listEvent)
+}
 
-	return nil
+// Usage of the commands that act on one task
+const (
+	doneUsage         = "Usage: /done [task], e.g. /done T-3 or /done \"Buy milk\", or use the buttons under /list"
+	deleteUsage       = "Usage: /delete [task], e.g. /delete T-3 or /delete \"Buy milk\""
+	snoozeUsage       = "Usage: /snooze [task] [when], e.g. /snooze T-3, /snooze T-3 2h or /snooze \"Buy milk\" tomorrow 9am"
+	testReminderUsage = "Usage: /testreminder [task], e.g. /testreminder T-3"
+	taskUsage         = "Usage: /task [task], e.g. /task T-3 or /task \"Buy milk\""
+)
+
+// requestTaskAction asks the nudge service to apply action to the task arg
+// names. It returns a reply for the user when the task cannot be resolved.
+func (cp *CommandProcessor) requestTaskAction(userID, chatID, action, arg, argument string) string {
+	taskID, reply := cp.resolveTaskID(userID, arg)
+	if reply != "" {
+		return reply
+	}
+
+	actionEvent := events.TaskActionRequested{
+		Event:    events.NewEvent(),
+		UserID:   userID,
+		ChatID:   chatID,
+		TaskID:   taskID,
+		Action:   action,
+		Argument: argument,
+	}
+
+	cp.eventBus.Publish(events.TopicTaskActionRequested, actionEvent)
+	return ""
 }
 
 // ProcessDoneCommand handles the /done command
@@ -132,51 +199,430 @@ func (cp *CommandProcessor) ProcessDoneCommand(userID, chatID string, args []str
 		zap.String("chat_id", chatID),
 		zap.Strings("args", args))
 
-	if len(args) == 0 {
-		return "Please specify a task ID or use the inline buttons to mark tasks as complete.", nil
+	parsed := newCommandArgs(doneUsage, args)
+	task, err := parsed.task()
+	if err == nil {
+		err = parsed.done()
+	}
+	if err != nil {
+		return usageReply(err)
 	}
 
-	taskID := args[0]
+	if reply := cp.requestTaskAction(userID, chatID, "done", task, ""); reply != "" {
+		return reply, nil
+	}
+	return fmt.Sprintf("Marking task %s as complete...", taskLabel(task)), nil
+}
 
-	// Publish task action requested event
-	actionEvent := events.TaskActionRequested{
+// ProcessDeleteCommand handles the /delete command
+func (cp *CommandProcessor) ProcessDeleteCommand(userID, chatID string, args []string) (string, error) {
+	cp.logger.Info("Processing delete command",
+		zap.String("user_id", userID),
+		zap.String("chat_id", chatID),
+		zap.Strings("args", args))
+
+	parsed := newCommandArgs(deleteUsage, args)
+	task, err := parsed.task()
+	if err == nil {
+		err = parsed.done()
+	}
+	if err != nil {
+		return usageReply(err)
+	}
+
+	if reply := cp.requestTaskAction(userID, chatID, "delete", task, ""); reply != "" {
+		return reply, nil
+	}
+	return fmt.Sprintf("Deleting task %s...", taskLabel(task)), nil
+}
+
+// ProcessSnoozeCommand handles the /snooze command. Without a duration or
+// time the nudge service snoozes the task for an hour.
+func (cp *CommandProcessor) ProcessSnoozeCommand(userID, chatID string, args []string) (string, error) {
+	cp.logger.Info("Processing snooze command",
+		zap.String("user_id", userID),
+		zap.String("chat_id", chatID),
+		zap.Strings("args", args))
+
+	parsed := newCommandArgs(snoozeUsage, args)
+	task, err := parsed.task()
+	if err != nil {
+		return usageReply(err)
+	}
+	var when string
+	if !parsed.empty() {
+		if when, err = parsed.when(); err != nil {
+			return usageReply(err)
+		}
+	}
+
+	if reply := cp.requestTaskAction(userID, chatID, "snooze", task, when); reply != "" {
+		return reply, nil
+	}
+	if when == "" {
+		return fmt.Sprintf("Snoozing task %s for an hour...", taskLabel(task)), nil
+	}
+	return fmt.Sprintf("Snoozing task %s until %s...", taskLabel(task), when), nil
+}
+
+// ProcessTestReminderCommand handles the /testreminder command
+func (cp *CommandProcessor) ProcessTestReminderCommand(userID, chatID string, args []string) (string, error) {
+	cp.logger.Info("Processing test reminder command",
+		zap.String("user_id", userID),
+		zap.String("chat_id", chatID),
+		zap.Strings("args", args))
+
+	parsed := newCommandArgs(testReminderUsage, args)
+	task, err := parsed.task()
+	if err == nil {
+		err = parsed.done()
+	}
+	if err != nil {
+		return usageReply(err)
+	}
+
+	// The reminder itself confirms success
+	return cp.requestTaskAction(userID, chatID, "test_reminder", task, ""), nil
+}
+
+// fieldUsage explains the /field command arguments
+const fieldUsage = "Usage: /field [task] [key] [text|number|date|enum:a|b|c] [value]\nor /field [task] [key] clear"
+
+// ProcessFieldCommand handles the /field command, which sets or clears a custom
+// field on a task. The outcome is reported once the nudge service has applied it.
+func (cp *CommandProcessor) ProcessFieldCommand(userID, chatID string, args []string) (string, error) {
+	cp.logger.Info("Processing field command",
+		zap.String("user_id", userID),
+		zap.String("chat_id", chatID),
+		zap.Strings("args", args))
+
+	parsed := newCommandArgs(fieldUsage, args)
+	task, err := parsed.task()
+	if err != nil {
+		return usageReply(err)
+	}
+	key, err := parsed.next("field name")
+	if err != nil {
+		return usageReply(err)
+	}
+	fieldType, err := parsed.next("field type, or clear")
+	if err != nil {
+		return usageReply(err)
+	}
+
+	taskID, reply := cp.resolveTaskID(userID, task)
+	if reply != "" {
+		return reply, nil
+	}
+
+	fieldEvent := events.TaskFieldUpdateRequested{
 		Event:  events.NewEvent(),
 		UserID: userID,
 		ChatID: chatID,
 		TaskID: taskID,
-		Action: "done",
+		Key:    strings.ToLower(key),
 	}
 
-	cp.eventBus.Publish(events.TopicTaskActionRequested, actionEvent)
+	switch lowered := strings.ToLower(fieldType); {
+	case lowered == "clear":
+		if err := parsed.done(); err != nil {
+			return usageReply(err)
+		}
+	case parsed.empty():
+		return usageReply(parsed.fail("Please add the value to set."))
+	case strings.HasPrefix(lowered, "enum:"):
+		fieldEvent.Type = "enum"
+		fieldEvent.Options = strings.Split(fieldType[len("enum:"):], "|")
+	default:
+		fieldEvent.Type = lowered
+	}
+	if fieldEvent.Type != "" {
+		fieldEvent.Value = parsed.rest()
+	}
+
+	cp.eventBus.Publish(events.TopicTaskFieldUpdateRequested, fieldEvent)
 
-	return fmt.Sprintf("Marking task %s as complete...", taskID), nil
+	return "", nil // Response will be sent via event handler
 }
 
-// ProcessDeleteCommand handles the /delete command
-func (cp *CommandProcessor) ProcessDeleteCommand(userID, chatID string, args []string) (string, error) {
-	cp.logger.Info("Processing delete command",
+// ProcessRescheduleCommand handles the /snoozeall and /moveto commands. The
+// nudge service replies with a preview of today's remaining tasks, which the
+// user confirms before anything is moved.
+func (cp *CommandProcessor) ProcessRescheduleCommand(userID, chatID string, command Command, args []string) (string, error) {
+	cp.logger.Info("Processing reschedule command",
 		zap.String("user_id", userID),
 		zap.String("chat_id", chatID),
+		zap.String("command", string(command)),
 		zap.Strings("args", args))
 
-	if len(args) == 0 {
-		return "Please specify a task ID or use the inline buttons to delete tasks.", nil
+	var argument string
+	var err error
+	if command == CommandSnoozeAll {
+		parsed := newCommandArgs("Usage: /snoozeall [duration], e.g. /snoozeall 2h", args)
+		if _, err = parsed.duration(); err == nil {
+			argument, err = args[0], parsed.done()
+		}
+	} else {
+		parsed := newCommandArgs("Usage: /moveto [day], e.g. /moveto tomorrow, /moveto friday or /moveto 2024-06-01", args)
+		if argument, err = parsed.next("day"); err == nil {
+			err = parsed.done()
+		}
+	}
+	if err != nil {
+		return usageReply(err)
 	}
 
-	taskID := args[0]
+	rescheduleEvent := events.TaskRescheduleRequested{
+		Event:    events.NewEvent(),
+		UserID:   userID,
+		ChatID:   chatID,
+		Command:  strings.TrimPrefix(string(command), "/"),
+		Argument: argument,
+	}
 
-	// Publish task action requested event
-	actionEvent := events.TaskActionRequested{
+	cp.eventBus.Publish(events.TopicTaskRescheduleRequested, rescheduleEvent)
+
+	return "", nil // Preview will be sent via event handler
+}
+
+// ProcessAPITokenCommand handles the /apitoken command. The token is sent once
+// the token service has issued it.
+func (cp *CommandProcessor) ProcessAPITokenCommand(userID, chatID string, args []string) (string, error) {
+	cp.logger.Info("Processing api token command",
+		zap.String("user_id", userID),
+		zap.String("chat_id", chatID))
+
+	revoke := len(args) > 0 && strings.EqualFold(args[0], "revoke")
+	if len(args) > 0 && !revoke {
+		return "Usage: /apitoken or /apitoken revoke", nil
+	}
+
+	tokenEvent := events.APITokenRequested{
 		Event:  events.NewEvent(),
 		UserID: userID,
 		ChatID: chatID,
-		TaskID: taskID,
-		Action: "delete",
+		Revoke: revoke,
 	}
 
-	cp.eventBus.Publish(events.TopicTaskActionRequested, actionEvent)
+	cp.eventBus.Publish(events.TopicAPITokenRequested, tokenEvent)
+
+	return "", nil
+}
+
+// ProcessRetentionCommand handles the /retention command. Without an argument
+// it shows how long completed tasks are kept; the answer is sent once the
+// nudge service has looked up or saved the choice.
+func (cp *CommandProcessor) ProcessRetentionCommand(userID, chatID string, args []string) (string, error) {
+	cp.logger.Info("Processing retention command",
+		zap.String("user_id", userID),
+		zap.String("chat_id", chatID),
+		zap.Strings("args", args))
+
+	retentionEvent := events.RetentionRequested{
+		Event:  events.NewEvent(),
+		UserID: userID,
+		ChatID: chatID,
+	}
+	if len(args) > 0 {
+		days, ok := parseRetention(args[0])
+		if !ok {
+			return "Usage: /retention [forever|90d|30d]", nil
+		}
+		retentionEvent.Days = &days
+	}
+
+	cp.eventBus.Publish(events.TopicRetentionRequested, retentionEvent)
+
+	return "", nil
+}
+
+// ProcessStatsCommand handles the /stats command. The report is sent once the
+// history service has compiled it.
+func (cp *CommandProcessor) ProcessStatsCommand(userID, chatID string) (string, error) {
+	cp.logger.Info("Processing stats command",
+		zap.String("user_id", userID),
+		zap.String("chat_id", chatID))
+
+	statsEvent := events.WeeklyStatsRequested{
+		Event:  events.NewEvent(),
+		UserID: userID,
+		ChatID: chatID,
+	}
+
+	cp.eventBus.Publish(events.TopicWeeklyStatsRequested, statsEvent)
+
+	return "", nil
+}
+
+// ProcessInviteCommand handles the /invite command. The invite link is sent
+// once the workspace service has created it.
+func (cp *CommandProcessor) ProcessInviteCommand(userID, chatID string, args []string) (string, error) {
+	cp.logger.Info("Processing invite command",
+		zap.String("user_id", userID),
+		zap.String("chat_id", chatID),
+		zap.Strings("args", args))
+
+	role := "editor"
+	if len(args) > 0 {
+		role = strings.ToLower(args[0])
+	}
+	switch role {
+	case "owner", "editor", "viewer":
+	default:
+		return "Usage: /invite [editor|viewer|owner]", nil
+	}
+
+	inviteEvent := events.WorkspaceInviteRequested{
+		Event:  events.NewEvent(),
+		UserID: userID,
+		ChatID: chatID,
+		Role:   role,
+	}
+
+	cp.eventBus.Publish(events.TopicWorkspaceInviteRequested, inviteEvent)
 
-	return fmt.Sprintf("Deleting task %s...", taskID), nil
+	return "", nil
+}
+
+// ProcessJoinCommand handles a /start command opened from an invite deep link
+func (cp *CommandProcessor) ProcessJoinCommand(userID, chatID, code string) (string, error) {
+	cp.logger.Info("Processing join command",
+		zap.String("user_id", userID),
+		zap.String("chat_id", chatID))
+
+	joinEvent := events.WorkspaceJoinRequested{
+		Event:  events.NewEvent(),
+		UserID: userID,
+		ChatID: chatID,
+		Code:   code,
+	}
+
+	cp.eventBus.Publish(events.TopicWorkspaceJoinRequested, joinEvent)
+
+	return "", nil
+}
+
+// ProcessNewListCommand handles the /newlist command. The invite link is sent
+// once the shared list service has created the list.
+func (cp *CommandProcessor) ProcessNewListCommand(userID, chatID string, args []string) (string, error) {
+	cp.logger.Info("Processing new list command",
+		zap.String("user_id", userID),
+		zap.String("chat_id", chatID))
+
+	name := strings.Join(args, " ")
+	if name == "" {
+		return "Usage: /newlist [name], e.g. /newlist Groceries", nil
+	}
+
+	createEvent := events.ListCreateRequested{
+		Event:  events.NewEvent(),
+		UserID: userID,
+		ChatID: chatID,
+		Name:   name,
+	}
+
+	cp.eventBus.Publish(events.TopicListCreateRequested, createEvent)
+
+	return "", nil
+}
+
+// ProcessListsCommand handles the /lists command
+func (cp *CommandProcessor) ProcessListsCommand(userID, chatID string) (string, error) {
+	cp.logger.Info("Processing lists command",
+		zap.String("user_id", userID),
+		zap.String("chat_id", chatID))
+
+	listsEvent := events.ListsRequested{
+		Event:  events.NewEvent(),
+		UserID: userID,
+		ChatID: chatID,
+	}
+
+	cp.eventBus.Publish(events.TopicListsRequested, listsEvent)
+
+	return "", nil
+}
+
+// ProcessAddToCommand handles the /addto command. The text after the list
+// name is parsed into tasks for the list like any other message.
+func (cp *CommandProcessor) ProcessAddToCommand(userID, chatID, arguments, locale string) (string, error) {
+	cp.logger.Info("Processing add to list command",
+		zap.String("user_id", userID),
+		zap.String("chat_id", chatID))
+
+	list, text, ok := splitAddToArguments(arguments)
+	if !ok {
+		return "Usage: /addto [list]: [task], e.g. /addto Groceries: milk and eggs", nil
+	}
+
+	taskEvent := events.ListTaskRequested{
+		Event:       events.NewEvent(),
+		UserID:      userID,
+		ChatID:      chatID,
+		List:        list,
+		MessageText: text,
+		Locale:      locale,
+	}
+
+	cp.eventBus.Publish(events.TopicListTaskRequested, taskEvent)
+
+	return "", nil
+}
+
+// splitAddToArguments splits "/addto" arguments into the list name and the
+// task text. The name ends at the first colon, or after one word without one.
+func splitAddToArguments(arguments string) (list, text string, ok bool) {
+	arguments = strings.TrimSpace(arguments)
+	if name, rest, found := strings.Cut(arguments, ":"); found {
+		list, text = strings.TrimSpace(name), strings.TrimSpace(rest)
+	} else if fields := strings.Fields(arguments); len(fields) > 1 {
+		list, text = fields[0], strings.TrimSpace(strings.TrimPrefix(arguments, fields[0]))
+	}
+	return list, text, list != "" && text != ""
+}
+
+// ProcessJoinListCommand handles a /start command opened from a shared list's invite link
+func (cp *CommandProcessor) ProcessJoinListCommand(userID, chatID, code string) (string, error) {
+	cp.logger.Info("Processing join list command",
+		zap.String("user_id", userID),
+		zap.String("chat_id", chatID))
+
+	joinEvent := events.ListJoinRequested{
+		Event:  events.NewEvent(),
+		UserID: userID,
+		ChatID: chatID,
+		Code:   code,
+	}
+
+	cp.eventBus.Publish(events.TopicListJoinRequested, joinEvent)
+
+	return "", nil
+}
+
+// ProcessDelegateCommand handles the /delegate command. The delegate is
+// looked up among the users of bot, who is asked to accept the task.
+func (cp *CommandProcessor) ProcessDelegateCommand(userID, chatID, bot string, args []string) (string, error) {
+	cp.logger.Info("Processing delegate command",
+		zap.String("user_id", userID),
+		zap.String("chat_id", chatID),
+		zap.Strings("args", args))
+
+	if len(args) < 2 || !strings.HasPrefix(args[1], "@") {
+		return "Usage: /delegate [task] @username, e.g. /delegate 1a2b3c4d @alex", nil
+	}
+
+	delegateEvent := events.TaskDelegationRequested{
+		Event:    events.NewEvent(),
+		UserID:   userID,
+		ChatID:   chatID,
+		TaskID:   args[0],
+		Username: args[1],
+		Bot:      bot,
+	}
+
+	cp.eventBus.Publish(events.TopicTaskDelegationRequested, delegateEvent)
+
+	return "", nil // Response will be sent via event handler
 }
 
 // HandleCallbackQuery processes inline keyboard button presses
@@ -193,6 +639,12 @@ func (cp *CommandProcessor) HandleCallbackQuery(callbackData *CallbackData, user
 		return cp.handleDeleteCallback(callbackData, userID, chatID)
 	case CallbackActionSnooze:
 		return cp.handleSnoozeCallback(callbackData, userID, chatID)
+	case CallbackActionStart, CallbackActionWait, CallbackActionMute, CallbackActionUnmute:
+		return cp.handleStatusCallback(callbackData, userID, chatID)
+	case CallbackActionHistory:
+		return cp.handleHistoryCallback(callbackData, userID, chatID)
+	case CallbackActionOriginal:
+		return cp.handleOriginalCallback(callbackData, userID, chatID)
 	case CallbackActionList:
 		return cp.handleListCallback(callbackData, userID, chatID)
 	case CallbackActionConfirm:
@@ -267,6 +719,66 @@ func (cp *CommandProcessor) handleSnoozeCallback(callbackData *CallbackData, use
 	return "⏰ Task snoozed for 1 hour!", nil
 }
 
+// handleStatusCallback processes Start and Wait button presses, which move a
+// task to in progress or waiting, and Mute and Unmute presses, which turn the
+// task's reminders off or on
+func (cp *CommandProcessor) handleStatusCallback(callbackData *CallbackData, userID, chatID string) (string, error) {
+	taskID, exists := callbackData.Data["task_id"]
+	if !exists {
+		return "Invalid task ID.", nil
+	}
+
+	actionEvent := events.TaskActionRequested{
+		Event:  events.NewEvent(),
+		UserID: userID,
+		ChatID: chatID,
+		TaskID: taskID,
+		Action: callbackData.Action,
+	}
+
+	cp.eventBus.Publish(events.TopicTaskActionRequested, actionEvent)
+
+	return "", nil // Response will be sent via event handler
+}
+
+// handleHistoryCallback processes history button presses
+func (cp *CommandProcessor) handleHistoryCallback(callbackData *CallbackData, userID, chatID string) (string, error) {
+	taskID, exists := callbackData.Data["task_id"]
+	if !exists {
+		return "Invalid task ID.", nil
+	}
+
+	historyEvent := events.TaskHistoryRequested{
+		Event:  events.NewEvent(),
+		UserID: userID,
+		ChatID: chatID,
+		TaskID: taskID,
+	}
+
+	cp.eventBus.Publish(events.TopicTaskHistoryRequested, historyEvent)
+
+	return "", nil // Response will be sent via event handler
+}
+
+// handleOriginalCallback processes show original button presses
+func (cp *CommandProcessor) handleOriginalCallback(callbackData *CallbackData, userID, chatID string) (string, error) {
+	taskID, exists := callbackData.Data["task_id"]
+	if !exists {
+		return "Invalid task ID.", nil
+	}
+
+	originalEvent := events.TaskOriginalRequested{
+		Event:  events.NewEvent(),
+		UserID: userID,
+		ChatID: chatID,
+		TaskID: taskID,
+	}
+
+	cp.eventBus.Publish(events.TopicTaskOriginalRequested, originalEvent)
+
+	return "", nil // Response will be sent via event handler
+}
+
 // handleListCallback processes list button presses
 func (cp *CommandProcessor) handleListCallback(callbackData *CallbackData, userID, chatID string) (string, error) {
 	// Publish task list requested event
@@ -347,6 +859,37 @@ func (sm *SessionManager) SetSession(userID string, session *ChatSession) {
 	sm.sessions[userID] = session
 }
 
+// UpdateSession applies an update to a user's session under the lock, creating the session if needed
+func (sm *SessionManager) UpdateSession(userID, chatID string, update func(session *ChatSession)) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	session, exists := sm.sessions[userID]
+	if !exists {
+		session = &ChatSession{
+			UserID: common.UserID(userID),
+			ChatID: common.ChatID(chatID),
+			State:  SessionStateIdle,
+		}
+		sm.sessions[userID] = session
+	}
+
+	update(session)
+	session.LastActivity = time.Now()
+}
+
+// MoveChat points the sessions held in a chat at the chat it migrated to
+func (sm *SessionManager) MoveChat(from, to common.ChatID) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	for _, session := range sm.sessions {
+		if session.ChatID == from {
+			session.ChatID = to
+		}
+	}
+}
+
 // UpdateLastActivity updates the last activity time for a session
 func (sm *SessionManager) UpdateLastActivity(userID string) {
 	sm.mutex.Lock()
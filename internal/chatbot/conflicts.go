@@ -0,0 +1,33 @@
+package chatbot
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"nudgebot-api/internal/events"
+)
+
+// formatNewTaskConflicts warns that a new task is due around the same time as
+// other tasks
+func formatNewTaskConflicts(conflicts []events.TaskConflict, dates DateFormat) string {
+	var text strings.Builder
+	text.WriteString("⚠️ This clashes with:")
+	for _, conflict := range conflicts {
+		text.WriteString(fmt.Sprintf("\n• <b>%s</b> at %s",
+			html.EscapeString(conflict.OtherTitle), dates.DateTime(conflict.OtherDueDate)))
+	}
+	return text.String()
+}
+
+// formatDigestConflicts lists the pairs of tasks due around the same time
+func formatDigestConflicts(conflicts []events.TaskConflict, dates DateFormat) string {
+	var text strings.Builder
+	text.WriteString("⚠️ <b>Clashing times</b>")
+	for _, conflict := range conflicts {
+		text.WriteString(fmt.Sprintf("\n• %s (%s) and %s (%s)",
+			html.EscapeString(conflict.Title), dates.Time(conflict.DueDate),
+			html.EscapeString(conflict.OtherTitle), dates.Time(conflict.OtherDueDate)))
+	}
+	return text.String()
+}
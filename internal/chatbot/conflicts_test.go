@@ -0,0 +1,20 @@
+package chatbot
+
+import (
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatConflicts(t *testing.T) {
+	due := time.Date(2024, 6, 3, 15, 0, 0, 0, time.UTC)
+	conflicts := []events.TaskConflict{{Title: "Team call", DueDate: due.Add(10 * time.Minute), OtherTitle: "Dentist <3", OtherDueDate: due}}
+
+	assert.Equal(t, "⚠️ This clashes with:\n• <b>Dentist &lt;3</b> at Jun 3 15:00", formatNewTaskConflicts(conflicts, fixedDates))
+
+	digest := formatReminderDigest(events.ReminderDigestDue{Reminders: []events.ReminderDue{{TaskID: "a", Title: "Pay rent"}}, Conflicts: conflicts}, fixedDates)
+	assert.Contains(t, digest, "⚠️ <b>Clashing times</b>\n• Team call (15:10) and Dentist &lt;3 (15:00)")
+}
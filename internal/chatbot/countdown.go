@@ -0,0 +1,115 @@
+package chatbot
+
+import (
+	"fmt"
+	"html"
+	"time"
+
+	"nudgebot-api/internal/events"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// offersCountdown reports whether a task due at dueDate is close enough to
+// offer a countdown for it
+func offersCountdown(dueDate *time.Time, now time.Time) bool {
+	if dueDate == nil {
+		return false
+	}
+	remaining := dueDate.Sub(now)
+	return remaining > 0 && remaining <= events.CountdownMaxLead
+}
+
+// formatRemaining renders time left as "2h 15m", rounding up to the minute
+func formatRemaining(remaining time.Duration) string {
+	minutes := int((remaining + time.Minute - 1) / time.Minute)
+	switch {
+	case minutes <= 1:
+		return "1m"
+	case minutes < 60:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes%60 == 0:
+		return fmt.Sprintf("%dh", minutes/60)
+	default:
+		return fmt.Sprintf("%dh %dm", minutes/60, minutes%60)
+	}
+}
+
+// formatCountdown renders a countdown message in its current state
+func formatCountdown(event events.CountdownUpdated) string {
+	title := html.EscapeString(event.Title)
+	switch event.State {
+	case events.CountdownRunning:
+		return fmt.Sprintf("⏳ <b>%s</b>\n\n%s left", title, formatRemaining(event.Remaining))
+	case events.CountdownDone:
+		if title == "" {
+			return "✅ Countdown over, the task is done."
+		}
+		return fmt.Sprintf("✅ <b>%s</b>\n\nDone in time, countdown over.", title)
+	case events.CountdownExpired:
+		return fmt.Sprintf("⌛ <b>%s</b>\n\nTime's up!", title)
+	default:
+		return fmt.Sprintf("❌ <b>Countdown Not Started</b>\n\n%s", html.EscapeString(event.Message))
+	}
+}
+
+// handleCountdownCallback posts the message a countdown is shown in and asks
+// the countdown service to start counting down in it
+func (s *chatbotService) handleCountdownCallback(callbackData *CallbackData, userID, chatID string) error {
+	taskID := callbackData.Data["task_id"]
+	chatIDInt, err := s.telegramChatID(chatID)
+	if err != nil {
+		return err
+	}
+
+	messageID, err := s.provider.SendTrackedMessage(chatIDInt, s.threads.Get(chatID), "⏳ Starting countdown...", s.keyboardBuilder.BuildCountdownKeyboard(taskID))
+	if err != nil {
+		return err
+	}
+
+	requestEvent := events.CountdownRequested{
+		Event:     events.NewEvent(),
+		UserID:    userID,
+		ChatID:    chatID,
+		TaskID:    taskID,
+		MessageID: messageID,
+	}
+	if err := s.eventBus.Publish(events.TopicCountdownRequested, requestEvent); err != nil {
+		s.logger.Error("Failed to publish countdown request",
+			zap.String("user_id", userID),
+			zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// handleCountdownUpdated edits a countdown message to show the time left, or
+// how the countdown ended
+func (s *chatbotService) handleCountdownUpdated(event events.CountdownUpdated) {
+	if !s.ownsUser(event.UserID) {
+		return
+	}
+
+	chatIDInt, err := s.telegramChatID(event.ChatID)
+	if err != nil {
+		s.logger.Error("Failed to update countdown",
+			zap.String("task_id", event.TaskID),
+			zap.Error(err))
+		return
+	}
+
+	// Only a running countdown keeps its Done button
+	keyboard := tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}
+	if event.State == events.CountdownRunning {
+		keyboard = s.keyboardBuilder.BuildCountdownKeyboard(event.TaskID)
+	}
+
+	if err := s.provider.EditMessageWithKeyboard(chatIDInt, event.MessageID, formatCountdown(event), keyboard); err != nil {
+		s.logger.Warn("Failed to update countdown",
+			zap.String("correlation_id", event.CorrelationID),
+			zap.String("task_id", event.TaskID),
+			zap.Int("message_id", event.MessageID),
+			zap.Error(err))
+	}
+}
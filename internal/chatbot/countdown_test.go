@@ -0,0 +1,53 @@
+package chatbot
+
+import (
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestFormatRemaining(t *testing.T) {
+	assert.Equal(t, "2h 15m", formatRemaining(2*time.Hour+14*time.Minute+30*time.Second))
+	assert.Equal(t, "3h", formatRemaining(3*time.Hour))
+	assert.Equal(t, "45m", formatRemaining(45*time.Minute))
+	assert.Equal(t, "1m", formatRemaining(10*time.Second))
+}
+
+func TestOffersCountdown(t *testing.T) {
+	now := time.Now()
+	soon, later, past := now.Add(3*time.Hour), now.Add(2*24*time.Hour), now.Add(-time.Hour)
+
+	assert.True(t, offersCountdown(&soon, now))
+	assert.False(t, offersCountdown(&later, now))
+	assert.False(t, offersCountdown(&past, now))
+	assert.False(t, offersCountdown(nil, now))
+}
+
+func TestChatbotService_CountdownEditsItsMessage(t *testing.T) {
+	eventBus := events.NewMockEventBus()
+	eventBus.SetSynchronousMode(true)
+	chatbot, _ := newBenchService(eventBus, zaptest.NewLogger(t))
+	provider := &listRecordingProvider{edited: make(map[int]string)}
+	chatbot.provider = provider
+
+	require.NoError(t, chatbot.handleCountdownCallback(&CallbackData{Action: CallbackActionCountdown, Data: map[string]string{"task_id": "task-1"}}, "user", "42"))
+	require.Len(t, provider.sent, 1)
+	requests := eventBus.GetPublishedEvents(events.TopicCountdownRequested)
+	require.Len(t, requests, 1)
+	request := requests[0].(events.CountdownRequested)
+	assert.Equal(t, "task-1", request.TaskID)
+	assert.Equal(t, 101, request.MessageID)
+
+	update := events.CountdownUpdated{Event: events.NewEvent(), UserID: "user", ChatID: "42", TaskID: "task-1", MessageID: 101, Title: "Catch train", State: events.CountdownRunning, Remaining: 2*time.Hour + 15*time.Minute}
+	chatbot.handleCountdownUpdated(update)
+	assert.Contains(t, provider.edited[101], "2h 15m left")
+
+	update.State = events.CountdownExpired
+	chatbot.handleCountdownUpdated(update)
+	assert.Contains(t, provider.edited[101], "Time's up")
+}
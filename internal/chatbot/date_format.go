@@ -0,0 +1,160 @@
+package chatbot
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// clock12Locales use a 12-hour clock; a region entry overrides its language's
+var clock12Locales = map[string]bool{
+	"en": true, "en-gb": false, "en-ie": false, "en-za": false,
+	"hi": true, "bn": true, "ur": true, "ar": true, "ko": true, "fil": true,
+	"zh-tw": true,
+}
+
+// monthFirstLocales write the month before the day; a region entry overrides
+// its language's
+var monthFirstLocales = map[string]bool{
+	"en": true, "en-gb": false, "en-ie": false, "en-au": false, "en-nz": false, "en-in": false, "en-za": false,
+	"zh": true, "ja": true, "ko": true, "hu": true, "lt": true,
+}
+
+// DateFormat renders times for one user: in their time zone, with the clock
+// and day-month order of their language, and relative to now for nearby days
+// ("today 9:00", "tomorrow 3:00 PM", "Fri 18:30").
+type DateFormat struct {
+	location   *time.Location
+	clock12    bool
+	monthFirst bool
+	now        time.Time
+}
+
+// NewDateFormat creates the format for an IANA time zone and a language such
+// as "en-US" or "vi", rendering relative to now. An empty or unknown zone is
+// UTC. An empty locale keeps a 24-hour clock with the month first; other
+// unknown languages get a 24-hour clock with the day first.
+func NewDateFormat(timezone, locale string, now time.Time) DateFormat {
+	location, err := time.LoadLocation(timezone)
+	if timezone == "" || err != nil {
+		location = time.UTC
+	}
+
+	format := DateFormat{location: location, now: now.In(location), monthFirst: true}
+	locale = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+	if locale == "" {
+		return format
+	}
+	format.clock12 = lookupLocale(clock12Locales, locale)
+	format.monthFirst = lookupLocale(monthFirstLocales, locale)
+	return format
+}
+
+// lookupLocale finds a locale's entry, falling back from its region to its
+// language
+func lookupLocale(table map[string]bool, locale string) bool {
+	if value, ok := table[locale]; ok {
+		return value
+	}
+	language, _, _ := strings.Cut(locale, "-")
+	return table[language]
+}
+
+// Location returns the time zone times are shown in
+func (f DateFormat) Location() *time.Location {
+	if f.location == nil {
+		return time.UTC
+	}
+	return f.location
+}
+
+// Time renders the time of day, such as "9:05" or "9:05 AM"
+func (f DateFormat) Time(t time.Time) string {
+	t = t.In(f.Location())
+	if !f.clock12 {
+		return fmt.Sprintf("%d:%02d", t.Hour(), t.Minute())
+	}
+	return t.Format("3:04 PM")
+}
+
+// Date renders the day, such as "Jun 3" or "3 Jun", with the year when it
+// isn't the current one
+func (f DateFormat) Date(t time.Time) string {
+	t = t.In(f.Location())
+	layout := "2 Jan"
+	if f.monthFirst {
+		layout = "Jan 2"
+	}
+	if t.Year() != f.now.Year() {
+		if f.monthFirst {
+			layout += ","
+		}
+		layout += " 2006"
+	}
+	return t.Format(layout)
+}
+
+// DateTime renders a moment, naming the day relatively when it is within a
+// week of now: "today 9:00", "tomorrow 9:00", "yesterday 9:00", or the
+// weekday for the rest of the coming week
+func (f DateFormat) DateTime(t time.Time) string {
+	t = t.In(f.Location())
+	clock := f.Time(t)
+
+	switch days := f.daysFromNow(t); {
+	case days == 0:
+		return "today " + clock
+	case days == 1:
+		return "tomorrow " + clock
+	case days == -1:
+		return "yesterday " + clock
+	case days > 1 && days < 7:
+		return t.Format("Mon") + " " + clock
+	default:
+		return f.Date(t) + " " + clock
+	}
+}
+
+// daysFromNow counts the calendar days between now and t in the format's
+// time zone
+func (f DateFormat) daysFromNow(t time.Time) int {
+	location := f.Location()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, location)
+	today := time.Date(f.now.Year(), f.now.Month(), f.now.Day(), 0, 0, 0, 0, location)
+	// Rounded because a day across a DST change is 23 or 25 hours
+	return int(math.Round(day.Sub(today).Hours() / 24))
+}
+
+// DueIn describes how far a due date is from now: "due in 45 minutes",
+// "due now" or "overdue by 2 days"
+func (f DateFormat) DueIn(due time.Time) string {
+	until := due.Sub(f.now)
+	switch {
+	case until.Abs() < time.Minute:
+		return "due now"
+	case until > 0:
+		return "due in " + formatSpan(until)
+	default:
+		return "overdue by " + formatSpan(-until)
+	}
+}
+
+// formatSpan rounds a duration to minutes under an hour, hours under two days
+// and days beyond
+func formatSpan(span time.Duration) string {
+	if minutes := int(span.Round(time.Minute) / time.Minute); minutes < 60 {
+		return pluralUnit(minutes, "minute")
+	}
+	if hours := int(span.Round(time.Hour) / time.Hour); hours < 48 {
+		return pluralUnit(hours, "hour")
+	}
+	return pluralUnit(int(span.Round(24*time.Hour)/(24*time.Hour)), "day")
+}
+
+func pluralUnit(count int, unit string) string {
+	if count == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", count, unit)
+}
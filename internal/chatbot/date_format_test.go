@@ -0,0 +1,83 @@
+package chatbot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fixedDates renders in UTC with a 24-hour clock, far enough from the dates
+// the formatter tests use that none of them is named relatively
+var fixedDates = NewDateFormat("", "", time.Date(2024, 9, 15, 12, 0, 0, 0, time.UTC))
+
+func TestDateFormat_Locales(t *testing.T) {
+	now := time.Date(2024, 3, 4, 15, 30, 0, 0, time.UTC)
+	due := time.Date(2024, 5, 20, 14, 5, 0, 0, time.UTC)
+
+	tests := []struct {
+		locale string
+		want   string
+	}{
+		{"", "May 20 14:05"},
+		{"en", "May 20 2:05 PM"},
+		{"en-US", "May 20 2:05 PM"},
+		{"en_GB", "20 May 14:05"},
+		{"en-AU", "20 May 2:05 PM"},
+		{"vi", "20 May 14:05"},
+		{"de-DE", "20 May 14:05"},
+		{"ja", "May 20 14:05"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewDateFormat("", tt.locale, now).DateTime(due))
+		})
+	}
+}
+
+func TestDateFormat_RelativeDays(t *testing.T) {
+	// Monday evening in Berlin is already Tuesday in Tokyo
+	now := time.Date(2024, 3, 4, 20, 30, 0, 0, time.UTC)
+	berlin := NewDateFormat("Europe/Berlin", "de", now)
+	tokyo := NewDateFormat("Asia/Tokyo", "ja", now)
+
+	tomorrowMorning := time.Date(2024, 3, 5, 8, 0, 0, 0, time.UTC)
+	assert.Equal(t, "tomorrow 9:00", berlin.DateTime(tomorrowMorning))
+	assert.Equal(t, "today 17:00", tokyo.DateTime(tomorrowMorning))
+	assert.Equal(t, "today 21:15", berlin.DateTime(now.Add(-15*time.Minute)))
+	assert.Equal(t, "yesterday 5:15", tokyo.DateTime(now.Add(-24*time.Hour-15*time.Minute)))
+	assert.Equal(t, "Fri 9:00", berlin.DateTime(tomorrowMorning.Add(3*24*time.Hour)))
+	assert.Equal(t, "12 Mar 9:00", berlin.DateTime(tomorrowMorning.Add(7*24*time.Hour)))
+	assert.Equal(t, "2 Jan 2025 9:00", berlin.DateTime(time.Date(2025, 1, 2, 8, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "Jan 2, 2025 3:00 AM", NewDateFormat("America/New_York", "en-US", now).DateTime(time.Date(2025, 1, 2, 8, 0, 0, 0, time.UTC)))
+}
+
+func TestDateFormat_AcrossDaylightSaving(t *testing.T) {
+	// Clocks in Berlin go forward on the night of March 31, 2024
+	now := time.Date(2024, 3, 30, 22, 0, 0, 0, time.UTC)
+	berlin := NewDateFormat("Europe/Berlin", "de", now)
+
+	assert.Equal(t, "tomorrow 23:00", berlin.DateTime(time.Date(2024, 3, 31, 21, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "Mon 9:00", berlin.DateTime(time.Date(2024, 4, 1, 7, 0, 0, 0, time.UTC)))
+}
+
+func TestDateFormat_UnknownTimezoneIsUTC(t *testing.T) {
+	format := NewDateFormat("Mars/Olympus", "en", time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC))
+
+	assert.Equal(t, time.UTC, format.Location())
+	assert.Equal(t, "9:05 AM", format.Time(time.Date(2024, 3, 4, 9, 5, 0, 0, time.UTC)))
+	assert.Equal(t, "Mar 4", format.Date(time.Date(2024, 3, 4, 9, 5, 0, 0, time.UTC)))
+}
+
+func TestDateFormat_DueIn(t *testing.T) {
+	now := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+	dates := NewDateFormat("", "", now)
+
+	assert.Equal(t, "due now", dates.DueIn(now.Add(20*time.Second)))
+	assert.Equal(t, "due in 1 minute", dates.DueIn(now.Add(time.Minute)))
+	assert.Equal(t, "due in 45 minutes", dates.DueIn(now.Add(45*time.Minute)))
+	assert.Equal(t, "due in 1 hour", dates.DueIn(now.Add(59*time.Minute+50*time.Second)))
+	assert.Equal(t, "due in 30 hours", dates.DueIn(now.Add(30*time.Hour)))
+	assert.Equal(t, "overdue by 10 minutes", dates.DueIn(now.Add(-10*time.Minute)))
+	assert.Equal(t, "overdue by 2 days", dates.DueIn(now.Add(-50*time.Hour)))
+}
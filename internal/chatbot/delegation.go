@@ -0,0 +1,115 @@
+package chatbot
+
+import (
+	"fmt"
+	"html"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// isDelegationAction reports whether a callback action answers a task hand-off
+func isDelegationAction(action string) bool {
+	return action == CallbackActionDelegationAccept || action == CallbackActionDelegationDecline
+}
+
+// formatTaskDelegated asks the delegate to take over a task
+func formatTaskDelegated(event events.TaskDelegated, dates DateFormat) string {
+	text := fmt.Sprintf("🤝 <b>%s</b> wants to hand you a task\n\n📋 <b>%s</b>",
+		html.EscapeString(event.DelegatedBy), html.EscapeString(event.Title))
+	if event.DueDate != nil {
+		text += "\n📅 Due " + dates.DateTime(*event.DueDate)
+	}
+	return text + "\n\nAccept it to make it yours, reminders included."
+}
+
+// formatTaskDelegationResolved tells the delegator how the delegate answered
+func formatTaskDelegationResolved(event events.TaskDelegationResolved) string {
+	if event.Accepted {
+		return fmt.Sprintf("🤝 %s accepted <b>%s</b>. It's off your list.",
+			html.EscapeString(event.Delegate), html.EscapeString(event.Title))
+	}
+	return fmt.Sprintf("🙅 %s declined <b>%s</b>. It's still yours.",
+		html.EscapeString(event.Delegate), html.EscapeString(event.Title))
+}
+
+// handleDelegationCallback passes the delegate's Accept or Decline on to the nudge service
+func (s *chatbotService) handleDelegationCallback(callbackData *CallbackData, userID, chatID string) error {
+	replyEvent := events.TaskDelegationReplied{
+		Event:  events.NewEvent(),
+		UserID: userID,
+		ChatID: chatID,
+		TaskID: callbackData.Data["task_id"],
+		Accept: callbackData.Action == CallbackActionDelegationAccept,
+	}
+	if err := s.eventBus.Publish(events.TopicTaskDelegationReplied, replyEvent); err != nil {
+		s.logger.Error("Failed to publish delegation answer",
+			zap.String("user_id", userID),
+			zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// handleTaskDelegationResponse reports the outcome of /delegate, or of an
+// answer to a hand-off, to the user who acted
+func (s *chatbotService) handleTaskDelegationResponse(event events.TaskDelegationResponse) {
+	if !s.ownsUser(event.UserID) {
+		return
+	}
+
+	s.logger.Info("Handling TaskDelegationResponse event",
+		zap.String("correlation_id", event.CorrelationID),
+		zap.String("user_id", event.UserID),
+		zap.String("action", event.Action),
+		zap.Bool("success", event.Success))
+
+	var text string
+	switch {
+	case !event.Success:
+		text = fmt.Sprintf("❌ <b>Hand-off Failed</b>\n\n%s", html.EscapeString(event.Message))
+	case event.Action == events.DelegationActionDelegate:
+		text = fmt.Sprintf("🤝 <b>%s</b>\n\n%s", html.EscapeString(event.Title), html.EscapeString(event.Message))
+	case event.Action == events.DelegationActionAccept:
+		text = fmt.Sprintf("✅ You accepted <b>%s</b>.\n\n%s", html.EscapeString(event.Title), html.EscapeString(event.Message))
+	default:
+		text = fmt.Sprintf("🙅 You declined <b>%s</b>.\n\n%s", html.EscapeString(event.Title), html.EscapeString(event.Message))
+	}
+
+	if err := s.SendMessage(common.ChatID(event.ChatID), text); err != nil {
+		s.logger.Error("Failed to send delegation result",
+			zap.String("correlation_id", event.CorrelationID),
+			zap.Error(err))
+	}
+}
+
+// handleTaskDelegated asks the delegate to accept or decline a task
+func (s *chatbotService) handleTaskDelegated(event events.TaskDelegated) {
+	if !s.ownsUser(event.UserID) {
+		return
+	}
+
+	keyboard := s.keyboardBuilder.ToDomainKeyboard(s.keyboardBuilder.BuildDelegationKeyboard(event.TaskID))
+	if err := s.SendMessageWithKeyboard(common.ChatID(event.ChatID), formatTaskDelegated(event, s.dateFormat(event.UserID)), keyboard); err != nil {
+		s.logger.Error("Failed to send task hand-off",
+			zap.String("correlation_id", event.CorrelationID),
+			zap.String("task_id", event.TaskID),
+			zap.Error(err))
+	}
+}
+
+// handleTaskDelegationResolved tells the delegator whether the delegate took the task
+func (s *chatbotService) handleTaskDelegationResolved(event events.TaskDelegationResolved) {
+	if !s.ownsUser(event.UserID) {
+		return
+	}
+
+	if err := s.SendMessage(common.ChatID(event.ChatID), formatTaskDelegationResolved(event)); err != nil {
+		s.logger.Error("Failed to send delegation answer",
+			zap.String("correlation_id", event.CorrelationID),
+			zap.String("task_id", event.TaskID),
+			zap.Error(err))
+	}
+}
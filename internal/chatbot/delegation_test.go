@@ -0,0 +1,26 @@
+package chatbot
+
+import (
+	"testing"
+
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatTaskDelegated(t *testing.T) {
+	text := formatTaskDelegated(events.TaskDelegated{Title: "Fix <sink>", DelegatedBy: "@alice"}, fixedDates)
+
+	assert.Contains(t, text, "@alice")
+	assert.Contains(t, text, "Fix &lt;sink&gt;")
+	assert.NotContains(t, text, "Due")
+}
+
+func TestFormatTaskDelegationResolved(t *testing.T) {
+	accepted := formatTaskDelegationResolved(events.TaskDelegationResolved{Title: "Book venue", Delegate: "@bob", Accepted: true})
+	assert.Contains(t, accepted, "@bob accepted")
+
+	declined := formatTaskDelegationResolved(events.TaskDelegationResolved{Title: "Book venue", Delegate: "@bob"})
+	assert.Contains(t, declined, "@bob declined")
+	assert.Contains(t, declined, "still yours")
+}
@@ -23,6 +23,7 @@ type Message struct {
 	Text        string        `json:"text" validate:"required"`
 	Timestamp   time.Time     `json:"timestamp" validate:"required"`
 	MessageType MessageType   `json:"message_type" validate:"required"`
+	Locale      string        `json:"locale,omitempty"` // language of the sender's Telegram client
 }
 
 // InlineKeyboard represents a Telegram inline keyboard
@@ -44,6 +45,14 @@ type ChatSession struct {
 	State        SessionState  `json:"state"`
 	Context      string        `json:"context"`
 	LastActivity time.Time     `json:"last_activity"`
+	Draft        *TaskDraft    `json:"draft,omitempty"`
+
+	// PendingReschedule is a /snoozeall or /moveto preview awaiting confirmation
+	PendingReschedule *PendingReschedule `json:"pending_reschedule,omitempty"`
+
+	// FailedParses are the latest messages the LLM could not parse, kept so
+	// they can be saved as plain tasks
+	FailedParses []*FailedParse `json:"failed_parses,omitempty"`
 }
 
 // SessionState represents the current state of a chat session
@@ -54,17 +63,37 @@ const (
 	SessionStateAwaitingTask   SessionState = "awaiting_task"
 	SessionStateConfirmingTask SessionState = "confirming_task"
 	SessionStateManagingTasks  SessionState = "managing_tasks"
+	SessionStateEditingTitle   SessionState = "editing_title"
 )
 
 // Command represents supported bot commands
 type Command string
 
 const (
-	CommandStart  Command = "/start"
-	CommandHelp   Command = "/help"
-	CommandList   Command = "/list"
-	CommandDone   Command = "/done"
-	CommandDelete Command = "/delete"
+	CommandStart        Command = "/start"
+	CommandHelp         Command = "/help"
+	CommandList         Command = "/list"
+	CommandDone         Command = "/done"
+	CommandDelete       Command = "/delete"
+	CommandTestReminder Command = "/testreminder"
+	CommandInvite       Command = "/invite"
+	CommandField        Command = "/field"
+	CommandSnoozeAll    Command = "/snoozeall"
+	CommandMoveTo       Command = "/moveto"
+	CommandAPIToken     Command = "/apitoken"
+	CommandStats        Command = "/stats"
+	CommandNewList      Command = "/newlist"
+	CommandLists        Command = "/lists"
+	CommandAddTo        Command = "/addto"
+	CommandDelegate     Command = "/delegate"
+	CommandPlan         Command = "/plan"
+	CommandRetention    Command = "/retention"
+	CommandSnooze       Command = "/snooze"
+	CommandSettings     Command = "/settings"
+	CommandBroadcast    Command = "/broadcast"
+	CommandGlobalStats  Command = "/globalstats"
+	CommandMaintenance  Command = "/maintenance"
+	CommandTask         Command = "/task"
 )
 
 // CallbackData represents data from inline keyboard callbacks
@@ -86,7 +115,8 @@ func (mt MessageType) IsValid() bool {
 // IsValid checks if the session state is valid
 func (ss SessionState) IsValid() bool {
 	switch ss {
-	case SessionStateIdle, SessionStateAwaitingTask, SessionStateConfirmingTask, SessionStateManagingTasks:
+	case SessionStateIdle, SessionStateAwaitingTask, SessionStateConfirmingTask, SessionStateManagingTasks,
+		SessionStateEditingTitle:
 		return true
 	default:
 		return false
@@ -96,7 +126,11 @@ func (ss SessionState) IsValid() bool {
 // IsValid checks if the command is valid
 func (c Command) IsValid() bool {
 	switch c {
-	case CommandStart, CommandHelp, CommandList, CommandDone, CommandDelete:
+	case CommandStart, CommandHelp, CommandList, CommandDone, CommandDelete, CommandTestReminder, CommandInvite,
+		CommandField, CommandSnoozeAll, CommandMoveTo, CommandAPIToken, CommandStats, CommandNewList, CommandLists,
+		CommandAddTo, CommandDelegate, CommandPlan,
+		CommandRetention, CommandSnooze, CommandSettings, CommandBroadcast, CommandGlobalStats,
+		CommandMaintenance, CommandTask:
 		return true
 	default:
 		return false
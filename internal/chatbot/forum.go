@@ -0,0 +1,78 @@
+package chatbot
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// ThreadTracker remembers the forum topic each chat was last written to, so
+// replies land in the topic the conversation is happening in. Chats that are
+// not forums have no topic.
+type ThreadTracker struct {
+	mu      sync.Mutex
+	threads map[string]int
+}
+
+// NewThreadTracker creates an empty tracker
+func NewThreadTracker() *ThreadTracker {
+	return &ThreadTracker{
+		threads: make(map[string]int),
+	}
+}
+
+// Set records the topic of the latest update in a chat; 0 means the chat itself
+func (t *ThreadTracker) Set(chatID string, threadID int) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if threadID == 0 {
+		delete(t.threads, chatID)
+		return
+	}
+	t.threads[chatID] = threadID
+}
+
+// Get returns the topic replies to a chat go to, or 0 for the chat itself
+func (t *ThreadTracker) Get(chatID string) int {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.threads[chatID]
+}
+
+// topicMessage holds the forum fields of a message, which the Telegram
+// library predates
+type topicMessage struct {
+	MessageThreadID int  `json:"message_thread_id"`
+	IsTopicMessage  bool `json:"is_topic_message"`
+}
+
+// ExtractThreadID returns the forum topic the update's message or pressed
+// button was in, or 0 outside forum topics. Replies in ordinary groups also
+// carry a message_thread_id, so only topic messages count.
+func (p *WebhookParser) ExtractThreadID(updateData []byte) int {
+	var update struct {
+		Message       *topicMessage `json:"message"`
+		CallbackQuery *struct {
+			Message *topicMessage `json:"message"`
+		} `json:"callback_query"`
+	}
+	if err := json.Unmarshal(updateData, &update); err != nil {
+		return 0
+	}
+
+	message := update.Message
+	if message == nil && update.CallbackQuery != nil {
+		message = update.CallbackQuery.Message
+	}
+	if message == nil || !message.IsTopicMessage {
+		return 0
+	}
+	return message.MessageThreadID
+}
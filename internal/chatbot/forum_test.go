@@ -0,0 +1,90 @@
+package chatbot
+
+import (
+	"testing"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// threadRecordingProvider records each message and the topic it was sent to
+type threadRecordingProvider struct {
+	TelegramProvider
+	threads []int
+	texts   []string
+}
+
+func (p *threadRecordingProvider) SendMessage(chatID int64, threadID int, text string) error {
+	p.threads = append(p.threads, threadID)
+	p.texts = append(p.texts, text)
+	return nil
+}
+
+func (p *threadRecordingProvider) SendTrackedMessage(chatID int64, threadID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) (int, error) {
+	p.threads = append(p.threads, threadID)
+	p.texts = append(p.texts, text)
+	return len(p.threads), nil
+}
+
+func TestThreadTracker_SetAndGet(t *testing.T) {
+	tracker := NewThreadTracker()
+	tracker.Set("chat", 7)
+	assert.Equal(t, 7, tracker.Get("chat"))
+	assert.Equal(t, 0, tracker.Get("other-chat"))
+
+	// Writing outside a topic sends replies to the chat itself again
+	tracker.Set("chat", 0)
+	assert.Equal(t, 0, tracker.Get("chat"))
+}
+
+func TestWebhookParser_ExtractThreadID(t *testing.T) {
+	parser := NewWebhookParser()
+
+	tests := []struct {
+		name   string
+		update string
+		want   int
+	}{
+		{"topic message", `{"update_id":1,"message":{"message_id":5,"message_thread_id":7,"is_topic_message":true,"text":"hi"}}`, 7},
+		{"reply outside a forum", `{"update_id":1,"message":{"message_id":5,"message_thread_id":3,"text":"hi"}}`, 0},
+		{"button in a topic", `{"update_id":1,"callback_query":{"id":"q","message":{"message_id":5,"message_thread_id":9,"is_topic_message":true}}}`, 9},
+		{"private message", `{"update_id":1,"message":{"message_id":5,"text":"hi"}}`, 0},
+		{"malformed update", `{`, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parser.ExtractThreadID([]byte(tt.update)))
+		})
+	}
+}
+
+func TestChatbotService_RepliesInForumTopic(t *testing.T) {
+	eventBus := events.NewMockEventBus()
+	eventBus.SetSynchronousMode(true)
+	chatbot, _ := newBenchService(eventBus, zaptest.NewLogger(t))
+	provider := &threadRecordingProvider{}
+	chatbot.provider = provider
+
+	chatID, err := chatbot.identities.Resolve("", -100123)
+	require.NoError(t, err)
+
+	// A task written in a topic carries the topic to the parser
+	update := `{"update_id":1,"message":{"message_id":5,"message_thread_id":7,"is_topic_message":true,"from":{"id":4242,"first_name":"Ann"},"chat":{"id":-100123,"type":"supergroup","is_forum":true},"date":1,"text":"buy milk"}}`
+	require.NoError(t, chatbot.HandleWebhook([]byte(update)))
+
+	messages := eventBus.GetPublishedEvents(events.TopicMessageReceived)
+	require.Len(t, messages, 1)
+	assert.Equal(t, 7, messages[0].(events.MessageReceived).ThreadID)
+
+	// Replies and reminders go to the topic
+	require.NoError(t, chatbot.SendMessage(common.ChatID(chatID), "Task saved"))
+	chatbot.handleReminderDue(events.ReminderDue{Event: events.NewEvent(), TaskID: "task-1", UserID: chatID, ChatID: chatID, ThreadID: 9})
+	require.NoError(t, chatbot.SendMessage("42", "Other chat"))
+	assert.Equal(t, []int{7, 9, 0}, provider.threads)
+}
@@ -0,0 +1,43 @@
+package chatbot
+
+import (
+	"fmt"
+	"testing"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/featureflags"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestChatbotService_GroupModeFlag(t *testing.T) {
+	eventBus := events.NewMockEventBus()
+	eventBus.SetSynchronousMode(true)
+	chatbot, _ := newBenchService(eventBus, zaptest.NewLogger(t))
+	member, err := chatbot.identities.Resolve("", 4242)
+	require.NoError(t, err)
+	chatbot.flags = featureflags.CheckerFunc(func(flag string, userID common.UserID) bool {
+		return flag == featureflags.FlagGroupMode && userID == common.UserID(member)
+	})
+
+	message := func(from, chat int64, chatType string) []byte {
+		return []byte(fmt.Sprintf(`{"update_id":1,"message":{"message_id":5,"from":{"id":%d,"first_name":"Ann"},"chat":{"id":%d,"type":%q},`+
+			`"date":1,"text":"buy milk tomorrow"}}`, from, chat, chatType))
+	}
+
+	// Without the flag the bot stays quiet in groups but answers in private
+	require.NoError(t, chatbot.HandleWebhook(message(5151, -100, "group")))
+	assert.Empty(t, eventBus.GetPublishedEvents(events.TopicMessageReceived))
+	require.NoError(t, chatbot.HandleWebhook(message(5151, 5151, "private")))
+	assert.Len(t, eventBus.GetPublishedEvents(events.TopicMessageReceived), 1)
+
+	// With the flag group messages are handled
+	eventBus.ClearEvents()
+	require.NoError(t, chatbot.HandleWebhook(message(4242, -100, "supergroup")))
+	received := eventBus.GetPublishedEvents(events.TopicMessageReceived)
+	require.Len(t, received, 1)
+	assert.Equal(t, member, received[0].(events.MessageReceived).UserID)
+}
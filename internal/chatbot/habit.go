@@ -0,0 +1,59 @@
+package chatbot
+
+import (
+	"fmt"
+	"html"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// habitNoun names one period of a habit, as in "5-day streak"
+func habitNoun(habit string) string {
+	if habit == "weekly" {
+		return "week"
+	}
+	return "day"
+}
+
+// formatHabit renders a habit's period and current streak for the task list and details
+func formatHabit(task events.TaskSummary) string {
+	label := "🔁 Daily"
+	if task.Habit == "weekly" {
+		label = "🔁 Weekly"
+	}
+	if task.Streak > 0 {
+		label += fmt.Sprintf(" · 🔥 %d-%s streak", task.Streak, habitNoun(task.Habit))
+	}
+	return label
+}
+
+// formatHabitMissed is the nudge sent when a habit's period ended undone
+func formatHabitMissed(event events.HabitMissed) string {
+	when, next := "yesterday", "Today"
+	if event.Habit == "weekly" {
+		when, next = "last week", "This week"
+	}
+
+	text := fmt.Sprintf("💤 You missed <b>%s</b> %s", html.EscapeString(event.Title), when)
+	if event.LostStreak > 1 {
+		text += fmt.Sprintf(", so your %d-%s streak starts over", event.LostStreak, habitNoun(event.Habit))
+	}
+	return text + fmt.Sprintf(".\n\n%s is a fresh start. Tap Done when it's done 💪", next)
+}
+
+// handleHabitMissed nudges the user about a habit period that ended undone
+func (s *chatbotService) handleHabitMissed(event events.HabitMissed) {
+	if !s.ownsUser(event.UserID) || event.Muted || event.ChatID == "" {
+		return
+	}
+
+	keyboard := s.keyboardBuilder.ToDomainKeyboard(s.keyboardBuilder.BuildReminderKeyboard(event.TaskID, ""))
+	if err := s.SendMessageWithKeyboard(common.ChatID(event.ChatID), formatHabitMissed(event), keyboard); err != nil {
+		s.logger.Error("Failed to send missed habit nudge",
+			zap.String("task_id", event.TaskID),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,24 @@
+package chatbot
+
+import (
+	"testing"
+
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatHabitMissed(t *testing.T) {
+	text := formatHabitMissed(events.HabitMissed{Title: "Run <5k>", Habit: "daily", LostStreak: 5})
+	assert.Contains(t, text, "You missed <b>Run &lt;5k&gt;</b> yesterday, so your 5-day streak starts over.")
+	assert.Contains(t, text, "Today is a fresh start.")
+
+	text = formatHabitMissed(events.HabitMissed{Title: "Call grandma", Habit: "weekly"})
+	assert.Contains(t, text, "You missed <b>Call grandma</b> last week.")
+	assert.Contains(t, text, "This week is a fresh start.")
+}
+
+func TestFormatHabit(t *testing.T) {
+	assert.Equal(t, "🔁 Daily · 🔥 3-day streak", formatHabit(events.TaskSummary{Habit: "daily", Streak: 3}))
+	assert.Equal(t, "🔁 Weekly", formatHabit(events.TaskSummary{Habit: "weekly"}))
+}
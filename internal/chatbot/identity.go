@@ -0,0 +1,279 @@
+package chatbot
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrIdentityNotFound is returned for internal IDs with no Telegram identity
+var ErrIdentityNotFound = errors.New("telegram identity not found")
+
+// TelegramIdentity maps a Telegram user or chat ID, as seen by a bot, to the
+// internal ID used for it everywhere else. Telegram gives a private chat the
+// ID of its user, so both share one identity.
+type TelegramIdentity struct {
+	Bot        string    `gorm:"type:varchar(64);primaryKey" json:"bot"`
+	TelegramID int64     `gorm:"primaryKey;autoIncrement:false" json:"telegram_id"`
+	ID         string    `gorm:"type:uuid;not null;index" json:"id"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName returns the table name for the TelegramIdentity model
+func (TelegramIdentity) TableName() string {
+	return "telegram_identities"
+}
+
+// IdentityMap translates between Telegram IDs and internal IDs. New Telegram
+// IDs are assigned DeriveInternalID, so IDs stored before the map existed
+// stay valid.
+type IdentityMap interface {
+	// Resolve returns the internal ID of a Telegram ID seen by the bot, recording new ones
+	Resolve(bot string, telegramID int64) (string, error)
+	// TelegramID returns the Telegram ID behind an internal ID
+	TelegramID(id string) (int64, error)
+	// Assign maps a Telegram ID seen by the bot to an internal ID
+	Assign(bot string, telegramID int64, id string) error
+}
+
+// DeriveInternalID returns the internal ID first assigned to a Telegram ID
+// seen by the bot; the default bot is named ""
+func DeriveInternalID(bot string, telegramID int64) string {
+	return scopedTelegramIDToUUID(bot, telegramID)
+}
+
+// memoryIdentityMap implements IdentityMap in memory
+type memoryIdentityMap struct {
+	mu         sync.RWMutex
+	internal   map[string]map[int64]string
+	telegramID map[string]int64
+}
+
+// NewMemoryIdentityMap creates an IdentityMap that is not persisted
+func NewMemoryIdentityMap() IdentityMap {
+	return &memoryIdentityMap{
+		internal:   make(map[string]map[int64]string),
+		telegramID: make(map[string]int64),
+	}
+}
+
+// Resolve returns the internal ID of a Telegram ID seen by the bot
+func (m *memoryIdentityMap) Resolve(bot string, telegramID int64) (string, error) {
+	m.mu.RLock()
+	id, ok := m.internal[bot][telegramID]
+	m.mu.RUnlock()
+	if ok {
+		return id, nil
+	}
+
+	id = DeriveInternalID(bot, telegramID)
+	m.set(bot, telegramID, id)
+	return id, nil
+}
+
+// TelegramID returns the Telegram ID behind an internal ID
+func (m *memoryIdentityMap) TelegramID(id string) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	telegramID, ok := m.telegramID[id]
+	if !ok {
+		return 0, ErrIdentityNotFound
+	}
+	return telegramID, nil
+}
+
+// Assign maps a Telegram ID seen by the bot to an internal ID. An internal
+// ID that already has a Telegram ID keeps it, so a user that accounts were
+// merged into is still messaged on their own account.
+func (m *memoryIdentityMap) Assign(bot string, telegramID int64, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.setInternal(bot, telegramID, id)
+	if _, ok := m.telegramID[id]; !ok {
+		m.telegramID[id] = telegramID
+	}
+	return nil
+}
+
+// set records a mapping in both directions
+func (m *memoryIdentityMap) set(bot string, telegramID int64, id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.setInternal(bot, telegramID, id)
+	m.telegramID[id] = telegramID
+}
+
+// setInternal records the internal ID of a Telegram ID; m.mu must be held
+func (m *memoryIdentityMap) setInternal(bot string, telegramID int64, id string) {
+	if m.internal[bot] == nil {
+		m.internal[bot] = make(map[int64]string)
+	}
+	// A reassigned Telegram ID no longer answers for its previous internal ID
+	if previous, ok := m.internal[bot][telegramID]; ok && previous != id && m.telegramID[previous] == telegramID {
+		delete(m.telegramID, previous)
+	}
+	m.internal[bot][telegramID] = id
+}
+
+// gormIdentityMap implements IdentityMap using GORM, caching lookups
+type gormIdentityMap struct {
+	db     *gorm.DB
+	logger *zap.Logger
+	cache  *memoryIdentityMap
+}
+
+// NewGormIdentityMap creates a GORM-based identity map
+func NewGormIdentityMap(db *gorm.DB, logger *zap.Logger) IdentityMap {
+	return &gormIdentityMap{
+		db:     db,
+		logger: logger,
+		cache:  NewMemoryIdentityMap().(*memoryIdentityMap),
+	}
+}
+
+// Resolve returns the internal ID of a Telegram ID seen by the bot
+func (m *gormIdentityMap) Resolve(bot string, telegramID int64) (string, error) {
+	m.cache.mu.RLock()
+	id, ok := m.cache.internal[bot][telegramID]
+	m.cache.mu.RUnlock()
+	if ok {
+		return id, nil
+	}
+
+	// The stored mapping wins over the derived ID, which only seeds new rows
+	identity := &TelegramIdentity{Bot: bot, TelegramID: telegramID, ID: DeriveInternalID(bot, telegramID)}
+	err := m.db.Clauses(clause.OnConflict{DoNothing: true}).Create(identity).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to record telegram identity: %w", err)
+	}
+
+	var stored TelegramIdentity
+	err = m.db.Where("bot = ? AND telegram_id = ?", bot, telegramID).First(&stored).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to look up telegram identity: %w", err)
+	}
+
+	m.cache.set(bot, telegramID, stored.ID)
+	return stored.ID, nil
+}
+
+// TelegramID returns the Telegram ID behind an internal ID
+func (m *gormIdentityMap) TelegramID(id string) (int64, error) {
+	if telegramID, err := m.cache.TelegramID(id); err == nil {
+		return telegramID, nil
+	}
+
+	// Several accounts can share an internal ID; the latest is current
+	var identities []TelegramIdentity
+	err := m.db.Where("id = ?", id).Order("created_at DESC").Limit(1).Find(&identities).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up telegram identity: %w", err)
+	}
+	if len(identities) == 0 {
+		return 0, ErrIdentityNotFound
+	}
+
+	identity := identities[0]
+	m.cache.set(identity.Bot, identity.TelegramID, identity.ID)
+	return identity.TelegramID, nil
+}
+
+// Assign maps a Telegram ID seen by the bot to an internal ID
+func (m *gormIdentityMap) Assign(bot string, telegramID int64, id string) error {
+	identity := &TelegramIdentity{Bot: bot, TelegramID: telegramID, ID: id}
+	err := m.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "bot"}, {Name: "telegram_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"id"}),
+	}).Create(identity).Error
+	if err != nil {
+		return fmt.Errorf("failed to assign telegram identity: %w", err)
+	}
+
+	// Only the forward lookup is cached; which account the internal ID is
+	// messaged on is left to the next TelegramID lookup
+	m.cache.mu.Lock()
+	m.cache.setInternal(bot, telegramID, id)
+	delete(m.cache.telegramID, id)
+	m.cache.mu.Unlock()
+	return nil
+}
+
+// handleAccountMerged points the merged user's Telegram accounts at the user they were merged into
+func (s *chatbotService) handleAccountMerged(event events.AccountMerged) {
+	s.reassignAccounts(event.Accounts, event.IntoUserID, event.CorrelationID)
+}
+
+// handleAccountMergeUndone points the Telegram accounts back at the merged user
+func (s *chatbotService) handleAccountMergeUndone(event events.AccountMergeUndone) {
+	s.reassignAccounts(event.Accounts, event.FromUserID, event.CorrelationID)
+}
+
+// reassignAccounts maps this bot's Telegram accounts to the user
+func (s *chatbotService) reassignAccounts(accounts []events.TelegramAccount, userID, correlationID string) {
+	for _, account := range accounts {
+		if account.Bot != s.config.Name {
+			continue
+		}
+		if err := s.identities.Assign(account.Bot, account.TelegramID, userID); err != nil {
+			s.logger.Error("Failed to reassign telegram account",
+				zap.String("correlation_id", correlationID),
+				zap.Int64("telegram_id", account.TelegramID),
+				zap.Error(err))
+		}
+	}
+}
+
+// resolveUserID maps the sender of an update to its internal user ID
+func (s *chatbotService) resolveUserID(update *tgbotapi.Update) (common.UserID, error) {
+	sender, err := s.parser.GetSender(update)
+	if err != nil {
+		return "", err
+	}
+
+	id, err := s.identities.Resolve(s.config.Name, sender.ID)
+	if err != nil {
+		return "", err
+	}
+	return common.UserID(id), nil
+}
+
+// resolveChatID maps the chat of an update to its internal chat ID
+func (s *chatbotService) resolveChatID(update *tgbotapi.Update) (common.ChatID, error) {
+	telegramChatID, err := s.parser.GetTelegramChatID(update)
+	if err != nil {
+		return "", err
+	}
+
+	id, err := s.identities.Resolve(s.config.Name, telegramChatID)
+	if err != nil {
+		return "", err
+	}
+	return common.ChatID(id), nil
+}
+
+// telegramChatID returns the Telegram chat to send to for an internal chat
+// ID. Numeric IDs are Telegram chat IDs already.
+func (s *chatbotService) telegramChatID(chatID string) (int64, error) {
+	if telegramChatID, err := strconv.ParseInt(chatID, 10, 64); err == nil {
+		return telegramChatID, nil
+	}
+
+	telegramChatID, err := s.identities.TelegramID(chatID)
+	if err != nil {
+		return 0, fmt.Errorf("invalid chat ID %s: %w", chatID, err)
+	}
+	return telegramChatID, nil
+}
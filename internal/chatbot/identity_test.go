@@ -0,0 +1,116 @@
+package chatbot
+
+import (
+	"testing"
+
+	"nudgebot-api/internal/config"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestMemoryIdentityMap(t *testing.T) {
+	identities := NewMemoryIdentityMap()
+
+	id, err := identities.Resolve("", 42)
+	require.NoError(t, err)
+	assert.Equal(t, DeriveInternalID("", 42), id)
+
+	// The same Telegram ID seen by another bot is another identity
+	scoped, err := identities.Resolve("acme", 42)
+	require.NoError(t, err)
+	assert.NotEqual(t, id, scoped)
+
+	telegramID, err := identities.TelegramID(scoped)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), telegramID)
+
+	_, err = identities.TelegramID(DeriveInternalID("", 7))
+	assert.ErrorIs(t, err, ErrIdentityNotFound)
+}
+
+func TestChatbotService_MergeKeepsTargetsAccount(t *testing.T) {
+	bus := events.NewMockEventBus()
+	bus.SetSynchronousMode(true)
+	service, _ := newBenchService(bus, zaptest.NewLogger(t))
+
+	into, err := service.identities.Resolve("", 42)
+	require.NoError(t, err)
+	from, err := service.identities.Resolve("", 7)
+	require.NoError(t, err)
+
+	accounts := []events.TelegramAccount{{TelegramID: 7}}
+	require.NoError(t, bus.Publish(events.TopicAccountMerged, events.AccountMerged{
+		Event: events.NewEvent(), MergeID: "merge", FromUserID: from, IntoUserID: into, Accounts: accounts,
+	}))
+
+	merged, err := service.identities.Resolve("", 7)
+	require.NoError(t, err)
+	assert.Equal(t, into, merged, "the merged account now acts as the target user")
+	telegramID, err := service.identities.TelegramID(into)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), telegramID, "the target user is still messaged on their own account")
+
+	require.NoError(t, bus.Publish(events.TopicAccountMergeUndone, events.AccountMergeUndone{
+		Event: events.NewEvent(), MergeID: "merge", FromUserID: from, IntoUserID: into, Accounts: accounts,
+	}))
+	telegramID, err = service.identities.TelegramID(from)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), telegramID)
+	telegramID, err = service.identities.TelegramID(into)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), telegramID)
+}
+
+func TestGormIdentityMap_AssignLeavesReverseLookupToDatabase(t *testing.T) {
+	// A dry run builds SQL without connecting to a database, so lookups the
+	// cache cannot answer find nothing
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+	})
+	require.NoError(t, err)
+	identities := NewGormIdentityMap(db, zaptest.NewLogger(t)).(*gormIdentityMap)
+
+	into := DeriveInternalID("", 42)
+	identities.cache.set("", 42, into)
+	require.NoError(t, identities.Assign("", 7, into))
+
+	_, err = identities.TelegramID(into)
+	assert.ErrorIs(t, err, ErrIdentityNotFound, "the merged account is not cached as the target's")
+	id, err := identities.Resolve("", 7)
+	require.NoError(t, err)
+	assert.Equal(t, into, id)
+}
+
+func TestChatbotService_SendsToResolvedChats(t *testing.T) {
+	provider := &listRecordingProvider{edited: make(map[int]string)}
+	service := &chatbotService{
+		logger:     zaptest.NewLogger(t),
+		provider:   provider,
+		parser:     NewWebhookParserForBot("acme"),
+		identities: NewMemoryIdentityMap(),
+		config:     config.ChatbotConfig{Name: "acme"},
+	}
+
+	update, err := service.parser.ParseUpdate([]byte(`{"update_id": 1, "message": {"message_id": 1, "from": {"id": 42}, "chat": {"id": -100, "type": "group"}, "text": "hi"}}`))
+	require.NoError(t, err)
+
+	userID, err := service.resolveUserID(update)
+	require.NoError(t, err)
+	assert.Equal(t, DeriveInternalID("acme", 42), string(userID))
+	chatID, err := service.resolveChatID(update)
+	require.NoError(t, err)
+
+	require.NoError(t, service.SendMessage(chatID, "hello"))
+	assert.Equal(t, []string{"hello"}, provider.messages)
+
+	// Numeric chat IDs are sent to as they are; unknown internal IDs are refused
+	require.NoError(t, service.SendMessage("42", "direct"))
+	assert.Error(t, service.SendMessage("00000000-0000-0000-0000-000000000000", "lost"))
+}
@@ -3,19 +3,34 @@ package chatbot
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
 	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 // KeyboardBuilder provides utilities for creating inline keyboards
-type KeyboardBuilder struct{}
+type KeyboardBuilder struct {
+	layout KeyboardLayout
+}
 
-// NewKeyboardBuilder creates a new KeyboardBuilder instance
+// NewKeyboardBuilder creates a new KeyboardBuilder instance with the default layout
 func NewKeyboardBuilder() *KeyboardBuilder {
-	return &KeyboardBuilder{}
+	return NewKeyboardBuilderWithLayout(DefaultKeyboardLayout())
+}
+
+// NewKeyboardBuilderWithLayout creates a KeyboardBuilder that arranges every
+// keyboard with the given layout rules
+func NewKeyboardBuilderWithLayout(layout KeyboardLayout) *KeyboardBuilder {
+	return &KeyboardBuilder{layout: layout}
+}
+
+// Layout returns the layout rules used by the builder
+func (kb *KeyboardBuilder) Layout() KeyboardLayout {
+	return kb.layout
 }
 
 // TaskSummary represents task information for keyboard display
@@ -34,158 +49,355 @@ const (
 	CallbackActionCancel   = "cancel"
 	CallbackActionList     = "list"
 	CallbackActionSnooze   = "snooze"
+	CallbackActionStart    = "start"
+	CallbackActionWait     = "wait"
+	CallbackActionHistory  = "history"
+	CallbackActionMute     = "mute"
+	CallbackActionUnmute   = "unmute"
+	CallbackActionViewTask = "view_task"
 	CallbackActionPrevPage = "prev_page"
 	CallbackActionNextPage = "next_page"
 	CallbackActionBack     = "back"
 	CallbackActionHelp     = "help"
+	CallbackActionNoop     = "noop"
+
+	// Task draft preview actions
+	CallbackActionDraftDue      = "draft_due"
+	CallbackActionDraftPriority = "draft_priority"
+	CallbackActionDraftTitle    = "draft_title"
+	CallbackActionDraftSave     = "draft_save"
+	CallbackActionDraftDiscard  = "draft_discard"
+
+	// Bulk reschedule confirmation actions
+	CallbackActionRescheduleApply  = "resched_apply"
+	CallbackActionRescheduleCancel = "resched_cancel"
+
+	// Saves a message the LLM could not parse as a plain task
+	CallbackActionPlainTask = "plain_task"
+
+	// Answers to a task handed off to the user
+	CallbackActionDelegationAccept  = "deleg_accept"
+	CallbackActionDelegationDecline = "deleg_decline"
+
+	// Starts a countdown to the due time of a task due within hours
+	CallbackActionCountdown = "countdown"
+
+	// Shows the full message a task was summarized from
+	CallbackActionOriginal = "original"
+
+	// Answers to a proposed day plan
+	CallbackActionPlanAccept     = "plan_accept"
+	CallbackActionPlanRegenerate = "plan_regen"
+
+	// Opens a page of the /settings menu, and saves an option chosen on it
+	CallbackActionSettings    = "settings"
+	CallbackActionSettingsSet = "settings_set"
 )
 
-// BuildTaskActionKeyboard creates Done/Delete buttons for a specific task
+// BuildTaskActionKeyboard creates Done/Delete/Snooze buttons and a second row of
+// Start/Wait/History buttons for a specific task
 func (kb *KeyboardBuilder) BuildTaskActionKeyboard(taskID string) tgbotapi.InlineKeyboardMarkup {
-	doneData := kb.encodeCallbackData(CallbackActionDone, map[string]string{
-		"task_id": taskID,
-	})
+	taskData := map[string]string{"task_id": taskID}
+
+	return tgbotapi.NewInlineKeyboardMarkup(kb.layout.Render([]ButtonSpec{
+		{Emoji: "✅", Text: "Done", CallbackData: kb.encodeCallbackData(CallbackActionDone, taskData)},
+		{Emoji: "❌", Text: "Delete", CallbackData: kb.encodeCallbackData(CallbackActionDelete, taskData)},
+		{Emoji: "⏰", Text: "Snooze", CallbackData: kb.encodeCallbackData(CallbackActionSnooze, taskData)},
+		{Emoji: "▶️", Text: "Start", CallbackData: kb.encodeCallbackData(CallbackActionStart, taskData), NewRow: true},
+		{Emoji: "⏳", Text: "Wait", CallbackData: kb.encodeCallbackData(CallbackActionWait, taskData)},
+		{Emoji: "🕘", Text: "History", CallbackData: kb.encodeCallbackData(CallbackActionHistory, taskData)},
+	})...)
+}
 
-	deleteData := kb.encodeCallbackData(CallbackActionDelete, map[string]string{
-		"task_id": taskID,
-	})
+// BuildTaskActionKeyboardWithCalendar adds an "Add to calendar" link below the
+// task action buttons; without a calendar URL it is BuildTaskActionKeyboard
+func (kb *KeyboardBuilder) BuildTaskActionKeyboardWithCalendar(taskID, calendarURL string) tgbotapi.InlineKeyboardMarkup {
+	markup := kb.BuildTaskActionKeyboard(taskID)
+	if calendarURL == "" {
+		return markup
+	}
 
-	snoozeData := kb.encodeCallbackData(CallbackActionSnooze, map[string]string{
-		"task_id": taskID,
-	})
+	markup.InlineKeyboard = append(markup.InlineKeyboard, kb.layout.Render([]ButtonSpec{
+		{Emoji: "📅", Text: "Add to calendar", URL: calendarURL},
+	})...)
+	return markup
+}
 
-	return tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("✅ Done", doneData),
-			tgbotapi.NewInlineKeyboardButtonData("❌ Delete", deleteData),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("⏰ Snooze", snoozeData),
-		),
-	)
+// BuildReminderKeyboard creates the task action buttons for a reminder, with a
+// Mute button that stops further reminders for the task and, for timed tasks,
+// an "Add to calendar" link
+func (kb *KeyboardBuilder) BuildReminderKeyboard(taskID, calendarURL string) tgbotapi.InlineKeyboardMarkup {
+	markup := kb.BuildTaskActionKeyboard(taskID)
+
+	buttons := []ButtonSpec{
+		{Emoji: "🔕", Text: "Mute", CallbackData: kb.encodeCallbackData(CallbackActionMute, map[string]string{"task_id": taskID})},
+	}
+	if calendarURL != "" {
+		buttons = append(buttons, ButtonSpec{Emoji: "📅", Text: "Add to calendar", URL: calendarURL})
+	}
+	markup.InlineKeyboard = append(markup.InlineKeyboard, kb.layout.Render(buttons)...)
+	return markup
+}
+
+// BuildTaskDetailsKeyboard creates the task action buttons for a task opened
+// from the task list, with a Mute or Unmute button matching its reminders and
+// a "Show original" button for tasks summarized from a long message
+func (kb *KeyboardBuilder) BuildTaskDetailsKeyboard(task events.TaskSummary) tgbotapi.InlineKeyboardMarkup {
+	markup := kb.BuildTaskActionKeyboard(task.ID)
+
+	taskData := map[string]string{"task_id": task.ID}
+	toggle := ButtonSpec{Emoji: "🔕", Text: "Mute", CallbackData: kb.encodeCallbackData(CallbackActionMute, taskData)}
+	if task.Muted {
+		toggle = ButtonSpec{Emoji: "🔔", Text: "Unmute", CallbackData: kb.encodeCallbackData(CallbackActionUnmute, taskData)}
+	}
+	markup.InlineKeyboard = append(markup.InlineKeyboard, kb.layout.Render([]ButtonSpec{toggle})...)
+	if task.HasOriginal {
+		markup = kb.AddOriginalButton(markup, task.ID)
+	}
+	return markup
+}
+
+// BuildReminderGroupKeyboard creates a row per reminded task, with a Done
+// button labeled by the task's number and title, a Snooze and a Mute button
+func (kb *KeyboardBuilder) BuildReminderGroupKeyboard(reminders []events.ReminderDue) tgbotapi.InlineKeyboardMarkup {
+	var buttons []ButtonSpec
+	for i, reminder := range reminders {
+		taskData := map[string]string{"task_id": reminder.TaskID}
+		buttons = append(buttons,
+			ButtonSpec{
+				Emoji:        "✅",
+				Text:         fmt.Sprintf("%d. %s", i+1, reminderTitle(reminder)),
+				CallbackData: kb.encodeCallbackData(CallbackActionDone, taskData),
+				NewRow:       true,
+			},
+			ButtonSpec{Emoji: "⏰", CallbackData: kb.encodeCallbackData(CallbackActionSnooze, taskData)},
+			ButtonSpec{Emoji: "🔕", CallbackData: kb.encodeCallbackData(CallbackActionMute, taskData)},
+		)
+	}
+
+	// Each task keeps its buttons on one row, whatever the row width
+	return tgbotapi.NewInlineKeyboardMarkup(kb.layout.Unbounded().Render(buttons)...)
 }
 
 // BuildTaskListKeyboard creates a paginated task list with action buttons
 func (kb *KeyboardBuilder) BuildTaskListKeyboard(tasks []TaskSummary, currentPage, totalPages int) tgbotapi.InlineKeyboardMarkup {
-	var rows [][]tgbotapi.InlineKeyboardButton
-
-	// Add task buttons (max 5 per page)
-	const tasksPerPage = 5
-	startIdx := currentPage * tasksPerPage
-	endIdx := startIdx + tasksPerPage
+	// Add task buttons for the current page, one task per row
+	startIdx := currentPage * TasksPerPage
+	endIdx := startIdx + TasksPerPage
 	if endIdx > len(tasks) {
 		endIdx = len(tasks)
 	}
 
+	var taskButtons []ButtonSpec
 	for i := startIdx; i < endIdx; i++ {
 		task := tasks[i]
-		buttonText := fmt.Sprintf("📋 %s", truncateText(task.Title, 30))
-
-		callbackData := kb.encodeCallbackData("view_task", map[string]string{
-			"task_id": string(task.ID),
+		taskButtons = append(taskButtons, ButtonSpec{
+			Emoji: "📋",
+			Text:  task.Title,
+			CallbackData: kb.encodeCallbackData(CallbackActionViewTask, map[string]string{
+				"task_id": string(task.ID),
+			}),
+			NewRow: true,
 		})
-
-		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData(buttonText, callbackData),
-		))
 	}
+	rows := kb.layout.Render(taskButtons)
 
 	// Add pagination row if needed
 	if totalPages > 1 {
-		var paginationRow []tgbotapi.InlineKeyboardButton
-
-		if currentPage > 0 {
-			prevData := kb.encodeCallbackData(CallbackActionPrevPage, map[string]string{
-				"page": fmt.Sprintf("%d", currentPage-1),
-			})
-			paginationRow = append(paginationRow, tgbotapi.NewInlineKeyboardButtonData("⬅️ Prev", prevData))
-		}
-
-		// Page indicator
-		pageText := fmt.Sprintf("%d/%d", currentPage+1, totalPages)
-		paginationRow = append(paginationRow, tgbotapi.NewInlineKeyboardButtonData(pageText, "noop"))
-
-		if currentPage < totalPages-1 {
-			nextData := kb.encodeCallbackData(CallbackActionNextPage, map[string]string{
-				"page": fmt.Sprintf("%d", currentPage+1),
-			})
-			paginationRow = append(paginationRow, tgbotapi.NewInlineKeyboardButtonData("➡️ Next", nextData))
-		}
-
-		rows = append(rows, paginationRow)
+		rows = append(rows, kb.paginationRow(currentPage, totalPages, "Prev", "Next", nil)...)
 	}
 
 	// Add back button
-	backData := kb.encodeCallbackData(CallbackActionBack, map[string]string{})
-	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
-		tgbotapi.NewInlineKeyboardButtonData("🔙 Back", backData),
-	))
+	rows = append(rows, kb.layout.Render([]ButtonSpec{
+		{Emoji: "🔙", Text: "Back", CallbackData: kb.encodeCallbackData(CallbackActionBack, map[string]string{})},
+	})...)
 
 	return tgbotapi.NewInlineKeyboardMarkup(rows...)
 }
 
 // BuildConfirmationKeyboard creates a confirmation dialog with Yes/No buttons
 func (kb *KeyboardBuilder) BuildConfirmationKeyboard(action string, taskID string) tgbotapi.InlineKeyboardMarkup {
-	confirmData := kb.encodeCallbackData(CallbackActionConfirm, map[string]string{
+	actionData := map[string]string{
 		"action":  action,
 		"task_id": taskID,
-	})
+	}
 
-	cancelData := kb.encodeCallbackData(CallbackActionCancel, map[string]string{
-		"action":  action,
-		"task_id": taskID,
+	return tgbotapi.NewInlineKeyboardMarkup(kb.layout.Render([]ButtonSpec{
+		{Emoji: "✅", Text: "Yes", CallbackData: kb.encodeCallbackData(CallbackActionConfirm, actionData)},
+		{Emoji: "❌", Text: "No", CallbackData: kb.encodeCallbackData(CallbackActionCancel, actionData)},
+	})...)
+}
+
+// BuildTaskDraftKeyboard creates the edit buttons shown under a parsed task preview
+func (kb *KeyboardBuilder) BuildTaskDraftKeyboard(draftID string) tgbotapi.InlineKeyboardMarkup {
+	draftData := map[string]string{"id": draftID}
+
+	return tgbotapi.NewInlineKeyboardMarkup(kb.layout.Render([]ButtonSpec{
+		{Emoji: "📅", Text: "+1 day", CallbackData: kb.encodeCallbackData(CallbackActionDraftDue, map[string]string{"id": draftID, "add": "1d"})},
+		{Emoji: "📅", Text: "+1 week", CallbackData: kb.encodeCallbackData(CallbackActionDraftDue, map[string]string{"id": draftID, "add": "1w"})},
+		{Emoji: "🏷", Text: "Priority", CallbackData: kb.encodeCallbackData(CallbackActionDraftPriority, draftData), NewRow: true},
+		{Emoji: "✏️", Text: "Title", CallbackData: kb.encodeCallbackData(CallbackActionDraftTitle, draftData)},
+		{Emoji: "💾", Text: "Save", CallbackData: kb.encodeCallbackData(CallbackActionDraftSave, draftData), NewRow: true},
+		{Emoji: "🗑", Text: "Discard", CallbackData: kb.encodeCallbackData(CallbackActionDraftDiscard, draftData)},
+	})...)
+}
+
+// BuildRescheduleKeyboard creates the Confirm/Cancel buttons under a bulk reschedule preview
+func (kb *KeyboardBuilder) BuildRescheduleKeyboard(rescheduleID string) tgbotapi.InlineKeyboardMarkup {
+	rescheduleData := map[string]string{"id": rescheduleID}
+
+	return tgbotapi.NewInlineKeyboardMarkup(kb.layout.Render([]ButtonSpec{
+		{Emoji: "✅", Text: "Confirm", CallbackData: kb.encodeCallbackData(CallbackActionRescheduleApply, rescheduleData)},
+		{Emoji: "❌", Text: "Cancel", CallbackData: kb.encodeCallbackData(CallbackActionRescheduleCancel, rescheduleData)},
+	})...)
+}
+
+// BuildPlainTaskKeyboard creates the button under a parse failure notice that
+// saves the message as a plain task
+func (kb *KeyboardBuilder) BuildPlainTaskKeyboard(failureID string) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(kb.layout.Render([]ButtonSpec{
+		{Emoji: "📝", Text: "Create as plain task", CallbackData: kb.encodeCallbackData(CallbackActionPlainTask, map[string]string{"id": failureID})},
+	})...)
+}
+
+// BuildDelegationKeyboard creates the Accept/Decline buttons under a task handed off to the user
+func (kb *KeyboardBuilder) BuildDelegationKeyboard(taskID string) tgbotapi.InlineKeyboardMarkup {
+	taskData := map[string]string{"task_id": taskID}
+
+	return tgbotapi.NewInlineKeyboardMarkup(kb.layout.Render([]ButtonSpec{
+		{Emoji: "✅", Text: "Accept", CallbackData: kb.encodeCallbackData(CallbackActionDelegationAccept, taskData)},
+		{Emoji: "🙅", Text: "Decline", CallbackData: kb.encodeCallbackData(CallbackActionDelegationDecline, taskData)},
+	})...)
+}
+
+// AddCountdownButton adds a button that starts a countdown for the task below
+// the buttons of a keyboard
+func (kb *KeyboardBuilder) AddCountdownButton(markup tgbotapi.InlineKeyboardMarkup, taskID string) tgbotapi.InlineKeyboardMarkup {
+	markup.InlineKeyboard = append(markup.InlineKeyboard, kb.layout.Render([]ButtonSpec{
+		{Emoji: "⏳", Text: "Countdown", CallbackData: kb.encodeCallbackData(CallbackActionCountdown, map[string]string{"task_id": taskID})},
+	})...)
+	return markup
+}
+
+// AddOriginalButton adds a button that shows the full message a task was
+// summarized from below the buttons of a keyboard
+func (kb *KeyboardBuilder) AddOriginalButton(markup tgbotapi.InlineKeyboardMarkup, taskID string) tgbotapi.InlineKeyboardMarkup {
+	markup.InlineKeyboard = append(markup.InlineKeyboard, kb.layout.Render([]ButtonSpec{
+		{Emoji: "📄", Text: "Show original", CallbackData: kb.encodeCallbackData(CallbackActionOriginal, map[string]string{"task_id": taskID})},
+	})...)
+	return markup
+}
+
+// BuildPlanKeyboard creates Accept/Regenerate buttons for a proposed day plan
+func (kb *KeyboardBuilder) BuildPlanKeyboard(planID string) tgbotapi.InlineKeyboardMarkup {
+	planData := map[string]string{"id": planID}
+
+	return tgbotapi.NewInlineKeyboardMarkup(kb.layout.Render([]ButtonSpec{
+		{Emoji: "✅", Text: "Accept", CallbackData: kb.encodeCallbackData(CallbackActionPlanAccept, planData)},
+		{Emoji: "🔄", Text: "Regenerate", CallbackData: kb.encodeCallbackData(CallbackActionPlanRegenerate, planData)},
+	})...)
+}
+
+// BuildPlanRetryKeyboard creates the Regenerate button under a plan that could not be read
+func (kb *KeyboardBuilder) BuildPlanRetryKeyboard(planID string) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(kb.layout.Render([]ButtonSpec{
+		{Emoji: "🔄", Text: "Regenerate", CallbackData: kb.encodeCallbackData(CallbackActionPlanRegenerate, map[string]string{"id": planID})},
+	})...)
+}
+
+// BuildSettingsKeyboard creates the buttons of the /settings menu: one per
+// setting on the summary, or a setting's options, its current one ticked,
+// with a Back button
+func (kb *KeyboardBuilder) BuildSettingsKeyboard(field string, settings events.UserSettings) tgbotapi.InlineKeyboardMarkup {
+	page, ok := findSettingPage(field)
+	if !ok {
+		buttons := make([]ButtonSpec, 0, len(settingPages))
+		for i, page := range settingPages {
+			buttons = append(buttons, ButtonSpec{
+				Emoji:        page.Emoji,
+				Text:         page.Title,
+				CallbackData: kb.encodeCallbackData(CallbackActionSettings, map[string]string{"p": page.Field}),
+				NewRow:       i%2 == 0,
+			})
+		}
+		return tgbotapi.NewInlineKeyboardMarkup(kb.layout.Render(buttons)...)
+	}
+
+	buttons := make([]ButtonSpec, 0, len(page.Options)+1)
+	for i, option := range page.Options {
+		button := ButtonSpec{
+			Text:         option.Label,
+			CallbackData: kb.encodeCallbackData(CallbackActionSettingsSet, map[string]string{"f": page.Field, "i": strconv.Itoa(i)}),
+			NewRow:       i%2 == 0,
+		}
+		if option.isCurrent(page.Field, settings) {
+			button.Emoji = "✓"
+		}
+		buttons = append(buttons, button)
+	}
+	buttons = append(buttons, ButtonSpec{
+		Emoji:        "◀️",
+		Text:         "Back",
+		CallbackData: kb.encodeCallbackData(CallbackActionSettings, map[string]string{}),
+		NewRow:       true,
 	})
+	return tgbotapi.NewInlineKeyboardMarkup(kb.layout.Render(buttons)...)
+}
 
-	return tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("✅ Yes", confirmData),
-			tgbotapi.NewInlineKeyboardButtonData("❌ No", cancelData),
-		),
-	)
+// BuildCountdownKeyboard creates the Done button under a running countdown
+func (kb *KeyboardBuilder) BuildCountdownKeyboard(taskID string) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(kb.layout.Render([]ButtonSpec{
+		{Emoji: "✅", Text: "Done", CallbackData: kb.encodeCallbackData(CallbackActionDone, map[string]string{"task_id": taskID})},
+	})...)
 }
 
 // BuildMainMenuKeyboard creates the main bot menu with common actions
 func (kb *KeyboardBuilder) BuildMainMenuKeyboard() tgbotapi.InlineKeyboardMarkup {
-	listData := kb.encodeCallbackData(CallbackActionList, map[string]string{})
-	helpData := kb.encodeCallbackData(CallbackActionHelp, map[string]string{})
+	return tgbotapi.NewInlineKeyboardMarkup(kb.layout.Render([]ButtonSpec{
+		{Emoji: "📋", Text: "My Tasks", CallbackData: kb.encodeCallbackData(CallbackActionList, map[string]string{})},
+		{Emoji: "❓", Text: "Help", CallbackData: kb.encodeCallbackData(CallbackActionHelp, map[string]string{}), NewRow: true},
+	})...)
+}
 
+// BuildPaginationKeyboard creates navigation buttons for pagination
+func (kb *KeyboardBuilder) BuildPaginationKeyboard(currentPage, totalPages int, baseCallback string) tgbotapi.InlineKeyboardMarkup {
 	return tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("📋 My Tasks", listData),
-		),
-		tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonData("❓ Help", helpData),
-		),
+		kb.paginationRow(currentPage, totalPages, "", "", map[string]string{"callback": baseCallback})...,
 	)
 }
 
-// BuildPaginationKeyboard creates navigation buttons for pagination
-func (kb *KeyboardBuilder) BuildPaginationKeyboard(currentPage, totalPages int, baseCallback string) tgbotapi.InlineKeyboardMarkup {
-	var buttons []tgbotapi.InlineKeyboardButton
+// paginationRow builds the Prev / page indicator / Next controls. They always
+// share one row regardless of the configured row width.
+func (kb *KeyboardBuilder) paginationRow(currentPage, totalPages int, prevText, nextText string, extra map[string]string) [][]tgbotapi.InlineKeyboardButton {
+	pageData := func(page int) map[string]string {
+		data := map[string]string{"page": fmt.Sprintf("%d", page)}
+		for key, value := range extra {
+			data[key] = value
+		}
+		return data
+	}
 
+	var buttons []ButtonSpec
 	if currentPage > 0 {
-		prevData := kb.encodeCallbackData(CallbackActionPrevPage, map[string]string{
-			"page":     fmt.Sprintf("%d", currentPage-1),
-			"callback": baseCallback,
+		buttons = append(buttons, ButtonSpec{
+			Emoji:        "⬅️",
+			Text:         prevText,
+			CallbackData: kb.encodeCallbackData(CallbackActionPrevPage, pageData(currentPage-1)),
 		})
-		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData("⬅️", prevData))
 	}
 
 	// Page indicator
-	pageText := fmt.Sprintf("%d/%d", currentPage+1, totalPages)
-	buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData(pageText, "noop"))
+	buttons = append(buttons, ButtonSpec{Text: fmt.Sprintf("%d/%d", currentPage+1, totalPages), CallbackData: CallbackActionNoop})
 
 	if currentPage < totalPages-1 {
-		nextData := kb.encodeCallbackData(CallbackActionNextPage, map[string]string{
-			"page":     fmt.Sprintf("%d", currentPage+1),
-			"callback": baseCallback,
+		buttons = append(buttons, ButtonSpec{
+			Emoji:        "➡️",
+			Text:         nextText,
+			CallbackData: kb.encodeCallbackData(CallbackActionNextPage, pageData(currentPage+1)),
 		})
-		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData("➡️", nextData))
 	}
 
-	return tgbotapi.NewInlineKeyboardMarkup(
-		tgbotapi.NewInlineKeyboardRow(buttons...),
-	)
+	return kb.layout.Unbounded().Render(buttons)
 }
 
 // ConvertDomainKeyboard converts domain InlineKeyboard to Telegram format
@@ -213,6 +425,29 @@ func (kb *KeyboardBuilder) ConvertDomainKeyboard(domainKeyboard InlineKeyboard)
 	return tgbotapi.NewInlineKeyboardMarkup(rows...)
 }
 
+// ToDomainKeyboard converts a Telegram keyboard to the domain InlineKeyboard format
+func (kb *KeyboardBuilder) ToDomainKeyboard(markup tgbotapi.InlineKeyboardMarkup) InlineKeyboard {
+	domainKeyboard := InlineKeyboard{
+		Buttons: make([][]InlineKeyboardButton, len(markup.InlineKeyboard)),
+	}
+
+	for i, row := range markup.InlineKeyboard {
+		domainKeyboard.Buttons[i] = make([]InlineKeyboardButton, len(row))
+		for j, button := range row {
+			domainButton := InlineKeyboardButton{Text: button.Text}
+			if button.CallbackData != nil {
+				domainButton.CallbackData = *button.CallbackData
+			}
+			if button.URL != nil {
+				domainButton.URL = *button.URL
+			}
+			domainKeyboard.Buttons[i][j] = domainButton
+		}
+	}
+
+	return domainKeyboard
+}
+
 // encodeCallbackData encodes callback data as JSON string
 func (kb *KeyboardBuilder) encodeCallbackData(action string, data map[string]string) string {
 	callbackData := CallbackData{
@@ -248,16 +483,3 @@ func (kb *KeyboardBuilder) DecodeCallbackData(callbackDataStr string) (*Callback
 
 	return &callbackData, nil
 }
-
-// truncateText truncates text to specified length with ellipsis
-func truncateText(text string, maxLength int) string {
-	if len(text) <= maxLength {
-		return text
-	}
-
-	if maxLength <= 3 {
-		return text[:maxLength]
-	}
-
-	return text[:maxLength-3] + "..."
-}
@@ -0,0 +1,146 @@
+package chatbot
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"nudgebot-api/internal/config"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// Default keyboard layout rules
+const (
+	DefaultMaxButtonsPerRow = 2
+	DefaultMaxLabelLength   = 30
+)
+
+// ButtonSpec declares a button independently of how it is laid out
+type ButtonSpec struct {
+	Emoji        string
+	Text         string
+	CallbackData string
+	URL          string
+	// NewRow starts a new row with this button
+	NewRow bool
+}
+
+// KeyboardLayout holds the rules used to turn button specs into keyboard rows
+type KeyboardLayout struct {
+	// MaxButtonsPerRow wraps rows longer than this; 0 leaves rows unbounded
+	MaxButtonsPerRow int
+	// MaxLabelLength truncates label text to this many characters; 0 disables truncation
+	MaxLabelLength int
+	// ShowEmoji prefixes labels with their emoji
+	ShowEmoji bool
+}
+
+// DefaultKeyboardLayout returns the layout used when nothing is configured
+func DefaultKeyboardLayout() KeyboardLayout {
+	return KeyboardLayout{
+		MaxButtonsPerRow: DefaultMaxButtonsPerRow,
+		MaxLabelLength:   DefaultMaxLabelLength,
+		ShowEmoji:        true,
+	}
+}
+
+// NewKeyboardLayoutFromConfig builds a layout from configuration. An empty
+// configuration yields the default layout.
+func NewKeyboardLayoutFromConfig(cfg config.KeyboardConfig) KeyboardLayout {
+	if cfg == (config.KeyboardConfig{}) {
+		return DefaultKeyboardLayout()
+	}
+
+	layout := KeyboardLayout{
+		MaxButtonsPerRow: cfg.MaxButtonsPerRow,
+		MaxLabelLength:   cfg.MaxLabelLength,
+		ShowEmoji:        cfg.ShowEmoji,
+	}
+	if layout.MaxButtonsPerRow < 0 {
+		layout.MaxButtonsPerRow = DefaultMaxButtonsPerRow
+	}
+	if layout.MaxLabelLength < 0 {
+		layout.MaxLabelLength = DefaultMaxLabelLength
+	}
+	return layout
+}
+
+// Unbounded returns a copy of the layout that never wraps rows, for controls
+// such as pagination that must stay on one line
+func (l KeyboardLayout) Unbounded() KeyboardLayout {
+	l.MaxButtonsPerRow = 0
+	return l
+}
+
+// Label renders the button text according to the emoji and truncation rules.
+// Emoji-only buttons keep their emoji even when emoji are turned off.
+func (l KeyboardLayout) Label(spec ButtonSpec) string {
+	text := spec.Text
+	if l.MaxLabelLength > 0 {
+		text = truncateText(text, l.MaxLabelLength)
+	}
+
+	if spec.Emoji == "" || (!l.ShowEmoji && text != "") {
+		return text
+	}
+	if text == "" {
+		return spec.Emoji
+	}
+	return spec.Emoji + " " + text
+}
+
+// Rows groups button specs into rows, starting a new row when a spec asks for
+// one or the current row is full
+func (l KeyboardLayout) Rows(specs []ButtonSpec) [][]ButtonSpec {
+	var rows [][]ButtonSpec
+
+	for _, spec := range specs {
+		last := len(rows) - 1
+		full := last >= 0 && l.MaxButtonsPerRow > 0 && len(rows[last]) >= l.MaxButtonsPerRow
+		if last < 0 || spec.NewRow || full {
+			rows = append(rows, []ButtonSpec{spec})
+			continue
+		}
+		rows[last] = append(rows[last], spec)
+	}
+
+	return rows
+}
+
+// Render lays out the button specs as Telegram keyboard rows
+func (l KeyboardLayout) Render(specs []ButtonSpec) [][]tgbotapi.InlineKeyboardButton {
+	specRows := l.Rows(specs)
+	rows := make([][]tgbotapi.InlineKeyboardButton, 0, len(specRows))
+
+	for _, specRow := range specRows {
+		row := make([]tgbotapi.InlineKeyboardButton, 0, len(specRow))
+		for _, spec := range specRow {
+			if spec.URL != "" {
+				row = append(row, tgbotapi.NewInlineKeyboardButtonURL(l.Label(spec), spec.URL))
+				continue
+			}
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(l.Label(spec), spec.CallbackData))
+		}
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+// truncateText truncates text to the given number of characters with an ellipsis
+func truncateText(text string, maxLength int) string {
+	if utf8.RuneCountInString(text) <= maxLength {
+		return text
+	}
+
+	runes := []rune(strings.TrimSpace(text))
+	if len(runes) <= maxLength {
+		return string(runes)
+	}
+
+	if maxLength <= 3 {
+		return string(runes[:maxLength])
+	}
+
+	return strings.TrimSpace(string(runes[:maxLength-3])) + "..."
+}
@@ -0,0 +1,60 @@
+package chatbot
+
+import (
+	"testing"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyboardLayout_Rows(t *testing.T) {
+	specs := []ButtonSpec{{Text: "a"}, {Text: "b"}, {Text: "c"}, {Text: "d", NewRow: true}, {Text: "e"}}
+
+	rows := KeyboardLayout{MaxButtonsPerRow: 2}.Rows(specs)
+	require.Len(t, rows, 3)
+	assert.Len(t, rows[0], 2)
+	assert.Len(t, rows[1], 1, "a forced new row ends the previous row early")
+	assert.Len(t, rows[2], 2)
+
+	rows = KeyboardLayout{}.Rows(specs)
+	require.Len(t, rows, 2, "unbounded rows only break on NewRow")
+	assert.Len(t, rows[0], 3)
+}
+
+func TestKeyboardLayout_Label(t *testing.T) {
+	layout := KeyboardLayout{MaxLabelLength: 10, ShowEmoji: true}
+
+	assert.Equal(t, "✅ Done", layout.Label(ButtonSpec{Emoji: "✅", Text: "Done"}))
+	assert.Equal(t, "📋 Buy fre...", layout.Label(ButtonSpec{Emoji: "📋", Text: "Buy fresh vegetables"}))
+	assert.Equal(t, "Tươi ng...", layout.Label(ButtonSpec{Text: "Tươi ngon quá"}), "truncation counts characters, not bytes")
+
+	layout.ShowEmoji = false
+	assert.Equal(t, "Done", layout.Label(ButtonSpec{Emoji: "✅", Text: "Done"}))
+	assert.Equal(t, "➡️", layout.Label(ButtonSpec{Emoji: "➡️"}), "emoji-only buttons keep their emoji")
+}
+
+func TestKeyboardBuilder_UsesLayout(t *testing.T) {
+	kb := NewKeyboardBuilderWithLayout(NewKeyboardLayoutFromConfig(config.KeyboardConfig{
+		MaxButtonsPerRow: 3,
+		MaxLabelLength:   8,
+		ShowEmoji:        false,
+	}))
+
+	actions := kb.BuildTaskActionKeyboard(string(common.NewID()))
+	require.Len(t, actions.InlineKeyboard, 2, "actions, then status and history buttons")
+	assert.Equal(t, "Done", actions.InlineKeyboard[0][0].Text)
+	assert.Equal(t, "Start", actions.InlineKeyboard[1][0].Text)
+	assert.Equal(t, "History", actions.InlineKeyboard[1][2].Text)
+
+	tasks := []TaskSummary{{ID: "1", Title: "Prepare quarterly report"}, {ID: "2", Title: "Gym"}}
+	list := kb.BuildTaskListKeyboard(tasks, 0, 3)
+	require.Len(t, list.InlineKeyboard, 4, "two task rows, pagination and back")
+	assert.Equal(t, "Prepa...", list.InlineKeyboard[0][0].Text)
+	assert.Len(t, list.InlineKeyboard[2], 2, "page indicator and next stay on one row")
+
+	defaults := NewKeyboardLayoutFromConfig(config.KeyboardConfig{})
+	assert.Equal(t, DefaultKeyboardLayout(), defaults)
+}
@@ -0,0 +1,117 @@
+package chatbot
+
+import (
+	"sync"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// Messages shown to users while the service sheds load
+const (
+	busyMessage     = "⏳ The bot is busy right now, so replies may take a little longer than usual. Your message has been received."
+	busyListMessage = "⏳ The bot is busy right now. Here is your task list as of your last /list; it may be out of date."
+	busyNoListReply = "⏳ The bot is busy right now and can't load your tasks. Please try /list again in a minute."
+)
+
+// loadShedState tracks degraded mode as announced by the health governor and
+// which chats have already been told the bot is busy
+type loadShedState struct {
+	mu       sync.Mutex
+	degraded bool
+	notified map[string]bool
+}
+
+// newLoadShedState creates a state in normal mode
+func newLoadShedState() *loadShedState {
+	return &loadShedState{notified: make(map[string]bool)}
+}
+
+// Degraded reports whether the service is shedding load
+func (l *loadShedState) Degraded() bool {
+	if l == nil {
+		return false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.degraded
+}
+
+// set switches mode; every chat is told again on the next degraded period
+func (l *loadShedState) set(degraded bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.degraded = degraded
+	l.notified = make(map[string]bool)
+}
+
+// shouldNotify reports whether a chat still needs the busy notice for the
+// current degraded period
+func (l *loadShedState) shouldNotify(chatID string) bool {
+	if l == nil {
+		return false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.degraded || l.notified[chatID] {
+		return false
+	}
+	l.notified[chatID] = true
+	return true
+}
+
+// handleSystemLoadChanged handles SystemLoadChanged events from the health governor
+func (s *chatbotService) handleSystemLoadChanged(event events.SystemLoadChanged) {
+	s.logger.Info("Handling SystemLoadChanged event",
+		zap.String("correlation_id", event.CorrelationID),
+		zap.Bool("degraded", event.Degraded),
+		zap.String("reason", event.Reason))
+
+	s.load.set(event.Degraded)
+}
+
+// processListCommand requests a fresh task list, or serves the last list shown
+// in the chat while the service is degraded
+func (s *chatbotService) processListCommand(userID, chatID string) error {
+	if !s.load.Degraded() {
+		return s.commandProcessor.ProcessListCommand(userID, chatID)
+	}
+
+	tracked, exists := s.listMessages.Get(chatID)
+	if !exists {
+		return s.SendMessage(common.ChatID(chatID), busyNoListReply)
+	}
+
+	s.logger.Info("Serving cached task list while degraded",
+		zap.String("user_id", userID),
+		zap.String("chat_id", chatID),
+		zap.Int("task_count", len(tracked.Tasks)))
+
+	if err := s.SendMessage(common.ChatID(chatID), busyListMessage); err != nil {
+		return err
+	}
+
+	// Post the cached list below the notice instead of editing the old message
+	s.listMessages.Delete(chatID)
+	return s.showTaskList(userID, chatID, tracked.Tasks, tracked.Page, 0)
+}
+
+// notifyIfBusy tells the chat once per degraded period that replies are delayed
+func (s *chatbotService) notifyIfBusy(chatID string) {
+	if !s.load.shouldNotify(chatID) {
+		return
+	}
+
+	if err := s.SendMessage(common.ChatID(chatID), busyMessage); err != nil {
+		s.logger.Warn("Failed to send busy notice",
+			zap.String("chat_id", chatID),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,43 @@
+package chatbot
+
+import (
+	"testing"
+
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadShedding_ListServedFromCache(t *testing.T) {
+	service, provider := newListTestService(t)
+	service.handleTaskListResponse(listResponse(2))
+
+	service.handleSystemLoadChanged(events.SystemLoadChanged{Event: events.NewEvent(), Degraded: true})
+	require.NoError(t, service.processListCommand("user", "42"))
+
+	require.Len(t, provider.messages, 1)
+	assert.Equal(t, busyListMessage, provider.messages[0])
+	require.Len(t, provider.sent, 2, "the cached list is posted as a new message")
+	assert.Contains(t, provider.sent[1], "2 active task(s)")
+
+	require.NoError(t, service.processListCommand("user", "7"))
+	assert.Equal(t, busyNoListReply, provider.messages[1], "chats without a cached list are told the bot is busy")
+}
+
+func TestLoadShedding_BusyNoticeOncePerPeriod(t *testing.T) {
+	service, provider := newListTestService(t)
+
+	service.notifyIfBusy("42")
+	assert.Empty(t, provider.messages, "no notice in normal mode")
+
+	service.handleSystemLoadChanged(events.SystemLoadChanged{Event: events.NewEvent(), Degraded: true})
+	service.notifyIfBusy("42")
+	service.notifyIfBusy("42")
+	assert.Equal(t, []string{busyMessage}, provider.messages)
+
+	service.handleSystemLoadChanged(events.SystemLoadChanged{Event: events.NewEvent(), Degraded: false})
+	service.handleSystemLoadChanged(events.SystemLoadChanged{Event: events.NewEvent(), Degraded: true})
+	service.notifyIfBusy("42")
+	assert.Len(t, provider.messages, 2, "a new degraded period notifies again")
+}
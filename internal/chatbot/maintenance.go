@@ -0,0 +1,119 @@
+package chatbot
+
+import (
+	"html"
+	"strings"
+	"sync"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// maintenanceUsage explains the /maintenance command
+const maintenanceUsage = "Usage: /maintenance on [notice] - Take the bot down for maintenance, /maintenance off - Bring it back"
+
+// maintenanceState is maintenance mode as last announced by the maintenance
+// switch
+type maintenanceState struct {
+	mu     sync.RWMutex
+	status events.MaintenanceChanged
+}
+
+// current returns the last announcement; a nil state is never in maintenance
+func (m *maintenanceState) current() events.MaintenanceChanged {
+	if m == nil {
+		return events.MaintenanceChanged{}
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.status
+}
+
+// set records an announcement
+func (m *maintenanceState) set(status events.MaintenanceChanged) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.status = status
+}
+
+// maintenanceExempt reports whether the sender of an update may use the bot
+// during maintenance: the users allowed to end it
+func (s *chatbotService) maintenanceExempt(update *tgbotapi.Update) bool {
+	sender, err := s.parser.GetSender(update)
+	if err != nil {
+		return false
+	}
+	return s.authorizer.Allowed(CommandMaintenance, sender.ID)
+}
+
+// sendMaintenanceNotice answers an update with the maintenance notice
+func (s *chatbotService) sendMaintenanceNotice(chatID string) error {
+	return s.SendMessage(common.ChatID(chatID), html.EscapeString(s.maintenance.current().Message))
+}
+
+// processMaintenanceCommand handles the /maintenance command. "on" and "off"
+// ask the maintenance switch for the change; without arguments it reports
+// the current mode.
+func (s *chatbotService) processMaintenanceCommand(userID, chatID, arguments string) (string, error) {
+	s.logger.Info("Processing maintenance command",
+		zap.String("user_id", userID),
+		zap.String("chat_id", chatID))
+
+	mode, message, _ := strings.Cut(strings.TrimSpace(arguments), " ")
+	request := events.MaintenanceRequested{
+		Event:   events.NewEvent(),
+		UserID:  userID,
+		ChatID:  chatID,
+		Message: strings.TrimSpace(message),
+	}
+
+	switch strings.ToLower(mode) {
+	case "":
+		return formatMaintenanceStatus(s.maintenance.current()), nil
+	case "on":
+		request.Enabled = true
+	case "off":
+		request.Message = ""
+	default:
+		return maintenanceUsage, nil
+	}
+
+	if err := s.eventBus.Publish(events.TopicMaintenanceRequested, request); err != nil {
+		return "", err
+	}
+	return "", nil // Confirmed once the switch announces the change
+}
+
+// formatMaintenanceStatus describes maintenance mode for admins
+func formatMaintenanceStatus(status events.MaintenanceChanged) string {
+	if !status.Enabled {
+		return "✅ Maintenance mode is off.\n\n" + maintenanceUsage
+	}
+	return "🛠 Maintenance mode is on since " + status.Since.UTC().Format("2006-01-02 15:04") + " UTC. Users see:\n\n" +
+		html.EscapeString(status.Message)
+}
+
+// handleMaintenanceChanged records the new mode, and confirms it to the admin
+// who asked for it from the chat
+func (s *chatbotService) handleMaintenanceChanged(event events.MaintenanceChanged) {
+	s.logger.Info("Handling MaintenanceChanged event",
+		zap.String("correlation_id", event.CorrelationID),
+		zap.Bool("enabled", event.Enabled))
+
+	s.maintenance.set(event)
+
+	if event.UserID == "" || !s.ownsUser(event.UserID) {
+		return
+	}
+	if err := s.SendMessage(common.ChatID(event.ChatID), formatMaintenanceStatus(event)); err != nil {
+		s.logger.Error("Failed to confirm maintenance mode",
+			zap.String("correlation_id", event.CorrelationID),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,87 @@
+package chatbot
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/config"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestChatbotService_MaintenanceMode(t *testing.T) {
+	eventBus := events.NewMockEventBus()
+	eventBus.SetSynchronousMode(true)
+	chatbot, _ := newBenchService(eventBus, zaptest.NewLogger(t))
+	provider := &broadcastProvider{}
+	chatbot.provider = provider
+	authorizer, err := NewCommandAuthorizer(config.AuthorizationConfig{AdminIDs: []int64{4242}})
+	require.NoError(t, err)
+	chatbot.authorizer = authorizer
+
+	message := func(from int64, text string) []byte {
+		return []byte(fmt.Sprintf(`{"update_id":1,"message":{"message_id":5,"from":{"id":%[1]d,"first_name":"Ann"},"chat":{"id":%[1]d,"type":"private"},`+
+			`"date":1,"text":%[2]q}}`, from, text))
+	}
+	command := func(from int64, text string) []byte {
+		return []byte(fmt.Sprintf(`{"update_id":1,"message":{"message_id":5,"from":{"id":%[1]d,"first_name":"Ann"},"chat":{"id":%[1]d,"type":"private"},`+
+			`"date":1,"text":%[2]q,"entities":[{"offset":0,"length":12,"type":"bot_command"}]}}`, from, text))
+	}
+
+	// An admin asks for maintenance; the switch announces it
+	require.NoError(t, chatbot.HandleWebhook(command(4242, "/maintenance on Upgrading <db>")))
+	requests := eventBus.GetPublishedEvents(events.TopicMaintenanceRequested)
+	require.Len(t, requests, 1)
+	request := requests[0].(events.MaintenanceRequested)
+	assert.True(t, request.Enabled)
+	assert.Equal(t, "Upgrading <db>", request.Message)
+
+	chatbot.handleMaintenanceChanged(events.MaintenanceChanged{
+		Event:   events.NewEvent(),
+		Enabled: true,
+		Message: request.Message,
+		Since:   time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC),
+		UserID:  request.UserID,
+		ChatID:  request.ChatID,
+	})
+	require.NotEmpty(t, provider.messages)
+	assert.Contains(t, provider.messages[len(provider.messages)-1], "Maintenance mode is on since 2026-03-01 09:30 UTC")
+
+	// Everyone else gets the notice instead of an answer
+	eventBus.ClearEvents()
+	require.NoError(t, chatbot.HandleWebhook(message(5151, "buy milk tomorrow")))
+	assert.Equal(t, "Upgrading &lt;db&gt;", provider.messages[len(provider.messages)-1])
+	assert.Empty(t, eventBus.GetPublishedEvents(events.TopicMessageReceived))
+	require.NoError(t, chatbot.HandleWebhook(command(5151, "/maintenance off")))
+	assert.Empty(t, eventBus.GetPublishedEvents(events.TopicMaintenanceRequested))
+
+	// Admins can still use the bot and end maintenance
+	response, err := chatbot.processMaintenanceCommand("admin", "4242", "")
+	require.NoError(t, err)
+	assert.Contains(t, response, "Users see:\n\nUpgrading &lt;db&gt;")
+	require.NoError(t, chatbot.HandleWebhook(command(4242, "/maintenance off")))
+	requests = eventBus.GetPublishedEvents(events.TopicMaintenanceRequested)
+	require.Len(t, requests, 1)
+	assert.False(t, requests[0].(events.MaintenanceRequested).Enabled)
+
+	chatbot.handleMaintenanceChanged(events.MaintenanceChanged{Event: events.NewEvent()})
+	sent := len(provider.messages)
+	require.NoError(t, chatbot.HandleWebhook(message(5151, "buy milk tomorrow")))
+	assert.NotContains(t, provider.messages[sent:], "Upgrading &lt;db&gt;", "the bot answers normally again")
+}
+
+func TestChatbotService_ProcessMaintenanceCommandUsage(t *testing.T) {
+	chatbot, _ := newListTestService(t)
+
+	response, err := chatbot.processMaintenanceCommand("admin", "42", "sideways")
+	require.NoError(t, err)
+	assert.Equal(t, maintenanceUsage, response)
+
+	response, err = chatbot.processMaintenanceCommand("admin", "42", "")
+	require.NoError(t, err)
+	assert.Contains(t, response, "Maintenance mode is off.")
+}
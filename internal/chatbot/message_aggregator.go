@@ -0,0 +1,137 @@
+package chatbot
+
+import (
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Aggregation limits keep batched bundles within what the LLM path accepts
+const (
+	DefaultAggregationMaxMessages = 10
+	maxAggregatedChars            = 2000
+)
+
+// MessageBatch is a group of consecutive messages from one user in one chat
+type MessageBatch struct {
+	UserID        string
+	ChatID        string
+	CorrelationID string
+	Locale        string // language of the user's Telegram client
+	Messages      []string
+}
+
+// pendingBatch is a batch waiting for its aggregation window to close
+type pendingBatch struct {
+	batch MessageBatch
+	chars int
+	timer *time.Timer
+}
+
+// MessageAggregator batches consecutive messages from the same user and chat.
+// Each new message restarts the window, so a bundle of forwarded messages is
+// flushed once the user stops sending.
+type MessageAggregator struct {
+	window      time.Duration
+	maxMessages int
+	flush       func(batch MessageBatch)
+
+	mu      sync.Mutex
+	pending map[string]*pendingBatch
+}
+
+// NewMessageAggregator creates a MessageAggregator. A zero window disables batching
+// and every message is flushed immediately.
+func NewMessageAggregator(window time.Duration, maxMessages int, flush func(batch MessageBatch)) *MessageAggregator {
+	if maxMessages <= 0 {
+		maxMessages = DefaultAggregationMaxMessages
+	}
+
+	return &MessageAggregator{
+		window:      window,
+		maxMessages: maxMessages,
+		flush:       flush,
+		pending:     make(map[string]*pendingBatch),
+	}
+}
+
+// Add queues a message, flushing the user's batch when it is full
+func (a *MessageAggregator) Add(userID, chatID, correlationID, locale, text string) {
+	if a.window <= 0 {
+		a.flush(MessageBatch{UserID: userID, ChatID: chatID, CorrelationID: correlationID, Locale: locale, Messages: []string{text}})
+		return
+	}
+
+	key := userID + ":" + chatID
+	textChars := utf8.RuneCountInString(text)
+
+	a.mu.Lock()
+	pending, exists := a.pending[key]
+
+	// Flush early if this message would overflow the batch
+	if exists && pending.chars+textChars > maxAggregatedChars {
+		a.removeLocked(key, pending)
+		a.mu.Unlock()
+		a.flush(pending.batch)
+		a.mu.Lock()
+		exists = false
+	}
+
+	if !exists {
+		pending = &pendingBatch{
+			batch: MessageBatch{UserID: userID, ChatID: chatID, CorrelationID: correlationID, Locale: locale},
+		}
+		a.pending[key] = pending
+		pending.timer = time.AfterFunc(a.window, func() { a.flushKey(key, pending) })
+	} else {
+		pending.timer.Reset(a.window)
+	}
+
+	pending.batch.Messages = append(pending.batch.Messages, text)
+	pending.chars += textChars
+
+	if len(pending.batch.Messages) >= a.maxMessages {
+		a.removeLocked(key, pending)
+		a.mu.Unlock()
+		a.flush(pending.batch)
+		return
+	}
+	a.mu.Unlock()
+}
+
+// FlushAll immediately flushes every pending batch, e.g. during shutdown
+func (a *MessageAggregator) FlushAll() {
+	a.mu.Lock()
+	batches := make([]MessageBatch, 0, len(a.pending))
+	for key, pending := range a.pending {
+		a.removeLocked(key, pending)
+		batches = append(batches, pending.batch)
+	}
+	a.mu.Unlock()
+
+	for _, batch := range batches {
+		a.flush(batch)
+	}
+}
+
+// flushKey is called when a batch's window expires
+func (a *MessageAggregator) flushKey(key string, expected *pendingBatch) {
+	a.mu.Lock()
+	pending, exists := a.pending[key]
+	if !exists || pending != expected {
+		a.mu.Unlock()
+		return
+	}
+	a.removeLocked(key, pending)
+	a.mu.Unlock()
+
+	a.flush(pending.batch)
+}
+
+// removeLocked drops a pending batch; the caller must hold the mutex
+func (a *MessageAggregator) removeLocked(key string, pending *pendingBatch) {
+	if pending.timer != nil {
+		pending.timer.Stop()
+	}
+	delete(a.pending, key)
+}
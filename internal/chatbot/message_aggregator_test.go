@@ -0,0 +1,105 @@
+package chatbot
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+type batchRecorder struct {
+	mu      sync.Mutex
+	batches []MessageBatch
+}
+
+func (r *batchRecorder) record(batch MessageBatch) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, batch)
+}
+
+func (r *batchRecorder) snapshot() []MessageBatch {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]MessageBatch(nil), r.batches...)
+}
+
+func TestMessageAggregator_DisabledWindowFlushesImmediately(t *testing.T) {
+	recorder := &batchRecorder{}
+	aggregator := NewMessageAggregator(0, 0, recorder.record)
+
+	aggregator.Add("user", "chat", "corr", "", "buy milk")
+
+	batches := recorder.snapshot()
+	require.Len(t, batches, 1)
+	assert.Equal(t, []string{"buy milk"}, batches[0].Messages)
+}
+
+func TestMessageAggregator_BatchesConsecutiveMessages(t *testing.T) {
+	recorder := &batchRecorder{}
+	aggregator := NewMessageAggregator(50*time.Millisecond, 10, recorder.record)
+
+	aggregator.Add("user", "chat", "corr", "vi", "eggs")
+	aggregator.Add("user", "chat", "corr", "vi", "bread")
+	aggregator.Add("other", "chat", "corr", "", "call mom")
+
+	assert.Empty(t, recorder.snapshot())
+
+	require.Eventually(t, func() bool { return len(recorder.snapshot()) == 2 }, time.Second, 10*time.Millisecond)
+
+	for _, batch := range recorder.snapshot() {
+		switch batch.UserID {
+		case "user":
+			assert.Equal(t, []string{"eggs", "bread"}, batch.Messages)
+			assert.Equal(t, "vi", batch.Locale)
+		case "other":
+			assert.Equal(t, []string{"call mom"}, batch.Messages)
+		default:
+			t.Fatalf("unexpected batch for %s", batch.UserID)
+		}
+	}
+}
+
+func TestMessageAggregator_FlushesWhenFull(t *testing.T) {
+	recorder := &batchRecorder{}
+	aggregator := NewMessageAggregator(time.Hour, 2, recorder.record)
+
+	aggregator.Add("user", "chat", "corr", "", "one")
+	aggregator.Add("user", "chat", "corr", "", "two")
+	aggregator.Add("user", "chat", "corr", "", "three")
+
+	batches := recorder.snapshot()
+	require.Len(t, batches, 1)
+	assert.Equal(t, []string{"one", "two"}, batches[0].Messages)
+
+	aggregator.FlushAll()
+	batches = recorder.snapshot()
+	require.Len(t, batches, 2)
+	assert.Equal(t, []string{"three"}, batches[1].Messages)
+}
+
+func TestChatbotService_StopFlushesPendingBatches(t *testing.T) {
+	eventBus := events.NewMockEventBus()
+	eventBus.SetSynchronousMode(true)
+	logger := zaptest.NewLogger(t)
+	chatbot, _ := newBenchService(eventBus, logger)
+	chatbot.aggregator = NewMessageAggregator(time.Hour, 10, chatbot.publishMessageBatch)
+	chatbot.updates = NewUpdateQueue(0, logger, chatbot.processUpdate)
+	chatbot.typing = NewTypingIndicator(func(chatID string) error { return nil }, typingRefreshInterval, typingMaxDuration, logger)
+
+	require.NoError(t, chatbot.HandleWebhook([]byte(fmt.Sprintf(`{"update_id":1,"message":{"message_id":5,"from":{"id":4242,"first_name":"Ann"},`+
+		`"chat":{"id":4242,"type":"private"},"date":1,"text":%q}}`, "buy milk tomorrow"))))
+	assert.Empty(t, eventBus.GetPublishedEvents(events.TopicMessageReceived), "the message waits for its batch window")
+
+	require.NoError(t, chatbot.Stop(context.Background()))
+	received := eventBus.GetPublishedEvents(events.TopicMessageReceived)
+	require.Len(t, received, 1)
+	assert.Equal(t, "buy milk tomorrow", received[0].(events.MessageReceived).MessageText)
+}
@@ -0,0 +1,48 @@
+package chatbot
+
+import (
+	"fmt"
+	"html"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// maxOriginalTextLength leaves room for the heading within Telegram's 4096
+// character limit
+const maxOriginalTextLength = 3800
+
+// formatOriginalText renders the full message a task was summarized from
+func formatOriginalText(title, text string) string {
+	runes := []rune(text)
+	if len(runes) > maxOriginalTextLength {
+		text = string(runes[:maxOriginalTextLength-1]) + "…"
+	}
+
+	heading := "📄 <b>Original Message</b>"
+	if title != "" {
+		heading += ": " + html.EscapeString(title)
+	}
+	return fmt.Sprintf("%s\n\n%s", heading, html.EscapeString(text))
+}
+
+// handleTaskOriginalResponse sends the requested original message to the chat
+func (s *chatbotService) handleTaskOriginalResponse(event events.TaskOriginalResponse) {
+	if !s.ownsUser(event.UserID) {
+		return
+	}
+
+	messageText := formatOriginalText(event.TaskTitle, event.Text)
+	if !event.Success {
+		messageText = fmt.Sprintf("❌ <b>Original Unavailable</b>\n\n%s", html.EscapeString(event.Message))
+	}
+
+	if err := s.SendMessage(common.ChatID(event.ChatID), messageText); err != nil {
+		s.logger.Error("Failed to send original message",
+			zap.String("correlation_id", event.CorrelationID),
+			zap.String("task_id", event.TaskID),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,48 @@
+package chatbot
+
+import (
+	"strings"
+	"testing"
+
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestFormatOriginalText(t *testing.T) {
+	text := formatOriginalText("Review <contract>", "Hi,\n\nPlease sign & return.")
+	assert.Equal(t, "📄 <b>Original Message</b>: Review &lt;contract&gt;\n\nHi,\n\nPlease sign &amp; return.", text)
+
+	long := formatOriginalText("", strings.Repeat("a", 5000))
+	assert.True(t, strings.HasSuffix(long, "…"))
+	assert.Less(t, len([]rune(long)), 4096)
+}
+
+func TestChatbotService_ShowsTheOriginalOfSummarizedTasks(t *testing.T) {
+	eventBus := events.NewMockEventBus()
+	eventBus.SetSynchronousMode(true)
+	chatbot, _ := newBenchService(eventBus, zaptest.NewLogger(t))
+	provider := &listRecordingProvider{edited: make(map[int]string)}
+	chatbot.provider = provider
+
+	keyboard := chatbot.keyboardBuilder.BuildTaskDetailsKeyboard(events.TaskSummary{ID: "task-1", HasOriginal: true})
+	last := keyboard.InlineKeyboard[len(keyboard.InlineKeyboard)-1]
+	require.Len(t, last, 1)
+	callbackData, err := chatbot.keyboardBuilder.DecodeCallbackData(*last[0].CallbackData)
+	require.NoError(t, err)
+	assert.Equal(t, CallbackActionOriginal, callbackData.Action)
+
+	_, err = chatbot.commandProcessor.HandleCallbackQuery(callbackData, "user", "42")
+	require.NoError(t, err)
+	requests := eventBus.GetPublishedEvents(events.TopicTaskOriginalRequested)
+	require.Len(t, requests, 1)
+	assert.Equal(t, "task-1", requests[0].(events.TaskOriginalRequested).TaskID)
+
+	chatbot.handleTaskOriginalResponse(events.TaskOriginalResponse{Event: events.NewEvent(), UserID: "user", ChatID: "42", TaskID: "task-1", TaskTitle: "Review contract", Text: "Hi, please review it.", Success: true})
+	chatbot.handleTaskOriginalResponse(events.TaskOriginalResponse{Event: events.NewEvent(), UserID: "user", ChatID: "42", TaskID: "task-1", Message: "I couldn't find that task."})
+	require.Len(t, provider.messages, 2)
+	assert.Contains(t, provider.messages[0], "Hi, please review it.")
+	assert.Contains(t, provider.messages[1], "Original Unavailable")
+}
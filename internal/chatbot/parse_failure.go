@@ -0,0 +1,194 @@
+package chatbot
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode/utf8"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// maxQuotedTextLength bounds how much of the original message a parse
+// failure notice repeats
+const maxQuotedTextLength = 500
+
+// Plain tasks must fit the byte limits enforced when tasks are stored
+const (
+	maxPlainTitleBytes       = 255
+	maxPlainDescriptionBytes = 2000
+)
+
+// maxFailedParses is how many unparsed messages a session keeps, so that the
+// buttons under recent failure notices keep working
+const maxFailedParses = 5
+
+// FailedParse is a message the LLM could not parse, kept in the user's
+// session until it is saved as a plain task or pushed out by newer failures
+type FailedParse struct {
+	ID            string `json:"id"`
+	CorrelationID string `json:"correlation_id"`
+	Text          string `json:"text"`
+	ListID        string `json:"list_id,omitempty"`
+}
+
+// PlainTask converts the message into a task titled by its text, without a
+// due date. Text that does not fit the title is kept in the description.
+func (f *FailedParse) PlainTask() events.ParsedTask {
+	text := strings.TrimSpace(f.Text)
+	task := events.ParsedTask{
+		Title:    strings.Join(strings.Fields(text), " "),
+		Priority: string(common.PriorityMedium),
+	}
+
+	if len(task.Title) > maxPlainTitleBytes {
+		task.Title = truncateBytes(task.Title, maxPlainTitleBytes)
+		task.Description = truncateBytes(text, maxPlainDescriptionBytes)
+	}
+	return task
+}
+
+// truncateBytes shortens text to at most maxBytes, cutting between characters
+// and ending with an ellipsis
+func truncateBytes(text string, maxBytes int) string {
+	if len(text) <= maxBytes {
+		return text
+	}
+
+	const ellipsis = "…"
+	cut := maxBytes - len(ellipsis)
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return strings.TrimSpace(text[:cut]) + ellipsis
+}
+
+// rememberFailedParse adds a failure to the session, dropping the oldest
+// beyond maxFailedParses
+func rememberFailedParse(session *ChatSession, failed *FailedParse) {
+	session.FailedParses = append(session.FailedParses, failed)
+	if excess := len(session.FailedParses) - maxFailedParses; excess > 0 {
+		session.FailedParses = session.FailedParses[excess:]
+	}
+}
+
+// takeFailedParse removes and returns the session's failure with the given ID
+func takeFailedParse(session *ChatSession, id string) *FailedParse {
+	for i, failed := range session.FailedParses {
+		if failed.ID == id {
+			session.FailedParses = append(session.FailedParses[:i:i], session.FailedParses[i+1:]...)
+			return failed
+		}
+	}
+	return nil
+}
+
+// formatParseFailure apologizes for a message that yielded no task and
+// quotes it back
+func formatParseFailure(event events.TaskParseFailed, offerPlainTask bool) string {
+	var reason string
+	switch event.Reason {
+	case events.ParseFailureProvider:
+		reason = "I couldn't reach my task parser just now."
+	case events.ParseFailureRejected:
+		reason = "I can't parse that message."
+	default:
+		reason = "I couldn't work out a task from your message."
+	}
+
+	var text strings.Builder
+	text.WriteString("😕 <b>Sorry!</b> " + reason)
+
+	quoted := strings.TrimSpace(event.MessageText)
+	if utf8.RuneCountInString(quoted) > maxQuotedTextLength {
+		quoted = string([]rune(quoted)[:maxQuotedTextLength]) + "…"
+	}
+	if quoted != "" {
+		text.WriteString("\n\n<i>" + html.EscapeString(quoted) + "</i>")
+	}
+
+	if offerPlainTask {
+		text.WriteString("\n\nTap below to save it as it is, or rephrase it and send it again.")
+	} else {
+		text.WriteString("\n\nPlease rephrase it and send it again.")
+	}
+	return text.String()
+}
+
+// handleTaskParseFailed tells the user their message yielded no task and
+// offers to save it as a plain task
+func (s *chatbotService) handleTaskParseFailed(event events.TaskParseFailed) {
+	if !s.ownsUser(event.UserID) {
+		return
+	}
+	s.typing.Stop(event.ChatID)
+
+	log := common.FlowLogger(s.logger, common.LogFlow{
+		UserID:        event.UserID,
+		ChatID:        event.ChatID,
+		CorrelationID: event.CorrelationID,
+	})
+	log.Info("Handling TaskParseFailed event",
+		zap.String("reason", event.Reason))
+
+	chatID := common.ChatID(event.ChatID)
+	if strings.TrimSpace(event.MessageText) == "" {
+		if err := s.SendMessage(chatID, formatParseFailure(event, false)); err != nil {
+			log.Error("Failed to send parse failure notice", zap.Error(err))
+		}
+		return
+	}
+
+	failed := &FailedParse{
+		ID:            string(common.NewID())[:draftIDLength],
+		CorrelationID: event.CorrelationID,
+		Text:          event.MessageText,
+		ListID:        event.ListID,
+	}
+	s.commandProcessor.sessionManager.UpdateSession(event.UserID, event.ChatID, func(session *ChatSession) {
+		rememberFailedParse(session, failed)
+	})
+
+	keyboard := s.keyboardBuilder.ToDomainKeyboard(s.keyboardBuilder.BuildPlainTaskKeyboard(failed.ID))
+	if err := s.SendMessageWithKeyboard(chatID, formatParseFailure(event, true), keyboard); err != nil {
+		log.Error("Failed to send parse failure notice", zap.Error(err))
+	}
+}
+
+// handlePlainTaskCallback saves the user's unparsed message as a plain task,
+// bypassing the LLM
+func (s *chatbotService) handlePlainTaskCallback(callbackData *CallbackData, userID, chatID string) error {
+	var failed *FailedParse
+
+	s.commandProcessor.sessionManager.UpdateSession(userID, chatID, func(session *ChatSession) {
+		failed = takeFailedParse(session, callbackData.Data["id"])
+	})
+
+	if failed == nil {
+		return s.SendMessage(common.ChatID(chatID), "This message has expired. Send the task again to create it.")
+	}
+
+	event := events.TaskParsed{
+		Event:      events.NewEvent(),
+		UserID:     userID,
+		ChatID:     chatID,
+		ParsedTask: failed.PlainTask(),
+		ListID:     failed.ListID,
+		ThreadID:   s.threads.Get(chatID),
+	}
+
+	s.logger.Info("Saving unparsed message as a plain task",
+		zap.String("correlation_id", failed.CorrelationID),
+		zap.String("user_id", userID))
+
+	if err := s.eventBus.Publish(events.TopicTaskParsed, event); err != nil {
+		s.logger.Error("Failed to publish plain task",
+			zap.String("correlation_id", failed.CorrelationID),
+			zap.Error(err))
+		return fmt.Errorf("failed to save plain task: %w", err)
+	}
+	return nil
+}
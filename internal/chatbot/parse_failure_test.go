@@ -0,0 +1,154 @@
+package chatbot
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"nudgebot-api/internal/events"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// keyboardRecordingProvider records plain and keyboard messages
+type keyboardRecordingProvider struct {
+	TelegramProvider
+	messages  []string
+	keyboards []tgbotapi.InlineKeyboardMarkup
+}
+
+func (p *keyboardRecordingProvider) SendMessage(chatID int64, threadID int, text string) error {
+	p.messages = append(p.messages, text)
+	p.keyboards = append(p.keyboards, tgbotapi.InlineKeyboardMarkup{})
+	return nil
+}
+
+func (p *keyboardRecordingProvider) SendMessageWithKeyboard(chatID int64, threadID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
+	p.messages = append(p.messages, text)
+	p.keyboards = append(p.keyboards, keyboard)
+	return nil
+}
+
+func TestFormatParseFailure(t *testing.T) {
+	event := events.TaskParseFailed{MessageText: "buy <milk> & eggs", Reason: events.ParseFailureInvalid}
+
+	text := formatParseFailure(event, true)
+	assert.Contains(t, text, "Sorry!")
+	assert.Contains(t, text, "<i>buy &lt;milk&gt; &amp; eggs</i>")
+	assert.Contains(t, text, "save it as it is")
+
+	event.Reason = events.ParseFailureProvider
+	assert.Contains(t, formatParseFailure(event, true), "couldn't reach my task parser")
+
+	event.MessageText = strings.Repeat("a", maxQuotedTextLength+10)
+	assert.Contains(t, formatParseFailure(event, false), strings.Repeat("a", maxQuotedTextLength)+"…</i>")
+}
+
+func TestFailedParse_PlainTask(t *testing.T) {
+	task := (&FailedParse{Text: "  call the\nplumber  "}).PlainTask()
+	assert.Equal(t, "call the plumber", task.Title)
+	assert.Equal(t, "medium", task.Priority)
+	assert.Empty(t, task.Description)
+
+	assert.Nil(t, task.DueDate)
+
+	long := strings.Repeat("word ", 100)
+	task = (&FailedParse{Text: long}).PlainTask()
+	assert.LessOrEqual(t, len(task.Title), maxPlainTitleBytes)
+	assert.True(t, strings.HasSuffix(task.Title, "…"))
+	assert.Equal(t, strings.TrimSpace(long), task.Description, "the full text is kept")
+
+	// Limits are in bytes, so multi-byte text is cut between characters
+	task = (&FailedParse{Text: strings.Repeat("привет мир ", 400)}).PlainTask()
+	assert.LessOrEqual(t, len(task.Title), maxPlainTitleBytes)
+	assert.LessOrEqual(t, len(task.Description), maxPlainDescriptionBytes)
+	assert.True(t, utf8.ValidString(task.Title))
+	assert.True(t, utf8.ValidString(task.Description))
+}
+
+func TestFailedParses_KeepsRecentFailures(t *testing.T) {
+	session := &ChatSession{}
+	for i := 0; i < maxFailedParses+2; i++ {
+		rememberFailedParse(session, &FailedParse{ID: string(rune('a' + i))})
+	}
+	require.Len(t, session.FailedParses, maxFailedParses)
+
+	assert.Nil(t, takeFailedParse(session, "a"), "the oldest failures are dropped")
+	assert.NotNil(t, takeFailedParse(session, "d"))
+	assert.Nil(t, takeFailedParse(session, "d"), "a failure is saved once")
+	assert.NotNil(t, takeFailedParse(session, "g"))
+	assert.Len(t, session.FailedParses, maxFailedParses-2)
+}
+
+func TestParseFailure_CreateAsPlainTask(t *testing.T) {
+	logger := zap.NewNop()
+	bus := events.NewEventBus(logger)
+	defer bus.Close()
+	provider := &keyboardRecordingProvider{}
+	service := &chatbotService{
+		eventBus:         bus,
+		logger:           logger,
+		provider:         provider,
+		keyboardBuilder:  NewKeyboardBuilder(),
+		commandProcessor: NewCommandProcessor(bus, logger),
+	}
+
+	parsed := make(chan events.TaskParsed, 1)
+	require.NoError(t, bus.Subscribe(events.TopicTaskParsed, func(event events.TaskParsed) { parsed <- event }))
+
+	service.handleTaskParseFailed(events.TaskParseFailed{Event: events.NewEvent(), UserID: "user", ChatID: "42",
+		MessageText: "renew the car insurance", Reason: events.ParseFailureInvalid})
+	require.Len(t, provider.messages, 1)
+	assert.Contains(t, provider.messages[0], "renew the car insurance")
+
+	button := provider.keyboards[0].InlineKeyboard[0][0]
+	assert.Contains(t, button.Text, "Create as plain task")
+	var callbackData *CallbackData
+	require.NoError(t, json.Unmarshal([]byte(*button.CallbackData), &callbackData))
+	require.Equal(t, CallbackActionPlainTask, callbackData.Action)
+
+	require.NoError(t, service.handlePlainTaskCallback(callbackData, "user", "42"))
+	select {
+	case event := <-parsed:
+		assert.Equal(t, "renew the car insurance", event.ParsedTask.Title)
+		assert.False(t, event.Preview)
+	case <-time.After(time.Second):
+		t.Fatal("plain task was not published")
+	}
+
+	// The button only works once
+	require.NoError(t, service.handlePlainTaskCallback(callbackData, "user", "42"))
+	assert.Contains(t, provider.messages[len(provider.messages)-1], "expired")
+
+	// Buttons under earlier notices keep working after later failures
+	for _, text := range []string{"book the vet", "pay the gardener"} {
+		service.handleTaskParseFailed(events.TaskParseFailed{Event: events.NewEvent(), UserID: "user", ChatID: "42",
+			MessageText: text, Reason: events.ParseFailureProvider})
+	}
+	var first *CallbackData
+	require.NoError(t, json.Unmarshal([]byte(*provider.keyboards[len(provider.keyboards)-2].InlineKeyboard[0][0].CallbackData), &first))
+	require.NoError(t, service.handlePlainTaskCallback(first, "user", "42"))
+	select {
+	case event := <-parsed:
+		assert.Equal(t, "book the vet", event.ParsedTask.Title)
+	case <-time.After(time.Second):
+		t.Fatal("plain task was not published")
+	}
+}
+
+func TestParseFailure_EmptyMessageHasNoButton(t *testing.T) {
+	logger := zap.NewNop()
+	provider := &keyboardRecordingProvider{}
+	service := &chatbotService{logger: logger, provider: provider}
+
+	service.handleTaskParseFailed(events.TaskParseFailed{Event: events.NewEvent(), UserID: "user", ChatID: "42",
+		Reason: events.ParseFailureRejected})
+	require.Len(t, provider.messages, 1)
+	assert.Contains(t, provider.messages[0], "rephrase")
+	assert.Empty(t, provider.keyboards[0].InlineKeyboard)
+}
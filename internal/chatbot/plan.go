@@ -0,0 +1,145 @@
+package chatbot
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// isPlanAction reports whether a callback answers a proposed day plan
+func isPlanAction(action string) bool {
+	return action == CallbackActionPlanAccept || action == CallbackActionPlanRegenerate
+}
+
+// formatPlanBlocks renders the time blocks of a plan, one per line, with
+// breaks in italics
+func formatPlanBlocks(blocks []events.PlanBlock, dates DateFormat) string {
+	lines := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		slot := fmt.Sprintf("%s–%s", dates.Time(block.Start), dates.Time(block.End))
+		if block.TaskID == "" {
+			lines = append(lines, fmt.Sprintf("%s <i>%s</i>", slot, html.EscapeString(block.Title)))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s %s", slot, html.EscapeString(block.Title)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatPlan renders a day plan message in its current state
+func formatPlan(event events.PlanUpdated, dates DateFormat) string {
+	switch event.State {
+	case events.PlanReady:
+		return fmt.Sprintf("🗓 <b>Your Plan for Today</b>\n\n%s", formatPlanBlocks(event.Blocks, dates))
+	case events.PlanAccepted:
+		reminders := fmt.Sprintf("%d reminders set", event.Reminders)
+		if event.Reminders == 1 {
+			reminders = "1 reminder set"
+		}
+		return fmt.Sprintf("🗓 <b>Your Plan for Today</b>\n\n%s\n\n✅ Plan accepted, %s.", formatPlanBlocks(event.Blocks, dates), reminders)
+	default:
+		return fmt.Sprintf("❌ %s", html.EscapeString(event.Message))
+	}
+}
+
+// processPlanCommand posts the message a day plan is written into and asks
+// the plan service to write it
+func (s *chatbotService) processPlanCommand(userID, chatID string) error {
+	messageID, err := s.sendStreamPlaceholder(chatID)
+	if err != nil {
+		return err
+	}
+
+	requestEvent := events.PlanRequested{
+		Event:     events.NewEvent(),
+		UserID:    userID,
+		ChatID:    chatID,
+		MessageID: messageID,
+	}
+	if err := s.eventBus.Publish(events.TopicPlanRequested, requestEvent); err != nil {
+		s.logger.Error("Failed to publish plan request",
+			zap.String("user_id", userID),
+			zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// handlePlanCallback passes an Accept or Regenerate on to the plan service
+func (s *chatbotService) handlePlanCallback(callbackData *CallbackData, userID, chatID string) error {
+	planID := callbackData.Data["id"]
+
+	topic := events.TopicPlanRequested
+	var event interface{} = events.PlanRequested{
+		Event:  events.NewEvent(),
+		UserID: userID,
+		ChatID: chatID,
+		PlanID: planID,
+	}
+	if callbackData.Action == CallbackActionPlanAccept {
+		topic = events.TopicPlanAcceptRequested
+		event = events.PlanAcceptRequested{
+			Event:  events.NewEvent(),
+			UserID: userID,
+			ChatID: chatID,
+			PlanID: planID,
+		}
+	}
+
+	if err := s.eventBus.Publish(topic, event); err != nil {
+		s.logger.Error("Failed to publish plan callback",
+			zap.String("action", callbackData.Action),
+			zap.String("plan_id", planID),
+			zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// handlePlanUpdated edits a plan message to show the proposed schedule and
+// its buttons, or how the plan ended
+func (s *chatbotService) handlePlanUpdated(event events.PlanUpdated) {
+	if !s.ownsUser(event.UserID) {
+		return
+	}
+
+	if event.MessageID == 0 {
+		// Plans that expired no longer know their message
+		if err := s.SendMessage(common.ChatID(event.ChatID), formatPlan(event, s.dateFormat(event.UserID))); err != nil {
+			s.logger.Error("Failed to send plan update",
+				zap.String("plan_id", event.PlanID),
+				zap.Error(err))
+		}
+		return
+	}
+
+	chatIDInt, err := s.telegramChatID(event.ChatID)
+	if err != nil {
+		s.logger.Error("Failed to update plan",
+			zap.String("plan_id", event.PlanID),
+			zap.Error(err))
+		return
+	}
+
+	keyboard := tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}
+	switch {
+	case event.State == events.PlanReady:
+		keyboard = s.keyboardBuilder.BuildPlanKeyboard(event.PlanID)
+	case event.State == events.PlanFailed && event.PlanID != "":
+		keyboard = s.keyboardBuilder.BuildPlanRetryKeyboard(event.PlanID)
+	}
+
+	if err := s.provider.EditMessageWithKeyboard(chatIDInt, event.MessageID, formatPlan(event, s.dateFormat(event.UserID)), keyboard); err != nil {
+		s.logger.Warn("Failed to update plan",
+			zap.String("correlation_id", event.CorrelationID),
+			zap.String("plan_id", event.PlanID),
+			zap.Int("message_id", event.MessageID),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,72 @@
+package chatbot
+
+import (
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestFormatPlan(t *testing.T) {
+	day := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	blocks := []events.PlanBlock{
+		{Start: day.Add(9 * time.Hour), End: day.Add(10 * time.Hour), TaskID: "task-1", Title: "Write <report>"},
+		{Start: day.Add(10 * time.Hour), End: day.Add(10*time.Hour + 15*time.Minute), Title: "Break"},
+	}
+
+	ready := formatPlan(events.PlanUpdated{State: events.PlanReady, Blocks: blocks}, fixedDates)
+	assert.Contains(t, ready, "9:00–10:00 Write &lt;report&gt;\n10:00–10:15 <i>Break</i>")
+
+	accepted := formatPlan(events.PlanUpdated{State: events.PlanAccepted, Blocks: blocks, Reminders: 1}, fixedDates)
+	assert.Contains(t, accepted, "✅ Plan accepted, 1 reminder set.")
+
+	assert.Equal(t, "❌ Nothing to plan", formatPlan(events.PlanUpdated{State: events.PlanFailed, Message: "Nothing to plan"}, fixedDates))
+}
+
+func TestChatbotService_PlanCommandAndCallbacks(t *testing.T) {
+	eventBus := events.NewMockEventBus()
+	eventBus.SetSynchronousMode(true)
+	chatbot, _ := newBenchService(eventBus, zaptest.NewLogger(t))
+	provider := &listRecordingProvider{edited: make(map[int]string)}
+	chatbot.provider = provider
+
+	require.NoError(t, chatbot.processPlanCommand("user", "42"))
+	require.Len(t, provider.sent, 1)
+	requests := eventBus.GetPublishedEvents(events.TopicPlanRequested)
+	require.Len(t, requests, 1)
+	assert.Equal(t, 101, requests[0].(events.PlanRequested).MessageID)
+
+	// The finished text is left to the plan update, which adds the buttons
+	chatbot.handleTextGenerated(events.TextGenerated{UserID: "user", ChatID: "42", MessageID: 101, Purpose: events.TextPurposePlan, Text: "09:00-10:00 #1 Report", Done: true, Success: true})
+	assert.Empty(t, provider.edited[101])
+
+	start := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	chatbot.handlePlanUpdated(events.PlanUpdated{UserID: "user", ChatID: "42", MessageID: 101, PlanID: "ab12cd34", State: events.PlanReady,
+		Blocks: []events.PlanBlock{{Start: start, End: start.Add(time.Hour), TaskID: "task-1", Title: "Report"}}})
+	assert.Contains(t, provider.edited[101], "9:00–10:00 Report")
+
+	require.NoError(t, chatbot.handlePlanCallback(&CallbackData{Action: CallbackActionPlanAccept, Data: map[string]string{"id": "ab12cd34"}}, "user", "42"))
+	accepts := eventBus.GetPublishedEvents(events.TopicPlanAcceptRequested)
+	require.Len(t, accepts, 1)
+	assert.Equal(t, "ab12cd34", accepts[0].(events.PlanAcceptRequested).PlanID)
+
+	require.NoError(t, chatbot.handlePlanCallback(&CallbackData{Action: CallbackActionPlanRegenerate, Data: map[string]string{"id": "ab12cd34"}}, "user", "42"))
+	requests = eventBus.GetPublishedEvents(events.TopicPlanRequested)
+	require.Len(t, requests, 2)
+	assert.Equal(t, "ab12cd34", requests[1].(events.PlanRequested).PlanID)
+}
+
+func TestKeyboardBuilder_PlanCallbacksFit(t *testing.T) {
+	kb := NewKeyboardBuilder()
+	keyboard := kb.BuildPlanKeyboard("ab12cd34")
+	for _, row := range keyboard.InlineKeyboard {
+		for _, button := range row {
+			require.NotNil(t, button.CallbackData)
+			assert.LessOrEqual(t, len(*button.CallbackData), 64)
+		}
+	}
+}
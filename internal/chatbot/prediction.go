@@ -0,0 +1,19 @@
+package chatbot
+
+import (
+	"fmt"
+	"html"
+
+	"nudgebot-api/internal/events"
+)
+
+// formatLateEstimate warns that a new task will likely be done after it is due
+func formatLateEstimate(estimate events.CompletionEstimate, dates DateFormat) string {
+	tasks := "tasks"
+	if estimate.Tag != "" {
+		tasks = "#" + html.EscapeString(estimate.Tag) + " tasks"
+	}
+	return fmt.Sprintf("⚠️ You usually finish %s in about %s, so this one may be done after it's due "+
+		"(around %s). Start early or give it more time?",
+		tasks, formatSlip(estimate.Typical), dates.DateTime(estimate.DoneBy))
+}
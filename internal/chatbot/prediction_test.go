@@ -0,0 +1,21 @@
+package chatbot
+
+import (
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatLateEstimate(t *testing.T) {
+	doneBy := time.Date(2024, 5, 4, 17, 30, 0, 0, time.UTC)
+
+	text := formatLateEstimate(events.CompletionEstimate{DoneBy: doneBy, Typical: 72 * time.Hour, Tag: "work", Late: true}, fixedDates)
+	assert.Contains(t, text, "You usually finish #work tasks in about 3 days")
+	assert.Contains(t, text, "(around May 4 17:30)")
+
+	text = formatLateEstimate(events.CompletionEstimate{DoneBy: doneBy, Typical: 5 * time.Hour, Late: true}, fixedDates)
+	assert.Contains(t, text, "You usually finish tasks in about 5 hours")
+}
@@ -1,16 +1,30 @@
 package chatbot
 
 import (
+	"context"
+
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 // TelegramProvider defines the contract for Telegram API operations
 type TelegramProvider interface {
-	// SendMessage sends a plain text message to the specified chat
-	SendMessage(chatID int64, text string) error
+	// SendMessage sends a plain text message to the specified chat. In forum
+	// groups threadID is the topic to post in; 0 posts to the chat itself.
+	SendMessage(chatID int64, threadID int, text string) error
 
 	// SendMessageWithKeyboard sends a message with an inline keyboard
-	SendMessageWithKeyboard(chatID int64, text string, keyboard tgbotapi.InlineKeyboardMarkup) error
+	SendMessageWithKeyboard(chatID int64, threadID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) error
+
+	// SendTrackedMessage sends a message with an inline keyboard and returns its message ID
+	// so it can be edited later
+	SendTrackedMessage(chatID int64, threadID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) (int, error)
+
+	// EditMessageWithKeyboard replaces the text and keyboard of a previously sent message
+	EditMessageWithKeyboard(chatID int64, messageID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) error
+
+	// SendChatAction shows a status such as tgbotapi.ChatTyping in the chat. It
+	// lasts about five seconds or until the bot sends a message.
+	SendChatAction(chatID int64, action string) error
 
 	// SetWebhook configures the webhook URL for receiving updates
 	SetWebhook(webhookURL string) error
@@ -18,8 +32,23 @@ type TelegramProvider interface {
 	// DeleteWebhook removes the configured webhook
 	DeleteWebhook() error
 
+	// GetWebhookInfo returns the webhook Telegram currently delivers updates
+	// to; its URL is empty when none is set
+	GetWebhookInfo() (tgbotapi.WebhookInfo, error)
+
 	// GetMe returns information about the bot
 	GetMe() (*tgbotapi.User, error)
+
+	// GetUpdates long-polls for updates with IDs from offset, waiting up to
+	// timeout seconds. It fails while a webhook is set.
+	GetUpdates(ctx context.Context, offset, timeout int) ([]RawUpdate, error)
+}
+
+// RawUpdate is an update returned by getUpdates in its original JSON form, so
+// it can go through the same pipeline as webhook updates
+type RawUpdate struct {
+	UpdateID int
+	Payload  []byte
 }
 
 // TelegramConfig holds configuration for Telegram provider
@@ -0,0 +1,192 @@
+package chatbot
+
+import (
+	"sync"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// Reactions that act on the task a reminder message is about
+const (
+	ReactionDone   = "👍"
+	ReactionSnooze = "💤"
+)
+
+// reactionActions maps reaction emojis to task actions
+var reactionActions = map[string]string{
+	ReactionDone:   "done",
+	ReactionSnooze: "snooze",
+}
+
+// maxTrackedReminders bounds the reminder messages remembered per chat
+const maxTrackedReminders = 50
+
+// ReactionType is one reaction on a message. Custom emoji and paid reactions
+// have no Emoji.
+type ReactionType struct {
+	Type  string `json:"type"`
+	Emoji string `json:"emoji,omitempty"`
+}
+
+// MessageReaction is a message_reaction update, which the Telegram library
+// predates. Telegram sends the message's reactions by the user before and
+// after the change.
+type MessageReaction struct {
+	Chat        tgbotapi.Chat  `json:"chat"`
+	MessageID   int            `json:"message_id"`
+	User        *tgbotapi.User `json:"user,omitempty"` // absent for anonymous reactions
+	Date        int            `json:"date"`
+	OldReaction []ReactionType `json:"old_reaction"`
+	NewReaction []ReactionType `json:"new_reaction"`
+}
+
+// AddedEmojis returns the emojis the user just added to the message
+func (r *MessageReaction) AddedEmojis() []string {
+	old := make(map[string]bool, len(r.OldReaction))
+	for _, reaction := range r.OldReaction {
+		old[reaction.Emoji] = true
+	}
+
+	var added []string
+	for _, reaction := range r.NewReaction {
+		if reaction.Emoji != "" && !old[reaction.Emoji] {
+			added = append(added, reaction.Emoji)
+		}
+	}
+	return added
+}
+
+// reminderMessage is a reminder sent for a task
+type reminderMessage struct {
+	MessageID int
+	TaskID    string
+	Reminder  events.ReminderDue // what the message was rendered from
+}
+
+// ReminderMessageTracker remembers which task the latest reminder messages in
+// each chat are about, so reactions to them can act on the task
+type ReminderMessageTracker struct {
+	mu       sync.Mutex
+	messages map[string][]reminderMessage
+}
+
+// NewReminderMessageTracker creates an empty tracker
+func NewReminderMessageTracker() *ReminderMessageTracker {
+	return &ReminderMessageTracker{
+		messages: make(map[string][]reminderMessage),
+	}
+}
+
+// Track records a reminder message sent to a chat, forgetting the oldest
+// once the chat has maxTrackedReminders
+func (t *ReminderMessageTracker) Track(chatID string, messageID int, taskID string) {
+	t.TrackReminder(chatID, messageID, events.ReminderDue{TaskID: taskID})
+}
+
+// TrackReminder records a reminder message along with the reminder it was
+// rendered from, so later nudges for the task can refresh it
+func (t *ReminderMessageTracker) TrackReminder(chatID string, messageID int, reminder events.ReminderDue) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	messages := append(t.messages[chatID], reminderMessage{MessageID: messageID, TaskID: reminder.TaskID, Reminder: reminder})
+	if len(messages) > maxTrackedReminders {
+		messages = messages[len(messages)-maxTrackedReminders:]
+	}
+	t.messages[chatID] = messages
+}
+
+// TaskFor returns the task a reminder message in the chat is about
+func (t *ReminderMessageTracker) TaskFor(chatID string, messageID int) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, message := range t.messages[chatID] {
+		if message.MessageID == messageID {
+			return message.TaskID, true
+		}
+	}
+	return "", false
+}
+
+// Earlier returns the reminder messages sent for a task in the chat before
+// messageID, oldest first
+func (t *ReminderMessageTracker) Earlier(chatID, taskID string, messageID int) []reminderMessage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var earlier []reminderMessage
+	for _, message := range t.messages[chatID] {
+		if message.TaskID == taskID && message.MessageID != messageID {
+			earlier = append(earlier, message)
+		}
+	}
+	return earlier
+}
+
+// Forget drops the reminder messages tracked for a chat
+func (t *ReminderMessageTracker) Forget(chatID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.messages, chatID)
+}
+
+// handleReaction completes or snoozes the task of a reminder the user reacted
+// to with 👍 or 💤. Reactions to other messages are ignored.
+func (s *chatbotService) handleReaction(reaction *MessageReaction, correlationID string) error {
+	if reaction.User == nil {
+		return nil
+	}
+
+	var action string
+	for _, emoji := range reaction.AddedEmojis() {
+		if mapped, ok := reactionActions[emoji]; ok {
+			action = mapped
+			break
+		}
+	}
+	if action == "" {
+		return nil
+	}
+
+	chatID, err := s.identities.Resolve(s.config.Name, reaction.Chat.ID)
+	if err != nil {
+		return WrapParsingError(err, "chat_id")
+	}
+	taskID, ok := s.reminderMessages.TaskFor(chatID, reaction.MessageID)
+	if !ok {
+		return nil
+	}
+	userID, err := s.identities.Resolve(s.config.Name, reaction.User.ID)
+	if err != nil {
+		return WrapParsingError(err, "user_id")
+	}
+
+	s.logger.Info("Processing reaction to reminder",
+		zap.String("correlation_id", correlationID),
+		zap.String("user_id", userID),
+		zap.String("task_id", taskID),
+		zap.String("action", action))
+
+	actionEvent := events.TaskActionRequested{
+		Event:  events.NewEvent(),
+		UserID: userID,
+		ChatID: chatID,
+		TaskID: taskID,
+		Action: action,
+	}
+	if err := s.eventBus.Publish(events.TopicTaskActionRequested, actionEvent); err != nil {
+		s.logger.Error("Failed to publish reaction task action",
+			zap.String("correlation_id", correlationID),
+			zap.Error(err))
+		return err
+	}
+
+	s.publishActivity(common.UserID(userID), common.ChatID(chatID), correlationID)
+	return nil
+}
@@ -0,0 +1,94 @@
+package chatbot
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestMessageReaction_AddedEmojis(t *testing.T) {
+	reaction := MessageReaction{
+		OldReaction: []ReactionType{{Type: "emoji", Emoji: "💤"}},
+		NewReaction: []ReactionType{{Type: "emoji", Emoji: "💤"}, {Type: "emoji", Emoji: "👍"}, {Type: "custom_emoji"}},
+	}
+
+	assert.Equal(t, []string{"👍"}, reaction.AddedEmojis())
+}
+
+func TestReminderMessageTracker_ForgetsOldestReminders(t *testing.T) {
+	tracker := NewReminderMessageTracker()
+	for i := 1; i <= maxTrackedReminders+1; i++ {
+		tracker.Track("chat", i, fmt.Sprintf("task-%d", i))
+	}
+
+	_, ok := tracker.TaskFor("chat", 1)
+	assert.False(t, ok)
+	taskID, ok := tracker.TaskFor("chat", maxTrackedReminders+1)
+	assert.True(t, ok)
+	assert.Equal(t, fmt.Sprintf("task-%d", maxTrackedReminders+1), taskID)
+	_, ok = tracker.TaskFor("other-chat", 2)
+	assert.False(t, ok)
+}
+
+func TestChatbotService_ReactionToReminderActsOnTask(t *testing.T) {
+	eventBus := events.NewMockEventBus()
+	eventBus.SetSynchronousMode(true)
+	logger := zaptest.NewLogger(t)
+	chatbot, provider := newBenchService(eventBus, logger)
+
+	const telegramID = 4242
+	chatID, err := chatbot.identities.Resolve("", telegramID)
+	require.NoError(t, err)
+
+	chatbot.handleReminderDue(events.ReminderDue{Event: events.NewEvent(), TaskID: "task-1", UserID: chatID, ChatID: chatID})
+	messageID := int(provider.sent.Load())
+
+	react := func(messageID int, emoji string) {
+		update := fmt.Sprintf(`{"update_id":7,"message_reaction":{"chat":{"id":%d,"type":"private"},"message_id":%d,"user":{"id":%d,"first_name":"Ann"},"date":1,"old_reaction":[],"new_reaction":[{"type":"emoji","emoji":%q}]}}`,
+			telegramID, messageID, telegramID, emoji)
+		require.NoError(t, chatbot.HandleWebhook([]byte(update)))
+	}
+
+	react(messageID, "🔥")
+	react(messageID+1, "👍")
+	assert.Empty(t, eventBus.GetPublishedEvents(events.TopicTaskActionRequested), "only 👍 and 💤 on reminders act on tasks")
+
+	react(messageID, "💤")
+	actions := eventBus.GetPublishedEvents(events.TopicTaskActionRequested)
+	require.Len(t, actions, 1)
+	action := actions[0].(events.TaskActionRequested)
+	assert.Equal(t, "task-1", action.TaskID)
+	assert.Equal(t, "snooze", action.Action)
+	assert.Equal(t, chatID, action.UserID)
+	assert.Equal(t, chatID, action.ChatID)
+}
+
+func TestChatbotService_LaterNudgesRefreshEarlierReminders(t *testing.T) {
+	chatbot, provider := newListTestService(t)
+	chatbot.reminderMessages = NewReminderMessageTracker()
+
+	due := time.Now().Add(3 * time.Hour)
+	reminder := events.ReminderDue{Event: events.NewEvent(), TaskID: "task-1", UserID: "user", ChatID: "42", DueDate: &due}
+	chatbot.handleReminderDue(reminder)
+	require.Len(t, provider.sent, 1)
+	assert.Contains(t, provider.sent[0], "⏳ Due in 3 hours")
+
+	// The task is overdue by the next nudge; both messages now say so
+	later := time.Now().Add(-2 * 24 * time.Hour)
+	reminder.DueDate = &later
+	chatbot.handleReminderDue(reminder)
+	require.Len(t, provider.sent, 2)
+	assert.Contains(t, provider.sent[1], "⏳ Overdue by 2 days")
+	assert.Contains(t, provider.edited[101], "⏳ Due in 3 hours", "the earlier message is re-rendered from its own reminder")
+
+	// Reminders for other tasks are left alone
+	other := events.ReminderDue{Event: events.NewEvent(), TaskID: "task-2", UserID: "user", ChatID: "42", DueDate: &due}
+	chatbot.handleReminderDue(other)
+	assert.Len(t, provider.edited, 1)
+}
@@ -0,0 +1,114 @@
+package chatbot
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// formatReminderGroup lists the tasks of a grouped reminder, numbered like the
+// buttons below it
+func formatReminderGroup(event events.ReminderGroupDue, dates DateFormat) string {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("⏰ <b>%d tasks need your attention</b>\n", len(event.Reminders)))
+	writeReminderList(&text, event.Reminders, dates)
+	text.WriteString("\n\nTap a task to mark it done, or snooze it.")
+	return text.String()
+}
+
+// formatReminderDigest lists the reminders of a daily digest
+func formatReminderDigest(event events.ReminderDigestDue, dates DateFormat) string {
+	var text strings.Builder
+	text.WriteString("📬 <b>Your daily digest</b>\n")
+	writeReminderList(&text, event.Reminders, dates)
+	if event.Holiday != nil {
+		text.WriteString("\n\n" + formatHolidayNotice(*event.Holiday, dates))
+	}
+	if len(event.Conflicts) > 0 {
+		text.WriteString("\n\n" + formatDigestConflicts(event.Conflicts, dates))
+	}
+	text.WriteString("\n\nTap a task to mark it done, or snooze it.")
+	return text.String()
+}
+
+// formatHolidayNotice tells that tomorrow is a public holiday, and where the
+// tasks due then were moved
+func formatHolidayNotice(notice events.HolidayNotice, dates DateFormat) string {
+	text := fmt.Sprintf("🎉 Tomorrow is <b>%s</b>, a public holiday", html.EscapeString(notice.Name))
+	if notice.Moved == 0 {
+		return text + "."
+	}
+	movedTo := notice.MovedTo.In(dates.Location())
+	return fmt.Sprintf("%s — %s moved to %s %s.", text, pluralUnit(notice.Moved, "task"), movedTo.Format("Mon"), dates.Date(movedTo))
+}
+
+// writeReminderList writes the numbered reminders, matching the buttons of
+// BuildReminderGroupKeyboard
+func writeReminderList(text *strings.Builder, reminders []events.ReminderDue, dates DateFormat) {
+	for i, reminder := range reminders {
+		due := ""
+		if reminder.DueDate != nil {
+			due = fmt.Sprintf(" — %s (%s)", dates.DueIn(*reminder.DueDate), dates.DateTime(*reminder.DueDate))
+		}
+		text.WriteString(fmt.Sprintf("\n<b>%d.</b> %s%s", i+1, html.EscapeString(reminderTitle(reminder)), due))
+	}
+}
+
+// reminderTitle is the task title, or its ID when the task could not be loaded
+func reminderTitle(reminder events.ReminderDue) string {
+	if reminder.Title != "" {
+		return reminder.Title
+	}
+	return "Task " + reminder.TaskID
+}
+
+// handleReminderGroupDue sends the reminders due for a chat in one message,
+// with Done and Snooze buttons for each task
+func (s *chatbotService) handleReminderGroupDue(event events.ReminderGroupDue) {
+	if !s.ownsUser(event.UserID) {
+		return
+	}
+
+	log := common.FlowLogger(s.logger, common.LogFlow{
+		UserID:        event.UserID,
+		ChatID:        event.ChatID,
+		CorrelationID: event.CorrelationID,
+	})
+
+	log.Info("Handling ReminderGroupDue event",
+		zap.String("group_id", event.GroupID),
+		zap.Int("reminders", len(event.Reminders)))
+
+	keyboard := s.keyboardBuilder.ToDomainKeyboard(s.keyboardBuilder.BuildReminderGroupKeyboard(event.Reminders))
+	if err := s.SendMessageWithKeyboard(common.ChatID(event.ChatID), formatReminderGroup(event, s.dateFormat(event.UserID)), keyboard); err != nil {
+		log.Error("Failed to send reminder group",
+			zap.Error(err))
+	}
+}
+
+// handleReminderDigestDue sends a chat's daily digest of low-key reminders
+func (s *chatbotService) handleReminderDigestDue(event events.ReminderDigestDue) {
+	if !s.ownsUser(event.UserID) {
+		return
+	}
+
+	log := common.FlowLogger(s.logger, common.LogFlow{
+		UserID:        event.UserID,
+		ChatID:        event.ChatID,
+		CorrelationID: event.CorrelationID,
+	})
+
+	log.Info("Handling ReminderDigestDue event",
+		zap.Int("reminders", len(event.Reminders)))
+
+	keyboard := s.keyboardBuilder.ToDomainKeyboard(s.keyboardBuilder.BuildReminderGroupKeyboard(event.Reminders))
+	if err := s.SendMessageWithKeyboard(common.ChatID(event.ChatID), formatReminderDigest(event, s.dateFormat(event.UserID)), keyboard); err != nil {
+		log.Error("Failed to send reminder digest",
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,68 @@
+package chatbot
+
+import (
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatReminderGroup(t *testing.T) {
+	due := time.Date(2024, 1, 2, 9, 30, 0, 0, time.UTC)
+	event := events.ReminderGroupDue{
+		Reminders: []events.ReminderDue{
+			{TaskID: "1", Title: "Pay <rent>", DueDate: &due},
+			{TaskID: "2"},
+		},
+	}
+
+	text := formatReminderGroup(event, NewDateFormat("", "", due.Add(-45*time.Minute)))
+	assert.Contains(t, text, "2 tasks need your attention")
+	assert.Contains(t, text, "<b>1.</b> Pay &lt;rent&gt; — due in 45 minutes (today 9:30)")
+	assert.Contains(t, text, "<b>2.</b> Task 2")
+}
+
+func TestFormatReminderDigest_Holiday(t *testing.T) {
+	event := events.ReminderDigestDue{
+		Reminders: []events.ReminderDue{{TaskID: "1", Title: "Pay rent"}},
+		Holiday:   &events.HolidayNotice{Name: "Christmas Day", Date: time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC)},
+	}
+	assert.Contains(t, formatReminderDigest(event, fixedDates), "\n\n🎉 Tomorrow is <b>Christmas Day</b>, a public holiday.\n\n")
+
+	event.Holiday.Moved = 3
+	event.Holiday.MovedTo = time.Date(2024, 12, 27, 0, 0, 0, 0, time.UTC)
+	assert.Contains(t, formatReminderDigest(event, fixedDates), "🎉 Tomorrow is <b>Christmas Day</b>, a public holiday — 3 tasks moved to Fri Dec 27.")
+}
+
+func TestKeyboardBuilder_ReminderGroupKeyboard(t *testing.T) {
+	kb := NewKeyboardBuilder()
+	markup := kb.BuildReminderGroupKeyboard([]events.ReminderDue{
+		{TaskID: "1", Title: "Pay rent"},
+		{TaskID: "2", Title: "Call mom"},
+		{TaskID: "3", Title: "Stretch"},
+	})
+
+	require.Len(t, markup.InlineKeyboard, 3, "one row per task")
+	for i, row := range markup.InlineKeyboard {
+		require.Len(t, row, 3)
+
+		done, err := kb.DecodeCallbackData(*row[0].CallbackData)
+		require.NoError(t, err)
+		assert.Equal(t, CallbackActionDone, done.Action)
+
+		snooze, err := kb.DecodeCallbackData(*row[1].CallbackData)
+		require.NoError(t, err)
+		assert.Equal(t, CallbackActionSnooze, snooze.Action)
+		assert.Equal(t, done.Data["task_id"], snooze.Data["task_id"])
+
+		mute, err := kb.DecodeCallbackData(*row[2].CallbackData)
+		require.NoError(t, err)
+		assert.Equal(t, CallbackActionMute, mute.Action)
+		assert.Equal(t, string(rune('1'+i)), done.Data["task_id"])
+	}
+	assert.Equal(t, "✅ 2. Call mom", markup.InlineKeyboard[1][0].Text)
+	assert.Equal(t, "⏰", markup.InlineKeyboard[1][1].Text)
+}
@@ -0,0 +1,60 @@
+package chatbot
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// parseRetention reads a /retention argument such as "forever", "90d" or
+// "30" into days, 0 for forever
+func parseRetention(arg string) (int, bool) {
+	arg = strings.ToLower(strings.TrimSpace(arg))
+	if arg == "forever" {
+		return 0, true
+	}
+	days, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSuffix(arg, "days"), "d"))
+	if err != nil || days <= 0 {
+		return 0, false
+	}
+	return days, true
+}
+
+// formatRetention describes how long completed tasks are kept
+func formatRetention(days int) string {
+	if days == 0 {
+		return "forever"
+	}
+	return fmt.Sprintf("for %d days", days)
+}
+
+// handleRetentionResponse tells the user how long their completed tasks are kept
+func (s *chatbotService) handleRetentionResponse(event events.RetentionResponse) {
+	if !s.ownsUser(event.UserID) {
+		return
+	}
+
+	var text string
+	switch {
+	case !event.Success:
+		text = fmt.Sprintf("❌ <b>Retention Not Changed</b>\n\n%s", html.EscapeString(event.Message))
+	case event.Changed && event.Days == 0:
+		text = "🗄 Completed tasks are now kept forever."
+	case event.Changed:
+		text = fmt.Sprintf("🗄 Completed tasks are now deleted %d days after you complete them.", event.Days)
+	default:
+		text = fmt.Sprintf("🗄 Completed tasks are kept %s.\n\nChange it with /retention forever, /retention 90d or /retention 30d.", formatRetention(event.Days))
+	}
+
+	if err := s.SendMessage(common.ChatID(event.ChatID), text); err != nil {
+		s.logger.Error("Failed to send retention response",
+			zap.String("correlation_id", event.CorrelationID),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,42 @@
+package chatbot
+
+import (
+	"testing"
+
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestParseRetention(t *testing.T) {
+	for arg, want := range map[string]int{"forever": 0, "Forever": 0, "90d": 90, "30": 30, "30days": 30} {
+		days, ok := parseRetention(arg)
+		assert.True(t, ok, arg)
+		assert.Equal(t, want, days, arg)
+	}
+	for _, arg := range []string{"", "soon", "-5d", "0"} {
+		_, ok := parseRetention(arg)
+		assert.False(t, ok, arg)
+	}
+}
+
+func TestCommandProcessor_ProcessRetentionCommand(t *testing.T) {
+	eventBus := events.NewMockEventBus()
+	processor := NewCommandProcessor(eventBus, zaptest.NewLogger(t))
+
+	response, err := processor.ProcessRetentionCommand("user", "42", []string{"soon"})
+	require.NoError(t, err)
+	assert.Contains(t, response, "Usage: /retention")
+	assert.Empty(t, eventBus.GetPublishedEvents(events.TopicRetentionRequested))
+
+	response, err = processor.ProcessRetentionCommand("user", "42", []string{"90d"})
+	require.NoError(t, err)
+	assert.Empty(t, response)
+	requests := eventBus.GetPublishedEvents(events.TopicRetentionRequested)
+	require.Len(t, requests, 1)
+	request := requests[0].(events.RetentionRequested)
+	require.NotNil(t, request.Days)
+	assert.Equal(t, 90, *request.Days)
+}
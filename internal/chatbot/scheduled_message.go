@@ -0,0 +1,59 @@
+package chatbot
+
+import (
+	"fmt"
+	"html"
+
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// formatScheduledMessageCreated confirms when a message will be sent
+func formatScheduledMessageCreated(event events.ScheduledMessageCreated, dates DateFormat) string {
+	return fmt.Sprintf("📨 Got it, I'll send you this %s:\n\n<i>%s</i>",
+		dates.DateTime(event.SendAt), html.EscapeString(event.Text))
+}
+
+// formatScheduledMessageDue is the scheduled message as delivered
+func formatScheduledMessageDue(event events.ScheduledMessageDue) string {
+	return "📨 <b>You asked me to send you this</b>\n\n" + html.EscapeString(event.Text)
+}
+
+// sendToThread sends a message to a forum topic of a chat, or to the chat
+// itself when threadID is 0
+func (s *chatbotService) sendToThread(chatID string, threadID int, text string) error {
+	chatIDInt, err := s.telegramChatID(chatID)
+	if err != nil {
+		return err
+	}
+	return s.provider.SendMessage(chatIDInt, threadID, text)
+}
+
+// handleScheduledMessageCreated confirms a message scheduled with "send me X at 5pm"
+func (s *chatbotService) handleScheduledMessageCreated(event events.ScheduledMessageCreated) {
+	if !s.ownsUser(event.UserID) {
+		return
+	}
+
+	s.typing.Stop(event.ChatID)
+	if err := s.sendToThread(event.ChatID, event.ThreadID, formatScheduledMessageCreated(event, s.dateFormat(event.UserID))); err != nil {
+		s.logger.Error("Failed to confirm scheduled message",
+			zap.String("correlation_id", event.CorrelationID),
+			zap.String("message_id", event.MessageID),
+			zap.Error(err))
+	}
+}
+
+// handleScheduledMessageDue delivers a scheduled message
+func (s *chatbotService) handleScheduledMessageDue(event events.ScheduledMessageDue) {
+	if !s.ownsUser(event.UserID) {
+		return
+	}
+
+	if err := s.sendToThread(event.ChatID, event.ThreadID, formatScheduledMessageDue(event)); err != nil {
+		s.logger.Error("Failed to send scheduled message",
+			zap.String("message_id", event.MessageID),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,33 @@
+package chatbot
+
+import (
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestChatbotService_SendsScheduledMessages(t *testing.T) {
+	eventBus := events.NewMockEventBus()
+	eventBus.SetSynchronousMode(true)
+	chatbot, _ := newBenchService(eventBus, zaptest.NewLogger(t))
+	provider := &threadRecordingProvider{}
+	chatbot.provider = provider
+
+	sendAt := time.Date(2024, 1, 10, 17, 0, 0, 0, time.UTC)
+	require.NoError(t, eventBus.Publish(events.TopicScheduledMessageCreated, events.ScheduledMessageCreated{
+		Event: events.NewEvent(), MessageID: "m1", UserID: "user", ChatID: "42", Text: "Gate code <1234>", SendAt: sendAt,
+	}))
+	require.NoError(t, eventBus.Publish(events.TopicScheduledMessageDue, events.ScheduledMessageDue{
+		Event: events.NewEvent(), MessageID: "m1", UserID: "user", ChatID: "42", ThreadID: 7, Text: "Gate code <1234>",
+	}))
+
+	require.Len(t, provider.texts, 2)
+	assert.Contains(t, provider.texts[0], "Jan 10, 2024 17:00")
+	assert.Contains(t, provider.texts[1], "Gate code &lt;1234&gt;")
+	assert.Equal(t, []int{0, 7}, provider.threads)
+}
@@ -1,13 +1,23 @@
 package chatbot
 
 import (
+	"context"
 	"fmt"
-	"strconv"
+	"html"
 	"strings"
+	"sync/atomic"
+	"time"
 
+	"nudgebot-api/internal/chaos"
 	"nudgebot-api/internal/common"
 	"nudgebot-api/internal/config"
+	"nudgebot-api/internal/debugcapture"
 	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/featureflags"
+	"nudgebot-api/internal/httpclient"
+	"nudgebot-api/internal/moderation"
+	"nudgebot-api/internal/probe"
+	"nudgebot-api/internal/user"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"go.uber.org/zap"
@@ -19,6 +29,8 @@ type ChatbotService interface {
 	SendMessageWithKeyboard(chatID common.ChatID, text string, keyboard InlineKeyboard) error
 	HandleWebhook(webhookData []byte) error
 	ProcessCommand(command Command, userID common.UserID, chatID common.ChatID) error
+	Ready() <-chan struct{}
+	common.Service
 }
 
 // chatbotService implements the ChatbotService interface
@@ -29,41 +41,171 @@ type chatbotService struct {
 	parser           *WebhookParser
 	keyboardBuilder  *KeyboardBuilder
 	commandProcessor *CommandProcessor
+	authorizer       *CommandAuthorizer
+	moderation       *moderation.Policy
+	aggregator       *MessageAggregator
+	listMessages     *ListMessageTracker
+	reminderMessages *ReminderMessageTracker
+	threads          *ThreadTracker
+	updates          *UpdateQueue
+	poller           *UpdatePoller
+	webhook          *WebhookRegistrar
+	directory        BotDirectory
+	users            user.Provisioner
+	identities       IdentityMap
+	capture          *debugcapture.Recorder
+	prefs            *userPrefsCache
+	flags            featureflags.Checker
+	load             *loadShedState
+	maintenance      *maintenanceState
+	typing           *TypingIndicator
+	streams          *StreamThrottle
+	ready            *common.Readiness
+	stopped          atomic.Bool
 	config           config.ChatbotConfig
 }
 
 // NewChatbotService creates a new instance of ChatbotService
 func NewChatbotService(eventBus events.EventBus, logger *zap.Logger, cfg config.ChatbotConfig) (ChatbotService, error) {
+	return NewChatbotServiceWithChaos(eventBus, logger, cfg, nil)
+}
+
+// NewChatbotServiceWithChaos creates a ChatbotService whose Telegram calls fail
+// at the rates configured on the injector. A nil injector disables injection.
+func NewChatbotServiceWithChaos(eventBus events.EventBus, logger *zap.Logger, cfg config.ChatbotConfig, injector *chaos.Injector) (ChatbotService, error) {
+	return NewChatbotServiceWithDirectory(eventBus, logger, cfg, injector, nil)
+}
+
+// NewChatbotServiceWithDirectory creates the ChatbotService for the bot named
+// by cfg.Name in a deployment running several bots. Users are scoped to the
+// bot they talk to, and the directory records them so the service only
+// delivers events about its own users. A nil directory delivers every event.
+func NewChatbotServiceWithDirectory(eventBus events.EventBus, logger *zap.Logger, cfg config.ChatbotConfig, injector *chaos.Injector, directory BotDirectory) (ChatbotService, error) {
+	return NewChatbotServiceWithUsers(eventBus, logger, cfg, injector, directory, nil)
+}
+
+// NewChatbotServiceWithUsers creates a ChatbotService that provisions a user
+// profile for every sender of an incoming update. A nil provisioner skips
+// provisioning.
+func NewChatbotServiceWithUsers(eventBus events.EventBus, logger *zap.Logger, cfg config.ChatbotConfig, injector *chaos.Injector, directory BotDirectory, users user.Provisioner) (ChatbotService, error) {
+	return NewChatbotServiceWithIdentities(eventBus, logger, cfg, injector, directory, users, nil)
+}
+
+// NewChatbotServiceWithIdentities creates a ChatbotService that maps Telegram
+// user and chat IDs to internal IDs through identities. A nil map keeps the
+// mappings in memory, so chats must be seen again after a restart before
+// messages can be sent to them.
+func NewChatbotServiceWithIdentities(eventBus events.EventBus, logger *zap.Logger, cfg config.ChatbotConfig, injector *chaos.Injector, directory BotDirectory, users user.Provisioner, identities IdentityMap) (ChatbotService, error) {
+	return NewChatbotServiceWithCapture(eventBus, logger, cfg, injector, directory, users, identities, nil)
+}
+
+// NewChatbotServiceWithCapture creates a ChatbotService that records the raw
+// updates and Bot API calls of sampled chats on the recorder. A nil recorder
+// disables capture.
+func NewChatbotServiceWithCapture(eventBus events.EventBus, logger *zap.Logger, cfg config.ChatbotConfig, injector *chaos.Injector, directory BotDirectory, users user.Provisioner, identities IdentityMap, recorder *debugcapture.Recorder) (ChatbotService, error) {
+	return NewChatbotServiceWithHTTPClient(eventBus, logger, cfg, injector, directory, users, identities, recorder, nil)
+}
+
+// NewChatbotServiceWithHTTPClient creates a ChatbotService whose Bot API calls
+// go through httpClient, which is responsible for timeouts and retries. A nil
+// client uses the library's default client.
+func NewChatbotServiceWithHTTPClient(eventBus events.EventBus, logger *zap.Logger, cfg config.ChatbotConfig, injector *chaos.Injector, directory BotDirectory, users user.Provisioner, identities IdentityMap, recorder *debugcapture.Recorder, httpClient httpclient.Doer) (ChatbotService, error) {
+	return NewChatbotServiceWithPrefs(eventBus, logger, cfg, injector, directory, users, identities, recorder, httpClient, nil)
+}
+
+// NewChatbotServiceWithPrefs creates a ChatbotService that shows dates in the
+// time zone and language each user chose, as found by prefs. A nil resolver
+// shows them in UTC and the language of the user's Telegram client.
+func NewChatbotServiceWithPrefs(eventBus events.EventBus, logger *zap.Logger, cfg config.ChatbotConfig, injector *chaos.Injector, directory BotDirectory, users user.Provisioner, identities IdentityMap, recorder *debugcapture.Recorder, httpClient httpclient.Doer, prefs PrefsResolver) (ChatbotService, error) {
+	return NewChatbotServiceWithFlags(eventBus, logger, cfg, injector, directory, users, identities, recorder, httpClient, prefs, nil)
+}
+
+// NewChatbotServiceWithFlags creates a ChatbotService that only answers in
+// group chats for users with the group_mode flag. Nil flags answer everywhere.
+func NewChatbotServiceWithFlags(eventBus events.EventBus, logger *zap.Logger, cfg config.ChatbotConfig, injector *chaos.Injector, directory BotDirectory, users user.Provisioner, identities IdentityMap, recorder *debugcapture.Recorder, httpClient httpclient.Doer, prefs PrefsResolver, flags featureflags.Checker) (ChatbotService, error) {
+	return NewChatbotServiceWithTaskCodes(eventBus, logger, cfg, injector, directory, users, identities, recorder, httpClient, prefs, flags, nil)
+}
+
+// NewChatbotServiceWithTaskCodes creates a ChatbotService that looks up task
+// codes such as T-42 with codes when it has not shown them itself, as after a
+// restart or on another replica. A nil resolver only knows the codes this
+// process has shown.
+func NewChatbotServiceWithTaskCodes(eventBus events.EventBus, logger *zap.Logger, cfg config.ChatbotConfig, injector *chaos.Injector, directory BotDirectory, users user.Provisioner, identities IdentityMap, recorder *debugcapture.Recorder, httpClient httpclient.Doer, prefs PrefsResolver, flags featureflags.Checker, codes TaskCodeResolver) (ChatbotService, error) {
+	if identities == nil {
+		identities = NewMemoryIdentityMap()
+	}
+
+	if cfg.Name != "" {
+		logger = logger.With(zap.String("bot", cfg.Name))
+	}
+
+	mode, err := ResolveReceiveMode(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	authorizer, err := NewCommandAuthorizer(cfg.Authorization)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create Telegram provider
-	provider, err := NewTelegramProvider(cfg, logger)
+	telegramProvider, err := NewTelegramProviderWithClient(cfg, httpClient, logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create telegram provider: %w", err)
 	}
+	// Capture wraps chaos so injected failures show up in captured calls
+	provider := NewCaptureTelegramProvider(NewChaosTelegramProvider(telegramProvider, injector), recorder, cfg.Name)
 
 	service := &chatbotService{
 		eventBus:         eventBus,
 		logger:           logger,
 		provider:         provider,
-		parser:           NewWebhookParser(),
-		keyboardBuilder:  NewKeyboardBuilder(),
-		commandProcessor: NewCommandProcessor(eventBus, logger),
+		parser:           NewWebhookParserForBot(cfg.Name),
+		keyboardBuilder:  NewKeyboardBuilderWithLayout(NewKeyboardLayoutFromConfig(cfg.Keyboard)),
+		commandProcessor: NewCommandProcessorWithTaskCodes(eventBus, logger, codes),
+		authorizer:       authorizer,
+		moderation:       moderation.NewPolicyFromConfig(cfg.Moderation, logger),
+		listMessages:     NewListMessageTracker(),
+		reminderMessages: NewReminderMessageTracker(),
+		threads:          NewThreadTracker(),
+		directory:        directory,
+		users:            users,
+		identities:       identities,
+		capture:          recorder,
+		prefs:            newUserPrefsCache(prefs, prefsCacheTTL),
+		flags:            flags,
+		load:             newLoadShedState(),
+		maintenance:      &maintenanceState{},
+		streams:          NewStreamThrottle(streamEditInterval),
+		ready:            common.NewReadiness(),
 		config:           cfg,
 	}
+	service.aggregator = NewMessageAggregator(
+		time.Duration(cfg.AggregationWindowMs)*time.Millisecond,
+		cfg.AggregationMaxMessages,
+		service.publishMessageBatch,
+	)
+	service.updates = NewUpdateQueue(cfg.UpdateQueueSize, logger, service.processUpdate)
+	service.typing = NewTypingIndicator(service.sendTyping, typingRefreshInterval, typingMaxDuration, logger)
 
 	// Subscribe to relevant events
 	service.setupEventSubscriptions()
 
-	// Setup webhook if configured and looks like a full URL
-	if cfg.WebhookURL != "" {
+	if mode == ReceiveModePolling {
+		// The webhook is deleted when polling starts; Telegram rejects getUpdates while one is set
+		service.poller = NewUpdatePoller(provider, cfg.PollTimeout, logger, service.publishPolledUpdate)
+		logger.Info("Receiving updates by polling instead of webhook")
+	} else if cfg.WebhookURL != "" {
+		// Setup webhook if configured and looks like a full URL
 		// Telegram requires a full HTTPS URL for webhooks (not a path).
 		// Only attempt to set the webhook automatically when the value
 		// appears to be a URL (starts with http/https). If a relative
 		// path is provided (e.g. "/api/v1/telegram/webhook"), skip
 		// automatic registration so local development isn't blocked.
+		// Registration waits for Serving, once the webhook route is served.
 		if strings.HasPrefix(cfg.WebhookURL, "http://") || strings.HasPrefix(cfg.WebhookURL, "https://") {
-			if err := provider.SetWebhook(cfg.WebhookURL); err != nil {
-				logger.Warn("Failed to set webhook", zap.Error(err))
-			}
+			service.webhook = NewWebhookRegistrarWithCertificate(provider, cfg.WebhookURL, cfg.WebhookCertificate != "", logger)
 		} else {
 			logger.Info("Skipping automatic webhook registration because configured webhook URL is not a full HTTP(S) URL", zap.String("webhook_url", cfg.WebhookURL))
 		}
@@ -80,12 +222,30 @@ func (s *chatbotService) setupEventSubscriptions() {
 		s.logger.Error("Failed to subscribe to TaskParsed events", zap.Error(err))
 	}
 
+	// Subscribe to TaskParseFailed events to stop the typing indicator
+	err = s.eventBus.Subscribe(events.TopicTaskParseFailed, s.handleTaskParseFailed)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to TaskParseFailed events", zap.Error(err))
+	}
+
 	// Subscribe to ReminderDue events
 	err = s.eventBus.Subscribe(events.TopicReminderDue, s.handleReminderDue)
 	if err != nil {
 		s.logger.Error("Failed to subscribe to ReminderDue events", zap.Error(err))
 	}
 
+	// Subscribe to ReminderGroupDue events for users who group their reminders
+	err = s.eventBus.Subscribe(events.TopicReminderGroupDue, s.handleReminderGroupDue)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to ReminderGroupDue events", zap.Error(err))
+	}
+
+	// Subscribe to ReminderDigestDue events for reminders routed to the daily digest
+	err = s.eventBus.Subscribe(events.TopicReminderDigestDue, s.handleReminderDigestDue)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to ReminderDigestDue events", zap.Error(err))
+	}
+
 	// Subscribe to TaskListResponse events from the nudge service
 	err = s.eventBus.Subscribe(events.TopicTaskListResponse, s.handleTaskListResponse)
 	if err != nil {
@@ -98,11 +258,251 @@ func (s *chatbotService) setupEventSubscriptions() {
 		s.logger.Error("Failed to subscribe to TaskActionResponse events", zap.Error(err))
 	}
 
+	// Subscribe to TaskHistoryResponse events from the history service
+	err = s.eventBus.Subscribe(events.TopicTaskHistoryResponse, s.handleTaskHistoryResponse)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to TaskHistoryResponse events", zap.Error(err))
+	}
+
+	// Subscribe to TaskRescheduleResponse events for /snoozeall and /moveto
+	err = s.eventBus.Subscribe(events.TopicTaskRescheduleResponse, s.handleTaskRescheduleResponse)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to TaskRescheduleResponse events", zap.Error(err))
+	}
+
+	// Subscribe to APITokenResponse events for /apitoken
+	err = s.eventBus.Subscribe(events.TopicAPITokenResponse, s.handleAPITokenResponse)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to APITokenResponse events", zap.Error(err))
+	}
+
+	// Subscribe to WeeklyStatsResponse events for /stats
+	err = s.eventBus.Subscribe(events.TopicWeeklyStatsResponse, s.handleWeeklyStatsResponse)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to WeeklyStatsResponse events", zap.Error(err))
+	}
+
+	// Subscribe to TaskSlipped events for accountability messages
+	err = s.eventBus.Subscribe(events.TopicTaskSlipped, s.handleTaskSlipped)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to TaskSlipped events", zap.Error(err))
+	}
+
+	// Subscribe to HabitMissed events for missed-period nudges
+	err = s.eventBus.Subscribe(events.TopicHabitMissed, s.handleHabitMissed)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to HabitMissed events", zap.Error(err))
+	}
+
 	// Subscribe to TaskCreated events for confirmation messages
 	err = s.eventBus.Subscribe(events.TopicTaskCreated, s.handleTaskCreated)
 	if err != nil {
 		s.logger.Error("Failed to subscribe to TaskCreated events", zap.Error(err))
 	}
+
+	// Subscribe to TasksCreated events for combined confirmation messages
+	err = s.eventBus.Subscribe(events.TopicTasksCreated, s.handleTasksCreated)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to TasksCreated events", zap.Error(err))
+	}
+
+	// Subscribe to workspace invite and join outcomes
+	err = s.eventBus.Subscribe(events.TopicWorkspaceInviteResponse, s.handleWorkspaceInviteResponse)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to WorkspaceInviteResponse events", zap.Error(err))
+	}
+
+	err = s.eventBus.Subscribe(events.TopicWorkspaceJoinResponse, s.handleWorkspaceJoinResponse)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to WorkspaceJoinResponse events", zap.Error(err))
+	}
+
+	// Subscribe to shared list outcomes and activity
+	err = s.eventBus.Subscribe(events.TopicListResponse, s.handleListResponse)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to ListResponse events", zap.Error(err))
+	}
+
+	err = s.eventBus.Subscribe(events.TopicListsResponse, s.handleListsResponse)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to ListsResponse events", zap.Error(err))
+	}
+
+	err = s.eventBus.Subscribe(events.TopicListTaskCompleted, s.handleListTaskCompleted)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to ListTaskCompleted events", zap.Error(err))
+	}
+
+	err = s.eventBus.Subscribe(events.TopicTaskDelegationResponse, s.handleTaskDelegationResponse)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to TaskDelegationResponse events", zap.Error(err))
+	}
+
+	err = s.eventBus.Subscribe(events.TopicTaskDelegated, s.handleTaskDelegated)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to TaskDelegated events", zap.Error(err))
+	}
+
+	err = s.eventBus.Subscribe(events.TopicTaskDelegationResolved, s.handleTaskDelegationResolved)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to TaskDelegationResolved events", zap.Error(err))
+	}
+
+	err = s.eventBus.Subscribe(events.TopicScheduledMessageCreated, s.handleScheduledMessageCreated)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to ScheduledMessageCreated events", zap.Error(err))
+	}
+
+	err = s.eventBus.Subscribe(events.TopicScheduledMessageDue, s.handleScheduledMessageDue)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to ScheduledMessageDue events", zap.Error(err))
+	}
+
+	err = s.eventBus.Subscribe(events.TopicCountdownUpdated, s.handleCountdownUpdated)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to CountdownUpdated events", zap.Error(err))
+	}
+
+	err = s.eventBus.Subscribe(events.TopicTaskOriginalResponse, s.handleTaskOriginalResponse)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to TaskOriginalResponse events", zap.Error(err))
+	}
+
+	err = s.eventBus.Subscribe(events.TopicTaskDetailsResponse, s.handleTaskDetailsResponse)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to TaskDetailsResponse events", zap.Error(err))
+	}
+
+	// Subscribe to TextGenerated events to show streamed text as it arrives
+	err = s.eventBus.Subscribe(events.TopicTextGenerated, s.handleTextGenerated)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to TextGenerated events", zap.Error(err))
+	}
+
+	// Subscribe to RetentionResponse events to answer /retention
+	err = s.eventBus.Subscribe(events.TopicRetentionResponse, s.handleRetentionResponse)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to RetentionResponse events", zap.Error(err))
+	}
+
+	// Subscribe to the answers to admin commands
+	err = s.eventBus.Subscribe(events.TopicBroadcastReady, s.handleBroadcastReady)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to BroadcastReady events", zap.Error(err))
+	}
+	err = s.eventBus.Subscribe(events.TopicGlobalStatsResponse, s.handleGlobalStatsResponse)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to GlobalStatsResponse events", zap.Error(err))
+	}
+
+	// Subscribe to MaintenanceChanged events to answer with the maintenance notice
+	err = s.eventBus.Subscribe(events.TopicMaintenanceChanged, s.handleMaintenanceChanged)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to MaintenanceChanged events", zap.Error(err))
+	}
+
+	// Subscribe to SettingsResponse events to show the /settings menu
+	err = s.eventBus.Subscribe(events.TopicSettingsResponse, s.handleSettingsResponse)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to SettingsResponse events", zap.Error(err))
+	}
+
+	// Subscribe to PlanUpdated events to show day plans as they are written
+	err = s.eventBus.Subscribe(events.TopicPlanUpdated, s.handlePlanUpdated)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to PlanUpdated events", zap.Error(err))
+	}
+
+	// Subscribe to UpdateReceived events queued by the webhook handler
+	err = s.eventBus.Subscribe(events.TopicUpdateReceived, s.handleUpdateReceived)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to UpdateReceived events", zap.Error(err))
+	}
+
+	// Subscribe to SystemLoadChanged events to shed load while degraded
+	err = s.eventBus.Subscribe(events.TopicSystemLoadChanged, s.handleSystemLoadChanged)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to SystemLoadChanged events", zap.Error(err))
+	}
+
+	// Subscribe to account merges, which move Telegram accounts between users
+	err = s.eventBus.Subscribe(events.TopicAccountMerged, s.handleAccountMerged)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to AccountMerged events", zap.Error(err))
+	}
+
+	err = s.eventBus.Subscribe(events.TopicAccountMergeUndone, s.handleAccountMergeUndone)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to AccountMergeUndone events", zap.Error(err))
+	}
+
+	// Subscribe to WelcomeBack events for users who unblocked the bot
+	err = s.eventBus.Subscribe(events.TopicWelcomeBack, s.handleWelcomeBack)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to WelcomeBack events", zap.Error(err))
+	}
+
+	s.ready.MarkReady()
+}
+
+// Ready returns a channel that is closed once event subscriptions are registered
+func (s *chatbotService) Ready() <-chan struct{} {
+	return s.ready.Ready()
+}
+
+// Start marks the service as running, starts consuming queued updates and,
+// in polling mode, deletes the webhook and starts polling
+func (s *chatbotService) Start(ctx context.Context) error {
+	s.stopped.Store(false)
+	s.updates.Start(ctx)
+
+	if s.poller != nil {
+		if err := s.provider.DeleteWebhook(); err != nil {
+			s.logger.Warn("Failed to delete webhook before polling", zap.Error(err))
+		}
+		s.poller.Start(ctx)
+	}
+	return nil
+}
+
+// Serving registers the webhook now that the server is serving its route
+func (s *chatbotService) Serving(ctx context.Context) {
+	if s.webhook != nil {
+		s.webhook.Start(ctx)
+	}
+}
+
+// Stop stops polling, processes the updates still queued and marks the service as stopped
+func (s *chatbotService) Stop(ctx context.Context) error {
+	s.stopped.Store(true)
+	s.typing.StopAll()
+	// Runs last, once no more updates arrive, so that messages still waiting
+	// for their batch window are published rather than lost
+	defer s.aggregator.FlushAll()
+
+	if s.webhook != nil {
+		if err := s.webhook.Stop(ctx); err != nil {
+			return err
+		}
+	}
+
+	if s.poller != nil {
+		if err := s.poller.Stop(ctx); err != nil {
+			return err
+		}
+	}
+	return s.updates.Stop(ctx)
+}
+
+// Health reports whether the service is ready and has not been stopped
+func (s *chatbotService) Health() error {
+	if !s.ready.IsReady() {
+		return common.ErrServiceNotReady
+	}
+	if s.stopped.Load() {
+		return common.ErrServiceStopped
+	}
+	return nil
 }
 
 // SendMessage sends a text message to the specified chat
@@ -111,12 +511,12 @@ func (s *chatbotService) SendMessage(chatID common.ChatID, text string) error {
 		zap.String("chat_id", string(chatID)),
 		zap.Int("text_length", len(text)))
 
-	chatIDInt, err := strconv.ParseInt(string(chatID), 10, 64)
+	chatIDInt, err := s.telegramChatID(string(chatID))
 	if err != nil {
-		return fmt.Errorf("invalid chat ID: %w", err)
+		return err
 	}
 
-	return s.provider.SendMessage(chatIDInt, text)
+	return s.provider.SendMessage(chatIDInt, s.threads.Get(string(chatID)), text)
 }
 
 // SendMessageWithKeyboard sends a message with an inline keyboard to the specified chat
@@ -126,19 +526,41 @@ func (s *chatbotService) SendMessageWithKeyboard(chatID common.ChatID, text stri
 		zap.Int("text_length", len(text)),
 		zap.Int("keyboard_rows", len(keyboard.Buttons)))
 
-	chatIDInt, err := strconv.ParseInt(string(chatID), 10, 64)
+	chatIDInt, err := s.telegramChatID(string(chatID))
 	if err != nil {
-		return fmt.Errorf("invalid chat ID: %w", err)
+		return err
 	}
 
 	// Convert domain keyboard to Telegram format
 	tgKeyboard := s.keyboardBuilder.ConvertDomainKeyboard(keyboard)
 
-	return s.provider.SendMessageWithKeyboard(chatIDInt, text, tgKeyboard)
+	return s.provider.SendMessageWithKeyboard(chatIDInt, s.threads.Get(string(chatID)), text, tgKeyboard)
 }
 
 // HandleWebhook processes incoming webhook data from Telegram
 func (s *chatbotService) HandleWebhook(webhookData []byte) error {
+	err := s.handleUpdate(webhookData)
+	s.captureUpdate(webhookData, err)
+	return err
+}
+
+// captureUpdate records a handled update on the debug capture recorder
+func (s *chatbotService) captureUpdate(webhookData []byte, handleErr error) {
+	if s.capture == nil {
+		return
+	}
+
+	var chatID int64
+	if update, err := s.parser.ParseUpdate(webhookData); err == nil {
+		if chat := update.FromChat(); chat != nil {
+			chatID = chat.ID
+		}
+	}
+	s.capture.RecordUpdate(s.config.Name, chatID, webhookData, handleErr)
+}
+
+// handleUpdate parses an update and routes it by message type
+func (s *chatbotService) handleUpdate(webhookData []byte) error {
 	correlationID := fmt.Sprintf("webhook_%d", len(webhookData))
 	s.logger.Debug("Handling webhook",
 		zap.String("correlation_id", correlationID),
@@ -156,8 +578,30 @@ func (s *chatbotService) HandleWebhook(webhookData []byte) error {
 	// Get correlation ID from update
 	correlationID = s.parser.BuildCorrelationID(update)
 
+	// Reactions are not decoded into the update and name their own user and chat
+	reaction, err := s.parser.ExtractReaction(webhookData)
+	if err != nil {
+		return WrapParsingError(err, "message_reaction")
+	}
+	if reaction != nil {
+		if s.maintenance.current().Enabled {
+			return nil // Reactions change tasks, which wait until maintenance ends
+		}
+		return s.handleReaction(reaction, correlationID)
+	}
+
+	// Blocking and unblocking the bot carry no message
+	if update.MyChatMember != nil {
+		return s.handleMyChatMember(update.MyChatMember, correlationID)
+	}
+
+	// Group upgrades are service messages that may not name a user
+	if migration, err := s.handleChatMigration(update, correlationID); migration {
+		return err
+	}
+
 	// Extract user and chat information
-	userID, err := s.parser.GetUserID(update)
+	userID, err := s.resolveUserID(update)
 	if err != nil {
 		s.logger.Error("Failed to extract user ID",
 			zap.String("correlation_id", correlationID),
@@ -165,7 +609,7 @@ func (s *chatbotService) HandleWebhook(webhookData []byte) error {
 		return WrapParsingError(err, "user_id")
 	}
 
-	chatID, err := s.parser.GetChatID(update)
+	chatID, err := s.resolveChatID(update)
 	if err != nil {
 		s.logger.Error("Failed to extract chat ID",
 			zap.String("correlation_id", correlationID),
@@ -173,6 +617,36 @@ func (s *chatbotService) HandleWebhook(webhookData []byte) error {
 		return WrapParsingError(err, "chat_id")
 	}
 
+	// Replies go to the forum topic the user wrote in
+	s.threads.Set(string(chatID), s.parser.ExtractThreadID(webhookData))
+
+	log := common.FlowLogger(s.logger, common.LogFlow{
+		UserID:        string(userID),
+		ChatID:        string(chatID),
+		CorrelationID: correlationID,
+	})
+
+	if !s.groupModeAllows(update, userID) {
+		log.Debug("Ignoring group chat update without group mode")
+		return nil
+	}
+
+	if s.maintenance.current().Enabled && !s.maintenanceExempt(update) {
+		return s.sendMaintenanceNotice(string(chatID))
+	}
+
+	if s.directory != nil {
+		if err := s.directory.Remember(userID, s.config.Name); err != nil {
+			log.Warn("Failed to record the user's bot", zap.Error(err))
+		}
+	}
+
+	s.provisionUser(update, userID, correlationID)
+	if sender, err := s.parser.GetSender(update); err == nil {
+		s.prefs.SetClientLocale(userID, sender.LanguageCode)
+	}
+	s.publishActivity(userID, chatID, correlationID)
+
 	// Determine the type of update and handle accordingly
 	messageType := s.parser.DetermineMessageType(update)
 
@@ -184,13 +658,121 @@ func (s *chatbotService) HandleWebhook(webhookData []byte) error {
 	case MessageTypeCallback:
 		return s.handleCallbackQuery(update, string(userID), string(chatID), correlationID)
 	default:
-		s.logger.Warn("Unknown message type",
-			zap.String("correlation_id", correlationID),
-			zap.String("message_type", string(messageType)))
+		log.Warn("Unknown message type", zap.String("message_type", string(messageType)))
 		return nil
 	}
 }
 
+// groupModeAllows reports whether the bot answers the update; group chats
+// are answered only for users with the group_mode flag
+func (s *chatbotService) groupModeAllows(update *tgbotapi.Update, userID common.UserID) bool {
+	if s.flags == nil {
+		return true
+	}
+	chat := update.FromChat()
+	if chat == nil || chat.IsPrivate() {
+		return true
+	}
+	return s.flags.IsEnabled(featureflags.FlagGroupMode, userID)
+}
+
+// provisionUser records the sender's profile; failures do not block the update
+func (s *chatbotService) provisionUser(update *tgbotapi.Update, userID common.UserID, correlationID string) {
+	if s.users == nil {
+		return
+	}
+
+	sender, err := s.parser.GetSender(update)
+	if err != nil {
+		return
+	}
+
+	profile := &user.User{
+		ID:         userID,
+		Bot:        s.config.Name,
+		TelegramID: sender.ID,
+		Username:   sender.UserName,
+		FirstName:  sender.FirstName,
+		LastName:   sender.LastName,
+	}
+	if err := s.users.Provision(profile); err != nil {
+		s.logger.Warn("Failed to provision user",
+			zap.String("correlation_id", correlationID),
+			zap.String("user_id", string(userID)),
+			zap.Error(err))
+	}
+}
+
+// publishActivity announces that the user just interacted with the bot in the chat
+func (s *chatbotService) publishActivity(userID common.UserID, chatID common.ChatID, correlationID string) {
+	activity := events.UserActivity{
+		Event:  events.NewEvent(),
+		UserID: string(userID),
+		ChatID: string(chatID),
+	}
+	if err := s.eventBus.Publish(events.TopicUserActivity, activity); err != nil {
+		s.logger.Warn("Failed to publish UserActivity event",
+			zap.String("correlation_id", correlationID),
+			zap.Error(err))
+	}
+}
+
+// handleUpdateReceived queues a webhook update for the update consumer,
+// ignoring updates received by other bots
+func (s *chatbotService) handleUpdateReceived(event events.UpdateReceived) {
+	if event.Bot != s.config.Name {
+		return
+	}
+	s.updates.Enqueue(event)
+}
+
+// ownsUser reports whether events about the user are delivered by this bot.
+// Synthetic probe users have no chat, so no bot owns them.
+func (s *chatbotService) ownsUser(userID string) bool {
+	if probe.IsProbeUser(userID) {
+		return false
+	}
+	if s.directory == nil {
+		return true
+	}
+
+	bot, err := s.directory.BotFor(common.UserID(userID))
+	if err != nil {
+		// Fall back to the default bot so the event is delivered once
+		s.logger.Warn("Failed to look up the user's bot",
+			zap.String("user_id", userID),
+			zap.Error(err))
+		return s.config.Name == ""
+	}
+	return bot == s.config.Name
+}
+
+// publishPolledUpdate feeds a polled update into the same pipeline as webhook updates
+func (s *chatbotService) publishPolledUpdate(update RawUpdate) {
+	event := events.UpdateReceived{
+		Event:    events.NewEvent(),
+		UpdateID: update.UpdateID,
+		Bot:      s.config.Name,
+		Payload:  update.Payload,
+	}
+	if err := s.eventBus.Publish(events.TopicUpdateReceived, event); err != nil {
+		s.logger.Error("Failed to queue polled update, processing inline",
+			zap.Int("update_id", update.UpdateID),
+			zap.Error(err))
+		s.processUpdate(event)
+	}
+}
+
+// processUpdate parses and handles one queued webhook update
+func (s *chatbotService) processUpdate(event events.UpdateReceived) {
+	if err := s.HandleWebhook(event.Payload); err != nil {
+		s.logger.Error("Failed to process queued update",
+			zap.String("correlation_id", event.CorrelationID),
+			zap.Int("update_id", event.UpdateID),
+			zap.Error(err))
+	}
+}
+
 // handleCommand processes bot commands
 func (s *chatbotService) handleCommand(update *tgbotapi.Update, userID, chatID, correlationID string) error {
 	command, err := s.parser.ExtractCommand(update.Message)
@@ -207,28 +789,83 @@ func (s *chatbotService) handleCommand(update *tgbotapi.Update, userID, chatID,
 		zap.String("user_id", userID),
 		zap.String("chat_id", chatID))
 
-	// Parse command arguments
-	args := strings.Fields(update.Message.Text)
-	if len(args) > 1 {
-		args = args[1:] // Remove command itself
-	} else {
-		args = []string{}
+	// Restricted commands are refused before their arguments are read
+	if !s.authorizer.Allowed(command, senderTelegramID(update.Message)) {
+		s.logger.Warn("Refused restricted command",
+			zap.String("correlation_id", correlationID),
+			zap.String("command", string(command)),
+			zap.String("user_id", userID))
+		return s.SendMessage(common.ChatID(chatID), fmt.Sprintf("⛔ You are not allowed to use %s.", command))
+	}
+
+	// Parse command arguments, keeping quoted titles together
+	args, err := splitArgs(update.Message.CommandArguments())
+	if err != nil {
+		return s.SendMessage(common.ChatID(chatID), err.Error())
 	}
 
 	var response string
 
 	switch command {
 	case CommandStart:
+		if len(args) > 0 && strings.HasPrefix(args[0], InviteStartPrefix) {
+			response, err = s.commandProcessor.ProcessJoinCommand(userID, chatID, strings.TrimPrefix(args[0], InviteStartPrefix))
+			break
+		}
+		if len(args) > 0 && strings.HasPrefix(args[0], ListInviteStartPrefix) {
+			response, err = s.commandProcessor.ProcessJoinListCommand(userID, chatID, strings.TrimPrefix(args[0], ListInviteStartPrefix))
+			break
+		}
+		if len(args) > 0 && strings.HasPrefix(args[0], TaskStartPrefix) {
+			response, err = s.commandProcessor.ProcessOpenTaskCommand(userID, chatID, strings.TrimPrefix(args[0], TaskStartPrefix))
+			break
+		}
 		response, err = s.commandProcessor.ProcessStartCommand(userID, chatID)
 	case CommandHelp:
 		response, err = s.commandProcessor.ProcessHelpCommand(userID, chatID)
 	case CommandList:
-		err = s.commandProcessor.ProcessListCommand(userID, chatID)
+		err = s.processListCommand(userID, chatID)
 		return err // Response will be sent via event
+	case CommandTask:
+		response, err = s.commandProcessor.ProcessTaskCommand(userID, chatID, args)
 	case CommandDone:
 		response, err = s.commandProcessor.ProcessDoneCommand(userID, chatID, args)
 	case CommandDelete:
 		response, err = s.commandProcessor.ProcessDeleteCommand(userID, chatID, args)
+	case CommandSnooze:
+		response, err = s.commandProcessor.ProcessSnoozeCommand(userID, chatID, args)
+	case CommandTestReminder:
+		response, err = s.commandProcessor.ProcessTestReminderCommand(userID, chatID, args)
+	case CommandInvite:
+		response, err = s.commandProcessor.ProcessInviteCommand(userID, chatID, args)
+	case CommandField:
+		response, err = s.commandProcessor.ProcessFieldCommand(userID, chatID, args)
+	case CommandSnoozeAll, CommandMoveTo:
+		response, err = s.commandProcessor.ProcessRescheduleCommand(userID, chatID, command, args)
+	case CommandAPIToken:
+		response, err = s.commandProcessor.ProcessAPITokenCommand(userID, chatID, args)
+	case CommandStats:
+		response, err = s.commandProcessor.ProcessStatsCommand(userID, chatID)
+	case CommandNewList:
+		response, err = s.commandProcessor.ProcessNewListCommand(userID, chatID, args)
+	case CommandLists:
+		response, err = s.commandProcessor.ProcessListsCommand(userID, chatID)
+	case CommandAddTo:
+		response, err = s.commandProcessor.ProcessAddToCommand(userID, chatID, update.Message.CommandArguments(), update.Message.From.LanguageCode)
+	case CommandDelegate:
+		response, err = s.commandProcessor.ProcessDelegateCommand(userID, chatID, s.config.Name, args)
+	case CommandRetention:
+		response, err = s.commandProcessor.ProcessRetentionCommand(userID, chatID, args)
+	case CommandSettings:
+		response, err = s.commandProcessor.ProcessSettingsCommand(userID, chatID, args)
+	case CommandBroadcast:
+		response, err = s.commandProcessor.ProcessBroadcastCommand(userID, chatID, s.config.Name, update.Message.CommandArguments())
+	case CommandGlobalStats:
+		response, err = s.commandProcessor.ProcessGlobalStatsCommand(userID, chatID)
+	case CommandMaintenance:
+		response, err = s.processMaintenanceCommand(userID, chatID, update.Message.CommandArguments())
+	case CommandPlan:
+		return s.processPlanCommand(userID, chatID) // The plan is written into its own message
 	default:
 		response = "Unknown command. Type /help for available commands."
 	}
@@ -264,15 +901,56 @@ func (s *chatbotService) handleTextMessage(update *tgbotapi.Update, userID, chat
 		zap.String("chat_id", chatID),
 		zap.Int("text_length", len(message.Text)))
 
-	// Publish MessageReceived event for task parsing
+	// Run content moderation before the text reaches the LLM
+	verdict := s.moderation.Evaluate(context.Background(), message.Text)
+	if !verdict.Allowed {
+		s.logger.Info("Text message rejected by content moderation",
+			zap.String("correlation_id", correlationID),
+			zap.String("user_id", userID),
+			zap.Strings("categories", verdict.Result.Categories))
+		return s.SendMessage(common.ChatID(chatID), "Sorry, I can't accept that message. Please rephrase your task.")
+	}
+
+	// A reply to the "edit title" button updates the pending draft instead of creating a task
+	if handled, err := s.handleDraftTitleEdit(userID, chatID, message.Text); handled {
+		return err
+	}
+
+	// Parsing is slow while the service is overloaded, so say so up front
+	s.notifyIfBusy(chatID)
+
+	// Queue the message; consecutive messages are batched into one parse request
+	s.aggregator.Add(userID, chatID, correlationID, message.Locale, message.Text)
+	return nil
+}
+
+// publishMessageBatch publishes a MessageReceived event for a batch of messages
+func (s *chatbotService) publishMessageBatch(batch MessageBatch) {
 	messageEvent := events.MessageReceived{
 		Event:       events.NewEvent(),
-		UserID:      userID,
-		ChatID:      chatID,
-		MessageText: message.Text,
+		UserID:      batch.UserID,
+		ChatID:      batch.ChatID,
+		MessageText: strings.Join(batch.Messages, "\n"),
+		Preview:     s.config.TaskPreview,
+		Locale:      batch.Locale,
+		ThreadID:    s.threads.Get(batch.ChatID),
+	}
+	if len(batch.Messages) > 1 {
+		messageEvent.Messages = batch.Messages
+		s.logger.Info("Publishing batched messages",
+			zap.String("correlation_id", batch.CorrelationID),
+			zap.String("user_id", batch.UserID),
+			zap.Int("message_count", len(batch.Messages)))
 	}
 
-	return s.eventBus.Publish(events.TopicMessageReceived, messageEvent)
+	// Parsing can take seconds; the chat shows "typing..." until it is done
+	s.typing.Start(batch.ChatID)
+	if err := s.eventBus.Publish(events.TopicMessageReceived, messageEvent); err != nil {
+		s.typing.Stop(batch.ChatID)
+		s.logger.Error("Failed to publish MessageReceived event",
+			zap.String("correlation_id", batch.CorrelationID),
+			zap.Error(err))
+	}
 }
 
 // handleCallbackQuery processes inline keyboard button presses
@@ -291,6 +969,38 @@ func (s *chatbotService) handleCallbackQuery(update *tgbotapi.Update, userID, ch
 		zap.String("chat_id", chatID),
 		zap.String("action", callbackData.Action))
 
+	if isDraftAction(callbackData.Action) {
+		return s.handleDraftCallback(callbackData, userID, chatID, callbackMessageID(update))
+	}
+	if isRescheduleAction(callbackData.Action) {
+		return s.handleRescheduleCallback(callbackData, userID, chatID)
+	}
+	if callbackData.Action == CallbackActionPlainTask {
+		return s.handlePlainTaskCallback(callbackData, userID, chatID)
+	}
+	if isDelegationAction(callbackData.Action) {
+		return s.handleDelegationCallback(callbackData, userID, chatID)
+	}
+	if callbackData.Action == CallbackActionCountdown {
+		return s.handleCountdownCallback(callbackData, userID, chatID)
+	}
+	if isPlanAction(callbackData.Action) {
+		return s.handlePlanCallback(callbackData, userID, chatID)
+	}
+	if isSettingsAction(callbackData.Action) {
+		return s.handleSettingsCallback(callbackData, userID, chatID, callbackMessageID(update))
+	}
+
+	switch callbackData.Action {
+	case CallbackActionPrevPage, CallbackActionNextPage:
+		return s.handleListPageCallback(callbackData, userID, chatID, callbackMessageID(update))
+	case CallbackActionViewTask:
+		return s.handleViewTaskCallback(callbackData, userID, chatID)
+	case CallbackActionNoop:
+		// Page indicator buttons carry no action
+		return nil
+	}
+
 	response, err := s.commandProcessor.HandleCallbackQuery(callbackData, userID, chatID)
 	if err != nil {
 		s.logger.Error("Callback query processing failed",
@@ -322,11 +1032,13 @@ func (s *chatbotService) ProcessCommand(command Command, userID common.UserID, c
 	case CommandHelp:
 		response, err = s.commandProcessor.ProcessHelpCommand(string(userID), string(chatID))
 	case CommandList:
-		return s.commandProcessor.ProcessListCommand(string(userID), string(chatID))
+		return s.processListCommand(string(userID), string(chatID))
 	case CommandDone:
 		response, err = s.commandProcessor.ProcessDoneCommand(string(userID), string(chatID), []string{})
 	case CommandDelete:
 		response, err = s.commandProcessor.ProcessDeleteCommand(string(userID), string(chatID), []string{})
+	case CommandTestReminder:
+		response, err = s.commandProcessor.ProcessTestReminderCommand(string(userID), string(chatID), []string{})
 	default:
 		response = "Unknown command. Type /help for available commands."
 	}
@@ -341,59 +1053,132 @@ func (s *chatbotService) ProcessCommand(command Command, userID common.UserID, c
 
 // handleTaskParsed handles TaskParsed events from the LLM service
 func (s *chatbotService) handleTaskParsed(event events.TaskParsed) {
-	s.logger.Info("Handling TaskParsed event",
-		zap.String("correlation_id", event.CorrelationID),
-		zap.String("user_id", event.UserID),
+	if !s.ownsUser(event.UserID) {
+		return
+	}
+	s.typing.Stop(event.ChatID)
+
+	log := common.FlowLogger(s.logger, common.LogFlow{
+		UserID:        event.UserID,
+		ChatID:        event.ChatID,
+		CorrelationID: event.CorrelationID,
+	})
+
+	log.Info("Handling TaskParsed event",
 		zap.String("task_title", event.ParsedTask.Title))
 
-	// TaskCreated events will handle the confirmation message with proper task ID
-	// This handler is kept for future processing needs or logging
-	s.logger.Debug("Task parsing completed, waiting for TaskCreated event for confirmation")
+	if !event.Preview {
+		// TaskCreated events will handle the confirmation message with proper task ID
+		log.Debug("Task parsing completed, waiting for TaskCreated event for confirmation")
+		return
+	}
+
+	// Several tasks from one message are saved together without a per-task preview
+	if len(event.AllTasks()) > 1 {
+		event.Event = events.NewEvent()
+		event.Preview = false
+		if err := s.eventBus.Publish(events.TopicTaskParsed, event); err != nil {
+			log.Error("Failed to publish TaskParsed event for multiple tasks",
+				zap.Error(err))
+		}
+		return
+	}
+
+	s.startTaskDraft(event)
 }
 
 // handleReminderDue handles ReminderDue events from the nudge service
 func (s *chatbotService) handleReminderDue(event events.ReminderDue) {
-	s.logger.Info("Handling ReminderDue event",
-		zap.String("correlation_id", event.CorrelationID),
-		zap.String("task_id", event.TaskID),
-		zap.String("user_id", event.UserID),
-		zap.String("chat_id", event.ChatID))
+	// Grouped reminders are sent together by handleReminderGroupDue, and
+	// reminders routed elsewhere by the channel router
+	if !s.ownsUser(event.UserID) || event.GroupID != "" || !event.SendsTo(events.ReminderChannelTelegram) {
+		return
+	}
 
-	// Create reminder message with task action keyboard
-	reminderText := fmt.Sprintf("⏰ <b>Task Reminder!</b>\n\nYou have a task that needs attention.\n\nTask ID: %s", event.TaskID)
+	log := common.FlowLogger(s.logger, common.LogFlow{
+		UserID:        event.UserID,
+		ChatID:        event.ChatID,
+		CorrelationID: event.CorrelationID,
+	})
 
-	// Create action keyboard for the task
-	keyboard := s.keyboardBuilder.BuildTaskActionKeyboard(event.TaskID)
+	log.Info("Handling ReminderDue event",
+		zap.String("task_id", event.TaskID))
 
-	// Convert to domain keyboard format
-	domainKeyboard := InlineKeyboard{
-		Buttons: make([][]InlineKeyboardButton, len(keyboard.InlineKeyboard)),
+	// The message is tracked so reacting to it with 👍 or 💤 acts on the task
+	chatIDInt, err := s.telegramChatID(event.ChatID)
+	if err != nil {
+		log.Error("Failed to send reminder",
+			zap.Error(err))
+		return
+	}
+	dates := s.dateFormat(event.UserID)
+	reminderText, markup := s.renderReminder(event, dates)
+	messageID, err := s.provider.SendTrackedMessage(chatIDInt, event.ThreadID, reminderText, markup)
+	if err != nil {
+		log.Error("Failed to send reminder",
+			zap.Error(err))
+		return
 	}
+	s.reminderMessages.TrackReminder(event.ChatID, messageID, event)
 
-	for i, row := range keyboard.InlineKeyboard {
-		domainKeyboard.Buttons[i] = make([]InlineKeyboardButton, len(row))
-		for j, button := range row {
-			domainKeyboard.Buttons[i][j] = InlineKeyboardButton{
-				Text:         button.Text,
-				CallbackData: *button.CallbackData,
-			}
+	// Earlier nudges for the task would still say how far off it was back then
+	for _, earlier := range s.reminderMessages.Earlier(event.ChatID, event.TaskID, messageID) {
+		if earlier.Reminder.DueDate == nil {
+			continue
 		}
+		text, markup := s.renderReminder(earlier.Reminder, dates)
+		if err := s.provider.EditMessageWithKeyboard(chatIDInt, earlier.MessageID, text, markup); err != nil {
+			log.Debug("Failed to refresh earlier reminder",
+				zap.Int("message_id", earlier.MessageID),
+				zap.Error(err))
+		}
+	}
+}
+
+// renderReminder returns the text and action keyboard of a reminder, saying
+// how long until the task is due as of dates' now
+func (s *chatbotService) renderReminder(event events.ReminderDue, dates DateFormat) (string, tgbotapi.InlineKeyboardMarkup) {
+	reminderText := formatReminder(event, dates)
+
+	// Action keyboard for the task, with a calendar link for timed tasks
+	calendarURL := calendarLink(event.Title, event.DueDate)
+	markup := s.keyboardBuilder.BuildReminderKeyboard(event.TaskID, calendarURL)
+	if !event.Test && offersCountdown(event.DueDate, time.Now()) {
+		markup = s.keyboardBuilder.AddCountdownButton(markup, event.TaskID)
 	}
+	return reminderText, markup
+}
 
-	err := s.SendMessageWithKeyboard(common.ChatID(event.ChatID), reminderText, domainKeyboard)
-	if err != nil {
-		s.logger.Error("Failed to send reminder",
-			zap.String("correlation_id", event.CorrelationID),
-			zap.Error(err))
+// formatReminder is the text of a reminder
+func formatReminder(event events.ReminderDue, dates DateFormat) string {
+	reminderText := fmt.Sprintf("⏰ <b>Task Reminder!</b>\n\nYou have a task that needs attention.\n\nTask ID: %s", event.TaskID)
+	if event.MessageTemplate != "" {
+		// Experiment variants supply their own wording
+		reminderText = strings.ReplaceAll(event.MessageTemplate, "{task_id}", event.TaskID)
+	}
+	if event.DueDate != nil {
+		dueIn := dates.DueIn(*event.DueDate)
+		reminderText += fmt.Sprintf("\n\n⏳ %s%s (%s)", strings.ToUpper(dueIn[:1]), dueIn[1:], dates.DateTime(*event.DueDate))
+	}
+	if event.Test {
+		reminderText = "🧪 <i>Test reminder - your reminder schedule is unchanged.</i>\n\n" + reminderText
 	}
+	return reminderText
 }
 
 // handleTaskListResponse handles TaskListResponse events from the nudge service
 func (s *chatbotService) handleTaskListResponse(event events.TaskListResponse) {
-	s.logger.Info("Handling TaskListResponse event",
-		zap.String("correlation_id", event.CorrelationID),
-		zap.String("user_id", event.UserID),
-		zap.String("chat_id", event.ChatID),
+	if !s.ownsUser(event.UserID) {
+		return
+	}
+
+	log := common.FlowLogger(s.logger, common.LogFlow{
+		UserID:        event.UserID,
+		ChatID:        event.ChatID,
+		CorrelationID: event.CorrelationID,
+	})
+
+	log.Info("Handling TaskListResponse event",
 		zap.Int("task_count", len(event.Tasks)),
 		zap.Bool("success", event.Success))
 
@@ -401,7 +1186,7 @@ func (s *chatbotService) handleTaskListResponse(event events.TaskListResponse) {
 
 	// Handle error responses
 	if !event.Success {
-		s.logger.Warn("Received error in TaskListResponse",
+		log.Warn("Received error in TaskListResponse",
 			zap.String("error_code", event.ErrorCode),
 			zap.String("error_message", event.ErrorMsg))
 
@@ -410,98 +1195,33 @@ func (s *chatbotService) handleTaskListResponse(event events.TaskListResponse) {
 		// Send error message to user
 		err := s.SendMessage(common.ChatID(event.ChatID), messageText)
 		if err != nil {
-			s.logger.Error("Failed to send task list error message",
-				zap.String("correlation_id", event.CorrelationID),
+			log.Error("Failed to send task list error message",
 				zap.Error(err))
 		}
 		return
 	}
 
-	// Handle successful responses
-	if len(event.Tasks) == 0 {
-		messageText = "📝 <b>Your Task List</b>\n\nYou have no active tasks. Great job! 🎉\n\nSend me a message to create a new task."
-	} else {
-		messageText = fmt.Sprintf("📝 <b>Your Task List</b>\n\nYou have %d active task(s):\n\n", len(event.Tasks))
-
-		for i, task := range event.Tasks {
-			taskNumber := i + 1
-			priority := strings.ToUpper(string(task.Priority[:1])) + strings.ToLower(string(task.Priority[1:]))
-
-			// Format task entry
-			taskEntry := fmt.Sprintf("<b>%d.</b> %s\n   🏷 <i>%s Priority</i>", taskNumber, task.Title, priority)
-
-			if task.Description != "" {
-				taskEntry += fmt.Sprintf("\n   📝 %s", task.Description)
-			}
-
-			if task.DueDate != nil {
-				dueText := task.DueDate.Format("Jan 2, 15:04")
-				if task.IsOverdue {
-					taskEntry += fmt.Sprintf("\n   ⏰ <b>OVERDUE:</b> %s", dueText)
-				} else {
-					taskEntry += fmt.Sprintf("\n   📅 Due: %s", dueText)
-				}
-			}
-
-			messageText += taskEntry + "\n\n"
-		}
-
-		// Convert event tasks to keyboard task format
-		keyboardTasks := make([]TaskSummary, len(event.Tasks))
-		for i, task := range event.Tasks {
-			keyboardTasks[i] = TaskSummary{
-				ID:      common.TaskID(task.ID),
-				Title:   task.Title,
-				DueDate: task.DueDate,
-				Status:  task.Status,
-			}
-		}
-
-		// Create task list keyboard with actions for each task (simple pagination for now)
-		currentPage := 0
-		totalPages := 1
-		keyboard := s.keyboardBuilder.BuildTaskListKeyboard(keyboardTasks, currentPage, totalPages)
-
-		// Convert to domain keyboard format
-		domainKeyboard := InlineKeyboard{
-			Buttons: make([][]InlineKeyboardButton, len(keyboard.InlineKeyboard)),
-		}
-
-		for i, row := range keyboard.InlineKeyboard {
-			domainKeyboard.Buttons[i] = make([]InlineKeyboardButton, len(row))
-			for j, button := range row {
-				domainKeyboard.Buttons[i][j] = InlineKeyboardButton{
-					Text:         button.Text,
-					CallbackData: *button.CallbackData,
-				}
-			}
-		}
-
-		// Send message with keyboard
-		err := s.SendMessageWithKeyboard(common.ChatID(event.ChatID), messageText, domainKeyboard)
-		if err != nil {
-			s.logger.Error("Failed to send task list with keyboard",
-				zap.String("correlation_id", event.CorrelationID),
-				zap.Error(err))
-		}
-		return
-	}
-
-	// Send simple message without keyboard for empty list
-	err := s.SendMessage(common.ChatID(event.ChatID), messageText)
-	if err != nil {
-		s.logger.Error("Failed to send task list message",
-			zap.String("correlation_id", event.CorrelationID),
+	// Handle successful responses, refreshing the chat's list message in place
+	s.commandProcessor.RememberTaskList(event.UserID, event.Tasks)
+	if err := s.showTaskList(event.UserID, event.ChatID, event.Tasks, event.Page, event.MessageID); err != nil {
+		log.Error("Failed to send task list message",
 			zap.Error(err))
 	}
 }
 
 // handleTaskActionResponse handles TaskActionResponse events from the nudge service
 func (s *chatbotService) handleTaskActionResponse(event events.TaskActionResponse) {
-	s.logger.Info("Handling TaskActionResponse event",
-		zap.String("correlation_id", event.CorrelationID),
-		zap.String("user_id", event.UserID),
-		zap.String("chat_id", event.ChatID),
+	if !s.ownsUser(event.UserID) {
+		return
+	}
+
+	log := common.FlowLogger(s.logger, common.LogFlow{
+		UserID:        event.UserID,
+		ChatID:        event.ChatID,
+		CorrelationID: event.CorrelationID,
+	})
+
+	log.Info("Handling TaskActionResponse event",
 		zap.String("task_id", event.TaskID),
 		zap.String("action", event.Action),
 		zap.Bool("success", event.Success))
@@ -521,6 +1241,26 @@ func (s *chatbotService) handleTaskActionResponse(event events.TaskActionRespons
 		case "snooze":
 			emoji = "😴"
 			messageText = fmt.Sprintf("%s <b>Task Snoozed!</b>\n\n%s", emoji, event.Message)
+		case "start":
+			emoji = "▶️"
+			messageText = fmt.Sprintf("%s <b>Task In Progress</b>\n\n%s", emoji, event.Message)
+		case "wait":
+			emoji = "⏳"
+			messageText = fmt.Sprintf("%s <b>Task Waiting</b>\n\n%s", emoji, event.Message)
+		case "mute":
+			emoji = "🔕"
+			messageText = fmt.Sprintf("%s <b>Task Muted</b>\n\n%s", emoji, event.Message)
+			s.listMessages.UpdateTask(event.ChatID, event.TaskID, func(task *events.TaskSummary) { task.Muted = true })
+		case "unmute":
+			emoji = "🔔"
+			messageText = fmt.Sprintf("%s <b>Task Unmuted</b>\n\n%s", emoji, event.Message)
+			s.listMessages.UpdateTask(event.ChatID, event.TaskID, func(task *events.TaskSummary) { task.Muted = false })
+		case "log_habit":
+			emoji = "🔥"
+			messageText = fmt.Sprintf("%s <b>Habit Logged</b>\n\n%s", emoji, event.Message)
+		case "field":
+			emoji = "🏷"
+			messageText = fmt.Sprintf("%s <b>Task Updated</b>\n\n%s", emoji, html.EscapeString(event.Message))
 		default:
 			emoji = "✅"
 			messageText = fmt.Sprintf("%s <b>Action Completed!</b>\n\n%s", emoji, event.Message)
@@ -532,56 +1272,117 @@ func (s *chatbotService) handleTaskActionResponse(event events.TaskActionRespons
 
 	err := s.SendMessage(common.ChatID(event.ChatID), messageText)
 	if err != nil {
-		s.logger.Error("Failed to send task action response",
-			zap.String("correlation_id", event.CorrelationID),
+		log.Error("Failed to send task action response",
 			zap.Error(err))
 	}
 }
 
 // handleTaskCreated handles TaskCreated events from the nudge service
 func (s *chatbotService) handleTaskCreated(event events.TaskCreated) {
-	s.logger.Info("Handling TaskCreated event",
-		zap.String("correlation_id", event.CorrelationID),
+	if !s.ownsUser(event.UserID) {
+		return
+	}
+
+	log := common.FlowLogger(s.logger, common.LogFlow{
+		UserID:        event.UserID,
+		CorrelationID: event.CorrelationID,
+	})
+
+	log.Info("Handling TaskCreated event",
 		zap.String("task_id", event.TaskID),
-		zap.String("user_id", event.UserID),
 		zap.String("task_title", event.Title))
 
-	// Create confirmation message with task details
+	// Tasks created in bulk are confirmed together by the TasksCreated event
+	if event.BatchID != "" {
+		return
+	}
+
+	confirmText := formatTaskCreated(event, s.dateFormat(event.UserID))
+	s.commandProcessor.RememberTask(event.UserID, events.TaskSummary{ID: event.TaskID, Code: event.Code, Title: event.Title})
+
+	// Create action keyboard for immediate task actions, with a calendar link for timed tasks
+	calendarURL := calendarLink(event.Title, event.DueDate)
+	markup := s.keyboardBuilder.BuildTaskActionKeyboardWithCalendar(event.TaskID, calendarURL)
+	if offersCountdown(event.DueDate, time.Now()) {
+		markup = s.keyboardBuilder.AddCountdownButton(markup, event.TaskID)
+	}
+	if event.HasOriginal {
+		markup = s.keyboardBuilder.AddOriginalButton(markup, event.TaskID)
+	}
+	domainKeyboard := s.keyboardBuilder.ToDomainKeyboard(markup)
+
+	// Determine chat ID from user ID (for now they're the same in Telegram)
+	chatID := event.UserID
+
+	err := s.SendMessageWithKeyboard(common.ChatID(chatID), confirmText, domainKeyboard)
+	if err != nil {
+		log.Error("Failed to send task creation confirmation",
+			zap.Error(err))
+	}
+}
+
+// formatTaskCreated is the confirmation of a task created from a message
+func formatTaskCreated(event events.TaskCreated, dates DateFormat) string {
 	confirmText := fmt.Sprintf("📋 <b>Task Created!</b>\n\n<b>Title:</b> %s\n<b>Priority:</b> %s",
 		event.Title,
 		event.Priority)
+	if event.Code != "" {
+		confirmText += fmt.Sprintf("\n<b>Code:</b> <code>%s</code>", event.Code)
+	}
 
 	if event.DueDate != nil {
-		confirmText += fmt.Sprintf("\n<b>Due:</b> %s", event.DueDate.Format("Jan 2, 2006 at 3:04 PM"))
+		confirmText += fmt.Sprintf("\n<b>Due:</b> %s", dates.DateTime(*event.DueDate))
 	}
 
-	confirmText += fmt.Sprintf("\n<b>Created:</b> %s", event.CreatedAt.Format("Jan 2, 15:04"))
-
-	// Create action keyboard for immediate task actions
-	keyboard := s.keyboardBuilder.BuildTaskActionKeyboard(event.TaskID)
+	confirmText += fmt.Sprintf("\n<b>Created:</b> %s", dates.DateTime(event.CreatedAt))
+	if event.Summary != "" {
+		confirmText += fmt.Sprintf("\n\n📝 %s", html.EscapeString(event.Summary))
+	}
+	if event.Estimate != nil && event.Estimate.Late {
+		confirmText += "\n\n" + formatLateEstimate(*event.Estimate, dates)
+	}
+	if len(event.Conflicts) > 0 {
+		confirmText += "\n\n" + formatNewTaskConflicts(event.Conflicts, dates)
+	}
+	return confirmText
+}
 
-	// Convert to domain keyboard format
-	domainKeyboard := InlineKeyboard{
-		Buttons: make([][]InlineKeyboardButton, len(keyboard.InlineKeyboard)),
+// handleTasksCreated sends one confirmation for several tasks created from a single message
+func (s *chatbotService) handleTasksCreated(event events.TasksCreated) {
+	if !s.ownsUser(event.UserID) {
+		return
 	}
 
-	for i, row := range keyboard.InlineKeyboard {
-		domainKeyboard.Buttons[i] = make([]InlineKeyboardButton, len(row))
-		for j, button := range row {
-			domainKeyboard.Buttons[i][j] = InlineKeyboardButton{
-				Text:         button.Text,
-				CallbackData: *button.CallbackData,
-			}
+	log := common.FlowLogger(s.logger, common.LogFlow{
+		UserID:        event.UserID,
+		ChatID:        event.ChatID,
+		CorrelationID: event.CorrelationID,
+	})
+
+	log.Info("Handling TasksCreated event",
+		zap.Int("task_count", len(event.Tasks)))
+
+	var confirmText strings.Builder
+	confirmText.WriteString(fmt.Sprintf("📋 <b>%d Tasks Created!</b>\n", len(event.Tasks)))
+
+	s.commandProcessor.RememberTasks(event.UserID, event.Tasks)
+	dates := s.dateFormat(event.UserID)
+	for i, task := range event.Tasks {
+		confirmText.WriteString(fmt.Sprintf("\n%d. %s<b>%s</b> (%s)", i+1, formatTaskCode(task.Code), task.Title, task.Priority))
+		if task.DueDate != nil {
+			confirmText.WriteString(fmt.Sprintf("\n   Due: %s", dates.DateTime(*task.DueDate)))
 		}
 	}
 
-	// Determine chat ID from user ID (for now they're the same in Telegram)
-	chatID := event.UserID
+	keyboard := s.keyboardBuilder.ToDomainKeyboard(s.keyboardBuilder.BuildMainMenuKeyboard())
 
-	err := s.SendMessageWithKeyboard(common.ChatID(chatID), confirmText, domainKeyboard)
-	if err != nil {
-		s.logger.Error("Failed to send task creation confirmation",
-			zap.String("correlation_id", event.CorrelationID),
+	chatID := event.ChatID
+	if chatID == "" {
+		chatID = event.UserID
+	}
+
+	if err := s.SendMessageWithKeyboard(common.ChatID(chatID), confirmText.String(), keyboard); err != nil {
+		log.Error("Failed to send tasks creation confirmation",
 			zap.Error(err))
 	}
 }
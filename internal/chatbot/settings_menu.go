@@ -0,0 +1,285 @@
+package chatbot
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// settingsUsage explains the /settings command
+const settingsUsage = "Usage: /settings, or /settings timezone Asia/Kolkata to type a value the menu doesn't offer."
+
+// settingOption is one choice offered for a setting, with the value sent to
+// the nudge service
+type settingOption struct {
+	Label string
+	Value string
+}
+
+// settingPage is a setting of the /settings menu and the options it offers
+type settingPage struct {
+	Field   string
+	Emoji   string
+	Title   string
+	Options []settingOption
+}
+
+// settingPages are the settings of the /settings menu, in the order shown
+var settingPages = []settingPage{
+	{Field: events.SettingInterval, Emoji: "⏱", Title: "Reminder interval", Options: []settingOption{
+		{"15 minutes", "15m"}, {"30 minutes", "30m"}, {"1 hour", "1h"}, {"2 hours", "2h"},
+		{"4 hours", "4h"}, {"8 hours", "8h"}, {"1 day", "24h"},
+	}},
+	{Field: events.SettingMaxNudges, Emoji: "🔁", Title: "Reminders per task", Options: []settingOption{
+		{"1", "1"}, {"2", "2"}, {"3", "3"}, {"5", "5"}, {"10", "10"},
+	}},
+	{Field: events.SettingQuietHours, Emoji: "🌙", Title: "Quiet hours", Options: []settingOption{
+		{"Off", events.SettingOff}, {"21:00–06:00", "21:00-06:00"}, {"22:00–07:00", "22:00-07:00"},
+		{"23:00–07:00", "23:00-07:00"}, {"23:00–08:00", "23:00-08:00"},
+	}},
+	{Field: events.SettingTimezone, Emoji: "🌍", Title: "Timezone", Options: []settingOption{
+		{"UTC", "UTC"}, {"London", "Europe/London"}, {"Berlin", "Europe/Berlin"},
+		{"New York", "America/New_York"}, {"Chicago", "America/Chicago"}, {"Los Angeles", "America/Los_Angeles"},
+		{"Ho Chi Minh City", "Asia/Ho_Chi_Minh"}, {"Singapore", "Asia/Singapore"}, {"Tokyo", "Asia/Tokyo"},
+		{"Sydney", "Australia/Sydney"},
+	}},
+	{Field: events.SettingLanguage, Emoji: "🗣", Title: "Language", Options: []settingOption{
+		{"Same as Telegram", events.SettingOff}, {"English", "en"}, {"Tiếng Việt", "vi"}, {"Español", "es"},
+		{"Português", "pt-br"}, {"Deutsch", "de"}, {"Français", "fr"},
+	}},
+	{Field: events.SettingWeekStart, Emoji: "📆", Title: "Week starts on", Options: []settingOption{
+		{"Monday", "monday"}, {"Sunday", "sunday"}, {"Saturday", "saturday"},
+	}},
+	{Field: events.SettingWorkingDays, Emoji: "💼", Title: "Working days", Options: []settingOption{
+		{"Mon–Fri", "mon,tue,wed,thu,fri"}, {"Sun–Thu", "sun,mon,tue,wed,thu"},
+		{"Mon–Sat", "mon,tue,wed,thu,fri,sat"}, {"Every day", "sun,mon,tue,wed,thu,fri,sat"},
+	}},
+	{Field: events.SettingCountry, Emoji: "🎉", Title: "Public holidays", Options: []settingOption{
+		{"Off", events.SettingOff}, {"United States", "US"}, {"United Kingdom", "GB"},
+		{"Germany", "DE"}, {"France", "FR"},
+	}},
+}
+
+// findSettingPage returns the menu page of a setting
+func findSettingPage(field string) (settingPage, bool) {
+	for _, page := range settingPages {
+		if page.Field == field {
+			return page, true
+		}
+	}
+	return settingPage{}, false
+}
+
+// settingValue returns a setting's current value in the form its options use
+func settingValue(field string, settings events.UserSettings) string {
+	switch field {
+	case events.SettingInterval:
+		return settings.NudgeInterval.String()
+	case events.SettingMaxNudges:
+		return strconv.Itoa(settings.MaxNudges)
+	case events.SettingQuietHours:
+		if settings.QuietHoursStart == "" {
+			return events.SettingOff
+		}
+		return settings.QuietHoursStart + "-" + settings.QuietHoursEnd
+	case events.SettingTimezone:
+		if settings.Timezone == "" {
+			return "UTC"
+		}
+		return settings.Timezone
+	case events.SettingLanguage:
+		if settings.Language == "" {
+			return events.SettingOff
+		}
+		return settings.Language
+	case events.SettingWeekStart:
+		return strings.ToLower(common.NewWeek(settings.WeekStart, "").Start.String())
+	case events.SettingWorkingDays:
+		return common.FormatWorkingDays(common.NewWeek("", settings.WorkingDays).WorkingDays)
+	case events.SettingCountry:
+		if settings.Country == "" {
+			return events.SettingOff
+		}
+		return settings.Country
+	}
+	return ""
+}
+
+// isCurrent reports whether an option is the setting's current value
+func (o settingOption) isCurrent(field string, settings events.UserSettings) bool {
+	if field == events.SettingInterval {
+		interval, err := common.ParseDuration(o.Value)
+		return err == nil && interval == settings.NudgeInterval
+	}
+	return o.Value == settingValue(field, settings)
+}
+
+// describe names the setting's current value, by its option's label when the
+// menu offers it
+func (p settingPage) describe(settings events.UserSettings) string {
+	for _, option := range p.Options {
+		if option.isCurrent(p.Field, settings) {
+			return option.Label
+		}
+	}
+	if p.Field == events.SettingWorkingDays {
+		return common.NewWeek(settings.WeekStart, settings.WorkingDays).DescribeWorkingDays()
+	}
+	return settingValue(p.Field, settings)
+}
+
+// formatSettings renders the /settings menu: a summary of every setting, or
+// the options of the one being changed
+func formatSettings(event events.SettingsResponse) string {
+	var status string
+	switch {
+	case !event.Success:
+		status = fmt.Sprintf("❌ %s\n\n", html.EscapeString(event.Message))
+	case event.Changed != "":
+		status = "✅ Saved.\n\n"
+	}
+
+	if page, ok := findSettingPage(event.Page); ok {
+		return fmt.Sprintf("⚙️ <b>Settings › %s</b>\n\n%sCurrently: %s\n\nPick a new value, or send /settings %s followed by your own.",
+			page.Title, status, html.EscapeString(page.describe(event.Settings)), page.Field)
+	}
+
+	var text strings.Builder
+	text.WriteString("⚙️ <b>Settings</b>\n\n")
+	text.WriteString(status)
+	for _, page := range settingPages {
+		text.WriteString(fmt.Sprintf("%s %s: <b>%s</b>\n", page.Emoji, page.Title, html.EscapeString(page.describe(event.Settings))))
+	}
+	text.WriteString("\nTap a setting to change it. Changes are saved straight away.")
+	return text.String()
+}
+
+// isSettingsAction reports whether a callback belongs to the /settings menu
+func isSettingsAction(action string) bool {
+	return action == CallbackActionSettings || action == CallbackActionSettingsSet
+}
+
+// callbackMessageID returns the message whose button was pressed, 0 when
+// Telegram doesn't say
+func callbackMessageID(update *tgbotapi.Update) int {
+	if update.CallbackQuery == nil || update.CallbackQuery.Message == nil {
+		return 0
+	}
+	return update.CallbackQuery.Message.MessageID
+}
+
+// ProcessSettingsCommand handles the /settings command. Without arguments it
+// opens the settings menu; "/settings <setting> <value>" changes a setting to
+// a value the menu doesn't offer.
+func (cp *CommandProcessor) ProcessSettingsCommand(userID, chatID string, args []string) (string, error) {
+	cp.logger.Info("Processing settings command",
+		zap.String("user_id", userID),
+		zap.String("chat_id", chatID))
+
+	settingsEvent := events.SettingsRequested{
+		Event:  events.NewEvent(),
+		UserID: userID,
+		ChatID: chatID,
+	}
+
+	if len(args) > 0 {
+		parsed := newCommandArgs(settingsUsage, args)
+		field, err := parsed.next("setting")
+		if err != nil {
+			return usageReply(err)
+		}
+		field = strings.ToLower(field)
+		if _, ok := findSettingPage(field); !ok {
+			return usageReply(parsed.fail("There is no setting called %q; use interval, max_nudges, quiet_hours, timezone, language, week_start, working_days or country.", field))
+		}
+		value := parsed.rest()
+		if value == "" {
+			return usageReply(parsed.fail("Please add the new %s.", strings.ReplaceAll(field, "_", " ")))
+		}
+		settingsEvent.Field = field
+		settingsEvent.Value = value
+	}
+
+	if err := cp.eventBus.Publish(events.TopicSettingsRequested, settingsEvent); err != nil {
+		return "", err
+	}
+	return "", nil // The menu is sent once the settings are loaded
+}
+
+// handleSettingsCallback opens a page of the settings menu, or saves the
+// option chosen on it, updating the menu in place
+func (s *chatbotService) handleSettingsCallback(callbackData *CallbackData, userID, chatID string, messageID int) error {
+	request := events.SettingsRequested{
+		Event:     events.NewEvent(),
+		UserID:    userID,
+		ChatID:    chatID,
+		MessageID: messageID,
+	}
+
+	if callbackData.Action == CallbackActionSettings {
+		request.Page = callbackData.Data["p"]
+	} else {
+		page, ok := findSettingPage(callbackData.Data["f"])
+		index, err := strconv.Atoi(callbackData.Data["i"])
+		if !ok || err != nil || index < 0 || index >= len(page.Options) {
+			// Buttons from an older menu; show the current one instead
+			s.logger.Warn("Unknown settings option",
+				zap.String("field", callbackData.Data["f"]),
+				zap.String("index", callbackData.Data["i"]))
+		} else {
+			request.Field = page.Field
+			request.Value = page.Options[index].Value
+		}
+	}
+
+	if err := s.eventBus.Publish(events.TopicSettingsRequested, request); err != nil {
+		s.logger.Error("Failed to publish settings request",
+			zap.String("user_id", userID),
+			zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// handleSettingsResponse shows the settings menu, editing the message the
+// user pressed a button in or sending a new one for /settings
+func (s *chatbotService) handleSettingsResponse(event events.SettingsResponse) {
+	if !s.ownsUser(event.UserID) {
+		return
+	}
+
+	// Dates follow a changed time zone or language from the next message on
+	if event.Success {
+		s.prefs.Set(common.UserID(event.UserID), UserPrefs{Timezone: event.Settings.Timezone, Language: event.Settings.Language})
+	}
+
+	chatIDInt, err := s.telegramChatID(event.ChatID)
+	if err != nil {
+		s.logger.Error("Failed to show settings",
+			zap.String("correlation_id", event.CorrelationID),
+			zap.Error(err))
+		return
+	}
+
+	text := formatSettings(event)
+	keyboard := s.keyboardBuilder.BuildSettingsKeyboard(event.Page, event.Settings)
+
+	if event.MessageID != 0 {
+		err = s.provider.EditMessageWithKeyboard(chatIDInt, event.MessageID, text, keyboard)
+	} else {
+		err = s.provider.SendMessageWithKeyboard(chatIDInt, s.threads.Get(event.ChatID), text, keyboard)
+	}
+	if err != nil {
+		s.logger.Warn("Failed to show settings",
+			zap.String("correlation_id", event.CorrelationID),
+			zap.Int("message_id", event.MessageID),
+			zap.Error(err))
+	}
+}
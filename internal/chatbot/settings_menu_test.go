@@ -0,0 +1,162 @@
+package chatbot
+
+import (
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/events"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// settingsRecordingProvider records new menus as well as edited ones
+type settingsRecordingProvider struct {
+	*listRecordingProvider
+	keyboards []tgbotapi.InlineKeyboardMarkup
+}
+
+func (p *settingsRecordingProvider) SendMessageWithKeyboard(chatID int64, threadID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
+	p.sent = append(p.sent, text)
+	p.keyboards = append(p.keyboards, keyboard)
+	return nil
+}
+
+func testUserSettings() events.UserSettings {
+	return events.UserSettings{NudgeInterval: time.Hour, MaxNudges: 3, QuietHoursStart: "22:00", QuietHoursEnd: "07:00", Timezone: "Asia/Kolkata"}
+}
+
+func TestFormatSettings(t *testing.T) {
+	summary := formatSettings(events.SettingsResponse{Success: true, Settings: testUserSettings()})
+	assert.Contains(t, summary, "⏱ Reminder interval: <b>1 hour</b>")
+	assert.Contains(t, summary, "🌙 Quiet hours: <b>22:00–07:00</b>")
+	assert.Contains(t, summary, "🌍 Timezone: <b>Asia/Kolkata</b>", "values the menu doesn't offer are shown as they are")
+	assert.Contains(t, summary, "🗣 Language: <b>Same as Telegram</b>")
+	assert.Contains(t, summary, "📆 Week starts on: <b>Monday</b>")
+	assert.Contains(t, summary, "💼 Working days: <b>Mon–Fri</b>")
+	assert.Contains(t, summary, "🎉 Public holidays: <b>Off</b>")
+
+	settings := testUserSettings()
+	settings.WeekStart, settings.WorkingDays = "sunday", "sun,mon,tue,thu"
+	custom := formatSettings(events.SettingsResponse{Success: true, Settings: settings})
+	assert.Contains(t, custom, "📆 Week starts on: <b>Sunday</b>")
+	assert.Contains(t, custom, "💼 Working days: <b>Sun, Mon, Tue, Thu</b>")
+
+	settings.Country = "JP"
+	assert.Contains(t, formatSettings(events.SettingsResponse{Success: true, Settings: settings}), "🎉 Public holidays: <b>JP</b>",
+		"countries the menu doesn't offer show their code")
+	assert.NotContains(t, summary, "Saved")
+
+	saved := formatSettings(events.SettingsResponse{Success: true, Changed: events.SettingMaxNudges, Settings: testUserSettings()})
+	assert.Contains(t, saved, "✅ Saved.")
+
+	refused := formatSettings(events.SettingsResponse{Message: "Quiet hours look like <22:00-07:00>."})
+	assert.Contains(t, refused, "❌ Quiet hours look like &lt;22:00-07:00&gt;.")
+
+	page := formatSettings(events.SettingsResponse{Success: true, Page: events.SettingMaxNudges, Settings: testUserSettings()})
+	assert.Contains(t, page, "Settings › Reminders per task")
+	assert.Contains(t, page, "Currently: 3")
+}
+
+func TestKeyboardBuilder_BuildSettingsKeyboard(t *testing.T) {
+	kb := NewKeyboardBuilder()
+
+	summary := kb.BuildSettingsKeyboard("", testUserSettings())
+	var pages []string
+	for _, row := range summary.InlineKeyboard {
+		for _, button := range row {
+			data, err := kb.DecodeCallbackData(*button.CallbackData)
+			require.NoError(t, err)
+			assert.Equal(t, CallbackActionSettings, data.Action)
+			pages = append(pages, data.Data["p"])
+		}
+	}
+	assert.Equal(t, []string{events.SettingInterval, events.SettingMaxNudges, events.SettingQuietHours, events.SettingTimezone, events.SettingLanguage, events.SettingWeekStart, events.SettingWorkingDays, events.SettingCountry}, pages)
+
+	// Every option fits Telegram's callback data limit and decodes back
+	for _, page := range settingPages {
+		keyboard := kb.BuildSettingsKeyboard(page.Field, testUserSettings())
+		options := 0
+		for _, row := range keyboard.InlineKeyboard {
+			for _, button := range row {
+				require.NotNil(t, button.CallbackData)
+				assert.LessOrEqual(t, len(*button.CallbackData), 64)
+				data, err := kb.DecodeCallbackData(*button.CallbackData)
+				require.NoError(t, err)
+				if data.Action == CallbackActionSettingsSet {
+					assert.Equal(t, page.Field, data.Data["f"])
+					options++
+				}
+			}
+		}
+		assert.Equal(t, len(page.Options), options, page.Field)
+	}
+
+	quietHours := kb.BuildSettingsKeyboard(events.SettingQuietHours, testUserSettings())
+	assert.Equal(t, "✓ 22:00–07:00", quietHours.InlineKeyboard[1][0].Text, "the current option is ticked")
+}
+
+func TestCommandProcessor_ProcessSettingsCommand(t *testing.T) {
+	eventBus := events.NewMockEventBus()
+	processor := NewCommandProcessor(eventBus, zaptest.NewLogger(t))
+
+	response, err := processor.ProcessSettingsCommand("user", "42", nil)
+	require.NoError(t, err)
+	assert.Empty(t, response)
+
+	response, err = processor.ProcessSettingsCommand("user", "42", []string{"Timezone", "America/Sao_Paulo"})
+	require.NoError(t, err)
+	assert.Empty(t, response)
+
+	response, err = processor.ProcessSettingsCommand("user", "42", []string{"colour", "blue"})
+	require.NoError(t, err)
+	assert.Contains(t, response, "There is no setting called \"colour\"")
+
+	response, err = processor.ProcessSettingsCommand("user", "42", []string{"language"})
+	require.NoError(t, err)
+	assert.Contains(t, response, "Please add the new language.")
+
+	requests := eventBus.GetPublishedEvents(events.TopicSettingsRequested)
+	require.Len(t, requests, 2)
+	assert.Empty(t, requests[0].(events.SettingsRequested).Field)
+	changed := requests[1].(events.SettingsRequested)
+	assert.Equal(t, events.SettingTimezone, changed.Field)
+	assert.Equal(t, "America/Sao_Paulo", changed.Value)
+}
+
+func TestChatbotService_SettingsMenu(t *testing.T) {
+	eventBus := events.NewMockEventBus()
+	chatbot, list := newListTestService(t)
+	chatbot.eventBus = eventBus
+	provider := &settingsRecordingProvider{listRecordingProvider: list}
+	chatbot.provider = provider
+
+	// /settings sends a new menu
+	chatbot.handleSettingsResponse(events.SettingsResponse{UserID: "user", ChatID: "42", Success: true, Settings: testUserSettings()})
+	require.Len(t, provider.sent, 1)
+	assert.Contains(t, provider.sent[0], "⚙️ <b>Settings</b>")
+
+	// Buttons in it update the same message
+	require.NoError(t, chatbot.handleSettingsCallback(&CallbackData{Action: CallbackActionSettings, Data: map[string]string{"p": events.SettingTimezone}}, "user", "42", 55))
+	require.NoError(t, chatbot.handleSettingsCallback(&CallbackData{Action: CallbackActionSettingsSet, Data: map[string]string{"f": events.SettingTimezone, "i": "2"}}, "user", "42", 55))
+	require.NoError(t, chatbot.handleSettingsCallback(&CallbackData{Action: CallbackActionSettingsSet, Data: map[string]string{"f": events.SettingTimezone, "i": "99"}}, "user", "42", 55))
+
+	requests := eventBus.GetPublishedEvents(events.TopicSettingsRequested)
+	require.Len(t, requests, 3)
+	opened := requests[0].(events.SettingsRequested)
+	assert.Equal(t, events.SettingTimezone, opened.Page)
+	assert.Equal(t, 55, opened.MessageID)
+	chosen := requests[1].(events.SettingsRequested)
+	assert.Equal(t, events.SettingTimezone, chosen.Field)
+	assert.Equal(t, "Europe/Berlin", chosen.Value)
+	assert.Empty(t, requests[2].(events.SettingsRequested).Field, "stale buttons only show the menu")
+
+	settings := testUserSettings()
+	settings.Timezone = "Europe/Berlin"
+	chatbot.handleSettingsResponse(events.SettingsResponse{UserID: "user", ChatID: "42", MessageID: 55, Success: true, Changed: events.SettingTimezone, Settings: settings})
+	assert.Contains(t, list.edited[55], "✅ Saved.")
+	assert.Contains(t, list.edited[55], "🌍 Timezone: <b>Berlin</b>")
+	assert.Len(t, provider.sent, 1)
+}
@@ -0,0 +1,129 @@
+package chatbot
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// ListInviteStartPrefix marks /start payloads that join a shared list
+const ListInviteStartPrefix = "list_"
+
+// ListInviteDeepLink returns the t.me link that opens the bot and joins the list
+func ListInviteDeepLink(botUsername, code string) string {
+	return fmt.Sprintf("https://t.me/%s?start=%s%s", botUsername, ListInviteStartPrefix, code)
+}
+
+// formatListTaskCompleted tells a member that someone else completed a task in a shared list
+func formatListTaskCompleted(event events.ListTaskCompleted) string {
+	return fmt.Sprintf("👥 <b>%s</b>\n\n✅ Another member completed <b>%s</b>.",
+		html.EscapeString(event.ListName), html.EscapeString(event.Title))
+}
+
+// formatLists renders the user's shared lists with their invite links
+func formatLists(lists []events.ListSummary, botUsername string) string {
+	if len(lists) == 0 {
+		return "👥 <b>Shared Lists</b>\n\nYou are not in any shared list yet. Create one with /newlist [name]."
+	}
+
+	var builder strings.Builder
+	builder.WriteString("👥 <b>Shared Lists</b>\n")
+	for _, list := range lists {
+		members := "1 member"
+		if list.Members != 1 {
+			members = fmt.Sprintf("%d members", list.Members)
+		}
+		builder.WriteString(fmt.Sprintf("\n<b>%s</b> · %s", html.EscapeString(list.Name), members))
+		if list.Owner {
+			builder.WriteString(" · owner")
+		}
+		if botUsername != "" {
+			builder.WriteString("\n" + ListInviteDeepLink(botUsername, list.Code))
+		}
+		builder.WriteString("\n")
+	}
+	builder.WriteString("\nAdd tasks with /addto [list]: [task]")
+	return builder.String()
+}
+
+// handleListResponse reports the outcome of /newlist, /addto and list invite links
+func (s *chatbotService) handleListResponse(event events.ListResponse) {
+	if !s.ownsUser(event.UserID) {
+		return
+	}
+
+	s.logger.Info("Handling ListResponse event",
+		zap.String("correlation_id", event.CorrelationID),
+		zap.String("user_id", event.UserID),
+		zap.String("action", event.Action),
+		zap.Bool("success", event.Success))
+
+	var text string
+	switch {
+	case !event.Success:
+		text = fmt.Sprintf("❌ <b>Shared List</b>\n\n%s", html.EscapeString(event.Message))
+	case event.Action == events.ListActionCreate:
+		bot, err := s.provider.GetMe()
+		if err != nil {
+			s.logger.Error("Failed to look up bot username for list invite link",
+				zap.String("correlation_id", event.CorrelationID),
+				zap.Error(err))
+			text = fmt.Sprintf("👥 <b>%s</b> created.\n\nSee its invite link with /lists.", html.EscapeString(event.Name))
+		} else {
+			text = fmt.Sprintf("👥 <b>%s</b> created.\n\n%s\n\n%s",
+				html.EscapeString(event.Name), ListInviteDeepLink(bot.UserName, event.Code), html.EscapeString(event.Message))
+		}
+	default:
+		text = fmt.Sprintf("👥 <b>Joined %s</b>\n\n%s", html.EscapeString(event.Name), html.EscapeString(event.Message))
+	}
+
+	if err := s.SendMessage(common.ChatID(event.ChatID), text); err != nil {
+		s.logger.Error("Failed to send shared list result",
+			zap.String("correlation_id", event.CorrelationID),
+			zap.Error(err))
+	}
+}
+
+// handleListsResponse sends the user's shared lists for /lists
+func (s *chatbotService) handleListsResponse(event events.ListsResponse) {
+	if !s.ownsUser(event.UserID) {
+		return
+	}
+
+	text := fmt.Sprintf("❌ <b>Shared Lists</b>\n\n%s", html.EscapeString(event.Message))
+	if event.Success {
+		var botUsername string
+		if bot, err := s.provider.GetMe(); err != nil {
+			s.logger.Warn("Failed to look up bot username for list invite links",
+				zap.String("correlation_id", event.CorrelationID),
+				zap.Error(err))
+		} else {
+			botUsername = bot.UserName
+		}
+		text = formatLists(event.Lists, botUsername)
+	}
+
+	if err := s.SendMessage(common.ChatID(event.ChatID), text); err != nil {
+		s.logger.Error("Failed to send shared lists",
+			zap.String("correlation_id", event.CorrelationID),
+			zap.Error(err))
+	}
+}
+
+// handleListTaskCompleted tells a member that a task in a shared list was completed
+func (s *chatbotService) handleListTaskCompleted(event events.ListTaskCompleted) {
+	if !s.ownsUser(event.UserID) {
+		return
+	}
+
+	if err := s.SendMessage(common.ChatID(event.ChatID), formatListTaskCompleted(event)); err != nil {
+		s.logger.Error("Failed to send shared list completion",
+			zap.String("task_id", event.TaskID),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,44 @@
+package chatbot
+
+import (
+	"testing"
+
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitAddToArguments(t *testing.T) {
+	tests := []struct {
+		arguments string
+		list      string
+		text      string
+		ok        bool
+	}{
+		{"Team sprint: fix login by Friday", "Team sprint", "fix login by Friday", true},
+		{"Groceries milk and eggs", "Groceries", "milk and eggs", true},
+		{"Groceries", "", "", false},
+		{"Groceries:", "Groceries", "", false},
+		{": milk", "", "milk", false},
+	}
+
+	for _, tt := range tests {
+		list, text, ok := splitAddToArguments(tt.arguments)
+		assert.Equal(t, tt.ok, ok, tt.arguments)
+		if tt.ok {
+			assert.Equal(t, tt.list, list, tt.arguments)
+			assert.Equal(t, tt.text, text, tt.arguments)
+		}
+	}
+}
+
+func TestFormatLists(t *testing.T) {
+	text := formatLists([]events.ListSummary{
+		{Name: "Groceries", Code: "abc", Members: 3, Owner: true},
+		{Name: "Team <sprint>", Code: "def", Members: 1},
+	}, "nudge_bot")
+	assert.Contains(t, text, "<b>Groceries</b> · 3 members · owner\nhttps://t.me/nudge_bot?start=list_abc")
+	assert.Contains(t, text, "<b>Team &lt;sprint&gt;</b> · 1 member\n")
+
+	assert.Contains(t, formatLists(nil, "nudge_bot"), "/newlist")
+}
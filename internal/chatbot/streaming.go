@@ -0,0 +1,153 @@
+package chatbot
+
+import (
+	"fmt"
+	"html"
+	"sync"
+	"time"
+
+	"nudgebot-api/internal/events"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+const (
+	// streamEditInterval spaces the edits of a streamed message out, as
+	// Telegram limits how often a chat's messages can be edited
+	streamEditInterval = 1500 * time.Millisecond
+	// maxStreamTextLength is the most text a Telegram message can show
+	maxStreamTextLength = 4096
+)
+
+// StreamThrottle decides which updates of streamed messages are shown. A
+// message is edited at most once per interval while text arrives; the
+// updates in between are skipped as the next one carries their text too.
+// A nil throttle shows every update.
+type StreamThrottle struct {
+	interval time.Duration
+	now      func() time.Time
+
+	mu     sync.Mutex
+	edited map[string]time.Time
+}
+
+// NewStreamThrottle creates a throttle editing each message at most once per interval
+func NewStreamThrottle(interval time.Duration) *StreamThrottle {
+	return &StreamThrottle{
+		interval: interval,
+		now:      time.Now,
+		edited:   make(map[string]time.Time),
+	}
+}
+
+// Allow reports whether an update of the chat's message should be shown. The
+// final update always is, and ends the message's stream.
+func (t *StreamThrottle) Allow(chatID string, messageID int, done bool) bool {
+	if t == nil {
+		return true
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := fmt.Sprintf("%s:%d", chatID, messageID)
+	if done {
+		delete(t.edited, key)
+		return true
+	}
+	now := t.now()
+	if last, ok := t.edited[key]; ok && now.Sub(last) < t.interval {
+		return false
+	}
+	t.edited[key] = now
+	return true
+}
+
+// formatStreamedText renders streamed text for a message, keeping the text
+// within what a message can show
+func formatStreamedText(event events.TextGenerated) string {
+	text := []rune(event.Text)
+	if len(text) > maxStreamTextLength {
+		text = append(text[:maxStreamTextLength-1], '…')
+	}
+
+	switch {
+	case event.Done && !event.Success && len(text) == 0:
+		return fmt.Sprintf("❌ %s", html.EscapeString(event.Message))
+	case event.Done && !event.Success:
+		return fmt.Sprintf("%s\n\n❌ %s", html.EscapeString(string(text)), html.EscapeString(event.Message))
+	case len(text) == 0:
+		return "✍️ Thinking..."
+	case !event.Done:
+		// Telegram counts the length after entities are parsed, so the
+		// cursor only goes over when the text fills the message
+		if len(text) < maxStreamTextLength {
+			return html.EscapeString(string(text)) + "▌"
+		}
+	}
+	return html.EscapeString(string(text))
+}
+
+// sendStreamPlaceholder posts the message streamed text is written into
+func (s *chatbotService) sendStreamPlaceholder(chatID string) (int, error) {
+	chatIDInt, err := s.telegramChatID(chatID)
+	if err != nil {
+		return 0, err
+	}
+	return s.provider.SendTrackedMessage(chatIDInt, s.threads.Get(chatID), "✍️ Thinking...", tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}})
+}
+
+// startTextStream posts a placeholder message and asks the LLM to stream
+// text for the purpose into it
+func (s *chatbotService) startTextStream(userID, chatID, purpose, prompt string) error {
+	messageID, err := s.sendStreamPlaceholder(chatID)
+	if err != nil {
+		return err
+	}
+
+	requestEvent := events.TextGenerationRequested{
+		Event:     events.NewEvent(),
+		UserID:    userID,
+		ChatID:    chatID,
+		MessageID: messageID,
+		Purpose:   purpose,
+		Prompt:    prompt,
+	}
+	if err := s.eventBus.Publish(events.TopicTextGenerationRequested, requestEvent); err != nil {
+		s.logger.Error("Failed to publish text generation request",
+			zap.String("user_id", userID),
+			zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// handleTextGenerated edits a streamed message to show the text generated so
+// far, throttled so the edits stay within Telegram's rate limits
+func (s *chatbotService) handleTextGenerated(event events.TextGenerated) {
+	if !s.ownsUser(event.UserID) || !s.streams.Allow(event.ChatID, event.MessageID, event.Done) {
+		return
+	}
+	if event.Purpose == events.TextPurposePlan && event.Done && event.Success {
+		// The finished plan is shown with its buttons once it is read
+		return
+	}
+
+	chatIDInt, err := s.telegramChatID(event.ChatID)
+	if err != nil {
+		s.logger.Error("Failed to update streamed message",
+			zap.String("purpose", event.Purpose),
+			zap.Error(err))
+		return
+	}
+
+	keyboard := tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}
+	if err := s.provider.EditMessageWithKeyboard(chatIDInt, event.MessageID, formatStreamedText(event), keyboard); err != nil {
+		s.logger.Warn("Failed to update streamed message",
+			zap.String("correlation_id", event.CorrelationID),
+			zap.String("purpose", event.Purpose),
+			zap.Int("message_id", event.MessageID),
+			zap.Error(err))
+	}
+}
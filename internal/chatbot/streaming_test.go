@@ -0,0 +1,73 @@
+package chatbot
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestStreamThrottle_Allow(t *testing.T) {
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	throttle := NewStreamThrottle(time.Second)
+	throttle.now = func() time.Time { return now }
+
+	assert.True(t, throttle.Allow("42", 1, false))
+	assert.True(t, throttle.Allow("42", 2, false), "messages are throttled separately")
+
+	now = now.Add(500 * time.Millisecond)
+	assert.False(t, throttle.Allow("42", 1, false))
+	assert.True(t, throttle.Allow("42", 1, true), "the final update is always shown")
+
+	now = now.Add(600 * time.Millisecond)
+	assert.True(t, throttle.Allow("42", 2, false))
+}
+
+func TestFormatStreamedText(t *testing.T) {
+	assert.Equal(t, "✍️ Thinking...", formatStreamedText(events.TextGenerated{}))
+	assert.Equal(t, "Plan &lt;today&gt;▌", formatStreamedText(events.TextGenerated{Text: "Plan <today>"}))
+	assert.Equal(t, "Plan", formatStreamedText(events.TextGenerated{Text: "Plan", Done: true, Success: true}))
+	assert.Equal(t, "Plan\n\n❌ Timed out", formatStreamedText(events.TextGenerated{Text: "Plan", Done: true, Message: "Timed out"}))
+	assert.Equal(t, "❌ Timed out", formatStreamedText(events.TextGenerated{Done: true, Message: "Timed out"}))
+
+	long := formatStreamedText(events.TextGenerated{Text: strings.Repeat("a", 5000), Done: true, Success: true})
+	assert.Len(t, []rune(long), maxStreamTextLength)
+	assert.True(t, strings.HasSuffix(long, "…"))
+}
+
+func TestChatbotService_StreamsTextIntoItsMessage(t *testing.T) {
+	eventBus := events.NewMockEventBus()
+	eventBus.SetSynchronousMode(true)
+	chatbot, _ := newBenchService(eventBus, zaptest.NewLogger(t))
+	provider := &listRecordingProvider{edited: make(map[int]string)}
+	chatbot.provider = provider
+	now := time.Now()
+	chatbot.streams = NewStreamThrottle(time.Second)
+	chatbot.streams.now = func() time.Time { return now }
+
+	require.NoError(t, chatbot.startTextStream("user", "42", "brief", "Plan my day"))
+	require.Len(t, provider.sent, 1)
+	requests := eventBus.GetPublishedEvents(events.TopicTextGenerationRequested)
+	require.Len(t, requests, 1)
+	request := requests[0].(events.TextGenerationRequested)
+	assert.Equal(t, 101, request.MessageID)
+	assert.Equal(t, "Plan my day", request.Prompt)
+
+	generated := events.TextGenerated{Event: events.NewEvent(), UserID: "user", ChatID: "42", MessageID: 101, Purpose: "brief", Text: "9:00"}
+	chatbot.handleTextGenerated(generated)
+	assert.Equal(t, "9:00▌", provider.edited[101])
+
+	// Updates inside the interval are skipped, the final one is not
+	generated.Text = "9:00 Write report"
+	chatbot.handleTextGenerated(generated)
+	assert.Equal(t, "9:00▌", provider.edited[101])
+
+	generated.Done, generated.Success = true, true
+	chatbot.handleTextGenerated(generated)
+	assert.Equal(t, "9:00 Write report", provider.edited[101])
+}
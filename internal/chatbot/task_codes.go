@@ -0,0 +1,232 @@
+package chatbot
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+	"sync"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// taskCodesUsers is how many users' tasks are remembered; the least
+	// recently active are forgotten first
+	taskCodesUsers = 10000
+	// taskCodesPerUser is how many codes and titles are remembered for one
+	// user before they are forgotten and learned again
+	taskCodesPerUser = 500
+)
+
+// TaskCodeResolver finds the task a user's short code stands for. It returns
+// "" when the user has no task with the code.
+type TaskCodeResolver interface {
+	TaskIDForCode(userID common.UserID, code int) (string, error)
+}
+
+// TaskCodeResolverFunc adapts a function to the TaskCodeResolver interface
+type TaskCodeResolverFunc func(userID common.UserID, code int) (string, error)
+
+// TaskIDForCode implements the TaskCodeResolver interface
+func (f TaskCodeResolverFunc) TaskIDForCode(userID common.UserID, code int) (string, error) {
+	return f(userID, code)
+}
+
+// taskCodes remembers which task each short code and title shown to a user
+// stands for. Tasks are learned from task lists and creation confirmations,
+// which are the only places users see codes. Only recent users are kept, so
+// codes missing here are looked up with the TaskCodeResolver.
+type taskCodes struct {
+	mu       sync.Mutex
+	capacity int
+	byUser   map[string]*list.Element
+	recent   *list.List // of *userTaskCodes, most recently active first
+}
+
+// userTaskCodes are the tasks one user has been shown
+type userTaskCodes struct {
+	userID string
+	codes  map[int]string
+	titles map[string]map[string]bool // lowercased title to task IDs
+}
+
+func newTaskCodes() *taskCodes {
+	return &taskCodes{
+		capacity: taskCodesUsers,
+		byUser:   make(map[string]*list.Element),
+		recent:   list.New(),
+	}
+}
+
+// remember records the code and title of a task shown to the user
+func (c *taskCodes) remember(userID string, task events.TaskSummary) {
+	if task.ID == "" {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	known := c.touch(userID)
+	if known == nil {
+		known = &userTaskCodes{userID: userID, codes: make(map[int]string), titles: make(map[string]map[string]bool)}
+		c.byUser[userID] = c.recent.PushFront(known)
+		if c.recent.Len() > c.capacity {
+			oldest := c.recent.Back()
+			c.recent.Remove(oldest)
+			delete(c.byUser, oldest.Value.(*userTaskCodes).userID)
+		}
+	}
+	if number, ok := common.ParseTaskCode(task.Code); ok {
+		if len(known.codes) >= taskCodesPerUser {
+			known.codes = make(map[int]string)
+		}
+		known.codes[number] = task.ID
+	}
+	if title := strings.ToLower(task.Title); title != "" {
+		if known.titles[title] == nil {
+			if len(known.titles) >= taskCodesPerUser {
+				known.titles = make(map[string]map[string]bool)
+			}
+			known.titles[title] = make(map[string]bool)
+		}
+		known.titles[title][task.ID] = true
+	}
+}
+
+// touch returns the user's tasks, marking them most recently active, or nil
+// when none are remembered. The caller holds the lock.
+func (c *taskCodes) touch(userID string) *userTaskCodes {
+	element, exists := c.byUser[userID]
+	if !exists {
+		return nil
+	}
+	c.recent.MoveToFront(element)
+	return element.Value.(*userTaskCodes)
+}
+
+// lookup returns the task the user's code names
+func (c *taskCodes) lookup(userID string, number int) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	known := c.touch(userID)
+	if known == nil {
+		return "", false
+	}
+	taskID, ok := known.codes[number]
+	return taskID, ok
+}
+
+// lookupTitle returns the IDs of the user's tasks with the title, ignoring case
+func (c *taskCodes) lookupTitle(userID, title string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	known := c.touch(userID)
+	if known == nil {
+		return nil
+	}
+	ids := make([]string, 0, len(known.titles[strings.ToLower(title)]))
+	for id := range known.titles[strings.ToLower(title)] {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// forgetTitles drops the titles remembered for the user
+func (c *taskCodes) forgetTitles(userID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if known := c.touch(userID); known != nil {
+		known.titles = make(map[string]map[string]bool)
+	}
+}
+
+// RememberTaskList records the user's task list. The list holds all of their
+// open tasks, so titles missing from it, of tasks since done or deleted, are
+// forgotten.
+func (cp *CommandProcessor) RememberTaskList(userID string, tasks []events.TaskSummary) {
+	cp.taskCodes.forgetTitles(userID)
+	cp.RememberTasks(userID, tasks)
+}
+
+// RememberTasks records the codes and titles of tasks shown to the user
+func (cp *CommandProcessor) RememberTasks(userID string, tasks []events.TaskSummary) {
+	for _, task := range tasks {
+		cp.taskCodes.remember(userID, task)
+	}
+}
+
+// RememberTask records the code and title of a task shown to the user
+func (cp *CommandProcessor) RememberTask(userID string, task events.TaskSummary) {
+	cp.taskCodes.remember(userID, task)
+}
+
+// resolveTaskID returns the task ID a command argument names: a short code
+// such as T-42, the title of a task the user has been shown, or the ID
+// itself. An unknown code or an ambiguous title resolves to "" with a reply
+// for the user.
+func (cp *CommandProcessor) resolveTaskID(userID, arg string) (string, string) {
+	if number, isCode := common.ParseTaskCode(arg); isCode {
+		taskID, ok := cp.taskCodes.lookup(userID, number)
+		if !ok {
+			taskID = cp.lookupTaskCode(userID, number)
+		}
+		if taskID == "" {
+			return "", fmt.Sprintf("I don't know task %s. Send /list to see your tasks and their codes.", common.FormatTaskCode(number))
+		}
+		return taskID, ""
+	}
+
+	switch ids := cp.taskCodes.lookupTitle(userID, arg); len(ids) {
+	case 0:
+		return arg, ""
+	case 1:
+		return ids[0], ""
+	default:
+		return "", fmt.Sprintf("You have %d tasks called %q. Use the code from /list instead.", len(ids), arg)
+	}
+}
+
+// lookupTaskCode finds a code that was not shown since the process started,
+// or was forgotten, with the resolver. It returns "" when nothing has the code.
+func (cp *CommandProcessor) lookupTaskCode(userID string, number int) string {
+	if cp.codeResolver == nil {
+		return ""
+	}
+
+	taskID, err := cp.codeResolver.TaskIDForCode(common.UserID(userID), number)
+	if err != nil {
+		cp.logger.Warn("Failed to look up task code",
+			zap.String("user_id", userID),
+			zap.Int("code", number),
+			zap.Error(err))
+		return ""
+	}
+	if taskID != "" {
+		cp.taskCodes.remember(userID, events.TaskSummary{ID: taskID, Code: common.FormatTaskCode(number)})
+	}
+	return taskID
+}
+
+// taskLabel names the task a command argument refers to in replies
+func taskLabel(arg string) string {
+	if number, ok := common.ParseTaskCode(arg); ok {
+		return common.FormatTaskCode(number)
+	}
+	return arg
+}
+
+// formatTaskCode shows a task's code ahead of its title, or nothing for tasks
+// without one
+func formatTaskCode(code string) string {
+	if code == "" {
+		return ""
+	}
+	return "<code>" + code + "</code> "
+}
@@ -0,0 +1,168 @@
+package chatbot
+
+import (
+	"fmt"
+	"testing"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestCommandProcessor_ResolvesTaskCodes(t *testing.T) {
+	eventBus := events.NewMockEventBus()
+	processor := NewCommandProcessor(eventBus, zaptest.NewLogger(t))
+	processor.RememberTasks("user", []events.TaskSummary{
+		{ID: "task-uuid-1", Code: "T-1", Title: "Dentist"},
+		{ID: "task-uuid-2", Code: "T-2", Title: "Groceries"},
+		{ID: "task-uuid-legacy", Title: "Created before codes"},
+	})
+	processor.RememberTask("other", events.TaskSummary{ID: "their-task", Code: "T-1", Title: "Dentist"})
+
+	response, err := processor.ProcessDoneCommand("user", "42", []string{"t2"})
+	require.NoError(t, err)
+	assert.Equal(t, "Marking task T-2 as complete...", response)
+
+	response, err = processor.ProcessSnoozeCommand("user", "42", []string{"T-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "Snoozing task T-1 for an hour...", response)
+
+	response, err = processor.ProcessDeleteCommand("user", "42", []string{"task-uuid-legacy"})
+	require.NoError(t, err)
+	assert.Equal(t, "Deleting task task-uuid-legacy...", response)
+
+	actions := eventBus.GetPublishedEvents(events.TopicTaskActionRequested)
+	require.Len(t, actions, 3)
+	assert.Equal(t, "task-uuid-2", actions[0].(events.TaskActionRequested).TaskID)
+	assert.Equal(t, "done", actions[0].(events.TaskActionRequested).Action)
+	assert.Equal(t, "task-uuid-1", actions[1].(events.TaskActionRequested).TaskID, "codes are per user")
+	assert.Equal(t, "snooze", actions[1].(events.TaskActionRequested).Action)
+	assert.Equal(t, "task-uuid-legacy", actions[2].(events.TaskActionRequested).TaskID)
+}
+
+func TestCommandProcessor_UnknownTaskCode(t *testing.T) {
+	eventBus := events.NewMockEventBus()
+	processor := NewCommandProcessor(eventBus, zaptest.NewLogger(t))
+	processor.RememberTask("other", events.TaskSummary{ID: "their-task", Code: "T-9"})
+
+	response, err := processor.ProcessDoneCommand("user", "42", []string{"T-9"})
+	require.NoError(t, err)
+	assert.Contains(t, response, "I don't know task T-9")
+	assert.Contains(t, response, "/list")
+	assert.Empty(t, eventBus.GetPublishedEvents(events.TopicTaskActionRequested))
+}
+
+func TestCommandProcessor_LooksUpUnseenTaskCodes(t *testing.T) {
+	eventBus := events.NewMockEventBus()
+	lookups := 0
+	resolver := TaskCodeResolverFunc(func(userID common.UserID, code int) (string, error) {
+		lookups++
+		if userID == "user" && code == 42 {
+			return "task-uuid-42", nil
+		}
+		return "", nil
+	})
+	// A fresh processor stands in for a restarted or different replica
+	processor := NewCommandProcessorWithTaskCodes(eventBus, zaptest.NewLogger(t), resolver)
+
+	response, err := processor.ProcessDoneCommand("user", "42", []string{"T-42"})
+	require.NoError(t, err)
+	assert.Equal(t, "Marking task T-42 as complete...", response)
+	_, err = processor.ProcessDoneCommand("user", "42", []string{"T-42"})
+	require.NoError(t, err)
+
+	response, err = processor.ProcessDoneCommand("user", "42", []string{"T-7"})
+	require.NoError(t, err)
+	assert.Contains(t, response, "I don't know task T-7")
+
+	actions := eventBus.GetPublishedEvents(events.TopicTaskActionRequested)
+	require.Len(t, actions, 2)
+	assert.Equal(t, "task-uuid-42", actions[0].(events.TaskActionRequested).TaskID)
+	assert.Equal(t, 2, lookups, "resolved codes are remembered")
+}
+
+func TestTaskCodes_ForgetsLeastRecentlyActiveUsers(t *testing.T) {
+	codes := newTaskCodes()
+	codes.capacity = 2
+	codes.remember("first", events.TaskSummary{ID: "first-task", Code: "T-1"})
+	codes.remember("second", events.TaskSummary{ID: "second-task", Code: "T-1"})
+	_, _ = codes.lookup("first", 1)
+	codes.remember("third", events.TaskSummary{ID: "third-task", Code: "T-1"})
+
+	_, ok := codes.lookup("second", 1)
+	assert.False(t, ok)
+	taskID, ok := codes.lookup("first", 1)
+	assert.True(t, ok)
+	assert.Equal(t, "first-task", taskID)
+
+	for number := 1; number <= taskCodesPerUser+1; number++ {
+		codes.remember("third", events.TaskSummary{ID: fmt.Sprintf("task-%d", number), Code: common.FormatTaskCode(number)})
+	}
+	codes.mu.Lock()
+	remembered := len(codes.byUser["third"].Value.(*userTaskCodes).codes)
+	codes.mu.Unlock()
+	assert.LessOrEqual(t, remembered, taskCodesPerUser)
+}
+
+func TestTaskListPage_ShowsCodes(t *testing.T) {
+	text := formatTaskListPage([]events.TaskSummary{
+		{ID: "task-1", Code: "T-7", Title: "Dentist", Priority: "high", Status: "active"},
+		{ID: "task-2", Title: "Legacy", Priority: "low", Status: "active"},
+	}, 0, 1, fixedDates)
+
+	assert.Contains(t, text, "<b>1.</b> <code>T-7</code> Dentist")
+	assert.Contains(t, text, "<b>2.</b> Legacy")
+}
+
+func TestCommandProcessor_ResolvesQuotedTitles(t *testing.T) {
+	eventBus := events.NewMockEventBus()
+	processor := NewCommandProcessor(eventBus, zaptest.NewLogger(t))
+	processor.RememberTaskList("user", []events.TaskSummary{
+		{ID: "milk-1", Code: "T-1", Title: "Buy milk"},
+		{ID: "call-1", Code: "T-2", Title: "Call mom"},
+		{ID: "call-2", Code: "T-3", Title: "Call mom"},
+	})
+
+	response, err := processor.ProcessSnoozeCommand("user", "42", []string{"buy milk", "tomorrow", "9am"})
+	require.NoError(t, err)
+	assert.Equal(t, "Snoozing task buy milk until tomorrow 9am...", response)
+	actions := eventBus.GetPublishedEvents(events.TopicTaskActionRequested)
+	require.Len(t, actions, 1)
+	assert.Equal(t, "milk-1", actions[0].(events.TaskActionRequested).TaskID)
+	assert.Equal(t, "tomorrow 9am", actions[0].(events.TaskActionRequested).Argument)
+
+	response, err = processor.ProcessDoneCommand("user", "42", []string{"Call mom"})
+	require.NoError(t, err)
+	assert.Contains(t, response, `You have 2 tasks called "Call mom"`)
+
+	// A new list forgets the titles of tasks no longer on it
+	processor.RememberTaskList("user", []events.TaskSummary{{ID: "call-2", Code: "T-3", Title: "Call mom"}})
+	_, err = processor.ProcessDoneCommand("user", "42", []string{"Call mom"})
+	require.NoError(t, err)
+	actions = eventBus.GetPublishedEvents(events.TopicTaskActionRequested)
+	require.Len(t, actions, 2)
+	assert.Equal(t, "call-2", actions[1].(events.TaskActionRequested).TaskID)
+}
+
+func TestCommandProcessor_RejectsBadArguments(t *testing.T) {
+	eventBus := events.NewMockEventBus()
+	processor := NewCommandProcessor(eventBus, zaptest.NewLogger(t))
+
+	response, err := processor.ProcessSnoozeCommand("user", "42", []string{"T-1", "someday"})
+	require.NoError(t, err)
+	assert.Contains(t, response, "Usage: /snooze")
+
+	response, err = processor.ProcessDeleteCommand("user", "42", []string{"Buy", "milk"})
+	require.NoError(t, err)
+	assert.Contains(t, response, "Put titles with spaces in quotes")
+
+	response, err = processor.ProcessRescheduleCommand("user", "42", CommandSnoozeAll, []string{"soon"})
+	require.NoError(t, err)
+	assert.Contains(t, response, "Usage: /snoozeall")
+
+	assert.Empty(t, eventBus.GetPublishedEvents(events.TopicTaskActionRequested))
+	assert.Empty(t, eventBus.GetPublishedEvents(events.TopicTaskRescheduleRequested))
+}
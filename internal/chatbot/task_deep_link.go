@@ -0,0 +1,57 @@
+package chatbot
+
+import (
+	"fmt"
+	"strconv"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// TaskStartPrefix marks /start payloads that open a task's details
+const TaskStartPrefix = "task_"
+
+// TaskDeepLink returns the t.me link that opens the bot on the task with the
+// short code, such as T-42, for linking tasks from emails, digests or a web page
+func TaskDeepLink(botUsername, code string) string {
+	return fmt.Sprintf("https://t.me/%s?start=%s%s", botUsername, TaskStartPrefix, code)
+}
+
+// parseTaskStartCode returns the task number of a deep link payload: a short
+// code such as T-42, or the bare number
+func parseTaskStartCode(payload string) (int, bool) {
+	if number, ok := common.ParseTaskCode(payload); ok {
+		return number, true
+	}
+	number, err := strconv.Atoi(payload)
+	if err != nil || number <= 0 {
+		return 0, false
+	}
+	return number, true
+}
+
+// ProcessOpenTaskCommand handles a /start command opened from a task's deep
+// link. The task is shown once the nudge service has looked it up.
+func (cp *CommandProcessor) ProcessOpenTaskCommand(userID, chatID, payload string) (string, error) {
+	cp.logger.Info("Processing open task command",
+		zap.String("user_id", userID),
+		zap.String("chat_id", chatID))
+
+	number, ok := parseTaskStartCode(payload)
+	if !ok {
+		return "That task link is not valid. See your tasks with /list.", nil
+	}
+
+	detailsEvent := events.TaskDetailsRequested{
+		Event:  events.NewEvent(),
+		UserID: userID,
+		ChatID: chatID,
+		Code:   number,
+	}
+
+	cp.eventBus.Publish(events.TopicTaskDetailsRequested, detailsEvent)
+
+	return "", nil
+}
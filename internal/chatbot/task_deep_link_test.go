@@ -0,0 +1,76 @@
+package chatbot
+
+import (
+	"testing"
+
+	"nudgebot-api/internal/events"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// startUpdate is a /start command carrying a deep link payload
+func startUpdate(payload string) *tgbotapi.Update {
+	text := "/start " + payload
+	return &tgbotapi.Update{
+		UpdateID: 1,
+		Message: &tgbotapi.Message{
+			From:     &tgbotapi.User{ID: 12345},
+			Chat:     &tgbotapi.Chat{ID: 42},
+			Text:     text,
+			Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len("/start")}},
+		},
+	}
+}
+
+func TestTaskDeepLink(t *testing.T) {
+	assert.Equal(t, "https://t.me/nudge_bot?start=task_T-42", TaskDeepLink("nudge_bot", "T-42"))
+
+	for payload, want := range map[string]int{"T-42": 42, "t42": 42, "42": 42} {
+		number, ok := parseTaskStartCode(payload)
+		require.True(t, ok, payload)
+		assert.Equal(t, want, number)
+	}
+	for _, payload := range []string{"", "T-0", "-3", "abc"} {
+		_, ok := parseTaskStartCode(payload)
+		assert.False(t, ok, payload)
+	}
+}
+
+func TestChatbotService_OpensTaskFromDeepLink(t *testing.T) {
+	eventBus := events.NewMockEventBus()
+	eventBus.SetSynchronousMode(true)
+	chatbot, _ := newBenchService(eventBus, zaptest.NewLogger(t))
+	provider := &detailsRecordingProvider{listRecordingProvider: listRecordingProvider{edited: make(map[int]string)}}
+	chatbot.provider = provider
+
+	require.NoError(t, chatbot.handleCommand(startUpdate("task_T-42"), "user", "42", "correlation"))
+	requests := eventBus.GetPublishedEvents(events.TopicTaskDetailsRequested)
+	require.Len(t, requests, 1)
+	assert.Equal(t, 42, requests[0].(events.TaskDetailsRequested).Code)
+
+	require.NoError(t, chatbot.handleCommand(startUpdate("task_nope"), "user", "42", "correlation"))
+	assert.Len(t, eventBus.GetPublishedEvents(events.TopicTaskDetailsRequested), 1, "invalid links ask for nothing")
+	require.NotEmpty(t, provider.messages)
+	assert.Contains(t, provider.messages[len(provider.messages)-1], "not valid")
+
+	chatbot.handleTaskDetailsResponse(events.TaskDetailsResponse{
+		Event: events.NewEvent(), UserID: "user", ChatID: "42", Success: true,
+		Task: events.TaskSummary{ID: "task-42", Code: "T-42", Title: "Renew passport", Priority: "high", Status: "active"},
+	})
+	require.Len(t, provider.texts, 1)
+	assert.Contains(t, provider.texts[0], "<b>Renew passport</b>")
+	assert.Equal(t, CallbackActionMute, lastButtonAction(t, chatbot.keyboardBuilder, provider.keyboards[0]).Action)
+
+	// The code can be typed in commands from now on
+	taskID, reply := chatbot.commandProcessor.resolveTaskID("user", "T-42")
+	assert.Empty(t, reply)
+	assert.Equal(t, "task-42", taskID)
+
+	chatbot.handleTaskDetailsResponse(events.TaskDetailsResponse{
+		Event: events.NewEvent(), UserID: "user", ChatID: "42", Message: "I couldn't find task T-7.",
+	})
+	assert.Contains(t, provider.messages[len(provider.messages)-1], "Task Unavailable")
+}
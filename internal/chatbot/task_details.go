@@ -0,0 +1,208 @@
+package chatbot
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// maxDetailReminders caps the reminders listed in a task's detail view
+const maxDetailReminders = 5
+
+// handleViewTaskCallback asks for the details of a task picked from the task
+// list; they are shown with the task's action buttons once the nudge service
+// has looked them up
+func (s *chatbotService) handleViewTaskCallback(callbackData *CallbackData, userID, chatID string) error {
+	taskID, exists := callbackData.Data["task_id"]
+	if !exists || taskID == "" {
+		return s.SendMessage(common.ChatID(chatID), "Invalid task ID.")
+	}
+
+	detailsEvent := events.TaskDetailsRequested{
+		Event:  events.NewEvent(),
+		UserID: userID,
+		ChatID: chatID,
+		TaskID: taskID,
+	}
+	return s.eventBus.Publish(events.TopicTaskDetailsRequested, detailsEvent)
+}
+
+// ProcessTaskCommand handles the /task command, showing one task by its code,
+// title or ID
+func (cp *CommandProcessor) ProcessTaskCommand(userID, chatID string, args []string) (string, error) {
+	cp.logger.Info("Processing task command",
+		zap.String("user_id", userID),
+		zap.String("chat_id", chatID),
+		zap.Strings("args", args))
+
+	parsed := newCommandArgs(taskUsage, args)
+	task, err := parsed.task()
+	if err == nil {
+		err = parsed.done()
+	}
+	if err != nil {
+		return usageReply(err)
+	}
+
+	detailsEvent := events.TaskDetailsRequested{
+		Event:  events.NewEvent(),
+		UserID: userID,
+		ChatID: chatID,
+	}
+	// Codes are looked up by the nudge service, so they work before /list
+	if number, isCode := common.ParseTaskCode(task); isCode {
+		detailsEvent.Code = number
+	} else {
+		taskID, reply := cp.resolveTaskID(userID, task)
+		if reply != "" {
+			return reply, nil
+		}
+		detailsEvent.TaskID = taskID
+	}
+
+	cp.eventBus.Publish(events.TopicTaskDetailsRequested, detailsEvent)
+
+	return "", nil
+}
+
+// handleTaskDetailsResponse shows a task's details with its action buttons
+func (s *chatbotService) handleTaskDetailsResponse(event events.TaskDetailsResponse) {
+	if !s.ownsUser(event.UserID) {
+		return
+	}
+
+	var err error
+	if event.Success {
+		s.commandProcessor.RememberTask(event.UserID, event.Task)
+		keyboard := s.keyboardBuilder.ToDomainKeyboard(s.keyboardBuilder.BuildTaskDetailsKeyboard(event.Task))
+		err = s.SendMessageWithKeyboard(common.ChatID(event.ChatID), formatTaskDetailView(event, s.dateFormat(event.UserID)), keyboard)
+	} else {
+		err = s.SendMessage(common.ChatID(event.ChatID), fmt.Sprintf("❌ <b>Task Unavailable</b>\n\n%s", html.EscapeString(event.Message)))
+	}
+	if err != nil {
+		s.logger.Error("Failed to send task details",
+			zap.String("correlation_id", event.CorrelationID),
+			zap.String("task_id", event.Task.ID),
+			zap.Error(err))
+	}
+}
+
+// formatTaskDetailView renders a task with its reminder schedule and a
+// summary of its history
+func formatTaskDetailView(details events.TaskDetailsResponse, dates DateFormat) string {
+	return formatTaskDetails(details.Task, dates) +
+		formatReminderSchedule(details.Reminders, dates) +
+		formatHistorySummary(details.History, dates)
+}
+
+// formatTaskDetails renders one task with everything the task list shows
+func formatTaskDetails(task events.TaskSummary, dates DateFormat) string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("📋 <b>%s</b>\n", task.Title))
+
+	builder.WriteString(fmt.Sprintf("\n<b>Priority:</b> %s", formatPriority(task.Priority)))
+	if label, ok := taskStatusLabels[task.Status]; ok {
+		builder.WriteString(fmt.Sprintf("\n<b>Status:</b> %s", label))
+	}
+
+	if task.Habit != "" {
+		builder.WriteString(fmt.Sprintf("\n<b>Habit:</b> %s", formatHabit(task)))
+	}
+	if task.List != "" {
+		builder.WriteString(fmt.Sprintf("\n<b>List:</b> %s", html.EscapeString(task.List)))
+	}
+
+	if task.DueDate != nil {
+		dueText := dates.DateTime(*task.DueDate)
+		if task.IsOverdue {
+			builder.WriteString(fmt.Sprintf("\n<b>Due:</b> %s ⏰ <b>OVERDUE</b>", dueText))
+		} else {
+			builder.WriteString(fmt.Sprintf("\n<b>Due:</b> %s", dueText))
+		}
+	}
+
+	if task.Description != "" {
+		builder.WriteString(fmt.Sprintf("\n\n📝 %s", task.Description))
+	}
+
+	builder.WriteString(formatCustomFields(task.CustomFields))
+	builder.WriteString(formatLinkPreviews(task.Links))
+	builder.WriteString(formatTaskLocation(task))
+
+	if task.Muted {
+		builder.WriteString("\n\n🔕 <i>Reminders are muted for this task.</i>")
+	}
+
+	return builder.String()
+}
+
+// formatTaskLocation shows the place a task mentions, with its address and a
+// map link once it has been geocoded
+func formatTaskLocation(task events.TaskSummary) string {
+	if task.Location == "" {
+		return ""
+	}
+	text := fmt.Sprintf("\n\n📍 %s", html.EscapeString(task.Location))
+	if task.Address != "" {
+		text += fmt.Sprintf("\n   <i>%s</i>", html.EscapeString(task.Address))
+	}
+	if task.MapURL != "" {
+		text += fmt.Sprintf("\n   🗺 <a href=\"%s\">Open map</a>", html.EscapeString(task.MapURL))
+	}
+	return text
+}
+
+// formatReminderSchedule lists the reminders still to be sent for a task
+func formatReminderSchedule(reminders []events.TaskReminderSummary, dates DateFormat) string {
+	if len(reminders) == 0 {
+		return ""
+	}
+
+	var text strings.Builder
+	text.WriteString("\n\n⏰ <b>Reminders</b>")
+	for i, reminder := range reminders {
+		if i == maxDetailReminders {
+			text.WriteString(fmt.Sprintf("\n   <i>and %d more</i>", len(reminders)-maxDetailReminders))
+			break
+		}
+		kind := "reminder"
+		if reminder.Type == "nudge" {
+			kind = "nudge"
+		}
+		text.WriteString(fmt.Sprintf("\n   • %s (%s)", dates.DateTime(reminder.ScheduledAt), kind))
+	}
+	return text.String()
+}
+
+// formatHistorySummary counts a task's history entries and shows the latest;
+// the History button shows the full timeline
+func formatHistorySummary(history events.TaskHistorySummary, dates DateFormat) string {
+	if history.Entries == 0 {
+		return ""
+	}
+
+	counts := []string{"1 entry"}
+	if history.Entries != 1 {
+		counts[0] = fmt.Sprintf("%d entries", history.Entries)
+	}
+	if history.Snoozes > 0 {
+		counts = append(counts, "snoozed "+pluralUnit(history.Snoozes, "time"))
+	}
+	if history.Nudges > 0 {
+		counts = append(counts, pluralUnit(history.Nudges, "nudge")+" sent")
+	}
+	if history.Edits > 0 {
+		counts = append(counts, "edited "+pluralUnit(history.Edits, "time"))
+	}
+
+	text := "\n\n🕘 <b>History:</b> " + strings.Join(counts, ", ")
+	if history.Last != nil {
+		text += fmt.Sprintf("\n   Last: %s %s", dates.DateTime(history.Last.OccurredAt), describeHistoryEntry(*history.Last))
+	}
+	return text
+}
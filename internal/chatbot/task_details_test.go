@@ -0,0 +1,163 @@
+package chatbot
+
+import (
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/events"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// detailsRecordingProvider records the messages sent with a keyboard
+type detailsRecordingProvider struct {
+	listRecordingProvider
+	texts     []string
+	keyboards []tgbotapi.InlineKeyboardMarkup
+}
+
+func (p *detailsRecordingProvider) SendMessageWithKeyboard(chatID int64, threadID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
+	p.texts = append(p.texts, text)
+	p.keyboards = append(p.keyboards, keyboard)
+	return nil
+}
+
+// lastButtonAction decodes the action of the last button of a keyboard
+func lastButtonAction(t *testing.T, kb *KeyboardBuilder, keyboard tgbotapi.InlineKeyboardMarkup) *CallbackData {
+	lastRow := keyboard.InlineKeyboard[len(keyboard.InlineKeyboard)-1]
+	data, err := kb.DecodeCallbackData(*lastRow[len(lastRow)-1].CallbackData)
+	require.NoError(t, err)
+	return data
+}
+
+// answerDetailsFromList responds to the last details request like the nudge
+// service would, with the task as the tracked list has it
+func answerDetailsFromList(t *testing.T, service *chatbotService, eventBus *events.MockEventBus) {
+	requests := eventBus.GetPublishedEvents(events.TopicTaskDetailsRequested)
+	require.NotEmpty(t, requests)
+	request := requests[len(requests)-1].(events.TaskDetailsRequested)
+
+	tracked, exists := service.listMessages.Get(request.ChatID)
+	require.True(t, exists)
+	for _, task := range tracked.Tasks {
+		if task.ID == request.TaskID {
+			service.handleTaskDetailsResponse(events.TaskDetailsResponse{
+				Event: events.NewEvent(), UserID: request.UserID, ChatID: request.ChatID, Task: task, Success: true,
+			})
+			return
+		}
+	}
+	t.Fatalf("task %s is not in the list", request.TaskID)
+}
+
+func TestTaskDetails_OffersUnmuteForMutedTasks(t *testing.T) {
+	service, _ := newListTestService(t)
+	eventBus := events.NewMockEventBus()
+	service.eventBus = eventBus
+	provider := &detailsRecordingProvider{listRecordingProvider: listRecordingProvider{edited: make(map[int]string)}}
+	service.provider = provider
+
+	service.handleTaskListResponse(listResponse(2))
+	view := &CallbackData{Action: CallbackActionViewTask, Data: map[string]string{"task_id": "task-1"}}
+
+	require.NoError(t, service.handleViewTaskCallback(view, "user", "42"))
+	answerDetailsFromList(t, service, eventBus)
+	require.Len(t, provider.texts, 1)
+	assert.Contains(t, provider.texts[0], "<b>Task 2</b>")
+	assert.NotContains(t, provider.texts[0], "muted")
+	assert.Equal(t, CallbackActionMute, lastButtonAction(t, service.keyboardBuilder, provider.keyboards[0]).Action)
+
+	service.handleTaskActionResponse(events.TaskActionResponse{
+		Event: events.NewEvent(), UserID: "user", ChatID: "42", TaskID: "task-1", Action: "mute", Success: true,
+	})
+
+	require.NoError(t, service.handleViewTaskCallback(view, "user", "42"))
+	answerDetailsFromList(t, service, eventBus)
+	require.Len(t, provider.texts, 2)
+	assert.Contains(t, provider.texts[1], "Reminders are muted")
+	unmute := lastButtonAction(t, service.keyboardBuilder, provider.keyboards[1])
+	assert.Equal(t, CallbackActionUnmute, unmute.Action)
+	assert.Equal(t, "task-1", unmute.Data["task_id"])
+
+	tracked, _ := service.listMessages.Get("42")
+	assert.False(t, tracked.Tasks[0].Muted, "only the muted task changes")
+	assert.Contains(t, formatTaskListPage(tracked.Tasks, 0, 1, fixedDates), "🔕 Muted")
+}
+
+func TestFormatTaskDetailView(t *testing.T) {
+	due := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+	details := events.TaskDetailsResponse{
+		Task: events.TaskSummary{ID: "task-1", Code: "T-3", Title: "Renew passport", Description: "Bring two photos and the old passport", Priority: "high", Status: "active"},
+		Reminders: []events.TaskReminderSummary{
+			{ScheduledAt: due, Type: "initial"},
+			{ScheduledAt: due.Add(time.Hour), Type: "nudge"},
+		},
+		History: events.TaskHistorySummary{
+			Entries: 4, Snoozes: 2, Edits: 1,
+			Last: &events.TaskHistoryEntry{Kind: "snoozed", Detail: "until tomorrow", OccurredAt: due.Add(-time.Hour)},
+		},
+	}
+
+	text := formatTaskDetailView(details, fixedDates)
+	assert.Contains(t, text, "📝 Bring two photos and the old passport")
+	assert.Contains(t, text, "⏰ <b>Reminders</b>\n   • "+fixedDates.DateTime(due)+" (reminder)\n   • "+fixedDates.DateTime(due.Add(time.Hour))+" (nudge)")
+	assert.Contains(t, text, "🕘 <b>History:</b> 4 entries, snoozed 2 times, edited 1 time")
+	assert.Contains(t, text, "Last: "+fixedDates.DateTime(due.Add(-time.Hour))+" snoozed until tomorrow")
+
+	details.Reminders, details.History = nil, events.TaskHistorySummary{}
+	text = formatTaskDetailView(details, fixedDates)
+	assert.NotContains(t, text, "Reminders")
+	assert.NotContains(t, text, "History")
+}
+
+func TestCommandProcessor_TaskCommand(t *testing.T) {
+	eventBus := events.NewMockEventBus()
+	processor := NewCommandProcessor(eventBus, zaptest.NewLogger(t))
+
+	reply, err := processor.ProcessTaskCommand("user", "42", []string{"T-7"})
+	require.NoError(t, err)
+	assert.Empty(t, reply)
+	processor.RememberTask("user", events.TaskSummary{ID: "task-9", Code: "T-9", Title: "Buy milk"})
+	_, err = processor.ProcessTaskCommand("user", "42", []string{"Buy milk"})
+	require.NoError(t, err)
+
+	requests := eventBus.GetPublishedEvents(events.TopicTaskDetailsRequested)
+	require.Len(t, requests, 2)
+	assert.Equal(t, 7, requests[0].(events.TaskDetailsRequested).Code, "codes are looked up even before /list")
+	assert.Equal(t, "task-9", requests[1].(events.TaskDetailsRequested).TaskID)
+
+	reply, err = processor.ProcessTaskCommand("user", "42", nil)
+	require.NoError(t, err)
+	assert.Contains(t, reply, "Usage: /task")
+}
+
+func TestKeyboardBuilder_ReminderKeyboardHasMute(t *testing.T) {
+	kb := NewKeyboardBuilder()
+
+	markup := kb.BuildReminderKeyboard("task-1", "")
+	mute := lastButtonAction(t, kb, markup)
+	assert.Equal(t, CallbackActionMute, mute.Action)
+	assert.Equal(t, "task-1", mute.Data["task_id"])
+
+	markup = kb.BuildReminderKeyboard("task-1", "https://calendar.example.com")
+	lastRow := markup.InlineKeyboard[len(markup.InlineKeyboard)-1]
+	require.Len(t, lastRow, 2)
+	assert.Equal(t, "🔕 Mute", lastRow[0].Text)
+	require.NotNil(t, lastRow[1].URL)
+}
+
+func TestFormatTaskDetails_ShowsLocation(t *testing.T) {
+	task := events.TaskSummary{ID: "task-1", Title: "Pick up package", Priority: "medium", Status: "active", Location: "post office"}
+	text := formatTaskDetails(task, fixedDates)
+	assert.Contains(t, text, "📍 post office")
+	assert.NotContains(t, text, "Open map", "no map until the place is geocoded")
+
+	task.Address = "Deutsche Post, Friedrichstraße 1, Berlin"
+	task.MapURL = "https://www.openstreetmap.org/?mlat=52.520000&mlon=13.388000#map=17/52.520000/13.388000"
+	text = formatTaskDetails(task, fixedDates)
+	assert.Contains(t, text, "<i>Deutsche Post, Friedrichstraße 1, Berlin</i>")
+	assert.Contains(t, text, `<a href="https://www.openstreetmap.org/?mlat=52.520000&amp;mlon=13.388000#map=17/52.520000/13.388000">Open map</a>`)
+}
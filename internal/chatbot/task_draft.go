@@ -0,0 +1,358 @@
+package chatbot
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// maxDraftTitleLength matches the title limit enforced when tasks are stored
+const maxDraftTitleLength = 255
+
+// draftIDLength keeps draft IDs short enough for Telegram's 64-byte callback data
+const draftIDLength = 8
+
+// Task draft errors
+var (
+	ErrInvalidDueOffset = errors.New("invalid due date offset")
+	ErrInvalidTitle     = errors.New("invalid task title")
+)
+
+// draftPriorityCycle is the order the priority button steps through
+var draftPriorityCycle = []common.Priority{
+	common.PriorityLow,
+	common.PriorityMedium,
+	common.PriorityHigh,
+	common.PriorityUrgent,
+}
+
+// TaskDraft is a parsed task kept in the user's session until it is saved or discarded
+type TaskDraft struct {
+	ID            string     `json:"id"`
+	CorrelationID string     `json:"correlation_id"`
+	Title         string     `json:"title"`
+	Description   string     `json:"description"`
+	DueDate       *time.Time `json:"due_date,omitempty"`
+	Priority      string     `json:"priority"`
+	Tags          []string   `json:"tags,omitempty"`
+	Habit         string     `json:"habit,omitempty"`
+	Kind          string     `json:"kind,omitempty"`
+	Location      string     `json:"location,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+
+	// ConfidenceLevel is how far the parse was trusted, warning the user to
+	// check low-confidence drafts
+	ConfidenceLevel string `json:"confidence_level,omitempty"`
+
+	// ListID is the shared list the task is saved into
+	ListID string `json:"list_id,omitempty"`
+
+	// MessageID is the preview message, edited in place as the draft changes
+	MessageID int `json:"message_id,omitempty"`
+}
+
+// NewTaskDraft creates a draft from a parsed task
+func NewTaskDraft(correlationID string, task events.ParsedTask) *TaskDraft {
+	return &TaskDraft{
+		ID:            string(common.NewID())[:draftIDLength],
+		CorrelationID: correlationID,
+		Title:         task.Title,
+		Description:   task.Description,
+		DueDate:       task.DueDate,
+		Priority:      task.Priority,
+		Tags:          task.Tags,
+		Habit:         task.Habit,
+		Kind:          task.Kind,
+		Location:      task.Location,
+		CreatedAt:     time.Now(),
+	}
+}
+
+// ShiftDue moves the due date forward by "1d" or "1w". A draft without a due
+// date is scheduled relative to 9am today.
+func (d *TaskDraft) ShiftDue(offset string, now time.Time) error {
+	var shift time.Duration
+	switch offset {
+	case "1d":
+		shift = 24 * time.Hour
+	case "1w":
+		shift = 7 * 24 * time.Hour
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidDueOffset, offset)
+	}
+
+	base := time.Date(now.Year(), now.Month(), now.Day(), 9, 0, 0, 0, now.Location())
+	if d.DueDate != nil {
+		base = *d.DueDate
+	}
+
+	due := base.Add(shift)
+	d.DueDate = &due
+	return nil
+}
+
+// CyclePriority steps the priority to the next level, wrapping from urgent to low
+func (d *TaskDraft) CyclePriority() {
+	for i, priority := range draftPriorityCycle {
+		if string(priority) == d.Priority {
+			d.Priority = string(draftPriorityCycle[(i+1)%len(draftPriorityCycle)])
+			return
+		}
+	}
+	d.Priority = string(common.PriorityMedium)
+}
+
+// SetTitle replaces the title after trimming and validating it
+func (d *TaskDraft) SetTitle(title string) error {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return fmt.Errorf("%w: title cannot be empty", ErrInvalidTitle)
+	}
+	if utf8.RuneCountInString(title) > maxDraftTitleLength {
+		return fmt.Errorf("%w: title exceeds %d characters", ErrInvalidTitle, maxDraftTitleLength)
+	}
+
+	d.Title = title
+	return nil
+}
+
+// ToParsedTask converts the draft back into the event payload used to save it
+func (d *TaskDraft) ToParsedTask() events.ParsedTask {
+	return events.ParsedTask{
+		Title:       d.Title,
+		Description: d.Description,
+		DueDate:     d.DueDate,
+		Priority:    d.Priority,
+		Tags:        d.Tags,
+		Habit:       d.Habit,
+		Kind:        d.Kind,
+		Location:    d.Location,
+	}
+}
+
+// FormatPreview renders the draft for the preview message
+func (d *TaskDraft) FormatPreview(dates DateFormat) string {
+	preview := fmt.Sprintf("📝 <b>New Task Preview</b>\n\n<b>Title:</b> %s\n<b>Priority:</b> %s", d.Title, d.Priority)
+
+	if d.DueDate != nil {
+		preview += fmt.Sprintf("\n<b>Due:</b> %s", dates.DateTime(*d.DueDate))
+	} else {
+		preview += "\n<b>Due:</b> <i>not set</i>"
+	}
+
+	if d.Habit != "" {
+		preview += fmt.Sprintf("\n<b>Repeats:</b> %s habit", d.Habit)
+	}
+
+	if d.ToParsedTask().IsScheduledMessage() {
+		preview += "\n<b>Sent to you</b> as a message when it is due"
+	}
+
+	if d.Location != "" {
+		preview += fmt.Sprintf("\n<b>Where:</b> 📍 %s", html.EscapeString(d.Location))
+	}
+
+	if d.Description != "" {
+		preview += fmt.Sprintf("\n<b>Description:</b> %s", d.Description)
+	}
+
+	switch d.ConfidenceLevel {
+	case events.ConfidenceLevelLow:
+		preview += "\n\n⚠️ I'm not sure I understood this. Please check the details."
+	case events.ConfidenceLevelVeryLow:
+		preview += "\n\n⚠️ This is my best guess. Please check every detail."
+	}
+
+	return preview + "\n\nAdjust the task below, then tap Save."
+}
+
+// isDraftAction reports whether a callback action belongs to the draft preview
+func isDraftAction(action string) bool {
+	switch action {
+	case CallbackActionDraftDue, CallbackActionDraftPriority, CallbackActionDraftTitle,
+		CallbackActionDraftSave, CallbackActionDraftDiscard:
+		return true
+	default:
+		return false
+	}
+}
+
+// startTaskDraft stores the parsed task as a draft and sends the preview
+func (s *chatbotService) startTaskDraft(event events.TaskParsed) {
+	draft := NewTaskDraft(event.CorrelationID, event.ParsedTask)
+	draft.ConfidenceLevel = event.ConfidenceLevel
+	draft.ListID = event.ListID
+
+	s.commandProcessor.sessionManager.UpdateSession(event.UserID, event.ChatID, func(session *ChatSession) {
+		session.Draft = draft
+		session.State = SessionStateConfirmingTask
+	})
+
+	s.showDraftPreview(event.UserID, event.ChatID, draft)
+}
+
+// showDraftPreview edits the draft's preview message in place, sending a new
+// one (and remembering its ID) when there is none yet
+func (s *chatbotService) showDraftPreview(userID, chatID string, draft *TaskDraft) {
+	chatIDInt, err := s.telegramChatID(chatID)
+	if err != nil {
+		s.logger.Error("Failed to show task draft preview",
+			zap.String("correlation_id", draft.CorrelationID),
+			zap.Error(err))
+		return
+	}
+
+	text := draft.FormatPreview(s.dateFormat(userID))
+	keyboard := s.keyboardBuilder.BuildTaskDraftKeyboard(draft.ID)
+
+	if draft.MessageID != 0 {
+		err = s.provider.EditMessageWithKeyboard(chatIDInt, draft.MessageID, text, keyboard)
+		if err == nil {
+			return
+		}
+		s.logger.Warn("Failed to edit task draft preview, sending a new one",
+			zap.String("correlation_id", draft.CorrelationID),
+			zap.Int("message_id", draft.MessageID),
+			zap.Error(err))
+	}
+
+	messageID, err := s.provider.SendTrackedMessage(chatIDInt, s.threads.Get(chatID), text, keyboard)
+	if err != nil {
+		s.logger.Error("Failed to send task draft preview",
+			zap.String("correlation_id", draft.CorrelationID),
+			zap.String("draft_id", draft.ID),
+			zap.Error(err))
+		return
+	}
+
+	s.commandProcessor.sessionManager.UpdateSession(userID, chatID, func(session *ChatSession) {
+		if session.Draft != nil && session.Draft.ID == draft.ID {
+			session.Draft.MessageID = messageID
+		}
+	})
+}
+
+// handleDraftCallback applies a preview button press to the user's draft.
+// messageID is the preview the button belongs to, 0 when Telegram doesn't say.
+func (s *chatbotService) handleDraftCallback(callbackData *CallbackData, userID, chatID string, messageID int) error {
+	var (
+		draft    TaskDraft
+		found    bool
+		response string
+		err      error
+	)
+
+	s.commandProcessor.sessionManager.UpdateSession(userID, chatID, func(session *ChatSession) {
+		if session.Draft == nil || session.Draft.ID != callbackData.Data["id"] {
+			return
+		}
+		found = true
+		if messageID != 0 {
+			session.Draft.MessageID = messageID
+		}
+
+		switch callbackData.Action {
+		case CallbackActionDraftDue:
+			err = session.Draft.ShiftDue(callbackData.Data["add"], time.Now())
+		case CallbackActionDraftPriority:
+			session.Draft.CyclePriority()
+		case CallbackActionDraftTitle:
+			session.State = SessionStateEditingTitle
+			response = "✏️ Send me the new title for this task."
+		case CallbackActionDraftSave, CallbackActionDraftDiscard:
+			draft = *session.Draft
+			session.Draft = nil
+			session.State = SessionStateIdle
+			return
+		}
+
+		draft = *session.Draft
+	})
+
+	if !found {
+		return s.SendMessage(common.ChatID(chatID), "This task preview has expired. Send the task again to create it.")
+	}
+	if err != nil {
+		return err
+	}
+	if response != "" {
+		return s.SendMessage(common.ChatID(chatID), response)
+	}
+
+	switch callbackData.Action {
+	case CallbackActionDraftSave:
+		return s.saveTaskDraft(&draft, userID, chatID)
+	case CallbackActionDraftDiscard:
+		return s.SendMessage(common.ChatID(chatID), "🗑 Task discarded.")
+	}
+
+	s.showDraftPreview(userID, chatID, &draft)
+	return nil
+}
+
+// handleDraftTitleEdit uses a follow-up message as the draft's new title. It
+// returns false when the user is not editing a draft title.
+func (s *chatbotService) handleDraftTitleEdit(userID, chatID, text string) (bool, error) {
+	var (
+		draft   TaskDraft
+		editing bool
+		err     error
+	)
+
+	s.commandProcessor.sessionManager.UpdateSession(userID, chatID, func(session *ChatSession) {
+		if session.State != SessionStateEditingTitle || session.Draft == nil {
+			return
+		}
+		editing = true
+
+		if err = session.Draft.SetTitle(text); err != nil {
+			return
+		}
+		session.State = SessionStateConfirmingTask
+		draft = *session.Draft
+	})
+
+	if !editing {
+		return false, nil
+	}
+	if err != nil {
+		return true, s.SendMessage(common.ChatID(chatID), fmt.Sprintf("That title can't be used. Please send a non-empty title up to %d characters.", maxDraftTitleLength))
+	}
+
+	s.showDraftPreview(userID, chatID, &draft)
+	return true, nil
+}
+
+// saveTaskDraft publishes the confirmed draft so the nudge service stores it
+func (s *chatbotService) saveTaskDraft(draft *TaskDraft, userID, chatID string) error {
+	event := events.TaskParsed{
+		Event:      events.NewEvent(),
+		UserID:     userID,
+		ChatID:     chatID,
+		ParsedTask: draft.ToParsedTask(),
+		ListID:     draft.ListID,
+		ThreadID:   s.threads.Get(chatID),
+	}
+
+	s.logger.Info("Saving confirmed task draft",
+		zap.String("correlation_id", draft.CorrelationID),
+		zap.String("draft_id", draft.ID),
+		zap.String("user_id", userID))
+
+	if err := s.eventBus.Publish(events.TopicTaskParsed, event); err != nil {
+		s.logger.Error("Failed to publish confirmed task draft",
+			zap.String("correlation_id", draft.CorrelationID),
+			zap.Error(err))
+		return err
+	}
+
+	return nil
+}
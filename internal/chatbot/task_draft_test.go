@@ -0,0 +1,139 @@
+package chatbot
+
+import (
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/events"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestTaskDraft_ShiftDue(t *testing.T) {
+	now := time.Date(2024, 3, 4, 15, 30, 0, 0, time.UTC)
+
+	t.Run("draft without due date starts from 9am today", func(t *testing.T) {
+		draft := NewTaskDraft("corr", events.ParsedTask{Title: "Call mom", Priority: "medium"})
+
+		require.NoError(t, draft.ShiftDue("1d", now))
+		require.NotNil(t, draft.DueDate)
+		assert.Equal(t, time.Date(2024, 3, 5, 9, 0, 0, 0, time.UTC), *draft.DueDate)
+	})
+
+	t.Run("existing due date is moved forward", func(t *testing.T) {
+		due := time.Date(2024, 3, 6, 14, 0, 0, 0, time.UTC)
+		draft := NewTaskDraft("corr", events.ParsedTask{Title: "Report", Priority: "high", DueDate: &due})
+
+		require.NoError(t, draft.ShiftDue("1w", now))
+		assert.Equal(t, time.Date(2024, 3, 13, 14, 0, 0, 0, time.UTC), *draft.DueDate)
+	})
+
+	t.Run("unknown offset is rejected", func(t *testing.T) {
+		draft := NewTaskDraft("corr", events.ParsedTask{Title: "Report", Priority: "high"})
+
+		assert.ErrorIs(t, draft.ShiftDue("1y", now), ErrInvalidDueOffset)
+		assert.Nil(t, draft.DueDate)
+	})
+}
+
+func TestTaskDraft_CyclePriority(t *testing.T) {
+	draft := NewTaskDraft("corr", events.ParsedTask{Title: "Report", Priority: "high"})
+
+	draft.CyclePriority()
+	assert.Equal(t, "urgent", draft.Priority)
+
+	draft.CyclePriority()
+	assert.Equal(t, "low", draft.Priority)
+
+	draft.Priority = "unknown"
+	draft.CyclePriority()
+	assert.Equal(t, "medium", draft.Priority)
+}
+
+func TestTaskDraft_SetTitle(t *testing.T) {
+	draft := NewTaskDraft("corr", events.ParsedTask{Title: "Report", Priority: "high"})
+
+	require.NoError(t, draft.SetTitle("  Quarterly report  "))
+	assert.Equal(t, "Quarterly report", draft.Title)
+	assert.Equal(t, "Quarterly report", draft.ToParsedTask().Title)
+
+	assert.ErrorIs(t, draft.SetTitle("   "), ErrInvalidTitle)
+	assert.Equal(t, "Quarterly report", draft.Title)
+}
+
+func TestTaskDraft_FormatPreviewWarnsOnLowConfidence(t *testing.T) {
+	draft := NewTaskDraft("corr", events.ParsedTask{Title: "Report", Priority: "high"})
+	assert.NotContains(t, draft.FormatPreview(fixedDates), "⚠️")
+
+	draft.ConfidenceLevel = events.ConfidenceLevelMedium
+	assert.NotContains(t, draft.FormatPreview(fixedDates), "⚠️")
+
+	draft.ConfidenceLevel = events.ConfidenceLevelLow
+	assert.Contains(t, draft.FormatPreview(fixedDates), "check the details")
+
+	draft.ConfidenceLevel = events.ConfidenceLevelVeryLow
+	assert.Contains(t, draft.FormatPreview(fixedDates), "best guess")
+}
+
+func TestKeyboardBuilder_TaskDraftCallbacksFitTelegramLimit(t *testing.T) {
+	kb := NewKeyboardBuilder()
+	keyboard := kb.BuildTaskDraftKeyboard("abcd1234")
+
+	for _, row := range keyboard.InlineKeyboard {
+		for _, button := range row {
+			require.NotNil(t, button.CallbackData)
+			data, err := kb.DecodeCallbackData(*button.CallbackData)
+			require.NoError(t, err)
+			assert.Equal(t, "abcd1234", data.Data["id"], "button %q lost its draft ID", button.Text)
+		}
+	}
+}
+
+// editRecordingProvider records which messages were edited
+type editRecordingProvider struct {
+	*discardProvider
+	edited []int
+}
+
+func (p *editRecordingProvider) EditMessageWithKeyboard(chatID int64, messageID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
+	p.edited = append(p.edited, messageID)
+	return nil
+}
+
+func TestChatbotService_DraftPreviewIsEditedInPlace(t *testing.T) {
+	bus := events.NewMockEventBus()
+	bus.SetSynchronousMode(true)
+	service, discard := newBenchService(bus, zap.NewNop())
+	provider := &editRecordingProvider{discardProvider: discard}
+	service.provider = provider
+
+	service.startTaskDraft(events.TaskParsed{
+		Event:      events.NewEvent(),
+		UserID:     "user-1",
+		ChatID:     "100",
+		ParsedTask: events.ParsedTask{Title: "Report", Priority: "high"},
+	})
+	require.EqualValues(t, 1, discard.sent.Load())
+
+	session, ok := service.commandProcessor.sessionManager.GetSession("user-1")
+	require.True(t, ok)
+	require.NotNil(t, session.Draft)
+	draftID := session.Draft.ID
+	assert.Equal(t, 1, session.Draft.MessageID)
+
+	priority := &CallbackData{Action: CallbackActionDraftPriority, Data: map[string]string{"id": draftID}}
+	require.NoError(t, service.handleDraftCallback(priority, "user-1", "100", 1))
+
+	title := &CallbackData{Action: CallbackActionDraftTitle, Data: map[string]string{"id": draftID}}
+	require.NoError(t, service.handleDraftCallback(title, "user-1", "100", 1))
+	handled, err := service.handleDraftTitleEdit("user-1", "100", "Quarterly report")
+	require.NoError(t, err)
+	require.True(t, handled)
+
+	assert.Equal(t, []int{1, 1}, provider.edited)
+	// only the initial preview and the title prompt were sent as new messages
+	assert.EqualValues(t, 2, discard.sent.Load())
+}
@@ -0,0 +1,99 @@
+package chatbot
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// maxHistoryEntries caps the timeline shown in chat; older entries are summarized
+const maxHistoryEntries = 15
+
+// historyIcons gives each history entry kind a compact marker
+var historyIcons = map[string]string{
+	"created":        "🆕",
+	"status_changed": "🔄",
+	"snoozed":        "😴",
+	"nudge_sent":     "🔔",
+	"edited":         "✏️",
+	"habit_logged":   "🔥",
+	"habit_missed":   "💤",
+}
+
+// formatTaskHistory renders a task's timeline as one line per entry, newest last
+func formatTaskHistory(title string, entries []events.TaskHistoryEntry, dates DateFormat) string {
+	var text strings.Builder
+	text.WriteString("🕘 <b>History</b>")
+	if title != "" {
+		text.WriteString(": " + html.EscapeString(title))
+	}
+	text.WriteString("\n")
+
+	if len(entries) == 0 {
+		text.WriteString("\nNothing recorded yet.")
+		return text.String()
+	}
+
+	if hidden := len(entries) - maxHistoryEntries; hidden > 0 {
+		text.WriteString(fmt.Sprintf("\n<i>%d earlier entries not shown</i>", hidden))
+		entries = entries[hidden:]
+	}
+
+	for _, entry := range entries {
+		icon, ok := historyIcons[entry.Kind]
+		if !ok {
+			icon = "•"
+		}
+		text.WriteString(fmt.Sprintf("\n%s %s %s", icon, dates.DateTime(entry.OccurredAt), describeHistoryEntry(entry)))
+	}
+	return text.String()
+}
+
+// describeHistoryEntry returns the short description of one history entry
+func describeHistoryEntry(entry events.TaskHistoryEntry) string {
+	switch entry.Kind {
+	case "created":
+		return "created"
+	case "status_changed":
+		if entry.FromStatus != "" {
+			return fmt.Sprintf("%s → %s", entry.FromStatus, entry.ToStatus)
+		}
+		return entry.ToStatus
+	case "snoozed":
+		return strings.TrimSpace("snoozed " + entry.Detail)
+	case "nudge_sent":
+		return "nudge sent"
+	case "edited":
+		return "edited " + html.EscapeString(entry.Detail)
+	case "habit_logged":
+		return strings.TrimSpace("done, " + entry.Detail)
+	case "habit_missed":
+		return strings.TrimSpace("missed, " + entry.Detail)
+	default:
+		return entry.Kind
+	}
+}
+
+// handleTaskHistoryResponse sends a requested task timeline to the chat
+func (s *chatbotService) handleTaskHistoryResponse(event events.TaskHistoryResponse) {
+	if !s.ownsUser(event.UserID) {
+		return
+	}
+
+	messageText := formatTaskHistory(event.TaskTitle, event.Entries, s.dateFormat(event.UserID))
+	if !event.Success {
+		messageText = fmt.Sprintf("❌ <b>History Unavailable</b>\n\n%s", event.Message)
+	}
+
+	if err := s.SendMessage(common.ChatID(event.ChatID), messageText); err != nil {
+		s.logger.Error("Failed to send task history",
+			zap.String("correlation_id", event.CorrelationID),
+			zap.String("task_id", event.TaskID),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,45 @@
+package chatbot
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatTaskHistory(t *testing.T) {
+	at := time.Date(2024, 1, 2, 9, 30, 0, 0, time.UTC)
+
+	t.Run("one line per entry", func(t *testing.T) {
+		text := formatTaskHistory("Pay <rent>", []events.TaskHistoryEntry{
+			{Kind: "created", OccurredAt: at},
+			{Kind: "snoozed", Detail: "until 2024-01-02T11:00:00Z", OccurredAt: at},
+			{Kind: "status_changed", FromStatus: "active", ToStatus: "completed", OccurredAt: at},
+		}, fixedDates)
+
+		assert.Contains(t, text, "Pay &lt;rent&gt;")
+		assert.Contains(t, text, "🆕 Jan 2 9:30 created")
+		assert.Contains(t, text, "😴 Jan 2 9:30 snoozed until")
+		assert.Contains(t, text, "active → completed")
+	})
+
+	t.Run("long timelines keep the latest entries", func(t *testing.T) {
+		var entries []events.TaskHistoryEntry
+		for i := 0; i < maxHistoryEntries+5; i++ {
+			entries = append(entries, events.TaskHistoryEntry{Kind: "edited", Detail: fmt.Sprintf("field%d", i), OccurredAt: at})
+		}
+
+		text := formatTaskHistory("", entries, fixedDates)
+		assert.Contains(t, text, "5 earlier entries not shown")
+		assert.NotContains(t, text, "field4\n")
+		assert.True(t, strings.HasSuffix(text, fmt.Sprintf("field%d", maxHistoryEntries+4)))
+	})
+
+	t.Run("empty history", func(t *testing.T) {
+		assert.Contains(t, formatTaskHistory("Task", nil, fixedDates), "Nothing recorded yet.")
+	})
+}
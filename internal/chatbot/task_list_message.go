@@ -0,0 +1,281 @@
+package chatbot
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// TasksPerPage is the number of tasks shown on one page of the task list
+const TasksPerPage = 5
+
+// listMessage is the task list message currently shown in a chat
+type listMessage struct {
+	MessageID int
+	Page      int
+	Tasks     []events.TaskSummary
+}
+
+// ListMessageTracker remembers the last task list message per chat so refreshes
+// and page changes edit it in place instead of posting a new message
+type ListMessageTracker struct {
+	mu       sync.Mutex
+	messages map[string]listMessage
+}
+
+// NewListMessageTracker creates an empty tracker
+func NewListMessageTracker() *ListMessageTracker {
+	return &ListMessageTracker{
+		messages: make(map[string]listMessage),
+	}
+}
+
+// Get returns the tracked list message for a chat
+func (t *ListMessageTracker) Get(chatID string) (listMessage, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	message, exists := t.messages[chatID]
+	return message, exists
+}
+
+// Set records the list message shown in a chat
+func (t *ListMessageTracker) Set(chatID string, message listMessage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.messages[chatID] = message
+}
+
+// UpdateTask applies a change to a task of the chat's tracked list, so task
+// details opened from the list stay current until the list is refreshed
+func (t *ListMessageTracker) UpdateTask(chatID, taskID string, update func(task *events.TaskSummary)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	message, exists := t.messages[chatID]
+	if !exists {
+		return
+	}
+	for i := range message.Tasks {
+		if message.Tasks[i].ID == taskID {
+			update(&message.Tasks[i])
+		}
+	}
+}
+
+// Delete forgets the list message of a chat so the next list is sent as a new message
+func (t *ListMessageTracker) Delete(chatID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.messages, chatID)
+}
+
+// totalPages returns the number of list pages needed for a task count
+func totalPages(taskCount int) int {
+	if taskCount == 0 {
+		return 1
+	}
+	return (taskCount + TasksPerPage - 1) / TasksPerPage
+}
+
+// showTaskList renders one page of the task list, editing messageID or else
+// the chat's tracked list message, and sending a new message when there is
+// nothing to edit or the edit fails
+func (s *chatbotService) showTaskList(userID, chatID string, tasks []events.TaskSummary, page, messageID int) error {
+	pages := totalPages(len(tasks))
+	if page < 0 {
+		page = 0
+	}
+	if page >= pages {
+		page = pages - 1
+	}
+
+	text := formatTaskListPage(tasks, page, pages, s.dateFormat(userID))
+
+	keyboard := tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{}}
+	if len(tasks) > 0 {
+		keyboardTasks := make([]TaskSummary, len(tasks))
+		for i, task := range tasks {
+			keyboardTasks[i] = TaskSummary{
+				ID:      common.TaskID(task.ID),
+				Title:   task.Title,
+				DueDate: task.DueDate,
+				Status:  task.Status,
+			}
+		}
+		keyboard = s.keyboardBuilder.BuildTaskListKeyboard(keyboardTasks, page, pages)
+	}
+
+	chatIDInt, err := s.telegramChatID(chatID)
+	if err != nil {
+		return err
+	}
+
+	if messageID == 0 {
+		if tracked, exists := s.listMessages.Get(chatID); exists {
+			messageID = tracked.MessageID
+		}
+	}
+
+	if messageID != 0 {
+		err := s.provider.EditMessageWithKeyboard(chatIDInt, messageID, text, keyboard)
+		if err == nil {
+			s.listMessages.Set(chatID, listMessage{MessageID: messageID, Page: page, Tasks: tasks})
+			return nil
+		}
+
+		// The message may have been deleted or be too old to edit
+		s.logger.Warn("Failed to edit task list message, sending a new one",
+			zap.String("chat_id", chatID),
+			zap.Int("message_id", messageID),
+			zap.Error(err))
+	}
+
+	messageID, err = s.provider.SendTrackedMessage(chatIDInt, s.threads.Get(chatID), text, keyboard)
+	if err != nil {
+		return err
+	}
+
+	s.listMessages.Set(chatID, listMessage{MessageID: messageID, Page: page, Tasks: tasks})
+	return nil
+}
+
+// handleListPageCallback moves the list message messageID (0 when Telegram
+// doesn't say) to another page. The tasks are fetched again so the page shows
+// the current list; while degraded the tracked list is paged instead.
+func (s *chatbotService) handleListPageCallback(callbackData *CallbackData, userID, chatID string, messageID int) error {
+	tracked, exists := s.listMessages.Get(chatID)
+
+	page, err := strconv.Atoi(callbackData.Data["page"])
+	if err != nil {
+		page = tracked.Page
+	}
+
+	if exists && s.load.Degraded() {
+		return s.showTaskList(userID, chatID, tracked.Tasks, page, messageID)
+	}
+
+	s.commandProcessor.requestTaskList(userID, chatID, page, messageID)
+	return nil
+}
+
+// taskStatusLabels marks list entries whose status is not plain active
+var taskStatusLabels = map[string]string{
+	"in_progress": "▶️ In progress",
+	"waiting":     "⏳ Waiting",
+}
+
+// formatPriority capitalizes a priority for display
+func formatPriority(priority string) string {
+	if priority == "" {
+		return ""
+	}
+	return strings.ToUpper(priority[:1]) + strings.ToLower(priority[1:])
+}
+
+// formatCustomFields renders a task's custom fields as one indented line each, sorted by key
+func formatCustomFields(fields map[string]string) string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var text strings.Builder
+	for _, key := range keys {
+		text.WriteString(fmt.Sprintf("\n   🔖 %s: %s", key, html.EscapeString(fields[key])))
+	}
+	return text.String()
+}
+
+// maxLinkPreviewLength caps the visible part of a link preview
+const maxLinkPreviewLength = 40
+
+// formatLinkPreviews renders each link as a compact, clickable host and path
+func formatLinkPreviews(links []string) string {
+	var text strings.Builder
+	for _, link := range links {
+		text.WriteString(fmt.Sprintf("\n   🔗 <a href=\"%s\">%s</a>", html.EscapeString(link), html.EscapeString(linkPreview(link))))
+	}
+	return text.String()
+}
+
+// linkPreview shortens a URL to its host and path, without "www."
+func linkPreview(link string) string {
+	parsed, err := url.Parse(link)
+	if err != nil || parsed.Host == "" {
+		return truncateText(link, maxLinkPreviewLength)
+	}
+	preview := strings.TrimPrefix(parsed.Host, "www.") + parsed.Path
+	return truncateText(preview, maxLinkPreviewLength)
+}
+
+// formatTaskListPage formats the tasks on one page of the task list
+func formatTaskListPage(tasks []events.TaskSummary, page, pages int, dates DateFormat) string {
+	if len(tasks) == 0 {
+		return "📝 <b>Your Task List</b>\n\nYou have no active tasks. Great job! 🎉\n\nSend me a message to create a new task."
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("📝 <b>Your Task List</b>\n\nYou have %d active task(s):", len(tasks)))
+	if pages > 1 {
+		builder.WriteString(fmt.Sprintf(" <i>(page %d/%d)</i>", page+1, pages))
+	}
+	builder.WriteString("\n\n")
+
+	start := page * TasksPerPage
+	end := start + TasksPerPage
+	if end > len(tasks) {
+		end = len(tasks)
+	}
+
+	for i := start; i < end; i++ {
+		task := tasks[i]
+		// Format task entry
+		taskEntry := fmt.Sprintf("<b>%d.</b> %s%s\n   🏷 <i>%s Priority</i>", i+1, formatTaskCode(task.Code), html.EscapeString(task.Title), formatPriority(task.Priority))
+		if label, ok := taskStatusLabels[task.Status]; ok {
+			taskEntry += " · " + label
+		}
+		if task.Muted {
+			taskEntry += " · 🔕 Muted"
+		}
+		if task.Habit != "" {
+			taskEntry += "\n   " + formatHabit(task)
+		}
+		if task.List != "" {
+			taskEntry += "\n   👥 " + html.EscapeString(task.List)
+		}
+
+		if task.Description != "" {
+			taskEntry += fmt.Sprintf("\n   📝 %s", html.EscapeString(task.Description))
+		}
+
+		if task.DueDate != nil {
+			dueText := dates.DateTime(*task.DueDate)
+			if task.IsOverdue {
+				taskEntry += fmt.Sprintf("\n   ⏰ <b>OVERDUE:</b> %s", dueText)
+			} else {
+				taskEntry += fmt.Sprintf("\n   📅 Due: %s", dueText)
+			}
+		}
+
+		taskEntry += formatCustomFields(task.CustomFields)
+		taskEntry += formatLinkPreviews(task.Links)
+
+		builder.WriteString(taskEntry + "\n\n")
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,147 @@
+package chatbot
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"nudgebot-api/internal/events"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// listRecordingProvider records plain sends, tracked sends and edits
+type listRecordingProvider struct {
+	TelegramProvider
+	messages  []string
+	sent      []string
+	edited    map[int]string
+	editError error
+}
+
+func (p *listRecordingProvider) SendMessage(chatID int64, threadID int, text string) error {
+	p.messages = append(p.messages, text)
+	return nil
+}
+
+func (p *listRecordingProvider) SendTrackedMessage(chatID int64, threadID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) (int, error) {
+	p.sent = append(p.sent, text)
+	return 100 + len(p.sent), nil
+}
+
+func (p *listRecordingProvider) EditMessageWithKeyboard(chatID int64, messageID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
+	if p.editError != nil {
+		return p.editError
+	}
+	p.edited[messageID] = text
+	return nil
+}
+
+func newListTestService(t *testing.T) (*chatbotService, *listRecordingProvider) {
+	provider := &listRecordingProvider{edited: make(map[int]string)}
+	return &chatbotService{
+		logger:           zaptest.NewLogger(t),
+		provider:         provider,
+		keyboardBuilder:  NewKeyboardBuilder(),
+		listMessages:     NewListMessageTracker(),
+		load:             newLoadShedState(),
+		commandProcessor: NewCommandProcessor(nil, zaptest.NewLogger(t)),
+	}, provider
+}
+
+func listResponse(taskCount int) events.TaskListResponse {
+	tasks := make([]events.TaskSummary, taskCount)
+	for i := range tasks {
+		tasks[i] = events.TaskSummary{ID: fmt.Sprintf("task-%d", i), Title: fmt.Sprintf("Task %d", i+1), Priority: "medium", Status: "active"}
+	}
+	return events.TaskListResponse{Event: events.NewEvent(), UserID: "user", ChatID: "42", Tasks: tasks, Success: true}
+}
+
+func TestTaskListMessage_RefreshEditsInPlace(t *testing.T) {
+	service, provider := newListTestService(t)
+
+	service.handleTaskListResponse(listResponse(2))
+	service.handleTaskListResponse(listResponse(3))
+
+	require.Len(t, provider.sent, 1, "the second /list must not post a new message")
+	assert.Contains(t, provider.edited[101], "3 active task(s)")
+}
+
+func TestTaskListMessage_PaginationReloadsTasks(t *testing.T) {
+	service, provider := newListTestService(t)
+	bus := events.NewMockEventBus()
+	bus.SetSynchronousMode(true)
+	service.commandProcessor = NewCommandProcessor(bus, zaptest.NewLogger(t))
+	service.handleTaskListResponse(listResponse(7))
+	assert.Contains(t, provider.sent[0], "page 1/2")
+
+	err := service.handleListPageCallback(&CallbackData{Action: CallbackActionNextPage, Data: map[string]string{"page": "1"}}, "user", "42", 101)
+	require.NoError(t, err)
+
+	published := bus.GetPublishedEvents(events.TopicTaskListRequested)
+	require.Len(t, published, 1, "a page change fetches the current tasks")
+	request := published[0].(events.TaskListRequested)
+	assert.Equal(t, 1, request.Page)
+	assert.Equal(t, 101, request.MessageID)
+
+	// A task was added since the list was first shown
+	response := listResponse(8)
+	response.Page, response.MessageID = request.Page, request.MessageID
+	service.handleTaskListResponse(response)
+
+	require.Len(t, provider.sent, 1)
+	assert.Contains(t, provider.edited[101], "page 2/2")
+	assert.Contains(t, provider.edited[101], "Task 8")
+	assert.NotContains(t, provider.edited[101], "Task 1\n")
+}
+
+func TestTaskListMessage_PaginationEditsPressedMessage(t *testing.T) {
+	service, provider := newListTestService(t)
+
+	response := listResponse(7)
+	response.Page, response.MessageID = 1, 55
+	service.handleTaskListResponse(response)
+
+	require.Empty(t, provider.sent, "the pressed message is edited even when the chat has no tracked list")
+	assert.Contains(t, provider.edited[55], "page 2/2")
+	tracked, exists := service.listMessages.Get("42")
+	require.True(t, exists)
+	assert.Equal(t, 55, tracked.MessageID)
+}
+
+func TestTaskListMessage_FallsBackWhenEditFails(t *testing.T) {
+	service, provider := newListTestService(t)
+	service.handleTaskListResponse(listResponse(1))
+
+	provider.editError = errors.New("message to edit not found")
+	service.handleTaskListResponse(listResponse(1))
+
+	require.Len(t, provider.sent, 2)
+	tracked, exists := service.listMessages.Get("42")
+	require.True(t, exists)
+	assert.Equal(t, 102, tracked.MessageID, "the new message becomes the tracked one")
+}
+
+func TestFormatTaskListPage_EscapesTitles(t *testing.T) {
+	tasks := []events.TaskSummary{{ID: "1", Title: "Fix <b>bold</b> & co", Description: "a < b", Priority: "medium"}}
+
+	text := formatTaskListPage(tasks, 0, 1, fixedDates)
+	assert.Contains(t, text, "Fix &lt;b&gt;bold&lt;/b&gt; &amp; co")
+	assert.Contains(t, text, "a &lt; b")
+}
+
+func TestFormatTaskListPage_ShowsLinkPreviews(t *testing.T) {
+	tasks := []events.TaskSummary{{
+		ID:       "1",
+		Title:    "Read this article",
+		Priority: "medium",
+		Links:    []string{"https://www.example.com/blog/2024/a-very-long-article-slug-about-go-generics"},
+	}}
+
+	text := formatTaskListPage(tasks, 0, 1, fixedDates)
+	assert.Contains(t, text, `<a href="https://www.example.com/blog/2024/a-very-long-article-slug-about-go-generics">`)
+	assert.Contains(t, text, "example.com/blog/2024/a-very-long-art...</a>")
+}
@@ -0,0 +1,145 @@
+package chatbot
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// PendingReschedule is a bulk reschedule preview kept in the user's session
+// until it is confirmed or cancelled. Only the previewed tasks are moved.
+type PendingReschedule struct {
+	ID       string   `json:"id"`
+	Command  string   `json:"command"`
+	Argument string   `json:"argument"`
+	TaskIDs  []string `json:"task_ids"`
+}
+
+// isRescheduleAction reports whether a callback action belongs to a reschedule preview
+func isRescheduleAction(action string) bool {
+	return action == CallbackActionRescheduleApply || action == CallbackActionRescheduleCancel
+}
+
+// formatReschedulePreview lists the tasks a bulk reschedule would move, with their old and new due dates
+func formatReschedulePreview(event events.TaskRescheduleResponse, dates DateFormat) string {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("🗓 <b>Move %d tasks?</b>\n", len(event.Tasks)))
+
+	for _, task := range event.Tasks {
+		if task.DueDate == nil {
+			continue
+		}
+		moved := task.DueDate.Add(event.Shift)
+		if event.ShiftDays != 0 {
+			moved = task.DueDate.In(dates.Location()).AddDate(0, 0, event.ShiftDays)
+		}
+		text.WriteString(fmt.Sprintf("\n• %s\n  %s → %s", html.EscapeString(task.Title),
+			dates.DateTime(*task.DueDate), dates.DateTime(moved)))
+	}
+
+	text.WriteString("\n\nConfirm to move them, or cancel to keep your plan.")
+	return text.String()
+}
+
+// formatRescheduleSummary lists the tasks a confirmed bulk reschedule moved
+func formatRescheduleSummary(event events.TaskRescheduleResponse, dates DateFormat) string {
+	if len(event.Tasks) == 0 {
+		return "Nothing was moved; those tasks were already done or rescheduled."
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("✅ <b>Moved %d tasks</b>\n", len(event.Tasks)))
+	for _, task := range event.Tasks {
+		due := ""
+		if task.DueDate != nil {
+			due = " — due " + dates.DateTime(*task.DueDate)
+		}
+		text.WriteString(fmt.Sprintf("\n• %s%s", html.EscapeString(task.Title), due))
+	}
+	return text.String()
+}
+
+// handleTaskRescheduleResponse sends a bulk reschedule preview for confirmation,
+// or the summary once the tasks have been moved
+func (s *chatbotService) handleTaskRescheduleResponse(event events.TaskRescheduleResponse) {
+	if !s.ownsUser(event.UserID) {
+		return
+	}
+
+	chatID := common.ChatID(event.ChatID)
+	var err error
+
+	switch {
+	case !event.Success:
+		err = s.SendMessage(chatID, fmt.Sprintf("❌ <b>Reschedule Failed</b>\n\n%s", html.EscapeString(event.Message)))
+	case event.Applied:
+		err = s.SendMessage(chatID, formatRescheduleSummary(event, s.dateFormat(event.UserID)))
+	case len(event.Tasks) == 0:
+		err = s.SendMessage(chatID, "🎉 Nothing left due today.")
+	default:
+		pending := &PendingReschedule{
+			ID:       string(common.NewID())[:draftIDLength],
+			Command:  event.Command,
+			Argument: event.Argument,
+		}
+		for _, task := range event.Tasks {
+			pending.TaskIDs = append(pending.TaskIDs, task.ID)
+		}
+
+		s.commandProcessor.sessionManager.UpdateSession(event.UserID, event.ChatID, func(session *ChatSession) {
+			session.PendingReschedule = pending
+		})
+
+		keyboard := s.keyboardBuilder.ToDomainKeyboard(s.keyboardBuilder.BuildRescheduleKeyboard(pending.ID))
+		err = s.SendMessageWithKeyboard(chatID, formatReschedulePreview(event, s.dateFormat(event.UserID)), keyboard)
+	}
+
+	if err != nil {
+		s.logger.Error("Failed to send reschedule message",
+			zap.String("correlation_id", event.CorrelationID),
+			zap.String("command", event.Command),
+			zap.Error(err))
+	}
+}
+
+// handleRescheduleCallback applies or discards the user's pending reschedule
+func (s *chatbotService) handleRescheduleCallback(callbackData *CallbackData, userID, chatID string) error {
+	var pending *PendingReschedule
+
+	s.commandProcessor.sessionManager.UpdateSession(userID, chatID, func(session *ChatSession) {
+		if session.PendingReschedule == nil || session.PendingReschedule.ID != callbackData.Data["id"] {
+			return
+		}
+		pending = session.PendingReschedule
+		session.PendingReschedule = nil
+	})
+
+	if pending == nil {
+		return s.SendMessage(common.ChatID(chatID), "This preview has expired. Send the command again to reschedule.")
+	}
+	if callbackData.Action == CallbackActionRescheduleCancel {
+		return s.SendMessage(common.ChatID(chatID), "❌ Reschedule cancelled. Your tasks were not changed.")
+	}
+
+	rescheduleEvent := events.TaskRescheduleRequested{
+		Event:    events.NewEvent(),
+		UserID:   userID,
+		ChatID:   chatID,
+		Command:  pending.Command,
+		Argument: pending.Argument,
+		TaskIDs:  pending.TaskIDs,
+	}
+	if err := s.eventBus.Publish(events.TopicTaskRescheduleRequested, rescheduleEvent); err != nil {
+		s.logger.Error("Failed to publish confirmed reschedule",
+			zap.String("user_id", userID),
+			zap.Error(err))
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package chatbot
+
+import (
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatReschedulePreview(t *testing.T) {
+	due := time.Date(2024, 1, 2, 9, 30, 0, 0, time.UTC)
+	event := events.TaskRescheduleResponse{
+		Shift: 24 * time.Hour,
+		Tasks: []events.TaskSummary{
+			{ID: "1", Title: "Pay <rent>", DueDate: &due},
+			{ID: "2", Title: "Stretch", DueDate: &due},
+		},
+	}
+
+	text := formatReschedulePreview(event, fixedDates)
+	assert.Contains(t, text, "Move 2 tasks?")
+	assert.Contains(t, text, "Pay &lt;rent&gt;")
+	assert.Contains(t, text, "Jan 2 9:30 → Jan 3 9:30")
+
+	moved := due.Add(event.Shift)
+	event.Applied = true
+	event.Tasks[0].DueDate = &moved
+	summary := formatRescheduleSummary(event, fixedDates)
+	assert.Contains(t, summary, "Moved 2 tasks")
+	assert.Contains(t, summary, "Pay &lt;rent&gt; — due Jan 3 9:30")
+}
+
+func TestFormatReschedulePreview_CalendarDaysKeepTimeOfDay(t *testing.T) {
+	dates := NewDateFormat("Europe/Berlin", "", time.Date(2024, 3, 29, 12, 0, 0, 0, time.UTC))
+	// 9:00 in Berlin, two days before clocks go forward
+	due := time.Date(2024, 3, 29, 8, 0, 0, 0, time.UTC)
+	event := events.TaskRescheduleResponse{
+		ShiftDays: 3,
+		Tasks:     []events.TaskSummary{{ID: "1", Title: "Stand-up", DueDate: &due}},
+	}
+
+	text := formatReschedulePreview(event, dates)
+	assert.Contains(t, text, "→ Mon 9:00")
+}
@@ -1,15 +1,28 @@
 package chatbot
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"nudgebot-api/internal/config"
+	"nudgebot-api/internal/httpclient"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"go.uber.org/zap"
 )
 
+// allowedUpdates are the update types the bot handles. Telegram only sends
+// message_reaction updates when they are asked for; my_chat_member tells when
+// a user blocks or unblocks the bot.
+var allowedUpdates = []string{"message", "callback_query", "message_reaction", "my_chat_member"}
+
 // telegramProvider implements the TelegramProvider interface using the telegram-bot-api library
 type telegramProvider struct {
 	bot    *tgbotapi.BotAPI
@@ -19,11 +32,25 @@ type telegramProvider struct {
 
 // NewTelegramProvider creates a new TelegramProvider instance
 func NewTelegramProvider(config config.ChatbotConfig, logger *zap.Logger) (TelegramProvider, error) {
+	return NewTelegramProviderWithClient(config, nil, logger)
+}
+
+// NewTelegramProviderWithClient creates a TelegramProvider making its Bot API
+// calls through httpClient. A nil client uses the library's default client.
+func NewTelegramProviderWithClient(config config.ChatbotConfig, httpClient httpclient.Doer, logger *zap.Logger) (TelegramProvider, error) {
 	if config.Token == "" {
 		return nil, fmt.Errorf("telegram bot token is required")
 	}
+	if config.WebhookCertificate != "" {
+		if _, err := os.Stat(config.WebhookCertificate); err != nil {
+			return nil, fmt.Errorf("webhook certificate is unreadable: %w", err)
+		}
+	}
 
-	bot, err := tgbotapi.NewBotAPI(config.Token)
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+	bot, err := tgbotapi.NewBotAPIWithClient(config.Token, tgbotapi.APIEndpoint, httpClient)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create telegram bot: %w", err)
 	}
@@ -47,18 +74,16 @@ func NewTelegramProvider(config config.ChatbotConfig, logger *zap.Logger) (Teleg
 }
 
 // SendMessage sends a plain text message to the specified chat
-func (p *telegramProvider) SendMessage(chatID int64, text string) error {
+func (p *telegramProvider) SendMessage(chatID int64, threadID int, text string) error {
 	correlationID := fmt.Sprintf("msg_%d_%d", chatID, time.Now().Unix())
 
 	p.logger.Debug("Sending message",
 		zap.String("correlation_id", correlationID),
 		zap.Int64("chat_id", chatID),
+		zap.Int("thread_id", threadID),
 		zap.Int("text_length", len(text)))
 
-	msg := tgbotapi.NewMessage(chatID, text)
-	msg.ParseMode = tgbotapi.ModeHTML
-
-	_, err := p.bot.Send(msg)
+	_, err := p.sendMessage(chatID, threadID, text, nil)
 	if err != nil {
 		p.logger.Error("Failed to send message",
 			zap.String("correlation_id", correlationID),
@@ -75,20 +100,17 @@ func (p *telegramProvider) SendMessage(chatID int64, text string) error {
 }
 
 // SendMessageWithKeyboard sends a message with an inline keyboard
-func (p *telegramProvider) SendMessageWithKeyboard(chatID int64, text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
+func (p *telegramProvider) SendMessageWithKeyboard(chatID int64, threadID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
 	correlationID := fmt.Sprintf("kbd_%d_%d", chatID, time.Now().Unix())
 
 	p.logger.Debug("Sending message with keyboard",
 		zap.String("correlation_id", correlationID),
 		zap.Int64("chat_id", chatID),
+		zap.Int("thread_id", threadID),
 		zap.Int("text_length", len(text)),
 		zap.Int("keyboard_rows", len(keyboard.InlineKeyboard)))
 
-	msg := tgbotapi.NewMessage(chatID, text)
-	msg.ParseMode = tgbotapi.ModeHTML
-	msg.ReplyMarkup = keyboard
-
-	_, err := p.bot.Send(msg)
+	_, err := p.sendMessage(chatID, threadID, text, &keyboard)
 	if err != nil {
 		p.logger.Error("Failed to send message with keyboard",
 			zap.String("correlation_id", correlationID),
@@ -104,17 +126,107 @@ func (p *telegramProvider) SendMessageWithKeyboard(chatID int64, text string, ke
 	return nil
 }
 
-// SetWebhook configures the webhook URL for receiving updates
-func (p *telegramProvider) SetWebhook(webhookURL string) error {
-	p.logger.Info("Setting webhook", zap.String("webhook_url", webhookURL))
+// SendTrackedMessage sends a message with an inline keyboard and returns its message ID
+func (p *telegramProvider) SendTrackedMessage(chatID int64, threadID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) (int, error) {
+	sent, err := p.sendMessage(chatID, threadID, text, &keyboard)
+	if err != nil {
+		p.logger.Error("Failed to send tracked message",
+			zap.Int64("chat_id", chatID),
+			zap.Error(err))
+		return 0, fmt.Errorf("failed to send message with keyboard: %w", err)
+	}
+
+	p.logger.Debug("Tracked message sent successfully",
+		zap.Int64("chat_id", chatID),
+		zap.Int("message_id", sent.MessageID))
+
+	return sent.MessageID, nil
+}
 
-	webhookConfig, err := tgbotapi.NewWebhook(webhookURL)
+// sendMessage sends an HTML message to a forum topic, or to the chat itself
+// when threadID is 0. The library predates forum topics, so the request is
+// built by hand to carry message_thread_id.
+func (p *telegramProvider) sendMessage(chatID int64, threadID int, text string, keyboard *tgbotapi.InlineKeyboardMarkup) (*tgbotapi.Message, error) {
+	params := tgbotapi.Params{}
+	params.AddNonZero64("chat_id", chatID)
+	params.AddNonZero("message_thread_id", threadID)
+	params.AddNonEmpty("text", text)
+	params.AddNonEmpty("parse_mode", tgbotapi.ModeHTML)
+	if keyboard != nil {
+		if err := params.AddInterface("reply_markup", keyboard); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := p.bot.MakeRequest("sendMessage", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var sent tgbotapi.Message
+	if err := json.Unmarshal(resp.Result, &sent); err != nil {
+		return nil, fmt.Errorf("failed to decode sent message: %w", err)
+	}
+	return &sent, nil
+}
+
+// EditMessageWithKeyboard replaces the text and keyboard of a previously sent message.
+// Telegram rejects edits that change nothing; those are treated as success.
+func (p *telegramProvider) EditMessageWithKeyboard(chatID int64, messageID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
+	edit := tgbotapi.NewEditMessageTextAndMarkup(chatID, messageID, text, keyboard)
+	edit.ParseMode = tgbotapi.ModeHTML
+
+	if _, err := p.bot.Request(edit); err != nil {
+		if strings.Contains(err.Error(), "message is not modified") {
+			return nil
+		}
+		p.logger.Warn("Failed to edit message",
+			zap.Int64("chat_id", chatID),
+			zap.Int("message_id", messageID),
+			zap.Error(err))
+		return fmt.Errorf("failed to edit message: %w", err)
+	}
+
+	p.logger.Debug("Message edited successfully",
+		zap.Int64("chat_id", chatID),
+		zap.Int("message_id", messageID))
+
+	return nil
+}
+
+// SendChatAction shows a status such as "typing" in the chat
+func (p *telegramProvider) SendChatAction(chatID int64, action string) error {
+	if _, err := p.bot.Request(tgbotapi.NewChatAction(chatID, action)); err != nil {
+		p.logger.Debug("Failed to send chat action",
+			zap.Int64("chat_id", chatID),
+			zap.String("action", action),
+			zap.Error(err))
+		return fmt.Errorf("failed to send chat action: %w", err)
+	}
+	return nil
+}
+
+// SetWebhook configures the webhook URL for receiving updates. A configured
+// certificate is uploaded so Telegram trusts a self-signed webhook.
+func (p *telegramProvider) SetWebhook(webhookURL string) error {
+	p.logger.Info("Setting webhook",
+		zap.String("webhook_url", webhookURL),
+		zap.Bool("custom_certificate", p.config.WebhookCertificate != ""))
+
+	var webhookConfig tgbotapi.WebhookConfig
+	var err error
+	if p.config.WebhookCertificate != "" {
+		webhookConfig, err = tgbotapi.NewWebhookWithCert(webhookURL, tgbotapi.FilePath(p.config.WebhookCertificate))
+	} else {
+		webhookConfig, err = tgbotapi.NewWebhook(webhookURL)
+	}
 	if err != nil {
 		p.logger.Error("Failed to create webhook config",
 			zap.String("webhook_url", webhookURL),
 			zap.Error(err))
 		return fmt.Errorf("failed to create webhook config: %w", err)
 	}
+	webhookConfig.AllowedUpdates = allowedUpdates
 
 	_, err = p.bot.Request(webhookConfig)
 	if err != nil {
@@ -142,6 +254,16 @@ func (p *telegramProvider) DeleteWebhook() error {
 	return nil
 }
 
+// GetWebhookInfo returns the webhook Telegram currently delivers updates to
+func (p *telegramProvider) GetWebhookInfo() (tgbotapi.WebhookInfo, error) {
+	info, err := p.bot.GetWebhookInfo()
+	if err != nil {
+		p.logger.Error("Failed to get webhook information", zap.Error(err))
+		return tgbotapi.WebhookInfo{}, fmt.Errorf("failed to get webhook information: %w", err)
+	}
+	return info, nil
+}
+
 // GetMe returns information about the bot
 func (p *telegramProvider) GetMe() (*tgbotapi.User, error) {
 	p.logger.Debug("Getting bot information")
@@ -158,3 +280,59 @@ func (p *telegramProvider) GetMe() (*tgbotapi.User, error) {
 
 	return &me, nil
 }
+
+// GetUpdates long-polls the Bot API for updates. The request is made directly
+// rather than through the library so it is abandoned as soon as ctx is done.
+func (p *telegramProvider) GetUpdates(ctx context.Context, offset, timeout int) ([]RawUpdate, error) {
+	params := url.Values{}
+	params.Set("offset", strconv.Itoa(offset))
+	params.Set("timeout", strconv.Itoa(timeout))
+	updateTypes, err := json.Marshal(allowedUpdates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode allowed updates: %w", err)
+	}
+	params.Set("allowed_updates", string(updateTypes))
+
+	endpoint := fmt.Sprintf(tgbotapi.APIEndpoint, p.bot.Token, "getUpdates")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create getUpdates request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.bot.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get updates: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var apiResp tgbotapi.APIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, fmt.Errorf("failed to decode getUpdates response: %w", err)
+	}
+	if !apiResp.Ok {
+		apiErr := &tgbotapi.Error{Code: apiResp.ErrorCode, Message: apiResp.Description}
+		if apiResp.Parameters != nil {
+			apiErr.ResponseParameters = *apiResp.Parameters
+		}
+		return nil, fmt.Errorf("failed to get updates: %w", apiErr)
+	}
+
+	var results []json.RawMessage
+	if err := json.Unmarshal(apiResp.Result, &results); err != nil {
+		return nil, fmt.Errorf("failed to decode updates: %w", err)
+	}
+
+	updates := make([]RawUpdate, 0, len(results))
+	for _, result := range results {
+		var envelope struct {
+			UpdateID int `json:"update_id"`
+		}
+		if err := json.Unmarshal(result, &envelope); err != nil {
+			return nil, fmt.Errorf("failed to decode update: %w", err)
+		}
+		updates = append(updates, RawUpdate{UpdateID: envelope.UpdateID, Payload: result})
+	}
+
+	return updates, nil
+}
@@ -0,0 +1,110 @@
+package chatbot
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"nudgebot-api/internal/events"
+)
+
+// ErrUnknownTemplate is returned by PreviewTemplate for a name it does not know
+var ErrUnknownTemplate = errors.New("unknown message template")
+
+// previewTemplates render the outgoing message templates with sample data,
+// so copy changes can be checked before they are deployed
+var previewTemplates = map[string]func(dates DateFormat, now time.Time) string{
+	"reminder": func(dates DateFormat, now time.Time) string {
+		return formatReminder(sampleReminder("Submit the quarterly report", now.Add(2*time.Hour)), dates)
+	},
+	"reminder_group": func(dates DateFormat, now time.Time) string {
+		return formatReminderGroup(events.ReminderGroupDue{Reminders: []events.ReminderDue{
+			sampleReminder("Submit the quarterly report", now.Add(2*time.Hour)),
+			sampleReminder("Book the dentist", now.Add(26*time.Hour)),
+		}}, dates)
+	},
+	"digest": func(dates DateFormat, now time.Time) string {
+		return formatReminderDigest(events.ReminderDigestDue{
+			Reminders: []events.ReminderDue{
+				sampleReminder("Submit the quarterly report", now.Add(2*time.Hour)),
+				sampleReminder("Water the plants", now.Add(5*time.Hour)),
+			},
+			Holiday: &events.HolidayNotice{Name: "Labour Day", Date: now.AddDate(0, 0, 1), Moved: 1, MovedTo: now.AddDate(0, 0, 2)},
+		}, dates)
+	},
+	"task_created": func(dates DateFormat, now time.Time) string {
+		due := now.Add(26 * time.Hour)
+		return formatTaskCreated(events.TaskCreated{
+			TaskID:    "00000000-0000-0000-0000-000000000001",
+			Title:     "Book the dentist",
+			Priority:  "medium",
+			Code:      "T-42",
+			DueDate:   &due,
+			CreatedAt: now,
+		}, dates)
+	},
+	"task_list": func(dates DateFormat, now time.Time) string {
+		due := now.Add(2 * time.Hour)
+		overdue := now.Add(-24 * time.Hour)
+		tasks := []events.TaskSummary{
+			{ID: "00000000-0000-0000-0000-000000000001", Code: "T-1", Title: "Submit the quarterly report", Priority: "high", Status: "active", DueDate: &due},
+			{ID: "00000000-0000-0000-0000-000000000002", Code: "T-2", Title: "Renew the passport", Priority: "urgent", Status: "active", DueDate: &overdue, IsOverdue: true},
+			{ID: "00000000-0000-0000-0000-000000000003", Code: "T-3", Title: "Read a book", Priority: "low", Status: "active"},
+		}
+		return formatTaskListPage(tasks, 0, totalPages(len(tasks)), dates)
+	},
+	"weekly_stats": func(dates DateFormat, now time.Time) string {
+		return formatWeeklyStats(events.WeeklyStatsResponse{
+			Since: now.AddDate(0, 0, -7), Created: 12, Completed: 9, Open: 7, Overdue: 2, Postponements: 3,
+			Slipped: []events.TaskSlippage{{Title: "Renew the passport", Postponements: 3, Slip: 72 * time.Hour}},
+			Success: true,
+		})
+	},
+	"delegation": func(dates DateFormat, now time.Time) string {
+		due := now.Add(26 * time.Hour)
+		return formatTaskDelegated(events.TaskDelegated{Title: "Order office supplies", DueDate: &due, DelegatedBy: "Ann"}, dates)
+	},
+	"habit_missed": func(dates DateFormat, now time.Time) string {
+		return formatHabitMissed(events.HabitMissed{Title: "Morning run", Habit: "daily", LostStreak: 5, NextDue: now.Add(12 * time.Hour)})
+	},
+	"slipped": func(dates DateFormat, now time.Time) string {
+		return formatSlippedNotice(events.TaskSlipped{Title: "Renew the passport", Postponements: 3})
+	},
+	"welcome_back": func(dates DateFormat, now time.Time) string {
+		due := now.Add(-30 * time.Hour)
+		return formatWelcomeBack(events.WelcomeBack{
+			AwaySince:    now.AddDate(0, 0, -3),
+			CameDue:      []events.TaskSummary{{Title: "Renew the passport", DueDate: &due}},
+			CameDueCount: 1,
+			Added:        1,
+			Open:         7,
+			Overdue:      2,
+		}, dates)
+	},
+}
+
+// sampleReminder is a reminder for a task due at due
+func sampleReminder(title string, due time.Time) events.ReminderDue {
+	return events.ReminderDue{TaskID: "00000000-0000-0000-0000-000000000001", Title: title, DueDate: &due, Priority: "high"}
+}
+
+// TemplateNames returns the names of the templates PreviewTemplate renders, sorted
+func TemplateNames() []string {
+	names := make([]string, 0, len(previewTemplates))
+	for name := range previewTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PreviewTemplate renders the named message template with sample data, as it
+// would be sent at now to a user in UTC with an English client
+func PreviewTemplate(name string, now time.Time) (string, error) {
+	render, ok := previewTemplates[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownTemplate, name)
+	}
+	return render(NewDateFormat("UTC", "en", now), now), nil
+}
@@ -0,0 +1,39 @@
+package chatbot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviewTemplate(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	names := TemplateNames()
+	assert.Contains(t, names, "reminder")
+	assert.Contains(t, names, "digest")
+	assert.Contains(t, names, "task_created")
+	assert.IsIncreasing(t, names)
+
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			text, err := PreviewTemplate(name, now)
+			require.NoError(t, err)
+			assert.NotEmpty(t, text)
+		})
+	}
+
+	text, err := PreviewTemplate("reminder", now)
+	require.NoError(t, err)
+	assert.Contains(t, text, "Task Reminder!")
+	assert.Contains(t, text, "Due in 2 hours")
+
+	text, err = PreviewTemplate("task_created", now)
+	require.NoError(t, err)
+	assert.Contains(t, text, "<code>T-42</code>")
+
+	_, err = PreviewTemplate("farewell", now)
+	assert.ErrorIs(t, err, ErrUnknownTemplate)
+}
@@ -3,8 +3,13 @@
 package chatbot
 
 import (
+	"context"
+	"time"
+
+	"nudgebot-api/internal/common"
 	"nudgebot-api/internal/config"
 	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/moderation"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"go.uber.org/zap"
@@ -17,10 +22,25 @@ func NewChatbotServiceWithProvider(eventBus events.EventBus, logger *zap.Logger,
 		logger:           logger,
 		provider:         provider,
 		parser:           NewWebhookParser(),
-		keyboardBuilder:  NewKeyboardBuilder(),
+		keyboardBuilder:  NewKeyboardBuilderWithLayout(NewKeyboardLayoutFromConfig(cfg.Keyboard)),
 		commandProcessor: NewCommandProcessor(eventBus, logger),
+		moderation:       moderation.NewPolicyFromConfig(cfg.Moderation, logger),
+		listMessages:     NewListMessageTracker(),
+		reminderMessages: NewReminderMessageTracker(),
+		threads:          NewThreadTracker(),
+		identities:       NewMemoryIdentityMap(),
+		load:             newLoadShedState(),
+		maintenance:      &maintenanceState{},
+		streams:          NewStreamThrottle(streamEditInterval),
+		ready:            common.NewReadiness(),
 		config:           cfg,
 	}
+	service.aggregator = NewMessageAggregator(
+		time.Duration(cfg.AggregationWindowMs)*time.Millisecond,
+		cfg.AggregationMaxMessages,
+		service.publishMessageBatch,
+	)
+	service.updates = NewUpdateQueue(cfg.UpdateQueueSize, logger, service.processUpdate)
 
 	// Subscribe to relevant events
 	service.setupEventSubscriptions()
@@ -37,6 +57,7 @@ type StubTelegramProvider struct {
 // SentMessage represents a message sent through the stub provider for verification
 type SentMessage struct {
 	ChatID   int64
+	ThreadID int
 	Text     string
 	Keyboard *tgbotapi.InlineKeyboardMarkup
 }
@@ -50,7 +71,7 @@ func NewStubTelegramProvider(logger *zap.Logger) TelegramProvider {
 }
 
 // SendMessage implements TelegramProvider interface (logs message but doesn't send)
-func (s *StubTelegramProvider) SendMessage(chatID int64, text string) error {
+func (s *StubTelegramProvider) SendMessage(chatID int64, threadID int, text string) error {
 	s.logger.Info("Stub Telegram provider sending message",
 		zap.Int64("chat_id", chatID),
 		zap.String("text", text))
@@ -58,6 +79,7 @@ func (s *StubTelegramProvider) SendMessage(chatID int64, text string) error {
 	// Store the message for verification
 	s.sentMessages = append(s.sentMessages, SentMessage{
 		ChatID:   chatID,
+		ThreadID: threadID,
 		Text:     text,
 		Keyboard: nil,
 	})
@@ -66,7 +88,7 @@ func (s *StubTelegramProvider) SendMessage(chatID int64, text string) error {
 }
 
 // SendMessageWithKeyboard implements TelegramProvider interface (logs message but doesn't send)
-func (s *StubTelegramProvider) SendMessageWithKeyboard(chatID int64, text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
+func (s *StubTelegramProvider) SendMessageWithKeyboard(chatID int64, threadID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
 	s.logger.Info("Stub Telegram provider sending message with keyboard",
 		zap.Int64("chat_id", chatID),
 		zap.String("text", text),
@@ -75,6 +97,7 @@ func (s *StubTelegramProvider) SendMessageWithKeyboard(chatID int64, text string
 	// Store the message for verification
 	s.sentMessages = append(s.sentMessages, SentMessage{
 		ChatID:   chatID,
+		ThreadID: threadID,
 		Text:     text,
 		Keyboard: &keyboard,
 	})
@@ -82,6 +105,31 @@ func (s *StubTelegramProvider) SendMessageWithKeyboard(chatID int64, text string
 	return nil
 }
 
+// SendTrackedMessage implements TelegramProvider interface and returns the stored message position as its ID
+func (s *StubTelegramProvider) SendTrackedMessage(chatID int64, threadID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) (int, error) {
+	if err := s.SendMessageWithKeyboard(chatID, threadID, text, keyboard); err != nil {
+		return 0, err
+	}
+	return len(s.sentMessages), nil
+}
+
+// EditMessageWithKeyboard implements TelegramProvider interface (logs the edit but doesn't send)
+func (s *StubTelegramProvider) EditMessageWithKeyboard(chatID int64, messageID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
+	s.logger.Info("Stub Telegram provider editing message",
+		zap.Int64("chat_id", chatID),
+		zap.Int("message_id", messageID),
+		zap.String("text", text))
+	return nil
+}
+
+// SendChatAction implements TelegramProvider interface (logs the action but doesn't send)
+func (s *StubTelegramProvider) SendChatAction(chatID int64, action string) error {
+	s.logger.Debug("Stub Telegram provider sending chat action",
+		zap.Int64("chat_id", chatID),
+		zap.String("action", action))
+	return nil
+}
+
 // SetWebhook implements TelegramProvider interface (logs webhook URL but doesn't set)
 func (s *StubTelegramProvider) SetWebhook(webhookURL string) error {
 	s.logger.Info("Stub Telegram provider setting webhook",
@@ -95,6 +143,11 @@ func (s *StubTelegramProvider) DeleteWebhook() error {
 	return nil
 }
 
+// GetWebhookInfo implements TelegramProvider interface; the stub has no webhook set
+func (s *StubTelegramProvider) GetWebhookInfo() (tgbotapi.WebhookInfo, error) {
+	return tgbotapi.WebhookInfo{}, nil
+}
+
 // GetMe implements TelegramProvider interface with mock bot information
 func (s *StubTelegramProvider) GetMe() (*tgbotapi.User, error) {
 	s.logger.Info("Stub Telegram provider getting bot info")
@@ -109,6 +162,13 @@ func (s *StubTelegramProvider) GetMe() (*tgbotapi.User, error) {
 	return mockUser, nil
 }
 
+// GetUpdates implements TelegramProvider interface; the stub never receives
+// updates, so it waits for ctx like an idle long poll
+func (s *StubTelegramProvider) GetUpdates(ctx context.Context, offset, timeout int) ([]RawUpdate, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
 // GetSentMessages returns all messages sent through this stub provider (for test verification)
 func (s *StubTelegramProvider) GetSentMessages() []SentMessage {
 	return s.sentMessages
@@ -0,0 +1,167 @@
+package chatbot
+
+import (
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// Telegram shows a chat action for about five seconds, so it is refreshed
+// a little sooner. Indicators give up after the LLM's parse timeout in case
+// neither TaskParsed nor TaskParseFailed arrives.
+const (
+	typingRefreshInterval = 4 * time.Second
+	typingMaxDuration     = 30 * time.Second
+)
+
+// TypingIndicator keeps "typing..." showing in chats while their messages are
+// being parsed. Each chat counts its pending messages and stops once all of
+// them are answered. A nil indicator does nothing.
+type TypingIndicator struct {
+	send        func(chatID string) error
+	interval    time.Duration
+	maxDuration time.Duration
+	logger      *zap.Logger
+
+	mu    sync.Mutex
+	chats map[string]*typingChat
+}
+
+// typingChat is the indicator of one chat
+type typingChat struct {
+	pending int
+	done    chan struct{}
+}
+
+// NewTypingIndicator creates an indicator that calls send for a chat every
+// interval until it is stopped or maxDuration has passed
+func NewTypingIndicator(send func(chatID string) error, interval, maxDuration time.Duration, logger *zap.Logger) *TypingIndicator {
+	return &TypingIndicator{
+		send:        send,
+		interval:    interval,
+		maxDuration: maxDuration,
+		logger:      logger,
+		chats:       make(map[string]*typingChat),
+	}
+}
+
+// Start shows the indicator in a chat, or adds a pending message to the one
+// already showing
+func (t *TypingIndicator) Start(chatID string) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	if chat, ok := t.chats[chatID]; ok {
+		chat.pending++
+		t.mu.Unlock()
+		return
+	}
+	chat := &typingChat{pending: 1, done: make(chan struct{})}
+	t.chats[chatID] = chat
+	t.mu.Unlock()
+
+	go t.run(chatID, chat)
+}
+
+// Stop marks one pending message of a chat as answered, removing the
+// indicator when none is left. Stopping a chat without an indicator does
+// nothing.
+func (t *TypingIndicator) Stop(chatID string) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	chat, ok := t.chats[chatID]
+	if !ok {
+		return
+	}
+	if chat.pending--; chat.pending > 0 {
+		return
+	}
+	delete(t.chats, chatID)
+	close(chat.done)
+}
+
+// StopAll removes every indicator
+func (t *TypingIndicator) StopAll() {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for chatID, chat := range t.chats {
+		delete(t.chats, chatID)
+		close(chat.done)
+	}
+}
+
+// Active reports whether the indicator is showing in a chat
+func (t *TypingIndicator) Active(chatID string) bool {
+	if t == nil {
+		return false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	_, ok := t.chats[chatID]
+	return ok
+}
+
+// run refreshes the chat action until the chat is stopped, it expires or
+// sending fails, as a chat that cannot receive it will not start to
+func (t *TypingIndicator) run(chatID string, chat *typingChat) {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+	expired := time.NewTimer(t.maxDuration)
+	defer expired.Stop()
+
+	for {
+		if err := t.send(chatID); err != nil {
+			t.logger.Debug("Stopping typing indicator",
+				zap.String("chat_id", chatID),
+				zap.Error(err))
+			t.remove(chatID, chat)
+			return
+		}
+
+		select {
+		case <-chat.done:
+			return
+		case <-expired.C:
+			t.logger.Debug("Typing indicator expired without a parse result",
+				zap.String("chat_id", chatID))
+			t.remove(chatID, chat)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// remove drops a chat's indicator unless it was already replaced
+func (t *TypingIndicator) remove(chatID string, chat *typingChat) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.chats[chatID] == chat {
+		delete(t.chats, chatID)
+	}
+}
+
+// sendTyping shows "typing..." in a chat
+func (s *chatbotService) sendTyping(chatID string) error {
+	telegramChatID, err := s.telegramChatID(chatID)
+	if err != nil {
+		return err
+	}
+	return s.provider.SendChatAction(telegramChatID, tgbotapi.ChatTyping)
+}
@@ -0,0 +1,124 @@
+package chatbot
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+)
+
+// typingRecorder counts the chat actions sent to each chat
+type typingRecorder struct {
+	mu    sync.Mutex
+	sends map[string]int
+	err   error
+}
+
+func (r *typingRecorder) send(chatID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sends[chatID]++
+	return r.err
+}
+
+func (r *typingRecorder) count(chatID string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.sends[chatID]
+}
+
+func newTypingRecorder() *typingRecorder {
+	return &typingRecorder{sends: make(map[string]int)}
+}
+
+func TestTypingIndicator_RefreshesUntilEveryMessageIsAnswered(t *testing.T) {
+	recorder := newTypingRecorder()
+	indicator := NewTypingIndicator(recorder.send, 10*time.Millisecond, time.Minute, zaptest.NewLogger(t))
+
+	indicator.Start("42")
+	indicator.Start("42")
+	assert.Eventually(t, func() bool { return recorder.count("42") >= 3 }, time.Second, time.Millisecond,
+		"the chat action is refreshed while parsing")
+
+	indicator.Stop("42")
+	assert.True(t, indicator.Active("42"), "one message is still being parsed")
+
+	indicator.Stop("42")
+	assert.False(t, indicator.Active("42"))
+	time.Sleep(20 * time.Millisecond)
+	sent := recorder.count("42")
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, sent, recorder.count("42"), "nothing is sent once stopped")
+
+	// Stopping a chat that is not typing is harmless
+	indicator.Stop("42")
+	indicator.Stop("7")
+}
+
+func TestTypingIndicator_ExpiresWithoutParseResult(t *testing.T) {
+	recorder := newTypingRecorder()
+	indicator := NewTypingIndicator(recorder.send, 5*time.Millisecond, 20*time.Millisecond, zaptest.NewLogger(t))
+
+	indicator.Start("42")
+	assert.Eventually(t, func() bool { return !indicator.Active("42") }, time.Second, time.Millisecond)
+	assert.Positive(t, recorder.count("42"))
+
+	// A new message starts a new indicator
+	indicator.Start("42")
+	assert.True(t, indicator.Active("42"))
+	indicator.StopAll()
+	assert.False(t, indicator.Active("42"))
+}
+
+func TestTypingIndicator_StopsWhenSendingFails(t *testing.T) {
+	recorder := newTypingRecorder()
+	recorder.err = errors.New("Forbidden: bot was blocked by the user")
+	indicator := NewTypingIndicator(recorder.send, time.Millisecond, time.Minute, zaptest.NewLogger(t))
+
+	indicator.Start("42")
+	assert.Eventually(t, func() bool { return !indicator.Active("42") }, time.Second, time.Millisecond)
+	assert.Equal(t, 1, recorder.count("42"))
+}
+
+func TestTypingIndicator_NilIsNoop(t *testing.T) {
+	var indicator *TypingIndicator
+	indicator.Start("42")
+	indicator.Stop("42")
+	indicator.StopAll()
+	assert.False(t, indicator.Active("42"))
+}
+
+func TestChatbotService_TypingWhileParsing(t *testing.T) {
+	logger := zap.NewNop()
+	bus := events.NewEventBus(logger)
+	defer bus.Close()
+	recorder := newTypingRecorder()
+	service := &chatbotService{
+		eventBus:         bus,
+		logger:           logger,
+		provider:         &discardProvider{},
+		keyboardBuilder:  NewKeyboardBuilder(),
+		commandProcessor: NewCommandProcessor(bus, logger),
+		typing:           NewTypingIndicator(recorder.send, time.Minute, time.Minute, logger),
+	}
+
+	service.publishMessageBatch(MessageBatch{UserID: "user", ChatID: "42", Messages: []string{"Call mom"}})
+	service.publishMessageBatch(MessageBatch{UserID: "user", ChatID: "7", Messages: []string{"asdf"}})
+	require.True(t, service.typing.Active("42"))
+	require.True(t, service.typing.Active("7"))
+
+	service.handleTaskParsed(events.TaskParsed{Event: events.NewEvent(), UserID: "user", ChatID: "42",
+		ParsedTask: events.ParsedTask{Title: "Call mom", Priority: "medium"}})
+	assert.False(t, service.typing.Active("42"))
+
+	service.handleTaskParseFailed(events.TaskParseFailed{Event: events.NewEvent(), UserID: "user", ChatID: "7",
+		Reason: events.ParseFailureInvalid})
+	assert.False(t, service.typing.Active("7"))
+}
@@ -0,0 +1,143 @@
+package chatbot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"nudgebot-api/internal/config"
+
+	"github.com/cenkalti/backoff/v4"
+	"go.uber.org/zap"
+)
+
+// Update receive modes
+const (
+	ReceiveModeWebhook = "webhook"
+	ReceiveModePolling = "polling"
+	ReceiveModeAuto    = "auto"
+)
+
+// DefaultPollTimeout is the long-poll timeout in seconds
+const DefaultPollTimeout = 25
+
+// ResolveReceiveMode returns how updates are received. The auto mode polls
+// unless the webhook URL is a full HTTP(S) URL Telegram can reach.
+func ResolveReceiveMode(cfg config.ChatbotConfig) (string, error) {
+	switch cfg.Mode {
+	case "", ReceiveModeWebhook:
+		return ReceiveModeWebhook, nil
+	case ReceiveModePolling:
+		return ReceiveModePolling, nil
+	case ReceiveModeAuto:
+		if strings.HasPrefix(cfg.WebhookURL, "http://") || strings.HasPrefix(cfg.WebhookURL, "https://") {
+			return ReceiveModeWebhook, nil
+		}
+		return ReceiveModePolling, nil
+	default:
+		return "", fmt.Errorf("unknown chatbot mode %q", cfg.Mode)
+	}
+}
+
+// UpdatePoller receives updates with a getUpdates long-polling loop for
+// deployments that cannot receive webhooks
+type UpdatePoller struct {
+	provider TelegramProvider
+	timeout  int
+	logger   *zap.Logger
+	publish  func(update RawUpdate)
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewUpdatePoller creates a poller that hands every received update to publish
+func NewUpdatePoller(provider TelegramProvider, timeout int, logger *zap.Logger, publish func(update RawUpdate)) *UpdatePoller {
+	if timeout <= 0 {
+		timeout = DefaultPollTimeout
+	}
+
+	return &UpdatePoller{
+		provider: provider,
+		timeout:  timeout,
+		logger:   logger,
+		publish:  publish,
+	}
+}
+
+// Start launches the polling loop. It stops when ctx is done or Stop is called.
+func (p *UpdatePoller) Start(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cancel != nil {
+		return
+	}
+
+	ctx, p.cancel = context.WithCancel(ctx)
+	p.done = make(chan struct{})
+	go p.run(ctx, p.done)
+
+	p.logger.Info("Polling Telegram for updates", zap.Int("poll_timeout_seconds", p.timeout))
+}
+
+// Stop ends the polling loop, abandoning the long poll in flight
+func (p *UpdatePoller) Stop(ctx context.Context) error {
+	p.mu.Lock()
+	cancel, done := p.cancel, p.done
+	p.cancel, p.done = nil, nil
+	p.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *UpdatePoller) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	retry := backoff.NewExponentialBackOff()
+	retry.InitialInterval = time.Second
+	retry.MaxInterval = 30 * time.Second
+	retry.MaxElapsedTime = 0
+
+	offset := 0
+	for ctx.Err() == nil {
+		updates, err := p.provider.GetUpdates(ctx, offset, p.timeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			wait := retry.NextBackOff()
+			p.logger.Warn("Failed to poll for updates, retrying",
+				zap.Duration("retry_in", wait),
+				zap.Error(err))
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+			continue
+		}
+		retry.Reset()
+
+		// Acknowledging an update is done by asking for the ones after it
+		for _, update := range updates {
+			p.publish(update)
+			offset = update.UpdateID + 1
+		}
+	}
+}
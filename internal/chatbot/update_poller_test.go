@@ -0,0 +1,120 @@
+package chatbot
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// pollingProvider serves scripted getUpdates responses and records the offsets asked for
+type pollingProvider struct {
+	TelegramProvider
+
+	mu        sync.Mutex
+	responses [][]RawUpdate
+	failures  int
+	offsets   []int
+}
+
+func (p *pollingProvider) GetUpdates(ctx context.Context, offset, timeout int) ([]RawUpdate, error) {
+	p.mu.Lock()
+	p.offsets = append(p.offsets, offset)
+	if p.failures > 0 {
+		p.failures--
+		p.mu.Unlock()
+		return nil, errors.New("conflict: webhook is active")
+	}
+	if len(p.responses) > 0 {
+		updates := p.responses[0]
+		p.responses = p.responses[1:]
+		p.mu.Unlock()
+		return updates, nil
+	}
+	p.mu.Unlock()
+
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (p *pollingProvider) requestedOffsets() []int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]int(nil), p.offsets...)
+}
+
+func TestUpdatePoller_PublishesAndAdvancesOffset(t *testing.T) {
+	provider := &pollingProvider{
+		responses: [][]RawUpdate{
+			{{UpdateID: 10, Payload: []byte(`{"update_id":10}`)}, {UpdateID: 11, Payload: []byte(`{"update_id":11}`)}},
+			{{UpdateID: 12, Payload: []byte(`{"update_id":12}`)}},
+		},
+	}
+
+	var mu sync.Mutex
+	var published []int
+	poller := NewUpdatePoller(provider, 1, zaptest.NewLogger(t), func(update RawUpdate) {
+		mu.Lock()
+		defer mu.Unlock()
+		published = append(published, update.UpdateID)
+	})
+
+	poller.Start(context.Background())
+	require.Eventually(t, func() bool {
+		return len(provider.requestedOffsets()) == 3
+	}, time.Second, 5*time.Millisecond)
+	require.NoError(t, poller.Stop(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []int{10, 11, 12}, published)
+	assert.Equal(t, []int{0, 12, 13}, provider.requestedOffsets())
+}
+
+func TestUpdatePoller_StopAbandonsLongPoll(t *testing.T) {
+	provider := &pollingProvider{}
+	poller := NewUpdatePoller(provider, 30, zaptest.NewLogger(t), func(update RawUpdate) {})
+
+	poller.Start(context.Background())
+	require.Eventually(t, func() bool {
+		return len(provider.requestedOffsets()) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, poller.Stop(ctx))
+}
+
+func TestResolveReceiveMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.ChatbotConfig
+		expected string
+		wantErr  bool
+	}{
+		{name: "default is webhook", cfg: config.ChatbotConfig{}, expected: ReceiveModeWebhook},
+		{name: "explicit polling", cfg: config.ChatbotConfig{Mode: "polling", WebhookURL: "https://bot.example.com/webhook"}, expected: ReceiveModePolling},
+		{name: "auto with public URL", cfg: config.ChatbotConfig{Mode: "auto", WebhookURL: "https://bot.example.com/webhook"}, expected: ReceiveModeWebhook},
+		{name: "auto with path only", cfg: config.ChatbotConfig{Mode: "auto", WebhookURL: "/api/v1/telegram/webhook"}, expected: ReceiveModePolling},
+		{name: "unknown mode", cfg: config.ChatbotConfig{Mode: "carrier-pigeon"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mode, err := ResolveReceiveMode(tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, mode)
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package chatbot
+
+import (
+	"context"
+	"sync"
+
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// DefaultUpdateQueueSize bounds the webhook updates waiting for the consumer
+const DefaultUpdateQueueSize = 256
+
+// UpdateQueue hands webhook updates to a dedicated consumer goroutine so the
+// webhook can answer Telegram before the update is parsed. Updates arriving
+// while the queue is full or stopped are processed inline rather than dropped,
+// because Telegram does not resend an update it received a 200 for.
+type UpdateQueue struct {
+	process func(update events.UpdateReceived)
+	logger  *zap.Logger
+	updates chan events.UpdateReceived
+
+	mu      sync.RWMutex
+	stopped bool
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewUpdateQueue creates a queue holding up to size updates. Updates queued
+// before Start are processed once the consumer starts.
+func NewUpdateQueue(size int, logger *zap.Logger, process func(update events.UpdateReceived)) *UpdateQueue {
+	if size <= 0 {
+		size = DefaultUpdateQueueSize
+	}
+
+	return &UpdateQueue{
+		process: process,
+		logger:  logger,
+		updates: make(chan events.UpdateReceived, size),
+	}
+}
+
+// Enqueue queues an update for the consumer
+func (q *UpdateQueue) Enqueue(update events.UpdateReceived) {
+	if q.tryEnqueue(update) {
+		return
+	}
+
+	q.logger.Warn("Update queue unavailable, processing update inline",
+		zap.String("correlation_id", update.CorrelationID),
+		zap.Int("update_id", update.UpdateID))
+	q.process(update)
+}
+
+func (q *UpdateQueue) tryEnqueue(update events.UpdateReceived) bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.stopped {
+		return false
+	}
+
+	select {
+	case q.updates <- update:
+		return true
+	default:
+		return false
+	}
+}
+
+// Start launches the consumer. The consumer runs until Stop rather than until
+// ctx is done, so queued updates are not lost when shutdown begins.
+func (q *UpdateQueue) Start(ctx context.Context) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.done != nil {
+		return
+	}
+
+	q.stopped = false
+	q.stop = make(chan struct{})
+	q.done = make(chan struct{})
+	go q.consume(q.stop, q.done)
+}
+
+// Stop processes the updates still queued and stops the consumer, giving up
+// waiting when ctx is done
+func (q *UpdateQueue) Stop(ctx context.Context) error {
+	q.mu.Lock()
+	q.stopped = true
+	stop, done := q.stop, q.done
+	q.stop, q.done = nil, nil
+	q.mu.Unlock()
+
+	if done == nil {
+		return nil
+	}
+
+	close(stop)
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Len returns the number of updates waiting for the consumer
+func (q *UpdateQueue) Len() int {
+	return len(q.updates)
+}
+
+func (q *UpdateQueue) consume(stop, done chan struct{}) {
+	defer close(done)
+
+	for {
+		select {
+		case update := <-q.updates:
+			q.process(update)
+		case <-stop:
+			for {
+				select {
+				case update := <-q.updates:
+					q.process(update)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
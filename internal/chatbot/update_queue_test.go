@@ -0,0 +1,89 @@
+package chatbot
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// updateRecorder collects the update IDs a queue processed
+type updateRecorder struct {
+	mu  sync.Mutex
+	ids []int
+}
+
+func (r *updateRecorder) process(update events.UpdateReceived) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ids = append(r.ids, update.UpdateID)
+}
+
+func (r *updateRecorder) processed() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int(nil), r.ids...)
+}
+
+func TestUpdateQueue_ProcessesInOrder(t *testing.T) {
+	recorder := &updateRecorder{}
+	queue := NewUpdateQueue(10, zaptest.NewLogger(t), recorder.process)
+
+	// Updates queued before Start wait for the consumer
+	queue.Enqueue(events.UpdateReceived{UpdateID: 1})
+	queue.Enqueue(events.UpdateReceived{UpdateID: 2})
+	assert.Empty(t, recorder.processed())
+	assert.Equal(t, 2, queue.Len())
+
+	queue.Start(context.Background())
+	queue.Enqueue(events.UpdateReceived{UpdateID: 3})
+
+	require.Eventually(t, func() bool {
+		return len(recorder.processed()) == 3
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []int{1, 2, 3}, recorder.processed())
+
+	require.NoError(t, queue.Stop(context.Background()))
+}
+
+func TestUpdateQueue_StopDrainsQueue(t *testing.T) {
+	release := make(chan struct{})
+	recorder := &updateRecorder{}
+	queue := NewUpdateQueue(10, zaptest.NewLogger(t), func(update events.UpdateReceived) {
+		<-release
+		recorder.process(update)
+	})
+	queue.Start(context.Background())
+
+	for id := 1; id <= 3; id++ {
+		queue.Enqueue(events.UpdateReceived{UpdateID: id})
+	}
+	close(release)
+
+	require.NoError(t, queue.Stop(context.Background()))
+	assert.Equal(t, []int{1, 2, 3}, recorder.processed())
+}
+
+func TestUpdateQueue_ProcessesInlineWhenUnavailable(t *testing.T) {
+	recorder := &updateRecorder{}
+	queue := NewUpdateQueue(1, zaptest.NewLogger(t), recorder.process)
+
+	// The consumer is not running, so the second update does not fit
+	queue.Enqueue(events.UpdateReceived{UpdateID: 1})
+	queue.Enqueue(events.UpdateReceived{UpdateID: 2})
+	assert.Equal(t, []int{2}, recorder.processed())
+
+	queue.Start(context.Background())
+	require.NoError(t, queue.Stop(context.Background()))
+	assert.Equal(t, []int{2, 1}, recorder.processed())
+
+	// After Stop updates are processed inline
+	queue.Enqueue(events.UpdateReceived{UpdateID: 3})
+	assert.Equal(t, []int{2, 1, 3}, recorder.processed())
+}
@@ -0,0 +1,129 @@
+package chatbot
+
+import (
+	"sync"
+	"time"
+
+	"nudgebot-api/internal/common"
+)
+
+// prefsCacheTTL is how long resolved preferences are reused before they are
+// looked up again
+const prefsCacheTTL = 5 * time.Minute
+
+// UserPrefs are the settings a user's dates are shown with
+type UserPrefs struct {
+	Timezone string // IANA name, UTC when empty
+	Language string // empty follows the Telegram client
+}
+
+// PrefsResolver finds the preferences a user chose
+type PrefsResolver interface {
+	UserPrefsFor(userID common.UserID) (UserPrefs, error)
+}
+
+// PrefsResolverFunc adapts a function to the PrefsResolver interface
+type PrefsResolverFunc func(userID common.UserID) (UserPrefs, error)
+
+// UserPrefsFor implements the PrefsResolver interface
+func (f PrefsResolverFunc) UserPrefsFor(userID common.UserID) (UserPrefs, error) {
+	return f(userID)
+}
+
+type cachedPrefs struct {
+	prefs    UserPrefs
+	expireAt time.Time
+}
+
+// userPrefsCache keeps resolved preferences so rendering a message doesn't
+// look up settings every time, along with the language of each user's
+// Telegram client. A nil cache resolves nothing.
+type userPrefsCache struct {
+	resolver PrefsResolver
+	ttl      time.Duration
+
+	mu            sync.Mutex
+	prefs         map[common.UserID]cachedPrefs
+	clientLocales map[common.UserID]string
+}
+
+// newUserPrefsCache creates a cache resolving through resolver, which may be nil
+func newUserPrefsCache(resolver PrefsResolver, ttl time.Duration) *userPrefsCache {
+	return &userPrefsCache{
+		resolver:      resolver,
+		ttl:           ttl,
+		prefs:         make(map[common.UserID]cachedPrefs),
+		clientLocales: make(map[common.UserID]string),
+	}
+}
+
+// Get returns the user's preferences, resolving them when not cached. A
+// failed lookup is cached as no preferences, so an outage isn't queried on
+// every message.
+func (c *userPrefsCache) Get(userID common.UserID) UserPrefs {
+	if c == nil {
+		return UserPrefs{}
+	}
+
+	now := time.Now()
+	c.mu.Lock()
+	cached, ok := c.prefs[userID]
+	c.mu.Unlock()
+	if ok && now.Before(cached.expireAt) {
+		return cached.prefs
+	}
+
+	var prefs UserPrefs
+	if c.resolver != nil {
+		prefs, _ = c.resolver.UserPrefsFor(userID)
+	}
+	c.Set(userID, prefs)
+	return prefs
+}
+
+// Set replaces the user's cached preferences, as after a change in /settings
+func (c *userPrefsCache) Set(userID common.UserID, prefs UserPrefs) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.prefs[userID] = cachedPrefs{prefs: prefs, expireAt: time.Now().Add(c.ttl)}
+}
+
+// SetClientLocale records the language of the user's Telegram client
+func (c *userPrefsCache) SetClientLocale(userID common.UserID, locale string) {
+	if c == nil || locale == "" {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.clientLocales[userID] = locale
+}
+
+// ClientLocale returns the language last seen from the user's Telegram client
+func (c *userPrefsCache) ClientLocale(userID common.UserID) string {
+	if c == nil {
+		return ""
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.clientLocales[userID]
+}
+
+// dateFormat returns how dates are shown to a user: in the time zone they
+// chose, and in the language they chose or their Telegram client's
+func (s *chatbotService) dateFormat(userID string) DateFormat {
+	prefs := s.prefs.Get(common.UserID(userID))
+	locale := prefs.Language
+	if locale == "" {
+		locale = s.prefs.ClientLocale(common.UserID(userID))
+	}
+	return NewDateFormat(prefs.Timezone, locale, time.Now())
+}
@@ -0,0 +1,75 @@
+package chatbot
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserPrefsCache_ResolvesOnce(t *testing.T) {
+	lookups := 0
+	resolver := PrefsResolverFunc(func(userID common.UserID) (UserPrefs, error) {
+		lookups++
+		if userID == "broken" {
+			return UserPrefs{}, errors.New("database unavailable")
+		}
+		return UserPrefs{Timezone: "Asia/Tokyo", Language: "ja"}, nil
+	})
+	cache := newUserPrefsCache(resolver, time.Minute)
+
+	assert.Equal(t, UserPrefs{Timezone: "Asia/Tokyo", Language: "ja"}, cache.Get("user"))
+	assert.Equal(t, UserPrefs{Timezone: "Asia/Tokyo", Language: "ja"}, cache.Get("user"))
+	assert.Equal(t, 1, lookups)
+
+	assert.Equal(t, UserPrefs{}, cache.Get("broken"))
+	assert.Equal(t, UserPrefs{}, cache.Get("broken"))
+	assert.Equal(t, 2, lookups, "failed lookups are not repeated on every message")
+
+	cache.Set("user", UserPrefs{Timezone: "Europe/Berlin"})
+	assert.Equal(t, UserPrefs{Timezone: "Europe/Berlin"}, cache.Get("user"))
+	assert.Equal(t, 2, lookups)
+}
+
+func TestUserPrefsCache_Nil(t *testing.T) {
+	var cache *userPrefsCache
+
+	cache.Set("user", UserPrefs{Language: "vi"})
+	cache.SetClientLocale("user", "vi")
+	assert.Equal(t, UserPrefs{}, cache.Get("user"))
+	assert.Empty(t, cache.ClientLocale("user"))
+}
+
+func TestChatbotService_DatesFollowSettings(t *testing.T) {
+	chatbot, list := newListTestService(t)
+	provider := &settingsRecordingProvider{listRecordingProvider: list}
+	chatbot.provider = provider
+	chatbot.prefs = newUserPrefsCache(nil, time.Minute)
+	chatbot.prefs.SetClientLocale("user", "en-US")
+
+	due := time.Date(time.Now().Year()+1, 1, 2, 17, 30, 0, 0, time.UTC)
+	response := listResponse(1)
+	response.Tasks[0].DueDate = &due
+
+	// Without settings the client's language is used, in UTC
+	chatbot.handleTaskListResponse(response)
+	require.Len(t, provider.sent, 1)
+	assert.Contains(t, provider.sent[0], "Jan 2, ")
+	assert.Contains(t, provider.sent[0], " 5:30 PM")
+
+	// A language and time zone chosen in /settings win
+	settings := testUserSettings()
+	settings.Timezone = "Europe/Berlin"
+	settings.Language = "de"
+	chatbot.handleSettingsResponse(events.SettingsResponse{UserID: "user", ChatID: "42", MessageID: 55, Success: true, Settings: settings})
+
+	chatbot.listMessages.Delete("42")
+	chatbot.handleTaskListResponse(response)
+	assert.Contains(t, provider.sent[len(provider.sent)-1], "2 Jan ")
+	assert.Contains(t, provider.sent[len(provider.sent)-1], " 18:30")
+}
@@ -0,0 +1,111 @@
+package chatbot
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidInitData is returned for Web App init data that is malformed, not
+// signed with the bot's token or too old
+var ErrInvalidInitData = errors.New("invalid web app init data")
+
+// WebAppUser is the Telegram user a Web App was opened by
+type WebAppUser struct {
+	ID           int64  `json:"id"`
+	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name,omitempty"`
+	Username     string `json:"username,omitempty"`
+	LanguageCode string `json:"language_code,omitempty"`
+}
+
+// WebAppInitData is the launch data Telegram passes to a Web App, after its
+// signature has been checked
+type WebAppInitData struct {
+	User     WebAppUser
+	AuthDate time.Time
+	QueryID  string
+}
+
+// ValidateWebAppInitData checks the Telegram.WebApp.initData query string a
+// Web App sends: its hash must be the HMAC-SHA256 of the other fields, keyed
+// with the HMAC-SHA256 of the bot token under "WebAppData", and it must have
+// been issued within maxAge of now. A zero maxAge accepts any age.
+func ValidateWebAppInitData(initData, botToken string, maxAge time.Duration, now time.Time) (*WebAppInitData, error) {
+	if botToken == "" {
+		return nil, fmt.Errorf("%w: no bot token configured", ErrInvalidInitData)
+	}
+	values, err := url.ParseQuery(initData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInitData, err)
+	}
+
+	hash := values.Get("hash")
+	if hash == "" {
+		return nil, fmt.Errorf("%w: missing hash", ErrInvalidInitData)
+	}
+	provided, err := hex.DecodeString(hash)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed hash", ErrInvalidInitData)
+	}
+	if !hmac.Equal(provided, webAppSignature(values, botToken)) {
+		return nil, fmt.Errorf("%w: hash mismatch", ErrInvalidInitData)
+	}
+
+	authDate, err := strconv.ParseInt(values.Get("auth_date"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing auth_date", ErrInvalidInitData)
+	}
+	data := &WebAppInitData{AuthDate: time.Unix(authDate, 0), QueryID: values.Get("query_id")}
+	if maxAge > 0 && now.Sub(data.AuthDate) > maxAge {
+		return nil, fmt.Errorf("%w: expired", ErrInvalidInitData)
+	}
+
+	if err := json.Unmarshal([]byte(values.Get("user")), &data.User); err != nil || data.User.ID == 0 {
+		return nil, fmt.Errorf("%w: missing user", ErrInvalidInitData)
+	}
+	return data, nil
+}
+
+// webAppSignature computes the HMAC Telegram signs init data with over the
+// data-check string: every field but hash as key=value, sorted by key and
+// joined by newlines
+func webAppSignature(values url.Values, botToken string) []byte {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if key != "hash" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	lines := make([]string, len(keys))
+	for i, key := range keys {
+		lines[i] = key + "=" + values.Get(key)
+	}
+
+	secret := hmac.New(sha256.New, []byte("WebAppData"))
+	secret.Write([]byte(botToken))
+	mac := hmac.New(sha256.New, secret.Sum(nil))
+	mac.Write([]byte(strings.Join(lines, "\n")))
+	return mac.Sum(nil)
+}
+
+// SignWebAppInitData builds init data for user signed with botToken the way
+// Telegram does, for tests and local development of the Web App
+func SignWebAppInitData(user WebAppUser, authDate time.Time, botToken string) string {
+	encodedUser, _ := json.Marshal(user)
+	values := url.Values{}
+	values.Set("user", string(encodedUser))
+	values.Set("auth_date", strconv.FormatInt(authDate.Unix(), 10))
+	values.Set("hash", hex.EncodeToString(webAppSignature(values, botToken)))
+	return values.Encode()
+}
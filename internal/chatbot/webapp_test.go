@@ -0,0 +1,61 @@
+package chatbot
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateWebAppInitData(t *testing.T) {
+	const token = "123456:test-token"
+	now := time.Now()
+	user := WebAppUser{ID: 42, FirstName: "Ada", Username: "ada"}
+
+	// Signed by hand as Telegram documents it: the data-check string is the
+	// fields but hash, sorted and joined by newlines
+	authDate := strconv.FormatInt(now.Add(-time.Minute).Unix(), 10)
+	userJSON := `{"id":42,"first_name":"Ada","username":"ada"}`
+	secret := hmac.New(sha256.New, []byte("WebAppData"))
+	secret.Write([]byte(token))
+	mac := hmac.New(sha256.New, secret.Sum(nil))
+	mac.Write([]byte("auth_date=" + authDate + "\nquery_id=AAF\nuser=" + userJSON))
+	signed := url.Values{"user": {userJSON}, "query_id": {"AAF"}, "auth_date": {authDate}, "hash": {hex.EncodeToString(mac.Sum(nil))}}
+
+	data, err := ValidateWebAppInitData(signed.Encode(), token, time.Hour, now)
+	require.NoError(t, err)
+	assert.Equal(t, user, data.User)
+	assert.Equal(t, "AAF", data.QueryID)
+	assert.Equal(t, authDate, strconv.FormatInt(data.AuthDate.Unix(), 10))
+
+	_, err = ValidateWebAppInitData(SignWebAppInitData(user, now, token), token, time.Hour, now)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		initData string
+	}{
+		{"another bot's token", SignWebAppInitData(user, now, "654321:other-token")},
+		{"expired", SignWebAppInitData(user, now.Add(-2*time.Hour), token)},
+		{"tampered user", strings.Replace(SignWebAppInitData(user, now, token), "42", "43", 1)},
+		{"no hash", "user=%7B%22id%22%3A42%7D&auth_date=1700000000"},
+		{"no user", SignWebAppInitData(WebAppUser{}, now, token)},
+		{"empty", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateWebAppInitData(tt.initData, token, time.Hour, now)
+			assert.ErrorIs(t, err, ErrInvalidInitData)
+		})
+	}
+
+	_, err = ValidateWebAppInitData(SignWebAppInitData(user, now, token), "", time.Hour, now)
+	assert.ErrorIs(t, err, ErrInvalidInitData, "without a token nothing is accepted")
+}
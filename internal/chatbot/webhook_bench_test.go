@@ -0,0 +1,225 @@
+package chatbot
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/config"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/nudge"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"go.uber.org/zap"
+)
+
+// The webhook path runs for every user message, so its cost is budgeted.
+// Allocations are deterministic and checked strictly; latency budgets leave
+// room for slow CI machines and only catch large regressions.
+var webhookBudgets = []struct {
+	name      string
+	bench     func(*testing.B)
+	maxNsOp   int64
+	maxAllocs int64
+}{
+	{"parse", BenchmarkWebhook_Parse, 25_000, 8},
+	{"publish", BenchmarkWebhook_Publish, 100_000, 70},
+	{"create_task", BenchmarkWebhook_CreateTask, 400_000, 260},
+}
+
+// benchUsers is the number of senders updates rotate through
+const benchUsers = 64
+
+// benchRepositoryReset bounds the in-memory repository, whose duplicate check
+// scans every stored task, so that it does not dominate long runs
+const benchRepositoryReset = 1000
+
+// discardProvider accepts every Bot API call without sending anything
+type discardProvider struct {
+	TelegramProvider
+	sent atomic.Int64
+}
+
+func (p *discardProvider) SendMessage(chatID int64, threadID int, text string) error {
+	p.sent.Add(1)
+	return nil
+}
+
+func (p *discardProvider) SendMessageWithKeyboard(chatID int64, threadID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
+	p.sent.Add(1)
+	return nil
+}
+
+func (p *discardProvider) SendTrackedMessage(chatID int64, threadID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) (int, error) {
+	p.sent.Add(1)
+	return int(p.sent.Load()), nil
+}
+
+// newBenchService wires a chatbot service to a real event bus without a
+// Telegram connection; messages are published as soon as they arrive
+func newBenchService(bus events.EventBus, logger *zap.Logger) (*chatbotService, *discardProvider) {
+	provider := &discardProvider{}
+	service := &chatbotService{
+		eventBus:         bus,
+		logger:           logger,
+		provider:         provider,
+		parser:           NewWebhookParser(),
+		keyboardBuilder:  NewKeyboardBuilder(),
+		commandProcessor: NewCommandProcessor(bus, logger),
+		listMessages:     NewListMessageTracker(),
+		reminderMessages: NewReminderMessageTracker(),
+		threads:          NewThreadTracker(),
+		identities:       NewMemoryIdentityMap(),
+		load:             newLoadShedState(),
+		maintenance:      &maintenanceState{},
+		ready:            common.NewReadiness(),
+		config:           config.ChatbotConfig{},
+	}
+	service.aggregator = NewMessageAggregator(0, 0, service.publishMessageBatch)
+	service.setupEventSubscriptions()
+	return service, provider
+}
+
+// newBenchPipeline wires the chatbot and nudge services with an in-memory
+// repository; a subscriber stands in for the LLM and parses every message as
+// a task titled by its text
+func newBenchPipeline(b *testing.B) (*chatbotService, *discardProvider) {
+	b.Helper()
+	logger := zap.NewNop()
+	bus := events.NewEventBus(logger)
+
+	if _, err := nudge.NewNudgeService(bus, logger, nudge.NewMemoryNudgeRepository(logger)); err != nil {
+		b.Fatalf("failed to create nudge service: %v", err)
+	}
+	err := bus.Subscribe(events.TopicMessageReceived, func(event events.MessageReceived) {
+		bus.Publish(events.TopicTaskParsed, events.TaskParsed{
+			Event:      events.NewEvent(),
+			UserID:     event.UserID,
+			ChatID:     event.ChatID,
+			ParsedTask: events.ParsedTask{Title: event.MessageText, Priority: "medium"},
+		})
+	})
+	if err != nil {
+		b.Fatalf("failed to subscribe: %v", err)
+	}
+
+	return newBenchService(bus, logger)
+}
+
+// benchUpdates returns a text message update for each iteration, rotating
+// senders and keeping texts unique so that no task is a duplicate
+func benchUpdates(n int) [][]byte {
+	updates := make([][]byte, n)
+	for i := range updates {
+		sender := 100000 + i%benchUsers
+		updates[i] = []byte(fmt.Sprintf(`{"update_id":%d,"message":{"message_id":%d,"date":1700000000,`+
+			`"from":{"id":%d,"is_bot":false,"first_name":"Bench"},"chat":{"id":%d,"type":"private"},`+
+			`"text":"Call the dentist about appointment %d"}}`, i+1, i+1, sender, sender, i))
+	}
+	return updates
+}
+
+// BenchmarkWebhook_Parse measures decoding an update and classifying it
+func BenchmarkWebhook_Parse(b *testing.B) {
+	parser := NewWebhookParser()
+	updates := benchUpdates(b.N)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		update, err := parser.ParseUpdate(updates[i])
+		if err != nil {
+			b.Fatal(err)
+		}
+		if parser.DetermineMessageType(update) != MessageTypeText {
+			b.Fatal("update is not a text message")
+		}
+	}
+}
+
+// BenchmarkWebhook_Publish measures handling a webhook up to the
+// MessageReceived event, with nothing parsing it further
+func BenchmarkWebhook_Publish(b *testing.B) {
+	logger := zap.NewNop()
+	bus := events.NewEventBus(logger)
+	service, _ := newBenchService(bus, logger)
+
+	var received atomic.Int64
+	if err := bus.Subscribe(events.TopicMessageReceived, func(events.MessageReceived) { received.Add(1) }); err != nil {
+		b.Fatal(err)
+	}
+	updates := benchUpdates(b.N)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := service.HandleWebhook(updates[i]); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+
+	if received.Load() != int64(b.N) {
+		b.Fatalf("published %d of %d messages", received.Load(), b.N)
+	}
+}
+
+// BenchmarkWebhook_CreateTask measures the full path from webhook to stored
+// task and sent confirmation
+func BenchmarkWebhook_CreateTask(b *testing.B) {
+	updates := benchUpdates(b.N)
+	service, provider := newBenchPipeline(b)
+	var sent int64
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if i > 0 && i%benchRepositoryReset == 0 {
+			b.StopTimer()
+			sent += provider.sent.Load()
+			service, provider = newBenchPipeline(b)
+			b.StartTimer()
+		}
+		if err := service.HandleWebhook(updates[i]); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.StopTimer()
+
+	// Every message is confirmed once, so nothing was dropped as a duplicate
+	if sent += provider.sent.Load(); sent != int64(b.N) {
+		b.Fatalf("sent %d confirmations for %d messages", sent, b.N)
+	}
+}
+
+// TestWebhookPath_PerformanceBudget fails when a webhook benchmark exceeds its
+// budget. Timings depend on the machine, so it only runs when PERF_BUDGET is
+// set, as `make perf-check` does.
+func TestWebhookPath_PerformanceBudget(t *testing.T) {
+	if os.Getenv("PERF_BUDGET") == "" {
+		t.Skip("set PERF_BUDGET=1 to check the webhook performance budget")
+	}
+
+	for _, budget := range webhookBudgets {
+		budget := budget
+		t.Run(budget.name, func(t *testing.T) {
+			result := testing.Benchmark(budget.bench)
+			if result.N == 0 {
+				t.Fatal("benchmark failed")
+			}
+			t.Logf("%s: %d ns/op (budget %d), %d allocs/op (budget %d), %d B/op",
+				budget.name, result.NsPerOp(), budget.maxNsOp, result.AllocsPerOp(), budget.maxAllocs, result.AllocedBytesPerOp())
+
+			if result.AllocsPerOp() > budget.maxAllocs {
+				t.Errorf("%d allocs/op exceeds the budget of %d", result.AllocsPerOp(), budget.maxAllocs)
+			}
+			if result.NsPerOp() > budget.maxNsOp {
+				t.Errorf("%s/op exceeds the budget of %s",
+					time.Duration(result.NsPerOp()), time.Duration(budget.maxNsOp))
+			}
+		})
+	}
+}
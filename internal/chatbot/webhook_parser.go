@@ -13,13 +13,22 @@ import (
 )
 
 // WebhookParser provides utilities for parsing Telegram webhook updates
-type WebhookParser struct{}
+type WebhookParser struct {
+	bot string
+}
 
 // NewWebhookParser creates a new WebhookParser instance
 func NewWebhookParser() *WebhookParser {
 	return &WebhookParser{}
 }
 
+// NewWebhookParserForBot creates a WebhookParser whose user and chat IDs are
+// scoped to the named bot, so the same Telegram user talking to two bots is
+// two users. The default bot, named "", keeps the unscoped IDs.
+func NewWebhookParserForBot(bot string) *WebhookParser {
+	return &WebhookParser{bot: bot}
+}
+
 // ParseUpdate unmarshals webhook data into a Telegram Update struct
 func (p *WebhookParser) ParseUpdate(updateData []byte) (*tgbotapi.Update, error) {
 	if len(updateData) == 0 {
@@ -39,6 +48,18 @@ func (p *WebhookParser) ParseUpdate(updateData []byte) (*tgbotapi.Update, error)
 	return &update, nil
 }
 
+// ExtractReaction decodes the message_reaction update in webhook data,
+// returning nil when the update is about something else
+func (p *WebhookParser) ExtractReaction(updateData []byte) (*MessageReaction, error) {
+	var update struct {
+		MessageReaction *MessageReaction `json:"message_reaction"`
+	}
+	if err := json.Unmarshal(updateData, &update); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal reaction update: %w", err)
+	}
+	return update.MessageReaction, nil
+}
+
 // telegramIDToUUID converts a Telegram numeric ID to a deterministic UUID
 func telegramIDToUUID(telegramID int64) string {
 	// Create a deterministic UUID based on the Telegram ID
@@ -47,6 +68,18 @@ func telegramIDToUUID(telegramID int64) string {
 		hash[0:4], hash[4:6], hash[6:8], hash[8:10], hash[10:16])
 }
 
+// scopedTelegramIDToUUID converts a Telegram numeric ID to a deterministic UUID
+// for the named bot
+func scopedTelegramIDToUUID(bot string, telegramID int64) string {
+	if bot == "" {
+		return telegramIDToUUID(telegramID)
+	}
+
+	hash := md5.Sum([]byte(fmt.Sprintf("telegram_id_%s_%d", bot, telegramID)))
+	return fmt.Sprintf("%x-%x-%x-%x-%x",
+		hash[0:4], hash[4:6], hash[6:8], hash[8:10], hash[10:16])
+}
+
 // ExtractMessage converts a Telegram message to domain Message struct
 func (p *WebhookParser) ExtractMessage(update *tgbotapi.Update) (*Message, error) {
 	if update == nil {
@@ -77,11 +110,12 @@ func (p *WebhookParser) ExtractMessage(update *tgbotapi.Update) (*Message, error
 
 	return &Message{
 		ID:          common.ID(strconv.Itoa(msg.MessageID)),
-		UserID:      common.UserID(telegramIDToUUID(msg.From.ID)),
-		ChatID:      common.ChatID(telegramIDToUUID(msg.Chat.ID)),
+		UserID:      common.UserID(scopedTelegramIDToUUID(p.bot, msg.From.ID)),
+		ChatID:      common.ChatID(scopedTelegramIDToUUID(p.bot, msg.Chat.ID)),
 		Text:        text,
 		Timestamp:   time.Unix(int64(msg.Date), 0),
 		MessageType: messageType,
+		Locale:      msg.From.LanguageCode,
 	}, nil
 }
 
@@ -149,6 +183,44 @@ func (p *WebhookParser) ExtractCommand(message *tgbotapi.Message) (Command, erro
 		return CommandDone, nil
 	case "delete":
 		return CommandDelete, nil
+	case "testreminder":
+		return CommandTestReminder, nil
+	case "invite":
+		return CommandInvite, nil
+	case "field":
+		return CommandField, nil
+	case "snoozeall":
+		return CommandSnoozeAll, nil
+	case "moveto":
+		return CommandMoveTo, nil
+	case "apitoken":
+		return CommandAPIToken, nil
+	case "stats":
+		return CommandStats, nil
+	case "newlist":
+		return CommandNewList, nil
+	case "lists":
+		return CommandLists, nil
+	case "addto":
+		return CommandAddTo, nil
+	case "delegate":
+		return CommandDelegate, nil
+	case "plan":
+		return CommandPlan, nil
+	case "retention":
+		return CommandRetention, nil
+	case "snooze":
+		return CommandSnooze, nil
+	case "settings":
+		return CommandSettings, nil
+	case "broadcast":
+		return CommandBroadcast, nil
+	case "globalstats":
+		return CommandGlobalStats, nil
+	case "maintenance":
+		return CommandMaintenance, nil
+	case "task":
+		return CommandTask, nil
 	default:
 		return "", fmt.Errorf("unknown command: %s", commandText)
 	}
@@ -174,40 +246,52 @@ func (p *WebhookParser) BuildCorrelationID(update *tgbotapi.Update) string {
 	return fmt.Sprintf("upd_%d_%d", updateID, timestamp)
 }
 
-// GetUserID extracts user ID from update
-func (p *WebhookParser) GetUserID(update *tgbotapi.Update) (common.UserID, error) {
+// GetSender extracts the Telegram user who sent the update
+func (p *WebhookParser) GetSender(update *tgbotapi.Update) (*tgbotapi.User, error) {
 	if update == nil {
-		return "", fmt.Errorf("update is nil")
+		return nil, fmt.Errorf("update is nil")
 	}
 
-	var userID int64
-
 	if update.Message != nil && update.Message.From != nil {
-		userID = update.Message.From.ID
-	} else if update.CallbackQuery != nil && update.CallbackQuery.From != nil {
-		userID = update.CallbackQuery.From.ID
-	} else {
-		return "", fmt.Errorf("no user information found in update")
+		return update.Message.From, nil
+	}
+	if update.CallbackQuery != nil && update.CallbackQuery.From != nil {
+		return update.CallbackQuery.From, nil
+	}
+	return nil, fmt.Errorf("no user information found in update")
+}
+
+// GetUserID extracts user ID from update
+func (p *WebhookParser) GetUserID(update *tgbotapi.Update) (common.UserID, error) {
+	sender, err := p.GetSender(update)
+	if err != nil {
+		return "", err
 	}
 
-	return common.UserID(telegramIDToUUID(userID)), nil
+	return common.UserID(scopedTelegramIDToUUID(p.bot, sender.ID)), nil
 }
 
-// GetChatID extracts chat ID from update
-func (p *WebhookParser) GetChatID(update *tgbotapi.Update) (common.ChatID, error) {
+// GetTelegramChatID extracts the Telegram chat ID from update
+func (p *WebhookParser) GetTelegramChatID(update *tgbotapi.Update) (int64, error) {
 	if update == nil {
-		return "", fmt.Errorf("update is nil")
+		return 0, fmt.Errorf("update is nil")
 	}
 
-	var chatID int64
-
 	if update.Message != nil && update.Message.Chat != nil {
-		chatID = update.Message.Chat.ID
-	} else if update.CallbackQuery != nil && update.CallbackQuery.Message != nil {
-		chatID = update.CallbackQuery.Message.Chat.ID
-	} else {
-		return "", fmt.Errorf("no chat information found in update")
+		return update.Message.Chat.ID, nil
+	}
+	if update.CallbackQuery != nil && update.CallbackQuery.Message != nil {
+		return update.CallbackQuery.Message.Chat.ID, nil
+	}
+	return 0, fmt.Errorf("no chat information found in update")
+}
+
+// GetChatID extracts chat ID from update
+func (p *WebhookParser) GetChatID(update *tgbotapi.Update) (common.ChatID, error) {
+	chatID, err := p.GetTelegramChatID(update)
+	if err != nil {
+		return "", err
 	}
 
-	return common.ChatID(telegramIDToUUID(chatID)), nil
+	return common.ChatID(scopedTelegramIDToUUID(p.bot, chatID)), nil
 }
@@ -0,0 +1,135 @@
+package chatbot
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"go.uber.org/zap"
+)
+
+// WebhookRegistrar registers the bot's webhook with Telegram once the server
+// is serving the webhook route, retrying until Telegram accepts it. Telegram
+// starts delivering as soon as the webhook is set, so registering earlier
+// loses the first updates.
+type WebhookRegistrar struct {
+	provider        TelegramProvider
+	url             string
+	certificate     bool
+	logger          *zap.Logger
+	initialInterval time.Duration
+	maxInterval     time.Duration
+
+	mu         sync.Mutex
+	cancel     context.CancelFunc
+	done       chan struct{}
+	registered bool
+}
+
+// NewWebhookRegistrar creates a registrar for the webhook at url
+func NewWebhookRegistrar(provider TelegramProvider, url string, logger *zap.Logger) *WebhookRegistrar {
+	return NewWebhookRegistrarWithCertificate(provider, url, false, logger)
+}
+
+// NewWebhookRegistrarWithCertificate creates a registrar for a webhook whose
+// self-signed certificate the provider uploads. A webhook Telegram has without
+// a certificate is registered again.
+func NewWebhookRegistrarWithCertificate(provider TelegramProvider, url string, certificate bool, logger *zap.Logger) *WebhookRegistrar {
+	return &WebhookRegistrar{
+		provider:        provider,
+		url:             url,
+		certificate:     certificate,
+		logger:          logger,
+		initialInterval: time.Second,
+		maxInterval:     time.Minute,
+	}
+}
+
+// Registered reports whether Telegram has accepted the webhook
+func (r *WebhookRegistrar) Registered() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.registered
+}
+
+// Start launches registration in the background. It does nothing while a
+// registration is running or once the webhook is registered, and gives up
+// when ctx is done or Stop is called.
+func (r *WebhookRegistrar) Start(ctx context.Context) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cancel != nil || r.registered {
+		return
+	}
+
+	ctx, r.cancel = context.WithCancel(ctx)
+	r.done = make(chan struct{})
+	go r.run(ctx, r.done)
+}
+
+// Stop abandons a registration still retrying
+func (r *WebhookRegistrar) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	r.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *WebhookRegistrar) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	retry := backoff.NewExponentialBackOff()
+	retry.InitialInterval = r.initialInterval
+	retry.MaxInterval = r.maxInterval
+	retry.MaxElapsedTime = 0
+
+	notify := func(err error, wait time.Duration) {
+		r.logger.Warn("Failed to register webhook, retrying",
+			zap.String("webhook_url", r.url),
+			zap.Duration("retry_in", wait),
+			zap.Error(err))
+	}
+
+	err := backoff.RetryNotify(r.register, backoff.WithContext(retry, ctx), notify)
+
+	r.mu.Lock()
+	r.registered = err == nil
+	r.cancel, r.done = nil, nil
+	r.mu.Unlock()
+
+	if err != nil && !errors.Is(err, context.Canceled) {
+		r.logger.Error("Gave up registering webhook",
+			zap.String("webhook_url", r.url),
+			zap.Error(err))
+	}
+}
+
+// register sets the webhook unless Telegram already delivers to the URL, so
+// restarts don't reset Telegram's pending updates and delivery state
+func (r *WebhookRegistrar) register() error {
+	info, err := r.provider.GetWebhookInfo()
+	if err == nil && info.URL == r.url && info.HasCustomCertificate == r.certificate {
+		r.logger.Info("Webhook already registered", zap.String("webhook_url", r.url))
+		return nil
+	}
+	if err != nil {
+		// Setting the webhook is still worth trying without the current one
+		r.logger.Warn("Failed to look up the current webhook", zap.Error(err))
+	}
+	return r.provider.SetWebhook(r.url)
+}
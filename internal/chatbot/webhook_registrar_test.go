@@ -0,0 +1,101 @@
+package chatbot
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// webhookProvider remembers the webhook it was given, failing the first
+// failures attempts to set one
+type webhookProvider struct {
+	TelegramProvider
+
+	mu          sync.Mutex
+	url         string
+	certificate bool
+	failures    int
+	sets        int
+}
+
+func (p *webhookProvider) GetWebhookInfo() (tgbotapi.WebhookInfo, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return tgbotapi.WebhookInfo{URL: p.url, HasCustomCertificate: p.certificate}, nil
+}
+
+func (p *webhookProvider) SetWebhook(webhookURL string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sets++
+	if p.failures > 0 {
+		p.failures--
+		return errors.New("bad gateway")
+	}
+	p.url = webhookURL
+	return nil
+}
+
+func (p *webhookProvider) setCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.sets
+}
+
+func TestWebhookRegistrar_RetriesUntilRegistered(t *testing.T) {
+	provider := &webhookProvider{failures: 2}
+	registrar := NewWebhookRegistrar(provider, "https://bot.example.com/api/v1/telegram/webhook", zaptest.NewLogger(t))
+	registrar.initialInterval = time.Millisecond
+
+	registrar.Start(context.Background())
+	require.Eventually(t, registrar.Registered, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 3, provider.setCount())
+
+	// Once registered, later notifications leave the webhook alone
+	registrar.Start(context.Background())
+	require.NoError(t, registrar.Stop(context.Background()))
+	assert.Equal(t, 3, provider.setCount())
+}
+
+func TestWebhookRegistrar_SkipsWebhookAlreadySet(t *testing.T) {
+	url := "https://bot.example.com/api/v1/telegram/webhook"
+	provider := &webhookProvider{url: url}
+	registrar := NewWebhookRegistrar(provider, url, zaptest.NewLogger(t))
+
+	registrar.Start(context.Background())
+	require.Eventually(t, registrar.Registered, time.Second, 5*time.Millisecond)
+	assert.Zero(t, provider.setCount())
+}
+
+func TestWebhookRegistrar_ReregistersWithoutCertificate(t *testing.T) {
+	url := "https://203.0.113.7:8443/api/v1/telegram/webhook"
+	provider := &webhookProvider{url: url}
+	registrar := NewWebhookRegistrarWithCertificate(provider, url, true, zaptest.NewLogger(t))
+
+	registrar.Start(context.Background())
+	require.Eventually(t, registrar.Registered, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 1, provider.setCount(), "the certificate is uploaded again")
+}
+
+func TestWebhookRegistrar_StopAbandonsRetries(t *testing.T) {
+	provider := &webhookProvider{failures: 1000}
+	registrar := NewWebhookRegistrar(provider, "https://bot.example.com/api/v1/telegram/webhook", zaptest.NewLogger(t))
+	registrar.initialInterval = time.Millisecond
+	registrar.maxInterval = time.Millisecond
+
+	registrar.Start(context.Background())
+	require.Eventually(t, func() bool { return provider.setCount() > 1 }, time.Second, time.Millisecond)
+	require.NoError(t, registrar.Stop(context.Background()))
+	assert.False(t, registrar.Registered())
+
+	sets := provider.setCount()
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, sets, provider.setCount())
+}
@@ -0,0 +1,232 @@
+package chatbot
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// sparkBlocks draw a sparkline, lowest value first
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// breakdownPriorities lists the priorities in the breakdown, most urgent first
+var breakdownPriorities = []struct {
+	priority string
+	label    string
+}{
+	{"urgent", "🚨 Urgent"},
+	{"high", "🔴 High"},
+	{"medium", "🟡 Medium"},
+	{"low", "🟢 Low"},
+}
+
+// breakdownTags caps the tags listed in the breakdown
+const breakdownTags = 5
+
+// formatWeeklyStats renders the /stats report, with the tasks postponed during
+// the week, how often each has slipped, where the open tasks stand and the
+// 30-day burndown
+func formatWeeklyStats(event events.WeeklyStatsResponse) string {
+	return formatWeek(event) + formatBreakdown(event.Breakdown) + formatBurndown(event.Burndown)
+}
+
+// formatWeek renders the week's counts and slipping tasks
+func formatWeek(event events.WeeklyStatsResponse) string {
+	var text strings.Builder
+	text.WriteString("📊 <b>Your Week</b>\n")
+	text.WriteString(fmt.Sprintf("\n🆕 Created: %d", event.Created))
+	text.WriteString(fmt.Sprintf("\n✅ Completed: %d", event.Completed))
+	text.WriteString(fmt.Sprintf("\n📋 Open: %d", event.Open))
+	if event.Overdue > 0 {
+		text.WriteString(fmt.Sprintf("\n⏰ Overdue: %d", event.Overdue))
+	}
+	text.WriteString(fmt.Sprintf("\n😴 Postponements: %d", event.Postponements))
+
+	if len(event.Slipped) == 0 {
+		text.WriteString("\n\nNothing slipped this week. Nice work!")
+		return text.String()
+	}
+
+	text.WriteString("\n\n<b>Slipping</b>")
+	for _, task := range event.Slipped {
+		line := fmt.Sprintf("\n• %s — postponed %s", html.EscapeString(task.Title), formatTimes(task.Postponements))
+		if task.Slip > 0 {
+			line += ", " + formatSlip(task.Slip) + " later than planned"
+		}
+		text.WriteString(line)
+	}
+	return text.String()
+}
+
+// formatBreakdown renders the open tasks by priority and by tag, most used
+// tags first, and the overdue tasks by how long ago they were due
+func formatBreakdown(breakdown *events.TaskBreakdown) string {
+	if breakdown == nil {
+		return ""
+	}
+
+	var text strings.Builder
+	var priorities []string
+	for _, entry := range breakdownPriorities {
+		if count := breakdown.ByPriority[entry.priority]; count > 0 {
+			priorities = append(priorities, fmt.Sprintf("%s: %d", entry.label, count))
+		}
+	}
+	if len(priorities) > 0 {
+		text.WriteString("\n\n<b>Open by Priority</b>\n" + strings.Join(priorities, " · "))
+	}
+
+	if len(breakdown.ByTag) > 0 {
+		tags := make([]string, 0, len(breakdown.ByTag))
+		for tag := range breakdown.ByTag {
+			tags = append(tags, tag)
+		}
+		sort.Slice(tags, func(i, j int) bool {
+			a, b := breakdown.ByTag[tags[i]], breakdown.ByTag[tags[j]]
+			if a != b {
+				return a > b
+			}
+			return tags[i] < tags[j]
+		})
+		if len(tags) > breakdownTags {
+			tags = tags[:breakdownTags]
+		}
+		for i, tag := range tags {
+			tags[i] = fmt.Sprintf("#%s %d", html.EscapeString(tag), breakdown.ByTag[tag])
+		}
+		text.WriteString("\n\n<b>Top Tags</b>\n" + strings.Join(tags, " · "))
+	}
+
+	buckets := []struct {
+		label string
+		count int
+	}{
+		{"under a day", breakdown.OverdueUnderADay},
+		{"1–3 days", breakdown.OverdueOneToThree},
+		{"3–7 days", breakdown.OverdueThreeToSeven},
+		{"over a week", breakdown.OverdueOverAWeek},
+	}
+	var overdue []string
+	for _, bucket := range buckets {
+		if bucket.count > 0 {
+			overdue = append(overdue, fmt.Sprintf("%s: %d", bucket.label, bucket.count))
+		}
+	}
+	if len(overdue) > 0 {
+		text.WriteString("\n\n<b>Overdue For</b>\n" + strings.Join(overdue, " · "))
+	}
+	return text.String()
+}
+
+// formatBurndown renders the burndown as sparklines of the open tasks and of
+// the tasks completed since the first day
+func formatBurndown(days []events.BurndownDay) string {
+	if len(days) == 0 {
+		return ""
+	}
+
+	open := make([]int, len(days))
+	burnup := make([]int, len(days))
+	completed := 0
+	for i, day := range days {
+		open[i] = day.Open
+		completed += day.Completed
+		burnup[i] = completed
+	}
+
+	first, last := days[0].Open, days[len(days)-1].Open
+	return fmt.Sprintf("\n\n<b>Last %d Days</b>\n📉 Open %s %d → %d\n📈 Done %s %d completed",
+		len(days), sparkline(open), first, last, sparkline(burnup), completed)
+}
+
+// sparkline draws the values scaled between zero and their maximum
+func sparkline(values []int) string {
+	highest := 0
+	for _, value := range values {
+		if value > highest {
+			highest = value
+		}
+	}
+
+	var line strings.Builder
+	for _, value := range values {
+		level := 0
+		if highest > 0 {
+			level = value * (len(sparkBlocks) - 1) / highest
+		}
+		line.WriteRune(sparkBlocks[level])
+	}
+	return line.String()
+}
+
+// formatSlippedNotice is the gentle nudge sent when a task keeps being postponed
+func formatSlippedNotice(event events.TaskSlipped) string {
+	return fmt.Sprintf("🤔 <b>%s</b> has been postponed %s.\n\n"+
+		"No judgement, it happens. Could a smaller first step help, or is it time to let it go? "+
+		"You can split it into a new task or delete it with /delete.",
+		html.EscapeString(event.Title), formatTimes(event.Postponements))
+}
+
+// formatTimes spells out a count of occurrences
+func formatTimes(count int) string {
+	switch count {
+	case 1:
+		return "once"
+	case 2:
+		return "twice"
+	default:
+		return fmt.Sprintf("%d times", count)
+	}
+}
+
+// formatSlip rounds how far a due date moved to days, or hours when under a day
+func formatSlip(slip time.Duration) string {
+	if days := int(slip.Hours() / 24); days > 0 {
+		if days == 1 {
+			return "1 day"
+		}
+		return fmt.Sprintf("%d days", days)
+	}
+	if hours := int(slip.Hours()); hours > 1 {
+		return fmt.Sprintf("%d hours", hours)
+	}
+	return "1 hour"
+}
+
+// handleWeeklyStatsResponse sends the /stats report to the chat
+func (s *chatbotService) handleWeeklyStatsResponse(event events.WeeklyStatsResponse) {
+	if !s.ownsUser(event.UserID) {
+		return
+	}
+
+	messageText := formatWeeklyStats(event)
+	if !event.Success {
+		messageText = fmt.Sprintf("❌ <b>Stats Unavailable</b>\n\n%s", event.Message)
+	}
+
+	if err := s.SendMessage(common.ChatID(event.ChatID), messageText); err != nil {
+		s.logger.Error("Failed to send weekly stats",
+			zap.String("correlation_id", event.CorrelationID),
+			zap.Error(err))
+	}
+}
+
+// handleTaskSlipped sends an accountability message for a task that keeps slipping
+func (s *chatbotService) handleTaskSlipped(event events.TaskSlipped) {
+	if !s.ownsUser(event.UserID) {
+		return
+	}
+
+	if err := s.SendMessage(common.ChatID(event.ChatID), formatSlippedNotice(event)); err != nil {
+		s.logger.Error("Failed to send slippage notice",
+			zap.String("task_id", event.TaskID),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,69 @@
+package chatbot
+
+import (
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatWeeklyStats(t *testing.T) {
+	t.Run("slipping tasks are reported", func(t *testing.T) {
+		text := formatWeeklyStats(events.WeeklyStatsResponse{
+			Created: 5, Completed: 3, Open: 4, Overdue: 1, Postponements: 6,
+			Slipped: []events.TaskSlippage{
+				{Title: "File <taxes>", Postponements: 4, Slip: 50 * time.Hour},
+				{Title: "Call mom", Postponements: 2, Slip: 3 * time.Hour},
+				{Title: "Stretch", Postponements: 1},
+			},
+		})
+
+		assert.Contains(t, text, "✅ Completed: 3")
+		assert.Contains(t, text, "⏰ Overdue: 1")
+		assert.Contains(t, text, "File &lt;taxes&gt; — postponed 4 times, 2 days later than planned")
+		assert.Contains(t, text, "Call mom — postponed twice, 3 hours later than planned")
+		assert.Contains(t, text, "Stretch — postponed once")
+	})
+
+	t.Run("a week without slippage", func(t *testing.T) {
+		text := formatWeeklyStats(events.WeeklyStatsResponse{Completed: 2})
+		assert.NotContains(t, text, "Overdue")
+		assert.Contains(t, text, "Nothing slipped this week.")
+	})
+
+	t.Run("open task breakdown", func(t *testing.T) {
+		text := formatWeeklyStats(events.WeeklyStatsResponse{
+			Breakdown: &events.TaskBreakdown{
+				ByPriority:        map[string]int{"high": 2, "low": 1},
+				ByTag:             map[string]int{"a": 1, "b": 1, "c": 1, "d": 1, "<home>": 3, "work": 2},
+				OverdueOneToThree: 1,
+				OverdueOverAWeek:  2,
+			},
+		})
+		assert.Contains(t, text, "<b>Open by Priority</b>\n🔴 High: 2 · 🟢 Low: 1")
+		assert.Contains(t, text, "<b>Top Tags</b>\n#&lt;home&gt; 3 · #work 2 · #a 1 · #b 1 · #c 1\n")
+		assert.Contains(t, text, "<b>Overdue For</b>\n1–3 days: 1 · over a week: 2")
+	})
+
+	t.Run("burndown sparklines", func(t *testing.T) {
+		day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+		text := formatWeeklyStats(events.WeeklyStatsResponse{
+			Burndown: []events.BurndownDay{
+				{Day: day, Open: 8, Completed: 0},
+				{Day: day.AddDate(0, 0, 1), Open: 4, Completed: 3},
+				{Day: day.AddDate(0, 0, 2), Open: 0, Completed: 4},
+			},
+		})
+		assert.Contains(t, text, "<b>Last 3 Days</b>")
+		assert.Contains(t, text, "📉 Open █▄▁ 8 → 0")
+		assert.Contains(t, text, "📈 Done ▁▄█ 7 completed")
+	})
+}
+
+func TestFormatSlippedNotice(t *testing.T) {
+	text := formatSlippedNotice(events.TaskSlipped{Title: "Clean garage", Postponements: 3})
+	assert.Contains(t, text, "<b>Clean garage</b> has been postponed 3 times.")
+	assert.Contains(t, text, "smaller first step")
+}
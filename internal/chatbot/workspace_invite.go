@@ -0,0 +1,75 @@
+package chatbot
+
+import (
+	"fmt"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// InviteStartPrefix marks /start payloads that redeem a workspace invite
+const InviteStartPrefix = "join_"
+
+// InviteDeepLink returns the t.me link that opens the bot and redeems the invite
+func InviteDeepLink(botUsername, code string) string {
+	return fmt.Sprintf("https://t.me/%s?start=%s%s", botUsername, InviteStartPrefix, code)
+}
+
+// handleWorkspaceInviteResponse sends the invite link created for /invite
+func (s *chatbotService) handleWorkspaceInviteResponse(event events.WorkspaceInviteResponse) {
+	if !s.ownsUser(event.UserID) {
+		return
+	}
+
+	s.logger.Info("Handling WorkspaceInviteResponse event",
+		zap.String("correlation_id", event.CorrelationID),
+		zap.String("user_id", event.UserID),
+		zap.String("chat_id", event.ChatID),
+		zap.Bool("success", event.Success))
+
+	text := fmt.Sprintf("❌ <b>Invite Failed</b>\n\n%s", event.Message)
+	if event.Success {
+		bot, err := s.provider.GetMe()
+		if err != nil {
+			s.logger.Error("Failed to look up bot username for invite link",
+				zap.String("correlation_id", event.CorrelationID),
+				zap.Error(err))
+			text = "❌ <b>Invite Failed</b>\n\nCould not build the invite link, please try again."
+		} else {
+			text = fmt.Sprintf("🔗 <b>Invite Link</b> (%s)\n\n%s\n\n%s",
+				event.Role, InviteDeepLink(bot.UserName, event.Code), event.Message)
+		}
+	}
+
+	if err := s.SendMessage(common.ChatID(event.ChatID), text); err != nil {
+		s.logger.Error("Failed to send workspace invite",
+			zap.String("correlation_id", event.CorrelationID),
+			zap.Error(err))
+	}
+}
+
+// handleWorkspaceJoinResponse tells the user whether the invite worked
+func (s *chatbotService) handleWorkspaceJoinResponse(event events.WorkspaceJoinResponse) {
+	if !s.ownsUser(event.UserID) {
+		return
+	}
+
+	s.logger.Info("Handling WorkspaceJoinResponse event",
+		zap.String("correlation_id", event.CorrelationID),
+		zap.String("user_id", event.UserID),
+		zap.String("chat_id", event.ChatID),
+		zap.Bool("success", event.Success))
+
+	text := fmt.Sprintf("❌ <b>Could Not Join</b>\n\n%s", event.Message)
+	if event.Success {
+		text = fmt.Sprintf("👥 <b>Joined Workspace</b>\n\n%s", event.Message)
+	}
+
+	if err := s.SendMessage(common.ChatID(event.ChatID), text); err != nil {
+		s.logger.Error("Failed to send workspace join result",
+			zap.String("correlation_id", event.CorrelationID),
+			zap.Error(err))
+	}
+}
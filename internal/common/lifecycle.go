@@ -0,0 +1,34 @@
+package common
+
+import (
+	"context"
+	"errors"
+)
+
+// Lifecycle errors reported by Service.Health
+var (
+	ErrServiceNotReady = errors.New("service not ready")
+	ErrServiceStopped  = errors.New("service stopped")
+)
+
+// Service is the lifecycle shared by the long-running modules so the server
+// can start, stop and health-check them uniformly
+type Service interface {
+	// Start begins background work. Event subscriptions are registered by the
+	// constructors, so services without background work only mark themselves running.
+	Start(ctx context.Context) error
+
+	// Stop finishes in-flight work, giving up when ctx is done
+	Stop(ctx context.Context) error
+
+	// Health returns nil while the service is able to do its work
+	Health() error
+}
+
+// ServingHook is implemented by services with work that needs the HTTP server
+// to be serving their routes first, such as registering a Telegram webhook
+type ServingHook interface {
+	// Serving is called once the server is listening with every route
+	// installed. Work that may be slow runs in the background until ctx is done.
+	Serving(ctx context.Context)
+}
@@ -0,0 +1,67 @@
+package common
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+type logFlowKey struct{}
+
+// LogFlow identifies the user flow a log line belongs to
+type LogFlow struct {
+	UserID        string
+	ChatID        string
+	CorrelationID string
+}
+
+// WithLogFlow returns a context carrying the flow. Empty fields keep the
+// values of a flow already carried by ctx.
+func WithLogFlow(ctx context.Context, flow LogFlow) context.Context {
+	if current, ok := LogFlowFromContext(ctx); ok {
+		if flow.UserID == "" {
+			flow.UserID = current.UserID
+		}
+		if flow.ChatID == "" {
+			flow.ChatID = current.ChatID
+		}
+		if flow.CorrelationID == "" {
+			flow.CorrelationID = current.CorrelationID
+		}
+	}
+	return context.WithValue(ctx, logFlowKey{}, flow)
+}
+
+// LogFlowFromContext returns the flow carried by ctx
+func LogFlowFromContext(ctx context.Context) (LogFlow, bool) {
+	flow, ok := ctx.Value(logFlowKey{}).(LogFlow)
+	return flow, ok
+}
+
+// ContextLogger returns a child of base that tags every line with the user_id,
+// chat_id and correlation_id carried by ctx. Base is returned as is when ctx
+// carries no flow.
+func ContextLogger(ctx context.Context, base *zap.Logger) *zap.Logger {
+	flow, ok := LogFlowFromContext(ctx)
+	if !ok {
+		return base
+	}
+
+	fields := make([]zap.Field, 0, 3)
+	if flow.UserID != "" {
+		fields = append(fields, zap.String("user_id", flow.UserID))
+	}
+	if flow.ChatID != "" {
+		fields = append(fields, zap.String("chat_id", flow.ChatID))
+	}
+	if flow.CorrelationID != "" {
+		fields = append(fields, zap.String("correlation_id", flow.CorrelationID))
+	}
+	return base.With(fields...)
+}
+
+// FlowLogger returns a child of base tagged with the flow, for code that has
+// no context to carry it, such as event handlers
+func FlowLogger(base *zap.Logger, flow LogFlow) *zap.Logger {
+	return ContextLogger(WithLogFlow(context.Background(), flow), base)
+}
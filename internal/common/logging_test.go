@@ -0,0 +1,40 @@
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestContextLogger_TagsFlowFields(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	base := zap.New(core)
+
+	ctx := WithLogFlow(context.Background(), LogFlow{UserID: "u1", ChatID: "c1", CorrelationID: "corr"})
+	ContextLogger(ctx, base).Info("handled", zap.String("task_id", "t1"))
+
+	entry := logs.All()[0]
+	assert.Equal(t, map[string]interface{}{
+		"user_id":        "u1",
+		"chat_id":        "c1",
+		"correlation_id": "corr",
+		"task_id":        "t1",
+	}, entry.ContextMap())
+}
+
+func TestWithLogFlow_KeepsOuterFields(t *testing.T) {
+	ctx := WithLogFlow(context.Background(), LogFlow{UserID: "u1", ChatID: "c1", CorrelationID: "outer"})
+	ctx = WithLogFlow(ctx, LogFlow{CorrelationID: "inner"})
+
+	flow, ok := LogFlowFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, LogFlow{UserID: "u1", ChatID: "c1", CorrelationID: "inner"}, flow)
+}
+
+func TestContextLogger_WithoutFlow(t *testing.T) {
+	base := zap.NewNop()
+	assert.Same(t, base, ContextLogger(context.Background(), base))
+}
@@ -0,0 +1,57 @@
+package common
+
+import (
+	"context"
+	"sync"
+)
+
+// ReadySignaler is implemented by services that signal when they can handle
+// events, typically once their event subscriptions are registered
+type ReadySignaler interface {
+	Ready() <-chan struct{}
+}
+
+// Readiness is a one-shot ready signal
+type Readiness struct {
+	once sync.Once
+	ch   chan struct{}
+}
+
+// NewReadiness creates a signal that is not ready yet
+func NewReadiness() *Readiness {
+	return &Readiness{ch: make(chan struct{})}
+}
+
+// MarkReady closes the ready channel; later calls do nothing
+func (r *Readiness) MarkReady() {
+	r.once.Do(func() {
+		close(r.ch)
+	})
+}
+
+// Ready returns a channel that is closed once MarkReady has been called
+func (r *Readiness) Ready() <-chan struct{} {
+	return r.ch
+}
+
+// IsReady reports whether MarkReady has been called
+func (r *Readiness) IsReady() bool {
+	select {
+	case <-r.ch:
+		return true
+	default:
+		return false
+	}
+}
+
+// WaitForReady blocks until every service is ready or the context is done
+func WaitForReady(ctx context.Context, services ...ReadySignaler) error {
+	for _, service := range services {
+		select {
+		case <-service.Ready():
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
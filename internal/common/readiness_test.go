@@ -0,0 +1,46 @@
+package common
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadiness_MarkReady(t *testing.T) {
+	readiness := NewReadiness()
+
+	select {
+	case <-readiness.Ready():
+		t.Fatal("readiness should not be signalled before MarkReady")
+	default:
+	}
+
+	readiness.MarkReady()
+	readiness.MarkReady() // second call must not panic
+
+	select {
+	case <-readiness.Ready():
+	default:
+		t.Fatal("readiness should be signalled after MarkReady")
+	}
+}
+
+func TestWaitForReady(t *testing.T) {
+	first, second := NewReadiness(), NewReadiness()
+	first.MarkReady()
+
+	go second.MarkReady()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, WaitForReady(ctx, first, second))
+}
+
+func TestWaitForReady_Timeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, WaitForReady(ctx, NewReadiness()), context.DeadlineExceeded)
+}
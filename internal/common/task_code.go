@@ -0,0 +1,36 @@
+package common
+
+import (
+	"strconv"
+	"strings"
+)
+
+// TaskCodePrefix starts the short codes users type instead of task IDs
+const TaskCodePrefix = "T-"
+
+// FormatTaskCode returns the short code of a task number, such as T-42, or ""
+// for tasks created before codes were assigned
+func FormatTaskCode(number int) string {
+	if number <= 0 {
+		return ""
+	}
+	return TaskCodePrefix + strconv.Itoa(number)
+}
+
+// ParseTaskCode returns the task number of a short code. The prefix is not
+// case sensitive and its dash is optional, so "t42" is T-42.
+func ParseTaskCode(code string) (int, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if !strings.HasPrefix(code, "T") {
+		return 0, false
+	}
+	digits := strings.TrimPrefix(strings.TrimPrefix(code, "T"), "-")
+	if digits == "" || digits[0] == '+' || digits[0] == '-' {
+		return 0, false
+	}
+	number, err := strconv.Atoi(digits)
+	if err != nil || number <= 0 {
+		return 0, false
+	}
+	return number, true
+}
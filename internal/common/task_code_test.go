@@ -0,0 +1,39 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatTaskCode(t *testing.T) {
+	assert.Equal(t, "T-42", FormatTaskCode(42))
+	assert.Equal(t, "", FormatTaskCode(0))
+}
+
+func TestParseTaskCode(t *testing.T) {
+	tests := []struct {
+		code   string
+		number int
+		ok     bool
+	}{
+		{"T-42", 42, true},
+		{"t-7", 7, true},
+		{"T42", 42, true},
+		{" T-1 ", 1, true},
+		{"T-0", 0, false},
+		{"T--3", 0, false},
+		{"T-", 0, false},
+		{"42", 0, false},
+		{"Tea", 0, false},
+		{"550e8400-e29b-41d4-a716-446655440000", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			number, ok := ParseTaskCode(tt.code)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.number, number)
+		})
+	}
+}
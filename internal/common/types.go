@@ -52,12 +52,30 @@ type (
 type TaskStatus string
 
 const (
-	TaskStatusActive    TaskStatus = "active"
-	TaskStatusCompleted TaskStatus = "completed"
-	TaskStatusSnoozed   TaskStatus = "snoozed"
-	TaskStatusDeleted   TaskStatus = "deleted"
+	TaskStatusActive     TaskStatus = "active"
+	TaskStatusInProgress TaskStatus = "in_progress"
+	TaskStatusWaiting    TaskStatus = "waiting"
+	TaskStatusCompleted  TaskStatus = "completed"
+	TaskStatusSnoozed    TaskStatus = "snoozed"
+	TaskStatusDeleted    TaskStatus = "deleted"
 )
 
+// TaskStatuses lists every task status in board order. A new status is added
+// here and to the nudge status manager's transition matrix.
+var TaskStatuses = []TaskStatus{
+	TaskStatusActive,
+	TaskStatusInProgress,
+	TaskStatusWaiting,
+	TaskStatusSnoozed,
+	TaskStatusCompleted,
+	TaskStatusDeleted,
+}
+
+// OpenTaskStatuses returns the statuses of tasks that still need doing
+func OpenTaskStatuses() []TaskStatus {
+	return []TaskStatus{TaskStatusActive, TaskStatusInProgress, TaskStatusWaiting}
+}
+
 // String returns the string representation of TaskStatus
 func (ts TaskStatus) String() string {
 	return string(ts)
@@ -65,12 +83,22 @@ func (ts TaskStatus) String() string {
 
 // IsValid checks if the TaskStatus is valid
 func (ts TaskStatus) IsValid() bool {
-	switch ts {
-	case TaskStatusActive, TaskStatusCompleted, TaskStatusSnoozed, TaskStatusDeleted:
-		return true
-	default:
-		return false
+	for _, status := range TaskStatuses {
+		if ts == status {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOpen reports whether a task with this status still needs doing
+func (ts TaskStatus) IsOpen() bool {
+	for _, status := range OpenTaskStatuses() {
+		if ts == status {
+			return true
+		}
 	}
+	return false
 }
 
 // Priority represents the priority level of a task
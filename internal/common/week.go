@@ -0,0 +1,153 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Week is how a user's week runs: the day it starts on and the days they work
+type Week struct {
+	Start       time.Weekday
+	WorkingDays [7]bool // indexed by time.Weekday
+}
+
+// DefaultWeek starts on Monday, with Monday to Friday as working days
+var DefaultWeek = Week{
+	Start:       time.Monday,
+	WorkingDays: [7]bool{time.Monday: true, time.Tuesday: true, time.Wednesday: true, time.Thursday: true, time.Friday: true},
+}
+
+// NewWeek builds a week from settings as stored: a weekday name and working
+// days in any form ParseWorkingDays accepts. Empty or invalid values keep
+// DefaultWeek's.
+func NewWeek(weekStart, workingDays string) Week {
+	week := DefaultWeek
+	if start, ok := ParseWeekday(weekStart); ok {
+		week.Start = start
+	}
+	if days, err := ParseWorkingDays(workingDays); err == nil {
+		week.WorkingDays = days
+	}
+	return week
+}
+
+// ParseWeekday parses a weekday name such as "Sunday" or "sun"
+func ParseWeekday(name string) (time.Weekday, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if len(name) < 3 {
+		return 0, false
+	}
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if strings.HasPrefix(strings.ToLower(weekday.String()), name) {
+			return weekday, true
+		}
+	}
+	return 0, false
+}
+
+// ParseWorkingDays parses working days written as ranges such as "mon-fri"
+// or "sun-thu", as a list such as "mon,tue,thu", or a mix of both
+func ParseWorkingDays(value string) ([7]bool, error) {
+	var days [7]bool
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return days, fmt.Errorf("no working days given")
+	}
+
+	for _, part := range strings.Split(value, ",") {
+		first, last, isRange := strings.Cut(part, "-")
+		from, ok := ParseWeekday(first)
+		if !ok {
+			return days, fmt.Errorf("%q is not a weekday", strings.TrimSpace(first))
+		}
+		to := from
+		if isRange {
+			if to, ok = ParseWeekday(last); !ok {
+				return days, fmt.Errorf("%q is not a weekday", strings.TrimSpace(last))
+			}
+		}
+		// Ranges may wrap past Saturday, as in fri-mon
+		for day := from; ; day = (day + 1) % 7 {
+			days[day] = true
+			if day == to {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+// FormatWorkingDays writes working days the way settings store them, such as
+// "mon,tue,wed,thu,fri"
+func FormatWorkingDays(days [7]bool) string {
+	var names []string
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		if days[weekday] {
+			names = append(names, strings.ToLower(weekday.String()[:3]))
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// Days returns the days of the week in order, starting with Start
+func (w Week) Days() []time.Weekday {
+	days := make([]time.Weekday, 7)
+	for i := range days {
+		days[i] = (w.Start + time.Weekday(i)) % 7
+	}
+	return days
+}
+
+// DescribeWorkingDays names the working days in week order, as a range such
+// as "Sun–Thu" when they follow each other and as a list otherwise
+func (w Week) DescribeWorkingDays() string {
+	var working []time.Weekday
+	for _, day := range w.Days() {
+		if w.WorkingDays[day] {
+			working = append(working, day)
+		}
+	}
+
+	switch {
+	case len(working) == 0:
+		return "none"
+	case len(working) == 7:
+		return "every day"
+	case len(working) > 2 && int(working[len(working)-1]-working[0]+7)%7 == len(working)-1:
+		return working[0].String()[:3] + "–" + working[len(working)-1].String()[:3]
+	}
+
+	names := make([]string, len(working))
+	for i, day := range working {
+		names[i] = day.String()[:3]
+	}
+	return strings.Join(names, ", ")
+}
+
+// StartOf returns midnight on the first day of the week containing t, in t's
+// location
+func (w Week) StartOf(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return day.AddDate(0, 0, -int((day.Weekday()-w.Start+7)%7))
+}
+
+// IsWorkingDay reports whether t falls on a working day
+func (w Week) IsWorkingDay(t time.Time) bool {
+	return w.WorkingDays[t.Weekday()]
+}
+
+// LastWorkingDay returns midnight on the last working day of the week
+// containing t, or on the week's last day when none is a working day
+func (w Week) LastWorkingDay(t time.Time) time.Time {
+	start := w.StartOf(t)
+	for offset := 6; offset > 0; offset-- {
+		if day := start.AddDate(0, 0, offset); w.IsWorkingDay(day) {
+			return day
+		}
+	}
+	if w.IsWorkingDay(start) {
+		return start
+	}
+	return start.AddDate(0, 0, 6)
+}
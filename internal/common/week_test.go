@@ -0,0 +1,52 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWorkingDays(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"mon-fri", "mon,tue,wed,thu,fri"},
+		{"Sun-Thu", "sun,mon,tue,wed,thu"},
+		{"mon, tue,thursday", "mon,tue,thu"},
+		{"fri-mon", "sun,mon,fri,sat"},
+		{"sat", "sat"},
+	}
+	for _, tt := range tests {
+		days, err := ParseWorkingDays(tt.value)
+		require.NoError(t, err, tt.value)
+		assert.Equal(t, tt.want, FormatWorkingDays(days), tt.value)
+	}
+
+	for _, value := range []string{"", "mon-funday", "weekdays", "mo"} {
+		_, err := ParseWorkingDays(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestWeek(t *testing.T) {
+	// Wednesday
+	now := time.Date(2024, 6, 5, 14, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC), DefaultWeek.StartOf(now))
+	assert.Equal(t, time.Date(2024, 6, 7, 0, 0, 0, 0, time.UTC), DefaultWeek.LastWorkingDay(now))
+	assert.Equal(t, "Mon–Fri", DefaultWeek.DescribeWorkingDays())
+
+	gulf := NewWeek("sunday", "sun-thu")
+	assert.Equal(t, time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC), gulf.StartOf(now))
+	assert.Equal(t, time.Date(2024, 6, 6, 0, 0, 0, 0, time.UTC), gulf.LastWorkingDay(now))
+	assert.False(t, gulf.IsWorkingDay(time.Date(2024, 6, 7, 9, 0, 0, 0, time.UTC)), "Friday is off")
+	assert.Equal(t, "Sun–Thu", gulf.DescribeWorkingDays())
+
+	// A Saturday start wraps the week across Sunday
+	assert.Equal(t, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), NewWeek("sat", "").StartOf(now))
+	assert.Equal(t, "Mon, Tue, Thu", NewWeek("", "mon,tue,thu").DescribeWorkingDays())
+	assert.Equal(t, DefaultWeek, NewWeek("someday", "nope"), "invalid values keep the defaults")
+}
@@ -0,0 +1,122 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultHour is the time of day "tomorrow" or a weekday stands for when no
+// time is given
+const DefaultHour = 9
+
+// ErrWhenPassed is returned by ParseWhen for times that are not after now
+var ErrWhenPassed = errors.New("that time has already passed")
+
+// WhenUsage describes the expressions ParseWhen accepts
+const WhenUsage = "a duration such as 30m, 2h or 1d, or a time such as 9am, tomorrow 9am, friday 14:00 or 2024-06-01"
+
+// ParseDuration parses a positive duration such as "30m", "2h", "1h30m" or
+// "1d", where "d" counts whole days
+func ParseDuration(argument string) (time.Duration, error) {
+	argument = strings.ToLower(strings.TrimSpace(argument))
+
+	var (
+		duration time.Duration
+		err      error
+	)
+	if days, ok := strings.CutSuffix(argument, "d"); ok {
+		var count int
+		count, err = strconv.Atoi(days)
+		duration = time.Duration(count) * 24 * time.Hour
+	} else {
+		duration, err = time.ParseDuration(argument)
+	}
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("%q is not a duration such as 30m, 2h or 1d", argument)
+	}
+	return duration, nil
+}
+
+// ParseWhen resolves a duration from now, or a day and time of day, to the
+// instant it names. Days are today, tomorrow, a weekday or a YYYY-MM-DD date;
+// times are 9am, 9:30pm, 21:00 or noon. A day without a time stands for
+// DefaultHour, and a time without a day for its next occurrence. Times are
+// read in now's location and must be after now.
+func ParseWhen(argument string, now time.Time) (time.Time, error) {
+	argument = strings.ToLower(strings.TrimSpace(argument))
+	if argument == "" {
+		return time.Time{}, fmt.Errorf("expected %s", WhenUsage)
+	}
+
+	fields := strings.Fields(argument)
+	if len(fields) == 1 {
+		if duration, err := ParseDuration(fields[0]); err == nil {
+			return now.Add(duration), nil
+		}
+	}
+	if len(fields) > 2 {
+		return time.Time{}, fmt.Errorf("%q is not %s", argument, WhenUsage)
+	}
+
+	day, dayOK := parseDay(fields[0], now)
+	hour, minute, timeOK := parseClock(fields[len(fields)-1])
+	switch {
+	case len(fields) == 2 && dayOK && timeOK:
+	case len(fields) == 1 && dayOK:
+		hour, minute = DefaultHour, 0
+	case len(fields) == 1 && timeOK:
+		day = now
+	default:
+		return time.Time{}, fmt.Errorf("%q is not %s", argument, WhenUsage)
+	}
+
+	when := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, now.Location())
+	if len(fields) == 1 && timeOK && !when.After(now) {
+		when = when.AddDate(0, 0, 1)
+	}
+	if !when.After(now) {
+		return time.Time{}, ErrWhenPassed
+	}
+	return when, nil
+}
+
+// parseDay returns the day a word names, relative to now
+func parseDay(word string, now time.Time) (time.Time, bool) {
+	switch word {
+	case "today":
+		return now, true
+	case "tomorrow":
+		return now.AddDate(0, 0, 1), true
+	}
+
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		full := strings.ToLower(weekday.String())
+		if word == full || word == full[:3] {
+			ahead := (int(weekday) - int(now.Weekday()) + 7) % 7
+			if ahead == 0 {
+				ahead = 7
+			}
+			return now.AddDate(0, 0, ahead), true
+		}
+	}
+
+	date, err := time.ParseInLocation("2006-01-02", word, now.Location())
+	return date, err == nil
+}
+
+// parseClock returns the time of day a word names
+func parseClock(word string) (int, int, bool) {
+	if word == "noon" {
+		return 12, 0, true
+	}
+
+	for _, layout := range []string{"3pm", "3:04pm", "15:04"} {
+		if clock, err := time.Parse(layout, word); err == nil {
+			return clock.Hour(), clock.Minute(), true
+		}
+	}
+	return 0, 0, false
+}
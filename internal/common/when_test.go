@@ -0,0 +1,63 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDuration(t *testing.T) {
+	for argument, want := range map[string]time.Duration{"30m": 30 * time.Minute, "2H": 2 * time.Hour, "1h30m": 90 * time.Minute, "1d": 24 * time.Hour} {
+		duration, err := ParseDuration(argument)
+		require.NoError(t, err, argument)
+		assert.Equal(t, want, duration, argument)
+	}
+	for _, argument := range []string{"", "soon", "0h", "-2h", "xd"} {
+		_, err := ParseDuration(argument)
+		assert.Error(t, err, argument)
+	}
+}
+
+func TestParseWhen(t *testing.T) {
+	saigon := time.FixedZone("ICT", 7*60*60)
+	// Wednesday afternoon
+	now := time.Date(2024, 6, 5, 14, 30, 0, 0, saigon)
+
+	tests := []struct {
+		argument string
+		want     time.Time
+	}{
+		{"2h", now.Add(2 * time.Hour)},
+		{"tomorrow", time.Date(2024, 6, 6, 9, 0, 0, 0, saigon)},
+		{"Tomorrow 9am", time.Date(2024, 6, 6, 9, 0, 0, 0, saigon)},
+		{"today 5:15pm", time.Date(2024, 6, 5, 17, 15, 0, 0, saigon)},
+		{"9am", time.Date(2024, 6, 6, 9, 0, 0, 0, saigon)},
+		{"21:00", time.Date(2024, 6, 5, 21, 0, 0, 0, saigon)},
+		{"noon", time.Date(2024, 6, 6, 12, 0, 0, 0, saigon)},
+		{"fri 14:00", time.Date(2024, 6, 7, 14, 0, 0, 0, saigon)},
+		{"wednesday", time.Date(2024, 6, 12, 9, 0, 0, 0, saigon)},
+		{"2024-07-01", time.Date(2024, 7, 1, 9, 0, 0, 0, saigon)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.argument, func(t *testing.T) {
+			when, err := ParseWhen(tt.argument, now)
+			require.NoError(t, err)
+			assert.True(t, tt.want.Equal(when), "got %s", when)
+		})
+	}
+}
+
+func TestParseWhen_Errors(t *testing.T) {
+	now := time.Date(2024, 6, 5, 14, 30, 0, 0, time.UTC)
+
+	_, err := ParseWhen("today 9am", now)
+	assert.ErrorIs(t, err, ErrWhenPassed)
+
+	for _, argument := range []string{"", "later", "tomorrow at 9am", "25:00", "someday 9am"} {
+		_, err := ParseWhen(argument, now)
+		assert.Error(t, err, argument)
+		assert.NotErrorIs(t, err, ErrWhenPassed, argument)
+	}
+}
@@ -8,13 +8,30 @@ import (
 )
 
 type Config struct {
-	Server    ServerConfig    `mapstructure:"server"`
-	Database  DatabaseConfig  `mapstructure:"database"`
-	Chatbot   ChatbotConfig   `mapstructure:"chatbot"`
-	LLM       LLMConfig       `mapstructure:"llm"`
-	Events    EventsConfig    `mapstructure:"events"`
-	Nudge     NudgeConfig     `mapstructure:"nudge"`
-	Scheduler SchedulerConfig `mapstructure:"scheduler"`
+	Profile      string             `mapstructure:"-"` // set from NUDGEBOT_PROFILE
+	Server       ServerConfig       `mapstructure:"server"`
+	Database     DatabaseConfig     `mapstructure:"database"`
+	Chatbot      ChatbotConfig      `mapstructure:"chatbot"`
+	LLM          LLMConfig          `mapstructure:"llm"`
+	Events       EventsConfig       `mapstructure:"events"`
+	Nudge        NudgeConfig        `mapstructure:"nudge"`
+	Scheduler    SchedulerConfig    `mapstructure:"scheduler"`
+	Experiments  ExperimentsConfig  `mapstructure:"experiments"`
+	FeatureFlags FeatureFlagsConfig `mapstructure:"feature_flags"`
+	Chaos        ChaosConfig        `mapstructure:"chaos"`
+	DebugCapture DebugCaptureConfig `mapstructure:"debug_capture"`
+	Probe        ProbeConfig        `mapstructure:"probe"`
+	Notify       NotifyConfig       `mapstructure:"notify"`
+	Holidays     HolidaysConfig     `mapstructure:"holidays"`
+	Geocoding    GeocodingConfig    `mapstructure:"geocoding"`
+	WebApp       WebAppConfig       `mapstructure:"web_app"`
+	Landing      LandingConfig      `mapstructure:"landing"`
+	LoadShedding LoadSheddingConfig `mapstructure:"load_shedding"`
+	Maintenance  MaintenanceConfig  `mapstructure:"maintenance"`
+	HTTPClient   HTTPClientConfig   `mapstructure:"http_client"`
+	Startup      StartupConfig      `mapstructure:"startup"`
+	Logging      LoggingConfig      `mapstructure:"logging"`
+	Tenants      []TenantConfig     `mapstructure:"tenants"`
 }
 
 type ServerConfig struct {
@@ -22,6 +39,7 @@ type ServerConfig struct {
 	Environment  string `mapstructure:"environment"`
 	ReadTimeout  int    `mapstructure:"read_timeout"`
 	WriteTimeout int    `mapstructure:"write_timeout"`
+	AdminToken   string `mapstructure:"admin_token"`
 }
 
 type DatabaseConfig struct {
@@ -34,12 +52,82 @@ type DatabaseConfig struct {
 	MaxOpenConns    int    `mapstructure:"max_open_conns"`
 	MaxIdleConns    int    `mapstructure:"max_idle_conns"`
 	ConnMaxLifetime int    `mapstructure:"conn_max_lifetime"`
+	ConnMaxIdleTime int    `mapstructure:"conn_max_idle_time"` // seconds; 0 keeps idle connections open
+	SlowQueryMs     int    `mapstructure:"slow_query_ms"`      // 0 disables slow query logging
+	// PgBouncer marks a connection through PgBouncer in transaction pooling
+	// mode, where server-side prepared statements cannot be used
+	PgBouncer          bool `mapstructure:"pgbouncer"`
+	PreparedStatements bool `mapstructure:"prepared_statements"` // extended protocol instead of the simple protocol
+	StatementCache     bool `mapstructure:"statement_cache"`     // reuse prepared statements per connection
+	RetryAttempts      int  `mapstructure:"retry_attempts"`      // retries after the server closed the connection
+	RetryBackoffMs     int  `mapstructure:"retry_backoff_ms"`
+	// AllowDestructiveMigrations lets production migrations drop or retype
+	// columns; set it only for the deploy that is meant to
+	AllowDestructiveMigrations bool `mapstructure:"allow_destructive_migrations"`
 }
 
 type ChatbotConfig struct {
-	WebhookURL string `mapstructure:"webhook_url"`
+	Name                   string                 `mapstructure:"name"` // empty for the default bot
+	Mode                   string                 `mapstructure:"mode"` // webhook, polling or auto
+	WebhookURL             string                 `mapstructure:"webhook_url"`
+	WebhookCertificate     string                 `mapstructure:"webhook_certificate"` // PEM public certificate uploaded for a self-signed webhook
+	WebhookAllowlist       WebhookAllowlistConfig `mapstructure:"webhook_allowlist"`
+	Proxy                  string                 `mapstructure:"proxy"` // http(s):// or socks5:// proxy for Bot API calls
+	Token                  string                 `mapstructure:"token"`
+	Timeout                int                    `mapstructure:"timeout"`
+	AggregationWindowMs    int                    `mapstructure:"aggregation_window_ms"`
+	AggregationMaxMessages int                    `mapstructure:"aggregation_max_messages"`
+	TaskPreview            bool                   `mapstructure:"task_preview"`
+	UpdateQueueSize        int                    `mapstructure:"update_queue_size"`
+	PollTimeout            int                    `mapstructure:"poll_timeout"`
+	Moderation             ModerationConfig       `mapstructure:"moderation"`
+	Keyboard               KeyboardConfig         `mapstructure:"keyboard"`
+	Bots                   []BotConfig            `mapstructure:"bots"` // additional bots sharing this deployment
+	Authorization          AuthorizationConfig    `mapstructure:"authorization"`
+}
+
+// WebhookAllowlistConfig limits the webhook endpoints to the addresses Telegram
+// delivers from. TrustedProxies names the proxies in front of the server whose
+// X-Forwarded-For header is believed; without any the connecting address is
+// checked.
+type WebhookAllowlistConfig struct {
+	Enabled        bool     `mapstructure:"enabled"`
+	Ranges         []string `mapstructure:"ranges"` // CIDRs, Telegram's published ranges by default
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+}
+
+// AuthorizationConfig decides who may run restricted commands. Users are named
+// by Telegram user ID; the admin_ids hold the admin role, which may run every
+// restricted command. Commands maps a command, without its slash, to the
+// roles allowed to run it, restricting it or changing who may.
+type AuthorizationConfig struct {
+	AdminIDs []int64             `mapstructure:"admin_ids"`
+	Roles    map[string][]int64  `mapstructure:"roles"` // more roles, by name, with their users
+	Commands map[string][]string `mapstructure:"commands"`
+}
+
+// BotConfig configures an additional Telegram bot. Unset fields fall back to
+// the default bot's settings, except the token.
+type BotConfig struct {
+	Name       string `mapstructure:"name"`
 	Token      string `mapstructure:"token"`
-	Timeout    int    `mapstructure:"timeout"`
+	WebhookURL string `mapstructure:"webhook_url"`
+	Mode       string `mapstructure:"mode"`
+}
+
+type KeyboardConfig struct {
+	MaxButtonsPerRow int  `mapstructure:"max_buttons_per_row"` // 0 leaves rows unbounded
+	MaxLabelLength   int  `mapstructure:"max_label_length"`    // 0 disables truncation
+	ShowEmoji        bool `mapstructure:"show_emoji"`
+}
+
+type ModerationConfig struct {
+	Enabled      bool     `mapstructure:"enabled"`
+	Action       string   `mapstructure:"action"` // reject, flag or allow
+	BlockedWords []string `mapstructure:"blocked_words"`
+	APIEndpoint  string   `mapstructure:"api_endpoint"`
+	APIKey       string   `mapstructure:"api_key"`
+	Timeout      int      `mapstructure:"timeout"`
 }
 
 type LLMConfig struct {
@@ -48,6 +136,51 @@ type LLMConfig struct {
 	Timeout     int    `mapstructure:"timeout"`
 	MaxRetries  int    `mapstructure:"max_retries"`
 	Model       string `mapstructure:"model"`
+	Proxy       string `mapstructure:"proxy"` // http(s):// or socks5:// proxy for API calls
+
+	// Parses at or above ConfidenceHigh are saved without confirmation. Below
+	// it the user confirms a preview, which asks them to check the details
+	// below ConfidenceMedium and flags the parse as a guess below ConfidenceLow.
+	ConfidenceHigh   float64 `mapstructure:"confidence_high"`
+	ConfidenceMedium float64 `mapstructure:"confidence_medium"`
+	ConfidenceLow    float64 `mapstructure:"confidence_low"`
+
+	Audit LLMAuditConfig `mapstructure:"audit"`
+
+	// PromptDir holds prompt templates that add versions or locale variants
+	// to the built-in prompts. Empty uses the built-in prompts only.
+	PromptDir string `mapstructure:"prompt_dir"`
+
+	// MaxConcurrent caps the provider calls running at once; waiting calls
+	// are shared fairly between users. A call waiting QueueAging seconds
+	// longer outranks a user's call already running.
+	MaxConcurrent int `mapstructure:"max_concurrent"`
+	QueueAging    int `mapstructure:"queue_aging"`
+
+	// Switch is the provider users get while the llm_provider_switch feature
+	// flag is on for them
+	Switch LLMSwitchConfig `mapstructure:"switch"`
+}
+
+// LLMSwitchConfig overrides the LLM endpoint, key and model for users moved
+// to another provider; empty fields keep the main provider's. Nothing is
+// switched while all are empty.
+type LLMSwitchConfig struct {
+	APIEndpoint string `mapstructure:"api_endpoint"`
+	APIKey      string `mapstructure:"api_key"`
+	Model       string `mapstructure:"model"`
+}
+
+// IsSet reports whether a provider to switch to is configured
+func (c LLMSwitchConfig) IsSet() bool {
+	return c.APIEndpoint != "" || c.APIKey != "" || c.Model != ""
+}
+
+// LLMAuditConfig controls recording each parse's prompt, raw response and
+// result for diagnosing bad parses
+type LLMAuditConfig struct {
+	Enabled       bool `mapstructure:"enabled"`
+	RetentionDays int  `mapstructure:"retention_days"` // audits older than this are purged nightly
 }
 
 type EventsConfig struct {
@@ -60,14 +193,252 @@ type NudgeConfig struct {
 	DefaultReminderInterval int `mapstructure:"default_reminder_interval"`
 	MaxNudges               int `mapstructure:"max_nudges"`
 	CleanupInterval         int `mapstructure:"cleanup_interval"`
+	InviteTTL               int `mapstructure:"invite_ttl"`           // hours a workspace invite link stays valid
+	MergeUndoWindow         int `mapstructure:"merge_undo_window"`    // hours an account merge can be undone
+	ArchiveAfterMonths      int `mapstructure:"archive_after_months"` // completed tasks and sent reminders older than this are archived; 0 disables
+	ArchiveBatchSize        int `mapstructure:"archive_batch_size"`   // rows moved per archive statement
+	ConflictTolerance       int `mapstructure:"conflict_tolerance"`   // minutes apart two timed tasks still clash
 }
 
 type SchedulerConfig struct {
-	PollInterval    int  `mapstructure:"poll_interval"`
-	NudgeDelay      int  `mapstructure:"nudge_delay"`
-	WorkerCount     int  `mapstructure:"worker_count"`
-	ShutdownTimeout int  `mapstructure:"shutdown_timeout"`
-	Enabled         bool `mapstructure:"enabled"`
+	PollInterval     int                  `mapstructure:"poll_interval"`
+	NudgeDelay       int                  `mapstructure:"nudge_delay"`
+	WorkerCount      int                  `mapstructure:"worker_count"`
+	MinWorkers       int                  `mapstructure:"min_workers"`
+	MaxWorkers       int                  `mapstructure:"max_workers"`
+	BacklogPerWorker int                  `mapstructure:"backlog_per_worker"`
+	ShutdownTimeout  int                  `mapstructure:"shutdown_timeout"`
+	ActivityDeferral int                  `mapstructure:"activity_deferral"` // seconds
+	Enabled          bool                 `mapstructure:"enabled"`
+	Jobs             map[string]JobConfig `mapstructure:"jobs"`
+}
+
+// JobConfig overrides the schedule of a registered periodic job. Jobs without
+// an entry run on their default schedule; Enabled defaults to true when omitted.
+type JobConfig struct {
+	Schedule string `mapstructure:"schedule"`
+	Enabled  *bool  `mapstructure:"enabled"`
+}
+
+type ExperimentsConfig struct {
+	Enabled          bool                   `mapstructure:"enabled"`
+	ConversionWindow int                    `mapstructure:"conversion_window"` // hours
+	Definitions      []ExperimentDefinition `mapstructure:"definitions"`
+}
+
+type ExperimentDefinition struct {
+	Name     string              `mapstructure:"name"`
+	Active   bool                `mapstructure:"active"`
+	Variants []ExperimentVariant `mapstructure:"variants"`
+}
+
+type ExperimentVariant struct {
+	Key              string `mapstructure:"key"`
+	Weight           int    `mapstructure:"weight"`
+	ReminderTemplate string `mapstructure:"reminder_template"`
+	NudgeDelay       int    `mapstructure:"nudge_delay"` // seconds, 0 keeps the scheduler default
+}
+
+// FeatureFlagsConfig declares the feature flags known to the service. Database
+// overrides are reloaded by the feature_flags_refresh scheduled job.
+type FeatureFlagsConfig struct {
+	Flags map[string]FeatureFlagConfig `mapstructure:"flags"`
+}
+
+// FeatureFlagConfig describes who a flag is enabled for. Environments overrides
+// Enabled for the named environments, RolloutPercentage limits the flag to a
+// stable cohort of users (nil means everyone) and Users are always enabled.
+type FeatureFlagConfig struct {
+	Enabled           bool            `mapstructure:"enabled"`
+	Environments      map[string]bool `mapstructure:"environments"`
+	RolloutPercentage *int            `mapstructure:"rollout_percentage"`
+	Users             []string        `mapstructure:"users"`
+}
+
+// ChaosConfig enables random fault injection for resilience testing. Rates are
+// probabilities between 0 and 1; a zero seed picks a random one. It must never
+// be enabled in production.
+type ChaosConfig struct {
+	Enabled                 bool    `mapstructure:"enabled"`
+	Seed                    int64   `mapstructure:"seed"`
+	TelegramRateLimitRate   float64 `mapstructure:"telegram_rate_limit_rate"`
+	TelegramServerErrorRate float64 `mapstructure:"telegram_server_error_rate"`
+	DatabaseTimeoutRate     float64 `mapstructure:"database_timeout_rate"`
+	LLMErrorRate            float64 `mapstructure:"llm_error_rate"`
+}
+
+// DebugCaptureConfig enables capturing a sample of raw Telegram updates and
+// outgoing Bot API calls, redacted, for debugging missing replies. SampleRate
+// is the fraction of chats captured; Chats are always captured.
+type DebugCaptureConfig struct {
+	Enabled    bool    `mapstructure:"enabled"`
+	SampleRate float64 `mapstructure:"sample_rate"`
+	Capacity   int     `mapstructure:"capacity"` // captures kept before the oldest are dropped
+	Chats      []int64 `mapstructure:"chats"`    // Telegram chat IDs
+	RedactText bool    `mapstructure:"redact_text"`
+}
+
+// ProbeConfig enables the synthetic end-to-end probe, a periodic job that
+// drives a synthetic user through parse, create, remind and complete. Its
+// schedule can be overridden under scheduler.jobs.synthetic_probe.
+type ProbeConfig struct {
+	Enabled      bool   `mapstructure:"enabled"`
+	Message      string `mapstructure:"message"`       // text sent as the synthetic user
+	StageTimeout int    `mapstructure:"stage_timeout"` // seconds each stage may take
+	AlertAfter   int    `mapstructure:"alert_after"`   // consecutive failures before alerting
+}
+
+// NotifyConfig configures the channels reminders can be routed to besides
+// Telegram. The daily digest schedule can be overridden under
+// scheduler.jobs.reminder_digest.
+type NotifyConfig struct {
+	Email EmailConfig `mapstructure:"email"`
+}
+
+// EmailConfig is the SMTP server reminders are emailed through. An empty host
+// leaves the email channel off.
+type EmailConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	From     string `mapstructure:"from"`
+	Timeout  int    `mapstructure:"timeout"` // seconds
+}
+
+// HolidaysConfig selects where the public holidays of the countries users
+// pick in /settings come from: "builtin" knows a few countries offline,
+// "nager" fetches any country's from a Nager.Date compatible API at URL, and
+// "off" observes none.
+type HolidaysConfig struct {
+	Source  string `mapstructure:"source"`
+	URL     string `mapstructure:"url"`
+	Timeout int    `mapstructure:"timeout"` // seconds
+}
+
+// GeocodingConfig looks up the places tasks mention, such as "the post
+// office", on a Nominatim server at URL. UserAgent identifies the bot to the
+// server, which its usage policy requires.
+type GeocodingConfig struct {
+	Enabled   bool   `mapstructure:"enabled"`
+	URL       string `mapstructure:"url"`
+	UserAgent string `mapstructure:"user_agent"`
+	Timeout   int    `mapstructure:"timeout"` // seconds
+}
+
+// WebAppConfig serves the Telegram Web App for managing tasks at /webapp/.
+// Its requests are signed by Telegram with the default bot's token; MaxAge
+// limits how long after opening the page they are accepted.
+type WebAppConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	MaxAge  int  `mapstructure:"max_age"` // seconds
+}
+
+// LandingConfig brands the public page served at the site root, which shows
+// the bot, how long the server has been up and whether it is down for
+// maintenance. BotUsername is the bot's Telegram username, without the @, for
+// the "Start chatting" link; the link is left out without it.
+type LandingConfig struct {
+	Enabled     bool   `mapstructure:"enabled"`
+	BotName     string `mapstructure:"bot_name"`
+	BotUsername string `mapstructure:"bot_username"`
+	Tagline     string `mapstructure:"tagline"`
+	AccentColor string `mapstructure:"accent_color"` // CSS hex color such as #2481cc
+	LogoURL     string `mapstructure:"logo_url"`
+}
+
+// LoadSheddingConfig controls when the service switches to degraded mode.
+// It enters degraded mode when either threshold is crossed and leaves it after
+// RecoveryChecks consecutive healthy checks.
+type LoadSheddingConfig struct {
+	Enabled        bool `mapstructure:"enabled"`
+	CheckInterval  int  `mapstructure:"check_interval"`    // seconds
+	MaxEventDepth  int  `mapstructure:"max_event_depth"`   // events still being handled
+	MaxDBLatencyMs int  `mapstructure:"max_db_latency_ms"` // moving average of repository calls
+	RecoveryChecks int  `mapstructure:"recovery_checks"`
+}
+
+// MaintenanceConfig sets whether the service starts in maintenance mode and
+// the notice shown to users meanwhile. Admins can toggle it at runtime.
+type MaintenanceConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Message string `mapstructure:"message"`
+}
+
+// HTTPClientConfig sets how outbound calls to Telegram and the LLM are
+// retried. Each service keeps its own timeout; llm.max_retries overrides
+// max_retries for LLM calls.
+type HTTPClientConfig struct {
+	MaxRetries       int     `mapstructure:"max_retries"`
+	RetryBudget      float64 `mapstructure:"retry_budget"` // retries earned by each request, so outages don't multiply traffic
+	InitialBackoffMs int     `mapstructure:"initial_backoff_ms"`
+	MaxBackoffMs     int     `mapstructure:"max_backoff_ms"`
+	MaxRetryAfter    int     `mapstructure:"max_retry_after"` // seconds; a longer Retry-After fails the call instead
+}
+
+// StartupConfig controls how external dependencies are retried at boot
+type StartupConfig struct {
+	InitialBackoffMs int `mapstructure:"initial_backoff_ms"`
+	MaxBackoff       int `mapstructure:"max_backoff"`  // seconds
+	MaxAttempts      int `mapstructure:"max_attempts"` // per dependency, 0 retries until shutdown
+}
+
+// TenantConfig configures a hosted tenant. Each tenant is served by its own
+// bot, named after the tenant, and may bring its own LLM key.
+type TenantConfig struct {
+	ID         string `mapstructure:"id"`
+	BotToken   string `mapstructure:"bot_token"`
+	WebhookURL string `mapstructure:"webhook_url"`
+	Mode       string `mapstructure:"mode"`
+	LLMAPIKey  string `mapstructure:"llm_api_key"` // empty uses the deployment's key
+	LLMModel   string `mapstructure:"llm_model"`   // empty uses the deployment's model
+	// LLMTimeout and LLMMaxRetries override llm.timeout, in seconds, and
+	// llm.max_retries for the tenant's calls; unset uses the deployment's
+	LLMTimeout    int  `mapstructure:"llm_timeout"`
+	LLMMaxRetries *int `mapstructure:"llm_max_retries"`
+}
+
+// LoggingConfig selects where logs are written and at which levels. Modules
+// are package names such as nudge or chatbot, whose level overrides Level;
+// both can be changed at runtime through the admin API.
+type LoggingConfig struct {
+	Level   string            `mapstructure:"level"` // debug, info, warn or error
+	Modules map[string]string `mapstructure:"modules"`
+	Stdout  bool              `mapstructure:"stdout"`
+	File    FileLogConfig     `mapstructure:"file"`
+	Syslog  SyslogLogConfig   `mapstructure:"syslog"`
+	OTLP    OTLPLogConfig     `mapstructure:"otlp"`
+}
+
+// FileLogConfig writes logs to a file that is rotated once it reaches
+// MaxSizeMB. Rotated files past MaxBackups or MaxAgeDays are removed.
+type FileLogConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	Path       string `mapstructure:"path"`
+	MaxSizeMB  int    `mapstructure:"max_size_mb"`
+	MaxBackups int    `mapstructure:"max_backups"`  // 0 keeps every rotated file
+	MaxAgeDays int    `mapstructure:"max_age_days"` // 0 keeps rotated files forever
+	Compress   bool   `mapstructure:"compress"`     // gzip rotated files
+}
+
+// SyslogLogConfig writes logs to syslog. An empty network and address use the
+// local syslog daemon.
+type SyslogLogConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Network string `mapstructure:"network"` // udp, tcp or empty for local
+	Address string `mapstructure:"address"`
+	Tag     string `mapstructure:"tag"`
+}
+
+// OTLPLogConfig exports logs to an OpenTelemetry collector over OTLP/HTTP
+type OTLPLogConfig struct {
+	Enabled         bool              `mapstructure:"enabled"`
+	Endpoint        string            `mapstructure:"endpoint"` // e.g. http://collector:4318/v1/logs
+	Headers         map[string]string `mapstructure:"headers"`
+	ServiceName     string            `mapstructure:"service_name"`
+	BatchSize       int               `mapstructure:"batch_size"`
+	FlushIntervalMs int               `mapstructure:"flush_interval_ms"`
+	QueueSize       int               `mapstructure:"queue_size"` // records waiting for export; more are dropped
 }
 
 func Load() (*Config, error) {
@@ -90,11 +461,24 @@ func Load() (*Config, error) {
 		}
 	}
 
+	// The selected profile overrides the config file
+	profile := selectedProfile()
+	if profile != "" {
+		if err := applyProfile(profile); err != nil {
+			return nil, err
+		}
+	}
+
 	var config Config
 	if err := viper.Unmarshal(&config); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	config.Profile = profile
+	if err := validateProfile(&config); err != nil {
+		return nil, err
+	}
+
 	return &config, nil
 }
 
@@ -103,6 +487,7 @@ func setDefaults() {
 	viper.SetDefault("server.environment", "development")
 	viper.SetDefault("server.read_timeout", 30)
 	viper.SetDefault("server.write_timeout", 30)
+	viper.SetDefault("server.admin_token", "")
 
 	viper.SetDefault("database.host", "localhost")
 	viper.SetDefault("database.port", 5432)
@@ -113,16 +498,55 @@ func setDefaults() {
 	viper.SetDefault("database.max_open_conns", 25)
 	viper.SetDefault("database.max_idle_conns", 5)
 	viper.SetDefault("database.conn_max_lifetime", 300)
+	viper.SetDefault("database.conn_max_idle_time", 0)
+	viper.SetDefault("database.slow_query_ms", 200)
+	viper.SetDefault("database.pgbouncer", false)
+	viper.SetDefault("database.prepared_statements", false)
+	viper.SetDefault("database.statement_cache", false)
+	viper.SetDefault("database.retry_attempts", 2)
+	viper.SetDefault("database.retry_backoff_ms", 50)
+	viper.SetDefault("database.allow_destructive_migrations", false)
 
+	viper.SetDefault("chatbot.mode", "webhook")
 	viper.SetDefault("chatbot.webhook_url", "/webhook")
+	viper.SetDefault("chatbot.webhook_certificate", "")
+	viper.SetDefault("chatbot.webhook_allowlist.enabled", false)
+	viper.SetDefault("chatbot.webhook_allowlist.ranges", []string{"149.154.160.0/20", "91.108.4.0/22"})
+	viper.SetDefault("chatbot.webhook_allowlist.trusted_proxies", []string{})
+	viper.SetDefault("chatbot.proxy", "")
 	viper.SetDefault("chatbot.token", "")
 	viper.SetDefault("chatbot.timeout", 30)
+	viper.SetDefault("chatbot.aggregation_window_ms", 1500)
+	viper.SetDefault("chatbot.aggregation_max_messages", 10)
+	viper.SetDefault("chatbot.task_preview", false)
+	viper.SetDefault("chatbot.update_queue_size", 256)
+	viper.SetDefault("chatbot.poll_timeout", 25)
+	viper.SetDefault("chatbot.moderation.enabled", false)
+	viper.SetDefault("chatbot.moderation.action", "reject")
+	viper.SetDefault("chatbot.moderation.blocked_words", []string{})
+	viper.SetDefault("chatbot.moderation.api_endpoint", "")
+	viper.SetDefault("chatbot.moderation.api_key", "")
+	viper.SetDefault("chatbot.moderation.timeout", 5)
+	viper.SetDefault("chatbot.keyboard.max_buttons_per_row", 2)
+	viper.SetDefault("chatbot.keyboard.max_label_length", 30)
+	viper.SetDefault("chatbot.keyboard.show_emoji", true)
+	viper.SetDefault("chatbot.bots", []BotConfig{})
+	viper.SetDefault("chatbot.authorization.admin_ids", []int64{})
 
 	viper.SetDefault("llm.api_endpoint", "https://generativelanguage.googleapis.com/v1beta/models/gemma-2-27b-it:generateContent")
 	viper.SetDefault("llm.api_key", "")
 	viper.SetDefault("llm.timeout", 30)
 	viper.SetDefault("llm.max_retries", 3)
+	viper.SetDefault("llm.proxy", "")
 	viper.SetDefault("llm.model", "gemma-2-27b-it")
+	viper.SetDefault("llm.confidence_high", 0.8)
+	viper.SetDefault("llm.confidence_medium", 0.6)
+	viper.SetDefault("llm.confidence_low", 0.4)
+	viper.SetDefault("llm.audit.enabled", false)
+	viper.SetDefault("llm.audit.retention_days", 30)
+	viper.SetDefault("llm.prompt_dir", "")
+	viper.SetDefault("llm.max_concurrent", 4)
+	viper.SetDefault("llm.queue_aging", 5)
 
 	viper.SetDefault("events.buffer_size", 1000)
 	viper.SetDefault("events.worker_count", 4)
@@ -131,10 +555,102 @@ func setDefaults() {
 	viper.SetDefault("nudge.default_reminder_interval", 3600) // 1 hour in seconds
 	viper.SetDefault("nudge.max_nudges", 3)
 	viper.SetDefault("nudge.cleanup_interval", 86400) // 24 hours in seconds
+	viper.SetDefault("nudge.invite_ttl", 168)         // 7 days in hours
+	viper.SetDefault("nudge.merge_undo_window", 72)   // 3 days in hours
+	viper.SetDefault("nudge.archive_after_months", 12)
+	viper.SetDefault("nudge.archive_batch_size", 500)
+	viper.SetDefault("nudge.conflict_tolerance", 15)
 
 	viper.SetDefault("scheduler.poll_interval", 30) // 30 seconds
 	viper.SetDefault("scheduler.nudge_delay", 7200) // 2 hours
 	viper.SetDefault("scheduler.worker_count", 2)
+	viper.SetDefault("scheduler.min_workers", 1)
+	viper.SetDefault("scheduler.max_workers", 0)         // 0 keeps the pool fixed at worker_count
+	viper.SetDefault("scheduler.backlog_per_worker", 25) // due reminders one worker is expected to drain per cycle
 	viper.SetDefault("scheduler.shutdown_timeout", 30)
+	viper.SetDefault("scheduler.activity_deferral", 300) // hold reminders for 5 minutes after user activity
 	viper.SetDefault("scheduler.enabled", true)
+
+	viper.SetDefault("experiments.enabled", false)
+	viper.SetDefault("experiments.conversion_window", 24)
+
+	viper.SetDefault("startup.initial_backoff_ms", 500)
+	viper.SetDefault("startup.max_backoff", 30)
+	viper.SetDefault("startup.max_attempts", 0)
+
+	viper.SetDefault("tenants", []TenantConfig{})
+
+	viper.SetDefault("load_shedding.enabled", true)
+	viper.SetDefault("load_shedding.check_interval", 5)
+	viper.SetDefault("load_shedding.max_event_depth", 50)
+	viper.SetDefault("load_shedding.max_db_latency_ms", 500)
+	viper.SetDefault("load_shedding.recovery_checks", 3)
+
+	viper.SetDefault("maintenance.enabled", false)
+	viper.SetDefault("maintenance.message", "")
+
+	viper.SetDefault("http_client.max_retries", 2)
+	viper.SetDefault("http_client.retry_budget", 0.2)
+	viper.SetDefault("http_client.initial_backoff_ms", 200)
+	viper.SetDefault("http_client.max_backoff_ms", 5000)
+	viper.SetDefault("http_client.max_retry_after", 30)
+
+	viper.SetDefault("chaos.enabled", false)
+	viper.SetDefault("chaos.seed", 0)
+	viper.SetDefault("chaos.telegram_rate_limit_rate", 0.0)
+	viper.SetDefault("chaos.telegram_server_error_rate", 0.0)
+	viper.SetDefault("chaos.database_timeout_rate", 0.0)
+	viper.SetDefault("chaos.llm_error_rate", 0.0)
+
+	viper.SetDefault("probe.enabled", false)
+	viper.SetDefault("probe.message", "Synthetic probe: check the task pipeline")
+	viper.SetDefault("probe.stage_timeout", 30)
+	viper.SetDefault("probe.alert_after", 3)
+
+	viper.SetDefault("notify.email.host", "")
+	viper.SetDefault("notify.email.port", 587)
+	viper.SetDefault("notify.email.username", "")
+	viper.SetDefault("notify.email.password", "")
+	viper.SetDefault("notify.email.from", "")
+	viper.SetDefault("notify.email.timeout", 10)
+
+	viper.SetDefault("holidays.source", "builtin")
+	viper.SetDefault("holidays.url", "https://date.nager.at")
+	viper.SetDefault("holidays.timeout", 10)
+
+	viper.SetDefault("geocoding.enabled", false)
+	viper.SetDefault("geocoding.url", "https://nominatim.openstreetmap.org")
+	viper.SetDefault("geocoding.user_agent", "NudgeBot")
+	viper.SetDefault("geocoding.timeout", 10)
+
+	viper.SetDefault("web_app.enabled", false)
+	viper.SetDefault("web_app.max_age", 86400)
+
+	viper.SetDefault("landing.enabled", true)
+	viper.SetDefault("landing.bot_name", "NudgeBot")
+	viper.SetDefault("landing.tagline", "Tell me your tasks, I'll nudge you until they're done.")
+	viper.SetDefault("landing.accent_color", "#2481cc")
+
+	viper.SetDefault("debug_capture.enabled", false)
+	viper.SetDefault("debug_capture.sample_rate", 0.0)
+	viper.SetDefault("debug_capture.capacity", 500)
+	viper.SetDefault("debug_capture.chats", []int64{})
+	viper.SetDefault("debug_capture.redact_text", true)
+
+	viper.SetDefault("logging.level", "info")
+	viper.SetDefault("logging.stdout", true)
+	viper.SetDefault("logging.file.enabled", false)
+	viper.SetDefault("logging.file.path", "logs/nudgebot.log")
+	viper.SetDefault("logging.file.max_size_mb", 100)
+	viper.SetDefault("logging.file.max_backups", 5)
+	viper.SetDefault("logging.file.max_age_days", 14)
+	viper.SetDefault("logging.file.compress", true)
+	viper.SetDefault("logging.syslog.enabled", false)
+	viper.SetDefault("logging.syslog.tag", "nudgebot")
+	viper.SetDefault("logging.otlp.enabled", false)
+	viper.SetDefault("logging.otlp.endpoint", "http://localhost:4318/v1/logs")
+	viper.SetDefault("logging.otlp.service_name", "nudgebot-api")
+	viper.SetDefault("logging.otlp.batch_size", 256)
+	viper.SetDefault("logging.otlp.flush_interval_ms", 2000)
+	viper.SetDefault("logging.otlp.queue_size", 4096)
 }
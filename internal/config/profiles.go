@@ -0,0 +1,77 @@
+package config
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// ProfileEnv names the environment variable that selects a configuration
+// profile
+const ProfileEnv = "NUDGEBOT_PROFILE"
+
+// Configuration profiles, each tuned for one kind of deployment
+const (
+	ProfileDev      = "dev"
+	ProfileSelfHost = "selfhost"
+	ProfileCloud    = "cloud"
+)
+
+// profileFiles holds the settings of each profile, one YAML file per profile
+//
+//go:embed profiles/*.yaml
+var profileFiles embed.FS
+
+// Profiles returns the names of the configuration profiles
+func Profiles() []string {
+	entries, _ := profileFiles.ReadDir("profiles")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".yaml"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// selectedProfile returns the profile named by ProfileEnv, "" when none is
+func selectedProfile() string {
+	return strings.ToLower(strings.TrimSpace(os.Getenv(ProfileEnv)))
+}
+
+// applyProfile layers the profile's settings over the config file. Environment
+// variables still take precedence over both.
+func applyProfile(profile string) error {
+	settings, err := profileFiles.ReadFile(path.Join("profiles", profile+".yaml"))
+	if err != nil {
+		return fmt.Errorf("%s names unknown profile %q; use one of %s", ProfileEnv, profile, strings.Join(Profiles(), ", "))
+	}
+	if err := viper.MergeConfig(bytes.NewReader(settings)); err != nil {
+		return fmt.Errorf("failed to apply profile %s: %w", profile, err)
+	}
+	return nil
+}
+
+// validateProfile checks the settings a profile depends on once everything
+// is applied
+func validateProfile(cfg *Config) error {
+	switch cfg.Profile {
+	case ProfileDev:
+		if cfg.Server.Environment == "production" {
+			return fmt.Errorf("the %s profile cannot run with server.environment production", ProfileDev)
+		}
+	case ProfileCloud:
+		if cfg.Chatbot.Mode == "webhook" && !strings.HasPrefix(cfg.Chatbot.WebhookURL, "https://") {
+			return fmt.Errorf("the %s profile needs chatbot.webhook_url set to a public https:// URL", ProfileCloud)
+		}
+		if cfg.Database.SSLMode == "disable" {
+			return fmt.Errorf("the %s profile needs database.sslmode other than disable", ProfileCloud)
+		}
+	}
+	return nil
+}
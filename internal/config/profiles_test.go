@@ -0,0 +1,100 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loadIn loads the configuration with dir as the working directory
+func loadIn(t *testing.T, dir string) (*Config, error) {
+	t.Helper()
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() {
+		require.NoError(t, os.Chdir(wd))
+	})
+
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	return Load()
+}
+
+func TestProfiles(t *testing.T) {
+	assert.Equal(t, []string{ProfileCloud, ProfileDev, ProfileSelfHost}, Profiles())
+}
+
+func TestLoad_Profiles(t *testing.T) {
+	t.Setenv(ProfileEnv, "SelfHost")
+	cfg, err := loadIn(t, t.TempDir())
+	require.NoError(t, err)
+	assert.Equal(t, ProfileSelfHost, cfg.Profile)
+	assert.Equal(t, "postgres", cfg.Database.Host)
+	assert.Equal(t, "auto", cfg.Chatbot.Mode)
+	assert.Equal(t, 10, cfg.Database.MaxOpenConns)
+	assert.Equal(t, 5432, cfg.Database.Port, "settings the profile leaves alone keep their defaults")
+
+	t.Setenv(ProfileEnv, ProfileDev)
+	cfg, err = loadIn(t, t.TempDir())
+	require.NoError(t, err)
+	assert.Equal(t, "polling", cfg.Chatbot.Mode)
+	assert.Equal(t, "debug", cfg.Logging.Level)
+
+	t.Setenv(ProfileEnv, "")
+	cfg, err = loadIn(t, t.TempDir())
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Profile)
+	assert.Equal(t, "webhook", cfg.Chatbot.Mode)
+}
+
+func TestLoad_ProfileOverridesConfigFileButNotEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(`
+database:
+  host: localhost
+  dbname: tasks
+chatbot:
+  mode: webhook
+`), 0o600))
+
+	t.Setenv(ProfileEnv, ProfileSelfHost)
+	t.Setenv("CHATBOT_MODE", "polling")
+	cfg, err := loadIn(t, dir)
+	require.NoError(t, err)
+	assert.Equal(t, "postgres", cfg.Database.Host)
+	assert.Equal(t, "tasks", cfg.Database.DBName)
+	assert.Equal(t, "polling", cfg.Chatbot.Mode)
+}
+
+func TestLoad_ProfileValidation(t *testing.T) {
+	t.Setenv(ProfileEnv, "staging")
+	_, err := loadIn(t, t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "use one of cloud, dev, selfhost")
+
+	t.Setenv(ProfileEnv, ProfileCloud)
+	_, err = loadIn(t, t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "chatbot.webhook_url")
+
+	t.Setenv("CHATBOT_WEBHOOK_URL", "https://bot.example.com/api/v1/telegram/webhook")
+	cfg, err := loadIn(t, t.TempDir())
+	require.NoError(t, err)
+	assert.Equal(t, "require", cfg.Database.SSLMode)
+	assert.Equal(t, 8, cfg.Scheduler.MaxWorkers)
+
+	t.Setenv("DATABASE_SSLMODE", "disable")
+	_, err = loadIn(t, t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "database.sslmode")
+
+	t.Setenv(ProfileEnv, ProfileDev)
+	t.Setenv("SERVER_ENVIRONMENT", "production")
+	_, err = loadIn(t, t.TempDir())
+	require.Error(t, err)
+}
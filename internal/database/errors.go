@@ -0,0 +1,53 @@
+package database
+
+import (
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"syscall"
+)
+
+// closedConnectionMessages are fragments of errors reported when the server,
+// or PgBouncer in front of it, closed the connection
+var closedConnectionMessages = []string{
+	"server closed the connection",
+	"conn closed",
+	"connection reset by peer",
+	"broken pipe",
+	"unexpected eof",
+}
+
+// IsConnectionClosed reports whether err means the connection to the server
+// was lost, so the statement may succeed on a new connection
+func IsConnectionClosed(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	// Connection exceptions (class 08) and the server shutting down the session
+	var pgErr interface{ SQLState() string }
+	if errors.As(err, &pgErr) {
+		state := pgErr.SQLState()
+		return strings.HasPrefix(state, "08") || state == "57P01" || state == "57P02" || state == "57P03"
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, fragment := range closedConnectionMessages {
+		if strings.Contains(message, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// SafeToRetry reports whether err was returned before the statement reached
+// the server, so retrying cannot apply it twice
+func SafeToRetry(err error) bool {
+	var retryable interface{ SafeToRetry() bool }
+	return errors.As(err, &retryable) && retryable.SafeToRetry()
+}
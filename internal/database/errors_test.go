@@ -0,0 +1,48 @@
+package database
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return "server error " + string(e) }
+func (e sqlStateError) SQLState() string { return string(e) }
+
+type unsentError struct{ error }
+
+func (e unsentError) SafeToRetry() bool { return true }
+
+func TestIsConnectionClosed(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"bad connection", driver.ErrBadConn, true},
+		{"wrapped unexpected EOF", fmt.Errorf("failed to receive message: %w", io.ErrUnexpectedEOF), true},
+		{"server closed the connection", errors.New("FATAL: server closed the connection unexpectedly"), true},
+		{"connection exception", sqlStateError("08006"), true},
+		{"admin shutdown", sqlStateError("57P01"), true},
+		{"unique violation", sqlStateError("23505"), false},
+		{"other error", errors.New("record not found"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsConnectionClosed(tt.err))
+		})
+	}
+}
+
+func TestSafeToRetry(t *testing.T) {
+	assert.True(t, SafeToRetry(fmt.Errorf("create task: %w", unsentError{io.EOF})))
+	assert.False(t, SafeToRetry(io.EOF))
+}
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PoolStats summarizes the use of the connection pool
+type PoolStats struct {
+	MaxOpen     int     `json:"max_open"` // 0 means unlimited
+	Open        int     `json:"open"`
+	InUse       int     `json:"in_use"`
+	Idle        int     `json:"idle"`
+	Utilization float64 `json:"utilization"` // in use out of max open, 0 when unlimited
+	// Waits are requests that found every connection in use
+	WaitCount         int64  `json:"wait_count"`
+	WaitDuration      string `json:"wait_duration"`
+	MaxIdleClosed     int64  `json:"max_idle_closed"`
+	MaxIdleTimeClosed int64  `json:"max_idle_time_closed"`
+	MaxLifetimeClosed int64  `json:"max_lifetime_closed"`
+}
+
+// Pool reports the utilization of a database connection pool
+type Pool struct {
+	db *sql.DB
+}
+
+// NewPool creates a reporter for the connection pool of db
+func NewPool(db *gorm.DB) (*Pool, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database instance is nil")
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get underlying sql.DB: %w", err)
+	}
+	return &Pool{db: sqlDB}, nil
+}
+
+// Stats returns the current pool statistics
+func (p *Pool) Stats() PoolStats {
+	return newPoolStats(p.db.Stats())
+}
+
+func newPoolStats(stats sql.DBStats) PoolStats {
+	summary := PoolStats{
+		MaxOpen:           stats.MaxOpenConnections,
+		Open:              stats.OpenConnections,
+		InUse:             stats.InUse,
+		Idle:              stats.Idle,
+		WaitCount:         stats.WaitCount,
+		WaitDuration:      stats.WaitDuration.Round(time.Microsecond).String(),
+		MaxIdleClosed:     stats.MaxIdleClosed,
+		MaxIdleTimeClosed: stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed: stats.MaxLifetimeClosed,
+	}
+	if stats.MaxOpenConnections > 0 {
+		summary.Utilization = float64(stats.InUse) / float64(stats.MaxOpenConnections)
+	}
+	return summary
+}
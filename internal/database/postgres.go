@@ -12,13 +12,13 @@ import (
 )
 
 func NewPostgresConnection(cfg config.DatabaseConfig) (*gorm.DB, error) {
-    // Use prefer_simple_protocol to avoid server-side prepared statement name collisions
-    // which can surface as: ERROR: prepared statement "..." already exists (SQLSTATE 42P05)
-    dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s prefer_simple_protocol=true",
-        cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName, cfg.SSLMode)
+    if err := validateStatementConfig(cfg); err != nil {
+        return nil, err
+    }
 
-    db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-        Logger: logger.Default.LogMode(logger.Silent),
+    db, err := gorm.Open(postgres.Open(buildDSN(cfg)), &gorm.Config{
+        Logger:      logger.Default.LogMode(logger.Silent),
+        PrepareStmt: cfg.StatementCache,
     })
     if err != nil {
         return nil, fmt.Errorf("failed to connect to database: %w", err)
@@ -33,6 +33,7 @@ func NewPostgresConnection(cfg config.DatabaseConfig) (*gorm.DB, error) {
     sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
     sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
     sqlDB.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime) * time.Second)
+    sqlDB.SetConnMaxIdleTime(time.Duration(cfg.ConnMaxIdleTime) * time.Second)
 
     // Test connection
     if err := sqlDB.Ping(); err != nil {
@@ -42,6 +43,32 @@ func NewPostgresConnection(cfg config.DatabaseConfig) (*gorm.DB, error) {
     return db, nil
 }
 
+// buildDSN builds the connection string. Unless prepared statements are
+// enabled, queries use the simple protocol, which creates no server-side
+// prepared statements. That avoids name collisions such as: ERROR: prepared
+// statement "..." already exists (SQLSTATE 42P05), and is required behind
+// PgBouncer in transaction pooling mode, where consecutive statements may run
+// on different server connections.
+func buildDSN(cfg config.DatabaseConfig) string {
+    dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+        cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName, cfg.SSLMode)
+    if !cfg.PreparedStatements {
+        dsn += " prefer_simple_protocol=true"
+    }
+    return dsn
+}
+
+// validateStatementConfig rejects prepared statement settings that cannot work
+func validateStatementConfig(cfg config.DatabaseConfig) error {
+    if cfg.StatementCache && !cfg.PreparedStatements {
+        return fmt.Errorf("database statement_cache requires prepared_statements")
+    }
+    if cfg.PgBouncer && cfg.PreparedStatements {
+        return fmt.Errorf("database prepared_statements cannot be used with pgbouncer transaction pooling")
+    }
+    return nil
+}
+
 func HealthCheck(db *gorm.DB) error {
     if db == nil {
         return fmt.Errorf("database instance is nil")
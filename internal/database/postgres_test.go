@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"testing"
 	"time"
@@ -631,3 +632,41 @@ func TestConnect_ConnectionLifecycle(t *testing.T) {
 	err = db.Create(&record2)
 	assert.Error(t, err)
 }
+
+func TestBuildDSN_SimpleProtocolUnlessPreparedStatements(t *testing.T) {
+	cfg := config.DatabaseConfig{Host: "db", Port: 6432, User: "app", Password: "secret", DBName: "nudgebot", SSLMode: "disable"}
+	assert.Contains(t, buildDSN(cfg), "prefer_simple_protocol=true")
+
+	cfg.PreparedStatements = true
+	assert.NotContains(t, buildDSN(cfg), "prefer_simple_protocol")
+}
+
+func TestNewPostgresConnection_RejectsPreparedStatementsBehindPgBouncer(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.DatabaseConfig
+		want string
+	}{
+		{"statement cache without prepared statements", config.DatabaseConfig{StatementCache: true}, "requires prepared_statements"},
+		{"prepared statements with pgbouncer", config.DatabaseConfig{PgBouncer: true, PreparedStatements: true}, "pgbouncer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := NewPostgresConnection(tt.cfg)
+			assert.Nil(t, db)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestPoolStats_Utilization(t *testing.T) {
+	stats := newPoolStats(sql.DBStats{MaxOpenConnections: 20, OpenConnections: 8, InUse: 5, Idle: 3, WaitCount: 2, WaitDuration: 1500 * time.Microsecond})
+	assert.Equal(t, 0.25, stats.Utilization)
+	assert.Equal(t, 8, stats.Open)
+	assert.Equal(t, int64(2), stats.WaitCount)
+	assert.Equal(t, "1.5ms", stats.WaitDuration)
+
+	// An unlimited pool has no utilization
+	assert.Zero(t, newPoolStats(sql.DBStats{InUse: 5}).Utilization)
+}
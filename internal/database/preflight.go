@@ -0,0 +1,109 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// ErrDestructiveMigration is returned when migrations would drop or retype
+// columns and destructive migrations are not allowed
+var ErrDestructiveMigration = errors.New("migrations contain destructive changes")
+
+// destructivePatterns match the DDL that can lose data or break the code
+// still running on the previous schema during a blue/green deploy
+var destructivePatterns = []struct {
+	pattern *regexp.Regexp
+	reason  string
+}{
+	{regexp.MustCompile(`(?i)\bDROP\s+TABLE\b`), "drops a table"},
+	{regexp.MustCompile(`(?i)\bDROP\s+COLUMN\b`), "drops a column"},
+	{regexp.MustCompile(`(?i)\bALTER\s+COLUMN\s+\S+\s+(SET\s+DATA\s+)?TYPE\b`), "changes a column type"},
+}
+
+// DestructiveChange is a migration statement that can lose data
+type DestructiveChange struct {
+	Statement string
+	Reason    string
+}
+
+// PlanMigrations runs migrate against a copy of db that records the
+// statements it would execute instead of executing them. Queries still run,
+// so migrations inspecting the schema plan against the real one.
+func PlanMigrations(db *gorm.DB, migrate func(db *gorm.DB) error) ([]string, error) {
+	recorder, err := gorm.Open(postgres.New(postgres.Config{Conn: db.ConnPool}), &gorm.Config{
+		Logger:               db.Logger,
+		NamingStrategy:       db.NamingStrategy,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open migration recorder: %w", err)
+	}
+
+	var statements []string
+	err = recorder.Callback().Raw().Replace("gorm:raw", func(tx *gorm.DB) {
+		statements = append(statements, tx.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open migration recorder: %w", err)
+	}
+
+	if err := migrate(recorder); err != nil {
+		return statements, fmt.Errorf("failed to plan migrations: %w", err)
+	}
+	return statements, nil
+}
+
+// DestructiveChanges returns the statements that drop a table or column or
+// change a column's type
+func DestructiveChanges(statements []string) []DestructiveChange {
+	var changes []DestructiveChange
+	for _, statement := range statements {
+		if reason := destructiveReason(statement); reason != "" {
+			changes = append(changes, DestructiveChange{Statement: statement, Reason: reason})
+		}
+	}
+	return changes
+}
+
+// destructiveReason explains why a statement is destructive, or returns ""
+func destructiveReason(statement string) string {
+	for _, destructive := range destructivePatterns {
+		if destructive.pattern.MatchString(statement) {
+			return destructive.reason
+		}
+	}
+	return ""
+}
+
+// RequireTables returns a post-migration check that the tables of models exist
+func RequireTables(models ...interface{}) func(db *gorm.DB) error {
+	return func(db *gorm.DB) error {
+		for _, model := range models {
+			if !db.Migrator().HasTable(model) {
+				return fmt.Errorf("table for %T does not exist", model)
+			}
+		}
+		return nil
+	}
+}
+
+// CheckMigrations plans migrate and returns its destructive changes. Unless
+// allowDestructive is set, any destructive change is also returned as an
+// error wrapping ErrDestructiveMigration.
+func CheckMigrations(db *gorm.DB, migrate func(db *gorm.DB) error, allowDestructive bool) ([]DestructiveChange, error) {
+	statements, err := PlanMigrations(db, migrate)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := DestructiveChanges(statements)
+	if len(changes) > 0 && !allowDestructive {
+		return changes, fmt.Errorf("%w: %d statement(s), the first %s: %s",
+			ErrDestructiveMigration, len(changes), changes[0].Reason, changes[0].Statement)
+	}
+	return changes, nil
+}
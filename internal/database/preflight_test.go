@@ -0,0 +1,107 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// countingPool is a connection that counts the statements executed on it
+type countingPool struct {
+	execs int
+}
+
+func (p *countingPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (p *countingPool) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	p.execs++
+	return nil, errors.New("not supported")
+}
+
+func (p *countingPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return nil, errors.New("not supported")
+}
+
+func (p *countingPool) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return nil
+}
+
+func openCountingDB(t *testing.T) (*gorm.DB, *countingPool) {
+	pool := &countingPool{}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: pool}), &gorm.Config{DisableAutomaticPing: true})
+	require.NoError(t, err)
+	return db, pool
+}
+
+func alterTasks(db *gorm.DB) error {
+	for _, statement := range []string{
+		"ALTER TABLE tasks ADD COLUMN notes text",
+		"ALTER TABLE tasks ALTER COLUMN title TYPE varchar(100)",
+		"DROP INDEX IF EXISTS idx_tasks_priority",
+	} {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestPlanMigrations_RecordsWithoutExecuting(t *testing.T) {
+	db, pool := openCountingDB(t)
+
+	statements, err := PlanMigrations(db, alterTasks)
+	require.NoError(t, err)
+
+	assert.Len(t, statements, 3)
+	assert.Equal(t, "ALTER TABLE tasks ALTER COLUMN title TYPE varchar(100)", statements[1])
+	assert.Zero(t, pool.execs)
+
+	// The source connection executes as before
+	db.Exec("SELECT 1")
+	assert.Equal(t, 1, pool.execs)
+}
+
+func TestDestructiveChanges(t *testing.T) {
+	changes := DestructiveChanges([]string{
+		`CREATE TABLE "countdowns" ("id" text)`,
+		`ALTER TABLE "tasks" ADD "notes" text`,
+		`ALTER TABLE "tasks" DROP COLUMN "notes"`,
+		`ALTER TABLE "tasks" ALTER COLUMN "title" TYPE varchar(100) USING "title"::varchar(100)`,
+		`alter table tasks alter column priority set data type integer`,
+		"DROP INDEX IF EXISTS idx_reminders_scheduled_at",
+		"DROP TABLE IF EXISTS tasks_archive CASCADE",
+	})
+
+	reasons := make([]string, 0, len(changes))
+	for _, change := range changes {
+		reasons = append(reasons, change.Reason)
+	}
+	assert.Equal(t, []string{"drops a column", "changes a column type", "changes a column type", "drops a table"}, reasons)
+}
+
+func TestCheckMigrations_RefusesDestructiveChangesUnlessAllowed(t *testing.T) {
+	db, _ := openCountingDB(t)
+
+	changes, err := CheckMigrations(db, alterTasks, false)
+	assert.ErrorIs(t, err, ErrDestructiveMigration)
+	assert.ErrorContains(t, err, "changes a column type")
+	assert.Len(t, changes, 1)
+
+	changes, err = CheckMigrations(db, alterTasks, true)
+	assert.NoError(t, err)
+	assert.Len(t, changes, 1)
+
+	changes, err = CheckMigrations(db, func(db *gorm.DB) error {
+		return db.Exec("CREATE INDEX IF NOT EXISTS idx_tasks_title ON tasks(title)").Error
+	}, false)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+}
@@ -0,0 +1,218 @@
+// Package debugcapture keeps a redacted sample of raw Telegram updates and
+// outgoing Bot API calls in memory, so reports of the bot not responding can
+// be traced without turning on debug logging for everyone.
+package debugcapture
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"time"
+
+	"nudgebot-api/internal/config"
+)
+
+// Kind identifies what a capture recorded
+type Kind string
+
+// Capture kinds
+const (
+	KindUpdate Kind = "update"
+	KindCall   Kind = "call"
+)
+
+// DefaultCapacity is the number of captures kept when none is configured
+const DefaultCapacity = 500
+
+// Capture is one recorded update or Bot API call
+type Capture struct {
+	ID         int64           `json:"id"`
+	Kind       Kind            `json:"kind"`
+	Bot        string          `json:"bot,omitempty"`
+	ChatID     int64           `json:"chat_id,omitempty"`
+	Method     string          `json:"method,omitempty"` // Bot API method of a call
+	Payload    json.RawMessage `json:"payload"`
+	Error      string          `json:"error,omitempty"`
+	DurationMs int64           `json:"duration_ms,omitempty"`
+	CapturedAt time.Time       `json:"captured_at"`
+}
+
+// Filter selects captures to list. Zero values match everything; a zero limit
+// returns every capture.
+type Filter struct {
+	ChatID int64
+	Kind   Kind
+	Limit  int
+}
+
+// Recorder stores captures in a fixed-size ring buffer. A nil Recorder records
+// nothing, so it can be wired unconditionally.
+type Recorder struct {
+	mu         sync.Mutex
+	captures   []Capture
+	next       int
+	sequence   int64
+	sampleRate float64
+	chats      map[int64]bool
+	redactText bool
+	now        func() time.Time
+}
+
+// NewRecorder creates a recorder keeping up to capacity captures
+func NewRecorder(capacity int, sampleRate float64, chats []int64, redactText bool) *Recorder {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+
+	always := make(map[int64]bool, len(chats))
+	for _, chatID := range chats {
+		always[chatID] = true
+	}
+
+	return &Recorder{
+		captures:   make([]Capture, 0, capacity),
+		sampleRate: clampRate(sampleRate),
+		chats:      always,
+		redactText: redactText,
+		now:        time.Now,
+	}
+}
+
+// NewRecorderFromConfig builds a recorder from configuration. It returns nil
+// when capture is disabled.
+func NewRecorderFromConfig(cfg config.DebugCaptureConfig) *Recorder {
+	if !cfg.Enabled {
+		return nil
+	}
+	return NewRecorder(cfg.Capacity, cfg.SampleRate, cfg.Chats, cfg.RedactText)
+}
+
+// Sampled reports whether traffic of the chat is captured. The decision is a
+// hash of the chat ID, so every update and reply of a sampled chat is kept.
+func (r *Recorder) Sampled(chatID int64) bool {
+	if r == nil {
+		return false
+	}
+	if r.chats[chatID] {
+		return true
+	}
+	if r.sampleRate <= 0 {
+		return false
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(strconv.FormatInt(chatID, 10)))
+	return float64(hash.Sum32()%10000) < r.sampleRate*10000
+}
+
+// RecordUpdate captures a raw update received from Telegram and the error
+// handling it returned, if the chat is sampled
+func (r *Recorder) RecordUpdate(bot string, chatID int64, payload []byte, handleErr error) {
+	if !r.Sampled(chatID) {
+		return
+	}
+
+	r.add(Capture{
+		Kind:    KindUpdate,
+		Bot:     bot,
+		ChatID:  chatID,
+		Payload: Redact(payload, r.redactText),
+		Error:   errorText(handleErr),
+	})
+}
+
+// RecordCall captures an outgoing Bot API call, if the chat is sampled
+func (r *Recorder) RecordCall(bot, method string, chatID int64, params interface{}, callErr error, duration time.Duration) {
+	if !r.Sampled(chatID) {
+		return
+	}
+
+	payload, err := json.Marshal(params)
+	if err != nil {
+		payload = []byte("null")
+	}
+
+	r.add(Capture{
+		Kind:       KindCall,
+		Bot:        bot,
+		ChatID:     chatID,
+		Method:     method,
+		Payload:    Redact(payload, r.redactText),
+		Error:      errorText(callErr),
+		DurationMs: duration.Milliseconds(),
+	})
+}
+
+func (r *Recorder) add(capture Capture) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sequence++
+	capture.ID = r.sequence
+	capture.CapturedAt = r.now()
+
+	if len(r.captures) < cap(r.captures) {
+		r.captures = append(r.captures, capture)
+	} else {
+		r.captures[r.next] = capture
+	}
+	r.next = (r.next + 1) % cap(r.captures)
+}
+
+// List returns the captures matching the filter, newest first
+func (r *Recorder) List(filter Filter) []Capture {
+	if r == nil {
+		return []Capture{}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	matched := []Capture{}
+	for i := 0; i < len(r.captures); i++ {
+		// Walk backwards from the most recent write
+		capture := r.captures[(r.next-1-i+2*len(r.captures))%len(r.captures)]
+		if filter.ChatID != 0 && capture.ChatID != filter.ChatID {
+			continue
+		}
+		if filter.Kind != "" && capture.Kind != filter.Kind {
+			continue
+		}
+		matched = append(matched, capture)
+		if filter.Limit > 0 && len(matched) == filter.Limit {
+			break
+		}
+	}
+	return matched
+}
+
+// Clear drops every capture
+func (r *Recorder) Clear() {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.captures = r.captures[:0]
+	r.next = 0
+}
+
+func errorText(err error) string {
+	if err == nil {
+		return ""
+	}
+	return redactSecrets(err.Error())
+}
+
+func clampRate(rate float64) float64 {
+	if rate < 0 {
+		return 0
+	}
+	if rate > 1 {
+		return 1
+	}
+	return rate
+}
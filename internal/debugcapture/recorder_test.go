@@ -0,0 +1,84 @@
+package debugcapture
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const update = `{
+	"update_id": 1001,
+	"message": {
+		"message_id": 7,
+		"from": {"id": 42, "first_name": "Ada", "last_name": "Lovelace", "username": "ada"},
+		"chat": {"id": 42, "type": "private", "first_name": "Ada"},
+		"text": "/add buy milk tomorrow"
+	}
+}`
+
+func TestRecorder_SamplesByChat(t *testing.T) {
+	recorder := NewRecorder(10, 0, []int64{42}, true)
+
+	recorder.RecordUpdate("", 42, []byte(update), nil)
+	recorder.RecordUpdate("", 7, []byte(update), nil)
+	recorder.RecordCall("", "sendMessage", 42, map[string]interface{}{"chat_id": 42, "text": "Added"}, errors.New("Bad Gateway"), 0)
+
+	captures := recorder.List(Filter{})
+	require.Len(t, captures, 2)
+	assert.Equal(t, KindCall, captures[0].Kind)
+	assert.Equal(t, "Bad Gateway", captures[0].Error)
+	assert.Equal(t, KindUpdate, captures[1].Kind)
+	assert.Equal(t, int64(42), captures[1].ChatID)
+
+	everyone := NewRecorder(10, 1, nil, true)
+	assert.True(t, everyone.Sampled(7))
+	assert.False(t, NewRecorder(10, 0, nil, true).Sampled(7))
+
+	var disabled *Recorder
+	disabled.RecordUpdate("", 42, []byte(update), nil)
+	assert.Empty(t, disabled.List(Filter{}))
+}
+
+func TestRecorder_RingBuffer(t *testing.T) {
+	recorder := NewRecorder(3, 1, nil, false)
+	for chatID := int64(1); chatID <= 5; chatID++ {
+		recorder.RecordCall("", "sendMessage", chatID, nil, nil, 0)
+	}
+
+	captures := recorder.List(Filter{})
+	require.Len(t, captures, 3)
+	assert.Equal(t, []int64{5, 4, 3}, []int64{captures[0].ChatID, captures[1].ChatID, captures[2].ChatID})
+	assert.Equal(t, int64(5), captures[0].ID)
+
+	limited := recorder.List(Filter{ChatID: 4, Limit: 1})
+	require.Len(t, limited, 1)
+	assert.Equal(t, int64(4), limited[0].ChatID)
+	assert.Empty(t, recorder.List(Filter{Kind: KindUpdate}))
+
+	recorder.Clear()
+	assert.Empty(t, recorder.List(Filter{}))
+}
+
+func TestRedact(t *testing.T) {
+	var redacted struct {
+		Message struct {
+			From map[string]interface{} `json:"from"`
+			Text string                 `json:"text"`
+		} `json:"message"`
+	}
+	require.NoError(t, json.Unmarshal(Redact([]byte(update), true), &redacted))
+	assert.Equal(t, Redacted, redacted.Message.From["first_name"])
+	assert.Equal(t, Redacted, redacted.Message.From["username"])
+	assert.Equal(t, float64(42), redacted.Message.From["id"])
+	assert.Equal(t, "/add [redacted 18 chars]", redacted.Message.Text)
+
+	require.NoError(t, json.Unmarshal(Redact([]byte(update), false), &redacted))
+	assert.Equal(t, "/add buy milk tomorrow", redacted.Message.Text)
+
+	token := `"Post https://api.telegram.org/bot123456789:AAHdqTcvCH1vGWJxfSeofSAs0K5PALDsaw/sendMessage"`
+	assert.NotContains(t, string(Redact([]byte(token), false)), "AAHdqTcvCH1vGWJxfSeofSAs0K5PALDsaw")
+	assert.JSONEq(t, `"[invalid JSON, 3 bytes]"`, string(Redact([]byte("{{{"), true)))
+}
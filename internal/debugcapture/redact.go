@@ -0,0 +1,90 @@
+package debugcapture
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces values that identify a person
+const Redacted = "[redacted]"
+
+// personalFields are the update fields whose values are always redacted
+var personalFields = map[string]bool{
+	"first_name":   true,
+	"last_name":    true,
+	"username":     true,
+	"phone_number": true,
+	"email":        true,
+	"bio":          true,
+}
+
+// textFields are message bodies, redacted when text redaction is on
+var textFields = map[string]bool{
+	"text":    true,
+	"caption": true,
+}
+
+// botTokenPattern matches Bot API tokens, which appear in request URLs and
+// some error messages
+var botTokenPattern = regexp.MustCompile(`\d{6,}:[A-Za-z0-9_-]{30,}`)
+
+// Redact returns the JSON payload with personal fields replaced and bot tokens
+// removed. With redactText, message texts keep only their leading command so
+// the shape of the conversation stays visible. Invalid JSON is replaced by a
+// note of its size.
+func Redact(payload []byte, redactText bool) json.RawMessage {
+	var value interface{}
+	if err := json.Unmarshal(payload, &value); err != nil {
+		note, _ := json.Marshal(fmt.Sprintf("[invalid JSON, %d bytes]", len(payload)))
+		return note
+	}
+
+	redacted, err := json.Marshal(redactValue(value, "", redactText))
+	if err != nil {
+		return json.RawMessage("null")
+	}
+	return redacted
+}
+
+func redactValue(value interface{}, key string, redactText bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for field, child := range v {
+			v[field] = redactValue(child, field, redactText)
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = redactValue(child, key, redactText)
+		}
+		return v
+	case string:
+		if personalFields[key] {
+			return Redacted
+		}
+		if redactText && textFields[key] {
+			return redactMessageText(v)
+		}
+		return redactSecrets(v)
+	default:
+		return v
+	}
+}
+
+// redactMessageText keeps a leading bot command and replaces the rest
+func redactMessageText(text string) string {
+	if strings.HasPrefix(text, "/") {
+		command := strings.Fields(text)[0]
+		if len(command) < len(text) {
+			return fmt.Sprintf("%s [redacted %d chars]", command, len(text)-len(command))
+		}
+		return command
+	}
+	return fmt.Sprintf("[redacted %d chars]", len(text))
+}
+
+func redactSecrets(text string) string {
+	return botTokenPattern.ReplaceAllString(text, Redacted)
+}
@@ -3,9 +3,11 @@ package events
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"sync"
+	"sync/atomic"
+	"time"
 
-	eventbus "github.com/asaskevich/EventBus"
 	"go.uber.org/zap"
 )
 
@@ -17,15 +19,32 @@ type EventBus interface {
 	Close() error
 }
 
-// eventBus wraps the EventBus library with additional functionality
+// DepthReporter is implemented by buses that can report how many published
+// events are still being handled
+type DepthReporter interface {
+	Pending() int
+}
+
+// errorType is used to recognise handlers that return an error
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// eventBus dispatches events synchronously to the handlers of a topic. Handlers
+// are called without holding any bus lock, so a handler may publish further
+// events, and a failing handler does not stop the others from running.
 type eventBus struct {
-	bus    eventbus.Bus
 	logger *zap.Logger
 	ctx    context.Context
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
 	mu     sync.RWMutex
 	closed bool
+
+	handlersMu sync.RWMutex
+	handlers   map[string][]reflect.Value
+
+	// inFlight counts publishes whose handlers have not returned yet
+	inFlight atomic.Int64
+	metrics  *busMetrics
 }
 
 // NewEventBus creates a new event bus instance
@@ -33,19 +52,21 @@ func NewEventBus(logger *zap.Logger) EventBus {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &eventBus{
-		bus:    eventbus.New(),
-		logger: logger,
-		ctx:    ctx,
-		cancel: cancel,
+		logger:   logger,
+		ctx:      ctx,
+		cancel:   cancel,
+		handlers: make(map[string][]reflect.Value),
+		metrics:  newBusMetrics(),
 	}
 }
 
 // Publish publishes an event to the specified topic
 func (eb *eventBus) Publish(topic string, data interface{}) error {
 	eb.mu.RLock()
-	defer eb.mu.RUnlock()
+	closed := eb.closed
+	eb.mu.RUnlock()
 
-	if eb.closed {
+	if closed {
 		return fmt.Errorf("event bus is closed")
 	}
 
@@ -53,10 +74,91 @@ func (eb *eventBus) Publish(topic string, data interface{}) error {
 		zap.String("topic", topic),
 		zap.Any("data", data))
 
-	eb.bus.Publish(topic, data)
+	eb.inFlight.Add(1)
+	defer eb.inFlight.Add(-1)
+
+	eb.handlersMu.RLock()
+	handlers := append([]reflect.Value(nil), eb.handlers[topic]...)
+	eb.handlersMu.RUnlock()
+
+	eb.metrics.published(topic)
+	for _, handler := range handlers {
+		eb.deliver(topic, handler, data)
+	}
 	return nil
 }
 
+// deliver calls one handler and records how late and how long it ran
+func (eb *eventBus) deliver(topic string, handler reflect.Value, data interface{}) {
+	started := time.Now()
+	lag := time.Duration(-1)
+	if event, ok := data.(timestamped); ok && !event.OccurredAt().IsZero() {
+		lag = started.Sub(event.OccurredAt())
+	}
+
+	eb.metrics.started(topic)
+	err := callHandler(handler, data)
+	eb.metrics.finished(topic, lag, time.Since(started), err)
+
+	if err != nil {
+		eb.logger.Error("Event handler failed",
+			zap.String("topic", topic),
+			zap.Error(err))
+	}
+}
+
+// callHandler invokes a handler with the event, turning a panic or a returned
+// error into an error
+func callHandler(handler reflect.Value, data interface{}) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("handler panicked: %v", recovered)
+		}
+	}()
+
+	handlerType := handler.Type()
+	args := make([]reflect.Value, handlerType.NumIn())
+	for i := range args {
+		argType := handlerType.In(i)
+		if i > 0 || data == nil {
+			args[i] = reflect.Zero(argType)
+			continue
+		}
+		value := reflect.ValueOf(data)
+		if !value.Type().AssignableTo(argType) {
+			return fmt.Errorf("cannot pass %T to handler taking %s", data, argType)
+		}
+		args[i] = value
+	}
+
+	results := handler.Call(args)
+	if len(results) > 0 {
+		last := results[len(results)-1]
+		if last.Type().Implements(errorType) && !last.IsNil() {
+			return last.Interface().(error)
+		}
+	}
+	return nil
+}
+
+// Pending returns the number of published events still being handled
+func (eb *eventBus) Pending() int {
+	return int(eb.inFlight.Load())
+}
+
+// TopicMetrics returns the traffic and handler health of every topic that has
+// been published to or subscribed to
+func (eb *eventBus) TopicMetrics() map[string]TopicMetrics {
+	eb.handlersMu.RLock()
+	subscribers := make(map[string]int, len(eb.handlers))
+	for topic, handlers := range eb.handlers {
+		subscribers[topic] = len(handlers)
+	}
+	eb.handlersMu.RUnlock()
+
+	return eb.metrics.snapshot(subscribers)
+}
+
 // Subscribe subscribes to events on the specified topic
 func (eb *eventBus) Subscribe(topic string, handler interface{}) error {
 	eb.mu.RLock()
@@ -66,12 +168,21 @@ func (eb *eventBus) Subscribe(topic string, handler interface{}) error {
 		return fmt.Errorf("event bus is closed")
 	}
 
+	value := reflect.ValueOf(handler)
+	if value.Kind() != reflect.Func {
+		return fmt.Errorf("%s is not of type reflect.Func", value.Kind())
+	}
+
 	eb.logger.Debug("Subscribing to topic", zap.String("topic", topic))
 
-	return eb.bus.Subscribe(topic, handler)
+	eb.handlersMu.Lock()
+	eb.handlers[topic] = append(eb.handlers[topic], value)
+	eb.handlersMu.Unlock()
+	return nil
 }
 
-// Unsubscribe unsubscribes from events on the specified topic
+// Unsubscribe unsubscribes from events on the specified topic. Handlers are
+// matched by function identity, so the value passed to Subscribe is needed.
 func (eb *eventBus) Unsubscribe(topic string, handler interface{}) error {
 	eb.mu.RLock()
 	defer eb.mu.RUnlock()
@@ -82,7 +193,25 @@ func (eb *eventBus) Unsubscribe(topic string, handler interface{}) error {
 
 	eb.logger.Debug("Unsubscribing from topic", zap.String("topic", topic))
 
-	return eb.bus.Unsubscribe(topic, handler)
+	eb.handlersMu.Lock()
+	defer eb.handlersMu.Unlock()
+
+	handlers, exists := eb.handlers[topic]
+	if !exists || len(handlers) == 0 {
+		return fmt.Errorf("topic %s doesn't exist", topic)
+	}
+
+	value := reflect.ValueOf(handler)
+	if value.Kind() != reflect.Func {
+		return fmt.Errorf("%s is not of type reflect.Func", value.Kind())
+	}
+	for i, subscribed := range handlers {
+		if subscribed.Type() == value.Type() && subscribed.Pointer() == value.Pointer() {
+			eb.handlers[topic] = append(handlers[:i:i], handlers[i+1:]...)
+			break
+		}
+	}
+	return nil
 }
 
 // Close gracefully shuts down the event bus
@@ -512,3 +512,78 @@ func TestEventBus_TopicIsolation(t *testing.T) {
 	}
 	mu.Unlock()
 }
+
+func TestEventBus_NestedPublish(t *testing.T) {
+	bus := NewEventBus(zap.NewNop())
+	defer bus.Close()
+
+	received := make(chan string, 1)
+	require.NoError(t, bus.Subscribe("test.outer", func(event string) {
+		require.NoError(t, bus.Publish("test.inner", event+" forwarded"))
+	}))
+	require.NoError(t, bus.Subscribe("test.inner", func(event string) {
+		received <- event
+	}))
+
+	done := make(chan error, 1)
+	go func() { done <- bus.Publish("test.outer", "event") }()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("publishing from a handler deadlocked")
+	}
+	assert.Equal(t, "event forwarded", <-received)
+}
+
+func TestEventBus_TopicMetrics(t *testing.T) {
+	bus := NewEventBus(zap.NewNop())
+	defer bus.Close()
+
+	require.NoError(t, bus.Subscribe(TopicReminderDue, func(event ReminderDue) {}))
+	require.NoError(t, bus.Subscribe(TopicReminderDue, func(event ReminderDue) error {
+		if event.TaskID == "bad" {
+			return assert.AnError
+		}
+		return nil
+	}))
+	require.NoError(t, bus.Subscribe(TopicTaskCreated, func(event TaskCreated) {}))
+
+	late := NewEvent()
+	late.Timestamp = time.Now().Add(-time.Minute)
+	require.NoError(t, bus.Publish(TopicReminderDue, ReminderDue{Event: late, TaskID: "good"}))
+	require.NoError(t, bus.Publish(TopicReminderDue, ReminderDue{Event: NewEvent(), TaskID: "bad"}))
+	require.NoError(t, bus.Publish("test.unsubscribed", "event"))
+
+	metrics := bus.(MetricsReporter).TopicMetrics()
+
+	reminders := metrics[TopicReminderDue]
+	assert.Equal(t, int64(2), reminders.Published)
+	assert.Equal(t, int64(4), reminders.Consumed)
+	assert.Equal(t, int64(1), reminders.HandlerErrors)
+	assert.Equal(t, 0.25, reminders.ErrorRate)
+	assert.Equal(t, 2, reminders.Subscribers)
+	assert.Equal(t, int64(0), reminders.InFlight)
+	maxLag, err := time.ParseDuration(reminders.MaxLag)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, maxLag, time.Minute)
+
+	assert.Equal(t, TopicMetrics{Subscribers: 1}, metrics[TopicTaskCreated])
+	assert.Equal(t, int64(1), metrics["test.unsubscribed"].Published)
+	assert.Equal(t, int64(0), metrics["test.unsubscribed"].Consumed)
+}
+
+func TestEventBus_HandlerPanicCountedAsError(t *testing.T) {
+	bus := NewEventBus(zap.NewNop())
+	defer bus.Close()
+
+	require.NoError(t, bus.Subscribe("test.panic", func(event string) { panic("boom") }))
+	require.NoError(t, bus.Publish("test.panic", "event"))
+	// Events of the wrong type are reported instead of panicking in reflection
+	require.NoError(t, bus.Publish("test.panic", 42))
+
+	metrics := bus.(MetricsReporter).TopicMetrics()["test.panic"]
+	assert.Equal(t, int64(2), metrics.HandlerErrors)
+	assert.Equal(t, 1.0, metrics.ErrorRate)
+}
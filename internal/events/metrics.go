@@ -0,0 +1,144 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// recentLagWeight is the weight of the newest observation in the moving
+// average of handler lag
+const recentLagWeight = 0.2
+
+// MetricsReporter is implemented by buses that keep per-topic metrics
+type MetricsReporter interface {
+	TopicMetrics() map[string]TopicMetrics
+}
+
+// timestamped is implemented by every event embedding Event
+type timestamped interface {
+	OccurredAt() time.Time
+}
+
+// TopicMetrics summarizes the traffic and handler health of one topic. Lag is
+// the time from an event's creation to a handler starting on it, so a growing
+// recent lag means the topic's consumers are falling behind.
+type TopicMetrics struct {
+	Published       int64   `json:"published"`
+	Consumed        int64   `json:"consumed"` // handler calls that finished
+	HandlerErrors   int64   `json:"handler_errors"`
+	ErrorRate       float64 `json:"error_rate"`
+	Subscribers     int     `json:"subscribers"`
+	InFlight        int64   `json:"in_flight"` // handler calls still running
+	AverageLag      string  `json:"average_lag"`
+	RecentLag       string  `json:"recent_lag"`
+	MaxLag          string  `json:"max_lag"`
+	AverageHandling string  `json:"average_handling"`
+	MaxHandling     string  `json:"max_handling"`
+}
+
+// busMetrics accumulates per-topic observations
+type busMetrics struct {
+	mu     sync.Mutex
+	topics map[string]*topicStats
+}
+
+type topicStats struct {
+	published     int64
+	consumed      int64
+	errors        int64
+	inFlight      int64
+	lagged        int64 // consumed events that carried a timestamp
+	totalLag      time.Duration
+	recentLag     time.Duration
+	maxLag        time.Duration
+	totalHandling time.Duration
+	maxHandling   time.Duration
+}
+
+func newBusMetrics() *busMetrics {
+	return &busMetrics{topics: make(map[string]*topicStats)}
+}
+
+// topic returns the stats of a topic; the caller holds mu
+func (m *busMetrics) topic(topic string) *topicStats {
+	stats, exists := m.topics[topic]
+	if !exists {
+		stats = &topicStats{}
+		m.topics[topic] = stats
+	}
+	return stats
+}
+
+func (m *busMetrics) published(topic string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.topic(topic).published++
+}
+
+func (m *busMetrics) started(topic string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.topic(topic).inFlight++
+}
+
+// finished records a handler call; a negative lag means the event had no timestamp
+func (m *busMetrics) finished(topic string, lag, handling time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := m.topic(topic)
+	stats.inFlight--
+	stats.consumed++
+	if err != nil {
+		stats.errors++
+	}
+
+	stats.totalHandling += handling
+	if handling > stats.maxHandling {
+		stats.maxHandling = handling
+	}
+
+	if lag < 0 {
+		return
+	}
+	if stats.lagged == 0 {
+		stats.recentLag = lag
+	} else {
+		stats.recentLag = time.Duration(recentLagWeight*float64(lag) + (1-recentLagWeight)*float64(stats.recentLag))
+	}
+	stats.lagged++
+	stats.totalLag += lag
+	if lag > stats.maxLag {
+		stats.maxLag = lag
+	}
+}
+
+// snapshot summarizes every topic seen, including topics that only have subscribers
+func (m *busMetrics) snapshot(subscribers map[string]int) map[string]TopicMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	summaries := make(map[string]TopicMetrics, len(m.topics))
+	for topic, count := range subscribers {
+		summaries[topic] = TopicMetrics{Subscribers: count}
+	}
+	for topic, stats := range m.topics {
+		summary := summaries[topic]
+		summary.Published = stats.published
+		summary.Consumed = stats.consumed
+		summary.HandlerErrors = stats.errors
+		summary.InFlight = stats.inFlight
+		summary.RecentLag = stats.recentLag.String()
+		summary.MaxLag = stats.maxLag.String()
+		summary.MaxHandling = stats.maxHandling.String()
+		if stats.consumed > 0 {
+			summary.ErrorRate = float64(stats.errors) / float64(stats.consumed)
+			summary.AverageHandling = (stats.totalHandling / time.Duration(stats.consumed)).String()
+		}
+		if stats.lagged > 0 {
+			summary.AverageLag = (stats.totalLag / time.Duration(stats.lagged)).String()
+		}
+		summaries[topic] = summary
+	}
+	return summaries
+}
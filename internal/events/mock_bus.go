@@ -2,6 +2,7 @@ package events
 
 import (
 	"fmt"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
@@ -244,6 +245,13 @@ func (m *MockEventBus) invokeHandler(handler interface{}, event interface{}) {
 	case func(interface{}):
 		h(event)
 		handlerInvoked = true
+	default:
+		// Other typed handlers are called when their parameter matches the event
+		fn := reflect.ValueOf(handler)
+		if fn.Kind() == reflect.Func && fn.Type().NumIn() == 1 && reflect.TypeOf(event) == fn.Type().In(0) {
+			fn.Call([]reflect.Value{reflect.ValueOf(event)})
+			handlerInvoked = true
+		}
 	}
 
 	// Log type mismatches for debugging
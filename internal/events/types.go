@@ -20,12 +20,33 @@ func NewEvent() Event {
 	}
 }
 
+// OccurredAt returns when the event was created
+func (e Event) OccurredAt() time.Time {
+	return e.Timestamp
+}
+
 // MessageReceived represents an event when a message is received from a user
 type MessageReceived struct {
 	Event
 	UserID      string `json:"user_id" validate:"required"`
 	ChatID      string `json:"chat_id" validate:"required"`
 	MessageText string `json:"message_text" validate:"required"`
+
+	// Messages is set when several consecutive messages were batched together;
+	// MessageText then holds them joined by newlines
+	Messages []string `json:"messages,omitempty"`
+
+	// Preview asks for the parsed task to be shown to the user before it is saved
+	Preview bool `json:"preview,omitempty"`
+
+	// Locale is the language of the user's Telegram client, such as "en" or "pt-br"
+	Locale string `json:"locale,omitempty"`
+
+	// ListID is the shared list the tasks go into; empty for the user's own tasks
+	ListID string `json:"list_id,omitempty"`
+
+	// ThreadID is the forum topic the message was written in; 0 outside forums
+	ThreadID int `json:"thread_id,omitempty"`
 }
 
 // ParsedTask represents a task that has been parsed from natural language
@@ -35,6 +56,18 @@ type ParsedTask struct {
 	DueDate     *time.Time `json:"due_date,omitempty"`
 	Priority    string     `json:"priority" validate:"required"`
 	Tags        []string   `json:"tags"`
+	Habit       string     `json:"habit,omitempty"`    // "daily" or "weekly" when the task repeats as a habit
+	Kind        string     `json:"kind,omitempty"`     // "message" for a note to send at DueDate, empty for a task
+	Location    string     `json:"location,omitempty"` // a place the task mentions, such as "post office"
+}
+
+// ParsedKindMessage is the Kind of a parse of "send me X at 5pm"
+const ParsedKindMessage = "message"
+
+// IsScheduledMessage reports whether the parse asks for its title to be sent
+// to the user at the due date instead of being tracked as a task
+func (t ParsedTask) IsScheduledMessage() bool {
+	return t.Kind == ParsedKindMessage && t.DueDate != nil
 }
 
 // TaskParsed represents an event when a task has been successfully parsed
@@ -43,6 +76,60 @@ type TaskParsed struct {
 	UserID     string     `json:"user_id" validate:"required"`
 	ChatID     string     `json:"chat_id" validate:"required"`
 	ParsedTask ParsedTask `json:"parsed_task" validate:"required"`
+
+	// ParsedTasks holds every task when one message yielded several; ParsedTask is the first of them
+	ParsedTasks []ParsedTask `json:"parsed_tasks,omitempty"`
+
+	// Preview marks a parse result awaiting user confirmation; it is not saved yet
+	Preview bool `json:"preview,omitempty"`
+
+	// ConfidenceLevel grades the parse against the user's confidence
+	// thresholds. Empty for tasks that were not parsed by the LLM.
+	ConfidenceLevel string `json:"confidence_level,omitempty"`
+
+	// ListID is the shared list the tasks go into; empty for the user's own tasks
+	ListID string `json:"list_id,omitempty"`
+
+	// ThreadID is the forum topic the message was written in; 0 outside forums
+	ThreadID int `json:"thread_id,omitempty"`
+
+	// OriginalText is the full message when it was long and the tasks
+	// summarize it; empty otherwise
+	OriginalText string `json:"original_text,omitempty"`
+}
+
+// TaskParseFailed is published when a received message yields no task, so
+// that the user can be told and offered to save the text as it is
+type TaskParseFailed struct {
+	Event
+	UserID      string `json:"user_id" validate:"required"`
+	ChatID      string `json:"chat_id" validate:"required"`
+	MessageText string `json:"message_text"`
+	Reason      string `json:"reason"`
+	ListID      string `json:"list_id,omitempty"` // shared list the message was meant for
+}
+
+// Reasons a message yields no task
+const (
+	ParseFailureRejected = "rejected" // input guardrails refused the message
+	ParseFailureProvider = "provider" // the LLM call failed
+	ParseFailureInvalid  = "invalid"  // no parsed task passed validation
+)
+
+// Confidence levels of a parse
+const (
+	ConfidenceLevelHigh    = "high"     // saved without confirmation
+	ConfidenceLevelMedium  = "medium"   // confirmed by the user
+	ConfidenceLevelLow     = "low"      // confirmed, with a request to check the details
+	ConfidenceLevelVeryLow = "very_low" // confirmed, flagged as a guess
+)
+
+// AllTasks returns every parsed task carried by the event
+func (e TaskParsed) AllTasks() []ParsedTask {
+	if len(e.ParsedTasks) > 0 {
+		return e.ParsedTasks
+	}
+	return []ParsedTask{e.ParsedTask}
 }
 
 // ReminderDue represents an event when a reminder is due to be sent
@@ -51,6 +138,86 @@ type ReminderDue struct {
 	TaskID string `json:"task_id" validate:"required"`
 	UserID string `json:"user_id" validate:"required"`
 	ChatID string `json:"chat_id" validate:"required"`
+
+	// Experiment fields are set when the reminder copy comes from an A/B test variant
+	Experiment      string `json:"experiment,omitempty"`
+	Variant         string `json:"variant,omitempty"`
+	MessageTemplate string `json:"message_template,omitempty"`
+
+	// Test is set for reminders fired on demand with /testreminder; they are
+	// delivered like real reminders but never touch the reminder schedule
+	Test bool `json:"test,omitempty"`
+
+	// Task details, set when the task could be loaded; used for calendar links
+	Title   string     `json:"title,omitempty"`
+	DueDate *time.Time `json:"due_date,omitempty"`
+
+	// ThreadID is the forum topic the task was created in; 0 outside forums
+	ThreadID int `json:"thread_id,omitempty"`
+
+	// GroupID is set when the reminder is sent as part of a ReminderGroupDue;
+	// the chat gets the group's message instead of one for this reminder
+	GroupID string `json:"group_id,omitempty"`
+
+	// Priority is the task's priority, set with the task details
+	Priority string `json:"priority,omitempty"`
+	// Channels lists where the reminder goes, from the user's routes for its
+	// priority. Empty sends it to Telegram only.
+	Channels []string `json:"channels,omitempty"`
+}
+
+// Reminder channels
+const (
+	ReminderChannelTelegram = "telegram"
+	ReminderChannelEmail    = "email"
+	ReminderChannelDigest   = "digest" // the next daily digest, instead of right away
+)
+
+// SendsTo reports whether the reminder is routed to a channel
+func (e ReminderDue) SendsTo(channel string) bool {
+	if len(e.Channels) == 0 {
+		return channel == ReminderChannelTelegram
+	}
+	for _, c := range e.Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// ReminderGroupDue combines the reminders due for one chat in the same
+// scheduler cycle, for users who have reminder grouping turned on
+type ReminderGroupDue struct {
+	Event
+	GroupID   string        `json:"group_id" validate:"required"`
+	UserID    string        `json:"user_id" validate:"required"`
+	ChatID    string        `json:"chat_id" validate:"required"`
+	Reminders []ReminderDue `json:"reminders" validate:"required,min=2"`
+}
+
+// ReminderDigestDue carries the reminders routed to a chat's daily digest
+// since the last one was sent
+type ReminderDigestDue struct {
+	Event
+	UserID    string        `json:"user_id" validate:"required"`
+	ChatID    string        `json:"chat_id" validate:"required"`
+	Reminders []ReminderDue `json:"reminders" validate:"required,min=1"`
+	// Conflicts lists the chat's tasks due around the same time over the
+	// next day; set on the first message of a digest only
+	Conflicts []TaskConflict `json:"conflicts,omitempty"`
+	// Holiday tells that tomorrow is a public holiday where the user lives;
+	// set on the first message of a digest only
+	Holiday *HolidayNotice `json:"holiday,omitempty"`
+}
+
+// HolidayNotice is a public holiday tomorrow, and the user's tasks due that
+// day that were moved to their next business day
+type HolidayNotice struct {
+	Name    string    `json:"name"`
+	Date    time.Time `json:"date"`
+	Moved   int       `json:"moved"`
+	MovedTo time.Time `json:"moved_to,omitempty"`
 }
 
 // TaskCompleted represents an event when a task has been completed
@@ -61,6 +228,78 @@ type TaskCompleted struct {
 	CompletedAt time.Time `json:"completed_at" validate:"required"`
 }
 
+// TaskStatusChanged is published whenever a stored task changes status,
+// including snoozes and deletions
+type TaskStatusChanged struct {
+	Event
+	TaskID       string     `json:"task_id" validate:"required"`
+	UserID       string     `json:"user_id" validate:"required"`
+	FromStatus   string     `json:"from_status,omitempty"`
+	ToStatus     string     `json:"to_status" validate:"required"`
+	SnoozedUntil *time.Time `json:"snoozed_until,omitempty"`
+	// PreviousDueDate is the due date a snooze replaced
+	PreviousDueDate *time.Time `json:"previous_due_date,omitempty"`
+}
+
+// TaskOverdue is published once when an open task passes its due date, and
+// again whenever it passes a new due date
+type TaskOverdue struct {
+	Event
+	TaskID   string    `json:"task_id" validate:"required"`
+	UserID   string    `json:"user_id" validate:"required"`
+	ChatID   string    `json:"chat_id,omitempty"`
+	Title    string    `json:"title" validate:"required"`
+	Priority string    `json:"priority,omitempty"`
+	DueDate  time.Time `json:"due_date" validate:"required"`
+}
+
+// HabitLogged is published when a habit is done for its current period
+type HabitLogged struct {
+	Event
+	TaskID     string     `json:"task_id" validate:"required"`
+	UserID     string     `json:"user_id" validate:"required"`
+	Habit      string     `json:"habit" validate:"required"`
+	Streak     int        `json:"streak"`
+	BestStreak int        `json:"best_streak"`
+	NextDue    *time.Time `json:"next_due,omitempty"`
+}
+
+// HabitMissed is published when a habit's period ended without it being done
+type HabitMissed struct {
+	Event
+	TaskID     string    `json:"task_id" validate:"required"`
+	UserID     string    `json:"user_id" validate:"required"`
+	ChatID     string    `json:"chat_id,omitempty"`
+	Title      string    `json:"title" validate:"required"`
+	Habit      string    `json:"habit" validate:"required"`
+	LostStreak int       `json:"lost_streak"`
+	NextDue    time.Time `json:"next_due"`
+	Muted      bool      `json:"muted,omitempty"` // the user turned off nudges for the habit or altogether
+}
+
+// TaskEdited is published when fields of a stored task are changed
+type TaskEdited struct {
+	Event
+	TaskID string   `json:"task_id" validate:"required"`
+	UserID string   `json:"user_id" validate:"required"`
+	Fields []string `json:"fields" validate:"required"` // names of the changed fields
+
+	// PreviousDueDate and DueDate are set when the due date is among the fields
+	PreviousDueDate *time.Time `json:"previous_due_date,omitempty"`
+	DueDate         *time.Time `json:"due_date,omitempty"`
+}
+
+// TaskSlipped is published when a task has been postponed often enough that
+// the user may want to rethink it
+type TaskSlipped struct {
+	Event
+	TaskID        string `json:"task_id" validate:"required"`
+	UserID        string `json:"user_id" validate:"required"`
+	ChatID        string `json:"chat_id" validate:"required"`
+	Title         string `json:"title" validate:"required"`
+	Postponements int    `json:"postponements" validate:"required"`
+}
+
 // TaskCreated represents an event when a new task has been created
 type TaskCreated struct {
 	Event
@@ -70,6 +309,52 @@ type TaskCreated struct {
 	DueDate   *time.Time `json:"due_date,omitempty"`
 	Priority  string     `json:"priority" validate:"required"`
 	CreatedAt time.Time  `json:"created_at" validate:"required"`
+	// Code is the short code users can type instead of the task ID, e.g. T-42
+	Code string `json:"code,omitempty"`
+
+	// Estimate predicts when the task will be done; nil without enough history
+	Estimate *CompletionEstimate `json:"estimate,omitempty"`
+	// Conflicts lists the user's open tasks due around the same time
+	Conflicts []TaskConflict `json:"conflicts,omitempty"`
+
+	// Summary is the condensed description of a task summarized from a long
+	// message, whose full text can be requested with TaskOriginalRequested
+	Summary     string `json:"summary,omitempty"`
+	HasOriginal bool   `json:"has_original,omitempty"`
+
+	// BatchID is set when the task was created as part of a bulk creation;
+	// a TasksCreated event with the same BatchID follows the items
+	BatchID   string `json:"batch_id,omitempty"`
+	BatchSize int    `json:"batch_size,omitempty"`
+}
+
+// CompletionEstimate predicts when a task will be done from how long the user
+// took over similar tasks. Late is set when that is after the task's due date.
+type CompletionEstimate struct {
+	DoneBy  time.Time     `json:"done_by"`
+	Typical time.Duration `json:"typical"`
+	Tag     string        `json:"tag,omitempty"` // tag of the tasks the estimate is based on
+	Late    bool          `json:"late"`
+}
+
+// TaskConflict is a pair of open tasks whose due times overlap
+type TaskConflict struct {
+	TaskID       string    `json:"task_id"`
+	Title        string    `json:"title"`
+	DueDate      time.Time `json:"due_date"`
+	OtherTaskID  string    `json:"other_task_id"`
+	OtherTitle   string    `json:"other_title"`
+	OtherDueDate time.Time `json:"other_due_date"`
+}
+
+// TasksCreated represents an event when several tasks were created together from one message
+type TasksCreated struct {
+	Event
+	UserID    string        `json:"user_id" validate:"required"`
+	ChatID    string        `json:"chat_id" validate:"required"`
+	Tasks     []TaskSummary `json:"tasks" validate:"required"`
+	CreatedAt time.Time     `json:"created_at" validate:"required"`
+	BatchID   string        `json:"batch_id,omitempty"`
 }
 
 // TaskListRequested represents an event when a user requests their task list
@@ -77,6 +362,11 @@ type TaskListRequested struct {
 	Event
 	UserID string `json:"user_id" validate:"required"`
 	ChatID string `json:"chat_id" validate:"required"`
+	// Page is the list page to show, counted from 0
+	Page int `json:"page,omitempty"`
+	// MessageID is the list message to update in place, 0 for the chat's
+	// tracked list message
+	MessageID int `json:"message_id,omitempty"`
 }
 
 // TaskActionRequested represents an event when a user requests a task action
@@ -86,6 +376,122 @@ type TaskActionRequested struct {
 	ChatID string `json:"chat_id" validate:"required"`
 	TaskID string `json:"task_id" validate:"required"`
 	Action string `json:"action" validate:"required"` // done, delete, snooze
+	// Argument is how long or until when to snooze, such as 2h or tomorrow
+	// 9am in the user's timezone; empty snoozes for an hour
+	Argument string `json:"argument,omitempty"`
+}
+
+// TaskFieldUpdateRequested represents a request to set or clear a custom field
+// on a task. The outcome is reported as a TaskActionResponse with action "field".
+type TaskFieldUpdateRequested struct {
+	Event
+	UserID  string   `json:"user_id" validate:"required"`
+	ChatID  string   `json:"chat_id" validate:"required"`
+	TaskID  string   `json:"task_id" validate:"required"`
+	Key     string   `json:"key" validate:"required"`
+	Type    string   `json:"type,omitempty"` // text, number, date, enum; empty clears the field
+	Value   string   `json:"value,omitempty"`
+	Options []string `json:"options,omitempty"` // allowed values of an enum field
+}
+
+// TaskRescheduleRequested represents a /snoozeall or /moveto command for today's
+// remaining tasks. Without TaskIDs it asks for a preview; with them it applies
+// the confirmed shift to those tasks.
+type TaskRescheduleRequested struct {
+	Event
+	UserID   string   `json:"user_id" validate:"required"`
+	ChatID   string   `json:"chat_id" validate:"required"`
+	Command  string   `json:"command" validate:"required"`  // snoozeall or moveto
+	Argument string   `json:"argument" validate:"required"` // e.g. 2h or tomorrow
+	TaskIDs  []string `json:"task_ids,omitempty"`
+}
+
+// TaskHistoryRequested represents a request to show a task's history timeline
+type TaskHistoryRequested struct {
+	Event
+	UserID string `json:"user_id" validate:"required"`
+	ChatID string `json:"chat_id" validate:"required"`
+	TaskID string `json:"task_id" validate:"required"`
+}
+
+// TaskHistoryEntry is one entry of a task's history timeline
+type TaskHistoryEntry struct {
+	Kind       string    `json:"kind" validate:"required"` // created, status_changed, snoozed, nudge_sent, edited
+	FromStatus string    `json:"from_status,omitempty"`
+	ToStatus   string    `json:"to_status,omitempty"`
+	Detail     string    `json:"detail,omitempty"`
+	OccurredAt time.Time `json:"occurred_at" validate:"required"`
+}
+
+// TaskHistoryResponse carries the timeline for a TaskHistoryRequested, oldest entry first
+type TaskHistoryResponse struct {
+	Event
+	UserID    string             `json:"user_id" validate:"required"`
+	ChatID    string             `json:"chat_id" validate:"required"`
+	TaskID    string             `json:"task_id" validate:"required"`
+	TaskTitle string             `json:"task_title,omitempty"`
+	Entries   []TaskHistoryEntry `json:"entries"`
+	Success   bool               `json:"success"`
+	Message   string             `json:"message,omitempty"`
+}
+
+// TaskOriginalRequested asks for the full message a task was summarized from
+type TaskOriginalRequested struct {
+	Event
+	UserID string `json:"user_id" validate:"required"`
+	ChatID string `json:"chat_id" validate:"required"`
+	TaskID string `json:"task_id" validate:"required"`
+}
+
+// TaskOriginalResponse carries the full message for a TaskOriginalRequested
+type TaskOriginalResponse struct {
+	Event
+	UserID    string `json:"user_id" validate:"required"`
+	ChatID    string `json:"chat_id" validate:"required"`
+	TaskID    string `json:"task_id" validate:"required"`
+	TaskTitle string `json:"task_title,omitempty"`
+	Text      string `json:"text,omitempty"`
+	Success   bool   `json:"success"`
+	Message   string `json:"message,omitempty"`
+}
+
+// TaskDetailsRequested asks for one task by ID, or by the short code typed
+// in /task or opened from a t.me deep link
+type TaskDetailsRequested struct {
+	Event
+	UserID string `json:"user_id" validate:"required"`
+	ChatID string `json:"chat_id" validate:"required"`
+	TaskID string `json:"task_id,omitempty"`
+	Code   int    `json:"code,omitempty"`
+}
+
+// TaskReminderSummary is a reminder scheduled for a task
+type TaskReminderSummary struct {
+	ScheduledAt time.Time `json:"scheduled_at"`
+	Type        string    `json:"type"` // initial or nudge
+}
+
+// TaskHistorySummary counts the entries of a task's history; the full
+// timeline is sent for a TaskHistoryRequested
+type TaskHistorySummary struct {
+	Entries int               `json:"entries"`
+	Snoozes int               `json:"snoozes"`
+	Nudges  int               `json:"nudges"`
+	Edits   int               `json:"edits"`
+	Last    *TaskHistoryEntry `json:"last,omitempty"`
+}
+
+// TaskDetailsResponse carries the task for a TaskDetailsRequested, with its
+// upcoming reminders, earliest first, and a summary of its history
+type TaskDetailsResponse struct {
+	Event
+	UserID    string                `json:"user_id" validate:"required"`
+	ChatID    string                `json:"chat_id" validate:"required"`
+	Task      TaskSummary           `json:"task"`
+	Reminders []TaskReminderSummary `json:"reminders,omitempty"`
+	History   TaskHistorySummary    `json:"history"`
+	Success   bool                  `json:"success"`
+	Message   string                `json:"message,omitempty"`
 }
 
 // UserSessionStarted represents an event when a user starts a session
@@ -96,6 +502,14 @@ type UserSessionStarted struct {
 	SessionType string `json:"session_type" validate:"required"`
 }
 
+// UserActivity is published whenever a user interacts with the bot in a chat,
+// so reminders can be held back while the user is active
+type UserActivity struct {
+	Event
+	UserID string `json:"user_id" validate:"required"`
+	ChatID string `json:"chat_id" validate:"required"`
+}
+
 // CommandExecuted represents an event when a command is executed
 type CommandExecuted struct {
 	Event
@@ -109,12 +523,27 @@ type CommandExecuted struct {
 // TaskSummary represents a lightweight task representation for responses
 type TaskSummary struct {
 	ID          string     `json:"id" validate:"required"`
+	Code        string     `json:"code,omitempty"` // short code users can type instead of the ID
 	Title       string     `json:"title" validate:"required"`
 	Description string     `json:"description"`
 	DueDate     *time.Time `json:"due_date,omitempty"`
 	Priority    string     `json:"priority" validate:"required"`
 	Status      string     `json:"status" validate:"required"`
 	IsOverdue   bool       `json:"is_overdue"`
+	Muted       bool       `json:"muted,omitempty"`
+	Habit       string     `json:"habit,omitempty"`
+	Streak      int        `json:"streak,omitempty"`
+	List        string     `json:"list,omitempty"`         // name of the shared list the task is in
+	HasOriginal bool       `json:"has_original,omitempty"` // the task summarizes a long message
+
+	CustomFields map[string]string `json:"custom_fields,omitempty"`
+	Links        []string          `json:"links,omitempty"`
+
+	// Location is the place the task mentions; Address and MapURL are set
+	// once it has been geocoded
+	Location string `json:"location,omitempty"`
+	Address  string `json:"address,omitempty"`
+	MapURL   string `json:"map_url,omitempty"`
 }
 
 // TaskListResponse represents an event response to task list requests
@@ -128,6 +557,8 @@ type TaskListResponse struct {
 	Success    bool          `json:"success"`
 	ErrorCode  string        `json:"error_code,omitempty"`
 	ErrorMsg   string        `json:"error_message,omitempty"`
+	Page       int           `json:"page,omitempty"`
+	MessageID  int           `json:"message_id,omitempty"`
 }
 
 // TaskActionResponse represents an event response to task action requests
@@ -141,17 +572,772 @@ type TaskActionResponse struct {
 	Message string `json:"message"`
 }
 
+// TaskRescheduleResponse carries the preview or outcome of a TaskRescheduleRequested
+type TaskRescheduleResponse struct {
+	Event
+	UserID   string        `json:"user_id" validate:"required"`
+	ChatID   string        `json:"chat_id" validate:"required"`
+	Command  string        `json:"command"`
+	Argument string        `json:"argument"`
+	Shift    time.Duration `json:"shift"`
+	// ShiftDays is set instead of Shift for moves by whole calendar days,
+	// which keep each task's time of day in the user's timezone
+	ShiftDays int           `json:"shift_days,omitempty"`
+	Tasks     []TaskSummary `json:"tasks"` // due dates before the shift in a preview, after it once applied
+	Applied   bool          `json:"applied"`
+	Success   bool          `json:"success"`
+	Message   string        `json:"message,omitempty"`
+}
+
+// APITokenRequested represents an /apitoken command to issue or revoke the
+// user's personal API token
+type APITokenRequested struct {
+	Event
+	UserID string `json:"user_id" validate:"required"`
+	ChatID string `json:"chat_id" validate:"required"`
+	Revoke bool   `json:"revoke,omitempty"`
+}
+
+// APITokenResponse carries the token issued for an APITokenRequested, or the
+// number of tokens revoked
+type APITokenResponse struct {
+	Event
+	UserID  string `json:"user_id" validate:"required"`
+	ChatID  string `json:"chat_id" validate:"required"`
+	Revoke  bool   `json:"revoke,omitempty"`
+	Token   string `json:"-"` // never serialized
+	Revoked int    `json:"revoked,omitempty"`
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+}
+
+// RetentionRequested represents a /retention command to show or change how
+// long the user's completed tasks are kept
+type RetentionRequested struct {
+	Event
+	UserID string `json:"user_id" validate:"required"`
+	ChatID string `json:"chat_id" validate:"required"`
+	// Days is the new retention, 0 to keep completed tasks forever; nil
+	// shows the current choice
+	Days *int `json:"days,omitempty"`
+}
+
+// RetentionResponse carries the user's retention after a RetentionRequested
+type RetentionResponse struct {
+	Event
+	UserID  string `json:"user_id" validate:"required"`
+	ChatID  string `json:"chat_id" validate:"required"`
+	Days    int    `json:"days"` // 0 keeps completed tasks forever
+	Changed bool   `json:"changed,omitempty"`
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+}
+
+// Settings the /settings menu shows and changes
+const (
+	SettingInterval    = "interval"     // a duration between reminders, such as "30m"
+	SettingMaxNudges   = "max_nudges"   // reminders per task, such as "3"
+	SettingQuietHours  = "quiet_hours"  // "22:00-07:00", or SettingOff
+	SettingTimezone    = "timezone"     // an IANA name such as "Europe/Berlin"
+	SettingLanguage    = "language"     // a language such as "vi", or SettingOff
+	SettingWeekStart   = "week_start"   // a weekday such as "sunday"
+	SettingWorkingDays = "working_days" // weekdays such as "mon-fri" or "sun,mon,tue"
+	SettingCountry     = "country"      // a country code such as "US" for public holidays, or SettingOff
+)
+
+// SettingOff turns quiet hours off, or makes the language follow the user's
+// Telegram client
+const SettingOff = "off"
+
+// SettingsRequested represents opening the /settings menu or choosing an
+// option in it
+type SettingsRequested struct {
+	Event
+	UserID string `json:"user_id" validate:"required"`
+	ChatID string `json:"chat_id" validate:"required"`
+	// MessageID is the menu to update in place, 0 to send a new one
+	MessageID int `json:"message_id,omitempty"`
+	// Page is the setting whose options to show, empty for the summary
+	Page string `json:"page,omitempty"`
+	// Field and Value change one setting before the menu is shown; an empty
+	// Field changes nothing
+	Field string `json:"field,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
+// UserSettings are the settings the /settings menu shows
+type UserSettings struct {
+	NudgeInterval   time.Duration `json:"nudge_interval"`
+	MaxNudges       int           `json:"max_nudges"`
+	QuietHoursStart string        `json:"quiet_hours_start,omitempty"`
+	QuietHoursEnd   string        `json:"quiet_hours_end,omitempty"`
+	Timezone        string        `json:"timezone,omitempty"`
+	Language        string        `json:"language,omitempty"`     // empty follows the Telegram client
+	WeekStart       string        `json:"week_start,omitempty"`   // empty is Monday
+	WorkingDays     string        `json:"working_days,omitempty"` // such as "mon,tue,wed,thu,fri"; empty is Monday to Friday
+	Country         string        `json:"country,omitempty"`      // such as "US"; empty observes no public holidays
+}
+
+// SettingsResponse carries the user's settings after a SettingsRequested
+type SettingsResponse struct {
+	Event
+	UserID    string       `json:"user_id" validate:"required"`
+	ChatID    string       `json:"chat_id" validate:"required"`
+	MessageID int          `json:"message_id,omitempty"`
+	Page      string       `json:"page,omitempty"`
+	Settings  UserSettings `json:"settings"`
+	Changed   string       `json:"changed,omitempty"` // the setting that was changed
+	Success   bool         `json:"success"`
+	Message   string       `json:"message,omitempty"`
+}
+
+// BroadcastRequested represents an admin's /broadcast of a message to every
+// user of their bot
+type BroadcastRequested struct {
+	Event
+	UserID string `json:"user_id" validate:"required"`
+	ChatID string `json:"chat_id" validate:"required"`
+	Bot    string `json:"bot,omitempty"` // empty for the default bot
+	Text   string `json:"text" validate:"required"`
+}
+
+// BroadcastReady carries the users a broadcast goes to, for the bot to send it
+type BroadcastReady struct {
+	Event
+	UserID      string  `json:"user_id" validate:"required"`
+	ChatID      string  `json:"chat_id" validate:"required"`
+	Bot         string  `json:"bot,omitempty"`
+	Text        string  `json:"text"`
+	TelegramIDs []int64 `json:"telegram_ids,omitempty"`
+	Success     bool    `json:"success"`
+	Message     string  `json:"message,omitempty"`
+}
+
+// GlobalStatsRequested represents an admin's /globalstats command
+type GlobalStatsRequested struct {
+	Event
+	UserID string `json:"user_id" validate:"required"`
+	ChatID string `json:"chat_id" validate:"required"`
+}
+
+// GlobalStats are deployment-wide counts; the recent ones cover the last
+// seven days, and reminders the last day
+type GlobalStats struct {
+	Users          int64 `json:"users"`
+	NewUsers       int64 `json:"new_users"`
+	OpenTasks      int64 `json:"open_tasks"`
+	TasksCreated   int64 `json:"tasks_created"`
+	TasksCompleted int64 `json:"tasks_completed"`
+	RemindersSent  int64 `json:"reminders_sent"`
+}
+
+// GlobalStatsResponse carries the counts after a GlobalStatsRequested
+type GlobalStatsResponse struct {
+	Event
+	UserID  string      `json:"user_id" validate:"required"`
+	ChatID  string      `json:"chat_id" validate:"required"`
+	Stats   GlobalStats `json:"stats"`
+	Success bool        `json:"success"`
+	Message string      `json:"message,omitempty"`
+}
+
+// MaintenanceRequested represents an admin's /maintenance command turning
+// maintenance mode on or off
+type MaintenanceRequested struct {
+	Event
+	UserID  string `json:"user_id" validate:"required"`
+	ChatID  string `json:"chat_id" validate:"required"`
+	Enabled bool   `json:"enabled"`
+	Message string `json:"message,omitempty"`
+}
+
+// MaintenanceChanged announces that maintenance mode was turned on or off.
+// UserID and ChatID name the admin who asked from the chat, and are empty
+// for changes made through the admin API.
+type MaintenanceChanged struct {
+	Event
+	Enabled bool      `json:"enabled"`
+	Message string    `json:"message,omitempty"`
+	Since   time.Time `json:"since"`
+	UserID  string    `json:"user_id,omitempty"`
+	ChatID  string    `json:"chat_id,omitempty"`
+}
+
+// WeeklyStatsRequested represents a /stats command for the user's last seven days
+type WeeklyStatsRequested struct {
+	Event
+	UserID string `json:"user_id" validate:"required"`
+	ChatID string `json:"chat_id" validate:"required"`
+}
+
+// TaskSlippage summarizes how often a task's due date was pushed back
+type TaskSlippage struct {
+	TaskID        string        `json:"task_id" validate:"required"`
+	Title         string        `json:"title" validate:"required"`
+	Postponements int           `json:"postponements"`
+	Slip          time.Duration `json:"slip"` // total time the due date moved back
+}
+
+// BurndownDay is one day of a burndown: the tasks open at the end of the day
+// and the tasks completed during it
+type BurndownDay struct {
+	Day       time.Time `json:"day"`
+	Open      int       `json:"open"`
+	Completed int       `json:"completed"`
+}
+
+// TaskBreakdown counts a user's open tasks by priority and by tag, and their
+// overdue tasks by how long ago they were due
+type TaskBreakdown struct {
+	ByPriority          map[string]int `json:"by_priority,omitempty"`
+	ByTag               map[string]int `json:"by_tag,omitempty"`
+	OverdueUnderADay    int            `json:"overdue_under_a_day"`
+	OverdueOneToThree   int            `json:"overdue_one_to_three_days"`
+	OverdueThreeToSeven int            `json:"overdue_three_to_seven_days"`
+	OverdueOverAWeek    int            `json:"overdue_over_a_week"`
+}
+
+// WeeklyStatsResponse answers a WeeklyStatsRequested. Slipped lists the tasks
+// postponed during the week, most postponed first. Burndown covers the last
+// 30 days, oldest first.
+type WeeklyStatsResponse struct {
+	Event
+	UserID        string         `json:"user_id" validate:"required"`
+	ChatID        string         `json:"chat_id" validate:"required"`
+	Since         time.Time      `json:"since"`
+	Created       int            `json:"created"`
+	Completed     int            `json:"completed"`
+	Open          int            `json:"open"`
+	Overdue       int            `json:"overdue"`
+	Postponements int            `json:"postponements"`
+	Slipped       []TaskSlippage `json:"slipped,omitempty"`
+	Burndown      []BurndownDay  `json:"burndown,omitempty"`
+	Breakdown     *TaskBreakdown `json:"breakdown,omitempty"`
+	Success       bool           `json:"success"`
+	Message       string         `json:"message,omitempty"`
+}
+
+// SystemLoadChanged announces that the service entered or left degraded mode
+type SystemLoadChanged struct {
+	Event
+	Degraded    bool   `json:"degraded"`
+	Reason      string `json:"reason,omitempty"`
+	EventDepth  int    `json:"event_depth"`
+	DBLatencyMs int64  `json:"db_latency_ms"`
+}
+
+// UpdateReceived carries a raw Telegram update accepted by the webhook and
+// queued for the chatbot to parse
+type UpdateReceived struct {
+	Event
+	UpdateID int    `json:"update_id"`
+	Bot      string `json:"bot,omitempty"` // receiving bot; empty for the default bot
+	Payload  []byte `json:"payload" validate:"required"`
+}
+
+// WorkspaceInviteRequested represents a request to invite people to the
+// workspace shared by a chat
+type WorkspaceInviteRequested struct {
+	Event
+	UserID string `json:"user_id" validate:"required"`
+	ChatID string `json:"chat_id" validate:"required"`
+	Role   string `json:"role" validate:"required"` // owner, editor or viewer
+}
+
+// WorkspaceInviteResponse carries the invite code created for a WorkspaceInviteRequested
+type WorkspaceInviteResponse struct {
+	Event
+	UserID  string `json:"user_id" validate:"required"`
+	ChatID  string `json:"chat_id" validate:"required"`
+	Role    string `json:"role"`
+	Code    string `json:"code,omitempty"`
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// WorkspaceJoinRequested represents a user opening an invite deep link
+type WorkspaceJoinRequested struct {
+	Event
+	UserID string `json:"user_id" validate:"required"`
+	ChatID string `json:"chat_id" validate:"required"`
+	Code   string `json:"code" validate:"required"`
+}
+
+// WorkspaceJoinResponse reports the outcome of a WorkspaceJoinRequested
+type WorkspaceJoinResponse struct {
+	Event
+	UserID      string `json:"user_id" validate:"required"`
+	ChatID      string `json:"chat_id" validate:"required"`
+	WorkspaceID string `json:"workspace_id,omitempty"` // chat ID of the joined workspace
+	Role        string `json:"role,omitempty"`
+	Success     bool   `json:"success"`
+	Message     string `json:"message"`
+}
+
+// ListCreateRequested represents a /newlist command for a shared task list
+type ListCreateRequested struct {
+	Event
+	UserID string `json:"user_id" validate:"required"`
+	ChatID string `json:"chat_id" validate:"required"`
+	Name   string `json:"name" validate:"required"`
+}
+
+// ListJoinRequested represents a user opening a shared list's invite link
+type ListJoinRequested struct {
+	Event
+	UserID string `json:"user_id" validate:"required"`
+	ChatID string `json:"chat_id" validate:"required"`
+	Code   string `json:"code" validate:"required"`
+}
+
+// ListTaskRequested represents an /addto command. Once the list is found the
+// message is parsed like any other, with the list set on MessageReceived.
+type ListTaskRequested struct {
+	Event
+	UserID      string `json:"user_id" validate:"required"`
+	ChatID      string `json:"chat_id" validate:"required"`
+	List        string `json:"list" validate:"required"` // list name
+	MessageText string `json:"message_text" validate:"required"`
+	Locale      string `json:"locale,omitempty"`
+}
+
+// Shared list actions reported by a ListResponse
+const (
+	ListActionCreate = "create"
+	ListActionJoin   = "join"
+	ListActionAdd    = "add"
+)
+
+// ListResponse reports the outcome of a ListCreateRequested, ListJoinRequested
+// or, when it fails, ListTaskRequested
+type ListResponse struct {
+	Event
+	UserID  string `json:"user_id" validate:"required"`
+	ChatID  string `json:"chat_id" validate:"required"`
+	Action  string `json:"action" validate:"required"`
+	ListID  string `json:"list_id,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Code    string `json:"code,omitempty"` // invite code, set for created lists
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+}
+
+// ListsRequested represents a /lists command for the user's shared lists
+type ListsRequested struct {
+	Event
+	UserID string `json:"user_id" validate:"required"`
+	ChatID string `json:"chat_id" validate:"required"`
+}
+
+// ListSummary is a shared list as shown to one of its members
+type ListSummary struct {
+	ID      string `json:"id" validate:"required"`
+	Name    string `json:"name" validate:"required"`
+	Code    string `json:"code" validate:"required"`
+	Members int    `json:"members"`
+	Owner   bool   `json:"owner"`
+}
+
+// ListsResponse carries the shared lists for a ListsRequested
+type ListsResponse struct {
+	Event
+	UserID  string        `json:"user_id" validate:"required"`
+	ChatID  string        `json:"chat_id" validate:"required"`
+	Lists   []ListSummary `json:"lists"`
+	Success bool          `json:"success"`
+	Message string        `json:"message,omitempty"`
+}
+
+// ListTaskCompleted tells one member of a shared list that another member
+// completed a task in it. One event is published per member to notify.
+type ListTaskCompleted struct {
+	Event
+	UserID      string `json:"user_id" validate:"required"` // member to notify
+	ChatID      string `json:"chat_id" validate:"required"`
+	ListID      string `json:"list_id" validate:"required"`
+	ListName    string `json:"list_name" validate:"required"`
+	TaskID      string `json:"task_id" validate:"required"`
+	Title       string `json:"title" validate:"required"`
+	CompletedBy string `json:"completed_by" validate:"required"`
+}
+
+// TaskDelegationRequested asks to hand a task off to another user, named by
+// their Telegram username as seen by Bot
+type TaskDelegationRequested struct {
+	Event
+	UserID   string `json:"user_id" validate:"required"`
+	ChatID   string `json:"chat_id" validate:"required"`
+	TaskID   string `json:"task_id" validate:"required"`
+	Username string `json:"username" validate:"required"`
+	Bot      string `json:"bot,omitempty"` // empty for the default bot
+}
+
+// Task delegation actions reported in TaskDelegationResponse
+const (
+	DelegationActionDelegate = "delegate"
+	DelegationActionAccept   = "accept"
+	DelegationActionDecline  = "decline"
+)
+
+// TaskDelegationResponse reports the outcome of a delegation request, or of
+// the delegate's answer, to the user who acted
+type TaskDelegationResponse struct {
+	Event
+	UserID  string `json:"user_id" validate:"required"`
+	ChatID  string `json:"chat_id" validate:"required"`
+	TaskID  string `json:"task_id"`
+	Title   string `json:"title,omitempty"`
+	Action  string `json:"action" validate:"required"`
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+}
+
+// TaskDelegated offers a task to its delegate, who accepts or declines it
+type TaskDelegated struct {
+	Event
+	UserID      string     `json:"user_id" validate:"required"` // delegate
+	ChatID      string     `json:"chat_id" validate:"required"`
+	TaskID      string     `json:"task_id" validate:"required"`
+	Title       string     `json:"title" validate:"required"`
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	DelegatedBy string     `json:"delegated_by" validate:"required"` // delegator's display name
+}
+
+// TaskDelegationReplied carries the delegate's answer to a TaskDelegated offer
+type TaskDelegationReplied struct {
+	Event
+	UserID string `json:"user_id" validate:"required"`
+	ChatID string `json:"chat_id" validate:"required"`
+	TaskID string `json:"task_id" validate:"required"`
+	Accept bool   `json:"accept"`
+}
+
+// TaskDelegationResolved tells the delegator whether the delegate accepted the task
+type TaskDelegationResolved struct {
+	Event
+	UserID   string `json:"user_id" validate:"required"` // delegator
+	ChatID   string `json:"chat_id" validate:"required"`
+	TaskID   string `json:"task_id" validate:"required"`
+	Title    string `json:"title" validate:"required"`
+	Delegate string `json:"delegate" validate:"required"` // delegate's display name
+	Accepted bool   `json:"accepted"`
+}
+
+// ScheduledMessageCreated is published when a message is stored to be sent later
+type ScheduledMessageCreated struct {
+	Event
+	MessageID string    `json:"message_id" validate:"required"`
+	UserID    string    `json:"user_id" validate:"required"`
+	ChatID    string    `json:"chat_id" validate:"required"`
+	ThreadID  int       `json:"thread_id,omitempty"`
+	Text      string    `json:"text" validate:"required"`
+	SendAt    time.Time `json:"send_at" validate:"required"`
+}
+
+// ScheduledMessageDue is published when a scheduled message is to be delivered
+type ScheduledMessageDue struct {
+	Event
+	MessageID string `json:"message_id" validate:"required"`
+	UserID    string `json:"user_id" validate:"required"`
+	ChatID    string `json:"chat_id" validate:"required"`
+	ThreadID  int    `json:"thread_id,omitempty"`
+	Text      string `json:"text" validate:"required"`
+}
+
+// CountdownMaxLead is how close a task's due date must be for a countdown to be offered
+const CountdownMaxLead = 12 * time.Hour
+
+// Countdown states
+const (
+	CountdownRunning  = "running"
+	CountdownDone     = "done"     // the task was completed or deleted in time
+	CountdownExpired  = "expired"  // the due time came
+	CountdownRejected = "rejected" // the countdown could not be started; Message says why
+)
+
+// CountdownRequested is published when the user starts a countdown for a
+// task. MessageID is the message the countdown is shown in.
+type CountdownRequested struct {
+	Event
+	UserID    string `json:"user_id" validate:"required"`
+	ChatID    string `json:"chat_id" validate:"required"`
+	TaskID    string `json:"task_id" validate:"required"`
+	MessageID int    `json:"message_id" validate:"required"`
+}
+
+// CountdownUpdated is published to refresh a countdown message. Remaining
+// is the time left while the countdown is running.
+type CountdownUpdated struct {
+	Event
+	UserID    string        `json:"user_id" validate:"required"`
+	ChatID    string        `json:"chat_id" validate:"required"`
+	TaskID    string        `json:"task_id" validate:"required"`
+	MessageID int           `json:"message_id" validate:"required"`
+	Title     string        `json:"title,omitempty"`
+	State     string        `json:"state" validate:"required"`
+	Remaining time.Duration `json:"remaining,omitempty"`
+	Message   string        `json:"message,omitempty"`
+}
+
+// TextGenerationRequested asks the LLM for free text, such as a plan, to be
+// streamed into the chat message MessageID. Purpose tells the requester what
+// the text is for once it is done.
+type TextGenerationRequested struct {
+	Event
+	UserID    string `json:"user_id" validate:"required"`
+	ChatID    string `json:"chat_id" validate:"required"`
+	MessageID int    `json:"message_id" validate:"required"`
+	Purpose   string `json:"purpose" validate:"required"`
+	Prompt    string `json:"prompt" validate:"required"`
+}
+
+// TextGenerated carries the text generated so far for a request. The last
+// event has Done set, with the full text on success or why it failed in
+// Message.
+type TextGenerated struct {
+	Event
+	UserID    string `json:"user_id" validate:"required"`
+	ChatID    string `json:"chat_id" validate:"required"`
+	MessageID int    `json:"message_id" validate:"required"`
+	Purpose   string `json:"purpose" validate:"required"`
+	Text      string `json:"text,omitempty"`
+	Done      bool   `json:"done"`
+	Success   bool   `json:"success"`
+	Message   string `json:"message,omitempty"`
+}
+
+// TextPurposePlan is the Purpose of the text written for /plan
+const TextPurposePlan = "plan"
+
+// PlanRequested asks for a schedule of the user's tasks for the rest of the
+// day, streamed into the message MessageID. PlanID is set to write a new
+// schedule for an existing plan instead.
+type PlanRequested struct {
+	Event
+	UserID    string `json:"user_id" validate:"required"`
+	ChatID    string `json:"chat_id" validate:"required"`
+	MessageID int    `json:"message_id,omitempty"`
+	PlanID    string `json:"plan_id,omitempty"`
+}
+
+// PlanAcceptRequested is published when the user accepts a plan, setting a
+// reminder for each planned task
+type PlanAcceptRequested struct {
+	Event
+	UserID string `json:"user_id" validate:"required"`
+	ChatID string `json:"chat_id" validate:"required"`
+	PlanID string `json:"plan_id" validate:"required"`
+}
+
+// PlanBlock is one time block of a plan; TaskID is empty for breaks
+type PlanBlock struct {
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	TaskID string    `json:"task_id,omitempty"`
+	Title  string    `json:"title"`
+}
+
+// Plan states
+const (
+	PlanReady    = "ready"    // the schedule is written and waits to be accepted
+	PlanAccepted = "accepted" // reminders were set for the planned tasks
+	PlanFailed   = "failed"   // no schedule; Message says why
+)
+
+// PlanUpdated is published to show a plan in its message. Reminders counts
+// the reminders set once the plan is accepted.
+type PlanUpdated struct {
+	Event
+	UserID    string      `json:"user_id" validate:"required"`
+	ChatID    string      `json:"chat_id" validate:"required"`
+	MessageID int         `json:"message_id" validate:"required"`
+	PlanID    string      `json:"plan_id,omitempty"`
+	State     string      `json:"state" validate:"required"`
+	Blocks    []PlanBlock `json:"blocks,omitempty"`
+	Reminders int         `json:"reminders,omitempty"`
+	Message   string      `json:"message,omitempty"`
+}
+
+// UserRegistered is published when a Telegram user contacts a bot for the first time
+type UserRegistered struct {
+	Event
+	UserID     string `json:"user_id" validate:"required"`
+	TelegramID int64  `json:"telegram_id" validate:"required"`
+	Bot        string `json:"bot,omitempty"` // empty for the default bot
+	Username   string `json:"username,omitempty"`
+	FirstName  string `json:"first_name,omitempty"`
+	LastName   string `json:"last_name,omitempty"`
+}
+
+// TelegramAccount identifies a Telegram user as seen by one bot
+type TelegramAccount struct {
+	Bot        string `json:"bot,omitempty"` // empty for the default bot
+	TelegramID int64  `json:"telegram_id"`
+}
+
+// AccountMerged is published when one user's data is moved to another user.
+// Accounts now resolve to IntoUserID.
+type AccountMerged struct {
+	Event
+	MergeID    string            `json:"merge_id" validate:"required"`
+	FromUserID string            `json:"from_user_id" validate:"required"`
+	IntoUserID string            `json:"into_user_id" validate:"required"`
+	Accounts   []TelegramAccount `json:"accounts,omitempty"`
+}
+
+// AccountMergeUndone is published when a merge is reverted. Accounts resolve
+// to FromUserID again.
+type AccountMergeUndone struct {
+	Event
+	MergeID    string            `json:"merge_id" validate:"required"`
+	FromUserID string            `json:"from_user_id" validate:"required"`
+	IntoUserID string            `json:"into_user_id" validate:"required"`
+	Accounts   []TelegramAccount `json:"accounts,omitempty"`
+}
+
+// ChatMigrated is published when Telegram upgrades a group to a supergroup,
+// which gets a new chat ID. Tasks and reminders of FromChatID are moved to
+// ToChatID; both are internal chat IDs.
+type ChatMigrated struct {
+	Event
+	Bot                string `json:"bot,omitempty"` // empty for the default bot
+	FromChatID         string `json:"from_chat_id" validate:"required"`
+	ToChatID           string `json:"to_chat_id" validate:"required"`
+	FromTelegramChatID int64  `json:"from_telegram_chat_id"`
+	ToTelegramChatID   int64  `json:"to_telegram_chat_id"`
+}
+
+// BotBlocked is published when a user blocks the bot in their private chat
+type BotBlocked struct {
+	Event
+	UserID    string    `json:"user_id" validate:"required"`
+	ChatID    string    `json:"chat_id" validate:"required"`
+	Bot       string    `json:"bot,omitempty"` // empty for the default bot
+	BlockedAt time.Time `json:"blocked_at"`
+}
+
+// BotUnblocked is published when a user who blocked the bot unblocks it
+type BotUnblocked struct {
+	Event
+	UserID string `json:"user_id" validate:"required"`
+	ChatID string `json:"chat_id" validate:"required"`
+	Bot    string `json:"bot,omitempty"` // empty for the default bot
+}
+
+// WelcomeBack summarizes what accumulated while a user had the bot blocked,
+// sent when they unblock it. CameDue lists the open tasks that fell due while
+// they were away, earliest first, up to a few of them.
+type WelcomeBack struct {
+	Event
+	UserID       string        `json:"user_id" validate:"required"`
+	ChatID       string        `json:"chat_id" validate:"required"`
+	AwaySince    time.Time     `json:"away_since"`
+	CameDue      []TaskSummary `json:"came_due,omitempty"`
+	CameDueCount int           `json:"came_due_count"`
+	Added        int           `json:"added"` // tasks created for the user while away
+	Open         int           `json:"open"`
+	Overdue      int           `json:"overdue"`
+}
+
 // Event topics constants
 const (
 	TopicMessageReceived     = "message.received"
 	TopicTaskParsed          = "task.parsed"
+	TopicTaskParseFailed     = "task.parse.failed"
 	TopicReminderDue         = "reminder.due"
+	TopicReminderGroupDue    = "reminder.group.due"
+	TopicReminderDigestDue   = "reminder.digest.due"
 	TopicTaskCompleted       = "task.completed"
 	TopicTaskCreated         = "task.created"
+	TopicTaskStatusChanged   = "task.status.changed"
+	TopicTaskOverdue         = "task.overdue"
+	TopicHabitLogged         = "habit.logged"
+	TopicHabitMissed         = "habit.missed"
+	TopicTaskSlipped         = "task.slipped"
+	TopicTaskEdited          = "task.edited"
+	TopicTasksCreated        = "tasks.created"
 	TopicTaskListRequested   = "task.list.requested"
 	TopicTaskActionRequested = "task.action.requested"
 	TopicUserSessionStarted  = "user.session.started"
+	TopicUserRegistered      = "user.registered"
+	TopicAccountMerged       = "account.merged"
+	TopicAccountMergeUndone  = "account.merge.undone"
+	TopicChatMigrated        = "chat.migrated"
+	TopicBotBlocked          = "bot.blocked"
+	TopicBotUnblocked        = "bot.unblocked"
+	TopicWelcomeBack         = "user.welcome_back"
 	TopicCommandExecuted     = "command.executed"
 	TopicTaskListResponse    = "task.list.response"
 	TopicTaskActionResponse  = "task.action.response"
+	TopicSystemLoadChanged   = "system.load.changed"
+	TopicUpdateReceived      = "telegram.update.received"
+
+	TopicWorkspaceInviteRequested = "workspace.invite.requested"
+	TopicWorkspaceInviteResponse  = "workspace.invite.response"
+	TopicWorkspaceJoinRequested   = "workspace.join.requested"
+	TopicWorkspaceJoinResponse    = "workspace.join.response"
+
+	TopicTaskHistoryRequested = "task.history.requested"
+	TopicTaskHistoryResponse  = "task.history.response"
+
+	TopicTaskFieldUpdateRequested = "task.field.update.requested"
+
+	TopicTaskRescheduleRequested = "task.reschedule.requested"
+	TopicTaskRescheduleResponse  = "task.reschedule.response"
+
+	TopicAPITokenRequested = "api.token.requested"
+	TopicAPITokenResponse  = "api.token.response"
+
+	TopicWeeklyStatsRequested = "stats.weekly.requested"
+	TopicWeeklyStatsResponse  = "stats.weekly.response"
+
+	TopicUserActivity = "user.activity"
+
+	TopicListCreateRequested = "list.create.requested"
+	TopicListJoinRequested   = "list.join.requested"
+	TopicListTaskRequested   = "list.task.requested"
+	TopicListResponse        = "list.response"
+	TopicListsRequested      = "lists.requested"
+	TopicListsResponse       = "lists.response"
+	TopicListTaskCompleted   = "list.task.completed"
+
+	TopicTaskDelegationRequested = "task.delegation.requested"
+	TopicTaskDelegationResponse  = "task.delegation.response"
+	TopicTaskDelegated           = "task.delegated"
+	TopicTaskDelegationReplied   = "task.delegation.replied"
+	TopicTaskDelegationResolved  = "task.delegation.resolved"
+
+	TopicScheduledMessageCreated = "scheduled_message.created"
+	TopicScheduledMessageDue     = "scheduled_message.due"
+
+	TopicCountdownRequested = "countdown.requested"
+	TopicCountdownUpdated   = "countdown.updated"
+
+	TopicTextGenerationRequested = "text.generation.requested"
+	TopicTextGenerated           = "text.generated"
+
+	TopicTaskOriginalRequested = "task.original.requested"
+	TopicTaskOriginalResponse  = "task.original.response"
+
+	TopicTaskDetailsRequested = "task.details.requested"
+	TopicTaskDetailsResponse  = "task.details.response"
+
+	TopicPlanRequested       = "plan.requested"
+	TopicPlanAcceptRequested = "plan.accept.requested"
+	TopicPlanUpdated         = "plan.updated"
+
+	TopicRetentionRequested = "retention.requested"
+	TopicRetentionResponse  = "retention.response"
+
+	TopicSettingsRequested = "settings.requested"
+	TopicSettingsResponse  = "settings.response"
+
+	TopicBroadcastRequested = "broadcast.requested"
+	TopicBroadcastReady     = "broadcast.ready"
+
+	TopicGlobalStatsRequested = "global_stats.requested"
+	TopicGlobalStatsResponse  = "global_stats.response"
+
+	TopicMaintenanceRequested = "maintenance.requested"
+	TopicMaintenanceChanged   = "maintenance.changed"
 )
@@ -406,6 +406,7 @@ func TestEventTypes_TopicConstants(t *testing.T) {
 	topics := []string{
 		TopicMessageReceived,
 		TopicTaskParsed,
+		TopicTaskParseFailed,
 		TopicReminderDue,
 		TopicTaskCompleted,
 		TopicTaskCreated,
@@ -433,6 +434,7 @@ func TestEventTypes_TopicConstants(t *testing.T) {
 	expectedTopics := map[string]string{
 		TopicMessageReceived:     "message.received",
 		TopicTaskParsed:          "task.parsed",
+		TopicTaskParseFailed:     "task.parse.failed",
 		TopicReminderDue:         "reminder.due",
 		TopicTaskCompleted:       "task.completed",
 		TopicTaskCreated:         "task.created",
@@ -555,3 +557,13 @@ func TestEventTypes_TaskSummaryStructure(t *testing.T) {
 func timePtr(t time.Time) *time.Time {
 	return &t
 }
+
+func TestReminderDue_SendsTo(t *testing.T) {
+	reminder := ReminderDue{}
+	assert.True(t, reminder.SendsTo(ReminderChannelTelegram), "unrouted reminders go to Telegram")
+	assert.False(t, reminder.SendsTo(ReminderChannelEmail))
+
+	reminder.Channels = []string{ReminderChannelDigest}
+	assert.False(t, reminder.SendsTo(ReminderChannelTelegram))
+	assert.True(t, reminder.SendsTo(ReminderChannelDigest))
+}
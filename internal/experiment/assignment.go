@@ -0,0 +1,39 @@
+package experiment
+
+import (
+	"hash/fnv"
+
+	"nudgebot-api/internal/common"
+)
+
+// AssignVariant deterministically buckets a user into one of the experiment's variants.
+// The same user always lands in the same variant as long as the variant weights do not change.
+func AssignVariant(exp Experiment, userID common.UserID) (Variant, bool) {
+	total := exp.TotalWeight()
+	if total == 0 {
+		return Variant{}, false
+	}
+
+	bucket := int(bucketHash(exp.Name, userID) % uint32(total))
+	for _, v := range exp.Variants {
+		if v.Weight <= 0 {
+			continue
+		}
+		if bucket < v.Weight {
+			return v, true
+		}
+		bucket -= v.Weight
+	}
+
+	return Variant{}, false
+}
+
+// bucketHash hashes the experiment name together with the user ID so that
+// assignments are independent across experiments
+func bucketHash(experimentName string, userID common.UserID) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(experimentName))
+	_, _ = h.Write([]byte{':'})
+	_, _ = h.Write([]byte(userID))
+	return h.Sum32()
+}
@@ -0,0 +1,98 @@
+package experiment
+
+import (
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/config"
+)
+
+// Experiment describes a set of variants users are bucketed into
+type Experiment struct {
+	Name     string    `json:"name"`
+	Active   bool      `json:"active"`
+	Variants []Variant `json:"variants"`
+}
+
+// Variant is one arm of an experiment with its reminder wording and timing
+type Variant struct {
+	Key              string        `json:"key"`
+	Weight           int           `json:"weight"`
+	ReminderTemplate string        `json:"reminder_template,omitempty"`
+	NudgeDelay       time.Duration `json:"nudge_delay,omitempty"`
+}
+
+// ReminderVariant is the variant selected for an outgoing reminder
+type ReminderVariant struct {
+	Experiment       string
+	Variant          string
+	ReminderTemplate string
+	NudgeDelay       time.Duration
+}
+
+// Exposure records that a user saw a variant for a given task
+type Exposure struct {
+	ID          common.ID     `gorm:"type:uuid;primaryKey" json:"id"`
+	Experiment  string        `gorm:"not null;index:idx_exposures_experiment_variant" json:"experiment"`
+	Variant     string        `gorm:"not null;index:idx_exposures_experiment_variant" json:"variant"`
+	UserID      common.UserID `gorm:"type:uuid;not null;index" json:"user_id"`
+	TaskID      common.TaskID `gorm:"type:uuid;not null;index" json:"task_id"`
+	ExposedAt   time.Time     `gorm:"not null" json:"exposed_at"`
+	CompletedAt *time.Time    `json:"completed_at,omitempty"`
+}
+
+// TableName returns the table name for the Exposure model
+func (Exposure) TableName() string {
+	return "experiment_exposures"
+}
+
+// VariantReport summarizes how a variant performed
+type VariantReport struct {
+	Variant        string        `json:"variant"`
+	Exposures      int           `json:"exposures"`
+	Users          int           `json:"users"`
+	Conversions    int           `json:"conversions"`
+	ConversionRate float64       `json:"conversion_rate"`
+	AvgTimeToDone  time.Duration `json:"avg_time_to_done"`
+}
+
+// Report summarizes an experiment's variants
+type Report struct {
+	Experiment       string          `json:"experiment"`
+	ConversionWindow time.Duration   `json:"conversion_window"`
+	Variants         []VariantReport `json:"variants"`
+	GeneratedAt      time.Time       `json:"generated_at"`
+}
+
+// TotalWeight returns the sum of positive variant weights
+func (e Experiment) TotalWeight() int {
+	total := 0
+	for _, v := range e.Variants {
+		if v.Weight > 0 {
+			total += v.Weight
+		}
+	}
+	return total
+}
+
+// FromConfig converts experiment definitions from configuration
+func FromConfig(definitions []config.ExperimentDefinition) []Experiment {
+	experiments := make([]Experiment, 0, len(definitions))
+	for _, def := range definitions {
+		exp := Experiment{
+			Name:     def.Name,
+			Active:   def.Active,
+			Variants: make([]Variant, 0, len(def.Variants)),
+		}
+		for _, v := range def.Variants {
+			exp.Variants = append(exp.Variants, Variant{
+				Key:              v.Key,
+				Weight:           v.Weight,
+				ReminderTemplate: v.ReminderTemplate,
+				NudgeDelay:       time.Duration(v.NudgeDelay) * time.Second,
+			})
+		}
+		experiments = append(experiments, exp)
+	}
+	return experiments
+}
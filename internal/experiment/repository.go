@@ -0,0 +1,80 @@
+package experiment
+
+import (
+	"fmt"
+	"time"
+
+	"nudgebot-api/internal/common"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ExposureRepository persists experiment exposures and their outcomes
+type ExposureRepository interface {
+	RecordExposure(exposure *Exposure) error
+	MarkTaskCompleted(taskID common.TaskID, completedAt time.Time) error
+	GetExposures(experiment string) ([]*Exposure, error)
+}
+
+// gormExposureRepository implements ExposureRepository using GORM
+type gormExposureRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewGormExposureRepository creates a new GORM-based exposure repository
+func NewGormExposureRepository(db *gorm.DB, logger *zap.Logger) ExposureRepository {
+	return &gormExposureRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// RecordExposure stores a new exposure
+func (r *gormExposureRepository) RecordExposure(exposure *Exposure) error {
+	if exposure.ID == "" {
+		exposure.ID = common.NewID()
+	}
+
+	if err := r.db.Create(exposure).Error; err != nil {
+		return fmt.Errorf("failed to record exposure: %w", err)
+	}
+
+	r.logger.Debug("Experiment exposure recorded",
+		zap.String("experiment", exposure.Experiment),
+		zap.String("variant", exposure.Variant),
+		zap.String("taskID", string(exposure.TaskID)))
+	return nil
+}
+
+// MarkTaskCompleted sets the completion time on every open exposure for the task
+func (r *gormExposureRepository) MarkTaskCompleted(taskID common.TaskID, completedAt time.Time) error {
+	err := r.db.Model(&Exposure{}).
+		Where("task_id = ? AND completed_at IS NULL", taskID).
+		Update("completed_at", completedAt).Error
+	if err != nil {
+		return fmt.Errorf("failed to mark exposures completed: %w", err)
+	}
+	return nil
+}
+
+// GetExposures returns all exposures recorded for an experiment
+func (r *gormExposureRepository) GetExposures(experiment string) ([]*Exposure, error) {
+	var exposures []*Exposure
+	err := r.db.Where("experiment = ?", experiment).
+		Order("exposed_at ASC").
+		Find(&exposures).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exposures: %w", err)
+	}
+	return exposures, nil
+}
+
+// RunMigrations creates the experiment tables
+func RunMigrations(db *gorm.DB) error {
+	if err := db.AutoMigrate(&Exposure{}); err != nil {
+		return fmt.Errorf("failed to auto-migrate experiment tables: %w", err)
+	}
+	return nil
+}
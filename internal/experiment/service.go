@@ -0,0 +1,242 @@
+package experiment
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/config"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// Experiment service errors
+var (
+	ErrExperimentNotFound = errors.New("experiment not found")
+	ErrNoVariants         = errors.New("experiment has no weighted variants")
+)
+
+// ExperimentService defines the interface for experiment operations
+type ExperimentService interface {
+	ListExperiments() []Experiment
+	AssignVariant(experimentName string, userID common.UserID) (*Variant, error)
+	SelectReminderVariant(userID common.UserID) (*ReminderVariant, bool)
+	GetReport(experimentName string) (*Report, error)
+	Ready() <-chan struct{}
+}
+
+// experimentService implements the ExperimentService interface
+type experimentService struct {
+	eventBus         events.EventBus
+	logger           *zap.Logger
+	repository       ExposureRepository
+	experiments      []Experiment
+	conversionWindow time.Duration
+	ready            *common.Readiness
+}
+
+// NewExperimentService creates a new instance of ExperimentService
+func NewExperimentService(eventBus events.EventBus, logger *zap.Logger, repository ExposureRepository, cfg config.ExperimentsConfig) ExperimentService {
+	window := time.Duration(cfg.ConversionWindow) * time.Hour
+	if window <= 0 {
+		window = 24 * time.Hour
+	}
+
+	service := &experimentService{
+		eventBus:         eventBus,
+		logger:           logger,
+		repository:       repository,
+		experiments:      FromConfig(cfg.Definitions),
+		conversionWindow: window,
+		ready:            common.NewReadiness(),
+	}
+
+	service.setupEventSubscriptions()
+
+	return service
+}
+
+// setupEventSubscriptions sets up event subscriptions for the experiment service
+func (s *experimentService) setupEventSubscriptions() {
+	if err := s.eventBus.Subscribe(events.TopicReminderDue, s.handleReminderDue); err != nil {
+		s.logger.Error("Failed to subscribe to ReminderDue events", zap.Error(err))
+	}
+
+	if err := s.eventBus.Subscribe(events.TopicTaskCompleted, s.handleTaskCompleted); err != nil {
+		s.logger.Error("Failed to subscribe to TaskCompleted events", zap.Error(err))
+	}
+
+	s.ready.MarkReady()
+}
+
+// Ready returns a channel that is closed once event subscriptions are registered
+func (s *experimentService) Ready() <-chan struct{} {
+	return s.ready.Ready()
+}
+
+// ListExperiments returns all configured experiments
+func (s *experimentService) ListExperiments() []Experiment {
+	return s.experiments
+}
+
+// AssignVariant returns the variant the user is bucketed into for an experiment
+func (s *experimentService) AssignVariant(experimentName string, userID common.UserID) (*Variant, error) {
+	exp, ok := s.findExperiment(experimentName)
+	if !ok {
+		return nil, ErrExperimentNotFound
+	}
+
+	variant, ok := AssignVariant(exp, userID)
+	if !ok {
+		return nil, ErrNoVariants
+	}
+
+	return &variant, nil
+}
+
+// SelectReminderVariant picks the reminder variant from the first active experiment
+func (s *experimentService) SelectReminderVariant(userID common.UserID) (*ReminderVariant, bool) {
+	for _, exp := range s.experiments {
+		if !exp.Active {
+			continue
+		}
+
+		variant, ok := AssignVariant(exp, userID)
+		if !ok {
+			continue
+		}
+
+		return &ReminderVariant{
+			Experiment:       exp.Name,
+			Variant:          variant.Key,
+			ReminderTemplate: variant.ReminderTemplate,
+			NudgeDelay:       variant.NudgeDelay,
+		}, true
+	}
+
+	return nil, false
+}
+
+// GetReport aggregates exposures into per-variant performance
+func (s *experimentService) GetReport(experimentName string) (*Report, error) {
+	exp, ok := s.findExperiment(experimentName)
+	if !ok {
+		return nil, ErrExperimentNotFound
+	}
+
+	exposures, err := s.repository.GetExposures(experimentName)
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildReport(exp, exposures, s.conversionWindow), nil
+}
+
+// BuildReport computes variant statistics from a set of exposures.
+// A conversion is an exposure whose task was completed within the conversion window.
+func BuildReport(exp Experiment, exposures []*Exposure, window time.Duration) *Report {
+	type accumulator struct {
+		exposures   int
+		users       map[common.UserID]struct{}
+		conversions int
+		totalToDone time.Duration
+	}
+
+	byVariant := make(map[string]*accumulator)
+	for _, v := range exp.Variants {
+		byVariant[v.Key] = &accumulator{users: make(map[common.UserID]struct{})}
+	}
+
+	for _, exposure := range exposures {
+		acc, ok := byVariant[exposure.Variant]
+		if !ok {
+			acc = &accumulator{users: make(map[common.UserID]struct{})}
+			byVariant[exposure.Variant] = acc
+		}
+
+		acc.exposures++
+		acc.users[exposure.UserID] = struct{}{}
+
+		if exposure.CompletedAt != nil {
+			elapsed := exposure.CompletedAt.Sub(exposure.ExposedAt)
+			if elapsed >= 0 && elapsed <= window {
+				acc.conversions++
+				acc.totalToDone += elapsed
+			}
+		}
+	}
+
+	report := &Report{
+		Experiment:       exp.Name,
+		ConversionWindow: window,
+		Variants:         make([]VariantReport, 0, len(byVariant)),
+		GeneratedAt:      time.Now(),
+	}
+
+	for key, acc := range byVariant {
+		variantReport := VariantReport{
+			Variant:     key,
+			Exposures:   acc.exposures,
+			Users:       len(acc.users),
+			Conversions: acc.conversions,
+		}
+		if acc.exposures > 0 {
+			variantReport.ConversionRate = float64(acc.conversions) / float64(acc.exposures)
+		}
+		if acc.conversions > 0 {
+			variantReport.AvgTimeToDone = acc.totalToDone / time.Duration(acc.conversions)
+		}
+		report.Variants = append(report.Variants, variantReport)
+	}
+
+	sort.Slice(report.Variants, func(i, j int) bool {
+		return report.Variants[i].Variant < report.Variants[j].Variant
+	})
+
+	return report
+}
+
+// findExperiment looks up an experiment by name
+func (s *experimentService) findExperiment(name string) (Experiment, bool) {
+	for _, exp := range s.experiments {
+		if exp.Name == name {
+			return exp, true
+		}
+	}
+	return Experiment{}, false
+}
+
+// handleReminderDue records an exposure for reminders sent under an experiment
+func (s *experimentService) handleReminderDue(event events.ReminderDue) {
+	if event.Experiment == "" || event.Variant == "" {
+		return
+	}
+
+	exposure := &Exposure{
+		ID:         common.NewID(),
+		Experiment: event.Experiment,
+		Variant:    event.Variant,
+		UserID:     common.UserID(event.UserID),
+		TaskID:     common.TaskID(event.TaskID),
+		ExposedAt:  event.Timestamp,
+	}
+
+	if err := s.repository.RecordExposure(exposure); err != nil {
+		s.logger.Error("Failed to record experiment exposure",
+			zap.String("correlationID", event.CorrelationID),
+			zap.String("experiment", event.Experiment),
+			zap.Error(err))
+	}
+}
+
+// handleTaskCompleted records the outcome for exposures of the completed task
+func (s *experimentService) handleTaskCompleted(event events.TaskCompleted) {
+	if err := s.repository.MarkTaskCompleted(common.TaskID(event.TaskID), event.CompletedAt); err != nil {
+		s.logger.Error("Failed to record experiment outcome",
+			zap.String("correlationID", event.CorrelationID),
+			zap.String("taskID", event.TaskID),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,86 @@
+package experiment
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testExperiment() Experiment {
+	return Experiment{
+		Name:   "reminder_copy",
+		Active: true,
+		Variants: []Variant{
+			{Key: "control", Weight: 50},
+			{Key: "friendly", Weight: 50, ReminderTemplate: "Hey! {task_id}"},
+		},
+	}
+}
+
+func TestAssignVariant(t *testing.T) {
+	exp := testExperiment()
+
+	t.Run("assignment is deterministic", func(t *testing.T) {
+		userID := common.UserID(common.NewID())
+		first, ok := AssignVariant(exp, userID)
+		require.True(t, ok)
+
+		for i := 0; i < 10; i++ {
+			again, ok := AssignVariant(exp, userID)
+			require.True(t, ok)
+			assert.Equal(t, first.Key, again.Key)
+		}
+	})
+
+	t.Run("users are spread across variants", func(t *testing.T) {
+		counts := make(map[string]int)
+		for i := 0; i < 1000; i++ {
+			variant, ok := AssignVariant(exp, common.UserID(fmt.Sprintf("user-%d", i)))
+			require.True(t, ok)
+			counts[variant.Key]++
+		}
+
+		assert.InDelta(t, 500, counts["control"], 100)
+		assert.InDelta(t, 500, counts["friendly"], 100)
+	})
+
+	t.Run("experiment without weights assigns nothing", func(t *testing.T) {
+		_, ok := AssignVariant(Experiment{Name: "empty", Variants: []Variant{{Key: "a"}}}, "user")
+		assert.False(t, ok)
+	})
+}
+
+func TestBuildReport(t *testing.T) {
+	exp := testExperiment()
+	exposedAt := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	within := exposedAt.Add(2 * time.Hour)
+	late := exposedAt.Add(48 * time.Hour)
+
+	exposures := []*Exposure{
+		{Experiment: exp.Name, Variant: "control", UserID: "u1", TaskID: "t1", ExposedAt: exposedAt, CompletedAt: &within},
+		{Experiment: exp.Name, Variant: "control", UserID: "u1", TaskID: "t2", ExposedAt: exposedAt},
+		{Experiment: exp.Name, Variant: "friendly", UserID: "u2", TaskID: "t3", ExposedAt: exposedAt, CompletedAt: &late},
+	}
+
+	report := BuildReport(exp, exposures, 24*time.Hour)
+
+	require.Len(t, report.Variants, 2)
+
+	control := report.Variants[0]
+	assert.Equal(t, "control", control.Variant)
+	assert.Equal(t, 2, control.Exposures)
+	assert.Equal(t, 1, control.Users)
+	assert.Equal(t, 1, control.Conversions)
+	assert.InDelta(t, 0.5, control.ConversionRate, 0.001)
+	assert.Equal(t, 2*time.Hour, control.AvgTimeToDone)
+
+	friendly := report.Variants[1]
+	assert.Equal(t, "friendly", friendly.Variant)
+	assert.Equal(t, 1, friendly.Exposures)
+	assert.Equal(t, 0, friendly.Conversions, "completion outside the window is not a conversion")
+}
@@ -0,0 +1,126 @@
+package featureflags
+
+import (
+	"hash/fnv"
+	"sort"
+	"strings"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/config"
+)
+
+// Well-known flags gating risky features
+const (
+	FlagLLMProviderSwitch = "llm_provider_switch"
+	FlagNewDigest         = "new_digest"
+	FlagGroupMode         = "group_mode"
+)
+
+// Flag is a feature flag resolved for the running environment
+type Flag struct {
+	Name string `json:"name"`
+	// Enabled is the flag's state in this environment before cohorts and overrides
+	Enabled           bool     `json:"enabled"`
+	RolloutPercentage int      `json:"rollout_percentage"`
+	Users             []string `json:"users,omitempty"`
+}
+
+// Override forces a flag on or off, either for everyone (empty UserID) or for
+// a single user
+type Override struct {
+	ID        common.ID     `gorm:"type:uuid;primaryKey" json:"id"`
+	Flag      string        `gorm:"not null;uniqueIndex:idx_feature_flag_overrides_flag_user" json:"flag"`
+	UserID    common.UserID `gorm:"not null;default:'';uniqueIndex:idx_feature_flag_overrides_flag_user" json:"user_id,omitempty"`
+	Enabled   bool          `gorm:"not null" json:"enabled"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// TableName returns the table name for the Override model
+func (Override) TableName() string {
+	return "feature_flag_overrides"
+}
+
+// FlagState is a flag together with the overrides currently applied to it
+type FlagState struct {
+	Flag
+	Overrides []Override `json:"overrides,omitempty"`
+}
+
+// FromConfig resolves configured flags for an environment
+func FromConfig(flags map[string]config.FeatureFlagConfig, environment string) map[string]Flag {
+	environment = strings.ToLower(environment)
+	resolved := make(map[string]Flag, len(flags))
+
+	for name, cfg := range flags {
+		name = strings.ToLower(name)
+		enabled := cfg.Enabled
+		for env, envEnabled := range cfg.Environments {
+			if strings.ToLower(env) == environment {
+				enabled = envEnabled
+			}
+		}
+
+		rollout := 100
+		if cfg.RolloutPercentage != nil {
+			rollout = clampPercentage(*cfg.RolloutPercentage)
+		}
+
+		users := append([]string(nil), cfg.Users...)
+		sort.Strings(users)
+
+		resolved[name] = Flag{
+			Name:              name,
+			Enabled:           enabled,
+			RolloutPercentage: rollout,
+			Users:             users,
+		}
+	}
+
+	return resolved
+}
+
+// Evaluate reports whether the flag is on for a user, ignoring overrides.
+// Listed users always get the flag; everyone else needs the flag enabled in
+// this environment and to fall inside the rollout cohort.
+func (f Flag) Evaluate(userID common.UserID) bool {
+	if userID != "" {
+		for _, user := range f.Users {
+			if user == string(userID) {
+				return true
+			}
+		}
+	}
+
+	if !f.Enabled {
+		return false
+	}
+	if f.RolloutPercentage >= 100 {
+		return true
+	}
+	if f.RolloutPercentage <= 0 || userID == "" {
+		return false
+	}
+
+	return int(cohortHash(f.Name, userID)%100) < f.RolloutPercentage
+}
+
+// cohortHash hashes the flag name together with the user ID so that rollout
+// cohorts are independent across flags and stable as the percentage grows
+func cohortHash(flagName string, userID common.UserID) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(flagName))
+	_, _ = h.Write([]byte{':'})
+	_, _ = h.Write([]byte(userID))
+	return h.Sum32()
+}
+
+func clampPercentage(percentage int) int {
+	if percentage < 0 {
+		return 0
+	}
+	if percentage > 100 {
+		return 100
+	}
+	return percentage
+}
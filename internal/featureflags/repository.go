@@ -0,0 +1,81 @@
+package featureflags
+
+import (
+	"fmt"
+	"time"
+
+	"nudgebot-api/internal/common"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OverrideRepository persists feature flag overrides
+type OverrideRepository interface {
+	ListOverrides() ([]Override, error)
+	SaveOverride(override *Override) error
+	DeleteOverride(flag string, userID common.UserID) error
+}
+
+// gormOverrideRepository implements OverrideRepository using GORM
+type gormOverrideRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewGormOverrideRepository creates a new GORM-based override repository
+func NewGormOverrideRepository(db *gorm.DB, logger *zap.Logger) OverrideRepository {
+	return &gormOverrideRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// ListOverrides returns every stored override
+func (r *gormOverrideRepository) ListOverrides() ([]Override, error) {
+	var overrides []Override
+	if err := r.db.Order("flag ASC, user_id ASC").Find(&overrides).Error; err != nil {
+		return nil, fmt.Errorf("failed to list feature flag overrides: %w", err)
+	}
+	return overrides, nil
+}
+
+// SaveOverride creates the override or replaces the existing one for the same flag and user
+func (r *gormOverrideRepository) SaveOverride(override *Override) error {
+	if override.ID == "" {
+		override.ID = common.NewID()
+	}
+	override.UpdatedAt = time.Now()
+
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "flag"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+	}).Create(override).Error
+	if err != nil {
+		return fmt.Errorf("failed to save feature flag override: %w", err)
+	}
+
+	r.logger.Debug("Feature flag override saved",
+		zap.String("flag", override.Flag),
+		zap.String("userID", string(override.UserID)),
+		zap.Bool("enabled", override.Enabled))
+	return nil
+}
+
+// DeleteOverride removes the override for a flag and user
+func (r *gormOverrideRepository) DeleteOverride(flag string, userID common.UserID) error {
+	err := r.db.Where("flag = ? AND user_id = ?", flag, userID).Delete(&Override{}).Error
+	if err != nil {
+		return fmt.Errorf("failed to delete feature flag override: %w", err)
+	}
+	return nil
+}
+
+// RunMigrations creates the feature flag tables
+func RunMigrations(db *gorm.DB) error {
+	if err := db.AutoMigrate(&Override{}); err != nil {
+		return fmt.Errorf("failed to auto-migrate feature flag tables: %w", err)
+	}
+	return nil
+}
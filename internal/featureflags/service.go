@@ -0,0 +1,202 @@
+package featureflags
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/config"
+
+	"go.uber.org/zap"
+)
+
+// Feature flag service errors
+var (
+	ErrFlagNotFound      = errors.New("feature flag not found")
+	ErrOverridesDisabled = errors.New("feature flag overrides are not persisted")
+)
+
+// Checker answers whether a feature is on. Services that only gate behaviour
+// depend on this rather than the full FlagService.
+type Checker interface {
+	IsEnabled(flag string, userID common.UserID) bool
+}
+
+// CheckerFunc adapts a function to the Checker interface
+type CheckerFunc func(flag string, userID common.UserID) bool
+
+// IsEnabled calls f(flag, userID)
+func (f CheckerFunc) IsEnabled(flag string, userID common.UserID) bool {
+	return f(flag, userID)
+}
+
+// FlagService defines the interface for feature flag operations
+type FlagService interface {
+	Checker
+	ListFlags() []FlagState
+	SetOverride(flag string, userID common.UserID, enabled bool) error
+	ClearOverride(flag string, userID common.UserID) error
+	Refresh(ctx context.Context) error
+}
+
+// overrideKey identifies an override; an empty user applies to everyone
+type overrideKey struct {
+	flag   string
+	userID common.UserID
+}
+
+// flagService implements the FlagService interface
+type flagService struct {
+	logger      *zap.Logger
+	repository  OverrideRepository
+	environment string
+	flags       map[string]Flag
+
+	mu        sync.RWMutex
+	overrides map[overrideKey]Override
+}
+
+// NewFlagService creates a new instance of FlagService. The repository is
+// optional; without it flags come from configuration only.
+func NewFlagService(logger *zap.Logger, repository OverrideRepository, cfg config.FeatureFlagsConfig, environment string) FlagService {
+	service := &flagService{
+		logger:      logger,
+		repository:  repository,
+		environment: environment,
+		flags:       FromConfig(cfg.Flags, environment),
+		overrides:   make(map[overrideKey]Override),
+	}
+
+	if err := service.Refresh(context.Background()); err != nil {
+		logger.Warn("Failed to load feature flag overrides", zap.Error(err))
+	}
+
+	return service
+}
+
+// IsEnabled reports whether a flag is on for a user. A user override wins over
+// a global override, which wins over configuration. Unknown flags are off.
+func (s *flagService) IsEnabled(flag string, userID common.UserID) bool {
+	flag = strings.ToLower(flag)
+
+	s.mu.RLock()
+	if userID != "" {
+		if override, exists := s.overrides[overrideKey{flag: flag, userID: userID}]; exists {
+			s.mu.RUnlock()
+			return override.Enabled
+		}
+	}
+	global, hasGlobal := s.overrides[overrideKey{flag: flag}]
+	s.mu.RUnlock()
+
+	if hasGlobal {
+		return global.Enabled
+	}
+
+	definition, exists := s.flags[flag]
+	if !exists {
+		return false
+	}
+	return definition.Evaluate(userID)
+}
+
+// ListFlags returns all configured flags with their overrides, sorted by name
+func (s *flagService) ListFlags() []FlagState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	states := make([]FlagState, 0, len(s.flags))
+	for _, definition := range s.flags {
+		state := FlagState{Flag: definition}
+		for key, override := range s.overrides {
+			if key.flag == definition.Name {
+				state.Overrides = append(state.Overrides, override)
+			}
+		}
+		sort.Slice(state.Overrides, func(i, j int) bool {
+			return state.Overrides[i].UserID < state.Overrides[j].UserID
+		})
+		states = append(states, state)
+	}
+
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Name < states[j].Name
+	})
+	return states
+}
+
+// SetOverride forces a configured flag on or off for a user, or for everyone
+// when userID is empty
+func (s *flagService) SetOverride(flag string, userID common.UserID, enabled bool) error {
+	if s.repository == nil {
+		return ErrOverridesDisabled
+	}
+
+	flag = strings.ToLower(flag)
+	if _, exists := s.flags[flag]; !exists {
+		return ErrFlagNotFound
+	}
+
+	override := &Override{Flag: flag, UserID: userID, Enabled: enabled}
+	if err := s.repository.SaveOverride(override); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.overrides[overrideKey{flag: flag, userID: userID}] = *override
+	s.mu.Unlock()
+
+	s.logger.Info("Feature flag override set",
+		zap.String("flag", flag),
+		zap.String("userID", string(userID)),
+		zap.Bool("enabled", enabled))
+	return nil
+}
+
+// ClearOverride removes an override so the flag falls back to configuration
+func (s *flagService) ClearOverride(flag string, userID common.UserID) error {
+	if s.repository == nil {
+		return ErrOverridesDisabled
+	}
+
+	flag = strings.ToLower(flag)
+	if err := s.repository.DeleteOverride(flag, userID); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	delete(s.overrides, overrideKey{flag: flag, userID: userID})
+	s.mu.Unlock()
+
+	s.logger.Info("Feature flag override cleared",
+		zap.String("flag", flag),
+		zap.String("userID", string(userID)))
+	return nil
+}
+
+// Refresh reloads overrides from the repository so changes made by other
+// instances take effect
+func (s *flagService) Refresh(ctx context.Context) error {
+	if s.repository == nil {
+		return nil
+	}
+
+	stored, err := s.repository.ListOverrides()
+	if err != nil {
+		return err
+	}
+
+	overrides := make(map[overrideKey]Override, len(stored))
+	for _, override := range stored {
+		overrides[overrideKey{flag: strings.ToLower(override.Flag), userID: override.UserID}] = override
+	}
+
+	s.mu.Lock()
+	s.overrides = overrides
+	s.mu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package featureflags
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// memoryOverrideRepository keeps overrides in memory for tests
+type memoryOverrideRepository struct {
+	overrides map[overrideKey]Override
+}
+
+func newMemoryOverrideRepository() *memoryOverrideRepository {
+	return &memoryOverrideRepository{overrides: make(map[overrideKey]Override)}
+}
+
+func (r *memoryOverrideRepository) ListOverrides() ([]Override, error) {
+	overrides := make([]Override, 0, len(r.overrides))
+	for _, override := range r.overrides {
+		overrides = append(overrides, override)
+	}
+	return overrides, nil
+}
+
+func (r *memoryOverrideRepository) SaveOverride(override *Override) error {
+	r.overrides[overrideKey{flag: override.Flag, userID: override.UserID}] = *override
+	return nil
+}
+
+func (r *memoryOverrideRepository) DeleteOverride(flag string, userID common.UserID) error {
+	delete(r.overrides, overrideKey{flag: flag, userID: userID})
+	return nil
+}
+
+func percentage(p int) *int {
+	return &p
+}
+
+func testFlagsConfig() config.FeatureFlagsConfig {
+	return config.FeatureFlagsConfig{
+		Flags: map[string]config.FeatureFlagConfig{
+			FlagLLMProviderSwitch: {
+				Enabled:      false,
+				Environments: map[string]bool{"development": true},
+			},
+			FlagNewDigest: {
+				Enabled:           true,
+				RolloutPercentage: percentage(30),
+				Users:             []string{"beta-tester"},
+			},
+			FlagGroupMode: {Enabled: false},
+		},
+	}
+}
+
+func TestFlagService_Environments(t *testing.T) {
+	development := NewFlagService(zaptest.NewLogger(t), nil, testFlagsConfig(), "development")
+	production := NewFlagService(zaptest.NewLogger(t), nil, testFlagsConfig(), "production")
+
+	assert.True(t, development.IsEnabled(FlagLLMProviderSwitch, "user"))
+	assert.False(t, production.IsEnabled(FlagLLMProviderSwitch, "user"))
+	assert.False(t, production.IsEnabled("unknown_flag", "user"), "unknown flags are off")
+}
+
+func TestFlagService_RolloutCohort(t *testing.T) {
+	service := NewFlagService(zaptest.NewLogger(t), nil, testFlagsConfig(), "production")
+
+	enabled := 0
+	for i := 0; i < 1000; i++ {
+		userID := common.UserID(fmt.Sprintf("user-%d", i))
+		first := service.IsEnabled(FlagNewDigest, userID)
+		assert.Equal(t, first, service.IsEnabled(FlagNewDigest, userID), "cohorts are stable")
+		if first {
+			enabled++
+		}
+	}
+
+	assert.InDelta(t, 300, enabled, 60)
+	assert.True(t, service.IsEnabled(FlagNewDigest, "beta-tester"), "listed users bypass the rollout")
+	assert.False(t, service.IsEnabled(FlagNewDigest, ""), "partial rollouts need a user")
+}
+
+func TestFlagService_Overrides(t *testing.T) {
+	repository := newMemoryOverrideRepository()
+	service := NewFlagService(zaptest.NewLogger(t), repository, testFlagsConfig(), "production")
+
+	require.NoError(t, service.SetOverride(FlagGroupMode, "", true))
+	require.NoError(t, service.SetOverride(FlagGroupMode, "opted-out", false))
+	assert.True(t, service.IsEnabled(FlagGroupMode, "someone"))
+	assert.False(t, service.IsEnabled(FlagGroupMode, "opted-out"), "user overrides win over global ones")
+
+	assert.ErrorIs(t, service.SetOverride("unknown_flag", "", true), ErrFlagNotFound)
+
+	// Overrides written by another instance show up after a refresh
+	other := NewFlagService(zaptest.NewLogger(t), repository, testFlagsConfig(), "production")
+	require.NoError(t, other.ClearOverride(FlagGroupMode, ""))
+	assert.True(t, service.IsEnabled(FlagGroupMode, "someone"))
+	require.NoError(t, service.Refresh(context.Background()))
+	assert.False(t, service.IsEnabled(FlagGroupMode, "someone"))
+
+	states := service.ListFlags()
+	require.Len(t, states, 3)
+	assert.Equal(t, FlagGroupMode, states[0].Name)
+	require.Len(t, states[0].Overrides, 1)
+	assert.Equal(t, common.UserID("opted-out"), states[0].Overrides[0].UserID)
+
+	configOnly := NewFlagService(zaptest.NewLogger(t), nil, testFlagsConfig(), "production")
+	assert.ErrorIs(t, configOnly.SetOverride(FlagGroupMode, "", true), ErrOverridesDisabled)
+}
@@ -0,0 +1,142 @@
+package geocode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"nudgebot-api/internal/httpclient"
+)
+
+// DefaultNominatimURL is OpenStreetMap's public Nominatim server
+const DefaultNominatimURL = "https://nominatim.openstreetmap.org"
+
+// nominatimInterval spaces requests to Nominatim, whose public server allows
+// one per second
+const nominatimInterval = time.Second
+
+// ErrNotFound is returned when no place matches the query
+var ErrNotFound = errors.New("place not found")
+
+// Place is where a free-text location was found
+type Place struct {
+	Address   string  `json:"address"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Geocoder finds the place a free-text location such as "the post office on
+// Main Street" names. Country is an ISO 3166 code to search in, empty to
+// search everywhere.
+type Geocoder interface {
+	Geocode(ctx context.Context, query, country string) (Place, error)
+}
+
+// nominatimResult is a match as the Nominatim search API returns it
+type nominatimResult struct {
+	DisplayName string `json:"display_name"`
+	Lat         string `json:"lat"`
+	Lon         string `json:"lon"`
+}
+
+// NominatimGeocoder searches a Nominatim server. Its usage policy asks for
+// an identifying user agent and at most one request per second.
+type NominatimGeocoder struct {
+	baseURL   string
+	userAgent string
+	client    httpclient.Doer
+
+	mu   sync.Mutex
+	next time.Time // when the next request may be sent
+}
+
+// NewNominatimGeocoder creates a geocoder searching the server at baseURL,
+// empty for DefaultNominatimURL, through client
+func NewNominatimGeocoder(baseURL, userAgent string, client httpclient.Doer) *NominatimGeocoder {
+	if baseURL == "" {
+		baseURL = DefaultNominatimURL
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &NominatimGeocoder{baseURL: strings.TrimSuffix(baseURL, "/"), userAgent: userAgent, client: client}
+}
+
+// wait blocks until a request may be sent under the rate limit
+func (g *NominatimGeocoder) wait(ctx context.Context) error {
+	g.mu.Lock()
+	now := time.Now()
+	at := g.next
+	if at.Before(now) {
+		at = now
+	}
+	g.next = at.Add(nominatimInterval)
+	g.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(at))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Geocode implements Geocoder with the best match of a search
+func (g *NominatimGeocoder) Geocode(ctx context.Context, query, country string) (Place, error) {
+	if err := g.wait(ctx); err != nil {
+		return Place{}, err
+	}
+
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("format", "jsonv2")
+	params.Set("limit", "1")
+	if country != "" {
+		params.Set("countrycodes", strings.ToLower(country))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.baseURL+"/search?"+params.Encode(), nil)
+	if err != nil {
+		return Place{}, fmt.Errorf("failed to create geocoding request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if g.userAgent != "" {
+		req.Header.Set("User-Agent", g.userAgent)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return Place{}, fmt.Errorf("failed to geocode: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Place{}, fmt.Errorf("geocoder returned %s", resp.Status)
+	}
+
+	var results []nominatimResult
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return Place{}, fmt.Errorf("failed to decode geocoding results: %w", err)
+	}
+	if len(results) == 0 {
+		return Place{}, ErrNotFound
+	}
+
+	latitude, err := strconv.ParseFloat(results[0].Lat, 64)
+	if err != nil {
+		return Place{}, fmt.Errorf("invalid latitude %q: %w", results[0].Lat, err)
+	}
+	longitude, err := strconv.ParseFloat(results[0].Lon, 64)
+	if err != nil {
+		return Place{}, fmt.Errorf("invalid longitude %q: %w", results[0].Lon, err)
+	}
+	return Place{Address: results[0].DisplayName, Latitude: latitude, Longitude: longitude}, nil
+}
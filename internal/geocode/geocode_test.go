@@ -0,0 +1,35 @@
+package geocode
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNominatimGeocoder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/search", r.URL.Path)
+		assert.Equal(t, "NudgeBot test", r.Header.Get("User-Agent"))
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("q") {
+		case "post office":
+			assert.Equal(t, "de", r.URL.Query().Get("countrycodes"))
+			w.Write([]byte(`[{"display_name": "Deutsche Post, Friedrichstraße 1, Berlin", "lat": "52.5200", "lon": "13.3880"}]`))
+		default:
+			w.Write([]byte(`[]`))
+		}
+	}))
+	defer server.Close()
+
+	geocoder := NewNominatimGeocoder(server.URL, "NudgeBot test", server.Client())
+	place, err := geocoder.Geocode(context.Background(), "post office", "DE")
+	require.NoError(t, err)
+	assert.Equal(t, Place{Address: "Deutsche Post, Friedrichstraße 1, Berlin", Latitude: 52.52, Longitude: 13.388}, place)
+
+	_, err = geocoder.Geocode(context.Background(), "nowhere at all", "")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
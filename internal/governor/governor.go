@@ -0,0 +1,247 @@
+// Package governor watches service health signals and switches the service
+// into a degraded mode when it is overloaded.
+package governor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/config"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// LatencySource reports recent database latency
+type LatencySource interface {
+	RecentLatency() time.Duration
+}
+
+// Governor decides whether the service runs normally or sheds load
+type Governor interface {
+	Degraded() bool
+	Status() Status
+	common.Service
+}
+
+// Status describes the current load mode and the signals behind it
+type Status struct {
+	Degraded    bool       `json:"degraded"`
+	Reason      string     `json:"reason,omitempty"`
+	EventDepth  int        `json:"event_depth"`
+	DBLatencyMs int64      `json:"db_latency_ms"`
+	Since       *time.Time `json:"since,omitempty"`
+	CheckedAt   *time.Time `json:"checked_at,omitempty"`
+}
+
+// governor implements the Governor interface
+type governor struct {
+	eventBus       events.EventBus
+	logger         *zap.Logger
+	depth          events.DepthReporter
+	latency        LatencySource
+	checkInterval  time.Duration
+	maxEventDepth  int
+	maxDBLatency   time.Duration
+	recoveryChecks int
+
+	mu            sync.RWMutex
+	status        Status
+	healthyChecks int
+	cancel        context.CancelFunc
+	done          chan struct{}
+}
+
+// NewGovernor creates a governor. Event depth is read from the event bus when
+// it implements events.DepthReporter; latency may be nil to ignore the database.
+func NewGovernor(eventBus events.EventBus, logger *zap.Logger, cfg config.LoadSheddingConfig, latency LatencySource) Governor {
+	g := &governor{
+		eventBus:       eventBus,
+		logger:         logger,
+		latency:        latency,
+		checkInterval:  time.Duration(cfg.CheckInterval) * time.Second,
+		maxEventDepth:  cfg.MaxEventDepth,
+		maxDBLatency:   time.Duration(cfg.MaxDBLatencyMs) * time.Millisecond,
+		recoveryChecks: cfg.RecoveryChecks,
+	}
+	if depth, ok := eventBus.(events.DepthReporter); ok {
+		g.depth = depth
+	}
+	if g.checkInterval <= 0 {
+		g.checkInterval = 5 * time.Second
+	}
+	if g.recoveryChecks <= 0 {
+		g.recoveryChecks = 1
+	}
+	if !cfg.Enabled {
+		// Thresholds of zero are never crossed
+		g.maxEventDepth = 0
+		g.maxDBLatency = 0
+	}
+
+	return g
+}
+
+// Degraded reports whether the service is shedding load
+func (g *governor) Degraded() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return g.status.Degraded
+}
+
+// Status returns the current load mode and the last observed signals
+func (g *governor) Status() Status {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return g.status
+}
+
+// Start begins checking the health signals periodically
+func (g *governor) Start(ctx context.Context) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.cancel != nil {
+		return fmt.Errorf("governor is already running")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	g.cancel = cancel
+	g.done = make(chan struct{})
+
+	go g.run(ctx, g.done)
+	return nil
+}
+
+// Stop stops the periodic checks
+func (g *governor) Stop(ctx context.Context) error {
+	g.mu.Lock()
+	cancel, done := g.cancel, g.done
+	g.cancel, g.done = nil, nil
+	g.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Health reports an error when the periodic checks are not running. Degraded
+// mode is a load signal, not a health failure.
+func (g *governor) Health() error {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	if g.cancel == nil {
+		return fmt.Errorf("governor is not running")
+	}
+	return nil
+}
+
+func (g *governor) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(g.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			g.check(now)
+		}
+	}
+}
+
+// check samples the signals and changes mode when needed. Entering degraded
+// mode is immediate; leaving it requires several healthy checks in a row.
+func (g *governor) check(now time.Time) {
+	eventDepth := 0
+	if g.depth != nil {
+		eventDepth = g.depth.Pending()
+	}
+	var dbLatency time.Duration
+	if g.latency != nil {
+		dbLatency = g.latency.RecentLatency()
+	}
+
+	var reasons []string
+	if g.maxEventDepth > 0 && eventDepth > g.maxEventDepth {
+		reasons = append(reasons, fmt.Sprintf("event depth %d above %d", eventDepth, g.maxEventDepth))
+	}
+	if g.maxDBLatency > 0 && dbLatency > g.maxDBLatency {
+		reasons = append(reasons, fmt.Sprintf("database latency %s above %s", dbLatency.Round(time.Millisecond), g.maxDBLatency))
+	}
+	overloaded := len(reasons) > 0
+
+	g.mu.Lock()
+	g.status.EventDepth = eventDepth
+	g.status.DBLatencyMs = dbLatency.Milliseconds()
+	g.status.CheckedAt = &now
+
+	changed := false
+	switch {
+	case overloaded:
+		g.healthyChecks = 0
+		g.status.Reason = strings.Join(reasons, "; ")
+		if !g.status.Degraded {
+			g.status.Degraded = true
+			g.status.Since = &now
+			changed = true
+		}
+	case g.status.Degraded:
+		g.healthyChecks++
+		if g.healthyChecks >= g.recoveryChecks {
+			g.healthyChecks = 0
+			g.status.Degraded = false
+			g.status.Reason = ""
+			g.status.Since = &now
+			changed = true
+		}
+	}
+	status := g.status
+	g.mu.Unlock()
+
+	if changed {
+		g.announce(status)
+	}
+}
+
+// announce logs the mode change and publishes it for other services
+func (g *governor) announce(status Status) {
+	if status.Degraded {
+		g.logger.Warn("Entering degraded mode, shedding load",
+			zap.String("reason", status.Reason),
+			zap.Int("eventDepth", status.EventDepth),
+			zap.Int64("dbLatencyMs", status.DBLatencyMs))
+	} else {
+		g.logger.Info("Load back to normal, leaving degraded mode",
+			zap.Int("eventDepth", status.EventDepth),
+			zap.Int64("dbLatencyMs", status.DBLatencyMs))
+	}
+
+	event := events.SystemLoadChanged{
+		Event:       events.NewEvent(),
+		Degraded:    status.Degraded,
+		Reason:      status.Reason,
+		EventDepth:  status.EventDepth,
+		DBLatencyMs: status.DBLatencyMs,
+	}
+	if err := g.eventBus.Publish(events.TopicSystemLoadChanged, event); err != nil {
+		g.logger.Error("Failed to publish SystemLoadChanged event", zap.Error(err))
+	}
+}
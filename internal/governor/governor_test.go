@@ -0,0 +1,62 @@
+package governor
+
+import (
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/config"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// fakeLatency reports a fixed database latency
+type fakeLatency struct {
+	latency time.Duration
+}
+
+func (f *fakeLatency) RecentLatency() time.Duration {
+	return f.latency
+}
+
+func TestGovernor_DegradesAndRecovers(t *testing.T) {
+	eventBus := events.NewMockEventBus()
+	latency := &fakeLatency{latency: 20 * time.Millisecond}
+	g := NewGovernor(eventBus, zaptest.NewLogger(t), config.LoadSheddingConfig{
+		Enabled:        true,
+		MaxDBLatencyMs: 500,
+		RecoveryChecks: 2,
+	}, latency).(*governor)
+
+	now := time.Now()
+	g.check(now)
+	assert.False(t, g.Degraded())
+
+	latency.latency = 800 * time.Millisecond
+	g.check(now.Add(time.Second))
+	require.True(t, g.Degraded())
+	assert.Contains(t, g.Status().Reason, "database latency")
+
+	latency.latency = 20 * time.Millisecond
+	g.check(now.Add(2 * time.Second))
+	assert.True(t, g.Degraded(), "one healthy check is not enough to recover")
+	g.check(now.Add(3 * time.Second))
+	assert.False(t, g.Degraded())
+
+	published := eventBus.GetPublishedEvents(events.TopicSystemLoadChanged)
+	require.Len(t, published, 2, "only mode changes are announced")
+	assert.True(t, published[0].(events.SystemLoadChanged).Degraded)
+	assert.False(t, published[1].(events.SystemLoadChanged).Degraded)
+}
+
+func TestGovernor_Disabled(t *testing.T) {
+	g := NewGovernor(events.NewMockEventBus(), zaptest.NewLogger(t), config.LoadSheddingConfig{
+		MaxDBLatencyMs: 1,
+	}, &fakeLatency{latency: time.Minute}).(*governor)
+
+	g.check(time.Now())
+	assert.False(t, g.Degraded())
+	assert.Equal(t, int64(60000), g.Status().DBLatencyMs, "signals are still reported")
+}
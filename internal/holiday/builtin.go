@@ -0,0 +1,140 @@
+package holiday
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// rule places a holiday in a year: on a fixed date, on the nth weekday of a
+// month (negative counts from the month's end), or a number of days from
+// Easter Sunday
+type rule struct {
+	name    string
+	month   time.Month
+	day     int
+	weekday time.Weekday
+	nth     int
+	easter  int
+	movable bool // placed by Easter
+}
+
+// fixed is a holiday on the same date every year
+func fixed(month time.Month, day int, name string) rule {
+	return rule{name: name, month: month, day: day}
+}
+
+// nthWeekday is a holiday on the nth weekday of a month; -1 is the last
+func nthWeekday(nth int, weekday time.Weekday, month time.Month, name string) rule {
+	return rule{name: name, month: month, weekday: weekday, nth: nth}
+}
+
+// fromEaster is a holiday a number of days after Easter Sunday
+func fromEaster(days int, name string) rule {
+	return rule{name: name, easter: days, movable: true}
+}
+
+// date returns the day the rule falls on in a year
+func (r rule) date(year int) time.Time {
+	switch {
+	case r.movable:
+		return easterSunday(year).AddDate(0, 0, r.easter)
+	case r.nth > 0:
+		first := time.Date(year, r.month, 1, 0, 0, 0, 0, time.UTC)
+		offset := (int(r.weekday) - int(first.Weekday()) + 7) % 7
+		return first.AddDate(0, 0, offset+7*(r.nth-1))
+	case r.nth < 0:
+		last := time.Date(year, r.month+1, 0, 0, 0, 0, 0, time.UTC)
+		offset := (int(last.Weekday()) - int(r.weekday) + 7) % 7
+		return last.AddDate(0, 0, -offset+7*(r.nth+1))
+	default:
+		return time.Date(year, r.month, r.day, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// easterSunday computes Western Easter with the anonymous Gregorian algorithm
+func easterSunday(year int) time.Time {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+// builtinRules are the nationwide public holidays of the countries the
+// built-in source knows. Days observed in lieu of a holiday falling on a
+// weekend are not included.
+var builtinRules = map[string][]rule{
+	"US": {
+		fixed(time.January, 1, "New Year's Day"),
+		nthWeekday(3, time.Monday, time.January, "Martin Luther King Jr. Day"),
+		nthWeekday(3, time.Monday, time.February, "Presidents' Day"),
+		nthWeekday(-1, time.Monday, time.May, "Memorial Day"),
+		fixed(time.June, 19, "Juneteenth"),
+		fixed(time.July, 4, "Independence Day"),
+		nthWeekday(1, time.Monday, time.September, "Labor Day"),
+		nthWeekday(2, time.Monday, time.October, "Columbus Day"),
+		fixed(time.November, 11, "Veterans Day"),
+		nthWeekday(4, time.Thursday, time.November, "Thanksgiving Day"),
+		fixed(time.December, 25, "Christmas Day"),
+	},
+	"GB": {
+		fixed(time.January, 1, "New Year's Day"),
+		fromEaster(-2, "Good Friday"),
+		fromEaster(1, "Easter Monday"),
+		nthWeekday(1, time.Monday, time.May, "Early May Bank Holiday"),
+		nthWeekday(-1, time.Monday, time.May, "Spring Bank Holiday"),
+		nthWeekday(-1, time.Monday, time.August, "Summer Bank Holiday"),
+		fixed(time.December, 25, "Christmas Day"),
+		fixed(time.December, 26, "Boxing Day"),
+	},
+	"DE": {
+		fixed(time.January, 1, "New Year's Day"),
+		fromEaster(-2, "Good Friday"),
+		fromEaster(1, "Easter Monday"),
+		fixed(time.May, 1, "Labour Day"),
+		fromEaster(39, "Ascension Day"),
+		fromEaster(50, "Whit Monday"),
+		fixed(time.October, 3, "German Unity Day"),
+		fixed(time.December, 25, "Christmas Day"),
+		fixed(time.December, 26, "St. Stephen's Day"),
+	},
+	"FR": {
+		fixed(time.January, 1, "New Year's Day"),
+		fromEaster(1, "Easter Monday"),
+		fixed(time.May, 1, "Labour Day"),
+		fixed(time.May, 8, "Victory in Europe Day"),
+		fromEaster(39, "Ascension Day"),
+		fromEaster(50, "Whit Monday"),
+		fixed(time.July, 14, "Bastille Day"),
+		fixed(time.August, 15, "Assumption Day"),
+		fixed(time.November, 1, "All Saints' Day"),
+		fixed(time.November, 11, "Armistice Day"),
+		fixed(time.December, 25, "Christmas Day"),
+	},
+}
+
+// BuiltinSource knows the nationwide public holidays of a few countries
+// without any network access
+type BuiltinSource struct{}
+
+// Holidays implements Source
+func (BuiltinSource) Holidays(ctx context.Context, country string, year int) ([]Holiday, error) {
+	rules, ok := builtinRules[country]
+	if !ok {
+		return nil, fmt.Errorf("no built-in holidays for %s", country)
+	}
+
+	holidays := make([]Holiday, len(rules))
+	for i, rule := range rules {
+		holidays[i] = Holiday{Date: rule.date(year), Name: rule.name}
+	}
+	return holidays, nil
+}
@@ -0,0 +1,192 @@
+package holiday
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/httpclient"
+
+	"go.uber.org/zap"
+)
+
+// Source names accepted in holidays.source
+const (
+	SourceBuiltin = "builtin"
+	SourceNager   = "nager"
+	SourceOff     = "off"
+)
+
+// failedLookupTTL is how long a country's year stays without holidays after
+// its source failed, before it is asked again
+const failedLookupTTL = time.Hour
+
+// lookupTimeout bounds one call to a source
+const lookupTimeout = 10 * time.Second
+
+// Holiday is a public holiday of a country
+type Holiday struct {
+	Date time.Time `json:"date"` // midnight UTC of the day
+	Name string    `json:"name"`
+}
+
+// on reports whether the holiday falls on t's calendar day, in t's location
+func (h Holiday) on(t time.Time) bool {
+	year, month, day := t.Date()
+	return h.Date.Year() == year && h.Date.Month() == month && h.Date.Day() == day
+}
+
+// Source supplies the public holidays of a country, by ISO 3166 code such as
+// "US" or "DE"
+type Source interface {
+	Holidays(ctx context.Context, country string, year int) ([]Holiday, error)
+}
+
+// NormalizeCountry returns a country code in the form sources take, such as
+// "US" for "us", or false when it isn't two letters
+func NormalizeCountry(country string) (string, bool) {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if len(country) != 2 || country[0] < 'A' || country[0] > 'Z' || country[1] < 'A' || country[1] > 'Z' {
+		return "", false
+	}
+	return country, true
+}
+
+// calendarEntry is a country's holidays for one year, as last looked up
+type calendarEntry struct {
+	holidays []Holiday
+	expires  time.Time // zero for lookups that succeeded
+}
+
+// Calendar answers holiday questions for any country, looking each country's
+// year up once. A nil Calendar knows no holidays.
+type Calendar struct {
+	source Source
+	logger *zap.Logger
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]calendarEntry
+}
+
+// NewCalendar creates a calendar over source
+func NewCalendar(source Source, logger *zap.Logger) *Calendar {
+	return &Calendar{
+		source:  source,
+		logger:  logger,
+		now:     time.Now,
+		entries: make(map[string]calendarEntry),
+	}
+}
+
+// NewCalendarFromSource creates a calendar over the named source: "builtin",
+// "nager" (fetched from baseURL with client) or "off", which returns nil
+func NewCalendarFromSource(name, baseURL string, client httpclient.Doer, logger *zap.Logger) (*Calendar, error) {
+	switch strings.ToLower(name) {
+	case SourceOff:
+		return nil, nil
+	case "", SourceBuiltin:
+		return NewCalendar(BuiltinSource{}, logger), nil
+	case SourceNager:
+		return NewCalendar(NewNagerSource(baseURL, client), logger), nil
+	default:
+		return nil, fmt.Errorf("unknown holiday source %q; use builtin, nager or off", name)
+	}
+}
+
+// year returns a country's holidays for a year
+func (c *Calendar) year(country string, year int) []Holiday {
+	key := fmt.Sprintf("%s/%d", country, year)
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && (entry.expires.IsZero() || c.now().Before(entry.expires)) {
+		return entry.holidays
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), lookupTimeout)
+	defer cancel()
+	holidays, err := c.source.Holidays(ctx, country, year)
+	entry = calendarEntry{holidays: holidays}
+	if err != nil {
+		c.logger.Warn("Failed to look up public holidays",
+			zap.String("country", country),
+			zap.Int("year", year),
+			zap.Error(err))
+		entry = calendarEntry{expires: c.now().Add(failedLookupTTL)}
+	}
+
+	c.mu.Lock()
+	c.entries[key] = entry
+	c.mu.Unlock()
+	return entry.holidays
+}
+
+// On returns the holiday falling on t's calendar day, in t's location
+func (c *Calendar) On(country string, t time.Time) (Holiday, bool) {
+	country, ok := NormalizeCountry(country)
+	if c == nil || !ok {
+		return Holiday{}, false
+	}
+	for _, holiday := range c.year(country, t.Year()) {
+		if holiday.on(t) {
+			return holiday, true
+		}
+	}
+	return Holiday{}, false
+}
+
+// Between returns the holidays from from's calendar day to to's, in order
+func (c *Calendar) Between(country string, from, to time.Time) []Holiday {
+	country, ok := NormalizeCountry(country)
+	if c == nil || !ok {
+		return nil
+	}
+
+	first := civilDate(from)
+	last := civilDate(to)
+	var between []Holiday
+	for year := first.Year(); year <= last.Year(); year++ {
+		for _, holiday := range c.year(country, year) {
+			if !holiday.Date.Before(first) && !holiday.Date.After(last) {
+				between = append(between, holiday)
+			}
+		}
+	}
+	sort.Slice(between, func(i, j int) bool { return between[i].Date.Before(between[j].Date) })
+	return between
+}
+
+// NextBusinessDay returns midnight, in after's location, of the first day
+// after after's that is a working day and no holiday
+func NextBusinessDay(after time.Time, week common.Week, holidays []Holiday) time.Time {
+	day := time.Date(after.Year(), after.Month(), after.Day(), 0, 0, 0, 0, after.Location())
+	// A year ahead at most, for weeks without working days
+	for i := 0; i < 366; i++ {
+		day = day.AddDate(0, 0, 1)
+		if week.IsWorkingDay(day) && !isHoliday(day, holidays) {
+			return day
+		}
+	}
+	return time.Date(after.Year(), after.Month(), after.Day()+1, 0, 0, 0, 0, after.Location())
+}
+
+// isHoliday reports whether one of the holidays falls on t's calendar day
+func isHoliday(t time.Time, holidays []Holiday) bool {
+	for _, holiday := range holidays {
+		if holiday.on(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// civilDate returns midnight UTC of t's calendar day, in t's location
+func civilDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,110 @@
+package holiday
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// countingSource serves fixed holidays and counts its lookups
+type countingSource struct {
+	holidays []Holiday
+	err      error
+	lookups  int
+}
+
+func (s *countingSource) Holidays(ctx context.Context, country string, year int) ([]Holiday, error) {
+	s.lookups++
+	return s.holidays, s.err
+}
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestBuiltinSource(t *testing.T) {
+	tests := []struct {
+		country string
+		want    time.Time
+		name    string
+	}{
+		{"US", date(2024, time.November, 28), "Thanksgiving Day"},
+		{"US", date(2024, time.May, 27), "Memorial Day"},
+		{"GB", date(2024, time.March, 29), "Good Friday"},
+		{"GB", date(2025, time.August, 25), "Summer Bank Holiday"},
+		{"DE", date(2024, time.May, 9), "Ascension Day"},
+		{"FR", date(2025, time.June, 9), "Whit Monday"},
+	}
+	calendar := NewCalendar(BuiltinSource{}, zap.NewNop())
+	for _, tt := range tests {
+		holiday, ok := calendar.On(tt.country, tt.want)
+		require.True(t, ok, "%s %s", tt.country, tt.name)
+		assert.Equal(t, tt.name, holiday.Name)
+	}
+
+	_, err := BuiltinSource{}.Holidays(context.Background(), "XX", 2024)
+	assert.Error(t, err)
+}
+
+func TestCalendar_LooksUpEachYearOnce(t *testing.T) {
+	source := &countingSource{holidays: []Holiday{{Date: date(2024, time.December, 25), Name: "Christmas Day"}}}
+	calendar := NewCalendar(source, zap.NewNop())
+
+	// The calendar day counts in the time's own location
+	evening := time.Date(2024, time.December, 25, 23, 30, 0, 0, time.FixedZone("UTC-8", -8*3600))
+	holiday, ok := calendar.On("us", evening)
+	require.True(t, ok)
+	assert.Equal(t, "Christmas Day", holiday.Name)
+	_, ok = calendar.On("US", date(2024, time.December, 24))
+	assert.False(t, ok)
+	assert.Equal(t, 1, source.lookups)
+
+	assert.Len(t, calendar.Between("US", date(2024, time.December, 1), date(2024, time.December, 31)), 1)
+	_, ok = calendar.On("USA", evening)
+	assert.False(t, ok, "countries are two-letter codes")
+
+	var none *Calendar
+	_, ok = none.On("US", evening)
+	assert.False(t, ok)
+	assert.Empty(t, none.Between("US", evening, evening))
+}
+
+func TestCalendar_RetriesFailedLookupsLater(t *testing.T) {
+	source := &countingSource{err: errors.New("holiday API unavailable")}
+	calendar := NewCalendar(source, zap.NewNop())
+	now := date(2024, time.June, 1)
+	calendar.now = func() time.Time { return now }
+
+	_, ok := calendar.On("DE", now)
+	assert.False(t, ok)
+	calendar.On("DE", now)
+	assert.Equal(t, 1, source.lookups, "a failure isn't retried on every question")
+
+	now = now.Add(failedLookupTTL + time.Minute)
+	calendar.On("DE", now)
+	assert.Equal(t, 2, source.lookups)
+}
+
+func TestNextBusinessDay(t *testing.T) {
+	holidays := []Holiday{{Date: date(2024, time.December, 25), Name: "Christmas Day"}, {Date: date(2024, time.December, 26), Name: "Boxing Day"}}
+
+	// Tuesday Dec 24: Wednesday and Thursday are holidays
+	assert.Equal(t, date(2024, time.December, 27), NextBusinessDay(time.Date(2024, 12, 24, 15, 0, 0, 0, time.UTC), common.DefaultWeek, holidays))
+	// Friday: the weekend is skipped
+	assert.Equal(t, date(2024, time.December, 30), NextBusinessDay(date(2024, time.December, 27), common.DefaultWeek, holidays))
+	// A Sunday to Thursday week works on Sunday
+	assert.Equal(t, date(2024, time.December, 29), NextBusinessDay(date(2024, time.December, 27), common.NewWeek("sunday", "sun-thu"), holidays))
+
+	_, err := NewCalendarFromSource("ical", "", nil, zap.NewNop())
+	assert.Error(t, err)
+	off, err := NewCalendarFromSource(SourceOff, "", nil, zap.NewNop())
+	require.NoError(t, err)
+	assert.Nil(t, off)
+}
@@ -0,0 +1,85 @@
+package holiday
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"nudgebot-api/internal/httpclient"
+)
+
+// DefaultNagerURL is the public Nager.Date API
+const DefaultNagerURL = "https://date.nager.at"
+
+// nagerHoliday is a holiday as the Nager.Date API returns it
+type nagerHoliday struct {
+	Date   string `json:"date"` // YYYY-MM-DD
+	Name   string `json:"name"` // in English; localName is in the country's language
+	Global bool   `json:"global"`
+}
+
+// NagerSource fetches public holidays from a Nager.Date compatible API,
+// which covers over a hundred countries
+type NagerSource struct {
+	baseURL string
+	client  httpclient.Doer
+}
+
+// NewNagerSource creates a source reading the API at baseURL, empty for
+// DefaultNagerURL, through client
+func NewNagerSource(baseURL string, client httpclient.Doer) *NagerSource {
+	if baseURL == "" {
+		baseURL = DefaultNagerURL
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &NagerSource{baseURL: strings.TrimSuffix(baseURL, "/"), client: client}
+}
+
+// Holidays implements Source. Holidays kept only in some regions of the
+// country are left out.
+func (s *NagerSource) Holidays(ctx context.Context, country string, year int) ([]Holiday, error) {
+	url := fmt.Sprintf("%s/api/v3/PublicHolidays/%d/%s", s.baseURL, year, country)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create holiday request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch holidays: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("no holidays known for %s", country)
+	case resp.StatusCode == http.StatusNoContent:
+		return nil, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("holiday API returned %s", resp.Status)
+	}
+
+	var fetched []nagerHoliday
+	if err := json.NewDecoder(resp.Body).Decode(&fetched); err != nil {
+		return nil, fmt.Errorf("failed to decode holidays: %w", err)
+	}
+
+	holidays := make([]Holiday, 0, len(fetched))
+	for _, holiday := range fetched {
+		if !holiday.Global {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", holiday.Date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid holiday date %q: %w", holiday.Date, err)
+		}
+		holidays = append(holidays, Holiday{Date: date, Name: holiday.Name})
+	}
+	return holidays, nil
+}
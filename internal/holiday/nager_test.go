@@ -0,0 +1,37 @@
+package holiday
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNagerSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/PublicHolidays/2024/DE":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`[
+				{"date": "2024-10-03", "localName": "Tag der Deutschen Einheit", "name": "German Unity Day", "global": true},
+				{"date": "2024-10-31", "localName": "Reformationstag", "name": "Reformation Day", "global": false, "counties": ["DE-BB"]}
+			]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	source := NewNagerSource(server.URL+"/", server.Client())
+	holidays, err := source.Holidays(context.Background(), "DE", 2024)
+	require.NoError(t, err)
+	assert.Equal(t, []Holiday{{Date: time.Date(2024, 10, 3, 0, 0, 0, 0, time.UTC), Name: "German Unity Day"}}, holidays,
+		"regional holidays are left out")
+
+	_, err = source.Holidays(context.Background(), "XX", 2024)
+	assert.Error(t, err)
+}
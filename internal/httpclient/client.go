@@ -0,0 +1,328 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"nudgebot-api/internal/config"
+
+	"github.com/cenkalti/backoff/v4"
+	"go.uber.org/zap"
+)
+
+// Doer sends HTTP requests; *Client, *http.Client and test doubles implement it
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Options configures a Client. Zero values take the defaults.
+type Options struct {
+	Timeout        time.Duration // per attempt
+	MaxRetries     int           // attempts after the first
+	RetryBudget    float64       // retries earned by each request
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	MaxRetryAfter  time.Duration // longest Retry-After waited for
+	Proxy          *url.URL      // see ParseProxy; nil follows HTTP(S)_PROXY
+}
+
+// Option defaults
+const (
+	DefaultTimeout        = 30 * time.Second
+	DefaultRetryBudget    = 0.2
+	DefaultInitialBackoff = 200 * time.Millisecond
+	DefaultMaxBackoff     = 5 * time.Second
+	DefaultMaxRetryAfter  = 30 * time.Second
+)
+
+// The retry budget is kept in thousandths of a retry. maxBudgetTokens caps
+// the retries saved up while calls succeed, so a long quiet spell doesn't pay
+// for a retry storm.
+const (
+	retryCost       = 1000
+	maxBudgetTokens = 10 * retryCost
+)
+
+// OptionsFromConfig returns the shared retry settings with the calling
+// service's timeout
+func OptionsFromConfig(cfg config.HTTPClientConfig, timeout time.Duration) Options {
+	return Options{
+		Timeout:        timeout,
+		MaxRetries:     cfg.MaxRetries,
+		RetryBudget:    cfg.RetryBudget,
+		InitialBackoff: time.Duration(cfg.InitialBackoffMs) * time.Millisecond,
+		MaxBackoff:     time.Duration(cfg.MaxBackoffMs) * time.Millisecond,
+		MaxRetryAfter:  time.Duration(cfg.MaxRetryAfter) * time.Second,
+	}
+}
+
+func (o Options) withDefaults() Options {
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultTimeout
+	}
+	if o.MaxRetries < 0 {
+		o.MaxRetries = 0
+	}
+	if o.RetryBudget <= 0 {
+		o.RetryBudget = DefaultRetryBudget
+	}
+	if o.InitialBackoff <= 0 {
+		o.InitialBackoff = DefaultInitialBackoff
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = DefaultMaxBackoff
+	}
+	if o.MaxRetryAfter <= 0 {
+		o.MaxRetryAfter = DefaultMaxRetryAfter
+	}
+	return o
+}
+
+// Client sends outbound HTTP requests with a timeout per attempt, retrying
+// rate limits, server errors of idempotent requests and failed connections with exponential
+// backoff. A 429's Retry-After is honored up to Options.MaxRetryAfter. Retries
+// come out of a budget earned by the requests sent, so an outage of the
+// remote service doesn't multiply the traffic sent to it.
+type Client struct {
+	name    string
+	http    *http.Client
+	options Options
+	metrics *Metrics
+	logger  *zap.Logger
+
+	mu     sync.Mutex
+	tokens int64
+}
+
+// New creates a client recording its calls in metrics under name. A nil
+// metrics records nothing.
+func New(name string, options Options, metrics *Metrics, logger *zap.Logger) *Client {
+	return NewWithTransport(name, options, nil, metrics, logger)
+}
+
+// NewWithTransport creates a client sending its requests through transport,
+// which then handles any proxy itself. A nil transport uses
+// http.DefaultTransport, through Options.Proxy when set.
+func NewWithTransport(name string, options Options, transport http.RoundTripper, metrics *Metrics, logger *zap.Logger) *Client {
+	options = options.withDefaults()
+	if transport == nil && options.Proxy != nil {
+		transport = proxyTransport(options.Proxy)
+	}
+	return &Client{
+		name:    name,
+		http:    &http.Client{Timeout: options.Timeout, Transport: transport},
+		options: options,
+		metrics: metrics,
+		logger:  logger.With(zap.String("http_client", name)),
+		tokens:  maxBudgetTokens,
+	}
+}
+
+// WithLimits returns a client sharing c's transport and metrics that times
+// out each attempt after timeout and retries at most maxRetries times. A zero
+// timeout keeps c's. The returned client has a retry budget of its own.
+func (c *Client) WithLimits(timeout time.Duration, maxRetries int) *Client {
+	options := c.options
+	if timeout > 0 {
+		options.Timeout = timeout
+	}
+	options.MaxRetries = max(maxRetries, 0)
+	return &Client{
+		name:    c.name,
+		http:    &http.Client{Timeout: options.Timeout, Transport: c.http.Transport},
+		options: options,
+		metrics: c.metrics,
+		logger:  c.logger,
+		tokens:  maxBudgetTokens,
+	}
+}
+
+// Do sends the request, retrying it while the failure is worth retrying and
+// the budget allows. Requests whose body can't be replayed are sent once.
+// Server errors are only retried for idempotent requests; see MarkIdempotent.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	started := time.Now()
+	c.earn()
+
+	retry := backoff.NewExponentialBackOff()
+	retry.InitialInterval = c.options.InitialBackoff
+	retry.MaxInterval = c.options.MaxBackoff
+	retry.MaxElapsedTime = 0
+	retry.Reset()
+
+	attempt := req
+	for retries := 0; ; retries++ {
+		resp, err := c.http.Do(attempt)
+
+		wait, retryable := c.retryDelay(req, resp, err, retry)
+		if !retryable || retries >= c.options.MaxRetries || !replayable(req) {
+			c.metrics.observe(c.name, time.Since(started), retries, resp, err)
+			return resp, err
+		}
+		if !c.spend() {
+			c.metrics.budgetExhausted(c.name)
+			c.metrics.observe(c.name, time.Since(started), retries, resp, err)
+			return resp, err
+		}
+
+		c.logger.Warn("Outbound request failed, retrying",
+			zap.String("method", req.Method),
+			zap.String("host", req.URL.Host),
+			zap.Int("status_code", statusCode(resp)),
+			zap.Duration("retry_in", wait),
+			zap.Error(err))
+
+		if resp != nil {
+			// Drain so the connection is reused
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+			resp.Body.Close()
+		}
+
+		if err := sleep(req.Context(), wait); err != nil {
+			c.metrics.observe(c.name, time.Since(started), retries, nil, err)
+			return nil, err
+		}
+
+		attempt, err = rewind(req)
+		if err != nil {
+			c.metrics.observe(c.name, time.Since(started), retries, nil, err)
+			return nil, err
+		}
+	}
+}
+
+// retryDelay decides whether an attempt's outcome is worth retrying and how
+// long to wait first
+func (c *Client) retryDelay(req *http.Request, resp *http.Response, err error, retry backoff.BackOff) (time.Duration, bool) {
+	if err != nil {
+		if req.Context().Err() != nil {
+			return 0, false
+		}
+		// A request that may have reached the server is only retried when repeating it is harmless
+		if !idempotent(req) && !notSent(err) {
+			return 0, false
+		}
+		return retry.NextBackOff(), true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		c.metrics.rateLimited(c.name)
+		if wait, ok := ParseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			if wait > c.options.MaxRetryAfter {
+				return 0, false
+			}
+			return wait, true
+		}
+		return retry.NextBackOff(), true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		// A gateway may have timed out after the server handled the request
+		return retry.NextBackOff(), idempotent(req)
+	}
+	return 0, false
+}
+
+// earn adds the retries one request pays for to the budget
+func (c *Client) earn() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tokens = min(c.tokens+int64(c.options.RetryBudget*retryCost), maxBudgetTokens)
+}
+
+// spend takes one retry from the budget, reporting false when it is empty
+func (c *Client) spend() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.tokens < retryCost {
+		return false
+	}
+	c.tokens -= retryCost
+	return true
+}
+
+// ParseRetryAfter parses a Retry-After header given in seconds or as an HTTP
+// date, reporting false when there is none
+func ParseRetryAfter(header string) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+// MarkIdempotent declares that repeating req is harmless, so server errors
+// are retried even though it is a POST. Like net/http, it marks the request
+// with an Idempotency-Key header that has no value and is not sent.
+func MarkIdempotent(req *http.Request) {
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	req.Header["Idempotency-Key"] = nil
+}
+
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	_, marked := req.Header["Idempotency-Key"]
+	return marked
+}
+
+// notSent reports whether err happened before the request reached the
+// server, such as a refused connection
+func notSent(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+func replayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// rewind returns a copy of req with a fresh body for the next attempt
+func rewind(req *http.Request) (*http.Request, error) {
+	next := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to replay request body: %w", err)
+		}
+		next.Body = body
+	}
+	return next, nil
+}
+
+func sleep(ctx context.Context, wait time.Duration) error {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func statusCode(resp *http.Response) int {
+	if resp == nil {
+		return 0
+	}
+	return resp.StatusCode
+}
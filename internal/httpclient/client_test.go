@@ -0,0 +1,194 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// fastOptions retries quickly so the tests don't wait on backoff
+func fastOptions() Options {
+	return Options{
+		Timeout:        time.Second,
+		MaxRetries:     3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+		MaxRetryAfter:  2 * time.Second,
+	}
+}
+
+func TestClient_RetriesGatewayErrorsWithBody(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, `{"text":"hi"}`, string(body), "the body is sent again on every attempt")
+		assert.Empty(t, r.Header.Values("Idempotency-Key"), "the idempotency marker is not sent")
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	metrics := NewMetrics()
+	client := New("llm", fastOptions(), metrics, zaptest.NewLogger(t))
+
+	req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(`{"text":"hi"}`))
+	require.NoError(t, err)
+	MarkIdempotent(req)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int32(3), calls.Load())
+	summary := metrics.Snapshot()["llm"]
+	assert.Equal(t, int64(1), summary.Requests)
+	assert.Equal(t, int64(2), summary.Retries)
+	assert.Zero(t, summary.Failures)
+}
+
+func TestClient_HonorsRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	var first time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			first = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		assert.GreaterOrEqual(t, time.Since(first), time.Second)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	metrics := NewMetrics()
+	client := New("telegram", fastOptions(), metrics, zaptest.NewLogger(t))
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, int64(1), metrics.Snapshot()["telegram"].RateLimited)
+}
+
+func TestClient_GivesUpOnLongRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := New("telegram", fastOptions(), nil, zaptest.NewLogger(t))
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode, "the caller sees the rate limit")
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestClient_DoesNotRetryServerErrorsOfPosts(t *testing.T) {
+	for _, status := range []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Empty(t, r.Header.Get("Idempotency-Key"))
+				calls.Add(1)
+				w.WriteHeader(status)
+			}))
+			defer server.Close()
+
+			metrics := NewMetrics()
+			client := New("telegram", fastOptions(), metrics, zaptest.NewLogger(t))
+			req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("chat_id=1&text=hi"))
+			require.NoError(t, err)
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, int32(1), calls.Load(), "the message may have been sent")
+			assert.Equal(t, int64(1), metrics.Snapshot()["telegram"].Failures)
+		})
+	}
+}
+
+func TestClient_RetryBudget(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	metrics := NewMetrics()
+	client := New("llm", fastOptions(), metrics, zaptest.NewLogger(t))
+	for i := 0; i < 10; i++ {
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	// The budget starts with 10 retries and each request earns a fifth of one
+	summary := metrics.Snapshot()["llm"]
+	assert.Equal(t, int64(10), summary.Requests)
+	assert.Equal(t, int64(11), summary.Retries)
+	assert.Equal(t, int32(21), calls.Load())
+	assert.Positive(t, summary.BudgetExhausted)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	wait, ok := ParseRetryAfter("7")
+	assert.True(t, ok)
+	assert.Equal(t, 7*time.Second, wait)
+
+	wait, ok = ParseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.InDelta(t, time.Hour.Seconds(), wait.Seconds(), 2)
+
+	_, ok = ParseRetryAfter("soon")
+	assert.False(t, ok)
+	_, ok = ParseRetryAfter("")
+	assert.False(t, ok)
+}
+
+func TestClient_WithLimits(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	metrics := NewMetrics()
+	client := New("llm", fastOptions(), metrics, zaptest.NewLogger(t)).WithLimits(0, 1)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, int32(2), calls.Load(), "one retry instead of three")
+	assert.Equal(t, time.Second, client.options.Timeout, "a zero timeout keeps the original")
+	assert.Equal(t, int64(1), metrics.Snapshot()["llm"].Requests, "calls are recorded with the original client's")
+}
@@ -0,0 +1,116 @@
+package httpclient
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Metrics counts the outbound calls of every client sharing it, by client name
+type Metrics struct {
+	mu      sync.RWMutex
+	clients map[string]*clientMetrics
+}
+
+// clientMetrics accumulates the calls of one client
+type clientMetrics struct {
+	requests        int64
+	retries         int64
+	failures        int64
+	rateLimited     int64
+	budgetExhausted int64
+	total           time.Duration
+	max             time.Duration
+}
+
+// ClientMetricsSummary summarizes the calls of one client. Failures are calls
+// that ended in an error or a 5xx after any retries; latency includes retries.
+type ClientMetricsSummary struct {
+	Requests        int64  `json:"requests"`
+	Retries         int64  `json:"retries"`
+	Failures        int64  `json:"failures"`
+	RateLimited     int64  `json:"rate_limited"`
+	BudgetExhausted int64  `json:"budget_exhausted"`
+	AverageLatency  string `json:"average_latency"`
+	MaxLatency      string `json:"max_latency"`
+}
+
+// NewMetrics creates a new metrics instance
+func NewMetrics() *Metrics {
+	return &Metrics{clients: make(map[string]*clientMetrics)}
+}
+
+// Snapshot returns the summary of every client keyed by name
+func (m *Metrics) Snapshot() map[string]ClientMetricsSummary {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	snapshot := make(map[string]ClientMetricsSummary, len(m.clients))
+	for name, stats := range m.clients {
+		summary := ClientMetricsSummary{
+			Requests:        stats.requests,
+			Retries:         stats.retries,
+			Failures:        stats.failures,
+			RateLimited:     stats.rateLimited,
+			BudgetExhausted: stats.budgetExhausted,
+			AverageLatency:  "0s",
+			MaxLatency:      stats.max.String(),
+		}
+		if stats.requests > 0 {
+			summary.AverageLatency = (stats.total / time.Duration(stats.requests)).String()
+		}
+		snapshot[name] = summary
+	}
+	return snapshot
+}
+
+// observe records a finished call; a nil Metrics records nothing
+func (m *Metrics) observe(name string, duration time.Duration, retries int, resp *http.Response, err error) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := m.client(name)
+	stats.requests++
+	stats.retries += int64(retries)
+	if err != nil || resp.StatusCode >= http.StatusInternalServerError {
+		stats.failures++
+	}
+	stats.total += duration
+	stats.max = max(stats.max, duration)
+}
+
+func (m *Metrics) rateLimited(name string) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.client(name).rateLimited++
+}
+
+func (m *Metrics) budgetExhausted(name string) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.client(name).budgetExhausted++
+}
+
+// client returns the stats of a client, creating them; m.mu must be held
+func (m *Metrics) client(name string) *clientMetrics {
+	stats, ok := m.clients[name]
+	if !ok {
+		stats = &clientMetrics{}
+		m.clients[name] = stats
+	}
+	return stats
+}
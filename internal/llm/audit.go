@@ -0,0 +1,149 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"nudgebot-api/internal/common"
+
+	"go.uber.org/zap"
+)
+
+// AuditPurgeJobName is the name the audit purge runs under on the job scheduler
+const AuditPurgeJobName = "llm_audit_purge"
+
+// DefaultAuditPurgeSchedule purges expired audits nightly, outside busy hours
+const DefaultAuditPurgeSchedule = "15 4 * * *"
+
+// ParseAudit records one parse: what was sent to the model, what came back
+// and what was made of it, so bad parses can be diagnosed and prompts improved
+type ParseAudit struct {
+	ID            common.ID     `gorm:"type:uuid;primaryKey" json:"id"`
+	CorrelationID string        `gorm:"type:varchar(64);index" json:"correlation_id"`
+	UserID        common.UserID `gorm:"type:varchar(36);index" json:"user_id"`
+	Model         string        `gorm:"type:varchar(100)" json:"model,omitempty"`
+	PromptVersion string        `gorm:"type:varchar(100);index" json:"prompt_version,omitempty"`
+	Prompt        string        `gorm:"type:text" json:"prompt,omitempty"`
+	RawResponse   string        `gorm:"type:text" json:"raw_response,omitempty"`
+	// ParsedTasks is the JSON list of tasks extracted from the response
+	ParsedTasks string  `gorm:"type:text" json:"parsed_tasks,omitempty"`
+	Confidence  float64 `json:"confidence"`
+	// Fallback marks parses served by the heuristic parser after the model failed
+	Fallback  bool      `gorm:"not null;default:false" json:"fallback"`
+	Error     string    `gorm:"type:text" json:"error,omitempty"`
+	CreatedAt time.Time `gorm:"not null;index" json:"created_at"`
+}
+
+// TableName returns the table name for the ParseAudit model
+func (ParseAudit) TableName() string {
+	return "llm_parse_audits"
+}
+
+// exchange collects what providers sent to and received from the model while
+// serving one request
+type exchange struct {
+	model         string
+	promptVersion string
+	prompt        string
+	rawResponse   string
+	fallback      bool
+}
+
+type exchangeKey struct{}
+
+// withExchange returns a context that providers record the exchange in
+func withExchange(ctx context.Context, e *exchange) context.Context {
+	return context.WithValue(ctx, exchangeKey{}, e)
+}
+
+// exchangeFrom returns the exchange carried by ctx, or nil when the request
+// is not audited
+func exchangeFrom(ctx context.Context) *exchange {
+	e, _ := ctx.Value(exchangeKey{}).(*exchange)
+	return e
+}
+
+// recordPrompt notes the prompt sent to the model and the template it was
+// rendered from
+func recordPrompt(ctx context.Context, model, promptVersion, prompt string) {
+	if e := exchangeFrom(ctx); e != nil {
+		e.model = model
+		e.promptVersion = promptVersion
+		e.prompt = prompt
+	}
+}
+
+// recordRawResponse notes the model's raw response. Retries replace it.
+func recordRawResponse(ctx context.Context, body []byte) {
+	if e := exchangeFrom(ctx); e != nil {
+		e.rawResponse = string(body)
+	}
+}
+
+// recordFallback notes that the fallback parser served the request
+func recordFallback(ctx context.Context) {
+	if e := exchangeFrom(ctx); e != nil {
+		e.fallback = true
+	}
+}
+
+// newParseAudit builds the audit of a parse from its exchange and outcome.
+// The response is nil when parsing failed.
+func newParseAudit(correlationID string, userID common.UserID, e *exchange, response *LLMResponse, parseErr error) *ParseAudit {
+	audit := &ParseAudit{
+		ID:            common.NewID(),
+		CorrelationID: correlationID,
+		UserID:        userID,
+		Model:         e.model,
+		PromptVersion: e.promptVersion,
+		Prompt:        e.prompt,
+		RawResponse:   e.rawResponse,
+		Fallback:      e.fallback,
+		CreatedAt:     time.Now(),
+	}
+
+	if response != nil {
+		audit.Confidence = response.Confidence
+		if tasks, err := json.Marshal(response.AllTasks()); err == nil {
+			audit.ParsedTasks = string(tasks)
+		}
+	}
+	if parseErr != nil {
+		audit.Error = parseErr.Error()
+	}
+	return audit
+}
+
+// AuditPurger deletes parse audits older than the retention period
+type AuditPurger struct {
+	repository    AuditRepository
+	logger        *zap.Logger
+	retentionDays int
+	now           func() time.Time
+}
+
+// NewAuditPurger creates a purger keeping audits for retentionDays
+func NewAuditPurger(repository AuditRepository, logger *zap.Logger, retentionDays int) *AuditPurger {
+	return &AuditPurger{
+		repository:    repository,
+		logger:        logger,
+		retentionDays: retentionDays,
+		now:           time.Now,
+	}
+}
+
+// Run deletes the audits recorded before the retention period
+func (p *AuditPurger) Run(ctx context.Context) error {
+	cutoff := p.now().AddDate(0, 0, -p.retentionDays)
+
+	purged, err := p.repository.PurgeAuditsBefore(ctx, cutoff)
+	if err != nil {
+		return err
+	}
+
+	p.logger.Info("Purged expired parse audits",
+		zap.Time("cutoff", cutoff),
+		zap.Int64("audits", purged))
+	return nil
+}
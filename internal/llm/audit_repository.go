@@ -0,0 +1,61 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"nudgebot-api/internal/common"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// AuditRepository persists parse audits
+type AuditRepository interface {
+	SaveAudit(audit *ParseAudit) error
+	PurgeAuditsBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// gormAuditRepository implements AuditRepository using GORM
+type gormAuditRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewGormAuditRepository creates a new GORM-based audit repository
+func NewGormAuditRepository(db *gorm.DB, logger *zap.Logger) AuditRepository {
+	return &gormAuditRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// SaveAudit stores a parse audit
+func (r *gormAuditRepository) SaveAudit(audit *ParseAudit) error {
+	if audit.ID == "" {
+		audit.ID = common.NewID()
+	}
+	if err := r.db.Create(audit).Error; err != nil {
+		return fmt.Errorf("failed to save parse audit: %w", err)
+	}
+	return nil
+}
+
+// PurgeAuditsBefore deletes the audits recorded before cutoff and returns how
+// many were deleted
+func (r *gormAuditRepository) PurgeAuditsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("created_at < ?", cutoff).Delete(&ParseAudit{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to purge parse audits: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// RunMigrations creates the LLM tables
+func RunMigrations(db *gorm.DB) error {
+	if err := db.AutoMigrate(&ParseAudit{}); err != nil {
+		return fmt.Errorf("failed to auto-migrate LLM tables: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryAuditRepository keeps audits in memory
+type memoryAuditRepository struct {
+	mu      sync.Mutex
+	audits  []*ParseAudit
+	purged  time.Time
+	saveErr error
+}
+
+func (r *memoryAuditRepository) SaveAudit(audit *ParseAudit) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.audits = append(r.audits, audit)
+	return r.saveErr
+}
+
+func (r *memoryAuditRepository) PurgeAuditsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	r.purged = cutoff
+	return 3, nil
+}
+
+// recordingProvider answers like a model would, recording its exchange
+type recordingProvider struct {
+	erroringProvider
+}
+
+func (p *recordingProvider) ParseTask(ctx context.Context, req ParseRequest) (*LLMResponse, error) {
+	recordPrompt(ctx, "gemma-test", "parse@v1", "Parse: "+req.Text)
+	recordRawResponse(ctx, []byte(`{"title":"Call mom","confidence":0.9}`))
+	return &LLMResponse{
+		ParsedTask: ParsedTask{Title: "Call mom", Priority: common.PriorityMedium},
+		Confidence: 0.9,
+	}, nil
+}
+
+func TestLLMService_AuditsParses(t *testing.T) {
+	logger := zap.NewNop()
+	bus := events.NewEventBus(logger)
+	defer bus.Close()
+
+	audits := &memoryAuditRepository{saveErr: errors.New("connection refused")}
+	service := &llmService{
+		eventBus: bus,
+		logger:   logger,
+		provider: &recordingProvider{},
+		ready:    common.NewReadiness(),
+		audit:    audits,
+	}
+
+	parsed := make(chan events.TaskParsed, 1)
+	require.NoError(t, bus.Subscribe(events.TopicTaskParsed, func(event events.TaskParsed) { parsed <- event }))
+
+	received := events.MessageReceived{Event: events.NewEvent(), UserID: "user", ChatID: "42", MessageText: "call mom"}
+	service.handleMessageReceived(received)
+	select {
+	case <-parsed:
+	case <-time.After(time.Second):
+		t.Fatal("a failed audit must not fail the parse")
+	}
+
+	require.Len(t, audits.audits, 1)
+	audit := audits.audits[0]
+	assert.Equal(t, received.CorrelationID, audit.CorrelationID)
+	assert.Equal(t, common.UserID("user"), audit.UserID)
+	assert.Equal(t, "gemma-test", audit.Model)
+	assert.Equal(t, "parse@v1", audit.PromptVersion)
+	assert.Equal(t, "Parse: call mom", audit.Prompt)
+	assert.Contains(t, audit.RawResponse, `"confidence":0.9`)
+	assert.Equal(t, 0.9, audit.Confidence)
+	assert.False(t, audit.Fallback)
+	assert.Empty(t, audit.Error)
+
+	var tasks []ParsedTask
+	require.NoError(t, json.Unmarshal([]byte(audit.ParsedTasks), &tasks))
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "Call mom", tasks[0].Title)
+}
+
+func TestLLMService_AuditsFallbackAndFailures(t *testing.T) {
+	audits := &memoryAuditRepository{}
+	service := &llmService{
+		logger: zap.NewNop(),
+		provider: NewFallbackProvider(&erroringProvider{err: errors.New("service unavailable")},
+			NewHeuristicProvider(nil), nil),
+		audit: audits,
+	}
+
+	response, err := service.parse(context.Background(), "corr", ParseRequest{Text: "call mom tomorrow", UserID: "user"})
+	require.NoError(t, err)
+	require.Len(t, audits.audits, 1)
+	assert.True(t, audits.audits[0].Fallback)
+	assert.Equal(t, response.Confidence, audits.audits[0].Confidence)
+
+	service.provider = &erroringProvider{err: errors.New("service unavailable")}
+	_, err = service.parse(context.Background(), "corr", ParseRequest{Text: "call mom", UserID: "user"})
+	require.Error(t, err)
+	require.Len(t, audits.audits, 2)
+	assert.Equal(t, "service unavailable", audits.audits[1].Error)
+	assert.Empty(t, audits.audits[1].ParsedTasks)
+}
+
+func TestAuditPurger_PurgesBeforeRetention(t *testing.T) {
+	audits := &memoryAuditRepository{}
+	purger := NewAuditPurger(audits, zap.NewNop(), 30)
+	now := time.Date(2024, 3, 31, 4, 15, 0, 0, time.UTC)
+	purger.now = func() time.Time { return now }
+
+	require.NoError(t, purger.Run(context.Background()))
+	assert.Equal(t, time.Date(2024, 3, 1, 4, 15, 0, 0, time.UTC), audits.purged)
+}
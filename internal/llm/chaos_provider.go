@@ -0,0 +1,61 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+
+	"nudgebot-api/internal/chaos"
+)
+
+// chaosProvider decorates an LLMProvider with injected service errors
+type chaosProvider struct {
+	next     LLMProvider
+	injector *chaos.Injector
+}
+
+// NewChaosProvider wraps a provider with fault injection. A nil injector
+// returns the provider unchanged.
+func NewChaosProvider(next LLMProvider, injector *chaos.Injector) LLMProvider {
+	if injector == nil {
+		return next
+	}
+	return &chaosProvider{next: next, injector: injector}
+}
+
+// fault returns a retryable API error when an LLM failure is injected
+func (p *chaosProvider) fault(operation string) error {
+	if !p.injector.Should(chaos.FaultLLMError) {
+		return nil
+	}
+	return NewAPIError(http.StatusServiceUnavailable, ErrorCodeServiceUnavailable,
+		"service unavailable", p.injector.Error(chaos.FaultLLMError, operation).Error())
+}
+
+func (p *chaosProvider) ParseTask(ctx context.Context, req ParseRequest) (*LLMResponse, error) {
+	if err := p.fault("ParseTask"); err != nil {
+		return nil, err
+	}
+	return p.next.ParseTask(ctx, req)
+}
+
+func (p *chaosProvider) ValidateConnection(ctx context.Context) error {
+	if err := p.fault("ValidateConnection"); err != nil {
+		return err
+	}
+	return p.next.ValidateConnection(ctx)
+}
+
+func (p *chaosProvider) GetModelInfo() ModelInfo {
+	return p.next.GetModelInfo()
+}
+
+func (p *chaosProvider) StreamText(ctx context.Context, req TextRequest, onText func(text string)) (string, error) {
+	if err := p.fault("StreamText"); err != nil {
+		return "", err
+	}
+	streamer, ok := streamerOf(p.next)
+	if !ok {
+		return "", ErrStreamingUnsupported
+	}
+	return streamer.StreamText(ctx, req, onText)
+}
@@ -0,0 +1,121 @@
+package llm
+
+import (
+	"fmt"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/config"
+	"nudgebot-api/internal/events"
+)
+
+// ConfidenceThresholds grade how far a parse can be trusted. Parses at or
+// above High are saved straight away; anything below needs the user's
+// confirmation.
+type ConfidenceThresholds struct {
+	High   float64
+	Medium float64
+	Low    float64
+}
+
+// DefaultConfidenceThresholds returns the thresholds used when none are configured
+func DefaultConfidenceThresholds() ConfidenceThresholds {
+	return ConfidenceThresholds{High: 0.8, Medium: 0.6, Low: 0.4}
+}
+
+// NewConfidenceThresholds returns the thresholds configured for the
+// deployment, or an error when they are out of order or outside 0 to 1
+func NewConfidenceThresholds(cfg config.LLMConfig) (ConfidenceThresholds, error) {
+	thresholds := ConfidenceThresholds{
+		High:   cfg.ConfidenceHigh,
+		Medium: cfg.ConfidenceMedium,
+		Low:    cfg.ConfidenceLow,
+	}
+	if err := thresholds.Validate(); err != nil {
+		return ConfidenceThresholds{}, err
+	}
+	return thresholds, nil
+}
+
+// Validate checks that 0 <= Low <= Medium <= High <= 1
+func (t ConfidenceThresholds) Validate() error {
+	for _, threshold := range []struct {
+		field string
+		value float64
+	}{
+		{"confidence_high", t.High},
+		{"confidence_medium", t.Medium},
+		{"confidence_low", t.Low},
+	} {
+		if threshold.value < 0 || threshold.value > 1 {
+			return NewConfigurationError(threshold.field, "confidence threshold out of range",
+				fmt.Sprintf("%v is not between 0 and 1", threshold.value))
+		}
+	}
+
+	if t.Low > t.Medium || t.Medium > t.High {
+		return NewConfigurationError("confidence_thresholds", "confidence thresholds out of order",
+			fmt.Sprintf("low %v, medium %v and high %v must not decrease", t.Low, t.Medium, t.High))
+	}
+	return nil
+}
+
+// Level grades a confidence score against the thresholds
+func (t ConfidenceThresholds) Level(confidence float64) string {
+	switch {
+	case confidence >= t.High:
+		return events.ConfidenceLevelHigh
+	case confidence >= t.Medium:
+		return events.ConfidenceLevelMedium
+	case confidence >= t.Low:
+		return events.ConfidenceLevelLow
+	default:
+		return events.ConfidenceLevelVeryLow
+	}
+}
+
+// NeedsConfirmation reports whether a parse with this confidence must be
+// confirmed by the user before it is saved
+func (t ConfidenceThresholds) NeedsConfirmation(confidence float64) bool {
+	return t.Level(confidence) != events.ConfidenceLevelHigh
+}
+
+// ThresholdOverride holds the thresholds a user has set for themselves. Nil
+// thresholds use the deployment's.
+type ThresholdOverride struct {
+	High   *float64
+	Medium *float64
+	Low    *float64
+}
+
+// Apply returns the thresholds with the override's set thresholds replacing
+// them. An override that would leave the thresholds invalid is ignored.
+func (o ThresholdOverride) Apply(t ConfidenceThresholds) ConfidenceThresholds {
+	overridden := t
+	if o.High != nil {
+		overridden.High = *o.High
+	}
+	if o.Medium != nil {
+		overridden.Medium = *o.Medium
+	}
+	if o.Low != nil {
+		overridden.Low = *o.Low
+	}
+
+	if overridden.Validate() != nil {
+		return t
+	}
+	return overridden
+}
+
+// ThresholdResolver finds a user's own confidence thresholds
+type ThresholdResolver interface {
+	ThresholdOverrideFor(userID common.UserID) (ThresholdOverride, error)
+}
+
+// ThresholdResolverFunc adapts a function to the ThresholdResolver interface
+type ThresholdResolverFunc func(userID common.UserID) (ThresholdOverride, error)
+
+// ThresholdOverrideFor implements the ThresholdResolver interface
+func (f ThresholdResolverFunc) ThresholdOverrideFor(userID common.UserID) (ThresholdOverride, error) {
+	return f(userID)
+}
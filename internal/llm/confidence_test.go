@@ -0,0 +1,110 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/config"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestConfidenceThresholds_Level(t *testing.T) {
+	thresholds := DefaultConfidenceThresholds()
+
+	assert.Equal(t, events.ConfidenceLevelHigh, thresholds.Level(0.8))
+	assert.Equal(t, events.ConfidenceLevelMedium, thresholds.Level(0.7))
+	assert.Equal(t, events.ConfidenceLevelLow, thresholds.Level(0.5))
+	assert.Equal(t, events.ConfidenceLevelVeryLow, thresholds.Level(HeuristicConfidence))
+
+	assert.False(t, thresholds.NeedsConfirmation(0.9))
+	assert.True(t, thresholds.NeedsConfirmation(0.7))
+}
+
+func TestNewConfidenceThresholds_Validates(t *testing.T) {
+	thresholds, err := NewConfidenceThresholds(config.LLMConfig{ConfidenceHigh: 0.9, ConfidenceMedium: 0.7, ConfidenceLow: 0.5})
+	require.NoError(t, err)
+	assert.Equal(t, ConfidenceThresholds{High: 0.9, Medium: 0.7, Low: 0.5}, thresholds)
+
+	_, err = NewConfidenceThresholds(config.LLMConfig{ConfidenceHigh: 1.2, ConfidenceMedium: 0.6, ConfidenceLow: 0.4})
+	var configErr ConfigurationError
+	require.ErrorAs(t, err, &configErr)
+	assert.Equal(t, "confidence_high", configErr.Field)
+
+	_, err = NewConfidenceThresholds(config.LLMConfig{ConfidenceHigh: 0.5, ConfidenceMedium: 0.6, ConfidenceLow: 0.4})
+	assert.Error(t, err, "thresholds must not decrease")
+}
+
+func TestThresholdOverride_Apply(t *testing.T) {
+	deployment := DefaultConfidenceThresholds()
+	high, low := 0.95, 0.7
+
+	overridden := ThresholdOverride{High: &high}.Apply(deployment)
+	assert.Equal(t, ConfidenceThresholds{High: 0.95, Medium: 0.6, Low: 0.4}, overridden)
+
+	// Raising low above the deployment's medium would leave them out of order
+	assert.Equal(t, deployment, ThresholdOverride{Low: &low}.Apply(deployment))
+	assert.Equal(t, deployment, ThresholdOverride{}.Apply(deployment))
+}
+
+// fixedProvider answers every request with the same confidence
+type fixedProvider struct {
+	erroringProvider
+	confidence float64
+}
+
+func (p *fixedProvider) ParseTask(ctx context.Context, req ParseRequest) (*LLMResponse, error) {
+	return &LLMResponse{
+		ParsedTask: ParsedTask{Title: "Call mom", Priority: common.PriorityMedium},
+		Confidence: p.confidence,
+	}, nil
+}
+
+func TestLLMService_ConfirmsParsesBelowUserThresholds(t *testing.T) {
+	logger := zap.NewNop()
+	bus := events.NewEventBus(logger)
+	defer bus.Close()
+
+	strict := 0.95
+	service := &llmService{
+		eventBus:   bus,
+		logger:     logger,
+		provider:   &fixedProvider{confidence: 0.9},
+		ready:      common.NewReadiness(),
+		thresholds: DefaultConfidenceThresholds(),
+		overrides: ThresholdResolverFunc(func(userID common.UserID) (ThresholdOverride, error) {
+			if userID == "strict" {
+				return ThresholdOverride{High: &strict}, nil
+			}
+			return ThresholdOverride{}, errors.New("record not found")
+		}),
+	}
+
+	parsed := make(chan events.TaskParsed, 1)
+	require.NoError(t, bus.Subscribe(events.TopicTaskParsed, func(event events.TaskParsed) { parsed <- event }))
+
+	receive := func(userID string) events.TaskParsed {
+		service.handleMessageReceived(events.MessageReceived{Event: events.NewEvent(), UserID: userID, ChatID: "42", MessageText: "call mom"})
+		select {
+		case event := <-parsed:
+			return event
+		case <-time.After(time.Second):
+			t.Fatal("no TaskParsed event")
+			return events.TaskParsed{}
+		}
+	}
+
+	event := receive("user")
+	assert.False(t, event.Preview, "trusted parses are saved straight away")
+	assert.Equal(t, events.ConfidenceLevelHigh, event.ConfidenceLevel)
+
+	event = receive("strict")
+	assert.True(t, event.Preview, "the user's own threshold asks for confirmation")
+	assert.Equal(t, events.ConfidenceLevelMedium, event.ConfidenceLevel)
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/holiday"
 )
 
 // ParseRequest represents a request to parse natural language text into a task
@@ -11,8 +12,37 @@ type ParseRequest struct {
 	Text    string        `json:"text" validate:"required"`
 	UserID  common.UserID `json:"user_id" validate:"required"`
 	Context *ContextData  `json:"context,omitempty"`
+
+	// Locale is the user's language, such as "vi" or "pt-BR", picking the
+	// prompt variant. Empty uses the default prompt.
+	Locale string `json:"locale,omitempty"`
+
+	// Week is how the user's week runs, for phrases such as "next week" or
+	// "end of the week". Nil uses common.DefaultWeek.
+	Week *common.Week `json:"week,omitempty"`
+
+	// Holidays are the public holidays coming up in the user's country, which
+	// are not business days
+	Holidays []holiday.Holiday `json:"holidays,omitempty"`
+
+	// Messages holds the individual messages of a forwarded bundle; each may yield its own task
+	Messages []string `json:"messages,omitempty"`
+
+	// Summarize asks for a long text, such as a pasted email, to be condensed
+	// into a short title and description
+	Summarize bool `json:"summarize,omitempty"`
 }
 
+// Habit periods a parsed task can repeat over
+const (
+	HabitDaily  = "daily"
+	HabitWeekly = "weekly"
+)
+
+// KindMessage marks a parse of "send me X at 5pm": the bot is to deliver the
+// text at the due date rather than track it as a task
+const KindMessage = "message"
+
 // ParsedTask represents a task that has been parsed from natural language
 type ParsedTask struct {
 	Title       string          `json:"title" validate:"required"`
@@ -20,13 +50,20 @@ type ParsedTask struct {
 	DueDate     *time.Time      `json:"due_date,omitempty"`
 	Priority    common.Priority `json:"priority" validate:"required"`
 	Tags        []string        `json:"tags"`
+	Habit       string          `json:"habit,omitempty"`    // "daily" or "weekly" when the task repeats as a habit
+	Kind        string          `json:"kind,omitempty"`     // "message" for a note to send at the due date, empty for a task
+	Location    string          `json:"location,omitempty"` // a place the task involves, such as "post office"
 }
 
 // LLMResponse represents the response from the LLM service
 type LLMResponse struct {
-	ParsedTask ParsedTask `json:"parsed_task" validate:"required"`
-	Confidence float64    `json:"confidence" validate:"min=0,max=1"`
-	Reasoning  string     `json:"reasoning"`
+	ParsedTask ParsedTask   `json:"parsed_task" validate:"required"`
+	Tasks      []ParsedTask `json:"tasks,omitempty"` // all tasks when a request yields more than one
+	Confidence float64      `json:"confidence" validate:"min=0,max=1"`
+	Reasoning  string       `json:"reasoning"`
+
+	// PromptVersion identifies the prompt template that produced the parse
+	PromptVersion string `json:"prompt_version,omitempty"`
 }
 
 // ParseError represents an error that occurred during parsing
@@ -54,15 +91,18 @@ type UserPrefs struct {
 	TimeZone        string          `json:"timezone"`
 	DateFormat      string          `json:"date_format"`
 	CommonTags      []string        `json:"common_tags"`
+	// Language is the language the user chose in /settings, used over their
+	// Telegram client's; empty follows the client
+	Language string `json:"language,omitempty"`
+	// WeekStart and WorkingDays are the week the user chose in /settings,
+	// empty for the default Monday to Friday
+	WeekStart   string `json:"week_start,omitempty"`
+	WorkingDays string `json:"working_days,omitempty"`
+	// Country is the ISO 3166 code whose public holidays the user observes,
+	// empty for none
+	Country string `json:"country,omitempty"`
 }
 
-// Confidence levels
-const (
-	ConfidenceHigh   = 0.8
-	ConfidenceMedium = 0.6
-	ConfidenceLow    = 0.4
-)
-
 // Parse error codes
 const (
 	ParseErrorCodeInvalidInput       = "INVALID_INPUT"
@@ -70,19 +110,18 @@ const (
 	ParseErrorCodeMissingTitle       = "MISSING_TITLE"
 	ParseErrorCodeInvalidDate        = "INVALID_DATE"
 	ParseErrorCodeServiceUnavailable = "SERVICE_UNAVAILABLE"
+	ParseErrorCodeUnsafeContent      = "UNSAFE_CONTENT"
 )
 
-// IsHighConfidence checks if the confidence level is high
-func (r LLMResponse) IsHighConfidence() bool {
-	return r.Confidence >= ConfidenceHigh
-}
-
-// IsMediumConfidence checks if the confidence level is medium
-func (r LLMResponse) IsMediumConfidence() bool {
-	return r.Confidence >= ConfidenceMedium && r.Confidence < ConfidenceHigh
+// IsBatch reports whether the request carries a bundle of messages
+func (r ParseRequest) IsBatch() bool {
+	return len(r.Messages) > 1
 }
 
-// IsLowConfidence checks if the confidence level is low
-func (r LLMResponse) IsLowConfidence() bool {
-	return r.Confidence < ConfidenceMedium
+// AllTasks returns every task in the response
+func (r LLMResponse) AllTasks() []ParsedTask {
+	if len(r.Tasks) > 0 {
+		return r.Tasks
+	}
+	return []ParsedTask{r.ParsedTask}
 }
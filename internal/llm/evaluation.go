@@ -0,0 +1,235 @@
+package llm
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+
+	"nudgebot-api/internal/common"
+)
+
+// evalDateLayout labels a due date by day only, when the time does not matter
+const evalDateLayout = "2006-01-02"
+
+// EvalCase is one labeled message of an evaluation dataset
+type EvalCase struct {
+	ID       string       `json:"id"`
+	Text     string       `json:"text"`
+	Locale   string       `json:"locale,omitempty"`
+	Expected EvalExpected `json:"expected"`
+}
+
+// EvalExpected is the task a message should parse into. Due is an RFC 3339
+// time, a date such as "2024-01-11" when only the day is labeled, or empty
+// when the message has no due date. An empty priority means medium.
+type EvalExpected struct {
+	Title    string          `json:"title"`
+	Due      string          `json:"due,omitempty"`
+	Priority common.Priority `json:"priority,omitempty"`
+}
+
+// LoadEvalCases reads a dataset with one JSON case per line. Blank lines and
+// lines starting with "//" are skipped.
+func LoadEvalCases(r io.Reader) ([]EvalCase, error) {
+	var cases []EvalCase
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "//") {
+			continue
+		}
+
+		var c EvalCase
+		if err := json.Unmarshal([]byte(text), &c); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if c.ID == "" {
+			c.ID = fmt.Sprintf("line-%d", line)
+		}
+		if c.Text == "" || c.Expected.Title == "" {
+			return nil, fmt.Errorf("line %d: case %s needs a text and an expected title", line, c.ID)
+		}
+		if c.Expected.Priority == "" {
+			c.Expected.Priority = common.PriorityMedium
+		}
+		if _, _, err := parseEvalDue(c.Expected.Due); err != nil {
+			return nil, fmt.Errorf("line %d: case %s: %w", line, c.ID, err)
+		}
+		cases = append(cases, c)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return cases, nil
+}
+
+// parseEvalDue parses a labeled due date, reporting whether only its day counts
+func parseEvalDue(due string) (*time.Time, bool, error) {
+	if due == "" {
+		return nil, false, nil
+	}
+	if t, err := time.Parse(time.RFC3339, due); err == nil {
+		return &t, false, nil
+	}
+	t, err := time.Parse(evalDateLayout, due)
+	if err != nil {
+		return nil, false, fmt.Errorf("due %q is neither an RFC 3339 time nor a date", due)
+	}
+	return &t, true, nil
+}
+
+// EvalResult is how one case was parsed and scored
+type EvalResult struct {
+	Case          EvalCase      `json:"case"`
+	Actual        *ParsedTask   `json:"actual,omitempty"`
+	Confidence    float64       `json:"confidence"`
+	PromptVersion string        `json:"prompt_version,omitempty"`
+	Error         string        `json:"error,omitempty"`
+	Latency       time.Duration `json:"latency"`
+	TitleMatch    bool          `json:"title_match"`
+	DueMatch      bool          `json:"due_match"`
+	PriorityMatch bool          `json:"priority_match"`
+}
+
+// Correct reports whether every scored field matched
+func (r EvalResult) Correct() bool {
+	return r.TitleMatch && r.DueMatch && r.PriorityMatch
+}
+
+// EvalReport scores a provider over a dataset. Failed parses count as
+// misses on every field.
+type EvalReport struct {
+	Provider         string        `json:"provider"`
+	Cases            int           `json:"cases"`
+	Failures         int           `json:"failures"`
+	TitleAccuracy    float64       `json:"title_accuracy"`
+	DueAccuracy      float64       `json:"due_accuracy"`
+	PriorityAccuracy float64       `json:"priority_accuracy"`
+	Accuracy         float64       `json:"accuracy"` // cases with every field right
+	MeanLatency      time.Duration `json:"mean_latency"`
+	Results          []EvalResult  `json:"results"`
+}
+
+// Misses lists the results with at least one wrong field
+func (r *EvalReport) Misses() []EvalResult {
+	var misses []EvalResult
+	for _, result := range r.Results {
+		if !result.Correct() {
+			misses = append(misses, result)
+		}
+	}
+	return misses
+}
+
+// Evaluate runs each case through the provider the way the service parses a
+// message, with input and output guardrails, and scores the first parsed
+// task against the label
+func Evaluate(ctx context.Context, provider LLMProvider, cases []EvalCase, timeout time.Duration) *EvalReport {
+	report := &EvalReport{
+		Provider: provider.GetModelInfo().Name,
+		Cases:    len(cases),
+		Results:  make([]EvalResult, 0, len(cases)),
+	}
+
+	var titles, dues, priorities, correct int
+	var latency time.Duration
+	for _, c := range cases {
+		result := evaluateCase(ctx, provider, c, timeout)
+		report.Results = append(report.Results, result)
+
+		latency += result.Latency
+		if result.Error != "" {
+			report.Failures++
+		}
+		if result.TitleMatch {
+			titles++
+		}
+		if result.DueMatch {
+			dues++
+		}
+		if result.PriorityMatch {
+			priorities++
+		}
+		if result.Correct() {
+			correct++
+		}
+	}
+
+	if len(cases) > 0 {
+		total := float64(len(cases))
+		report.TitleAccuracy = float64(titles) / total
+		report.DueAccuracy = float64(dues) / total
+		report.PriorityAccuracy = float64(priorities) / total
+		report.Accuracy = float64(correct) / total
+		report.MeanLatency = latency / time.Duration(len(cases))
+	}
+	return report
+}
+
+// evaluateCase parses and scores one case
+func evaluateCase(ctx context.Context, provider LLMProvider, c EvalCase, timeout time.Duration) EvalResult {
+	result := EvalResult{Case: c}
+
+	text, err := SanitizeInput(c.Text)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	started := time.Now()
+	response, err := provider.ParseTask(ctx, ParseRequest{Text: text, UserID: "eval", Locale: c.Locale})
+	result.Latency = time.Since(started)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if err := ValidateParsedTask(response.ParsedTask); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	task := response.ParsedTask
+	result.Actual = &task
+	result.Confidence = response.Confidence
+	result.PromptVersion = response.PromptVersion
+
+	priority := task.Priority
+	if priority == "" {
+		priority = common.PriorityMedium
+	}
+	result.TitleMatch = normalizeEvalTitle(task.Title) == normalizeEvalTitle(c.Expected.Title)
+	result.DueMatch = dueMatches(c.Expected.Due, task.DueDate)
+	result.PriorityMatch = priority == c.Expected.Priority
+	return result
+}
+
+// normalizeEvalTitle ignores case, punctuation and spacing when comparing titles
+func normalizeEvalTitle(title string) string {
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	return strings.Join(words, " ")
+}
+
+// dueMatches compares a parsed due date with its label, to the minute or by
+// day when only the day is labeled
+func dueMatches(label string, actual *time.Time) bool {
+	expected, dayOnly, err := parseEvalDue(label)
+	if err != nil || expected == nil || actual == nil {
+		return err == nil && expected == nil && actual == nil
+	}
+	if dayOnly {
+		return actual.Format(evalDateLayout) == expected.Format(evalDateLayout)
+	}
+	return actual.Truncate(time.Minute).Equal(expected.Truncate(time.Minute))
+}
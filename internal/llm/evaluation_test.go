@@ -0,0 +1,95 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// answeringProvider answers every request with the same response
+type answeringProvider struct {
+	erroringProvider
+	response *LLMResponse
+}
+
+func (p *answeringProvider) ParseTask(ctx context.Context, req ParseRequest) (*LLMResponse, error) {
+	return p.response, nil
+}
+
+func TestLoadEvalCases(t *testing.T) {
+	cases, err := LoadEvalCases(strings.NewReader(`
+// comment
+{"text":"buy milk","expected":{"title":"Buy milk"}}
+{"id":"rent","text":"pay rent friday","expected":{"title":"Pay rent","due":"2024-01-12","priority":"high"}}
+`))
+	require.NoError(t, err)
+	require.Len(t, cases, 2)
+	assert.Equal(t, "line-3", cases[0].ID)
+	assert.Equal(t, common.PriorityMedium, cases[0].Expected.Priority)
+	assert.Equal(t, "rent", cases[1].ID)
+
+	_, err = LoadEvalCases(strings.NewReader(`{"text":"buy milk","expected":{"title":"Buy milk","due":"friday"}}`))
+	assert.ErrorContains(t, err, "line 1")
+	_, err = LoadEvalCases(strings.NewReader(`{"text":"buy milk","expected":{}}`))
+	assert.Error(t, err)
+}
+
+func TestEvaluate_ScoresFields(t *testing.T) {
+	due := time.Date(2024, 1, 11, 15, 0, 30, 0, time.UTC)
+	provider := &answeringProvider{response: &LLMResponse{
+		ParsedTask: ParsedTask{Title: "call  Sarah!", DueDate: &due, Priority: common.PriorityMedium},
+		Confidence: 0.9,
+	}}
+
+	cases := []EvalCase{
+		{ID: "exact", Text: "x", Expected: EvalExpected{Title: "Call Sarah", Due: "2024-01-11T15:00:00Z", Priority: common.PriorityMedium}},
+		{ID: "by-day", Text: "x", Expected: EvalExpected{Title: "Call Sarah", Due: "2024-01-11", Priority: common.PriorityMedium}},
+		{ID: "wrong", Text: "x", Expected: EvalExpected{Title: "Call Tom", Priority: common.PriorityHigh}},
+	}
+	report := Evaluate(context.Background(), provider, cases, time.Second)
+
+	assert.Equal(t, 3, report.Cases)
+	assert.Zero(t, report.Failures)
+	assert.InDelta(t, 2.0/3, report.TitleAccuracy, 1e-9)
+	assert.InDelta(t, 2.0/3, report.DueAccuracy, 1e-9)
+	assert.InDelta(t, 2.0/3, report.PriorityAccuracy, 1e-9)
+	assert.InDelta(t, 2.0/3, report.Accuracy, 1e-9)
+
+	misses := report.Misses()
+	require.Len(t, misses, 1)
+	assert.Equal(t, "wrong", misses[0].Case.ID)
+	assert.False(t, misses[0].DueMatch, "a due date where none is expected is a miss")
+}
+
+func TestEvaluate_FailuresAreMisses(t *testing.T) {
+	cases := []EvalCase{{ID: "down", Text: "buy milk", Expected: EvalExpected{Title: "Buy milk", Priority: common.PriorityMedium}}}
+	report := Evaluate(context.Background(), &erroringProvider{err: errors.New("service unavailable")}, cases, time.Second)
+
+	assert.Equal(t, 1, report.Failures)
+	assert.Zero(t, report.Accuracy)
+	assert.Equal(t, "service unavailable", report.Results[0].Error)
+	assert.False(t, report.Results[0].DueMatch)
+}
+
+func TestEvaluate_HeuristicOnSampleDataset(t *testing.T) {
+	file, err := os.Open("testdata/eval_dataset.jsonl")
+	require.NoError(t, err)
+	defer file.Close()
+	cases, err := LoadEvalCases(file)
+	require.NoError(t, err)
+
+	now := time.Date(2024, 1, 10, 10, 0, 0, 0, time.UTC)
+	report := Evaluate(context.Background(), NewHeuristicProvider(common.NewMockClock(now)), cases, time.Second)
+
+	assert.Equal(t, len(cases), report.Cases)
+	assert.Zero(t, report.Failures)
+	assert.GreaterOrEqual(t, report.Accuracy, 0.75, "the heuristic parser regressed on the sample dataset")
+}
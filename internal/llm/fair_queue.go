@@ -0,0 +1,222 @@
+package llm
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"nudgebot-api/internal/common"
+)
+
+// Defaults used when the queue is configured with non-positive values
+const (
+	DefaultMaxConcurrent = 4
+	DefaultQueueAging    = 5 * time.Second
+)
+
+// queueWaiter is one call waiting for a slot
+type queueWaiter struct {
+	userID     common.UserID
+	enqueuedAt time.Time
+	admit      chan struct{}
+}
+
+// FairQueue caps the LLM calls running at once and hands free slots out
+// fairly between users. Each slot a user is given pushes their next turn back
+// by aging. A waiting call's turn is the later of when it was queued and its
+// user's next turn, and the earliest turn goes first. A burst from one user
+// is spread out between other users' calls, and since turns never move later
+// once a call is queued, every call is eventually the oldest and none starves.
+type FairQueue struct {
+	maxConcurrent int
+	aging         time.Duration
+	now           func() time.Time
+
+	mu       sync.Mutex
+	running  int
+	inFlight map[common.UserID]int
+	waiting  map[common.UserID][]*queueWaiter
+	nextTurn map[common.UserID]time.Time
+
+	admitted  int64
+	abandoned int64
+	totalWait time.Duration
+	maxWait   time.Duration
+}
+
+// QueueMetrics is a snapshot of the LLM queue
+type QueueMetrics struct {
+	MaxConcurrent int    `json:"max_concurrent"`
+	Running       int    `json:"running"`
+	Waiting       int    `json:"waiting"`
+	WaitingUsers  int    `json:"waiting_users"`
+	Admitted      int64  `json:"admitted"`
+	Abandoned     int64  `json:"abandoned"` // calls whose context ended while waiting
+	AverageWait   string `json:"average_wait"`
+	MaxWait       string `json:"max_wait"`
+}
+
+// NewFairQueue creates a queue running at most maxConcurrent calls
+func NewFairQueue(maxConcurrent int, aging time.Duration) *FairQueue {
+	if maxConcurrent <= 0 {
+		maxConcurrent = DefaultMaxConcurrent
+	}
+	if aging <= 0 {
+		aging = DefaultQueueAging
+	}
+	return &FairQueue{
+		maxConcurrent: maxConcurrent,
+		aging:         aging,
+		now:           time.Now,
+		inFlight:      make(map[common.UserID]int),
+		waiting:       make(map[common.UserID][]*queueWaiter),
+		nextTurn:      make(map[common.UserID]time.Time),
+	}
+}
+
+// Acquire waits for a slot for the user's call. The returned release frees
+// the slot and must be called once the call is done.
+func (q *FairQueue) Acquire(ctx context.Context, userID common.UserID) (func(), error) {
+	q.mu.Lock()
+	waiter := &queueWaiter{userID: userID, enqueuedAt: q.now(), admit: make(chan struct{})}
+	q.waiting[userID] = append(q.waiting[userID], waiter)
+	q.dispatch()
+	q.mu.Unlock()
+
+	select {
+	case <-waiter.admit:
+		return q.releaser(userID), nil
+	case <-ctx.Done():
+		q.mu.Lock()
+		defer q.mu.Unlock()
+		select {
+		case <-waiter.admit:
+			// Admitted while giving up, so the slot goes to the next call
+			q.release(userID)
+		default:
+			q.remove(waiter)
+		}
+		q.abandoned++
+		return nil, ctx.Err()
+	}
+}
+
+// Metrics returns the queue depth and wait times
+func (q *FairQueue) Metrics() QueueMetrics {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	metrics := QueueMetrics{
+		MaxConcurrent: q.maxConcurrent,
+		Running:       q.running,
+		WaitingUsers:  len(q.waiting),
+		Admitted:      q.admitted,
+		Abandoned:     q.abandoned,
+		AverageWait:   "0s",
+		MaxWait:       q.maxWait.String(),
+	}
+	for _, waiters := range q.waiting {
+		metrics.Waiting += len(waiters)
+	}
+	if q.admitted > 0 {
+		metrics.AverageWait = (q.totalWait / time.Duration(q.admitted)).String()
+	}
+	return metrics
+}
+
+// releaser returns a release func that frees the slot once
+func (q *FairQueue) releaser(userID common.UserID) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			q.release(userID)
+		})
+	}
+}
+
+// release frees a slot of the user and admits the next calls. The caller
+// holds q.mu.
+func (q *FairQueue) release(userID common.UserID) {
+	q.running--
+	if q.inFlight[userID]--; q.inFlight[userID] <= 0 {
+		delete(q.inFlight, userID)
+	}
+	q.forgetIdle(userID)
+	q.dispatch()
+}
+
+// dispatch admits the best ranked waiting calls while slots are free. The
+// caller holds q.mu.
+func (q *FairQueue) dispatch() {
+	for q.running < q.maxConcurrent {
+		next := q.next()
+		if next == nil {
+			return
+		}
+		turn := q.turn(next)
+		q.remove(next)
+
+		waited := q.now().Sub(next.enqueuedAt)
+		q.running++
+		q.inFlight[next.userID]++
+		q.nextTurn[next.userID] = turn.Add(q.aging)
+		q.admitted++
+		q.totalWait += waited
+		if waited > q.maxWait {
+			q.maxWait = waited
+		}
+		close(next.admit)
+	}
+}
+
+// next returns the waiting call to admit next: among the oldest call of each
+// user, the one with the earliest turn, the earliest queued on a tie
+func (q *FairQueue) next() *queueWaiter {
+	var best *queueWaiter
+	var bestTurn time.Time
+	for _, waiters := range q.waiting {
+		head := waiters[0]
+		turn := q.turn(head)
+		if best == nil || turn.Before(bestTurn) || (turn.Equal(bestTurn) && head.enqueuedAt.Before(best.enqueuedAt)) {
+			best, bestTurn = head, turn
+		}
+	}
+	return best
+}
+
+// turn returns when a waiting call's turn is: when it was queued, unless its
+// user's next turn is later. The caller holds q.mu.
+func (q *FairQueue) turn(waiter *queueWaiter) time.Time {
+	if next := q.nextTurn[waiter.userID]; next.After(waiter.enqueuedAt) {
+		return next
+	}
+	return waiter.enqueuedAt
+}
+
+// remove drops a call from its user's waiting list. The caller holds q.mu.
+func (q *FairQueue) remove(waiter *queueWaiter) {
+	waiters := q.waiting[waiter.userID]
+	for i, candidate := range waiters {
+		if candidate == waiter {
+			waiters = append(waiters[:i], waiters[i+1:]...)
+			break
+		}
+	}
+	if len(waiters) == 0 {
+		delete(q.waiting, waiter.userID)
+		q.forgetIdle(waiter.userID)
+		return
+	}
+	q.waiting[waiter.userID] = waiters
+}
+
+// forgetIdle drops the next turn of a user with no calls running or waiting
+// once it has passed, as it no longer holds their calls back. The caller
+// holds q.mu.
+func (q *FairQueue) forgetIdle(userID common.UserID) {
+	if q.inFlight[userID] == 0 && len(q.waiting[userID]) == 0 && !q.nextTurn[userID].After(q.now()) {
+		delete(q.nextTurn, userID)
+	}
+}
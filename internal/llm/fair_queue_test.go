@@ -0,0 +1,114 @@
+package llm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// queueClock is a manual clock for the fair queue
+type queueClock struct{ now time.Time }
+
+func (c *queueClock) advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestQueue(maxConcurrent int) (*FairQueue, *queueClock) {
+	clock := &queueClock{now: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)}
+	queue := NewFairQueue(maxConcurrent, 5*time.Second)
+	queue.now = func() time.Time { return clock.now }
+	return queue, clock
+}
+
+// enqueue starts an Acquire for the user and returns a channel receiving its
+// release once admitted
+func enqueue(t *testing.T, queue *FairQueue, userID common.UserID) <-chan func() {
+	admitted := make(chan func(), 1)
+	before := queue.Metrics().Waiting + queue.Metrics().Running
+	go func() {
+		release, err := queue.Acquire(context.Background(), userID)
+		if err == nil {
+			admitted <- release
+		}
+	}()
+	require.Eventually(t, func() bool {
+		metrics := queue.Metrics()
+		return metrics.Waiting+metrics.Running > before
+	}, time.Second, time.Millisecond)
+	return admitted
+}
+
+func TestFairQueue_SharesSlotsBetweenUsers(t *testing.T) {
+	queue, clock := newTestQueue(1)
+
+	burst := make([]<-chan func(), 3)
+	for i := range burst {
+		burst[i] = enqueue(t, queue, "alice")
+		clock.advance(time.Second)
+	}
+	bob := enqueue(t, queue, "bob")
+
+	metrics := queue.Metrics()
+	assert.Equal(t, 1, metrics.Running)
+	assert.Equal(t, 3, metrics.Waiting)
+	assert.Equal(t, 2, metrics.WaitingUsers)
+
+	// Alice's first call holds the only slot; Bob goes before her next ones
+	release := <-burst[0]
+	clock.advance(time.Second)
+	release()
+	release = <-bob
+	assert.Empty(t, burst[1])
+
+	release()
+	release = <-burst[1]
+	release()
+	release = <-burst[2]
+	release()
+
+	metrics = queue.Metrics()
+	assert.Equal(t, 0, metrics.Running)
+	assert.Equal(t, 0, metrics.Waiting)
+	assert.Equal(t, int64(4), metrics.Admitted)
+	assert.Equal(t, "3s", metrics.MaxWait)
+}
+
+func TestFairQueue_OldCallsAreNotStarved(t *testing.T) {
+	queue, clock := newTestQueue(1)
+
+	release := <-enqueue(t, queue, "alice")
+	waiting := enqueue(t, queue, "alice")
+
+	// A newcomer only goes first while Alice's turn is still ahead
+	clock.advance(10 * time.Second)
+	late := enqueue(t, queue, "bob")
+	release()
+
+	release = <-waiting
+	assert.Empty(t, late)
+	release()
+	<-late
+}
+
+func TestFairQueue_AbandonedCallsLeaveTheQueue(t *testing.T) {
+	queue, _ := newTestQueue(1)
+
+	release, err := queue.Acquire(context.Background(), "alice")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = queue.Acquire(ctx, "bob")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	metrics := queue.Metrics()
+	assert.Equal(t, 0, metrics.Waiting)
+	assert.Equal(t, int64(1), metrics.Abandoned)
+
+	release()
+	release() // releasing twice frees the slot once
+	assert.Equal(t, 0, queue.Metrics().Running)
+}
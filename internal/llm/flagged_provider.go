@@ -0,0 +1,59 @@
+package llm
+
+import (
+	"context"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/featureflags"
+)
+
+// flaggedProvider moves users to another provider behind the
+// llm_provider_switch feature flag, so a new model can be rolled out to a
+// cohort before everyone
+type flaggedProvider struct {
+	current  LLMProvider
+	switched LLMProvider
+	flags    featureflags.Checker
+}
+
+// NewFlaggedProvider creates a provider that serves users the
+// llm_provider_switch flag is on for with switched, and everyone else with
+// current
+func NewFlaggedProvider(current, switched LLMProvider, flags featureflags.Checker) LLMProvider {
+	return &flaggedProvider{
+		current:  current,
+		switched: switched,
+		flags:    flags,
+	}
+}
+
+// ParseTask implements the LLMProvider interface
+func (p *flaggedProvider) ParseTask(ctx context.Context, req ParseRequest) (*LLMResponse, error) {
+	return p.providerFor(req.UserID).ParseTask(ctx, req)
+}
+
+// ValidateConnection implements the LLMProvider interface
+func (p *flaggedProvider) ValidateConnection(ctx context.Context) error {
+	return p.current.ValidateConnection(ctx)
+}
+
+// GetModelInfo implements the LLMProvider interface
+func (p *flaggedProvider) GetModelInfo() ModelInfo {
+	return p.current.GetModelInfo()
+}
+
+// StreamText implements the StreamingProvider interface with the user's provider
+func (p *flaggedProvider) StreamText(ctx context.Context, req TextRequest, onText func(text string)) (string, error) {
+	streamer, ok := streamerOf(p.providerFor(req.UserID))
+	if !ok {
+		return "", ErrStreamingUnsupported
+	}
+	return streamer.StreamText(ctx, req, onText)
+}
+
+func (p *flaggedProvider) providerFor(userID common.UserID) LLMProvider {
+	if p.flags.IsEnabled(featureflags.FlagLLMProviderSwitch, userID) {
+		return p.switched
+	}
+	return p.current
+}
@@ -0,0 +1,25 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/featureflags"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlaggedProvider_SwitchesFlaggedUsers(t *testing.T) {
+	flags := featureflags.CheckerFunc(func(flag string, userID common.UserID) bool {
+		return flag == featureflags.FlagLLMProviderSwitch && userID == "early-adopter"
+	})
+	provider := NewFlaggedProvider(&erroringProvider{err: errors.New("current")}, &erroringProvider{err: errors.New("switched")}, flags)
+
+	_, err := provider.ParseTask(context.Background(), ParseRequest{Text: "buy milk", UserID: "early-adopter"})
+	assert.EqualError(t, err, "switched")
+
+	_, err = provider.ParseTask(context.Background(), ParseRequest{Text: "buy milk", UserID: "someone"})
+	assert.EqualError(t, err, "current")
+}
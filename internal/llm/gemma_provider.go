@@ -1,6 +1,7 @@
 package llm
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -12,8 +13,8 @@ import (
 
 	"nudgebot-api/internal/common"
 	"nudgebot-api/internal/config"
+	"nudgebot-api/internal/httpclient"
 
-	"github.com/cenkalti/backoff/v4"
 	"go.uber.org/zap"
 )
 
@@ -21,8 +22,8 @@ import (
 type GemmaProvider struct {
 	config     config.LLMConfig
 	logger     *zap.Logger
-	httpClient *http.Client
-	backoff    backoff.BackOff
+	httpClient httpclient.Doer
+	prompts    *PromptStore
 }
 
 // GemmaRequest represents the request structure for Gemma API
@@ -83,28 +84,37 @@ type GemmaError struct {
 	Status  string `json:"status"`
 }
 
-// NewGemmaProvider creates a new GemmaProvider instance
-func NewGemmaProvider(config config.LLMConfig, logger *zap.Logger) *GemmaProvider {
-	// Create HTTP client with timeout
-	httpClient := &http.Client{
-		Timeout: time.Duration(config.Timeout) * time.Second,
-	}
+// gemmaSafetySettings block harmful content in every request
+var gemmaSafetySettings = []GemmaSafetySetting{
+	{Category: "HARM_CATEGORY_HARASSMENT", Threshold: "BLOCK_MEDIUM_AND_ABOVE"},
+	{Category: "HARM_CATEGORY_HATE_SPEECH", Threshold: "BLOCK_MEDIUM_AND_ABOVE"},
+	{Category: "HARM_CATEGORY_SEXUALLY_EXPLICIT", Threshold: "BLOCK_MEDIUM_AND_ABOVE"},
+	{Category: "HARM_CATEGORY_DANGEROUS_CONTENT", Threshold: "BLOCK_MEDIUM_AND_ABOVE"},
+}
 
-	// Create exponential backoff strategy
-	backoffStrategy := backoff.NewExponentialBackOff()
-	backoffStrategy.InitialInterval = 1 * time.Second
-	backoffStrategy.MaxInterval = 30 * time.Second
-	backoffStrategy.MaxElapsedTime = 2 * time.Minute
-	backoffStrategy.Multiplier = 2.0
+// NewGemmaProvider creates a new GemmaProvider instance using the built-in prompts
+func NewGemmaProvider(config config.LLMConfig, logger *zap.Logger) *GemmaProvider {
+	return NewGemmaProviderWithPrompts(config, builtinPromptStore(), logger)
+}
 
-	// Limit retries based on config
-	backoffWithRetry := backoff.WithMaxRetries(backoffStrategy, uint64(config.MaxRetries))
+// NewGemmaProviderWithPrompts creates a GemmaProvider that renders its prompts
+// from the store
+func NewGemmaProviderWithPrompts(config config.LLMConfig, prompts *PromptStore, logger *zap.Logger) *GemmaProvider {
+	httpClient := httpclient.New("llm", httpclient.Options{
+		Timeout:    time.Duration(config.Timeout) * time.Second,
+		MaxRetries: config.MaxRetries,
+	}, nil, logger)
+	return NewGemmaProviderWithClient(config, prompts, httpClient, logger)
+}
 
+// NewGemmaProviderWithClient creates a GemmaProvider that sends its requests
+// through httpClient, which is responsible for timeouts and retries
+func NewGemmaProviderWithClient(config config.LLMConfig, prompts *PromptStore, httpClient httpclient.Doer, logger *zap.Logger) *GemmaProvider {
 	return &GemmaProvider{
 		config:     config,
 		logger:     logger,
 		httpClient: httpClient,
-		backoff:    backoffWithRetry,
+		prompts:    prompts,
 	}
 }
 
@@ -120,7 +130,11 @@ func (p *GemmaProvider) ParseTask(ctx context.Context, req ParseRequest) (*LLMRe
 	}
 
 	// Build the prompt
-	prompt := p.buildPrompt(req)
+	prompt, promptVersion, err := p.buildPrompt(req)
+	if err != nil {
+		return nil, NewExtendedParseError(ParseErrorCodeInvalidInput, "Failed to build prompt", err.Error(), false)
+	}
+	recordPrompt(ctx, p.config.Model, promptVersion, prompt)
 
 	// Create the request
 	gemmaReq := GemmaRequest{
@@ -138,34 +152,11 @@ func (p *GemmaProvider) ParseTask(ctx context.Context, req ParseRequest) (*LLMRe
 			TopP:            0.95,
 			MaxOutputTokens: 1024,
 		},
-		SafetySettings: []GemmaSafetySetting{
-			{Category: "HARM_CATEGORY_HARASSMENT", Threshold: "BLOCK_MEDIUM_AND_ABOVE"},
-			{Category: "HARM_CATEGORY_HATE_SPEECH", Threshold: "BLOCK_MEDIUM_AND_ABOVE"},
-			{Category: "HARM_CATEGORY_SEXUALLY_EXPLICIT", Threshold: "BLOCK_MEDIUM_AND_ABOVE"},
-			{Category: "HARM_CATEGORY_DANGEROUS_CONTENT", Threshold: "BLOCK_MEDIUM_AND_ABOVE"},
-		},
-	}
-
-	// Execute with retry logic
-	var response *LLMResponse
-	var err error
-
-	operation := func() error {
-		response, err = p.callAPI(ctx, gemmaReq)
-		if err != nil {
-			// Check if error is retryable
-			if IsRetryable(err) {
-				p.logger.Warn("Retryable error occurred, will retry",
-					zap.Error(err))
-				return err
-			}
-			// Non-retryable error, stop retrying
-			return backoff.Permanent(err)
-		}
-		return nil
+		SafetySettings: gemmaSafetySettings,
 	}
 
-	err = backoff.Retry(operation, backoff.WithContext(p.backoff, ctx))
+	// Rate limits and unavailability are retried by the HTTP client
+	response, err := p.callAPI(ctx, gemmaReq)
 	if err != nil {
 		p.logger.Error("Failed to parse task after retries",
 			zap.Error(err),
@@ -173,6 +164,7 @@ func (p *GemmaProvider) ParseTask(ctx context.Context, req ParseRequest) (*LLMRe
 		return nil, err
 	}
 
+	response.PromptVersion = promptVersion
 	return response, nil
 }
 
@@ -203,24 +195,107 @@ func (p *GemmaProvider) GetModelInfo() ModelInfo {
 			"task_parsing",
 			"json_output",
 			"natural_language_understanding",
+			CapabilityStreaming,
 		},
 		MaxTokens: 8192,
 	}
 }
 
-// buildPrompt creates a structured prompt for the Gemma API
-func (p *GemmaProvider) buildPrompt(req ParseRequest) string {
-	prompt := `You are a task parsing assistant. Parse the following natural language text into a structured task.
+// StreamText implements the StreamingProvider interface with the API's
+// server-sent events endpoint, calling onText with the text so far after
+// each chunk. Streams are not retried as their text has already been shown.
+func (p *GemmaProvider) StreamText(ctx context.Context, req TextRequest, onText func(text string)) (string, error) {
+	if p.config.APIKey == "" {
+		return "", NewConfigurationError("api_key", "API key is required", "Gemma API key must be configured")
+	}
+
+	requestBody, err := json.Marshal(GemmaRequest{
+		Contents: []GemmaContent{{Parts: []GemmaPart{{Text: req.Prompt}}, Role: "user"}},
+		GenerationConfig: GemmaGenerationConfig{
+			Temperature:     0.7, // conversational text rather than structured output
+			TopK:            40,
+			TopP:            0.95,
+			MaxOutputTokens: 1024,
+		},
+		SafetySettings: gemmaSafetySettings,
+	})
+	if err != nil {
+		return "", NewExtendedParseError(ParseErrorCodeInvalidInput, "Failed to marshal request", err.Error(), false)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.streamEndpoint(), bytes.NewBuffer(requestBody))
+	if err != nil {
+		return "", NewNetworkError("create_request", "Failed to create HTTP request", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-goog-api-key", p.config.APIKey)
+	// Parsing again has no side effects, so gateway errors are worth retrying
+	httpclient.MarkIdempotent(httpReq)
+
+	httpResp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return "", NewNetworkError("http_request", "Failed to make HTTP request", err)
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		responseBody, _ := io.ReadAll(httpResp.Body)
+		return "", p.handleHTTPError(httpResp.StatusCode, httpResp.Header, responseBody)
+	}
+
+	var text strings.Builder
+	scanner := bufio.NewScanner(httpResp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var chunk GemmaResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return text.String(), NewExtendedParseError(ParseErrorCodeInvalidInput, "Failed to parse stream chunk", err.Error(), false)
+		}
+		if chunk.Error != nil {
+			return text.String(), NewAPIError(chunk.Error.Code, chunk.Error.Status, chunk.Error.Message, data)
+		}
+		if len(chunk.Candidates) == 0 {
+			continue
+		}
+		for _, part := range chunk.Candidates[0].Content.Parts {
+			text.WriteString(part.Text)
+		}
+		onText(text.String())
+	}
+	if err := scanner.Err(); err != nil {
+		return text.String(), NewNetworkError("read_response", "Failed to read response stream", err)
+	}
+	return text.String(), nil
+}
 
-IMPORTANT: You must respond with valid JSON only, no other text or explanations.
+// streamEndpoint returns the streaming variant of the configured endpoint
+func (p *GemmaProvider) streamEndpoint() string {
+	endpoint := strings.Replace(p.config.APIEndpoint, ":generateContent", ":streamGenerateContent", 1)
+	if strings.Contains(endpoint, "?") {
+		return endpoint + "&alt=sse"
+	}
+	return endpoint + "?alt=sse"
+}
 
-The JSON must have this exact structure:
+// taskListSchemaPrompt describes the JSON task list every prompt asks the model to return
+const taskListSchemaPrompt = `The JSON must have this exact structure:
 {
-  "title": "clear, concise task title",
-  "description": "detailed description if available, empty string if not",
-  "due_date": "ISO 8601 date string if a date is mentioned, null if not",
-  "priority": "low|medium|high|urgent",
-  "tags": ["array", "of", "relevant", "tags"],
+  "tasks": [
+    {
+      "title": "clear, concise task title",
+      "description": "detailed description if available, empty string if not",
+      "due_date": "ISO 8601 date string if a date is mentioned, null if not",
+      "priority": "low|medium|high|urgent",
+      "tags": ["array", "of", "relevant", "tags"],
+      "habit": "daily|weekly for something to repeat as a habit (e.g. \"exercise daily\"), empty string if not",
+      "kind": "message when the user asks to be sent something at a time rather than to do it (e.g. \"send me the wifi password at 5pm\"; the title is then the text to send), empty string for a task",
+      "location": "a place the task involves, as the user named it (e.g. \"post office\"), empty string if none"
+    }
+  ],
   "confidence": 0.85,
   "reasoning": "brief explanation of parsing decisions"
 }
@@ -230,14 +305,43 @@ Priority guidelines:
 - "high": important, has deadline within days
 - "medium": normal task, may have loose deadline
 - "low": minor task, no urgency indicators
+`
+
+// buildPrompt renders the prompt for a request in the user's locale and
+// returns it with the ID of the template it came from
+func (p *GemmaProvider) buildPrompt(req ParseRequest) (string, string, error) {
+	data := PromptData{Schema: taskListSchemaPrompt, Locale: req.Locale}
+	if req.Week != nil {
+		data.WeekStart = req.Week.Start.String()
+		data.WorkingDays = req.Week.DescribeWorkingDays()
+	}
+	if len(req.Holidays) > 0 {
+		holidays := make([]string, len(req.Holidays))
+		for i, day := range req.Holidays {
+			holidays[i] = fmt.Sprintf("%s (%s)", day.Date.Format("2006-01-02"), day.Name)
+		}
+		data.Holidays = strings.Join(holidays, ", ")
+	}
 
-Extract tags from context, topics, or task categories mentioned.
-
-Text to parse: "` + req.Text + `"
-
-Respond with JSON only:`
+	// User text is JSON-encoded so quotes and newlines cannot break out of the delimited block
+	if req.IsBatch() {
+		quotedMessages, err := json.Marshal(req.Messages)
+		if err != nil {
+			quotedMessages = []byte(`[]`)
+		}
+		data.Messages = string(quotedMessages)
+		return p.prompts.Render(PromptParseBatch, req.Locale, data)
+	}
 
-	return prompt
+	quotedText, err := json.Marshal(req.Text)
+	if err != nil {
+		quotedText = []byte(`""`)
+	}
+	data.Text = string(quotedText)
+	if req.Summarize {
+		return p.prompts.Render(PromptSummarize, req.Locale, data)
+	}
+	return p.prompts.Render(PromptParse, req.Locale, data)
 }
 
 // callAPI makes the actual HTTP request to the Gemma API
@@ -262,6 +366,7 @@ func (p *GemmaProvider) callAPI(ctx context.Context, req GemmaRequest) (*LLMResp
 	// Set headers
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("x-goog-api-key", p.config.APIKey)
+	httpclient.MarkIdempotent(httpReq)
 
 	// Make the request
 	httpResp, err := p.httpClient.Do(httpReq)
@@ -275,10 +380,11 @@ func (p *GemmaProvider) callAPI(ctx context.Context, req GemmaRequest) (*LLMResp
 	if err != nil {
 		return nil, NewNetworkError("read_response", "Failed to read response body", err)
 	}
+	recordRawResponse(ctx, responseBody)
 
 	// Handle HTTP errors
 	if httpResp.StatusCode != http.StatusOK {
-		return nil, p.handleHTTPError(httpResp.StatusCode, responseBody)
+		return nil, p.handleHTTPError(httpResp.StatusCode, httpResp.Header, responseBody)
 	}
 
 	// Parse response
@@ -331,15 +437,12 @@ func (p *GemmaProvider) parseGemmaResponse(responseBody []byte) (*LLMResponse, e
 	responseText := candidate.Content.Parts[0].Text
 	jsonStr := p.extractJSON(responseText)
 
-	// Parse the extracted JSON
+	// Parse the extracted JSON; a single task object is accepted for older prompt formats
 	var taskData struct {
-		Title       string     `json:"title"`
-		Description string     `json:"description"`
-		DueDate     *time.Time `json:"due_date"`
-		Priority    string     `json:"priority"`
-		Tags        []string   `json:"tags"`
-		Confidence  float64    `json:"confidence"`
-		Reasoning   string     `json:"reasoning"`
+		gemmaTaskData
+		Tasks      []gemmaTaskData `json:"tasks"`
+		Confidence float64         `json:"confidence"`
+		Reasoning  string          `json:"reasoning"`
 	}
 
 	if err := json.Unmarshal([]byte(jsonStr), &taskData); err != nil {
@@ -351,9 +454,48 @@ func (p *GemmaProvider) parseGemmaResponse(responseBody []byte) (*LLMResponse, e
 		)
 	}
 
-	// Validate and convert priority
+	if len(taskData.Tasks) == 0 {
+		return &LLMResponse{
+			ParsedTask: taskData.toParsedTask(),
+			Confidence: taskData.Confidence,
+			Reasoning:  taskData.Reasoning,
+		}, nil
+	}
+
+	tasks := make([]ParsedTask, 0, len(taskData.Tasks))
+	for _, task := range taskData.Tasks {
+		tasks = append(tasks, task.toParsedTask())
+	}
+
+	// Create the response
+	response := &LLMResponse{
+		ParsedTask: tasks[0],
+		Confidence: taskData.Confidence,
+		Reasoning:  taskData.Reasoning,
+	}
+	if len(tasks) > 1 {
+		response.Tasks = tasks
+	}
+
+	return response, nil
+}
+
+// gemmaTaskData is the task shape the prompt asks the model to produce
+type gemmaTaskData struct {
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	DueDate     *time.Time `json:"due_date"`
+	Priority    string     `json:"priority"`
+	Tags        []string   `json:"tags"`
+	Habit       string     `json:"habit"`
+	Kind        string     `json:"kind"`
+	Location    string     `json:"location"`
+}
+
+// toParsedTask converts model output to a ParsedTask, defaulting unknown priorities to medium
+func (d gemmaTaskData) toParsedTask() ParsedTask {
 	var priority common.Priority
-	switch strings.ToLower(taskData.Priority) {
+	switch strings.ToLower(d.Priority) {
 	case "low":
 		priority = common.PriorityLow
 	case "medium":
@@ -366,20 +508,26 @@ func (p *GemmaProvider) parseGemmaResponse(responseBody []byte) (*LLMResponse, e
 		priority = common.PriorityMedium // Default fallback
 	}
 
-	// Create the response
-	response := &LLMResponse{
-		ParsedTask: ParsedTask{
-			Title:       taskData.Title,
-			Description: taskData.Description,
-			DueDate:     taskData.DueDate,
-			Priority:    priority,
-			Tags:        taskData.Tags,
-		},
-		Confidence: taskData.Confidence,
-		Reasoning:  taskData.Reasoning,
+	habit := strings.ToLower(strings.TrimSpace(d.Habit))
+	if habit != HabitDaily && habit != HabitWeekly {
+		habit = ""
 	}
 
-	return response, nil
+	kind := strings.ToLower(strings.TrimSpace(d.Kind))
+	if kind != KindMessage {
+		kind = ""
+	}
+
+	return ParsedTask{
+		Title:       d.Title,
+		Description: d.Description,
+		DueDate:     d.DueDate,
+		Priority:    priority,
+		Tags:        d.Tags,
+		Habit:       habit,
+		Kind:        kind,
+		Location:    strings.TrimSpace(d.Location),
+	}
 }
 
 // extractJSON extracts JSON from response text that might contain other content
@@ -408,7 +556,7 @@ func (p *GemmaProvider) extractJSON(text string) string {
 }
 
 // handleHTTPError creates appropriate error based on HTTP status code
-func (p *GemmaProvider) handleHTTPError(statusCode int, responseBody []byte) error {
+func (p *GemmaProvider) handleHTTPError(statusCode int, header http.Header, responseBody []byte) error {
 	var errorMsg string = "Unknown error"
 	var errorCode string = ErrorCodeUnknown
 
@@ -432,7 +580,9 @@ func (p *GemmaProvider) handleHTTPError(statusCode int, responseBody []byte) err
 		return NewAPIError(statusCode, ErrorCodeRequestTooLarge, "Request too large", errorMsg)
 	case http.StatusTooManyRequests:
 		retryAfter := 60 // Default to 60 seconds
-		// Try to parse Retry-After header would go here in a real implementation
+		if wait, ok := httpclient.ParseRetryAfter(header.Get("Retry-After")); ok {
+			retryAfter = int(wait.Round(time.Second) / time.Second)
+		}
 		return NewRateLimitError(retryAfter, errorMsg)
 	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
 		return NewAPIError(statusCode, ErrorCodeServiceUnavailable, "Service unavailable", errorMsg)
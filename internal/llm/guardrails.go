@@ -0,0 +1,191 @@
+package llm
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Guardrail limits applied to text flowing into and out of the LLM
+const (
+	MaxInputLength       = 2000
+	MaxTitleLength       = 255
+	MaxDescriptionLength = 2000
+	MaxTagCount          = 10
+	MaxTagLength         = 50
+)
+
+// controlContentPatterns match instructions aimed at the model rather than task
+// content: attempts to override its instructions, role or system prompt
+var controlContentPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\b(ignore|disregard|forget|override)\b.{0,30}\b(previous|prior|above|earlier|all|system)\b.{0,20}\b(instructions?|prompts?|rules?|context)\b`),
+	regexp.MustCompile(`(?i)\b(new|updated|your)\s+(system|developer)\s+prompt\s*(is\b|:)`),
+	regexp.MustCompile(`(?i)\b(reveal|print|repeat|show)\s+(me\s+)?(your|the)\s+(system|developer)\s+prompt\b`),
+	regexp.MustCompile(`(?i)\byou\s+are\s+now\b`),
+	regexp.MustCompile(`(?i)</?\s*(system|assistant|instructions?)\s*>`),
+}
+
+// suspiciousContentPatterns match phrases that occur in injection attempts
+// but also in ordinary tasks; they are logged, not rejected
+var suspiciousContentPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\b(system|developer)\s+prompt\b`),
+	regexp.MustCompile(`(?i)\bact\s+as\s+(an?\s+)?(admin|administrator|developer|system|root)\b`),
+	regexp.MustCompile(`(?i)\b(output|print|reveal|dump|leak)\b.{0,30}\b(all\s+)?(user\s+data|credentials|api\s+keys?|secrets?|passwords?)\b`),
+}
+
+// SanitizeInput normalizes user text before it is embedded in a prompt.
+// Control characters are stripped, whitespace is collapsed and the result
+// is rejected if it is empty or exceeds MaxInputLength.
+func SanitizeInput(text string) (string, error) {
+	if !utf8.ValidString(text) {
+		text = strings.ToValidUTF8(text, "")
+	}
+
+	cleaned := strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\t' {
+			return ' '
+		}
+		if unicode.IsControl(r) || unicode.Is(unicode.Cf, r) {
+			return -1
+		}
+		return r
+	}, text)
+	cleaned = strings.Join(strings.Fields(cleaned), " ")
+
+	if cleaned == "" {
+		return "", ParseError{
+			Code:    ParseErrorCodeInvalidInput,
+			Message: "Input text is empty",
+			Details: "Text must contain printable characters",
+		}
+	}
+
+	if utf8.RuneCountInString(cleaned) > MaxInputLength {
+		return "", ParseError{
+			Code:    ParseErrorCodeInvalidInput,
+			Message: "Input text is too long",
+			Details: fmt.Sprintf("Text cannot exceed %d characters", MaxInputLength),
+		}
+	}
+
+	return cleaned, nil
+}
+
+// ContainsControlContent reports whether text looks like an attempt to steer the model
+func ContainsControlContent(text string) bool {
+	for _, pattern := range controlContentPatterns {
+		if pattern.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// ContainsSuspiciousContent reports whether text has phrases that are worth
+// logging as a possible injection but are too common in real tasks to reject
+func ContainsSuspiciousContent(text string) bool {
+	for _, pattern := range suspiciousContentPatterns {
+		if pattern.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateParsedTask checks LLM output against the ParsedTask schema and length policy,
+// and rejects tasks that carry control content instead of a task description
+func ValidateParsedTask(task ParsedTask) error {
+	if strings.TrimSpace(task.Title) == "" {
+		return ParseError{
+			Code:    ParseErrorCodeMissingTitle,
+			Message: "Task title is required",
+			Details: "Every task must have a non-empty title",
+		}
+	}
+
+	if utf8.RuneCountInString(task.Title) > MaxTitleLength {
+		return ParseError{
+			Code:    ParseErrorCodeInvalidInput,
+			Message: "Task title is too long",
+			Details: fmt.Sprintf("Title cannot exceed %d characters", MaxTitleLength),
+		}
+	}
+
+	if utf8.RuneCountInString(task.Description) > MaxDescriptionLength {
+		return ParseError{
+			Code:    ParseErrorCodeInvalidInput,
+			Message: "Task description is too long",
+			Details: fmt.Sprintf("Description cannot exceed %d characters", MaxDescriptionLength),
+		}
+	}
+
+	if !task.Priority.IsValid() {
+		return ParseError{
+			Code:    ParseErrorCodeInvalidInput,
+			Message: "Invalid priority level",
+			Details: "Priority must be one of: low, medium, high, urgent",
+		}
+	}
+
+	if task.Habit != "" && task.Habit != HabitDaily && task.Habit != HabitWeekly {
+		return ParseError{
+			Code:    ParseErrorCodeInvalidInput,
+			Message: "Invalid habit period",
+			Details: "Habit must be daily, weekly or empty",
+		}
+	}
+
+	if task.Kind != "" && task.Kind != KindMessage {
+		return ParseError{
+			Code:    ParseErrorCodeInvalidInput,
+			Message: "Invalid task kind",
+			Details: "Kind must be message or empty",
+		}
+	}
+
+	if len(task.Tags) > MaxTagCount {
+		return ParseError{
+			Code:    ParseErrorCodeInvalidInput,
+			Message: "Too many tags",
+			Details: fmt.Sprintf("A task cannot have more than %d tags", MaxTagCount),
+		}
+	}
+
+	for _, tag := range task.Tags {
+		if tag == "" || utf8.RuneCountInString(tag) > MaxTagLength {
+			return ParseError{
+				Code:    ParseErrorCodeInvalidInput,
+				Message: "Invalid tag",
+				Details: fmt.Sprintf("Tags must be between 1 and %d characters", MaxTagLength),
+			}
+		}
+	}
+
+	fields := append([]string{task.Title, task.Description}, task.Tags...)
+	for _, field := range fields {
+		if hasControlCharacters(field) || ContainsControlContent(field) {
+			return ParseError{
+				Code:    ParseErrorCodeUnsafeContent,
+				Message: "Task contains disallowed content",
+				Details: "Parsed task contains instructions or control characters",
+			}
+		}
+	}
+
+	return nil
+}
+
+// hasControlCharacters reports whether text contains non-printable control runes
+func hasControlCharacters(text string) bool {
+	for _, r := range text {
+		if r == '\n' || r == '\t' {
+			continue
+		}
+		if unicode.IsControl(r) || unicode.Is(unicode.Cf, r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,476 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/holiday"
+)
+
+// HeuristicConfidence is the confidence reported for rule-based parses
+const HeuristicConfidence = 0.3
+
+var (
+	hashtagPattern      = regexp.MustCompile(`#([\p{L}\p{N}_-]+)`)
+	isoDatePattern      = regexp.MustCompile(`\b(\d{4})-(\d{2})-(\d{2})\b`)
+	relativePattern     = regexp.MustCompile(`(?i)\bin\s+(\d+|an?|one)\s+(minutes?|mins?|hours?|hrs?|days?|weeks?)\b`)
+	dayWordPattern      = regexp.MustCompile(`(?i)\b(today|tonight|tomorrow)\b`)
+	businessDayPattern  = regexp.MustCompile(`(?i)\b(?:(?:by|on)\s+)?(?:the\s+)?next\s+(?:business|working|work)\s+day\b`)
+	weekWordPattern     = regexp.MustCompile(`(?i)\b(?:(?:by|before)\s+)?(?:the\s+)?(next\s+week|end\s+of\s+(?:the\s+)?week)\b`)
+	weekdayPattern      = regexp.MustCompile(`(?i)\b(?:(?:on|by|this|next)\s+)?(monday|tuesday|wednesday|thursday|friday|saturday|sunday|mon|tue|tues|wed|thu|thur|thurs|fri|sat|sun)\b`)
+	monthDayPattern     = regexp.MustCompile(`(?i)\b(?:on\s+|by\s+)?(jan|feb|mar|apr|may|jun|jul|aug|sep|sept|oct|nov|dec)[a-z]*\.?\s+(\d{1,2})(?:st|nd|rd|th)?\b`)
+	timeOfDayPattern    = regexp.MustCompile(`(?i)\b(?:at\s+)?(\d{1,2})(?::(\d{2}))?\s*(am|pm)\b|\bat\s+(\d{1,2}):(\d{2})\b`)
+	dailyHabitPattern   = regexp.MustCompile(`(?i)\b(daily|every\s*day|each\s+day)\b`)
+	weeklyHabitPattern  = regexp.MustCompile(`(?i)\b(weekly|every\s+week|each\s+week)\b`)
+	locationPattern     = regexp.MustCompile(`(?i)\b(?:at|from)\s+the\s+([\p{L}\p{N}' -]+?)\s*(?:$|[,.;:!?]|\b(?:to|and|for|before|after|by|on|with)\b)`)
+	sendMePattern       = regexp.MustCompile(`(?i)^\s*(?:please\s+)?(?:send|text|message)\s+me\s+`)
+	dueConnectorPattern = regexp.MustCompile(`(?i)\b(by|on|at|due|before)\s*$`)
+	extraSpacePattern   = regexp.MustCompile(`\s{2,}`)
+)
+
+// priorityKeywords maps phrases to priorities, checked in order
+var priorityKeywords = []struct {
+	pattern  *regexp.Regexp
+	priority common.Priority
+}{
+	{regexp.MustCompile(`(?i)\b(urgent|urgently|asap|critical|immediately|emergency)\b!*`), common.PriorityUrgent},
+	{regexp.MustCompile(`(?i)\b(high priority|important)\b!*`), common.PriorityHigh},
+	{regexp.MustCompile(`(?i)\b(low priority|whenever|someday|no rush)\b`), common.PriorityLow},
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wed": time.Wednesday, "thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday,
+	"fri": time.Friday, "sat": time.Saturday,
+}
+
+var months = map[string]time.Month{
+	"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April,
+	"may": time.May, "jun": time.June, "jul": time.July, "aug": time.August,
+	"sep": time.September, "sept": time.September, "oct": time.October,
+	"nov": time.November, "dec": time.December,
+}
+
+// HeuristicProvider is a rule-based LLMProvider used when the real model is unavailable
+type HeuristicProvider struct {
+	clock common.Clock
+}
+
+// NewHeuristicProvider creates a new HeuristicProvider instance
+func NewHeuristicProvider(clock common.Clock) *HeuristicProvider {
+	if clock == nil {
+		clock = common.NewRealClock()
+	}
+	return &HeuristicProvider{clock: clock}
+}
+
+// ParseTask implements the LLMProvider interface
+func (p *HeuristicProvider) ParseTask(ctx context.Context, req ParseRequest) (*LLMResponse, error) {
+	now := p.clock.Now()
+	week := common.DefaultWeek
+	if req.Week != nil {
+		week = *req.Week
+	}
+
+	texts := splitTaskList(req.Text)
+	if req.IsBatch() {
+		texts = req.Messages
+	}
+
+	tasks := make([]ParsedTask, 0, len(texts))
+	for _, text := range texts {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+		tasks = append(tasks, parseHeuristicTask(text, now, week, req.Holidays))
+	}
+
+	if len(tasks) == 0 {
+		return nil, ParseError{
+			Code:    ParseErrorCodeInvalidInput,
+			Message: "Input text is empty",
+			Details: "Heuristic parser requires non-empty text",
+		}
+	}
+
+	response := &LLMResponse{
+		ParsedTask: tasks[0],
+		Confidence: HeuristicConfidence,
+		Reasoning:  "Parsed with heuristic fallback rules because the language model was unavailable",
+	}
+	if len(tasks) > 1 {
+		response.Tasks = tasks
+	}
+
+	return response, nil
+}
+
+// splitTaskList splits a message listing several tasks, e.g. "buy milk, call mom, and pay rent".
+// Text without list separators is returned as a single item.
+func splitTaskList(text string) []string {
+	var parts []string
+	switch {
+	case strings.ContainsAny(text, ";\n"):
+		parts = strings.FieldsFunc(text, func(r rune) bool { return r == ';' || r == '\n' })
+	case strings.Contains(text, ","):
+		parts = strings.Split(text, ",")
+	default:
+		return []string{text}
+	}
+
+	items := make([]string, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		part = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(part, "and "), "And "))
+		if part != "" {
+			items = append(items, part)
+		}
+	}
+
+	if len(items) < 2 {
+		return []string{text}
+	}
+	return items
+}
+
+// parseHeuristicTask extracts tags, priority and due date from a single message
+func parseHeuristicTask(text string, now time.Time, week common.Week, holidays []holiday.Holiday) ParsedTask {
+	remaining := text
+
+	tags := extractTags(remaining)
+	remaining = hashtagPattern.ReplaceAllString(remaining, "")
+
+	priority := common.PriorityMedium
+	for _, keyword := range priorityKeywords {
+		if keyword.pattern.MatchString(remaining) {
+			priority = keyword.priority
+			remaining = keyword.pattern.ReplaceAllString(remaining, "")
+			break
+		}
+	}
+
+	habit := ""
+	switch {
+	case dailyHabitPattern.MatchString(remaining):
+		habit = HabitDaily
+		remaining = dailyHabitPattern.ReplaceAllString(remaining, "")
+	case weeklyHabitPattern.MatchString(remaining):
+		habit = HabitWeekly
+		remaining = weeklyHabitPattern.ReplaceAllString(remaining, "")
+	}
+
+	dueDate, remaining := extractDueDate(remaining, now, week, holidays)
+
+	// "send me X at 5pm" asks for X to be delivered, not for a task
+	kind := ""
+	if dueDate != nil && sendMePattern.MatchString(remaining) {
+		kind = KindMessage
+		remaining = sendMePattern.ReplaceAllString(remaining, "")
+	}
+
+	title := cleanTitle(remaining)
+	if title == "" {
+		title = cleanTitle(text)
+	}
+
+	return ParsedTask{
+		Title:    title,
+		DueDate:  dueDate,
+		Priority: priority,
+		Tags:     tags,
+		Habit:    habit,
+		Kind:     kind,
+		Location: extractLocation(remaining),
+	}
+}
+
+// extractLocation finds a place such as "at the post office" in text; the
+// place stays in the title, which reads better with it
+func extractLocation(text string) string {
+	match := locationPattern.FindStringSubmatch(text)
+	if match == nil {
+		return ""
+	}
+	return strings.TrimSpace(match[1])
+}
+
+// ValidateConnection implements the LLMProvider interface; the heuristic parser is always available
+func (p *HeuristicProvider) ValidateConnection(ctx context.Context) error {
+	return nil
+}
+
+// GetModelInfo implements the LLMProvider interface
+func (p *HeuristicProvider) GetModelInfo() ModelInfo {
+	return ModelInfo{
+		Name:         "heuristic",
+		Version:      "1.0",
+		Provider:     "builtin",
+		Capabilities: []string{"task_parsing", "date_extraction"},
+	}
+}
+
+// extractTags collects hashtags from the text
+func extractTags(text string) []string {
+	matches := hashtagPattern.FindAllStringSubmatch(text, -1)
+	tags := make([]string, 0, len(matches))
+	seen := make(map[string]bool)
+	for _, match := range matches {
+		tag := strings.ToLower(match[1])
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// extractDueDate finds the first recognizable date and time expression and
+// returns the resolved due date with the matched phrases removed from the text.
+// "next week" and "end of the week" follow the user's week, and "next business
+// day" skips their days off and holidays.
+func extractDueDate(text string, now time.Time, week common.Week, holidays []holiday.Holiday) (*time.Time, string) {
+	var date time.Time
+	found := false
+
+	if loc := relativePattern.FindStringSubmatchIndex(text); loc != nil {
+		match := relativePattern.FindStringSubmatch(text)
+		amount := 1
+		if n, err := strconv.Atoi(match[1]); err == nil {
+			amount = n
+		}
+		unit := strings.ToLower(match[2])
+		var d time.Duration
+		switch {
+		case strings.HasPrefix(unit, "min"):
+			d = time.Duration(amount) * time.Minute
+		case strings.HasPrefix(unit, "h"):
+			d = time.Duration(amount) * time.Hour
+		case strings.HasPrefix(unit, "day"):
+			d = time.Duration(amount) * 24 * time.Hour
+		default:
+			d = time.Duration(amount) * 7 * 24 * time.Hour
+		}
+		due := now.Add(d)
+		text = text[:loc[0]] + text[loc[1]:]
+		return &due, text
+	}
+
+	if match := isoDatePattern.FindStringSubmatch(text); match != nil {
+		if parsed, err := time.ParseInLocation("2006-01-02", match[0], now.Location()); err == nil {
+			date = parsed
+			found = true
+			text = strings.Replace(text, match[0], "", 1)
+		}
+	}
+
+	if !found {
+		if match := dayWordPattern.FindStringSubmatch(text); match != nil {
+			date = startOfDay(now)
+			switch strings.ToLower(match[1]) {
+			case "tomorrow":
+				date = date.AddDate(0, 0, 1)
+			case "tonight":
+				date = date.Add(20 * time.Hour)
+			}
+			found = true
+			text = strings.Replace(text, match[0], "", 1)
+		}
+	}
+
+	if !found {
+		if match := businessDayPattern.FindString(text); match != "" {
+			date = holiday.NextBusinessDay(now, week, holidays)
+			found = true
+			text = strings.Replace(text, match, "", 1)
+		}
+	}
+
+	if !found {
+		if match := weekWordPattern.FindStringSubmatch(text); match != nil {
+			if strings.HasPrefix(strings.ToLower(match[1]), "next") {
+				date = week.StartOf(now).AddDate(0, 0, 7)
+			} else {
+				date = week.LastWorkingDay(now)
+			}
+			found = true
+			text = strings.Replace(text, match[0], "", 1)
+		}
+	}
+
+	if !found {
+		if match := weekdayPattern.FindStringSubmatch(text); match != nil {
+			key := strings.ToLower(match[1])
+			if len(key) > 4 {
+				key = key[:3]
+			}
+			target := weekdays[key]
+			days := (int(target) - int(now.Weekday()) + 7) % 7
+			if days == 0 || strings.HasPrefix(strings.ToLower(match[0]), "next") {
+				days += 7
+			}
+			date = startOfDay(now).AddDate(0, 0, days)
+			found = true
+			text = strings.Replace(text, match[0], "", 1)
+		}
+	}
+
+	if !found {
+		if match := monthDayPattern.FindStringSubmatch(text); match != nil {
+			month := months[strings.ToLower(match[1])[:3]]
+			day, _ := strconv.Atoi(match[2])
+			date = time.Date(now.Year(), month, day, 0, 0, 0, 0, now.Location())
+			if date.Before(startOfDay(now)) {
+				date = date.AddDate(1, 0, 0)
+			}
+			found = true
+			text = strings.Replace(text, match[0], "", 1)
+		}
+	}
+
+	if match := timeOfDayPattern.FindStringSubmatch(text); match != nil {
+		hour, minute, ok := parseTimeOfDay(match)
+		if ok {
+			if !found {
+				date = startOfDay(now)
+				found = true
+				if time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, now.Location()).Before(now) {
+					date = date.AddDate(0, 0, 1)
+				}
+			}
+			date = time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, now.Location())
+			text = strings.Replace(text, match[0], "", 1)
+		}
+	} else if found && date.Hour() == 0 && date.Minute() == 0 {
+		// Date without a time defaults to the morning
+		date = date.Add(9 * time.Hour)
+	}
+
+	if !found {
+		return nil, text
+	}
+
+	return &date, text
+}
+
+// parseTimeOfDay converts a timeOfDayPattern match into hour and minute
+func parseTimeOfDay(match []string) (int, int, bool) {
+	if match[1] != "" {
+		hour, _ := strconv.Atoi(match[1])
+		minute := 0
+		if match[2] != "" {
+			minute, _ = strconv.Atoi(match[2])
+		}
+		if hour < 1 || hour > 12 || minute > 59 {
+			return 0, 0, false
+		}
+		meridiem := strings.ToLower(match[3])
+		if meridiem == "pm" && hour != 12 {
+			hour += 12
+		}
+		if meridiem == "am" && hour == 12 {
+			hour = 0
+		}
+		return hour, minute, true
+	}
+
+	hour, _ := strconv.Atoi(match[4])
+	minute, _ := strconv.Atoi(match[5])
+	if hour > 23 || minute > 59 {
+		return 0, 0, false
+	}
+	return hour, minute, true
+}
+
+// startOfDay truncates t to midnight in its location
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// cleanTitle tidies the leftover text into a task title
+func cleanTitle(text string) string {
+	title := extraSpacePattern.ReplaceAllString(strings.TrimSpace(text), " ")
+	title = strings.Trim(title, " ,.;:!-")
+	title = strings.TrimSpace(dueConnectorPattern.ReplaceAllString(title, ""))
+	title = strings.Trim(title, " ,.;:!-")
+
+	for _, prefix := range []string{"remind me to ", "remember to ", "i need to ", "i have to ", "don't forget to "} {
+		if strings.HasPrefix(strings.ToLower(title), prefix) {
+			title = title[len(prefix):]
+			break
+		}
+	}
+
+	if utf8Title := []rune(title); len(utf8Title) > 0 {
+		utf8Title[0] = unicode.ToUpper(utf8Title[0])
+		if len(utf8Title) > MaxTitleLength {
+			utf8Title = utf8Title[:MaxTitleLength]
+		}
+		title = string(utf8Title)
+	}
+
+	return title
+}
+
+// fallbackProvider delegates to a primary provider and falls back to a secondary one on failure
+type fallbackProvider struct {
+	primary    LLMProvider
+	fallback   LLMProvider
+	onFallback func(err error)
+}
+
+// NewFallbackProvider wraps primary so that provider errors and timeouts are served by fallback
+func NewFallbackProvider(primary, fallback LLMProvider, onFallback func(err error)) LLMProvider {
+	return &fallbackProvider{
+		primary:    primary,
+		fallback:   fallback,
+		onFallback: onFallback,
+	}
+}
+
+// ParseTask implements the LLMProvider interface
+func (p *fallbackProvider) ParseTask(ctx context.Context, req ParseRequest) (*LLMResponse, error) {
+	response, err := p.primary.ParseTask(ctx, req)
+	if err == nil {
+		return response, nil
+	}
+
+	if p.onFallback != nil {
+		p.onFallback(err)
+	}
+	recordFallback(ctx)
+
+	// The fallback runs with a fresh context since the primary may have exhausted the deadline
+	fallbackResponse, fallbackErr := p.fallback.ParseTask(context.Background(), req)
+	if fallbackErr != nil {
+		return nil, fmt.Errorf("fallback parser failed after primary error (%v): %w", err, fallbackErr)
+	}
+
+	return fallbackResponse, nil
+}
+
+// ValidateConnection implements the LLMProvider interface
+func (p *fallbackProvider) ValidateConnection(ctx context.Context) error {
+	return p.primary.ValidateConnection(ctx)
+}
+
+// GetModelInfo implements the LLMProvider interface
+func (p *fallbackProvider) GetModelInfo() ModelInfo {
+	return p.primary.GetModelInfo()
+}
+
+// StreamText implements the StreamingProvider interface with the primary
+// provider. The heuristic parser can't write free text, so there is no fallback.
+func (p *fallbackProvider) StreamText(ctx context.Context, req TextRequest, onText func(text string)) (string, error) {
+	streamer, ok := streamerOf(p.primary)
+	if !ok {
+		return "", ErrStreamingUnsupported
+	}
+	return streamer.StreamText(ctx, req, onText)
+}
@@ -0,0 +1,240 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/holiday"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeuristicProvider_ParseTask(t *testing.T) {
+	// Wednesday, 10:00
+	now := time.Date(2024, 1, 10, 10, 0, 0, 0, time.UTC)
+	provider := NewHeuristicProvider(common.NewMockClock(now))
+
+	tests := []struct {
+		name         string
+		text         string
+		wantTitle    string
+		wantPriority common.Priority
+		wantDue      *time.Time
+		wantTags     []string
+		wantHabit    string
+		wantKind     string
+		wantLocation string
+	}{
+		{
+			name:         "tomorrow with time",
+			text:         "call Sarah tomorrow at 3pm",
+			wantTitle:    "Call Sarah",
+			wantPriority: common.PriorityMedium,
+			wantDue:      timePtr(time.Date(2024, 1, 11, 15, 0, 0, 0, time.UTC)),
+		},
+		{
+			name:         "weekday with urgent keyword",
+			text:         "URGENT pay rent by Friday",
+			wantTitle:    "Pay rent",
+			wantPriority: common.PriorityUrgent,
+			wantDue:      timePtr(time.Date(2024, 1, 12, 9, 0, 0, 0, time.UTC)),
+		},
+		{
+			name:         "relative duration",
+			text:         "remind me to stretch in 2 hours",
+			wantTitle:    "Stretch",
+			wantPriority: common.PriorityMedium,
+			wantDue:      timePtr(now.Add(2 * time.Hour)),
+		},
+		{
+			name:         "iso date and hashtags",
+			text:         "submit report 2024-02-01 #work #Q1",
+			wantTitle:    "Submit report",
+			wantPriority: common.PriorityMedium,
+			wantDue:      timePtr(time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC)),
+			wantTags:     []string{"work", "q1"},
+		},
+		{
+			name:         "no date low priority",
+			text:         "clean garage someday",
+			wantTitle:    "Clean garage",
+			wantPriority: common.PriorityLow,
+		},
+		{
+			name:         "daily habit",
+			text:         "exercise every day",
+			wantTitle:    "Exercise",
+			wantPriority: common.PriorityMedium,
+			wantHabit:    HabitDaily,
+		},
+		{
+			name:         "weekly habit",
+			text:         "call grandma weekly",
+			wantTitle:    "Call grandma",
+			wantPriority: common.PriorityMedium,
+			wantHabit:    HabitWeekly,
+		},
+		{
+			name:         "message to send later",
+			text:         "send me the wifi password at 5pm",
+			wantTitle:    "The wifi password",
+			wantPriority: common.PriorityMedium,
+			wantDue:      timePtr(time.Date(2024, 1, 10, 17, 0, 0, 0, time.UTC)),
+			wantKind:     KindMessage,
+		},
+		{
+			name:         "place mentioned",
+			text:         "pick up package at the post office tomorrow",
+			wantTitle:    "Pick up package at the post office",
+			wantPriority: common.PriorityMedium,
+			wantDue:      timePtr(time.Date(2024, 1, 11, 9, 0, 0, 0, time.UTC)),
+			wantLocation: "post office",
+		},
+		{
+			name:         "sending is a task without a time",
+			text:         "send me the report",
+			wantTitle:    "Send me the report",
+			wantPriority: common.PriorityMedium,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := provider.ParseTask(context.Background(), ParseRequest{Text: tt.text, UserID: "user"})
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantTitle, response.ParsedTask.Title)
+			assert.Equal(t, tt.wantPriority, response.ParsedTask.Priority)
+			if tt.wantDue == nil {
+				assert.Nil(t, response.ParsedTask.DueDate)
+			} else {
+				require.NotNil(t, response.ParsedTask.DueDate)
+				assert.True(t, tt.wantDue.Equal(*response.ParsedTask.DueDate), "got %v", response.ParsedTask.DueDate)
+			}
+			if tt.wantTags != nil {
+				assert.Equal(t, tt.wantTags, response.ParsedTask.Tags)
+			}
+			assert.Equal(t, tt.wantHabit, response.ParsedTask.Habit)
+			assert.Equal(t, tt.wantKind, response.ParsedTask.Kind)
+			assert.Equal(t, tt.wantLocation, response.ParsedTask.Location)
+			assert.True(t, DefaultConfidenceThresholds().NeedsConfirmation(response.Confidence), "heuristic parses are confirmed")
+		})
+	}
+}
+
+func TestHeuristicProvider_FollowsUserWeek(t *testing.T) {
+	// Wednesday, 10:00
+	now := time.Date(2024, 1, 10, 10, 0, 0, 0, time.UTC)
+	provider := NewHeuristicProvider(common.NewMockClock(now))
+	gulf := common.NewWeek("sunday", "sun-thu")
+
+	tests := []struct {
+		text    string
+		week    *common.Week
+		wantDue time.Time
+	}{
+		{"plan the offsite next week", nil, time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)},
+		{"plan the offsite next week", &gulf, time.Date(2024, 1, 14, 9, 0, 0, 0, time.UTC)},
+		{"send the invoice by the end of the week", nil, time.Date(2024, 1, 12, 9, 0, 0, 0, time.UTC)},
+		{"send the invoice by the end of the week", &gulf, time.Date(2024, 1, 11, 9, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		response, err := provider.ParseTask(context.Background(), ParseRequest{Text: tt.text, UserID: "user", Week: tt.week})
+		require.NoError(t, err)
+		require.NotNil(t, response.ParsedTask.DueDate, tt.text)
+		assert.Equal(t, tt.wantDue, *response.ParsedTask.DueDate, tt.text)
+		assert.NotContains(t, response.ParsedTask.Title, "week")
+	}
+}
+
+func TestHeuristicProvider_NextBusinessDaySkipsHolidays(t *testing.T) {
+	// Tuesday Dec 24, 10:00
+	now := time.Date(2024, 12, 24, 10, 0, 0, 0, time.UTC)
+	provider := NewHeuristicProvider(common.NewMockClock(now))
+	holidays := []holiday.Holiday{
+		{Date: time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC), Name: "Christmas Day"},
+		{Date: time.Date(2024, 12, 26, 0, 0, 0, 0, time.UTC), Name: "Boxing Day"},
+	}
+
+	response, err := provider.ParseTask(context.Background(), ParseRequest{Text: "file the expense report next business day", UserID: "user", Holidays: holidays})
+	require.NoError(t, err)
+	require.NotNil(t, response.ParsedTask.DueDate)
+	assert.Equal(t, time.Date(2024, 12, 27, 9, 0, 0, 0, time.UTC), *response.ParsedTask.DueDate)
+	assert.Equal(t, "File the expense report", response.ParsedTask.Title)
+
+	response, err = provider.ParseTask(context.Background(), ParseRequest{Text: "file the expense report next working day", UserID: "user"})
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 12, 25, 9, 0, 0, 0, time.UTC), *response.ParsedTask.DueDate, "without a country no day is a holiday")
+}
+
+func TestFallbackProvider_UsesFallbackOnError(t *testing.T) {
+	primary := &erroringProvider{err: errors.New("service unavailable")}
+	var fallbackErr error
+	provider := NewFallbackProvider(primary, NewHeuristicProvider(nil), func(err error) { fallbackErr = err })
+
+	response, err := provider.ParseTask(context.Background(), ParseRequest{Text: "buy milk", UserID: "user"})
+	require.NoError(t, err)
+	assert.Equal(t, "Buy milk", response.ParsedTask.Title)
+	assert.Equal(t, HeuristicConfidence, response.Confidence)
+	assert.EqualError(t, fallbackErr, "service unavailable")
+}
+
+type erroringProvider struct {
+	err error
+}
+
+func (p *erroringProvider) ParseTask(ctx context.Context, req ParseRequest) (*LLMResponse, error) {
+	return nil, p.err
+}
+
+func (p *erroringProvider) ValidateConnection(ctx context.Context) error {
+	return p.err
+}
+
+func (p *erroringProvider) GetModelInfo() ModelInfo {
+	return ModelInfo{Name: "erroring"}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestHeuristicProvider_ParseTaskBatch(t *testing.T) {
+	provider := NewHeuristicProvider(nil)
+
+	response, err := provider.ParseTask(context.Background(), ParseRequest{
+		Text:     "buy eggs\ncall mom",
+		UserID:   "user",
+		Messages: []string{"buy eggs", " ", "call mom"},
+	})
+	require.NoError(t, err)
+
+	tasks := response.AllTasks()
+	require.Len(t, tasks, 2)
+	assert.Equal(t, "Buy eggs", tasks[0].Title)
+	assert.Equal(t, "Call mom", tasks[1].Title)
+}
+
+func TestHeuristicProvider_ParseTaskList(t *testing.T) {
+	now := time.Date(2024, 1, 10, 10, 0, 0, 0, time.UTC)
+	provider := NewHeuristicProvider(common.NewMockClock(now))
+
+	response, err := provider.ParseTask(context.Background(), ParseRequest{
+		Text:   "Buy milk, call mom, and pay rent by Friday",
+		UserID: "user",
+	})
+	require.NoError(t, err)
+
+	tasks := response.AllTasks()
+	require.Len(t, tasks, 3)
+	assert.Equal(t, "Buy milk", tasks[0].Title)
+	assert.Equal(t, "Call mom", tasks[1].Title)
+	assert.Equal(t, "Pay rent", tasks[2].Title)
+	require.NotNil(t, tasks[2].DueDate)
+	assert.Equal(t, time.Friday, tasks[2].DueDate.Weekday())
+}
@@ -0,0 +1,72 @@
+package llm
+
+import (
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/holiday"
+
+	"go.uber.org/zap"
+)
+
+// PrefsResolver finds the preferences a user chose
+type PrefsResolver interface {
+	UserPrefsFor(userID common.UserID) (UserPrefs, error)
+}
+
+// PrefsResolverFunc adapts a function to the PrefsResolver interface
+type PrefsResolverFunc func(userID common.UserID) (UserPrefs, error)
+
+// UserPrefsFor implements the PrefsResolver interface
+func (f PrefsResolverFunc) UserPrefsFor(userID common.UserID) (UserPrefs, error) {
+	return f(userID)
+}
+
+// holidayHorizon is how far ahead the parser is told about public holidays
+const holidayHorizon = 31 * 24 * time.Hour
+
+// prefsFor returns the preferences a user chose, empty when there are none
+func (s *llmService) prefsFor(log *zap.Logger, userID common.UserID) UserPrefs {
+	if s.prefs == nil {
+		return UserPrefs{}
+	}
+
+	prefs, err := s.prefs.UserPrefsFor(userID)
+	if err != nil {
+		log.Debug("No preferences for user, using the defaults", zap.Error(err))
+		return UserPrefs{}
+	}
+	return prefs
+}
+
+// locale returns the language to parse a user's messages in: the one they
+// chose, or their Telegram client's
+func (p UserPrefs) locale(clientLocale string) string {
+	if p.Language == "" {
+		return clientLocale
+	}
+	return p.Language
+}
+
+// week returns the week the user chose, nil when they kept the default
+func (p UserPrefs) week() *common.Week {
+	if p.WeekStart == "" && p.WorkingDays == "" {
+		return nil
+	}
+	week := common.NewWeek(p.WeekStart, p.WorkingDays)
+	return &week
+}
+
+// upcomingHolidays returns the public holidays of the user's country from
+// today, in their time zone, to holidayHorizon ahead
+func (s *llmService) upcomingHolidays(prefs UserPrefs, now time.Time) []holiday.Holiday {
+	if prefs.Country == "" {
+		return nil
+	}
+	location, err := time.LoadLocation(prefs.TimeZone)
+	if err != nil {
+		location = time.UTC
+	}
+	now = now.In(location)
+	return s.holidays.Between(prefs.Country, now, now.Add(holidayHorizon))
+}
@@ -0,0 +1,51 @@
+package llm
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/holiday"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestLLMService_PrefsFor(t *testing.T) {
+	service := &llmService{logger: zap.NewNop()}
+	assert.Equal(t, "en", service.prefsFor(service.logger, "user").locale("en"), "without prefs the client's language is used")
+
+	service.prefs = PrefsResolverFunc(func(userID common.UserID) (UserPrefs, error) {
+		switch userID {
+		case "chose":
+			return UserPrefs{Language: "vi", WeekStart: "sunday", WorkingDays: "sun,mon,tue,wed,thu"}, nil
+		case "unknown":
+			return UserPrefs{}, errors.New("record not found")
+		}
+		return UserPrefs{}, nil
+	})
+
+	assert.Equal(t, "vi", service.prefsFor(service.logger, "chose").locale("en"))
+	assert.Equal(t, "en", service.prefsFor(service.logger, "follows").locale("en"))
+	assert.Equal(t, "en", service.prefsFor(service.logger, "unknown").locale("en"))
+
+	week := service.prefsFor(service.logger, "chose").week()
+	require.NotNil(t, week)
+	assert.Equal(t, common.NewWeek("sunday", "sun-thu"), *week)
+	assert.Nil(t, service.prefsFor(service.logger, "follows").week(), "the default week adds no hint")
+}
+
+func TestLLMService_UpcomingHolidays(t *testing.T) {
+	service := &llmService{logger: zap.NewNop(), holidays: holiday.NewCalendar(holiday.BuiltinSource{}, zap.NewNop())}
+	now := time.Date(2024, 12, 20, 12, 0, 0, 0, time.UTC)
+
+	holidays := service.upcomingHolidays(UserPrefs{Country: "GB", TimeZone: "Europe/London"}, now)
+	require.Len(t, holidays, 3)
+	assert.Equal(t, "Christmas Day", holidays[0].Name)
+	assert.Equal(t, "Boxing Day", holidays[1].Name)
+	assert.Equal(t, "New Year's Day", holidays[2].Name)
+
+	assert.Empty(t, service.upcomingHolidays(UserPrefs{}, now), "users without a country observe no holidays")
+}
@@ -0,0 +1,246 @@
+package llm
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+
+	"go.uber.org/zap"
+)
+
+// Prompt template names
+const (
+	PromptParse      = "parse"
+	PromptParseBatch = "parse_batch"
+	PromptSummarize  = "summarize"
+)
+
+// builtinPrompts are the prompts the service ships with; templates in the
+// configured prompt directory add newer versions or replace them
+//
+//go:embed prompts/*.tmpl
+var builtinPrompts embed.FS
+
+// promptFileName matches "<name>.v<version>.tmpl" and the per-locale
+// variants "<name>.v<version>.<locale>.tmpl"
+var promptFileName = regexp.MustCompile(`^([a-z_]+)\.v([0-9]+)(?:\.([A-Za-z]{2,3}(?:-[A-Za-z0-9]+)?))?\.tmpl$`)
+
+// builtinPromptStore serves the built-in prompts to providers created without a store
+var builtinPromptStore = sync.OnceValue(func() *PromptStore {
+	store, err := NewPromptStore("", zap.NewNop())
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in prompts: %v", err))
+	}
+	return store
+})
+
+// PromptData is what prompt templates render. User text is JSON-encoded so
+// that quotes and newlines cannot break out of its delimited block.
+type PromptData struct {
+	Text     string // the message, as a JSON string
+	Messages string // the messages of a forwarded bundle, as a JSON array
+	Schema   string // the JSON shape the response must have
+	Locale   string // the user's language, empty when unknown
+
+	// WeekStart and WorkingDays describe the user's week, such as "Sunday"
+	// and "Sun–Thu"; empty for the default Monday to Friday
+	WeekStart   string
+	WorkingDays string
+
+	// Holidays lists the public holidays coming up for the user, such as
+	// "2024-12-25 (Christmas Day)"; empty when there are none
+	Holidays string
+}
+
+// PromptTemplate is one version of a prompt, for one locale or for any
+type PromptTemplate struct {
+	Name    string `json:"name"`
+	Version int    `json:"version"`
+	Locale  string `json:"locale,omitempty"`
+	Source  string `json:"source"` // "builtin" or the file it was loaded from
+
+	template *template.Template
+}
+
+// ID identifies the template a prompt was rendered from, such as
+// "parse@v2" or "parse@v2/vi"
+func (t *PromptTemplate) ID() string {
+	id := fmt.Sprintf("%s@v%d", t.Name, t.Version)
+	if t.Locale != "" {
+		id += "/" + t.Locale
+	}
+	return id
+}
+
+// PromptStore holds the prompt templates. Each prompt renders from its latest
+// version that has a default template, in the user's locale when that
+// version has a variant for it.
+type PromptStore struct {
+	dir    string
+	logger *zap.Logger
+
+	mu        sync.RWMutex
+	templates map[string][]*PromptTemplate // by name, latest version first
+}
+
+// NewPromptStore loads the built-in prompts and those in dir. An empty dir
+// uses the built-in prompts only.
+func NewPromptStore(dir string, logger *zap.Logger) (*PromptStore, error) {
+	store := &PromptStore{dir: dir, logger: logger}
+	if err := store.Reload(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Reload reads the templates again, so prompt changes apply without a
+// restart. The loaded templates are kept when any template is invalid.
+func (s *PromptStore) Reload() error {
+	templates := make(map[string]*PromptTemplate)
+
+	builtin, err := fs.Sub(builtinPrompts, "prompts")
+	if err != nil {
+		return fmt.Errorf("failed to open built-in prompts: %w", err)
+	}
+	if err := loadPromptTemplates(builtin, "builtin", templates); err != nil {
+		return err
+	}
+	if s.dir != "" {
+		if err := loadPromptTemplates(os.DirFS(s.dir), s.dir, templates); err != nil {
+			return err
+		}
+	}
+
+	byName := make(map[string][]*PromptTemplate)
+	for _, t := range templates {
+		byName[t.Name] = append(byName[t.Name], t)
+	}
+	for _, versions := range byName {
+		sort.Slice(versions, func(i, j int) bool {
+			if versions[i].Version != versions[j].Version {
+				return versions[i].Version > versions[j].Version
+			}
+			return versions[i].Locale < versions[j].Locale
+		})
+	}
+
+	s.mu.Lock()
+	s.templates = byName
+	s.mu.Unlock()
+
+	s.logger.Info("Loaded prompt templates",
+		zap.String("dir", s.dir),
+		zap.Int("templates", len(templates)))
+	return nil
+}
+
+// loadPromptTemplates parses the templates in fsys into templates, keyed by
+// name, version and locale so later sources replace earlier ones
+func loadPromptTemplates(fsys fs.FS, source string, templates map[string]*PromptTemplate) error {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return fmt.Errorf("failed to read prompts from %s: %w", source, err)
+	}
+
+	for _, entry := range entries {
+		match := promptFileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return fmt.Errorf("failed to read prompt %s: %w", entry.Name(), err)
+		}
+		parsed, err := template.New(entry.Name()).Option("missingkey=error").Parse(string(content))
+		if err != nil {
+			return fmt.Errorf("invalid prompt %s: %w", entry.Name(), err)
+		}
+
+		version, _ := strconv.Atoi(match[2])
+		t := &PromptTemplate{
+			Name:     match[1],
+			Version:  version,
+			Locale:   strings.ToLower(match[3]),
+			Source:   source,
+			template: parsed,
+		}
+		if source != "builtin" {
+			t.Source = filepath.Join(source, entry.Name())
+		}
+		templates[t.ID()] = t
+	}
+	return nil
+}
+
+// Render renders the latest version of a prompt for a locale such as "vi" or
+// "pt-BR", using the version's default when it has no variant for the locale.
+// It returns the prompt and the ID of the template it came from.
+func (s *PromptStore) Render(name, locale string, data PromptData) (string, string, error) {
+	t := s.template(name, locale)
+	if t == nil {
+		return "", "", fmt.Errorf("no prompt template named %q", name)
+	}
+
+	var prompt strings.Builder
+	if err := t.template.Execute(&prompt, data); err != nil {
+		return "", "", fmt.Errorf("failed to render prompt %s: %w", t.ID(), err)
+	}
+	return strings.TrimSpace(prompt.String()), t.ID(), nil
+}
+
+// template picks the template of a prompt for a locale from the latest
+// version with a default, trying the full locale, then its language, then
+// the default
+func (s *PromptStore) template(name, locale string) *PromptTemplate {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	versions := s.templates[name]
+	latest := -1
+	for _, t := range versions {
+		if t.Locale == "" {
+			latest = t.Version
+			break
+		}
+	}
+
+	locale = strings.ToLower(locale)
+	language, _, _ := strings.Cut(locale, "-")
+	for _, candidate := range []string{locale, language, ""} {
+		for _, t := range versions {
+			if t.Version == latest && t.Locale == candidate {
+				return t
+			}
+		}
+	}
+	return nil
+}
+
+// Templates lists the loaded templates, latest version first
+func (s *PromptStore) Templates() []PromptTemplate {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	names := make([]string, 0, len(s.templates))
+	for name := range s.templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var templates []PromptTemplate
+	for _, name := range names {
+		for _, t := range s.templates[name] {
+			templates = append(templates, *t)
+		}
+	}
+	return templates
+}
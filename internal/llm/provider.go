@@ -2,8 +2,18 @@ package llm
 
 import (
 	"context"
+	"errors"
+
+	"nudgebot-api/internal/common"
 )
 
+// CapabilityStreaming is listed in the model info of providers that stream
+// generated text
+const CapabilityStreaming = "streaming"
+
+// ErrStreamingUnsupported is returned when the provider can't stream text
+var ErrStreamingUnsupported = errors.New("streaming is not supported by the LLM provider")
+
 // LLMProvider defines the interface for LLM implementations
 type LLMProvider interface {
 	// ParseTask parses natural language text into a structured task
@@ -30,3 +40,39 @@ type ModelInfo struct {
 	Capabilities []string `json:"capabilities"` // List of supported capabilities
 	MaxTokens    int      `json:"max_tokens"`   // Maximum token limit
 }
+
+// TextRequest asks for free text, such as a plan, rather than a parsed task
+type TextRequest struct {
+	Prompt string
+	UserID common.UserID
+}
+
+// StreamingProvider is implemented by providers that can stream generated
+// text. Wrapping providers implement it too, so whether text can be streamed
+// is decided by the CapabilityStreaming flag in GetModelInfo.
+type StreamingProvider interface {
+	LLMProvider
+
+	// StreamText generates text for the prompt, calling onText with the text
+	// generated so far each time more arrives, and returns the full text
+	StreamText(ctx context.Context, req TextRequest, onText func(text string)) (string, error)
+}
+
+// HasCapability reports whether the model info lists the capability
+func (m ModelInfo) HasCapability(capability string) bool {
+	for _, c := range m.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// streamerOf returns the provider as a StreamingProvider when it can stream
+func streamerOf(provider LLMProvider) (StreamingProvider, bool) {
+	streamer, ok := provider.(StreamingProvider)
+	if !ok || !provider.GetModelInfo().HasCapability(CapabilityStreaming) {
+		return nil, false
+	}
+	return streamer, true
+}
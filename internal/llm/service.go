@@ -2,11 +2,18 @@ package llm
 
 import (
 	"context"
+	"fmt"
+	"sync/atomic"
 	"time"
 
+	"nudgebot-api/internal/chaos"
 	"nudgebot-api/internal/common"
 	"nudgebot-api/internal/config"
 	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/featureflags"
+	"nudgebot-api/internal/holiday"
+	"nudgebot-api/internal/httpclient"
+	"nudgebot-api/internal/tenant"
 
 	"go.uber.org/zap"
 )
@@ -16,6 +23,8 @@ type LLMService interface {
 	ParseTask(text string, userID common.UserID) (*LLMResponse, error)
 	ValidateTask(parsedTask ParsedTask) error
 	GetSuggestions(partialText string, userID common.UserID) ([]string, error)
+	Ready() <-chan struct{}
+	common.Service
 }
 
 // llmService implements the LLMService interface
@@ -23,17 +32,181 @@ type llmService struct {
 	eventBus events.EventBus
 	logger   *zap.Logger
 	provider LLMProvider
+	ready    *common.Readiness
+	stopped  atomic.Bool
+
+	// Thresholds decide which parses the user confirms; a user's own
+	// thresholds are looked up with overrides when set
+	thresholds ConfidenceThresholds
+	overrides  ThresholdResolver
+
+	// audit records each parse when set
+	audit AuditRepository
+
+	// queue caps concurrent provider calls and shares them fairly between
+	// users when set
+	queue *FairQueue
+
+	// prefs finds the language a user chose when set
+	prefs PrefsResolver
+
+	// holidays knows the public holidays of the users' countries when set
+	holidays *holiday.Calendar
 }
 
 // NewLLMService creates a new instance of LLMService
 func NewLLMService(eventBus events.EventBus, logger *zap.Logger, config config.LLMConfig) LLMService {
-	// Create Gemma provider
-	provider := NewGemmaProvider(config, logger)
+	return NewLLMServiceWithChaos(eventBus, logger, config, nil)
+}
+
+// NewLLMServiceWithChaos creates an LLMService whose primary provider fails at
+// the rate configured on the injector, exercising the heuristic fallback. A nil
+// injector disables injection.
+func NewLLMServiceWithChaos(eventBus events.EventBus, logger *zap.Logger, config config.LLMConfig, injector *chaos.Injector) LLMService {
+	return NewLLMServiceWithTenants(eventBus, logger, config, injector, nil, nil)
+}
+
+// NewLLMServiceWithTenants creates an LLMService that parses each tenant's
+// messages with the tenant's own API key and model when one is configured.
+// A nil resolver serves every user with the deployment's provider.
+func NewLLMServiceWithTenants(eventBus events.EventBus, logger *zap.Logger, cfg config.LLMConfig, injector *chaos.Injector, tenants []config.TenantConfig, resolver tenant.Resolver) LLMService {
+	return NewLLMServiceWithOverrides(eventBus, logger, cfg, injector, tenants, resolver, nil)
+}
+
+// NewLLMServiceWithOverrides creates an LLMService that grades each user's
+// parses with the confidence thresholds they have set, falling back to the
+// deployment's. A nil overrides uses the deployment's thresholds for everyone.
+func NewLLMServiceWithOverrides(eventBus events.EventBus, logger *zap.Logger, cfg config.LLMConfig, injector *chaos.Injector, tenants []config.TenantConfig, resolver tenant.Resolver, overrides ThresholdResolver) LLMService {
+	return NewLLMServiceWithAudit(eventBus, logger, cfg, injector, tenants, resolver, overrides, nil)
+}
+
+// NewLLMServiceWithAudit creates an LLMService that records every parse's
+// prompt, raw response and result in audit. A nil audit records nothing.
+func NewLLMServiceWithAudit(eventBus events.EventBus, logger *zap.Logger, cfg config.LLMConfig, injector *chaos.Injector, tenants []config.TenantConfig, resolver tenant.Resolver, overrides ThresholdResolver, audit AuditRepository) LLMService {
+	return NewLLMServiceWithPrompts(eventBus, logger, cfg, injector, tenants, resolver, overrides, audit, nil)
+}
+
+// NewLLMServiceWithPrompts creates an LLMService that renders its prompts from
+// the store, so they can be changed and reloaded without a restart. A nil
+// store uses the built-in prompts.
+func NewLLMServiceWithPrompts(eventBus events.EventBus, logger *zap.Logger, cfg config.LLMConfig, injector *chaos.Injector, tenants []config.TenantConfig, resolver tenant.Resolver, overrides ThresholdResolver, audit AuditRepository, prompts *PromptStore) LLMService {
+	return NewLLMServiceWithQueue(eventBus, logger, cfg, injector, tenants, resolver, overrides, audit, prompts, nil)
+}
+
+// NewLLMServiceWithQueue creates an LLMService whose provider calls wait in
+// the fair queue, so a burst from one chat can't take every slot. A nil queue
+// lets every call through at once.
+func NewLLMServiceWithQueue(eventBus events.EventBus, logger *zap.Logger, cfg config.LLMConfig, injector *chaos.Injector, tenants []config.TenantConfig, resolver tenant.Resolver, overrides ThresholdResolver, audit AuditRepository, prompts *PromptStore, queue *FairQueue) LLMService {
+	return NewLLMServiceWithPrefs(eventBus, logger, cfg, injector, tenants, resolver, overrides, audit, prompts, queue, nil)
+}
+
+// NewLLMServiceWithPrefs creates an LLMService that parses messages in the
+// language each user chose, falling back to their Telegram client's. A nil
+// prefs always uses the client's language.
+func NewLLMServiceWithPrefs(eventBus events.EventBus, logger *zap.Logger, cfg config.LLMConfig, injector *chaos.Injector, tenants []config.TenantConfig, resolver tenant.Resolver, overrides ThresholdResolver, audit AuditRepository, prompts *PromptStore, queue *FairQueue, prefs PrefsResolver) LLMService {
+	return NewLLMServiceWithHTTPClient(eventBus, logger, cfg, injector, tenants, resolver, overrides, audit, prompts, queue, prefs, nil)
+}
+
+// NewLLMServiceWithHTTPClient creates an LLMService whose provider calls go
+// through httpClient, shared by every tenant's provider. A nil client gives
+// each provider its own, retrying llm.max_retries times.
+func NewLLMServiceWithHTTPClient(eventBus events.EventBus, logger *zap.Logger, cfg config.LLMConfig, injector *chaos.Injector, tenants []config.TenantConfig, resolver tenant.Resolver, overrides ThresholdResolver, audit AuditRepository, prompts *PromptStore, queue *FairQueue, prefs PrefsResolver, httpClient httpclient.Doer) LLMService {
+	return NewLLMServiceWithHolidays(eventBus, logger, cfg, injector, tenants, resolver, overrides, audit, prompts, queue, prefs, httpClient, nil)
+}
+
+// NewLLMServiceWithHolidays creates an LLMService that tells the parser the
+// public holidays coming up in each user's country, so that "next business
+// day" skips them. A nil calendar knows no holidays.
+func NewLLMServiceWithHolidays(eventBus events.EventBus, logger *zap.Logger, cfg config.LLMConfig, injector *chaos.Injector, tenants []config.TenantConfig, resolver tenant.Resolver, overrides ThresholdResolver, audit AuditRepository, prompts *PromptStore, queue *FairQueue, prefs PrefsResolver, httpClient httpclient.Doer, holidays *holiday.Calendar) LLMService {
+	return NewLLMServiceWithFlags(eventBus, logger, cfg, injector, tenants, resolver, overrides, audit, prompts, queue, prefs, httpClient, holidays, nil)
+}
+
+// NewLLMServiceWithFlags creates an LLMService that parses with the provider
+// configured under cfg.Switch for users the llm_provider_switch feature flag
+// is on for. A nil checker, or no switch provider, keeps everyone on the main
+// provider.
+func NewLLMServiceWithFlags(eventBus events.EventBus, logger *zap.Logger, cfg config.LLMConfig, injector *chaos.Injector, tenants []config.TenantConfig, resolver tenant.Resolver, overrides ThresholdResolver, audit AuditRepository, prompts *PromptStore, queue *FairQueue, prefs PrefsResolver, httpClient httpclient.Doer, holidays *holiday.Calendar, flags featureflags.Checker) LLMService {
+	if prompts == nil {
+		prompts = builtinPromptStore()
+	}
+
+	thresholds, err := NewConfidenceThresholds(cfg)
+	if err != nil {
+		logger.Warn("Invalid confidence thresholds, using the defaults", zap.Error(err))
+		thresholds = DefaultConfidenceThresholds()
+	}
+
+	// Create Gemma providers with the heuristic parser as a fallback when they are unavailable
+	newProvider := func(providerConfig config.LLMConfig) LLMProvider {
+		var gemma *GemmaProvider
+		if httpClient != nil {
+			client := httpClient
+			// A shared client applies the deployment's limits, so providers
+			// with limits of their own get a copy applying theirs
+			if shared, ok := httpClient.(*httpclient.Client); ok && (providerConfig.Timeout != cfg.Timeout || providerConfig.MaxRetries != cfg.MaxRetries) {
+				client = shared.WithLimits(time.Duration(providerConfig.Timeout)*time.Second, providerConfig.MaxRetries)
+			}
+			gemma = NewGemmaProviderWithClient(providerConfig, prompts, client, logger)
+		} else {
+			gemma = NewGemmaProviderWithPrompts(providerConfig, prompts, logger)
+		}
+		primary := NewChaosProvider(gemma, injector)
+		return NewFallbackProvider(primary, NewHeuristicProvider(nil), func(err error) {
+			logger.Warn("LLM provider unavailable, using heuristic fallback parser", zap.Error(err))
+		})
+	}
+
+	provider := newProvider(cfg)
+	if flags != nil && cfg.Switch.IsSet() {
+		switchConfig := cfg
+		if cfg.Switch.APIEndpoint != "" {
+			switchConfig.APIEndpoint = cfg.Switch.APIEndpoint
+		}
+		if cfg.Switch.APIKey != "" {
+			switchConfig.APIKey = cfg.Switch.APIKey
+		}
+		if cfg.Switch.Model != "" {
+			switchConfig.Model = cfg.Switch.Model
+		}
+		provider = NewFlaggedProvider(provider, newProvider(switchConfig), flags)
+	}
+	if resolver != nil {
+		providers := make(map[string]LLMProvider)
+		for _, t := range tenants {
+			if t.LLMAPIKey == "" && t.LLMModel == "" && t.LLMTimeout == 0 && t.LLMMaxRetries == nil {
+				continue
+			}
+			tenantConfig := cfg
+			if t.LLMAPIKey != "" {
+				tenantConfig.APIKey = t.LLMAPIKey
+			}
+			if t.LLMModel != "" {
+				tenantConfig.Model = t.LLMModel
+			}
+			if t.LLMTimeout > 0 {
+				tenantConfig.Timeout = t.LLMTimeout
+			}
+			if t.LLMMaxRetries != nil {
+				tenantConfig.MaxRetries = *t.LLMMaxRetries
+			}
+			providers[t.ID] = newProvider(tenantConfig)
+		}
+		if len(providers) > 0 {
+			provider = NewTenantProvider(provider, providers, resolver, logger)
+		}
+	}
 
 	service := &llmService{
-		eventBus: eventBus,
-		logger:   logger,
-		provider: provider,
+		eventBus:   eventBus,
+		logger:     logger,
+		provider:   provider,
+		ready:      common.NewReadiness(),
+		thresholds: thresholds,
+		overrides:  overrides,
+		audit:      audit,
+		queue:      queue,
+		prefs:      prefs,
+		holidays:   holidays,
 	}
 
 	// Subscribe to relevant events
@@ -49,6 +222,42 @@ func (s *llmService) setupEventSubscriptions() {
 	if err != nil {
 		s.logger.Error("Failed to subscribe to MessageReceived events", zap.Error(err))
 	}
+
+	// Subscribe to TextGenerationRequested events for streamed text
+	err = s.eventBus.Subscribe(events.TopicTextGenerationRequested, s.handleTextGenerationRequested)
+	if err != nil {
+		s.logger.Error("Failed to subscribe to TextGenerationRequested events", zap.Error(err))
+	}
+
+	s.ready.MarkReady()
+}
+
+// Ready returns a channel that is closed once event subscriptions are registered
+func (s *llmService) Ready() <-chan struct{} {
+	return s.ready.Ready()
+}
+
+// Start marks the service as running; subscriptions are registered by the constructor
+func (s *llmService) Start(ctx context.Context) error {
+	s.stopped.Store(false)
+	return nil
+}
+
+// Stop marks the service as stopped
+func (s *llmService) Stop(ctx context.Context) error {
+	s.stopped.Store(true)
+	return nil
+}
+
+// Health reports whether the service is ready and has not been stopped
+func (s *llmService) Health() error {
+	if !s.ready.IsReady() {
+		return common.ErrServiceNotReady
+	}
+	if s.stopped.Load() {
+		return common.ErrServiceStopped
+	}
+	return nil
 }
 
 // ParseTask parses natural language text into a structured task
@@ -57,9 +266,14 @@ func (s *llmService) ParseTask(text string, userID common.UserID) (*LLMResponse,
 		zap.String("text", text),
 		zap.String("userID", string(userID)))
 
+	sanitized, err := SanitizeInput(text)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create parse request
 	parseRequest := ParseRequest{
-		Text:    text,
+		Text:    sanitized,
 		UserID:  userID,
 		Context: nil, // Context can be added later for conversation flow
 	}
@@ -69,37 +283,58 @@ func (s *llmService) ParseTask(text string, userID common.UserID) (*LLMResponse,
 	defer cancel()
 
 	// Delegate to provider
-	response, err := s.provider.ParseTask(ctx, parseRequest)
+	response, err := s.parse(ctx, "", parseRequest)
 	if err != nil {
 		s.logger.Error("Failed to parse task", zap.Error(err))
 		return nil, err
 	}
 
+	if err := s.ValidateTask(response.ParsedTask); err != nil {
+		s.logger.Warn("Parsed task rejected by guardrails", zap.Error(err))
+		return nil, err
+	}
+
 	return response, nil
 }
 
+// parse runs a request through the provider once the queue has a slot for
+// the user, recording the exchange when parses are audited
+func (s *llmService) parse(ctx context.Context, correlationID string, req ParseRequest) (*LLMResponse, error) {
+	if s.queue != nil {
+		release, err := s.queue.Acquire(ctx, req.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("waiting for LLM capacity: %w", err)
+		}
+		defer release()
+	}
+
+	if s.audit == nil {
+		return s.provider.ParseTask(ctx, req)
+	}
+
+	e := &exchange{}
+	response, err := s.provider.ParseTask(withExchange(ctx, e), req)
+	if auditErr := s.audit.SaveAudit(newParseAudit(correlationID, req.UserID, e, response, err)); auditErr != nil {
+		s.logger.Warn("Failed to save parse audit",
+			zap.String("correlation_id", correlationID),
+			zap.Error(auditErr))
+	}
+	return response, err
+}
+
 // ValidateTask validates a parsed task for completeness and correctness
 func (s *llmService) ValidateTask(parsedTask ParsedTask) error {
 	s.logger.Info("Validating task", zap.String("title", parsedTask.Title))
 
-	if parsedTask.Title == "" {
-		return ParseError{
-			Code:    ParseErrorCodeMissingTitle,
-			Message: "Task title is required",
-			Details: "Every task must have a non-empty title",
+	fields := append([]string{parsedTask.Title, parsedTask.Description}, parsedTask.Tags...)
+	for _, field := range fields {
+		if ContainsSuspiciousContent(field) {
+			s.logger.Warn("Parsed task contains possible prompt injection", zap.String("title", parsedTask.Title))
+			break
 		}
 	}
 
-	if !parsedTask.Priority.IsValid() {
-		return ParseError{
-			Code:    ParseErrorCodeInvalidInput,
-			Message: "Invalid priority level",
-			Details: "Priority must be one of: low, medium, high, urgent",
-		}
-	}
-
-	// TODO: Add more validation rules
-	return nil
+	return ValidateParsedTask(parsedTask)
 }
 
 // GetSuggestions provides auto-completion suggestions for partial text
@@ -121,54 +356,157 @@ func (s *llmService) GetSuggestions(partialText string, userID common.UserID) ([
 
 // handleMessageReceived handles MessageReceived events from the chatbot
 func (s *llmService) handleMessageReceived(event events.MessageReceived) {
-	s.logger.Info("Handling MessageReceived event",
-		zap.String("correlationID", event.CorrelationID),
-		zap.String("userID", event.UserID),
+	ctx := common.WithLogFlow(context.Background(), common.LogFlow{
+		UserID:        event.UserID,
+		ChatID:        event.ChatID,
+		CorrelationID: event.CorrelationID,
+	})
+	log := common.ContextLogger(ctx, s.logger)
+
+	log.Info("Handling MessageReceived event",
 		zap.String("messageText", event.MessageText))
 
+	sanitized, err := SanitizeInput(event.MessageText)
+	if err != nil {
+		log.Warn("Message rejected by input guardrails",
+			zap.Error(err))
+		s.publishParseFailed(log, event, events.ParseFailureRejected)
+		return
+	}
+
+	if ContainsControlContent(sanitized) || ContainsSuspiciousContent(sanitized) {
+		log.Warn("Message contains possible prompt injection")
+	}
+
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
 	// Create parse request
+	prefs := s.prefsFor(log, common.UserID(event.UserID))
 	parseRequest := ParseRequest{
-		Text:    event.MessageText,
-		UserID:  common.UserID(event.UserID),
-		Context: nil, // Context can be added later for conversation flow
+		Text:     sanitized,
+		UserID:   common.UserID(event.UserID),
+		Context:  nil, // Context can be added later for conversation flow
+		Locale:   prefs.locale(event.Locale),
+		Week:     prefs.week(),
+		Holidays: s.upcomingHolidays(prefs, time.Now()),
+	}
+
+	// Forwarded bundles are parsed together so related messages can be merged
+	if len(event.Messages) > 1 {
+		parseRequest.Messages = s.sanitizeMessages(event.Messages)
 	}
 
+	// Long messages such as pasted emails are summarized into a short task
+	parseRequest.Summarize = !parseRequest.IsBatch() && NeedsSummary(sanitized)
+
 	// Parse the message text into a task using the provider
-	response, err := s.provider.ParseTask(ctx, parseRequest)
+	response, err := s.parse(ctx, event.CorrelationID, parseRequest)
 	if err != nil {
-		s.logger.Error("Failed to parse task", zap.Error(err))
+		log.Error("Failed to parse task", zap.Error(err))
+		s.publishParseFailed(log, event, events.ParseFailureProvider)
 		return
 	}
 
-	// Validate the parsed task
-	if err := s.ValidateTask(response.ParsedTask); err != nil {
-		s.logger.Error("Task validation failed", zap.Error(err))
-		return
+	// Validate each parsed task and convert to events.ParsedTask format
+	var eventsParsedTasks []events.ParsedTask
+	for _, parsedTask := range response.AllTasks() {
+		if err := s.ValidateTask(parsedTask); err != nil {
+			log.Error("Task validation failed", zap.Error(err))
+			continue
+		}
+		if parseRequest.Summarize {
+			parsedTask = condenseSummary(parsedTask)
+		}
+
+		eventsParsedTasks = append(eventsParsedTasks, events.ParsedTask{
+			Title:       parsedTask.Title,
+			Description: parsedTask.Description,
+			DueDate:     parsedTask.DueDate,
+			Priority:    string(parsedTask.Priority),
+			Tags:        parsedTask.Tags,
+			Habit:       parsedTask.Habit,
+			Kind:        parsedTask.Kind,
+			Location:    parsedTask.Location,
+		})
 	}
 
-	// Convert to events.ParsedTask format
-	eventsParsedTask := events.ParsedTask{
-		Title:       response.ParsedTask.Title,
-		Description: response.ParsedTask.Description,
-		DueDate:     response.ParsedTask.DueDate,
-		Priority:    string(response.ParsedTask.Priority),
-		Tags:        response.ParsedTask.Tags,
+	if len(eventsParsedTasks) == 0 {
+		s.publishParseFailed(log, event, events.ParseFailureInvalid)
+		return
 	}
 
+	// Parses the user's thresholds do not trust are confirmed before saving
+	thresholds := s.thresholdsFor(log, common.UserID(event.UserID))
+	level := thresholds.Level(response.Confidence)
+	log.Debug("Graded parse confidence",
+		zap.Float64("confidence", response.Confidence),
+		zap.String("level", level),
+		zap.String("prompt_version", response.PromptVersion))
+
 	// Publish TaskParsed event
 	taskParsedEvent := events.TaskParsed{
-		Event:      events.NewEvent(),
-		UserID:     event.UserID,
-		ChatID:     event.ChatID, // Include ChatID from the original message
-		ParsedTask: eventsParsedTask,
+		Event:           events.NewEvent(),
+		UserID:          event.UserID,
+		ChatID:          event.ChatID, // Include ChatID from the original message
+		ParsedTask:      eventsParsedTasks[0],
+		Preview:         event.Preview || thresholds.NeedsConfirmation(response.Confidence),
+		ConfidenceLevel: level,
+		ListID:          event.ListID,
+		ThreadID:        event.ThreadID,
+	}
+	if parseRequest.Summarize {
+		taskParsedEvent.OriginalText = event.MessageText
 	}
+	if len(eventsParsedTasks) > 1 {
+		taskParsedEvent.ParsedTasks = eventsParsedTasks
+	}
+
+	if err := s.eventBus.Publish(events.TopicTaskParsed, taskParsedEvent); err != nil {
+		log.Error("Failed to publish TaskParsed event", zap.Error(err))
+	}
+}
 
-	err = s.eventBus.Publish(events.TopicTaskParsed, taskParsedEvent)
+// thresholdsFor returns the confidence thresholds that apply to a user
+func (s *llmService) thresholdsFor(log *zap.Logger, userID common.UserID) ConfidenceThresholds {
+	if s.overrides == nil {
+		return s.thresholds
+	}
+
+	override, err := s.overrides.ThresholdOverrideFor(userID)
 	if err != nil {
-		s.logger.Error("Failed to publish TaskParsed event", zap.Error(err))
+		log.Debug("No confidence thresholds for user, using the deployment's", zap.Error(err))
+		return s.thresholds
+	}
+	return override.Apply(s.thresholds)
+}
+
+// publishParseFailed tells the chat that a message yielded no task
+func (s *llmService) publishParseFailed(log *zap.Logger, event events.MessageReceived, reason string) {
+	failedEvent := events.TaskParseFailed{
+		Event:       events.NewEvent(),
+		UserID:      event.UserID,
+		ChatID:      event.ChatID,
+		MessageText: event.MessageText,
+		Reason:      reason,
+		ListID:      event.ListID,
+	}
+	if err := s.eventBus.Publish(events.TopicTaskParseFailed, failedEvent); err != nil {
+		log.Error("Failed to publish TaskParseFailed event", zap.Error(err))
+	}
+}
+
+// sanitizeMessages applies input guardrails to each bundled message, dropping rejected ones
+func (s *llmService) sanitizeMessages(messages []string) []string {
+	sanitized := make([]string, 0, len(messages))
+	for _, message := range messages {
+		cleaned, err := SanitizeInput(message)
+		if err != nil {
+			s.logger.Debug("Dropping bundled message rejected by input guardrails", zap.Error(err))
+			continue
+		}
+		sanitized = append(sanitized, cleaned)
 	}
+	return sanitized
 }
@@ -0,0 +1,77 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// textGenerationTimeout bounds a streamed generation, which runs longer than
+// a parse
+const textGenerationTimeout = 90 * time.Second
+
+// handleTextGenerationRequested streams text for the request, publishing a
+// TextGenerated event each time more arrives and a final one once it is done
+func (s *llmService) handleTextGenerationRequested(event events.TextGenerationRequested) {
+	ctx := common.WithLogFlow(context.Background(), common.LogFlow{
+		UserID:        event.UserID,
+		ChatID:        event.ChatID,
+		CorrelationID: event.CorrelationID,
+	})
+	log := common.ContextLogger(ctx, s.logger).With(zap.String("purpose", event.Purpose))
+
+	ctx, cancel := context.WithTimeout(ctx, textGenerationTimeout)
+	defer cancel()
+
+	text, err := s.streamText(ctx, TextRequest{Prompt: event.Prompt, UserID: common.UserID(event.UserID)}, func(text string) {
+		s.publishTextGenerated(log, event, events.TextGenerated{Text: text})
+	})
+	if err != nil {
+		log.Warn("Failed to generate text", zap.Error(err))
+		message := "The assistant couldn't write a reply right now. Please try again later."
+		if errors.Is(err, ErrStreamingUnsupported) {
+			message = "The configured assistant can't write replies like this one."
+		}
+		s.publishTextGenerated(log, event, events.TextGenerated{Text: text, Done: true, Message: message})
+		return
+	}
+	s.publishTextGenerated(log, event, events.TextGenerated{Text: text, Done: true, Success: true})
+}
+
+// streamText streams text from the provider once the queue has a slot for
+// the user
+func (s *llmService) streamText(ctx context.Context, req TextRequest, onText func(text string)) (string, error) {
+	streamer, ok := streamerOf(s.provider)
+	if !ok {
+		return "", ErrStreamingUnsupported
+	}
+
+	if s.queue != nil {
+		release, err := s.queue.Acquire(ctx, req.UserID)
+		if err != nil {
+			return "", fmt.Errorf("waiting for LLM capacity: %w", err)
+		}
+		defer release()
+	}
+	return streamer.StreamText(ctx, req, onText)
+}
+
+// publishTextGenerated publishes the progress of a request, addressed to the
+// request's message
+func (s *llmService) publishTextGenerated(log *zap.Logger, request events.TextGenerationRequested, generated events.TextGenerated) {
+	generated.Event = events.NewEvent()
+	generated.CorrelationID = request.CorrelationID
+	generated.UserID = request.UserID
+	generated.ChatID = request.ChatID
+	generated.MessageID = request.MessageID
+	generated.Purpose = request.Purpose
+	if err := s.eventBus.Publish(events.TopicTextGenerated, generated); err != nil {
+		log.Error("Failed to publish TextGenerated event", zap.Error(err))
+	}
+}
@@ -0,0 +1,99 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/config"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// streamingProvider streams its chunks one by one
+type streamingProvider struct {
+	erroringProvider
+	chunks []string
+}
+
+func (p *streamingProvider) GetModelInfo() ModelInfo {
+	return ModelInfo{Name: "streaming-test", Capabilities: []string{CapabilityStreaming}}
+}
+
+func (p *streamingProvider) StreamText(ctx context.Context, req TextRequest, onText func(text string)) (string, error) {
+	text := ""
+	for _, chunk := range p.chunks {
+		text += chunk
+		onText(text)
+	}
+	return text, nil
+}
+
+func TestGemmaProvider_StreamText(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.URL.Path, ":streamGenerateContent")
+		assert.Equal(t, "sse", r.URL.Query().Get("alt"))
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{"9:00 Write", " report\n", "10:30 Gym"} {
+			fmt.Fprintf(w, "data: {\"candidates\":[{\"content\":{\"parts\":[{\"text\":%q}]}}]}\n\n", chunk)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewGemmaProvider(config.LLMConfig{APIKey: "key", APIEndpoint: server.URL + "/models/gemma:generateContent"}, zap.NewNop())
+	streamer, ok := streamerOf(provider)
+	require.True(t, ok)
+
+	var updates []string
+	text, err := streamer.StreamText(context.Background(), TextRequest{Prompt: "Plan my day"}, func(text string) {
+		updates = append(updates, text)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "9:00 Write report\n10:30 Gym", text)
+	assert.Equal(t, []string{"9:00 Write", "9:00 Write report\n", "9:00 Write report\n10:30 Gym"}, updates)
+}
+
+func TestLLMService_StreamsGeneratedText(t *testing.T) {
+	bus := events.NewEventBus(zap.NewNop())
+	defer bus.Close()
+
+	service := &llmService{
+		eventBus: bus,
+		logger:   zap.NewNop(),
+		provider: NewFallbackProvider(&streamingProvider{chunks: []string{"9:00 ", "Write report"}}, NewHeuristicProvider(nil), nil),
+		ready:    common.NewReadiness(),
+		queue:    NewFairQueue(1, 0),
+	}
+
+	var generated []events.TextGenerated
+	require.NoError(t, bus.Subscribe(events.TopicTextGenerated, func(event events.TextGenerated) {
+		generated = append(generated, event)
+	}))
+
+	request := events.TextGenerationRequested{Event: events.NewEvent(), UserID: "user", ChatID: "42", MessageID: 7, Purpose: "plan", Prompt: "Plan my day"}
+	service.handleTextGenerationRequested(request)
+
+	require.Len(t, generated, 3)
+	assert.Equal(t, "9:00 ", generated[0].Text)
+	assert.False(t, generated[1].Done)
+	assert.Equal(t, 7, generated[2].MessageID)
+	assert.Equal(t, "9:00 Write report", generated[2].Text)
+	assert.True(t, generated[2].Done)
+	assert.True(t, generated[2].Success)
+	assert.Equal(t, int64(1), service.queue.Metrics().Admitted)
+
+	// The heuristic parser can't stream, so the request fails at once
+	generated = nil
+	service.provider = NewHeuristicProvider(nil)
+	service.handleTextGenerationRequested(request)
+	require.Len(t, generated, 1)
+	assert.True(t, generated[0].Done)
+	assert.False(t, generated[0].Success)
+	assert.NotEmpty(t, generated[0].Message)
+}
@@ -0,0 +1,44 @@
+package llm
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Messages this long are summarized into a task instead of parsed as one;
+// summarized titles and descriptions are kept to the summary lengths even
+// when the model or the heuristic fallback writes more
+const (
+	SummarizeMinLength       = 400
+	SummaryTitleLength       = 80
+	SummaryDescriptionLength = 500
+)
+
+// NeedsSummary reports whether a sanitized message is long enough to be
+// summarized, such as a pasted email
+func NeedsSummary(text string) bool {
+	return utf8.RuneCountInString(text) >= SummarizeMinLength
+}
+
+// condenseSummary keeps a summarized task's title and description to the
+// summary lengths
+func condenseSummary(task ParsedTask) ParsedTask {
+	task.Title = truncateWords(task.Title, SummaryTitleLength)
+	task.Description = truncateWords(task.Description, SummaryDescriptionLength)
+	return task
+}
+
+// truncateWords shortens text to at most max runes, cutting at the last word
+// that fits and marking the cut with an ellipsis
+func truncateWords(text string, max int) string {
+	runes := []rune(strings.TrimSpace(text))
+	if len(runes) <= max {
+		return string(runes)
+	}
+
+	cut := string(runes[:max-1])
+	if space := strings.LastIndexByte(cut, ' '); space > 0 {
+		cut = cut[:space]
+	}
+	return strings.TrimRight(cut, " ,.;:-") + "…"
+}
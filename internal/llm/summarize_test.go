@@ -0,0 +1,83 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/config"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// wordyProvider answers with a title and description longer than a summary's
+type wordyProvider struct {
+	erroringProvider
+	requests []ParseRequest
+}
+
+func (p *wordyProvider) ParseTask(ctx context.Context, req ParseRequest) (*LLMResponse, error) {
+	p.requests = append(p.requests, req)
+	return &LLMResponse{
+		ParsedTask: ParsedTask{
+			Title:       strings.Repeat("Review the contract ", 10),
+			Description: strings.Repeat("Check the payment terms. ", 40),
+			Priority:    common.PriorityHigh,
+		},
+		Confidence: 0.9,
+	}, nil
+}
+
+func TestTruncateWords(t *testing.T) {
+	assert.Equal(t, "Call mom", truncateWords(" Call mom ", 20))
+	assert.Equal(t, "Review the…", truncateWords("Review the contract, then sign", 15))
+	assert.Equal(t, "Reviewing…", truncateWords("Reviewingthecontract", 10))
+}
+
+func TestGemmaProvider_SummarizePrompt(t *testing.T) {
+	provider := NewGemmaProvider(config.LLMConfig{}, zap.NewNop())
+
+	prompt, version, err := provider.buildPrompt(ParseRequest{Text: "Hi team, please review the contract", Summarize: true})
+	require.NoError(t, err)
+	assert.Equal(t, "summarize@v1", version)
+	assert.Contains(t, prompt, "at most 60 characters")
+	assert.Contains(t, prompt, `"Hi team, please review the contract"`)
+}
+
+func TestLLMService_SummarizesLongMessages(t *testing.T) {
+	logger := zap.NewNop()
+	bus := events.NewEventBus(logger)
+	defer bus.Close()
+
+	provider := &wordyProvider{}
+	service := &llmService{
+		eventBus:   bus,
+		logger:     logger,
+		provider:   provider,
+		ready:      common.NewReadiness(),
+		thresholds: DefaultConfidenceThresholds(),
+	}
+
+	var parsed []events.TaskParsed
+	require.NoError(t, bus.Subscribe(events.TopicTaskParsed, func(event events.TaskParsed) { parsed = append(parsed, event) }))
+
+	email := "Hi,\n\n" + strings.Repeat("Please review the attached contract before Friday. ", 10) + "\n\nThanks,\nAnna"
+	require.True(t, NeedsSummary(email))
+	service.handleMessageReceived(events.MessageReceived{Event: events.NewEvent(), UserID: "user", ChatID: "42", MessageText: email})
+	service.handleMessageReceived(events.MessageReceived{Event: events.NewEvent(), UserID: "user", ChatID: "42", MessageText: "review contract"})
+
+	require.Len(t, provider.requests, 2)
+	assert.True(t, provider.requests[0].Summarize)
+	assert.False(t, provider.requests[1].Summarize)
+
+	require.Len(t, parsed, 2)
+	assert.Equal(t, email, parsed[0].OriginalText, "the original keeps its line breaks")
+	assert.LessOrEqual(t, utf8.RuneCountInString(parsed[0].ParsedTask.Title), SummaryTitleLength)
+	assert.LessOrEqual(t, utf8.RuneCountInString(parsed[0].ParsedTask.Description), SummaryDescriptionLength)
+	assert.Empty(t, parsed[1].OriginalText)
+}
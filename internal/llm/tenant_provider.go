@@ -0,0 +1,69 @@
+package llm
+
+import (
+	"context"
+
+	"nudgebot-api/internal/tenant"
+
+	"go.uber.org/zap"
+)
+
+// tenantProvider routes parse requests to the provider configured for the
+// requesting user's tenant, so hosted tenants are billed to their own LLM key
+type tenantProvider struct {
+	defaultProvider LLMProvider
+	providers       map[string]LLMProvider
+	resolver        tenant.Resolver
+	logger          *zap.Logger
+}
+
+// NewTenantProvider creates a provider that serves each tenant with its own
+// provider. Tenants without one, and users whose tenant cannot be resolved,
+// are served by defaultProvider.
+func NewTenantProvider(defaultProvider LLMProvider, providers map[string]LLMProvider, resolver tenant.Resolver, logger *zap.Logger) LLMProvider {
+	return &tenantProvider{
+		defaultProvider: defaultProvider,
+		providers:       providers,
+		resolver:        resolver,
+		logger:          logger,
+	}
+}
+
+// ParseTask implements the LLMProvider interface
+func (p *tenantProvider) ParseTask(ctx context.Context, req ParseRequest) (*LLMResponse, error) {
+	return p.providerFor(req).ParseTask(ctx, req)
+}
+
+// ValidateConnection implements the LLMProvider interface
+func (p *tenantProvider) ValidateConnection(ctx context.Context) error {
+	return p.defaultProvider.ValidateConnection(ctx)
+}
+
+// GetModelInfo implements the LLMProvider interface
+func (p *tenantProvider) GetModelInfo() ModelInfo {
+	return p.defaultProvider.GetModelInfo()
+}
+
+// StreamText implements the StreamingProvider interface with the tenant's provider
+func (p *tenantProvider) StreamText(ctx context.Context, req TextRequest, onText func(text string)) (string, error) {
+	streamer, ok := streamerOf(p.providerFor(ParseRequest{UserID: req.UserID}))
+	if !ok {
+		return "", ErrStreamingUnsupported
+	}
+	return streamer.StreamText(ctx, req, onText)
+}
+
+func (p *tenantProvider) providerFor(req ParseRequest) LLMProvider {
+	id, err := p.resolver.TenantFor(req.UserID)
+	if err != nil {
+		p.logger.Warn("Failed to resolve tenant, using the default LLM provider",
+			zap.String("userID", string(req.UserID)),
+			zap.Error(err))
+		return p.defaultProvider
+	}
+
+	if provider, ok := p.providers[id]; ok {
+		return provider
+	}
+	return p.defaultProvider
+}
@@ -0,0 +1,82 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/config"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/httpclient"
+	"nudgebot-api/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestTenantProvider_RoutesByTenant(t *testing.T) {
+	resolver := tenant.ResolverFunc(func(userID common.UserID) (string, error) {
+		switch userID {
+		case "acme-user":
+			return "acme", nil
+		case "broken":
+			return "", errors.New("lookup failed")
+		default:
+			return tenant.DefaultID, nil
+		}
+	})
+	provider := NewTenantProvider(
+		&erroringProvider{err: errors.New("default")},
+		map[string]LLMProvider{"acme": &erroringProvider{err: errors.New("acme")}},
+		resolver,
+		zaptest.NewLogger(t),
+	)
+
+	_, err := provider.ParseTask(context.Background(), ParseRequest{Text: "buy milk", UserID: "acme-user"})
+	assert.EqualError(t, err, "acme")
+
+	_, err = provider.ParseTask(context.Background(), ParseRequest{Text: "buy milk", UserID: "someone"})
+	assert.EqualError(t, err, "default")
+
+	_, err = provider.ParseTask(context.Background(), ParseRequest{Text: "buy milk", UserID: "broken"})
+	assert.EqualError(t, err, "default")
+}
+
+func TestLLMService_AppliesTenantLimitsToSharedClient(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	logger := zaptest.NewLogger(t)
+	shared := httpclient.New("llm", httpclient.Options{
+		Timeout:        time.Second,
+		MaxRetries:     2,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+	}, nil, logger)
+	noRetries := 0
+	tenants := []config.TenantConfig{{ID: "acme", LLMMaxRetries: &noRetries}}
+	resolver := tenant.ResolverFunc(func(userID common.UserID) (string, error) {
+		if userID == "acme-user" {
+			return "acme", nil
+		}
+		return tenant.DefaultID, nil
+	})
+	cfg := config.LLMConfig{APIEndpoint: server.URL, APIKey: "key", Model: "gemma", Timeout: 1, MaxRetries: 2}
+	service := NewLLMServiceWithHTTPClient(events.NewMockEventBus(), logger, cfg, nil, tenants, resolver, nil, nil, nil, nil, nil, shared)
+
+	_, _ = service.ParseTask("buy milk", "someone")
+	assert.Equal(t, int32(3), calls.Load(), "the deployment retries twice")
+
+	calls.Store(0)
+	_, _ = service.ParseTask("buy milk", "acme-user")
+	assert.Equal(t, int32(1), calls.Load(), "the tenant does not retry")
+}
@@ -18,6 +18,7 @@ func NewLLMServiceWithProvider(eventBus events.EventBus, logger *zap.Logger, pro
 		eventBus: eventBus,
 		logger:   logger,
 		provider: provider,
+		ready:    common.NewReadiness(),
 	}
 
 	// Subscribe to relevant events
@@ -62,7 +63,7 @@ func (s *StubLLMProvider) ParseTask(ctx context.Context, req ParseRequest) (*LLM
 			Priority:    common.PriorityMedium,
 			Tags:        []string{"personal", "call"},
 		}
-		confidence = ConfidenceHigh
+		confidence = DefaultConfidenceThresholds().High
 	default:
 		// Generic parsing for other messages
 		parsedTask = ParsedTask{
@@ -72,7 +73,7 @@ func (s *StubLLMProvider) ParseTask(ctx context.Context, req ParseRequest) (*LLM
 			Priority:    common.PriorityMedium,
 			Tags:        []string{"general"},
 		}
-		confidence = ConfidenceMedium
+		confidence = DefaultConfidenceThresholds().Medium
 	}
 
 	response := &LLMResponse{
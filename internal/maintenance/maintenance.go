@@ -0,0 +1,171 @@
+// Package maintenance holds the switch that takes the service down for
+// maintenance and brings it back, without a restart.
+package maintenance
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/config"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// DefaultMessage is the notice shown to users when none is given
+const DefaultMessage = "🛠 The bot is down for maintenance and will be back shortly. Please try again later."
+
+// Status describes maintenance mode and the notice shown meanwhile
+type Status struct {
+	Enabled bool       `json:"enabled"`
+	Message string     `json:"message"`
+	Since   *time.Time `json:"since,omitempty"`
+}
+
+// Switch turns maintenance mode on and off and announces each change with a
+// MaintenanceChanged event. The state belongs to this instance and is back to
+// the configured one after a restart.
+type Switch struct {
+	eventBus events.EventBus
+	logger   *zap.Logger
+	clock    common.Clock
+	ready    *common.Readiness
+
+	mu     sync.RWMutex
+	status Status
+}
+
+// NewSwitch creates a switch in the configured mode and subscribes it to the
+// /maintenance command
+func NewSwitch(eventBus events.EventBus, logger *zap.Logger, cfg config.MaintenanceConfig) *Switch {
+	s := &Switch{
+		eventBus: eventBus,
+		logger:   logger,
+		clock:    common.NewRealClock(),
+		ready:    common.NewReadiness(),
+	}
+	s.status = s.newStatus(cfg.Enabled, cfg.Message)
+
+	if err := eventBus.Subscribe(events.TopicMaintenanceRequested, s.handleMaintenanceRequested); err != nil {
+		logger.Error("Failed to subscribe to MaintenanceRequested events", zap.Error(err))
+	}
+	s.ready.MarkReady()
+
+	return s
+}
+
+// Ready returns a channel that is closed once the event subscription is registered
+func (s *Switch) Ready() <-chan struct{} {
+	return s.ready.Ready()
+}
+
+// Enabled reports whether the service is down for maintenance. A nil switch
+// is never enabled.
+func (s *Switch) Enabled() bool {
+	if s == nil {
+		return false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.status.Enabled
+}
+
+// Status returns the current mode and notice
+func (s *Switch) Status() Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.status
+}
+
+// Set turns maintenance mode on or off. An empty message shows the default
+// notice.
+func (s *Switch) Set(enabled bool, message string) Status {
+	return s.set(enabled, message, "", "")
+}
+
+// Start announces the configured mode, so services that subscribed at
+// construction start in it
+func (s *Switch) Start(ctx context.Context) error {
+	status := s.Status()
+	if status.Enabled {
+		s.announce(status, "", "")
+	}
+	return nil
+}
+
+// Stop does nothing; the switch has no background work
+func (s *Switch) Stop(ctx context.Context) error {
+	return nil
+}
+
+// Health always reports healthy. Maintenance mode is deliberate, not a failure.
+func (s *Switch) Health() error {
+	return nil
+}
+
+// newStatus builds the status for a mode entered now
+func (s *Switch) newStatus(enabled bool, message string) Status {
+	status := Status{Enabled: enabled, Message: strings.TrimSpace(message)}
+	if status.Message == "" {
+		status.Message = DefaultMessage
+	}
+	if enabled {
+		since := s.clock.Now()
+		status.Since = &since
+	}
+	return status
+}
+
+// set changes mode and announces it, naming the admin who asked from the chat
+func (s *Switch) set(enabled bool, message, userID, chatID string) Status {
+	s.mu.Lock()
+	status := s.newStatus(enabled, message)
+	if enabled && s.status.Enabled {
+		// Changing the notice doesn't restart the maintenance window
+		status.Since = s.status.Since
+	}
+	s.status = status
+	s.mu.Unlock()
+
+	s.announce(status, userID, chatID)
+	return status
+}
+
+// announce logs the mode and publishes it for other services
+func (s *Switch) announce(status Status, userID, chatID string) {
+	if status.Enabled {
+		s.logger.Warn("Maintenance mode on", zap.String("message", status.Message))
+	} else {
+		s.logger.Info("Maintenance mode off")
+	}
+
+	event := events.MaintenanceChanged{
+		Event:   events.NewEvent(),
+		Enabled: status.Enabled,
+		Message: status.Message,
+		UserID:  userID,
+		ChatID:  chatID,
+	}
+	if status.Since != nil {
+		event.Since = *status.Since
+	}
+	if err := s.eventBus.Publish(events.TopicMaintenanceChanged, event); err != nil {
+		s.logger.Error("Failed to publish MaintenanceChanged event", zap.Error(err))
+	}
+}
+
+// handleMaintenanceRequested applies an admin's /maintenance command
+func (s *Switch) handleMaintenanceRequested(event events.MaintenanceRequested) {
+	s.logger.Info("Maintenance mode changed from the chat",
+		zap.String("correlationID", event.CorrelationID),
+		zap.String("userID", event.UserID),
+		zap.Bool("enabled", event.Enabled))
+
+	s.set(event.Enabled, event.Message, event.UserID, event.ChatID)
+}
@@ -0,0 +1,81 @@
+package maintenance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/config"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestSwitch_SetAnnouncesChanges(t *testing.T) {
+	eventBus := events.NewMockEventBus()
+	s := NewSwitch(eventBus, zaptest.NewLogger(t), config.MaintenanceConfig{})
+	clock := common.NewMockClock(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC))
+	s.clock = clock
+
+	require.NoError(t, s.Start(context.Background()))
+	assert.Empty(t, eventBus.GetPublishedEvents(events.TopicMaintenanceChanged), "normal mode is not announced at start")
+	assert.False(t, s.Enabled())
+
+	status := s.Set(true, "  Upgrading the database  ")
+	assert.True(t, s.Enabled())
+	assert.Equal(t, "Upgrading the database", status.Message)
+	require.NotNil(t, status.Since)
+	started := *status.Since
+
+	clock.Advance(time.Minute)
+	status = s.Set(true, "")
+	assert.Equal(t, DefaultMessage, status.Message)
+	assert.Equal(t, started, *status.Since, "a new notice keeps the maintenance window")
+
+	status = s.Set(false, "")
+	assert.False(t, status.Enabled)
+	assert.Nil(t, status.Since)
+
+	published := eventBus.GetPublishedEvents(events.TopicMaintenanceChanged)
+	require.Len(t, published, 3)
+	first := published[0].(events.MaintenanceChanged)
+	assert.True(t, first.Enabled)
+	assert.Equal(t, "Upgrading the database", first.Message)
+	assert.Equal(t, started, first.Since)
+	assert.Empty(t, first.UserID)
+	assert.False(t, published[2].(events.MaintenanceChanged).Enabled)
+}
+
+func TestSwitch_StartsInConfiguredMode(t *testing.T) {
+	eventBus := events.NewMockEventBus()
+	s := NewSwitch(eventBus, zaptest.NewLogger(t), config.MaintenanceConfig{Enabled: true, Message: "Back at noon"})
+	assert.True(t, s.Enabled())
+
+	require.NoError(t, s.Start(context.Background()))
+	published := eventBus.GetPublishedEvents(events.TopicMaintenanceChanged)
+	require.Len(t, published, 1)
+	assert.Equal(t, "Back at noon", published[0].(events.MaintenanceChanged).Message)
+}
+
+func TestSwitch_HandleMaintenanceRequested(t *testing.T) {
+	eventBus := events.NewMockEventBus()
+	s := NewSwitch(eventBus, zaptest.NewLogger(t), config.MaintenanceConfig{})
+
+	s.handleMaintenanceRequested(events.MaintenanceRequested{Event: events.NewEvent(), UserID: "admin", ChatID: "42", Enabled: true})
+	assert.True(t, s.Enabled())
+
+	published := eventBus.GetPublishedEvents(events.TopicMaintenanceChanged)
+	require.Len(t, published, 1)
+	changed := published[0].(events.MaintenanceChanged)
+	assert.Equal(t, "admin", changed.UserID)
+	assert.Equal(t, "42", changed.ChatID)
+	assert.Equal(t, DefaultMessage, changed.Message)
+}
+
+func TestSwitch_NilIsNeverEnabled(t *testing.T) {
+	var s *Switch
+	assert.False(t, s.Enabled())
+}
@@ -1,6 +1,7 @@
 package mocks
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"sync"
@@ -17,13 +18,18 @@ type MockTelegramProvider struct {
 	mutex              sync.RWMutex
 	sentMessages       []MockMessage
 	sentKeyboards      []MockKeyboardMessage
+	editedMessages     []MockKeyboardMessage
+	chatActions        []string
 	webhookURL         string
 	botInfo            *tgbotapi.User
 	sendMessageError   error
 	sendKeyboardError  error
+	editMessageError   error
 	setWebhookError    error
 	deleteWebhookError error
 	getMeError         error
+	getUpdatesError    error
+	pendingUpdates     []chatbot.RawUpdate
 	rateLimitDelay     time.Duration
 	callCounts         map[string]int
 }
@@ -63,7 +69,7 @@ func NewMockTelegramProvider() *MockTelegramProvider {
 }
 
 // SendMessage implements the TelegramProvider interface
-func (m *MockTelegramProvider) SendMessage(chatID int64, text string) error {
+func (m *MockTelegramProvider) SendMessage(chatID int64, threadID int, text string) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
@@ -90,7 +96,7 @@ func (m *MockTelegramProvider) SendMessage(chatID int64, text string) error {
 }
 
 // SendMessageWithKeyboard implements the TelegramProvider interface
-func (m *MockTelegramProvider) SendMessageWithKeyboard(chatID int64, text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
+func (m *MockTelegramProvider) SendMessageWithKeyboard(chatID int64, threadID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
@@ -128,6 +134,50 @@ func (m *MockTelegramProvider) SendMessageWithKeyboard(chatID int64, text string
 	return nil
 }
 
+// SendTrackedMessage implements the TelegramProvider interface
+func (m *MockTelegramProvider) SendTrackedMessage(chatID int64, threadID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) (int, error) {
+	if err := m.SendMessageWithKeyboard(chatID, threadID, text, keyboard); err != nil {
+		return 0, err
+	}
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.sentKeyboards[len(m.sentKeyboards)-1].MessageID, nil
+}
+
+// EditMessageWithKeyboard implements the TelegramProvider interface
+func (m *MockTelegramProvider) EditMessageWithKeyboard(chatID int64, messageID int, text string, keyboard tgbotapi.InlineKeyboardMarkup) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.callCounts["EditMessageWithKeyboard"]++
+
+	if m.editMessageError != nil {
+		return m.editMessageError
+	}
+
+	m.editedMessages = append(m.editedMessages, MockKeyboardMessage{
+		ChatID:    chatID,
+		Text:      text,
+		Keyboard:  keyboard,
+		Timestamp: time.Now(),
+		MessageID: messageID,
+	})
+
+	return nil
+}
+
+// SendChatAction implements the TelegramProvider interface
+func (m *MockTelegramProvider) SendChatAction(chatID int64, action string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.callCounts["SendChatAction"]++
+	m.chatActions = append(m.chatActions, action)
+	return nil
+}
+
 // SetWebhook implements the TelegramProvider interface
 func (m *MockTelegramProvider) SetWebhook(webhookURL string) error {
 	m.mutex.Lock()
@@ -158,6 +208,16 @@ func (m *MockTelegramProvider) DeleteWebhook() error {
 	return nil
 }
 
+// GetWebhookInfo implements the TelegramProvider interface
+func (m *MockTelegramProvider) GetWebhookInfo() (tgbotapi.WebhookInfo, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.callCounts["GetWebhookInfo"]++
+
+	return tgbotapi.WebhookInfo{URL: m.webhookURL}, nil
+}
+
 // GetMe implements the TelegramProvider interface
 func (m *MockTelegramProvider) GetMe() (*tgbotapi.User, error) {
 	m.mutex.RLock()
@@ -172,6 +232,33 @@ func (m *MockTelegramProvider) GetMe() (*tgbotapi.User, error) {
 	return m.botInfo, nil
 }
 
+// GetUpdates implements the TelegramProvider interface, returning queued
+// updates from offset or waiting briefly like an idle long poll
+func (m *MockTelegramProvider) GetUpdates(ctx context.Context, offset, timeout int) ([]chatbot.RawUpdate, error) {
+	m.mutex.Lock()
+	m.callCounts["GetUpdates"]++
+	err := m.getUpdatesError
+	var updates []chatbot.RawUpdate
+	for _, update := range m.pendingUpdates {
+		if update.UpdateID >= offset {
+			updates = append(updates, update)
+		}
+	}
+	m.mutex.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+	if len(updates) == 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	return updates, nil
+}
+
 // Test helper methods
 
 // GetSentMessages returns all sent messages
@@ -195,6 +282,26 @@ func (m *MockTelegramProvider) GetSentKeyboards() []MockKeyboardMessage {
 	return keyboards
 }
 
+// GetEditedMessages returns all message edits
+func (m *MockTelegramProvider) GetEditedMessages() []MockKeyboardMessage {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	edits := make([]MockKeyboardMessage, len(m.editedMessages))
+	copy(edits, m.editedMessages)
+	return edits
+}
+
+// GetChatActions returns the chat actions sent, such as "typing"
+func (m *MockTelegramProvider) GetChatActions() []string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	actions := make([]string, len(m.chatActions))
+	copy(actions, m.chatActions)
+	return actions
+}
+
 // GetLastMessage returns the last sent message
 func (m *MockTelegramProvider) GetLastMessage() *MockMessage {
 	m.mutex.RLock()
@@ -261,6 +368,14 @@ func (m *MockTelegramProvider) SetSendKeyboardError(err error) {
 	m.sendKeyboardError = err
 }
 
+// SetEditMessageError configures the provider to return an error on EditMessageWithKeyboard
+func (m *MockTelegramProvider) SetEditMessageError(err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.editMessageError = err
+}
+
 // SetWebhookError configures the provider to return an error on SetWebhook
 func (m *MockTelegramProvider) SetWebhookError(err error) {
 	m.mutex.Lock()
@@ -282,6 +397,20 @@ func (m *MockTelegramProvider) SetGetMeError(err error) {
 	m.getMeError = err
 }
 
+// SetGetUpdatesError configures GetUpdates to fail
+func (m *MockTelegramProvider) SetGetUpdatesError(err error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.getUpdatesError = err
+}
+
+// QueueUpdate makes an update available to GetUpdates
+func (m *MockTelegramProvider) QueueUpdate(update chatbot.RawUpdate) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.pendingUpdates = append(m.pendingUpdates, update)
+}
+
 // SetBotInfo configures the bot information returned by GetMe
 func (m *MockTelegramProvider) SetBotInfo(botInfo *tgbotapi.User) {
 	m.mutex.Lock()
@@ -303,6 +432,8 @@ func (m *MockTelegramProvider) ClearHistory() {
 
 	m.sentMessages = make([]MockMessage, 0)
 	m.sentKeyboards = make([]MockKeyboardMessage, 0)
+	m.editedMessages = nil
+	m.chatActions = nil
 	m.callCounts = make(map[string]int)
 }
 
@@ -10,6 +10,7 @@
 package mocks
 
 import (
+	context "context"
 	chatbot "nudgebot-api/internal/chatbot"
 	common "nudgebot-api/internal/common"
 	reflect "reflect"
@@ -55,6 +56,20 @@ func (mr *MockChatbotServiceMockRecorder) HandleWebhook(webhookData any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleWebhook", reflect.TypeOf((*MockChatbotService)(nil).HandleWebhook), webhookData)
 }
 
+// Health mocks base method.
+func (m *MockChatbotService) Health() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Health")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Health indicates an expected call of Health.
+func (mr *MockChatbotServiceMockRecorder) Health() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Health", reflect.TypeOf((*MockChatbotService)(nil).Health))
+}
+
 // ProcessCommand mocks base method.
 func (m *MockChatbotService) ProcessCommand(command chatbot.Command, userID common.UserID, chatID common.ChatID) error {
 	m.ctrl.T.Helper()
@@ -69,6 +84,20 @@ func (mr *MockChatbotServiceMockRecorder) ProcessCommand(command, userID, chatID
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessCommand", reflect.TypeOf((*MockChatbotService)(nil).ProcessCommand), command, userID, chatID)
 }
 
+// Ready mocks base method.
+func (m *MockChatbotService) Ready() <-chan struct{} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ready")
+	ret0, _ := ret[0].(<-chan struct{})
+	return ret0
+}
+
+// Ready indicates an expected call of Ready.
+func (mr *MockChatbotServiceMockRecorder) Ready() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ready", reflect.TypeOf((*MockChatbotService)(nil).Ready))
+}
+
 // SendMessage mocks base method.
 func (m *MockChatbotService) SendMessage(chatID common.ChatID, text string) error {
 	m.ctrl.T.Helper()
@@ -96,3 +125,31 @@ func (mr *MockChatbotServiceMockRecorder) SendMessageWithKeyboard(chatID, text,
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMessageWithKeyboard", reflect.TypeOf((*MockChatbotService)(nil).SendMessageWithKeyboard), chatID, text, keyboard)
 }
+
+// Start mocks base method.
+func (m *MockChatbotService) Start(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Start", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Start indicates an expected call of Start.
+func (mr *MockChatbotServiceMockRecorder) Start(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockChatbotService)(nil).Start), ctx)
+}
+
+// Stop mocks base method.
+func (m *MockChatbotService) Stop(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stop", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Stop indicates an expected call of Stop.
+func (mr *MockChatbotServiceMockRecorder) Stop(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockChatbotService)(nil).Stop), ctx)
+}
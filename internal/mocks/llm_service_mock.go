@@ -10,6 +10,7 @@
 package mocks
 
 import (
+	context "context"
 	common "nudgebot-api/internal/common"
 	llm "nudgebot-api/internal/llm"
 	reflect "reflect"
@@ -56,6 +57,20 @@ func (mr *MockLLMServiceMockRecorder) GetSuggestions(partialText, userID any) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSuggestions", reflect.TypeOf((*MockLLMService)(nil).GetSuggestions), partialText, userID)
 }
 
+// Health mocks base method.
+func (m *MockLLMService) Health() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Health")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Health indicates an expected call of Health.
+func (mr *MockLLMServiceMockRecorder) Health() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Health", reflect.TypeOf((*MockLLMService)(nil).Health))
+}
+
 // ParseTask mocks base method.
 func (m *MockLLMService) ParseTask(text string, userID common.UserID) (*llm.LLMResponse, error) {
 	m.ctrl.T.Helper()
@@ -71,6 +86,48 @@ func (mr *MockLLMServiceMockRecorder) ParseTask(text, userID any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ParseTask", reflect.TypeOf((*MockLLMService)(nil).ParseTask), text, userID)
 }
 
+// Ready mocks base method.
+func (m *MockLLMService) Ready() <-chan struct{} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ready")
+	ret0, _ := ret[0].(<-chan struct{})
+	return ret0
+}
+
+// Ready indicates an expected call of Ready.
+func (mr *MockLLMServiceMockRecorder) Ready() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ready", reflect.TypeOf((*MockLLMService)(nil).Ready))
+}
+
+// Start mocks base method.
+func (m *MockLLMService) Start(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Start", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Start indicates an expected call of Start.
+func (mr *MockLLMServiceMockRecorder) Start(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockLLMService)(nil).Start), ctx)
+}
+
+// Stop mocks base method.
+func (m *MockLLMService) Stop(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stop", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Stop indicates an expected call of Stop.
+func (mr *MockLLMServiceMockRecorder) Stop(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockLLMService)(nil).Stop), ctx)
+}
+
 // ValidateTask mocks base method.
 func (m *MockLLMService) ValidateTask(parsedTask llm.ParsedTask) error {
 	m.ctrl.T.Helper()
@@ -10,6 +10,7 @@
 package mocks
 
 import (
+	context "context"
 	common "nudgebot-api/internal/common"
 	nudge "nudgebot-api/internal/nudge"
 	reflect "reflect"
@@ -316,6 +317,77 @@ func (m *MockNudgeRepository) EXPECT() *MockNudgeRepositoryMockRecorder {
 	return m.recorder
 }
 
+// BulkShiftDueDates mocks base method.
+func (m *MockNudgeRepository) BulkShiftDueDates(taskIDs []common.TaskID, shift time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkShiftDueDates", taskIDs, shift)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BulkShiftDueDates indicates an expected call of BulkShiftDueDates.
+func (mr *MockNudgeRepositoryMockRecorder) BulkShiftDueDates(taskIDs, shift any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkShiftDueDates", reflect.TypeOf((*MockNudgeRepository)(nil).BulkShiftDueDates), taskIDs, shift)
+}
+
+// GetNewlyOverdueTasks mocks base method.
+func (m *MockNudgeRepository) GetNewlyOverdueTasks(now time.Time, limit int) ([]*nudge.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNewlyOverdueTasks", now, limit)
+	ret0, _ := ret[0].([]*nudge.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNewlyOverdueTasks indicates an expected call of GetNewlyOverdueTasks.
+func (mr *MockNudgeRepositoryMockRecorder) GetNewlyOverdueTasks(now, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNewlyOverdueTasks", reflect.TypeOf((*MockNudgeRepository)(nil).GetNewlyOverdueTasks), now, limit)
+}
+
+// MarkTaskOverdue mocks base method.
+func (m *MockNudgeRepository) MarkTaskOverdue(taskID common.TaskID, dueDate time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkTaskOverdue", taskID, dueDate)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkTaskOverdue indicates an expected call of MarkTaskOverdue.
+func (mr *MockNudgeRepositoryMockRecorder) MarkTaskOverdue(taskID, dueDate any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkTaskOverdue", reflect.TypeOf((*MockNudgeRepository)(nil).MarkTaskOverdue), taskID, dueDate)
+}
+
+// MoveChatID mocks base method.
+func (m *MockNudgeRepository) MoveChatID(from, to common.ChatID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveChatID", from, to)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MoveChatID indicates an expected call of MoveChatID.
+func (mr *MockNudgeRepositoryMockRecorder) MoveChatID(from, to any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveChatID", reflect.TypeOf((*MockNudgeRepository)(nil).MoveChatID), from, to)
+}
+
+// BulkUpdateTaskStatus mocks base method.
+func (m *MockNudgeRepository) BulkUpdateTaskStatus(taskIDs []common.TaskID, status common.TaskStatus) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkUpdateTaskStatus", taskIDs, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BulkUpdateTaskStatus indicates an expected call of BulkUpdateTaskStatus.
+func (mr *MockNudgeRepositoryMockRecorder) BulkUpdateTaskStatus(taskIDs, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkUpdateTaskStatus", reflect.TypeOf((*MockNudgeRepository)(nil).BulkUpdateTaskStatus), taskIDs, status)
+}
+
 // CreateOrUpdateNudgeSettings mocks base method.
 func (m *MockNudgeRepository) CreateOrUpdateNudgeSettings(settings *nudge.NudgeSettings) error {
 	m.ctrl.T.Helper()
@@ -430,6 +502,21 @@ func (mr *MockNudgeRepositoryMockRecorder) GetNudgeSettingsByUserID(userID any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNudgeSettingsByUserID", reflect.TypeOf((*MockNudgeRepository)(nil).GetNudgeSettingsByUserID), userID)
 }
 
+// GetOverdueTasks mocks base method.
+func (m *MockNudgeRepository) GetOverdueTasks(userID common.UserID) ([]*nudge.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOverdueTasks", userID)
+	ret0, _ := ret[0].([]*nudge.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOverdueTasks indicates an expected call of GetOverdueTasks.
+func (mr *MockNudgeRepositoryMockRecorder) GetOverdueTasks(userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOverdueTasks", reflect.TypeOf((*MockNudgeRepository)(nil).GetOverdueTasks), userID)
+}
+
 // GetRemindersByTaskID mocks base method.
 func (m *MockNudgeRepository) GetRemindersByTaskID(taskID common.TaskID) ([]*nudge.Reminder, error) {
 	m.ctrl.T.Helper()
@@ -518,6 +605,20 @@ func (mr *MockNudgeRepositoryMockRecorder) UpdateTask(task any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTask", reflect.TypeOf((*MockNudgeRepository)(nil).UpdateTask), task)
 }
 
+// WithContext mocks base method.
+func (m *MockNudgeRepository) WithContext(ctx context.Context) nudge.NudgeRepository {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithContext", ctx)
+	ret0, _ := ret[0].(nudge.NudgeRepository)
+	return ret0
+}
+
+// WithContext indicates an expected call of WithContext.
+func (mr *MockNudgeRepositoryMockRecorder) WithContext(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithContext", reflect.TypeOf((*MockNudgeRepository)(nil).WithContext), ctx)
+}
+
 // WithTransaction mocks base method.
 func (m *MockNudgeRepository) WithTransaction(fn func(nudge.NudgeRepository) error) error {
 	m.ctrl.T.Helper()
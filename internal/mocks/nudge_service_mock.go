@@ -10,6 +10,7 @@
 package mocks
 
 import (
+	context "context"
 	common "nudgebot-api/internal/common"
 	nudge "nudgebot-api/internal/nudge"
 	reflect "reflect"
@@ -84,6 +85,20 @@ func (mr *MockNudgeServiceMockRecorder) CreateTask(task any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTask", reflect.TypeOf((*MockNudgeService)(nil).CreateTask), task)
 }
 
+// CreateTasks mocks base method.
+func (m *MockNudgeService) CreateTasks(tasks []*nudge.Task) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTasks", tasks)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateTasks indicates an expected call of CreateTasks.
+func (mr *MockNudgeServiceMockRecorder) CreateTasks(tasks any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTasks", reflect.TypeOf((*MockNudgeService)(nil).CreateTasks), tasks)
+}
+
 // DeleteTask mocks base method.
 func (m *MockNudgeService) DeleteTask(taskID common.TaskID) error {
 	m.ctrl.T.Helper()
@@ -98,6 +113,35 @@ func (mr *MockNudgeServiceMockRecorder) DeleteTask(taskID any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTask", reflect.TypeOf((*MockNudgeService)(nil).DeleteTask), taskID)
 }
 
+// EditTask mocks base method.
+func (m *MockNudgeService) EditTask(taskID common.TaskID, edit nudge.TaskEdit) (*nudge.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EditTask", taskID, edit)
+	ret0, _ := ret[0].(*nudge.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EditTask indicates an expected call of EditTask.
+func (mr *MockNudgeServiceMockRecorder) EditTask(taskID, edit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditTask", reflect.TypeOf((*MockNudgeService)(nil).EditTask), taskID, edit)
+}
+
+// FireTestReminder mocks base method.
+func (m *MockNudgeService) FireTestReminder(taskID common.TaskID, chatID common.ChatID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FireTestReminder", taskID, chatID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FireTestReminder indicates an expected call of FireTestReminder.
+func (mr *MockNudgeServiceMockRecorder) FireTestReminder(taskID, chatID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FireTestReminder", reflect.TypeOf((*MockNudgeService)(nil).FireTestReminder), taskID, chatID)
+}
+
 // GetNudgeSettings mocks base method.
 func (m *MockNudgeService) GetNudgeSettings(userID common.UserID) (*nudge.NudgeSettings, error) {
 	m.ctrl.T.Helper()
@@ -128,6 +172,21 @@ func (mr *MockNudgeServiceMockRecorder) GetOverdueTasks(userID any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOverdueTasks", reflect.TypeOf((*MockNudgeService)(nil).GetOverdueTasks), userID)
 }
 
+// GetTask mocks base method.
+func (m *MockNudgeService) GetTask(userID common.UserID, taskID common.TaskID) (*nudge.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTask", userID, taskID)
+	ret0, _ := ret[0].(*nudge.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTask indicates an expected call of GetTask.
+func (mr *MockNudgeServiceMockRecorder) GetTask(userID, taskID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTask", reflect.TypeOf((*MockNudgeService)(nil).GetTask), userID, taskID)
+}
+
 // GetTaskStats mocks base method.
 func (m *MockNudgeService) GetTaskStats(userID common.UserID) (*nudge.TaskStats, error) {
 	m.ctrl.T.Helper()
@@ -158,6 +217,64 @@ func (mr *MockNudgeServiceMockRecorder) GetTasks(userID, filter any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTasks", reflect.TypeOf((*MockNudgeService)(nil).GetTasks), userID, filter)
 }
 
+// GetTasksDueToday mocks base method.
+func (m *MockNudgeService) GetTasksDueToday(userID common.UserID) ([]*nudge.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTasksDueToday", userID)
+	ret0, _ := ret[0].([]*nudge.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTasksDueToday indicates an expected call of GetTasksDueToday.
+func (mr *MockNudgeServiceMockRecorder) GetTasksDueToday(userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTasksDueToday", reflect.TypeOf((*MockNudgeService)(nil).GetTasksDueToday), userID)
+}
+
+// Health mocks base method.
+func (m *MockNudgeService) Health() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Health")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Health indicates an expected call of Health.
+func (mr *MockNudgeServiceMockRecorder) Health() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Health", reflect.TypeOf((*MockNudgeService)(nil).Health))
+}
+
+// Ready mocks base method.
+func (m *MockNudgeService) Ready() <-chan struct{} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ready")
+	ret0, _ := ret[0].(<-chan struct{})
+	return ret0
+}
+
+// Ready indicates an expected call of Ready.
+func (mr *MockNudgeServiceMockRecorder) Ready() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ready", reflect.TypeOf((*MockNudgeService)(nil).Ready))
+}
+
+// RemoveCustomField mocks base method.
+func (m *MockNudgeService) RemoveCustomField(taskID common.TaskID, key string) (*nudge.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveCustomField", taskID, key)
+	ret0, _ := ret[0].(*nudge.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveCustomField indicates an expected call of RemoveCustomField.
+func (mr *MockNudgeServiceMockRecorder) RemoveCustomField(taskID, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveCustomField", reflect.TypeOf((*MockNudgeService)(nil).RemoveCustomField), taskID, key)
+}
+
 // ScheduleReminder mocks base method.
 func (m *MockNudgeService) ScheduleReminder(taskID common.TaskID, scheduledAt time.Time, reminderType nudge.ReminderType) error {
 	m.ctrl.T.Helper()
@@ -172,6 +289,36 @@ func (mr *MockNudgeServiceMockRecorder) ScheduleReminder(taskID, scheduledAt, re
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleReminder", reflect.TypeOf((*MockNudgeService)(nil).ScheduleReminder), taskID, scheduledAt, reminderType)
 }
 
+// SetCustomField mocks base method.
+func (m *MockNudgeService) SetCustomField(taskID common.TaskID, key string, field nudge.CustomField) (*nudge.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetCustomField", taskID, key, field)
+	ret0, _ := ret[0].(*nudge.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetCustomField indicates an expected call of SetCustomField.
+func (mr *MockNudgeServiceMockRecorder) SetCustomField(taskID, key, field any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCustomField", reflect.TypeOf((*MockNudgeService)(nil).SetCustomField), taskID, key, field)
+}
+
+// ShiftTaskDueDates mocks base method.
+func (m *MockNudgeService) ShiftTaskDueDates(userID common.UserID, taskIDs []common.TaskID, shift nudge.DueShift) ([]*nudge.Task, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ShiftTaskDueDates", userID, taskIDs, shift)
+	ret0, _ := ret[0].([]*nudge.Task)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ShiftTaskDueDates indicates an expected call of ShiftTaskDueDates.
+func (mr *MockNudgeServiceMockRecorder) ShiftTaskDueDates(userID, taskIDs, shift any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShiftTaskDueDates", reflect.TypeOf((*MockNudgeService)(nil).ShiftTaskDueDates), userID, taskIDs, shift)
+}
+
 // SnoozeTask mocks base method.
 func (m *MockNudgeService) SnoozeTask(taskID common.TaskID, snoozeUntil time.Time) error {
 	m.ctrl.T.Helper()
@@ -186,6 +333,34 @@ func (mr *MockNudgeServiceMockRecorder) SnoozeTask(taskID, snoozeUntil any) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SnoozeTask", reflect.TypeOf((*MockNudgeService)(nil).SnoozeTask), taskID, snoozeUntil)
 }
 
+// Start mocks base method.
+func (m *MockNudgeService) Start(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Start", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Start indicates an expected call of Start.
+func (mr *MockNudgeServiceMockRecorder) Start(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Start", reflect.TypeOf((*MockNudgeService)(nil).Start), ctx)
+}
+
+// Stop mocks base method.
+func (m *MockNudgeService) Stop(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stop", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Stop indicates an expected call of Stop.
+func (mr *MockNudgeServiceMockRecorder) Stop(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockNudgeService)(nil).Stop), ctx)
+}
+
 // UpdateNudgeSettings mocks base method.
 func (m *MockNudgeService) UpdateNudgeSettings(settings *nudge.NudgeSettings) error {
 	m.ctrl.T.Helper()
@@ -213,3 +388,17 @@ func (mr *MockNudgeServiceMockRecorder) UpdateTaskStatus(taskID, status any) *go
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTaskStatus", reflect.TypeOf((*MockNudgeService)(nil).UpdateTaskStatus), taskID, status)
 }
+
+// WithContext mocks base method.
+func (m *MockNudgeService) WithContext(ctx context.Context) nudge.NudgeService {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithContext", ctx)
+	ret0, _ := ret[0].(nudge.NudgeService)
+	return ret0
+}
+
+// WithContext indicates an expected call of WithContext.
+func (mr *MockNudgeServiceMockRecorder) WithContext(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithContext", reflect.TypeOf((*MockNudgeService)(nil).WithContext), ctx)
+}
@@ -55,6 +55,20 @@ func (mr *MockSchedulerMockRecorder) GetMetrics() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMetrics", reflect.TypeOf((*MockScheduler)(nil).GetMetrics))
 }
 
+// Health mocks base method.
+func (m *MockScheduler) Health() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Health")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Health indicates an expected call of Health.
+func (mr *MockSchedulerMockRecorder) Health() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Health", reflect.TypeOf((*MockScheduler)(nil).Health))
+}
+
 // IsRunning mocks base method.
 func (m *MockScheduler) IsRunning() bool {
 	m.ctrl.T.Helper()
@@ -69,6 +83,20 @@ func (mr *MockSchedulerMockRecorder) IsRunning() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsRunning", reflect.TypeOf((*MockScheduler)(nil).IsRunning))
 }
 
+// Ready mocks base method.
+func (m *MockScheduler) Ready() <-chan struct{} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ready")
+	ret0, _ := ret[0].(<-chan struct{})
+	return ret0
+}
+
+// Ready indicates an expected call of Ready.
+func (mr *MockSchedulerMockRecorder) Ready() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ready", reflect.TypeOf((*MockScheduler)(nil).Ready))
+}
+
 // Start mocks base method.
 func (m *MockScheduler) Start(ctx context.Context) error {
 	m.ctrl.T.Helper()
@@ -84,15 +112,15 @@ func (mr *MockSchedulerMockRecorder) Start(ctx any) *gomock.Call {
 }
 
 // Stop mocks base method.
-func (m *MockScheduler) Stop() error {
+func (m *MockScheduler) Stop(ctx context.Context) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Stop")
+	ret := m.ctrl.Call(m, "Stop", ctx)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Stop indicates an expected call of Stop.
-func (mr *MockSchedulerMockRecorder) Stop() *gomock.Call {
+func (mr *MockSchedulerMockRecorder) Stop(ctx any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockScheduler)(nil).Stop))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockScheduler)(nil).Stop), ctx)
 }
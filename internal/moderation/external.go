@@ -0,0 +1,71 @@
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// externalModerator delegates checks to an HTTP moderation API.
+// The API receives {"text": "..."} and must answer with a Result JSON body.
+type externalModerator struct {
+	endpoint   string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewExternalModerator creates a moderator backed by an external API
+func NewExternalModerator(endpoint, apiKey string, timeout time.Duration) Moderator {
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+
+	return &externalModerator{
+		endpoint:   endpoint,
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// Check implements the Moderator interface
+func (m *externalModerator) Check(ctx context.Context, text string) (Result, error) {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to marshal moderation request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to create moderation request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if m.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+m.apiKey)
+	}
+
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("moderation request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to read moderation response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return Result{}, fmt.Errorf("moderation API returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var result Result
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return Result{}, fmt.Errorf("failed to parse moderation response: %w", err)
+	}
+
+	return result, nil
+}
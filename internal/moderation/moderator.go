@@ -0,0 +1,154 @@
+package moderation
+
+import (
+	"context"
+	"time"
+
+	"nudgebot-api/internal/config"
+
+	"go.uber.org/zap"
+)
+
+// Action determines what happens to content that a moderator flags
+type Action string
+
+const (
+	ActionReject Action = "reject"
+	ActionFlag   Action = "flag"
+	ActionAllow  Action = "allow"
+)
+
+// IsValid checks if the action is a supported value
+func (a Action) IsValid() bool {
+	switch a {
+	case ActionReject, ActionFlag, ActionAllow:
+		return true
+	default:
+		return false
+	}
+}
+
+// Result describes the outcome of a moderation check
+type Result struct {
+	Flagged    bool     `json:"flagged"`
+	Categories []string `json:"categories,omitempty"`
+	Matches    []string `json:"matches,omitempty"`
+}
+
+// Moderator inspects text and reports whether it should be flagged
+type Moderator interface {
+	Check(ctx context.Context, text string) (Result, error)
+}
+
+// Verdict is the decision a Policy reaches for a piece of content
+type Verdict struct {
+	Allowed bool
+	Flagged bool
+	Result  Result
+}
+
+// Policy applies the configured action to a moderator's results
+type Policy struct {
+	moderator Moderator
+	action    Action
+	logger    *zap.Logger
+}
+
+// NewPolicy creates a Policy around a moderator. A nil moderator allows all content.
+func NewPolicy(moderator Moderator, action Action, logger *zap.Logger) *Policy {
+	if !action.IsValid() {
+		action = ActionReject
+	}
+
+	return &Policy{
+		moderator: moderator,
+		action:    action,
+		logger:    logger,
+	}
+}
+
+// NewPolicyFromConfig builds the word-list and optional external moderators from configuration
+func NewPolicyFromConfig(cfg config.ModerationConfig, logger *zap.Logger) *Policy {
+	if !cfg.Enabled {
+		return NewPolicy(nil, ActionAllow, logger)
+	}
+
+	var moderators []Moderator
+	moderators = append(moderators, NewWordListModerator(cfg.BlockedWords))
+	if cfg.APIEndpoint != "" {
+		timeout := time.Duration(cfg.Timeout) * time.Second
+		moderators = append(moderators, NewExternalModerator(cfg.APIEndpoint, cfg.APIKey, timeout))
+	}
+
+	return NewPolicy(NewChainModerator(moderators...), Action(cfg.Action), logger)
+}
+
+// Evaluate checks text and decides whether it may continue through the pipeline.
+// Moderator failures are logged and the content is allowed so outages do not block users.
+func (p *Policy) Evaluate(ctx context.Context, text string) Verdict {
+	if p == nil || p.moderator == nil {
+		return Verdict{Allowed: true}
+	}
+
+	result, err := p.moderator.Check(ctx, text)
+	if err != nil {
+		p.logger.Warn("Content moderation check failed, allowing content", zap.Error(err))
+		return Verdict{Allowed: true}
+	}
+
+	if !result.Flagged {
+		return Verdict{Allowed: true, Result: result}
+	}
+
+	switch p.action {
+	case ActionReject:
+		p.logger.Info("Content rejected by moderation",
+			zap.Strings("categories", result.Categories))
+		return Verdict{Allowed: false, Flagged: true, Result: result}
+	case ActionFlag:
+		p.logger.Warn("Content flagged by moderation",
+			zap.Strings("categories", result.Categories))
+		return Verdict{Allowed: true, Flagged: true, Result: result}
+	default:
+		return Verdict{Allowed: true, Result: result}
+	}
+}
+
+// chainModerator runs several moderators and flags content if any of them do
+type chainModerator struct {
+	moderators []Moderator
+}
+
+// NewChainModerator combines moderators into one
+func NewChainModerator(moderators ...Moderator) Moderator {
+	return &chainModerator{moderators: moderators}
+}
+
+// Check implements the Moderator interface
+func (c *chainModerator) Check(ctx context.Context, text string) (Result, error) {
+	combined := Result{}
+	var firstErr error
+
+	for _, moderator := range c.moderators {
+		result, err := moderator.Check(ctx, text)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		if result.Flagged {
+			combined.Flagged = true
+			combined.Categories = append(combined.Categories, result.Categories...)
+			combined.Matches = append(combined.Matches, result.Matches...)
+		}
+	}
+
+	// Only surface an error when no moderator produced a verdict
+	if !combined.Flagged && firstErr != nil {
+		return combined, firstErr
+	}
+
+	return combined, nil
+}
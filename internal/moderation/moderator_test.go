@@ -0,0 +1,99 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+type failingModerator struct{}
+
+func (failingModerator) Check(ctx context.Context, text string) (Result, error) {
+	return Result{}, errors.New("moderation backend down")
+}
+
+func TestWordListModerator_Check(t *testing.T) {
+	moderator := NewWordListModerator([]string{"darn", " Heck "})
+
+	tests := []struct {
+		name    string
+		text    string
+		flagged bool
+	}{
+		{name: "clean text", text: "call Sarah tomorrow", flagged: false},
+		{name: "blocked word", text: "finish the darn report", flagged: true},
+		{name: "case and punctuation insensitive", text: "HECK! pay rent", flagged: true},
+		{name: "substring does not match", text: "check the heckler list", flagged: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := moderator.Check(context.Background(), tt.text)
+			require.NoError(t, err)
+			assert.Equal(t, tt.flagged, result.Flagged)
+		})
+	}
+}
+
+func TestPolicy_Evaluate(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	words := NewWordListModerator([]string{"darn"})
+
+	tests := []struct {
+		name        string
+		policy      *Policy
+		text        string
+		wantAllowed bool
+		wantFlagged bool
+	}{
+		{name: "reject blocks flagged content", policy: NewPolicy(words, ActionReject, logger), text: "darn it", wantAllowed: false, wantFlagged: true},
+		{name: "flag lets flagged content through", policy: NewPolicy(words, ActionFlag, logger), text: "darn it", wantAllowed: true, wantFlagged: true},
+		{name: "allow ignores flagged content", policy: NewPolicy(words, ActionAllow, logger), text: "darn it", wantAllowed: true, wantFlagged: false},
+		{name: "clean content is allowed", policy: NewPolicy(words, ActionReject, logger), text: "buy milk", wantAllowed: true, wantFlagged: false},
+		{name: "nil moderator allows everything", policy: NewPolicy(nil, ActionReject, logger), text: "darn it", wantAllowed: true, wantFlagged: false},
+		{name: "moderator errors fail open", policy: NewPolicy(failingModerator{}, ActionReject, logger), text: "darn it", wantAllowed: true, wantFlagged: false},
+		{name: "nil policy allows everything", policy: nil, text: "darn it", wantAllowed: true, wantFlagged: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict := tt.policy.Evaluate(context.Background(), tt.text)
+			assert.Equal(t, tt.wantAllowed, verdict.Allowed)
+			assert.Equal(t, tt.wantFlagged, verdict.Flagged)
+		})
+	}
+}
+
+func TestExternalModerator_Check(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		_ = json.NewEncoder(w).Encode(Result{
+			Flagged:    body["text"] == "abusive",
+			Categories: []string{"harassment"},
+		})
+	}))
+	defer server.Close()
+
+	moderator := NewExternalModerator(server.URL, "secret", time.Second)
+
+	result, err := moderator.Check(context.Background(), "abusive")
+	require.NoError(t, err)
+	assert.True(t, result.Flagged)
+	assert.Equal(t, []string{"harassment"}, result.Categories)
+
+	result, err = moderator.Check(context.Background(), "buy milk")
+	require.NoError(t, err)
+	assert.False(t, result.Flagged)
+}
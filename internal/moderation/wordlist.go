@@ -0,0 +1,68 @@
+package moderation
+
+import (
+	"context"
+	"strings"
+	"unicode"
+)
+
+// CategoryBlockedWord is reported when text contains a blocked word
+const CategoryBlockedWord = "blocked_word"
+
+// defaultBlockedWords is a small baseline list used when no words are configured
+var defaultBlockedWords = []string{
+	"fuck",
+	"shit",
+	"bitch",
+	"bastard",
+	"asshole",
+	"cunt",
+	"motherfucker",
+}
+
+// wordListModerator flags text containing any word from a fixed list
+type wordListModerator struct {
+	words map[string]struct{}
+}
+
+// NewWordListModerator creates a moderator from a list of blocked words.
+// An empty list falls back to the built-in defaults.
+func NewWordListModerator(words []string) Moderator {
+	if len(words) == 0 {
+		words = defaultBlockedWords
+	}
+
+	set := make(map[string]struct{}, len(words))
+	for _, word := range words {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word != "" {
+			set[word] = struct{}{}
+		}
+	}
+
+	return &wordListModerator{words: set}
+}
+
+// Check implements the Moderator interface
+func (m *wordListModerator) Check(ctx context.Context, text string) (Result, error) {
+	tokens := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+
+	var matches []string
+	for _, token := range tokens {
+		if _, blocked := m.words[token]; blocked {
+			matches = append(matches, token)
+		}
+	}
+
+	if len(matches) == 0 {
+		return Result{}, nil
+	}
+
+	return Result{
+		Flagged:    true,
+		Categories: []string{CategoryBlockedWord},
+		Matches:    matches,
+	}, nil
+}
@@ -0,0 +1,388 @@
+package notify
+
+import (
+	"context"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/featureflags"
+	"nudgebot-api/internal/holiday"
+	"nudgebot-api/internal/nudge"
+	"nudgebot-api/internal/tenant"
+
+	"go.uber.org/zap"
+)
+
+// DigestJobName is the name the daily digest runs under on the job scheduler
+const DigestJobName = "reminder_digest"
+
+// DefaultDigestSchedule sends the digest every morning
+const DefaultDigestSchedule = "0 8 * * *"
+
+// MaxDigestSize caps how many reminders one digest message lists; larger
+// digests are split across messages
+const MaxDigestSize = 20
+
+// DigestEntry is a reminder waiting for the next daily digest of its chat
+type DigestEntry struct {
+	ID        common.ID     `gorm:"type:uuid;primaryKey" json:"id"`
+	TenantID  string        `gorm:"type:varchar(64);not null;default:'default';index" json:"tenant_id,omitempty"`
+	UserID    common.UserID `gorm:"type:varchar(36);not null;index" json:"user_id"`
+	ChatID    common.ChatID `gorm:"type:varchar(64);not null" json:"chat_id"`
+	TaskID    common.TaskID `gorm:"type:varchar(36);not null" json:"task_id"`
+	Title     string        `gorm:"type:varchar(255)" json:"title,omitempty"`
+	Priority  string        `gorm:"type:varchar(16)" json:"priority,omitempty"`
+	DueDate   *time.Time    `json:"due_date,omitempty"`
+	CreatedAt time.Time     `gorm:"not null;index" json:"created_at"`
+}
+
+// TableName returns the table name for the DigestEntry model
+func (DigestEntry) TableName() string {
+	return "reminder_digest_entries"
+}
+
+// DigestSender queues reminders for the next daily digest
+type DigestSender struct {
+	repository DigestRepository
+}
+
+// NewDigestSender creates a sender queuing reminders in repository
+func NewDigestSender(repository DigestRepository) *DigestSender {
+	return &DigestSender{repository: repository}
+}
+
+// Send implements Sender
+func (s *DigestSender) Send(ctx context.Context, reminder events.ReminderDue) error {
+	return s.repository.AddDigestEntry(ctx, &DigestEntry{
+		ID:        common.NewID(),
+		UserID:    common.UserID(reminder.UserID),
+		ChatID:    common.ChatID(reminder.ChatID),
+		TaskID:    common.TaskID(reminder.TaskID),
+		Title:     reminder.Title,
+		Priority:  reminder.Priority,
+		DueDate:   reminder.DueDate,
+		CreatedAt: time.Now(),
+	})
+}
+
+// TaskLookup loads tasks, so that closed tasks are left out of digests. ctx
+// carries the tenant of the digest entry the task is looked up for.
+type TaskLookup interface {
+	GetTaskByID(ctx context.Context, id common.TaskID) (*nudge.Task, error)
+}
+
+// TaskLookupFunc adapts a function to the TaskLookup interface
+type TaskLookupFunc func(ctx context.Context, id common.TaskID) (*nudge.Task, error)
+
+// GetTaskByID implements TaskLookup
+func (f TaskLookupFunc) GetTaskByID(ctx context.Context, id common.TaskID) (*nudge.Task, error) {
+	return f(ctx, id)
+}
+
+// ConflictFinder finds a user's open tasks whose due times overlap
+type ConflictFinder interface {
+	Conflicts(userID common.UserID, from, to time.Time) ([]nudge.TaskConflict, error)
+}
+
+// SettingsLookup loads a user's settings, for the working days digests are
+// sent on
+type SettingsLookup interface {
+	GetNudgeSettingsByUserID(userID common.UserID) (*nudge.NudgeSettings, error)
+}
+
+// TaskMover moves a user's tasks due on a public holiday to their next
+// business day
+type TaskMover interface {
+	GetTasks(userID common.UserID, filter nudge.TaskFilter) ([]*nudge.Task, error)
+	ShiftTaskDueDates(userID common.UserID, taskIDs []common.TaskID, shift nudge.DueShift) ([]*nudge.Task, error)
+}
+
+// holidayLookahead is how far past a holiday the next business day is looked
+// for
+const holidayLookahead = 30
+
+// digestConflictWindow is how far ahead a digest warns about clashing tasks
+const digestConflictWindow = 24 * time.Hour
+
+// Digester sends each chat the reminders queued for its digest
+type Digester struct {
+	repository DigestRepository
+	tasks      TaskLookup
+	conflicts  ConflictFinder
+	settings   SettingsLookup
+	holidays   *holiday.Calendar
+	mover      TaskMover
+	flags      featureflags.Checker
+	eventBus   events.EventBus
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+// NewDigester creates the daily digest job
+func NewDigester(repository DigestRepository, tasks TaskLookup, eventBus events.EventBus, logger *zap.Logger) *Digester {
+	return NewDigesterWithConflicts(repository, tasks, nil, eventBus, logger)
+}
+
+// NewDigesterWithConflicts creates the daily digest job, warning each chat
+// about its tasks due around the same time over the next day. A nil finder
+// leaves conflicts out.
+func NewDigesterWithConflicts(repository DigestRepository, tasks TaskLookup, conflicts ConflictFinder, eventBus events.EventBus, logger *zap.Logger) *Digester {
+	return NewDigesterWithSettings(repository, tasks, conflicts, nil, eventBus, logger)
+}
+
+// NewDigesterWithSettings creates the daily digest job, holding a user's
+// digest on the days off they chose in their working days until their next
+// working day. A nil lookup sends every digest every day.
+func NewDigesterWithSettings(repository DigestRepository, tasks TaskLookup, conflicts ConflictFinder, settings SettingsLookup, eventBus events.EventBus, logger *zap.Logger) *Digester {
+	return NewDigesterWithHolidays(repository, tasks, conflicts, settings, nil, nil, eventBus, logger)
+}
+
+// NewDigesterWithHolidays creates the daily digest job, telling users whose
+// country has a public holiday tomorrow and moving their tasks due that day
+// to their next business day with mover. A nil calendar leaves holidays out;
+// a nil mover only tells.
+func NewDigesterWithHolidays(repository DigestRepository, tasks TaskLookup, conflicts ConflictFinder, settings SettingsLookup, holidays *holiday.Calendar, mover TaskMover, eventBus events.EventBus, logger *zap.Logger) *Digester {
+	return NewDigesterWithFlags(repository, tasks, conflicts, settings, holidays, mover, nil, eventBus, logger)
+}
+
+// NewDigesterWithFlags creates the daily digest job, adding holiday notices
+// and conflict warnings only for users with the new_digest flag; the others
+// get the plain list of reminders. Nil flags give everyone the new digest.
+func NewDigesterWithFlags(repository DigestRepository, tasks TaskLookup, conflicts ConflictFinder, settings SettingsLookup, holidays *holiday.Calendar, mover TaskMover, flags featureflags.Checker, eventBus events.EventBus, logger *zap.Logger) *Digester {
+	return &Digester{
+		repository: repository,
+		tasks:      tasks,
+		conflicts:  conflicts,
+		settings:   settings,
+		holidays:   holidays,
+		mover:      mover,
+		flags:      flags,
+		eventBus:   eventBus,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// Run publishes a ReminderDigestDue per chat with queued reminders and clears
+// the queue. Each task is listed once, and tasks closed since their reminder
+// are left out. Reminders of users on a day off stay queued.
+func (d *Digester) Run(ctx context.Context) error {
+	entries, err := d.repository.DigestEntries(ctx)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	type chatKey struct {
+		userID common.UserID
+		chatID common.ChatID
+	}
+	digests := make(map[chatKey][]events.ReminderDue)
+	listed := make(map[chatKey]map[common.TaskID]bool)
+	var order []chatKey
+	processed := make([]common.ID, 0, len(entries))
+	dayOff := make(map[common.UserID]bool)
+	held := 0
+
+	for _, entry := range entries {
+		off, checked := dayOff[entry.UserID]
+		if !checked {
+			off = d.isDayOff(entry.UserID)
+			dayOff[entry.UserID] = off
+		}
+		if off {
+			held++
+			continue
+		}
+
+		processed = append(processed, entry.ID)
+		owner := tenant.WithID(tenant.WithUser(ctx, entry.UserID), entry.TenantID)
+		if task, err := d.tasks.GetTaskByID(owner, entry.TaskID); err != nil || !task.Status.IsOpen() {
+			continue
+		}
+
+		key := chatKey{userID: entry.UserID, chatID: entry.ChatID}
+		if listed[key] == nil {
+			listed[key] = make(map[common.TaskID]bool)
+			order = append(order, key)
+		}
+		if listed[key][entry.TaskID] {
+			continue
+		}
+		listed[key][entry.TaskID] = true
+
+		digests[key] = append(digests[key], events.ReminderDue{
+			TaskID:   string(entry.TaskID),
+			UserID:   string(entry.UserID),
+			ChatID:   string(entry.ChatID),
+			Title:    entry.Title,
+			DueDate:  entry.DueDate,
+			Priority: entry.Priority,
+		})
+	}
+
+	noticed := make(map[common.UserID]bool)
+	for _, key := range order {
+		reminders := digests[key]
+		enriched := d.newDigest(key.userID)
+
+		// Tasks are moved off a holiday once per user, before conflicts are
+		// looked for at their new times
+		var notice *events.HolidayNotice
+		if enriched && !noticed[key.userID] {
+			noticed[key.userID] = true
+			var moved map[common.TaskID]*time.Time
+			notice, moved = d.moveOffHoliday(key.userID)
+			for i := range reminders {
+				if due, ok := moved[common.TaskID(reminders[i].TaskID)]; ok {
+					reminders[i].DueDate = due
+				}
+			}
+		}
+
+		var conflicts []events.TaskConflict
+		if enriched {
+			conflicts = d.upcomingConflicts(key.userID)
+		}
+		for len(reminders) > 0 {
+			size := min(len(reminders), MaxDigestSize)
+			digest := events.ReminderDigestDue{
+				Event:     events.NewEvent(),
+				UserID:    string(key.userID),
+				ChatID:    string(key.chatID),
+				Reminders: reminders[:size],
+				Conflicts: conflicts,
+				Holiday:   notice,
+			}
+			if err := d.eventBus.Publish(events.TopicReminderDigestDue, digest); err != nil {
+				return err
+			}
+			reminders = reminders[size:]
+			conflicts = nil
+			notice = nil
+		}
+	}
+
+	if err := d.repository.DeleteDigestEntries(ctx, processed); err != nil {
+		return err
+	}
+
+	d.logger.Info("Sent reminder digests",
+		zap.Int("chats", len(order)),
+		zap.Int("entries", len(processed)),
+		zap.Int("held", held))
+	return nil
+}
+
+// isDayOff reports whether today, in the user's time zone, is not one of the
+// working days they chose. Users who never chose working days get a digest
+// every day.
+func (d *Digester) isDayOff(userID common.UserID) bool {
+	if d.settings == nil {
+		return false
+	}
+
+	settings, err := d.settings.GetNudgeSettingsByUserID(userID)
+	if err != nil || settings == nil || settings.WorkingDays == "" {
+		return false
+	}
+	return !settings.Week().IsWorkingDay(d.now().In(settings.Location()))
+}
+
+// newDigest reports whether the user's digest tells about holidays and
+// conflicts
+func (d *Digester) newDigest(userID common.UserID) bool {
+	return d.flags == nil || d.flags.IsEnabled(featureflags.FlagNewDigest, userID)
+}
+
+// moveOffHoliday checks whether tomorrow, in the user's time zone, is a
+// public holiday of their country. If it is, their open tasks due that day
+// are moved to their next business day, keeping the time of day; the notice
+// and the moved tasks' new due dates are returned.
+func (d *Digester) moveOffHoliday(userID common.UserID) (*events.HolidayNotice, map[common.TaskID]*time.Time) {
+	if d.holidays == nil || d.settings == nil {
+		return nil, nil
+	}
+	settings, err := d.settings.GetNudgeSettingsByUserID(userID)
+	if err != nil || settings == nil || settings.Country == "" {
+		return nil, nil
+	}
+
+	now := d.now().In(settings.Location())
+	tomorrow := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location())
+	day, ok := d.holidays.On(settings.Country, tomorrow)
+	if !ok {
+		return nil, nil
+	}
+	notice := &events.HolidayNotice{Name: day.Name, Date: tomorrow}
+	if d.mover == nil {
+		return notice, nil
+	}
+
+	end := tomorrow.AddDate(0, 0, 1).Add(-time.Microsecond)
+	due, err := d.mover.GetTasks(userID, nudge.TaskFilter{
+		UserID:    userID,
+		Statuses:  common.OpenTaskStatuses(),
+		DueAfter:  &tomorrow,
+		DueBefore: &end,
+	})
+	if err != nil || len(due) == 0 {
+		if err != nil {
+			d.logger.Warn("Failed to find tasks due on a holiday",
+				zap.String("user_id", string(userID)),
+				zap.Error(err))
+		}
+		return notice, nil
+	}
+
+	upcoming := d.holidays.Between(settings.Country, tomorrow, tomorrow.AddDate(0, 0, holidayLookahead))
+	next := holiday.NextBusinessDay(tomorrow, settings.Week(), upcoming)
+	// Whole days, like /moveto, so tasks keep their time of day
+	days := int(next.Sub(tomorrow).Round(24*time.Hour) / (24 * time.Hour))
+
+	taskIDs := make([]common.TaskID, len(due))
+	for i, task := range due {
+		taskIDs[i] = task.ID
+	}
+	moved, err := d.mover.ShiftTaskDueDates(userID, taskIDs, nudge.DueShift{Days: days})
+	if err != nil {
+		d.logger.Warn("Failed to move tasks off a holiday",
+			zap.String("user_id", string(userID)),
+			zap.String("holiday", day.Name),
+			zap.Error(err))
+		return notice, nil
+	}
+
+	dueDates := make(map[common.TaskID]*time.Time, len(moved))
+	for _, task := range moved {
+		dueDates[task.ID] = task.DueDate
+	}
+	notice.Moved = len(moved)
+	notice.MovedTo = next
+	return notice, dueDates
+}
+
+// upcomingConflicts returns the user's clashing tasks due over the next day
+func (d *Digester) upcomingConflicts(userID common.UserID) []events.TaskConflict {
+	if d.conflicts == nil {
+		return nil
+	}
+
+	now := d.now()
+	conflicts, err := d.conflicts.Conflicts(userID, now, now.Add(digestConflictWindow))
+	if err != nil {
+		d.logger.Warn("Failed to check digest tasks for conflicts",
+			zap.String("user_id", string(userID)),
+			zap.Error(err))
+		return nil
+	}
+
+	converted := make([]events.TaskConflict, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		converted = append(converted, conflict.ToEvent())
+	}
+	return converted
+}
@@ -0,0 +1,72 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+
+	"nudgebot-api/internal/common"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// DigestRepository persists the reminders queued for daily digests
+type DigestRepository interface {
+	AddDigestEntry(ctx context.Context, entry *DigestEntry) error
+	// DigestEntries returns the queued entries, oldest first
+	DigestEntries(ctx context.Context) ([]DigestEntry, error)
+	DeleteDigestEntries(ctx context.Context, ids []common.ID) error
+}
+
+// gormDigestRepository implements DigestRepository using GORM
+type gormDigestRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewGormDigestRepository creates a new GORM-based digest repository
+func NewGormDigestRepository(db *gorm.DB, logger *zap.Logger) DigestRepository {
+	return &gormDigestRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// AddDigestEntry queues a reminder for the next digest
+func (r *gormDigestRepository) AddDigestEntry(ctx context.Context, entry *DigestEntry) error {
+	if entry.ID == "" {
+		entry.ID = common.NewID()
+	}
+	if err := r.db.WithContext(ctx).Create(entry).Error; err != nil {
+		return fmt.Errorf("failed to queue digest entry: %w", err)
+	}
+	return nil
+}
+
+// DigestEntries returns the queued entries, oldest first
+func (r *gormDigestRepository) DigestEntries(ctx context.Context) ([]DigestEntry, error) {
+	var entries []DigestEntry
+	if err := r.db.WithContext(ctx).Order("created_at").Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to load digest entries: %w", err)
+	}
+	return entries, nil
+}
+
+// DeleteDigestEntries removes entries once they went out in a digest
+func (r *gormDigestRepository) DeleteDigestEntries(ctx context.Context, ids []common.ID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Delete(&DigestEntry{}).Error; err != nil {
+		return fmt.Errorf("failed to delete digest entries: %w", err)
+	}
+	return nil
+}
+
+// RunMigrations creates the notification tables
+func RunMigrations(db *gorm.DB) error {
+	if err := db.AutoMigrate(&DigestEntry{}); err != nil {
+		return fmt.Errorf("failed to auto-migrate notification tables: %w", err)
+	}
+	return nil
+}
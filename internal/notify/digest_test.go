@@ -0,0 +1,290 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/featureflags"
+	"nudgebot-api/internal/holiday"
+	"nudgebot-api/internal/nudge"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryDigestRepository keeps digest entries in memory
+type memoryDigestRepository struct {
+	entries []DigestEntry
+}
+
+func (r *memoryDigestRepository) AddDigestEntry(ctx context.Context, entry *DigestEntry) error {
+	r.entries = append(r.entries, *entry)
+	return nil
+}
+
+func (r *memoryDigestRepository) DigestEntries(ctx context.Context) ([]DigestEntry, error) {
+	return append([]DigestEntry(nil), r.entries...), nil
+}
+
+func (r *memoryDigestRepository) DeleteDigestEntries(ctx context.Context, ids []common.ID) error {
+	deleted := make(map[common.ID]bool)
+	for _, id := range ids {
+		deleted[id] = true
+	}
+	kept := r.entries[:0]
+	for _, entry := range r.entries {
+		if !deleted[entry.ID] {
+			kept = append(kept, entry)
+		}
+	}
+	r.entries = kept
+	return nil
+}
+
+// taskStatuses looks up tasks by ID, knowing only their status
+type taskStatuses map[common.TaskID]common.TaskStatus
+
+func (t taskStatuses) GetTaskByID(ctx context.Context, id common.TaskID) (*nudge.Task, error) {
+	status, ok := t[id]
+	if !ok {
+		return nil, errors.New("task not found")
+	}
+	return &nudge.Task{ID: id, Status: status}, nil
+}
+
+func TestDigester_SendsOneDigestPerChat(t *testing.T) {
+	repository := &memoryDigestRepository{}
+	sender := NewDigestSender(repository)
+	queue := func(userID, chatID, taskID string) {
+		require.NoError(t, sender.Send(context.Background(), events.ReminderDue{
+			TaskID: taskID, UserID: userID, ChatID: chatID, Title: "Task " + taskID,
+		}))
+	}
+	queue("ada", "1", "a")
+	queue("bob", "2", "b")
+	queue("ada", "1", "c")
+	queue("ada", "1", "a") // a nudge for a task already queued
+	queue("ada", "1", "done")
+	queue("ada", "1", "gone")
+
+	tasks := taskStatuses{"a": common.TaskStatusActive, "b": common.TaskStatusActive, "c": common.TaskStatusActive, "done": common.TaskStatusCompleted}
+	eventBus := events.NewMockEventBus()
+	require.NoError(t, NewDigester(repository, tasks, eventBus, zap.NewNop()).Run(context.Background()))
+
+	published := eventBus.GetPublishedEvents(events.TopicReminderDigestDue)
+	require.Len(t, published, 2)
+	ada := published[0].(events.ReminderDigestDue)
+	assert.Equal(t, "ada", ada.UserID)
+	assert.Equal(t, "1", ada.ChatID)
+	require.Len(t, ada.Reminders, 2, "each open task is listed once")
+	assert.Equal(t, "a", ada.Reminders[0].TaskID)
+	assert.Equal(t, "c", ada.Reminders[1].TaskID)
+	assert.Equal(t, "bob", published[1].(events.ReminderDigestDue).UserID)
+
+	assert.Empty(t, repository.entries, "sent and dropped entries are cleared")
+}
+
+func TestDigester_SplitsLargeDigests(t *testing.T) {
+	repository := &memoryDigestRepository{}
+	tasks := taskStatuses{}
+	for i := 0; i < MaxDigestSize+1; i++ {
+		taskID := common.TaskID(fmt.Sprintf("task-%d", i))
+		tasks[taskID] = common.TaskStatusActive
+		repository.entries = append(repository.entries, DigestEntry{ID: common.NewID(), UserID: "ada", ChatID: "1", TaskID: taskID})
+	}
+
+	eventBus := events.NewMockEventBus()
+	require.NoError(t, NewDigester(repository, tasks, eventBus, zap.NewNop()).Run(context.Background()))
+
+	published := eventBus.GetPublishedEvents(events.TopicReminderDigestDue)
+	require.Len(t, published, 2)
+	assert.Len(t, published[0].(events.ReminderDigestDue).Reminders, MaxDigestSize)
+	assert.Len(t, published[1].(events.ReminderDigestDue).Reminders, 1)
+}
+
+// fixedConflicts reports the same conflicts for every user
+type fixedConflicts []nudge.TaskConflict
+
+func (c fixedConflicts) Conflicts(userID common.UserID, from, to time.Time) ([]nudge.TaskConflict, error) {
+	return c, nil
+}
+
+func TestDigester_WarnsAboutConflicts(t *testing.T) {
+	repository := &memoryDigestRepository{}
+	tasks := taskStatuses{}
+	for i := 0; i < MaxDigestSize+1; i++ {
+		taskID := common.TaskID(fmt.Sprintf("task-%d", i))
+		tasks[taskID] = common.TaskStatusActive
+		repository.entries = append(repository.entries, DigestEntry{ID: common.NewID(), UserID: "ada", ChatID: "1", TaskID: taskID})
+	}
+	due := time.Date(2024, 6, 3, 15, 0, 0, 0, time.UTC)
+	later := due.Add(10 * time.Minute)
+	conflicts := fixedConflicts{{
+		Task:  &nudge.Task{ID: "a", Title: "Dentist", DueDate: &due},
+		Other: &nudge.Task{ID: "b", Title: "Team call", DueDate: &later},
+	}}
+
+	eventBus := events.NewMockEventBus()
+	require.NoError(t, NewDigesterWithConflicts(repository, tasks, conflicts, eventBus, zap.NewNop()).Run(context.Background()))
+
+	published := eventBus.GetPublishedEvents(events.TopicReminderDigestDue)
+	require.Len(t, published, 2)
+	first := published[0].(events.ReminderDigestDue)
+	require.Len(t, first.Conflicts, 1)
+	assert.Equal(t, "Dentist", first.Conflicts[0].Title)
+	assert.Equal(t, "Team call", first.Conflicts[0].OtherTitle)
+	assert.Empty(t, published[1].(events.ReminderDigestDue).Conflicts, "conflicts are listed once per digest")
+}
+
+// settingsByUser looks up settings from a map
+type settingsByUser map[common.UserID]*nudge.NudgeSettings
+
+func (s settingsByUser) GetNudgeSettingsByUserID(userID common.UserID) (*nudge.NudgeSettings, error) {
+	settings, ok := s[userID]
+	if !ok {
+		return nil, errors.New("settings not found")
+	}
+	return settings, nil
+}
+
+func TestDigester_HoldsDigestsOnDaysOff(t *testing.T) {
+	repository := &memoryDigestRepository{}
+	for _, userID := range []common.UserID{"ada", "bob", "eve"} {
+		repository.entries = append(repository.entries, DigestEntry{ID: common.NewID(), UserID: userID, ChatID: common.ChatID(userID), TaskID: "task-" + common.TaskID(userID)})
+	}
+	tasks := taskStatuses{"task-ada": common.TaskStatusActive, "task-bob": common.TaskStatusActive, "task-eve": common.TaskStatusActive}
+	settings := settingsByUser{
+		"ada": {WorkingDays: "sun-thu"},
+		// Still Thursday evening in Los Angeles
+		"bob": {WorkingDays: "mon-thu", Timezone: "America/Los_Angeles"},
+	}
+
+	eventBus := events.NewMockEventBus()
+	digester := NewDigesterWithSettings(repository, tasks, nil, settings, eventBus, zap.NewNop())
+	// Friday 06:00 UTC
+	digester.now = func() time.Time { return time.Date(2024, 6, 7, 6, 0, 0, 0, time.UTC) }
+	require.NoError(t, digester.Run(context.Background()))
+
+	published := eventBus.GetPublishedEvents(events.TopicReminderDigestDue)
+	require.Len(t, published, 2)
+	assert.Equal(t, "bob", published[0].(events.ReminderDigestDue).UserID)
+	assert.Equal(t, "eve", published[1].(events.ReminderDigestDue).UserID, "users without working days get a digest every day")
+
+	require.Len(t, repository.entries, 1, "a day off keeps the reminders for the next digest")
+	assert.Equal(t, common.UserID("ada"), repository.entries[0].UserID)
+}
+
+// memoryTaskMover keeps tasks in memory and records the shifts applied
+type memoryTaskMover struct {
+	tasks  []*nudge.Task
+	shifts []nudge.DueShift
+}
+
+func (m *memoryTaskMover) GetTasks(userID common.UserID, filter nudge.TaskFilter) ([]*nudge.Task, error) {
+	var found []*nudge.Task
+	for _, task := range m.tasks {
+		if task.UserID == userID && task.DueDate != nil && !task.DueDate.Before(*filter.DueAfter) && !task.DueDate.After(*filter.DueBefore) {
+			found = append(found, task)
+		}
+	}
+	return found, nil
+}
+
+func (m *memoryTaskMover) ShiftTaskDueDates(userID common.UserID, taskIDs []common.TaskID, shift nudge.DueShift) ([]*nudge.Task, error) {
+	m.shifts = append(m.shifts, shift)
+	var moved []*nudge.Task
+	for _, task := range m.tasks {
+		for _, id := range taskIDs {
+			if task.ID == id {
+				due := shift.Apply(*task.DueDate, time.UTC)
+				task.DueDate = &due
+				moved = append(moved, task)
+			}
+		}
+	}
+	return moved, nil
+}
+
+func TestDigester_MovesTasksOffHolidays(t *testing.T) {
+	// Christmas Day is tomorrow, then Boxing Day, then the weekend
+	christmas := time.Date(2026, 12, 25, 10, 0, 0, 0, time.UTC)
+	sameDay := time.Date(2026, 12, 25, 16, 30, 0, 0, time.UTC)
+	after := time.Date(2026, 12, 28, 9, 0, 0, 0, time.UTC)
+	mover := &memoryTaskMover{tasks: []*nudge.Task{
+		{ID: "call", UserID: "ada", DueDate: &christmas},
+		{ID: "pay", UserID: "ada", DueDate: &sameDay},
+		{ID: "later", UserID: "ada", DueDate: &after},
+	}}
+
+	repository := &memoryDigestRepository{entries: []DigestEntry{
+		{ID: common.NewID(), UserID: "ada", ChatID: "1", TaskID: "call", DueDate: &christmas},
+		{ID: common.NewID(), UserID: "bob", ChatID: "2", TaskID: "other"},
+	}}
+	tasks := taskStatuses{"call": common.TaskStatusActive, "other": common.TaskStatusActive}
+	settings := settingsByUser{"ada": {Country: "GB"}, "bob": {}}
+	calendar := holiday.NewCalendar(holiday.BuiltinSource{}, zap.NewNop())
+
+	eventBus := events.NewMockEventBus()
+	digester := NewDigesterWithHolidays(repository, tasks, nil, settings, calendar, mover, eventBus, zap.NewNop())
+	digester.now = func() time.Time { return time.Date(2026, 12, 24, 8, 0, 0, 0, time.UTC) }
+	require.NoError(t, digester.Run(context.Background()))
+
+	published := eventBus.GetPublishedEvents(events.TopicReminderDigestDue)
+	require.Len(t, published, 2)
+	ada := published[0].(events.ReminderDigestDue)
+	require.NotNil(t, ada.Holiday)
+	assert.Equal(t, "Christmas Day", ada.Holiday.Name)
+	assert.Equal(t, 2, ada.Holiday.Moved)
+	assert.Equal(t, time.Date(2026, 12, 28, 0, 0, 0, 0, time.UTC), ada.Holiday.MovedTo, "Boxing Day and the weekend are skipped")
+	assert.Equal(t, time.Date(2026, 12, 28, 10, 0, 0, 0, time.UTC), *ada.Reminders[0].DueDate, "the digest lists the new due date")
+	assert.Equal(t, time.Date(2026, 12, 28, 16, 30, 0, 0, time.UTC), *mover.tasks[1].DueDate)
+	assert.Equal(t, after, *mover.tasks[2].DueDate)
+
+	assert.Nil(t, published[1].(events.ReminderDigestDue).Holiday, "users without a country observe no holidays")
+}
+
+func TestDigester_NewDigestFlag(t *testing.T) {
+	christmas := time.Date(2026, 12, 25, 10, 0, 0, 0, time.UTC)
+	later := christmas.Add(10 * time.Minute)
+	mover := &memoryTaskMover{tasks: []*nudge.Task{
+		{ID: "call", UserID: "ada", DueDate: &christmas},
+		{ID: "send", UserID: "bob", DueDate: &christmas},
+	}}
+	repository := &memoryDigestRepository{entries: []DigestEntry{
+		{ID: common.NewID(), UserID: "ada", ChatID: "1", TaskID: "call", DueDate: &christmas},
+		{ID: common.NewID(), UserID: "bob", ChatID: "2", TaskID: "send", DueDate: &christmas},
+	}}
+	tasks := taskStatuses{"call": common.TaskStatusActive, "send": common.TaskStatusActive}
+	settings := settingsByUser{"ada": {Country: "GB"}, "bob": {Country: "GB"}}
+	conflicts := fixedConflicts{{
+		Task:  &nudge.Task{ID: "a", Title: "Dentist", DueDate: &christmas},
+		Other: &nudge.Task{ID: "b", Title: "Team call", DueDate: &later},
+	}}
+	calendar := holiday.NewCalendar(holiday.BuiltinSource{}, zap.NewNop())
+	flags := featureflags.CheckerFunc(func(flag string, userID common.UserID) bool {
+		return flag == featureflags.FlagNewDigest && userID == "ada"
+	})
+
+	eventBus := events.NewMockEventBus()
+	digester := NewDigesterWithFlags(repository, tasks, conflicts, settings, calendar, mover, flags, eventBus, zap.NewNop())
+	digester.now = func() time.Time { return time.Date(2026, 12, 24, 8, 0, 0, 0, time.UTC) }
+	require.NoError(t, digester.Run(context.Background()))
+
+	published := eventBus.GetPublishedEvents(events.TopicReminderDigestDue)
+	require.Len(t, published, 2)
+	ada := published[0].(events.ReminderDigestDue)
+	require.NotNil(t, ada.Holiday)
+	assert.Len(t, ada.Conflicts, 1)
+	assert.NotEqual(t, christmas, *mover.tasks[0].DueDate)
+
+	bob := published[1].(events.ReminderDigestDue)
+	assert.Nil(t, bob.Holiday, "users without the flag get the plain digest")
+	assert.Empty(t, bob.Conflicts)
+	assert.Equal(t, christmas, *mover.tasks[1].DueDate, "their tasks stay where they are")
+}
@@ -0,0 +1,192 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/config"
+	"nudgebot-api/internal/events"
+)
+
+// ErrNoEmailAddress is returned for reminders routed to email for users
+// without an address
+var ErrNoEmailAddress = errors.New("user has no email address")
+
+// Mailer sends a plain text email
+type Mailer interface {
+	SendMail(ctx context.Context, to, subject, body string) error
+}
+
+// AddressBook looks up where to email a user
+type AddressBook interface {
+	EmailAddress(userID common.UserID) (string, error)
+}
+
+// AddressBookFunc adapts a function to the AddressBook interface
+type AddressBookFunc func(userID common.UserID) (string, error)
+
+// EmailAddress implements AddressBook
+func (f AddressBookFunc) EmailAddress(userID common.UserID) (string, error) {
+	return f(userID)
+}
+
+// EmailSender emails reminders to the user's address
+type EmailSender struct {
+	mailer    Mailer
+	addresses AddressBook
+}
+
+// NewEmailSender creates a sender emailing reminders through mailer
+func NewEmailSender(mailer Mailer, addresses AddressBook) *EmailSender {
+	return &EmailSender{
+		mailer:    mailer,
+		addresses: addresses,
+	}
+}
+
+// Send implements Sender
+func (s *EmailSender) Send(ctx context.Context, reminder events.ReminderDue) error {
+	address, err := s.addresses.EmailAddress(common.UserID(reminder.UserID))
+	if err != nil {
+		return fmt.Errorf("failed to look up email address: %w", err)
+	}
+	if address == "" {
+		return ErrNoEmailAddress
+	}
+
+	subject, body := formatReminderEmail(reminder)
+	return s.mailer.SendMail(ctx, address, subject, body)
+}
+
+// formatReminderEmail writes the subject and body of a reminder email
+func formatReminderEmail(reminder events.ReminderDue) (string, string) {
+	title := reminder.Title
+	if title == "" {
+		title = "Task " + reminder.TaskID
+	}
+
+	var body strings.Builder
+	body.WriteString("You have a task that needs attention:\r\n\r\n")
+	body.WriteString(title + "\r\n")
+	if reminder.DueDate != nil {
+		body.WriteString("Due: " + reminder.DueDate.Format("Mon Jan 2, 2006 at 15:04 MST") + "\r\n")
+	}
+	if reminder.Priority != "" {
+		body.WriteString("Priority: " + reminder.Priority + "\r\n")
+	}
+	body.WriteString("\r\nOpen the chat with NudgeBot to mark it done or snooze it.\r\n")
+
+	return "Reminder: " + title, body.String()
+}
+
+// SMTPMailer sends email through an SMTP server, upgrading to TLS when the
+// server offers it
+type SMTPMailer struct {
+	config config.EmailConfig
+	from   *mail.Address
+}
+
+// NewSMTPMailer creates a mailer for the configured SMTP server
+func NewSMTPMailer(cfg config.EmailConfig) (*SMTPMailer, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("email host is required")
+	}
+	if cfg.Port <= 0 || cfg.Port > 65535 {
+		return nil, fmt.Errorf("email port %d is not a valid port", cfg.Port)
+	}
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("email from address %q is invalid: %w", cfg.From, err)
+	}
+	return &SMTPMailer{config: cfg, from: from}, nil
+}
+
+// SendMail implements Mailer
+func (m *SMTPMailer) SendMail(ctx context.Context, to, subject, body string) error {
+	recipient, err := mail.ParseAddress(to)
+	if err != nil {
+		return fmt.Errorf("invalid recipient %q: %w", to, err)
+	}
+
+	timeout := time.Duration(m.config.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = sendTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+
+	dialer := &net.Dialer{Deadline: deadline}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(m.config.Host, strconv.Itoa(m.config.Port)))
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, m.config.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.config.Host}); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+	if m.config.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(m.from.Address); err != nil {
+		return fmt.Errorf("SMTP server rejected sender: %w", err)
+	}
+	if err := client.Rcpt(recipient.Address); err != nil {
+		return fmt.Errorf("SMTP server rejected recipient: %w", err)
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start message: %w", err)
+	}
+	if _, err := writer.Write(buildMessage(m.from, recipient, subject, body, time.Now())); err != nil {
+		writer.Close()
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("SMTP server rejected message: %w", err)
+	}
+	return client.Quit()
+}
+
+// buildMessage formats a plain text UTF-8 email
+func buildMessage(from, to *mail.Address, subject, body string, date time.Time) []byte {
+	var message strings.Builder
+	message.WriteString("From: " + from.String() + "\r\n")
+	message.WriteString("To: " + to.String() + "\r\n")
+	message.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	message.WriteString("Date: " + date.Format(time.RFC1123Z) + "\r\n")
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	message.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	message.WriteString("\r\n")
+	message.WriteString(body)
+	return []byte(message.String())
+}
@@ -0,0 +1,70 @@
+package notify
+
+import (
+	"context"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/config"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubMailer records the last email
+type stubMailer struct {
+	to, subject, body string
+}
+
+func (m *stubMailer) SendMail(ctx context.Context, to, subject, body string) error {
+	m.to, m.subject, m.body = to, subject, body
+	return nil
+}
+
+func TestEmailSender_Send(t *testing.T) {
+	mailer := &stubMailer{}
+	addresses := map[common.UserID]string{"user": "ada@example.com"}
+	sender := NewEmailSender(mailer, AddressBookFunc(func(userID common.UserID) (string, error) {
+		return addresses[userID], nil
+	}))
+
+	due := time.Date(2024, 1, 12, 9, 0, 0, 0, time.UTC)
+	require.NoError(t, sender.Send(context.Background(), events.ReminderDue{
+		TaskID: "1", UserID: "user", Title: "Pay rent", DueDate: &due, Priority: "high",
+	}))
+	assert.Equal(t, "ada@example.com", mailer.to)
+	assert.Equal(t, "Reminder: Pay rent", mailer.subject)
+	assert.Contains(t, mailer.body, "Due: Fri Jan 12, 2024 at 09:00 UTC")
+	assert.Contains(t, mailer.body, "Priority: high")
+
+	err := sender.Send(context.Background(), events.ReminderDue{TaskID: "2", UserID: "someone"})
+	assert.ErrorIs(t, err, ErrNoEmailAddress)
+}
+
+func TestBuildMessage_EncodesHeaders(t *testing.T) {
+	from := &mail.Address{Name: "NudgeBot", Address: "nudge@example.com"}
+	to := &mail.Address{Address: "ada@example.com"}
+
+	message := string(buildMessage(from, to, "Reminder: Café\r\nBcc: x@example.com", "body", time.Unix(0, 0).UTC()))
+	headers, body, ok := strings.Cut(message, "\r\n\r\n")
+	require.True(t, ok)
+	assert.Equal(t, "body", body)
+	assert.Contains(t, headers, `From: "NudgeBot" <nudge@example.com>`)
+	assert.Contains(t, headers, "Subject: =?utf-8?q?")
+	assert.NotContains(t, headers, "\r\nBcc:", "a title cannot inject headers")
+}
+
+func TestNewSMTPMailer_Validates(t *testing.T) {
+	_, err := NewSMTPMailer(config.EmailConfig{Host: "smtp.example.com", Port: 587, From: "nudge@example.com"})
+	assert.NoError(t, err)
+
+	_, err = NewSMTPMailer(config.EmailConfig{Host: "smtp.example.com", Port: 587})
+	assert.Error(t, err, "a sender address is required")
+
+	_, err = NewSMTPMailer(config.EmailConfig{From: "nudge@example.com", Port: 587})
+	assert.Error(t, err)
+}
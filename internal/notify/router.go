@@ -0,0 +1,80 @@
+// Package notify delivers reminders over the channels users route them to
+// besides Telegram, such as email and the daily digest.
+package notify
+
+import (
+	"context"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// sendTimeout bounds how long one channel may take to deliver a reminder
+const sendTimeout = 30 * time.Second
+
+// Sender delivers a reminder over one channel
+type Sender interface {
+	Send(ctx context.Context, reminder events.ReminderDue) error
+}
+
+// Router sends each due reminder to the channels it is routed to. Telegram
+// reminders are sent by the chatbot, so the router skips that channel.
+type Router struct {
+	logger  *zap.Logger
+	senders map[string]Sender
+	ready   *common.Readiness
+}
+
+// NewRouter creates a router delivering reminders with the senders, keyed by
+// channel. Reminders routed to a channel without a sender are logged and dropped.
+func NewRouter(eventBus events.EventBus, logger *zap.Logger, senders map[string]Sender) *Router {
+	router := &Router{
+		logger:  logger,
+		senders: senders,
+		ready:   common.NewReadiness(),
+	}
+
+	if err := eventBus.Subscribe(events.TopicReminderDue, router.handleReminderDue); err != nil {
+		logger.Error("Failed to subscribe to ReminderDue events", zap.Error(err))
+	}
+	router.ready.MarkReady()
+
+	return router
+}
+
+// Ready returns a channel that is closed once the router is subscribed
+func (r *Router) Ready() <-chan struct{} {
+	return r.ready.Ready()
+}
+
+// handleReminderDue delivers a reminder over each of its channels but Telegram
+func (r *Router) handleReminderDue(event events.ReminderDue) {
+	for _, channel := range event.Channels {
+		if channel == events.ReminderChannelTelegram {
+			continue
+		}
+
+		log := r.logger.With(
+			zap.String("channel", channel),
+			zap.String("task_id", event.TaskID),
+			zap.String("user_id", event.UserID))
+
+		sender, ok := r.senders[channel]
+		if !ok {
+			log.Warn("No sender for reminder channel, dropping reminder")
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		err := sender.Send(ctx, event)
+		cancel()
+		if err != nil {
+			log.Error("Failed to send reminder", zap.Error(err))
+			continue
+		}
+		log.Debug("Reminder sent")
+	}
+}
@@ -0,0 +1,46 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// recordingSender records the reminders it is asked to send
+type recordingSender struct {
+	mu   sync.Mutex
+	sent []events.ReminderDue
+	err  error
+}
+
+func (s *recordingSender) Send(ctx context.Context, reminder events.ReminderDue) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, reminder)
+	return s.err
+}
+
+func TestRouter_SendsToRoutedChannels(t *testing.T) {
+	email := &recordingSender{err: errors.New("smtp down")}
+	digest := &recordingSender{}
+	router := NewRouter(events.NewMockEventBus(), zap.NewNop(), map[string]Sender{
+		events.ReminderChannelEmail:  email,
+		events.ReminderChannelDigest: digest,
+	})
+
+	router.handleReminderDue(events.ReminderDue{TaskID: "1", Channels: []string{"telegram", "email", "sms"}})
+	router.handleReminderDue(events.ReminderDue{TaskID: "2", Channels: []string{"digest"}})
+	router.handleReminderDue(events.ReminderDue{TaskID: "3"})
+
+	require.Len(t, email.sent, 1, "a failing channel does not stop the others")
+	assert.Equal(t, "1", email.sent[0].TaskID)
+	require.Len(t, digest.sent, 1)
+	assert.Equal(t, "2", digest.sent[0].TaskID)
+}
@@ -0,0 +1,127 @@
+package nudge
+
+import (
+	"sync"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// activityWriteInterval limits how often a chat's activity is written to the
+// repository; a chat in conversation produces many updates in a row
+const activityWriteInterval = 30 * time.Second
+
+// ChatActivity records when a user last interacted with the bot in a chat
+type ChatActivity struct {
+	ChatID       common.ChatID `json:"chat_id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID     string        `json:"tenant_id,omitempty" gorm:"type:varchar(64);not null;default:'default';index"`
+	UserID       common.UserID `json:"user_id" gorm:"type:varchar(36);not null"`
+	LastActiveAt time.Time     `json:"last_active_at" gorm:"type:timestamp;not null"`
+}
+
+// TableName returns the table name for the ChatActivity model
+func (ChatActivity) TableName() string {
+	return "chat_activity"
+}
+
+// ActivityTracker records user activity per chat and reports the latest one
+type ActivityTracker interface {
+	// LastActivity returns when a user last interacted with the bot in the chat
+	LastActivity(chatID common.ChatID) (time.Time, bool)
+	Ready() <-chan struct{}
+}
+
+// activityTracker implements the ActivityTracker interface
+type activityTracker struct {
+	eventBus   events.EventBus
+	logger     *zap.Logger
+	repository ActivityRepository
+	clock      common.Clock
+	ready      *common.Readiness
+
+	mu      sync.RWMutex
+	latest  map[common.ChatID]time.Time
+	written map[common.ChatID]time.Time
+}
+
+// NewActivityTracker creates an ActivityTracker fed by UserActivity events.
+// Activity is kept in memory and written through to repository, which also
+// answers for chats not seen since the process started.
+func NewActivityTracker(eventBus events.EventBus, logger *zap.Logger, repository ActivityRepository) ActivityTracker {
+	tracker := &activityTracker{
+		eventBus:   eventBus,
+		logger:     logger,
+		repository: repository,
+		clock:      common.NewRealClock(),
+		ready:      common.NewReadiness(),
+		latest:     make(map[common.ChatID]time.Time),
+		written:    make(map[common.ChatID]time.Time),
+	}
+
+	if err := eventBus.Subscribe(events.TopicUserActivity, tracker.handleUserActivity); err != nil {
+		logger.Error("Failed to subscribe to UserActivity events", zap.Error(err))
+	}
+	tracker.ready.MarkReady()
+
+	return tracker
+}
+
+// Ready returns a channel that is closed once the event subscription is registered
+func (t *activityTracker) Ready() <-chan struct{} {
+	return t.ready.Ready()
+}
+
+// LastActivity returns when a user last interacted with the bot in the chat
+func (t *activityTracker) LastActivity(chatID common.ChatID) (time.Time, bool) {
+	t.mu.RLock()
+	at, ok := t.latest[chatID]
+	t.mu.RUnlock()
+	if ok {
+		return at, true
+	}
+
+	activity, err := t.repository.GetChatActivity(chatID)
+	if err != nil || activity == nil {
+		return time.Time{}, false
+	}
+
+	t.mu.Lock()
+	if current, seen := t.latest[chatID]; !seen || activity.LastActiveAt.After(current) {
+		t.latest[chatID] = activity.LastActiveAt
+		t.written[chatID] = activity.LastActiveAt
+	}
+	t.mu.Unlock()
+	return activity.LastActiveAt, true
+}
+
+// handleUserActivity records a user's interaction with the bot
+func (t *activityTracker) handleUserActivity(event events.UserActivity) {
+	chatID := common.ChatID(event.ChatID)
+	now := t.clock.Now()
+
+	t.mu.Lock()
+	t.latest[chatID] = now
+	write := now.Sub(t.written[chatID]) >= activityWriteInterval
+	if write {
+		t.written[chatID] = now
+	}
+	t.mu.Unlock()
+
+	if !write {
+		return
+	}
+
+	activity := &ChatActivity{
+		ChatID:       chatID,
+		UserID:       common.UserID(event.UserID),
+		LastActiveAt: now,
+	}
+	if err := t.repository.RecordChatActivity(activity); err != nil {
+		t.logger.Warn("Failed to record chat activity",
+			zap.String("chatID", event.ChatID),
+			zap.Error(err))
+	}
+}
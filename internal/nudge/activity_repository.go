@@ -0,0 +1,92 @@
+package nudge
+
+import (
+	"errors"
+	"sync"
+
+	"nudgebot-api/internal/common"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ActivityRepository persists the latest user activity per chat
+type ActivityRepository interface {
+	RecordChatActivity(activity *ChatActivity) error
+	// GetChatActivity returns nil when the chat has no recorded activity
+	GetChatActivity(chatID common.ChatID) (*ChatActivity, error)
+}
+
+// gormActivityRepository implements ActivityRepository using GORM
+type gormActivityRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewGormActivityRepository creates a new GORM-based activity repository
+func NewGormActivityRepository(db *gorm.DB, logger *zap.Logger) ActivityRepository {
+	return &gormActivityRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// RecordChatActivity stores the chat's latest activity, replacing the previous one
+func (r *gormActivityRepository) RecordChatActivity(activity *ChatActivity) error {
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "chat_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "last_active_at"}),
+	}).Create(activity).Error
+	if err != nil {
+		return WrapRepositoryError(err, "record chat activity")
+	}
+	return nil
+}
+
+// GetChatActivity returns nil when the chat has no recorded activity
+func (r *gormActivityRepository) GetChatActivity(chatID common.ChatID) (*ChatActivity, error) {
+	var activity ChatActivity
+	err := r.db.Where("chat_id = ?", chatID).First(&activity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, WrapRepositoryError(err, "get chat activity")
+	}
+	return &activity, nil
+}
+
+// memoryActivityRepository implements ActivityRepository in memory
+type memoryActivityRepository struct {
+	mu       sync.RWMutex
+	activity map[common.ChatID]ChatActivity
+}
+
+// NewMemoryActivityRepository creates an in-memory activity repository
+func NewMemoryActivityRepository() ActivityRepository {
+	return &memoryActivityRepository{
+		activity: make(map[common.ChatID]ChatActivity),
+	}
+}
+
+// RecordChatActivity stores the chat's latest activity, replacing the previous one
+func (r *memoryActivityRepository) RecordChatActivity(activity *ChatActivity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.activity[activity.ChatID] = *activity
+	return nil
+}
+
+// GetChatActivity returns nil when the chat has no recorded activity
+func (r *memoryActivityRepository) GetChatActivity(chatID common.ChatID) (*ChatActivity, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	activity, ok := r.activity[chatID]
+	if !ok {
+		return nil, nil
+	}
+	return &activity, nil
+}
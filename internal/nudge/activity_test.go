@@ -0,0 +1,61 @@
+package nudge
+
+import (
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestActivityTracker_RecordsUserActivity(t *testing.T) {
+	bus := events.NewMockEventBus()
+	bus.SetSynchronousMode(true)
+	repository := NewMemoryActivityRepository()
+	tracker := NewActivityTracker(bus, zaptest.NewLogger(t), repository).(*activityTracker)
+	clock := common.NewMockClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	tracker.clock = clock
+
+	chatID := common.ChatID("12345")
+	_, ok := tracker.LastActivity(chatID)
+	assert.False(t, ok)
+
+	publish := func() {
+		require.NoError(t, bus.Publish(events.TopicUserActivity, events.UserActivity{
+			Event: events.NewEvent(), UserID: "user-1", ChatID: string(chatID),
+		}))
+	}
+
+	publish()
+	first := clock.Now()
+	at, ok := tracker.LastActivity(chatID)
+	require.True(t, ok)
+	assert.Equal(t, first, at)
+
+	// Activity inside the write interval is tracked but not written through
+	clock.Advance(10 * time.Second)
+	publish()
+	at, _ = tracker.LastActivity(chatID)
+	assert.Equal(t, clock.Now(), at)
+	stored, err := repository.GetChatActivity(chatID)
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.Equal(t, first, stored.LastActiveAt)
+
+	clock.Advance(activityWriteInterval)
+	publish()
+	stored, err = repository.GetChatActivity(chatID)
+	require.NoError(t, err)
+	assert.Equal(t, clock.Now(), stored.LastActiveAt)
+
+	t.Run("falls back to the repository", func(t *testing.T) {
+		restarted := NewActivityTracker(events.NewMockEventBus(), zaptest.NewLogger(t), repository)
+		at, ok := restarted.LastActivity(chatID)
+		require.True(t, ok)
+		assert.Equal(t, clock.Now(), at)
+	})
+}
@@ -0,0 +1,137 @@
+package nudge
+
+import (
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// GlobalStatsReader counts users, tasks and reminders across the deployment
+type GlobalStatsReader interface {
+	// GlobalStats counts everything, with recent activity since the given time
+	// and reminders sent since remindersSince
+	GlobalStats(since, remindersSince time.Time) (events.GlobalStats, error)
+}
+
+// gormGlobalStatsReader implements GlobalStatsReader using GORM
+type gormGlobalStatsReader struct {
+	db *gorm.DB
+}
+
+// NewGormGlobalStatsReader creates a GORM-based global stats reader
+func NewGormGlobalStatsReader(db *gorm.DB) GlobalStatsReader {
+	return &gormGlobalStatsReader{db: db}
+}
+
+// GlobalStats counts the live tables; archived tasks are left out
+func (r *gormGlobalStatsReader) GlobalStats(since, remindersSince time.Time) (events.GlobalStats, error) {
+	var stats events.GlobalStats
+	counts := []struct {
+		count *int64
+		query *gorm.DB
+	}{
+		{&stats.Users, r.db.Table("users")},
+		{&stats.NewUsers, r.db.Table("users").Where("created_at >= ?", since)},
+		{&stats.OpenTasks, r.db.Model(&Task{}).Where("status NOT IN ?", []common.TaskStatus{common.TaskStatusCompleted, common.TaskStatusDeleted})},
+		{&stats.TasksCreated, r.db.Model(&Task{}).Where("created_at >= ?", since)},
+		{&stats.TasksCompleted, r.db.Model(&Task{}).Where("status = ? AND completed_at >= ?", common.TaskStatusCompleted, since)},
+		{&stats.RemindersSent, r.db.Model(&Reminder{}).Where("sent_at >= ?", remindersSince)},
+	}
+	for _, count := range counts {
+		if err := count.query.Count(count.count).Error; err != nil {
+			return events.GlobalStats{}, WrapRepositoryError(err, "count global stats")
+		}
+	}
+	return stats, nil
+}
+
+// GlobalStatsService answers the /globalstats command
+type GlobalStatsService struct {
+	eventBus events.EventBus
+	logger   *zap.Logger
+	reader   GlobalStatsReader
+	clock    common.Clock
+	ready    *common.Readiness
+}
+
+// NewGlobalStatsService creates a GlobalStatsService counting with reader
+func NewGlobalStatsService(eventBus events.EventBus, logger *zap.Logger, reader GlobalStatsReader) *GlobalStatsService {
+	service := &GlobalStatsService{
+		eventBus: eventBus,
+		logger:   logger,
+		reader:   reader,
+		clock:    common.NewRealClock(),
+		ready:    common.NewReadiness(),
+	}
+
+	if err := eventBus.Subscribe(events.TopicGlobalStatsRequested, service.handleGlobalStatsRequested); err != nil {
+		logger.Error("Failed to subscribe to GlobalStatsRequested events", zap.Error(err))
+	}
+	service.ready.MarkReady()
+
+	return service
+}
+
+// Ready returns a channel that is closed once the event subscription is registered
+func (s *GlobalStatsService) Ready() <-chan struct{} {
+	return s.ready.Ready()
+}
+
+// handleGlobalStatsRequested counts the last week's activity for an admin
+func (s *GlobalStatsService) handleGlobalStatsRequested(event events.GlobalStatsRequested) {
+	response := events.GlobalStatsResponse{
+		Event:  events.NewEvent(),
+		UserID: event.UserID,
+		ChatID: event.ChatID,
+	}
+
+	now := s.clock.Now()
+	stats, err := s.reader.GlobalStats(now.AddDate(0, 0, -7), now.Add(-24*time.Hour))
+	if err != nil {
+		s.logger.Error("Failed to count global stats",
+			zap.String("correlationID", event.CorrelationID),
+			zap.Error(err))
+		response.Message = "Something went wrong, please try again later."
+	} else {
+		response.Stats = stats
+		response.Success = true
+	}
+
+	if err := s.eventBus.Publish(events.TopicGlobalStatsResponse, response); err != nil {
+		s.logger.Error("Failed to publish GlobalStatsResponse event", zap.Error(err))
+	}
+}
+
+// handleBroadcastRequested looks up everyone who contacted the admin's bot,
+// for the bot to send them the broadcast
+func (s *nudgeService) handleBroadcastRequested(event events.BroadcastRequested) {
+	s = s.forUser(event.UserID)
+	ready := events.BroadcastReady{
+		Event:  events.NewEvent(),
+		UserID: event.UserID,
+		ChatID: event.ChatID,
+		Bot:    event.Bot,
+		Text:   event.Text,
+	}
+
+	if s.users == nil {
+		ready.Message = "Broadcasts need the user directory, which this deployment doesn't keep."
+	} else if telegramIDs, err := s.users.TelegramIDs(event.Bot); err != nil {
+		s.logger.Error("Failed to list broadcast recipients",
+			zap.String("correlationID", event.CorrelationID),
+			zap.String("bot", event.Bot),
+			zap.Error(err))
+		ready.Message = "Something went wrong, please try again later."
+	} else {
+		ready.TelegramIDs = telegramIDs
+		ready.Success = true
+	}
+
+	if err := s.eventBus.Publish(events.TopicBroadcastReady, ready); err != nil {
+		s.logger.Error("Failed to publish BroadcastReady event", zap.Error(err))
+	}
+}
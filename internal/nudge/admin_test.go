@@ -0,0 +1,72 @@
+package nudge
+
+import (
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// globalStatsFunc adapts a function to the GlobalStatsReader interface
+type globalStatsFunc func(since, remindersSince time.Time) (events.GlobalStats, error)
+
+func (f globalStatsFunc) GlobalStats(since, remindersSince time.Time) (events.GlobalStats, error) {
+	return f(since, remindersSince)
+}
+
+func TestGlobalStatsService_CountsTheLastWeek(t *testing.T) {
+	eventBus := events.NewMockEventBus()
+	eventBus.SetSynchronousMode(true)
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+
+	var since, remindersSince time.Time
+	service := NewGlobalStatsService(eventBus, zaptest.NewLogger(t), globalStatsFunc(func(from, reminders time.Time) (events.GlobalStats, error) {
+		since, remindersSince = from, reminders
+		return events.GlobalStats{Users: 3, OpenTasks: 7}, nil
+	}))
+	service.clock = common.NewMockClock(now)
+
+	require.NoError(t, eventBus.Publish(events.TopicGlobalStatsRequested, events.GlobalStatsRequested{Event: events.NewEvent(), UserID: "admin", ChatID: "42"}))
+
+	responses := eventBus.GetPublishedEvents(events.TopicGlobalStatsResponse)
+	require.Len(t, responses, 1)
+	response := responses[0].(events.GlobalStatsResponse)
+	assert.True(t, response.Success)
+	assert.Equal(t, int64(7), response.Stats.OpenTasks)
+	assert.Equal(t, now.AddDate(0, 0, -7), since)
+	assert.Equal(t, now.Add(-24*time.Hour), remindersSince)
+}
+
+func TestNudgeService_FindsBroadcastRecipients(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	eventBus := events.NewMockEventBus()
+	eventBus.SetSynchronousMode(true)
+	users := user.NewMemoryRepository()
+	service, err := NewNudgeServiceWithDelegation(eventBus, logger, NewMemoryNudgeRepository(logger), nil, nil, nil, users)
+	require.NoError(t, err)
+	nudge := service.(*nudgeService)
+
+	for _, profile := range []user.User{
+		{ID: common.UserID(common.NewID()), TelegramID: 2},
+		{ID: common.UserID(common.NewID()), TelegramID: 1},
+		{ID: common.UserID(common.NewID()), TelegramID: 3, Bot: "staging"},
+	} {
+		_, err := users.Upsert(&profile)
+		require.NoError(t, err)
+	}
+
+	nudge.handleBroadcastRequested(events.BroadcastRequested{Event: events.NewEvent(), UserID: "admin", ChatID: "1", Text: "Hello"})
+
+	ready := eventBus.GetPublishedEvents(events.TopicBroadcastReady)
+	require.Len(t, ready, 1)
+	broadcast := ready[0].(events.BroadcastReady)
+	assert.True(t, broadcast.Success)
+	assert.Equal(t, "Hello", broadcast.Text)
+	assert.Equal(t, []int64{1, 2}, broadcast.TelegramIDs, "only the default bot's users")
+}
@@ -0,0 +1,182 @@
+package nudge
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// APITokenPrefix marks personal API tokens so they are recognizable when leaked
+const APITokenPrefix = "nb_"
+
+// apiTokenBytes is the amount of randomness in a personal API token
+const apiTokenBytes = 24
+
+// ErrAPITokenNotFound is returned for unknown or revoked API tokens
+var ErrAPITokenNotFound = errors.New("api token not found")
+
+// APIToken is a personal token used by browser extensions and shortcuts to
+// add tasks for a user. Only a hash of the token is stored.
+type APIToken struct {
+	TokenHash  string        `json:"-" gorm:"primaryKey;type:varchar(64)"`
+	TenantID   string        `json:"tenant_id,omitempty" gorm:"type:varchar(64);not null;default:'default';index"`
+	UserID     common.UserID `json:"user_id" gorm:"type:varchar(36);not null;index"`
+	ChatID     common.ChatID `json:"chat_id" gorm:"type:varchar(36);not null"` // chat that receives confirmations
+	CreatedAt  time.Time     `json:"created_at" gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+	LastUsedAt *time.Time    `json:"last_used_at,omitempty" gorm:"type:timestamp"`
+}
+
+// TableName returns the table name for the APIToken model
+func (APIToken) TableName() string {
+	return "api_tokens"
+}
+
+// APITokenService issues, checks and revokes personal API tokens
+type APITokenService interface {
+	// IssueToken creates a token for the user, replacing any previous one. The
+	// plain token is returned once and cannot be recovered later.
+	IssueToken(userID common.UserID, chatID common.ChatID) (string, error)
+	Authenticate(token string) (*APIToken, error)
+	RevokeTokens(userID common.UserID) (int, error)
+	Ready() <-chan struct{}
+}
+
+// apiTokenService implements the APITokenService interface
+type apiTokenService struct {
+	eventBus   events.EventBus
+	logger     *zap.Logger
+	repository APITokenRepository
+	clock      common.Clock
+	ready      *common.Readiness
+}
+
+// NewAPITokenService creates an APITokenService that answers /apitoken requests from the chat
+func NewAPITokenService(eventBus events.EventBus, logger *zap.Logger, repository APITokenRepository) APITokenService {
+	service := &apiTokenService{
+		eventBus:   eventBus,
+		logger:     logger,
+		repository: repository,
+		clock:      common.NewRealClock(),
+		ready:      common.NewReadiness(),
+	}
+
+	if err := eventBus.Subscribe(events.TopicAPITokenRequested, service.handleAPITokenRequested); err != nil {
+		logger.Error("Failed to subscribe to APITokenRequested events", zap.Error(err))
+	}
+	service.ready.MarkReady()
+
+	return service
+}
+
+// Ready returns a channel that is closed once the event subscription is registered
+func (s *apiTokenService) Ready() <-chan struct{} {
+	return s.ready.Ready()
+}
+
+// IssueToken creates a token for the user, replacing any previous one
+func (s *apiTokenService) IssueToken(userID common.UserID, chatID common.ChatID) (string, error) {
+	secret := make([]byte, apiTokenBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("generate api token: %w", err)
+	}
+	token := APITokenPrefix + hex.EncodeToString(secret)
+
+	if _, err := s.repository.DeleteUserTokens(userID); err != nil {
+		return "", err
+	}
+	record := &APIToken{
+		TokenHash: hashAPIToken(token),
+		UserID:    userID,
+		ChatID:    chatID,
+		CreatedAt: s.clock.Now(),
+	}
+	if err := s.repository.CreateToken(record); err != nil {
+		return "", err
+	}
+
+	s.logger.Info("API token issued", zap.String("userID", string(userID)))
+	return token, nil
+}
+
+// Authenticate returns the token record for a plain token and records its use
+func (s *apiTokenService) Authenticate(token string) (*APIToken, error) {
+	if !strings.HasPrefix(token, APITokenPrefix) {
+		return nil, ErrAPITokenNotFound
+	}
+
+	record, err := s.repository.GetToken(hashAPIToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, ErrAPITokenNotFound
+	}
+
+	now := s.clock.Now()
+	record.LastUsedAt = &now
+	if err := s.repository.TouchToken(record.TokenHash, now); err != nil {
+		s.logger.Warn("Failed to record API token use",
+			zap.String("userID", string(record.UserID)),
+			zap.Error(err))
+	}
+	return record, nil
+}
+
+// RevokeTokens deletes the user's tokens and returns how many there were
+func (s *apiTokenService) RevokeTokens(userID common.UserID) (int, error) {
+	revoked, err := s.repository.DeleteUserTokens(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	s.logger.Info("API tokens revoked",
+		zap.String("userID", string(userID)),
+		zap.Int("count", revoked))
+	return revoked, nil
+}
+
+// handleAPITokenRequested issues or revokes a token for the /apitoken command
+func (s *apiTokenService) handleAPITokenRequested(event events.APITokenRequested) {
+	response := events.APITokenResponse{
+		Event:  events.NewEvent(),
+		UserID: event.UserID,
+		ChatID: event.ChatID,
+		Revoke: event.Revoke,
+	}
+	response.CorrelationID = event.CorrelationID
+
+	var err error
+	if event.Revoke {
+		response.Revoked, err = s.RevokeTokens(common.UserID(event.UserID))
+	} else {
+		response.Token, err = s.IssueToken(common.UserID(event.UserID), common.ChatID(event.ChatID))
+	}
+	if err != nil {
+		s.logger.Error("API token request failed",
+			zap.String("correlationID", event.CorrelationID),
+			zap.String("userID", event.UserID),
+			zap.Error(err))
+		response.Message = "Something went wrong, please try again."
+	} else {
+		response.Success = true
+	}
+
+	if err := s.eventBus.Publish(events.TopicAPITokenResponse, response); err != nil {
+		s.logger.Error("Failed to publish APITokenResponse", zap.Error(err))
+	}
+}
+
+// hashAPIToken returns the stored form of a plain token
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,134 @@
+package nudge
+
+import (
+	"sync"
+	"time"
+
+	"nudgebot-api/internal/common"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// APITokenRepository persists personal API tokens by hash
+type APITokenRepository interface {
+	CreateToken(token *APIToken) error
+	// GetToken returns nil when no token has the hash
+	GetToken(tokenHash string) (*APIToken, error)
+	TouchToken(tokenHash string, usedAt time.Time) error
+	DeleteUserTokens(userID common.UserID) (int, error)
+}
+
+// gormAPITokenRepository implements APITokenRepository using GORM
+type gormAPITokenRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewGormAPITokenRepository creates a new GORM-based API token repository
+func NewGormAPITokenRepository(db *gorm.DB, logger *zap.Logger) APITokenRepository {
+	return &gormAPITokenRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreateToken stores a new token
+func (r *gormAPITokenRepository) CreateToken(token *APIToken) error {
+	if err := r.db.Create(token).Error; err != nil {
+		return WrapRepositoryError(err, "create api token")
+	}
+	return nil
+}
+
+// GetToken returns nil when no token has the hash
+func (r *gormAPITokenRepository) GetToken(tokenHash string) (*APIToken, error) {
+	var tokens []*APIToken
+	err := r.db.Where("token_hash = ?", tokenHash).Limit(1).Find(&tokens).Error
+	if err != nil {
+		return nil, WrapRepositoryError(err, "get api token")
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	return tokens[0], nil
+}
+
+// TouchToken records when a token was last used
+func (r *gormAPITokenRepository) TouchToken(tokenHash string, usedAt time.Time) error {
+	err := r.db.Model(&APIToken{}).Where("token_hash = ?", tokenHash).Update("last_used_at", usedAt).Error
+	if err != nil {
+		return WrapRepositoryError(err, "touch api token")
+	}
+	return nil
+}
+
+// DeleteUserTokens deletes every token of a user and returns how many were deleted
+func (r *gormAPITokenRepository) DeleteUserTokens(userID common.UserID) (int, error) {
+	result := r.db.Where("user_id = ?", userID).Delete(&APIToken{})
+	if result.Error != nil {
+		return 0, WrapRepositoryError(result.Error, "delete api tokens")
+	}
+	return int(result.RowsAffected), nil
+}
+
+// memoryAPITokenRepository implements APITokenRepository in memory
+type memoryAPITokenRepository struct {
+	mu     sync.RWMutex
+	tokens map[string]APIToken
+}
+
+// NewMemoryAPITokenRepository creates an in-memory API token repository
+func NewMemoryAPITokenRepository() APITokenRepository {
+	return &memoryAPITokenRepository{
+		tokens: make(map[string]APIToken),
+	}
+}
+
+// CreateToken stores a new token
+func (r *memoryAPITokenRepository) CreateToken(token *APIToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tokens[token.TokenHash] = *token
+	return nil
+}
+
+// GetToken returns nil when no token has the hash
+func (r *memoryAPITokenRepository) GetToken(tokenHash string) (*APIToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	token, ok := r.tokens[tokenHash]
+	if !ok {
+		return nil, nil
+	}
+	return &token, nil
+}
+
+// TouchToken records when a token was last used
+func (r *memoryAPITokenRepository) TouchToken(tokenHash string, usedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if token, ok := r.tokens[tokenHash]; ok {
+		token.LastUsedAt = &usedAt
+		r.tokens[tokenHash] = token
+	}
+	return nil
+}
+
+// DeleteUserTokens deletes every token of a user and returns how many were deleted
+func (r *memoryAPITokenRepository) DeleteUserTokens(userID common.UserID) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleted := 0
+	for hash, token := range r.tokens {
+		if token.UserID == userID {
+			delete(r.tokens, hash)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,60 @@
+package nudge
+
+import (
+	"strings"
+	"testing"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestAPITokenService_IssueAndRevoke(t *testing.T) {
+	bus := events.NewMockEventBus()
+	bus.SetSynchronousMode(true)
+	repository := NewMemoryAPITokenRepository()
+	service := NewAPITokenService(bus, zaptest.NewLogger(t), repository)
+	userID := common.UserID(common.NewID())
+
+	first, err := service.IssueToken(userID, "12345")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(first, APITokenPrefix))
+
+	token, err := service.Authenticate(first)
+	require.NoError(t, err)
+	assert.Equal(t, userID, token.UserID)
+	assert.Equal(t, common.ChatID("12345"), token.ChatID)
+	assert.NotNil(t, token.LastUsedAt)
+
+	// Only the hash is stored
+	stored, err := repository.GetToken(first)
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+
+	// A new token replaces the old one
+	second, err := service.IssueToken(userID, "12345")
+	require.NoError(t, err)
+	_, err = service.Authenticate(first)
+	assert.ErrorIs(t, err, ErrAPITokenNotFound)
+
+	t.Run("revoked from the chat", func(t *testing.T) {
+		require.NoError(t, bus.Publish(events.TopicAPITokenRequested, events.APITokenRequested{
+			Event: events.NewEvent(), UserID: string(userID), ChatID: "12345", Revoke: true,
+		}))
+
+		responses := bus.GetPublishedEvents(events.TopicAPITokenResponse)
+		require.Len(t, responses, 1)
+		response := responses[0].(events.APITokenResponse)
+		assert.True(t, response.Success)
+		assert.Equal(t, 1, response.Revoked)
+
+		_, err := service.Authenticate(second)
+		assert.ErrorIs(t, err, ErrAPITokenNotFound)
+	})
+
+	_, err = service.Authenticate("not-a-token")
+	assert.ErrorIs(t, err, ErrAPITokenNotFound)
+}
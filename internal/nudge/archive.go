@@ -0,0 +1,207 @@
+package nudge
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"nudgebot-api/internal/common"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Completed tasks and sent reminders older than the archive age are moved to
+// these tables, keeping the live tables small for long-lived deployments
+const (
+	TaskArchiveTable     = "tasks_archive"
+	ReminderArchiveTable = "reminders_archive"
+)
+
+// ArchiveJobName is the name the archiver runs under on the job scheduler
+const ArchiveJobName = "archive"
+
+// DefaultArchiveSchedule runs the archiver nightly, outside busy hours
+const DefaultArchiveSchedule = "30 3 * * *"
+
+// archivedColumns are the columns statistics read from live and archived
+// tasks together
+const archivedColumns = "id, tenant_id, user_id, status, due_date, created_at, completed_at"
+
+// archivedReminderColumns are the columns statistics read from live and
+// archived reminders together
+const archivedReminderColumns = "id, tenant_id, task_id, user_id, sent_at"
+
+// Archiver moves completed tasks and sent reminders older than a number of
+// months to the archive tables. Unsent reminders of archived tasks are moved
+// with them.
+type Archiver struct {
+	db          *gorm.DB
+	logger      *zap.Logger
+	afterMonths int
+	batchSize   int
+	now         func() time.Time
+}
+
+// NewArchiver creates an archiver for rows older than afterMonths, moving at
+// most batchSize rows per statement
+func NewArchiver(db *gorm.DB, logger *zap.Logger, afterMonths, batchSize int) *Archiver {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	return &Archiver{
+		db:          db,
+		logger:      logger,
+		afterMonths: afterMonths,
+		batchSize:   batchSize,
+		now:         time.Now,
+	}
+}
+
+// Run archives everything older than the cutoff, batch by batch, stopping
+// early when ctx is done
+func (a *Archiver) Run(ctx context.Context) error {
+	cutoff := a.now().AddDate(0, -a.afterMonths, 0)
+	db := a.db.WithContext(ctx)
+
+	// Reminders go first so that none is left behind by its archived task
+	reminders, err := a.drain(ctx, func() (int64, error) {
+		return ArchiveReminders(db, cutoff, a.batchSize)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to archive reminders: %w", err)
+	}
+
+	tasks, err := a.drain(ctx, func() (int64, error) {
+		return ArchiveCompletedTasks(db, cutoff, a.batchSize)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to archive tasks: %w", err)
+	}
+
+	a.logger.Info("Archived old tasks and reminders",
+		zap.Time("cutoff", cutoff),
+		zap.Int64("tasks", tasks),
+		zap.Int64("reminders", reminders))
+	return nil
+}
+
+// drain runs archive until a batch comes back short
+func (a *Archiver) drain(ctx context.Context, archive func() (int64, error)) (int64, error) {
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		moved, err := archive()
+		total += moved
+		if err != nil || moved < int64(a.batchSize) {
+			return total, err
+		}
+	}
+}
+
+// archivedTasks matches the tasks completed before a cutoff
+const archivedTasks = "status = ? AND COALESCE(completed_at, updated_at) < ?"
+
+// ArchiveCompletedTasks moves up to limit tasks completed before cutoff to
+// the task archive and returns how many were moved
+func ArchiveCompletedTasks(db *gorm.DB, cutoff time.Time, limit int) (int64, error) {
+	result := moveRows(db, "tasks", TaskArchiveTable, &Task{}, limit,
+		archivedTasks, common.TaskStatusCompleted, cutoff)
+	return result.RowsAffected, result.Error
+}
+
+// ArchiveReminders moves up to limit reminders sent before cutoff, or
+// belonging to tasks completed before cutoff, to the reminder archive and
+// returns how many were moved
+func ArchiveReminders(db *gorm.DB, cutoff time.Time, limit int) (int64, error) {
+	result := moveRows(db, "reminders", ReminderArchiveTable, &Reminder{}, limit,
+		"(sent_at IS NOT NULL AND sent_at < ?) OR task_id IN (SELECT id FROM tasks WHERE "+archivedTasks+")",
+		cutoff, common.TaskStatusCompleted, cutoff)
+	return result.RowsAffected, result.Error
+}
+
+// moveRows deletes up to limit rows matching condition from live and inserts
+// them into archive in one statement, so that a failed batch moves nothing.
+// Columns are copied by name, as the archive may have gained columns in a
+// different order.
+func moveRows(db *gorm.DB, live, archive string, model interface{}, limit int, condition string, vars ...interface{}) *gorm.DB {
+	statement := &gorm.Statement{DB: db}
+	if err := statement.Parse(model); err != nil {
+		tx := db.Session(&gorm.Session{})
+		tx.AddError(fmt.Errorf("failed to parse %T: %w", model, err))
+		return tx
+	}
+	columns := strings.Join(statement.Schema.DBNames, ", ")
+
+	return db.Exec("WITH moved AS (DELETE FROM "+live+" WHERE id IN (SELECT id FROM "+live+" WHERE "+condition+" LIMIT ?) RETURNING "+columns+") "+
+		"INSERT INTO "+archive+" ("+columns+") SELECT "+columns+" FROM moved", append(vars, limit)...)
+}
+
+// tasksWithArchive queries live and archived tasks together, for statistics
+// over a user's whole history. Only archivedColumns can be read.
+func tasksWithArchive(db *gorm.DB) *gorm.DB {
+	return db.Model(&Task{}).Table("(?) AS tasks", withArchive(db, "tasks", TaskArchiveTable, archivedColumns))
+}
+
+// remindersWithArchive queries live and archived reminders together. Only
+// archivedReminderColumns can be read.
+func remindersWithArchive(db *gorm.DB) *gorm.DB {
+	return db.Model(&Reminder{}).Table("(?) AS reminders", withArchive(db, "reminders", ReminderArchiveTable, archivedReminderColumns))
+}
+
+// withArchive selects columns from a live table and its archive. The tenant
+// plugin scopes the outer query, so tenant_id must be among the columns.
+func withArchive(db *gorm.DB, live, archive, columns string) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).Raw(
+		"SELECT " + columns + " FROM " + live + " UNION ALL SELECT " + columns + " FROM " + archive)
+}
+
+// createArchiveTables creates the archive tables like the live tables and
+// adds the columns the live tables gained since
+func createArchiveTables(db *gorm.DB) error {
+	archives := []struct {
+		live    string
+		archive string
+		model   interface{}
+		indexes []string
+	}{
+		{"tasks", TaskArchiveTable, &Task{}, []string{
+			"CREATE INDEX IF NOT EXISTS idx_tasks_archive_user_completed ON " + TaskArchiveTable + "(user_id, completed_at)",
+		}},
+		{"reminders", ReminderArchiveTable, &Reminder{}, []string{
+			"CREATE INDEX IF NOT EXISTS idx_reminders_archive_task_id ON " + ReminderArchiveTable + "(task_id)",
+			"CREATE INDEX IF NOT EXISTS idx_reminders_archive_user_sent ON " + ReminderArchiveTable + "(user_id, sent_at)",
+		}},
+	}
+
+	for _, archive := range archives {
+		create := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (LIKE %s INCLUDING DEFAULTS, PRIMARY KEY (id))", archive.archive, archive.live)
+		if err := db.Exec(create).Error; err != nil {
+			return fmt.Errorf("failed to create table %s: %w", archive.archive, err)
+		}
+
+		statement := &gorm.Statement{DB: db}
+		if err := statement.Parse(archive.model); err != nil {
+			return fmt.Errorf("failed to parse %T: %w", archive.model, err)
+		}
+		migrator := db.Table(archive.archive).Migrator()
+		for _, column := range statement.Schema.DBNames {
+			if migrator.HasColumn(archive.model, column) {
+				continue
+			}
+			if err := migrator.AddColumn(archive.model, column); err != nil {
+				return fmt.Errorf("failed to add column %s to %s: %w", column, archive.archive, err)
+			}
+		}
+
+		for _, index := range archive.indexes {
+			if err := db.Exec(index).Error; err != nil {
+				return fmt.Errorf("failed to create index on %s: %w", archive.archive, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package nudge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func openDryRun(t *testing.T) *gorm.DB {
+	t.Helper()
+	// A dry run builds SQL without connecting to a database
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(tenant.NewPlugin()))
+	return db
+}
+
+func TestMoveRows_MovesTasksInOneStatement(t *testing.T) {
+	db := openDryRun(t)
+	cutoff := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	statement := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return moveRows(tx, "tasks", TaskArchiveTable, &Task{}, 100, archivedTasks, common.TaskStatusCompleted, cutoff)
+	})
+	assert.Contains(t, statement, "WITH moved AS (DELETE FROM tasks WHERE id IN (SELECT id FROM tasks WHERE status = 'completed' AND COALESCE(completed_at, updated_at) < '2025-01-01 00:00:00' LIMIT 100)")
+	assert.Contains(t, statement, "INSERT INTO tasks_archive (id, tenant_id, user_id,")
+	assert.Contains(t, statement, "custom_fields")
+}
+
+func TestTasksWithArchive_KeepsTenantScope(t *testing.T) {
+	db := openDryRun(t)
+	ctx := tenant.WithID(context.Background(), "acme")
+
+	var count int64
+	statement := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tasksWithArchive(tx.WithContext(ctx)).Where("user_id = ?", "user-1").Count(&count)
+	})
+	assert.Contains(t, statement, "FROM (SELECT "+archivedColumns+" FROM tasks UNION ALL SELECT "+archivedColumns+" FROM tasks_archive) AS tasks")
+	assert.Contains(t, statement, `"tasks"."tenant_id" = 'acme'`)
+}
+
+func TestArchiver_StopsWhenContextIsDone(t *testing.T) {
+	archiver := NewArchiver(openDryRun(t), zap.NewNop(), 12, 0)
+	assert.Equal(t, 500, archiver.batchSize)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, archiver.Run(ctx), context.Canceled)
+}
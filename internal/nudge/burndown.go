@@ -0,0 +1,100 @@
+package nudge
+
+import (
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// BurndownDays is how many days, today included, the /stats burndown covers
+const BurndownDays = 30
+
+// burndownDayFormat keys cached rollups by calendar day
+const burndownDayFormat = "2006-01-02"
+
+// TaskStatsDay is a user's daily rollup: the tasks open at the end of the day
+// and the tasks completed during it. Days are UTC calendar days. Only days
+// that are over are cached, so a rollup never changes once stored.
+type TaskStatsDay struct {
+	UserID    common.UserID `json:"user_id" gorm:"primaryKey;type:varchar(36)"`
+	Day       time.Time     `json:"day" gorm:"primaryKey;type:date"`
+	TenantID  string        `json:"tenant_id,omitempty" gorm:"type:varchar(64);not null;default:'default';index"`
+	Open      int           `json:"open" gorm:"not null;default:0"`
+	Completed int           `json:"completed" gorm:"not null;default:0"`
+	CreatedAt time.Time     `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName returns the table name for the TaskStatsDay model
+func (TaskStatsDay) TableName() string {
+	return "task_stats_daily"
+}
+
+// DailyStatsOf rolls up the user's tasks for the day starting at day, up to
+// now when the day is not over yet. Deleted tasks are left out.
+func DailyStatsOf(userID common.UserID, tasks []*Task, day, now time.Time) *TaskStatsDay {
+	end := day.AddDate(0, 0, 1)
+	if now.Before(end) {
+		end = now
+	}
+
+	stats := &TaskStatsDay{UserID: userID, Day: day}
+	for _, task := range tasks {
+		if task.Status == common.TaskStatusDeleted || !task.CreatedAt.Before(end) {
+			continue
+		}
+		if task.CompletedAt == nil || !task.CompletedAt.Before(end) {
+			stats.Open++
+			continue
+		}
+		if !task.CompletedAt.Before(day) {
+			stats.Completed++
+		}
+	}
+	return stats
+}
+
+// burndown returns the user's last BurndownDays daily rollups, oldest first.
+// Cached days are reused; the rest are rolled up from tasks and the days that
+// are over are cached for the next request.
+func (s *historyService) burndown(userID common.UserID, tasks []*Task, now time.Time) []events.BurndownDay {
+	today := now.UTC().Truncate(24 * time.Hour)
+	from := today.AddDate(0, 0, -(BurndownDays - 1))
+
+	cached := make(map[string]*TaskStatsDay)
+	if s.stats != nil {
+		days, err := s.stats.GetDailyStats(userID, from, today)
+		if err != nil {
+			s.logger.Warn("Failed to load cached daily stats",
+				zap.String("userID", string(userID)),
+				zap.Error(err))
+		}
+		for _, day := range days {
+			cached[day.Day.UTC().Format(burndownDayFormat)] = day
+		}
+	}
+
+	series := make([]events.BurndownDay, 0, BurndownDays)
+	var computed []*TaskStatsDay
+	for day := from; !day.After(today); day = day.AddDate(0, 0, 1) {
+		stats, ok := cached[day.Format(burndownDayFormat)]
+		if !ok {
+			stats = DailyStatsOf(userID, tasks, day, now)
+			if day.Before(today) {
+				computed = append(computed, stats)
+			}
+		}
+		series = append(series, events.BurndownDay{Day: day, Open: stats.Open, Completed: stats.Completed})
+	}
+
+	if s.stats != nil && len(computed) > 0 {
+		if err := s.stats.SaveDailyStats(computed); err != nil {
+			s.logger.Warn("Failed to cache daily stats",
+				zap.String("userID", string(userID)),
+				zap.Error(err))
+		}
+	}
+	return series
+}
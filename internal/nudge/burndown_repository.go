@@ -0,0 +1,115 @@
+package nudge
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"nudgebot-api/internal/common"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StatsRepository caches users' daily task rollups
+type StatsRepository interface {
+	// GetDailyStats returns the user's cached rollups for the days from
+	// from through to, oldest first
+	GetDailyStats(userID common.UserID, from, to time.Time) ([]*TaskStatsDay, error)
+	// SaveDailyStats stores rollups, replacing cached ones for the same day
+	SaveDailyStats(days []*TaskStatsDay) error
+}
+
+// gormStatsRepository implements StatsRepository using GORM
+type gormStatsRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewGormStatsRepository creates a new GORM-based stats repository
+func NewGormStatsRepository(db *gorm.DB, logger *zap.Logger) StatsRepository {
+	return &gormStatsRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetDailyStats retrieves the user's cached rollups in the range
+func (r *gormStatsRepository) GetDailyStats(userID common.UserID, from, to time.Time) ([]*TaskStatsDay, error) {
+	var days []*TaskStatsDay
+	err := r.db.
+		Where("user_id = ? AND day >= ? AND day <= ?", userID, from, to).
+		Order("day ASC").
+		Find(&days).Error
+	if err != nil {
+		return nil, WrapRepositoryError(err, "get daily stats")
+	}
+	return days, nil
+}
+
+// SaveDailyStats upserts the rollups
+func (r *gormStatsRepository) SaveDailyStats(days []*TaskStatsDay) error {
+	if len(days) == 0 {
+		return nil
+	}
+
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "day"}},
+		DoUpdates: clause.AssignmentColumns([]string{"open", "completed"}),
+	}).Create(days).Error
+	if err != nil {
+		return WrapRepositoryError(err, "save daily stats")
+	}
+	return nil
+}
+
+// memoryStatsRepository implements StatsRepository in memory
+type memoryStatsRepository struct {
+	mu   sync.RWMutex
+	days map[common.UserID]map[string]*TaskStatsDay
+}
+
+// NewMemoryStatsRepository creates an in-memory stats repository
+func NewMemoryStatsRepository() StatsRepository {
+	return &memoryStatsRepository{
+		days: make(map[common.UserID]map[string]*TaskStatsDay),
+	}
+}
+
+// GetDailyStats retrieves the user's cached rollups in the range
+func (r *memoryStatsRepository) GetDailyStats(userID common.UserID, from, to time.Time) ([]*TaskStatsDay, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var days []*TaskStatsDay
+	for _, day := range r.days[userID] {
+		if day.Day.Before(from) || day.Day.After(to) {
+			continue
+		}
+		dayCopy := *day
+		days = append(days, &dayCopy)
+	}
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Day.Before(days[j].Day)
+	})
+	return days, nil
+}
+
+// SaveDailyStats upserts the rollups
+func (r *memoryStatsRepository) SaveDailyStats(days []*TaskStatsDay) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, day := range days {
+		if day.CreatedAt.IsZero() {
+			day.CreatedAt = time.Now()
+		}
+		if r.days[day.UserID] == nil {
+			r.days[day.UserID] = make(map[string]*TaskStatsDay)
+		}
+		dayCopy := *day
+		r.days[day.UserID][day.Day.UTC().Format(burndownDayFormat)] = &dayCopy
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package nudge
+
+import (
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestDailyStatsOf(t *testing.T) {
+	day := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		moment := day.Add(d)
+		return &moment
+	}
+	userID := common.UserID("user-1")
+	tasks := []*Task{
+		{Title: "Open all day", CreatedAt: day.Add(-48 * time.Hour)},
+		{Title: "Done today", CreatedAt: day.Add(-48 * time.Hour), CompletedAt: at(10 * time.Hour)},
+		{Title: "Done yesterday", CreatedAt: day.Add(-48 * time.Hour), CompletedAt: at(-time.Hour)},
+		{Title: "Done tomorrow", CreatedAt: day.Add(-48 * time.Hour), CompletedAt: at(30 * time.Hour)},
+		{Title: "Created tomorrow", CreatedAt: day.Add(25 * time.Hour)},
+		{Title: "Deleted", CreatedAt: day.Add(-48 * time.Hour), Status: common.TaskStatusDeleted},
+	}
+
+	stats := DailyStatsOf(userID, tasks, day, day.Add(72*time.Hour))
+	assert.Equal(t, userID, stats.UserID)
+	assert.Equal(t, 2, stats.Open, "tasks completed after the day count as open")
+	assert.Equal(t, 1, stats.Completed)
+
+	// A day in progress only counts up to now
+	stats = DailyStatsOf(userID, tasks, day, day.Add(8*time.Hour))
+	assert.Equal(t, 3, stats.Open)
+	assert.Equal(t, 0, stats.Completed)
+}
+
+func TestHistoryService_BurndownCachesFinishedDays(t *testing.T) {
+	bus := events.NewMockEventBus()
+	logger := zaptest.NewLogger(t)
+	stats := NewMemoryStatsRepository()
+	history := NewHistoryServiceWithStats(bus, logger, NewMemoryHistoryRepository(), NewMemoryNudgeRepository(logger), nil, stats).(*historyService)
+
+	userID := common.UserID(common.NewID())
+	now := time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC)
+	completedAt := now.Add(-2 * time.Hour)
+	tasks := []*Task{
+		{Title: "Write report", CreatedAt: now.AddDate(0, 0, -40)},
+		{Title: "Pay rent", CreatedAt: now.AddDate(0, 0, -3), CompletedAt: &completedAt},
+	}
+
+	series := history.burndown(userID, tasks, now)
+	require.Len(t, series, BurndownDays)
+	assert.Equal(t, time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC), series[0].Day)
+	assert.Equal(t, events.BurndownDay{Day: time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC), Open: 2}, series[BurndownDays-2])
+	assert.Equal(t, events.BurndownDay{Day: time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), Open: 1, Completed: 1}, series[BurndownDays-1])
+
+	// Every day but today is cached
+	cached, err := stats.GetDailyStats(userID, series[0].Day, series[BurndownDays-1].Day)
+	require.NoError(t, err)
+	assert.Len(t, cached, BurndownDays-1)
+
+	// Cached days are served from the stats table, not rolled up again
+	series = history.burndown(userID, nil, now)
+	assert.Equal(t, 2, series[BurndownDays-2].Open)
+	assert.Equal(t, 0, series[BurndownDays-1].Open, "today is always rolled up afresh")
+}
@@ -2,10 +2,13 @@ package nudge
 
 import (
 	"fmt"
+	"net/mail"
 	"strings"
 	"time"
 
 	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/holiday"
 )
 
 // Business rule constants
@@ -20,6 +23,7 @@ const (
 	DefaultMaxNudges       = 3
 	ReminderLeadTime       = time.Hour // Default lead time before due date
 	NudgeBackoffMultiplier = 2.0       // Exponential backoff for nudges
+	MaxActivityDeferral    = 2 * time.Hour
 )
 
 // TaskValidator provides validation for task operations
@@ -73,6 +77,11 @@ func (v *TaskValidator) ValidateTask(task *Task) error {
 		return NewTaskValidationError("priority", task.Priority, "invalid priority value")
 	}
 
+	// Validate Habit
+	if task.Habit != "" && !task.Habit.IsValid() {
+		return NewTaskValidationError("habit", task.Habit, "habit must be daily or weekly")
+	}
+
 	// Validate Status
 	if !task.Status.IsValid() {
 		return NewTaskValidationError("status", task.Status, "invalid status value")
@@ -92,36 +101,58 @@ func (v *TaskValidator) ValidateTask(task *Task) error {
 		return NewTaskValidationError("completed_at", task.CompletedAt, "completed_at is required when status is completed")
 	}
 
+	// Validate CustomFields
+	if err := task.CustomFields.Validate(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// statusTransitions is the matrix of allowed status changes, keyed by the current status
+var statusTransitions = map[common.TaskStatus][]common.TaskStatus{
+	common.TaskStatusActive: {
+		common.TaskStatusInProgress, common.TaskStatusWaiting, common.TaskStatusSnoozed,
+		common.TaskStatusCompleted, common.TaskStatusDeleted,
+	},
+	common.TaskStatusInProgress: {
+		common.TaskStatusActive, common.TaskStatusWaiting, common.TaskStatusCompleted, common.TaskStatusDeleted,
+	},
+	common.TaskStatusWaiting: {
+		common.TaskStatusActive, common.TaskStatusInProgress, common.TaskStatusCompleted, common.TaskStatusDeleted,
+	},
+	// Snoozed tasks can be activated, completed, or deleted
+	common.TaskStatusSnoozed: {
+		common.TaskStatusActive, common.TaskStatusCompleted, common.TaskStatusDeleted,
+	},
+	// Completed tasks can only be deleted or reactivated
+	common.TaskStatusCompleted: {
+		common.TaskStatusDeleted, common.TaskStatusActive,
+	},
+	// Deleted tasks can only be reactivated
+	common.TaskStatusDeleted: {
+		common.TaskStatusActive,
+	},
+}
+
+// CanTransition reports whether a task may move from one status to another
+func CanTransition(from, to common.TaskStatus) bool {
+	for _, allowed := range statusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
 // ValidateStatusTransition ensures valid status transitions
 func (v *TaskValidator) ValidateStatusTransition(from, to common.TaskStatus) error {
 	if from == to {
 		return nil // No transition needed
 	}
 
-	switch from {
-	case common.TaskStatusActive:
-		// Active tasks can transition to any other status
-		if to == common.TaskStatusCompleted || to == common.TaskStatusSnoozed || to == common.TaskStatusDeleted {
-			return nil
-		}
-	case common.TaskStatusSnoozed:
-		// Snoozed tasks can be activated, completed, or deleted
-		if to == common.TaskStatusActive || to == common.TaskStatusCompleted || to == common.TaskStatusDeleted {
-			return nil
-		}
-	case common.TaskStatusCompleted:
-		// Completed tasks can only be deleted or reactivated
-		if to == common.TaskStatusDeleted || to == common.TaskStatusActive {
-			return nil
-		}
-	case common.TaskStatusDeleted:
-		// Deleted tasks can only be reactivated
-		if to == common.TaskStatusActive {
-			return nil
-		}
+	if CanTransition(from, to) {
+		return nil
 	}
 
 	return NewStatusTransitionError(from, to, "invalid status transition")
@@ -141,6 +172,21 @@ func (v *TaskValidator) ValidateTaskFilter(filter TaskFilter) error {
 	if filter.Status != nil && !filter.Status.IsValid() {
 		return NewTaskValidationError("status", filter.Status, "invalid status in filter")
 	}
+	for _, status := range filter.Statuses {
+		if !status.IsValid() {
+			return NewTaskValidationError("statuses", status, "invalid status in filter")
+		}
+	}
+
+	for key := range filter.CustomFields {
+		if err := ValidateCustomFieldKey(key); err != nil {
+			return err
+		}
+	}
+
+	if filter.Code < 0 {
+		return NewTaskValidationError("code", filter.Code, "code cannot be negative")
+	}
 
 	// Validate Priority
 	if filter.Priority != nil && !filter.Priority.IsValid() {
@@ -220,8 +266,13 @@ func (rm *ReminderManager) ShouldCreateNudge(task *Task, reminderCount int, sett
 		return false
 	}
 
-	// Don't nudge if task is not in active status
-	if task.Status != common.TaskStatusActive {
+	// Don't nudge if task is not being worked on
+	if task.Status != common.TaskStatusActive && task.Status != common.TaskStatusInProgress {
+		return false
+	}
+
+	// Don't nudge if the user muted the task
+	if task.Muted {
 		return false
 	}
 
@@ -252,6 +303,55 @@ func (rm *ReminderManager) GetNextNudgeTime(lastNudge time.Time, settings *Nudge
 	return lastNudge.Add(backoffInterval)
 }
 
+// IsQuietTime reports whether t falls inside the user's quiet hours
+func (rm *ReminderManager) IsQuietTime(settings *NudgeSettings, t time.Time) bool {
+	if settings == nil || settings.QuietHoursStart == "" || settings.QuietHoursEnd == "" {
+		return false
+	}
+
+	start, err := parseClockMinutes(settings.QuietHoursStart)
+	if err != nil {
+		return false
+	}
+	end, err := parseClockMinutes(settings.QuietHoursEnd)
+	if err != nil || start == end {
+		return false
+	}
+
+	local := t.In(settings.Location())
+	minute := local.Hour()*60 + local.Minute()
+
+	if start < end {
+		return minute >= start && minute < end
+	}
+	// Quiet hours wrap past midnight, e.g. 22:00-07:00
+	return minute >= start || minute < end
+}
+
+// ShouldDeliverReminder decides whether a due reminder may be sent now. Reminders
+// are held during quiet hours unless the user opted into urgent_override and the
+// task is high or urgent priority.
+func (rm *ReminderManager) ShouldDeliverReminder(task *Task, settings *NudgeSettings, now time.Time) bool {
+	if !rm.IsQuietTime(settings, now) {
+		return true
+	}
+
+	if settings.UrgentOverride && task != nil {
+		return task.Priority == common.PriorityHigh || task.Priority == common.PriorityUrgent
+	}
+
+	return false
+}
+
+// parseClockMinutes parses an "HH:MM" time of day into minutes after midnight
+func parseClockMinutes(value string) (int, error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, expected HH:MM", value)
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
 // TaskStatusManager handles task status transitions and business rules
 type TaskStatusManager struct{}
 
@@ -403,5 +503,122 @@ func ValidateNudgeSettings(settings *NudgeSettings) error {
 		return NewTaskValidationError("max_nudges", settings.MaxNudges, fmt.Sprintf("max nudges cannot exceed %d", MaxNudgesPerTask))
 	}
 
+	if (settings.QuietHoursStart == "") != (settings.QuietHoursEnd == "") {
+		return NewTaskValidationError("quiet_hours", settings.QuietHoursStart+"-"+settings.QuietHoursEnd, "quiet hours need both a start and an end")
+	}
+
+	if settings.QuietHoursStart != "" {
+		if _, err := parseClockMinutes(settings.QuietHoursStart); err != nil {
+			return NewTaskValidationError("quiet_hours_start", settings.QuietHoursStart, err.Error())
+		}
+		if _, err := parseClockMinutes(settings.QuietHoursEnd); err != nil {
+			return NewTaskValidationError("quiet_hours_end", settings.QuietHoursEnd, err.Error())
+		}
+	}
+
+	if settings.Timezone != "" {
+		if _, err := time.LoadLocation(settings.Timezone); err != nil {
+			return NewTaskValidationError("timezone", settings.Timezone, "timezone must be a valid IANA name")
+		}
+	}
+
+	if deferral := settings.ActivityDeferral; deferral != nil && (*deferral < 0 || *deferral > MaxActivityDeferral) {
+		return NewTaskValidationError("activity_deferral", *deferral, fmt.Sprintf("activity deferral must be between 0 and %v", MaxActivityDeferral))
+	}
+
+	if !ValidRetentionDays(settings.RetentionDays) {
+		return NewTaskValidationError("retention_days", settings.RetentionDays, "retention must be forever (0), 90 or 30 days")
+	}
+
+	if !ValidLanguage(settings.Language) {
+		return NewTaskValidationError("language", settings.Language, "language must be a code such as en or pt-br")
+	}
+
+	if _, ok := common.ParseWeekday(settings.WeekStart); settings.WeekStart != "" && !ok {
+		return NewTaskValidationError("week_start", settings.WeekStart, "week start must be a weekday")
+	}
+	if _, err := common.ParseWorkingDays(settings.WorkingDays); settings.WorkingDays != "" && err != nil {
+		return NewTaskValidationError("working_days", settings.WorkingDays, "working days must be weekdays such as mon-fri")
+	}
+	if _, ok := holiday.NormalizeCountry(settings.Country); settings.Country != "" && !ok {
+		return NewTaskValidationError("country", settings.Country, "country must be a two-letter code such as US")
+	}
+
+	if err := validateReminderChannels(settings); err != nil {
+		return err
+	}
+
+	return validateConfidenceThresholds(settings)
+}
+
+// validateReminderChannels checks each priority routes reminders to known
+// channels, and that a route to email has an address to send to
+func validateReminderChannels(settings *NudgeSettings) error {
+	routes := []struct {
+		field string
+		route string
+	}{
+		{"channels_high", settings.ChannelsHigh},
+		{"channels_medium", settings.ChannelsMedium},
+		{"channels_low", settings.ChannelsLow},
+	}
+
+	for _, r := range routes {
+		if strings.TrimSpace(r.route) != "" && len(splitChannels(r.route)) == 0 {
+			return NewTaskValidationError(r.field, r.route, "route must name at least one channel")
+		}
+
+		seen := make(map[string]bool)
+		for _, channel := range splitChannels(r.route) {
+			switch channel {
+			case events.ReminderChannelTelegram, events.ReminderChannelEmail, events.ReminderChannelDigest:
+			default:
+				return NewTaskValidationError(r.field, r.route, fmt.Sprintf("unknown channel %q; use telegram, email or digest", channel))
+			}
+			if seen[channel] {
+				return NewTaskValidationError(r.field, r.route, fmt.Sprintf("channel %q is listed twice", channel))
+			}
+			seen[channel] = true
+
+			if channel == events.ReminderChannelEmail && settings.Email == "" {
+				return NewTaskValidationError(r.field, r.route, "an email address is needed to route reminders to email")
+			}
+		}
+	}
+
+	if settings.Email != "" {
+		if _, err := mail.ParseAddress(settings.Email); err != nil {
+			return NewTaskValidationError("email", settings.Email, "email must be a valid address")
+		}
+	}
+	return nil
+}
+
+// validateConfidenceThresholds checks the thresholds a user has set lie
+// between 0 and 1 and do not decrease from low to high. Unset thresholds are
+// checked against the deployment's when parses are graded.
+func validateConfidenceThresholds(settings *NudgeSettings) error {
+	thresholds := []struct {
+		field string
+		value *float64
+	}{
+		{"confidence_low", settings.ConfidenceLow},
+		{"confidence_medium", settings.ConfidenceMedium},
+		{"confidence_high", settings.ConfidenceHigh},
+	}
+
+	previous := -1.0
+	for _, threshold := range thresholds {
+		if threshold.value == nil {
+			continue
+		}
+		if *threshold.value < 0 || *threshold.value > 1 {
+			return NewTaskValidationError(threshold.field, *threshold.value, "confidence threshold must be between 0 and 1")
+		}
+		if *threshold.value < previous {
+			return NewTaskValidationError(threshold.field, *threshold.value, "confidence thresholds must not decrease from low to high")
+		}
+		previous = *threshold.value
+	}
 	return nil
 }
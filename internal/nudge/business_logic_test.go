@@ -0,0 +1,218 @@
+package nudge
+
+import (
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReminderManager_ShouldDeliverReminder(t *testing.T) {
+	manager := NewReminderManager()
+	settings := &NudgeSettings{
+		QuietHoursStart: "22:00",
+		QuietHoursEnd:   "07:00",
+		Timezone:        "Europe/Berlin",
+	}
+
+	// 23:30 in Berlin (UTC+1 in January)
+	night := time.Date(2024, time.January, 10, 22, 30, 0, 0, time.UTC)
+	// 12:00 in Berlin
+	noon := time.Date(2024, time.January, 10, 11, 0, 0, 0, time.UTC)
+
+	lowTask := &Task{Priority: common.PriorityLow}
+	urgentTask := &Task{Priority: common.PriorityUrgent}
+
+	assert.True(t, manager.IsQuietTime(settings, night))
+	assert.False(t, manager.IsQuietTime(settings, noon))
+
+	assert.True(t, manager.ShouldDeliverReminder(lowTask, settings, noon))
+	assert.False(t, manager.ShouldDeliverReminder(lowTask, settings, night))
+	assert.False(t, manager.ShouldDeliverReminder(urgentTask, settings, night), "urgent tasks wait unless the user opts in")
+
+	settings.UrgentOverride = true
+	assert.True(t, manager.ShouldDeliverReminder(urgentTask, settings, night))
+	assert.True(t, manager.ShouldDeliverReminder(&Task{Priority: common.PriorityHigh}, settings, night))
+	assert.False(t, manager.ShouldDeliverReminder(lowTask, settings, night))
+
+	assert.True(t, manager.ShouldDeliverReminder(lowTask, &NudgeSettings{}, night), "no quiet hours configured")
+}
+
+func TestValidateNudgeSettings_QuietHours(t *testing.T) {
+	base := func() *NudgeSettings {
+		return &NudgeSettings{
+			UserID:        common.UserID(common.NewID()),
+			NudgeInterval: DefaultNudgeInterval,
+			MaxNudges:     DefaultMaxNudges,
+		}
+	}
+
+	valid := base()
+	valid.QuietHoursStart, valid.QuietHoursEnd, valid.Timezone = "22:00", "07:00", "Asia/Ho_Chi_Minh"
+	assert.NoError(t, ValidateNudgeSettings(valid))
+
+	missingEnd := base()
+	missingEnd.QuietHoursStart = "22:00"
+	assert.Error(t, ValidateNudgeSettings(missingEnd))
+
+	badClock := base()
+	badClock.QuietHoursStart, badClock.QuietHoursEnd = "25:00", "07:00"
+	assert.Error(t, ValidateNudgeSettings(badClock))
+
+	badZone := base()
+	badZone.Timezone = "Mars/Olympus"
+	assert.Error(t, ValidateNudgeSettings(badZone))
+}
+
+func TestValidateNudgeSettings_ConfidenceThresholds(t *testing.T) {
+	threshold := func(value float64) *float64 { return &value }
+	settings := &NudgeSettings{
+		UserID:        common.UserID(common.NewID()),
+		NudgeInterval: DefaultNudgeInterval,
+		MaxNudges:     DefaultMaxNudges,
+	}
+	assert.NoError(t, ValidateNudgeSettings(settings), "unset thresholds use the deployment's")
+
+	settings.ConfidenceHigh, settings.ConfidenceLow = threshold(0.9), threshold(0.3)
+	assert.NoError(t, ValidateNudgeSettings(settings))
+
+	settings.ConfidenceMedium = threshold(0.95)
+	assert.Error(t, ValidateNudgeSettings(settings), "thresholds must not decrease")
+
+	settings.ConfidenceMedium = nil
+	settings.ConfidenceHigh = threshold(1.5)
+	assert.Error(t, ValidateNudgeSettings(settings))
+}
+
+func TestValidateNudgeSettings_ReminderChannels(t *testing.T) {
+	settings := &NudgeSettings{
+		UserID:         common.UserID(common.NewID()),
+		NudgeInterval:  DefaultNudgeInterval,
+		MaxNudges:      DefaultMaxNudges,
+		ChannelsMedium: "telegram",
+		ChannelsLow:    "digest",
+	}
+	assert.NoError(t, ValidateNudgeSettings(settings))
+
+	settings.ChannelsHigh = "telegram, email"
+	assert.Error(t, ValidateNudgeSettings(settings), "email needs an address")
+
+	settings.Email = "ada@example.com"
+	assert.NoError(t, ValidateNudgeSettings(settings))
+
+	settings.ChannelsLow = "digest,sms"
+	assert.Error(t, ValidateNudgeSettings(settings))
+
+	settings.ChannelsLow = "digest,digest"
+	assert.Error(t, ValidateNudgeSettings(settings))
+
+	settings.ChannelsLow = " , "
+	assert.Error(t, ValidateNudgeSettings(settings))
+
+	settings.ChannelsLow = ""
+	settings.Email = "not an address"
+	assert.Error(t, ValidateNudgeSettings(settings))
+}
+
+func TestNudgeSettings_ReminderChannels(t *testing.T) {
+	settings := &NudgeSettings{ChannelsHigh: "telegram, email", ChannelsLow: "digest"}
+
+	assert.Equal(t, []string{"telegram", "email"}, settings.ReminderChannels(common.PriorityUrgent))
+	assert.Equal(t, []string{"telegram", "email"}, settings.ReminderChannels(common.PriorityHigh))
+	assert.Nil(t, settings.ReminderChannels(common.PriorityMedium), "unset routes go to Telegram")
+	assert.Equal(t, []string{"digest"}, settings.ReminderChannels(common.PriorityLow))
+
+	var unset *NudgeSettings
+	assert.Nil(t, unset.ReminderChannels(common.PriorityHigh))
+}
+
+func TestTaskStatusManager_KanbanStatuses(t *testing.T) {
+	validator := NewTaskValidator()
+	manager := NewTaskStatusManager()
+
+	assert.NoError(t, validator.ValidateStatusTransition(common.TaskStatusActive, common.TaskStatusInProgress))
+	assert.NoError(t, validator.ValidateStatusTransition(common.TaskStatusInProgress, common.TaskStatusWaiting))
+	assert.NoError(t, validator.ValidateStatusTransition(common.TaskStatusWaiting, common.TaskStatusCompleted))
+	assert.Error(t, validator.ValidateStatusTransition(common.TaskStatusSnoozed, common.TaskStatusWaiting))
+	assert.Error(t, validator.ValidateStatusTransition(common.TaskStatusInProgress, common.TaskStatusSnoozed))
+
+	task := &Task{Status: common.TaskStatusActive}
+	assert.NoError(t, manager.TransitionStatus(task, common.TaskStatusInProgress))
+	assert.Equal(t, common.TaskStatusInProgress, task.Status)
+	assert.NoError(t, manager.TransitionStatus(task, common.TaskStatusCompleted))
+	assert.NotNil(t, task.CompletedAt)
+
+	filter := TaskFilter{Statuses: common.OpenTaskStatuses()}
+	assert.True(t, filter.MatchesStatus(common.TaskStatusWaiting))
+	assert.False(t, filter.MatchesStatus(common.TaskStatusSnoozed))
+	assert.Error(t, validator.ValidateTaskFilter(TaskFilter{UserID: common.UserID(common.NewID()), Statuses: []common.TaskStatus{"blocked"}}))
+}
+
+func TestRescheduleShift(t *testing.T) {
+	// Wednesday
+	now := time.Date(2024, 5, 15, 18, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		command  string
+		argument string
+		want     DueShift
+		wantErr  bool
+	}{
+		{name: "snooze hours", command: RescheduleSnoozeAll, argument: "2h", want: DueShift{Duration: 2 * time.Hour}},
+		{name: "snooze days", command: RescheduleSnoozeAll, argument: "1d", want: DueShift{Duration: 24 * time.Hour}},
+		{name: "snooze too long", command: RescheduleSnoozeAll, argument: "30d", wantErr: true},
+		{name: "snooze negative", command: RescheduleSnoozeAll, argument: "-1h", wantErr: true},
+		{name: "move tomorrow", command: RescheduleMoveTo, argument: "Tomorrow", want: DueShift{Days: 1}},
+		{name: "move to weekday", command: RescheduleMoveTo, argument: "fri", want: DueShift{Days: 2}},
+		{name: "same weekday is next week", command: RescheduleMoveTo, argument: "wednesday", want: DueShift{Days: 7}},
+		{name: "move to date", command: RescheduleMoveTo, argument: "2024-06-01", want: DueShift{Days: 17}},
+		{name: "move to today", command: RescheduleMoveTo, argument: "2024-05-15", wantErr: true},
+		{name: "unknown day", command: RescheduleMoveTo, argument: "someday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shift, err := RescheduleShift(tt.command, tt.argument, now)
+			if tt.wantErr {
+				assert.True(t, IsValidationError(err))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, shift)
+		})
+	}
+}
+
+func TestDueShift_KeepsTimeOfDayAcrossDaylightSaving(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	// Clocks go forward on 31 March 2024
+	due := time.Date(2024, 3, 29, 9, 0, 0, 0, berlin)
+	moved := DueShift{Days: 3}.Apply(due, berlin)
+	assert.Equal(t, time.Date(2024, 4, 1, 9, 0, 0, 0, berlin), moved)
+	assert.Equal(t, 71*time.Hour, moved.Sub(due))
+
+	assert.Equal(t, due.Add(72*time.Hour), DueShift{Duration: 72 * time.Hour}.Apply(due, berlin))
+}
+
+func TestNewCustomField(t *testing.T) {
+	date, err := NewCustomField(CustomFieldDate, " 2026-03-01 ", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "2026-03-01", date.Value)
+
+	_, err = NewCustomField(CustomFieldDate, "March 1st", nil)
+	assert.Error(t, err)
+	_, err = NewCustomField(CustomFieldNumber, "lots", nil)
+	assert.Error(t, err)
+	_, err = NewCustomField(CustomFieldEnum, "blocked", []string{"todo", "done"})
+	assert.Error(t, err)
+	_, err = NewCustomField(CustomFieldText, "", nil)
+	assert.Error(t, err)
+	_, err = NewCustomField("colour", "red", nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,196 @@
+package nudge
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"nudgebot-api/internal/chaos"
+	"nudgebot-api/internal/common"
+)
+
+// chaosNudgeRepository decorates a NudgeRepository with injected query timeouts
+type chaosNudgeRepository struct {
+	next     NudgeRepository
+	injector *chaos.Injector
+}
+
+// NewChaosNudgeRepository wraps a repository with fault injection. Injected
+// errors wrap both chaos.ErrInjected and context.DeadlineExceeded, like a
+// query cancelled by its statement timeout. A nil injector returns the
+// repository unchanged.
+func NewChaosNudgeRepository(next NudgeRepository, injector *chaos.Injector) NudgeRepository {
+	if injector == nil {
+		return next
+	}
+	return &chaosNudgeRepository{next: next, injector: injector}
+}
+
+// fault returns an injected timeout for the method, if any
+func (r *chaosNudgeRepository) fault(method string) error {
+	if !r.injector.Should(chaos.FaultDatabaseTimeout) {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", r.injector.Error(chaos.FaultDatabaseTimeout, method), context.DeadlineExceeded)
+}
+
+// Task operations
+
+func (r *chaosNudgeRepository) CreateTask(task *Task) error {
+	if err := r.fault("CreateTask"); err != nil {
+		return err
+	}
+	return r.next.CreateTask(task)
+}
+
+func (r *chaosNudgeRepository) GetTaskByID(taskID common.TaskID) (*Task, error) {
+	if err := r.fault("GetTaskByID"); err != nil {
+		return nil, err
+	}
+	return r.next.GetTaskByID(taskID)
+}
+
+func (r *chaosNudgeRepository) GetTasksByUserID(userID common.UserID, filter TaskFilter) ([]*Task, error) {
+	if err := r.fault("GetTasksByUserID"); err != nil {
+		return nil, err
+	}
+	return r.next.GetTasksByUserID(userID, filter)
+}
+
+func (r *chaosNudgeRepository) UpdateTask(task *Task) error {
+	if err := r.fault("UpdateTask"); err != nil {
+		return err
+	}
+	return r.next.UpdateTask(task)
+}
+
+func (r *chaosNudgeRepository) DeleteTask(taskID common.TaskID) error {
+	if err := r.fault("DeleteTask"); err != nil {
+		return err
+	}
+	return r.next.DeleteTask(taskID)
+}
+
+func (r *chaosNudgeRepository) GetTaskStats(userID common.UserID) (*TaskStats, error) {
+	if err := r.fault("GetTaskStats"); err != nil {
+		return nil, err
+	}
+	return r.next.GetTaskStats(userID)
+}
+
+func (r *chaosNudgeRepository) GetOverdueTasks(userID common.UserID) ([]*Task, error) {
+	if err := r.fault("GetOverdueTasks"); err != nil {
+		return nil, err
+	}
+	return r.next.GetOverdueTasks(userID)
+}
+
+func (r *chaosNudgeRepository) BulkUpdateTaskStatus(taskIDs []common.TaskID, status common.TaskStatus) error {
+	if err := r.fault("BulkUpdateTaskStatus"); err != nil {
+		return err
+	}
+	return r.next.BulkUpdateTaskStatus(taskIDs, status)
+}
+
+func (r *chaosNudgeRepository) BulkShiftDueDates(taskIDs []common.TaskID, shift time.Duration) error {
+	if err := r.fault("BulkShiftDueDates"); err != nil {
+		return err
+	}
+	return r.next.BulkShiftDueDates(taskIDs, shift)
+}
+
+func (r *chaosNudgeRepository) MoveChatID(from, to common.ChatID) error {
+	if err := r.fault("MoveChatID"); err != nil {
+		return err
+	}
+	return r.next.MoveChatID(from, to)
+}
+
+func (r *chaosNudgeRepository) GetNewlyOverdueTasks(now time.Time, limit int) ([]*Task, error) {
+	if err := r.fault("GetNewlyOverdueTasks"); err != nil {
+		return nil, err
+	}
+	return r.next.GetNewlyOverdueTasks(now, limit)
+}
+
+func (r *chaosNudgeRepository) MarkTaskOverdue(taskID common.TaskID, dueDate time.Time) error {
+	if err := r.fault("MarkTaskOverdue"); err != nil {
+		return err
+	}
+	return r.next.MarkTaskOverdue(taskID, dueDate)
+}
+
+// Reminder operations
+
+func (r *chaosNudgeRepository) CreateReminder(reminder *Reminder) error {
+	if err := r.fault("CreateReminder"); err != nil {
+		return err
+	}
+	return r.next.CreateReminder(reminder)
+}
+
+func (r *chaosNudgeRepository) GetDueReminders(before time.Time) ([]*Reminder, error) {
+	if err := r.fault("GetDueReminders"); err != nil {
+		return nil, err
+	}
+	return r.next.GetDueReminders(before)
+}
+
+func (r *chaosNudgeRepository) MarkReminderSent(reminderID common.ID) error {
+	if err := r.fault("MarkReminderSent"); err != nil {
+		return err
+	}
+	return r.next.MarkReminderSent(reminderID)
+}
+
+func (r *chaosNudgeRepository) GetRemindersByTaskID(taskID common.TaskID) ([]*Reminder, error) {
+	if err := r.fault("GetRemindersByTaskID"); err != nil {
+		return nil, err
+	}
+	return r.next.GetRemindersByTaskID(taskID)
+}
+
+func (r *chaosNudgeRepository) DeleteReminder(reminderID common.ID) error {
+	if err := r.fault("DeleteReminder"); err != nil {
+		return err
+	}
+	return r.next.DeleteReminder(reminderID)
+}
+
+// Nudge settings operations
+
+func (r *chaosNudgeRepository) GetNudgeSettingsByUserID(userID common.UserID) (*NudgeSettings, error) {
+	if err := r.fault("GetNudgeSettingsByUserID"); err != nil {
+		return nil, err
+	}
+	return r.next.GetNudgeSettingsByUserID(userID)
+}
+
+func (r *chaosNudgeRepository) CreateOrUpdateNudgeSettings(settings *NudgeSettings) error {
+	if err := r.fault("CreateOrUpdateNudgeSettings"); err != nil {
+		return err
+	}
+	return r.next.CreateOrUpdateNudgeSettings(settings)
+}
+
+func (r *chaosNudgeRepository) DeleteNudgeSettings(userID common.UserID) error {
+	if err := r.fault("DeleteNudgeSettings"); err != nil {
+		return err
+	}
+	return r.next.DeleteNudgeSettings(userID)
+}
+
+// WithContext injects faults into the calls made with ctx
+func (r *chaosNudgeRepository) WithContext(ctx context.Context) NudgeRepository {
+	return NewChaosNudgeRepository(r.next.WithContext(ctx), r.injector)
+}
+
+// WithTransaction can fail to begin and injects faults into the calls made inside it
+func (r *chaosNudgeRepository) WithTransaction(fn func(NudgeRepository) error) error {
+	if err := r.fault("WithTransaction"); err != nil {
+		return err
+	}
+	return r.next.WithTransaction(func(tx NudgeRepository) error {
+		return fn(NewChaosNudgeRepository(tx, r.injector))
+	})
+}
@@ -0,0 +1,36 @@
+package nudge
+
+import (
+	"context"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/tenant"
+
+	"go.uber.org/zap"
+)
+
+// handleChatMigrated moves the tasks, reminders, workspace and other rows of
+// a group that became a supergroup to the supergroup's chat, so reminders
+// reach the chat the members now write in and its roles still apply
+func (s *nudgeService) handleChatMigrated(event events.ChatMigrated) {
+	if s.repository == nil || event.FromChatID == event.ToChatID {
+		return
+	}
+
+	// The chat's rows are in the tenant of the bot it talks to
+	repository := s.repository.WithContext(tenant.WithBot(context.Background(), event.Bot))
+	if err := repository.MoveChatID(common.ChatID(event.FromChatID), common.ChatID(event.ToChatID)); err != nil {
+		s.logger.Error("Failed to move tasks to the migrated chat",
+			zap.String("correlationID", event.CorrelationID),
+			zap.String("fromChatID", event.FromChatID),
+			zap.String("toChatID", event.ToChatID),
+			zap.Error(err))
+		return
+	}
+
+	s.logger.Info("Moved tasks to the migrated chat",
+		zap.String("fromChatID", event.FromChatID),
+		zap.String("toChatID", event.ToChatID),
+		zap.Int64("toTelegramChatID", event.ToTelegramChatID))
+}
@@ -0,0 +1,84 @@
+package nudge
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestNudgeService_MovesTasksOfMigratedChat(t *testing.T) {
+	bus := events.NewMockEventBus()
+	bus.SetSynchronousMode(true)
+	logger := zaptest.NewLogger(t)
+	repo := NewMemoryNudgeRepository(logger)
+	_, err := NewNudgeService(bus, logger, repo)
+	require.NoError(t, err)
+
+	userID := common.UserID(common.NewID())
+	group, supergroup, other := common.ChatID(common.NewID()), common.ChatID(common.NewID()), common.ChatID(common.NewID())
+	task := &Task{ID: common.TaskID(common.NewID()), UserID: userID, ChatID: group, Title: "Book the venue",
+		Priority: common.PriorityMedium, Status: common.TaskStatusActive}
+	require.NoError(t, repo.CreateTask(task))
+	elsewhere := &Task{ID: common.TaskID(common.NewID()), UserID: userID, ChatID: other, Title: "Water plants",
+		Priority: common.PriorityMedium, Status: common.TaskStatusActive}
+	require.NoError(t, repo.CreateTask(elsewhere))
+	reminder := &Reminder{ID: common.NewID(), TaskID: task.ID, UserID: userID, ChatID: group,
+		ScheduledAt: time.Now().Add(time.Hour), ReminderType: ReminderTypeInitial}
+	require.NoError(t, repo.CreateReminder(reminder))
+
+	require.NoError(t, bus.Publish(events.TopicChatMigrated, events.ChatMigrated{
+		Event: events.NewEvent(), FromChatID: string(group), ToChatID: string(supergroup),
+		FromTelegramChatID: -123, ToTelegramChatID: -100123,
+	}))
+
+	moved, err := repo.GetTaskByID(task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, supergroup, moved.ChatID)
+	reminders, err := repo.GetRemindersByTaskID(task.ID)
+	require.NoError(t, err)
+	require.Len(t, reminders, 1)
+	assert.Equal(t, supergroup, reminders[0].ChatID)
+
+	untouched, err := repo.GetTaskByID(elsewhere.ID)
+	require.NoError(t, err)
+	assert.Equal(t, other, untouched.ChatID, "tasks of other chats stay where they are")
+}
+
+func TestMoveChatID_MovesEveryChatTable(t *testing.T) {
+	// A dry run builds SQL without connecting to a database
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+	})
+	require.NoError(t, err)
+
+	var statements []string
+	record := func(db *gorm.DB) {
+		statements = append(statements, db.Statement.SQL.String())
+	}
+	require.NoError(t, db.Callback().Update().After("gorm:update").Register("test:record", record))
+	require.NoError(t, db.Callback().Delete().After("gorm:delete").Register("test:record", record))
+
+	require.NoError(t, moveChatRows(db, "group", "supergroup"))
+
+	moved := make(map[string]bool)
+	for _, statement := range statements {
+		for _, table := range []string{"tasks", "reminders", "workspace_members", "workspace_invites",
+			"shared_list_members", "scheduled_messages", "countdowns", "api_tokens", "chat_activity"} {
+			if strings.HasPrefix(statement, `UPDATE "`+table+`" SET "chat_id"=`) {
+				moved[table] = true
+			}
+		}
+	}
+	assert.Len(t, moved, 9, "every chat-keyed table is moved: %v", statements)
+}
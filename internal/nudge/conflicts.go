@@ -0,0 +1,158 @@
+package nudge
+
+import (
+	"sort"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// DefaultConflictTolerance is how close two timed tasks can be before they
+// clash
+const DefaultConflictTolerance = 15 * time.Minute
+
+// conflictSearchWindow bounds the tasks a new task is checked against; no
+// task block is longer than a day
+const conflictSearchWindow = 24 * time.Hour
+
+// TaskConflict is a pair of open tasks whose times overlap
+type TaskConflict struct {
+	Task  *Task
+	Other *Task
+}
+
+// ToEvent converts the conflict for events
+func (c TaskConflict) ToEvent() events.TaskConflict {
+	return events.TaskConflict{
+		TaskID:       string(c.Task.ID),
+		Title:        c.Task.Title,
+		DueDate:      *c.Task.DueDate,
+		OtherTaskID:  string(c.Other.ID),
+		OtherTitle:   c.Other.Title,
+		OtherDueDate: *c.Other.DueDate,
+	}
+}
+
+// HasConcreteTime reports whether a due date names a time of day. Tasks due
+// on a day without a time are stored at midnight and never clash.
+func HasConcreteTime(due *time.Time) bool {
+	if due == nil {
+		return false
+	}
+	return due.Hour() != 0 || due.Minute() != 0
+}
+
+// taskBlock returns when a timed task takes place: the estimate minutes up to
+// its due time, or just the due time without an estimate
+func taskBlock(task *Task) (time.Time, time.Time) {
+	end := *task.DueDate
+	return end.Add(-time.Duration(taskEstimateMinutes(task)) * time.Minute), end
+}
+
+// TasksOverlap reports whether two tasks with concrete due times take place
+// within tolerance of each other
+func TasksOverlap(a, b *Task, tolerance time.Duration) bool {
+	if a.ID == b.ID || !HasConcreteTime(a.DueDate) || !HasConcreteTime(b.DueDate) {
+		return false
+	}
+	aStart, aEnd := taskBlock(a)
+	bStart, bEnd := taskBlock(b)
+	return aStart.Before(bEnd.Add(tolerance)) && bStart.Before(aEnd.Add(tolerance))
+}
+
+// FindConflicts returns the overlapping pairs among the open tasks, the
+// earliest first
+func FindConflicts(tasks []*Task, tolerance time.Duration) []TaskConflict {
+	timed := make([]*Task, 0, len(tasks))
+	for _, task := range tasks {
+		if task.Status.IsOpen() && HasConcreteTime(task.DueDate) {
+			timed = append(timed, task)
+		}
+	}
+	sort.SliceStable(timed, func(i, j int) bool { return timed[i].DueDate.Before(*timed[j].DueDate) })
+
+	var conflicts []TaskConflict
+	for i, task := range timed {
+		for _, other := range timed[i+1:] {
+			if TasksOverlap(task, other, tolerance) {
+				conflicts = append(conflicts, TaskConflict{Task: task, Other: other})
+			}
+		}
+	}
+	return conflicts
+}
+
+// ConflictChecker finds the user's open tasks whose due times overlap
+type ConflictChecker struct {
+	tasks     NudgeRepository
+	tolerance time.Duration
+}
+
+// NewConflictChecker creates a checker treating tasks within tolerance of
+// each other as clashing
+func NewConflictChecker(tasks NudgeRepository, tolerance time.Duration) *ConflictChecker {
+	return &ConflictChecker{tasks: tasks, tolerance: tolerance}
+}
+
+// ConflictsWith returns the conflicts of a task with the user's other open
+// tasks
+func (c *ConflictChecker) ConflictsWith(task *Task) ([]TaskConflict, error) {
+	if !HasConcreteTime(task.DueDate) {
+		return nil, nil
+	}
+
+	from, to := task.DueDate.Add(-conflictSearchWindow), task.DueDate.Add(conflictSearchWindow)
+	others, err := c.openTasks(task.UserID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var conflicts []TaskConflict
+	for _, other := range others {
+		if TasksOverlap(task, other, c.tolerance) {
+			conflicts = append(conflicts, TaskConflict{Task: task, Other: other})
+		}
+	}
+	return conflicts, nil
+}
+
+// Conflicts returns the clashes among the user's open tasks due between from
+// and to
+func (c *ConflictChecker) Conflicts(userID common.UserID, from, to time.Time) ([]TaskConflict, error) {
+	tasks, err := c.openTasks(userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return FindConflicts(tasks, c.tolerance), nil
+}
+
+// openTasks loads the user's open tasks due between from and to
+func (c *ConflictChecker) openTasks(userID common.UserID, from, to time.Time) ([]*Task, error) {
+	return c.tasks.GetTasksByUserID(userID, TaskFilter{
+		UserID:    userID,
+		Statuses:  common.OpenTaskStatuses(),
+		DueAfter:  &from,
+		DueBefore: &to,
+	})
+}
+
+// findConflicts returns the conflicts of a newly created task for its
+// TaskCreated event
+func (s *nudgeService) findConflicts(task *Task) []events.TaskConflict {
+	conflicts, err := s.conflicts.ConflictsWith(task)
+	if err != nil {
+		s.logger.Warn("Failed to check task for conflicts",
+			zap.String("taskID", string(task.ID)),
+			zap.Error(err))
+		return nil
+	}
+
+	converted := make([]events.TaskConflict, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		converted = append(converted, conflict.ToEvent())
+	}
+	return converted
+}
@@ -0,0 +1,71 @@
+package nudge
+
+import (
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTasksOverlap(t *testing.T) {
+	base := time.Date(2024, 6, 3, 15, 0, 0, 0, time.UTC)
+	task := func(id string, due time.Time, estimate string) *Task {
+		task := &Task{ID: common.TaskID(id), DueDate: &due, Status: common.TaskStatusActive}
+		if estimate != "" {
+			task.CustomFields = map[string]CustomField{PlanEstimateField: {Type: CustomFieldNumber, Value: estimate}}
+		}
+		return task
+	}
+
+	assert.True(t, TasksOverlap(task("a", base, ""), task("b", base.Add(10*time.Minute), ""), 15*time.Minute))
+	assert.False(t, TasksOverlap(task("a", base, ""), task("b", base.Add(20*time.Minute), ""), 15*time.Minute))
+	assert.False(t, TasksOverlap(task("a", base, ""), task("b", base.Add(10*time.Minute), ""), 0), "without tolerance only exact times clash")
+	assert.True(t, TasksOverlap(task("a", base, ""), task("b", base.Add(90*time.Minute), "120"), 0), "a task takes its estimate up to its due time")
+	assert.False(t, TasksOverlap(task("a", base, ""), task("a", base, ""), 15*time.Minute), "a task does not clash with itself")
+
+	midnight := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	assert.False(t, TasksOverlap(task("a", midnight, ""), task("b", midnight, ""), 15*time.Minute), "tasks due on a day have no time")
+}
+
+func TestFindConflicts(t *testing.T) {
+	base := time.Date(2024, 6, 3, 15, 0, 0, 0, time.UTC)
+	at := func(id string, minutes int) *Task {
+		due := base.Add(time.Duration(minutes) * time.Minute)
+		return &Task{ID: common.TaskID(id), Title: id, DueDate: &due, Status: common.TaskStatusActive}
+	}
+	done := at("done", 5)
+	done.Status = common.TaskStatusCompleted
+
+	conflicts := FindConflicts([]*Task{at("late", 120), at("second", 10), done, at("first", 0), {ID: "undated", Status: common.TaskStatusActive}}, DefaultConflictTolerance)
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, "first", conflicts[0].Task.Title)
+	assert.Equal(t, "second", conflicts[0].Other.Title)
+}
+
+func TestNudgeService_WarnsAboutConflictsOnCreate(t *testing.T) {
+	service, repo, eventBus := newBulkTestService(t)
+	userID := common.UserID(common.NewID())
+	due := time.Now().Add(48 * time.Hour).Truncate(time.Hour).Add(30 * time.Minute)
+
+	existing := bulkTask(userID, "Dentist")
+	existing.ID = common.TaskID(common.NewID())
+	existing.DueDate = &due
+	require.NoError(t, repo.CreateTask(existing))
+
+	clashing := bulkTask(userID, "Team call")
+	clashing.ID = common.TaskID(common.NewID())
+	soon := due.Add(10 * time.Minute)
+	clashing.DueDate = &soon
+	require.NoError(t, service.CreateTask(clashing))
+
+	created := eventBus.GetPublishedEvents(events.TopicTaskCreated)
+	require.Len(t, created, 1)
+	conflicts := created[0].(events.TaskCreated).Conflicts
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, "Dentist", conflicts[0].OtherTitle)
+	assert.Equal(t, string(existing.ID), conflicts[0].OtherTaskID)
+}
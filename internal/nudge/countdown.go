@@ -0,0 +1,237 @@
+package nudge
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/tenant"
+
+	"go.uber.org/zap"
+)
+
+// Countdown keeps a message in the task's chat counting down to its due
+// date. There is at most one countdown per task.
+type Countdown struct {
+	TaskID       common.TaskID `json:"task_id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID     string        `json:"tenant_id,omitempty" gorm:"type:varchar(64);not null;default:'default';index"`
+	UserID       common.UserID `json:"user_id" gorm:"type:varchar(36);not null;index"`
+	ChatID       common.ChatID `json:"chat_id" gorm:"type:varchar(36);not null"`
+	MessageID    int           `json:"message_id" gorm:"not null"`
+	NextUpdateAt time.Time     `json:"next_update_at" gorm:"type:timestamp;not null;index"`
+	CreatedAt    time.Time     `json:"created_at" gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+}
+
+// TableName returns the table name for the Countdown model
+func (Countdown) TableName() string {
+	return "countdowns"
+}
+
+// CountdownInterval is how long a countdown with the given time left waits
+// before its message is edited again. Updates get more frequent as the due
+// time nears, keeping edits well inside Telegram's rate limits.
+func CountdownInterval(remaining time.Duration) time.Duration {
+	switch {
+	case remaining > 3*time.Hour:
+		return 30 * time.Minute
+	case remaining > time.Hour:
+		return 10 * time.Minute
+	case remaining > 15*time.Minute:
+		return 5 * time.Minute
+	default:
+		return time.Minute
+	}
+}
+
+// NewCountdownUpdate creates the CountdownUpdated event refreshing a countdown's message
+func NewCountdownUpdate(countdown *Countdown, title, state string, remaining time.Duration) events.CountdownUpdated {
+	return events.CountdownUpdated{
+		Event:     events.NewEvent(),
+		UserID:    string(countdown.UserID),
+		ChatID:    string(countdown.ChatID),
+		TaskID:    string(countdown.TaskID),
+		MessageID: countdown.MessageID,
+		Title:     title,
+		State:     state,
+		Remaining: remaining,
+	}
+}
+
+// CountdownService starts countdowns for tasks due within hours and ends
+// them when the task is done. The scheduler's countdown job keeps running
+// countdowns up to date.
+type CountdownService interface {
+	Start(taskID common.TaskID, userID common.UserID, chatID common.ChatID, messageID int) (*Countdown, *Task, error)
+	Ready() <-chan struct{}
+}
+
+// countdownService implements the CountdownService interface
+type countdownService struct {
+	eventBus   events.EventBus
+	logger     *zap.Logger
+	repository CountdownRepository
+	tasks      NudgeRepository
+	ready      *common.Readiness
+	now        func() time.Time
+}
+
+// NewCountdownService creates a CountdownService
+func NewCountdownService(eventBus events.EventBus, logger *zap.Logger, repository CountdownRepository, tasks NudgeRepository) CountdownService {
+	service := &countdownService{
+		eventBus:   eventBus,
+		logger:     logger,
+		repository: repository,
+		tasks:      tasks,
+		ready:      common.NewReadiness(),
+		now:        time.Now,
+	}
+
+	service.setupEventSubscriptions()
+
+	return service
+}
+
+// setupEventSubscriptions sets up event subscriptions for the countdown service
+func (s *countdownService) setupEventSubscriptions() {
+	if err := s.eventBus.Subscribe(events.TopicCountdownRequested, s.handleCountdownRequested); err != nil {
+		s.logger.Error("Failed to subscribe to CountdownRequested events", zap.Error(err))
+	}
+
+	if err := s.eventBus.Subscribe(events.TopicTaskStatusChanged, s.handleTaskStatusChanged); err != nil {
+		s.logger.Error("Failed to subscribe to TaskStatusChanged events", zap.Error(err))
+	}
+
+	s.ready.MarkReady()
+}
+
+// Ready returns a channel that is closed once event subscriptions are registered
+func (s *countdownService) Ready() <-chan struct{} {
+	return s.ready.Ready()
+}
+
+// Start counts down to the task's due date in the message. A countdown
+// already running for the task moves to the new message.
+func (s *countdownService) Start(taskID common.TaskID, userID common.UserID, chatID common.ChatID, messageID int) (*Countdown, *Task, error) {
+	task, err := s.tasks.WithContext(tenant.WithUser(context.Background(), userID)).GetTaskByID(taskID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if task.UserID != userID {
+		return nil, nil, NewBusinessRuleError("not_task_owner", "Only the task's owner can start a countdown.")
+	}
+	if task.Status == common.TaskStatusCompleted || task.Status == common.TaskStatusDeleted {
+		return nil, nil, NewBusinessRuleError("task_closed", "This task is already done.")
+	}
+	if task.DueDate == nil {
+		return nil, nil, NewBusinessRuleError("no_due_date", "This task has no due time to count down to.")
+	}
+
+	now := s.now()
+	remaining := task.DueDate.Sub(now)
+	if remaining <= 0 {
+		return nil, nil, NewBusinessRuleError("already_due", "This task is already due.")
+	}
+	if remaining > events.CountdownMaxLead {
+		return nil, nil, NewBusinessRuleError("due_too_late", "Countdowns are for tasks due within the next 12 hours.")
+	}
+
+	countdown := &Countdown{
+		TaskID:       task.ID,
+		TenantID:     task.TenantID,
+		UserID:       userID,
+		ChatID:       chatID,
+		MessageID:    messageID,
+		NextUpdateAt: now.Add(CountdownInterval(remaining)),
+	}
+	if err := s.repository.SaveCountdown(countdown); err != nil {
+		return nil, nil, err
+	}
+
+	s.logger.Info("Countdown started",
+		zap.String("taskID", string(task.ID)),
+		zap.Duration("remaining", remaining))
+	return countdown, task, nil
+}
+
+// handleCountdownRequested starts a countdown and shows its first update
+func (s *countdownService) handleCountdownRequested(event events.CountdownRequested) {
+	countdown, task, err := s.Start(common.TaskID(event.TaskID), common.UserID(event.UserID), common.ChatID(event.ChatID), event.MessageID)
+	if err != nil {
+		s.logger.Warn("Failed to start countdown",
+			zap.String("correlationID", event.CorrelationID),
+			zap.String("taskID", event.TaskID),
+			zap.Error(err))
+
+		rejected := events.CountdownUpdated{
+			Event:     events.NewEvent(),
+			UserID:    event.UserID,
+			ChatID:    event.ChatID,
+			TaskID:    event.TaskID,
+			MessageID: event.MessageID,
+			State:     events.CountdownRejected,
+			Message:   countdownErrorMessage(err),
+		}
+		rejected.CorrelationID = event.CorrelationID
+		s.publishUpdate(rejected)
+		return
+	}
+
+	update := NewCountdownUpdate(countdown, task.Title, events.CountdownRunning, task.DueDate.Sub(s.now()))
+	update.CorrelationID = event.CorrelationID
+	s.publishUpdate(update)
+}
+
+// handleTaskStatusChanged ends the countdown of a task that was completed or deleted
+func (s *countdownService) handleTaskStatusChanged(event events.TaskStatusChanged) {
+	if event.ToStatus != string(common.TaskStatusCompleted) && event.ToStatus != string(common.TaskStatusDeleted) {
+		return
+	}
+
+	countdown, err := s.repository.GetCountdown(common.TaskID(event.TaskID))
+	if err != nil {
+		s.logger.Error("Failed to look up countdown",
+			zap.String("taskID", event.TaskID),
+			zap.Error(err))
+		return
+	}
+	if countdown == nil {
+		return
+	}
+	if err := s.repository.DeleteCountdown(countdown.TaskID); err != nil {
+		s.logger.Error("Failed to end countdown",
+			zap.String("taskID", event.TaskID),
+			zap.Error(err))
+		return
+	}
+
+	title := ""
+	ctx := tenant.WithID(tenant.WithUser(context.Background(), countdown.UserID), countdown.TenantID)
+	if task, err := s.tasks.WithContext(ctx).GetTaskByID(countdown.TaskID); err == nil {
+		title = task.Title
+	}
+	update := NewCountdownUpdate(countdown, title, events.CountdownDone, 0)
+	update.CorrelationID = event.CorrelationID
+	s.publishUpdate(update)
+}
+
+// publishUpdate publishes a CountdownUpdated event
+func (s *countdownService) publishUpdate(update events.CountdownUpdated) {
+	if err := s.eventBus.Publish(events.TopicCountdownUpdated, update); err != nil {
+		s.logger.Error("Failed to publish CountdownUpdated event", zap.Error(err))
+	}
+}
+
+// countdownErrorMessage returns a message that can be shown to the user
+func countdownErrorMessage(err error) string {
+	var nudgeErr NudgeError
+	if errors.As(err, &nudgeErr) && nudgeErr.Code() != ErrCodeRepository {
+		return nudgeErr.Message()
+	}
+	var notFound common.NotFoundError
+	if errors.As(err, &notFound) || errors.Is(err, ErrTaskNotFound) {
+		return "I couldn't find that task. See your tasks with /list."
+	}
+	return "Something went wrong, please try again later."
+}
@@ -0,0 +1,160 @@
+package nudge
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"nudgebot-api/internal/common"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CountdownRepository persists running countdowns. Looking up a task
+// without a countdown returns nil without an error.
+type CountdownRepository interface {
+	// SaveCountdown stores a countdown, replacing the task's previous one
+	SaveCountdown(countdown *Countdown) error
+	GetCountdown(taskID common.TaskID) (*Countdown, error)
+	// GetDueCountdowns returns up to limit countdowns whose next update is
+	// not after now, earliest first
+	GetDueCountdowns(now time.Time, limit int) ([]*Countdown, error)
+	DeleteCountdown(taskID common.TaskID) error
+}
+
+// gormCountdownRepository implements CountdownRepository using GORM
+type gormCountdownRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewGormCountdownRepository creates a new GORM-based countdown repository
+func NewGormCountdownRepository(db *gorm.DB, logger *zap.Logger) CountdownRepository {
+	return &gormCountdownRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// SaveCountdown stores a countdown, replacing the task's previous one
+func (r *gormCountdownRepository) SaveCountdown(countdown *Countdown) error {
+	if countdown.CreatedAt.IsZero() {
+		countdown.CreatedAt = time.Now()
+	}
+
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "task_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "chat_id", "message_id", "next_update_at"}),
+	}).Create(countdown).Error
+	if err != nil {
+		return WrapRepositoryError(err, "save countdown")
+	}
+	return nil
+}
+
+// GetCountdown retrieves a task's countdown
+func (r *gormCountdownRepository) GetCountdown(taskID common.TaskID) (*Countdown, error) {
+	var countdowns []*Countdown
+	if err := r.db.Where("task_id = ?", taskID).Limit(1).Find(&countdowns).Error; err != nil {
+		return nil, WrapRepositoryError(err, "get countdown")
+	}
+	if len(countdowns) == 0 {
+		return nil, nil
+	}
+	return countdowns[0], nil
+}
+
+// GetDueCountdowns retrieves countdowns whose message is due for an update
+func (r *gormCountdownRepository) GetDueCountdowns(now time.Time, limit int) ([]*Countdown, error) {
+	var countdowns []*Countdown
+	err := r.db.
+		Where("next_update_at <= ?", now).
+		Order("next_update_at ASC").
+		Limit(limit).
+		Find(&countdowns).Error
+	if err != nil {
+		return nil, WrapRepositoryError(err, "get due countdowns")
+	}
+	return countdowns, nil
+}
+
+// DeleteCountdown removes a task's countdown
+func (r *gormCountdownRepository) DeleteCountdown(taskID common.TaskID) error {
+	if err := r.db.Where("task_id = ?", taskID).Delete(&Countdown{}).Error; err != nil {
+		return WrapRepositoryError(err, "delete countdown")
+	}
+
+	r.logger.Debug("Countdown deleted", zap.String("taskID", string(taskID)))
+	return nil
+}
+
+// memoryCountdownRepository implements CountdownRepository in memory
+type memoryCountdownRepository struct {
+	mu         sync.RWMutex
+	countdowns map[common.TaskID]*Countdown
+}
+
+// NewMemoryCountdownRepository creates a CountdownRepository that is not persisted
+func NewMemoryCountdownRepository() CountdownRepository {
+	return &memoryCountdownRepository{
+		countdowns: make(map[common.TaskID]*Countdown),
+	}
+}
+
+// SaveCountdown stores a countdown, replacing the task's previous one
+func (r *memoryCountdownRepository) SaveCountdown(countdown *Countdown) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if countdown.CreatedAt.IsZero() {
+		countdown.CreatedAt = time.Now()
+	}
+	countdownCopy := *countdown
+	r.countdowns[countdown.TaskID] = &countdownCopy
+	return nil
+}
+
+// GetCountdown retrieves a task's countdown
+func (r *memoryCountdownRepository) GetCountdown(taskID common.TaskID) (*Countdown, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	countdown, ok := r.countdowns[taskID]
+	if !ok {
+		return nil, nil
+	}
+	countdownCopy := *countdown
+	return &countdownCopy, nil
+}
+
+// GetDueCountdowns retrieves countdowns whose message is due for an update
+func (r *memoryCountdownRepository) GetDueCountdowns(now time.Time, limit int) ([]*Countdown, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var countdowns []*Countdown
+	for _, countdown := range r.countdowns {
+		if !countdown.NextUpdateAt.After(now) {
+			countdownCopy := *countdown
+			countdowns = append(countdowns, &countdownCopy)
+		}
+	}
+	sort.Slice(countdowns, func(i, j int) bool {
+		return countdowns[i].NextUpdateAt.Before(countdowns[j].NextUpdateAt)
+	})
+	if len(countdowns) > limit {
+		countdowns = countdowns[:limit]
+	}
+	return countdowns, nil
+}
+
+// DeleteCountdown removes a task's countdown
+func (r *memoryCountdownRepository) DeleteCountdown(taskID common.TaskID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.countdowns, taskID)
+	return nil
+}
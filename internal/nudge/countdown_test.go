@@ -0,0 +1,85 @@
+package nudge
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestCountdownInterval(t *testing.T) {
+	assert.Equal(t, 30*time.Minute, CountdownInterval(5*time.Hour))
+	assert.Equal(t, 10*time.Minute, CountdownInterval(2*time.Hour))
+	assert.Equal(t, 5*time.Minute, CountdownInterval(40*time.Minute))
+	assert.Equal(t, time.Minute, CountdownInterval(10*time.Minute))
+}
+
+func TestCountdownService_StartsAndEndsWithTask(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	eventBus := events.NewMockEventBus()
+	eventBus.SetSynchronousMode(true)
+	tasks := NewMemoryNudgeRepository(logger)
+	countdowns := NewMemoryCountdownRepository()
+	NewCountdownService(eventBus, logger, countdowns, tasks)
+	userID := common.UserID(common.NewID())
+
+	newTask := func(title string, due *time.Time) *Task {
+		task := &Task{
+			ID:       common.TaskID(common.NewID()),
+			UserID:   userID,
+			ChatID:   "12345",
+			Title:    title,
+			DueDate:  due,
+			Priority: common.PriorityHigh,
+			Status:   common.TaskStatusActive,
+		}
+		require.NoError(t, tasks.CreateTask(task))
+		return task
+	}
+	soon := time.Now().Add(2*time.Hour + 15*time.Minute)
+	later := time.Now().Add(48 * time.Hour)
+	task := newTask("Submit grant", &soon)
+
+	require.NoError(t, eventBus.Publish(events.TopicCountdownRequested, events.CountdownRequested{
+		Event: events.NewEvent(), UserID: string(userID), ChatID: "12345", TaskID: string(task.ID), MessageID: 99,
+	}))
+
+	updates := eventBus.GetPublishedEvents(events.TopicCountdownUpdated)
+	require.Len(t, updates, 1)
+	first := updates[0].(events.CountdownUpdated)
+	assert.Equal(t, events.CountdownRunning, first.State)
+	assert.Equal(t, 99, first.MessageID)
+	assert.InDelta(t, (2*time.Hour + 15*time.Minute).Seconds(), first.Remaining.Seconds(), 5)
+
+	countdown, err := countdowns.GetCountdown(task.ID)
+	require.NoError(t, err)
+	require.NotNil(t, countdown)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), countdown.NextUpdateAt, 5*time.Second)
+
+	// Completing the task ends the countdown right away
+	require.NoError(t, eventBus.Publish(events.TopicTaskStatusChanged, events.TaskStatusChanged{
+		Event: events.NewEvent(), TaskID: string(task.ID), UserID: string(userID), ToStatus: string(common.TaskStatusCompleted),
+	}))
+	updates = eventBus.GetPublishedEvents(events.TopicCountdownUpdated)
+	require.Len(t, updates, 2)
+	assert.Equal(t, events.CountdownDone, updates[1].(events.CountdownUpdated).State)
+	countdown, err = countdowns.GetCountdown(task.ID)
+	require.NoError(t, err)
+	assert.Nil(t, countdown)
+
+	// Tasks without a due time in the next hours get no countdown
+	service := NewCountdownService(events.NewMockEventBus(), logger, countdowns, tasks)
+	var ruleErr BusinessRuleError
+	_, _, err = service.Start(newTask("Book flights", &later).ID, userID, "12345", 1)
+	assert.True(t, errors.As(err, &ruleErr))
+	_, _, err = service.Start(newTask("Read book", nil).ID, userID, "12345", 1)
+	assert.True(t, errors.As(err, &ruleErr))
+	_, _, err = service.Start(task.ID, common.UserID(common.NewID()), "12345", 1)
+	assert.True(t, errors.As(err, &ruleErr))
+}
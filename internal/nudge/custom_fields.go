@@ -0,0 +1,145 @@
+package nudge
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CustomFieldType is the type of a user-defined task field
+type CustomFieldType string
+
+// Custom field types
+const (
+	CustomFieldText   CustomFieldType = "text"
+	CustomFieldNumber CustomFieldType = "number"
+	CustomFieldDate   CustomFieldType = "date"
+	CustomFieldEnum   CustomFieldType = "enum"
+)
+
+// Custom field limits
+const (
+	MaxCustomFields         = 20
+	MaxCustomFieldTextLen   = 500
+	MaxCustomFieldEnumCount = 20
+	CustomFieldDateLayout   = "2006-01-02"
+)
+
+// customFieldKeyPattern restricts keys to short lowercase identifiers, which keeps
+// them safe to use in JSONB paths and chat commands
+var customFieldKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+// IsValid checks if the custom field type is valid
+func (t CustomFieldType) IsValid() bool {
+	switch t {
+	case CustomFieldText, CustomFieldNumber, CustomFieldDate, CustomFieldEnum:
+		return true
+	default:
+		return false
+	}
+}
+
+// CustomField is the typed value of one user-defined task field. Value is kept
+// in a normalized string form so fields filter by plain equality: numbers are
+// formatted without trailing zeros and dates as YYYY-MM-DD.
+type CustomField struct {
+	Type    CustomFieldType `json:"type"`
+	Value   string          `json:"value"`
+	Options []string        `json:"options,omitempty"` // allowed values of an enum field
+}
+
+// CustomFields maps a field key to its value; stored as JSONB on the task
+type CustomFields map[string]CustomField
+
+// NewCustomField parses raw into a typed field. Enum values must match one of
+// options, ignoring case, and are stored as the matching option.
+func NewCustomField(fieldType CustomFieldType, raw string, options []string) (CustomField, error) {
+	raw = strings.TrimSpace(raw)
+	field := CustomField{Type: fieldType}
+
+	switch fieldType {
+	case CustomFieldText:
+		if raw == "" || len(raw) > MaxCustomFieldTextLen {
+			return field, NewTaskValidationError("custom_field", raw, "text must be 1-500 characters")
+		}
+		field.Value = raw
+	case CustomFieldNumber:
+		number, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return field, NewTaskValidationError("custom_field", raw, "value is not a number")
+		}
+		field.Value = strconv.FormatFloat(number, 'f', -1, 64)
+	case CustomFieldDate:
+		date, err := time.Parse(CustomFieldDateLayout, raw)
+		if err != nil {
+			return field, NewTaskValidationError("custom_field", raw, "date must be YYYY-MM-DD")
+		}
+		field.Value = date.Format(CustomFieldDateLayout)
+	case CustomFieldEnum:
+		if len(options) == 0 || len(options) > MaxCustomFieldEnumCount {
+			return field, NewTaskValidationError("custom_field", options, "enum needs 1-20 options")
+		}
+		for _, option := range options {
+			if strings.EqualFold(option, raw) {
+				field.Value = option
+			}
+		}
+		if field.Value == "" {
+			return field, NewTaskValidationError("custom_field", raw, "value must be one of "+strings.Join(options, ", "))
+		}
+		field.Options = options
+	default:
+		return field, NewTaskValidationError("custom_field", fieldType, "unknown field type")
+	}
+
+	return field, nil
+}
+
+// ValidateCustomFieldKey checks that a custom field key is a short lowercase identifier
+func ValidateCustomFieldKey(key string) error {
+	if !customFieldKeyPattern.MatchString(key) {
+		return NewTaskValidationError("custom_field", key, "key must be lowercase letters, digits or underscores, starting with a letter")
+	}
+	return nil
+}
+
+// Validate checks the number of fields, their keys and types
+func (f CustomFields) Validate() error {
+	if len(f) > MaxCustomFields {
+		return NewTaskValidationError("custom_fields", len(f), "a task can have at most 20 custom fields")
+	}
+	for key, field := range f {
+		if err := ValidateCustomFieldKey(key); err != nil {
+			return err
+		}
+		if !field.Type.IsValid() {
+			return NewTaskValidationError("custom_fields", field.Type, "unknown field type")
+		}
+	}
+	return nil
+}
+
+// Matches reports whether every wanted key/value pair is present. Values are
+// compared in their normalized form.
+func (f CustomFields) Matches(wanted map[string]string) bool {
+	for key, value := range wanted {
+		field, ok := f[key]
+		if !ok || field.Value != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Display returns the field values by key, or nil when there are none
+func (f CustomFields) Display() map[string]string {
+	if len(f) == 0 {
+		return nil
+	}
+	display := make(map[string]string, len(f))
+	for key, field := range f {
+		display[key] = field.Value
+	}
+	return display
+}
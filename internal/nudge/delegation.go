@@ -0,0 +1,268 @@
+package nudge
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/user"
+
+	"go.uber.org/zap"
+)
+
+// DelegationStatus tracks a task's hand-off to another user
+type DelegationStatus string
+
+const (
+	DelegationPending  DelegationStatus = "pending"
+	DelegationAccepted DelegationStatus = "accepted"
+	DelegationDeclined DelegationStatus = "declined"
+)
+
+// ErrDelegateNotFound is returned when no user of the bot has the delegate's username
+var ErrDelegateNotFound = errors.New("delegate not found")
+
+// delegateTask offers the delegator's task to the bot's user with the
+// username. The task stays with the delegator until the delegate accepts.
+func (s *nudgeService) delegateTask(taskID common.TaskID, delegatorID common.UserID, bot, username string) (*Task, *user.User, error) {
+	if s.repository == nil || s.users == nil {
+		return nil, nil, NewBusinessRuleError("delegation_unavailable", "Handing off tasks is not available right now.")
+	}
+
+	username = strings.TrimPrefix(strings.TrimSpace(username), "@")
+	if username == "" {
+		return nil, nil, NewTaskValidationError("username", username, "name the user to hand the task to, e.g. @alex")
+	}
+
+	task, err := s.repository.GetTaskByID(taskID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if task.UserID != delegatorID {
+		return nil, nil, NewBusinessRuleError("not_task_owner", "Only the task's owner can hand it off.")
+	}
+	if task.Status == common.TaskStatusCompleted {
+		return nil, nil, NewBusinessRuleError("task_completed", "This task is already done.")
+	}
+
+	delegate, err := s.users.FindByUsername(bot, username)
+	if err != nil {
+		return nil, nil, err
+	}
+	if delegate == nil {
+		return nil, nil, ErrDelegateNotFound
+	}
+	if delegate.ID == delegatorID {
+		return nil, nil, NewBusinessRuleError("self_delegation", "You can't hand a task off to yourself.")
+	}
+
+	task.DelegateID = delegate.ID
+	task.DelegatedBy = delegatorID
+	task.DelegationStatus = DelegationPending
+	if err := s.repository.UpdateTask(task); err != nil {
+		return nil, nil, err
+	}
+
+	s.logger.Info("Task delegated",
+		zap.String("taskID", string(task.ID)),
+		zap.String("delegatedBy", string(delegatorID)),
+		zap.String("delegateID", string(delegate.ID)))
+	return task, delegate, nil
+}
+
+// answerDelegation records the delegate's answer to a pending hand-off and
+// returns the task with the chat it was in before. Accepting moves the task,
+// and its reminders, to the delegate in chatID.
+func (s *nudgeService) answerDelegation(taskID common.TaskID, delegateID common.UserID, chatID common.ChatID, accept bool) (*Task, common.ChatID, error) {
+	if s.repository == nil {
+		return nil, "", fmt.Errorf("repository not initialized")
+	}
+
+	task, err := s.repository.GetTaskByID(taskID)
+	if err != nil {
+		return nil, "", err
+	}
+	if task.DelegateID != delegateID || task.DelegationStatus != DelegationPending {
+		return nil, "", NewBusinessRuleError("no_pending_delegation", "This task is no longer waiting for your answer.")
+	}
+	previousChat := task.ChatID
+
+	if !accept {
+		task.DelegationStatus = DelegationDeclined
+		if err := s.repository.UpdateTask(task); err != nil {
+			return nil, "", err
+		}
+		s.logger.Info("Task delegation declined",
+			zap.String("taskID", string(task.ID)),
+			zap.String("delegateID", string(delegateID)))
+		return task, previousChat, nil
+	}
+
+	task.UserID = delegateID
+	task.ChatID = chatID
+	task.ThreadID = 0
+	task.DelegationStatus = DelegationAccepted
+	if err := s.repository.UpdateTask(task); err != nil {
+		return nil, "", err
+	}
+
+	// Reminders go to the task's new owner from now on
+	s.cancelTaskReminders(task.ID)
+	if task.Status != common.TaskStatusCompleted {
+		s.scheduleInitialReminder(task)
+	}
+
+	s.logger.Info("Task delegation accepted",
+		zap.String("taskID", string(task.ID)),
+		zap.String("delegateID", string(delegateID)))
+	return task, previousChat, nil
+}
+
+// handleTaskDelegationRequested offers a task to the user named in a /delegate command
+func (s *nudgeService) handleTaskDelegationRequested(event events.TaskDelegationRequested) {
+	s = s.forUser(event.UserID)
+	response := newDelegationResponse(event.Event, event.UserID, event.ChatID, event.TaskID, events.DelegationActionDelegate)
+
+	task, delegate, err := s.delegateTask(common.TaskID(event.TaskID), common.UserID(event.UserID), event.Bot, event.Username)
+	if err != nil {
+		s.logger.Warn("Failed to delegate task",
+			zap.String("correlationID", event.CorrelationID),
+			zap.String("taskID", event.TaskID),
+			zap.Error(err))
+		response.Message = delegationErrorMessage(err)
+		s.publishDelegationResponse(response)
+		return
+	}
+
+	response.Success = true
+	response.Title = task.Title
+	response.Message = fmt.Sprintf("I asked %s to take it over and will tell you when they answer.", displayName(delegate))
+	s.publishDelegationResponse(response)
+
+	offer := events.TaskDelegated{
+		Event:       events.NewEvent(),
+		UserID:      string(delegate.ID),
+		ChatID:      string(delegate.ID), // the delegate's private chat
+		TaskID:      string(task.ID),
+		Title:       task.Title,
+		DueDate:     task.DueDate,
+		DelegatedBy: s.userName(task.DelegatedBy),
+	}
+	offer.CorrelationID = event.CorrelationID
+
+	if err := s.eventBus.Publish(events.TopicTaskDelegated, offer); err != nil {
+		s.logger.Error("Failed to publish TaskDelegated event", zap.Error(err))
+	}
+}
+
+// handleTaskDelegationReplied applies the delegate's answer and tells the delegator
+func (s *nudgeService) handleTaskDelegationReplied(event events.TaskDelegationReplied) {
+	s = s.forUser(event.UserID)
+	action := events.DelegationActionDecline
+	if event.Accept {
+		action = events.DelegationActionAccept
+	}
+	response := newDelegationResponse(event.Event, event.UserID, event.ChatID, event.TaskID, action)
+
+	// The delegator is told in the chat the task was in before it changed hands
+	task, delegatorChat, err := s.answerDelegation(common.TaskID(event.TaskID), common.UserID(event.UserID), common.ChatID(event.ChatID), event.Accept)
+	if err != nil {
+		s.logger.Warn("Failed to answer task delegation",
+			zap.String("correlationID", event.CorrelationID),
+			zap.String("taskID", event.TaskID),
+			zap.Error(err))
+		response.Message = delegationErrorMessage(err)
+		s.publishDelegationResponse(response)
+		return
+	}
+
+	response.Success = true
+	response.Title = task.Title
+	if event.Accept {
+		response.Message = "It's yours now; you'll get its reminders from here on."
+	} else {
+		response.Message = "No problem, I'll let them know."
+	}
+	s.publishDelegationResponse(response)
+
+	resolved := events.TaskDelegationResolved{
+		Event:    events.NewEvent(),
+		UserID:   string(task.DelegatedBy),
+		ChatID:   string(delegatorChat),
+		TaskID:   string(task.ID),
+		Title:    task.Title,
+		Delegate: s.userName(task.DelegateID),
+		Accepted: event.Accept,
+	}
+	resolved.CorrelationID = event.CorrelationID
+
+	if err := s.eventBus.Publish(events.TopicTaskDelegationResolved, resolved); err != nil {
+		s.logger.Error("Failed to publish TaskDelegationResolved event", zap.Error(err))
+	}
+}
+
+// userName returns how a user is shown to other users
+func (s *nudgeService) userName(userID common.UserID) string {
+	if s.users == nil {
+		return displayName(nil)
+	}
+	profile, err := s.users.GetByID(userID)
+	if err != nil {
+		s.logger.Warn("Failed to look up user name",
+			zap.String("userID", string(userID)),
+			zap.Error(err))
+	}
+	return displayName(profile)
+}
+
+// displayName returns the user's @username, falling back to their first name
+func displayName(profile *user.User) string {
+	switch {
+	case profile == nil:
+		return "someone"
+	case profile.Username != "":
+		return "@" + profile.Username
+	case profile.FirstName != "":
+		return profile.FirstName
+	default:
+		return "someone"
+	}
+}
+
+// newDelegationResponse creates a TaskDelegationResponse answering a request
+func newDelegationResponse(request events.Event, userID, chatID, taskID, action string) events.TaskDelegationResponse {
+	response := events.TaskDelegationResponse{
+		Event:  events.NewEvent(),
+		UserID: userID,
+		ChatID: chatID,
+		TaskID: taskID,
+		Action: action,
+	}
+	response.CorrelationID = request.CorrelationID
+	return response
+}
+
+// publishDelegationResponse publishes a TaskDelegationResponse
+func (s *nudgeService) publishDelegationResponse(response events.TaskDelegationResponse) {
+	if err := s.eventBus.Publish(events.TopicTaskDelegationResponse, response); err != nil {
+		s.logger.Error("Failed to publish TaskDelegationResponse", zap.Error(err))
+	}
+}
+
+// delegationErrorMessage returns a message that can be shown to the user
+func delegationErrorMessage(err error) string {
+	var nudgeErr NudgeError
+	if errors.As(err, &nudgeErr) && nudgeErr.Code() != ErrCodeRepository {
+		return nudgeErr.Message()
+	}
+	var notFound common.NotFoundError
+	if errors.As(err, &notFound) || errors.Is(err, ErrTaskNotFound) {
+		return "I couldn't find that task. See your tasks with /list."
+	}
+	if errors.Is(err, ErrDelegateNotFound) {
+		return "I don't know that user yet. Ask them to start a chat with me first."
+	}
+	return "Something went wrong, please try again later."
+}
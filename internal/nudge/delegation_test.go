@@ -0,0 +1,135 @@
+package nudge
+
+import (
+	"testing"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestNudgeService_DelegatesTaskOnceAccepted(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	eventBus := events.NewMockEventBus()
+	eventBus.SetSynchronousMode(true)
+	repository := NewMemoryNudgeRepository(logger)
+	users := user.NewMemoryRepository()
+	service, err := NewNudgeServiceWithDelegation(eventBus, logger, repository, nil, nil, nil, users)
+	require.NoError(t, err)
+	nudge := service.(*nudgeService)
+
+	alice, bob := common.UserID(common.NewID()), common.UserID(common.NewID())
+	_, err = users.Upsert(&user.User{ID: alice, TelegramID: 1, Username: "alice"})
+	require.NoError(t, err)
+	_, err = users.Upsert(&user.User{ID: bob, TelegramID: 2, Username: "Bob"})
+	require.NoError(t, err)
+
+	task := &Task{ID: common.TaskID(common.NewID()), UserID: alice, ChatID: common.ChatID(alice), Title: "File taxes", Priority: common.PriorityMedium, Status: common.TaskStatusActive}
+	require.NoError(t, repository.CreateTask(task))
+
+	nudge.handleTaskDelegationRequested(events.TaskDelegationRequested{
+		Event:    events.NewEvent(),
+		UserID:   string(alice),
+		ChatID:   string(alice),
+		TaskID:   string(task.ID),
+		Username: "@bob",
+	})
+
+	offers := eventBus.GetPublishedEvents(events.TopicTaskDelegated)
+	require.Len(t, offers, 1)
+	offer := offers[0].(events.TaskDelegated)
+	assert.Equal(t, string(bob), offer.UserID)
+	assert.Equal(t, "@alice", offer.DelegatedBy)
+
+	pending, err := repository.GetTaskByID(task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, alice, pending.UserID, "the task stays with the delegator until accepted")
+	assert.Equal(t, DelegationPending, pending.DelegationStatus)
+
+	nudge.handleTaskDelegationReplied(events.TaskDelegationReplied{
+		Event:  events.NewEvent(),
+		UserID: string(bob),
+		ChatID: string(bob),
+		TaskID: string(task.ID),
+		Accept: true,
+	})
+
+	accepted, err := repository.GetTaskByID(task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, bob, accepted.UserID)
+	assert.Equal(t, common.ChatID(bob), accepted.ChatID)
+	assert.Equal(t, DelegationAccepted, accepted.DelegationStatus)
+
+	resolved := eventBus.GetPublishedEvents(events.TopicTaskDelegationResolved)
+	require.Len(t, resolved, 1)
+	notice := resolved[0].(events.TaskDelegationResolved)
+	assert.Equal(t, string(alice), notice.UserID)
+	assert.Equal(t, string(alice), notice.ChatID)
+	assert.Equal(t, "@Bob", notice.Delegate)
+	assert.True(t, notice.Accepted)
+
+	// The offer can only be answered once
+	nudge.handleTaskDelegationReplied(events.TaskDelegationReplied{
+		Event:  events.NewEvent(),
+		UserID: string(bob),
+		ChatID: string(bob),
+		TaskID: string(task.ID),
+	})
+	responses := eventBus.GetPublishedEvents(events.TopicTaskDelegationResponse)
+	require.Len(t, responses, 3)
+	assert.False(t, responses[2].(events.TaskDelegationResponse).Success)
+}
+
+func TestNudgeService_DelegationDeclinedOrRejected(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	eventBus := events.NewMockEventBus()
+	eventBus.SetSynchronousMode(true)
+	repository := NewMemoryNudgeRepository(logger)
+	users := user.NewMemoryRepository()
+	service, err := NewNudgeServiceWithDelegation(eventBus, logger, repository, nil, nil, nil, users)
+	require.NoError(t, err)
+	nudge := service.(*nudgeService)
+
+	alice, bob := common.UserID(common.NewID()), common.UserID(common.NewID())
+	_, err = users.Upsert(&user.User{ID: alice, TelegramID: 1, Username: "alice"})
+	require.NoError(t, err)
+	_, err = users.Upsert(&user.User{ID: bob, TelegramID: 2, Username: "bob"})
+	require.NoError(t, err)
+
+	task := &Task{ID: common.TaskID(common.NewID()), UserID: alice, ChatID: "team-chat", Title: "Book venue", Priority: common.PriorityMedium, Status: common.TaskStatusActive}
+	require.NoError(t, repository.CreateTask(task))
+
+	_, _, err = nudge.delegateTask(task.ID, bob, "", "alice")
+	assert.Error(t, err, "only the owner can hand a task off")
+	_, _, err = nudge.delegateTask(task.ID, alice, "", "alice")
+	assert.Error(t, err, "a task cannot be handed to its owner")
+	_, _, err = nudge.delegateTask(task.ID, alice, "", "carol")
+	assert.ErrorIs(t, err, ErrDelegateNotFound)
+	_, _, err = nudge.delegateTask(task.ID, alice, "other-bot", "bob")
+	assert.ErrorIs(t, err, ErrDelegateNotFound, "users are looked up per bot")
+
+	_, _, err = nudge.delegateTask(task.ID, alice, "", "bob")
+	require.NoError(t, err)
+
+	nudge.handleTaskDelegationReplied(events.TaskDelegationReplied{
+		Event:  events.NewEvent(),
+		UserID: string(bob),
+		ChatID: string(bob),
+		TaskID: string(task.ID),
+	})
+
+	declined, err := repository.GetTaskByID(task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, alice, declined.UserID)
+	assert.Equal(t, DelegationDeclined, declined.DelegationStatus)
+
+	resolved := eventBus.GetPublishedEvents(events.TopicTaskDelegationResolved)
+	require.Len(t, resolved, 1)
+	notice := resolved[0].(events.TaskDelegationResolved)
+	assert.Equal(t, "team-chat", notice.ChatID)
+	assert.False(t, notice.Accepted)
+}
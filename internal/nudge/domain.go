@@ -1,6 +1,7 @@
 package nudge
 
 import (
+	"strings"
 	"time"
 
 	"nudgebot-api/internal/common"
@@ -9,6 +10,7 @@ import (
 // Task represents a task in the nudge system
 type Task struct {
 	ID          common.TaskID     `json:"id" gorm:"primaryKey;type:varchar(36)" validate:"required"`
+	TenantID    string            `json:"tenant_id,omitempty" gorm:"type:varchar(64);not null;default:'default';index"`
 	UserID      common.UserID     `json:"user_id" gorm:"type:varchar(36);not null;index" validate:"required"`
 	ChatID      common.ChatID     `json:"chat_id" gorm:"type:varchar(36);index"`
 	Title       string            `json:"title" gorm:"type:varchar(255);not null" validate:"required"`
@@ -19,12 +21,50 @@ type Task struct {
 	CreatedAt   time.Time         `json:"created_at" gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
 	UpdatedAt   time.Time         `json:"updated_at" gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
 	CompletedAt *time.Time        `json:"completed_at" gorm:"type:timestamp"`
+	// Muted stops reminders and nudges for the task while it stays open
+	Muted bool `json:"muted" gorm:"not null;default:false"`
+	// OverdueSince caches the due date the task was found overdue for; it
+	// stops matching when the due date moves, so the task can become overdue again
+	OverdueSince *time.Time `json:"overdue_since,omitempty" gorm:"type:timestamp"`
+	// Habit makes the task repeat: completing it logs an occurrence and moves
+	// the due date to the end of the next period instead of closing the task
+	Habit      HabitPeriod `json:"habit,omitempty" gorm:"type:varchar(10)"`
+	Streak     int         `json:"streak,omitempty" gorm:"not null;default:0"`
+	BestStreak int         `json:"best_streak,omitempty" gorm:"not null;default:0"`
+	LastDoneAt *time.Time  `json:"last_done_at,omitempty" gorm:"type:timestamp"`
+	// ListID is the shared list the task is in; every member of the list sees it
+	ListID common.ID `json:"list_id,omitempty" gorm:"type:varchar(36);index"`
+	// DelegatedBy offered the task to DelegateID; the task changes hands
+	// once the delegate accepts
+	DelegateID       common.UserID    `json:"delegate_id,omitempty" gorm:"type:varchar(36);index"`
+	DelegatedBy      common.UserID    `json:"delegated_by,omitempty" gorm:"type:varchar(36)"`
+	DelegationStatus DelegationStatus `json:"delegation_status,omitempty" gorm:"type:varchar(10)"`
+	// ThreadID is the forum topic of ChatID the task was created in; its
+	// reminders are posted there. 0 outside forums.
+	ThreadID int `json:"thread_id,omitempty" gorm:"not null;default:0"`
+	// Tags are the labels parsed from the task's message
+	Tags []string `json:"tags,omitempty" gorm:"type:jsonb;serializer:json"`
+	// OriginalText is the full message a task was summarized from, such as a
+	// pasted email; empty when the message was parsed as it is
+	OriginalText string `json:"original_text,omitempty" gorm:"type:text"`
+	// Code numbers the task among its user's tasks, so that commands can
+	// name it as T-42 instead of by ID; 0 for tasks created before codes
+	Code int `json:"code,omitempty" gorm:"not null;default:0"`
+
+	// CustomFields holds the user-defined fields set on the task
+	CustomFields CustomFields `json:"custom_fields,omitempty" gorm:"type:jsonb;serializer:json"`
+	// Links are the URLs the task references, used for previews and dedupe
+	Links TaskLinks `json:"links,omitempty" gorm:"type:jsonb;serializer:json;index:idx_tasks_links,type:gin"`
+	// Location is the place the task mentions, geocoded in the background
+	// when a geocoder is configured
+	Location *TaskLocation `json:"location,omitempty" gorm:"type:jsonb;serializer:json"`
 }
 
 // Reminder represents a reminder for a task
 type Reminder struct {
 	ID           common.ID     `json:"id" gorm:"primaryKey;type:varchar(36)" validate:"required"`
 	TaskID       common.TaskID `json:"task_id" gorm:"type:varchar(36);not null;index" validate:"required"`
+	TenantID     string        `json:"tenant_id,omitempty" gorm:"type:varchar(64);not null;default:'default';index"`
 	UserID       common.UserID `json:"user_id" gorm:"type:varchar(36);not null;index" validate:"required"`
 	ChatID       common.ChatID `json:"chat_id" gorm:"type:varchar(36);not null;index" validate:"required"`
 	ScheduledAt  time.Time     `json:"scheduled_at" gorm:"type:timestamp;not null" validate:"required"`
@@ -40,15 +80,29 @@ const (
 	ReminderTypeNudge   ReminderType = "nudge"
 )
 
-// TaskFilter represents filtering options for querying tasks
+// TaskFilter represents filtering options for querying tasks. Statuses matches
+// any of the listed statuses and is combined with Status when both are set.
+//
+// CustomFields matches tasks whose custom fields have all the given values, in
+// the normalized form stored on the task.
+//
+// ListIDs widens the user's tasks to those of other members in these shared lists.
+//
+// Code matches the task with that short code number. Codes are numbered per
+// user, so it is only meaningful without ListIDs.
 type TaskFilter struct {
-	UserID    common.UserID      `json:"user_id"`
-	Status    *common.TaskStatus `json:"status,omitempty"`
-	Priority  *common.Priority   `json:"priority,omitempty"`
-	DueBefore *time.Time         `json:"due_before,omitempty"`
-	DueAfter  *time.Time         `json:"due_after,omitempty"`
-	Limit     int                `json:"limit,omitempty"`
-	Offset    int                `json:"offset,omitempty"`
+	UserID    common.UserID       `json:"user_id"`
+	ListIDs   []common.ID         `json:"list_ids,omitempty"`
+	Status    *common.TaskStatus  `json:"status,omitempty"`
+	Statuses  []common.TaskStatus `json:"statuses,omitempty"`
+	Priority  *common.Priority    `json:"priority,omitempty"`
+	DueBefore *time.Time          `json:"due_before,omitempty"`
+	DueAfter  *time.Time          `json:"due_after,omitempty"`
+	Limit     int                 `json:"limit,omitempty"`
+	Offset    int                 `json:"offset,omitempty"`
+	Code      int                 `json:"code,omitempty"`
+
+	CustomFields map[string]string `json:"custom_fields,omitempty"`
 }
 
 // TaskStats represents statistics about a user's tasks
@@ -57,16 +111,210 @@ type TaskStats struct {
 	CompletedTasks int64 `json:"completed_tasks"`
 	OverdueTasks   int64 `json:"overdue_tasks"`
 	ActiveTasks    int64 `json:"active_tasks"`
+	// ByStatus counts the non-deleted tasks in each status
+	ByStatus map[common.TaskStatus]int64 `json:"by_status,omitempty"`
+	// ByPriority and ByTag count the open tasks in each priority and with each tag
+	ByPriority map[common.Priority]int64 `json:"by_priority,omitempty"`
+	ByTag      map[string]int64          `json:"by_tag,omitempty"`
+	// OverdueByAge breaks OverdueTasks down by how long they have been overdue
+	OverdueByAge OverdueBuckets `json:"overdue_by_age"`
+}
+
+// OverdueBuckets counts overdue tasks by how long ago they were due
+type OverdueBuckets struct {
+	UnderADay        int64 `json:"under_a_day"`
+	OneToThreeDays   int64 `json:"one_to_three_days"`
+	ThreeToSevenDays int64 `json:"three_to_seven_days"`
+	OverAWeek        int64 `json:"over_a_week"`
+}
+
+// Add counts a task overdue by the given time in its bucket
+func (b *OverdueBuckets) Add(overdue time.Duration) {
+	switch {
+	case overdue < 24*time.Hour:
+		b.UnderADay++
+	case overdue < 3*24*time.Hour:
+		b.OneToThreeDays++
+	case overdue < 7*24*time.Hour:
+		b.ThreeToSevenDays++
+	default:
+		b.OverAWeek++
+	}
+}
+
+// NewTaskStats creates empty task statistics
+func NewTaskStats() *TaskStats {
+	return &TaskStats{
+		ByStatus:   make(map[common.TaskStatus]int64),
+		ByPriority: make(map[common.Priority]int64),
+		ByTag:      make(map[string]int64),
+	}
+}
+
+// countOpen adds an open task to the priority, tag and overdue breakdowns
+func (s *TaskStats) countOpen(task *Task, now time.Time) {
+	s.ByPriority[task.Priority]++
+	for _, tag := range task.Tags {
+		s.ByTag[tag]++
+	}
+	if task.DueDate != nil && task.DueDate.Before(now) {
+		s.OverdueByAge.Add(now.Sub(*task.DueDate))
+	}
+}
+
+// MatchesOwner reports whether a task of the user, or in one of the filter's
+// shared lists, passes the filter
+func (f TaskFilter) MatchesOwner(task Task, userID common.UserID) bool {
+	if task.UserID == userID {
+		return true
+	}
+	if task.ListID == "" {
+		return false
+	}
+	for _, listID := range f.ListIDs {
+		if task.ListID == listID {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesStatus reports whether a task in the given status passes the filter's status constraints
+func (f TaskFilter) MatchesStatus(status common.TaskStatus) bool {
+	if f.Status != nil && status != *f.Status {
+		return false
+	}
+	if len(f.Statuses) == 0 {
+		return true
+	}
+	for _, allowed := range f.Statuses {
+		if status == allowed {
+			return true
+		}
+	}
+	return false
 }
 
 // NudgeSettings represents user-specific nudge settings
 type NudgeSettings struct {
 	UserID        common.UserID `json:"user_id" gorm:"primaryKey;type:varchar(36)" validate:"required"`
+	TenantID      string        `json:"tenant_id,omitempty" gorm:"type:varchar(64);not null;default:'default';index"`
 	NudgeInterval time.Duration `json:"nudge_interval" gorm:"type:bigint;not null;default:3600000000000"` // 1 hour in nanoseconds
 	MaxNudges     int           `json:"max_nudges" gorm:"type:int;not null;default:3"`
 	Enabled       bool          `json:"enabled" gorm:"type:boolean;not null;default:true"`
-	CreatedAt     time.Time     `json:"created_at" gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
-	UpdatedAt     time.Time     `json:"updated_at" gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+
+	// Quiet hours hold reminders between start and end ("HH:MM", may wrap past
+	// midnight) in the user's timezone. Empty values disable quiet hours.
+	QuietHoursStart string `json:"quiet_hours_start,omitempty" gorm:"type:varchar(5)"`
+	QuietHoursEnd   string `json:"quiet_hours_end,omitempty" gorm:"type:varchar(5)"`
+	Timezone        string `json:"timezone,omitempty" gorm:"type:varchar(64)"` // IANA name, UTC when empty
+	// UrgentOverride lets high and urgent priority reminders through quiet hours
+	UrgentOverride bool `json:"urgent_override" gorm:"type:boolean;not null;default:false"`
+
+	// ActivityDeferral holds reminders that fall due within this long after the
+	// user last interacted with the bot in the chat. Nil uses the scheduler's
+	// default; zero turns deferral off.
+	ActivityDeferral *time.Duration `json:"activity_deferral,omitempty" gorm:"type:bigint"`
+
+	// GroupReminders sends the reminders due for a chat in the same scheduler
+	// cycle as one message, instead of one message per task
+	GroupReminders bool `json:"group_reminders" gorm:"type:boolean;not null;default:false"`
+
+	// Reminder routes per task priority, as comma-separated channels such as
+	// "telegram,email". Empty routes send reminders to Telegram only; urgent
+	// tasks follow the high priority route.
+	ChannelsHigh   string `json:"channels_high,omitempty" gorm:"type:varchar(64)"`
+	ChannelsMedium string `json:"channels_medium,omitempty" gorm:"type:varchar(64)"`
+	ChannelsLow    string `json:"channels_low,omitempty" gorm:"type:varchar(64)"`
+	// Email receives the reminders routed to the email channel
+	Email string `json:"email,omitempty" gorm:"type:varchar(255)"`
+
+	// Confidence thresholds replace the deployment's for the user's parses,
+	// so they can confirm more or fewer of them. Nil uses the deployment's.
+	ConfidenceHigh   *float64 `json:"confidence_high,omitempty" gorm:"type:double precision"`
+	ConfidenceMedium *float64 `json:"confidence_medium,omitempty" gorm:"type:double precision"`
+	ConfidenceLow    *float64 `json:"confidence_low,omitempty" gorm:"type:double precision"`
+
+	// RetentionDays is how long completed tasks are kept before the retention
+	// purge deletes them, one of RetentionOptions; 0 keeps them forever
+	RetentionDays int `json:"retention_days" gorm:"type:int;not null;default:0"`
+
+	// Language picks the language messages are parsed in, such as "vi" or
+	// "pt-br"; empty follows the user's Telegram client
+	Language string `json:"language,omitempty" gorm:"type:varchar(16)"`
+
+	// WeekStart is the weekday the user's week starts on, such as "sunday",
+	// and WorkingDays their working days, such as "sun,mon,tue,wed,thu".
+	// Empty values are Monday and Monday to Friday.
+	WeekStart   string `json:"week_start,omitempty" gorm:"type:varchar(9)"`
+	WorkingDays string `json:"working_days,omitempty" gorm:"type:varchar(32)"`
+
+	// Country is the ISO 3166 code, such as "US", whose public holidays the
+	// user observes; empty observes none
+	Country string `json:"country,omitempty" gorm:"type:varchar(2)"`
+
+	// BlockedAt is when the user blocked BlockedBot, empty for the default
+	// bot. While set, reminders through that bot are paused; nil once they
+	// unblock it.
+	BlockedAt  *time.Time `json:"blocked_at,omitempty" gorm:"type:timestamp"`
+	BlockedBot string     `json:"blocked_bot,omitempty" gorm:"type:varchar(64)"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+}
+
+// Location returns the user's timezone, UTC when unset or unknown
+func (s *NudgeSettings) Location() *time.Location {
+	if s == nil || s.Timezone == "" {
+		return time.UTC
+	}
+	location, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// Week returns how the user's week runs, common.DefaultWeek when unset
+func (s *NudgeSettings) Week() common.Week {
+	if s == nil {
+		return common.DefaultWeek
+	}
+	return common.NewWeek(s.WeekStart, s.WorkingDays)
+}
+
+// ReminderChannels returns the channels reminders for a task of the given
+// priority go to, nil for Telegram only
+func (s *NudgeSettings) ReminderChannels(priority common.Priority) []string {
+	if s == nil {
+		return nil
+	}
+
+	route := s.ChannelsMedium
+	switch priority {
+	case common.PriorityUrgent, common.PriorityHigh:
+		route = s.ChannelsHigh
+	case common.PriorityLow:
+		route = s.ChannelsLow
+	}
+	return splitChannels(route)
+}
+
+// BlockedOn reports whether the user has blocked the bot, empty for the
+// default bot
+func (s *NudgeSettings) BlockedOn(bot string) bool {
+	return s != nil && s.BlockedAt != nil && s.BlockedBot == bot
+}
+
+// splitChannels parses a comma-separated channel route
+func splitChannels(route string) []string {
+	var channels []string
+	for _, channel := range strings.Split(route, ",") {
+		if channel = strings.TrimSpace(channel); channel != "" {
+			channels = append(channels, channel)
+		}
+	}
+	return channels
 }
 
 // IsValid checks if the reminder type is valid
@@ -84,7 +332,16 @@ func (t Task) IsOverdue() bool {
 	if t.DueDate == nil {
 		return false
 	}
-	return time.Now().After(*t.DueDate) && t.Status == common.TaskStatusActive
+	return time.Now().After(*t.DueDate) && t.Status.IsOpen()
+}
+
+// NewlyOverdue reports whether the task passed its due date before now
+// without having been marked overdue for that due date
+func (t Task) NewlyOverdue(now time.Time) bool {
+	if t.DueDate == nil || !t.DueDate.Before(now) || !t.Status.IsOpen() {
+		return false
+	}
+	return t.OverdueSince == nil || !t.OverdueSince.Equal(*t.DueDate)
 }
 
 // IsCompleted checks if the task is completed
@@ -92,9 +349,10 @@ func (t Task) IsCompleted() bool {
 	return t.Status == common.TaskStatusCompleted
 }
 
-// CanBeNudged checks if the task can receive nudges
+// CanBeNudged checks if the task can receive nudges. Waiting tasks are blocked
+// on someone else, so only active and in-progress tasks are nudged.
 func (t Task) CanBeNudged() bool {
-	return t.Status == common.TaskStatusActive && t.DueDate != nil
+	return (t.Status == common.TaskStatusActive || t.Status == common.TaskStatusInProgress) && t.DueDate != nil
 }
 
 // TableName returns the table name for the Task model
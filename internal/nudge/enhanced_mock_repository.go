@@ -3,6 +3,7 @@
 package nudge
 
 import (
+	"context"
 	"sync"
 	"time"
 
@@ -114,10 +115,15 @@ func (m *EnhancedMockNudgeRepository) CreateTask(task *Task) error {
 
 	// Check for duplicates
 	for _, existingTask := range m.tasks {
-		if existingTask.UserID == task.UserID && existingTask.Title == task.Title &&
-			(existingTask.Status == common.TaskStatusActive || existingTask.Status == common.TaskStatusSnoozed) {
+		if existingTask.UserID != task.UserID || !isDedupeStatus(existingTask.Status) {
+			continue
+		}
+		if existingTask.Title == task.Title {
 			return NewTaskValidationError("title", task.Title, "task with this title already exists")
 		}
+		if link, shared := existingTask.Links.Shares(task.Links); shared {
+			return NewTaskValidationError("links", link, "task with this link already exists")
+		}
 	}
 
 	// Set timestamps
@@ -171,7 +177,7 @@ func (m *EnhancedMockNudgeRepository) GetTasksByUserID(userID common.UserID, fil
 		}
 
 		// Apply filters
-		if filter.Status != nil && task.Status != *filter.Status {
+		if !filter.MatchesStatus(task.Status) || !task.CustomFields.Matches(filter.CustomFields) {
 			continue
 		}
 		if filter.Priority != nil && task.Priority != *filter.Priority {
@@ -256,7 +262,7 @@ func (m *EnhancedMockNudgeRepository) GetTaskStats(userID common.UserID) (*TaskS
 		return nil, err
 	}
 
-	stats := &TaskStats{}
+	stats := NewTaskStats()
 	now := time.Now()
 
 	for _, task := range m.tasks {
@@ -265,21 +271,169 @@ func (m *EnhancedMockNudgeRepository) GetTaskStats(userID common.UserID) (*TaskS
 		}
 
 		stats.TotalTasks++
+		stats.ByStatus[task.Status]++
 
 		switch task.Status {
 		case common.TaskStatusCompleted:
 			stats.CompletedTasks++
 		case common.TaskStatusActive:
 			stats.ActiveTasks++
+		}
+		if task.Status.IsOpen() {
 			if task.DueDate != nil && task.DueDate.Before(now) {
 				stats.OverdueTasks++
 			}
+			stats.countOpen(task, now)
 		}
 	}
 
 	return stats, nil
 }
 
+// GetOverdueTasks retrieves active tasks past their due date
+func (m *EnhancedMockNudgeRepository) GetOverdueTasks(userID common.UserID) ([]*Task, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	m.incrementCallCount("GetOverdueTasks")
+
+	if err := m.checkError("GetOverdueTasks"); err != nil {
+		return nil, err
+	}
+
+	var result []*Task
+	for _, task := range m.tasks {
+		if task.UserID == userID && task.IsOverdue() {
+			taskCopy := *task
+			result = append(result, &taskCopy)
+		}
+	}
+
+	return result, nil
+}
+
+// BulkUpdateTaskStatus updates multiple tasks' status
+func (m *EnhancedMockNudgeRepository) BulkUpdateTaskStatus(taskIDs []common.TaskID, status common.TaskStatus) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.incrementCallCount("BulkUpdateTaskStatus")
+
+	if err := m.checkError("BulkUpdateTaskStatus"); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, taskID := range taskIDs {
+		task, exists := m.tasks[string(taskID)]
+		if !exists {
+			continue
+		}
+
+		task.Status = status
+		task.UpdatedAt = now
+		if status == common.TaskStatusCompleted {
+			task.CompletedAt = &now
+		}
+	}
+
+	return nil
+}
+
+// GetNewlyOverdueTasks retrieves up to limit tasks that passed their due date
+// without being marked overdue for it
+func (m *EnhancedMockNudgeRepository) GetNewlyOverdueTasks(now time.Time, limit int) ([]*Task, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	m.incrementCallCount("GetNewlyOverdueTasks")
+
+	if err := m.checkError("GetNewlyOverdueTasks"); err != nil {
+		return nil, err
+	}
+
+	var result []*Task
+	for _, task := range m.tasks {
+		if task.NewlyOverdue(now) && (limit <= 0 || len(result) < limit) {
+			taskCopy := *task
+			result = append(result, &taskCopy)
+		}
+	}
+
+	return result, nil
+}
+
+// MarkTaskOverdue records the due date a task was found overdue for
+func (m *EnhancedMockNudgeRepository) MarkTaskOverdue(taskID common.TaskID, dueDate time.Time) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.incrementCallCount("MarkTaskOverdue")
+
+	if err := m.checkError("MarkTaskOverdue"); err != nil {
+		return err
+	}
+
+	if task, exists := m.tasks[string(taskID)]; exists && task.DueDate != nil && task.DueDate.Equal(dueDate) {
+		task.OverdueSince = &dueDate
+	}
+	return nil
+}
+
+// BulkShiftDueDates moves the due dates of several tasks and their unsent reminders
+func (m *EnhancedMockNudgeRepository) BulkShiftDueDates(taskIDs []common.TaskID, shift time.Duration) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.incrementCallCount("BulkShiftDueDates")
+
+	if err := m.checkError("BulkShiftDueDates"); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	shifted := make(map[common.TaskID]bool, len(taskIDs))
+	for _, taskID := range taskIDs {
+		shifted[taskID] = true
+		task, exists := m.tasks[string(taskID)]
+		if !exists || task.DueDate == nil {
+			continue
+		}
+
+		due := task.DueDate.Add(shift)
+		task.DueDate = &due
+		task.UpdatedAt = now
+	}
+	for _, reminder := range m.reminders {
+		if shifted[reminder.TaskID] && reminder.SentAt == nil {
+			reminder.ScheduledAt = reminder.ScheduledAt.Add(shift)
+		}
+	}
+
+	return nil
+}
+
+// MoveChatID readdresses the tasks and reminders of a chat to another chat ID
+func (m *EnhancedMockNudgeRepository) MoveChatID(from, to common.ChatID) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.incrementCallCount("MoveChatID")
+
+	if err := m.checkError("MoveChatID"); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, task := range m.tasks {
+		if task.ChatID == from {
+			task.ChatID = to
+			task.UpdatedAt = now
+		}
+	}
+	for _, reminder := range m.reminders {
+		if reminder.ChatID == from {
+			reminder.ChatID = to
+		}
+	}
+
+	return nil
+}
+
 // Reminder operations
 
 // CreateReminder creates a new reminder
@@ -455,6 +609,12 @@ func (m *EnhancedMockNudgeRepository) DeleteNudgeSettings(userID common.UserID)
 	return nil
 }
 
+// WithContext returns the mock itself; it has no tenants to scope
+func (m *EnhancedMockNudgeRepository) WithContext(ctx context.Context) NudgeRepository {
+	m.incrementCallCount("WithContext")
+	return m
+}
+
 // WithTransaction executes a function within a simulated transaction
 func (m *EnhancedMockNudgeRepository) WithTransaction(fn func(NudgeRepository) error) error {
 	m.incrementCallCount("WithTransaction")
@@ -2,6 +2,7 @@ package nudge
 
 import (
 	"fmt"
+	"strings"
 
 	"nudgebot-api/internal/common"
 )
@@ -23,6 +24,7 @@ const (
 	ErrCodeUserNotFound         = "USER_NOT_FOUND"
 	ErrCodeUnauthorized         = "UNAUTHORIZED"
 	ErrCodeInvalidRequest       = "INVALID_REQUEST"
+	ErrCodeBulkCreateFailed     = "BULK_CREATE_FAILED"
 )
 
 // NudgeError interface for nudge-specific errors
@@ -218,6 +220,53 @@ func (e TaskListError) Unwrap() error {
 	return e.Cause
 }
 
+// TaskCreateFailure describes why one task in a bulk creation failed
+type TaskCreateFailure struct {
+	Index int
+	Title string
+	Err   error
+}
+
+// BulkCreateError reports a bulk task creation that stored nothing, listing the tasks that failed
+type BulkCreateError struct {
+	Total    int
+	Failures []TaskCreateFailure
+}
+
+func (e BulkCreateError) Error() string {
+	details := make([]string, 0, len(e.Failures))
+	for _, failure := range e.Failures {
+		details = append(details, fmt.Sprintf("task %d (%q): %v", failure.Index, failure.Title, failure.Err))
+	}
+	return fmt.Sprintf("bulk task creation failed for %d of %d tasks: %s", len(e.Failures), e.Total, strings.Join(details, "; "))
+}
+
+func (e BulkCreateError) Code() string {
+	return ErrCodeBulkCreateFailed
+}
+
+func (e BulkCreateError) Message() string {
+	return fmt.Sprintf("%d of %d tasks could not be created; no tasks were saved", len(e.Failures), e.Total)
+}
+
+func (e BulkCreateError) Temporary() bool {
+	for _, failure := range e.Failures {
+		if nudgeErr, ok := failure.Err.(NudgeError); !ok || !nudgeErr.Temporary() {
+			return false
+		}
+	}
+	return len(e.Failures) > 0
+}
+
+// Unwrap exposes the individual failures to errors.Is and errors.As
+func (e BulkCreateError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failures))
+	for _, failure := range e.Failures {
+		errs = append(errs, failure.Err)
+	}
+	return errs
+}
+
 // Error wrapping utilities
 
 // WrapRepositoryError wraps an error as a RepositoryError
@@ -1,6 +1,8 @@
 package nudge
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"time"
 
@@ -39,10 +41,7 @@ func (r *gormNudgeRepository) CreateTask(task *Task) error {
 	// Check for duplicate tasks (same user, title, and status)
 	var existingCount int64
 	err := r.db.Model(&Task{}).
-		Where("user_id = ? AND title = ? AND status IN ?", task.UserID, task.Title, []common.TaskStatus{
-			common.TaskStatusActive,
-			common.TaskStatusSnoozed,
-		}).
+		Where("user_id = ? AND title = ? AND status IN ?", task.UserID, task.Title, dedupeStatuses()).
 		Count(&existingCount).Error
 
 	if err != nil {
@@ -53,6 +52,23 @@ func (r *gormNudgeRepository) CreateTask(task *Task) error {
 		return NewTaskValidationError("title", task.Title, "task with this title already exists for user")
 	}
 
+	// Check for open tasks referencing the same link
+	for _, link := range task.Links {
+		contains, err := json.Marshal(TaskLinks{{URL: link.URL}})
+		if err != nil {
+			return WrapRepositoryError(err, "link duplicate check")
+		}
+		err = r.db.Model(&Task{}).
+			Where("user_id = ? AND status IN ? AND links @> CAST(? AS jsonb)", task.UserID, dedupeStatuses(), string(contains)).
+			Count(&existingCount).Error
+		if err != nil {
+			return WrapRepositoryError(err, "link duplicate check")
+		}
+		if existingCount > 0 {
+			return NewTaskValidationError("links", link.URL, "task with this link already exists for user")
+		}
+	}
+
 	// Set timestamps
 	now := time.Now()
 	task.CreatedAt = now
@@ -98,15 +114,29 @@ func (r *gormNudgeRepository) GetTasksByUserID(userID common.UserID, filter Task
 
 	// Build query using query builder
 	qb := NewQueryBuilder(r.db)
-	taskQuery := qb.TaskQuery().WithUserID(userID)
+	taskQuery := qb.TaskQuery()
+	if len(filter.ListIDs) > 0 {
+		taskQuery = taskQuery.WithUserOrLists(userID, filter.ListIDs)
+	} else {
+		taskQuery = taskQuery.WithUserID(userID)
+	}
 
 	// Apply filters
 	if filter.Status != nil {
 		taskQuery = taskQuery.WithStatus(*filter.Status)
 	}
+	if len(filter.Statuses) > 0 {
+		taskQuery = taskQuery.WithStatuses(filter.Statuses)
+	}
+	if len(filter.CustomFields) > 0 {
+		taskQuery = taskQuery.WithCustomFields(filter.CustomFields)
+	}
 	if filter.Priority != nil {
 		taskQuery = taskQuery.WithPriority(*filter.Priority)
 	}
+	if filter.Code > 0 {
+		taskQuery = taskQuery.WithCode(filter.Code)
+	}
 	if filter.DueAfter != nil || filter.DueBefore != nil {
 		taskQuery = taskQuery.WithDueDateRange(filter.DueAfter, filter.DueBefore)
 	}
@@ -241,13 +271,7 @@ func (r *gormNudgeRepository) CreateReminder(reminder *Reminder) error {
 func (r *gormNudgeRepository) GetDueReminders(before time.Time) ([]*Reminder, error) {
 	r.logger.Debug("Getting due reminders", zap.Time("before", before))
 
-	qb := NewQueryBuilder(r.db)
-	reminders, err := qb.ReminderQuery().
-		WithDueBefore(before).
-		WithUnsent().
-		WithTaskJoin().
-		Find()
-
+	reminders, err := dueRemindersQuery(r.db, before).Find()
 	if err != nil {
 		return nil, WrapRepositoryError(err, "get due reminders")
 	}
@@ -382,6 +406,15 @@ func (r *gormNudgeRepository) DeleteNudgeSettings(userID common.UserID) error {
 
 // Transaction support
 
+// WithContext returns the repository running its queries with ctx, so a
+// tenant the context carries scopes them through the tenant plugin
+func (r *gormNudgeRepository) WithContext(ctx context.Context) NudgeRepository {
+	return &gormNudgeRepository{
+		db:     r.db.WithContext(ctx),
+		logger: r.logger,
+	}
+}
+
 // WithTransaction executes a function within a database transaction
 func (r *gormNudgeRepository) WithTransaction(fn func(NudgeRepository) error) error {
 	r.logger.Debug("Starting transaction")
@@ -456,6 +489,78 @@ func (r *gormNudgeRepository) BulkUpdateTaskStatus(taskIDs []common.TaskID, stat
 	return nil
 }
 
+// BulkShiftDueDates moves the due dates of several tasks and their unsent reminders
+func (r *gormNudgeRepository) BulkShiftDueDates(taskIDs []common.TaskID, shift time.Duration) error {
+	r.logger.Debug("Bulk shifting task due dates",
+		zap.Int("count", len(taskIDs)),
+		zap.Duration("shift", shift))
+
+	if len(taskIDs) == 0 {
+		return nil
+	}
+
+	err := BulkShiftDueDates(r.db, taskIDs, shift)
+	if err != nil {
+		return WrapRepositoryError(err, "bulk shift due dates")
+	}
+
+	r.logger.Info("Bulk due date shift completed", zap.Int("count", len(taskIDs)))
+	return nil
+}
+
+// MoveChatID readdresses the tasks, reminders and other rows of a chat to another chat ID
+func (r *gormNudgeRepository) MoveChatID(from, to common.ChatID) error {
+	r.logger.Debug("Moving chat ID",
+		zap.String("from", string(from)),
+		zap.String("to", string(to)))
+
+	if err := MoveChatID(r.db, from, to); err != nil {
+		return WrapRepositoryError(err, "move chat ID")
+	}
+
+	r.logger.Info("Chat ID moved",
+		zap.String("from", string(from)),
+		zap.String("to", string(to)))
+	return nil
+}
+
+// GetNewlyOverdueTasks retrieves open tasks that passed their due date since
+// they were last marked overdue, earliest due date first
+func (r *gormNudgeRepository) GetNewlyOverdueTasks(now time.Time, limit int) ([]*Task, error) {
+	r.logger.Debug("Getting newly overdue tasks", zap.Time("now", now), zap.Int("limit", limit))
+
+	qb := NewQueryBuilder(r.db)
+	tasks, err := qb.TaskQuery().
+		WithNewlyOverdue(now).
+		OrderByDueDate().
+		WithPagination(limit, 0).
+		Find()
+
+	if err != nil {
+		return nil, WrapRepositoryError(err, "get newly overdue tasks")
+	}
+
+	return tasks, nil
+}
+
+// MarkTaskOverdue records the due date a task was found overdue for, unless
+// the due date has moved in the meantime
+func (r *gormNudgeRepository) MarkTaskOverdue(taskID common.TaskID, dueDate time.Time) error {
+	r.logger.Debug("Marking task overdue",
+		zap.String("taskID", string(taskID)),
+		zap.Time("dueDate", dueDate))
+
+	// updated_at is left alone, the cached flag is not an edit of the task
+	result := r.db.Model(&Task{}).
+		Where("id = ? AND due_date = ?", taskID, dueDate).
+		UpdateColumn("overdue_since", dueDate)
+
+	if result.Error != nil {
+		return WrapRepositoryError(result.Error, "mark task overdue")
+	}
+	return nil
+}
+
 // CleanupOldData removes old sent reminders and deleted tasks
 func (r *gormNudgeRepository) CleanupOldData(olderThan time.Duration) error {
 	r.logger.Debug("Cleaning up old data", zap.Duration("olderThan", olderThan))
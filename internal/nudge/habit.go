@@ -0,0 +1,142 @@
+package nudge
+
+import (
+	"time"
+
+	"nudgebot-api/internal/common"
+)
+
+// HabitPeriod is how often a habit task is meant to be done
+type HabitPeriod string
+
+// Habit periods
+const (
+	HabitDaily  HabitPeriod = "daily"
+	HabitWeekly HabitPeriod = "weekly"
+)
+
+// IsValid checks if the habit period is valid
+func (p HabitPeriod) IsValid() bool {
+	switch p {
+	case HabitDaily, HabitWeekly:
+		return true
+	default:
+		return false
+	}
+}
+
+// Start returns the start of the period containing t, in t's location. Weeks
+// start on Monday.
+func (p HabitPeriod) Start(t time.Time) time.Time {
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	if p == HabitWeekly {
+		daysSinceMonday := (int(start.Weekday()) + 6) % 7
+		start = start.AddDate(0, 0, -daysSinceMonday)
+	}
+	return start
+}
+
+// End returns the end of the period containing t, which is when the next one starts
+func (p HabitPeriod) End(t time.Time) time.Time {
+	return p.next(p.Start(t))
+}
+
+// next returns the start of the period after the one starting at start
+func (p HabitPeriod) next(start time.Time) time.Time {
+	if p == HabitWeekly {
+		return start.AddDate(0, 0, 7)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// previous returns the start of the period before the one starting at start
+func (p HabitPeriod) previous(start time.Time) time.Time {
+	if p == HabitWeekly {
+		return start.AddDate(0, 0, -7)
+	}
+	return start.AddDate(0, 0, -1)
+}
+
+// Noun names one period, as in "5-day streak" or "this week"
+func (p HabitPeriod) Noun() string {
+	if p == HabitWeekly {
+		return "week"
+	}
+	return "day"
+}
+
+// Current names the period containing now, as in "done for today"
+func (p HabitPeriod) Current() string {
+	if p == HabitWeekly {
+		return "this week"
+	}
+	return "today"
+}
+
+// IsHabit reports whether the task repeats as a habit instead of being completed once
+func (t Task) IsHabit() bool {
+	return t.Habit != ""
+}
+
+// DoneThisPeriod reports whether the habit was already logged in the period containing now
+func (t Task) DoneThisPeriod(now time.Time) bool {
+	return t.LastDoneAt != nil && !t.LastDoneAt.Before(t.Habit.Start(now))
+}
+
+// LogHabit records an occurrence of the habit at now, given in the user's
+// timezone. The streak grows when the previous period was done too and starts
+// over otherwise; the task stays open, due at the end of the next period.
+func (t *Task) LogHabit(now time.Time) error {
+	if !t.IsHabit() {
+		return NewBusinessRuleError("not_a_habit", "only habits can be logged")
+	}
+	if !t.Status.IsOpen() {
+		return NewBusinessRuleError("habit_closed", "the habit is no longer open")
+	}
+	if t.DoneThisPeriod(now) {
+		return NewBusinessRuleError("habit_already_logged", "the habit is already done for "+t.Habit.Current())
+	}
+
+	start := t.Habit.Start(now)
+	if t.LastDoneAt != nil && !t.LastDoneAt.Before(t.Habit.previous(start)) {
+		t.Streak++
+	} else {
+		t.Streak = 1
+	}
+	if t.Streak > t.BestStreak {
+		t.BestStreak = t.Streak
+	}
+
+	due := t.Habit.next(t.Habit.next(start))
+	t.LastDoneAt = &now
+	t.DueDate = &due
+	t.Status = common.TaskStatusActive
+	t.UpdatedAt = time.Now()
+	return nil
+}
+
+// RollOverHabit moves a habit on once its due date passed, with now in the
+// user's timezone. A due date left inside its period by a snooze moves back to
+// the end of the period. A period that ended without the habit done is missed:
+// the streak starts over and the task becomes due at the end of the current
+// period. It reports whether a period was missed and the streak that was lost.
+func (t *Task) RollOverHabit(now time.Time) (bool, int) {
+	if t.Status == common.TaskStatusSnoozed {
+		t.Status = common.TaskStatusActive
+	}
+	t.UpdatedAt = time.Now()
+
+	if t.DueDate != nil {
+		periodEnd := t.Habit.End(t.DueDate.In(now.Location()).Add(-time.Nanosecond))
+		if now.Before(periodEnd) {
+			t.DueDate = &periodEnd
+			return false, 0
+		}
+	}
+
+	lost := t.Streak
+	due := t.Habit.End(now)
+	t.Streak = 0
+	t.DueDate = &due
+	return true, lost
+}
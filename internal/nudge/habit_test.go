@@ -0,0 +1,114 @@
+package nudge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHabitPeriod_Bounds(t *testing.T) {
+	// Wednesday afternoon
+	now := time.Date(2024, 1, 10, 15, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC), HabitDaily.Start(now))
+	assert.Equal(t, time.Date(2024, 1, 11, 0, 0, 0, 0, time.UTC), HabitDaily.End(now))
+	assert.Equal(t, time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC), HabitWeekly.Start(now), "weeks start on Monday")
+	assert.Equal(t, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), HabitWeekly.End(now))
+
+	sunday := time.Date(2024, 1, 14, 23, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC), HabitWeekly.Start(sunday))
+}
+
+func TestTask_LogHabit(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 8, 0, 0, 0, time.UTC) }
+	habit := &Task{Habit: HabitDaily, Status: common.TaskStatusActive}
+
+	require.NoError(t, habit.LogHabit(day(10)))
+	require.NoError(t, habit.LogHabit(day(11)))
+	assert.Equal(t, 2, habit.Streak)
+	assert.Equal(t, common.TaskStatusActive, habit.Status, "habits stay open")
+	assert.Equal(t, time.Date(2024, 1, 13, 0, 0, 0, 0, time.UTC), *habit.DueDate, "due at the end of the next day")
+
+	assert.Error(t, habit.LogHabit(day(11).Add(time.Hour)), "once per day")
+
+	require.NoError(t, habit.LogHabit(day(13)))
+	assert.Equal(t, 1, habit.Streak, "a skipped day starts the streak over")
+	assert.Equal(t, 2, habit.BestStreak)
+
+	assert.Error(t, (&Task{Status: common.TaskStatusActive}).LogHabit(day(10)), "only habits are logged")
+}
+
+func TestTask_RollOverHabit(t *testing.T) {
+	now := time.Date(2024, 1, 10, 15, 0, 0, 0, time.UTC)
+
+	t.Run("a snooze inside the period is not a miss", func(t *testing.T) {
+		snoozedUntil := now.Add(-time.Minute)
+		habit := &Task{Habit: HabitDaily, Status: common.TaskStatusSnoozed, Streak: 4, DueDate: &snoozedUntil}
+
+		missed, _ := habit.RollOverHabit(now)
+		assert.False(t, missed)
+		assert.Equal(t, 4, habit.Streak)
+		assert.Equal(t, common.TaskStatusActive, habit.Status)
+		assert.Equal(t, HabitDaily.End(now), *habit.DueDate)
+	})
+
+	t.Run("a period that ended undone is missed", func(t *testing.T) {
+		due := HabitDaily.Start(now)
+		habit := &Task{Habit: HabitDaily, Status: common.TaskStatusActive, Streak: 4, BestStreak: 6, DueDate: &due}
+
+		missed, lost := habit.RollOverHabit(now)
+		assert.True(t, missed)
+		assert.Equal(t, 4, lost)
+		assert.Zero(t, habit.Streak)
+		assert.Equal(t, 6, habit.BestStreak)
+		assert.Equal(t, HabitDaily.End(now), *habit.DueDate)
+	})
+}
+
+func TestNudgeService_CompletingAHabitLogsIt(t *testing.T) {
+	service, repo, eventBus := newBulkTestService(t)
+	userID := common.UserID(common.NewID())
+	task := bulkTask(userID, "Exercise")
+	task.ID = common.TaskID(common.NewID())
+	task.Habit = HabitDaily
+	require.NoError(t, service.CreateTask(task))
+	require.NotNil(t, task.DueDate, "a habit is due at the end of its first period")
+
+	service.(*nudgeService).handleTaskActionRequested(events.TaskActionRequested{
+		Event:  events.NewEvent(),
+		UserID: string(userID),
+		ChatID: "12345",
+		TaskID: string(task.ID),
+		Action: "done",
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, service.Stop(ctx))
+
+	responses := eventBus.GetPublishedEvents(events.TopicTaskActionResponse)
+	require.Len(t, responses, 1)
+	response := responses[0].(events.TaskActionResponse)
+	assert.True(t, response.Success)
+	assert.Equal(t, "log_habit", response.Action)
+	assert.Equal(t, "Done for today! 🔥 1-day streak.", response.Message)
+
+	stored, err := repo.GetTaskByID(task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, common.TaskStatusActive, stored.Status)
+	assert.Nil(t, stored.CompletedAt)
+	assert.Equal(t, 1, stored.Streak)
+	require.NotNil(t, stored.LastDoneAt)
+
+	logged := eventBus.GetPublishedEvents(events.TopicHabitLogged)
+	require.Len(t, logged, 1)
+	assert.Equal(t, 1, logged[0].(events.HabitLogged).Streak)
+	assert.Empty(t, eventBus.GetPublishedEvents(events.TopicTaskCompleted))
+
+	assert.Error(t, service.UpdateTaskStatus(task.ID, common.TaskStatusCompleted), "a habit is logged once per period")
+}
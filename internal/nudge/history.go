@@ -0,0 +1,294 @@
+package nudge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/tenant"
+
+	"go.uber.org/zap"
+)
+
+// TaskEventKind is the kind of entry in a task's history
+type TaskEventKind string
+
+// Task history entry kinds
+const (
+	TaskEventCreated       TaskEventKind = "created"
+	TaskEventStatusChanged TaskEventKind = "status_changed"
+	TaskEventSnoozed       TaskEventKind = "snoozed"
+	TaskEventNudgeSent     TaskEventKind = "nudge_sent"
+	TaskEventEdited        TaskEventKind = "edited"
+	TaskEventHabitLogged   TaskEventKind = "habit_logged"
+	TaskEventHabitMissed   TaskEventKind = "habit_missed"
+)
+
+// TaskEvent is one entry in a task's history, recorded from the events
+// published about the task
+type TaskEvent struct {
+	ID         common.ID     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TaskID     common.TaskID `json:"task_id" gorm:"type:varchar(36);not null;index"`
+	TenantID   string        `json:"tenant_id,omitempty" gorm:"type:varchar(64);not null;default:'default';index"`
+	Kind       TaskEventKind `json:"kind" gorm:"type:varchar(20);not null"`
+	FromStatus string        `json:"from_status,omitempty" gorm:"type:varchar(20)"`
+	ToStatus   string        `json:"to_status,omitempty" gorm:"type:varchar(20)"`
+	Detail     string        `json:"detail,omitempty" gorm:"type:text"`
+	FromDue    *time.Time    `json:"from_due,omitempty" gorm:"type:timestamp"`
+	ToDue      *time.Time    `json:"to_due,omitempty" gorm:"type:timestamp"`
+	OccurredAt time.Time     `json:"occurred_at" gorm:"type:timestamp;not null;index"`
+}
+
+// TableName returns the table name for the TaskEvent model
+func (TaskEvent) TableName() string {
+	return "task_events"
+}
+
+// HistoryService records task history and serves a task's timeline
+type HistoryService interface {
+	// GetHistory returns the task's history, oldest entry first
+	GetHistory(taskID common.TaskID) ([]*TaskEvent, error)
+	Ready() <-chan struct{}
+}
+
+// historyService implements the HistoryService interface
+type historyService struct {
+	eventBus   events.EventBus
+	logger     *zap.Logger
+	repository HistoryRepository
+	tasks      NudgeRepository
+	workspaces WorkspaceService
+	stats      StatsRepository
+	ready      *common.Readiness
+}
+
+// NewHistoryService creates a HistoryService that records task events into
+// repository. Tasks are looked up to check who may see a task's history; a nil
+// workspace service limits every user to their own tasks.
+func NewHistoryService(eventBus events.EventBus, logger *zap.Logger, repository HistoryRepository, tasks NudgeRepository, workspaces WorkspaceService) HistoryService {
+	return NewHistoryServiceWithStats(eventBus, logger, repository, tasks, workspaces, nil)
+}
+
+// NewHistoryServiceWithStats creates a HistoryService that caches the daily
+// rollups behind the /stats burndown in stats. A nil stats repository rolls
+// up every day on each request.
+func NewHistoryServiceWithStats(eventBus events.EventBus, logger *zap.Logger, repository HistoryRepository, tasks NudgeRepository, workspaces WorkspaceService, stats StatsRepository) HistoryService {
+	service := &historyService{
+		eventBus:   eventBus,
+		logger:     logger,
+		repository: repository,
+		tasks:      tasks,
+		workspaces: workspaces,
+		stats:      stats,
+		ready:      common.NewReadiness(),
+	}
+
+	service.setupEventSubscriptions()
+
+	return service
+}
+
+// setupEventSubscriptions sets up event subscriptions for the history service
+func (s *historyService) setupEventSubscriptions() {
+	if err := s.eventBus.Subscribe(events.TopicTaskCreated, s.handleTaskCreated); err != nil {
+		s.logger.Error("Failed to subscribe to TaskCreated events", zap.Error(err))
+	}
+
+	if err := s.eventBus.Subscribe(events.TopicTaskStatusChanged, s.handleTaskStatusChanged); err != nil {
+		s.logger.Error("Failed to subscribe to TaskStatusChanged events", zap.Error(err))
+	}
+
+	if err := s.eventBus.Subscribe(events.TopicTaskEdited, s.handleTaskEdited); err != nil {
+		s.logger.Error("Failed to subscribe to TaskEdited events", zap.Error(err))
+	}
+
+	if err := s.eventBus.Subscribe(events.TopicHabitLogged, s.handleHabitLogged); err != nil {
+		s.logger.Error("Failed to subscribe to HabitLogged events", zap.Error(err))
+	}
+
+	if err := s.eventBus.Subscribe(events.TopicHabitMissed, s.handleHabitMissed); err != nil {
+		s.logger.Error("Failed to subscribe to HabitMissed events", zap.Error(err))
+	}
+
+	if err := s.eventBus.Subscribe(events.TopicReminderDue, s.handleReminderDue); err != nil {
+		s.logger.Error("Failed to subscribe to ReminderDue events", zap.Error(err))
+	}
+
+	if err := s.eventBus.Subscribe(events.TopicTaskHistoryRequested, s.handleHistoryRequested); err != nil {
+		s.logger.Error("Failed to subscribe to TaskHistoryRequested events", zap.Error(err))
+	}
+
+	if err := s.eventBus.Subscribe(events.TopicWeeklyStatsRequested, s.handleWeeklyStatsRequested); err != nil {
+		s.logger.Error("Failed to subscribe to WeeklyStatsRequested events", zap.Error(err))
+	}
+
+	s.ready.MarkReady()
+}
+
+// Ready returns a channel that is closed once event subscriptions are registered
+func (s *historyService) Ready() <-chan struct{} {
+	return s.ready.Ready()
+}
+
+// GetHistory returns the task's history, oldest entry first
+func (s *historyService) GetHistory(taskID common.TaskID) ([]*TaskEvent, error) {
+	return s.repository.ListTaskEvents(taskID)
+}
+
+// record appends an entry to a task's history; failures are logged only
+func (s *historyService) record(entry *TaskEvent) {
+	entry.ID = common.ID(common.NewID())
+	if err := s.repository.AppendTaskEvent(entry); err != nil {
+		s.logger.Error("Failed to record task history",
+			zap.String("taskID", string(entry.TaskID)),
+			zap.String("kind", string(entry.Kind)),
+			zap.Error(err))
+	}
+}
+
+// handleTaskCreated records the creation of a task
+func (s *historyService) handleTaskCreated(event events.TaskCreated) {
+	s.record(&TaskEvent{
+		TaskID:     common.TaskID(event.TaskID),
+		Kind:       TaskEventCreated,
+		ToStatus:   string(common.TaskStatusActive),
+		Detail:     event.Title,
+		OccurredAt: event.CreatedAt,
+	})
+}
+
+// handleTaskStatusChanged records a status transition or snooze
+func (s *historyService) handleTaskStatusChanged(event events.TaskStatusChanged) {
+	entry := &TaskEvent{
+		TaskID:     common.TaskID(event.TaskID),
+		Kind:       TaskEventStatusChanged,
+		FromStatus: event.FromStatus,
+		ToStatus:   event.ToStatus,
+		OccurredAt: event.Timestamp,
+	}
+	if event.SnoozedUntil != nil {
+		entry.Kind = TaskEventSnoozed
+		entry.Detail = "until " + event.SnoozedUntil.UTC().Format(time.RFC3339)
+		entry.FromDue = event.PreviousDueDate
+		entry.ToDue = event.SnoozedUntil
+	}
+	s.record(entry)
+	s.noticeSlippage(entry)
+}
+
+// handleTaskEdited records which fields of a task were changed
+func (s *historyService) handleTaskEdited(event events.TaskEdited) {
+	entry := &TaskEvent{
+		TaskID:     common.TaskID(event.TaskID),
+		Kind:       TaskEventEdited,
+		Detail:     strings.Join(event.Fields, ", "),
+		FromDue:    event.PreviousDueDate,
+		ToDue:      event.DueDate,
+		OccurredAt: event.Timestamp,
+	}
+	s.record(entry)
+	s.noticeSlippage(entry)
+}
+
+// handleHabitLogged records a habit done for its period, with the streak it reached
+func (s *historyService) handleHabitLogged(event events.HabitLogged) {
+	s.record(&TaskEvent{
+		TaskID:     common.TaskID(event.TaskID),
+		Kind:       TaskEventHabitLogged,
+		Detail:     fmt.Sprintf("streak %d", event.Streak),
+		OccurredAt: event.Timestamp,
+	})
+}
+
+// handleHabitMissed records a habit period that ended undone
+func (s *historyService) handleHabitMissed(event events.HabitMissed) {
+	s.record(&TaskEvent{
+		TaskID:     common.TaskID(event.TaskID),
+		Kind:       TaskEventHabitMissed,
+		Detail:     fmt.Sprintf("streak of %d lost", event.LostStreak),
+		OccurredAt: event.Timestamp,
+	})
+}
+
+// handleReminderDue records a nudge sent for a task; test reminders are not nudges
+func (s *historyService) handleReminderDue(event events.ReminderDue) {
+	if event.Test {
+		return
+	}
+	s.record(&TaskEvent{
+		TaskID:     common.TaskID(event.TaskID),
+		Kind:       TaskEventNudgeSent,
+		OccurredAt: event.Timestamp,
+	})
+}
+
+// handleHistoryRequested answers a chatbot request for a task's timeline
+func (s *historyService) handleHistoryRequested(event events.TaskHistoryRequested) {
+	response := events.TaskHistoryResponse{
+		Event:  events.NewEvent(),
+		UserID: event.UserID,
+		ChatID: event.ChatID,
+		TaskID: event.TaskID,
+	}
+
+	title, history, err := s.historyFor(common.TaskID(event.TaskID), common.UserID(event.UserID))
+	if err != nil {
+		s.logger.Warn("Task history request failed",
+			zap.String("correlationID", event.CorrelationID),
+			zap.String("taskID", event.TaskID),
+			zap.Error(err))
+		response.Message = "Could not load the task history."
+		var permissionErr PermissionError
+		if errors.Is(err, ErrTaskNotFound) {
+			response.Message = "Task not found."
+		} else if errors.As(err, &permissionErr) {
+			response.Message = "Not allowed: " + permissionErr.Message()
+		}
+	} else {
+		response.Success = true
+		response.TaskTitle = title
+		response.Entries = make([]events.TaskHistoryEntry, len(history))
+		for i, entry := range history {
+			response.Entries[i] = events.TaskHistoryEntry{
+				Kind:       string(entry.Kind),
+				FromStatus: entry.FromStatus,
+				ToStatus:   entry.ToStatus,
+				Detail:     entry.Detail,
+				OccurredAt: entry.OccurredAt,
+			}
+		}
+	}
+
+	if err := s.eventBus.Publish(events.TopicTaskHistoryResponse, response); err != nil {
+		s.logger.Error("Failed to publish TaskHistoryResponse event",
+			zap.String("taskID", event.TaskID),
+			zap.Error(err))
+	}
+}
+
+// historyFor returns the task's title and history if the user may view the task
+func (s *historyService) historyFor(taskID common.TaskID, userID common.UserID) (string, []*TaskEvent, error) {
+	task, err := s.tasks.WithContext(tenant.WithUser(context.Background(), userID)).GetTaskByID(taskID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if task.UserID != userID {
+		if s.workspaces == nil || task.ChatID == "" {
+			return "", nil, fmt.Errorf("%w: task %s does not belong to user %s", ErrTaskNotFound, taskID, userID)
+		}
+		if err := s.workspaces.Authorize(task.ChatID, userID, ActionView); err != nil {
+			return "", nil, err
+		}
+	}
+
+	history, err := s.GetHistory(taskID)
+	if err != nil {
+		return "", nil, err
+	}
+	return task.Title, history, nil
+}
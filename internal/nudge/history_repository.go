@@ -0,0 +1,135 @@
+package nudge
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"nudgebot-api/internal/common"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// HistoryRepository persists task history entries
+type HistoryRepository interface {
+	AppendTaskEvent(entry *TaskEvent) error
+	// ListTaskEvents returns the task's history, oldest entry first
+	ListTaskEvents(taskID common.TaskID) ([]*TaskEvent, error)
+	// ListTaskEventsSince returns the history of the tasks from since on, oldest entry first
+	ListTaskEventsSince(taskIDs []common.TaskID, since time.Time) ([]*TaskEvent, error)
+}
+
+// gormHistoryRepository implements HistoryRepository using GORM
+type gormHistoryRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewGormHistoryRepository creates a new GORM-based history repository
+func NewGormHistoryRepository(db *gorm.DB, logger *zap.Logger) HistoryRepository {
+	return &gormHistoryRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// AppendTaskEvent stores a history entry in the tenant of its task
+func (r *gormHistoryRepository) AppendTaskEvent(entry *TaskEvent) error {
+	if entry.TenantID == "" {
+		var tenants []string
+		if err := r.db.Model(&Task{}).Where("id = ?", entry.TaskID).Limit(1).Pluck("tenant_id", &tenants).Error; err != nil {
+			return WrapRepositoryError(err, "find task tenant")
+		}
+		if len(tenants) > 0 {
+			entry.TenantID = tenants[0]
+		}
+	}
+	if err := r.db.Create(entry).Error; err != nil {
+		return WrapRepositoryError(err, "append task event")
+	}
+	return nil
+}
+
+// ListTaskEvents returns the task's history, oldest entry first
+func (r *gormHistoryRepository) ListTaskEvents(taskID common.TaskID) ([]*TaskEvent, error) {
+	var entries []*TaskEvent
+	err := r.db.Where("task_id = ?", taskID).Order("occurred_at ASC").Find(&entries).Error
+	if err != nil {
+		return nil, WrapRepositoryError(err, "list task events")
+	}
+	return entries, nil
+}
+
+// ListTaskEventsSince returns the history of the tasks from since on, oldest entry first
+func (r *gormHistoryRepository) ListTaskEventsSince(taskIDs []common.TaskID, since time.Time) ([]*TaskEvent, error) {
+	var entries []*TaskEvent
+	if len(taskIDs) == 0 {
+		return entries, nil
+	}
+	err := r.db.Where("task_id IN ? AND occurred_at >= ?", taskIDs, since).Order("occurred_at ASC").Find(&entries).Error
+	if err != nil {
+		return nil, WrapRepositoryError(err, "list task events since")
+	}
+	return entries, nil
+}
+
+// memoryHistoryRepository implements HistoryRepository in memory
+type memoryHistoryRepository struct {
+	mu      sync.RWMutex
+	entries map[common.TaskID][]*TaskEvent
+}
+
+// NewMemoryHistoryRepository creates an in-memory history repository
+func NewMemoryHistoryRepository() HistoryRepository {
+	return &memoryHistoryRepository{
+		entries: make(map[common.TaskID][]*TaskEvent),
+	}
+}
+
+// AppendTaskEvent stores a history entry
+func (r *memoryHistoryRepository) AppendTaskEvent(entry *TaskEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *entry
+	r.entries[entry.TaskID] = append(r.entries[entry.TaskID], &stored)
+	return nil
+}
+
+// ListTaskEvents returns the task's history, oldest entry first
+func (r *memoryHistoryRepository) ListTaskEvents(taskID common.TaskID) ([]*TaskEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entries := make([]*TaskEvent, 0, len(r.entries[taskID]))
+	for _, entry := range r.entries[taskID] {
+		copied := *entry
+		entries = append(entries, &copied)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].OccurredAt.Before(entries[j].OccurredAt)
+	})
+	return entries, nil
+}
+
+// ListTaskEventsSince returns the history of the tasks from since on, oldest entry first
+func (r *memoryHistoryRepository) ListTaskEventsSince(taskIDs []common.TaskID, since time.Time) ([]*TaskEvent, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var entries []*TaskEvent
+	for _, taskID := range taskIDs {
+		for _, entry := range r.entries[taskID] {
+			if entry.OccurredAt.Before(since) {
+				continue
+			}
+			copied := *entry
+			entries = append(entries, &copied)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].OccurredAt.Before(entries[j].OccurredAt)
+	})
+	return entries, nil
+}
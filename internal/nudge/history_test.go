@@ -0,0 +1,81 @@
+package nudge
+
+import (
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestHistoryService_RecordsTaskEvents(t *testing.T) {
+	bus := events.NewMockEventBus()
+	bus.SetSynchronousMode(true)
+	logger := zaptest.NewLogger(t)
+	tasks := NewMemoryNudgeRepository(logger)
+	history := NewHistoryService(bus, logger, NewMemoryHistoryRepository(), tasks, nil)
+
+	userID := common.UserID(common.NewID())
+	task := &Task{
+		ID:       common.TaskID(common.NewID()),
+		UserID:   userID,
+		ChatID:   common.ChatID(userID),
+		Title:    "Write report",
+		Priority: common.PriorityMedium,
+		Status:   common.TaskStatusActive,
+	}
+	require.NoError(t, tasks.CreateTask(task))
+
+	createdAt := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	snoozedUntil := createdAt.Add(2 * time.Hour)
+	snoozed := events.NewEvent()
+	snoozed.Timestamp = createdAt.Add(time.Hour)
+	nudged := events.NewEvent()
+	nudged.Timestamp = createdAt.Add(3 * time.Hour)
+
+	// Published out of order; the timeline is chronological
+	require.NoError(t, bus.Publish(events.TopicReminderDue, events.ReminderDue{Event: nudged, TaskID: string(task.ID)}))
+	require.NoError(t, bus.Publish(events.TopicReminderDue, events.ReminderDue{Event: nudged, TaskID: string(task.ID), Test: true}))
+	require.NoError(t, bus.Publish(events.TopicTaskCreated, events.TaskCreated{Event: events.NewEvent(), TaskID: string(task.ID), Title: task.Title, CreatedAt: createdAt}))
+	require.NoError(t, bus.Publish(events.TopicTaskStatusChanged, events.TaskStatusChanged{
+		Event: snoozed, TaskID: string(task.ID), FromStatus: "active", ToStatus: "snoozed", SnoozedUntil: &snoozedUntil,
+	}))
+
+	entries, err := history.GetHistory(task.ID)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, TaskEventCreated, entries[0].Kind)
+	assert.Equal(t, TaskEventSnoozed, entries[1].Kind)
+	assert.Equal(t, "until 2024-01-01T11:00:00Z", entries[1].Detail)
+	assert.Equal(t, TaskEventNudgeSent, entries[2].Kind)
+
+	t.Run("owner receives the timeline", func(t *testing.T) {
+		require.NoError(t, bus.Publish(events.TopicTaskHistoryRequested, events.TaskHistoryRequested{
+			Event: events.NewEvent(), UserID: string(userID), ChatID: string(task.ChatID), TaskID: string(task.ID),
+		}))
+
+		responses := bus.GetPublishedEvents(events.TopicTaskHistoryResponse)
+		require.Len(t, responses, 1)
+		response := responses[0].(events.TaskHistoryResponse)
+		assert.True(t, response.Success)
+		assert.Equal(t, "Write report", response.TaskTitle)
+		assert.Len(t, response.Entries, 3)
+	})
+
+	t.Run("other users are refused", func(t *testing.T) {
+		bus.ClearEvents()
+		require.NoError(t, bus.Publish(events.TopicTaskHistoryRequested, events.TaskHistoryRequested{
+			Event: events.NewEvent(), UserID: string(common.NewID()), ChatID: "other", TaskID: string(task.ID),
+		}))
+
+		responses := bus.GetPublishedEvents(events.TopicTaskHistoryResponse)
+		require.Len(t, responses, 1)
+		response := responses[0].(events.TaskHistoryResponse)
+		assert.False(t, response.Success)
+		assert.Empty(t, response.Entries)
+	})
+}
@@ -0,0 +1,214 @@
+package nudge
+
+import (
+	"context"
+	"time"
+
+	"nudgebot-api/internal/common"
+
+	"go.uber.org/zap"
+)
+
+// instrumentedNudgeRepository decorates a NudgeRepository with latency metrics
+// and slow query logging
+type instrumentedNudgeRepository struct {
+	next          NudgeRepository
+	metrics       *RepositoryMetrics
+	slowThreshold time.Duration
+	logger        *zap.Logger
+}
+
+// NewInstrumentedNudgeRepository wraps a repository so every call is timed.
+// Calls slower than slowThreshold are logged with their arguments; a zero
+// threshold disables slow query logging.
+func NewInstrumentedNudgeRepository(next NudgeRepository, metrics *RepositoryMetrics, slowThreshold time.Duration, logger *zap.Logger) NudgeRepository {
+	return &instrumentedNudgeRepository{
+		next:          next,
+		metrics:       metrics,
+		slowThreshold: slowThreshold,
+		logger:        logger,
+	}
+}
+
+// observe records the call and logs it when it exceeded the slow threshold
+func (r *instrumentedNudgeRepository) observe(method string, start time.Time, err error, fields ...zap.Field) {
+	duration := time.Since(start)
+	slow := r.slowThreshold > 0 && duration >= r.slowThreshold
+
+	r.metrics.Observe(method, duration, err != nil, slow)
+
+	if slow {
+		fields = append(fields,
+			zap.String("method", method),
+			zap.Duration("duration", duration),
+			zap.Duration("threshold", r.slowThreshold))
+		if err != nil {
+			fields = append(fields, zap.Error(err))
+		}
+		r.logger.Warn("Slow repository query", fields...)
+	}
+}
+
+// Task operations
+
+func (r *instrumentedNudgeRepository) CreateTask(task *Task) (err error) {
+	defer func(start time.Time) {
+		r.observe("CreateTask", start, err, zap.String("taskID", string(task.ID)), zap.String("userID", string(task.UserID)))
+	}(time.Now())
+	return r.next.CreateTask(task)
+}
+
+func (r *instrumentedNudgeRepository) GetTaskByID(taskID common.TaskID) (task *Task, err error) {
+	defer func(start time.Time) {
+		r.observe("GetTaskByID", start, err, zap.String("taskID", string(taskID)))
+	}(time.Now())
+	return r.next.GetTaskByID(taskID)
+}
+
+func (r *instrumentedNudgeRepository) GetTasksByUserID(userID common.UserID, filter TaskFilter) (tasks []*Task, err error) {
+	defer func(start time.Time) {
+		r.observe("GetTasksByUserID", start, err, zap.String("userID", string(userID)), zap.Any("filter", filter))
+	}(time.Now())
+	return r.next.GetTasksByUserID(userID, filter)
+}
+
+func (r *instrumentedNudgeRepository) UpdateTask(task *Task) (err error) {
+	defer func(start time.Time) {
+		r.observe("UpdateTask", start, err, zap.String("taskID", string(task.ID)))
+	}(time.Now())
+	return r.next.UpdateTask(task)
+}
+
+func (r *instrumentedNudgeRepository) DeleteTask(taskID common.TaskID) (err error) {
+	defer func(start time.Time) {
+		r.observe("DeleteTask", start, err, zap.String("taskID", string(taskID)))
+	}(time.Now())
+	return r.next.DeleteTask(taskID)
+}
+
+func (r *instrumentedNudgeRepository) GetTaskStats(userID common.UserID) (stats *TaskStats, err error) {
+	defer func(start time.Time) {
+		r.observe("GetTaskStats", start, err, zap.String("userID", string(userID)))
+	}(time.Now())
+	return r.next.GetTaskStats(userID)
+}
+
+func (r *instrumentedNudgeRepository) GetOverdueTasks(userID common.UserID) (tasks []*Task, err error) {
+	defer func(start time.Time) {
+		r.observe("GetOverdueTasks", start, err, zap.String("userID", string(userID)))
+	}(time.Now())
+	return r.next.GetOverdueTasks(userID)
+}
+
+func (r *instrumentedNudgeRepository) BulkUpdateTaskStatus(taskIDs []common.TaskID, status common.TaskStatus) (err error) {
+	defer func(start time.Time) {
+		r.observe("BulkUpdateTaskStatus", start, err, zap.Int("count", len(taskIDs)), zap.String("status", string(status)))
+	}(time.Now())
+	return r.next.BulkUpdateTaskStatus(taskIDs, status)
+}
+
+func (r *instrumentedNudgeRepository) BulkShiftDueDates(taskIDs []common.TaskID, shift time.Duration) (err error) {
+	defer func(start time.Time) {
+		r.observe("BulkShiftDueDates", start, err, zap.Int("count", len(taskIDs)), zap.Duration("shift", shift))
+	}(time.Now())
+	return r.next.BulkShiftDueDates(taskIDs, shift)
+}
+
+func (r *instrumentedNudgeRepository) MoveChatID(from, to common.ChatID) (err error) {
+	defer func(start time.Time) {
+		r.observe("MoveChatID", start, err, zap.String("from", string(from)), zap.String("to", string(to)))
+	}(time.Now())
+	return r.next.MoveChatID(from, to)
+}
+
+func (r *instrumentedNudgeRepository) GetNewlyOverdueTasks(now time.Time, limit int) (tasks []*Task, err error) {
+	defer func(start time.Time) {
+		r.observe("GetNewlyOverdueTasks", start, err, zap.Int("limit", limit))
+	}(time.Now())
+	return r.next.GetNewlyOverdueTasks(now, limit)
+}
+
+func (r *instrumentedNudgeRepository) MarkTaskOverdue(taskID common.TaskID, dueDate time.Time) (err error) {
+	defer func(start time.Time) {
+		r.observe("MarkTaskOverdue", start, err, zap.String("taskID", string(taskID)))
+	}(time.Now())
+	return r.next.MarkTaskOverdue(taskID, dueDate)
+}
+
+// Reminder operations
+
+func (r *instrumentedNudgeRepository) CreateReminder(reminder *Reminder) (err error) {
+	defer func(start time.Time) {
+		r.observe("CreateReminder", start, err, zap.String("reminderID", string(reminder.ID)), zap.String("taskID", string(reminder.TaskID)))
+	}(time.Now())
+	return r.next.CreateReminder(reminder)
+}
+
+func (r *instrumentedNudgeRepository) GetDueReminders(before time.Time) (reminders []*Reminder, err error) {
+	defer func(start time.Time) {
+		r.observe("GetDueReminders", start, err, zap.Time("before", before))
+	}(time.Now())
+	return r.next.GetDueReminders(before)
+}
+
+func (r *instrumentedNudgeRepository) MarkReminderSent(reminderID common.ID) (err error) {
+	defer func(start time.Time) {
+		r.observe("MarkReminderSent", start, err, zap.String("reminderID", string(reminderID)))
+	}(time.Now())
+	return r.next.MarkReminderSent(reminderID)
+}
+
+func (r *instrumentedNudgeRepository) GetRemindersByTaskID(taskID common.TaskID) (reminders []*Reminder, err error) {
+	defer func(start time.Time) {
+		r.observe("GetRemindersByTaskID", start, err, zap.String("taskID", string(taskID)))
+	}(time.Now())
+	return r.next.GetRemindersByTaskID(taskID)
+}
+
+func (r *instrumentedNudgeRepository) DeleteReminder(reminderID common.ID) (err error) {
+	defer func(start time.Time) {
+		r.observe("DeleteReminder", start, err, zap.String("reminderID", string(reminderID)))
+	}(time.Now())
+	return r.next.DeleteReminder(reminderID)
+}
+
+// Nudge settings operations
+
+func (r *instrumentedNudgeRepository) GetNudgeSettingsByUserID(userID common.UserID) (settings *NudgeSettings, err error) {
+	defer func(start time.Time) {
+		r.observe("GetNudgeSettingsByUserID", start, err, zap.String("userID", string(userID)))
+	}(time.Now())
+	return r.next.GetNudgeSettingsByUserID(userID)
+}
+
+func (r *instrumentedNudgeRepository) CreateOrUpdateNudgeSettings(settings *NudgeSettings) (err error) {
+	defer func(start time.Time) {
+		r.observe("CreateOrUpdateNudgeSettings", start, err, zap.String("userID", string(settings.UserID)))
+	}(time.Now())
+	return r.next.CreateOrUpdateNudgeSettings(settings)
+}
+
+func (r *instrumentedNudgeRepository) DeleteNudgeSettings(userID common.UserID) (err error) {
+	defer func(start time.Time) {
+		r.observe("DeleteNudgeSettings", start, err, zap.String("userID", string(userID)))
+	}(time.Now())
+	return r.next.DeleteNudgeSettings(userID)
+}
+
+// Transaction support
+
+// WithContext instruments the calls made with ctx
+func (r *instrumentedNudgeRepository) WithContext(ctx context.Context) NudgeRepository {
+	return NewInstrumentedNudgeRepository(r.next.WithContext(ctx), r.metrics, r.slowThreshold, r.logger)
+}
+
+// WithTransaction times the whole transaction and instruments the calls made inside it
+func (r *instrumentedNudgeRepository) WithTransaction(fn func(NudgeRepository) error) (err error) {
+	defer func(start time.Time) {
+		r.observe("WithTransaction", start, err)
+	}(time.Now())
+
+	return r.next.WithTransaction(func(tx NudgeRepository) error {
+		return fn(NewInstrumentedNudgeRepository(tx, r.metrics, r.slowThreshold, r.logger))
+	})
+}
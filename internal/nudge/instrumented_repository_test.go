@@ -0,0 +1,77 @@
+package nudge
+
+import (
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestInstrumentedNudgeRepository(t *testing.T) {
+	core, logs := observer.New(zap.WarnLevel)
+	logger := zap.New(core)
+	metrics := NewRepositoryMetrics()
+
+	repo := NewInstrumentedNudgeRepository(NewMemoryNudgeRepository(zap.NewNop()), metrics, time.Nanosecond, logger)
+
+	userID := common.UserID(common.NewID())
+	task := &Task{
+		ID:       common.TaskID(common.NewID()),
+		UserID:   userID,
+		Title:    "Write report",
+		Priority: common.PriorityHigh,
+		Status:   common.TaskStatusActive,
+	}
+	require.NoError(t, repo.CreateTask(task))
+
+	_, err := repo.GetTaskByID("missing")
+	require.Error(t, err)
+
+	_, err = repo.GetTasksByUserID(userID, TaskFilter{UserID: userID, Limit: 10})
+	require.NoError(t, err)
+
+	err = repo.WithTransaction(func(tx NudgeRepository) error {
+		_, err := tx.GetTaskStats(userID)
+		return err
+	})
+	require.NoError(t, err)
+
+	byMethod := make(map[string]MethodMetricsSummary)
+	for _, summary := range metrics.Snapshot() {
+		byMethod[summary.Method] = summary
+	}
+
+	assert.Equal(t, int64(1), byMethod["CreateTask"].Count)
+	assert.Equal(t, int64(1), byMethod["GetTaskByID"].Errors)
+	assert.Equal(t, int64(1), byMethod["GetTaskStats"].Count, "calls inside a transaction are instrumented")
+	assert.Equal(t, int64(1), byMethod["WithTransaction"].Count)
+
+	buckets := byMethod["CreateTask"].Buckets
+	require.NotEmpty(t, buckets)
+	assert.Equal(t, "+Inf", buckets[len(buckets)-1].UpperBound)
+	assert.Equal(t, int64(1), buckets[len(buckets)-1].Count)
+
+	// Every call exceeds a 1ns threshold, so slow queries are logged with their filters
+	slowFilterLogs := logs.FilterMessage("Slow repository query").FilterField(zap.String("method", "GetTasksByUserID"))
+	require.Equal(t, 1, slowFilterLogs.Len())
+	assert.Contains(t, slowFilterLogs.All()[0].ContextMap(), "filter")
+}
+
+func TestInstrumentedNudgeRepository_NoSlowLogWhenDisabled(t *testing.T) {
+	core, logs := observer.New(zap.WarnLevel)
+	metrics := NewRepositoryMetrics()
+
+	repo := NewInstrumentedNudgeRepository(NewMemoryNudgeRepository(zap.NewNop()), metrics, 0, zap.New(core))
+
+	_, err := repo.GetOverdueTasks(common.UserID(common.NewID()))
+	require.NoError(t, err)
+
+	assert.Zero(t, logs.Len())
+	require.Len(t, metrics.Snapshot(), 1)
+	assert.Zero(t, metrics.Snapshot()[0].SlowQueries)
+}
@@ -0,0 +1,145 @@
+package nudge
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"nudgebot-api/internal/common"
+)
+
+// MaxTaskLinks caps the links stored on one task
+const MaxTaskLinks = 10
+
+// linkPattern finds http(s) URLs in free text
+var linkPattern = regexp.MustCompile(`https?://[^\s<>"']+`)
+
+// trackingParams are query parameters dropped when normalizing a link, so the
+// same article shared from different places dedupes
+var trackingParams = []string{"utm_source", "utm_medium", "utm_campaign", "utm_term", "utm_content", "fbclid", "gclid"}
+
+// TaskLink is a URL referenced by a task
+type TaskLink struct {
+	URL  string `json:"url"` // normalized, see NormalizeLink
+	Host string `json:"host"`
+}
+
+// TaskLinks is the list of links on a task; stored as JSONB
+type TaskLinks []TaskLink
+
+// NormalizeLink returns the canonical form of a URL used for storage and
+// dedupe: lowercase scheme and host, no fragment, tracking parameters or
+// trailing slash. It reports false for anything that is not an http(s) URL.
+func NormalizeLink(raw string) (TaskLink, bool) {
+	parsed, err := url.Parse(strings.TrimRight(raw, ".,;:!?)]}"))
+	if err != nil || parsed.Host == "" {
+		return TaskLink{}, false
+	}
+
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return TaskLink{}, false
+	}
+	parsed.Host = strings.ToLower(parsed.Host)
+	parsed.Fragment = ""
+	parsed.User = nil
+
+	query := parsed.Query()
+	for _, param := range trackingParams {
+		query.Del(param)
+	}
+	parsed.RawQuery = query.Encode()
+	parsed.Path = strings.TrimSuffix(parsed.Path, "/")
+	parsed.RawPath = ""
+
+	return TaskLink{URL: parsed.String(), Host: strings.TrimPrefix(parsed.Hostname(), "www.")}, true
+}
+
+// ExtractLinks returns the distinct normalized links found in text, in order of
+// appearance, along with the text with those links removed
+func ExtractLinks(text string) (TaskLinks, string) {
+	var links TaskLinks
+	seen := make(map[string]bool)
+	for _, raw := range linkPattern.FindAllString(text, -1) {
+		link, ok := NormalizeLink(raw)
+		if !ok || seen[link.URL] || len(links) == MaxTaskLinks {
+			continue
+		}
+		seen[link.URL] = true
+		links = append(links, link)
+	}
+
+	stripped := strings.Join(strings.Fields(linkPattern.ReplaceAllString(text, "")), " ")
+	return links, strings.TrimRight(stripped, " :-–—")
+}
+
+// attachLinks moves URLs found in a task's title and description into its
+// links. The title keeps the link when it is nothing but the link.
+func attachLinks(task *Task) {
+	if len(task.Links) > 0 {
+		return
+	}
+
+	titleLinks, title := ExtractLinks(task.Title)
+	descriptionLinks, _ := ExtractLinks(task.Description)
+	links, _ := ExtractLinks(strings.Join(append(titleLinks, descriptionLinks...).URLs(), " "))
+	if len(links) == 0 {
+		return
+	}
+
+	task.Links = links
+	if len(titleLinks) > 0 && len(title) >= MinTaskTitleLength {
+		task.Title = title
+	}
+}
+
+// duplicateLinkInBatch reports a task whose link was already used by an earlier
+// task of the same user in a bulk create; seen maps user and URL to a task index
+func duplicateLinkInBatch(task *Task, index int, seen map[string]int) (TaskCreateFailure, bool) {
+	for _, link := range task.Links {
+		linkKey := string(task.UserID) + ":" + link.URL
+		if first, exists := seen[linkKey]; exists {
+			return TaskCreateFailure{
+				Index: index,
+				Title: task.Title,
+				Err:   NewTaskValidationError("links", link.URL, fmt.Sprintf("duplicates the link of task %d in the same batch", first)),
+			}, true
+		}
+	}
+	for _, link := range task.Links {
+		seen[string(task.UserID)+":"+link.URL] = index
+	}
+	return TaskCreateFailure{}, false
+}
+
+// URLs returns the links' normalized URLs
+func (l TaskLinks) URLs() []string {
+	urls := make([]string, len(l))
+	for i, link := range l {
+		urls[i] = link.URL
+	}
+	return urls
+}
+
+// Shares returns a URL the two link lists have in common, if any
+func (l TaskLinks) Shares(other TaskLinks) (string, bool) {
+	for _, link := range l {
+		for _, otherLink := range other {
+			if link.URL == otherLink.URL {
+				return link.URL, true
+			}
+		}
+	}
+	return "", false
+}
+
+// dedupeStatuses are the statuses of tasks that new tasks must not duplicate
+func dedupeStatuses() []common.TaskStatus {
+	return append(common.OpenTaskStatuses(), common.TaskStatusSnoozed)
+}
+
+// isDedupeStatus reports whether new tasks must not duplicate a task in this status
+func isDedupeStatus(status common.TaskStatus) bool {
+	return status.IsOpen() || status == common.TaskStatusSnoozed
+}
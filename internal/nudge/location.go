@@ -0,0 +1,111 @@
+package nudge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/geocode"
+
+	"go.uber.org/zap"
+)
+
+// geocodeTimeout bounds a lookup of a task's location, including the wait
+// for the geocoder's rate limit
+const geocodeTimeout = 30 * time.Second
+
+// TaskLocation is a place a task mentions, such as "post office". Address
+// and the coordinates are filled in once the place is geocoded.
+type TaskLocation struct {
+	Name      string   `json:"name"`
+	Address   string   `json:"address,omitempty"`
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+}
+
+// NewTaskLocation returns the location named name, nil when name is blank
+func NewTaskLocation(name string) *TaskLocation {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil
+	}
+	return &TaskLocation{Name: name}
+}
+
+// Geocoded reports whether the location's coordinates are known
+func (l *TaskLocation) Geocoded() bool {
+	return l != nil && l.Latitude != nil && l.Longitude != nil
+}
+
+// MapURL links to the location on OpenStreetMap, empty until it is geocoded
+func (l *TaskLocation) MapURL() string {
+	if !l.Geocoded() {
+		return ""
+	}
+	return fmt.Sprintf("https://www.openstreetmap.org/?mlat=%.6f&mlon=%.6f#map=17/%.6f/%.6f",
+		*l.Latitude, *l.Longitude, *l.Latitude, *l.Longitude)
+}
+
+// summarize copies the location into a task summary
+func (l *TaskLocation) summarize(summary *events.TaskSummary) {
+	if l == nil {
+		return
+	}
+	summary.Location = l.Name
+	summary.Address = l.Address
+	summary.MapURL = l.MapURL()
+}
+
+// geocodeTask looks up the place the task mentions and stores its address
+// and coordinates. Lookups run in the background after the task is created,
+// so a slow or unavailable geocoder never holds up task creation.
+func (s *nudgeService) geocodeTask(task *Task) {
+	if s.geocoder == nil || s.repository == nil || task.Location == nil || task.Location.Geocoded() {
+		return
+	}
+	log := s.logger.With(zap.String("taskID", string(task.ID)))
+	name := task.Location.Name
+	s = s.forTask(task)
+
+	// Search the user's country first, when they told us where they live
+	var country string
+	if settings, err := s.repository.GetNudgeSettingsByUserID(task.UserID); err == nil && settings != nil {
+		country = settings.Country
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), geocodeTimeout)
+	defer cancel()
+	place, err := s.geocoder.Geocode(ctx, name, country)
+	switch {
+	case errors.Is(err, geocode.ErrNotFound):
+		log.Debug("Task location not found", zap.String("location", name))
+		return
+	case err != nil:
+		log.Warn("Failed to geocode task location", zap.String("location", name), zap.Error(err))
+		return
+	}
+
+	// Reload the task: it may have changed while the lookup ran
+	current, err := s.repository.GetTaskByID(task.ID)
+	if err != nil {
+		log.Warn("Failed to reload task after geocoding", zap.Error(err))
+		return
+	}
+	if current.Location == nil || current.Location.Name != name {
+		return
+	}
+	current.Location = &TaskLocation{
+		Name:      name,
+		Address:   place.Address,
+		Latitude:  &place.Latitude,
+		Longitude: &place.Longitude,
+	}
+	if err := s.repository.UpdateTask(current); err != nil {
+		log.Warn("Failed to store task location", zap.Error(err))
+		return
+	}
+	log.Debug("Task location geocoded", zap.String("location", name))
+}
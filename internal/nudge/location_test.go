@@ -0,0 +1,90 @@
+package nudge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/geocode"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// stubGeocoder knows a fixed set of places and records the countries searched
+type stubGeocoder struct {
+	places    map[string]geocode.Place
+	countries []string
+}
+
+func (g *stubGeocoder) Geocode(ctx context.Context, query, country string) (geocode.Place, error) {
+	g.countries = append(g.countries, country)
+	place, ok := g.places[query]
+	if !ok {
+		return geocode.Place{}, geocode.ErrNotFound
+	}
+	return place, nil
+}
+
+func TestTaskLocation_MapURL(t *testing.T) {
+	assert.Nil(t, NewTaskLocation("  "))
+
+	location := NewTaskLocation(" post office ")
+	assert.Equal(t, "post office", location.Name)
+	assert.False(t, location.Geocoded())
+	assert.Empty(t, location.MapURL())
+
+	latitude, longitude := 52.52, 13.388
+	location.Latitude, location.Longitude = &latitude, &longitude
+	assert.Equal(t, "https://www.openstreetmap.org/?mlat=52.520000&mlon=13.388000#map=17/52.520000/13.388000", location.MapURL())
+}
+
+func TestNudgeService_GeocodesTaskLocations(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	eventBus := events.NewMockEventBus()
+	repo := NewMemoryNudgeRepository(logger)
+	geocoder := &stubGeocoder{places: map[string]geocode.Place{
+		"post office": {Address: "Deutsche Post, Friedrichstraße 1, Berlin", Latitude: 52.52, Longitude: 13.388},
+	}}
+	service, err := NewNudgeServiceWithGeocoder(eventBus, logger, repo, nil, nil, nil, nil, DefaultConflictTolerance, geocoder)
+	require.NoError(t, err)
+
+	userID := common.UserID(common.NewID())
+	require.NoError(t, repo.CreateOrUpdateNudgeSettings(&NudgeSettings{
+		UserID:        userID,
+		NudgeInterval: DefaultNudgeInterval,
+		MaxNudges:     DefaultMaxNudges,
+		Country:       "DE",
+	}))
+
+	found := bulkTask(userID, "Pick up package at the post office")
+	found.ID = common.TaskID(common.NewID())
+	found.Location = NewTaskLocation("post office")
+	require.NoError(t, service.CreateTask(found))
+
+	missing := bulkTask(userID, "Meet Anna at the old bakery")
+	missing.ID = common.TaskID(common.NewID())
+	missing.Location = NewTaskLocation("old bakery")
+	require.NoError(t, service.CreateTask(missing))
+
+	// Lookups run in the background and finish before Stop returns
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, service.Stop(ctx))
+
+	stored, err := repo.GetTaskByID(found.ID)
+	require.NoError(t, err)
+	require.True(t, stored.Location.Geocoded())
+	assert.Equal(t, "Deutsche Post, Friedrichstraße 1, Berlin", stored.Location.Address)
+	assert.Equal(t, 52.52, *stored.Location.Latitude)
+	assert.False(t, found.Location.Geocoded(), "the caller's task is not changed from the background")
+
+	stored, err = repo.GetTaskByID(missing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &TaskLocation{Name: "old bakery"}, stored.Location, "places that aren't found keep their name")
+
+	assert.Equal(t, []string{"DE", "DE"}, geocoder.countries)
+}
@@ -0,0 +1,595 @@
+package nudge
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"nudgebot-api/internal/common"
+
+	"go.uber.org/zap"
+)
+
+// memoryData holds the records of an in-memory repository. Records are stored
+// by value so callers never share state with the store.
+type memoryData struct {
+	tasks     map[common.TaskID]Task
+	reminders map[common.ID]Reminder
+	settings  map[common.UserID]NudgeSettings
+}
+
+// newMemoryData creates an empty data set
+func newMemoryData() *memoryData {
+	return &memoryData{
+		tasks:     make(map[common.TaskID]Task),
+		reminders: make(map[common.ID]Reminder),
+		settings:  make(map[common.UserID]NudgeSettings),
+	}
+}
+
+// clone copies the data set for use inside a transaction
+func (d *memoryData) clone() *memoryData {
+	cloned := newMemoryData()
+	for id, task := range d.tasks {
+		cloned.tasks[id] = task
+	}
+	for id, reminder := range d.reminders {
+		cloned.reminders[id] = reminder
+	}
+	for id, settings := range d.settings {
+		cloned.settings[id] = settings
+	}
+	return cloned
+}
+
+// memoryNudgeRepository implements the NudgeRepository interface in memory.
+// It follows the same validation and error contract as the GORM repository,
+// which makes it suitable for local development and for exercising the
+// repository contract without a database.
+type memoryNudgeRepository struct {
+	mutex  sync.RWMutex
+	data   *memoryData
+	logger *zap.Logger
+}
+
+// NewMemoryNudgeRepository creates a new in-memory nudge repository
+func NewMemoryNudgeRepository(logger *zap.Logger) NudgeRepository {
+	return &memoryNudgeRepository{
+		data:   newMemoryData(),
+		logger: logger,
+	}
+}
+
+// Task operations
+
+// CreateTask stores a new task
+func (r *memoryNudgeRepository) CreateTask(task *Task) error {
+	r.logger.Debug("Creating task", zap.String("taskID", string(task.ID)), zap.String("userID", string(task.UserID)))
+
+	validator := NewTaskValidator()
+	if err := validator.ValidateTask(task); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.data.tasks[task.ID]; exists {
+		return ErrDuplicateTask
+	}
+
+	// Check for duplicate tasks (same user, title or link, and open status)
+	for _, existing := range r.data.tasks {
+		if existing.UserID != task.UserID || !isDedupeStatus(existing.Status) {
+			continue
+		}
+		if existing.Title == task.Title {
+			return NewTaskValidationError("title", task.Title, "task with this title already exists for user")
+		}
+		if link, shared := existing.Links.Shares(task.Links); shared {
+			return NewTaskValidationError("links", link, "task with this link already exists for user")
+		}
+	}
+
+	now := time.Now()
+	task.CreatedAt = now
+	task.UpdatedAt = now
+	if task.Code == 0 {
+		task.Code = r.nextTaskCode(task.UserID)
+	}
+
+	r.data.tasks[task.ID] = *task
+	return nil
+}
+
+// nextTaskCode returns the code after the user's highest; callers hold the mutex
+func (r *memoryNudgeRepository) nextTaskCode(userID common.UserID) int {
+	highest := 0
+	for _, task := range r.data.tasks {
+		if task.UserID == userID && task.Code > highest {
+			highest = task.Code
+		}
+	}
+	return highest + 1
+}
+
+// GetTaskByID retrieves a task by its ID
+func (r *memoryNudgeRepository) GetTaskByID(taskID common.TaskID) (*Task, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	task, exists := r.data.tasks[taskID]
+	if !exists {
+		return nil, common.NotFoundError{Resource: "Task", ID: string(taskID)}
+	}
+
+	return &task, nil
+}
+
+// GetTasksByUserID retrieves tasks for a user with filtering, ordered by
+// priority and then due date like the GORM repository
+func (r *memoryNudgeRepository) GetTasksByUserID(userID common.UserID, filter TaskFilter) ([]*Task, error) {
+	validator := NewTaskValidator()
+	if err := validator.ValidateTaskFilter(filter); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	tasks := r.selectTasks(func(task Task) bool {
+		if !filter.MatchesOwner(task, userID) {
+			return false
+		}
+		if !filter.MatchesStatus(task.Status) || !task.CustomFields.Matches(filter.CustomFields) {
+			return false
+		}
+		if filter.Priority != nil && task.Priority != *filter.Priority {
+			return false
+		}
+		if filter.Code > 0 && task.Code != filter.Code {
+			return false
+		}
+		if filter.DueAfter != nil && (task.DueDate == nil || task.DueDate.Before(*filter.DueAfter)) {
+			return false
+		}
+		if filter.DueBefore != nil && (task.DueDate == nil || task.DueDate.After(*filter.DueBefore)) {
+			return false
+		}
+		return true
+	})
+	r.mutex.RUnlock()
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		wi, wj := GetTaskPriorityWeight(tasks[i].Priority), GetTaskPriorityWeight(tasks[j].Priority)
+		if wi != wj {
+			return wi > wj
+		}
+		return dueBefore(tasks[i], tasks[j])
+	})
+
+	if filter.Limit > 0 || filter.Offset > 0 {
+		limit := filter.Limit
+		if limit == 0 {
+			limit = 50 // Default limit
+		}
+		if filter.Offset >= len(tasks) {
+			return []*Task{}, nil
+		}
+		tasks = tasks[filter.Offset:]
+		if limit < len(tasks) {
+			tasks = tasks[:limit]
+		}
+	}
+
+	return tasks, nil
+}
+
+// UpdateTask updates an existing task
+func (r *memoryNudgeRepository) UpdateTask(task *Task) error {
+	validator := NewTaskValidator()
+	if err := validator.ValidateTask(task); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	existing, exists := r.data.tasks[task.ID]
+	if !exists {
+		return common.NotFoundError{Resource: "Task", ID: string(task.ID)}
+	}
+
+	task.CreatedAt = existing.CreatedAt
+	task.UpdatedAt = time.Now()
+	r.data.tasks[task.ID] = *task
+	return nil
+}
+
+// DeleteTask performs soft delete on a task
+func (r *memoryNudgeRepository) DeleteTask(taskID common.TaskID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	task, exists := r.data.tasks[taskID]
+	if !exists {
+		return common.NotFoundError{Resource: "Task", ID: string(taskID)}
+	}
+
+	task.Status = common.TaskStatusDeleted
+	task.UpdatedAt = time.Now()
+	r.data.tasks[taskID] = task
+	return nil
+}
+
+// GetTaskStats retrieves task statistics for a user
+func (r *memoryNudgeRepository) GetTaskStats(userID common.UserID) (*TaskStats, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	stats := NewTaskStats()
+	now := time.Now()
+	for _, task := range r.data.tasks {
+		if task.UserID != userID || task.Status == common.TaskStatusDeleted {
+			continue
+		}
+
+		switch task.Status {
+		case common.TaskStatusCompleted:
+			stats.CompletedTasks++
+		case common.TaskStatusActive:
+			stats.ActiveTasks++
+		}
+		if task.Status.IsOpen() {
+			if task.DueDate != nil && task.DueDate.Before(now) {
+				stats.OverdueTasks++
+			}
+			stats.countOpen(&task, now)
+		}
+		stats.ByStatus[task.Status]++
+		stats.TotalTasks++
+	}
+
+	return stats, nil
+}
+
+// GetOverdueTasks retrieves active tasks past their due date, earliest first
+func (r *memoryNudgeRepository) GetOverdueTasks(userID common.UserID) ([]*Task, error) {
+	now := time.Now()
+
+	r.mutex.RLock()
+	tasks := r.selectTasks(func(task Task) bool {
+		return task.UserID == userID && task.Status.IsOpen() &&
+			task.DueDate != nil && task.DueDate.Before(now)
+	})
+	r.mutex.RUnlock()
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return dueBefore(tasks[i], tasks[j])
+	})
+
+	return tasks, nil
+}
+
+// BulkUpdateTaskStatus updates multiple tasks' status. Unknown IDs are ignored,
+// matching the single UPDATE issued by the GORM repository.
+func (r *memoryNudgeRepository) BulkUpdateTaskStatus(taskIDs []common.TaskID, status common.TaskStatus) error {
+	if len(taskIDs) == 0 {
+		return nil
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	for _, taskID := range taskIDs {
+		task, exists := r.data.tasks[taskID]
+		if !exists {
+			continue
+		}
+
+		task.Status = status
+		task.UpdatedAt = now
+		if status == common.TaskStatusCompleted {
+			completedAt := now
+			task.CompletedAt = &completedAt
+		}
+		r.data.tasks[taskID] = task
+	}
+
+	return nil
+}
+
+// MoveChatID readdresses the tasks and reminders of a chat to another chat ID
+func (r *memoryNudgeRepository) MoveChatID(from, to common.ChatID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	for id, task := range r.data.tasks {
+		if task.ChatID == from {
+			task.ChatID = to
+			task.UpdatedAt = now
+			r.data.tasks[id] = task
+		}
+	}
+	for id, reminder := range r.data.reminders {
+		if reminder.ChatID == from {
+			reminder.ChatID = to
+			r.data.reminders[id] = reminder
+		}
+	}
+
+	return nil
+}
+
+// BulkShiftDueDates moves the due dates of several tasks and the scheduled times
+// of their unsent reminders. Unknown IDs and undated tasks are skipped.
+func (r *memoryNudgeRepository) BulkShiftDueDates(taskIDs []common.TaskID, shift time.Duration) error {
+	if len(taskIDs) == 0 {
+		return nil
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	shifted := make(map[common.TaskID]bool, len(taskIDs))
+	for _, taskID := range taskIDs {
+		shifted[taskID] = true
+		task, exists := r.data.tasks[taskID]
+		if !exists || task.DueDate == nil {
+			continue
+		}
+
+		due := task.DueDate.Add(shift)
+		task.DueDate = &due
+		task.UpdatedAt = now
+		r.data.tasks[taskID] = task
+	}
+
+	for id, reminder := range r.data.reminders {
+		if shifted[reminder.TaskID] && reminder.SentAt == nil {
+			reminder.ScheduledAt = reminder.ScheduledAt.Add(shift)
+			r.data.reminders[id] = reminder
+		}
+	}
+
+	return nil
+}
+
+// GetNewlyOverdueTasks returns up to limit tasks that passed their due date
+// without being marked overdue for it, earliest due date first
+func (r *memoryNudgeRepository) GetNewlyOverdueTasks(now time.Time, limit int) ([]*Task, error) {
+	r.mutex.RLock()
+	tasks := r.selectTasks(func(task Task) bool {
+		return task.NewlyOverdue(now)
+	})
+	r.mutex.RUnlock()
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return dueBefore(tasks[i], tasks[j])
+	})
+	if limit > 0 && len(tasks) > limit {
+		tasks = tasks[:limit]
+	}
+
+	return tasks, nil
+}
+
+// MarkTaskOverdue records the due date a task was found overdue for. Unknown
+// tasks and tasks whose due date moved are left alone.
+func (r *memoryNudgeRepository) MarkTaskOverdue(taskID common.TaskID, dueDate time.Time) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	task, exists := r.data.tasks[taskID]
+	if !exists || task.DueDate == nil || !task.DueDate.Equal(dueDate) {
+		return nil
+	}
+
+	overdueSince := dueDate
+	task.OverdueSince = &overdueSince
+	r.data.tasks[taskID] = task
+	return nil
+}
+
+// Reminder operations
+
+// CreateReminder stores a new reminder for an existing task
+func (r *memoryNudgeRepository) CreateReminder(reminder *Reminder) error {
+	if !reminder.ReminderType.IsValid() {
+		return NewTaskValidationError("reminder_type", reminder.ReminderType, "invalid reminder type")
+	}
+
+	if reminder.ScheduledAt.Before(time.Now().Add(-1 * time.Hour)) {
+		return NewTaskValidationError("scheduled_at", reminder.ScheduledAt, "scheduled time cannot be more than 1 hour in the past")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	task, exists := r.data.tasks[reminder.TaskID]
+	if !exists || task.UserID != reminder.UserID {
+		return common.NotFoundError{Resource: "Task", ID: string(reminder.TaskID)}
+	}
+
+	r.data.reminders[reminder.ID] = *reminder
+	return nil
+}
+
+// GetDueReminders retrieves unsent reminders scheduled at or before the given time
+func (r *memoryNudgeRepository) GetDueReminders(before time.Time) ([]*Reminder, error) {
+	r.mutex.RLock()
+	reminders := r.selectReminders(func(reminder Reminder) bool {
+		if _, exists := r.data.tasks[reminder.TaskID]; !exists {
+			return false
+		}
+		return reminder.SentAt == nil && !reminder.ScheduledAt.After(before)
+	})
+	r.mutex.RUnlock()
+
+	return reminders, nil
+}
+
+// MarkReminderSent marks a reminder as sent
+func (r *memoryNudgeRepository) MarkReminderSent(reminderID common.ID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	reminder, exists := r.data.reminders[reminderID]
+	if !exists || reminder.SentAt != nil {
+		return common.NotFoundError{Resource: "Reminder", ID: string(reminderID)}
+	}
+
+	now := time.Now()
+	reminder.SentAt = &now
+	r.data.reminders[reminderID] = reminder
+	return nil
+}
+
+// GetRemindersByTaskID retrieves all reminders for a specific task
+func (r *memoryNudgeRepository) GetRemindersByTaskID(taskID common.TaskID) ([]*Reminder, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.selectReminders(func(reminder Reminder) bool {
+		return reminder.TaskID == taskID
+	}), nil
+}
+
+// DeleteReminder deletes a reminder
+func (r *memoryNudgeRepository) DeleteReminder(reminderID common.ID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.data.reminders[reminderID]; !exists {
+		return common.NotFoundError{Resource: "Reminder", ID: string(reminderID)}
+	}
+
+	delete(r.data.reminders, reminderID)
+	return nil
+}
+
+// Nudge settings operations
+
+// GetNudgeSettingsByUserID retrieves nudge settings for a user, returning
+// defaults when none are stored
+func (r *memoryNudgeRepository) GetNudgeSettingsByUserID(userID common.UserID) (*NudgeSettings, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if settings, exists := r.data.settings[userID]; exists {
+		return &settings, nil
+	}
+
+	now := time.Now()
+	return &NudgeSettings{
+		UserID:        userID,
+		NudgeInterval: DefaultNudgeInterval,
+		MaxNudges:     DefaultMaxNudges,
+		Enabled:       true,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}, nil
+}
+
+// CreateOrUpdateNudgeSettings creates or updates nudge settings
+func (r *memoryNudgeRepository) CreateOrUpdateNudgeSettings(settings *NudgeSettings) error {
+	if err := ValidateNudgeSettings(settings); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	if settings.CreatedAt.IsZero() {
+		settings.CreatedAt = now
+	}
+	settings.UpdatedAt = now
+
+	r.data.settings[settings.UserID] = *settings
+	return nil
+}
+
+// DeleteNudgeSettings deletes nudge settings for a user
+func (r *memoryNudgeRepository) DeleteNudgeSettings(userID common.UserID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.data.settings[userID]; !exists {
+		return common.NotFoundError{Resource: "NudgeSettings", ID: string(userID)}
+	}
+
+	delete(r.data.settings, userID)
+	return nil
+}
+
+// Transaction support
+
+// WithContext returns the repository itself, which has no tenants to scope
+func (r *memoryNudgeRepository) WithContext(ctx context.Context) NudgeRepository {
+	return r
+}
+
+// WithTransaction runs fn against a copy of the data and commits it only when
+// fn succeeds. Transactions hold the write lock, so fn must use the repository
+// it is given rather than the outer one.
+func (r *memoryNudgeRepository) WithTransaction(fn func(NudgeRepository) error) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	txRepo := &memoryNudgeRepository{
+		data:   r.data.clone(),
+		logger: r.logger,
+	}
+
+	if err := fn(txRepo); err != nil {
+		r.logger.Debug("Transaction failed, rolling back", zap.Error(err))
+		return err
+	}
+
+	r.data = txRepo.data
+	return nil
+}
+
+// selectTasks returns copies of the tasks matching the predicate; the caller must hold the lock
+func (r *memoryNudgeRepository) selectTasks(match func(task Task) bool) []*Task {
+	tasks := make([]*Task, 0)
+	for _, task := range r.data.tasks {
+		if match(task) {
+			taskCopy := task
+			tasks = append(tasks, &taskCopy)
+		}
+	}
+	return tasks
+}
+
+// selectReminders returns copies of the reminders matching the predicate ordered
+// by schedule; the caller must hold the lock
+func (r *memoryNudgeRepository) selectReminders(match func(reminder Reminder) bool) []*Reminder {
+	reminders := make([]*Reminder, 0)
+	for _, reminder := range r.data.reminders {
+		if match(reminder) {
+			reminderCopy := reminder
+			reminders = append(reminders, &reminderCopy)
+		}
+	}
+
+	sort.SliceStable(reminders, func(i, j int) bool {
+		return reminders[i].ScheduledAt.Before(reminders[j].ScheduledAt)
+	})
+	return reminders
+}
+
+// dueBefore orders tasks by due date with undated tasks last
+func dueBefore(a, b *Task) bool {
+	switch {
+	case a.DueDate == nil:
+		return false
+	case b.DueDate == nil:
+		return true
+	default:
+		return a.DueDate.Before(*b.DueDate)
+	}
+}
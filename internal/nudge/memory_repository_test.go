@@ -0,0 +1,184 @@
+package nudge
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// runRepositoryContract exercises behaviour every NudgeRepository implementation must share
+func runRepositoryContract(t *testing.T, newRepo func(t *testing.T) NudgeRepository) {
+	userID := common.UserID(common.NewID())
+
+	newTask := func(title string, priority common.Priority, due *time.Time) *Task {
+		return &Task{
+			ID:       common.TaskID(common.NewID()),
+			UserID:   userID,
+			ChatID:   "chat-1",
+			Title:    title,
+			Priority: priority,
+			Status:   common.TaskStatusActive,
+			DueDate:  due,
+		}
+	}
+
+	t.Run("create and get task", func(t *testing.T) {
+		repo := newRepo(t)
+		task := newTask("Write report", common.PriorityHigh, nil)
+		require.NoError(t, repo.CreateTask(task))
+
+		stored, err := repo.GetTaskByID(task.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Write report", stored.Title)
+		assert.False(t, stored.CreatedAt.IsZero())
+
+		_, err = repo.GetTaskByID("missing")
+		assert.True(t, errors.As(err, &common.NotFoundError{}))
+	})
+
+	t.Run("duplicate open task is rejected", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.CreateTask(newTask("Buy milk", common.PriorityLow, nil)))
+		assert.Error(t, repo.CreateTask(newTask("Buy milk", common.PriorityLow, nil)))
+	})
+
+	t.Run("tasks are ordered by priority then due date", func(t *testing.T) {
+		repo := newRepo(t)
+		soon := time.Now().Add(time.Hour)
+		later := time.Now().Add(24 * time.Hour)
+
+		require.NoError(t, repo.CreateTask(newTask("low", common.PriorityLow, &soon)))
+		require.NoError(t, repo.CreateTask(newTask("urgent later", common.PriorityUrgent, &later)))
+		require.NoError(t, repo.CreateTask(newTask("urgent undated", common.PriorityUrgent, nil)))
+		require.NoError(t, repo.CreateTask(newTask("urgent soon", common.PriorityUrgent, &soon)))
+
+		tasks, err := repo.GetTasksByUserID(userID, TaskFilter{UserID: userID})
+		require.NoError(t, err)
+		require.Len(t, tasks, 4)
+		assert.Equal(t, []string{"urgent soon", "urgent later", "urgent undated", "low"},
+			[]string{tasks[0].Title, tasks[1].Title, tasks[2].Title, tasks[3].Title})
+	})
+
+	t.Run("overdue tasks and bulk status update", func(t *testing.T) {
+		repo := newRepo(t)
+		past := time.Now().Add(-2 * time.Hour)
+		future := time.Now().Add(2 * time.Hour)
+
+		overdue := newTask("overdue", common.PriorityMedium, &past)
+		upcoming := newTask("upcoming", common.PriorityMedium, &future)
+		require.NoError(t, repo.CreateTask(overdue))
+		require.NoError(t, repo.CreateTask(upcoming))
+
+		tasks, err := repo.GetOverdueTasks(userID)
+		require.NoError(t, err)
+		require.Len(t, tasks, 1)
+		assert.Equal(t, overdue.ID, tasks[0].ID)
+
+		require.NoError(t, repo.BulkUpdateTaskStatus([]common.TaskID{overdue.ID, upcoming.ID}, common.TaskStatusCompleted))
+
+		stored, err := repo.GetTaskByID(overdue.ID)
+		require.NoError(t, err)
+		assert.Equal(t, common.TaskStatusCompleted, stored.Status)
+		assert.NotNil(t, stored.CompletedAt)
+
+		tasks, err = repo.GetOverdueTasks(userID)
+		require.NoError(t, err)
+		assert.Empty(t, tasks)
+
+		stats, err := repo.GetTaskStats(userID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), stats.TotalTasks)
+		assert.Equal(t, int64(2), stats.CompletedTasks)
+	})
+
+	t.Run("stats break open tasks down", func(t *testing.T) {
+		repo := newRepo(t)
+		past := time.Now().Add(-2 * time.Hour)
+
+		report := newTask("report", common.PriorityHigh, &past)
+		report.Tags = []string{"work"}
+		taxes := newTask("taxes", common.PriorityHigh, nil)
+		taxes.Tags = []string{"home", "money"}
+		groceries := newTask("groceries", common.PriorityLow, nil)
+		groceries.Tags = []string{"home"}
+		done := newTask("done", common.PriorityUrgent, &past)
+		done.Tags = []string{"work"}
+		for _, task := range []*Task{report, taxes, groceries, done} {
+			require.NoError(t, repo.CreateTask(task))
+		}
+		require.NoError(t, repo.BulkUpdateTaskStatus([]common.TaskID{done.ID}, common.TaskStatusCompleted))
+
+		stats, err := repo.GetTaskStats(userID)
+		require.NoError(t, err)
+		assert.Equal(t, map[common.Priority]int64{common.PriorityHigh: 2, common.PriorityLow: 1}, stats.ByPriority)
+		assert.Equal(t, map[string]int64{"work": 1, "home": 2, "money": 1}, stats.ByTag)
+		assert.Equal(t, OverdueBuckets{UnderADay: 1}, stats.OverdueByAge)
+	})
+
+	t.Run("due reminders are returned until marked sent", func(t *testing.T) {
+		repo := newRepo(t)
+		task := newTask("Call mom", common.PriorityMedium, nil)
+		require.NoError(t, repo.CreateTask(task))
+
+		reminder := &Reminder{
+			ID:           common.NewID(),
+			TaskID:       task.ID,
+			UserID:       userID,
+			ChatID:       "chat-1",
+			ScheduledAt:  time.Now().Add(-time.Minute),
+			ReminderType: ReminderTypeInitial,
+		}
+		require.NoError(t, repo.CreateReminder(reminder))
+
+		due, err := repo.GetDueReminders(time.Now())
+		require.NoError(t, err)
+		require.Len(t, due, 1)
+
+		require.NoError(t, repo.MarkReminderSent(reminder.ID))
+		due, err = repo.GetDueReminders(time.Now())
+		require.NoError(t, err)
+		assert.Empty(t, due)
+	})
+
+	t.Run("failed transaction is rolled back", func(t *testing.T) {
+		repo := newRepo(t)
+		first := newTask("first", common.PriorityMedium, nil)
+
+		err := repo.WithTransaction(func(tx NudgeRepository) error {
+			if err := tx.CreateTask(first); err != nil {
+				return err
+			}
+			return errors.New("abort")
+		})
+		require.Error(t, err)
+
+		_, err = repo.GetTaskByID(first.ID)
+		assert.True(t, errors.As(err, &common.NotFoundError{}))
+
+		require.NoError(t, repo.WithTransaction(func(tx NudgeRepository) error {
+			return tx.CreateTask(first)
+		}))
+		_, err = repo.GetTaskByID(first.ID)
+		assert.NoError(t, err)
+	})
+}
+
+func TestOverdueBuckets_Add(t *testing.T) {
+	var buckets OverdueBuckets
+	for _, overdue := range []time.Duration{time.Hour, 24 * time.Hour, 71 * time.Hour, 72 * time.Hour, 7 * 24 * time.Hour, 30 * 24 * time.Hour} {
+		buckets.Add(overdue)
+	}
+	assert.Equal(t, OverdueBuckets{UnderADay: 1, OneToThreeDays: 2, ThreeToSevenDays: 1, OverAWeek: 2}, buckets)
+}
+
+func TestMemoryNudgeRepository_Contract(t *testing.T) {
+	runRepositoryContract(t, func(t *testing.T) NudgeRepository {
+		return NewMemoryNudgeRepository(zaptest.NewLogger(t))
+	})
+}
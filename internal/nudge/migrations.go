@@ -12,6 +12,11 @@ import (
 	"gorm.io/gorm"
 )
 
+// SchemaVersion numbers the shape of the nudge tables. Bump it whenever a
+// model gains, loses or changes a column, so that backups record which
+// schema they were taken with.
+const SchemaVersion = 6
+
 // RunMigrations performs auto-migration for all nudge-related tables
 func RunMigrations(db *gorm.DB) error {
 	// Auto-migrate all nudge models with retry/backoff
@@ -27,6 +32,16 @@ func RunMigrations(db *gorm.DB) error {
 			&Task{},
 			&Reminder{},
 			&NudgeSettings{},
+			&WorkspaceMember{},
+			&WorkspaceInvite{},
+			&TaskEvent{},
+			&ChatActivity{},
+			&APIToken{},
+			&SharedList{},
+			&SharedListMember{},
+			&ScheduledMessage{},
+			&Countdown{},
+			&TaskStatsDay{},
 		)
 		if err == nil {
 			break
@@ -60,11 +75,47 @@ func RunMigrations(db *gorm.DB) error {
 		return fmt.Errorf("failed to create indexes: %w", err)
 	}
 
+	if err := createArchiveTables(db); err != nil {
+		return fmt.Errorf("failed to create archive tables: %w", err)
+	}
+
+	if err := backfillTaskCodes(db); err != nil {
+		return fmt.Errorf("failed to assign task codes: %w", err)
+	}
+
+	if err := backfillTaskChats(db); err != nil {
+		return fmt.Errorf("failed to assign task chats: %w", err)
+	}
+
 	return nil
 }
 
+// identitiesTable maps Telegram accounts to internal IDs; it is owned by the chatbot
+const identitiesTable = "telegram_identities"
+
+// backfillTaskChatsSQL gives the tasks created before chats were tracked the
+// private chat of their user. Telegram gives a private chat the ID of its
+// user, so the chat has the user's identity; users without one are skipped.
+var backfillTaskChatsSQL = fmt.Sprintf(`UPDATE tasks SET chat_id = tasks.user_id
+	WHERE COALESCE(tasks.chat_id, '') = '' AND EXISTS (
+		SELECT 1 FROM %s AS identity WHERE CAST(identity.id AS TEXT) = tasks.user_id)`, identitiesTable)
+
+// backfillTaskChats assigns chats to legacy tasks, so reminders can be
+// delivered for them. It does nothing until the chatbot's identity table exists.
+func backfillTaskChats(db *gorm.DB) error {
+	if !db.Migrator().HasTable(identitiesTable) {
+		return nil
+	}
+	return db.Exec(backfillTaskChatsSQL).Error
+}
+
 // createIndexes creates performance indexes for nudge tables
 func createIndexes(db *gorm.DB) error {
+	// Telegram IDs are unique per bot; drop the index that made them globally unique
+	if err := db.Exec("DROP INDEX IF EXISTS idx_users_telegram_id").Error; err != nil {
+		return fmt.Errorf("failed to drop legacy user index: %w", err)
+	}
+
 	// Task table indexes
 	taskIndexes := []string{
 		"CREATE INDEX IF NOT EXISTS idx_tasks_user_id ON tasks(user_id)",
@@ -78,6 +129,7 @@ func createIndexes(db *gorm.DB) error {
 		"CREATE INDEX IF NOT EXISTS idx_tasks_user_priority ON tasks(user_id, priority)",
 		"CREATE INDEX IF NOT EXISTS idx_tasks_status_due_date ON tasks(status, due_date)",
 		"CREATE INDEX IF NOT EXISTS idx_tasks_user_chat ON tasks(user_id, chat_id)",
+		"CREATE INDEX IF NOT EXISTS idx_tasks_user_code ON tasks(user_id, code)",
 	}
 
 	for _, index := range taskIndexes {
@@ -86,15 +138,22 @@ func createIndexes(db *gorm.DB) error {
 		}
 	}
 
+	// Reminder polling reads only unsent reminders, so the partial index below
+	// replaces the full scheduled_at indexes
+	for _, legacy := range []string{"idx_reminders_scheduled_at", "idx_reminders_scheduled_sent"} {
+		if err := db.Exec("DROP INDEX IF EXISTS " + legacy).Error; err != nil {
+			return fmt.Errorf("failed to drop legacy reminder index: %w", err)
+		}
+	}
+
 	// Reminder table indexes
 	reminderIndexes := []string{
 		"CREATE INDEX IF NOT EXISTS idx_reminders_task_id ON reminders(task_id)",
 		"CREATE INDEX IF NOT EXISTS idx_reminders_user_id ON reminders(user_id)",
 		"CREATE INDEX IF NOT EXISTS idx_reminders_chat_id ON reminders(chat_id)",
-		"CREATE INDEX IF NOT EXISTS idx_reminders_scheduled_at ON reminders(scheduled_at)",
 		"CREATE INDEX IF NOT EXISTS idx_reminders_sent_at ON reminders(sent_at)",
 		"CREATE INDEX IF NOT EXISTS idx_reminders_type ON reminders(reminder_type)",
-		"CREATE INDEX IF NOT EXISTS idx_reminders_scheduled_sent ON reminders(scheduled_at, sent_at)",
+		"CREATE INDEX IF NOT EXISTS idx_reminders_due_unsent ON reminders(scheduled_at) WHERE sent_at IS NULL",
 		"CREATE INDEX IF NOT EXISTS idx_reminders_task_scheduled ON reminders(task_id, scheduled_at)",
 		"CREATE INDEX IF NOT EXISTS idx_reminders_user_chat ON reminders(user_id, chat_id)",
 	}
@@ -125,6 +184,8 @@ func createIndexes(db *gorm.DB) error {
 func DropTables(db *gorm.DB) error {
 	// Drop tables in reverse order to handle foreign key dependencies
 	tables := []string{
+		ReminderArchiveTable,
+		TaskArchiveTable,
 		"reminders",
 		"nudge_settings",
 		"tasks",
@@ -142,7 +203,7 @@ func DropTables(db *gorm.DB) error {
 // ValidateMigrations checks if all required tables and indexes exist
 func ValidateMigrations(db *gorm.DB) error {
 	// Check if tables exist
-	requiredTables := []string{"users", "tasks", "reminders", "nudge_settings"}
+	requiredTables := []string{"users", "tasks", "reminders", "nudge_settings", TaskArchiveTable, ReminderArchiveTable}
 
 	for _, table := range requiredTables {
 		var exists bool
@@ -159,7 +220,7 @@ func ValidateMigrations(db *gorm.DB) error {
 	requiredIndexes := []string{
 		"idx_tasks_user_id",
 		"idx_tasks_status",
-		"idx_reminders_scheduled_at",
+		"idx_reminders_due_unsent",
 		"idx_reminders_task_id",
 	}
 
@@ -177,8 +238,13 @@ func ValidateMigrations(db *gorm.DB) error {
 	return nil
 }
 
-// MigrateWithValidation runs migrations and validates the result
-func MigrateWithValidation(db *gorm.DB) error {
+// MigrationValidator checks the database after migrations have run
+type MigrationValidator func(db *gorm.DB) error
+
+// MigrateWithValidation runs migrations and validates the result, including
+// that the hot queries can use their indexes, then runs the extra validators
+// in order
+func MigrateWithValidation(db *gorm.DB, validators ...MigrationValidator) error {
 	if err := RunMigrations(db); err != nil {
 		return err
 	}
@@ -187,6 +253,16 @@ func MigrateWithValidation(db *gorm.DB) error {
 		return fmt.Errorf("migration validation failed: %w", err)
 	}
 
+	if err := ValidateQueryPlans(db); err != nil {
+		return fmt.Errorf("query plan validation failed: %w", err)
+	}
+
+	for i, validate := range validators {
+		if err := validate(db); err != nil {
+			return fmt.Errorf("post-migration validator %d failed: %w", i+1, err)
+		}
+	}
+
 	return nil
 }
 
@@ -208,6 +284,15 @@ func GetTableStats(db *gorm.DB) (map[string]int64, error) {
 	}
 	stats["reminders"] = reminderCount
 
+	// Count archived rows
+	for _, table := range []string{TaskArchiveTable, ReminderArchiveTable} {
+		var archived int64
+		if err := db.Table(table).Count(&archived).Error; err != nil {
+			return nil, fmt.Errorf("failed to count %s: %w", table, err)
+		}
+		stats[table] = archived
+	}
+
 	// Count nudge settings
 	var settingsCount int64
 	if err := db.Model(&NudgeSettings{}).Count(&settingsCount).Error; err != nil {
@@ -1,6 +1,7 @@
 package nudge
 
 import (
+	"context"
 	"time"
 
 	"nudgebot-api/internal/common"
@@ -86,17 +87,22 @@ func (m *MockTaskRepository) GetTaskStats(userID common.UserID) (*TaskStats, err
 		return nil, m.getError
 	}
 
-	stats := &TaskStats{}
+	stats := NewTaskStats()
+	now := time.Now()
 	for _, task := range m.tasks {
 		if task.UserID == userID {
 			stats.TotalTasks++
+			stats.ByStatus[task.Status]++
 			if task.Status == common.TaskStatusCompleted {
 				stats.CompletedTasks++
 			} else if task.Status == common.TaskStatusActive {
 				stats.ActiveTasks++
-				if task.IsOverdue() {
-					stats.OverdueTasks++
-				}
+			}
+			if task.IsOverdue() {
+				stats.OverdueTasks++
+			}
+			if task.Status.IsOpen() {
+				stats.countOpen(task, now)
 			}
 		}
 	}
@@ -104,6 +110,97 @@ func (m *MockTaskRepository) GetTaskStats(userID common.UserID) (*TaskStats, err
 	return stats, nil
 }
 
+func (m *MockTaskRepository) GetOverdueTasks(userID common.UserID) ([]*Task, error) {
+	if m.getError != nil {
+		return nil, m.getError
+	}
+
+	var tasks []*Task
+	for _, task := range m.tasks {
+		if task.UserID == userID && task.IsOverdue() {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+func (m *MockTaskRepository) BulkUpdateTaskStatus(taskIDs []common.TaskID, status common.TaskStatus) error {
+	if m.updateError != nil {
+		return m.updateError
+	}
+
+	for _, taskID := range taskIDs {
+		if task, exists := m.tasks[taskID]; exists {
+			task.Status = status
+		}
+	}
+	return nil
+}
+
+func (m *MockTaskRepository) GetNewlyOverdueTasks(now time.Time, limit int) ([]*Task, error) {
+	if m.getError != nil {
+		return nil, m.getError
+	}
+
+	var tasks []*Task
+	for _, task := range m.tasks {
+		if task.NewlyOverdue(now) && (limit <= 0 || len(tasks) < limit) {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+func (m *MockTaskRepository) MarkTaskOverdue(taskID common.TaskID, dueDate time.Time) error {
+	if m.updateError != nil {
+		return m.updateError
+	}
+
+	if task, exists := m.tasks[taskID]; exists && task.DueDate != nil && task.DueDate.Equal(dueDate) {
+		task.OverdueSince = &dueDate
+	}
+	return nil
+}
+
+func (m *MockTaskRepository) BulkShiftDueDates(taskIDs []common.TaskID, shift time.Duration) error {
+	if m.updateError != nil {
+		return m.updateError
+	}
+
+	shifted := make(map[common.TaskID]bool, len(taskIDs))
+	for _, taskID := range taskIDs {
+		if task, exists := m.tasks[taskID]; exists && task.DueDate != nil {
+			due := task.DueDate.Add(shift)
+			task.DueDate = &due
+		}
+		shifted[taskID] = true
+	}
+	for _, reminder := range m.reminders {
+		if shifted[reminder.TaskID] && reminder.SentAt == nil {
+			reminder.ScheduledAt = reminder.ScheduledAt.Add(shift)
+		}
+	}
+	return nil
+}
+
+func (m *MockTaskRepository) MoveChatID(from, to common.ChatID) error {
+	if m.updateError != nil {
+		return m.updateError
+	}
+
+	for _, task := range m.tasks {
+		if task.ChatID == from {
+			task.ChatID = to
+		}
+	}
+	for _, reminder := range m.reminders {
+		if reminder.ChatID == from {
+			reminder.ChatID = to
+		}
+	}
+	return nil
+}
+
 // Reminder repository methods
 func (m *MockTaskRepository) CreateReminder(reminder *Reminder) error {
 	if m.createError != nil {
@@ -206,6 +303,10 @@ func (m *MockTaskRepository) DeleteNudgeSettings(userID common.UserID) error {
 }
 
 // Transaction support
+func (m *MockTaskRepository) WithContext(ctx context.Context) NudgeRepository {
+	return m
+}
+
 func (m *MockTaskRepository) WithTransaction(fn func(NudgeRepository) error) error {
 	// For mock, just execute the function with the same repository
 	return fn(m)
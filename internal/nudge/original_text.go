@@ -0,0 +1,74 @@
+package nudge
+
+import (
+	"errors"
+	"fmt"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// handleTaskOriginalRequested answers a chatbot request for the full message
+// a task was summarized from
+func (s *nudgeService) handleTaskOriginalRequested(event events.TaskOriginalRequested) {
+	s = s.forUser(event.UserID)
+	response := events.TaskOriginalResponse{
+		Event:  events.NewEvent(),
+		UserID: event.UserID,
+		ChatID: event.ChatID,
+		TaskID: event.TaskID,
+	}
+
+	task, err := s.originalTaskFor(common.TaskID(event.TaskID), common.UserID(event.UserID))
+	switch {
+	case err != nil:
+		s.logger.Warn("Original text request failed",
+			zap.String("correlationID", event.CorrelationID),
+			zap.String("taskID", event.TaskID),
+			zap.Error(err))
+		response.Message = originalTextErrorMessage(err)
+	case task.OriginalText == "":
+		response.Message = "This task was not summarized, its description is the full text."
+	default:
+		response.Success = true
+		response.TaskTitle = task.Title
+		response.Text = task.OriginalText
+	}
+
+	if err := s.eventBus.Publish(events.TopicTaskOriginalResponse, response); err != nil {
+		s.logger.Error("Failed to publish TaskOriginalResponse event",
+			zap.String("taskID", event.TaskID),
+			zap.Error(err))
+	}
+}
+
+// originalTaskFor returns the task if the user may view it
+func (s *nudgeService) originalTaskFor(taskID common.TaskID, userID common.UserID) (*Task, error) {
+	if s.repository == nil {
+		return nil, fmt.Errorf("repository not initialized")
+	}
+
+	task, err := s.repository.GetTaskByID(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizeTaskAction(task, userID, "view"); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// originalTextErrorMessage returns a message that can be shown to the user
+func originalTextErrorMessage(err error) string {
+	var notFound common.NotFoundError
+	if errors.As(err, &notFound) || errors.Is(err, ErrTaskNotFound) {
+		return "I couldn't find that task. See your tasks with /list."
+	}
+	var permissionErr PermissionError
+	if errors.As(err, &permissionErr) {
+		return "Not allowed: " + permissionErr.Message()
+	}
+	return "Something went wrong, please try again later."
+}
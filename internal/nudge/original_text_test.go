@@ -0,0 +1,57 @@
+package nudge
+
+import (
+	"testing"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNudgeService_KeepsTheOriginalOfSummarizedTasks(t *testing.T) {
+	service, repo, eventBus := newBulkTestService(t)
+	nudge := service.(*nudgeService)
+	userID := common.UserID(common.NewID())
+	email := "Hi,\n\nPlease review the attached contract before Friday.\n\nThanks,\nAnna"
+
+	nudge.handleTaskParsed(events.TaskParsed{
+		Event:        events.NewEvent(),
+		UserID:       string(userID),
+		ChatID:       "12345",
+		ParsedTask:   events.ParsedTask{Title: "Review contract", Description: "Anna needs it reviewed before Friday.", Priority: "high"},
+		OriginalText: email,
+	})
+
+	created := eventBus.GetPublishedEvents(events.TopicTaskCreated)
+	require.Len(t, created, 1)
+	event := created[0].(events.TaskCreated)
+	assert.True(t, event.HasOriginal)
+	assert.Equal(t, "Anna needs it reviewed before Friday.", event.Summary)
+
+	task, err := repo.GetTaskByID(common.TaskID(event.TaskID))
+	require.NoError(t, err)
+	assert.Equal(t, email, task.OriginalText)
+
+	request := func(requester common.UserID, taskID string) events.TaskOriginalResponse {
+		eventBus.ClearEvents()
+		nudge.handleTaskOriginalRequested(events.TaskOriginalRequested{Event: events.NewEvent(), UserID: string(requester), ChatID: "12345", TaskID: taskID})
+		responses := eventBus.GetPublishedEvents(events.TopicTaskOriginalResponse)
+		require.Len(t, responses, 1)
+		return responses[0].(events.TaskOriginalResponse)
+	}
+
+	response := request(userID, event.TaskID)
+	assert.True(t, response.Success)
+	assert.Equal(t, "Review contract", response.TaskTitle)
+	assert.Equal(t, email, response.Text)
+
+	response = request(common.UserID(common.NewID()), event.TaskID)
+	assert.False(t, response.Success, "other users can't read the original")
+	assert.Empty(t, response.Text)
+
+	response = request(userID, "missing")
+	assert.False(t, response.Success)
+	assert.Contains(t, response.Message, "couldn't find")
+}
@@ -0,0 +1,161 @@
+package nudge
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestSelectPlanTasks(t *testing.T) {
+	now := time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)
+	at := func(hour int) *time.Time {
+		due := now.Add(time.Duration(hour-8) * time.Hour)
+		return &due
+	}
+	task := func(title string, priority common.Priority, due *time.Time) *Task {
+		return &Task{Title: title, Priority: priority, DueDate: due, Status: common.TaskStatusActive}
+	}
+	done := task("Done already", common.PriorityUrgent, at(9))
+	done.Status = common.TaskStatusCompleted
+
+	selected := SelectPlanTasks([]*Task{
+		task("Tomorrow", common.PriorityUrgent, at(30)),
+		task("Afternoon", common.PriorityLow, at(15)),
+		task("Undated urgent", common.PriorityUrgent, nil),
+		task("Undated low", common.PriorityLow, nil),
+		task("Overdue", common.PriorityMedium, at(6)),
+		task("Afternoon high", common.PriorityHigh, at(15)),
+		done,
+	}, now)
+
+	titles := make([]string, len(selected))
+	for i, task := range selected {
+		titles[i] = task.Title
+	}
+	assert.Equal(t, []string{"Overdue", "Afternoon high", "Afternoon", "Undated urgent"}, titles)
+}
+
+func TestPlanPrompt(t *testing.T) {
+	now := time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC)
+	due := now.Add(2 * time.Hour)
+	task := &Task{
+		Title:        "Write report",
+		Priority:     common.PriorityHigh,
+		DueDate:      &due,
+		CustomFields: map[string]CustomField{PlanEstimateField: {Type: CustomFieldNumber, Value: "45"}},
+	}
+
+	prompt := PlanPrompt([]*Task{task}, now)
+	assert.Contains(t, prompt, "from 08:00 until 22:00")
+	assert.Contains(t, prompt, `"number":1,"title":"Write report","priority":"high","due":"10:00","estimate_minutes":45`)
+	assert.Contains(t, prompt, "untrusted")
+}
+
+func TestParsePlan(t *testing.T) {
+	day := time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)
+	tasks := []*Task{{ID: "task-1", Title: "Write report"}, {ID: "task-2", Title: "Call the bank"}}
+
+	blocks := ParsePlan(strings.Join([]string{
+		"Here is your plan:",
+		"- 09:00-10:30 #1 Write the report",
+		"10:30 – 10:45 Coffee break",
+		"10:45-11:15 #2 Bank",
+		"11:15-11:45 #2 Bank again",
+		"12:00-13:00 #7 Unknown task",
+		"25:00-26:00 #1 Not a time",
+		"23:30-00:30 Wind down",
+	}, "\n"), day, tasks)
+
+	require.Len(t, blocks, 4)
+	assert.Equal(t, events.PlanBlock{Start: day.Add(9 * time.Hour), End: day.Add(10*time.Hour + 30*time.Minute), TaskID: "task-1", Title: "Write report"}, blocks[0])
+	assert.Equal(t, "", blocks[1].TaskID)
+	assert.Equal(t, "Coffee break", blocks[1].Title)
+	assert.Equal(t, "Call the bank", blocks[2].Title)
+	assert.Equal(t, day.Add(24*time.Hour+30*time.Minute), blocks[3].End, "blocks past midnight end the next day")
+}
+
+func TestPlanService_WritesAndAcceptsPlan(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	eventBus := events.NewMockEventBus()
+	eventBus.SetSynchronousMode(true)
+	tasks := NewMemoryNudgeRepository(logger)
+	service := NewPlanService(eventBus, logger, tasks).(*planService)
+	// Reminders can't be set in the past, so the plan is for tomorrow
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+	now := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 8, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	userID := common.UserID(common.NewID())
+
+	due := now.Add(4 * time.Hour)
+	task := &Task{ID: common.TaskID(common.NewID()), UserID: userID, ChatID: "12345", Title: "Write report", DueDate: &due, Priority: common.PriorityHigh, Status: common.TaskStatusActive}
+	require.NoError(t, tasks.CreateTask(task))
+
+	require.NoError(t, eventBus.Publish(events.TopicPlanRequested, events.PlanRequested{
+		Event: events.NewEvent(), UserID: string(userID), ChatID: "12345", MessageID: 99,
+	}))
+	requests := eventBus.GetPublishedEvents(events.TopicTextGenerationRequested)
+	require.Len(t, requests, 1)
+	request := requests[0].(events.TextGenerationRequested)
+	assert.Equal(t, events.TextPurposePlan, request.Purpose)
+	assert.Equal(t, 99, request.MessageID)
+	assert.Contains(t, request.Prompt, "Write report")
+
+	require.NoError(t, eventBus.Publish(events.TopicTextGenerated, events.TextGenerated{
+		Event: request.Event, UserID: string(userID), ChatID: "12345", MessageID: 99, Purpose: events.TextPurposePlan,
+		Text: "09:00-10:00 #1 Write report\n10:00-10:15 Break", Done: true, Success: true,
+	}))
+	updates := eventBus.GetPublishedEvents(events.TopicPlanUpdated)
+	require.Len(t, updates, 1)
+	ready := updates[0].(events.PlanUpdated)
+	assert.Equal(t, events.PlanReady, ready.State)
+	assert.Equal(t, 99, ready.MessageID)
+	require.Len(t, ready.Blocks, 2)
+	assert.Equal(t, string(task.ID), ready.Blocks[0].TaskID)
+
+	// Only the user the plan was written for can accept it
+	eventBus.ClearEvents()
+	require.NoError(t, eventBus.Publish(events.TopicPlanAcceptRequested, events.PlanAcceptRequested{
+		Event: events.NewEvent(), UserID: "someone-else", ChatID: "12345", PlanID: ready.PlanID,
+	}))
+	failed := eventBus.GetPublishedEvents(events.TopicPlanUpdated)[0].(events.PlanUpdated)
+	assert.Equal(t, events.PlanFailed, failed.State)
+
+	eventBus.ClearEvents()
+	require.NoError(t, eventBus.Publish(events.TopicPlanAcceptRequested, events.PlanAcceptRequested{
+		Event: events.NewEvent(), UserID: string(userID), ChatID: "12345", PlanID: ready.PlanID,
+	}))
+	accepted := eventBus.GetPublishedEvents(events.TopicPlanUpdated)[0].(events.PlanUpdated)
+	assert.Equal(t, events.PlanAccepted, accepted.State)
+	assert.Equal(t, 1, accepted.Reminders)
+
+	reminders, err := tasks.GetRemindersByTaskID(task.ID)
+	require.NoError(t, err)
+	require.Len(t, reminders, 1)
+	assert.Equal(t, now.Add(time.Hour), reminders[0].ScheduledAt)
+	assert.Equal(t, ReminderTypeInitial, reminders[0].ReminderType)
+}
+
+func TestPlanService_NothingToPlan(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	eventBus := events.NewMockEventBus()
+	eventBus.SetSynchronousMode(true)
+	NewPlanService(eventBus, logger, NewMemoryNudgeRepository(logger))
+
+	require.NoError(t, eventBus.Publish(events.TopicPlanRequested, events.PlanRequested{
+		Event: events.NewEvent(), UserID: "user", ChatID: "12345", MessageID: 99,
+	}))
+
+	assert.Empty(t, eventBus.GetPublishedEvents(events.TopicTextGenerationRequested))
+	updates := eventBus.GetPublishedEvents(events.TopicPlanUpdated)
+	require.Len(t, updates, 1)
+	update := updates[0].(events.PlanUpdated)
+	assert.Equal(t, events.PlanFailed, update.State)
+	assert.Empty(t, update.PlanID, "there is no plan to regenerate")
+}
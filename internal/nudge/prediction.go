@@ -0,0 +1,108 @@
+package nudge
+
+import (
+	"sort"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// predictionMinSamples is how many completed tasks an estimate needs
+	predictionMinSamples = 3
+	// predictionHistory caps the completed tasks an estimate is based on
+	predictionHistory = 200
+)
+
+// CompletionPrediction estimates when a task will be done from how long the
+// user took to complete similar tasks
+type CompletionPrediction struct {
+	DoneBy  time.Time
+	Typical time.Duration // median time from creation to completion
+	Samples int
+	// Tag is the tag whose tasks the estimate is based on, empty when it is
+	// based on all the user's completed tasks
+	Tag string
+}
+
+// PredictCompletion estimates when a task with the tags, created at
+// createdAt, will be done. The latencies of completed tasks sharing the tag
+// with the most history are used, or of all completed tasks when no tag has
+// predictionMinSamples. It returns nil without enough history.
+func PredictCompletion(completed []*Task, tags []string, createdAt time.Time) *CompletionPrediction {
+	var all []time.Duration
+	byTag := make(map[string][]time.Duration)
+	for _, task := range completed {
+		if task.CompletedAt == nil || task.CompletedAt.Before(task.CreatedAt) {
+			continue
+		}
+		latency := task.CompletedAt.Sub(task.CreatedAt)
+		all = append(all, latency)
+		for _, tag := range task.Tags {
+			byTag[tag] = append(byTag[tag], latency)
+		}
+	}
+
+	samples, basis := all, ""
+	for _, tag := range tags {
+		if len(byTag[tag]) >= predictionMinSamples && (basis == "" || len(byTag[tag]) > len(samples)) {
+			samples, basis = byTag[tag], tag
+		}
+	}
+	if len(samples) < predictionMinSamples {
+		return nil
+	}
+
+	typical := medianDuration(samples)
+	return &CompletionPrediction{
+		DoneBy:  createdAt.Add(typical),
+		Typical: typical,
+		Samples: len(samples),
+		Tag:     basis,
+	}
+}
+
+// medianDuration returns the median of the durations, which it sorts
+func medianDuration(durations []time.Duration) time.Duration {
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	middle := len(durations) / 2
+	if len(durations)%2 == 0 {
+		return (durations[middle-1] + durations[middle]) / 2
+	}
+	return durations[middle]
+}
+
+// estimateCompletion predicts when a newly created task will be done from the
+// user's completed tasks. Habits repeat, so they get no estimate.
+func (s *nudgeService) estimateCompletion(task *Task) *events.CompletionEstimate {
+	if s.repository == nil || task.Habit != "" {
+		return nil
+	}
+
+	status := common.TaskStatusCompleted
+	completed, err := s.repository.GetTasksByUserID(task.UserID, TaskFilter{
+		UserID: task.UserID,
+		Status: &status,
+		Limit:  predictionHistory,
+	})
+	if err != nil {
+		s.logger.Warn("Failed to load completed tasks for prediction",
+			zap.String("userID", string(task.UserID)),
+			zap.Error(err))
+		return nil
+	}
+
+	prediction := PredictCompletion(completed, task.Tags, task.CreatedAt)
+	if prediction == nil {
+		return nil
+	}
+	return &events.CompletionEstimate{
+		DoneBy:  prediction.DoneBy,
+		Typical: prediction.Typical,
+		Tag:     prediction.Tag,
+		Late:    task.DueDate != nil && prediction.DoneBy.After(*task.DueDate),
+	}
+}
@@ -0,0 +1,91 @@
+package nudge
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPredictCompletion(t *testing.T) {
+	created := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	completedTask := func(took time.Duration, tags ...string) *Task {
+		done := created.Add(took)
+		return &Task{CreatedAt: created, CompletedAt: &done, Tags: tags}
+	}
+	history := []*Task{
+		completedTask(time.Hour),
+		completedTask(2 * time.Hour),
+		completedTask(3 * time.Hour),
+		completedTask(48*time.Hour, "work"),
+		completedTask(72*time.Hour, "work"),
+		completedTask(96*time.Hour, "work"),
+		completedTask(120*time.Hour, "work"),
+		completedTask(time.Hour, "home"),
+		{CreatedAt: created}, // never completed
+	}
+	now := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+
+	prediction := PredictCompletion(history, []string{"home", "work"}, now)
+	require.NotNil(t, prediction)
+	assert.Equal(t, "work", prediction.Tag, "tags with too little history are skipped")
+	assert.Equal(t, 84*time.Hour, prediction.Typical)
+	assert.Equal(t, 4, prediction.Samples)
+	assert.Equal(t, now.Add(84*time.Hour), prediction.DoneBy)
+
+	prediction = PredictCompletion(history, []string{"home"}, now)
+	require.NotNil(t, prediction)
+	assert.Empty(t, prediction.Tag)
+	assert.Equal(t, 8, prediction.Samples)
+	assert.Equal(t, 25*time.Hour+30*time.Minute, prediction.Typical)
+
+	assert.Nil(t, PredictCompletion(history[:2], nil, now), "too little history")
+}
+
+func TestNudgeService_WarnsWhenTaskLikelyDoneAfterDue(t *testing.T) {
+	service, repo, eventBus := newBulkTestService(t)
+	userID := common.UserID(common.NewID())
+
+	for i := 0; i < predictionMinSamples; i++ {
+		task := bulkTask(userID, fmt.Sprintf("Report %d", i))
+		task.ID = common.TaskID(common.NewID())
+		task.Tags = []string{"work"}
+		require.NoError(t, repo.CreateTask(task))
+		// The repository keeps the creation time, so the tasks "finish" in three days
+		completedAt := task.CreatedAt.Add(3 * 24 * time.Hour)
+		task.Status = common.TaskStatusCompleted
+		task.CompletedAt = &completedAt
+		require.NoError(t, repo.UpdateTask(task))
+	}
+
+	tomorrow := time.Now().Add(24 * time.Hour)
+	rushed := bulkTask(userID, "Quarterly report")
+	rushed.ID = common.TaskID(common.NewID())
+	rushed.Tags = []string{"work"}
+	rushed.DueDate = &tomorrow
+	require.NoError(t, service.CreateTask(rushed))
+
+	nextMonth := time.Now().AddDate(0, 1, 0)
+	relaxed := bulkTask(userID, "Annual report")
+	relaxed.ID = common.TaskID(common.NewID())
+	relaxed.DueDate = &nextMonth
+	require.NoError(t, service.CreateTask(relaxed))
+
+	published := eventBus.GetPublishedEvents(events.TopicTaskCreated)
+	require.Len(t, published, 2)
+	estimate := published[0].(events.TaskCreated).Estimate
+	require.NotNil(t, estimate)
+	assert.True(t, estimate.Late)
+	assert.Equal(t, "work", estimate.Tag)
+	assert.Equal(t, 3*24*time.Hour, estimate.Typical)
+
+	estimate = published[1].(events.TaskCreated).Estimate
+	require.NotNil(t, estimate)
+	assert.False(t, estimate.Late)
+	assert.Empty(t, estimate.Tag)
+}
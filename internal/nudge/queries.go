@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/tenant"
 
 	"gorm.io/gorm"
 )
@@ -36,6 +37,12 @@ func (tqb *TaskQueryBuilder) WithUserID(userID common.UserID) *TaskQueryBuilder
 	return tqb
 }
 
+// WithUserOrLists filters tasks to the user's own and those in the shared lists
+func (tqb *TaskQueryBuilder) WithUserOrLists(userID common.UserID, listIDs []common.ID) *TaskQueryBuilder {
+	tqb.query = tqb.query.Where("(user_id = ? OR list_id IN ?)", userID, listIDs)
+	return tqb
+}
+
 // WithStatus filters tasks by status
 func (tqb *TaskQueryBuilder) WithStatus(status common.TaskStatus) *TaskQueryBuilder {
 	tqb.query = tqb.query.Where("status = ?", status)
@@ -48,12 +55,27 @@ func (tqb *TaskQueryBuilder) WithStatuses(statuses []common.TaskStatus) *TaskQue
 	return tqb
 }
 
+// WithCustomFields filters tasks whose custom fields have all the given
+// normalized values. Keys are validated identifiers before they get here.
+func (tqb *TaskQueryBuilder) WithCustomFields(fields map[string]string) *TaskQueryBuilder {
+	for key, value := range fields {
+		tqb.query = tqb.query.Where("custom_fields -> ? ->> 'value' = ?", key, value)
+	}
+	return tqb
+}
+
 // WithPriority filters tasks by priority
 func (tqb *TaskQueryBuilder) WithPriority(priority common.Priority) *TaskQueryBuilder {
 	tqb.query = tqb.query.Where("priority = ?", priority)
 	return tqb
 }
 
+// WithCode filters tasks by short code number
+func (tqb *TaskQueryBuilder) WithCode(code int) *TaskQueryBuilder {
+	tqb.query = tqb.query.Where("code = ?", code)
+	return tqb
+}
+
 // WithDueDateRange filters tasks by due date range
 func (tqb *TaskQueryBuilder) WithDueDateRange(after, before *time.Time) *TaskQueryBuilder {
 	if after != nil {
@@ -68,7 +90,15 @@ func (tqb *TaskQueryBuilder) WithDueDateRange(after, before *time.Time) *TaskQue
 // WithOverdue filters for overdue tasks
 func (tqb *TaskQueryBuilder) WithOverdue() *TaskQueryBuilder {
 	now := time.Now()
-	tqb.query = tqb.query.Where("due_date < ? AND status = ?", now, common.TaskStatusActive)
+	tqb.query = tqb.query.Where("due_date < ? AND status IN ?", now, common.OpenTaskStatuses())
+	return tqb
+}
+
+// WithNewlyOverdue filters for open tasks past their due date that have not
+// been marked overdue for it
+func (tqb *TaskQueryBuilder) WithNewlyOverdue(now time.Time) *TaskQueryBuilder {
+	tqb.query = tqb.query.Where("due_date < ? AND status IN ? AND (overdue_since IS NULL OR overdue_since <> due_date)",
+		now, common.OpenTaskStatuses())
 	return tqb
 }
 
@@ -76,7 +106,7 @@ func (tqb *TaskQueryBuilder) WithOverdue() *TaskQueryBuilder {
 func (tqb *TaskQueryBuilder) WithDueSoon(within time.Duration) *TaskQueryBuilder {
 	now := time.Now()
 	dueSoon := now.Add(within)
-	tqb.query = tqb.query.Where("due_date BETWEEN ? AND ? AND status = ?", now, dueSoon, common.TaskStatusActive)
+	tqb.query = tqb.query.Where("due_date BETWEEN ? AND ? AND status IN ?", now, dueSoon, common.OpenTaskStatuses())
 	return tqb
 }
 
@@ -212,6 +242,12 @@ func GetTasksWithReminders(db *gorm.DB, userID common.UserID, filter TaskFilter)
 	if filter.Status != nil {
 		query = query.Where("status = ?", *filter.Status)
 	}
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+	for key, value := range filter.CustomFields {
+		query = query.Where("custom_fields -> ? ->> 'value' = ?", key, value)
+	}
 	if filter.Priority != nil {
 		query = query.Where("priority = ?", *filter.Priority)
 	}
@@ -243,7 +279,7 @@ func GetOverdueTasksWithCounts(db *gorm.DB, userID common.UserID) ([]*Task, erro
 	err := db.Model(&Task{}).
 		Select("tasks.*, COUNT(reminders.id) as reminder_count").
 		Joins("LEFT JOIN reminders ON tasks.id = reminders.task_id").
-		Where("tasks.user_id = ? AND tasks.status = ? AND tasks.due_date < ?", userID, common.TaskStatusActive, now).
+		Where("tasks.user_id = ? AND tasks.status IN ? AND tasks.due_date < ?", userID, common.OpenTaskStatuses(), now).
 		Group("tasks.id").
 		Order("tasks.due_date ASC").
 		Find(&tasks).Error
@@ -258,7 +294,7 @@ func GetTasksDueSoon(db *gorm.DB, userID common.UserID, within time.Duration) ([
 
 	var tasks []*Task
 	err := db.Model(&Task{}).
-		Where("user_id = ? AND status = ? AND due_date BETWEEN ? AND ?", userID, common.TaskStatusActive, now, dueSoon).
+		Where("user_id = ? AND status IN ? AND due_date BETWEEN ? AND ?", userID, common.OpenTaskStatuses(), now, dueSoon).
 		Order("due_date ASC").
 		Find(&tasks).Error
 
@@ -269,14 +305,14 @@ func GetTasksDueSoon(db *gorm.DB, userID common.UserID, within time.Duration) ([
 func GetUserTaskSummary(db *gorm.DB, userID common.UserID) (*TaskStats, error) {
 	var stats TaskStats
 
-	// Get total tasks
-	err := db.Model(&Task{}).Where("user_id = ? AND status != ?", userID, common.TaskStatusDeleted).Count(&stats.TotalTasks).Error
+	// Totals include archived tasks; active and overdue tasks are never archived
+	err := tasksWithArchive(db).Where("user_id = ? AND status != ?", userID, common.TaskStatusDeleted).Count(&stats.TotalTasks).Error
 	if err != nil {
 		return nil, err
 	}
 
 	// Get completed tasks
-	err = db.Model(&Task{}).Where("user_id = ? AND status = ?", userID, common.TaskStatusCompleted).Count(&stats.CompletedTasks).Error
+	err = tasksWithArchive(db).Where("user_id = ? AND status = ?", userID, common.TaskStatusCompleted).Count(&stats.CompletedTasks).Error
 	if err != nil {
 		return nil, err
 	}
@@ -289,14 +325,83 @@ func GetUserTaskSummary(db *gorm.DB, userID common.UserID) (*TaskStats, error) {
 
 	// Get overdue tasks
 	now := time.Now()
-	err = db.Model(&Task{}).Where("user_id = ? AND status = ? AND due_date < ?", userID, common.TaskStatusActive, now).Count(&stats.OverdueTasks).Error
+	err = db.Model(&Task{}).Where("user_id = ? AND status IN ? AND due_date < ?", userID, common.OpenTaskStatuses(), now).Count(&stats.OverdueTasks).Error
 	if err != nil {
 		return nil, err
 	}
 
+	// Get the per-status breakdown
+	var rows []struct {
+		Status common.TaskStatus
+		Count  int64
+	}
+	err = tasksWithArchive(db).Select("status, COUNT(*) AS count").
+		Where("user_id = ? AND status != ?", userID, common.TaskStatusDeleted).
+		Group("status").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	stats.ByStatus = make(map[common.TaskStatus]int64, len(rows))
+	for _, row := range rows {
+		stats.ByStatus[row.Status] = row.Count
+	}
+
+	if err := addOpenTaskBreakdown(db, userID, &stats, now); err != nil {
+		return nil, err
+	}
+
 	return &stats, nil
 }
 
+// addOpenTaskBreakdown counts the user's open tasks by priority, by tag and
+// by how long they have been overdue
+func addOpenTaskBreakdown(db *gorm.DB, userID common.UserID, stats *TaskStats, now time.Time) error {
+	var priorities []struct {
+		Priority common.Priority
+		Count    int64
+	}
+	err := db.Model(&Task{}).Select("priority, COUNT(*) AS count").
+		Where("user_id = ? AND status IN ?", userID, common.OpenTaskStatuses()).
+		Group("priority").Scan(&priorities).Error
+	if err != nil {
+		return err
+	}
+	stats.ByPriority = make(map[common.Priority]int64, len(priorities))
+	for _, row := range priorities {
+		stats.ByPriority[row.Priority] = row.Count
+	}
+
+	var tags []struct {
+		Tag   string
+		Count int64
+	}
+	err = db.Table("tasks, jsonb_array_elements_text(COALESCE(tasks.tags, '[]'::jsonb)) AS tag").
+		Scopes(tenant.ScopeTable("tasks")).
+		Select("tag, COUNT(*) AS count").
+		Where("tasks.user_id = ? AND tasks.status IN ?", userID, common.OpenTaskStatuses()).
+		Group("tag").Scan(&tags).Error
+	if err != nil {
+		return err
+	}
+	stats.ByTag = make(map[string]int64, len(tags))
+	for _, row := range tags {
+		stats.ByTag[row.Tag] = row.Count
+	}
+
+	// Overdue tasks are few, so they are bucketed here rather than in SQL
+	var dueDates []time.Time
+	err = db.Model(&Task{}).
+		Where("user_id = ? AND status IN ? AND due_date < ?", userID, common.OpenTaskStatuses(), now).
+		Pluck("due_date", &dueDates).Error
+	if err != nil {
+		return err
+	}
+	for _, dueDate := range dueDates {
+		stats.OverdueByAge.Add(now.Sub(dueDate))
+	}
+	return nil
+}
+
 // Batch operations
 
 // BulkUpdateTaskStatus updates multiple tasks' status in a single operation
@@ -314,6 +419,85 @@ func BulkUpdateTaskStatus(db *gorm.DB, taskIDs []common.TaskID, status common.Ta
 	return db.Model(&Task{}).Where("id IN ?", taskIDs).Updates(updates).Error
 }
 
+// BulkShiftDueDates moves the due dates of tasks and the scheduled times of their
+// unsent reminders by shift in one transaction
+func BulkShiftDueDates(db *gorm.DB, taskIDs []common.TaskID, shift time.Duration) error {
+	seconds := shift.Seconds()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&Task{}).Where("id IN ? AND due_date IS NOT NULL", taskIDs).Updates(map[string]interface{}{
+			"due_date":   gorm.Expr("due_date + make_interval(secs => ?)", seconds),
+			"updated_at": time.Now(),
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&Reminder{}).Where("task_id IN ? AND sent_at IS NULL", taskIDs).
+			Update("scheduled_at", gorm.Expr("scheduled_at + make_interval(secs => ?)", seconds)).Error
+	})
+}
+
+// digestEntriesTable holds the notify package's digest entries, which are
+// addressed by chat like the nudge tables
+const digestEntriesTable = "reminder_digest_entries"
+
+// MoveChatID moves every row addressed to a chat to another chat ID in one
+// transaction: tasks, reminders, workspace members and invites, shared list
+// memberships, scheduled messages, countdowns, API token confirmations, chat
+// activity and queued digest entries. A member's role in the old chat
+// replaces any role they were given in the new chat, while activity already
+// seen in the new chat is kept as the more recent.
+func MoveChatID(db *gorm.DB, from, to common.ChatID) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		return moveChatRows(tx, from, to)
+	})
+}
+
+// moveChatRows runs the statements of MoveChatID
+func moveChatRows(tx *gorm.DB, from, to common.ChatID) error {
+	err := tx.Model(&Task{}).Where("chat_id = ?", from).Updates(map[string]interface{}{
+		"chat_id":    to,
+		"updated_at": time.Now(),
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	for _, model := range []interface{}{&Reminder{}, &WorkspaceInvite{}, &SharedListMember{}, &ScheduledMessage{}, &Countdown{}, &APIToken{}} {
+		if err := tx.Model(model).Where("chat_id = ?", from).Update("chat_id", to).Error; err != nil {
+			return err
+		}
+	}
+
+	// The chat is part of these primary keys, so rows that would collide with
+	// the new chat's are removed first
+	err = tx.Where("chat_id = ? AND user_id IN (?)", to,
+		tx.Model(&WorkspaceMember{}).Select("user_id").Where("chat_id = ?", from)).
+		Delete(&WorkspaceMember{}).Error
+	if err != nil {
+		return err
+	}
+	if err := tx.Model(&WorkspaceMember{}).Where("chat_id = ?", from).Update("chat_id", to).Error; err != nil {
+		return err
+	}
+	err = tx.Where("chat_id = ? AND EXISTS (?)", from,
+		tx.Model(&ChatActivity{}).Select("1").Where("chat_id = ?", to)).
+		Delete(&ChatActivity{}).Error
+	if err != nil {
+		return err
+	}
+	if err := tx.Model(&ChatActivity{}).Where("chat_id = ?", from).Update("chat_id", to).Error; err != nil {
+		return err
+	}
+
+	if !tx.Migrator().HasTable(digestEntriesTable) {
+		return nil
+	}
+	return tx.Table(digestEntriesTable).Scopes(tenant.ScopeTable(digestEntriesTable)).
+		Where("chat_id = ?", from).Update("chat_id", to).Error
+}
+
 // BulkCreateReminders creates multiple reminders in a single operation
 func BulkCreateReminders(db *gorm.DB, reminders []*Reminder) error {
 	if len(reminders) == 0 {
@@ -335,7 +519,7 @@ func GetTaskCompletionRate(db *gorm.DB, userID common.UserID, since time.Time) (
 	var total, completed int64
 
 	// Get total tasks created since the specified time
-	err := db.Model(&Task{}).Where("user_id = ? AND created_at >= ?", userID, since).Count(&total).Error
+	err := tasksWithArchive(db).Where("user_id = ? AND created_at >= ?", userID, since).Count(&total).Error
 	if err != nil {
 		return 0, err
 	}
@@ -345,7 +529,7 @@ func GetTaskCompletionRate(db *gorm.DB, userID common.UserID, since time.Time) (
 	}
 
 	// Get completed tasks created since the specified time
-	err = db.Model(&Task{}).Where("user_id = ? AND created_at >= ? AND status = ?", userID, since, common.TaskStatusCompleted).Count(&completed).Error
+	err = tasksWithArchive(db).Where("user_id = ? AND created_at >= ? AND status = ?", userID, since, common.TaskStatusCompleted).Count(&completed).Error
 	if err != nil {
 		return 0, err
 	}
@@ -359,7 +543,7 @@ func GetUserEngagementMetrics(db *gorm.DB, userID common.UserID, since time.Time
 
 	// Tasks created
 	var tasksCreated int64
-	err := db.Model(&Task{}).Where("user_id = ? AND created_at >= ?", userID, since).Count(&tasksCreated).Error
+	err := tasksWithArchive(db).Where("user_id = ? AND created_at >= ?", userID, since).Count(&tasksCreated).Error
 	if err != nil {
 		return nil, err
 	}
@@ -367,7 +551,7 @@ func GetUserEngagementMetrics(db *gorm.DB, userID common.UserID, since time.Time
 
 	// Tasks completed
 	var tasksCompleted int64
-	err = db.Model(&Task{}).Where("user_id = ? AND completed_at >= ?", userID, since).Count(&tasksCompleted).Error
+	err = tasksWithArchive(db).Where("user_id = ? AND completed_at >= ?", userID, since).Count(&tasksCompleted).Error
 	if err != nil {
 		return nil, err
 	}
@@ -375,7 +559,7 @@ func GetUserEngagementMetrics(db *gorm.DB, userID common.UserID, since time.Time
 
 	// Average completion time (in hours)
 	var avgCompletionTime float64
-	err = db.Model(&Task{}).
+	err = tasksWithArchive(db).
 		Select("AVG(EXTRACT(EPOCH FROM (completed_at - created_at))/3600) as avg_hours").
 		Where("user_id = ? AND completed_at >= ? AND completed_at IS NOT NULL", userID, since).
 		Scan(&avgCompletionTime).Error
@@ -386,7 +570,7 @@ func GetUserEngagementMetrics(db *gorm.DB, userID common.UserID, since time.Time
 
 	// Reminders sent
 	var remindersSent int64
-	err = db.Model(&Reminder{}).Where("user_id = ? AND sent_at >= ?", userID, since).Count(&remindersSent).Error
+	err = remindersWithArchive(db).Where("user_id = ? AND sent_at >= ?", userID, since).Count(&remindersSent).Error
 	if err != nil {
 		return nil, err
 	}
@@ -401,8 +585,8 @@ func GetReminderEffectivenessStats(db *gorm.DB, userID common.UserID, since time
 
 	// Tasks completed within 24 hours of reminder
 	var quickCompletions int64
-	err := db.Model(&Task{}).
-		Joins("JOIN reminders ON tasks.id = reminders.task_id").
+	err := tasksWithArchive(db).
+		Joins("JOIN (?) AS reminders ON tasks.id = reminders.task_id", withArchive(db, "reminders", ReminderArchiveTable, archivedReminderColumns)).
 		Where("tasks.user_id = ? AND reminders.sent_at >= ? AND tasks.completed_at IS NOT NULL AND tasks.completed_at <= reminders.sent_at + INTERVAL '24 hours'", userID, since).
 		Count(&quickCompletions).Error
 	if err != nil {
@@ -412,7 +596,7 @@ func GetReminderEffectivenessStats(db *gorm.DB, userID common.UserID, since time
 
 	// Total reminders sent
 	var totalReminders int64
-	err = db.Model(&Reminder{}).Where("user_id = ? AND sent_at >= ?", userID, since).Count(&totalReminders).Error
+	err = remindersWithArchive(db).Where("user_id = ? AND sent_at >= ?", userID, since).Count(&totalReminders).Error
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,128 @@
+package nudge
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PlanNode is a node of a PostgreSQL query plan, as EXPLAIN (FORMAT JSON)
+// reports it
+type PlanNode struct {
+	NodeType  string     `json:"Node Type"`
+	Relation  string     `json:"Relation Name"`
+	IndexName string     `json:"Index Name"`
+	Plans     []PlanNode `json:"Plans"`
+}
+
+// UsesIndex reports whether the plan scans the index anywhere
+func (n PlanNode) UsesIndex(index string) bool {
+	if n.IndexName == index {
+		return true
+	}
+	for _, child := range n.Plans {
+		if child.UsesIndex(index) {
+			return true
+		}
+	}
+	return false
+}
+
+// Indexes returns the indexes the plan scans
+func (n PlanNode) Indexes() []string {
+	var indexes []string
+	if n.IndexName != "" {
+		indexes = append(indexes, n.IndexName)
+	}
+	for _, child := range n.Plans {
+		indexes = append(indexes, child.Indexes()...)
+	}
+	return indexes
+}
+
+// indexedQuery is a frequent query and the index it must be able to use
+type indexedQuery struct {
+	name  string
+	index string
+	query func(tx *gorm.DB) *gorm.DB
+}
+
+// indexedQueries returns the queries checked by ValidateQueryPlans
+func indexedQueries() []indexedQuery {
+	return []indexedQuery{
+		// The scheduler runs this on every poll
+		{name: "due reminders", index: "idx_reminders_due_unsent", query: findDueReminders(time.Now())},
+	}
+}
+
+// dueRemindersQuery selects the unsent reminders scheduled at or before before
+func dueRemindersQuery(db *gorm.DB, before time.Time) *ReminderQueryBuilder {
+	return NewQueryBuilder(db).ReminderQuery().
+		WithDueBefore(before).
+		WithUnsent().
+		WithTaskJoin()
+}
+
+// findDueReminders is the statement GetDueReminders runs
+func findDueReminders(before time.Time) func(tx *gorm.DB) *gorm.DB {
+	return func(tx *gorm.DB) *gorm.DB {
+		var reminders []*Reminder
+		return dueRemindersQuery(tx, before).query.Find(&reminders)
+	}
+}
+
+// ExplainDueReminders returns the plan PostgreSQL chooses for the query
+// GetDueReminders runs
+func ExplainDueReminders(db *gorm.DB, before time.Time) (PlanNode, error) {
+	return explain(db, findDueReminders(before))
+}
+
+// ValidateQueryPlans checks that each frequent query can use its index.
+// Fresh or small tables are scanned sequentially whatever the indexes, so
+// sequential scans are ruled out to see whether the index matches the query.
+func ValidateQueryPlans(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SET LOCAL enable_seqscan = off").Error; err != nil {
+			return fmt.Errorf("failed to disable sequential scans: %w", err)
+		}
+
+		for _, indexed := range indexedQueries() {
+			plan, err := explain(tx, indexed.query)
+			if err != nil {
+				return fmt.Errorf("failed to explain %s query: %w", indexed.name, err)
+			}
+			if !plan.UsesIndex(indexed.index) {
+				return fmt.Errorf("%s query does not use index %s, it uses %v", indexed.name, indexed.index, plan.Indexes())
+			}
+		}
+		return nil
+	})
+}
+
+// explain runs EXPLAIN on the statement the query would execute
+func explain(db *gorm.DB, query func(tx *gorm.DB) *gorm.DB) (PlanNode, error) {
+	statement := db.ToSQL(query)
+
+	var output string
+	if err := db.Raw("EXPLAIN (FORMAT JSON) " + statement).Row().Scan(&output); err != nil {
+		return PlanNode{}, err
+	}
+
+	return decodePlan(output)
+}
+
+// decodePlan decodes the output of EXPLAIN (FORMAT JSON)
+func decodePlan(output string) (PlanNode, error) {
+	var plans []struct {
+		Plan PlanNode `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(output), &plans); err != nil {
+		return PlanNode{}, fmt.Errorf("failed to decode query plan: %w", err)
+	}
+	if len(plans) == 0 {
+		return PlanNode{}, fmt.Errorf("query plan is empty")
+	}
+	return plans[0].Plan, nil
+}
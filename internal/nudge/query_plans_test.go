@@ -0,0 +1,44 @@
+package nudge
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestFindDueReminders_MatchesPartialIndexPredicate(t *testing.T) {
+	// A dry run builds SQL without connecting to a database
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+	})
+	require.NoError(t, err)
+
+	// idx_reminders_due_unsent only holds rows WHERE sent_at IS NULL, so the
+	// query must state that condition to be able to use it
+	statement := db.ToSQL(findDueReminders(time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)))
+	assert.Contains(t, statement, "sent_at IS NULL")
+	assert.Contains(t, statement, "scheduled_at <= '2026-01-02 09:00:00'")
+}
+
+func TestDecodePlan(t *testing.T) {
+	output := `[{"Plan": {"Node Type": "Nested Loop", "Plans": [
+		{"Node Type": "Index Scan", "Relation Name": "reminders", "Index Name": "idx_reminders_due_unsent"},
+		{"Node Type": "Index Scan", "Relation Name": "tasks", "Index Name": "tasks_pkey"}
+	]}}]`
+
+	plan, err := decodePlan(output)
+	require.NoError(t, err)
+	assert.Equal(t, "Nested Loop", plan.NodeType)
+	assert.True(t, plan.UsesIndex("idx_reminders_due_unsent"))
+	assert.False(t, plan.UsesIndex("idx_reminders_sent_at"))
+	assert.Equal(t, []string{"idx_reminders_due_unsent", "tasks_pkey"}, plan.Indexes())
+
+	_, err = decodePlan(`[]`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,143 @@
+package nudge
+
+import (
+	"sort"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// maxWelcomeBackTasks is how many of the tasks that fell due while a user was
+// away the welcome back summary lists
+const maxWelcomeBackTasks = 5
+
+// handleBotBlocked marks a user who blocked the bot as inactive, which pauses
+// their reminders through it. Blocking again keeps the time they first went
+// away.
+func (s *nudgeService) handleBotBlocked(event events.BotBlocked) {
+	s = s.forUser(event.UserID)
+	if s.repository == nil {
+		return
+	}
+
+	userID := common.UserID(event.UserID)
+	settings, err := s.repository.GetNudgeSettingsByUserID(userID)
+	if err != nil {
+		s.logger.Error("Failed to load settings of user who blocked the bot",
+			zap.String("correlationID", event.CorrelationID),
+			zap.String("userID", event.UserID),
+			zap.Error(err))
+		return
+	}
+	if settings.BlockedOn(event.Bot) {
+		return
+	}
+
+	if settings.BlockedAt == nil {
+		blockedAt := event.BlockedAt
+		if blockedAt.IsZero() {
+			blockedAt = time.Now()
+		}
+		settings.BlockedAt = &blockedAt
+	}
+	settings.BlockedBot = event.Bot
+	settings.UpdatedAt = time.Now()
+	if err := s.repository.CreateOrUpdateNudgeSettings(settings); err != nil {
+		s.logger.Error("Failed to mark user inactive",
+			zap.String("correlationID", event.CorrelationID),
+			zap.String("userID", event.UserID),
+			zap.Error(err))
+		return
+	}
+
+	s.logger.Info("User blocked the bot, reminders paused", zap.String("userID", event.UserID))
+}
+
+// handleBotUnblocked resumes the reminders of a user who unblocked the bot and
+// welcomes them back with what accumulated while they were away
+func (s *nudgeService) handleBotUnblocked(event events.BotUnblocked) {
+	s = s.forUser(event.UserID)
+	if s.repository == nil {
+		return
+	}
+
+	userID := common.UserID(event.UserID)
+	settings, err := s.repository.GetNudgeSettingsByUserID(userID)
+	if err != nil {
+		s.logger.Error("Failed to load settings of user who unblocked the bot",
+			zap.String("correlationID", event.CorrelationID),
+			zap.String("userID", event.UserID),
+			zap.Error(err))
+		return
+	}
+	if !settings.BlockedOn(event.Bot) {
+		return
+	}
+
+	awaySince := *settings.BlockedAt
+	settings.BlockedAt = nil
+	settings.BlockedBot = ""
+	settings.UpdatedAt = time.Now()
+	if err := s.repository.CreateOrUpdateNudgeSettings(settings); err != nil {
+		s.logger.Error("Failed to mark user active",
+			zap.String("correlationID", event.CorrelationID),
+			zap.String("userID", event.UserID),
+			zap.Error(err))
+		return
+	}
+	s.logger.Info("User unblocked the bot, reminders resumed", zap.String("userID", event.UserID))
+
+	welcome, err := s.welcomeBack(userID, awaySince, time.Now())
+	if err != nil {
+		s.logger.Error("Failed to summarize what the user missed",
+			zap.String("correlationID", event.CorrelationID),
+			zap.String("userID", event.UserID),
+			zap.Error(err))
+		return
+	}
+	welcome.Event = events.NewEvent()
+	welcome.UserID = event.UserID
+	welcome.ChatID = event.ChatID
+
+	if err := s.eventBus.Publish(events.TopicWelcomeBack, *welcome); err != nil {
+		s.logger.Error("Failed to publish WelcomeBack event",
+			zap.String("userID", event.UserID),
+			zap.Error(err))
+	}
+}
+
+// welcomeBack summarizes the user's open tasks as of now: those that fell due
+// and those created since awaySince, and how many are open and overdue
+func (s *nudgeService) welcomeBack(userID common.UserID, awaySince, now time.Time) (*events.WelcomeBack, error) {
+	tasks, err := s.repository.GetTasksByUserID(userID, TaskFilter{UserID: userID, Statuses: common.OpenTaskStatuses()})
+	if err != nil {
+		return nil, err
+	}
+
+	welcome := &events.WelcomeBack{AwaySince: awaySince, Open: len(tasks)}
+	var cameDue []*Task
+	for _, task := range tasks {
+		if task.IsOverdue() {
+			welcome.Overdue++
+		}
+		if task.CreatedAt.After(awaySince) {
+			welcome.Added++
+		}
+		if task.DueDate != nil && task.DueDate.After(awaySince) && !task.DueDate.After(now) {
+			cameDue = append(cameDue, task)
+		}
+	}
+
+	sort.Slice(cameDue, func(i, j int) bool { return cameDue[i].DueDate.Before(*cameDue[j].DueDate) })
+	welcome.CameDueCount = len(cameDue)
+	for i, task := range cameDue {
+		if i == maxWelcomeBackTasks {
+			break
+		}
+		welcome.CameDue = append(welcome.CameDue, summarizeTask(task, ""))
+	}
+	return welcome, nil
+}
@@ -0,0 +1,92 @@
+package nudge
+
+import (
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestNudgeService_PausesUsersWhoBlockTheBot(t *testing.T) {
+	bus := events.NewMockEventBus()
+	bus.SetSynchronousMode(true)
+	logger := zaptest.NewLogger(t)
+	repo := NewMemoryNudgeRepository(logger)
+	_, err := NewNudgeService(bus, logger, repo)
+	require.NoError(t, err)
+
+	userID := common.UserID(common.NewID())
+	chatID := common.ChatID(userID)
+	now := time.Now()
+	blockedAt := now.Add(-20 * time.Hour)
+	// The repository stamps tasks as created now, so earlier ones are backdated
+	memory := repo.(*memoryNudgeRepository)
+	newTask := func(title string, due *time.Time, created time.Time) {
+		task := Task{ID: common.TaskID(common.NewID()), UserID: userID, ChatID: chatID, Title: title,
+			DueDate: due, Priority: common.PriorityMedium, Status: common.TaskStatusActive}
+		require.NoError(t, repo.CreateTask(&task))
+		stored := memory.data.tasks[task.ID]
+		stored.CreatedAt = created
+		memory.data.tasks[task.ID] = stored
+	}
+	dueWhileAway, dueBefore, dueLater := now.Add(-10*time.Hour), now.Add(-22*time.Hour), now.Add(24*time.Hour)
+	newTask("Pay rent", &dueWhileAway, now.Add(-23*time.Hour))
+	newTask("Renew passport", &dueBefore, now.Add(-23*time.Hour))
+	newTask("Call mum", &dueLater, now.Add(-time.Hour))
+
+	require.NoError(t, bus.Publish(events.TopicBotBlocked, events.BotBlocked{
+		Event: events.NewEvent(), UserID: string(userID), ChatID: string(chatID), BlockedAt: blockedAt,
+	}))
+	settings, err := repo.GetNudgeSettingsByUserID(userID)
+	require.NoError(t, err)
+	require.NotNil(t, settings.BlockedAt)
+	assert.True(t, blockedAt.Equal(*settings.BlockedAt))
+
+	// Blocking again keeps the time the user first went away
+	require.NoError(t, bus.Publish(events.TopicBotBlocked, events.BotBlocked{
+		Event: events.NewEvent(), UserID: string(userID), ChatID: string(chatID), BlockedAt: now,
+	}))
+	settings, err = repo.GetNudgeSettingsByUserID(userID)
+	require.NoError(t, err)
+	assert.True(t, blockedAt.Equal(*settings.BlockedAt))
+
+	// Unblocking another bot leaves this one blocked
+	require.NoError(t, bus.Publish(events.TopicBotUnblocked, events.BotUnblocked{
+		Event: events.NewEvent(), UserID: string(userID), ChatID: string(chatID), Bot: "work_bot",
+	}))
+	settings, err = repo.GetNudgeSettingsByUserID(userID)
+	require.NoError(t, err)
+	assert.True(t, settings.BlockedOn(""))
+	assert.False(t, settings.BlockedOn("work_bot"))
+	assert.Empty(t, bus.GetPublishedEvents(events.TopicWelcomeBack))
+
+	require.NoError(t, bus.Publish(events.TopicBotUnblocked, events.BotUnblocked{
+		Event: events.NewEvent(), UserID: string(userID), ChatID: string(chatID),
+	}))
+	settings, err = repo.GetNudgeSettingsByUserID(userID)
+	require.NoError(t, err)
+	assert.Nil(t, settings.BlockedAt)
+
+	published := bus.GetPublishedEvents(events.TopicWelcomeBack)
+	require.Len(t, published, 1)
+	welcome := published[0].(events.WelcomeBack)
+	assert.Equal(t, string(chatID), welcome.ChatID)
+	assert.True(t, blockedAt.Equal(welcome.AwaySince))
+	assert.Equal(t, 1, welcome.CameDueCount)
+	require.Len(t, welcome.CameDue, 1)
+	assert.Equal(t, "Pay rent", welcome.CameDue[0].Title)
+	assert.Equal(t, 1, welcome.Added)
+	assert.Equal(t, 3, welcome.Open)
+	assert.Equal(t, 2, welcome.Overdue)
+
+	// A user who never blocked the bot is not welcomed back
+	require.NoError(t, bus.Publish(events.TopicBotUnblocked, events.BotUnblocked{
+		Event: events.NewEvent(), UserID: string(userID), ChatID: string(chatID),
+	}))
+	assert.Len(t, bus.GetPublishedEvents(events.TopicWelcomeBack), 1)
+}
@@ -1,6 +1,7 @@
 package nudge
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -50,6 +51,20 @@ type NudgeRepository interface {
 	UpdateTask(task *Task) error
 	DeleteTask(taskID common.TaskID) error
 	GetTaskStats(userID common.UserID) (*TaskStats, error)
+	GetOverdueTasks(userID common.UserID) ([]*Task, error)
+	BulkUpdateTaskStatus(taskIDs []common.TaskID, status common.TaskStatus) error
+	// BulkShiftDueDates moves the due dates of the tasks and the unsent reminders
+	// scheduled for them by shift
+	BulkShiftDueDates(taskIDs []common.TaskID, shift time.Duration) error
+	// GetNewlyOverdueTasks returns up to limit open tasks, across users, whose
+	// due date passed before now and that are not yet marked overdue for it
+	GetNewlyOverdueTasks(now time.Time, limit int) ([]*Task, error)
+	// MarkTaskOverdue records that the task is overdue for dueDate. It does
+	// nothing when the task's due date has changed since.
+	MarkTaskOverdue(taskID common.TaskID, dueDate time.Time) error
+	// MoveChatID readdresses every task, reminder and other row of a chat to
+	// another chat, for a group Telegram migrated to a supergroup with a new ID
+	MoveChatID(from, to common.ChatID) error
 
 	// Reminder operations
 	CreateReminder(reminder *Reminder) error
@@ -65,4 +80,9 @@ type NudgeRepository interface {
 
 	// Transaction support
 	WithTransaction(fn func(NudgeRepository) error) error
+
+	// WithContext returns the repository making its calls with ctx, which
+	// carries who they are made for. Tenant scoped repositories scope calls
+	// addressed by task, reminder or chat ID to that caller's tenant.
+	WithContext(ctx context.Context) NudgeRepository
 }
@@ -0,0 +1,177 @@
+package nudge
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// latencyBuckets are the upper bounds of the repository latency histogram
+var latencyBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+}
+
+// recentLatencyWeight is the weight of the newest observation in the moving
+// average returned by RecentLatency
+const recentLatencyWeight = 0.2
+
+// RepositoryMetrics records per-method latency histograms for repository calls
+type RepositoryMetrics struct {
+	mu      sync.RWMutex
+	methods map[string]*methodMetrics
+	recent  time.Duration
+}
+
+// methodMetrics accumulates observations for one repository method
+type methodMetrics struct {
+	count        int64
+	errors       int64
+	slowQueries  int64
+	retries      int64
+	total        time.Duration
+	max          time.Duration
+	bucketCounts []int64 // one per latency bucket plus +Inf
+}
+
+// LatencyBucket is a cumulative histogram bucket
+type LatencyBucket struct {
+	UpperBound string `json:"le"`
+	Count      int64  `json:"count"`
+}
+
+// MethodMetricsSummary summarizes the observations for one repository method
+type MethodMetricsSummary struct {
+	Method         string          `json:"method"`
+	Count          int64           `json:"count"`
+	Errors         int64           `json:"errors"`
+	SlowQueries    int64           `json:"slow_queries"`
+	Retries        int64           `json:"retries"`
+	AverageLatency string          `json:"average_latency"`
+	MaxLatency     string          `json:"max_latency"`
+	Buckets        []LatencyBucket `json:"buckets"`
+}
+
+// NewRepositoryMetrics creates a new metrics instance
+func NewRepositoryMetrics() *RepositoryMetrics {
+	return &RepositoryMetrics{
+		methods: make(map[string]*methodMetrics),
+	}
+}
+
+// Observe records one call of a repository method
+func (m *RepositoryMetrics) Observe(method string, duration time.Duration, failed, slow bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := m.method(method)
+
+	if m.recent == 0 {
+		m.recent = duration
+	} else {
+		m.recent = time.Duration(recentLatencyWeight*float64(duration) + (1-recentLatencyWeight)*float64(m.recent))
+	}
+
+	stats.count++
+	stats.total += duration
+	if duration > stats.max {
+		stats.max = duration
+	}
+	if failed {
+		stats.errors++
+	}
+	if slow {
+		stats.slowQueries++
+	}
+
+	bucket := sort.Search(len(latencyBuckets), func(i int) bool {
+		return duration <= latencyBuckets[i]
+	})
+	stats.bucketCounts[bucket]++
+}
+
+// ObserveRetry records that a call of a repository method was retried after
+// losing its connection
+func (m *RepositoryMetrics) ObserveRetry(method string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.method(method).retries++
+}
+
+// method returns the metrics of a method, creating them on first use; the
+// caller holds mu
+func (m *RepositoryMetrics) method(method string) *methodMetrics {
+	stats, exists := m.methods[method]
+	if !exists {
+		stats = &methodMetrics{bucketCounts: make([]int64, len(latencyBuckets)+1)}
+		m.methods[method] = stats
+	}
+	return stats
+}
+
+// RecentLatency returns a moving average of repository call latency across
+// all methods, weighted towards the latest calls
+func (m *RepositoryMetrics) RecentLatency() time.Duration {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.recent
+}
+
+// Snapshot returns a summary of every observed method sorted by name
+func (m *RepositoryMetrics) Snapshot() []MethodMetricsSummary {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	summaries := make([]MethodMetricsSummary, 0, len(m.methods))
+	for method, stats := range m.methods {
+		summary := MethodMetricsSummary{
+			Method:      method,
+			Count:       stats.count,
+			Errors:      stats.errors,
+			SlowQueries: stats.slowQueries,
+			Retries:     stats.retries,
+			MaxLatency:  stats.max.String(),
+			Buckets:     make([]LatencyBucket, 0, len(stats.bucketCounts)),
+		}
+		if stats.count > 0 {
+			summary.AverageLatency = (stats.total / time.Duration(stats.count)).String()
+		}
+
+		var cumulative int64
+		for i, count := range stats.bucketCounts {
+			cumulative += count
+			upperBound := "+Inf"
+			if i < len(latencyBuckets) {
+				upperBound = latencyBuckets[i].String()
+			}
+			summary.Buckets = append(summary.Buckets, LatencyBucket{UpperBound: upperBound, Count: cumulative})
+		}
+
+		summaries = append(summaries, summary)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Method < summaries[j].Method
+	})
+
+	return summaries
+}
+
+// Reset clears all recorded observations
+func (m *RepositoryMetrics) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.methods = make(map[string]*methodMetrics)
+	m.recent = 0
+}
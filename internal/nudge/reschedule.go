@@ -0,0 +1,128 @@
+package nudge
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"nudgebot-api/internal/common"
+)
+
+// Bulk reschedule commands
+const (
+	RescheduleSnoozeAll = "snoozeall"
+	RescheduleMoveTo    = "moveto"
+)
+
+// MaxBulkSnooze caps how far /snoozeall pushes tasks back
+const MaxBulkSnooze = 7 * 24 * time.Hour
+
+// MaxMoveDays caps how many days ahead /moveto can move tasks
+const MaxMoveDays = 365
+
+// DueShift is how far a bulk reschedule moves due dates: by a fixed Duration,
+// or by whole calendar Days, which keep each task's time of day in the user's
+// timezone across daylight saving changes
+type DueShift struct {
+	Duration time.Duration
+	Days     int
+}
+
+// IsZero reports whether the shift moves nothing
+func (s DueShift) IsZero() bool {
+	return s.Duration == 0 && s.Days == 0
+}
+
+// Apply returns due moved by the shift, counting days in loc
+func (s DueShift) Apply(due time.Time, loc *time.Location) time.Time {
+	if s.Days != 0 {
+		return due.In(loc).AddDate(0, 0, s.Days)
+	}
+	return due.Add(s.Duration)
+}
+
+// RescheduleShift resolves a bulk reschedule command to the shift applied to due
+// dates. Snoozing takes a duration such as "2h", "90m" or "1d". Moving takes
+// "tomorrow", a weekday name or a YYYY-MM-DD date and moves by calendar days;
+// now must be in the user's timezone.
+func RescheduleShift(command, argument string, now time.Time) (DueShift, error) {
+	argument = strings.ToLower(strings.TrimSpace(argument))
+
+	switch command {
+	case RescheduleSnoozeAll:
+		duration, err := parseSnoozeDuration(argument)
+		return DueShift{Duration: duration}, err
+	case RescheduleMoveTo:
+		days, err := daysUntil(argument, now)
+		return DueShift{Days: days}, err
+	default:
+		return DueShift{}, NewTaskValidationError("command", command, "unknown reschedule command")
+	}
+}
+
+// parseSnoozeDuration parses a positive duration up to MaxBulkSnooze; "d" counts whole days
+func parseSnoozeDuration(argument string) (time.Duration, error) {
+	duration, err := common.ParseDuration(argument)
+	if err != nil {
+		return 0, NewTaskValidationError("duration", argument, "use a positive duration such as 30m, 2h or 1d")
+	}
+	if duration > MaxBulkSnooze {
+		return 0, NewTaskValidationError("duration", argument, fmt.Sprintf("must be at most %s", MaxBulkSnooze))
+	}
+	return duration, nil
+}
+
+// daysUntil returns how many calendar days after now's date the target day is
+func daysUntil(argument string, now time.Time) (int, error) {
+	today := civilDate(now)
+
+	var target time.Time
+	switch argument {
+	case "tomorrow":
+		target = today.AddDate(0, 0, 1)
+	default:
+		if weekday, ok := parseWeekday(argument); ok {
+			ahead := (int(weekday) - int(today.Weekday()) + 7) % 7
+			if ahead == 0 {
+				ahead = 7
+			}
+			target = today.AddDate(0, 0, ahead)
+			break
+		}
+
+		date, err := time.Parse("2006-01-02", argument)
+		if err != nil {
+			return 0, NewTaskValidationError("date", argument, "use tomorrow, a weekday or a YYYY-MM-DD date")
+		}
+		target = date
+	}
+
+	days := int(target.Sub(today).Hours() / 24)
+	if days < 1 || days > MaxMoveDays {
+		return 0, NewTaskValidationError("date", argument, fmt.Sprintf("must be between tomorrow and %d days ahead", MaxMoveDays))
+	}
+	return days, nil
+}
+
+// parseWeekday accepts full and three-letter weekday names
+func parseWeekday(name string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		full := strings.ToLower(day.String())
+		if name == full || name == full[:3] {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+// civilDate returns t's calendar date as midnight UTC, so date arithmetic is not
+// affected by daylight saving changes
+func civilDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// dayBounds returns the first and last instant of t's day in t's location
+func dayBounds(t time.Time) (time.Time, time.Time) {
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return start, start.AddDate(0, 0, 1).Add(-time.Microsecond)
+}
@@ -0,0 +1,188 @@
+package nudge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// RetentionForever keeps a user's completed tasks until they delete them
+const RetentionForever = 0
+
+// RetentionOptions are the retention periods in days users can choose
+var RetentionOptions = []int{RetentionForever, 90, 30}
+
+// RetentionPurgeJobName is the name the retention purge runs under on the job scheduler
+const RetentionPurgeJobName = "retention_purge"
+
+// DefaultRetentionPurgeSchedule runs the purge nightly, after the archiver
+const DefaultRetentionPurgeSchedule = "45 3 * * *"
+
+// ValidRetentionDays reports whether days is one of RetentionOptions
+func ValidRetentionDays(days int) bool {
+	for _, option := range RetentionOptions {
+		if days == option {
+			return true
+		}
+	}
+	return false
+}
+
+// expiredTasks matches the completed tasks of a task table kept longer than
+// their user's retention; the table and the purge time fill it in
+const expiredTasks = "SELECT id FROM %[1]s WHERE status = ? AND EXISTS (SELECT 1 FROM nudge_settings WHERE nudge_settings.user_id = %[1]s.user_id " +
+	"AND nudge_settings.retention_days > 0 AND COALESCE(%[1]s.completed_at, %[1]s.updated_at) < ?::timestamp - nudge_settings.retention_days * INTERVAL '1 day')"
+
+// RetentionPurger deletes completed tasks, live and archived, once they are
+// older than their user's retention, with their reminders. Users who keep
+// completed tasks forever are left alone.
+type RetentionPurger struct {
+	db        *gorm.DB
+	logger    *zap.Logger
+	batchSize int
+	now       func() time.Time
+}
+
+// NewRetentionPurger creates a purger deleting at most batchSize rows per statement
+func NewRetentionPurger(db *gorm.DB, logger *zap.Logger, batchSize int) *RetentionPurger {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	return &RetentionPurger{
+		db:        db,
+		logger:    logger,
+		batchSize: batchSize,
+		now:       time.Now,
+	}
+}
+
+// Run purges the expired tasks batch by batch, stopping early when ctx is done
+func (p *RetentionPurger) Run(ctx context.Context) error {
+	now := p.now()
+	db := p.db.WithContext(ctx)
+
+	// Reminders go first so that none is left behind by its purged task
+	var reminders int64
+	for _, table := range []string{"reminders", ReminderArchiveTable} {
+		purged, err := p.drain(ctx, func() (int64, error) {
+			return PurgeExpiredReminders(db, table, now, p.batchSize)
+		})
+		reminders += purged
+		if err != nil {
+			return fmt.Errorf("failed to purge %s: %w", table, err)
+		}
+	}
+
+	var tasks int64
+	for _, table := range []string{"tasks", TaskArchiveTable} {
+		purged, err := p.drain(ctx, func() (int64, error) {
+			return PurgeExpiredTasks(db, table, now, p.batchSize)
+		})
+		tasks += purged
+		if err != nil {
+			return fmt.Errorf("failed to purge %s: %w", table, err)
+		}
+	}
+
+	p.logger.Info("Purged completed tasks past their retention",
+		zap.Int64("tasks", tasks),
+		zap.Int64("reminders", reminders))
+	return nil
+}
+
+// drain runs purge until a batch comes back short
+func (p *RetentionPurger) drain(ctx context.Context, purge func() (int64, error)) (int64, error) {
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		purged, err := purge()
+		total += purged
+		if err != nil || purged < int64(p.batchSize) {
+			return total, err
+		}
+	}
+}
+
+// PurgeExpiredTasks deletes up to limit tasks of a task table that were
+// completed longer ago than their user's retention at now, and returns how
+// many were deleted
+func PurgeExpiredTasks(db *gorm.DB, table string, now time.Time, limit int) (int64, error) {
+	result := deleteExpiredTasks(db, table, now, limit)
+	return result.RowsAffected, result.Error
+}
+
+// deleteExpiredTasks deletes up to limit expired tasks of a task table
+func deleteExpiredTasks(db *gorm.DB, table string, now time.Time, limit int) *gorm.DB {
+	return db.Exec("DELETE FROM "+table+" WHERE id IN ("+fmt.Sprintf(expiredTasks, table)+" LIMIT ?)",
+		common.TaskStatusCompleted, now, limit)
+}
+
+// PurgeExpiredReminders deletes up to limit reminders of a reminder table
+// whose tasks, live or archived, are past their user's retention at now, and
+// returns how many were deleted
+func PurgeExpiredReminders(db *gorm.DB, table string, now time.Time, limit int) (int64, error) {
+	result := deleteExpiredReminders(db, table, now, limit)
+	return result.RowsAffected, result.Error
+}
+
+// deleteExpiredReminders deletes up to limit reminders of expired tasks from
+// a reminder table
+func deleteExpiredReminders(db *gorm.DB, table string, now time.Time, limit int) *gorm.DB {
+	return db.Exec("DELETE FROM "+table+" WHERE id IN (SELECT id FROM "+table+" WHERE task_id IN ("+
+		fmt.Sprintf(expiredTasks, "tasks")+" UNION ALL "+fmt.Sprintf(expiredTasks, TaskArchiveTable)+") LIMIT ?)",
+		common.TaskStatusCompleted, now, common.TaskStatusCompleted, now, limit)
+}
+
+// handleRetentionRequested shows or changes how long the user's completed
+// tasks are kept
+func (s *nudgeService) handleRetentionRequested(event events.RetentionRequested) {
+	s = s.forUser(event.UserID)
+	response := events.RetentionResponse{
+		Event:  events.NewEvent(),
+		UserID: event.UserID,
+		ChatID: event.ChatID,
+	}
+
+	settings, err := s.GetNudgeSettings(common.UserID(event.UserID))
+	switch {
+	case err != nil:
+		s.logger.Error("Failed to load nudge settings for retention",
+			zap.String("correlationID", event.CorrelationID),
+			zap.String("userID", event.UserID),
+			zap.Error(err))
+		response.Message = "Something went wrong, please try again later."
+	case event.Days == nil || *event.Days == settings.RetentionDays:
+		response.Success = true
+		response.Days = settings.RetentionDays
+	default:
+		previous := settings.RetentionDays
+		settings.RetentionDays = *event.Days
+		if err := s.UpdateNudgeSettings(settings); err != nil {
+			response.Days = previous
+			response.Message = "Something went wrong, please try again later."
+			var validationErr TaskValidationError
+			if errors.As(err, &validationErr) {
+				response.Message = "Completed tasks can be kept forever, for 90 days or for 30 days."
+			}
+			break
+		}
+		response.Success = true
+		response.Changed = true
+		response.Days = settings.RetentionDays
+	}
+
+	if err := s.eventBus.Publish(events.TopicRetentionResponse, response); err != nil {
+		s.logger.Error("Failed to publish RetentionResponse event",
+			zap.String("userID", event.UserID),
+			zap.Error(err))
+	}
+}
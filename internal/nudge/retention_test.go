@@ -0,0 +1,86 @@
+package nudge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestValidRetentionDays(t *testing.T) {
+	assert.True(t, ValidRetentionDays(RetentionForever))
+	assert.True(t, ValidRetentionDays(90))
+	assert.True(t, ValidRetentionDays(30))
+	assert.False(t, ValidRetentionDays(7))
+
+	settings := &NudgeSettings{UserID: "user", NudgeInterval: DefaultNudgeInterval, MaxNudges: DefaultMaxNudges, RetentionDays: 45}
+	assert.Error(t, ValidateNudgeSettings(settings))
+}
+
+func TestPurgeExpiredTasks_UsesEachUsersRetention(t *testing.T) {
+	db := openDryRun(t)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	statement := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return deleteExpiredTasks(tx, TaskArchiveTable, now, 100)
+	})
+	assert.Contains(t, statement, "DELETE FROM tasks_archive WHERE id IN (SELECT id FROM tasks_archive WHERE status = 'completed'")
+	assert.Contains(t, statement, "nudge_settings.user_id = tasks_archive.user_id AND nudge_settings.retention_days > 0")
+	assert.Contains(t, statement, "COALESCE(tasks_archive.completed_at, tasks_archive.updated_at) < '2025-01-01 00:00:00'::timestamp - nudge_settings.retention_days * INTERVAL '1 day'")
+	assert.Contains(t, statement, "LIMIT 100")
+
+	statement = db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return deleteExpiredReminders(tx, "reminders", now, 100)
+	})
+	assert.Contains(t, statement, "DELETE FROM reminders WHERE id IN (SELECT id FROM reminders WHERE task_id IN (SELECT id FROM tasks WHERE")
+	assert.Contains(t, statement, "UNION ALL SELECT id FROM tasks_archive WHERE")
+}
+
+func TestRetentionPurger_StopsWhenContextIsDone(t *testing.T) {
+	purger := NewRetentionPurger(openDryRun(t), zap.NewNop(), 0)
+	assert.Equal(t, 500, purger.batchSize)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, purger.Run(ctx), context.Canceled)
+}
+
+func TestNudgeService_ChangesRetention(t *testing.T) {
+	_, repo, eventBus := newBulkTestService(t)
+	eventBus.SetSynchronousMode(true)
+	userID := common.UserID(common.NewID())
+	request := func(days *int) events.RetentionResponse {
+		eventBus.ClearEvents()
+		require.NoError(t, eventBus.Publish(events.TopicRetentionRequested, events.RetentionRequested{
+			Event: events.NewEvent(), UserID: string(userID), ChatID: "12345", Days: days,
+		}))
+		responses := eventBus.GetPublishedEvents(events.TopicRetentionResponse)
+		require.Len(t, responses, 1)
+		return responses[0].(events.RetentionResponse)
+	}
+	days := func(days int) *int { return &days }
+
+	current := request(nil)
+	assert.True(t, current.Success)
+	assert.Equal(t, RetentionForever, current.Days)
+	assert.False(t, current.Changed)
+
+	changed := request(days(30))
+	assert.True(t, changed.Success)
+	assert.True(t, changed.Changed)
+	settings, err := repo.GetNudgeSettingsByUserID(userID)
+	require.NoError(t, err)
+	assert.Equal(t, 30, settings.RetentionDays)
+
+	rejected := request(days(45))
+	assert.False(t, rejected.Success)
+	assert.Equal(t, 30, rejected.Days)
+	assert.Contains(t, rejected.Message, "90 days")
+}
@@ -0,0 +1,233 @@
+package nudge
+
+import (
+	"context"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/database"
+
+	"go.uber.org/zap"
+)
+
+// retryingNudgeRepository decorates a NudgeRepository with retries of calls
+// that failed because the server closed the connection, as PgBouncer or a
+// restarting server does to idle connections
+type retryingNudgeRepository struct {
+	next     NudgeRepository
+	attempts int
+	backoff  time.Duration
+	metrics  *RepositoryMetrics
+	logger   *zap.Logger
+}
+
+// NewRetryingNudgeRepository wraps a repository so that calls failing on a
+// closed connection are retried up to attempts times, waiting backoff before
+// the first retry and twice as long before each further one. Retries are
+// counted on metrics when it is not nil. Zero attempts return the repository
+// unchanged.
+//
+// Inserts carry their primary key, so retrying one that did commit fails as a
+// duplicate instead of inserting twice. Calls that are not idempotent and
+// transactions are only retried when the statement never reached the server.
+func NewRetryingNudgeRepository(next NudgeRepository, attempts int, backoff time.Duration, metrics *RepositoryMetrics, logger *zap.Logger) NudgeRepository {
+	if attempts <= 0 {
+		return next
+	}
+	return &retryingNudgeRepository{
+		next:     next,
+		attempts: attempts,
+		backoff:  backoff,
+		metrics:  metrics,
+		logger:   logger,
+	}
+}
+
+// retry calls call until it succeeds, fails for another reason than a closed
+// connection or runs out of attempts
+func (r *retryingNudgeRepository) retry(method string, idempotent bool, call func() error) error {
+	err := call()
+	delay := r.backoff
+	for attempt := 1; attempt <= r.attempts && r.retryable(err, idempotent); attempt++ {
+		r.logger.Warn("Retrying repository call after losing the database connection",
+			zap.String("method", method),
+			zap.Int("attempt", attempt),
+			zap.Error(err))
+		if r.metrics != nil {
+			r.metrics.ObserveRetry(method)
+		}
+
+		time.Sleep(delay)
+		delay *= 2
+		err = call()
+	}
+	return err
+}
+
+func (r *retryingNudgeRepository) retryable(err error, idempotent bool) bool {
+	if !database.IsConnectionClosed(err) {
+		return false
+	}
+	return idempotent || database.SafeToRetry(err)
+}
+
+// Task operations
+
+func (r *retryingNudgeRepository) CreateTask(task *Task) error {
+	return r.retry("CreateTask", true, func() error {
+		return r.next.CreateTask(task)
+	})
+}
+
+func (r *retryingNudgeRepository) GetTaskByID(taskID common.TaskID) (task *Task, err error) {
+	err = r.retry("GetTaskByID", true, func() error {
+		task, err = r.next.GetTaskByID(taskID)
+		return err
+	})
+	return task, err
+}
+
+func (r *retryingNudgeRepository) GetTasksByUserID(userID common.UserID, filter TaskFilter) (tasks []*Task, err error) {
+	err = r.retry("GetTasksByUserID", true, func() error {
+		tasks, err = r.next.GetTasksByUserID(userID, filter)
+		return err
+	})
+	return tasks, err
+}
+
+func (r *retryingNudgeRepository) UpdateTask(task *Task) error {
+	return r.retry("UpdateTask", true, func() error {
+		return r.next.UpdateTask(task)
+	})
+}
+
+func (r *retryingNudgeRepository) DeleteTask(taskID common.TaskID) error {
+	return r.retry("DeleteTask", true, func() error {
+		return r.next.DeleteTask(taskID)
+	})
+}
+
+func (r *retryingNudgeRepository) GetTaskStats(userID common.UserID) (stats *TaskStats, err error) {
+	err = r.retry("GetTaskStats", true, func() error {
+		stats, err = r.next.GetTaskStats(userID)
+		return err
+	})
+	return stats, err
+}
+
+func (r *retryingNudgeRepository) GetOverdueTasks(userID common.UserID) (tasks []*Task, err error) {
+	err = r.retry("GetOverdueTasks", true, func() error {
+		tasks, err = r.next.GetOverdueTasks(userID)
+		return err
+	})
+	return tasks, err
+}
+
+func (r *retryingNudgeRepository) BulkUpdateTaskStatus(taskIDs []common.TaskID, status common.TaskStatus) error {
+	return r.retry("BulkUpdateTaskStatus", true, func() error {
+		return r.next.BulkUpdateTaskStatus(taskIDs, status)
+	})
+}
+
+// BulkShiftDueDates adds to the due dates, so applying it twice would shift them twice
+func (r *retryingNudgeRepository) BulkShiftDueDates(taskIDs []common.TaskID, shift time.Duration) error {
+	return r.retry("BulkShiftDueDates", false, func() error {
+		return r.next.BulkShiftDueDates(taskIDs, shift)
+	})
+}
+
+// MoveChatID moves rows still addressed to from, so applying it twice is harmless
+func (r *retryingNudgeRepository) MoveChatID(from, to common.ChatID) error {
+	return r.retry("MoveChatID", true, func() error {
+		return r.next.MoveChatID(from, to)
+	})
+}
+
+func (r *retryingNudgeRepository) GetNewlyOverdueTasks(now time.Time, limit int) (tasks []*Task, err error) {
+	err = r.retry("GetNewlyOverdueTasks", true, func() error {
+		tasks, err = r.next.GetNewlyOverdueTasks(now, limit)
+		return err
+	})
+	return tasks, err
+}
+
+func (r *retryingNudgeRepository) MarkTaskOverdue(taskID common.TaskID, dueDate time.Time) error {
+	return r.retry("MarkTaskOverdue", true, func() error {
+		return r.next.MarkTaskOverdue(taskID, dueDate)
+	})
+}
+
+// Reminder operations
+
+func (r *retryingNudgeRepository) CreateReminder(reminder *Reminder) error {
+	return r.retry("CreateReminder", true, func() error {
+		return r.next.CreateReminder(reminder)
+	})
+}
+
+func (r *retryingNudgeRepository) GetDueReminders(before time.Time) (reminders []*Reminder, err error) {
+	err = r.retry("GetDueReminders", true, func() error {
+		reminders, err = r.next.GetDueReminders(before)
+		return err
+	})
+	return reminders, err
+}
+
+func (r *retryingNudgeRepository) MarkReminderSent(reminderID common.ID) error {
+	return r.retry("MarkReminderSent", true, func() error {
+		return r.next.MarkReminderSent(reminderID)
+	})
+}
+
+func (r *retryingNudgeRepository) GetRemindersByTaskID(taskID common.TaskID) (reminders []*Reminder, err error) {
+	err = r.retry("GetRemindersByTaskID", true, func() error {
+		reminders, err = r.next.GetRemindersByTaskID(taskID)
+		return err
+	})
+	return reminders, err
+}
+
+func (r *retryingNudgeRepository) DeleteReminder(reminderID common.ID) error {
+	return r.retry("DeleteReminder", true, func() error {
+		return r.next.DeleteReminder(reminderID)
+	})
+}
+
+// Nudge settings operations
+
+func (r *retryingNudgeRepository) GetNudgeSettingsByUserID(userID common.UserID) (settings *NudgeSettings, err error) {
+	err = r.retry("GetNudgeSettingsByUserID", true, func() error {
+		settings, err = r.next.GetNudgeSettingsByUserID(userID)
+		return err
+	})
+	return settings, err
+}
+
+func (r *retryingNudgeRepository) CreateOrUpdateNudgeSettings(settings *NudgeSettings) error {
+	return r.retry("CreateOrUpdateNudgeSettings", true, func() error {
+		return r.next.CreateOrUpdateNudgeSettings(settings)
+	})
+}
+
+func (r *retryingNudgeRepository) DeleteNudgeSettings(userID common.UserID) error {
+	return r.retry("DeleteNudgeSettings", true, func() error {
+		return r.next.DeleteNudgeSettings(userID)
+	})
+}
+
+// Transaction support
+
+// WithContext retries the calls made with ctx like the repository's own
+func (r *retryingNudgeRepository) WithContext(ctx context.Context) NudgeRepository {
+	return NewRetryingNudgeRepository(r.next.WithContext(ctx), r.attempts, r.backoff, r.metrics, r.logger)
+}
+
+// WithTransaction retries the whole transaction only when it failed before
+// reaching the server; a connection lost during commit leaves unknown whether
+// it committed. Calls inside the transaction are not retried, as its
+// connection is gone.
+func (r *retryingNudgeRepository) WithTransaction(fn func(NudgeRepository) error) error {
+	return r.retry("WithTransaction", false, func() error {
+		return r.next.WithTransaction(fn)
+	})
+}
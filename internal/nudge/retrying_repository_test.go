@@ -0,0 +1,94 @@
+package nudge
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// droppingRepository fails the first calls as if the server closed the connection
+type droppingRepository struct {
+	NudgeRepository
+	failures int
+	calls    int
+}
+
+func (r *droppingRepository) drop() error {
+	r.calls++
+	if r.calls <= r.failures {
+		return WrapRepositoryError(errors.New("FATAL: server closed the connection unexpectedly"), "query")
+	}
+	return nil
+}
+
+func (r *droppingRepository) GetTaskByID(taskID common.TaskID) (*Task, error) {
+	if err := r.drop(); err != nil {
+		return nil, err
+	}
+	return r.NudgeRepository.GetTaskByID(taskID)
+}
+
+func (r *droppingRepository) BulkShiftDueDates(taskIDs []common.TaskID, shift time.Duration) error {
+	if err := r.drop(); err != nil {
+		return err
+	}
+	return r.NudgeRepository.BulkShiftDueDates(taskIDs, shift)
+}
+
+func TestRetryingNudgeRepository_RetriesClosedConnections(t *testing.T) {
+	memory := NewMemoryNudgeRepository(zap.NewNop())
+	task := &Task{ID: common.TaskID(common.NewID()), UserID: common.UserID(common.NewID()), Title: "Renew passport", Priority: common.PriorityMedium, Status: common.TaskStatusActive}
+	require.NoError(t, memory.CreateTask(task))
+
+	dropping := &droppingRepository{NudgeRepository: memory, failures: 2}
+	metrics := NewRepositoryMetrics()
+	repo := NewRetryingNudgeRepository(dropping, 2, time.Millisecond, metrics, zap.NewNop())
+
+	found, err := repo.GetTaskByID(task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, task.Title, found.Title)
+	assert.Equal(t, 3, dropping.calls)
+
+	summaries := metrics.Snapshot()
+	require.Len(t, summaries, 1)
+	assert.Equal(t, "GetTaskByID", summaries[0].Method)
+	assert.Equal(t, int64(2), summaries[0].Retries)
+}
+
+func TestRetryingNudgeRepository_GivesUpAfterAttempts(t *testing.T) {
+	dropping := &droppingRepository{NudgeRepository: NewMemoryNudgeRepository(zap.NewNop()), failures: 5}
+	repo := NewRetryingNudgeRepository(dropping, 2, time.Millisecond, nil, zap.NewNop())
+
+	_, err := repo.GetTaskByID("task")
+	assert.Error(t, err)
+	assert.Equal(t, 3, dropping.calls)
+}
+
+func TestRetryingNudgeRepository_DoesNotRepeatShifts(t *testing.T) {
+	dropping := &droppingRepository{NudgeRepository: NewMemoryNudgeRepository(zap.NewNop()), failures: 1}
+	repo := NewRetryingNudgeRepository(dropping, 2, time.Millisecond, nil, zap.NewNop())
+
+	// The shift may have been applied before the connection closed
+	assert.Error(t, repo.BulkShiftDueDates([]common.TaskID{"task"}, time.Hour))
+	assert.Equal(t, 1, dropping.calls)
+}
+
+func TestRetryingNudgeRepository_OtherErrorsAreNotRetried(t *testing.T) {
+	dropping := &droppingRepository{NudgeRepository: NewMemoryNudgeRepository(zap.NewNop())}
+	repo := NewRetryingNudgeRepository(dropping, 2, time.Millisecond, nil, zap.NewNop())
+
+	_, err := repo.GetTaskByID("missing")
+	assert.Error(t, err)
+	assert.Equal(t, 1, dropping.calls)
+}
+
+func TestNewRetryingNudgeRepository_ZeroAttemptsReturnsRepository(t *testing.T) {
+	memory := NewMemoryNudgeRepository(zap.NewNop())
+	assert.Same(t, memory, NewRetryingNudgeRepository(memory, 0, 0, nil, zap.NewNop()))
+}
@@ -0,0 +1,149 @@
+package nudge
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// maxScheduledMessageLength bounds scheduled messages to what fits in one Telegram message
+const maxScheduledMessageLength = 4000
+
+// ScheduledMessage is a note the bot sends to a chat at a given time, as
+// asked for with "send me X at 5pm". Unlike a task it is never completed,
+// snoozed or nudged about; it is delivered once.
+type ScheduledMessage struct {
+	ID        common.ID     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID  string        `json:"tenant_id,omitempty" gorm:"type:varchar(64);not null;default:'default';index"`
+	UserID    common.UserID `json:"user_id" gorm:"type:varchar(36);not null;index"`
+	ChatID    common.ChatID `json:"chat_id" gorm:"type:varchar(36);not null"`
+	ThreadID  int           `json:"thread_id,omitempty" gorm:"not null;default:0"`
+	Text      string        `json:"text" gorm:"type:text;not null"`
+	SendAt    time.Time     `json:"send_at" gorm:"type:timestamp;not null;index"`
+	SentAt    *time.Time    `json:"sent_at,omitempty" gorm:"type:timestamp;index"`
+	CreatedAt time.Time     `json:"created_at" gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+}
+
+// TableName returns the table name for the ScheduledMessage model
+func (ScheduledMessage) TableName() string {
+	return "scheduled_messages"
+}
+
+// ScheduledMessageService stores messages users ask to be sent later. They
+// are delivered by the scheduler's scheduled message job.
+type ScheduledMessageService interface {
+	Schedule(message *ScheduledMessage) error
+	Ready() <-chan struct{}
+}
+
+// scheduledMessageService implements the ScheduledMessageService interface
+type scheduledMessageService struct {
+	eventBus   events.EventBus
+	logger     *zap.Logger
+	repository ScheduledMessageRepository
+	ready      *common.Readiness
+}
+
+// NewScheduledMessageService creates a ScheduledMessageService
+func NewScheduledMessageService(eventBus events.EventBus, logger *zap.Logger, repository ScheduledMessageRepository) ScheduledMessageService {
+	service := &scheduledMessageService{
+		eventBus:   eventBus,
+		logger:     logger,
+		repository: repository,
+		ready:      common.NewReadiness(),
+	}
+
+	service.setupEventSubscriptions()
+
+	return service
+}
+
+// setupEventSubscriptions sets up event subscriptions for the scheduled message service
+func (s *scheduledMessageService) setupEventSubscriptions() {
+	if err := s.eventBus.Subscribe(events.TopicTaskParsed, s.handleTaskParsed); err != nil {
+		s.logger.Error("Failed to subscribe to TaskParsed events", zap.Error(err))
+	}
+
+	s.ready.MarkReady()
+}
+
+// Ready returns a channel that is closed once event subscriptions are registered
+func (s *scheduledMessageService) Ready() <-chan struct{} {
+	return s.ready.Ready()
+}
+
+// Schedule stores a message to be sent at its SendAt time. A time that has
+// already passed sends the message on the job's next run.
+func (s *scheduledMessageService) Schedule(message *ScheduledMessage) error {
+	message.Text = strings.TrimSpace(message.Text)
+	if message.Text == "" || utf8.RuneCountInString(message.Text) > maxScheduledMessageLength {
+		return NewTaskValidationError("text", message.Text, fmt.Sprintf("message must be 1 to %d characters", maxScheduledMessageLength))
+	}
+	if message.SendAt.IsZero() {
+		return NewTaskValidationError("send_at", message.SendAt, "a time to send the message is required")
+	}
+	if message.ID == "" {
+		message.ID = common.NewID()
+	}
+
+	if err := s.repository.CreateScheduledMessage(message); err != nil {
+		return err
+	}
+
+	s.logger.Info("Message scheduled",
+		zap.String("messageID", string(message.ID)),
+		zap.String("userID", string(message.UserID)),
+		zap.Time("sendAt", message.SendAt))
+	return nil
+}
+
+// handleTaskParsed stores the parses of "send me X at 5pm" in a TaskParsed
+// event; the nudge service creates tasks from the rest
+func (s *scheduledMessageService) handleTaskParsed(event events.TaskParsed) {
+	// Previewed parses are stored once the user confirms the draft
+	if event.Preview {
+		return
+	}
+
+	for _, parsedTask := range event.AllTasks() {
+		if !parsedTask.IsScheduledMessage() {
+			continue
+		}
+
+		message := &ScheduledMessage{
+			UserID:   common.UserID(event.UserID),
+			ChatID:   common.ChatID(event.ChatID),
+			ThreadID: event.ThreadID,
+			Text:     parsedTask.Title,
+			SendAt:   *parsedTask.DueDate,
+		}
+		if err := s.Schedule(message); err != nil {
+			s.logger.Error("Failed to schedule message",
+				zap.String("correlationID", event.CorrelationID),
+				zap.String("userID", event.UserID),
+				zap.Error(err))
+			continue
+		}
+
+		created := events.ScheduledMessageCreated{
+			Event:     events.NewEvent(),
+			MessageID: string(message.ID),
+			UserID:    string(message.UserID),
+			ChatID:    string(message.ChatID),
+			ThreadID:  message.ThreadID,
+			Text:      message.Text,
+			SendAt:    message.SendAt,
+		}
+		created.CorrelationID = event.CorrelationID
+
+		if err := s.eventBus.Publish(events.TopicScheduledMessageCreated, created); err != nil {
+			s.logger.Error("Failed to publish ScheduledMessageCreated event", zap.Error(err))
+		}
+	}
+}
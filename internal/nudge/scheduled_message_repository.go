@@ -0,0 +1,132 @@
+package nudge
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"nudgebot-api/internal/common"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ScheduledMessageRepository persists messages to be sent later
+type ScheduledMessageRepository interface {
+	CreateScheduledMessage(message *ScheduledMessage) error
+	// GetDueScheduledMessages returns up to limit unsent messages whose send
+	// time is not after now, earliest first
+	GetDueScheduledMessages(now time.Time, limit int) ([]*ScheduledMessage, error)
+	MarkScheduledMessageSent(messageID common.ID, sentAt time.Time) error
+}
+
+// gormScheduledMessageRepository implements ScheduledMessageRepository using GORM
+type gormScheduledMessageRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewGormScheduledMessageRepository creates a new GORM-based scheduled message repository
+func NewGormScheduledMessageRepository(db *gorm.DB, logger *zap.Logger) ScheduledMessageRepository {
+	return &gormScheduledMessageRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreateScheduledMessage stores a new scheduled message
+func (r *gormScheduledMessageRepository) CreateScheduledMessage(message *ScheduledMessage) error {
+	message.CreatedAt = time.Now()
+	if err := r.db.Create(message).Error; err != nil {
+		return WrapRepositoryError(err, "create scheduled message")
+	}
+	return nil
+}
+
+// GetDueScheduledMessages retrieves unsent messages that are due
+func (r *gormScheduledMessageRepository) GetDueScheduledMessages(now time.Time, limit int) ([]*ScheduledMessage, error) {
+	var messages []*ScheduledMessage
+	err := r.db.
+		Where("sent_at IS NULL AND send_at <= ?", now).
+		Order("send_at ASC").
+		Limit(limit).
+		Find(&messages).Error
+	if err != nil {
+		return nil, WrapRepositoryError(err, "get due scheduled messages")
+	}
+	return messages, nil
+}
+
+// MarkScheduledMessageSent records that a message was delivered
+func (r *gormScheduledMessageRepository) MarkScheduledMessageSent(messageID common.ID, sentAt time.Time) error {
+	result := r.db.Model(&ScheduledMessage{}).
+		Where("id = ?", messageID).
+		Update("sent_at", sentAt)
+	if result.Error != nil {
+		return WrapRepositoryError(result.Error, "mark scheduled message sent")
+	}
+	if result.RowsAffected == 0 {
+		return common.NotFoundError{Resource: "ScheduledMessage", ID: string(messageID)}
+	}
+
+	r.logger.Debug("Scheduled message marked sent", zap.String("messageID", string(messageID)))
+	return nil
+}
+
+// memoryScheduledMessageRepository implements ScheduledMessageRepository in memory
+type memoryScheduledMessageRepository struct {
+	mu       sync.RWMutex
+	messages map[common.ID]*ScheduledMessage
+}
+
+// NewMemoryScheduledMessageRepository creates a ScheduledMessageRepository that is not persisted
+func NewMemoryScheduledMessageRepository() ScheduledMessageRepository {
+	return &memoryScheduledMessageRepository{
+		messages: make(map[common.ID]*ScheduledMessage),
+	}
+}
+
+// CreateScheduledMessage stores a new scheduled message
+func (r *memoryScheduledMessageRepository) CreateScheduledMessage(message *ScheduledMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	message.CreatedAt = time.Now()
+	messageCopy := *message
+	r.messages[message.ID] = &messageCopy
+	return nil
+}
+
+// GetDueScheduledMessages retrieves unsent messages that are due
+func (r *memoryScheduledMessageRepository) GetDueScheduledMessages(now time.Time, limit int) ([]*ScheduledMessage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var messages []*ScheduledMessage
+	for _, message := range r.messages {
+		if message.SentAt == nil && !message.SendAt.After(now) {
+			messageCopy := *message
+			messages = append(messages, &messageCopy)
+		}
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].SendAt.Before(messages[j].SendAt)
+	})
+	if len(messages) > limit {
+		messages = messages[:limit]
+	}
+	return messages, nil
+}
+
+// MarkScheduledMessageSent records that a message was delivered
+func (r *memoryScheduledMessageRepository) MarkScheduledMessageSent(messageID common.ID, sentAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	message, ok := r.messages[messageID]
+	if !ok {
+		return common.NotFoundError{Resource: "ScheduledMessage", ID: string(messageID)}
+	}
+	message.SentAt = &sentAt
+	return nil
+}
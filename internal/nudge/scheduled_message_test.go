@@ -0,0 +1,71 @@
+package nudge
+
+import (
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestScheduledMessageService_StoresMessagesInsteadOfTasks(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	eventBus := events.NewMockEventBus()
+	eventBus.SetSynchronousMode(true)
+	messages := NewMemoryScheduledMessageRepository()
+	NewScheduledMessageService(eventBus, logger, messages)
+	tasks := NewMemoryNudgeRepository(logger)
+	_, err := NewNudgeService(eventBus, logger, tasks)
+	require.NoError(t, err)
+	userID := common.UserID(common.NewID())
+
+	sendAt := time.Now().Add(-time.Minute)
+	parsed := events.TaskParsed{
+		Event:  events.NewEvent(),
+		UserID: string(userID),
+		ChatID: "12345",
+		ParsedTasks: []events.ParsedTask{
+			{Title: "The gate code is 1234", DueDate: &sendAt, Priority: "medium", Kind: events.ParsedKindMessage},
+			{Title: "Send me the report", Priority: "medium", Kind: events.ParsedKindMessage},
+		},
+		ThreadID: 7,
+	}
+	parsed.ParsedTask = parsed.ParsedTasks[0]
+	require.NoError(t, eventBus.Publish(events.TopicTaskParsed, parsed))
+
+	due, err := messages.GetDueScheduledMessages(time.Now(), 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, "The gate code is 1234", due[0].Text)
+	assert.Equal(t, common.ChatID("12345"), due[0].ChatID)
+	assert.Equal(t, 7, due[0].ThreadID)
+
+	created := eventBus.GetPublishedEvents(events.TopicScheduledMessageCreated)
+	require.Len(t, created, 1)
+	assert.Equal(t, string(due[0].ID), created[0].(events.ScheduledMessageCreated).MessageID)
+
+	// A message without a time to send it is kept as a task
+	stored, err := tasks.GetTasksByUserID(userID, TaskFilter{UserID: userID})
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	assert.Equal(t, "Send me the report", stored[0].Title)
+}
+
+func TestScheduledMessageService_Schedule(t *testing.T) {
+	service := NewScheduledMessageService(events.NewMockEventBus(), zaptest.NewLogger(t), NewMemoryScheduledMessageRepository())
+
+	var validationErr TaskValidationError
+	err := service.Schedule(&ScheduledMessage{UserID: "user", ChatID: "chat", Text: "  ", SendAt: time.Now()})
+	assert.ErrorAs(t, err, &validationErr)
+	err = service.Schedule(&ScheduledMessage{UserID: "user", ChatID: "chat", Text: "Hello"})
+	assert.ErrorAs(t, err, &validationErr)
+
+	message := &ScheduledMessage{UserID: "user", ChatID: "chat", Text: " Hello ", SendAt: time.Now()}
+	require.NoError(t, service.Schedule(message))
+	assert.NotEmpty(t, message.ID)
+	assert.Equal(t, "Hello", message.Text)
+}
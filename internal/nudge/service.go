@@ -1,12 +1,20 @@
 package nudge
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"nudgebot-api/internal/common"
 	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/geocode"
+	"nudgebot-api/internal/moderation"
+	"nudgebot-api/internal/tenant"
+	"nudgebot-api/internal/user"
 
 	"go.uber.org/zap"
 )
@@ -14,6 +22,7 @@ import (
 // NudgeService defines the interface for nudge operations
 type NudgeService interface {
 	CreateTask(task *Task) error
+	CreateTasks(tasks []*Task) error
 	GetTasks(userID common.UserID, filter TaskFilter) ([]*Task, error)
 	UpdateTaskStatus(taskID common.TaskID, status common.TaskStatus) error
 	DeleteTask(taskID common.TaskID) error
@@ -26,9 +35,21 @@ type NudgeService interface {
 	SnoozeTask(taskID common.TaskID, snoozeUntil time.Time) error
 	GetOverdueTasks(userID common.UserID) ([]*Task, error)
 	BulkUpdateStatus(taskIDs []common.TaskID, status common.TaskStatus) error
+	FireTestReminder(taskID common.TaskID, chatID common.ChatID) error
+	SetCustomField(taskID common.TaskID, key string, field CustomField) (*Task, error)
+	RemoveCustomField(taskID common.TaskID, key string) (*Task, error)
+	GetTasksDueToday(userID common.UserID) ([]*Task, error)
+	ShiftTaskDueDates(userID common.UserID, taskIDs []common.TaskID, shift DueShift) ([]*Task, error)
+	GetTask(userID common.UserID, taskID common.TaskID) (*Task, error)
+	EditTask(taskID common.TaskID, edit TaskEdit) (*Task, error)
 
 	// Health check methods
 	CheckSubscriptionHealth() error
+	Ready() <-chan struct{}
+	// WithContext returns the service acting for the caller ctx carries; see
+	// NudgeRepository.WithContext
+	WithContext(ctx context.Context) NudgeService
+	common.Service
 }
 
 // nudgeService implements the NudgeService interface
@@ -39,14 +60,71 @@ type nudgeService struct {
 	validator       *TaskValidator
 	reminderManager *ReminderManager
 	statusManager   *TaskStatusManager
-
-	// Subscription tracking
+	moderation      *moderation.Policy
+	workspaces      WorkspaceService
+	lists           SharedListService
+	users           user.Repository
+	conflicts       *ConflictChecker
+	geocoder        geocode.Geocoder
+	history         HistoryService
+
+	// Subscription tracking, shared with the services WithContext returns
 	subscriptions map[string]bool
-	mu            sync.RWMutex
+	mu            *sync.RWMutex
+	ready         *common.Readiness
+
+	// Reminder bookkeeping runs in the background; Stop waits for it
+	background *sync.WaitGroup
+	stopped    *atomic.Bool
 }
 
 // NewNudgeService creates a new instance of NudgeService
 func NewNudgeService(eventBus events.EventBus, logger *zap.Logger, repository NudgeRepository) (NudgeService, error) {
+	return NewNudgeServiceWithModeration(eventBus, logger, repository, nil)
+}
+
+// NewNudgeServiceWithModeration creates a NudgeService that moderates parsed tasks before storing them.
+// A nil policy allows all content.
+func NewNudgeServiceWithModeration(eventBus events.EventBus, logger *zap.Logger, repository NudgeRepository, policy *moderation.Policy) (NudgeService, error) {
+	return NewNudgeServiceWithWorkspaces(eventBus, logger, repository, policy, nil)
+}
+
+// NewNudgeServiceWithWorkspaces creates a NudgeService that lets workspace
+// members act on each other's tasks as far as their role allows. A nil
+// workspace service limits every user to their own tasks.
+func NewNudgeServiceWithWorkspaces(eventBus events.EventBus, logger *zap.Logger, repository NudgeRepository, policy *moderation.Policy, workspaces WorkspaceService) (NudgeService, error) {
+	return NewNudgeServiceWithLists(eventBus, logger, repository, policy, workspaces, nil)
+}
+
+// NewNudgeServiceWithLists creates a NudgeService that stores tasks in shared
+// lists, shows them to every member and tells members when one of them
+// completes a task. A nil list service keeps every task personal.
+func NewNudgeServiceWithLists(eventBus events.EventBus, logger *zap.Logger, repository NudgeRepository, policy *moderation.Policy, workspaces WorkspaceService, lists SharedListService) (NudgeService, error) {
+	return NewNudgeServiceWithDelegation(eventBus, logger, repository, policy, workspaces, lists, nil)
+}
+
+// NewNudgeServiceWithDelegation creates a NudgeService that lets users hand
+// tasks off to other users of the same bot, found by username in users. A nil
+// user repository turns delegation requests down.
+func NewNudgeServiceWithDelegation(eventBus events.EventBus, logger *zap.Logger, repository NudgeRepository, policy *moderation.Policy, workspaces WorkspaceService, lists SharedListService, users user.Repository) (NudgeService, error) {
+	return NewNudgeServiceWithConflicts(eventBus, logger, repository, policy, workspaces, lists, users, DefaultConflictTolerance)
+}
+
+// NewNudgeServiceWithConflicts creates a NudgeService that warns about new
+// tasks due within conflictTolerance of another of the user's timed tasks
+func NewNudgeServiceWithConflicts(eventBus events.EventBus, logger *zap.Logger, repository NudgeRepository, policy *moderation.Policy, workspaces WorkspaceService, lists SharedListService, users user.Repository, conflictTolerance time.Duration) (NudgeService, error) {
+	return NewNudgeServiceWithGeocoder(eventBus, logger, repository, policy, workspaces, lists, users, conflictTolerance, nil)
+}
+
+// NewNudgeServiceWithGeocoder creates a NudgeService that looks up the places
+// tasks mention with geocoder; nil leaves locations as the user wrote them
+func NewNudgeServiceWithGeocoder(eventBus events.EventBus, logger *zap.Logger, repository NudgeRepository, policy *moderation.Policy, workspaces WorkspaceService, lists SharedListService, users user.Repository, conflictTolerance time.Duration, geocoder geocode.Geocoder) (NudgeService, error) {
+	return NewNudgeServiceWithHistory(eventBus, logger, repository, policy, workspaces, lists, users, conflictTolerance, geocoder, nil)
+}
+
+// NewNudgeServiceWithHistory creates a NudgeService that summarizes a task's
+// history from history in its detail view; nil leaves the summary out
+func NewNudgeServiceWithHistory(eventBus events.EventBus, logger *zap.Logger, repository NudgeRepository, policy *moderation.Policy, workspaces WorkspaceService, lists SharedListService, users user.Repository, conflictTolerance time.Duration, geocoder geocode.Geocoder, history HistoryService) (NudgeService, error) {
 	if repository == nil {
 		logger.Warn("NudgeService initialized with nil repository - using mock behavior")
 	}
@@ -58,8 +136,18 @@ func NewNudgeService(eventBus events.EventBus, logger *zap.Logger, repository Nu
 		validator:       NewTaskValidator(),
 		reminderManager: NewReminderManager(),
 		statusManager:   NewTaskStatusManager(),
+		moderation:      policy,
+		workspaces:      workspaces,
+		lists:           lists,
+		users:           users,
+		conflicts:       NewConflictChecker(repository, conflictTolerance),
+		geocoder:        geocoder,
+		history:         history,
 		subscriptions:   make(map[string]bool),
-		mu:              sync.RWMutex{},
+		mu:              &sync.RWMutex{},
+		ready:           common.NewReadiness(),
+		background:      &sync.WaitGroup{},
+		stopped:         &atomic.Bool{},
 	}
 
 	// Subscribe to relevant events with retry logic
@@ -77,6 +165,20 @@ func (s *nudgeService) setupEventSubscriptions() error {
 		events.TopicTaskParsed:          s.handleTaskParsed,
 		events.TopicTaskListRequested:   s.handleTaskListRequested,
 		events.TopicTaskActionRequested: s.handleTaskActionRequested,
+
+		events.TopicTaskFieldUpdateRequested: s.handleTaskFieldUpdateRequested,
+		events.TopicTaskRescheduleRequested:  s.handleTaskRescheduleRequested,
+		events.TopicHabitMissed:              s.handleHabitMissed,
+		events.TopicTaskDelegationRequested:  s.handleTaskDelegationRequested,
+		events.TopicTaskDelegationReplied:    s.handleTaskDelegationReplied,
+		events.TopicTaskOriginalRequested:    s.handleTaskOriginalRequested,
+		events.TopicTaskDetailsRequested:     s.handleTaskDetailsRequested,
+		events.TopicRetentionRequested:       s.handleRetentionRequested,
+		events.TopicSettingsRequested:        s.handleSettingsRequested,
+		events.TopicBroadcastRequested:       s.handleBroadcastRequested,
+		events.TopicChatMigrated:             s.handleChatMigrated,
+		events.TopicBotBlocked:               s.handleBotBlocked,
+		events.TopicBotUnblocked:             s.handleBotUnblocked,
 	}
 
 	maxRetries := 3
@@ -107,9 +209,87 @@ func (s *nudgeService) setupEventSubscriptions() error {
 	}
 
 	s.logger.Info("All event subscriptions established successfully")
+	s.ready.MarkReady()
 	return nil
 }
 
+// Ready returns a channel that is closed once all required subscriptions are registered
+func (s *nudgeService) Ready() <-chan struct{} {
+	return s.ready.Ready()
+}
+
+// WithContext returns the service making its repository calls for the caller
+// ctx carries, so tasks and reminders addressed by ID are only found in the
+// caller's tenant
+func (s *nudgeService) WithContext(ctx context.Context) NudgeService {
+	return s.withContext(ctx)
+}
+
+// withContext returns a copy of the service whose repository calls are made
+// with ctx; the copy shares the subscriptions and background work
+func (s *nudgeService) withContext(ctx context.Context) *nudgeService {
+	if s.repository == nil {
+		return s
+	}
+	scoped := *s
+	scoped.repository = s.repository.WithContext(ctx)
+	return &scoped
+}
+
+// forUser returns the service acting for the user, as event handlers do for
+// the user who sent the request
+func (s *nudgeService) forUser(userID string) *nudgeService {
+	return s.withContext(tenant.WithUser(context.Background(), common.UserID(userID)))
+}
+
+// forTask returns the service acting in the task's tenant, for work done in
+// the background once the task is stored
+func (s *nudgeService) forTask(task *Task) *nudgeService {
+	return s.withContext(tenant.WithID(tenant.WithUser(context.Background(), task.UserID), task.TenantID))
+}
+
+// Start marks the service as running; subscriptions are registered by the constructor
+func (s *nudgeService) Start(ctx context.Context) error {
+	s.stopped.Store(false)
+	return nil
+}
+
+// Stop waits for background reminder scheduling and cancellation to finish
+func (s *nudgeService) Stop(ctx context.Context) error {
+	s.stopped.Store(true)
+
+	done := make(chan struct{})
+	go func() {
+		s.background.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.logger.Warn("Nudge service stopped before background work finished")
+		return ctx.Err()
+	}
+}
+
+// Health reports whether the service is running with all required subscriptions
+func (s *nudgeService) Health() error {
+	if s.stopped.Load() {
+		return common.ErrServiceStopped
+	}
+	return s.CheckSubscriptionHealth()
+}
+
+// goBackground runs fn in a goroutine tracked by Stop
+func (s *nudgeService) goBackground(fn func()) {
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+		fn()
+	}()
+}
+
 // subscribeWithRetry attempts to subscribe to a topic with exponential backoff retry logic
 func (s *nudgeService) subscribeWithRetry(topic string, handler interface{}, maxRetries int, baseDelay, maxDelay time.Duration) error {
 	var lastErr error
@@ -196,6 +376,8 @@ func (s *nudgeService) CreateTask(task *Task) error {
 		zap.String("userID", string(task.UserID)),
 		zap.String("title", task.Title))
 
+	attachLinks(task)
+
 	// Validate task using business logic
 	if err := s.validator.ValidateTask(task); err != nil {
 		s.logger.Error("Task validation failed", zap.Error(err))
@@ -210,6 +392,8 @@ func (s *nudgeService) CreateTask(task *Task) error {
 	task.UpdatedAt = time.Now()
 
 	if s.repository != nil {
+		s.startHabit(task)
+
 		err := s.repository.CreateTask(task)
 		if err != nil {
 			s.logger.Error("Failed to create task in repository", zap.Error(err))
@@ -218,7 +402,10 @@ func (s *nudgeService) CreateTask(task *Task) error {
 
 		// Schedule initial reminder if due date is set
 		if task.DueDate != nil {
-			go s.scheduleInitialReminder(task)
+			s.goBackground(func() { s.scheduleInitialReminder(task) })
+		}
+		if task.Location != nil {
+			s.goBackground(func() { s.geocodeTask(task) })
 		}
 
 		// Publish TaskCreated event
@@ -230,6 +417,13 @@ func (s *nudgeService) CreateTask(task *Task) error {
 			DueDate:   task.DueDate,
 			Priority:  string(task.Priority),
 			CreatedAt: task.CreatedAt,
+			Code:      task.CodeString(),
+			Estimate:  s.estimateCompletion(task),
+			Conflicts: s.findConflicts(task),
+		}
+		if task.OriginalText != "" {
+			event.Summary = task.Description
+			event.HasOriginal = true
 		}
 		s.eventBus.Publish(events.TopicTaskCreated, event)
 
@@ -242,6 +436,136 @@ func (s *nudgeService) CreateTask(task *Task) error {
 	return nil
 }
 
+// CreateTasks creates several tasks in one transaction so either all or none are stored.
+// Events are published only after the transaction commits: one TaskCreated per task
+// sharing a batch ID, followed by a TasksCreated summary.
+func (s *nudgeService) CreateTasks(tasks []*Task) error {
+	s.logger.Info("Creating tasks in bulk", zap.Int("task_count", len(tasks)))
+
+	if len(tasks) == 0 {
+		return nil
+	}
+
+	// Validate every task up front so the caller gets all failures at once
+	now := time.Now()
+	var failures []TaskCreateFailure
+	seenTitles := make(map[string]int)
+	seenLinks := make(map[string]int)
+	for i, task := range tasks {
+		if task.ID == "" {
+			task.ID = common.TaskID(common.NewID())
+		}
+		attachLinks(task)
+
+		if err := s.validator.ValidateTask(task); err != nil {
+			failures = append(failures, TaskCreateFailure{Index: i, Title: task.Title, Err: err})
+			continue
+		}
+
+		titleKey := string(task.UserID) + ":" + task.Title
+		if first, exists := seenTitles[titleKey]; exists {
+			failures = append(failures, TaskCreateFailure{
+				Index: i,
+				Title: task.Title,
+				Err:   NewTaskValidationError("title", task.Title, fmt.Sprintf("duplicates task %d in the same batch", first)),
+			})
+			continue
+		}
+		seenTitles[titleKey] = i
+
+		if failure, duplicate := duplicateLinkInBatch(task, i, seenLinks); duplicate {
+			failures = append(failures, failure)
+			continue
+		}
+
+		task.CreatedAt = now
+		task.UpdatedAt = now
+		s.startHabit(task)
+	}
+
+	if len(failures) > 0 {
+		err := BulkCreateError{Total: len(tasks), Failures: failures}
+		s.logger.Error("Bulk task validation failed", zap.Error(err))
+		return err
+	}
+
+	if s.repository == nil {
+		s.logger.Info("Tasks created successfully (mock)", zap.Int("task_count", len(tasks)))
+		return nil
+	}
+
+	err := s.repository.WithTransaction(func(repo NudgeRepository) error {
+		for i, task := range tasks {
+			if err := repo.CreateTask(task); err != nil {
+				return BulkCreateError{
+					Total:    len(tasks),
+					Failures: []TaskCreateFailure{{Index: i, Title: task.Title, Err: err}},
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("Bulk task creation rolled back", zap.Error(err))
+		return err
+	}
+
+	batchID := common.NewID()
+	summaries := make([]events.TaskSummary, 0, len(tasks))
+	for _, task := range tasks {
+		// Schedule initial reminder if due date is set
+		if task.DueDate != nil {
+			s.goBackground(func() { s.scheduleInitialReminder(task) })
+		}
+		if task.Location != nil {
+			s.goBackground(func() { s.geocodeTask(task) })
+		}
+
+		event := events.TaskCreated{
+			Event:     events.NewEvent(),
+			TaskID:    string(task.ID),
+			UserID:    string(task.UserID),
+			Title:     task.Title,
+			DueDate:   task.DueDate,
+			Priority:  string(task.Priority),
+			CreatedAt: task.CreatedAt,
+			Code:      task.CodeString(),
+			BatchID:   string(batchID),
+			BatchSize: len(tasks),
+		}
+		if err := s.eventBus.Publish(events.TopicTaskCreated, event); err != nil {
+			s.logger.Error("Failed to publish TaskCreated event", zap.String("taskID", string(task.ID)), zap.Error(err))
+		}
+
+		summaries = append(summaries, events.TaskSummary{
+			ID:          string(task.ID),
+			Code:        task.CodeString(),
+			Title:       task.Title,
+			Description: task.Description,
+			DueDate:     task.DueDate,
+			Priority:    string(task.Priority),
+			Status:      string(task.Status),
+
+			Links: task.Links.URLs(),
+		})
+	}
+
+	summaryEvent := events.TasksCreated{
+		Event:     events.NewEvent(),
+		UserID:    string(tasks[0].UserID),
+		ChatID:    string(tasks[0].ChatID),
+		Tasks:     summaries,
+		CreatedAt: now,
+		BatchID:   string(batchID),
+	}
+	if err := s.eventBus.Publish(events.TopicTasksCreated, summaryEvent); err != nil {
+		s.logger.Error("Failed to publish TasksCreated event", zap.Error(err))
+	}
+
+	s.logger.Info("Tasks created successfully", zap.Int("task_count", len(tasks)), zap.String("batchID", string(batchID)))
+	return nil
+}
+
 // GetTasks retrieves tasks for a user with optional filtering
 func (s *nudgeService) GetTasks(userID common.UserID, filter TaskFilter) ([]*Task, error) {
 	s.logger.Info("Getting tasks",
@@ -288,6 +612,12 @@ func (s *nudgeService) UpdateTaskStatus(taskID common.TaskID, status common.Task
 			return err
 		}
 
+		if status == common.TaskStatusCompleted && task.IsHabit() {
+			return s.logHabit(task)
+		}
+
+		previousStatus := task.Status
+
 		// Use status manager for proper status transitions
 		if err := s.statusManager.TransitionStatus(task, status); err != nil {
 			s.logger.Error("Status transition failed", zap.Error(err))
@@ -300,11 +630,13 @@ func (s *nudgeService) UpdateTaskStatus(taskID common.TaskID, status common.Task
 			return err
 		}
 
+		s.publishStatusChanged(task, previousStatus, nil, nil)
+
 		// Handle status-specific actions
 		switch status {
 		case common.TaskStatusCompleted:
 			// Cancel future reminders for completed task
-			go s.cancelTaskReminders(taskID)
+			s.goBackground(func() { s.cancelTaskReminders(taskID) })
 
 			// Publish TaskCompleted event
 			event := events.TaskCompleted{
@@ -317,12 +649,12 @@ func (s *nudgeService) UpdateTaskStatus(taskID common.TaskID, status common.Task
 
 		case common.TaskStatusDeleted:
 			// Cancel all reminders for deleted task
-			go s.cancelTaskReminders(taskID)
+			s.goBackground(func() { s.cancelTaskReminders(taskID) })
 
 		case common.TaskStatusActive:
 			// If reactivating, schedule new reminders
 			if task.DueDate != nil {
-				go s.scheduleInitialReminder(task)
+				s.goBackground(func() { s.scheduleInitialReminder(task) })
 			}
 		}
 
@@ -342,7 +674,18 @@ func (s *nudgeService) DeleteTask(taskID common.TaskID) error {
 	s.logger.Info("Deleting task", zap.String("taskID", string(taskID)))
 
 	if s.repository != nil {
-		return s.repository.DeleteTask(taskID)
+		task, err := s.repository.GetTaskByID(taskID)
+		if err != nil {
+			return err
+		}
+		if err := s.repository.DeleteTask(taskID); err != nil {
+			return err
+		}
+
+		previousStatus := task.Status
+		task.Status = common.TaskStatusDeleted
+		s.publishStatusChanged(task, previousStatus, nil, nil)
+		return nil
 	}
 
 	// Mock implementation when repository is nil
@@ -359,12 +702,7 @@ func (s *nudgeService) GetTaskStats(userID common.UserID) (*TaskStats, error) {
 	}
 
 	// Mock implementation when repository is nil
-	return &TaskStats{
-		TotalTasks:     0,
-		CompletedTasks: 0,
-		OverdueTasks:   0,
-		ActiveTasks:    0,
-	}, nil
+	return NewTaskStats(), nil
 }
 
 // ScheduleReminder schedules a reminder for a task
@@ -382,32 +720,17 @@ func (s *nudgeService) ScheduleReminder(taskID common.TaskID, scheduledAt time.T
 			return err
 		}
 
-		// Determine ChatID - use task's ChatID if available, otherwise use UserID as fallback
-		//
-		// ChatID Resolution Strategy:
-		// 1. Primary: Use ChatID stored in the task (for tasks created from chat interactions)
-		// 2. Fallback: Use UserID as ChatID (for backwards compatibility with existing tasks)
-		//
-		// This approach ensures:
-		// - New tasks created from chat interactions have proper ChatID tracking
-		// - Existing tasks without ChatID continue to work (assuming UserID == ChatID for private chats)
-		// - Clear logging when fallback is used for debugging and migration purposes
-		chatID := task.ChatID
-		if chatID == "" {
-			// Fallback: assume ChatID equals UserID for backwards compatibility
-			// This handles cases where tasks were created before ChatID tracking
-			chatID = common.ChatID(task.UserID)
-			s.logger.Warn("No ChatID found in task, using UserID as fallback",
-				zap.String("taskID", string(taskID)),
-				zap.String("userID", string(task.UserID)),
-				zap.String("fallback_chatID", string(chatID)))
+		// Reminders are delivered to the chat the task was created in; tasks
+		// created before chats were tracked get one from backfillTaskChats
+		if task.ChatID == "" {
+			return NewReminderSchedulingError(taskID, "task has no chat to deliver the reminder to", nil)
 		}
 
 		reminder := &Reminder{
 			ID:           common.ID(common.NewID()),
 			TaskID:       taskID,
 			UserID:       task.UserID,
-			ChatID:       chatID, // Use the resolved ChatID
+			ChatID:       task.ChatID,
 			ScheduledAt:  scheduledAt,
 			ReminderType: reminderType,
 		}
@@ -471,60 +794,117 @@ func (s *nudgeService) UpdateNudgeSettings(settings *NudgeSettings) error {
 
 // handleTaskParsed handles TaskParsed events from the LLM service
 func (s *nudgeService) handleTaskParsed(event events.TaskParsed) {
-	s.logger.Info("Handling TaskParsed event",
-		zap.String("correlationID", event.CorrelationID),
-		zap.String("userID", event.UserID),
-		zap.String("chatID", event.ChatID),
+	s = s.forUser(event.UserID)
+	ctx := common.WithLogFlow(context.Background(), common.LogFlow{
+		UserID:        event.UserID,
+		ChatID:        event.ChatID,
+		CorrelationID: event.CorrelationID,
+	})
+	log := common.ContextLogger(ctx, s.logger)
+
+	log.Info("Handling TaskParsed event",
 		zap.String("taskTitle", event.ParsedTask.Title))
 
-	// Create a task from the parsed event
-	task := &Task{
-		ID:          common.TaskID(common.NewID()),
-		UserID:      common.UserID(event.UserID),
-		ChatID:      common.ChatID(event.ChatID), // Store ChatID from the event
-		Title:       event.ParsedTask.Title,
-		Description: event.ParsedTask.Description,
-		DueDate:     event.ParsedTask.DueDate,
-		Priority:    common.Priority(event.ParsedTask.Priority),
-		Status:      common.TaskStatusActive,
+	// Previewed tasks are saved once the user confirms the draft
+	if event.Preview {
+		log.Debug("Skipping task awaiting preview confirmation")
+		return
 	}
 
-	err := s.CreateTask(task)
-	if err != nil {
-		s.logger.Error("Failed to create task from parsed event", zap.Error(err))
+	listID, ok := s.taskListFor(event)
+	if !ok {
+		log.Warn("Skipping tasks for a shared list the user is not in",
+			zap.String("listID", event.ListID))
 		return
 	}
 
-	s.logger.Info("Task created successfully from parsed event", zap.String("taskID", string(task.ID)))
+	var tasks []*Task
+	for _, parsedTask := range event.AllTasks() {
+		// Messages to send later are stored by the ScheduledMessageService
+		if parsedTask.IsScheduledMessage() {
+			continue
+		}
+
+		// Moderate the parsed content before it is stored
+		content := strings.TrimSpace(parsedTask.Title + " " + parsedTask.Description)
+		if verdict := s.moderation.Evaluate(ctx, content); !verdict.Allowed {
+			log.Warn("Parsed task rejected by content moderation",
+				zap.Strings("categories", verdict.Result.Categories))
+			continue
+		}
+
+		// Create a task from the parsed event
+		tasks = append(tasks, &Task{
+			ID:          common.TaskID(common.NewID()),
+			UserID:      common.UserID(event.UserID),
+			ChatID:      common.ChatID(event.ChatID), // Store ChatID from the event
+			Title:       parsedTask.Title,
+			Description: parsedTask.Description,
+			DueDate:     parsedTask.DueDate,
+			Priority:    common.Priority(parsedTask.Priority),
+			Status:      common.TaskStatusActive,
+			Habit:       HabitPeriod(parsedTask.Habit),
+			ListID:      listID,
+			ThreadID:    event.ThreadID,
+			Tags:        parsedTask.Tags,
+			Location:    NewTaskLocation(parsedTask.Location),
+
+			OriginalText: event.OriginalText,
+		})
+	}
+
+	switch len(tasks) {
+	case 0:
+		return
+	case 1:
+		if err := s.CreateTask(tasks[0]); err != nil {
+			log.Error("Failed to create task from parsed event", zap.Error(err))
+			return
+		}
+		log.Info("Task created successfully from parsed event", zap.String("taskID", string(tasks[0].ID)))
+	default:
+		if err := s.CreateTasks(tasks); err != nil {
+			log.Error("Failed to create tasks from parsed event",
+				zap.Int("task_count", len(tasks)),
+				zap.Error(err))
+			return
+		}
+		log.Info("Tasks created successfully from parsed event", zap.Int("task_count", len(tasks)))
+	}
 }
 
 // handleTaskListRequested handles TaskListRequested events from the chatbot
 func (s *nudgeService) handleTaskListRequested(event events.TaskListRequested) {
-	s.logger.Info("Handling TaskListRequested event",
-		zap.String("correlationID", event.CorrelationID),
-		zap.String("userID", event.UserID),
-		zap.String("chatID", event.ChatID))
+	s = s.forUser(event.UserID)
+	log := common.FlowLogger(s.logger, common.LogFlow{
+		UserID:        event.UserID,
+		ChatID:        event.ChatID,
+		CorrelationID: event.CorrelationID,
+	})
+
+	log.Info("Handling TaskListRequested event")
 
 	// Validate the request
 	if err := s.validateTaskListRequest(event); err != nil {
-		s.logger.Error("Task list request validation failed",
-			zap.String("userID", event.UserID),
-			zap.String("chatID", event.ChatID),
+		log.Error("Task list request validation failed",
 			zap.Error(err))
 		s.publishTaskListErrorResponse(event, err)
 		return
 	}
 
-	// Get tasks for the user
+	// Get tasks for the user, and those other members added to their shared lists
 	filter := TaskFilter{
-		UserID: common.UserID(event.UserID),
-		Status: &[]common.TaskStatus{common.TaskStatusActive}[0], // Only active tasks
+		UserID:   common.UserID(event.UserID),
+		Statuses: common.OpenTaskStatuses(), // Only tasks that still need doing
+	}
+	listNames := s.listNames(common.UserID(event.UserID))
+	for listID := range listNames {
+		filter.ListIDs = append(filter.ListIDs, listID)
 	}
 
 	tasks, err := s.GetTasks(common.UserID(event.UserID), filter)
 	if err != nil {
-		s.logger.Error("Failed to get tasks for list request",
-			zap.String("userID", event.UserID),
+		log.Error("Failed to get tasks for list request",
 			zap.Error(err))
 
 		// Create appropriate error based on the underlying cause
@@ -542,15 +922,7 @@ func (s *nudgeService) handleTaskListRequested(event events.TaskListRequested) {
 	// Convert tasks to TaskSummary format
 	taskSummaries := make([]events.TaskSummary, len(tasks))
 	for i, task := range tasks {
-		taskSummaries[i] = events.TaskSummary{
-			ID:          string(task.ID),
-			Title:       task.Title,
-			Description: task.Description,
-			DueDate:     task.DueDate,
-			Priority:    string(task.Priority),
-			Status:      string(task.Status),
-			IsOverdue:   task.IsOverdue(),
-		}
+		taskSummaries[i] = summarizeTask(task, listNames[task.ListID])
 	}
 
 	// Publish successful TaskListResponse event
@@ -564,27 +936,31 @@ func (s *nudgeService) handleTaskListRequested(event events.TaskListRequested) {
 		Success:    true,
 		ErrorCode:  "",
 		ErrorMsg:   "",
+		Page:       event.Page,
+		MessageID:  event.MessageID,
 	}
 
 	err = s.eventBus.Publish(events.TopicTaskListResponse, response)
 	if err != nil {
-		s.logger.Error("Failed to publish TaskListResponse event",
-			zap.String("userID", event.UserID),
+		log.Error("Failed to publish TaskListResponse event",
 			zap.Error(err))
 		return
 	}
 
-	s.logger.Info("TaskListResponse published successfully",
-		zap.String("userID", event.UserID),
+	log.Info("TaskListResponse published successfully",
 		zap.Int("taskCount", len(taskSummaries)))
 }
 
 // handleTaskActionRequested handles TaskActionRequested events from the chatbot
 func (s *nudgeService) handleTaskActionRequested(event events.TaskActionRequested) {
-	s.logger.Info("Handling TaskActionRequested event",
-		zap.String("correlationID", event.CorrelationID),
-		zap.String("userID", event.UserID),
-		zap.String("chatID", event.ChatID),
+	s = s.forUser(event.UserID)
+	log := common.FlowLogger(s.logger, common.LogFlow{
+		UserID:        event.UserID,
+		ChatID:        event.ChatID,
+		CorrelationID: event.CorrelationID,
+	})
+
+	log.Info("Handling TaskActionRequested event",
 		zap.String("taskID", event.TaskID),
 		zap.String("action", event.Action))
 
@@ -594,12 +970,15 @@ func (s *nudgeService) handleTaskActionRequested(event events.TaskActionRequeste
 
 	// Validate the event structure first
 	if err = s.validateTaskActionRequest(event); err != nil {
-		s.logger.Error("Task action request validation failed",
+		log.Error("Task action request validation failed",
 			zap.String("taskID", event.TaskID),
-			zap.String("userID", event.UserID),
 			zap.String("action", event.Action),
 			zap.Error(err))
 		message = "Invalid request: " + err.Error()
+		var permissionErr PermissionError
+		if errors.As(err, &permissionErr) {
+			message = "Not allowed: " + permissionErr.Message()
+		}
 		success = false
 		s.publishTaskActionResponse(event, success, message)
 		return
@@ -611,6 +990,12 @@ func (s *nudgeService) handleTaskActionRequested(event events.TaskActionRequeste
 		err = s.UpdateTaskStatus(common.TaskID(event.TaskID), common.TaskStatusCompleted)
 		if err == nil {
 			message = "Task marked as completed successfully!"
+			if habit, ok := s.loggedHabit(common.TaskID(event.TaskID)); ok {
+				event.Action = "log_habit"
+				message = habitLoggedMessage(habit)
+			} else {
+				s.notifyListCompletion(common.TaskID(event.TaskID), common.UserID(event.UserID))
+			}
 		} else {
 			message = "Failed to mark task as completed: " + err.Error()
 			success = false
@@ -625,17 +1010,62 @@ func (s *nudgeService) handleTaskActionRequested(event events.TaskActionRequeste
 			success = false
 		}
 
+	case "start":
+		err = s.UpdateTaskStatus(common.TaskID(event.TaskID), common.TaskStatusInProgress)
+		if err == nil {
+			message = "Task marked as in progress!"
+		} else {
+			message = "Failed to start task: " + err.Error()
+			success = false
+		}
+
+	case "wait":
+		err = s.UpdateTaskStatus(common.TaskID(event.TaskID), common.TaskStatusWaiting)
+		if err == nil {
+			message = "Task marked as waiting!"
+		} else {
+			message = "Failed to mark task as waiting: " + err.Error()
+			success = false
+		}
+
 	case "snooze":
 		// Snooze for 1 hour by default
 		snoozeUntil := time.Now().Add(time.Hour)
-		err = s.SnoozeTask(common.TaskID(event.TaskID), snoozeUntil)
+		message = "Task snoozed for 1 hour!"
+		if event.Argument != "" {
+			snoozeUntil, err = common.ParseWhen(event.Argument, s.userNow(common.UserID(event.UserID)))
+			message = fmt.Sprintf("Task snoozed until %s!", snoozeUntil.Format("Mon Jan 2, 15:04"))
+		}
 		if err == nil {
-			message = "Task snoozed for 1 hour!"
-		} else {
+			err = s.SnoozeTask(common.TaskID(event.TaskID), snoozeUntil)
+		}
+		if err != nil {
 			message = "Failed to snooze task: " + err.Error()
 			success = false
 		}
 
+	case "mute", "unmute":
+		muted := event.Action == "mute"
+		err = s.setTaskMuted(common.TaskID(event.TaskID), muted)
+		switch {
+		case err != nil:
+			message = "Failed to " + event.Action + " task: " + err.Error()
+			success = false
+		case muted:
+			message = "You won't get more reminders for this task. Unmute it from the task details."
+		default:
+			message = "Reminders for this task are back on."
+		}
+
+	case "test_reminder":
+		err = s.FireTestReminder(common.TaskID(event.TaskID), common.ChatID(event.ChatID))
+		if err == nil {
+			// The reminder message itself confirms the channel works
+			return
+		}
+		message = "Failed to send test reminder: " + err.Error()
+		success = false
+
 	default:
 		err = NewInvalidTaskActionError(event.Action)
 		message = "Invalid action: " + event.Action
@@ -643,7 +1073,7 @@ func (s *nudgeService) handleTaskActionRequested(event events.TaskActionRequeste
 	}
 
 	if err != nil {
-		s.logger.Error("Failed to process task action",
+		log.Error("Failed to process task action",
 			zap.String("action", event.Action),
 			zap.String("taskID", event.TaskID),
 			zap.Error(err))
@@ -652,8 +1082,262 @@ func (s *nudgeService) handleTaskActionRequested(event events.TaskActionRequeste
 	s.publishTaskActionResponse(event, success, message)
 }
 
+// handleTaskFieldUpdateRequested sets or clears a custom field from the chatbot
+func (s *nudgeService) handleTaskFieldUpdateRequested(event events.TaskFieldUpdateRequested) {
+	s = s.forUser(event.UserID)
+	log := common.FlowLogger(s.logger, common.LogFlow{
+		UserID:        event.UserID,
+		ChatID:        event.ChatID,
+		CorrelationID: event.CorrelationID,
+	})
+
+	log.Info("Handling TaskFieldUpdateRequested event",
+		zap.String("taskID", event.TaskID),
+		zap.String("key", event.Key))
+
+	actionEvent := events.TaskActionRequested{
+		Event:  event.Event,
+		UserID: event.UserID,
+		ChatID: event.ChatID,
+		TaskID: event.TaskID,
+		Action: "field",
+	}
+
+	message, err := s.applyFieldUpdate(event)
+	if err != nil {
+		log.Warn("Custom field update failed",
+			zap.String("taskID", event.TaskID),
+			zap.String("key", event.Key),
+			zap.Error(err))
+		message = "Failed to update field: " + err.Error()
+		var permissionErr PermissionError
+		if errors.As(err, &permissionErr) {
+			message = "Not allowed: " + permissionErr.Message()
+		}
+	}
+
+	s.publishTaskActionResponse(actionEvent, err == nil, message)
+}
+
+// applyFieldUpdate authorizes and applies a chatbot field update, returning the confirmation message
+func (s *nudgeService) applyFieldUpdate(event events.TaskFieldUpdateRequested) (string, error) {
+	if s.repository == nil {
+		return "", fmt.Errorf("repository not initialized")
+	}
+
+	task, err := s.repository.GetTaskByID(common.TaskID(event.TaskID))
+	if err != nil {
+		return "", err
+	}
+	if err := s.authorizeTaskAction(task, common.UserID(event.UserID), "field"); err != nil {
+		return "", err
+	}
+
+	if event.Type == "" {
+		if _, err := s.RemoveCustomField(task.ID, event.Key); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Field %s cleared.", event.Key), nil
+	}
+
+	field, err := NewCustomField(CustomFieldType(event.Type), event.Value, event.Options)
+	if err != nil {
+		return "", err
+	}
+	if _, err := s.SetCustomField(task.ID, event.Key, field); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Field %s set to %s.", event.Key, field.Value), nil
+}
+
+// handleTaskRescheduleRequested previews or applies a /snoozeall or /moveto
+// command. The preview lists today's remaining tasks; the confirmed request
+// shifts exactly the tasks that were previewed.
+func (s *nudgeService) handleTaskRescheduleRequested(event events.TaskRescheduleRequested) {
+	s = s.forUser(event.UserID)
+	log := common.FlowLogger(s.logger, common.LogFlow{
+		UserID:        event.UserID,
+		ChatID:        event.ChatID,
+		CorrelationID: event.CorrelationID,
+	})
+
+	log.Info("Handling TaskRescheduleRequested event",
+		zap.String("command", event.Command),
+		zap.Int("task_count", len(event.TaskIDs)))
+
+	response := events.TaskRescheduleResponse{
+		Event:    events.NewEvent(),
+		UserID:   event.UserID,
+		ChatID:   event.ChatID,
+		Command:  event.Command,
+		Argument: event.Argument,
+		Applied:  len(event.TaskIDs) > 0,
+	}
+
+	tasks, shift, err := s.applyReschedule(event)
+	if err != nil {
+		log.Warn("Bulk reschedule failed",
+			zap.String("command", event.Command),
+			zap.Error(err))
+		response.Applied = false
+		response.Message = "Failed to reschedule tasks: " + err.Error()
+	} else {
+		response.Success = true
+		response.Shift = shift.Duration
+		response.ShiftDays = shift.Days
+		for _, task := range tasks {
+			response.Tasks = append(response.Tasks, events.TaskSummary{
+				ID:          string(task.ID),
+				Code:        task.CodeString(),
+				Title:       task.Title,
+				Description: task.Description,
+				DueDate:     task.DueDate,
+				Priority:    string(task.Priority),
+				Status:      string(task.Status),
+				IsOverdue:   task.IsOverdue(),
+				Muted:       task.Muted,
+				Habit:       string(task.Habit),
+				Streak:      task.Streak,
+			})
+		}
+	}
+
+	if err := s.eventBus.Publish(events.TopicTaskRescheduleResponse, response); err != nil {
+		log.Error("Failed to publish TaskRescheduleResponse event",
+			zap.Error(err))
+	}
+}
+
+// applyReschedule resolves the requested shift and returns the tasks it affects:
+// today's remaining tasks for a preview, the shifted tasks once confirmed
+func (s *nudgeService) applyReschedule(event events.TaskRescheduleRequested) ([]*Task, DueShift, error) {
+	if s.repository == nil {
+		return nil, DueShift{}, fmt.Errorf("repository not initialized")
+	}
+
+	userID := common.UserID(event.UserID)
+	shift, err := RescheduleShift(event.Command, event.Argument, s.userNow(userID))
+	if err != nil {
+		return nil, DueShift{}, err
+	}
+
+	if len(event.TaskIDs) == 0 {
+		tasks, err := s.GetTasksDueToday(userID)
+		return tasks, shift, err
+	}
+
+	taskIDs := make([]common.TaskID, len(event.TaskIDs))
+	for i, taskID := range event.TaskIDs {
+		taskIDs[i] = common.TaskID(taskID)
+	}
+	tasks, err := s.ShiftTaskDueDates(userID, taskIDs, shift)
+	return tasks, shift, err
+}
+
 // Additional service methods
 
+// GetTasksDueToday returns the user's open tasks due today in their timezone
+func (s *nudgeService) GetTasksDueToday(userID common.UserID) ([]*Task, error) {
+	s.logger.Info("Getting tasks due today", zap.String("userID", string(userID)))
+
+	if s.repository == nil {
+		return []*Task{}, nil
+	}
+
+	start, end := dayBounds(s.userNow(userID))
+	return s.repository.GetTasksByUserID(userID, TaskFilter{
+		UserID:    userID,
+		Statuses:  common.OpenTaskStatuses(),
+		DueAfter:  &start,
+		DueBefore: &end,
+	})
+}
+
+// ShiftTaskDueDates moves the due dates and pending reminders of the user's
+// tasks by shift, counting days in the user's timezone. Tasks that belong to
+// someone else, are no longer open or have no due date are skipped; the
+// shifted tasks are returned.
+func (s *nudgeService) ShiftTaskDueDates(userID common.UserID, taskIDs []common.TaskID, shift DueShift) ([]*Task, error) {
+	s.logger.Info("Shifting task due dates",
+		zap.String("userID", string(userID)),
+		zap.Int("count", len(taskIDs)),
+		zap.Duration("shift", shift.Duration),
+		zap.Int("shift_days", shift.Days))
+
+	if s.repository == nil {
+		return nil, fmt.Errorf("repository not initialized")
+	}
+	s = s.forUser(string(userID))
+	if shift.IsZero() {
+		return nil, NewTaskValidationError("shift", shift, "shift cannot be zero")
+	}
+	location := s.userNow(userID).Location()
+
+	var eligible []common.TaskID
+	previousDue := make(map[common.TaskID]*time.Time)
+	// Calendar days are a different duration for tasks on either side of a
+	// daylight saving change, so tasks are shifted in groups of equal duration
+	groups := make(map[time.Duration][]common.TaskID)
+	for _, taskID := range taskIDs {
+		task, err := s.repository.GetTaskByID(taskID)
+		if err != nil {
+			if errors.Is(err, ErrTaskNotFound) || IsNotFoundError(err) {
+				continue
+			}
+			return nil, err
+		}
+		if task.UserID == userID && task.Status.IsOpen() && task.DueDate != nil {
+			eligible = append(eligible, taskID)
+			previousDue[taskID] = task.DueDate
+			delta := shift.Apply(*task.DueDate, location).Sub(*task.DueDate)
+			groups[delta] = append(groups[delta], taskID)
+		}
+	}
+	if len(eligible) == 0 {
+		return []*Task{}, nil
+	}
+
+	for delta, group := range groups {
+		if err := s.repository.BulkShiftDueDates(group, delta); err != nil {
+			return nil, err
+		}
+	}
+
+	tasks := make([]*Task, 0, len(eligible))
+	for _, taskID := range eligible {
+		task, err := s.repository.GetTaskByID(taskID)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+
+		event := events.TaskEdited{
+			Event:           events.NewEvent(),
+			TaskID:          string(task.ID),
+			UserID:          string(task.UserID),
+			Fields:          []string{"due_date"},
+			PreviousDueDate: previousDue[taskID],
+			DueDate:         task.DueDate,
+		}
+		if err := s.eventBus.Publish(events.TopicTaskEdited, event); err != nil {
+			s.logger.Error("Failed to publish TaskEdited event",
+				zap.String("taskID", string(task.ID)),
+				zap.Error(err))
+		}
+	}
+
+	return tasks, nil
+}
+
+// userNow returns the current time in the user's timezone
+func (s *nudgeService) userNow(userID common.UserID) time.Time {
+	settings, err := s.repository.GetNudgeSettingsByUserID(userID)
+	if err != nil {
+		settings = nil
+	}
+	return time.Now().In(settings.Location())
+}
+
 // SnoozeTask snoozes a task until a specific time
 func (s *nudgeService) SnoozeTask(taskID common.TaskID, snoozeUntil time.Time) error {
 	s.logger.Info("Snoozing task",
@@ -666,6 +1350,9 @@ func (s *nudgeService) SnoozeTask(taskID common.TaskID, snoozeUntil time.Time) e
 			return err
 		}
 
+		previousStatus := task.Status
+		previousDue := task.DueDate
+
 		// Use status manager for snoozing
 		if err := s.statusManager.SnoozeTask(task, snoozeUntil); err != nil {
 			return err
@@ -676,9 +1363,11 @@ func (s *nudgeService) SnoozeTask(taskID common.TaskID, snoozeUntil time.Time) e
 			return err
 		}
 
+		s.publishStatusChanged(task, previousStatus, &snoozeUntil, previousDue)
+
 		// Cancel existing reminders and schedule new ones
-		go s.cancelTaskReminders(taskID)
-		go s.scheduleInitialReminder(task)
+		s.goBackground(func() { s.cancelTaskReminders(taskID) })
+		s.goBackground(func() { s.scheduleInitialReminder(task) })
 
 		s.logger.Info("Task snoozed successfully", zap.String("taskID", string(taskID)))
 		return nil
@@ -689,35 +1378,337 @@ func (s *nudgeService) SnoozeTask(taskID common.TaskID, snoozeUntil time.Time) e
 	return nil
 }
 
-// GetOverdueTasks retrieves overdue tasks for a user
-func (s *nudgeService) GetOverdueTasks(userID common.UserID) ([]*Task, error) {
-	s.logger.Info("Getting overdue tasks", zap.String("userID", string(userID)))
+// setTaskMuted mutes or unmutes a task's reminders. The scheduler drops the
+// reminders of a muted task; unmuting schedules a fresh initial reminder.
+func (s *nudgeService) setTaskMuted(taskID common.TaskID, muted bool) error {
+	s.logger.Info("Setting task muted",
+		zap.String("taskID", string(taskID)),
+		zap.Bool("muted", muted))
 
-	if s.repository != nil {
-		// Use specialized repository method if available
-		if repo, ok := s.repository.(*gormNudgeRepository); ok {
-			return repo.GetOverdueTasks(userID)
+	if s.repository == nil {
+		return nil
+	}
+
+	task, err := s.repository.GetTaskByID(taskID)
+	if err != nil {
+		return err
+	}
+	if task.Muted == muted {
+		return nil
+	}
+
+	task.Muted = muted
+	if err := s.repository.UpdateTask(task); err != nil {
+		return err
+	}
+
+	if !muted {
+		s.goBackground(func() {
+			s.cancelTaskReminders(taskID)
+			s.scheduleInitialReminder(task)
+		})
+	}
+	return nil
+}
+
+// taskListFor returns the shared list parsed tasks go into. It reports false
+// when the user is not a member of the list the event names.
+func (s *nudgeService) taskListFor(event events.TaskParsed) (common.ID, bool) {
+	if event.ListID == "" {
+		return "", true
+	}
+	if s.lists == nil {
+		return "", false
+	}
+
+	member, err := s.lists.IsMember(common.ID(event.ListID), common.UserID(event.UserID))
+	if err != nil {
+		s.logger.Error("Failed to check shared list membership",
+			zap.String("listID", event.ListID),
+			zap.Error(err))
+		return "", false
+	}
+	return common.ID(event.ListID), member
+}
+
+// listNames returns the names of the user's shared lists by ID
+func (s *nudgeService) listNames(userID common.UserID) map[common.ID]string {
+	names := make(map[common.ID]string)
+	if s.lists == nil {
+		return names
+	}
+
+	lists, err := s.lists.ListsForUser(userID)
+	if err != nil {
+		// The user's own tasks are still worth showing
+		s.logger.Warn("Failed to load shared lists",
+			zap.String("userID", string(userID)),
+			zap.Error(err))
+		return names
+	}
+	for _, list := range lists {
+		names[list.ID] = list.Name
+	}
+	return names
+}
+
+// notifyListCompletion tells the other members of a task's shared list that
+// the user completed it
+func (s *nudgeService) notifyListCompletion(taskID common.TaskID, completedBy common.UserID) {
+	if s.lists == nil || s.repository == nil {
+		return
+	}
+
+	task, err := s.repository.GetTaskByID(taskID)
+	if err != nil || task.ListID == "" {
+		return
+	}
+	list, err := s.lists.GetList(task.ListID)
+	if err != nil {
+		s.logger.Warn("Failed to load shared list of completed task",
+			zap.String("taskID", string(taskID)),
+			zap.Error(err))
+		return
+	}
+	members, err := s.lists.ListMembers(list.ID)
+	if err != nil {
+		s.logger.Warn("Failed to load shared list members",
+			zap.String("listID", string(list.ID)),
+			zap.Error(err))
+		return
+	}
+
+	for _, member := range members {
+		if member.UserID == completedBy || member.ChatID == "" {
+			continue
+		}
+		event := events.ListTaskCompleted{
+			Event:       events.NewEvent(),
+			UserID:      string(member.UserID),
+			ChatID:      string(member.ChatID),
+			ListID:      string(list.ID),
+			ListName:    list.Name,
+			TaskID:      string(task.ID),
+			Title:       task.Title,
+			CompletedBy: string(completedBy),
+		}
+		if err := s.eventBus.Publish(events.TopicListTaskCompleted, event); err != nil {
+			s.logger.Error("Failed to publish ListTaskCompleted event", zap.Error(err))
 		}
+	}
+}
+
+// startHabit makes a new habit due at the end of its current period unless it
+// has a due date of its own
+func (s *nudgeService) startHabit(task *Task) {
+	if !task.IsHabit() || task.DueDate != nil || s.repository == nil {
+		return
+	}
+	due := task.Habit.End(s.userNow(task.UserID))
+	task.DueDate = &due
+}
+
+// logHabit records a habit as done for its current period. The task stays
+// open and its reminders move on to the next period.
+func (s *nudgeService) logHabit(task *Task) error {
+	if err := task.LogHabit(s.userNow(task.UserID)); err != nil {
+		return err
+	}
+	if err := s.repository.UpdateTask(task); err != nil {
+		return err
+	}
+
+	event := events.HabitLogged{
+		Event:      events.NewEvent(),
+		TaskID:     string(task.ID),
+		UserID:     string(task.UserID),
+		Habit:      string(task.Habit),
+		Streak:     task.Streak,
+		BestStreak: task.BestStreak,
+		NextDue:    task.DueDate,
+	}
+	if err := s.eventBus.Publish(events.TopicHabitLogged, event); err != nil {
+		s.logger.Error("Failed to publish HabitLogged event",
+			zap.String("taskID", string(task.ID)),
+			zap.Error(err))
+	}
 
-		// Fallback to filtered query
-		filter := TaskFilter{
-			UserID: userID,
-			Status: &[]common.TaskStatus{common.TaskStatusActive}[0],
+	s.goBackground(func() {
+		s.cancelTaskReminders(task.ID)
+		s.scheduleInitialReminder(task)
+	})
+
+	s.logger.Info("Habit logged",
+		zap.String("taskID", string(task.ID)),
+		zap.Int("streak", task.Streak))
+	return nil
+}
+
+// logHabits logs the habits among taskIDs for a bulk completion and returns
+// the remaining tasks. Habits already done for the period are left as they are.
+func (s *nudgeService) logHabits(taskIDs []common.TaskID) ([]common.TaskID, error) {
+	remaining := make([]common.TaskID, 0, len(taskIDs))
+	for _, taskID := range taskIDs {
+		task, err := s.repository.GetTaskByID(taskID)
+		if err != nil || !task.IsHabit() {
+			remaining = append(remaining, taskID)
+			continue
 		}
-		tasks, err := s.repository.GetTasksByUserID(userID, filter)
-		if err != nil {
+
+		var ruleErr BusinessRuleError
+		if err := s.logHabit(task); err != nil && !errors.As(err, &ruleErr) {
 			return nil, err
 		}
+	}
+	return remaining, nil
+}
 
-		// Filter for overdue tasks
-		var overdueTasks []*Task
-		for _, task := range tasks {
-			if task.IsOverdue() {
-				overdueTasks = append(overdueTasks, task)
-			}
-		}
+// loggedHabit returns the task when it is a habit, so a completion can be
+// confirmed as a logged occurrence
+func (s *nudgeService) loggedHabit(taskID common.TaskID) (*Task, bool) {
+	if s.repository == nil {
+		return nil, false
+	}
+	task, err := s.repository.GetTaskByID(taskID)
+	if err != nil || !task.IsHabit() {
+		return nil, false
+	}
+	return task, true
+}
+
+// habitLoggedMessage confirms a logged habit with its streak
+func habitLoggedMessage(task *Task) string {
+	message := fmt.Sprintf("Done for %s! 🔥 %d-%s streak", task.Habit.Current(), task.Streak, task.Habit.Noun())
+	if task.BestStreak > task.Streak {
+		message += fmt.Sprintf(" (best: %d)", task.BestStreak)
+	}
+	return message + "."
+}
+
+// handleHabitMissed schedules the reminder for the period a missed habit moved on to
+func (s *nudgeService) handleHabitMissed(event events.HabitMissed) {
+	s = s.forUser(event.UserID)
+	if s.repository == nil {
+		return
+	}
+
+	task, err := s.repository.GetTaskByID(common.TaskID(event.TaskID))
+	if err != nil {
+		s.logger.Error("Failed to get missed habit",
+			zap.String("taskID", event.TaskID),
+			zap.Error(err))
+		return
+	}
+
+	s.cancelTaskReminders(task.ID)
+	s.scheduleInitialReminder(task)
+}
+
+// FireTestReminder publishes a ReminderDue for a task right away so the user can
+// preview the reminder. Stored reminders are neither created nor marked sent.
+func (s *nudgeService) FireTestReminder(taskID common.TaskID, chatID common.ChatID) error {
+	s.logger.Info("Firing test reminder",
+		zap.String("taskID", string(taskID)),
+		zap.String("chatID", string(chatID)))
+
+	if s.repository == nil {
+		return fmt.Errorf("repository not initialized")
+	}
+
+	task, err := s.repository.GetTaskByID(taskID)
+	if err != nil {
+		return err
+	}
+
+	if chatID == "" {
+		chatID = task.ChatID
+	}
+
+	reminderEvent := events.ReminderDue{
+		Event:  events.NewEvent(),
+		TaskID: string(task.ID),
+		UserID: string(task.UserID),
+		ChatID: string(chatID),
+		Test:   true,
+
+		Title:   task.Title,
+		DueDate: task.DueDate,
+	}
+	if chatID == task.ChatID {
+		reminderEvent.ThreadID = task.ThreadID
+	}
+
+	return s.eventBus.Publish(events.TopicReminderDue, reminderEvent)
+}
+
+// SetCustomField sets a custom field on a task, replacing any value under the same key
+func (s *nudgeService) SetCustomField(taskID common.TaskID, key string, field CustomField) (*Task, error) {
+	if err := ValidateCustomFieldKey(key); err != nil {
+		return nil, err
+	}
+	if !field.Type.IsValid() {
+		return nil, NewTaskValidationError("custom_field", field.Type, "unknown field type")
+	}
+
+	return s.updateCustomFields(taskID, key, func(fields CustomFields) {
+		fields[key] = field
+	})
+}
+
+// RemoveCustomField clears a custom field from a task; clearing a missing field is not an error
+func (s *nudgeService) RemoveCustomField(taskID common.TaskID, key string) (*Task, error) {
+	return s.updateCustomFields(taskID, key, func(fields CustomFields) {
+		delete(fields, key)
+	})
+}
+
+// updateCustomFields applies change to a copy of the task's custom fields, saves
+// the task and records the edit in its history
+func (s *nudgeService) updateCustomFields(taskID common.TaskID, key string, change func(CustomFields)) (*Task, error) {
+	if s.repository == nil {
+		return nil, fmt.Errorf("repository not initialized")
+	}
+
+	task, err := s.repository.GetTaskByID(taskID)
+	if err != nil {
+		return nil, err
+	}
 
-		return overdueTasks, nil
+	fields := make(CustomFields, len(task.CustomFields)+1)
+	for existingKey, existing := range task.CustomFields {
+		fields[existingKey] = existing
+	}
+	change(fields)
+	task.CustomFields = fields
+
+	if err := s.repository.UpdateTask(task); err != nil {
+		return nil, err
+	}
+
+	event := events.TaskEdited{
+		Event:  events.NewEvent(),
+		TaskID: string(task.ID),
+		UserID: string(task.UserID),
+		Fields: []string{"custom_fields." + key},
+	}
+	if err := s.eventBus.Publish(events.TopicTaskEdited, event); err != nil {
+		s.logger.Error("Failed to publish TaskEdited event",
+			zap.String("taskID", string(task.ID)),
+			zap.Error(err))
+	}
+
+	s.logger.Info("Task custom fields updated",
+		zap.String("taskID", string(taskID)),
+		zap.String("key", key))
+	return task, nil
+}
+
+// GetOverdueTasks retrieves overdue tasks for a user
+func (s *nudgeService) GetOverdueTasks(userID common.UserID) ([]*Task, error) {
+	s.logger.Info("Getting overdue tasks", zap.String("userID", string(userID)))
+
+	if s.repository != nil {
+		return s.repository.GetOverdueTasks(userID)
 	}
 
 	// Mock implementation
@@ -731,22 +1722,13 @@ func (s *nudgeService) BulkUpdateStatus(taskIDs []common.TaskID, status common.T
 		zap.String("status", string(status)))
 
 	if s.repository != nil {
-		// Use specialized repository method if available
-		if repo, ok := s.repository.(*gormNudgeRepository); ok {
-			return repo.BulkUpdateTaskStatus(taskIDs, status)
-		}
-
-		// Fallback to individual updates
-		for _, taskID := range taskIDs {
-			if err := s.UpdateTaskStatus(taskID, status); err != nil {
-				s.logger.Error("Failed to update task status in bulk operation",
-					zap.String("taskID", string(taskID)),
-					zap.Error(err))
+		if status == common.TaskStatusCompleted {
+			var err error
+			if taskIDs, err = s.logHabits(taskIDs); err != nil {
 				return err
 			}
 		}
-
-		return nil
+		return s.repository.BulkUpdateTaskStatus(taskIDs, status)
 	}
 
 	// Mock implementation
@@ -761,6 +1743,7 @@ func (s *nudgeService) scheduleInitialReminder(task *Task) {
 	if s.repository == nil {
 		return
 	}
+	s = s.forTask(task)
 
 	// Get user settings
 	settings, err := s.repository.GetNudgeSettingsByUserID(task.UserID)
@@ -781,6 +1764,25 @@ func (s *nudgeService) scheduleInitialReminder(task *Task) {
 	}
 }
 
+// publishStatusChanged announces a task's status change; snoozedUntil and the
+// due date it replaced are set for snoozes
+func (s *nudgeService) publishStatusChanged(task *Task, previousStatus common.TaskStatus, snoozedUntil, previousDue *time.Time) {
+	event := events.TaskStatusChanged{
+		Event:           events.NewEvent(),
+		TaskID:          string(task.ID),
+		UserID:          string(task.UserID),
+		FromStatus:      string(previousStatus),
+		ToStatus:        string(task.Status),
+		SnoozedUntil:    snoozedUntil,
+		PreviousDueDate: previousDue,
+	}
+	if err := s.eventBus.Publish(events.TopicTaskStatusChanged, event); err != nil {
+		s.logger.Error("Failed to publish TaskStatusChanged event",
+			zap.String("taskID", string(task.ID)),
+			zap.Error(err))
+	}
+}
+
 // cancelTaskReminders cancels all future reminders for a task
 func (s *nudgeService) cancelTaskReminders(taskID common.TaskID) {
 	if s.repository == nil {
@@ -835,10 +1837,15 @@ func (s *nudgeService) validateTaskActionRequest(event events.TaskActionRequeste
 
 	// Validate action is allowed
 	validActions := map[string]bool{
-		"done":     true,
-		"complete": true,
-		"delete":   true,
-		"snooze":   true,
+		"done":          true,
+		"complete":      true,
+		"delete":        true,
+		"snooze":        true,
+		"start":         true,
+		"wait":          true,
+		"mute":          true,
+		"unmute":        true,
+		"test_reminder": true,
 	}
 	if !validActions[event.Action] {
 		return NewInvalidTaskActionError(event.Action)
@@ -860,9 +1867,9 @@ func (s *nudgeService) validateTaskActionRequest(event events.TaskActionRequeste
 		return fmt.Errorf("failed to retrieve task: %w", err)
 	}
 
-	// Validate task belongs to the requesting user
-	if string(task.UserID) != event.UserID {
-		return fmt.Errorf("task %s does not belong to user %s", event.TaskID, event.UserID)
+	// Validate the requesting user may act on the task
+	if err := s.authorizeTaskAction(task, common.UserID(event.UserID), event.Action); err != nil {
+		return err
 	}
 
 	// Validate the action is valid for the current task status
@@ -873,14 +1880,57 @@ func (s *nudgeService) validateTaskActionRequest(event events.TaskActionRequeste
 	return nil
 }
 
+// authorizeTaskAction allows users to act on their own tasks and those in
+// their shared lists, and members of the task's workspace to act on it as far
+// as their role allows
+func (s *nudgeService) authorizeTaskAction(task *Task, userID common.UserID, action string) error {
+	if task.UserID == userID {
+		return nil
+	}
+	if task.ListID != "" && s.lists != nil {
+		member, err := s.lists.IsMember(task.ListID, userID)
+		if err != nil {
+			return err
+		}
+		if member {
+			return nil
+		}
+	}
+	if s.workspaces == nil || task.ChatID == "" {
+		return fmt.Errorf("task %s does not belong to user %s", task.ID, userID)
+	}
+
+	var workspaceAction string
+	switch action {
+	case "done", "complete":
+		workspaceAction = ActionComplete
+	case "delete":
+		workspaceAction = ActionDelete
+	case "snooze":
+		workspaceAction = ActionSnooze
+	case "view":
+		workspaceAction = ActionView
+	default:
+		workspaceAction = ActionEdit
+	}
+	return s.workspaces.Authorize(task.ChatID, userID, workspaceAction)
+}
+
 // validateActionForTaskStatus validates if an action is valid for the current task status
 func (s *nudgeService) validateActionForTaskStatus(action string, currentStatus common.TaskStatus) error {
 	switch action {
 	case "done", "complete":
-		// Can only complete active or snoozed tasks
-		if currentStatus != common.TaskStatusActive && currentStatus != common.TaskStatusSnoozed {
+		if !CanTransition(currentStatus, common.TaskStatusCompleted) {
 			return fmt.Errorf("cannot complete task with status %s", currentStatus)
 		}
+	case "start":
+		if !CanTransition(currentStatus, common.TaskStatusInProgress) {
+			return fmt.Errorf("cannot start task with status %s", currentStatus)
+		}
+	case "wait":
+		if !CanTransition(currentStatus, common.TaskStatusWaiting) {
+			return fmt.Errorf("cannot mark task with status %s as waiting", currentStatus)
+		}
 	case "delete":
 		// Can delete tasks in any status except already deleted
 		if currentStatus == common.TaskStatusDeleted {
@@ -891,6 +1941,16 @@ func (s *nudgeService) validateActionForTaskStatus(action string, currentStatus
 		if currentStatus != common.TaskStatusActive {
 			return fmt.Errorf("can only snooze active tasks, current status is %s", currentStatus)
 		}
+	case "mute", "unmute":
+		// Only tasks that can still be reminded about
+		if !currentStatus.IsOpen() && currentStatus != common.TaskStatusSnoozed {
+			return fmt.Errorf("cannot %s a task with status %s", action, currentStatus)
+		}
+	case "test_reminder":
+		// Only tasks that can still be reminded about
+		if !currentStatus.IsOpen() && currentStatus != common.TaskStatusSnoozed {
+			return fmt.Errorf("cannot test reminders for a task with status %s", currentStatus)
+		}
 	}
 	return nil
 }
@@ -972,6 +2032,8 @@ func (s *nudgeService) publishTaskListErrorResponse(event events.TaskListRequest
 		Success:    false,
 		ErrorCode:  errorCode,
 		ErrorMsg:   errorMsg,
+		Page:       event.Page,
+		MessageID:  event.MessageID,
 	}
 
 	publishErr := s.eventBus.Publish(events.TopicTaskListResponse, response)
@@ -0,0 +1,414 @@
+package nudge
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func newBulkTestService(t *testing.T) (NudgeService, NudgeRepository, *events.MockEventBus) {
+	logger := zaptest.NewLogger(t)
+	eventBus := events.NewMockEventBus()
+	repo := NewMemoryNudgeRepository(logger)
+
+	service, err := NewNudgeService(eventBus, logger, repo)
+	require.NoError(t, err)
+
+	return service, repo, eventBus
+}
+
+func bulkTask(userID common.UserID, title string) *Task {
+	return &Task{
+		UserID:   userID,
+		ChatID:   "12345",
+		Title:    title,
+		Priority: common.PriorityMedium,
+		Status:   common.TaskStatusActive,
+	}
+}
+
+func TestNudgeService_CreateTasks(t *testing.T) {
+	t.Run("all tasks are stored and announced after commit", func(t *testing.T) {
+		service, repo, eventBus := newBulkTestService(t)
+		userID := common.UserID(common.NewID())
+
+		err := service.CreateTasks([]*Task{bulkTask(userID, "Buy milk"), bulkTask(userID, "Call mom")})
+		require.NoError(t, err)
+
+		stored, err := repo.GetTasksByUserID(userID, TaskFilter{UserID: userID})
+		require.NoError(t, err)
+		assert.Len(t, stored, 2)
+
+		created := eventBus.GetPublishedEvents(events.TopicTaskCreated)
+		require.Len(t, created, 2)
+		first := created[0].(events.TaskCreated)
+		assert.NotEmpty(t, first.BatchID)
+		assert.Equal(t, 2, first.BatchSize)
+
+		summaries := eventBus.GetPublishedEvents(events.TopicTasksCreated)
+		require.Len(t, summaries, 1)
+		assert.Equal(t, first.BatchID, summaries[0].(events.TasksCreated).BatchID)
+	})
+
+	t.Run("validation failures store nothing and report every task", func(t *testing.T) {
+		service, repo, eventBus := newBulkTestService(t)
+		userID := common.UserID(common.NewID())
+
+		err := service.CreateTasks([]*Task{
+			bulkTask(userID, "Buy milk"),
+			bulkTask(userID, ""),
+			bulkTask(userID, "Buy milk"),
+		})
+
+		var bulkErr BulkCreateError
+		require.True(t, errors.As(err, &bulkErr))
+		assert.Equal(t, 3, bulkErr.Total)
+		require.Len(t, bulkErr.Failures, 2)
+		assert.Equal(t, 1, bulkErr.Failures[0].Index)
+		assert.Equal(t, 2, bulkErr.Failures[1].Index)
+
+		stored, err := repo.GetTasksByUserID(userID, TaskFilter{UserID: userID})
+		require.NoError(t, err)
+		assert.Empty(t, stored)
+		assert.Empty(t, eventBus.GetPublishedEvents(events.TopicTaskCreated))
+	})
+
+	t.Run("repository failure rolls back the whole batch", func(t *testing.T) {
+		service, repo, eventBus := newBulkTestService(t)
+		userID := common.UserID(common.NewID())
+		require.NoError(t, repo.CreateTask(&Task{
+			ID:       common.TaskID(common.NewID()),
+			UserID:   userID,
+			Title:    "Call mom",
+			Priority: common.PriorityMedium,
+			Status:   common.TaskStatusActive,
+		}))
+
+		err := service.CreateTasks([]*Task{bulkTask(userID, "Buy milk"), bulkTask(userID, "Call mom")})
+
+		var bulkErr BulkCreateError
+		require.True(t, errors.As(err, &bulkErr))
+		require.Len(t, bulkErr.Failures, 1)
+		assert.Equal(t, 1, bulkErr.Failures[0].Index)
+
+		stored, err := repo.GetTasksByUserID(userID, TaskFilter{UserID: userID})
+		require.NoError(t, err)
+		assert.Len(t, stored, 1, "the first task must not survive the rollback")
+		assert.Empty(t, eventBus.GetPublishedEvents(events.TopicTaskCreated))
+	})
+}
+
+func TestNudgeService_FireTestReminder(t *testing.T) {
+	service, repo, eventBus := newBulkTestService(t)
+	userID := common.UserID(common.NewID())
+	task := bulkTask(userID, "Water plants")
+	task.ID = common.TaskID(common.NewID())
+	require.NoError(t, service.CreateTask(task))
+
+	remindersBefore, err := repo.GetRemindersByTaskID(task.ID)
+	require.NoError(t, err)
+
+	require.NoError(t, service.FireTestReminder(task.ID, "67890"))
+
+	published := eventBus.GetPublishedEvents(events.TopicReminderDue)
+	require.Len(t, published, 1)
+	reminder := published[0].(events.ReminderDue)
+	assert.True(t, reminder.Test)
+	assert.Equal(t, string(task.ID), reminder.TaskID)
+	assert.Equal(t, "67890", reminder.ChatID)
+
+	remindersAfter, err := repo.GetRemindersByTaskID(task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, remindersBefore, remindersAfter, "test reminders must not touch the schedule")
+
+	assert.Error(t, service.FireTestReminder(common.TaskID(common.NewID()), "67890"))
+}
+
+func TestNudgeService_RemindsInTheTaskForumTopic(t *testing.T) {
+	service, repo, eventBus := newBulkTestService(t)
+	userID := common.UserID(common.NewID())
+
+	service.(*nudgeService).handleTaskParsed(events.TaskParsed{
+		Event:      events.NewEvent(),
+		UserID:     string(userID),
+		ChatID:     "-100123",
+		ParsedTask: events.ParsedTask{Title: "Review PR", Priority: "medium"},
+		ThreadID:   7,
+	})
+
+	tasks, err := repo.GetTasksByUserID(userID, TaskFilter{UserID: userID})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, 7, tasks[0].ThreadID)
+
+	// The topic only applies in the task's own chat
+	require.NoError(t, service.FireTestReminder(tasks[0].ID, ""))
+	require.NoError(t, service.FireTestReminder(tasks[0].ID, "67890"))
+	published := eventBus.GetPublishedEvents(events.TopicReminderDue)
+	require.Len(t, published, 2)
+	assert.Equal(t, 7, published[0].(events.ReminderDue).ThreadID)
+	assert.Equal(t, 0, published[1].(events.ReminderDue).ThreadID)
+}
+
+func TestNudgeService_StopWaitsForReminderScheduling(t *testing.T) {
+	service, repo, _ := newBulkTestService(t)
+	require.NoError(t, service.Start(context.Background()))
+	require.NoError(t, service.Health())
+
+	task := bulkTask(common.UserID(common.NewID()), "Pay rent")
+	task.ID = common.TaskID(common.NewID())
+	dueDate := time.Now().Add(48 * time.Hour)
+	task.DueDate = &dueDate
+	require.NoError(t, service.CreateTask(task))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, service.Stop(ctx))
+
+	// The initial reminder is scheduled in the background before Stop returns
+	reminders, err := repo.GetRemindersByTaskID(task.ID)
+	require.NoError(t, err)
+	assert.NotEmpty(t, reminders)
+	assert.ErrorIs(t, service.Health(), common.ErrServiceStopped)
+}
+
+func TestNudgeService_ScheduleReminderRequiresChat(t *testing.T) {
+	service, repo, _ := newBulkTestService(t)
+
+	task := bulkTask(common.UserID(common.NewID()), "Call the bank")
+	task.ID = common.TaskID(common.NewID())
+	task.ChatID = ""
+	require.NoError(t, repo.CreateTask(task))
+
+	err := service.ScheduleReminder(task.ID, time.Now().Add(time.Hour), ReminderTypeInitial)
+	var schedulingErr ReminderSchedulingError
+	assert.True(t, errors.As(err, &schedulingErr))
+
+	reminders, err := repo.GetRemindersByTaskID(task.ID)
+	require.NoError(t, err)
+	assert.Empty(t, reminders)
+}
+
+func TestNudgeService_CustomFields(t *testing.T) {
+	service, _, eventBus := newBulkTestService(t)
+	userID := common.UserID(common.NewID())
+	task := bulkTask(userID, "Quarterly budget")
+	task.ID = common.TaskID(common.NewID())
+	require.NoError(t, service.CreateTask(task))
+	other := bulkTask(userID, "Team offsite")
+	other.ID = common.TaskID(common.NewID())
+	require.NoError(t, service.CreateTask(other))
+
+	budget, err := NewCustomField(CustomFieldNumber, "250.50", nil)
+	require.NoError(t, err)
+	stage, err := NewCustomField(CustomFieldEnum, "DOING", []string{"todo", "doing", "done"})
+	require.NoError(t, err)
+	assert.Equal(t, "doing", stage.Value)
+
+	_, err = service.SetCustomField(task.ID, "budget", budget)
+	require.NoError(t, err)
+	updated, err := service.SetCustomField(task.ID, "stage", stage)
+	require.NoError(t, err)
+	assert.Equal(t, "250.5", updated.CustomFields["budget"].Value)
+
+	edits := eventBus.GetPublishedEvents(events.TopicTaskEdited)
+	require.Len(t, edits, 2)
+	assert.Equal(t, []string{"custom_fields.stage"}, edits[1].(events.TaskEdited).Fields)
+
+	tasks, err := service.GetTasks(userID, TaskFilter{UserID: userID, CustomFields: map[string]string{"stage": "doing"}})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, task.ID, tasks[0].ID)
+
+	cleared, err := service.RemoveCustomField(task.ID, "stage")
+	require.NoError(t, err)
+	assert.NotContains(t, cleared.CustomFields, "stage")
+
+	_, err = service.SetCustomField(task.ID, "Bad Key", budget)
+	assert.True(t, IsValidationError(err))
+}
+
+func TestNudgeService_RescheduleToday(t *testing.T) {
+	service, repo, eventBus := newBulkTestService(t)
+	eventBus.SetSynchronousMode(true)
+	userID := common.UserID(common.NewID())
+
+	start, end := dayBounds(time.Now().UTC())
+	dueToday := start.Add(time.Minute)
+	dueTomorrow := end.Add(time.Hour)
+	today := bulkTask(userID, "Send invoices")
+	today.ID = common.TaskID(common.NewID())
+	today.DueDate = &dueToday
+	later := bulkTask(userID, "Plan sprint")
+	later.ID = common.TaskID(common.NewID())
+	later.DueDate = &dueTomorrow
+	require.NoError(t, service.CreateTask(today))
+	require.NoError(t, service.CreateTask(later))
+
+	request := events.TaskRescheduleRequested{
+		Event:    events.NewEvent(),
+		UserID:   string(userID),
+		ChatID:   "12345",
+		Command:  RescheduleSnoozeAll,
+		Argument: "2h",
+	}
+	require.NoError(t, eventBus.Publish(events.TopicTaskRescheduleRequested, request))
+
+	// The preview lists today's task without moving it
+	responses := eventBus.GetPublishedEvents(events.TopicTaskRescheduleResponse)
+	require.Len(t, responses, 1)
+	preview := responses[0].(events.TaskRescheduleResponse)
+	assert.True(t, preview.Success)
+	assert.False(t, preview.Applied)
+	assert.Equal(t, 2*time.Hour, preview.Shift)
+	require.Len(t, preview.Tasks, 1)
+	assert.Equal(t, string(today.ID), preview.Tasks[0].ID)
+	stored, err := repo.GetTaskByID(today.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.DueDate.Equal(dueToday))
+
+	request.TaskIDs = []string{string(today.ID)}
+	require.NoError(t, eventBus.Publish(events.TopicTaskRescheduleRequested, request))
+
+	responses = eventBus.GetPublishedEvents(events.TopicTaskRescheduleResponse)
+	require.Len(t, responses, 2)
+	applied := responses[1].(events.TaskRescheduleResponse)
+	assert.True(t, applied.Applied)
+	require.Len(t, applied.Tasks, 1)
+	stored, err = repo.GetTaskByID(today.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.DueDate.Equal(dueToday.Add(2*time.Hour)))
+
+	t.Run("other users' tasks are not moved", func(t *testing.T) {
+		moved, err := service.ShiftTaskDueDates(common.UserID(common.NewID()), []common.TaskID{later.ID}, DueShift{Duration: time.Hour})
+		require.NoError(t, err)
+		assert.Empty(t, moved)
+	})
+
+	t.Run("invalid argument is reported", func(t *testing.T) {
+		request.TaskIDs = nil
+		request.Argument = "soon"
+		require.NoError(t, eventBus.Publish(events.TopicTaskRescheduleRequested, request))
+		responses := eventBus.GetPublishedEvents(events.TopicTaskRescheduleResponse)
+		failed := responses[len(responses)-1].(events.TaskRescheduleResponse)
+		assert.False(t, failed.Success)
+		assert.Contains(t, failed.Message, "duration")
+	})
+}
+
+func TestNudgeService_ShiftTaskDueDatesByCalendarDays(t *testing.T) {
+	service, repo, _ := newBulkTestService(t)
+	userID := common.UserID(common.NewID())
+	require.NoError(t, repo.CreateOrUpdateNudgeSettings(&NudgeSettings{UserID: userID, NudgeInterval: DefaultNudgeInterval, MaxNudges: DefaultMaxNudges, Timezone: "Europe/Berlin"}))
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	// One task before and one after clocks go forward on 28 March 2027
+	beforeDue := time.Date(2027, 3, 27, 9, 0, 0, 0, berlin)
+	afterDue := time.Date(2027, 3, 30, 9, 0, 0, 0, berlin)
+	before := bulkTask(userID, "Water plants")
+	before.ID = common.TaskID(common.NewID())
+	before.DueDate = &beforeDue
+	after := bulkTask(userID, "Pay rent")
+	after.ID = common.TaskID(common.NewID())
+	after.DueDate = &afterDue
+	require.NoError(t, repo.CreateTask(before))
+	require.NoError(t, repo.CreateTask(after))
+
+	moved, err := service.ShiftTaskDueDates(userID, []common.TaskID{before.ID, after.ID}, DueShift{Days: 2})
+	require.NoError(t, err)
+	require.Len(t, moved, 2)
+
+	stored, err := repo.GetTaskByID(before.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.DueDate.Equal(time.Date(2027, 3, 29, 9, 0, 0, 0, berlin)), "got %s", stored.DueDate.In(berlin))
+	stored, err = repo.GetTaskByID(after.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.DueDate.Equal(time.Date(2027, 4, 1, 9, 0, 0, 0, berlin)), "got %s", stored.DueDate.In(berlin))
+}
+
+func TestNudgeService_TaskLinks(t *testing.T) {
+	service, _, _ := newBulkTestService(t)
+	userID := common.UserID(common.NewID())
+
+	task := bulkTask(userID, "Read this article https://WWW.Example.com/post/?utm_source=feed#intro")
+	task.ID = common.TaskID(common.NewID())
+	require.NoError(t, service.CreateTask(task))
+	assert.Equal(t, "Read this article", task.Title)
+	require.Len(t, task.Links, 1)
+	assert.Equal(t, "https://www.example.com/post", task.Links[0].URL)
+	assert.Equal(t, "example.com", task.Links[0].Host)
+
+	duplicate := bulkTask(userID, "Share with the team")
+	duplicate.ID = common.TaskID(common.NewID())
+	duplicate.Description = "see https://www.example.com/post"
+	err := service.CreateTask(duplicate)
+	assert.True(t, IsValidationError(err), "same link for the same user is a duplicate")
+
+	otherUser := bulkTask(common.UserID(common.NewID()), "Read this article https://www.example.com/post")
+	otherUser.ID = common.TaskID(common.NewID())
+	assert.NoError(t, service.CreateTask(otherUser))
+
+	batch := []*Task{
+		bulkTask(userID, "Skim https://go.dev/blog"),
+		bulkTask(userID, "Summarize https://go.dev/blog/"),
+	}
+	var bulkErr BulkCreateError
+	require.ErrorAs(t, service.CreateTasks(batch), &bulkErr)
+	require.Len(t, bulkErr.Failures, 1)
+	assert.Equal(t, 1, bulkErr.Failures[0].Index)
+}
+
+func TestNudgeService_MuteAndUnmute(t *testing.T) {
+	service, repo, eventBus := newBulkTestService(t)
+	userID := common.UserID(common.NewID())
+	task := bulkTask(userID, "Stretch")
+	task.ID = common.TaskID(common.NewID())
+	dueDate := time.Now().Add(48 * time.Hour)
+	task.DueDate = &dueDate
+	require.NoError(t, repo.CreateTask(task))
+
+	act := func(action string) events.TaskActionResponse {
+		service.(*nudgeService).handleTaskActionRequested(events.TaskActionRequested{
+			Event:  events.NewEvent(),
+			UserID: string(userID),
+			ChatID: "12345",
+			TaskID: string(task.ID),
+			Action: action,
+		})
+		responses := eventBus.GetPublishedEvents(events.TopicTaskActionResponse)
+		return responses[len(responses)-1].(events.TaskActionResponse)
+	}
+
+	assert.True(t, act("mute").Success)
+	stored, err := repo.GetTaskByID(task.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.Muted)
+	assert.Equal(t, common.TaskStatusActive, stored.Status, "muting keeps the task open")
+
+	assert.True(t, act("unmute").Success)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, service.Stop(ctx))
+
+	stored, err = repo.GetTaskByID(task.ID)
+	require.NoError(t, err)
+	assert.False(t, stored.Muted)
+	reminders, err := repo.GetRemindersByTaskID(task.ID)
+	require.NoError(t, err)
+	assert.Len(t, reminders, 1, "unmuting schedules a fresh reminder")
+
+	stored.Status = common.TaskStatusCompleted
+	now := time.Now()
+	stored.CompletedAt = &now
+	require.NoError(t, repo.UpdateTask(stored))
+	assert.False(t, act("mute").Success, "closed tasks have no reminders to mute")
+}
@@ -0,0 +1,166 @@
+package nudge
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/holiday"
+
+	"go.uber.org/zap"
+)
+
+// languageTag matches the languages users can choose, such as "vi" or "pt-br"
+var languageTag = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})?$`)
+
+// ValidLanguage reports whether language is empty or a lowercase language tag
+func ValidLanguage(language string) bool {
+	return language == "" || languageTag.MatchString(language)
+}
+
+// userSettings returns the settings the /settings menu shows
+func userSettings(settings *NudgeSettings) events.UserSettings {
+	return events.UserSettings{
+		NudgeInterval:   settings.NudgeInterval,
+		MaxNudges:       settings.MaxNudges,
+		QuietHoursStart: settings.QuietHoursStart,
+		QuietHoursEnd:   settings.QuietHoursEnd,
+		Timezone:        settings.Timezone,
+		Language:        settings.Language,
+		WeekStart:       settings.WeekStart,
+		WorkingDays:     settings.WorkingDays,
+		Country:         settings.Country,
+	}
+}
+
+// applySetting changes one setting to a /settings menu value, returning why
+// the user's value was refused, or "" when it was applied
+func applySetting(settings *NudgeSettings, field, value string) string {
+	value = strings.TrimSpace(value)
+
+	switch field {
+	case events.SettingInterval:
+		interval, err := common.ParseDuration(value)
+		if err != nil || interval < MinNudgeInterval || interval > MaxNudgeInterval {
+			return "Reminders can be between 15m and 24h apart."
+		}
+		settings.NudgeInterval = interval
+	case events.SettingMaxNudges:
+		count, err := strconv.Atoi(value)
+		if err != nil || count < 1 || count > MaxNudgesPerTask {
+			return fmt.Sprintf("Tasks can be reminded of 1 to %d times.", MaxNudgesPerTask)
+		}
+		settings.MaxNudges = count
+	case events.SettingQuietHours:
+		if strings.EqualFold(value, events.SettingOff) {
+			settings.QuietHoursStart, settings.QuietHoursEnd = "", ""
+			break
+		}
+		start, end, _ := strings.Cut(value, "-")
+		_, startErr := parseClockMinutes(start)
+		_, endErr := parseClockMinutes(end)
+		if startErr != nil || endErr != nil {
+			return "Quiet hours look like 22:00-07:00."
+		}
+		settings.QuietHoursStart, settings.QuietHoursEnd = start, end
+	case events.SettingTimezone:
+		if _, err := time.LoadLocation(value); err != nil || value == "" || value == "Local" {
+			return fmt.Sprintf("%q is not a timezone; use a name such as Europe/Berlin.", value)
+		}
+		settings.Timezone = value
+	case events.SettingLanguage:
+		language := strings.ToLower(value)
+		if language == events.SettingOff {
+			language = ""
+		}
+		if !ValidLanguage(language) {
+			return fmt.Sprintf("%q is not a language; use a code such as en or vi.", value)
+		}
+		settings.Language = language
+	case events.SettingWeekStart:
+		weekday, ok := common.ParseWeekday(value)
+		if !ok {
+			return fmt.Sprintf("%q is not a weekday; use a day such as sunday or monday.", value)
+		}
+		settings.WeekStart = strings.ToLower(weekday.String())
+	case events.SettingWorkingDays:
+		days, err := common.ParseWorkingDays(value)
+		if err != nil {
+			return fmt.Sprintf("%q are not working days; use days such as mon-fri or sun,mon,tue.", value)
+		}
+		settings.WorkingDays = common.FormatWorkingDays(days)
+	case events.SettingCountry:
+		if strings.EqualFold(value, events.SettingOff) {
+			settings.Country = ""
+			break
+		}
+		country, ok := holiday.NormalizeCountry(value)
+		if !ok {
+			return fmt.Sprintf("%q is not a country; use a two-letter code such as US or DE.", value)
+		}
+		settings.Country = country
+	default:
+		return fmt.Sprintf("There is no setting called %q.", field)
+	}
+	return ""
+}
+
+// handleSettingsRequested shows the user's settings, changing one of them
+// first when the request chose an option
+func (s *nudgeService) handleSettingsRequested(event events.SettingsRequested) {
+	s = s.forUser(event.UserID)
+	response := events.SettingsResponse{
+		Event:     events.NewEvent(),
+		UserID:    event.UserID,
+		ChatID:    event.ChatID,
+		MessageID: event.MessageID,
+		Page:      event.Page,
+	}
+
+	settings, err := s.GetNudgeSettings(common.UserID(event.UserID))
+	if err != nil {
+		s.logger.Error("Failed to load nudge settings for the settings menu",
+			zap.String("correlationID", event.CorrelationID),
+			zap.String("userID", event.UserID),
+			zap.Error(err))
+		response.Message = "Something went wrong, please try again later."
+		s.publishSettingsResponse(response)
+		return
+	}
+	response.Settings = userSettings(settings)
+	response.Success = true
+
+	if event.Field != "" {
+		changed := *settings
+		if problem := applySetting(&changed, event.Field, event.Value); problem != "" {
+			response.Success = false
+			response.Message = problem
+		} else if err := s.UpdateNudgeSettings(&changed); err != nil {
+			response.Success = false
+			response.Message = "Something went wrong, please try again later."
+			var validationErr TaskValidationError
+			if errors.As(err, &validationErr) {
+				response.Message = fmt.Sprintf("That setting can't be saved: %s.", validationErr.Message())
+			}
+		} else {
+			response.Settings = userSettings(&changed)
+			response.Changed = event.Field
+		}
+	}
+
+	s.publishSettingsResponse(response)
+}
+
+// publishSettingsResponse sends the settings menu back to the chat
+func (s *nudgeService) publishSettingsResponse(response events.SettingsResponse) {
+	if err := s.eventBus.Publish(events.TopicSettingsResponse, response); err != nil {
+		s.logger.Error("Failed to publish SettingsResponse event",
+			zap.String("userID", response.UserID),
+			zap.Error(err))
+	}
+}
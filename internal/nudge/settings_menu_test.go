@@ -0,0 +1,127 @@
+package nudge
+
+import (
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplySetting(t *testing.T) {
+	tests := []struct {
+		field   string
+		value   string
+		refused bool
+		check   func(t *testing.T, settings *NudgeSettings)
+	}{
+		{events.SettingInterval, "30m", false, func(t *testing.T, s *NudgeSettings) { assert.Equal(t, 30*time.Minute, s.NudgeInterval) }},
+		{events.SettingInterval, "5m", true, nil},
+		{events.SettingInterval, "2d", true, nil},
+		{events.SettingMaxNudges, "5", false, func(t *testing.T, s *NudgeSettings) { assert.Equal(t, 5, s.MaxNudges) }},
+		{events.SettingMaxNudges, "0", true, nil},
+		{events.SettingMaxNudges, "many", true, nil},
+		{events.SettingQuietHours, "22:00-07:00", false, func(t *testing.T, s *NudgeSettings) {
+			assert.Equal(t, "22:00", s.QuietHoursStart)
+			assert.Equal(t, "07:00", s.QuietHoursEnd)
+		}},
+		{events.SettingQuietHours, "OFF", false, func(t *testing.T, s *NudgeSettings) { assert.Empty(t, s.QuietHoursStart+s.QuietHoursEnd) }},
+		{events.SettingQuietHours, "22:00", true, nil},
+		{events.SettingTimezone, "Asia/Tokyo", false, func(t *testing.T, s *NudgeSettings) { assert.Equal(t, "Asia/Tokyo", s.Timezone) }},
+		{events.SettingTimezone, "Mars/Olympus", true, nil},
+		{events.SettingTimezone, "Local", true, nil},
+		{events.SettingLanguage, "PT-BR", false, func(t *testing.T, s *NudgeSettings) { assert.Equal(t, "pt-br", s.Language) }},
+		{events.SettingLanguage, "off", false, func(t *testing.T, s *NudgeSettings) { assert.Empty(t, s.Language) }},
+		{events.SettingLanguage, "english!", true, nil},
+		{events.SettingWeekStart, "Sun", false, func(t *testing.T, s *NudgeSettings) { assert.Equal(t, "sunday", s.WeekStart) }},
+		{events.SettingWeekStart, "someday", true, nil},
+		{events.SettingWorkingDays, "sun-thu", false, func(t *testing.T, s *NudgeSettings) { assert.Equal(t, "sun,mon,tue,wed,thu", s.WorkingDays) }},
+		{events.SettingWorkingDays, "weekdays", true, nil},
+		{events.SettingCountry, "de", false, func(t *testing.T, s *NudgeSettings) { assert.Equal(t, "DE", s.Country) }},
+		{events.SettingCountry, "off", false, func(t *testing.T, s *NudgeSettings) { assert.Empty(t, s.Country) }},
+		{events.SettingCountry, "Germany", true, nil},
+		{"colour", "blue", true, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field+"="+tt.value, func(t *testing.T) {
+			settings := &NudgeSettings{NudgeInterval: time.Hour, MaxNudges: 3, QuietHoursStart: "23:00", QuietHoursEnd: "08:00", Language: "vi"}
+			problem := applySetting(settings, tt.field, tt.value)
+			if tt.refused {
+				assert.NotEmpty(t, problem)
+				return
+			}
+			assert.Empty(t, problem)
+			tt.check(t, settings)
+		})
+	}
+}
+
+func TestValidateNudgeSettings_Language(t *testing.T) {
+	settings := &NudgeSettings{UserID: common.UserID(common.NewID()), NudgeInterval: DefaultNudgeInterval, MaxNudges: DefaultMaxNudges, Language: "vi"}
+	assert.NoError(t, ValidateNudgeSettings(settings))
+
+	settings.Language = "Vietnamese"
+	assert.Error(t, ValidateNudgeSettings(settings))
+}
+
+func TestValidateNudgeSettings_Week(t *testing.T) {
+	settings := &NudgeSettings{UserID: common.UserID(common.NewID()), NudgeInterval: DefaultNudgeInterval, MaxNudges: DefaultMaxNudges, WeekStart: "sunday", WorkingDays: "sun,mon,tue,wed,thu"}
+	assert.NoError(t, ValidateNudgeSettings(settings))
+	assert.Equal(t, common.NewWeek("sunday", "sun-thu"), settings.Week())
+
+	settings.WeekStart = "funday"
+	assert.Error(t, ValidateNudgeSettings(settings))
+
+	settings.WeekStart = ""
+	settings.WorkingDays = "weekdays"
+	assert.Error(t, ValidateNudgeSettings(settings))
+}
+
+func TestValidateNudgeSettings_Country(t *testing.T) {
+	settings := &NudgeSettings{UserID: common.UserID(common.NewID()), NudgeInterval: DefaultNudgeInterval, MaxNudges: DefaultMaxNudges, Country: "GB"}
+	assert.NoError(t, ValidateNudgeSettings(settings))
+
+	settings.Country = "GBR"
+	assert.Error(t, ValidateNudgeSettings(settings))
+}
+
+func TestNudgeService_SettingsMenu(t *testing.T) {
+	_, repo, eventBus := newBulkTestService(t)
+	eventBus.SetSynchronousMode(true)
+	userID := common.UserID(common.NewID())
+	request := func(field, value string) events.SettingsResponse {
+		eventBus.ClearEvents()
+		require.NoError(t, eventBus.Publish(events.TopicSettingsRequested, events.SettingsRequested{
+			Event: events.NewEvent(), UserID: string(userID), ChatID: "12345", MessageID: 7, Field: field, Value: value,
+		}))
+		responses := eventBus.GetPublishedEvents(events.TopicSettingsResponse)
+		require.Len(t, responses, 1)
+		return responses[0].(events.SettingsResponse)
+	}
+
+	summary := request("", "")
+	assert.True(t, summary.Success)
+	assert.Equal(t, 7, summary.MessageID)
+	assert.Empty(t, summary.Changed)
+	assert.Equal(t, time.Hour, summary.Settings.NudgeInterval)
+
+	changed := request(events.SettingTimezone, "Europe/Berlin")
+	assert.True(t, changed.Success)
+	assert.Equal(t, events.SettingTimezone, changed.Changed)
+	assert.Equal(t, "Europe/Berlin", changed.Settings.Timezone)
+	settings, err := repo.GetNudgeSettingsByUserID(userID)
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Berlin", settings.Timezone)
+
+	refused := request(events.SettingMaxNudges, "99")
+	assert.False(t, refused.Success)
+	assert.NotEmpty(t, refused.Message)
+	assert.Equal(t, "Europe/Berlin", refused.Settings.Timezone, "the menu still shows the saved settings")
+	settings, err = repo.GetNudgeSettingsByUserID(userID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, settings.MaxNudges)
+}
@@ -0,0 +1,384 @@
+package nudge
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// maxListNameLength bounds shared list names so they fit in buttons and messages
+const maxListNameLength = 64
+
+// Shared list errors
+var (
+	ErrListNotFound       = errors.New("shared list not found")
+	ErrListInviteNotFound = errors.New("shared list invite not found")
+)
+
+// SharedList is a named task list, such as "Groceries", that several users
+// join through its invite link. Tasks in the list are visible to all members.
+type SharedList struct {
+	ID         common.ID     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID   string        `json:"tenant_id,omitempty" gorm:"type:varchar(64);not null;default:'default';index"`
+	Name       string        `json:"name" gorm:"type:varchar(64);not null"`
+	OwnerID    common.UserID `json:"owner_id" gorm:"type:varchar(36);not null;index"`
+	InviteCode string        `json:"invite_code" gorm:"type:varchar(32);not null;uniqueIndex"`
+	CreatedAt  time.Time     `json:"created_at" gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+}
+
+// TableName returns the table name for the SharedList model
+func (SharedList) TableName() string {
+	return "shared_lists"
+}
+
+// SharedListMember is a user's membership in a shared list. ChatID is where
+// the member is told about other members' activity in the list.
+type SharedListMember struct {
+	ListID    common.ID     `json:"list_id" gorm:"primaryKey;type:varchar(36)"`
+	UserID    common.UserID `json:"user_id" gorm:"primaryKey;type:varchar(36);index"`
+	TenantID  string        `json:"tenant_id,omitempty" gorm:"type:varchar(64);not null;default:'default';index"`
+	ChatID    common.ChatID `json:"chat_id" gorm:"type:varchar(36);not null"`
+	CreatedAt time.Time     `json:"created_at" gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+}
+
+// TableName returns the table name for the SharedListMember model
+func (SharedListMember) TableName() string {
+	return "shared_list_members"
+}
+
+// SharedListService manages shared lists and their members
+type SharedListService interface {
+	CreateList(ownerID common.UserID, chatID common.ChatID, name string) (*SharedList, error)
+	Join(code string, userID common.UserID, chatID common.ChatID) (*SharedList, error)
+	FindList(userID common.UserID, name string) (*SharedList, error)
+	GetList(listID common.ID) (*SharedList, error)
+	ListsForUser(userID common.UserID) ([]*SharedList, error)
+	ListMembers(listID common.ID) ([]*SharedListMember, error)
+	IsMember(listID common.ID, userID common.UserID) (bool, error)
+	Ready() <-chan struct{}
+}
+
+// sharedListService implements the SharedListService interface
+type sharedListService struct {
+	eventBus   events.EventBus
+	logger     *zap.Logger
+	repository SharedListRepository
+	ready      *common.Readiness
+}
+
+// NewSharedListService creates a SharedListService
+func NewSharedListService(eventBus events.EventBus, logger *zap.Logger, repository SharedListRepository) SharedListService {
+	service := &sharedListService{
+		eventBus:   eventBus,
+		logger:     logger,
+		repository: repository,
+		ready:      common.NewReadiness(),
+	}
+
+	service.setupEventSubscriptions()
+
+	return service
+}
+
+// setupEventSubscriptions sets up event subscriptions for the shared list service
+func (s *sharedListService) setupEventSubscriptions() {
+	if err := s.eventBus.Subscribe(events.TopicListCreateRequested, s.handleCreateRequested); err != nil {
+		s.logger.Error("Failed to subscribe to ListCreateRequested events", zap.Error(err))
+	}
+
+	if err := s.eventBus.Subscribe(events.TopicListJoinRequested, s.handleJoinRequested); err != nil {
+		s.logger.Error("Failed to subscribe to ListJoinRequested events", zap.Error(err))
+	}
+
+	if err := s.eventBus.Subscribe(events.TopicListTaskRequested, s.handleTaskRequested); err != nil {
+		s.logger.Error("Failed to subscribe to ListTaskRequested events", zap.Error(err))
+	}
+
+	if err := s.eventBus.Subscribe(events.TopicListsRequested, s.handleListsRequested); err != nil {
+		s.logger.Error("Failed to subscribe to ListsRequested events", zap.Error(err))
+	}
+
+	s.ready.MarkReady()
+}
+
+// Ready returns a channel that is closed once event subscriptions are registered
+func (s *sharedListService) Ready() <-chan struct{} {
+	return s.ready.Ready()
+}
+
+// CreateList creates a list owned by the user, who becomes its first member.
+// A user cannot be in two lists with the same name.
+func (s *sharedListService) CreateList(ownerID common.UserID, chatID common.ChatID, name string) (*SharedList, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" || utf8.RuneCountInString(name) > maxListNameLength {
+		return nil, NewTaskValidationError("name", name, fmt.Sprintf("list name must be 1 to %d characters", maxListNameLength))
+	}
+
+	existing, err := s.FindList(ownerID, name)
+	if err != nil && !errors.Is(err, ErrListNotFound) {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, NewBusinessRuleError("duplicate_list", fmt.Sprintf("you already have a list called %q", existing.Name))
+	}
+
+	list := &SharedList{
+		ID:         common.NewID(),
+		Name:       name,
+		OwnerID:    ownerID,
+		InviteCode: strings.ReplaceAll(string(common.NewID()), "-", ""),
+	}
+	if err := s.repository.CreateList(list); err != nil {
+		return nil, err
+	}
+	if err := s.repository.SaveMember(&SharedListMember{ListID: list.ID, UserID: ownerID, ChatID: chatID}); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Shared list created",
+		zap.String("listID", string(list.ID)),
+		zap.String("ownerID", string(ownerID)))
+	return list, nil
+}
+
+// Join redeems a list's invite code. Members who join again only update the
+// chat they are notified in.
+func (s *sharedListService) Join(code string, userID common.UserID, chatID common.ChatID) (*SharedList, error) {
+	list, err := s.repository.GetListByCode(code)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		return nil, ErrListInviteNotFound
+	}
+
+	existing, err := s.FindList(userID, list.Name)
+	if err != nil && !errors.Is(err, ErrListNotFound) {
+		return nil, err
+	}
+	if existing != nil && existing.ID != list.ID {
+		return nil, NewBusinessRuleError("duplicate_list", fmt.Sprintf("you already have a list called %q", existing.Name))
+	}
+
+	if err := s.repository.SaveMember(&SharedListMember{ListID: list.ID, UserID: userID, ChatID: chatID}); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("User joined shared list",
+		zap.String("listID", string(list.ID)),
+		zap.String("userID", string(userID)))
+	return list, nil
+}
+
+// FindList returns the user's list with the given name, ignoring case
+func (s *sharedListService) FindList(userID common.UserID, name string) (*SharedList, error) {
+	lists, err := s.repository.ListsForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	name = strings.Join(strings.Fields(name), " ")
+	for _, list := range lists {
+		if strings.EqualFold(list.Name, name) {
+			return list, nil
+		}
+	}
+	return nil, ErrListNotFound
+}
+
+// GetList returns a list by ID
+func (s *sharedListService) GetList(listID common.ID) (*SharedList, error) {
+	list, err := s.repository.GetList(listID)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		return nil, ErrListNotFound
+	}
+	return list, nil
+}
+
+// ListsForUser returns the lists the user is a member of
+func (s *sharedListService) ListsForUser(userID common.UserID) ([]*SharedList, error) {
+	return s.repository.ListsForUser(userID)
+}
+
+// ListMembers returns the members of a list
+func (s *sharedListService) ListMembers(listID common.ID) ([]*SharedListMember, error) {
+	return s.repository.ListMembers(listID)
+}
+
+// IsMember reports whether the user is a member of the list
+func (s *sharedListService) IsMember(listID common.ID, userID common.UserID) (bool, error) {
+	member, err := s.repository.GetMember(listID, userID)
+	if err != nil {
+		return false, err
+	}
+	return member != nil, nil
+}
+
+// handleCreateRequested creates a list for a /newlist command
+func (s *sharedListService) handleCreateRequested(event events.ListCreateRequested) {
+	response := s.newResponse(event.Event, event.UserID, event.ChatID, events.ListActionCreate)
+
+	list, err := s.CreateList(common.UserID(event.UserID), common.ChatID(event.ChatID), event.Name)
+	if err != nil {
+		s.logger.Warn("Failed to create shared list",
+			zap.String("correlationID", event.CorrelationID),
+			zap.String("userID", event.UserID),
+			zap.Error(err))
+		response.Message = listErrorMessage(err)
+	} else {
+		response.Success = true
+		response.ListID = string(list.ID)
+		response.Name = list.Name
+		response.Code = list.InviteCode
+		response.Message = "Share the link below to let others join. Add tasks with /addto " + list.Name + ": ..."
+	}
+
+	s.publishResponse(response)
+}
+
+// handleJoinRequested redeems a list invite opened from a deep link
+func (s *sharedListService) handleJoinRequested(event events.ListJoinRequested) {
+	response := s.newResponse(event.Event, event.UserID, event.ChatID, events.ListActionJoin)
+
+	list, err := s.Join(event.Code, common.UserID(event.UserID), common.ChatID(event.ChatID))
+	if err != nil {
+		s.logger.Warn("Failed to join shared list",
+			zap.String("correlationID", event.CorrelationID),
+			zap.String("userID", event.UserID),
+			zap.Error(err))
+		response.Message = listErrorMessage(err)
+	} else {
+		response.Success = true
+		response.ListID = string(list.ID)
+		response.Name = list.Name
+		response.Message = fmt.Sprintf("Its tasks now show up in /list. Add tasks with /addto %s: ...", list.Name)
+	}
+
+	s.publishResponse(response)
+}
+
+// handleTaskRequested finds the list named in an /addto command and passes
+// the message on to be parsed into tasks for it
+func (s *sharedListService) handleTaskRequested(event events.ListTaskRequested) {
+	list, err := s.FindList(common.UserID(event.UserID), event.List)
+	if err != nil {
+		s.logger.Warn("Failed to find shared list for task",
+			zap.String("correlationID", event.CorrelationID),
+			zap.String("userID", event.UserID),
+			zap.Error(err))
+
+		response := s.newResponse(event.Event, event.UserID, event.ChatID, events.ListActionAdd)
+		response.Name = event.List
+		response.Message = listErrorMessage(err)
+		s.publishResponse(response)
+		return
+	}
+
+	message := events.MessageReceived{
+		Event:       events.NewEvent(),
+		UserID:      event.UserID,
+		ChatID:      event.ChatID,
+		MessageText: event.MessageText,
+		Locale:      event.Locale,
+		ListID:      string(list.ID),
+	}
+	message.CorrelationID = event.CorrelationID
+
+	if err := s.eventBus.Publish(events.TopicMessageReceived, message); err != nil {
+		s.logger.Error("Failed to publish MessageReceived for shared list", zap.Error(err))
+	}
+}
+
+// handleListsRequested lists the user's shared lists for a /lists command
+func (s *sharedListService) handleListsRequested(event events.ListsRequested) {
+	response := events.ListsResponse{
+		Event:  events.NewEvent(),
+		UserID: event.UserID,
+		ChatID: event.ChatID,
+	}
+	response.CorrelationID = event.CorrelationID
+
+	summaries, err := s.summarize(common.UserID(event.UserID))
+	if err != nil {
+		s.logger.Warn("Failed to list shared lists",
+			zap.String("correlationID", event.CorrelationID),
+			zap.String("userID", event.UserID),
+			zap.Error(err))
+		response.Message = listErrorMessage(err)
+	} else {
+		response.Success = true
+		response.Lists = summaries
+	}
+
+	if err := s.eventBus.Publish(events.TopicListsResponse, response); err != nil {
+		s.logger.Error("Failed to publish ListsResponse", zap.Error(err))
+	}
+}
+
+// summarize returns the user's lists with their member counts
+func (s *sharedListService) summarize(userID common.UserID) ([]events.ListSummary, error) {
+	lists, err := s.repository.ListsForUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]events.ListSummary, 0, len(lists))
+	for _, list := range lists {
+		members, err := s.repository.ListMembers(list.ID)
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, events.ListSummary{
+			ID:      string(list.ID),
+			Name:    list.Name,
+			Code:    list.InviteCode,
+			Members: len(members),
+			Owner:   list.OwnerID == userID,
+		})
+	}
+	return summaries, nil
+}
+
+// newResponse creates a ListResponse answering a request
+func (s *sharedListService) newResponse(request events.Event, userID, chatID, action string) events.ListResponse {
+	response := events.ListResponse{
+		Event:  events.NewEvent(),
+		UserID: userID,
+		ChatID: chatID,
+		Action: action,
+	}
+	response.CorrelationID = request.CorrelationID
+	return response
+}
+
+// publishResponse publishes a ListResponse
+func (s *sharedListService) publishResponse(response events.ListResponse) {
+	if err := s.eventBus.Publish(events.TopicListResponse, response); err != nil {
+		s.logger.Error("Failed to publish ListResponse", zap.Error(err))
+	}
+}
+
+// listErrorMessage returns a message that can be shown to the user
+func listErrorMessage(err error) string {
+	var nudgeErr NudgeError
+	if errors.As(err, &nudgeErr) && nudgeErr.Code() != ErrCodeRepository {
+		return nudgeErr.Message()
+	}
+	if errors.Is(err, ErrListNotFound) {
+		return "I couldn't find that list. See your lists with /lists."
+	}
+	if errors.Is(err, ErrListInviteNotFound) {
+		return "This invite link is invalid."
+	}
+	return "Something went wrong, please try again later."
+}
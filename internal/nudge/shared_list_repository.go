@@ -0,0 +1,248 @@
+package nudge
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"nudgebot-api/internal/common"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SharedListRepository persists shared lists and their members. Lookups of
+// missing lists and members return nil without an error.
+type SharedListRepository interface {
+	CreateList(list *SharedList) error
+	GetList(listID common.ID) (*SharedList, error)
+	GetListByCode(code string) (*SharedList, error)
+	ListsForUser(userID common.UserID) ([]*SharedList, error)
+	GetMember(listID common.ID, userID common.UserID) (*SharedListMember, error)
+	ListMembers(listID common.ID) ([]*SharedListMember, error)
+	SaveMember(member *SharedListMember) error
+}
+
+// gormSharedListRepository implements SharedListRepository using GORM
+type gormSharedListRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewGormSharedListRepository creates a new GORM-based shared list repository
+func NewGormSharedListRepository(db *gorm.DB, logger *zap.Logger) SharedListRepository {
+	return &gormSharedListRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreateList stores a new list
+func (r *gormSharedListRepository) CreateList(list *SharedList) error {
+	list.CreatedAt = time.Now()
+	if err := r.db.Create(list).Error; err != nil {
+		return WrapRepositoryError(err, "create shared list")
+	}
+	return nil
+}
+
+// GetList retrieves a list by its ID
+func (r *gormSharedListRepository) GetList(listID common.ID) (*SharedList, error) {
+	var lists []*SharedList
+	if err := r.db.Where("id = ?", listID).Limit(1).Find(&lists).Error; err != nil {
+		return nil, WrapRepositoryError(err, "get shared list")
+	}
+	if len(lists) == 0 {
+		return nil, nil
+	}
+	return lists[0], nil
+}
+
+// GetListByCode retrieves a list by its invite code
+func (r *gormSharedListRepository) GetListByCode(code string) (*SharedList, error) {
+	var lists []*SharedList
+	if err := r.db.Where("invite_code = ?", code).Limit(1).Find(&lists).Error; err != nil {
+		return nil, WrapRepositoryError(err, "get shared list by invite code")
+	}
+	if len(lists) == 0 {
+		return nil, nil
+	}
+	return lists[0], nil
+}
+
+// ListsForUser retrieves the lists a user is a member of, oldest first
+func (r *gormSharedListRepository) ListsForUser(userID common.UserID) ([]*SharedList, error) {
+	var lists []*SharedList
+	err := r.db.
+		Joins("JOIN shared_list_members ON shared_list_members.list_id = shared_lists.id").
+		Where("shared_list_members.user_id = ?", userID).
+		Order("shared_lists.created_at ASC").
+		Find(&lists).Error
+	if err != nil {
+		return nil, WrapRepositoryError(err, "list shared lists for user")
+	}
+	return lists, nil
+}
+
+// GetMember retrieves a user's membership in a list
+func (r *gormSharedListRepository) GetMember(listID common.ID, userID common.UserID) (*SharedListMember, error) {
+	var members []*SharedListMember
+	err := r.db.Where("list_id = ? AND user_id = ?", listID, userID).Limit(1).Find(&members).Error
+	if err != nil {
+		return nil, WrapRepositoryError(err, "get shared list member")
+	}
+	if len(members) == 0 {
+		return nil, nil
+	}
+	return members[0], nil
+}
+
+// ListMembers retrieves every member of a list
+func (r *gormSharedListRepository) ListMembers(listID common.ID) ([]*SharedListMember, error) {
+	var members []*SharedListMember
+	err := r.db.Where("list_id = ?", listID).Order("created_at ASC").Find(&members).Error
+	if err != nil {
+		return nil, WrapRepositoryError(err, "list shared list members")
+	}
+	return members, nil
+}
+
+// SaveMember adds a member to a list or updates the chat they are notified in
+func (r *gormSharedListRepository) SaveMember(member *SharedListMember) error {
+	if member.CreatedAt.IsZero() {
+		member.CreatedAt = time.Now()
+	}
+
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "list_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"chat_id"}),
+	}).Create(member).Error
+	if err != nil {
+		return WrapRepositoryError(err, "save shared list member")
+	}
+
+	r.logger.Debug("Shared list member saved",
+		zap.String("listID", string(member.ListID)),
+		zap.String("userID", string(member.UserID)))
+	return nil
+}
+
+// memorySharedListRepository implements SharedListRepository in memory
+type memorySharedListRepository struct {
+	mu      sync.RWMutex
+	lists   map[common.ID]*SharedList
+	members map[common.ID]map[common.UserID]*SharedListMember
+}
+
+// NewMemorySharedListRepository creates a SharedListRepository that is not persisted
+func NewMemorySharedListRepository() SharedListRepository {
+	return &memorySharedListRepository{
+		lists:   make(map[common.ID]*SharedList),
+		members: make(map[common.ID]map[common.UserID]*SharedListMember),
+	}
+}
+
+// CreateList stores a new list
+func (r *memorySharedListRepository) CreateList(list *SharedList) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	list.CreatedAt = time.Now()
+	listCopy := *list
+	r.lists[list.ID] = &listCopy
+	return nil
+}
+
+// GetList retrieves a list by its ID
+func (r *memorySharedListRepository) GetList(listID common.ID) (*SharedList, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	list, ok := r.lists[listID]
+	if !ok {
+		return nil, nil
+	}
+	listCopy := *list
+	return &listCopy, nil
+}
+
+// GetListByCode retrieves a list by its invite code
+func (r *memorySharedListRepository) GetListByCode(code string) (*SharedList, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, list := range r.lists {
+		if list.InviteCode == code {
+			listCopy := *list
+			return &listCopy, nil
+		}
+	}
+	return nil, nil
+}
+
+// ListsForUser retrieves the lists a user is a member of, oldest first
+func (r *memorySharedListRepository) ListsForUser(userID common.UserID) ([]*SharedList, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var lists []*SharedList
+	for listID, members := range r.members {
+		if _, ok := members[userID]; ok {
+			listCopy := *r.lists[listID]
+			lists = append(lists, &listCopy)
+		}
+	}
+	sort.Slice(lists, func(i, j int) bool {
+		return lists[i].CreatedAt.Before(lists[j].CreatedAt)
+	})
+	return lists, nil
+}
+
+// GetMember retrieves a user's membership in a list
+func (r *memorySharedListRepository) GetMember(listID common.ID, userID common.UserID) (*SharedListMember, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	member, ok := r.members[listID][userID]
+	if !ok {
+		return nil, nil
+	}
+	memberCopy := *member
+	return &memberCopy, nil
+}
+
+// ListMembers retrieves every member of a list
+func (r *memorySharedListRepository) ListMembers(listID common.ID) ([]*SharedListMember, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	members := make([]*SharedListMember, 0, len(r.members[listID]))
+	for _, member := range r.members[listID] {
+		memberCopy := *member
+		members = append(members, &memberCopy)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].CreatedAt.Before(members[j].CreatedAt)
+	})
+	return members, nil
+}
+
+// SaveMember adds a member to a list or updates the chat they are notified in
+func (r *memorySharedListRepository) SaveMember(member *SharedListMember) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.members[member.ListID] == nil {
+		r.members[member.ListID] = make(map[common.UserID]*SharedListMember)
+	}
+	if existing, ok := r.members[member.ListID][member.UserID]; ok {
+		member.CreatedAt = existing.CreatedAt
+	} else if member.CreatedAt.IsZero() {
+		member.CreatedAt = time.Now()
+	}
+
+	memberCopy := *member
+	r.members[member.ListID][member.UserID] = &memberCopy
+	return nil
+}
@@ -0,0 +1,106 @@
+package nudge
+
+import (
+	"errors"
+	"testing"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestSharedListService_CreateAndJoin(t *testing.T) {
+	service := NewSharedListService(events.NewMockEventBus(), zaptest.NewLogger(t), NewMemorySharedListRepository())
+
+	list, err := service.CreateList("alice", "chat-alice", "  Groceries ")
+	require.NoError(t, err)
+	assert.Equal(t, "Groceries", list.Name)
+
+	// Names are unique among a user's lists, ignoring case
+	_, err = service.CreateList("alice", "chat-alice", "groceries")
+	var ruleErr BusinessRuleError
+	assert.True(t, errors.As(err, &ruleErr))
+
+	joined, err := service.Join(list.InviteCode, "bob", "chat-bob")
+	require.NoError(t, err)
+	assert.Equal(t, list.ID, joined.ID)
+
+	found, err := service.FindList("bob", "GROCERIES")
+	require.NoError(t, err)
+	assert.Equal(t, list.ID, found.ID)
+
+	members, err := service.ListMembers(list.ID)
+	require.NoError(t, err)
+	assert.Len(t, members, 2)
+
+	_, err = service.Join("unknown", "carol", "chat-carol")
+	assert.ErrorIs(t, err, ErrListInviteNotFound)
+	_, err = service.FindList("carol", "Groceries")
+	assert.ErrorIs(t, err, ErrListNotFound)
+}
+
+func TestNudgeService_SharesListTasksWithMembers(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	eventBus := events.NewMockEventBus()
+	eventBus.SetSynchronousMode(true)
+	lists := NewSharedListService(eventBus, logger, NewMemorySharedListRepository())
+	service, err := NewNudgeServiceWithLists(eventBus, logger, NewMemoryNudgeRepository(logger), nil, nil, lists)
+	require.NoError(t, err)
+	nudge := service.(*nudgeService)
+	alice, bob, mallory := common.UserID(common.NewID()), common.UserID(common.NewID()), common.UserID(common.NewID())
+
+	list, err := lists.CreateList(alice, "chat-alice", "Groceries")
+	require.NoError(t, err)
+	_, err = lists.Join(list.InviteCode, bob, "chat-bob")
+	require.NoError(t, err)
+
+	// Bob adds a task to the list; tasks for lists he is not in are dropped
+	nudge.handleTaskParsed(events.TaskParsed{
+		Event:      events.NewEvent(),
+		UserID:     string(bob),
+		ChatID:     "chat-bob",
+		ParsedTask: events.ParsedTask{Title: "Buy milk", Priority: "medium"},
+		ListID:     string(list.ID),
+	})
+	nudge.handleTaskParsed(events.TaskParsed{
+		Event:      events.NewEvent(),
+		UserID:     string(mallory),
+		ChatID:     "chat-mallory",
+		ParsedTask: events.ParsedTask{Title: "Sneaky", Priority: "medium"},
+		ListID:     string(list.ID),
+	})
+
+	// Alice sees Bob's task in her list
+	nudge.handleTaskListRequested(events.TaskListRequested{Event: events.NewEvent(), UserID: string(alice), ChatID: "chat-alice"})
+	responses := eventBus.GetPublishedEvents(events.TopicTaskListResponse)
+	require.Len(t, responses, 1)
+	tasks := responses[0].(events.TaskListResponse).Tasks
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "Buy milk", tasks[0].Title)
+	assert.Equal(t, "Groceries", tasks[0].List)
+
+	// Alice completes it and Bob is told
+	nudge.handleTaskActionRequested(events.TaskActionRequested{
+		Event:  events.NewEvent(),
+		UserID: string(alice),
+		ChatID: "chat-alice",
+		TaskID: tasks[0].ID,
+		Action: "done",
+	})
+	actions := eventBus.GetPublishedEvents(events.TopicTaskActionResponse)
+	require.Len(t, actions, 1)
+	assert.True(t, actions[0].(events.TaskActionResponse).Success)
+
+	notices := eventBus.GetPublishedEvents(events.TopicListTaskCompleted)
+	require.Len(t, notices, 1)
+	notice := notices[0].(events.ListTaskCompleted)
+	assert.Equal(t, string(bob), notice.UserID)
+	assert.Equal(t, "chat-bob", notice.ChatID)
+	assert.Equal(t, string(alice), notice.CompletedBy)
+
+	task := &Task{ID: common.TaskID(tasks[0].ID), UserID: bob, ListID: list.ID}
+	assert.Error(t, nudge.authorizeTaskAction(task, mallory, "done"))
+}
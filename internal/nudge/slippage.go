@@ -0,0 +1,228 @@
+package nudge
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/tenant"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// SlippageNoticeThreshold is how many postponements earn a task a gentle
+	// accountability message; another follows every SlippageNoticeThreshold more
+	SlippageNoticeThreshold = 3
+
+	// weeklyStatsPeriod is the window a /stats report covers
+	weeklyStatsPeriod = 7 * 24 * time.Hour
+	// weeklyStatsSlipped caps the tasks listed in the slippage report
+	weeklyStatsSlipped = 5
+)
+
+// Postpones reports whether the entry pushed the task's due date back. Every
+// snooze counts; an edit counts when it moved an existing due date later.
+func (e *TaskEvent) Postpones() bool {
+	switch e.Kind {
+	case TaskEventSnoozed:
+		return true
+	case TaskEventEdited:
+		return e.FromDue != nil && e.ToDue != nil && e.ToDue.After(*e.FromDue)
+	default:
+		return false
+	}
+}
+
+// Slippage is how far a task's due date has moved back over its history
+type Slippage struct {
+	Postponements int
+	Slip          time.Duration // total time the due date moved back
+	LastPostponed time.Time
+}
+
+// BreakdownOf converts task statistics to the breakdown shown in /stats
+func BreakdownOf(stats *TaskStats) *events.TaskBreakdown {
+	breakdown := &events.TaskBreakdown{
+		ByPriority:          make(map[string]int, len(stats.ByPriority)),
+		ByTag:               make(map[string]int, len(stats.ByTag)),
+		OverdueUnderADay:    int(stats.OverdueByAge.UnderADay),
+		OverdueOneToThree:   int(stats.OverdueByAge.OneToThreeDays),
+		OverdueThreeToSeven: int(stats.OverdueByAge.ThreeToSevenDays),
+		OverdueOverAWeek:    int(stats.OverdueByAge.OverAWeek),
+	}
+	for priority, count := range stats.ByPriority {
+		breakdown.ByPriority[string(priority)] = int(count)
+	}
+	for tag, count := range stats.ByTag {
+		breakdown.ByTag[tag] = int(count)
+	}
+	return breakdown
+}
+
+// SlippageOf computes the slippage of one task from its history
+func SlippageOf(history []*TaskEvent) Slippage {
+	var slippage Slippage
+	for _, entry := range history {
+		if !entry.Postpones() {
+			continue
+		}
+		slippage.Postponements++
+		if entry.FromDue != nil && entry.ToDue != nil && entry.ToDue.After(*entry.FromDue) {
+			slippage.Slip += entry.ToDue.Sub(*entry.FromDue)
+		}
+		if entry.OccurredAt.After(slippage.LastPostponed) {
+			slippage.LastPostponed = entry.OccurredAt
+		}
+	}
+	return slippage
+}
+
+// noticeSlippage publishes a TaskSlipped when a postponement brings the task
+// to the notice threshold or another multiple of it
+func (s *historyService) noticeSlippage(entry *TaskEvent) {
+	if !entry.Postpones() {
+		return
+	}
+
+	history, err := s.repository.ListTaskEvents(entry.TaskID)
+	if err != nil {
+		s.logger.Error("Failed to load task history for slippage",
+			zap.String("taskID", string(entry.TaskID)),
+			zap.Error(err))
+		return
+	}
+	count := SlippageOf(history).Postponements
+	if count < SlippageNoticeThreshold || count%SlippageNoticeThreshold != 0 {
+		return
+	}
+
+	task, err := s.tasks.WithContext(tenant.WithID(context.Background(), entry.TenantID)).GetTaskByID(entry.TaskID)
+	if err != nil || task.ChatID == "" {
+		return
+	}
+
+	event := events.TaskSlipped{
+		Event:         events.NewEvent(),
+		TaskID:        string(task.ID),
+		UserID:        string(task.UserID),
+		ChatID:        string(task.ChatID),
+		Title:         task.Title,
+		Postponements: count,
+	}
+	if err := s.eventBus.Publish(events.TopicTaskSlipped, event); err != nil {
+		s.logger.Error("Failed to publish TaskSlipped event",
+			zap.String("taskID", string(task.ID)),
+			zap.Error(err))
+	}
+}
+
+// handleWeeklyStatsRequested answers a /stats command with the user's last
+// seven days, the tasks they kept postponing, a breakdown of their open tasks
+// and their 30-day burndown
+func (s *historyService) handleWeeklyStatsRequested(event events.WeeklyStatsRequested) {
+	response, err := s.weeklyStats(common.UserID(event.UserID), time.Now())
+	if err != nil {
+		s.logger.Warn("Weekly stats request failed",
+			zap.String("correlationID", event.CorrelationID),
+			zap.String("userID", event.UserID),
+			zap.Error(err))
+		response = &events.WeeklyStatsResponse{Message: "Could not load your weekly stats."}
+	}
+	response.Event = events.NewEvent()
+	response.UserID = event.UserID
+	response.ChatID = event.ChatID
+
+	if err := s.eventBus.Publish(events.TopicWeeklyStatsResponse, *response); err != nil {
+		s.logger.Error("Failed to publish WeeklyStatsResponse event",
+			zap.String("userID", event.UserID),
+			zap.Error(err))
+	}
+}
+
+// weeklyStats summarizes the user's tasks over the week before now. Slipped
+// lists the tasks postponed during the week with their postponements overall.
+func (s *historyService) weeklyStats(userID common.UserID, now time.Time) (*events.WeeklyStatsResponse, error) {
+	since := now.Add(-weeklyStatsPeriod)
+	stats := &events.WeeklyStatsResponse{Since: since, Success: true}
+
+	tasks, err := s.tasks.GetTasksByUserID(userID, TaskFilter{UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+	stats.Burndown = s.burndown(userID, tasks, now)
+
+	taskStats, err := s.tasks.GetTaskStats(userID)
+	if err != nil {
+		// The report still has the week without the breakdown
+		s.logger.Warn("Failed to load task breakdown",
+			zap.String("userID", string(userID)),
+			zap.Error(err))
+	} else {
+		stats.Breakdown = BreakdownOf(taskStats)
+	}
+
+	titles := make(map[common.TaskID]string)
+	var touched []common.TaskID
+	for _, task := range tasks {
+		if !task.CreatedAt.Before(since) {
+			stats.Created++
+		}
+		if task.CompletedAt != nil && !task.CompletedAt.Before(since) {
+			stats.Completed++
+		}
+		if task.Status.IsOpen() {
+			stats.Open++
+			if task.DueDate != nil && task.DueDate.Before(now) {
+				stats.Overdue++
+			}
+		}
+		// A postponement updates the task, so untouched tasks cannot have slipped this week
+		if !task.UpdatedAt.Before(since) {
+			titles[task.ID] = task.Title
+			touched = append(touched, task.ID)
+		}
+	}
+
+	history, err := s.repository.ListTaskEventsSince(touched, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	byTask := make(map[common.TaskID][]*TaskEvent)
+	for _, entry := range history {
+		byTask[entry.TaskID] = append(byTask[entry.TaskID], entry)
+		if entry.Postpones() && !entry.OccurredAt.Before(since) {
+			stats.Postponements++
+		}
+	}
+
+	for taskID, entries := range byTask {
+		slippage := SlippageOf(entries)
+		if slippage.Postponements == 0 || slippage.LastPostponed.Before(since) {
+			continue
+		}
+		stats.Slipped = append(stats.Slipped, events.TaskSlippage{
+			TaskID:        string(taskID),
+			Title:         titles[taskID],
+			Postponements: slippage.Postponements,
+			Slip:          slippage.Slip,
+		})
+	}
+	sort.Slice(stats.Slipped, func(i, j int) bool {
+		a, b := stats.Slipped[i], stats.Slipped[j]
+		if a.Postponements != b.Postponements {
+			return a.Postponements > b.Postponements
+		}
+		if a.Slip != b.Slip {
+			return a.Slip > b.Slip
+		}
+		return a.Title < b.Title
+	})
+	if len(stats.Slipped) > weeklyStatsSlipped {
+		stats.Slipped = stats.Slipped[:weeklyStatsSlipped]
+	}
+
+	return stats, nil
+}
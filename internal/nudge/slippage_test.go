@@ -0,0 +1,120 @@
+package nudge
+
+import (
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestSlippageOf(t *testing.T) {
+	due := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		moved := due.Add(d)
+		return &moved
+	}
+
+	slippage := SlippageOf([]*TaskEvent{
+		{Kind: TaskEventCreated, OccurredAt: due},
+		{Kind: TaskEventSnoozed, FromDue: at(0), ToDue: at(2 * time.Hour), OccurredAt: due.Add(time.Hour)},
+		{Kind: TaskEventSnoozed, ToDue: at(5 * time.Hour), OccurredAt: due.Add(2 * time.Hour)},
+		{Kind: TaskEventEdited, FromDue: at(5 * time.Hour), ToDue: at(29 * time.Hour), OccurredAt: due.Add(3 * time.Hour)},
+		{Kind: TaskEventEdited, FromDue: at(29 * time.Hour), ToDue: at(time.Hour), OccurredAt: due.Add(4 * time.Hour)},
+		{Kind: TaskEventEdited, Detail: "custom_fields.owner", OccurredAt: due.Add(5 * time.Hour)},
+	})
+
+	assert.Equal(t, 3, slippage.Postponements, "moving a due date earlier or editing other fields is no postponement")
+	assert.Equal(t, 26*time.Hour, slippage.Slip)
+	assert.Equal(t, due.Add(3*time.Hour), slippage.LastPostponed)
+}
+
+func TestHistoryService_SlippageNoticesAndWeeklyStats(t *testing.T) {
+	bus := events.NewMockEventBus()
+	bus.SetSynchronousMode(true)
+	logger := zaptest.NewLogger(t)
+	tasks := NewMemoryNudgeRepository(logger)
+	history := NewHistoryService(bus, logger, NewMemoryHistoryRepository(), tasks, nil).(*historyService)
+
+	userID := common.UserID(common.NewID())
+	due := time.Now().Add(-time.Hour)
+	slipping := &Task{
+		ID:       common.TaskID(common.NewID()),
+		UserID:   userID,
+		ChatID:   common.ChatID(userID),
+		Title:    "File taxes",
+		Priority: common.PriorityMedium,
+		Status:   common.TaskStatusActive,
+		DueDate:  &due,
+	}
+	done := &Task{
+		ID:       common.TaskID(common.NewID()),
+		UserID:   userID,
+		Title:    "Buy milk",
+		Priority: common.PriorityLow,
+		Status:   common.TaskStatusActive,
+	}
+	require.NoError(t, tasks.CreateTask(slipping))
+	require.NoError(t, tasks.CreateTask(done))
+	completedAt := time.Now()
+	done.Status = common.TaskStatusCompleted
+	done.CompletedAt = &completedAt
+	require.NoError(t, tasks.UpdateTask(done))
+
+	snooze := func(from time.Time, by time.Duration) time.Time {
+		to := from.Add(by)
+		require.NoError(t, bus.Publish(events.TopicTaskStatusChanged, events.TaskStatusChanged{
+			Event: events.NewEvent(), TaskID: string(slipping.ID), UserID: string(userID),
+			FromStatus: "active", ToStatus: "snoozed", SnoozedUntil: &to, PreviousDueDate: &from,
+		}))
+		return to
+	}
+
+	next := snooze(due, time.Hour)
+	next = snooze(next, time.Hour)
+	assert.Empty(t, bus.GetPublishedEvents(events.TopicTaskSlipped))
+
+	moved := next.Add(24 * time.Hour)
+	require.NoError(t, bus.Publish(events.TopicTaskEdited, events.TaskEdited{
+		Event: events.NewEvent(), TaskID: string(slipping.ID), UserID: string(userID),
+		Fields: []string{"due_date"}, PreviousDueDate: &next, DueDate: &moved,
+	}))
+	slipped := bus.GetPublishedEvents(events.TopicTaskSlipped)
+	require.Len(t, slipped, 1)
+	notice := slipped[0].(events.TaskSlipped)
+	assert.Equal(t, "File taxes", notice.Title)
+	assert.Equal(t, string(slipping.ChatID), notice.ChatID)
+	assert.Equal(t, 3, notice.Postponements)
+
+	snooze(moved, time.Hour)
+	assert.Len(t, bus.GetPublishedEvents(events.TopicTaskSlipped), 1, "the next notice waits for six postponements")
+
+	require.NoError(t, bus.Publish(events.TopicWeeklyStatsRequested, events.WeeklyStatsRequested{
+		Event: events.NewEvent(), UserID: string(userID), ChatID: string(slipping.ChatID),
+	}))
+	responses := bus.GetPublishedEvents(events.TopicWeeklyStatsResponse)
+	require.Len(t, responses, 1)
+	stats := responses[0].(events.WeeklyStatsResponse)
+	assert.True(t, stats.Success)
+	assert.Equal(t, string(slipping.ChatID), stats.ChatID)
+	assert.Equal(t, 2, stats.Created)
+	assert.Equal(t, 1, stats.Completed)
+	assert.Equal(t, 1, stats.Open)
+	assert.Equal(t, 1, stats.Overdue)
+	assert.Equal(t, 4, stats.Postponements)
+	require.Len(t, stats.Slipped, 1)
+	assert.Equal(t, "File taxes", stats.Slipped[0].Title)
+	assert.Equal(t, 4, stats.Slipped[0].Postponements)
+	assert.Equal(t, 27*time.Hour, stats.Slipped[0].Slip)
+
+	t.Run("postponements before the week are not counted", func(t *testing.T) {
+		report, err := history.weeklyStats(userID, time.Now().Add(8*24*time.Hour))
+		require.NoError(t, err)
+		assert.Zero(t, report.Postponements)
+		assert.Empty(t, report.Slipped)
+	})
+}
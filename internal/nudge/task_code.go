@@ -0,0 +1,50 @@
+package nudge
+
+import (
+	"fmt"
+
+	"nudgebot-api/internal/common"
+
+	"gorm.io/gorm"
+)
+
+// nextTaskCode finds the highest code a user's tasks, live or archived, were
+// given; an archived task's code is never handed out again
+var nextTaskCode = fmt.Sprintf("SELECT GREATEST((SELECT COALESCE(MAX(code), 0) FROM tasks WHERE user_id = @user), "+
+	"(SELECT COALESCE(MAX(code), 0) FROM %s WHERE user_id = @user)) + 1", TaskArchiveTable)
+
+// backfillTaskCodesSQL numbers the live tasks created before codes existed
+// after their user's highest code, oldest first
+const backfillTaskCodesSQL = `UPDATE tasks SET code = numbered.code FROM (
+	SELECT id, (SELECT COALESCE(MAX(coded.code), 0) FROM tasks AS coded WHERE coded.user_id = uncoded.user_id)
+		+ ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY created_at, id) AS code
+	FROM tasks AS uncoded WHERE code = 0
+) AS numbered WHERE tasks.id = numbered.id`
+
+// CodeString returns the task's short code, such as T-42
+func (t *Task) CodeString() string {
+	return common.FormatTaskCode(t.Code)
+}
+
+// BeforeCreate gives a new task the next code of its user. A transaction
+// scoped advisory lock on the user keeps concurrent creations from taking the
+// same code.
+func (t *Task) BeforeCreate(tx *gorm.DB) error {
+	// Dry runs only render the insert
+	if t.Code != 0 || tx.DryRun {
+		return nil
+	}
+
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "task_code:"+string(t.UserID)).Error; err != nil {
+		return fmt.Errorf("failed to lock task codes: %w", err)
+	}
+	if err := tx.Raw(nextTaskCode, map[string]interface{}{"user": t.UserID}).Scan(&t.Code).Error; err != nil {
+		return fmt.Errorf("failed to number task: %w", err)
+	}
+	return nil
+}
+
+// backfillTaskCodes gives codes to the live tasks created before codes existed
+func backfillTaskCodes(db *gorm.DB) error {
+	return db.Exec(backfillTaskCodesSQL).Error
+}
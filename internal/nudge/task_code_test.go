@@ -0,0 +1,40 @@
+package nudge
+
+import (
+	"testing"
+
+	"nudgebot-api/internal/common"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestMemoryRepository_NumbersTasksPerUser(t *testing.T) {
+	repo := NewMemoryNudgeRepository(zaptest.NewLogger(t))
+
+	ada, grace := common.UserID(common.NewID()), common.UserID(common.NewID())
+	create := func(userID common.UserID, title string) *Task {
+		task := &Task{
+			ID:       common.TaskID(common.NewID()),
+			UserID:   userID,
+			Title:    title,
+			Priority: common.PriorityMedium,
+			Status:   common.TaskStatusActive,
+		}
+		require.NoError(t, repo.CreateTask(task))
+		return task
+	}
+
+	first := create(ada, "Dentist")
+	second := create(ada, "Groceries")
+	other := create(grace, "Taxes")
+
+	assert.Equal(t, "T-1", first.CodeString())
+	assert.Equal(t, "T-2", second.CodeString())
+	assert.Equal(t, "T-1", other.CodeString(), "each user counts from one")
+
+	stored, err := repo.GetTaskByID(second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stored.Code)
+}
@@ -0,0 +1,187 @@
+package nudge
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// summarizeTask converts a task to the summary the chatbot shows; listName is
+// the shared list the task is in, empty for personal tasks
+func summarizeTask(task *Task, listName string) events.TaskSummary {
+	summary := events.TaskSummary{
+		ID:          string(task.ID),
+		Code:        task.CodeString(),
+		Title:       task.Title,
+		Description: task.Description,
+		DueDate:     task.DueDate,
+		Priority:    string(task.Priority),
+		Status:      string(task.Status),
+		IsOverdue:   task.IsOverdue(),
+		Muted:       task.Muted,
+		Habit:       string(task.Habit),
+		Streak:      task.Streak,
+		List:        listName,
+		HasOriginal: task.OriginalText != "",
+
+		CustomFields: task.CustomFields.Display(),
+		Links:        task.Links.URLs(),
+	}
+	task.Location.summarize(&summary)
+	return summary
+}
+
+// handleTaskDetailsRequested answers a chatbot request for one task, by ID
+// or by the user's short code for it
+func (s *nudgeService) handleTaskDetailsRequested(event events.TaskDetailsRequested) {
+	s = s.forUser(event.UserID)
+	response := events.TaskDetailsResponse{
+		Event:  events.NewEvent(),
+		UserID: event.UserID,
+		ChatID: event.ChatID,
+	}
+
+	userID := common.UserID(event.UserID)
+	task, err := s.detailedTaskFor(userID, event)
+	if err != nil {
+		s.logger.Warn("Task details request failed",
+			zap.String("correlationID", event.CorrelationID),
+			zap.String("taskID", event.TaskID),
+			zap.Int("code", event.Code),
+			zap.Error(err))
+		response.Message = taskDetailsErrorMessage(err, event.Code)
+	} else {
+		response.Success = true
+		response.Task = summarizeTask(task, s.listNames(userID)[task.ListID])
+		response.Reminders = s.upcomingReminders(task.ID)
+		response.History = s.historySummary(task.ID)
+	}
+
+	if err := s.eventBus.Publish(events.TopicTaskDetailsResponse, response); err != nil {
+		s.logger.Error("Failed to publish TaskDetailsResponse event",
+			zap.String("taskID", event.TaskID),
+			zap.Int("code", event.Code),
+			zap.Error(err))
+	}
+}
+
+// detailedTaskFor returns the task a details request names if the user may
+// view it; deleted tasks are not found
+func (s *nudgeService) detailedTaskFor(userID common.UserID, event events.TaskDetailsRequested) (*Task, error) {
+	if s.repository == nil {
+		return nil, fmt.Errorf("repository not initialized")
+	}
+	if event.TaskID == "" {
+		return s.taskByCode(userID, event.Code)
+	}
+
+	task, err := s.repository.GetTaskByID(common.TaskID(event.TaskID))
+	if err != nil {
+		return nil, err
+	}
+	if task.Status == common.TaskStatusDeleted {
+		return nil, ErrTaskNotFound
+	}
+	if err := s.authorizeTaskAction(task, userID, "view"); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// taskByCode returns the user's task with the short code, deleted tasks aside
+func (s *nudgeService) taskByCode(userID common.UserID, code int) (*Task, error) {
+	if code <= 0 {
+		return nil, ErrTaskNotFound
+	}
+
+	statuses := make([]common.TaskStatus, 0, len(common.TaskStatuses))
+	for _, status := range common.TaskStatuses {
+		if status != common.TaskStatusDeleted {
+			statuses = append(statuses, status)
+		}
+	}
+	tasks, err := s.repository.GetTasksByUserID(userID, TaskFilter{UserID: userID, Statuses: statuses, Code: code})
+	if err != nil {
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		return nil, ErrTaskNotFound
+	}
+	return tasks[0], nil
+}
+
+// upcomingReminders returns the task's reminders not sent yet, earliest
+// first; the details are still worth showing when they can't be loaded
+func (s *nudgeService) upcomingReminders(taskID common.TaskID) []events.TaskReminderSummary {
+	reminders, err := s.repository.GetRemindersByTaskID(taskID)
+	if err != nil {
+		s.logger.Warn("Failed to load reminders for task details", zap.String("taskID", string(taskID)), zap.Error(err))
+		return nil
+	}
+
+	var upcoming []events.TaskReminderSummary
+	for _, reminder := range reminders {
+		if reminder.SentAt == nil {
+			upcoming = append(upcoming, events.TaskReminderSummary{ScheduledAt: reminder.ScheduledAt, Type: string(reminder.ReminderType)})
+		}
+	}
+	sort.Slice(upcoming, func(i, j int) bool { return upcoming[i].ScheduledAt.Before(upcoming[j].ScheduledAt) })
+	return upcoming
+}
+
+// historySummary counts the entries of the task's history
+func (s *nudgeService) historySummary(taskID common.TaskID) events.TaskHistorySummary {
+	var summary events.TaskHistorySummary
+	if s.history == nil {
+		return summary
+	}
+	history, err := s.history.GetHistory(taskID)
+	if err != nil {
+		s.logger.Warn("Failed to load history for task details", zap.String("taskID", string(taskID)), zap.Error(err))
+		return summary
+	}
+
+	summary.Entries = len(history)
+	for _, entry := range history {
+		switch entry.Kind {
+		case TaskEventSnoozed:
+			summary.Snoozes++
+		case TaskEventNudgeSent:
+			summary.Nudges++
+		case TaskEventEdited:
+			summary.Edits++
+		}
+	}
+	if len(history) > 0 {
+		last := history[len(history)-1]
+		summary.Last = &events.TaskHistoryEntry{
+			Kind:       string(last.Kind),
+			FromStatus: last.FromStatus,
+			ToStatus:   last.ToStatus,
+			Detail:     last.Detail,
+			OccurredAt: last.OccurredAt,
+		}
+	}
+	return summary
+}
+
+// taskDetailsErrorMessage returns a message that can be shown to the user
+func taskDetailsErrorMessage(err error, code int) string {
+	var notFound common.NotFoundError
+	if errors.As(err, &notFound) || errors.Is(err, ErrTaskNotFound) {
+		if code > 0 {
+			return fmt.Sprintf("I couldn't find task %s. It may have been deleted; see your tasks with /list.", common.FormatTaskCode(code))
+		}
+		return "I couldn't find that task. It may have been deleted; see your tasks with /list."
+	}
+	var permissionErr PermissionError
+	if errors.As(err, &permissionErr) {
+		return "Not allowed: " + permissionErr.Message()
+	}
+	return "Something went wrong, please try again later."
+}
@@ -0,0 +1,102 @@
+package nudge
+
+import (
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestNudgeService_TaskDetailsByCode(t *testing.T) {
+	service, repo, eventBus := newBulkTestService(t)
+	userID := common.UserID(common.NewID())
+
+	task := bulkTask(userID, "Renew passport")
+	task.ID = common.TaskID(common.NewID())
+	task.Location = NewTaskLocation("town hall")
+	require.NoError(t, service.CreateTask(task))
+	// Another user's first task has the same code
+	other := bulkTask(common.UserID(common.NewID()), "Someone else's task")
+	other.ID = common.TaskID(common.NewID())
+	require.NoError(t, service.CreateTask(other))
+	require.Equal(t, task.Code, other.Code)
+
+	deleted := bulkTask(userID, "Old task")
+	deleted.ID = common.TaskID(common.NewID())
+	require.NoError(t, service.CreateTask(deleted))
+	deleted.Status = common.TaskStatusDeleted
+	require.NoError(t, repo.UpdateTask(deleted))
+
+	request := func(code int) events.TaskDetailsResponse {
+		s := service.(*nudgeService)
+		s.handleTaskDetailsRequested(events.TaskDetailsRequested{Event: events.NewEvent(), UserID: string(userID), ChatID: "12345", Code: code})
+		responses := eventBus.GetPublishedEvents(events.TopicTaskDetailsResponse)
+		return responses[len(responses)-1].(events.TaskDetailsResponse)
+	}
+
+	found := request(task.Code)
+	require.True(t, found.Success, found.Message)
+	assert.Equal(t, string(task.ID), found.Task.ID)
+	assert.Equal(t, "T-1", found.Task.Code)
+	assert.Equal(t, "town hall", found.Task.Location)
+
+	missing := request(deleted.Code)
+	assert.False(t, missing.Success, "deleted tasks are not shown")
+	assert.Contains(t, missing.Message, "T-2")
+
+	assert.False(t, request(99).Success)
+}
+
+func TestNudgeService_TaskDetailsWithRemindersAndHistory(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	eventBus := events.NewMockEventBus()
+	repo := NewMemoryNudgeRepository(logger)
+	historyRepo := NewMemoryHistoryRepository()
+	history := NewHistoryService(events.NewMockEventBus(), logger, historyRepo, repo, nil)
+	service, err := NewNudgeServiceWithHistory(eventBus, logger, repo, nil, nil, nil, nil, DefaultConflictTolerance, nil, history)
+	require.NoError(t, err)
+
+	userID := common.UserID(common.NewID())
+	task := bulkTask(userID, "Renew passport")
+	task.ID = common.TaskID(common.NewID())
+	require.NoError(t, repo.CreateTask(task))
+
+	now := time.Now()
+	sent := now.Add(-time.Minute)
+	for _, reminder := range []*Reminder{
+		{ScheduledAt: now.Add(2 * time.Hour), ReminderType: ReminderTypeNudge},
+		{ScheduledAt: now.Add(time.Hour), ReminderType: ReminderTypeInitial},
+		{ScheduledAt: sent, SentAt: &sent, ReminderType: ReminderTypeInitial},
+	} {
+		reminder.ID = common.ID(common.NewID())
+		reminder.TaskID, reminder.UserID, reminder.ChatID = task.ID, userID, task.ChatID
+		require.NoError(t, repo.CreateReminder(reminder))
+	}
+	for i, kind := range []TaskEventKind{TaskEventCreated, TaskEventSnoozed, TaskEventSnoozed, TaskEventNudgeSent} {
+		require.NoError(t, historyRepo.AppendTaskEvent(&TaskEvent{
+			ID: common.ID(common.NewID()), TaskID: task.ID, Kind: kind, OccurredAt: now.Add(time.Duration(i-10) * time.Hour),
+		}))
+	}
+
+	s := service.(*nudgeService)
+	s.handleTaskDetailsRequested(events.TaskDetailsRequested{Event: events.NewEvent(), UserID: string(userID), ChatID: "12345", TaskID: string(task.ID)})
+	s.handleTaskDetailsRequested(events.TaskDetailsRequested{Event: events.NewEvent(), UserID: string(common.NewID()), ChatID: "12345", TaskID: string(task.ID)})
+	responses := eventBus.GetPublishedEvents(events.TopicTaskDetailsResponse)
+	require.Len(t, responses, 2)
+
+	details := responses[0].(events.TaskDetailsResponse)
+	require.True(t, details.Success, details.Message)
+	require.Len(t, details.Reminders, 2, "sent reminders are left out")
+	assert.Equal(t, "initial", details.Reminders[0].Type)
+	assert.Equal(t, "nudge", details.Reminders[1].Type)
+	assert.Equal(t, events.TaskHistorySummary{Entries: 4, Snoozes: 2, Nudges: 1, Last: details.History.Last}, details.History)
+	require.NotNil(t, details.History.Last)
+	assert.Equal(t, "nudge_sent", details.History.Last.Kind)
+
+	assert.False(t, responses[1].(events.TaskDetailsResponse).Success, "other users can't view the task")
+}
@@ -0,0 +1,120 @@
+package nudge
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// TaskEdit holds the fields to change on a task; nil fields are left as they are
+type TaskEdit struct {
+	Title       *string
+	Description *string
+	Priority    *common.Priority
+	DueDate     *time.Time
+	// ClearDueDate removes the due date; DueDate is ignored when it is set
+	ClearDueDate bool
+}
+
+// GetTask returns one of the user's tasks. Tasks of other users and deleted
+// tasks are reported as ErrTaskNotFound.
+func (s *nudgeService) GetTask(userID common.UserID, taskID common.TaskID) (*Task, error) {
+	if s.repository == nil {
+		return nil, fmt.Errorf("repository not initialized")
+	}
+
+	task, err := s.forUser(string(userID)).repository.GetTaskByID(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.UserID != userID || task.Status == common.TaskStatusDeleted {
+		return nil, ErrTaskNotFound
+	}
+	return task, nil
+}
+
+// EditTask changes the title, description, priority or due date of a task.
+// Pending reminders follow a changed due date.
+func (s *nudgeService) EditTask(taskID common.TaskID, edit TaskEdit) (*Task, error) {
+	s.logger.Info("Editing task", zap.String("taskID", string(taskID)))
+
+	if s.repository == nil {
+		return nil, fmt.Errorf("repository not initialized")
+	}
+
+	task, err := s.repository.GetTaskByID(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []string
+	if edit.Title != nil && strings.TrimSpace(*edit.Title) != task.Title {
+		task.Title = strings.TrimSpace(*edit.Title)
+		fields = append(fields, "title")
+	}
+	if edit.Description != nil && strings.TrimSpace(*edit.Description) != task.Description {
+		task.Description = strings.TrimSpace(*edit.Description)
+		fields = append(fields, "description")
+	}
+	if edit.Priority != nil && *edit.Priority != task.Priority {
+		task.Priority = *edit.Priority
+		fields = append(fields, "priority")
+	}
+
+	previousDue := task.DueDate
+	switch {
+	case edit.ClearDueDate && task.DueDate != nil:
+		task.DueDate = nil
+		fields = append(fields, "due_date")
+	case !edit.ClearDueDate && edit.DueDate != nil && (task.DueDate == nil || !task.DueDate.Equal(*edit.DueDate)):
+		dueDate := *edit.DueDate
+		task.DueDate = &dueDate
+		fields = append(fields, "due_date")
+	}
+
+	if len(fields) == 0 {
+		return task, nil
+	}
+
+	if err := s.validator.ValidateTask(task); err != nil {
+		return nil, err
+	}
+	task.UpdatedAt = time.Now()
+	if err := s.repository.UpdateTask(task); err != nil {
+		return nil, err
+	}
+
+	event := events.TaskEdited{
+		Event:  events.NewEvent(),
+		TaskID: string(task.ID),
+		UserID: string(task.UserID),
+		Fields: fields,
+	}
+	if previousDue != task.DueDate {
+		event.PreviousDueDate = previousDue
+		event.DueDate = task.DueDate
+		if task.Status.IsOpen() {
+			s.goBackground(func() {
+				s.cancelTaskReminders(taskID)
+				if task.DueDate != nil {
+					s.scheduleInitialReminder(task)
+				}
+			})
+		}
+	}
+	if err := s.eventBus.Publish(events.TopicTaskEdited, event); err != nil {
+		s.logger.Error("Failed to publish TaskEdited event",
+			zap.String("taskID", string(task.ID)),
+			zap.Error(err))
+	}
+
+	s.logger.Info("Task edited",
+		zap.String("taskID", string(taskID)),
+		zap.Strings("fields", fields))
+	return task, nil
+}
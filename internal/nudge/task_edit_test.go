@@ -0,0 +1,77 @@
+package nudge
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNudgeService_EditTask(t *testing.T) {
+	service, repo, eventBus := newBulkTestService(t)
+	userID := common.UserID(common.NewID())
+
+	task := bulkTask(userID, "Buy milk")
+	task.ID = common.TaskID(common.NewID())
+	require.NoError(t, repo.CreateTask(task))
+
+	title := "  Buy oat milk "
+	priority := common.PriorityHigh
+	dueDate := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+	edited, err := service.EditTask(task.ID, TaskEdit{Title: &title, Priority: &priority, DueDate: &dueDate})
+	require.NoError(t, err)
+	assert.Equal(t, "Buy oat milk", edited.Title)
+	assert.Equal(t, common.PriorityHigh, edited.Priority)
+	require.NotNil(t, edited.DueDate)
+	assert.True(t, dueDate.Equal(*edited.DueDate))
+
+	published := eventBus.GetPublishedEvents(events.TopicTaskEdited)
+	require.Len(t, published, 1)
+	event := published[0].(events.TaskEdited)
+	assert.Equal(t, []string{"title", "priority", "due_date"}, event.Fields)
+	assert.Nil(t, event.PreviousDueDate)
+
+	// Unchanged values are not an edit
+	_, err = service.EditTask(task.ID, TaskEdit{Title: &title})
+	require.NoError(t, err)
+	assert.Len(t, eventBus.GetPublishedEvents(events.TopicTaskEdited), 1)
+
+	empty := " "
+	_, err = service.EditTask(task.ID, TaskEdit{Title: &empty})
+	assert.True(t, IsValidationError(err))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, service.Stop(ctx))
+
+	// The new due date got a reminder
+	reminders, err := repo.GetRemindersByTaskID(task.ID)
+	require.NoError(t, err)
+	assert.Len(t, reminders, 1)
+}
+
+func TestNudgeService_GetTask(t *testing.T) {
+	service, repo, _ := newBulkTestService(t)
+	userID := common.UserID(common.NewID())
+
+	task := bulkTask(userID, "Renew passport")
+	task.ID = common.TaskID(common.NewID())
+	require.NoError(t, repo.CreateTask(task))
+
+	found, err := service.GetTask(userID, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Renew passport", found.Title)
+
+	_, err = service.GetTask(common.UserID(common.NewID()), task.ID)
+	assert.ErrorIs(t, err, ErrTaskNotFound, "other users' tasks are not found")
+
+	task.Status = common.TaskStatusDeleted
+	require.NoError(t, repo.UpdateTask(task))
+	_, err = service.GetTask(userID, task.ID)
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+}
@@ -0,0 +1,328 @@
+package nudge
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/tenant"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// tenantNudgeRepository scopes every operation to a tenant. The tenant is
+// carried in the GORM context, where the tenant plugin tags created rows and
+// filters queries. Operations run in the tenant of the caller the context
+// carries (see tenant.Caller), so a row tagged with another tenant is never
+// reached. Operations addressed by a task, reminder or chat ID are refused
+// without a caller; user-addressed ones then use the user's tenant, and the
+// scheduler's scans run once per known tenant.
+type tenantNudgeRepository struct {
+	db       *gorm.DB
+	logger   *zap.Logger
+	resolver tenant.Resolver
+	tenants  []string
+	ctx      context.Context
+}
+
+// NewTenantNudgeRepository creates a GORM-based nudge repository whose
+// queries are scoped to each caller's tenant. tenantIDs lists the tenants the
+// scheduler's scans cover. The db must have the tenant plugin installed.
+func NewTenantNudgeRepository(db *gorm.DB, logger *zap.Logger, resolver tenant.Resolver, tenantIDs []string) NudgeRepository {
+	return &tenantNudgeRepository{
+		db:       db,
+		logger:   logger,
+		resolver: resolver,
+		tenants:  tenantIDs,
+		ctx:      context.Background(),
+	}
+}
+
+// forTenant returns a repository scoped to the tenant
+func (r *tenantNudgeRepository) forTenant(id string) NudgeRepository {
+	return &gormNudgeRepository{
+		db:     r.db.WithContext(tenant.WithID(r.ctx, id)),
+		logger: r.logger.With(zap.String("tenantID", id)),
+	}
+}
+
+// forCaller returns a repository scoped to the caller's tenant; ok is false
+// when the context carries no caller
+func (r *tenantNudgeRepository) forCaller() (repo NudgeRepository, ok bool, err error) {
+	id, ok, err := tenant.Caller(r.ctx, r.resolver, r.tenants)
+	if err != nil {
+		return nil, false, WrapRepositoryError(err, "resolve tenant")
+	}
+	if !ok {
+		return nil, false, nil
+	}
+	return r.forTenant(id), true, nil
+}
+
+// scoped returns a repository scoped to the caller's tenant, for operations
+// addressed by ID, which are only made for a caller
+func (r *tenantNudgeRepository) scoped(operation string) (NudgeRepository, error) {
+	repo, ok, err := r.forCaller()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, WrapRepositoryError(tenant.ErrNoCaller, operation)
+	}
+	return repo, nil
+}
+
+// forUser returns a repository scoped to the caller's tenant, or to the
+// user's tenant for calls made without a caller
+func (r *tenantNudgeRepository) forUser(userID common.UserID) (NudgeRepository, error) {
+	if repo, ok, err := r.forCaller(); ok || err != nil {
+		return repo, err
+	}
+
+	id, err := r.resolver.TenantFor(userID)
+	if err != nil {
+		return nil, WrapRepositoryError(err, "resolve tenant")
+	}
+	return r.forTenant(id), nil
+}
+
+// scanned returns the tenants a scan covers: the caller's, or every known
+// tenant for calls made without a caller
+func (r *tenantNudgeRepository) scanned() ([]string, error) {
+	id, ok, err := tenant.Caller(r.ctx, r.resolver, r.tenants)
+	if err != nil {
+		return nil, WrapRepositoryError(err, "resolve tenant")
+	}
+	if ok {
+		return []string{id}, nil
+	}
+	return r.tenants, nil
+}
+
+// WithContext returns the repository making its calls for the caller ctx carries
+func (r *tenantNudgeRepository) WithContext(ctx context.Context) NudgeRepository {
+	scoped := *r
+	scoped.ctx = ctx
+	return &scoped
+}
+
+// CreateTask creates a task in the caller's tenant
+func (r *tenantNudgeRepository) CreateTask(task *Task) error {
+	repo, err := r.forUser(task.UserID)
+	if err != nil {
+		return err
+	}
+	return repo.CreateTask(task)
+}
+
+// GetTaskByID retrieves a task by its ID within the caller's tenant
+func (r *tenantNudgeRepository) GetTaskByID(taskID common.TaskID) (*Task, error) {
+	repo, err := r.scoped("get task by ID")
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetTaskByID(taskID)
+}
+
+// GetTasksByUserID retrieves the user's tasks within the caller's tenant
+func (r *tenantNudgeRepository) GetTasksByUserID(userID common.UserID, filter TaskFilter) ([]*Task, error) {
+	repo, err := r.forUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetTasksByUserID(userID, filter)
+}
+
+// UpdateTask updates a task within the caller's tenant
+func (r *tenantNudgeRepository) UpdateTask(task *Task) error {
+	repo, err := r.forUser(task.UserID)
+	if err != nil {
+		return err
+	}
+	return repo.UpdateTask(task)
+}
+
+// DeleteTask soft deletes a task within the caller's tenant
+func (r *tenantNudgeRepository) DeleteTask(taskID common.TaskID) error {
+	repo, err := r.scoped("delete task")
+	if err != nil {
+		return err
+	}
+	return repo.DeleteTask(taskID)
+}
+
+// GetTaskStats retrieves the user's task statistics
+func (r *tenantNudgeRepository) GetTaskStats(userID common.UserID) (*TaskStats, error) {
+	repo, err := r.forUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetTaskStats(userID)
+}
+
+// GetOverdueTasks retrieves the user's overdue tasks within the caller's tenant
+func (r *tenantNudgeRepository) GetOverdueTasks(userID common.UserID) ([]*Task, error) {
+	repo, err := r.forUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetOverdueTasks(userID)
+}
+
+// BulkUpdateTaskStatus updates the status of several tasks within the
+// caller's tenant; tasks of other tenants are left alone
+func (r *tenantNudgeRepository) BulkUpdateTaskStatus(taskIDs []common.TaskID, status common.TaskStatus) error {
+	repo, err := r.scoped("bulk update task status")
+	if err != nil {
+		return err
+	}
+	return repo.BulkUpdateTaskStatus(taskIDs, status)
+}
+
+// BulkShiftDueDates moves the due dates of several tasks and their unsent
+// reminders within the caller's tenant
+func (r *tenantNudgeRepository) BulkShiftDueDates(taskIDs []common.TaskID, shift time.Duration) error {
+	repo, err := r.scoped("bulk shift due dates")
+	if err != nil {
+		return err
+	}
+	return repo.BulkShiftDueDates(taskIDs, shift)
+}
+
+// MoveChatID readdresses the rows of a chat within the caller's tenant,
+// which is that of the bot the chat talks to
+func (r *tenantNudgeRepository) MoveChatID(from, to common.ChatID) error {
+	repo, err := r.scoped("move chat ID")
+	if err != nil {
+		return err
+	}
+	return repo.MoveChatID(from, to)
+}
+
+// GetNewlyOverdueTasks retrieves the newly overdue tasks of each scanned
+// tenant, earliest due date first
+func (r *tenantNudgeRepository) GetNewlyOverdueTasks(now time.Time, limit int) ([]*Task, error) {
+	ids, err := r.scanned()
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []*Task
+	for _, id := range ids {
+		found, err := r.forTenant(id).GetNewlyOverdueTasks(now, limit)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, found...)
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].DueDate.Before(*tasks[j].DueDate)
+	})
+	if limit > 0 && len(tasks) > limit {
+		tasks = tasks[:limit]
+	}
+	return tasks, nil
+}
+
+// MarkTaskOverdue records the due date a task was found overdue for
+func (r *tenantNudgeRepository) MarkTaskOverdue(taskID common.TaskID, dueDate time.Time) error {
+	repo, err := r.scoped("mark task overdue")
+	if err != nil {
+		return err
+	}
+	return repo.MarkTaskOverdue(taskID, dueDate)
+}
+
+// CreateReminder creates a reminder in the caller's tenant
+func (r *tenantNudgeRepository) CreateReminder(reminder *Reminder) error {
+	repo, err := r.forUser(reminder.UserID)
+	if err != nil {
+		return err
+	}
+	return repo.CreateReminder(reminder)
+}
+
+// GetDueReminders retrieves the due reminders of each scanned tenant
+func (r *tenantNudgeRepository) GetDueReminders(before time.Time) ([]*Reminder, error) {
+	ids, err := r.scanned()
+	if err != nil {
+		return nil, err
+	}
+
+	var reminders []*Reminder
+	for _, id := range ids {
+		due, err := r.forTenant(id).GetDueReminders(before)
+		if err != nil {
+			return nil, err
+		}
+		reminders = append(reminders, due...)
+	}
+	return reminders, nil
+}
+
+// MarkReminderSent marks a reminder as sent within the caller's tenant
+func (r *tenantNudgeRepository) MarkReminderSent(reminderID common.ID) error {
+	repo, err := r.scoped("mark reminder sent")
+	if err != nil {
+		return err
+	}
+	return repo.MarkReminderSent(reminderID)
+}
+
+// GetRemindersByTaskID retrieves the reminders of a task within the caller's tenant
+func (r *tenantNudgeRepository) GetRemindersByTaskID(taskID common.TaskID) ([]*Reminder, error) {
+	repo, err := r.scoped("get reminders by task ID")
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetRemindersByTaskID(taskID)
+}
+
+// DeleteReminder deletes a reminder within the caller's tenant
+func (r *tenantNudgeRepository) DeleteReminder(reminderID common.ID) error {
+	repo, err := r.scoped("delete reminder")
+	if err != nil {
+		return err
+	}
+	return repo.DeleteReminder(reminderID)
+}
+
+// GetNudgeSettingsByUserID retrieves the user's nudge settings within the caller's tenant
+func (r *tenantNudgeRepository) GetNudgeSettingsByUserID(userID common.UserID) (*NudgeSettings, error) {
+	repo, err := r.forUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	return repo.GetNudgeSettingsByUserID(userID)
+}
+
+// CreateOrUpdateNudgeSettings saves the user's nudge settings in the caller's tenant
+func (r *tenantNudgeRepository) CreateOrUpdateNudgeSettings(settings *NudgeSettings) error {
+	repo, err := r.forUser(settings.UserID)
+	if err != nil {
+		return err
+	}
+	return repo.CreateOrUpdateNudgeSettings(settings)
+}
+
+// DeleteNudgeSettings deletes the user's nudge settings within the caller's tenant
+func (r *tenantNudgeRepository) DeleteNudgeSettings(userID common.UserID) error {
+	repo, err := r.forUser(userID)
+	if err != nil {
+		return err
+	}
+	return repo.DeleteNudgeSettings(userID)
+}
+
+// WithTransaction executes fn within a transaction whose operations are
+// scoped like the repository's own
+func (r *tenantNudgeRepository) WithTransaction(fn func(NudgeRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		scoped := *r
+		scoped.db = tx
+		return fn(&scoped)
+	})
+}
@@ -0,0 +1,132 @@
+package nudge
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestTenantNudgeRepository_ScopesToUsersTenant(t *testing.T) {
+	// A dry run builds SQL without connecting to a database
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(tenant.NewPlugin()))
+
+	acmeUser := common.UserID(common.NewID())
+	resolver := tenant.ResolverFunc(func(userID common.UserID) (string, error) {
+		if userID == acmeUser {
+			return "acme", nil
+		}
+		return "", errors.New("unknown user")
+	})
+	repo := NewTenantNudgeRepository(db, zap.NewNop(), resolver, []string{tenant.DefaultID, "acme"})
+
+	task := &Task{
+		ID:       common.TaskID(common.NewID()),
+		UserID:   acmeUser,
+		Title:    "Write report",
+		Priority: common.PriorityHigh,
+		Status:   common.TaskStatusActive,
+	}
+	require.NoError(t, repo.CreateTask(task))
+	assert.Equal(t, "acme", task.TenantID)
+
+	settings := &NudgeSettings{UserID: acmeUser, NudgeInterval: DefaultNudgeInterval, MaxNudges: 3, Enabled: true}
+	require.NoError(t, repo.CreateOrUpdateNudgeSettings(settings))
+	assert.Equal(t, "acme", settings.TenantID)
+
+	// Users whose tenant cannot be resolved are refused rather than unscoped
+	_, err = repo.GetTasksByUserID(common.UserID(common.NewID()), TaskFilter{})
+	assert.Error(t, err)
+}
+
+func TestTenantNudgeRepository_ScopesByIDToCallersTenant(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(tenant.NewPlugin()))
+
+	// A dry run finds no rows, so the scoped statements are recorded instead
+	type statement struct {
+		sql  string
+		vars []interface{}
+	}
+	var statements []statement
+	record := func(db *gorm.DB) {
+		statements = append(statements, statement{sql: db.Statement.SQL.String(), vars: db.Statement.Vars})
+	}
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:record", record))
+	require.NoError(t, db.Callback().Update().After("gorm:update").Register("test:record", record))
+	require.NoError(t, db.Callback().Delete().After("gorm:delete").Register("test:record", record))
+
+	globexUser := common.UserID(common.NewID())
+	resolver := tenant.ResolverFunc(func(userID common.UserID) (string, error) {
+		if userID == globexUser {
+			return "globex", nil
+		}
+		return "acme", nil
+	})
+	repo := NewTenantNudgeRepository(db, zap.NewNop(), resolver, []string{tenant.DefaultID, "acme", "globex"})
+	taskID := common.TaskID(common.NewID())
+
+	// Calls addressed by ID are refused without a caller
+	_, err = repo.GetTaskByID(taskID)
+	assert.ErrorIs(t, err, tenant.ErrNoCaller)
+	assert.ErrorIs(t, repo.MoveChatID("old-chat", "new-chat"), tenant.ErrNoCaller)
+	assert.Empty(t, statements)
+
+	// They run in the caller's tenant, whoever owns the row
+	caller := repo.WithContext(tenant.WithUser(context.Background(), globexUser))
+	_, _ = caller.GetTaskByID(taskID)
+	_ = caller.DeleteTask(taskID)
+	_ = caller.MarkReminderSent(common.ID(common.NewID()))
+	_ = caller.DeleteReminder(common.ID(common.NewID()))
+	_, _ = caller.GetRemindersByTaskID(taskID)
+	_ = caller.MarkTaskOverdue(taskID, time.Now())
+	_ = caller.BulkUpdateTaskStatus([]common.TaskID{taskID}, common.TaskStatusCompleted)
+	require.Len(t, statements, 7)
+	for _, statement := range statements {
+		assert.Contains(t, statement.sql, `"tenant_id" =`)
+		assert.Contains(t, statement.vars, "globex")
+	}
+
+	// A chat's rows are moved within the tenant of the bot it talks to
+	statements = nil
+	bot := repo.WithContext(tenant.WithBot(context.Background(), "acme")).(*tenantNudgeRepository)
+	scoped, err := bot.scoped("move chat ID")
+	require.NoError(t, err)
+	require.NoError(t, moveChatRows(scoped.(*gormNudgeRepository).db, "old-chat", "new-chat"))
+	require.NotEmpty(t, statements)
+	for _, statement := range statements {
+		assert.Contains(t, statement.sql, `"tenant_id" =`, statement.sql)
+		assert.Contains(t, statement.vars, "acme", statement.sql)
+	}
+
+	// The scheduler's scan runs once for each tenant, or for the caller's only
+	statements = nil
+	_, err = repo.GetDueReminders(time.Now())
+	require.NoError(t, err)
+	require.Len(t, statements, 3)
+	statements = nil
+	_, err = repo.WithContext(tenant.WithID(context.Background(), "acme")).GetDueReminders(time.Now())
+	require.NoError(t, err)
+	require.Len(t, statements, 1)
+	assert.Contains(t, statements[0].vars, "acme")
+}
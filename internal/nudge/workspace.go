@@ -0,0 +1,396 @@
+package nudge
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"go.uber.org/zap"
+)
+
+// Role is a member's role in a workspace, the set of people sharing a chat's tasks
+type Role string
+
+// Workspace roles, from most to least privileged
+const (
+	RoleOwner  Role = "owner"
+	RoleEditor Role = "editor"
+	RoleViewer Role = "viewer"
+)
+
+// Workspace actions checked against a member's role
+const (
+	ActionView     = "view"
+	ActionComplete = "complete"
+	ActionEdit     = "edit"
+	ActionSnooze   = "snooze"
+	ActionDelete   = "delete"
+	ActionInvite   = "invite"
+	ActionManage   = "manage_members"
+)
+
+// DefaultInviteTTL is how long an invite link stays valid
+const DefaultInviteTTL = 7 * 24 * time.Hour
+
+// ErrInviteNotFound is returned for unknown or expired invite codes
+var ErrInviteNotFound = errors.New("invite not found or expired")
+
+// roleActions lists what each role may do to tasks of other members
+var roleActions = map[Role]map[string]bool{
+	RoleOwner: {
+		ActionView: true, ActionComplete: true, ActionEdit: true, ActionSnooze: true,
+		ActionDelete: true, ActionInvite: true, ActionManage: true,
+	},
+	RoleEditor: {
+		ActionView: true, ActionComplete: true, ActionEdit: true, ActionSnooze: true,
+	},
+	RoleViewer: {
+		ActionView: true,
+	},
+}
+
+// IsValid checks if the role is known
+func (r Role) IsValid() bool {
+	_, ok := roleActions[r]
+	return ok
+}
+
+// Allows reports whether the role permits the action
+func (r Role) Allows(action string) bool {
+	return roleActions[r][action]
+}
+
+// WorkspaceMember is a user's membership in the workspace of a chat
+type WorkspaceMember struct {
+	ChatID    common.ChatID `json:"chat_id" gorm:"primaryKey;type:varchar(36)"`
+	UserID    common.UserID `json:"user_id" gorm:"primaryKey;type:varchar(36)"`
+	TenantID  string        `json:"tenant_id,omitempty" gorm:"type:varchar(64);not null;default:'default';index"`
+	Role      Role          `json:"role" gorm:"type:varchar(20);not null"`
+	CreatedAt time.Time     `json:"created_at" gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt time.Time     `json:"updated_at" gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+}
+
+// TableName returns the table name for the WorkspaceMember model
+func (WorkspaceMember) TableName() string {
+	return "workspace_members"
+}
+
+// WorkspaceInvite is an invite link granting a role in a chat's workspace
+type WorkspaceInvite struct {
+	Code      string        `json:"code" gorm:"primaryKey;type:varchar(32)"`
+	ChatID    common.ChatID `json:"chat_id" gorm:"type:varchar(36);not null;index"`
+	TenantID  string        `json:"tenant_id,omitempty" gorm:"type:varchar(64);not null;default:'default';index"`
+	Role      Role          `json:"role" gorm:"type:varchar(20);not null"`
+	CreatedBy common.UserID `json:"created_by" gorm:"type:varchar(36);not null"`
+	ExpiresAt time.Time     `json:"expires_at" gorm:"type:timestamp;not null"`
+	CreatedAt time.Time     `json:"created_at" gorm:"type:timestamp;not null;default:CURRENT_TIMESTAMP"`
+}
+
+// TableName returns the table name for the WorkspaceInvite model
+func (WorkspaceInvite) TableName() string {
+	return "workspace_invites"
+}
+
+// PermissionError reports an action the user's workspace role does not allow
+type PermissionError struct {
+	UserID common.UserID
+	ChatID common.ChatID
+	Action string
+	Role   Role // empty when the user is not a member
+}
+
+func (e PermissionError) Error() string {
+	if e.Role == "" {
+		return fmt.Sprintf("user %s is not a member of workspace %s", e.UserID, e.ChatID)
+	}
+	return fmt.Sprintf("role %s in workspace %s does not allow %s", e.Role, e.ChatID, e.Action)
+}
+
+func (e PermissionError) Code() string {
+	return ErrCodeUnauthorized
+}
+
+func (e PermissionError) Message() string {
+	if e.Role == "" {
+		return "you are not a member of this workspace"
+	}
+	return fmt.Sprintf("your role (%s) does not allow this action", e.Role)
+}
+
+func (e PermissionError) Temporary() bool {
+	return false
+}
+
+// WorkspaceService manages workspace membership, invites and role checks
+type WorkspaceService interface {
+	Authorize(chatID common.ChatID, userID common.UserID, action string) error
+	CreateInvite(chatID common.ChatID, inviterID common.UserID, role Role) (*WorkspaceInvite, error)
+	Join(code string, userID common.UserID) (*WorkspaceMember, error)
+	ListMembers(chatID common.ChatID) ([]*WorkspaceMember, error)
+	SetRole(chatID common.ChatID, actorID, userID common.UserID, role Role) error
+	RemoveMember(chatID common.ChatID, actorID, userID common.UserID) error
+	Ready() <-chan struct{}
+}
+
+// workspaceService implements the WorkspaceService interface
+type workspaceService struct {
+	eventBus   events.EventBus
+	logger     *zap.Logger
+	repository WorkspaceRepository
+	inviteTTL  time.Duration
+	clock      common.Clock
+	ready      *common.Readiness
+}
+
+// NewWorkspaceService creates a WorkspaceService whose invites expire after
+// inviteTTL. A non-positive TTL uses DefaultInviteTTL.
+func NewWorkspaceService(eventBus events.EventBus, logger *zap.Logger, repository WorkspaceRepository, inviteTTL time.Duration) WorkspaceService {
+	if inviteTTL <= 0 {
+		inviteTTL = DefaultInviteTTL
+	}
+
+	service := &workspaceService{
+		eventBus:   eventBus,
+		logger:     logger,
+		repository: repository,
+		inviteTTL:  inviteTTL,
+		clock:      common.NewRealClock(),
+		ready:      common.NewReadiness(),
+	}
+
+	service.setupEventSubscriptions()
+
+	return service
+}
+
+// setupEventSubscriptions sets up event subscriptions for the workspace service
+func (s *workspaceService) setupEventSubscriptions() {
+	if err := s.eventBus.Subscribe(events.TopicWorkspaceInviteRequested, s.handleInviteRequested); err != nil {
+		s.logger.Error("Failed to subscribe to WorkspaceInviteRequested events", zap.Error(err))
+	}
+
+	if err := s.eventBus.Subscribe(events.TopicWorkspaceJoinRequested, s.handleJoinRequested); err != nil {
+		s.logger.Error("Failed to subscribe to WorkspaceJoinRequested events", zap.Error(err))
+	}
+
+	s.ready.MarkReady()
+}
+
+// Ready returns a channel that is closed once event subscriptions are registered
+func (s *workspaceService) Ready() <-chan struct{} {
+	return s.ready.Ready()
+}
+
+// Authorize checks that the user's role in the chat's workspace allows the action
+func (s *workspaceService) Authorize(chatID common.ChatID, userID common.UserID, action string) error {
+	member, err := s.repository.GetMember(chatID, userID)
+	if err != nil {
+		return err
+	}
+	if member == nil {
+		return PermissionError{UserID: userID, ChatID: chatID, Action: action}
+	}
+	if !member.Role.Allows(action) {
+		return PermissionError{UserID: userID, ChatID: chatID, Action: action, Role: member.Role}
+	}
+	return nil
+}
+
+// CreateInvite creates an invite link for the workspace. The first person to
+// invite others to a chat without members becomes its owner.
+func (s *workspaceService) CreateInvite(chatID common.ChatID, inviterID common.UserID, role Role) (*WorkspaceInvite, error) {
+	if !role.IsValid() {
+		return nil, NewTaskValidationError("role", role, "role must be owner, editor or viewer")
+	}
+
+	members, err := s.repository.ListMembers(chatID)
+	if err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		owner := &WorkspaceMember{ChatID: chatID, UserID: inviterID, Role: RoleOwner}
+		if err := s.repository.SaveMember(owner); err != nil {
+			return nil, err
+		}
+		s.logger.Info("Workspace created",
+			zap.String("chatID", string(chatID)),
+			zap.String("ownerID", string(inviterID)))
+	} else if err := s.Authorize(chatID, inviterID, ActionInvite); err != nil {
+		return nil, err
+	}
+
+	invite := &WorkspaceInvite{
+		Code:      strings.ReplaceAll(string(common.NewID()), "-", ""),
+		ChatID:    chatID,
+		Role:      role,
+		CreatedBy: inviterID,
+		ExpiresAt: s.clock.Now().Add(s.inviteTTL),
+	}
+	if err := s.repository.CreateInvite(invite); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Workspace invite created",
+		zap.String("chatID", string(chatID)),
+		zap.String("inviterID", string(inviterID)),
+		zap.String("role", string(role)))
+	return invite, nil
+}
+
+// Join redeems an invite code. Joining never lowers an existing member's role.
+func (s *workspaceService) Join(code string, userID common.UserID) (*WorkspaceMember, error) {
+	invite, err := s.repository.GetInvite(code)
+	if err != nil {
+		return nil, err
+	}
+	if invite == nil || !s.clock.Now().Before(invite.ExpiresAt) {
+		return nil, ErrInviteNotFound
+	}
+
+	member, err := s.repository.GetMember(invite.ChatID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member != nil && !roleOutranks(invite.Role, member.Role) {
+		return member, nil
+	}
+
+	member = &WorkspaceMember{ChatID: invite.ChatID, UserID: userID, Role: invite.Role}
+	if err := s.repository.SaveMember(member); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("User joined workspace",
+		zap.String("chatID", string(invite.ChatID)),
+		zap.String("userID", string(userID)),
+		zap.String("role", string(member.Role)))
+	return member, nil
+}
+
+// ListMembers returns the members of a chat's workspace
+func (s *workspaceService) ListMembers(chatID common.ChatID) ([]*WorkspaceMember, error) {
+	return s.repository.ListMembers(chatID)
+}
+
+// SetRole changes a member's role; only owners may do so
+func (s *workspaceService) SetRole(chatID common.ChatID, actorID, userID common.UserID, role Role) error {
+	if !role.IsValid() {
+		return NewTaskValidationError("role", role, "role must be owner, editor or viewer")
+	}
+	if err := s.Authorize(chatID, actorID, ActionManage); err != nil {
+		return err
+	}
+	if err := s.ensureAnotherOwner(chatID, userID, role); err != nil {
+		return err
+	}
+
+	return s.repository.SaveMember(&WorkspaceMember{ChatID: chatID, UserID: userID, Role: role})
+}
+
+// RemoveMember removes a member from the workspace; only owners may do so
+func (s *workspaceService) RemoveMember(chatID common.ChatID, actorID, userID common.UserID) error {
+	if err := s.Authorize(chatID, actorID, ActionManage); err != nil {
+		return err
+	}
+	if err := s.ensureAnotherOwner(chatID, userID, ""); err != nil {
+		return err
+	}
+
+	return s.repository.DeleteMember(chatID, userID)
+}
+
+// ensureAnotherOwner refuses changes that would leave the workspace without an owner
+func (s *workspaceService) ensureAnotherOwner(chatID common.ChatID, userID common.UserID, newRole Role) error {
+	if newRole == RoleOwner {
+		return nil
+	}
+
+	members, err := s.repository.ListMembers(chatID)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		if member.Role == RoleOwner && member.UserID != userID {
+			return nil
+		}
+	}
+	return NewBusinessRuleError("last_owner", "a workspace must keep at least one owner")
+}
+
+// roleOutranks reports whether role a grants more than role b
+func roleOutranks(a, b Role) bool {
+	rank := map[Role]int{RoleViewer: 1, RoleEditor: 2, RoleOwner: 3}
+	return rank[a] > rank[b]
+}
+
+// handleInviteRequested creates an invite for a chat command
+func (s *workspaceService) handleInviteRequested(event events.WorkspaceInviteRequested) {
+	response := events.WorkspaceInviteResponse{
+		Event:  events.NewEvent(),
+		UserID: event.UserID,
+		ChatID: event.ChatID,
+		Role:   event.Role,
+	}
+	response.CorrelationID = event.CorrelationID
+
+	invite, err := s.CreateInvite(common.ChatID(event.ChatID), common.UserID(event.UserID), Role(event.Role))
+	if err != nil {
+		s.logger.Warn("Failed to create workspace invite",
+			zap.String("correlationID", event.CorrelationID),
+			zap.String("chatID", event.ChatID),
+			zap.Error(err))
+		response.Message = workspaceErrorMessage(err)
+	} else {
+		response.Success = true
+		response.Code = invite.Code
+		response.Message = fmt.Sprintf("Invite valid until %s", invite.ExpiresAt.Format("2006-01-02 15:04 MST"))
+	}
+
+	if err := s.eventBus.Publish(events.TopicWorkspaceInviteResponse, response); err != nil {
+		s.logger.Error("Failed to publish WorkspaceInviteResponse", zap.Error(err))
+	}
+}
+
+// handleJoinRequested redeems an invite opened from a deep link
+func (s *workspaceService) handleJoinRequested(event events.WorkspaceJoinRequested) {
+	response := events.WorkspaceJoinResponse{
+		Event:  events.NewEvent(),
+		UserID: event.UserID,
+		ChatID: event.ChatID,
+	}
+	response.CorrelationID = event.CorrelationID
+
+	member, err := s.Join(event.Code, common.UserID(event.UserID))
+	if err != nil {
+		s.logger.Warn("Failed to join workspace",
+			zap.String("correlationID", event.CorrelationID),
+			zap.String("userID", event.UserID),
+			zap.Error(err))
+		response.Message = workspaceErrorMessage(err)
+	} else {
+		response.Success = true
+		response.WorkspaceID = string(member.ChatID)
+		response.Role = string(member.Role)
+		response.Message = fmt.Sprintf("You joined the workspace as %s.", member.Role)
+	}
+
+	if err := s.eventBus.Publish(events.TopicWorkspaceJoinResponse, response); err != nil {
+		s.logger.Error("Failed to publish WorkspaceJoinResponse", zap.Error(err))
+	}
+}
+
+// workspaceErrorMessage returns a message that can be shown to the user
+func workspaceErrorMessage(err error) string {
+	var nudgeErr NudgeError
+	if errors.As(err, &nudgeErr) && nudgeErr.Code() != ErrCodeRepository {
+		return nudgeErr.Message()
+	}
+	if errors.Is(err, ErrInviteNotFound) {
+		return "This invite link is invalid or has expired."
+	}
+	return "Something went wrong, please try again later."
+}
@@ -0,0 +1,207 @@
+package nudge
+
+import (
+	"sync"
+	"time"
+
+	"nudgebot-api/internal/common"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WorkspaceRepository persists workspace members and invites. Lookups of
+// missing members and invites return nil without an error.
+type WorkspaceRepository interface {
+	GetMember(chatID common.ChatID, userID common.UserID) (*WorkspaceMember, error)
+	ListMembers(chatID common.ChatID) ([]*WorkspaceMember, error)
+	SaveMember(member *WorkspaceMember) error
+	DeleteMember(chatID common.ChatID, userID common.UserID) error
+	CreateInvite(invite *WorkspaceInvite) error
+	GetInvite(code string) (*WorkspaceInvite, error)
+}
+
+// gormWorkspaceRepository implements WorkspaceRepository using GORM
+type gormWorkspaceRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewGormWorkspaceRepository creates a new GORM-based workspace repository
+func NewGormWorkspaceRepository(db *gorm.DB, logger *zap.Logger) WorkspaceRepository {
+	return &gormWorkspaceRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetMember retrieves a user's membership in a workspace
+func (r *gormWorkspaceRepository) GetMember(chatID common.ChatID, userID common.UserID) (*WorkspaceMember, error) {
+	var members []*WorkspaceMember
+	err := r.db.Where("chat_id = ? AND user_id = ?", chatID, userID).Limit(1).Find(&members).Error
+	if err != nil {
+		return nil, WrapRepositoryError(err, "get workspace member")
+	}
+	if len(members) == 0 {
+		return nil, nil
+	}
+	return members[0], nil
+}
+
+// ListMembers retrieves every member of a workspace
+func (r *gormWorkspaceRepository) ListMembers(chatID common.ChatID) ([]*WorkspaceMember, error) {
+	var members []*WorkspaceMember
+	err := r.db.Where("chat_id = ?", chatID).Order("created_at ASC").Find(&members).Error
+	if err != nil {
+		return nil, WrapRepositoryError(err, "list workspace members")
+	}
+	return members, nil
+}
+
+// SaveMember creates a membership or updates its role
+func (r *gormWorkspaceRepository) SaveMember(member *WorkspaceMember) error {
+	now := time.Now()
+	if member.CreatedAt.IsZero() {
+		member.CreatedAt = now
+	}
+	member.UpdatedAt = now
+
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "chat_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role", "updated_at"}),
+	}).Create(member).Error
+	if err != nil {
+		return WrapRepositoryError(err, "save workspace member")
+	}
+
+	r.logger.Debug("Workspace member saved",
+		zap.String("chatID", string(member.ChatID)),
+		zap.String("userID", string(member.UserID)),
+		zap.String("role", string(member.Role)))
+	return nil
+}
+
+// DeleteMember removes a user from a workspace
+func (r *gormWorkspaceRepository) DeleteMember(chatID common.ChatID, userID common.UserID) error {
+	err := r.db.Where("chat_id = ? AND user_id = ?", chatID, userID).Delete(&WorkspaceMember{}).Error
+	if err != nil {
+		return WrapRepositoryError(err, "delete workspace member")
+	}
+	return nil
+}
+
+// CreateInvite stores a new invite
+func (r *gormWorkspaceRepository) CreateInvite(invite *WorkspaceInvite) error {
+	invite.CreatedAt = time.Now()
+	if err := r.db.Create(invite).Error; err != nil {
+		return WrapRepositoryError(err, "create workspace invite")
+	}
+	return nil
+}
+
+// GetInvite retrieves an invite by its code
+func (r *gormWorkspaceRepository) GetInvite(code string) (*WorkspaceInvite, error) {
+	var invites []*WorkspaceInvite
+	if err := r.db.Where("code = ?", code).Limit(1).Find(&invites).Error; err != nil {
+		return nil, WrapRepositoryError(err, "get workspace invite")
+	}
+	if len(invites) == 0 {
+		return nil, nil
+	}
+	return invites[0], nil
+}
+
+// memoryWorkspaceRepository implements WorkspaceRepository in memory
+type memoryWorkspaceRepository struct {
+	mu      sync.RWMutex
+	members map[common.ChatID]map[common.UserID]*WorkspaceMember
+	invites map[string]*WorkspaceInvite
+}
+
+// NewMemoryWorkspaceRepository creates a WorkspaceRepository that is not persisted
+func NewMemoryWorkspaceRepository() WorkspaceRepository {
+	return &memoryWorkspaceRepository{
+		members: make(map[common.ChatID]map[common.UserID]*WorkspaceMember),
+		invites: make(map[string]*WorkspaceInvite),
+	}
+}
+
+// GetMember retrieves a user's membership in a workspace
+func (r *memoryWorkspaceRepository) GetMember(chatID common.ChatID, userID common.UserID) (*WorkspaceMember, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	member, ok := r.members[chatID][userID]
+	if !ok {
+		return nil, nil
+	}
+	memberCopy := *member
+	return &memberCopy, nil
+}
+
+// ListMembers retrieves every member of a workspace
+func (r *memoryWorkspaceRepository) ListMembers(chatID common.ChatID) ([]*WorkspaceMember, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	members := make([]*WorkspaceMember, 0, len(r.members[chatID]))
+	for _, member := range r.members[chatID] {
+		memberCopy := *member
+		members = append(members, &memberCopy)
+	}
+	return members, nil
+}
+
+// SaveMember creates a membership or updates its role
+func (r *memoryWorkspaceRepository) SaveMember(member *WorkspaceMember) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.members[member.ChatID] == nil {
+		r.members[member.ChatID] = make(map[common.UserID]*WorkspaceMember)
+	}
+	now := time.Now()
+	if existing, ok := r.members[member.ChatID][member.UserID]; ok {
+		member.CreatedAt = existing.CreatedAt
+	} else if member.CreatedAt.IsZero() {
+		member.CreatedAt = now
+	}
+	member.UpdatedAt = now
+
+	memberCopy := *member
+	r.members[member.ChatID][member.UserID] = &memberCopy
+	return nil
+}
+
+// DeleteMember removes a user from a workspace
+func (r *memoryWorkspaceRepository) DeleteMember(chatID common.ChatID, userID common.UserID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.members[chatID], userID)
+	return nil
+}
+
+// CreateInvite stores a new invite
+func (r *memoryWorkspaceRepository) CreateInvite(invite *WorkspaceInvite) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	invite.CreatedAt = time.Now()
+	inviteCopy := *invite
+	r.invites[invite.Code] = &inviteCopy
+	return nil
+}
+
+// GetInvite retrieves an invite by its code
+func (r *memoryWorkspaceRepository) GetInvite(code string) (*WorkspaceInvite, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	invite, ok := r.invites[code]
+	if !ok {
+		return nil, nil
+	}
+	inviteCopy := *invite
+	return &inviteCopy, nil
+}
@@ -0,0 +1,102 @@
+package nudge
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func newWorkspaceTestService(t *testing.T) (*workspaceService, *common.MockClock) {
+	clock := common.NewMockClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	service := NewWorkspaceService(events.NewMockEventBus(), zaptest.NewLogger(t), NewMemoryWorkspaceRepository(), time.Hour).(*workspaceService)
+	service.clock = clock
+	return service, clock
+}
+
+func TestRole_Allows(t *testing.T) {
+	assert.True(t, RoleOwner.Allows(ActionDelete))
+	assert.True(t, RoleEditor.Allows(ActionComplete))
+	assert.False(t, RoleEditor.Allows(ActionDelete))
+	assert.True(t, RoleViewer.Allows(ActionView))
+	assert.False(t, RoleViewer.Allows(ActionComplete))
+	assert.False(t, Role("admin").IsValid())
+}
+
+func TestWorkspaceService_InviteAndJoin(t *testing.T) {
+	service, clock := newWorkspaceTestService(t)
+	chatID := common.ChatID("chat-1")
+
+	// The first inviter becomes the owner
+	invite, err := service.CreateInvite(chatID, "alice", RoleViewer)
+	require.NoError(t, err)
+	require.NoError(t, service.Authorize(chatID, "alice", ActionManage))
+
+	member, err := service.Join(invite.Code, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, RoleViewer, member.Role)
+
+	// Viewers can neither invite nor complete others' tasks
+	_, err = service.CreateInvite(chatID, "bob", RoleEditor)
+	var permErr PermissionError
+	assert.True(t, errors.As(err, &permErr))
+	assert.Error(t, service.Authorize(chatID, "bob", ActionComplete))
+
+	// Joining with a lower role keeps the existing one
+	member, err = service.Join(invite.Code, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, RoleOwner, member.Role)
+
+	clock.Advance(2 * time.Hour)
+	_, err = service.Join(invite.Code, "carol")
+	assert.ErrorIs(t, err, ErrInviteNotFound)
+}
+
+func TestWorkspaceService_KeepsAnOwner(t *testing.T) {
+	service, _ := newWorkspaceTestService(t)
+	chatID := common.ChatID("chat-1")
+
+	_, err := service.CreateInvite(chatID, "alice", RoleEditor)
+	require.NoError(t, err)
+
+	var ruleErr BusinessRuleError
+	err = service.SetRole(chatID, "alice", "alice", RoleEditor)
+	assert.True(t, errors.As(err, &ruleErr))
+	err = service.RemoveMember(chatID, "alice", "alice")
+	assert.True(t, errors.As(err, &ruleErr))
+
+	require.NoError(t, service.SetRole(chatID, "alice", "bob", RoleOwner))
+	require.NoError(t, service.RemoveMember(chatID, "bob", "alice"))
+
+	members, err := service.ListMembers(chatID)
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	assert.Equal(t, common.UserID("bob"), members[0].UserID)
+}
+
+func TestNudgeService_AuthorizesTaskActionsByRole(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	eventBus := events.NewMockEventBus()
+	workspaces := NewWorkspaceService(eventBus, logger, NewMemoryWorkspaceRepository(), 0)
+	service, err := NewNudgeServiceWithWorkspaces(eventBus, logger, NewMemoryNudgeRepository(logger), nil, workspaces)
+	require.NoError(t, err)
+	nudge := service.(*nudgeService)
+
+	chatID := common.ChatID("chat-1")
+	invite, err := workspaces.CreateInvite(chatID, "alice", RoleEditor)
+	require.NoError(t, err)
+	_, err = workspaces.Join(invite.Code, "bob")
+	require.NoError(t, err)
+
+	task := &Task{ID: common.TaskID(common.NewID()), UserID: "alice", ChatID: chatID}
+	assert.NoError(t, nudge.authorizeTaskAction(task, "alice", "delete"))
+	assert.NoError(t, nudge.authorizeTaskAction(task, "bob", "done"))
+	assert.Error(t, nudge.authorizeTaskAction(task, "bob", "delete"))
+	assert.Error(t, nudge.authorizeTaskAction(task, "mallory", "done"))
+}
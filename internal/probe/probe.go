@@ -0,0 +1,360 @@
+// Package probe runs a synthetic end-to-end check of the task pipeline. Each
+// run sends a message as a throwaway synthetic user and follows it over the
+// event bus through parsing, task creation, a reminder and completion.
+package probe
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/config"
+	"nudgebot-api/internal/events"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// JobName is the name the probe runs under on the job scheduler
+const JobName = "synthetic_probe"
+
+// DefaultSchedule runs the probe every five minutes
+const DefaultSchedule = "*/5 * * * *"
+
+// userPrefix marks synthetic users. The rest of a probe user ID is random, so
+// it is still a valid UUID and every run has its own user.
+const userPrefix = "00000000-0000-4000-8000-"
+
+// Stage is a step of the probed pipeline
+type Stage string
+
+// Probe stages, in the order they run
+const (
+	StageParse    Stage = "parse"
+	StageCreate   Stage = "create"
+	StageRemind   Stage = "remind"
+	StageComplete Stage = "complete"
+	StageCleanup  Stage = "cleanup"
+)
+
+// IsProbeUser reports whether the user ID belongs to a synthetic probe user.
+// Nothing about probe users is delivered to chats.
+func IsProbeUser(userID string) bool {
+	return strings.HasPrefix(userID, userPrefix)
+}
+
+// newProbeUserID returns a fresh synthetic user ID
+func newProbeUserID() string {
+	random := strings.ReplaceAll(uuid.New().String(), "-", "")
+	return userPrefix + random[:12]
+}
+
+// Result describes one probe run
+type Result struct {
+	UserID      string          `json:"user_id"`
+	TaskID      string          `json:"task_id,omitempty"`
+	StartedAt   time.Time       `json:"started_at"`
+	DurationMs  int64           `json:"duration_ms"`
+	Success     bool            `json:"success"`
+	FailedStage Stage           `json:"failed_stage,omitempty"`
+	Error       string          `json:"error,omitempty"`
+	StagesMs    map[Stage]int64 `json:"stages_ms"` // time each completed stage took
+}
+
+// Status summarizes the probe's recent runs
+type Status struct {
+	Healthy             bool       `json:"healthy"`
+	Runs                int64      `json:"runs"`
+	Failures            int64      `json:"failures"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	Alerting            bool       `json:"alerting"`
+	LastSuccess         *time.Time `json:"last_success,omitempty"`
+	LastResult          *Result    `json:"last_result,omitempty"`
+}
+
+// Prober runs the synthetic probe and reports its results
+type Prober interface {
+	// Run performs one probe run; it has the signature of a periodic job
+	Run(ctx context.Context) error
+	Status() Status
+}
+
+// prober implements the Prober interface
+type prober struct {
+	eventBus     events.EventBus
+	logger       *zap.Logger
+	message      string
+	stageTimeout time.Duration
+	alertAfter   int
+	clock        common.Clock
+
+	mu      sync.Mutex
+	current *run
+	status  Status
+}
+
+// run tracks the events of the run in progress. Events of different topics
+// may arrive out of order, so those a stage does not wait for are kept for
+// the later stages.
+type run struct {
+	userID   string
+	received chan interface{}
+	pending  []interface{}
+}
+
+// NewProber creates a prober and subscribes it to the pipeline's events
+func NewProber(eventBus events.EventBus, logger *zap.Logger, cfg config.ProbeConfig) (Prober, error) {
+	p := &prober{
+		eventBus:     eventBus,
+		logger:       logger,
+		message:      cfg.Message,
+		stageTimeout: time.Duration(cfg.StageTimeout) * time.Second,
+		alertAfter:   cfg.AlertAfter,
+		clock:        common.NewRealClock(),
+		status:       Status{Healthy: true},
+	}
+	if p.message == "" {
+		p.message = "Synthetic probe: check the task pipeline"
+	}
+	if p.stageTimeout <= 0 {
+		p.stageTimeout = 30 * time.Second
+	}
+	if p.alertAfter <= 0 {
+		p.alertAfter = 1
+	}
+
+	subscriptions := map[string]interface{}{
+		events.TopicTaskParsed:         func(event events.TaskParsed) { p.deliver(event.UserID, event) },
+		events.TopicTaskCreated:        func(event events.TaskCreated) { p.deliver(event.UserID, event) },
+		events.TopicReminderDue:        func(event events.ReminderDue) { p.deliver(event.UserID, event) },
+		events.TopicTaskActionResponse: func(event events.TaskActionResponse) { p.deliver(event.UserID, event) },
+	}
+	for topic, handler := range subscriptions {
+		if err := eventBus.Subscribe(topic, handler); err != nil {
+			return nil, fmt.Errorf("failed to subscribe to %s: %w", topic, err)
+		}
+	}
+
+	return p, nil
+}
+
+// deliver hands an event to the run in progress if it is about the run's user
+func (p *prober) deliver(userID string, event interface{}) {
+	if !IsProbeUser(userID) {
+		return
+	}
+
+	p.mu.Lock()
+	current := p.current
+	p.mu.Unlock()
+
+	if current == nil || current.userID != userID {
+		return
+	}
+	select {
+	case current.received <- event:
+	default:
+		p.logger.Warn("Dropping probe event, run is not keeping up", zap.String("user_id", userID))
+	}
+}
+
+// Run sends the probe message and waits for each stage in turn. The synthetic
+// task is deleted at the end, also when a later stage fails.
+func (p *prober) Run(ctx context.Context) error {
+	current := &run{userID: newProbeUserID(), received: make(chan interface{}, 16)}
+	p.mu.Lock()
+	p.current = current
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		p.current = nil
+		p.mu.Unlock()
+	}()
+
+	result := &Result{
+		UserID:    current.userID,
+		StartedAt: p.clock.Now(),
+		StagesMs:  make(map[Stage]int64),
+	}
+	err := p.drive(ctx, current, result)
+	if err != nil && result.TaskID != "" && result.FailedStage != StageCleanup {
+		// Best effort, so failed runs do not leave tasks behind
+		p.requestAction(current, result.TaskID, "delete")
+	}
+
+	result.DurationMs = p.clock.Now().Sub(result.StartedAt).Milliseconds()
+	result.Success = err == nil
+	if err != nil {
+		result.Error = err.Error()
+	}
+	p.record(result)
+	return err
+}
+
+// drive runs the stages, recording how long each took and which one failed
+func (p *prober) drive(ctx context.Context, current *run, result *Result) error {
+	stage := func(name Stage, fn func() error) error {
+		started := p.clock.Now()
+		if err := fn(); err != nil {
+			result.FailedStage = name
+			return fmt.Errorf("probe %s stage failed: %w", name, err)
+		}
+		result.StagesMs[name] = p.clock.Now().Sub(started).Milliseconds()
+		return nil
+	}
+
+	steps := []struct {
+		name Stage
+		fn   func() error
+	}{
+		{StageParse, func() error {
+			message := events.MessageReceived{
+				Event:       events.NewEvent(),
+				UserID:      current.userID,
+				ChatID:      current.userID,
+				MessageText: p.message,
+			}
+			if err := p.eventBus.Publish(events.TopicMessageReceived, message); err != nil {
+				return err
+			}
+			_, err := p.await(ctx, current, func(event interface{}) bool {
+				_, ok := event.(events.TaskParsed)
+				return ok
+			})
+			return err
+		}},
+		{StageCreate, func() error {
+			event, err := p.await(ctx, current, func(event interface{}) bool {
+				_, ok := event.(events.TaskCreated)
+				return ok
+			})
+			if err != nil {
+				return err
+			}
+			result.TaskID = event.(events.TaskCreated).TaskID
+			return nil
+		}},
+		{StageRemind, func() error {
+			if err := p.requestAction(current, result.TaskID, "test_reminder"); err != nil {
+				return err
+			}
+			_, err := p.await(ctx, current, func(event interface{}) bool {
+				reminder, ok := event.(events.ReminderDue)
+				return ok && reminder.TaskID == result.TaskID
+			})
+			return err
+		}},
+		{StageComplete, func() error { return p.actionSucceeds(ctx, current, result.TaskID, "done") }},
+		{StageCleanup, func() error { return p.actionSucceeds(ctx, current, result.TaskID, "delete") }},
+	}
+
+	for _, step := range steps {
+		if err := stage(step.name, step.fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// actionSucceeds requests a task action and waits for a successful response
+func (p *prober) actionSucceeds(ctx context.Context, current *run, taskID, action string) error {
+	if err := p.requestAction(current, taskID, action); err != nil {
+		return err
+	}
+	event, err := p.await(ctx, current, func(event interface{}) bool {
+		response, ok := event.(events.TaskActionResponse)
+		return ok && response.TaskID == taskID && response.Action == action
+	})
+	if err != nil {
+		return err
+	}
+	if response := event.(events.TaskActionResponse); !response.Success {
+		return fmt.Errorf("%s was rejected: %s", action, response.Message)
+	}
+	return nil
+}
+
+func (p *prober) requestAction(current *run, taskID, action string) error {
+	return p.eventBus.Publish(events.TopicTaskActionRequested, events.TaskActionRequested{
+		Event:  events.NewEvent(),
+		UserID: current.userID,
+		ChatID: current.userID,
+		TaskID: taskID,
+		Action: action,
+	})
+}
+
+// await returns the first event of the run that matches
+func (p *prober) await(ctx context.Context, current *run, match func(interface{}) bool) (interface{}, error) {
+	for i, event := range current.pending {
+		if match(event) {
+			current.pending = append(current.pending[:i], current.pending[i+1:]...)
+			return event, nil
+		}
+	}
+
+	timeout := time.NewTimer(p.stageTimeout)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case event := <-current.received:
+			if match(event) {
+				return event, nil
+			}
+			current.pending = append(current.pending, event)
+		case <-timeout.C:
+			return nil, fmt.Errorf("no response within %s", p.stageTimeout)
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// record stores a result and alerts once consecutive failures reach the threshold
+func (p *prober) record(result *Result) {
+	p.mu.Lock()
+	wasAlerting := p.status.Alerting
+	p.status.Runs++
+	p.status.LastResult = result
+	if result.Success {
+		finishedAt := result.StartedAt.Add(time.Duration(result.DurationMs) * time.Millisecond)
+		p.status.LastSuccess = &finishedAt
+		p.status.ConsecutiveFailures = 0
+		p.status.Alerting = false
+	} else {
+		p.status.Failures++
+		p.status.ConsecutiveFailures++
+		p.status.Alerting = p.status.ConsecutiveFailures >= p.alertAfter
+	}
+	p.status.Healthy = !p.status.Alerting
+	status := p.status
+	p.mu.Unlock()
+
+	switch {
+	case status.Alerting && !wasAlerting:
+		p.logger.Error("Synthetic probe is failing",
+			zap.Int("consecutive_failures", status.ConsecutiveFailures),
+			zap.String("failed_stage", string(result.FailedStage)),
+			zap.String("error", result.Error))
+	case !result.Success:
+		p.logger.Warn("Synthetic probe run failed",
+			zap.Int("consecutive_failures", status.ConsecutiveFailures),
+			zap.String("failed_stage", string(result.FailedStage)),
+			zap.String("error", result.Error))
+	case wasAlerting:
+		p.logger.Info("Synthetic probe recovered", zap.Int64("duration_ms", result.DurationMs))
+	default:
+		p.logger.Debug("Synthetic probe run succeeded", zap.Int64("duration_ms", result.DurationMs))
+	}
+}
+
+// Status returns a summary of the probe's runs
+func (p *prober) Status() Status {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.status
+}
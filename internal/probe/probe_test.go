@@ -0,0 +1,80 @@
+package probe
+
+import (
+	"context"
+	"testing"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/config"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/nudge"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestProber_RunsThePipeline(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	bus := events.NewMockEventBus()
+	bus.SetSynchronousMode(true)
+
+	repository := nudge.NewMemoryNudgeRepository(logger)
+	_, err := nudge.NewNudgeService(bus, logger, repository)
+	require.NoError(t, err)
+
+	// Stand in for the LLM service
+	require.NoError(t, bus.Subscribe(events.TopicMessageReceived, func(event events.MessageReceived) {
+		bus.Publish(events.TopicTaskParsed, events.TaskParsed{
+			Event:      events.NewEvent(),
+			UserID:     event.UserID,
+			ChatID:     event.ChatID,
+			ParsedTask: events.ParsedTask{Title: event.MessageText, Priority: "medium"},
+		})
+	}))
+
+	prober, err := NewProber(bus, logger, config.ProbeConfig{StageTimeout: 1, AlertAfter: 2})
+	require.NoError(t, err)
+
+	require.NoError(t, prober.Run(context.Background()))
+
+	status := prober.Status()
+	assert.True(t, status.Healthy)
+	assert.Equal(t, int64(1), status.Runs)
+	require.NotNil(t, status.LastResult)
+	assert.True(t, status.LastResult.Success)
+	assert.True(t, IsProbeUser(status.LastResult.UserID))
+	assert.True(t, common.ID(status.LastResult.UserID).IsValid())
+	assert.Len(t, status.LastResult.StagesMs, 5)
+
+	// The synthetic task is cleaned up
+	task, err := repository.GetTaskByID(common.TaskID(status.LastResult.TaskID))
+	require.NoError(t, err)
+	assert.Equal(t, common.TaskStatusDeleted, task.Status)
+}
+
+func TestProber_AlertsAfterConsecutiveFailures(t *testing.T) {
+	bus := events.NewMockEventBus()
+	bus.SetSynchronousMode(true)
+
+	// Nothing parses the message, so every run times out at the first stage
+	prober, err := NewProber(bus, zaptest.NewLogger(t), config.ProbeConfig{StageTimeout: 1, AlertAfter: 2})
+	require.NoError(t, err)
+
+	assert.Error(t, prober.Run(context.Background()))
+	status := prober.Status()
+	assert.True(t, status.Healthy, "a single failure does not alert")
+	assert.Equal(t, StageParse, status.LastResult.FailedStage)
+
+	assert.Error(t, prober.Run(context.Background()))
+	status = prober.Status()
+	assert.False(t, status.Healthy)
+	assert.True(t, status.Alerting)
+	assert.Equal(t, 2, status.ConsecutiveFailures)
+	assert.Equal(t, int64(2), status.Failures)
+}
+
+func TestIsProbeUser(t *testing.T) {
+	assert.True(t, IsProbeUser(newProbeUserID()))
+	assert.False(t, IsProbeUser(string(common.NewID())))
+}
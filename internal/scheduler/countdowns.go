@@ -0,0 +1,132 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/nudge"
+	"nudgebot-api/internal/tenant"
+
+	"go.uber.org/zap"
+)
+
+// CountdownJobName is the name countdown updates run under on the job scheduler
+const CountdownJobName = "countdowns"
+
+// DefaultCountdownSchedule checks for countdowns to update every minute; each
+// countdown sets its own cadence with nudge.CountdownInterval
+const DefaultCountdownSchedule = "* * * * *"
+
+// countdownBatchSize is how many countdowns are updated per query
+const countdownBatchSize = 200
+
+// CountdownUpdater edits countdown messages as their tasks' due times near.
+// A countdown ends once its task is due, completed or deleted.
+type CountdownUpdater struct {
+	countdowns nudge.CountdownRepository
+	tasks      nudge.NudgeRepository
+	eventBus   events.EventBus
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+// NewCountdownUpdater creates a countdown updater
+func NewCountdownUpdater(countdowns nudge.CountdownRepository, tasks nudge.NudgeRepository, eventBus events.EventBus, logger *zap.Logger) *CountdownUpdater {
+	return &CountdownUpdater{
+		countdowns: countdowns,
+		tasks:      tasks,
+		eventBus:   eventBus,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// Run updates every countdown that is due for an update, batch by batch,
+// stopping early when ctx is done
+func (u *CountdownUpdater) Run(ctx context.Context) error {
+	now := u.now()
+
+	var updated, ended int
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		countdowns, err := u.countdowns.GetDueCountdowns(now, countdownBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to get due countdowns: %w", err)
+		}
+
+		for _, countdown := range countdowns {
+			running, err := u.update(countdown, now)
+			if err != nil {
+				return fmt.Errorf("failed to update countdown for task %s: %w", countdown.TaskID, err)
+			}
+			if running {
+				updated++
+			} else {
+				ended++
+			}
+		}
+
+		if len(countdowns) < countdownBatchSize {
+			break
+		}
+	}
+
+	if updated > 0 || ended > 0 {
+		u.logger.Info("Updated countdowns",
+			zap.Int("updated", updated),
+			zap.Int("ended", ended))
+	}
+	return nil
+}
+
+// update refreshes one countdown and reports whether it is still running
+func (u *CountdownUpdater) update(countdown *nudge.Countdown, now time.Time) (bool, error) {
+	ctx := tenant.WithID(tenant.WithUser(context.Background(), countdown.UserID), countdown.TenantID)
+	task, err := u.tasks.WithContext(ctx).GetTaskByID(countdown.TaskID)
+	var notFound common.NotFoundError
+	if err != nil && !errors.As(err, &notFound) {
+		return false, err
+	}
+
+	var update events.CountdownUpdated
+	switch {
+	case task == nil || task.Status == common.TaskStatusCompleted || task.Status == common.TaskStatusDeleted || task.DueDate == nil:
+		title := ""
+		if task != nil {
+			title = task.Title
+		}
+		update = nudge.NewCountdownUpdate(countdown, title, events.CountdownDone, 0)
+	case !task.DueDate.After(now):
+		update = nudge.NewCountdownUpdate(countdown, task.Title, events.CountdownExpired, 0)
+	default:
+		remaining := task.DueDate.Sub(now)
+		countdown.NextUpdateAt = now.Add(nudge.CountdownInterval(remaining))
+		if err := u.countdowns.SaveCountdown(countdown); err != nil {
+			return false, err
+		}
+		u.publish(nudge.NewCountdownUpdate(countdown, task.Title, events.CountdownRunning, remaining))
+		return true, nil
+	}
+
+	if err := u.countdowns.DeleteCountdown(countdown.TaskID); err != nil {
+		return false, err
+	}
+	u.publish(update)
+	return false, nil
+}
+
+// publish publishes a CountdownUpdated event
+func (u *CountdownUpdater) publish(update events.CountdownUpdated) {
+	if err := u.eventBus.Publish(events.TopicCountdownUpdated, update); err != nil {
+		u.logger.Error("Failed to publish CountdownUpdated event",
+			zap.String("task_id", update.TaskID),
+			zap.Error(err))
+	}
+}
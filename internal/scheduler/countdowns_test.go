@@ -0,0 +1,75 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/nudge"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestCountdownUpdater_UpdatesUntilDue(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	tasks := nudge.NewMemoryNudgeRepository(logger)
+	countdowns := nudge.NewMemoryCountdownRepository()
+	eventBus := events.NewMockEventBus()
+	updater := NewCountdownUpdater(countdowns, tasks, eventBus, logger)
+
+	userID := common.UserID(common.NewID())
+	startCountdown := func(title string, due time.Time, status common.TaskStatus, messageID int) *nudge.Task {
+		task := &nudge.Task{
+			ID:       common.TaskID(common.NewID()),
+			UserID:   userID,
+			ChatID:   "12345",
+			Title:    title,
+			DueDate:  &due,
+			Priority: common.PriorityHigh,
+			Status:   status,
+		}
+		require.NoError(t, tasks.CreateTask(task))
+		require.NoError(t, countdowns.SaveCountdown(&nudge.Countdown{
+			TaskID:       task.ID,
+			UserID:       userID,
+			ChatID:       "12345",
+			MessageID:    messageID,
+			NextUpdateAt: time.Now().Add(-time.Second),
+		}))
+		return task
+	}
+	running := startCountdown("Catch train", time.Now().Add(40*time.Minute), common.TaskStatusActive, 1)
+	expired := startCountdown("Submit grant", time.Now().Add(-time.Minute), common.TaskStatusActive, 2)
+	done := startCountdown("Call landlord", time.Now().Add(time.Hour), common.TaskStatusDeleted, 3)
+
+	require.NoError(t, updater.Run(context.Background()))
+
+	states := make(map[int]events.CountdownUpdated)
+	for _, published := range eventBus.GetPublishedEvents(events.TopicCountdownUpdated) {
+		update := published.(events.CountdownUpdated)
+		states[update.MessageID] = update
+	}
+	require.Len(t, states, 3)
+	assert.Equal(t, events.CountdownRunning, states[1].State)
+	assert.InDelta(t, (40 * time.Minute).Seconds(), states[1].Remaining.Seconds(), 5)
+	assert.Equal(t, events.CountdownExpired, states[2].State)
+	assert.Equal(t, events.CountdownDone, states[3].State)
+
+	// Only the running countdown is left, waiting for its next update
+	countdown, err := countdowns.GetCountdown(running.ID)
+	require.NoError(t, err)
+	require.NotNil(t, countdown)
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), countdown.NextUpdateAt, 5*time.Second)
+	for _, ended := range []*nudge.Task{expired, done} {
+		countdown, err := countdowns.GetCountdown(ended.ID)
+		require.NoError(t, err)
+		assert.Nil(t, countdown)
+	}
+
+	require.NoError(t, updater.Run(context.Background()))
+	assert.Len(t, eventBus.GetPublishedEvents(events.TopicCountdownUpdated), 3, "nothing is due again yet")
+}
@@ -0,0 +1,206 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxCronSearch bounds how far ahead Next looks for a matching time, which
+// covers expressions such as "0 0 29 2 *" that only fire in leap years
+const maxCronSearch = 5 * 366 * 24 * time.Hour
+
+// cronDescriptors maps the predefined schedules to their five-field form
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronField describes the allowed range of one cron field
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day_of_month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day_of_week", min: 0, max: 6},
+}
+
+// CronSchedule is a parsed five-field cron expression
+// (minute hour day-of-month month day-of-week)
+type CronSchedule struct {
+	expression string
+	minutes    uint64
+	hours      uint64
+	daysOfMon  uint64
+	months     uint64
+	daysOfWeek uint64
+	// Standard cron semantics: when both day fields are restricted a time
+	// matches if either of them does
+	domRestricted bool
+	dowRestricted bool
+}
+
+// ParseCronExpression parses a standard five-field cron expression. Fields
+// accept "*", single values, ranges ("1-5"), lists ("1,15") and steps ("*/15",
+// "0-30/10"). Day of week runs from 0 (Sunday) to 6, with 7 accepted as Sunday.
+// The descriptors @yearly, @monthly, @weekly, @daily and @hourly are supported.
+func ParseCronExpression(expression string) (*CronSchedule, error) {
+	spec := strings.TrimSpace(expression)
+	if descriptor, ok := cronDescriptors[strings.ToLower(spec)]; ok {
+		spec = descriptor
+	}
+
+	parts := strings.Fields(spec)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("cron expression %q must have %d fields, got %d", expression, len(cronFields), len(parts))
+	}
+
+	bits := make([]uint64, len(cronFields))
+	for i, part := range parts {
+		field := cronFields[i]
+		if field.name == "day_of_week" {
+			// Allow 7 as an alias for Sunday
+			field.max = 7
+		}
+
+		parsed, err := parseCronField(part, field)
+		if err != nil {
+			return nil, fmt.Errorf("cron expression %q: %w", expression, err)
+		}
+		bits[i] = parsed
+	}
+
+	// Fold 7 (Sunday) onto 0
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = bits[4]&^(1<<7) | 1
+	}
+
+	return &CronSchedule{
+		expression:    expression,
+		minutes:       bits[0],
+		hours:         bits[1],
+		daysOfMon:     bits[2],
+		months:        bits[3],
+		daysOfWeek:    bits[4],
+		domRestricted: parts[2] != "*",
+		dowRestricted: parts[4] != "*",
+	}, nil
+}
+
+// parseCronField converts one comma separated field into a bit set
+func parseCronField(part string, field cronField) (uint64, error) {
+	var bits uint64
+
+	for _, item := range strings.Split(part, ",") {
+		rangePart, step := item, 1
+		if slash := strings.Index(item, "/"); slash >= 0 {
+			rangePart = item[:slash]
+			value, err := strconv.Atoi(item[slash+1:])
+			if err != nil || value <= 0 {
+				return 0, fmt.Errorf("invalid step %q in %s field", item[slash+1:], field.name)
+			}
+			step = value
+		}
+
+		start, end := field.min, field.max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if start, err = parseCronValue(bounds[0], field); err != nil {
+				return 0, err
+			}
+			if end, err = parseCronValue(bounds[1], field); err != nil {
+				return 0, err
+			}
+			if start > end {
+				return 0, fmt.Errorf("invalid range %q in %s field", rangePart, field.name)
+			}
+		default:
+			value, err := parseCronValue(rangePart, field)
+			if err != nil {
+				return 0, err
+			}
+			start = value
+			// "5/10" means every 10 starting at 5
+			if step == 1 {
+				end = value
+			}
+		}
+
+		for value := start; value <= end; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+
+	return bits, nil
+}
+
+// parseCronValue parses a single numeric value and checks its range
+func parseCronValue(value string, field cronField) (int, error) {
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q in %s field", value, field.name)
+	}
+	if parsed < field.min || parsed > field.max {
+		return 0, fmt.Errorf("value %d out of range [%d-%d] in %s field", parsed, field.min, field.max, field.name)
+	}
+	return parsed, nil
+}
+
+// String returns the expression the schedule was parsed from
+func (c *CronSchedule) String() string {
+	return c.expression
+}
+
+// Next returns the first matching minute strictly after t, in t's location.
+// It returns the zero time if nothing matches within the search window.
+func (c *CronSchedule) Next(t time.Time) time.Time {
+	next := t.Truncate(time.Minute).Add(time.Minute)
+	limit := next.Add(maxCronSearch)
+
+	for next.Before(limit) {
+		if c.months&(1<<uint(next.Month())) == 0 {
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, next.Location())
+			continue
+		}
+		if !c.matchesDay(next) {
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, next.Location())
+			continue
+		}
+		if c.hours&(1<<uint(next.Hour())) == 0 {
+			next = time.Date(next.Year(), next.Month(), next.Day(), next.Hour()+1, 0, 0, 0, next.Location())
+			continue
+		}
+		if c.minutes&(1<<uint(next.Minute())) == 0 {
+			next = next.Add(time.Minute)
+			continue
+		}
+		return next
+	}
+
+	return time.Time{}
+}
+
+// matchesDay applies the day-of-month / day-of-week rules
+func (c *CronSchedule) matchesDay(t time.Time) bool {
+	domMatch := c.daysOfMon&(1<<uint(t.Day())) != 0
+	dowMatch := c.daysOfWeek&(1<<uint(t.Weekday())) != 0
+
+	if c.domRestricted && c.dowRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
@@ -0,0 +1,54 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCronSchedule_Next(t *testing.T) {
+	// Wednesday
+	from := time.Date(2024, time.January, 10, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		expression string
+		want       time.Time
+	}{
+		{"*/15 * * * *", time.Date(2024, time.January, 10, 10, 15, 0, 0, time.UTC)},
+		{"0 8 * * *", time.Date(2024, time.January, 11, 8, 0, 0, 0, time.UTC)},
+		{"30 9 * * 1-5", time.Date(2024, time.January, 11, 9, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, time.January, 14, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 */3 *", time.Date(2024, time.April, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, time.January, 10, 11, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2024, time.January, 14, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: the 20th or any Monday, whichever comes first
+		{"0 12 20 * 1", time.Date(2024, time.January, 15, 12, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			schedule, err := ParseCronExpression(tt.expression)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, schedule.Next(from))
+		})
+	}
+}
+
+func TestParseCronExpression_Invalid(t *testing.T) {
+	for _, expression := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+	} {
+		_, err := ParseCronExpression(expression)
+		assert.Error(t, err, expression)
+	}
+}
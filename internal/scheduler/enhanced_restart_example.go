@@ -3,8 +3,9 @@ package scheduler
 // This file contains an example of how to implement proper worker restart
 // mechanism if automatic restart is desired in the future.
 //
-// The current implementation (in scheduler.go) removes automatic restart
-// to prevent WaitGroup corruption and maintain clean shutdown semantics.
+// The current implementation (in scheduler.go and pool.go) does not restart a
+// panicked worker in place; the dispatcher's autoscaler tops the pool back up
+// to min_workers on its next cycle instead.
 //
 // If you want to add automatic restart capability, consider these approaches:
 
@@ -0,0 +1,427 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/config"
+
+	"go.uber.org/zap"
+)
+
+// JobFunc is the body of a periodic job. The context is cancelled when the
+// job scheduler stops.
+type JobFunc func(ctx context.Context) error
+
+// JobScheduler runs named periodic jobs on cron expressions
+type JobScheduler interface {
+	Register(name, schedule string, fn JobFunc) error
+	RunNow(name string) error
+	common.Service
+	IsRunning() bool
+	GetJobStatuses() []JobStatus
+}
+
+// LoadGate reports whether the service is shedding load. Periodic jobs are
+// not interactive, so their runs are deferred while it is degraded.
+type LoadGate interface {
+	Degraded() bool
+}
+
+// JobStatus reports the configuration and run history of a job
+type JobStatus struct {
+	Name         string     `json:"name"`
+	Schedule     string     `json:"schedule"`
+	Enabled      bool       `json:"enabled"`
+	Running      bool       `json:"running"`
+	Runs         int64      `json:"runs"`
+	Failures     int64      `json:"failures"`
+	Skipped      int64      `json:"skipped_overlaps"`
+	Deferred     int64      `json:"deferred"`
+	LastRun      *time.Time `json:"last_run,omitempty"`
+	LastDuration string     `json:"last_duration,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+	NextRun      *time.Time `json:"next_run,omitempty"`
+}
+
+// scheduledJob is a registered job and its run state
+type scheduledJob struct {
+	name     string
+	schedule *CronSchedule
+	enabled  bool
+	fn       JobFunc
+	running  atomic.Bool
+
+	mu           sync.Mutex
+	runs         int64
+	failures     int64
+	skipped      int64
+	deferred     int64
+	lastRun      time.Time
+	lastDuration time.Duration
+	lastError    string
+	nextRun      time.Time
+}
+
+// jobScheduler implements the JobScheduler interface
+type jobScheduler struct {
+	jobsConfig      map[string]config.JobConfig
+	shutdownTimeout time.Duration
+	logger          *zap.Logger
+	loadGate        LoadGate
+	maintenance     MaintenanceGate
+
+	mu   sync.RWMutex
+	jobs map[string]*scheduledJob
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	running atomic.Bool
+}
+
+// NewJobScheduler creates a job scheduler. Per-job schedule overrides and
+// enable flags are read from cfg.Jobs, keyed by job name.
+func NewJobScheduler(cfg config.SchedulerConfig, logger *zap.Logger) JobScheduler {
+	return NewJobSchedulerWithLoadGate(cfg, logger, nil)
+}
+
+// NewJobSchedulerWithLoadGate creates a job scheduler that skips scheduled runs
+// while the load gate reports the service as degraded. RunNow is not gated.
+func NewJobSchedulerWithLoadGate(cfg config.SchedulerConfig, logger *zap.Logger, loadGate LoadGate) JobScheduler {
+	return NewJobSchedulerWithMaintenance(cfg, logger, loadGate, nil)
+}
+
+// NewJobSchedulerWithMaintenance creates a job scheduler that also skips
+// scheduled runs while the service is down for maintenance. RunNow is not
+// gated, so admins can still run a job by hand.
+func NewJobSchedulerWithMaintenance(cfg config.SchedulerConfig, logger *zap.Logger, loadGate LoadGate, maintenance MaintenanceGate) JobScheduler {
+	jobsConfig := make(map[string]config.JobConfig, len(cfg.Jobs))
+	for name, jobConfig := range cfg.Jobs {
+		// Viper lower-cases map keys, so match names case-insensitively
+		jobsConfig[strings.ToLower(name)] = jobConfig
+	}
+
+	return &jobScheduler{
+		jobsConfig:      jobsConfig,
+		shutdownTimeout: time.Duration(cfg.ShutdownTimeout) * time.Second,
+		logger:          logger,
+		loadGate:        loadGate,
+		maintenance:     maintenance,
+		jobs:            make(map[string]*scheduledJob),
+	}
+}
+
+// Register adds a job with its default cron schedule. Configuration may
+// override the schedule or disable the job. Jobs must be registered before Start.
+func (s *jobScheduler) Register(name, schedule string, fn JobFunc) error {
+	if name == "" {
+		return NewConfigurationError("jobs.name", name, "must not be empty")
+	}
+	if fn == nil {
+		return NewConfigurationError(fmt.Sprintf("jobs.%s", name), nil, "job function must not be nil")
+	}
+	if s.running.Load() {
+		return NewSchedulerError("job_scheduler_running", "jobs must be registered before the job scheduler starts")
+	}
+
+	enabled := true
+	if jobConfig, exists := s.jobsConfig[strings.ToLower(name)]; exists {
+		if jobConfig.Schedule != "" {
+			schedule = jobConfig.Schedule
+		}
+		if jobConfig.Enabled != nil {
+			enabled = *jobConfig.Enabled
+		}
+	}
+
+	cronSchedule, err := ParseCronExpression(schedule)
+	if err != nil {
+		return NewConfigurationError(fmt.Sprintf("jobs.%s.schedule", name), schedule, err.Error())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.jobs[name]; exists {
+		return NewSchedulerError("job_already_registered", fmt.Sprintf("job %q is already registered", name))
+	}
+
+	s.jobs[name] = &scheduledJob{
+		name:     name,
+		schedule: cronSchedule,
+		enabled:  enabled,
+		fn:       fn,
+	}
+
+	s.logger.Info("Registered scheduled job",
+		zap.String("job", name),
+		zap.String("schedule", cronSchedule.String()),
+		zap.Bool("enabled", enabled))
+
+	return nil
+}
+
+// RunNow triggers a job immediately, outside its schedule. It fails when the
+// scheduler is not running or the previous run has not finished.
+func (s *jobScheduler) RunNow(name string) error {
+	if !s.running.Load() {
+		return NewSchedulerError("job_scheduler_not_running", "job scheduler is not running")
+	}
+
+	s.mu.RLock()
+	job, exists := s.jobs[name]
+	s.mu.RUnlock()
+	if !exists {
+		return NewSchedulerError("job_not_found", fmt.Sprintf("job %q is not registered", name))
+	}
+
+	if !s.trigger(job) {
+		return NewTemporarySchedulerError("job_already_running", fmt.Sprintf("job %q is already running", name))
+	}
+	return nil
+}
+
+// Start launches a timer loop for every enabled job
+func (s *jobScheduler) Start(ctx context.Context) error {
+	if s.running.Load() {
+		return NewSchedulerError("job_scheduler_already_running", "job scheduler is already running")
+	}
+
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	s.running.Store(true)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	enabledCount := 0
+	for _, job := range s.jobs {
+		if !job.enabled {
+			continue
+		}
+		enabledCount++
+		s.wg.Add(1)
+		go s.runLoop(job)
+	}
+
+	s.logger.Info("Job scheduler started",
+		zap.Int("registered_jobs", len(s.jobs)),
+		zap.Int("enabled_jobs", enabledCount))
+
+	return nil
+}
+
+// Stop cancels pending runs and waits for in-flight jobs to finish. The
+// scheduler counts as stopped even when waiting times out or is cancelled.
+func (s *jobScheduler) Stop(ctx context.Context) error {
+	// Marking it stopped first also keeps RunNow from starting runs meanwhile
+	if !s.running.CompareAndSwap(true, false) {
+		return NewSchedulerError("job_scheduler_not_running", "job scheduler is not running")
+	}
+
+	s.logger.Info("Stopping job scheduler...")
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(s.shutdownTimeout):
+		s.logger.Warn("Job scheduler shutdown timed out, some jobs may still be running")
+		return NewShutdownError("job shutdown timeout exceeded", int(s.shutdownTimeout.Seconds()))
+	case <-ctx.Done():
+		s.logger.Warn("Job scheduler shutdown cancelled, some jobs may still be running")
+		return ctx.Err()
+	}
+
+	s.logger.Info("Job scheduler stopped successfully")
+	return nil
+}
+
+// IsRunning returns true if the job scheduler is currently running
+func (s *jobScheduler) IsRunning() bool {
+	return s.running.Load()
+}
+
+// Health reports an error when the job scheduler is not running
+func (s *jobScheduler) Health() error {
+	if !s.running.Load() {
+		return NewSchedulerError("job_scheduler_not_running", "job scheduler is not running")
+	}
+	return nil
+}
+
+// GetJobStatuses returns the status of every registered job sorted by name
+func (s *jobScheduler) GetJobStatuses() []JobStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		statuses = append(statuses, job.status())
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	return statuses
+}
+
+// runLoop sleeps until each scheduled time of a job and triggers it
+func (s *jobScheduler) runLoop(job *scheduledJob) {
+	defer s.wg.Done()
+
+	for {
+		next := job.schedule.Next(time.Now())
+		if next.IsZero() {
+			s.logger.Warn("Job schedule never fires, stopping its loop",
+				zap.String("job", job.name),
+				zap.String("schedule", job.schedule.String()))
+			return
+		}
+		job.setNextRun(next)
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			if s.loadGate != nil && s.loadGate.Degraded() {
+				job.recordDeferral()
+				s.logger.Info("Deferring job run, service is shedding load",
+					zap.String("job", job.name),
+					zap.Time("scheduled_at", next))
+				continue
+			}
+			if s.maintenance != nil && s.maintenance.Enabled() {
+				job.recordDeferral()
+				s.logger.Info("Deferring job run, down for maintenance",
+					zap.String("job", job.name),
+					zap.Time("scheduled_at", next))
+				continue
+			}
+			if !s.trigger(job) {
+				job.recordSkip()
+				s.logger.Warn("Skipping job run, previous run still in progress",
+					zap.String("job", job.name),
+					zap.Time("scheduled_at", next))
+			}
+		}
+	}
+}
+
+// trigger starts a run unless the previous run of the job is still going
+func (s *jobScheduler) trigger(job *scheduledJob) bool {
+	if !job.running.CompareAndSwap(false, true) {
+		return false
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer job.running.Store(false)
+		s.execute(job)
+	}()
+
+	return true
+}
+
+// execute runs the job body and records the outcome
+func (s *jobScheduler) execute(job *scheduledJob) {
+	startTime := time.Now()
+	jobLogger := s.logger.With(zap.String("job", job.name))
+	jobLogger.Debug("Running scheduled job")
+
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("job panic: %v", r)
+			}
+		}()
+		return job.fn(s.ctx)
+	}()
+
+	duration := time.Since(startTime)
+	job.recordRun(startTime, duration, err)
+
+	if err != nil {
+		jobLogger.Error("Scheduled job failed", zap.Duration("duration", duration), zap.Error(err))
+		return
+	}
+	jobLogger.Info("Scheduled job completed", zap.Duration("duration", duration))
+}
+
+func (j *scheduledJob) setNextRun(next time.Time) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.nextRun = next
+}
+
+func (j *scheduledJob) recordSkip() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.skipped++
+}
+
+func (j *scheduledJob) recordDeferral() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.deferred++
+}
+
+func (j *scheduledJob) recordRun(start time.Time, duration time.Duration, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.runs++
+	j.lastRun = start
+	j.lastDuration = duration
+	j.lastError = ""
+	if err != nil {
+		j.failures++
+		j.lastError = err.Error()
+	}
+}
+
+func (j *scheduledJob) status() JobStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	status := JobStatus{
+		Name:      j.name,
+		Schedule:  j.schedule.String(),
+		Enabled:   j.enabled,
+		Running:   j.running.Load(),
+		Runs:      j.runs,
+		Failures:  j.failures,
+		Skipped:   j.skipped,
+		Deferred:  j.deferred,
+		LastError: j.lastError,
+	}
+	if !j.lastRun.IsZero() {
+		lastRun := j.lastRun
+		status.LastRun = &lastRun
+		status.LastDuration = j.lastDuration.String()
+	}
+	if j.enabled && !j.nextRun.IsZero() {
+		nextRun := j.nextRun
+		status.NextRun = &nextRun
+	}
+
+	return status
+}
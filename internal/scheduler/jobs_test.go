@@ -0,0 +1,97 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestJobScheduler_ConfigOverrides(t *testing.T) {
+	disabled := false
+	jobs := NewJobScheduler(config.SchedulerConfig{
+		ShutdownTimeout: 5,
+		Jobs: map[string]config.JobConfig{
+			"daily_digest": {Schedule: "0 7 * * *"},
+			"purge":        {Enabled: &disabled},
+		},
+	}, zaptest.NewLogger(t))
+
+	noop := func(ctx context.Context) error { return nil }
+	require.NoError(t, jobs.Register("daily_digest", "0 8 * * *", noop))
+	require.NoError(t, jobs.Register("purge", "@daily", noop))
+	require.NoError(t, jobs.Register("rollup", "@hourly", noop))
+
+	err := jobs.Register("rollup", "@hourly", noop)
+	require.Error(t, err)
+
+	err = jobs.Register("broken", "not a cron", noop)
+	require.Error(t, err)
+	assert.True(t, IsConfigurationError(err))
+
+	statuses := jobs.GetJobStatuses()
+	require.Len(t, statuses, 3)
+	assert.Equal(t, "daily_digest", statuses[0].Name)
+	assert.Equal(t, "0 7 * * *", statuses[0].Schedule)
+	assert.Equal(t, "purge", statuses[1].Name)
+	assert.False(t, statuses[1].Enabled)
+	assert.True(t, statuses[2].Enabled)
+}
+
+func TestJobScheduler_OverlapProtection(t *testing.T) {
+	jobs := NewJobScheduler(config.SchedulerConfig{ShutdownTimeout: 5}, zaptest.NewLogger(t))
+
+	release := make(chan struct{})
+	require.NoError(t, jobs.Register("slow", "@yearly", func(ctx context.Context) error {
+		<-release
+		return errors.New("boom")
+	}))
+
+	require.NoError(t, jobs.Start(context.Background()))
+
+	require.NoError(t, jobs.RunNow("slow"))
+	err := jobs.RunNow("slow")
+	require.Error(t, err, "a second run must not start while the first is in progress")
+
+	close(release)
+	require.Eventually(t, func() bool {
+		return !jobs.GetJobStatuses()[0].Running
+	}, time.Second, 10*time.Millisecond)
+
+	status := jobs.GetJobStatuses()[0]
+	assert.Equal(t, int64(1), status.Runs)
+	assert.Equal(t, int64(1), status.Failures)
+	assert.Equal(t, "boom", status.LastError)
+	assert.NotNil(t, status.NextRun)
+
+	require.NoError(t, jobs.Stop(context.Background()))
+	assert.Error(t, jobs.RunNow("slow"))
+}
+
+func TestJobScheduler_StopTimeoutStillStops(t *testing.T) {
+	// The stuck job outlives the test, so it must not log through t
+	jobs := NewJobScheduler(config.SchedulerConfig{}, zap.NewNop())
+
+	release := make(chan struct{})
+	defer close(release)
+	require.NoError(t, jobs.Register("stuck", "@yearly", func(ctx context.Context) error {
+		<-release
+		return nil
+	}))
+
+	require.NoError(t, jobs.Start(context.Background()))
+	require.NoError(t, jobs.RunNow("stuck"))
+
+	err := jobs.Stop(context.Background())
+	require.Error(t, err, "the stuck job outlives the shutdown timeout")
+	assert.False(t, jobs.IsRunning())
+	assert.Error(t, jobs.Health())
+	assert.Error(t, jobs.RunNow("stuck"))
+}
@@ -11,9 +11,16 @@ type SchedulerMetrics struct {
 	RemindersProcessed    int64
 	NudgesCreated         int64
 	ProcessingErrors      int64
+	RemindersHeld         int64
+	RemindersDeferred     int64
+	RemindersGrouped      int64
+	RemindersMuted        int64
+	RemindersPaused       int64
 	AverageProcessingTime time.Duration
 	LastProcessingTime    time.Time
 	WorkerUtilization     map[int]float64
+	CurrentWorkers        int
+	BacklogDepth          int
 	totalProcessingTime   time.Duration
 	processingCycles      int64
 }
@@ -32,9 +39,16 @@ type MetricsSummary struct {
 	RemindersProcessed    int64           `json:"reminders_processed"`
 	NudgesCreated         int64           `json:"nudges_created"`
 	ProcessingErrors      int64           `json:"processing_errors"`
+	RemindersHeld         int64           `json:"reminders_held"`
+	RemindersDeferred     int64           `json:"reminders_deferred"`
+	RemindersGrouped      int64           `json:"reminders_grouped"`
+	RemindersMuted        int64           `json:"reminders_muted"`
+	RemindersPaused       int64           `json:"reminders_paused"`
 	AverageProcessingTime string          `json:"average_processing_time"`
 	LastProcessingTime    time.Time       `json:"last_processing_time"`
 	WorkerUtilization     map[int]float64 `json:"worker_utilization"`
+	CurrentWorkers        int             `json:"current_workers"`
+	BacklogDepth          int             `json:"backlog_depth"`
 	ProcessingRate        float64         `json:"processing_rate_per_minute"`
 	ErrorRate             float64         `json:"error_rate_percentage"`
 }
@@ -69,6 +83,46 @@ func (m *SchedulerMetrics) RecordNudgeCreated() {
 	m.NudgesCreated++
 }
 
+// RecordReminderHeld counts a due reminder held back by quiet hours
+func (m *SchedulerMetrics) RecordReminderHeld() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.RemindersHeld++
+}
+
+// RecordReminderDeferred counts a due reminder held back after recent user activity
+func (m *SchedulerMetrics) RecordReminderDeferred() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.RemindersDeferred++
+}
+
+// RecordRemindersGrouped counts reminders sent together in one grouped message
+func (m *SchedulerMetrics) RecordRemindersGrouped(count int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.RemindersGrouped += int64(count)
+}
+
+// RecordReminderMuted counts a due reminder dropped because its task is muted
+func (m *SchedulerMetrics) RecordReminderMuted() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.RemindersMuted++
+}
+
+// RecordReminderPaused counts a due reminder dropped because its user blocked the bot
+func (m *SchedulerMetrics) RecordReminderPaused() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.RemindersPaused++
+}
+
 // RecordProcessingError increments the error counter
 func (m *SchedulerMetrics) RecordProcessingError(err error) {
 	m.mu.Lock()
@@ -89,6 +143,30 @@ func (m *SchedulerMetrics) RecordWorkerActivity(workerID int, active bool) {
 	}
 }
 
+// RecordWorkerRetired drops a stopped worker from the utilization metrics
+func (m *SchedulerMetrics) RecordWorkerRetired(workerID int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.WorkerUtilization, workerID)
+}
+
+// RecordWorkerCount records the current size of the worker pool
+func (m *SchedulerMetrics) RecordWorkerCount(count int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.CurrentWorkers = count
+}
+
+// RecordBacklogDepth records the number of due reminders seen by the last poll
+func (m *SchedulerMetrics) RecordBacklogDepth(depth int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.BacklogDepth = depth
+}
+
 // IsHealthy determines if the scheduler is healthy based on metrics
 func (m *SchedulerMetrics) IsHealthy() bool {
 	m.mu.RLock()
@@ -132,9 +210,16 @@ func (m *SchedulerMetrics) GetMetricsSummary() MetricsSummary {
 		RemindersProcessed:    m.RemindersProcessed,
 		NudgesCreated:         m.NudgesCreated,
 		ProcessingErrors:      m.ProcessingErrors,
+		RemindersHeld:         m.RemindersHeld,
+		RemindersDeferred:     m.RemindersDeferred,
+		RemindersGrouped:      m.RemindersGrouped,
+		RemindersMuted:        m.RemindersMuted,
+		RemindersPaused:       m.RemindersPaused,
 		AverageProcessingTime: m.AverageProcessingTime.String(),
 		LastProcessingTime:    m.LastProcessingTime,
 		WorkerUtilization:     m.copyWorkerUtilization(),
+		CurrentWorkers:        m.CurrentWorkers,
+		BacklogDepth:          m.BacklogDepth,
 		ProcessingRate:        processingRate,
 		ErrorRate:             errorRate * 100, // Convert to percentage
 	}
@@ -186,9 +271,15 @@ func (m *SchedulerMetrics) Reset() {
 	m.RemindersProcessed = 0
 	m.NudgesCreated = 0
 	m.ProcessingErrors = 0
+	m.RemindersHeld = 0
+	m.RemindersDeferred = 0
+	m.RemindersGrouped = 0
+	m.RemindersMuted = 0
+	m.RemindersPaused = 0
 	m.AverageProcessingTime = 0
 	m.LastProcessingTime = time.Time{}
 	m.totalProcessingTime = 0
 	m.processingCycles = 0
+	m.BacklogDepth = 0
 	m.WorkerUtilization = make(map[int]float64)
 }
@@ -0,0 +1,205 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/nudge"
+	"nudgebot-api/internal/tenant"
+
+	"go.uber.org/zap"
+)
+
+// OverdueJobName is the name the overdue detector runs under on the job scheduler
+const OverdueJobName = "overdue_detection"
+
+// DefaultOverdueSchedule looks for tasks that became overdue every minute
+const DefaultOverdueSchedule = "* * * * *"
+
+// overdueBatchSize is how many overdue tasks are handled per query
+const overdueBatchSize = 200
+
+// OverdueDetector finds open tasks that passed their due date, announces them
+// with a TaskOverdue event and makes sure each gets nudged, even when no
+// reminder was ever scheduled for it. Habits are never overdue: they roll over
+// to their next period, and a period that ended undone is announced as missed.
+type OverdueDetector struct {
+	repository nudge.NudgeRepository
+	bots       BotLookup
+	eventBus   events.EventBus
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+// NewOverdueDetector creates an overdue detector
+func NewOverdueDetector(repository nudge.NudgeRepository, eventBus events.EventBus, logger *zap.Logger) *OverdueDetector {
+	return NewOverdueDetectorWithBots(repository, nil, eventBus, logger)
+}
+
+// NewOverdueDetectorWithBots creates an overdue detector that holds back
+// nudges only for users who blocked the bot bots says they talk to. A nil
+// lookup checks the default bot.
+func NewOverdueDetectorWithBots(repository nudge.NudgeRepository, bots BotLookup, eventBus events.EventBus, logger *zap.Logger) *OverdueDetector {
+	return &OverdueDetector{
+		repository: repository,
+		bots:       bots,
+		eventBus:   eventBus,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// Run handles every task that became overdue since the last run, batch by
+// batch, stopping early when ctx is done
+func (d *OverdueDetector) Run(ctx context.Context) error {
+	now := d.now()
+
+	var detected, nudged, habits int
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		tasks, err := d.repository.GetNewlyOverdueTasks(now, overdueBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to get newly overdue tasks: %w", err)
+		}
+
+		for _, task := range tasks {
+			if task.IsHabit() {
+				if err := d.rollOverHabit(task, now); err != nil {
+					return fmt.Errorf("failed to roll over habit %s: %w", task.ID, err)
+				}
+				habits++
+				continue
+			}
+			if d.handleOverdueTask(task, now) {
+				nudged++
+			}
+			// Marking comes last, so a task is announced again rather than
+			// missed when the run fails half way
+			if err := d.repositoryFor(task).MarkTaskOverdue(task.ID, *task.DueDate); err != nil {
+				return fmt.Errorf("failed to mark task %s overdue: %w", task.ID, err)
+			}
+			detected++
+		}
+
+		if len(tasks) < overdueBatchSize {
+			break
+		}
+	}
+
+	if detected > 0 || habits > 0 {
+		d.logger.Info("Detected overdue tasks",
+			zap.Int("tasks", detected),
+			zap.Int("nudges_created", nudged),
+			zap.Int("habits_rolled_over", habits))
+	}
+	return nil
+}
+
+// repositoryFor returns the repository scoped to the tenant of a task
+func (d *OverdueDetector) repositoryFor(task *nudge.Task) nudge.NudgeRepository {
+	ctx := tenant.WithUser(context.Background(), task.UserID)
+	return d.repository.WithContext(tenant.WithID(ctx, task.TenantID))
+}
+
+// handleOverdueTask publishes the TaskOverdue event for a task and reports
+// whether a first nudge was created for it
+func (d *OverdueDetector) handleOverdueTask(task *nudge.Task, now time.Time) bool {
+	overdueEvent := events.TaskOverdue{
+		Event:    events.NewEvent(),
+		TaskID:   string(task.ID),
+		UserID:   string(task.UserID),
+		ChatID:   string(task.ChatID),
+		Title:    task.Title,
+		Priority: string(task.Priority),
+		DueDate:  *task.DueDate,
+	}
+	if err := d.eventBus.Publish(events.TopicTaskOverdue, overdueEvent); err != nil {
+		d.logger.Error("Failed to publish TaskOverdue event",
+			zap.String("task_id", string(task.ID)),
+			zap.Error(err))
+	}
+
+	created, err := d.ensureFirstNudge(task, now)
+	if err != nil {
+		d.logger.Error("Failed to create first nudge for overdue task",
+			zap.String("task_id", string(task.ID)),
+			zap.Error(err))
+	}
+	return created
+}
+
+// ensureFirstNudge schedules a nudge right away for an overdue task that has
+// neither been nudged nor has a reminder still to come
+func (d *OverdueDetector) ensureFirstNudge(task *nudge.Task, now time.Time) (bool, error) {
+	if task.Muted || !task.CanBeNudged() || task.ChatID == "" {
+		return false, nil
+	}
+	if settings, err := d.repository.GetNudgeSettingsByUserID(task.UserID); err == nil && (!settings.Enabled || settings.BlockedOn(userBot(d.bots, task.UserID, d.logger))) {
+		return false, nil
+	}
+
+	reminders, err := d.repositoryFor(task).GetRemindersByTaskID(task.ID)
+	if err != nil {
+		return false, err
+	}
+	for _, reminder := range reminders {
+		if reminder.ReminderType == nudge.ReminderTypeNudge || reminder.SentAt == nil {
+			return false, nil
+		}
+	}
+
+	firstNudge := &nudge.Reminder{
+		ID:           common.NewID(),
+		TenantID:     task.TenantID,
+		TaskID:       task.ID,
+		UserID:       task.UserID,
+		ChatID:       task.ChatID,
+		ScheduledAt:  now,
+		ReminderType: nudge.ReminderTypeNudge,
+	}
+	if err := d.repository.CreateReminder(firstNudge); err != nil {
+		return false, NewNudgeCreationError(string(task.ID), "create_reminder", err)
+	}
+	return true, nil
+}
+
+// rollOverHabit moves a habit whose due date passed on to its current period,
+// announcing a missed period with a HabitMissed event
+func (d *OverdueDetector) rollOverHabit(task *nudge.Task, now time.Time) error {
+	settings, err := d.repository.GetNudgeSettingsByUserID(task.UserID)
+	if err != nil {
+		settings = nil
+	}
+
+	missed, lost := task.RollOverHabit(now.In(settings.Location()))
+	if err := d.repository.UpdateTask(task); err != nil {
+		return err
+	}
+	if !missed {
+		return nil
+	}
+
+	missedEvent := events.HabitMissed{
+		Event:      events.NewEvent(),
+		TaskID:     string(task.ID),
+		UserID:     string(task.UserID),
+		ChatID:     string(task.ChatID),
+		Title:      task.Title,
+		Habit:      string(task.Habit),
+		LostStreak: lost,
+		NextDue:    *task.DueDate,
+		Muted:      task.Muted || (settings != nil && (!settings.Enabled || settings.BlockedOn(userBot(d.bots, task.UserID, d.logger)))),
+	}
+	if err := d.eventBus.Publish(events.TopicHabitMissed, missedEvent); err != nil {
+		d.logger.Error("Failed to publish HabitMissed event",
+			zap.String("task_id", string(task.ID)),
+			zap.Error(err))
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/nudge"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestOverdueDetector_AnnouncesAndNudgesOnce(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	repo := nudge.NewMemoryNudgeRepository(logger)
+	eventBus := events.NewMockEventBus()
+	detector := NewOverdueDetector(repo, eventBus, logger)
+
+	userID := common.UserID(common.NewID())
+	newTask := func(title string, due time.Time, muted bool) *nudge.Task {
+		task := &nudge.Task{
+			ID:       common.TaskID(common.NewID()),
+			UserID:   userID,
+			ChatID:   "12345",
+			Title:    title,
+			DueDate:  &due,
+			Priority: common.PriorityMedium,
+			Status:   common.TaskStatusActive,
+			Muted:    muted,
+		}
+		require.NoError(t, repo.CreateTask(task))
+		return task
+	}
+
+	overdue := newTask("File taxes", time.Now().Add(-time.Hour), false)
+	muted := newTask("Water plants", time.Now().Add(-2*time.Hour), true)
+	newTask("Call mom", time.Now().Add(time.Hour), false)
+
+	require.NoError(t, detector.Run(context.Background()))
+
+	published := eventBus.GetPublishedEvents(events.TopicTaskOverdue)
+	require.Len(t, published, 2)
+	assert.Equal(t, "Water plants", published[0].(events.TaskOverdue).Title, "earliest due date first")
+	assert.Equal(t, string(overdue.ID), published[1].(events.TaskOverdue).TaskID)
+
+	reminders, err := repo.GetRemindersByTaskID(overdue.ID)
+	require.NoError(t, err)
+	require.Len(t, reminders, 1)
+	assert.Equal(t, nudge.ReminderTypeNudge, reminders[0].ReminderType)
+	reminders, err = repo.GetRemindersByTaskID(muted.ID)
+	require.NoError(t, err)
+	assert.Empty(t, reminders, "muted tasks are announced but not nudged")
+
+	stored, err := repo.GetTaskByID(overdue.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.OverdueSince)
+	assert.True(t, stored.OverdueSince.Equal(*overdue.DueDate))
+
+	// Nothing new on the next run
+	require.NoError(t, detector.Run(context.Background()))
+	assert.Len(t, eventBus.GetPublishedEvents(events.TopicTaskOverdue), 2)
+
+	// Missing a new due date is announced again, but the nudge already exists
+	newDue := time.Now().Add(-time.Minute)
+	stored.DueDate = &newDue
+	require.NoError(t, repo.UpdateTask(stored))
+	require.NoError(t, detector.Run(context.Background()))
+	assert.Len(t, eventBus.GetPublishedEvents(events.TopicTaskOverdue), 3)
+	reminders, err = repo.GetRemindersByTaskID(overdue.ID)
+	require.NoError(t, err)
+	assert.Len(t, reminders, 1)
+}
+
+func TestOverdueDetector_KeepsPendingReminders(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	repo := nudge.NewMemoryNudgeRepository(logger)
+	detector := NewOverdueDetector(repo, events.NewMockEventBus(), logger)
+
+	due := time.Now().Add(-time.Hour)
+	task := &nudge.Task{
+		ID:       common.TaskID(common.NewID()),
+		UserID:   common.UserID(common.NewID()),
+		ChatID:   "12345",
+		Title:    "Renew passport",
+		DueDate:  &due,
+		Priority: common.PriorityHigh,
+		Status:   common.TaskStatusActive,
+	}
+	require.NoError(t, repo.CreateTask(task))
+	require.NoError(t, repo.CreateReminder(&nudge.Reminder{
+		ID:           common.NewID(),
+		TaskID:       task.ID,
+		UserID:       task.UserID,
+		ChatID:       task.ChatID,
+		ScheduledAt:  time.Now().Add(time.Minute),
+		ReminderType: nudge.ReminderTypeInitial,
+	}))
+
+	require.NoError(t, detector.Run(context.Background()))
+
+	reminders, err := repo.GetRemindersByTaskID(task.ID)
+	require.NoError(t, err)
+	assert.Len(t, reminders, 1, "the pending reminder will nudge the user")
+}
+
+func TestOverdueDetector_RollsOverHabits(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	repo := nudge.NewMemoryNudgeRepository(logger)
+	eventBus := events.NewMockEventBus()
+	detector := NewOverdueDetector(repo, eventBus, logger)
+
+	now := time.Now()
+	due := nudge.HabitDaily.Start(now)
+	habit := &nudge.Task{
+		ID:       common.TaskID(common.NewID()),
+		UserID:   common.UserID(common.NewID()),
+		ChatID:   "12345",
+		Title:    "Exercise",
+		DueDate:  &due,
+		Priority: common.PriorityMedium,
+		Status:   common.TaskStatusActive,
+		Habit:    nudge.HabitDaily,
+		Streak:   5,
+	}
+	require.NoError(t, repo.CreateTask(habit))
+
+	require.NoError(t, detector.Run(context.Background()))
+
+	assert.Empty(t, eventBus.GetPublishedEvents(events.TopicTaskOverdue), "habits are never overdue")
+	missed := eventBus.GetPublishedEvents(events.TopicHabitMissed)
+	require.Len(t, missed, 1)
+	assert.Equal(t, 5, missed[0].(events.HabitMissed).LostStreak)
+
+	stored, err := repo.GetTaskByID(habit.ID)
+	require.NoError(t, err)
+	assert.Zero(t, stored.Streak)
+	assert.True(t, stored.DueDate.After(now), "due again at the end of the current day")
+
+	require.NoError(t, detector.Run(context.Background()))
+	assert.Len(t, eventBus.GetPublishedEvents(events.TopicHabitMissed), 1, "a missed day is announced once")
+}
@@ -0,0 +1,226 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/nudge"
+
+	"go.uber.org/zap"
+)
+
+// MaxReminderGroupSize caps how many reminders one grouped message lists;
+// larger groups are split across messages
+const MaxReminderGroupSize = 10
+
+// reminderJob is a due reminder, or a group of due reminders for one chat,
+// handed from the dispatcher to a worker
+type reminderJob struct {
+	reminder *nudge.Reminder
+	// group holds the reminders sent together in one message, instead of reminder
+	group []*nudge.Reminder
+	done  func()
+}
+
+// dispatcher polls for due reminders on every tick, resizes the worker pool to
+// match the backlog and fans the reminders out to the workers
+func (s *scheduler) dispatcher() {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			s.logger.Info("Dispatcher stopping due to context cancellation")
+			return
+		case <-s.ticker.C:
+			if s.maintenance != nil && s.maintenance.Enabled() {
+				s.logger.Debug("Skipping reminder processing cycle, down for maintenance")
+				continue
+			}
+			if err := s.dispatchDueReminders(); err != nil {
+				s.logger.Error("Failed to process reminders", zap.Error(err))
+				s.metrics.RecordProcessingError(err)
+			}
+		}
+	}
+}
+
+// dispatchDueReminders runs one processing cycle and waits until every
+// dispatched reminder has been handled, so the next poll never sees a
+// reminder that is still in flight
+func (s *scheduler) dispatchDueReminders() error {
+	startTime := time.Now()
+	s.logger.Debug("Starting reminder processing cycle")
+
+	reminders, err := s.repository.GetDueReminders(time.Now())
+	if err != nil {
+		return NewTemporarySchedulerError("fetch_due_reminders", fmt.Sprintf("failed to fetch due reminders: %v", err))
+	}
+
+	s.metrics.RecordBacklogDepth(len(reminders))
+	workerCount := s.autoscale(len(reminders))
+
+	if len(reminders) == 0 {
+		s.logger.Debug("No due reminders found")
+		return nil
+	}
+
+	s.logger.Info("Processing due reminders",
+		zap.Int("reminder_count", len(reminders)),
+		zap.Int("worker_count", workerCount))
+
+	var batch sync.WaitGroup
+	for _, job := range s.reminderJobs(reminders) {
+		batch.Add(1)
+		job.done = batch.Done
+		select {
+		case s.jobs <- job:
+		case <-s.ctx.Done():
+			batch.Done()
+			batch.Wait()
+			return nil
+		}
+	}
+	batch.Wait()
+
+	processingDuration := time.Since(startTime)
+	s.metrics.RecordReminderProcessed(processingDuration)
+
+	s.logger.Info("Reminder processing cycle completed",
+		zap.Int("total_reminders", len(reminders)),
+		zap.Int("worker_count", workerCount),
+		zap.Duration("processing_duration", processingDuration))
+
+	return nil
+}
+
+// reminderJobs turns due reminders into jobs, grouping the reminders for a
+// chat when the user has reminder grouping turned on
+func (s *scheduler) reminderJobs(reminders []*nudge.Reminder) []reminderJob {
+	type chatKey struct {
+		userID common.UserID
+		chatID common.ChatID
+	}
+
+	grouping := make(map[common.UserID]bool)
+	groups := make(map[chatKey][]*nudge.Reminder)
+	var order []chatKey
+	var jobs []reminderJob
+
+	for _, reminder := range reminders {
+		enabled, known := grouping[reminder.UserID]
+		if !known {
+			settings, err := s.repository.GetNudgeSettingsByUserID(reminder.UserID)
+			enabled = err == nil && settings.GroupReminders
+			grouping[reminder.UserID] = enabled
+		}
+		if !enabled {
+			jobs = append(jobs, reminderJob{reminder: reminder})
+			continue
+		}
+
+		key := chatKey{userID: reminder.UserID, chatID: reminder.ChatID}
+		if _, exists := groups[key]; !exists {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], reminder)
+	}
+
+	for _, key := range order {
+		group := groups[key]
+		for len(group) > 0 {
+			size := min(len(group), MaxReminderGroupSize)
+			jobs = append(jobs, reminderJob{group: group[:size]})
+			group = group[size:]
+		}
+	}
+	return jobs
+}
+
+// autoscale resizes the worker pool for the given backlog and returns the new size
+func (s *scheduler) autoscale(backlog int) int {
+	s.poolMu.Lock()
+	defer s.poolMu.Unlock()
+
+	current := len(s.workers)
+	target := desiredWorkers(backlog, current, s.minWorkers, s.maxWorkers, s.config.BacklogPerWorker)
+	if target != current {
+		s.logger.Info("Scaling scheduler workers",
+			zap.Int("from", current),
+			zap.Int("to", target),
+			zap.Int("backlog", backlog))
+		s.scaleLocked(target)
+	}
+
+	return target
+}
+
+// scaleLocked starts or retires workers until the pool has the target size.
+// The caller must hold poolMu.
+func (s *scheduler) scaleLocked(target int) {
+	for len(s.workers) < target {
+		workerID := s.nextWorkerID
+		s.nextWorkerID++
+
+		workerCtx, cancel := context.WithCancel(s.ctx)
+		s.workers[workerID] = cancel
+		s.wg.Add(1)
+		go s.worker(workerCtx, workerID)
+	}
+
+	// Retire the newest workers first so long-lived worker IDs stay stable
+	for len(s.workers) > target {
+		newest := -1
+		for workerID := range s.workers {
+			if workerID > newest {
+				newest = workerID
+			}
+		}
+		s.workers[newest]()
+		delete(s.workers, newest)
+		s.metrics.RecordWorkerRetired(newest)
+	}
+
+	s.metrics.RecordWorkerCount(len(s.workers))
+}
+
+// removeWorker drops an exited worker from the pool
+func (s *scheduler) removeWorker(workerID int) {
+	s.poolMu.Lock()
+	defer s.poolMu.Unlock()
+
+	if cancel, exists := s.workers[workerID]; exists {
+		cancel()
+		delete(s.workers, workerID)
+	}
+	s.metrics.RecordWorkerRetired(workerID)
+	s.metrics.RecordWorkerCount(len(s.workers))
+}
+
+// desiredWorkers computes the pool size for a backlog. The pool grows straight
+// to the demanded size but shrinks by at most one worker per cycle so a short
+// lull does not tear the pool down.
+func desiredWorkers(backlog, current, minWorkers, maxWorkers, backlogPerWorker int) int {
+	target := minWorkers
+	if backlogPerWorker > 0 {
+		target = (backlog + backlogPerWorker - 1) / backlogPerWorker
+	}
+	if target < current-1 {
+		target = current - 1
+	}
+	return clampWorkers(target, minWorkers, maxWorkers)
+}
+
+// clampWorkers bounds a worker count to the configured pool limits
+func clampWorkers(count, minWorkers, maxWorkers int) int {
+	if count < minWorkers {
+		return minWorkers
+	}
+	if count > maxWorkers {
+		return maxWorkers
+	}
+	return count
+}
@@ -0,0 +1,163 @@
+package scheduler
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/config"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/nudge"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestDesiredWorkers(t *testing.T) {
+	tests := []struct {
+		name    string
+		backlog int
+		current int
+		want    int
+	}{
+		{name: "idle pool stays at minimum", backlog: 0, current: 1, want: 1},
+		{name: "grows straight to demand", backlog: 60, current: 1, want: 3},
+		{name: "capped at maximum", backlog: 1000, current: 2, want: 4},
+		{name: "shrinks one worker per cycle", backlog: 0, current: 4, want: 3},
+		{name: "partial backlog needs a worker", backlog: 1, current: 2, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, desiredWorkers(tt.backlog, tt.current, 1, 4, 25))
+		})
+	}
+}
+
+func TestNewScheduler_AutoscaleValidation(t *testing.T) {
+	base := config.SchedulerConfig{
+		PollInterval:    1,
+		NudgeDelay:      60,
+		WorkerCount:     2,
+		ShutdownTimeout: 5,
+	}
+
+	invalid := base
+	invalid.MinWorkers = 5
+	invalid.MaxWorkers = 2
+	invalid.BacklogPerWorker = 10
+	_, err := NewScheduler(invalid, nil, nil, zaptest.NewLogger(t))
+	require.Error(t, err)
+	assert.True(t, IsConfigurationError(err))
+
+	// Without max_workers the pool is fixed and the autoscale fields are ignored
+	_, err = NewScheduler(base, nil, nil, zaptest.NewLogger(t))
+	require.NoError(t, err)
+}
+
+func TestScheduler_ScalesWithBacklog(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	repo := nudge.NewMemoryNudgeRepository(logger)
+	eventBus := events.NewMockEventBus()
+
+	userID := common.UserID(common.NewID())
+	for i := 0; i < 5; i++ {
+		taskID := common.TaskID(common.NewID())
+		require.NoError(t, repo.CreateTask(&nudge.Task{
+			ID:       taskID,
+			UserID:   userID,
+			Title:    "Task " + string(taskID),
+			Priority: common.PriorityMedium,
+			Status:   common.TaskStatusActive,
+		}))
+		require.NoError(t, repo.CreateReminder(&nudge.Reminder{
+			ID:           common.NewID(),
+			TaskID:       taskID,
+			UserID:       userID,
+			ChatID:       "12345",
+			ScheduledAt:  time.Now().Add(-time.Minute),
+			ReminderType: nudge.ReminderTypeInitial,
+		}))
+	}
+
+	s, err := NewScheduler(config.SchedulerConfig{
+		PollInterval:     1,
+		NudgeDelay:       60,
+		WorkerCount:      1,
+		MinWorkers:       1,
+		MaxWorkers:       3,
+		BacklogPerWorker: 2,
+		ShutdownTimeout:  5,
+	}, repo, eventBus, logger)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Start(context.Background()))
+	defer s.Stop(context.Background())
+
+	require.Eventually(t, func() bool {
+		remaining, err := repo.GetDueReminders(time.Now())
+		return err == nil && len(remaining) == 0
+	}, 5*time.Second, 50*time.Millisecond)
+
+	summary := s.GetMetrics().GetMetricsSummary()
+	assert.Equal(t, 5, summary.BacklogDepth)
+	assert.Equal(t, 3, summary.CurrentWorkers)
+	assert.Len(t, eventBus.GetPublishedEvents(events.TopicReminderDue), 5)
+}
+
+// fakeMaintenance is a maintenance switch tests can flip
+type fakeMaintenance struct {
+	enabled atomic.Bool
+}
+
+func (f *fakeMaintenance) Enabled() bool {
+	return f.enabled.Load()
+}
+
+func TestScheduler_HoldsRemindersDuringMaintenance(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	repo := nudge.NewMemoryNudgeRepository(logger)
+	eventBus := events.NewMockEventBus()
+
+	taskID := common.TaskID(common.NewID())
+	userID := common.UserID(common.NewID())
+	require.NoError(t, repo.CreateTask(&nudge.Task{
+		ID:       taskID,
+		UserID:   userID,
+		Title:    "Renew passport",
+		Priority: common.PriorityMedium,
+		Status:   common.TaskStatusActive,
+	}))
+	require.NoError(t, repo.CreateReminder(&nudge.Reminder{
+		ID:           common.NewID(),
+		TaskID:       taskID,
+		UserID:       userID,
+		ChatID:       "12345",
+		ScheduledAt:  time.Now().Add(-time.Minute),
+		ReminderType: nudge.ReminderTypeInitial,
+	}))
+
+	maintenance := &fakeMaintenance{}
+	maintenance.enabled.Store(true)
+	s, err := NewSchedulerWithMaintenance(config.SchedulerConfig{
+		PollInterval:    1,
+		NudgeDelay:      60,
+		WorkerCount:     1,
+		ShutdownTimeout: 5,
+	}, repo, eventBus, logger, nil, nil, maintenance)
+	require.NoError(t, err)
+
+	require.NoError(t, s.Start(context.Background()))
+	defer s.Stop(context.Background())
+
+	time.Sleep(1500 * time.Millisecond)
+	assert.Empty(t, eventBus.GetPublishedEvents(events.TopicReminderDue), "no reminders are sent during maintenance")
+
+	maintenance.enabled.Store(false)
+	require.Eventually(t, func() bool {
+		return len(eventBus.GetPublishedEvents(events.TopicReminderDue)) == 1
+	}, 5*time.Second, 50*time.Millisecond, "held reminders are sent once maintenance ends")
+}
@@ -0,0 +1,85 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/nudge"
+
+	"go.uber.org/zap"
+)
+
+// ScheduledMessageJobName is the name scheduled message delivery runs under on the job scheduler
+const ScheduledMessageJobName = "scheduled_messages"
+
+// DefaultScheduledMessageSchedule delivers due scheduled messages every minute
+const DefaultScheduledMessageSchedule = "* * * * *"
+
+// scheduledMessageBatchSize is how many due messages are delivered per query
+const scheduledMessageBatchSize = 200
+
+// ScheduledMessageSender delivers messages users asked to be sent later by
+// publishing a ScheduledMessageDue event for each one that is due
+type ScheduledMessageSender struct {
+	repository nudge.ScheduledMessageRepository
+	eventBus   events.EventBus
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+// NewScheduledMessageSender creates a scheduled message sender
+func NewScheduledMessageSender(repository nudge.ScheduledMessageRepository, eventBus events.EventBus, logger *zap.Logger) *ScheduledMessageSender {
+	return &ScheduledMessageSender{
+		repository: repository,
+		eventBus:   eventBus,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// Run delivers every due message, batch by batch, stopping early when ctx is done
+func (s *ScheduledMessageSender) Run(ctx context.Context) error {
+	now := s.now()
+
+	var sent int
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		messages, err := s.repository.GetDueScheduledMessages(now, scheduledMessageBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to get due scheduled messages: %w", err)
+		}
+
+		for _, message := range messages {
+			dueEvent := events.ScheduledMessageDue{
+				Event:     events.NewEvent(),
+				MessageID: string(message.ID),
+				UserID:    string(message.UserID),
+				ChatID:    string(message.ChatID),
+				ThreadID:  message.ThreadID,
+				Text:      message.Text,
+			}
+			// A message that cannot be announced stays due and is retried next run
+			if err := s.eventBus.Publish(events.TopicScheduledMessageDue, dueEvent); err != nil {
+				return fmt.Errorf("failed to publish scheduled message %s: %w", message.ID, err)
+			}
+			if err := s.repository.MarkScheduledMessageSent(message.ID, now); err != nil {
+				return fmt.Errorf("failed to mark scheduled message %s sent: %w", message.ID, err)
+			}
+			sent++
+		}
+
+		if len(messages) < scheduledMessageBatchSize {
+			break
+		}
+	}
+
+	if sent > 0 {
+		s.logger.Info("Sent scheduled messages", zap.Int("messages", sent))
+	}
+	return nil
+}
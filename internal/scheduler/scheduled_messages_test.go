@@ -0,0 +1,50 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/nudge"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestScheduledMessageSender_SendsDueMessagesOnce(t *testing.T) {
+	repo := nudge.NewMemoryScheduledMessageRepository()
+	eventBus := events.NewMockEventBus()
+	sender := NewScheduledMessageSender(repo, eventBus, zaptest.NewLogger(t))
+
+	userID := common.UserID(common.NewID())
+	schedule := func(text string, sendAt time.Time) {
+		require.NoError(t, repo.CreateScheduledMessage(&nudge.ScheduledMessage{
+			ID:       common.NewID(),
+			UserID:   userID,
+			ChatID:   "12345",
+			ThreadID: 7,
+			Text:     text,
+			SendAt:   sendAt,
+		}))
+	}
+	schedule("Wifi password is hunter2", time.Now().Add(-time.Minute))
+	schedule("Gate code 1234", time.Now().Add(-time.Hour))
+	schedule("Pack umbrella", time.Now().Add(time.Hour))
+
+	require.NoError(t, sender.Run(context.Background()))
+
+	published := eventBus.GetPublishedEvents(events.TopicScheduledMessageDue)
+	require.Len(t, published, 2)
+	first := published[0].(events.ScheduledMessageDue)
+	assert.Equal(t, "Gate code 1234", first.Text, "earliest send time first")
+	assert.Equal(t, string(userID), first.UserID)
+	assert.Equal(t, "12345", first.ChatID)
+	assert.Equal(t, 7, first.ThreadID)
+
+	// Sent messages are not sent again
+	require.NoError(t, sender.Run(context.Background()))
+	assert.Len(t, eventBus.GetPublishedEvents(events.TopicScheduledMessageDue), 2)
+}
@@ -7,8 +7,10 @@ import (
 	"sync/atomic"
 	"time"
 
+	"nudgebot-api/internal/common"
 	"nudgebot-api/internal/config"
 	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/experiment"
 	"nudgebot-api/internal/nudge"
 
 	"go.uber.org/zap"
@@ -16,19 +18,45 @@ import (
 
 // Scheduler defines the interface for the background reminder scheduler
 type Scheduler interface {
-	Start(ctx context.Context) error
-	Stop() error
+	common.Service
 	IsRunning() bool
 	GetMetrics() *SchedulerMetrics
+	Ready() <-chan struct{}
+}
+
+// ReminderVariantSelector chooses the experiment variant used for a user's reminders
+type ReminderVariantSelector interface {
+	SelectReminderVariant(userID common.UserID) (*experiment.ReminderVariant, bool)
+}
+
+// ActivitySource reports when a user last interacted with the bot in a chat
+type ActivitySource interface {
+	LastActivity(chatID common.ChatID) (time.Time, bool)
+}
+
+// MaintenanceGate reports whether the service is down for maintenance. Due
+// reminders are held until it is back, then sent on the next poll.
+type MaintenanceGate interface {
+	Enabled() bool
+}
+
+// BotLookup returns the bot a user's reminders are sent through, empty for
+// the default bot
+type BotLookup interface {
+	BotFor(userID common.UserID) (string, error)
 }
 
 // scheduler implements the Scheduler interface
 type scheduler struct {
-	config     config.SchedulerConfig
-	repository nudge.NudgeRepository
-	eventBus   events.EventBus
-	logger     *zap.Logger
-	metrics    *SchedulerMetrics
+	config      config.SchedulerConfig
+	repository  nudge.NudgeRepository
+	eventBus    events.EventBus
+	logger      *zap.Logger
+	metrics     *SchedulerMetrics
+	variants    ReminderVariantSelector
+	activity    ActivitySource
+	maintenance MaintenanceGate
+	bots        BotLookup
 
 	// Context and cancellation
 	ctx    context.Context
@@ -38,10 +66,45 @@ type scheduler struct {
 	wg      sync.WaitGroup
 	ticker  *time.Ticker
 	running atomic.Bool
+	ready   *common.Readiness
+
+	// Worker pool
+	jobs         chan reminderJob
+	poolMu       sync.Mutex
+	workers      map[int]context.CancelFunc
+	nextWorkerID int
+	minWorkers   int
+	maxWorkers   int
 }
 
 // NewScheduler creates a new scheduler instance
 func NewScheduler(cfg config.SchedulerConfig, repository nudge.NudgeRepository, eventBus events.EventBus, logger *zap.Logger) (Scheduler, error) {
+	return NewSchedulerWithExperiments(cfg, repository, eventBus, logger, nil)
+}
+
+// NewSchedulerWithExperiments creates a scheduler that applies experiment variants to reminder
+// wording and nudge timing. A nil selector keeps the default copy and timing.
+func NewSchedulerWithExperiments(cfg config.SchedulerConfig, repository nudge.NudgeRepository, eventBus events.EventBus, logger *zap.Logger, variants ReminderVariantSelector) (Scheduler, error) {
+	return NewSchedulerWithActivity(cfg, repository, eventBus, logger, variants, nil)
+}
+
+// NewSchedulerWithActivity creates a scheduler that also holds reminders due
+// shortly after the user was last active in the chat. A nil activity source
+// disables the hold.
+func NewSchedulerWithActivity(cfg config.SchedulerConfig, repository nudge.NudgeRepository, eventBus events.EventBus, logger *zap.Logger, variants ReminderVariantSelector, activity ActivitySource) (Scheduler, error) {
+	return NewSchedulerWithMaintenance(cfg, repository, eventBus, logger, variants, activity, nil)
+}
+
+// NewSchedulerWithMaintenance creates a scheduler that sends no reminders
+// while the service is down for maintenance. A nil gate never pauses.
+func NewSchedulerWithMaintenance(cfg config.SchedulerConfig, repository nudge.NudgeRepository, eventBus events.EventBus, logger *zap.Logger, variants ReminderVariantSelector, activity ActivitySource, maintenance MaintenanceGate) (Scheduler, error) {
+	return NewSchedulerWithBots(cfg, repository, eventBus, logger, variants, activity, maintenance, nil)
+}
+
+// NewSchedulerWithBots creates a scheduler that pauses a user's reminders only
+// while they have blocked the bot that bots says they talk to. A nil lookup
+// sends every reminder through the default bot.
+func NewSchedulerWithBots(cfg config.SchedulerConfig, repository nudge.NudgeRepository, eventBus events.EventBus, logger *zap.Logger, variants ReminderVariantSelector, activity ActivitySource, maintenance MaintenanceGate, bots BotLookup) (Scheduler, error) {
 	// Validate configuration
 	if cfg.PollInterval <= 0 {
 		return nil, NewConfigurationError("poll_interval", cfg.PollInterval, "must be greater than 0")
@@ -55,13 +118,38 @@ func NewScheduler(cfg config.SchedulerConfig, repository nudge.NudgeRepository,
 	if cfg.ShutdownTimeout <= 0 {
 		return nil, NewConfigurationError("shutdown_timeout", cfg.ShutdownTimeout, "must be greater than 0")
 	}
+	if cfg.ActivityDeferral < 0 {
+		return nil, NewConfigurationError("activity_deferral", cfg.ActivityDeferral, "cannot be negative")
+	}
+
+	// Autoscaling is enabled by setting max_workers; otherwise the pool stays at worker_count
+	minWorkers, maxWorkers := cfg.WorkerCount, cfg.WorkerCount
+	if cfg.MaxWorkers > 0 {
+		if cfg.MinWorkers <= 0 {
+			return nil, NewConfigurationError("min_workers", cfg.MinWorkers, "must be greater than 0")
+		}
+		if cfg.MinWorkers > cfg.MaxWorkers {
+			return nil, NewConfigurationError("max_workers", cfg.MaxWorkers, "must be at least min_workers")
+		}
+		if cfg.BacklogPerWorker <= 0 {
+			return nil, NewConfigurationError("backlog_per_worker", cfg.BacklogPerWorker, "must be greater than 0")
+		}
+		minWorkers, maxWorkers = cfg.MinWorkers, cfg.MaxWorkers
+	}
 
 	return &scheduler{
-		config:     cfg,
-		repository: repository,
-		eventBus:   eventBus,
-		logger:     logger,
-		metrics:    NewSchedulerMetrics(),
+		config:      cfg,
+		repository:  repository,
+		eventBus:    eventBus,
+		logger:      logger,
+		metrics:     NewSchedulerMetrics(),
+		variants:    variants,
+		activity:    activity,
+		maintenance: maintenance,
+		bots:        bots,
+		minWorkers:  minWorkers,
+		maxWorkers:  maxWorkers,
+		ready:       common.NewReadiness(),
 	}, nil
 }
 
@@ -73,25 +161,40 @@ func (s *scheduler) Start(ctx context.Context) error {
 
 	s.ctx, s.cancel = context.WithCancel(ctx)
 	s.ticker = time.NewTicker(time.Duration(s.config.PollInterval) * time.Second)
+	s.jobs = make(chan reminderJob)
+	s.workers = make(map[int]context.CancelFunc)
 	s.running.Store(true)
 
+	initialWorkers := clampWorkers(s.config.WorkerCount, s.minWorkers, s.maxWorkers)
+
 	s.logger.Info("Starting reminder scheduler",
 		zap.Int("poll_interval_seconds", s.config.PollInterval),
 		zap.Int("nudge_delay_seconds", s.config.NudgeDelay),
-		zap.Int("worker_count", s.config.WorkerCount))
+		zap.Int("worker_count", initialWorkers),
+		zap.Int("min_workers", s.minWorkers),
+		zap.Int("max_workers", s.maxWorkers))
 
-	// Start worker goroutines
-	for i := 0; i < s.config.WorkerCount; i++ {
-		s.wg.Add(1)
-		go s.worker(i)
-	}
+	// Start the dispatcher and the initial worker pool
+	s.wg.Add(1)
+	go s.dispatcher()
+
+	s.poolMu.Lock()
+	s.scaleLocked(initialWorkers)
+	s.poolMu.Unlock()
 
 	s.logger.Info("Reminder scheduler started successfully")
+	s.ready.MarkReady()
 	return nil
 }
 
-// Stop gracefully shuts down the scheduler
-func (s *scheduler) Stop() error {
+// Ready returns a channel that is closed once the worker pool has started
+func (s *scheduler) Ready() <-chan struct{} {
+	return s.ready.Ready()
+}
+
+// Stop gracefully shuts down the scheduler, waiting for workers until ctx is
+// done or the configured shutdown timeout passes
+func (s *scheduler) Stop(ctx context.Context) error {
 	if !s.running.Load() {
 		return NewSchedulerError("scheduler_not_running", "scheduler is not running")
 	}
@@ -121,6 +224,9 @@ func (s *scheduler) Stop() error {
 	case <-time.After(time.Duration(s.config.ShutdownTimeout) * time.Second):
 		s.logger.Warn("Scheduler shutdown timed out, some workers may still be running")
 		return NewShutdownError("shutdown timeout exceeded", s.config.ShutdownTimeout)
+	case <-ctx.Done():
+		s.logger.Warn("Scheduler shutdown cancelled, some workers may still be running")
+		return ctx.Err()
 	}
 
 	s.running.Store(false)
@@ -128,6 +234,14 @@ func (s *scheduler) Stop() error {
 	return nil
 }
 
+// Health reports an error when the scheduler is not running
+func (s *scheduler) Health() error {
+	if !s.running.Load() {
+		return NewSchedulerError("scheduler_not_running", "scheduler is not running")
+	}
+	return nil
+}
+
 // IsRunning returns true if the scheduler is currently running
 func (s *scheduler) IsRunning() bool {
 	return s.running.Load()
@@ -138,9 +252,11 @@ func (s *scheduler) GetMetrics() *SchedulerMetrics {
 	return s.metrics
 }
 
-// worker is the main worker goroutine that processes reminders
-func (s *scheduler) worker(workerID int) {
+// worker is a pool goroutine that processes reminders handed out by the dispatcher.
+// It exits when the scheduler stops or when the autoscaler retires it.
+func (s *scheduler) worker(ctx context.Context, workerID int) {
 	defer s.wg.Done()
+	defer s.removeWorker(workerID)
 	defer func() {
 		if r := recover(); r != nil {
 			s.logger.Error("Worker panic recovered - worker will be terminated",
@@ -148,7 +264,7 @@ func (s *scheduler) worker(workerID int) {
 				zap.Any("panic", r))
 			panicErr := NewWorkerError(workerID, "panic_recovery", fmt.Errorf("worker panic: %v", r))
 			s.metrics.RecordProcessingError(panicErr)
-			// Do not restart automatically - let the scheduler handle worker management
+			// Do not restart here - the autoscaler replaces lost workers on its next cycle
 			// This prevents WaitGroup corruption and maintains clean shutdown semantics
 		}
 	}()
@@ -164,16 +280,12 @@ func (s *scheduler) worker(workerID int) {
 
 	for {
 		select {
-		case <-s.ctx.Done():
+		case <-ctx.Done():
 			workerLogger.Info("Worker stopping due to context cancellation")
-			s.metrics.RecordWorkerActivity(workerID, false)
 			return
-		case <-s.ticker.C:
+		case job := <-s.jobs:
 			s.metrics.RecordWorkerActivity(workerID, true)
-			if err := worker.processReminders(); err != nil {
-				workerLogger.Error("Failed to process reminders", zap.Error(err))
-				s.metrics.RecordProcessingError(err)
-			}
+			worker.handleJob(job)
 			s.metrics.RecordWorkerActivity(workerID, false)
 		}
 	}
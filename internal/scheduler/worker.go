@@ -1,11 +1,14 @@
 package scheduler
 
 import (
+	"context"
 	"time"
 
 	"nudgebot-api/internal/common"
 	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/experiment"
 	"nudgebot-api/internal/nudge"
+	"nudgebot-api/internal/tenant"
 
 	"go.uber.org/zap"
 )
@@ -17,79 +20,324 @@ type reminderWorker struct {
 	logger    *zap.Logger
 }
 
-// processReminders fetches and processes all due reminders
-func (w *reminderWorker) processReminders() error {
-	startTime := time.Now()
-	w.logger.Debug("Starting reminder processing cycle")
+// handleJob processes one dispatched reminder, or group of reminders, and
+// creates follow-up nudges when needed
+func (w *reminderWorker) handleJob(job reminderJob) {
+	defer job.done()
 
-	// Fetch due reminders
-	reminders, err := w.scheduler.repository.GetDueReminders(time.Now())
+	if len(job.group) > 0 {
+		w.handleGroup(job.group)
+		return
+	}
+
+	reminder := w.load(job.reminder)
+	if w.droppedAsMuted(reminder) || w.pausedWhileBlocked(reminder) || !w.sendableNow(reminder) {
+		return
+	}
+
+	if err := w.processReminder(reminder); err != nil {
+		w.logger.Error("Failed to process reminder",
+			zap.String("reminder_id", string(reminder.ID)),
+			zap.String("task_id", string(reminder.TaskID)),
+			zap.Error(err))
+		w.scheduler.metrics.RecordProcessingError(err)
+		return
+	}
+
+	w.followUp(reminder.Reminder)
+}
+
+// dueReminder is a due reminder with its task and its user's settings, loaded
+// once for every check made before it is sent
+type dueReminder struct {
+	*nudge.Reminder
+	task     *nudge.Task          // nil when the task could not be loaded
+	settings *nudge.NudgeSettings // nil when the user has no settings
+}
+
+// repositoryFor returns the repository scoped to the tenant of a reminder,
+// or of its user for reminders stored before they carried one
+func (w *reminderWorker) repositoryFor(reminder *nudge.Reminder) nudge.NudgeRepository {
+	ctx := tenant.WithUser(context.Background(), reminder.UserID)
+	return w.scheduler.repository.WithContext(tenant.WithID(ctx, reminder.TenantID))
+}
+
+// load looks up the task and settings of a due reminder
+func (w *reminderWorker) load(reminder *nudge.Reminder) dueReminder {
+	due := dueReminder{Reminder: reminder}
+	repository := w.repositoryFor(reminder)
+	if task, err := repository.GetTaskByID(reminder.TaskID); err == nil {
+		due.task = task
+	}
+	if settings, err := repository.GetNudgeSettingsByUserID(reminder.UserID); err == nil {
+		due.settings = settings
+	}
+	return due
+}
+
+// handleGroup sends the reminders of a group that are not held back in one
+// message. A single remaining reminder is sent on its own.
+func (w *reminderWorker) handleGroup(group []*nudge.Reminder) {
+	var sendable []dueReminder
+	for _, reminder := range group {
+		due := w.load(reminder)
+		if !w.droppedAsMuted(due) && !w.pausedWhileBlocked(due) && w.sendableNow(due) {
+			sendable = append(sendable, due)
+		}
+	}
+
+	var err error
+	switch len(sendable) {
+	case 0:
+		return
+	case 1:
+		err = w.processReminder(sendable[0])
+	default:
+		err = w.processReminderGroup(sendable)
+	}
 	if err != nil {
-		return WrapWorkerError(err, w.workerID, "fetch_due_reminders")
+		w.logger.Error("Failed to process reminder group",
+			zap.String("chat_id", string(group[0].ChatID)),
+			zap.Int("reminders", len(sendable)),
+			zap.Error(err))
+		w.scheduler.metrics.RecordProcessingError(err)
+		return
 	}
 
-	if len(reminders) == 0 {
-		w.logger.Debug("No due reminders found")
-		return nil
+	for _, reminder := range sendable {
+		w.followUp(reminder.Reminder)
 	}
+}
 
-	w.logger.Info("Processing due reminders", zap.Int("reminder_count", len(reminders)))
+// droppedAsMuted marks a reminder for a muted task as sent without sending
+// it, so it is not picked up again and no follow-up nudge is created
+func (w *reminderWorker) droppedAsMuted(reminder dueReminder) bool {
+	if reminder.task == nil || !reminder.task.Muted {
+		return false
+	}
 
-	processedCount := 0
-	nudgesCreated := 0
-	errorCount := 0
+	if err := w.repositoryFor(reminder.Reminder).MarkReminderSent(reminder.ID); err != nil {
+		w.logger.Error("Failed to drop reminder for muted task",
+			zap.String("reminder_id", string(reminder.ID)),
+			zap.String("task_id", string(reminder.TaskID)),
+			zap.Error(err))
+		w.scheduler.metrics.RecordProcessingError(NewReminderProcessingError(string(reminder.ID), "mark_sent", err))
+		return true
+	}
 
-	// Process each reminder
-	for _, reminder := range reminders {
-		if err := w.processReminder(reminder); err != nil {
-			w.logger.Error("Failed to process reminder",
-				zap.String("reminder_id", string(reminder.ID)),
-				zap.String("task_id", string(reminder.TaskID)),
-				zap.Error(err))
-			w.scheduler.metrics.RecordProcessingError(err)
-			errorCount++
-			continue
+	w.logger.Debug("Dropping reminder for muted task",
+		zap.String("reminder_id", string(reminder.ID)),
+		zap.String("task_id", string(reminder.TaskID)))
+	w.scheduler.metrics.RecordReminderMuted()
+	return true
+}
+
+// pausedWhileBlocked reports whether the user blocked the bot the reminder is
+// sent through. Paused reminders are deleted without being sent, so they are
+// not polled again while the user is away or all sent at once when they
+// return; the welcome back summary lists the tasks that fell due meanwhile.
+// No follow-up nudge is created.
+func (w *reminderWorker) pausedWhileBlocked(reminder dueReminder) bool {
+	if !reminder.settings.BlockedOn(userBot(w.scheduler.bots, reminder.UserID, w.logger)) {
+		return false
+	}
+
+	if err := w.repositoryFor(reminder.Reminder).DeleteReminder(reminder.ID); err != nil {
+		w.logger.Error("Failed to pause reminder while the user has the bot blocked",
+			zap.String("reminder_id", string(reminder.ID)),
+			zap.String("user_id", string(reminder.UserID)),
+			zap.Error(err))
+		w.scheduler.metrics.RecordProcessingError(NewReminderProcessingError(string(reminder.ID), "pause", err))
+		return true
+	}
+
+	w.logger.Debug("Pausing reminder while the user has the bot blocked",
+		zap.String("reminder_id", string(reminder.ID)),
+		zap.String("user_id", string(reminder.UserID)))
+	w.scheduler.metrics.RecordReminderPaused()
+	return true
+}
+
+// userBot returns the bot the user's reminders are sent through, empty for
+// the default bot
+func userBot(bots BotLookup, userID common.UserID, logger *zap.Logger) string {
+	if bots == nil {
+		return ""
+	}
+
+	bot, err := bots.BotFor(userID)
+	if err != nil {
+		// The default bot delivers when the user's bot is unknown
+		logger.Warn("Failed to look up the user's bot",
+			zap.String("user_id", string(userID)),
+			zap.Error(err))
+		return ""
+	}
+	return bot
+}
+
+// sendableNow reports whether a reminder may go out this cycle, counting the
+// ones held back after recent activity or during quiet hours
+func (w *reminderWorker) sendableNow(reminder dueReminder) bool {
+	if w.deferredByActivity(reminder) {
+		w.logger.Debug("Deferring reminder after recent user activity",
+			zap.String("reminder_id", string(reminder.ID)),
+			zap.String("task_id", string(reminder.TaskID)))
+		w.scheduler.metrics.RecordReminderDeferred()
+		return false
+	}
+
+	if !w.deliverableNow(reminder) {
+		w.logger.Debug("Holding reminder during quiet hours",
+			zap.String("reminder_id", string(reminder.ID)),
+			zap.String("task_id", string(reminder.TaskID)))
+		w.scheduler.metrics.RecordReminderHeld()
+		return false
+	}
+
+	return true
+}
+
+// followUp creates a follow-up nudge for a sent reminder when one is due
+func (w *reminderWorker) followUp(reminder *nudge.Reminder) {
+	if !w.shouldCreateNudge(reminder) {
+		return
+	}
+
+	if err := w.createNudgeReminder(reminder); err != nil {
+		w.logger.Error("Failed to create nudge reminder",
+			zap.String("reminder_id", string(reminder.ID)),
+			zap.String("task_id", string(reminder.TaskID)),
+			zap.Error(err))
+		w.scheduler.metrics.RecordProcessingError(err)
+		return
+	}
+	w.scheduler.metrics.RecordNudgeCreated()
+}
+
+// deliverableNow applies the user's quiet hours. Held reminders stay unsent and
+// are picked up again by the first poll after quiet hours end.
+func (w *reminderWorker) deliverableNow(reminder dueReminder) bool {
+	settings := reminder.settings
+	if settings == nil || settings.QuietHoursStart == "" {
+		return true
+	}
+
+	reminderManager := nudge.NewReminderManager()
+	if !reminderManager.IsQuietTime(settings, time.Now()) {
+		return true
+	}
+
+	// Only the task priority can lift the hold, via the urgent override
+	if reminder.task == nil {
+		w.logger.Error("Failed to get task for quiet hours evaluation",
+			zap.String("task_id", string(reminder.TaskID)))
+		return false
+	}
+
+	return reminderManager.ShouldDeliverReminder(reminder.task, settings, time.Now())
+}
+
+// deferredByActivity reports whether the user interacted with the bot in the
+// reminder's chat too recently to be nudged. Deferred reminders stay unsent and
+// go out on the first poll after the deferral window.
+func (w *reminderWorker) deferredByActivity(reminder dueReminder) bool {
+	if w.scheduler.activity == nil {
+		return false
+	}
+
+	lastActive, ok := w.scheduler.activity.LastActivity(reminder.ChatID)
+	if !ok {
+		return false
+	}
+
+	deferral := time.Duration(w.scheduler.config.ActivityDeferral) * time.Second
+	if reminder.settings != nil && reminder.settings.ActivityDeferral != nil {
+		deferral = *reminder.settings.ActivityDeferral
+	}
+
+	return deferral > 0 && time.Since(lastActive) < deferral
+}
+
+// processReminder handles a single reminder
+func (w *reminderWorker) processReminder(reminder dueReminder) error {
+	if err := w.scheduler.eventBus.Publish(events.TopicReminderDue, w.reminderDueEvent(reminder)); err != nil {
+		return NewReminderProcessingError(string(reminder.ID), "publish_event", err)
+	}
+
+	// Mark reminder as sent
+	if err := w.repositoryFor(reminder.Reminder).MarkReminderSent(reminder.ID); err != nil {
+		return NewReminderProcessingError(string(reminder.ID), "mark_sent", err)
+	}
+
+	w.logger.Debug("Reminder processed successfully",
+		zap.String("reminder_id", string(reminder.ID)),
+		zap.String("task_id", string(reminder.TaskID)),
+		zap.String("reminder_type", string(reminder.ReminderType)))
+
+	return nil
+}
+
+// processReminderGroup sends the reminders for the same chat that are routed
+// to Telegram as one message. Each reminder still gets its ReminderDue, marked
+// with the group, so that history, experiments and other channels see every
+// reminder sent.
+func (w *reminderWorker) processReminderGroup(reminders []dueReminder) error {
+	group := events.ReminderGroupDue{
+		Event:   events.NewEvent(),
+		GroupID: string(common.NewID()),
+		UserID:  string(reminders[0].UserID),
+		ChatID:  string(reminders[0].ChatID),
+	}
+
+	reminderDueEvents := make([]events.ReminderDue, len(reminders))
+	var telegram []int
+	for i, reminder := range reminders {
+		reminderDueEvents[i] = w.reminderDueEvent(reminder)
+		if reminderDueEvents[i].SendsTo(events.ReminderChannelTelegram) {
+			telegram = append(telegram, i)
+		}
+	}
+
+	// A lone Telegram reminder is sent on its own
+	if len(telegram) > 1 {
+		for _, i := range telegram {
+			reminderDueEvents[i].GroupID = group.GroupID
+			group.Reminders = append(group.Reminders, reminderDueEvents[i])
 		}
+	}
 
-		processedCount++
-
-		// Check if we should create a nudge
-		if w.shouldCreateNudge(reminder) {
-			if err := w.createNudgeReminder(reminder); err != nil {
-				w.logger.Error("Failed to create nudge reminder",
-					zap.String("reminder_id", string(reminder.ID)),
-					zap.String("task_id", string(reminder.TaskID)),
-					zap.Error(err))
-				w.scheduler.metrics.RecordProcessingError(err)
-				errorCount++
-			} else {
-				nudgesCreated++
-			}
+	for i, reminderDueEvent := range reminderDueEvents {
+		if err := w.scheduler.eventBus.Publish(events.TopicReminderDue, reminderDueEvent); err != nil {
+			return NewReminderProcessingError(string(reminders[i].ID), "publish_event", err)
 		}
 	}
 
-	processingDuration := time.Since(startTime)
+	if len(group.Reminders) > 0 {
+		if err := w.scheduler.eventBus.Publish(events.TopicReminderGroupDue, group); err != nil {
+			return NewReminderProcessingError(group.GroupID, "publish_group_event", err)
+		}
+		w.scheduler.metrics.RecordRemindersGrouped(len(group.Reminders))
+	}
 
-	// Record metrics
-	w.scheduler.metrics.RecordReminderProcessed(processingDuration)
-	for i := 0; i < nudgesCreated; i++ {
-		w.scheduler.metrics.RecordNudgeCreated()
+	for _, reminder := range reminders {
+		if err := w.repositoryFor(reminder.Reminder).MarkReminderSent(reminder.ID); err != nil {
+			return NewReminderProcessingError(string(reminder.ID), "mark_sent", err)
+		}
 	}
 
-	w.logger.Info("Reminder processing cycle completed",
-		zap.Int("total_reminders", len(reminders)),
-		zap.Int("processed_count", processedCount),
-		zap.Int("nudges_created", nudgesCreated),
-		zap.Int("error_count", errorCount),
-		zap.Duration("processing_duration", processingDuration))
+	w.logger.Debug("Reminder group processed successfully",
+		zap.String("group_id", group.GroupID),
+		zap.String("chat_id", group.ChatID),
+		zap.Int("reminders", len(reminders)),
+		zap.Int("grouped", len(group.Reminders)))
 
 	return nil
 }
 
-// processReminder handles a single reminder
-func (w *reminderWorker) processReminder(reminder *nudge.Reminder) error {
-	// Publish ReminderDue event with proper ChatID from reminder data
-	//
+// reminderDueEvent builds the ReminderDue for a reminder, with the task
+// details, its channels and the user's experiment variant
+func (w *reminderWorker) reminderDueEvent(reminder dueReminder) events.ReminderDue {
 	// ChatID Resolution:
 	// The ChatID is now properly stored in the reminder data structure, eliminating
 	// the previous assumption that ChatID equals UserID. This ensures:
@@ -108,21 +356,23 @@ func (w *reminderWorker) processReminder(reminder *nudge.Reminder) error {
 		ChatID: string(reminder.ChatID), // Use the actual ChatID from reminder data
 	}
 
-	if err := w.scheduler.eventBus.Publish(events.TopicReminderDue, reminderDueEvent); err != nil {
-		return NewReminderProcessingError(string(reminder.ID), "publish_event", err)
-	}
+	if task := reminder.task; task != nil {
+		reminderDueEvent.Title = task.Title
+		reminderDueEvent.DueDate = task.DueDate
+		reminderDueEvent.Priority = string(task.Priority)
+		reminderDueEvent.ThreadID = task.ThreadID
 
-	// Mark reminder as sent
-	if err := w.scheduler.repository.MarkReminderSent(reminder.ID); err != nil {
-		return NewReminderProcessingError(string(reminder.ID), "mark_sent", err)
+		// Route the reminder by priority; without settings it goes to Telegram
+		reminderDueEvent.Channels = reminder.settings.ReminderChannels(task.Priority)
 	}
 
-	w.logger.Debug("Reminder processed successfully",
-		zap.String("reminder_id", string(reminder.ID)),
-		zap.String("task_id", string(reminder.TaskID)),
-		zap.String("reminder_type", string(reminder.ReminderType)))
+	if variant, ok := w.selectVariant(reminder.UserID); ok {
+		reminderDueEvent.Experiment = variant.Experiment
+		reminderDueEvent.Variant = variant.Variant
+		reminderDueEvent.MessageTemplate = variant.ReminderTemplate
+	}
 
-	return nil
+	return reminderDueEvent
 }
 
 // shouldCreateNudge determines if a follow-up nudge should be created
@@ -133,7 +383,7 @@ func (w *reminderWorker) shouldCreateNudge(reminder *nudge.Reminder) bool {
 	}
 
 	// Get the task to check its status
-	task, err := w.scheduler.repository.GetTaskByID(reminder.TaskID)
+	task, err := w.repositoryFor(reminder).GetTaskByID(reminder.TaskID)
 	if err != nil {
 		w.logger.Error("Failed to get task for nudge evaluation",
 			zap.String("task_id", string(reminder.TaskID)),
@@ -141,8 +391,8 @@ func (w *reminderWorker) shouldCreateNudge(reminder *nudge.Reminder) bool {
 		return false
 	}
 
-	// Don't nudge if task is not active
-	if task.Status != common.TaskStatusActive {
+	// Don't nudge unless the task is being worked on
+	if task.Status != common.TaskStatusActive && task.Status != common.TaskStatusInProgress {
 		w.logger.Debug("Task is not active, skipping nudge creation",
 			zap.String("task_id", string(reminder.TaskID)),
 			zap.String("status", string(task.Status)))
@@ -150,7 +400,7 @@ func (w *reminderWorker) shouldCreateNudge(reminder *nudge.Reminder) bool {
 	}
 
 	// Get existing reminders for this task to count nudges
-	existingReminders, err := w.scheduler.repository.GetRemindersByTaskID(reminder.TaskID)
+	existingReminders, err := w.repositoryFor(reminder).GetRemindersByTaskID(reminder.TaskID)
 	if err != nil {
 		w.logger.Error("Failed to get existing reminders for nudge evaluation",
 			zap.String("task_id", string(reminder.TaskID)),
@@ -205,6 +455,11 @@ func (w *reminderWorker) createNudgeReminder(originalReminder *nudge.Reminder) e
 		}
 	}
 
+	// Experiment variants may override the nudge timing
+	if variant, ok := w.selectVariant(originalReminder.UserID); ok && variant.NudgeDelay > 0 {
+		nudgeSettings.NudgeInterval = variant.NudgeDelay
+	}
+
 	// Use business logic to calculate next nudge time with exponential backoff
 	reminderManager := nudge.NewReminderManager()
 	nudgeTime := reminderManager.GetNextNudgeTime(originalReminder.ScheduledAt, nudgeSettings)
@@ -213,6 +468,7 @@ func (w *reminderWorker) createNudgeReminder(originalReminder *nudge.Reminder) e
 	// This ensures nudge reminders maintain the same chat context as the original reminder
 	nudgeReminder := &nudge.Reminder{
 		ID:           common.NewID(),
+		TenantID:     originalReminder.TenantID,
 		TaskID:       originalReminder.TaskID,
 		UserID:       originalReminder.UserID,
 		ChatID:       originalReminder.ChatID, // Preserve ChatID from original reminder
@@ -236,3 +492,11 @@ func (w *reminderWorker) createNudgeReminder(originalReminder *nudge.Reminder) e
 
 	return nil
 }
+
+// selectVariant returns the experiment variant for a user's reminders, if any
+func (w *reminderWorker) selectVariant(userID common.UserID) (*experiment.ReminderVariant, bool) {
+	if w.scheduler.variants == nil {
+		return nil, false
+	}
+	return w.scheduler.variants.SelectReminderVariant(userID)
+}
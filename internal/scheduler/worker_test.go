@@ -0,0 +1,373 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/config"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/nudge"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// stubActivity reports fixed last-activity times per chat
+type stubActivity map[common.ChatID]time.Time
+
+func (a stubActivity) LastActivity(chatID common.ChatID) (time.Time, bool) {
+	at, ok := a[chatID]
+	return at, ok
+}
+
+func TestReminderWorker_DefersAfterUserActivity(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	repo := nudge.NewMemoryNudgeRepository(logger)
+	eventBus := events.NewMockEventBus()
+	activity := stubActivity{"12345": time.Now().Add(-time.Minute)}
+
+	s, err := NewSchedulerWithActivity(config.SchedulerConfig{
+		PollInterval:     1,
+		NudgeDelay:       60,
+		WorkerCount:      1,
+		ShutdownTimeout:  5,
+		ActivityDeferral: 300,
+	}, repo, eventBus, logger, nil, activity)
+	require.NoError(t, err)
+	impl := s.(*scheduler)
+	worker := &reminderWorker{scheduler: impl, workerID: 1, logger: logger}
+
+	userID := common.UserID(common.NewID())
+	taskID := common.TaskID(common.NewID())
+	require.NoError(t, repo.CreateTask(&nudge.Task{
+		ID:       taskID,
+		UserID:   userID,
+		Title:    "Call the bank",
+		Priority: common.PriorityMedium,
+		Status:   common.TaskStatusActive,
+	}))
+	reminder := &nudge.Reminder{
+		ID:           common.NewID(),
+		TaskID:       taskID,
+		UserID:       userID,
+		ChatID:       "12345",
+		ScheduledAt:  time.Now().Add(-time.Minute),
+		ReminderType: nudge.ReminderTypeInitial,
+	}
+	require.NoError(t, repo.CreateReminder(reminder))
+
+	worker.handleJob(reminderJob{reminder: reminder, done: func() {}})
+	assert.Empty(t, eventBus.GetPublishedEvents(events.TopicReminderDue))
+	assert.Equal(t, int64(1), impl.metrics.GetMetricsSummary().RemindersDeferred)
+
+	// A user who turned deferral off is reminded right away
+	off := time.Duration(0)
+	require.NoError(t, repo.CreateOrUpdateNudgeSettings(&nudge.NudgeSettings{
+		UserID:           userID,
+		NudgeInterval:    time.Hour,
+		MaxNudges:        3,
+		Enabled:          true,
+		ActivityDeferral: &off,
+	}))
+	worker.handleJob(reminderJob{reminder: reminder, done: func() {}})
+	published := eventBus.GetPublishedEvents(events.TopicReminderDue)
+	require.Len(t, published, 1)
+	assert.Equal(t, "Call the bank", published[0].(events.ReminderDue).Title)
+}
+
+func TestReminderWorker_GroupsRemindersPerChat(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	repo := nudge.NewMemoryNudgeRepository(logger)
+	eventBus := events.NewMockEventBus()
+
+	s, err := NewScheduler(config.SchedulerConfig{
+		PollInterval:    1,
+		NudgeDelay:      60,
+		WorkerCount:     1,
+		ShutdownTimeout: 5,
+	}, repo, eventBus, logger)
+	require.NoError(t, err)
+	impl := s.(*scheduler)
+	worker := &reminderWorker{scheduler: impl, workerID: 1, logger: logger}
+
+	grouped := common.UserID(common.NewID())
+	require.NoError(t, repo.CreateOrUpdateNudgeSettings(&nudge.NudgeSettings{
+		UserID:         grouped,
+		NudgeInterval:  time.Hour,
+		MaxNudges:      3,
+		Enabled:        true,
+		GroupReminders: true,
+	}))
+	ungrouped := common.UserID(common.NewID())
+
+	addReminder := func(userID common.UserID, title string) *nudge.Reminder {
+		taskID := common.TaskID(common.NewID())
+		require.NoError(t, repo.CreateTask(&nudge.Task{
+			ID:       taskID,
+			UserID:   userID,
+			Title:    title,
+			Priority: common.PriorityMedium,
+			Status:   common.TaskStatusActive,
+		}))
+		reminder := &nudge.Reminder{
+			ID:           common.NewID(),
+			TaskID:       taskID,
+			UserID:       userID,
+			ChatID:       "12345",
+			ScheduledAt:  time.Now().Add(-time.Minute),
+			ReminderType: nudge.ReminderTypeInitial,
+		}
+		require.NoError(t, repo.CreateReminder(reminder))
+		return reminder
+	}
+	due := []*nudge.Reminder{
+		addReminder(grouped, "Pay rent"),
+		addReminder(ungrouped, "Water plants"),
+		addReminder(grouped, "Call mom"),
+		addReminder(grouped, "Stretch"),
+	}
+
+	jobs := impl.reminderJobs(due)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, due[1], jobs[0].reminder)
+	require.Len(t, jobs[1].group, 3)
+
+	worker.handleJob(reminderJob{group: jobs[1].group, done: func() {}})
+
+	groups := eventBus.GetPublishedEvents(events.TopicReminderGroupDue)
+	require.Len(t, groups, 1)
+	group := groups[0].(events.ReminderGroupDue)
+	assert.Equal(t, string(grouped), group.UserID)
+	assert.Equal(t, "12345", group.ChatID)
+	require.Len(t, group.Reminders, 3)
+	assert.Equal(t, "Pay rent", group.Reminders[0].Title)
+
+	published := eventBus.GetPublishedEvents(events.TopicReminderDue)
+	require.Len(t, published, 3, "history and experiments still see each reminder")
+	for _, event := range published {
+		assert.Equal(t, group.GroupID, event.(events.ReminderDue).GroupID)
+	}
+
+	remaining, err := repo.GetDueReminders(time.Now())
+	require.NoError(t, err)
+	require.Len(t, remaining, 1, "grouped reminders are marked sent")
+	assert.Equal(t, due[1].ID, remaining[0].ID)
+	assert.Equal(t, int64(3), impl.metrics.GetMetricsSummary().RemindersGrouped)
+}
+
+func TestScheduler_SplitsLargeReminderGroups(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	repo := nudge.NewMemoryNudgeRepository(logger)
+	s, err := NewScheduler(config.SchedulerConfig{
+		PollInterval:    1,
+		NudgeDelay:      60,
+		WorkerCount:     1,
+		ShutdownTimeout: 5,
+	}, repo, events.NewMockEventBus(), logger)
+	require.NoError(t, err)
+
+	userID := common.UserID(common.NewID())
+	require.NoError(t, repo.CreateOrUpdateNudgeSettings(&nudge.NudgeSettings{
+		UserID:         userID,
+		NudgeInterval:  time.Hour,
+		MaxNudges:      3,
+		Enabled:        true,
+		GroupReminders: true,
+	}))
+
+	var due []*nudge.Reminder
+	for i := 0; i < MaxReminderGroupSize+2; i++ {
+		due = append(due, &nudge.Reminder{ID: common.NewID(), UserID: userID, ChatID: "12345"})
+	}
+	due = append(due, &nudge.Reminder{ID: common.NewID(), UserID: userID, ChatID: "67890"})
+
+	jobs := s.(*scheduler).reminderJobs(due)
+	require.Len(t, jobs, 3)
+	assert.Len(t, jobs[0].group, MaxReminderGroupSize)
+	assert.Len(t, jobs[1].group, 2)
+	assert.Len(t, jobs[2].group, 1)
+	assert.Equal(t, common.ChatID("67890"), jobs[2].group[0].ChatID)
+}
+
+func TestReminderWorker_RoutesRemindersByPriority(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	repo := nudge.NewMemoryNudgeRepository(logger)
+	eventBus := events.NewMockEventBus()
+
+	s, err := NewScheduler(config.SchedulerConfig{
+		PollInterval:    1,
+		NudgeDelay:      60,
+		WorkerCount:     1,
+		ShutdownTimeout: 5,
+	}, repo, eventBus, logger)
+	require.NoError(t, err)
+	worker := &reminderWorker{scheduler: s.(*scheduler), workerID: 1, logger: logger}
+
+	userID := common.UserID(common.NewID())
+	require.NoError(t, repo.CreateOrUpdateNudgeSettings(&nudge.NudgeSettings{
+		UserID:         userID,
+		NudgeInterval:  time.Hour,
+		MaxNudges:      3,
+		Enabled:        true,
+		GroupReminders: true,
+		ChannelsLow:    "digest",
+	}))
+
+	var group []*nudge.Reminder
+	for _, priority := range []common.Priority{common.PriorityMedium, common.PriorityLow, common.PriorityHigh} {
+		taskID := common.TaskID(common.NewID())
+		require.NoError(t, repo.CreateTask(&nudge.Task{
+			ID:       taskID,
+			UserID:   userID,
+			Title:    string(priority),
+			Priority: priority,
+			Status:   common.TaskStatusActive,
+		}))
+		group = append(group, &nudge.Reminder{
+			ID:           common.NewID(),
+			TaskID:       taskID,
+			UserID:       userID,
+			ChatID:       "12345",
+			ScheduledAt:  time.Now().Add(-time.Minute),
+			ReminderType: nudge.ReminderTypeInitial,
+		})
+	}
+
+	worker.handleJob(reminderJob{group: group, done: func() {}})
+
+	groups := eventBus.GetPublishedEvents(events.TopicReminderGroupDue)
+	require.Len(t, groups, 1)
+	grouped := groups[0].(events.ReminderGroupDue).Reminders
+	require.Len(t, grouped, 2, "the digest reminder is left out of the Telegram message")
+	assert.Equal(t, "medium", grouped[0].Title)
+	assert.Equal(t, "high", grouped[1].Title)
+
+	published := eventBus.GetPublishedEvents(events.TopicReminderDue)
+	require.Len(t, published, 3)
+	low := published[1].(events.ReminderDue)
+	assert.Equal(t, "low", low.Priority)
+	assert.Equal(t, []string{"digest"}, low.Channels)
+	assert.Empty(t, low.GroupID)
+}
+
+func TestReminderWorker_DropsRemindersForMutedTasks(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	repo := nudge.NewMemoryNudgeRepository(logger)
+	eventBus := events.NewMockEventBus()
+
+	s, err := NewScheduler(config.SchedulerConfig{
+		PollInterval:    1,
+		NudgeDelay:      60,
+		WorkerCount:     1,
+		ShutdownTimeout: 5,
+	}, repo, eventBus, logger)
+	require.NoError(t, err)
+	worker := &reminderWorker{scheduler: s.(*scheduler), workerID: 1, logger: logger}
+
+	userID := common.UserID(common.NewID())
+	due := time.Now().Add(30 * time.Minute)
+	taskID := common.TaskID(common.NewID())
+	require.NoError(t, repo.CreateTask(&nudge.Task{
+		ID:       taskID,
+		UserID:   userID,
+		Title:    "Muted task",
+		DueDate:  &due,
+		Priority: common.PriorityHigh,
+		Status:   common.TaskStatusActive,
+		Muted:    true,
+	}))
+	reminder := &nudge.Reminder{
+		ID:           common.NewID(),
+		TaskID:       taskID,
+		UserID:       userID,
+		ChatID:       "12345",
+		ScheduledAt:  time.Now().Add(-time.Minute),
+		ReminderType: nudge.ReminderTypeInitial,
+	}
+	require.NoError(t, repo.CreateReminder(reminder))
+
+	worker.handleJob(reminderJob{reminder: reminder, done: func() {}})
+
+	assert.Empty(t, eventBus.GetPublishedEvents(events.TopicReminderDue))
+	dueReminders, err := repo.GetDueReminders(time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, dueReminders, "the dropped reminder is not picked up again")
+	reminders, err := repo.GetRemindersByTaskID(taskID)
+	require.NoError(t, err)
+	assert.Len(t, reminders, 1, "no follow-up nudge is created")
+	assert.Equal(t, int64(1), s.(*scheduler).metrics.RemindersMuted)
+}
+
+// stubBots names the bot each user talks to
+type stubBots map[common.UserID]string
+
+func (b stubBots) BotFor(userID common.UserID) (string, error) {
+	return b[userID], nil
+}
+
+func TestReminderWorker_PausesRemindersWhileUserBlockedTheBot(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	repo := nudge.NewMemoryNudgeRepository(logger)
+	eventBus := events.NewMockEventBus()
+
+	bots := stubBots{}
+	s, err := NewSchedulerWithBots(config.SchedulerConfig{
+		PollInterval:    1,
+		NudgeDelay:      60,
+		WorkerCount:     1,
+		ShutdownTimeout: 5,
+	}, repo, eventBus, logger, nil, nil, nil, bots)
+	require.NoError(t, err)
+	worker := &reminderWorker{scheduler: s.(*scheduler), workerID: 1, logger: logger}
+
+	userID := common.UserID(common.NewID())
+	blockedAt := time.Now().Add(-time.Hour)
+	require.NoError(t, repo.CreateOrUpdateNudgeSettings(&nudge.NudgeSettings{
+		UserID:        userID,
+		NudgeInterval: time.Hour,
+		MaxNudges:     3,
+		Enabled:       true,
+		BlockedAt:     &blockedAt,
+	}))
+	due := time.Now().Add(30 * time.Minute)
+	taskID := common.TaskID(common.NewID())
+	require.NoError(t, repo.CreateTask(&nudge.Task{
+		ID:       taskID,
+		UserID:   userID,
+		Title:    "Task of a user who left",
+		DueDate:  &due,
+		Priority: common.PriorityHigh,
+		Status:   common.TaskStatusActive,
+	}))
+	reminder := &nudge.Reminder{
+		ID:           common.NewID(),
+		TaskID:       taskID,
+		UserID:       userID,
+		ChatID:       "12345",
+		ScheduledAt:  time.Now().Add(-time.Minute),
+		ReminderType: nudge.ReminderTypeInitial,
+	}
+	require.NoError(t, repo.CreateReminder(reminder))
+
+	worker.handleJob(reminderJob{reminder: reminder, done: func() {}})
+
+	assert.Empty(t, eventBus.GetPublishedEvents(events.TopicReminderDue))
+	dueReminders, err := repo.GetDueReminders(time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, dueReminders, "the paused reminder is not polled again")
+	reminders, err := repo.GetRemindersByTaskID(taskID)
+	require.NoError(t, err)
+	assert.Empty(t, reminders, "no follow-up nudge is created")
+	assert.Equal(t, int64(1), s.(*scheduler).metrics.RemindersPaused)
+	assert.Zero(t, s.(*scheduler).metrics.RemindersProcessed)
+
+	// The block is on the default bot; a user now talking to another bot is
+	// reminded through it
+	bots[userID] = "work_bot"
+	reminder.ID = common.NewID()
+	require.NoError(t, repo.CreateReminder(reminder))
+	worker.handleJob(reminderJob{reminder: reminder, done: func() {}})
+	assert.Len(t, eventBus.GetPublishedEvents(events.TopicReminderDue), 1)
+}
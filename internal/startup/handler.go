@@ -0,0 +1,29 @@
+package startup
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// SwappableHandler serves HTTP with a handler that can be replaced at runtime,
+// so the server can listen before the full router is built
+type SwappableHandler struct {
+	current atomic.Value
+}
+
+// NewSwappableHandler creates a handler that initially delegates to initial
+func NewSwappableHandler(initial http.Handler) *SwappableHandler {
+	h := &SwappableHandler{}
+	h.Swap(initial)
+	return h
+}
+
+// Swap replaces the handler used for subsequent requests
+func (h *SwappableHandler) Swap(next http.Handler) {
+	h.current.Store(&next)
+}
+
+// ServeHTTP implements http.Handler
+func (h *SwappableHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	(*h.current.Load().(*http.Handler)).ServeHTTP(w, r)
+}
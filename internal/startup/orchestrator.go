@@ -0,0 +1,181 @@
+// Package startup brings up external dependencies in order, retrying each one
+// with backoff instead of failing the process when it is briefly unavailable.
+package startup
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"nudgebot-api/internal/config"
+
+	"github.com/cenkalti/backoff/v4"
+	"go.uber.org/zap"
+)
+
+// Dependency states
+const (
+	StatePending  = "pending"
+	StateStarting = "starting"
+	StateReady    = "ready"
+	StateFailed   = "failed"
+)
+
+// InitFunc initializes one dependency. It is called again after a backoff
+// when it returns an error.
+type InitFunc func(ctx context.Context) error
+
+// DependencyStatus reports the startup progress of one dependency
+type DependencyStatus struct {
+	Name      string     `json:"name"`
+	State     string     `json:"state"`
+	Attempts  int        `json:"attempts"`
+	LastError string     `json:"last_error,omitempty"`
+	ReadyAt   *time.Time `json:"ready_at,omitempty"`
+}
+
+// step is a registered dependency and its progress
+type step struct {
+	name   string
+	init   InitFunc
+	status DependencyStatus
+}
+
+// Orchestrator initializes dependencies one after another in registration order
+type Orchestrator struct {
+	logger          *zap.Logger
+	initialInterval time.Duration
+	maxInterval     time.Duration
+	maxAttempts     int
+
+	mu    sync.RWMutex
+	steps []*step
+	ready bool
+}
+
+// NewOrchestrator creates an orchestrator with the configured backoff. A
+// MaxAttempts of zero retries each dependency until the context is cancelled.
+func NewOrchestrator(cfg config.StartupConfig, logger *zap.Logger) *Orchestrator {
+	initialInterval := time.Duration(cfg.InitialBackoffMs) * time.Millisecond
+	if initialInterval <= 0 {
+		initialInterval = 500 * time.Millisecond
+	}
+	maxInterval := time.Duration(cfg.MaxBackoff) * time.Second
+	if maxInterval < initialInterval {
+		maxInterval = initialInterval
+	}
+
+	return &Orchestrator{
+		logger:          logger,
+		initialInterval: initialInterval,
+		maxInterval:     maxInterval,
+		maxAttempts:     cfg.MaxAttempts,
+	}
+}
+
+// Add registers a dependency. Dependencies start in the order they are added.
+func (o *Orchestrator) Add(name string, init InitFunc) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.steps = append(o.steps, &step{
+		name:   name,
+		init:   init,
+		status: DependencyStatus{Name: name, State: StatePending},
+	})
+}
+
+// Run initializes every dependency in order. It returns an error when a
+// dependency runs out of attempts or the context is cancelled first.
+func (o *Orchestrator) Run(ctx context.Context) error {
+	o.mu.RLock()
+	steps := append([]*step(nil), o.steps...)
+	o.mu.RUnlock()
+
+	for _, s := range steps {
+		if err := o.runStep(ctx, s); err != nil {
+			return err
+		}
+	}
+
+	o.mu.Lock()
+	o.ready = true
+	o.mu.Unlock()
+
+	o.logger.Info("All startup dependencies ready", zap.Int("dependencies", len(steps)))
+	return nil
+}
+
+// runStep retries one dependency with exponential backoff
+func (o *Orchestrator) runStep(ctx context.Context, s *step) error {
+	policy := backoff.NewExponentialBackOff()
+	policy.InitialInterval = o.initialInterval
+	policy.MaxInterval = o.maxInterval
+	policy.MaxElapsedTime = 0
+
+	var retry backoff.BackOff = policy
+	if o.maxAttempts > 0 {
+		retry = backoff.WithMaxRetries(policy, uint64(o.maxAttempts-1))
+	}
+
+	o.update(s, func(status *DependencyStatus) { status.State = StateStarting })
+
+	operation := func() error {
+		err := s.init(ctx)
+		o.update(s, func(status *DependencyStatus) {
+			status.Attempts++
+			status.LastError = ""
+			if err != nil {
+				status.LastError = err.Error()
+			}
+		})
+		return err
+	}
+	notify := func(err error, wait time.Duration) {
+		o.logger.Warn("Startup dependency unavailable, retrying",
+			zap.String("dependency", s.name),
+			zap.Duration("retry_in", wait),
+			zap.Error(err))
+	}
+
+	if err := backoff.RetryNotify(operation, backoff.WithContext(retry, ctx), notify); err != nil {
+		o.update(s, func(status *DependencyStatus) { status.State = StateFailed })
+		return fmt.Errorf("startup dependency %q failed: %w", s.name, err)
+	}
+
+	now := time.Now()
+	o.update(s, func(status *DependencyStatus) {
+		status.State = StateReady
+		status.ReadyAt = &now
+	})
+	o.logger.Info("Startup dependency ready", zap.String("dependency", s.name))
+	return nil
+}
+
+func (o *Orchestrator) update(s *step, fn func(status *DependencyStatus)) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	fn(&s.status)
+}
+
+// Ready reports whether every dependency has started
+func (o *Orchestrator) Ready() bool {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	return o.ready
+}
+
+// Statuses returns the progress of every dependency in startup order
+func (o *Orchestrator) Statuses() []DependencyStatus {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	statuses := make([]DependencyStatus, 0, len(o.steps))
+	for _, s := range o.steps {
+		statuses = append(statuses, s.status)
+	}
+	return statuses
+}
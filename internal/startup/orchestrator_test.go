@@ -0,0 +1,104 @@
+package startup
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+func fastConfig(maxAttempts int) config.StartupConfig {
+	return config.StartupConfig{InitialBackoffMs: 1, MaxBackoff: 1, MaxAttempts: maxAttempts}
+}
+
+func TestOrchestrator_RetriesInOrder(t *testing.T) {
+	orchestrator := NewOrchestrator(fastConfig(0), zaptest.NewLogger(t))
+
+	var order []string
+	failures := 2
+	orchestrator.Add("postgres", func(ctx context.Context) error {
+		if failures > 0 {
+			failures--
+			return errors.New("connection refused")
+		}
+		order = append(order, "postgres")
+		return nil
+	})
+	orchestrator.Add("telegram", func(ctx context.Context) error {
+		order = append(order, "telegram")
+		return nil
+	})
+
+	assert.False(t, orchestrator.Ready())
+	require.NoError(t, orchestrator.Run(context.Background()))
+
+	assert.True(t, orchestrator.Ready())
+	assert.Equal(t, []string{"postgres", "telegram"}, order)
+
+	statuses := orchestrator.Statuses()
+	require.Len(t, statuses, 2)
+	assert.Equal(t, StateReady, statuses[0].State)
+	assert.Equal(t, 3, statuses[0].Attempts)
+	assert.Empty(t, statuses[0].LastError)
+	assert.NotNil(t, statuses[0].ReadyAt)
+}
+
+func TestOrchestrator_GivesUp(t *testing.T) {
+	orchestrator := NewOrchestrator(fastConfig(3), zaptest.NewLogger(t))
+	orchestrator.Add("postgres", func(ctx context.Context) error {
+		return errors.New("connection refused")
+	})
+	orchestrator.Add("telegram", func(ctx context.Context) error {
+		t.Fatal("later dependencies must not start")
+		return nil
+	})
+
+	err := orchestrator.Run(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "postgres")
+
+	statuses := orchestrator.Statuses()
+	assert.Equal(t, StateFailed, statuses[0].State)
+	assert.Equal(t, 3, statuses[0].Attempts)
+	assert.Equal(t, "connection refused", statuses[0].LastError)
+	assert.Equal(t, StatePending, statuses[1].State)
+	assert.False(t, orchestrator.Ready())
+}
+
+func TestOrchestrator_StopsOnCancel(t *testing.T) {
+	orchestrator := NewOrchestrator(fastConfig(0), zaptest.NewLogger(t))
+	orchestrator.Add("postgres", func(ctx context.Context) error {
+		return errors.New("connection refused")
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	assert.Error(t, orchestrator.Run(ctx))
+}
+
+func TestSwappableHandler(t *testing.T) {
+	handler := NewSwappableHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+	handler.Swap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
@@ -0,0 +1,165 @@
+package tenant
+
+import (
+	"fmt"
+	"reflect"
+
+	"nudgebot-api/internal/common"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// fieldName is the model field holding the tenant ID
+const fieldName = "TenantID"
+
+// Plugin scopes GORM calls to the tenant carried by the statement context.
+// Rows created through models with a TenantID field are tagged with the
+// tenant, and queries, updates and deletes on those models only match the
+// tenant's rows. Raw SQL and calls without a tenant in the context are not
+// scoped.
+type Plugin struct{}
+
+// NewPlugin creates the tenant scoping plugin; install it with db.Use
+func NewPlugin() *Plugin {
+	return &Plugin{}
+}
+
+// Name implements gorm.Plugin
+func (p *Plugin) Name() string {
+	return "tenant"
+}
+
+// Initialize implements gorm.Plugin by registering the scoping callbacks
+func (p *Plugin) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Create().Before("gorm:create").Register("tenant:assign", assignTenant); err != nil {
+		return err
+	}
+	if err := db.Callback().Query().Before("gorm:query").Register("tenant:scope", scopeToTenant); err != nil {
+		return err
+	}
+	if err := db.Callback().Row().Before("gorm:row").Register("tenant:scope", scopeToTenant); err != nil {
+		return err
+	}
+	// Saving a struct writes every column, so updates also keep the tag
+	if err := db.Callback().Update().Before("gorm:update").Register("tenant:assign", assignTenant); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("tenant:scope", scopeToTenant); err != nil {
+		return err
+	}
+	return db.Callback().Delete().Before("gorm:delete").Register("tenant:scope", scopeToTenant)
+}
+
+// assignTenant tags created and updated rows with the tenant
+func assignTenant(db *gorm.DB) {
+	id, ok := FromContext(db.Statement.Context)
+	if !ok || db.Statement.Schema == nil || db.Statement.Schema.LookUpField(fieldName) == nil {
+		return
+	}
+	db.Statement.SetColumn(fieldName, id, true)
+}
+
+// scopeToTenant restricts the statement to the tenant's rows
+func scopeToTenant(db *gorm.DB) {
+	id, ok := FromContext(db.Statement.Context)
+	if !ok || db.Statement.Schema == nil {
+		return
+	}
+
+	field := db.Statement.Schema.LookUpField(fieldName)
+	if field == nil {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: id},
+	}})
+}
+
+// ScopeTable restricts a statement the Plugin cannot scope, such as one on a
+// table named in SQL rather than through a model, to the tenant carried by
+// its context. The table must have a tenant_id column.
+func ScopeTable(table string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		id, ok := FromContext(db.Statement.Context)
+		if !ok {
+			return db
+		}
+		return db.Where(clause.Eq{Column: clause.Column{Table: table, Name: "tenant_id"}, Value: id})
+	}
+}
+
+// ownerFields are the model fields that can hold the user a row belongs to,
+// in order of preference
+var ownerFields = []string{"UserID", "OwnerID", "CreatedBy"}
+
+// OwnerPlugin tags rows created without a tenant in the context with the
+// tenant of the user they belong to, so rows written by code that is not
+// tenant aware still land in their owner's tenant. It only applies to models
+// with a TenantID field and one of the ownerFields, and runs after the Plugin.
+type OwnerPlugin struct {
+	resolver Resolver
+}
+
+// NewOwnerPlugin creates the plugin tagging rows with their owner's tenant as
+// found by resolver; install it with db.Use after the Plugin
+func NewOwnerPlugin(resolver Resolver) *OwnerPlugin {
+	return &OwnerPlugin{resolver: resolver}
+}
+
+// Name implements gorm.Plugin
+func (p *OwnerPlugin) Name() string {
+	return "tenant_owner"
+}
+
+// Initialize implements gorm.Plugin by registering the tagging callback
+func (p *OwnerPlugin) Initialize(db *gorm.DB) error {
+	return db.Callback().Create().After("tenant:assign").Before("gorm:create").Register("tenant:assign_owner", p.assignOwnerTenant)
+}
+
+// assignOwnerTenant tags each created row that has no tenant yet with its
+// owner's tenant
+func (p *OwnerPlugin) assignOwnerTenant(db *gorm.DB) {
+	if _, ok := FromContext(db.Statement.Context); ok || db.Statement.Schema == nil {
+		return
+	}
+	tenantField := db.Statement.Schema.LookUpField(fieldName)
+	var userField *schema.Field
+	for _, name := range ownerFields {
+		if userField = db.Statement.Schema.LookUpField(name); userField != nil {
+			break
+		}
+	}
+	if tenantField == nil || userField == nil {
+		return
+	}
+
+	ctx := db.Statement.Context
+	tag := func(row reflect.Value) {
+		if _, zero := tenantField.ValueOf(ctx, row); !zero {
+			return
+		}
+		userID, zero := userField.ValueOf(ctx, row)
+		if zero {
+			return
+		}
+		id, err := p.resolver.TenantFor(common.UserID(fmt.Sprint(userID)))
+		if err != nil {
+			// The column default keeps the row in the default tenant
+			return
+		}
+		if err := tenantField.Set(ctx, row, id); err != nil {
+			_ = db.AddError(err)
+		}
+	}
+
+	switch rows := reflect.Indirect(db.Statement.ReflectValue); rows.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rows.Len(); i++ {
+			tag(reflect.Indirect(rows.Index(i)))
+		}
+	case reflect.Struct:
+		tag(rows)
+	}
+}
@@ -0,0 +1,128 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/config"
+)
+
+// DefaultID is the tenant of users talking to the deployment's own bots
+const DefaultID = "default"
+
+// ErrNoCaller is returned for calls that must be scoped to the caller's
+// tenant but whose context does not say who the caller is
+var ErrNoCaller = errors.New("no tenant, user or bot in the context")
+
+type contextKey struct{}
+
+type userKey struct{}
+
+type botKey struct{}
+
+// WithID returns a context carrying the tenant ID. Database calls made with
+// the context are scoped to the tenant by the Plugin.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the tenant ID carried by ctx
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(contextKey{}).(string)
+	return id, ok && id != ""
+}
+
+// WithUser returns a context carrying the user a call is made for, whose
+// tenant the call is scoped to by Caller
+func WithUser(ctx context.Context, userID common.UserID) context.Context {
+	return context.WithValue(ctx, userKey{}, userID)
+}
+
+// WithBot returns a context carrying the bot a call is made for, empty for
+// the default bot, for calls about a chat rather than a user
+func WithBot(ctx context.Context, bot string) context.Context {
+	return context.WithValue(ctx, botKey{}, bot)
+}
+
+// Caller returns the tenant of the caller ctx carries: its tenant ID, or else
+// the tenant of its user or of its bot. Bots are tenants when listed in
+// tenantIDs. ok is false when ctx carries no caller.
+func Caller(ctx context.Context, resolver Resolver, tenantIDs []string) (id string, ok bool, err error) {
+	if id, ok := FromContext(ctx); ok {
+		return id, true, nil
+	}
+	if userID, ok := ctx.Value(userKey{}).(common.UserID); ok && userID != "" {
+		id, err := resolver.TenantFor(userID)
+		return id, err == nil, err
+	}
+	if bot, ok := ctx.Value(botKey{}).(string); ok {
+		for _, id := range tenantIDs {
+			if id == bot && bot != "" {
+				return bot, true, nil
+			}
+		}
+		return DefaultID, true, nil
+	}
+	return "", false, nil
+}
+
+// Resolver finds the tenant a user belongs to
+type Resolver interface {
+	TenantFor(userID common.UserID) (string, error)
+}
+
+// ResolverFunc adapts a function to the Resolver interface
+type ResolverFunc func(userID common.UserID) (string, error)
+
+// TenantFor calls f(userID)
+func (f ResolverFunc) TenantFor(userID common.UserID) (string, error) {
+	return f(userID)
+}
+
+// NewBotResolver resolves tenants from the bot each user talks to. Users of
+// a tenant's bot belong to that tenant; everyone else belongs to DefaultID.
+func NewBotResolver(botFor func(userID common.UserID) (string, error), tenants []config.TenantConfig) Resolver {
+	ids := make(map[string]bool, len(tenants))
+	for _, t := range tenants {
+		ids[t.ID] = true
+	}
+
+	return ResolverFunc(func(userID common.UserID) (string, error) {
+		bot, err := botFor(userID)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve tenant: %w", err)
+		}
+		if ids[bot] {
+			return bot, nil
+		}
+		return DefaultID, nil
+	})
+}
+
+// IDs returns the default tenant followed by the configured tenants
+func IDs(tenants []config.TenantConfig) []string {
+	ids := []string{DefaultID}
+	for _, t := range tenants {
+		ids = append(ids, t.ID)
+	}
+	return ids
+}
+
+// BotConfigs returns the bot serving each tenant, named after the tenant
+func BotConfigs(tenants []config.TenantConfig) ([]config.BotConfig, error) {
+	bots := make([]config.BotConfig, 0, len(tenants))
+	for _, t := range tenants {
+		if t.ID == DefaultID {
+			return nil, fmt.Errorf("tenant ID %q is reserved", DefaultID)
+		}
+		bots = append(bots, config.BotConfig{
+			Name:       t.ID,
+			Token:      t.BotToken,
+			WebhookURL: t.WebhookURL,
+			Mode:       t.Mode,
+		})
+	}
+	return bots, nil
+}
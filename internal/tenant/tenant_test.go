@@ -0,0 +1,198 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type scopedRow struct {
+	ID       string
+	TenantID string
+	Title    string
+}
+
+type sharedRow struct {
+	ID    string
+	Title string
+}
+
+type ownedRow struct {
+	ID       string
+	TenantID string
+	UserID   string
+}
+
+// dryRunDB builds SQL without connecting to a database
+func dryRunDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		SkipDefaultTransaction: true,
+		DisableAutomaticPing:   true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(NewPlugin()))
+	return db
+}
+
+func TestPlugin_ScopesModelsWithTenantID(t *testing.T) {
+	db := dryRunDB(t)
+	scoped := db.WithContext(WithID(context.Background(), "acme"))
+
+	stmt := scoped.Where("title = ?", "report").Find(&[]scopedRow{}).Statement
+	assert.Contains(t, stmt.SQL.String(), `"scoped_rows"."tenant_id" = $2`)
+	assert.Equal(t, []interface{}{"report", "acme"}, stmt.Vars)
+
+	stmt = scoped.Model(&scopedRow{}).Where("id = ?", "1").Update("title", "done").Statement
+	assert.Contains(t, stmt.SQL.String(), `"scoped_rows"."tenant_id" =`)
+
+	stmt = scoped.Where("id = ?", "1").Delete(&scopedRow{}).Statement
+	assert.Contains(t, stmt.SQL.String(), `"scoped_rows"."tenant_id" =`)
+
+	row := &scopedRow{ID: "1", Title: "report"}
+	scoped.Create(row)
+	assert.Equal(t, "acme", row.TenantID)
+
+	// Saving a row built without the tag keeps it tagged
+	saved := &scopedRow{ID: "1", Title: "edited"}
+	stmt = scoped.Save(saved).Statement
+	assert.Equal(t, "acme", saved.TenantID)
+	assert.Contains(t, stmt.SQL.String(), `"scoped_rows"."tenant_id" =`)
+}
+
+func TestPlugin_LeavesOtherCallsUnscoped(t *testing.T) {
+	db := dryRunDB(t)
+
+	// No tenant in the context
+	stmt := db.Find(&[]scopedRow{}).Statement
+	assert.NotContains(t, stmt.SQL.String(), "tenant_id")
+
+	// Models without a tenant column
+	stmt = db.WithContext(WithID(context.Background(), "acme")).Find(&[]sharedRow{}).Statement
+	assert.NotContains(t, stmt.SQL.String(), "tenant_id")
+}
+
+func TestScopeTable(t *testing.T) {
+	db := dryRunDB(t)
+
+	stmt := db.WithContext(WithID(context.Background(), "acme")).Table("digest_entries").
+		Scopes(ScopeTable("digest_entries")).Where("chat_id = ?", "1").Update("chat_id", "2").Statement
+	assert.Contains(t, stmt.SQL.String(), `"digest_entries"."tenant_id" =`)
+	assert.Contains(t, stmt.Vars, "acme")
+
+	stmt = db.Table("digest_entries").Scopes(ScopeTable("digest_entries")).Where("chat_id = ?", "1").Update("chat_id", "2").Statement
+	assert.NotContains(t, stmt.SQL.String(), "tenant_id")
+}
+
+func TestOwnerPlugin_TagsRowsWithOwnersTenant(t *testing.T) {
+	db := dryRunDB(t)
+	require.NoError(t, db.Use(NewOwnerPlugin(ResolverFunc(func(userID common.UserID) (string, error) {
+		if userID == "broken" {
+			return "", errors.New("lookup failed")
+		}
+		return "tenant-of-" + string(userID), nil
+	}))))
+
+	row := &ownedRow{ID: "1", UserID: "ann"}
+	db.Create(row)
+	assert.Equal(t, "tenant-of-ann", row.TenantID)
+
+	rows := []ownedRow{{ID: "2", UserID: "bob"}, {ID: "3", UserID: "cy", TenantID: "acme"}, {ID: "4", UserID: "broken"}}
+	db.Create(&rows)
+	assert.Equal(t, "tenant-of-bob", rows[0].TenantID)
+	assert.Equal(t, "acme", rows[1].TenantID, "rows already tagged keep their tenant")
+	assert.Empty(t, rows[2].TenantID, "rows of unknown owners keep the column default")
+
+	// The tenant in the context wins over the owner's
+	row = &ownedRow{ID: "5", UserID: "ann"}
+	db.WithContext(WithID(context.Background(), "acme")).Create(row)
+	assert.Equal(t, "acme", row.TenantID)
+}
+
+func TestCaller(t *testing.T) {
+	resolver := ResolverFunc(func(userID common.UserID) (string, error) {
+		if userID == "broken" {
+			return "", errors.New("lookup failed")
+		}
+		return "acme", nil
+	})
+	tenantIDs := []string{DefaultID, "acme"}
+	ctx := context.Background()
+
+	id, ok, err := Caller(WithUser(WithID(ctx, "globex"), "ann"), resolver, tenantIDs)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "globex", id, "a tenant in the context wins")
+
+	id, ok, err = Caller(WithUser(ctx, "ann"), resolver, tenantIDs)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "acme", id)
+
+	_, ok, err = Caller(WithUser(ctx, "broken"), resolver, tenantIDs)
+	assert.Error(t, err)
+	assert.False(t, ok)
+
+	id, ok, err = Caller(WithBot(ctx, "acme"), resolver, tenantIDs)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "acme", id)
+
+	// Bots that are not tenants, and the default bot, talk for the default tenant
+	for _, bot := range []string{"staging", ""} {
+		id, ok, err = Caller(WithBot(ctx, bot), resolver, tenantIDs)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, DefaultID, id)
+	}
+
+	_, ok, err = Caller(ctx, resolver, tenantIDs)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestNewBotResolver(t *testing.T) {
+	bots := map[common.UserID]string{"acme-user": "acme", "staging-user": "staging"}
+	resolver := NewBotResolver(func(userID common.UserID) (string, error) {
+		if userID == "broken" {
+			return "", errors.New("lookup failed")
+		}
+		return bots[userID], nil
+	}, []config.TenantConfig{{ID: "acme"}})
+
+	id, err := resolver.TenantFor("acme-user")
+	require.NoError(t, err)
+	assert.Equal(t, "acme", id)
+
+	// Bots that are not tenants, and users of the default bot, share the default tenant
+	id, err = resolver.TenantFor("staging-user")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultID, id)
+	id, err = resolver.TenantFor("someone")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultID, id)
+
+	_, err = resolver.TenantFor("broken")
+	assert.Error(t, err)
+}
+
+func TestBotConfigs(t *testing.T) {
+	bots, err := BotConfigs([]config.TenantConfig{{ID: "acme", BotToken: "token", Mode: "polling"}})
+	require.NoError(t, err)
+	assert.Equal(t, []config.BotConfig{{Name: "acme", Token: "token", Mode: "polling"}}, bots)
+
+	_, err = BotConfigs([]config.TenantConfig{{ID: DefaultID, BotToken: "token"}})
+	assert.Error(t, err)
+}
+
+func TestIDs(t *testing.T) {
+	assert.Equal(t, []string{DefaultID, "acme"}, IDs([]config.TenantConfig{{ID: "acme"}}))
+}
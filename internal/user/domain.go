@@ -6,10 +6,13 @@ import (
 	"nudgebot-api/internal/common"
 )
 
-// User represents a user in the system
+// User represents a user in the system. A Telegram user talking to several
+// bots is a separate user for each bot; Bot is empty for the default bot.
 type User struct {
 	ID         common.UserID `gorm:"type:varchar(36);primaryKey" json:"id"`
-	TelegramID int64         `gorm:"uniqueIndex;not null" json:"telegram_id"`
+	TenantID   string        `gorm:"type:varchar(64);not null;default:'default';index" json:"tenant_id,omitempty"`
+	Bot        string        `gorm:"type:varchar(64);not null;default:'';uniqueIndex:idx_users_bot_telegram_id" json:"bot,omitempty"`
+	TelegramID int64         `gorm:"uniqueIndex:idx_users_bot_telegram_id;not null" json:"telegram_id"`
 	Username   string        `gorm:"type:varchar(255)" json:"username"`
 	FirstName  string        `gorm:"type:varchar(255)" json:"first_name,omitempty"`
 	LastName   string        `gorm:"type:varchar(255)" json:"last_name,omitempty"`
@@ -0,0 +1,144 @@
+package user
+
+import (
+	"container/list"
+	"sync"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/tenant"
+
+	"go.uber.org/zap"
+)
+
+// provisionedCacheSize is how many written profiles are remembered; the least
+// recently seen are forgotten first and simply written again on next contact
+const provisionedCacheSize = 10000
+
+// Provisioner creates and refreshes users from the profiles seen in Telegram updates
+type Provisioner interface {
+	Provision(profile *User) error
+}
+
+// provisioner implements Provisioner, writing only new or changed profiles
+type provisioner struct {
+	eventBus   events.EventBus
+	logger     *zap.Logger
+	repository Repository
+	tenants    map[string]bool
+
+	mu       sync.Mutex
+	capacity int
+	seen     map[common.UserID]*list.Element
+	recent   *list.List // of User, most recently seen first
+}
+
+// NewProvisioner creates a Provisioner that publishes UserRegistered for
+// users it creates
+func NewProvisioner(eventBus events.EventBus, logger *zap.Logger, repository Repository) Provisioner {
+	return NewProvisionerWithTenants(eventBus, logger, repository, nil)
+}
+
+// NewProvisionerWithTenants creates a Provisioner that files the users of a
+// tenant's bot under that tenant, and everyone else under tenant.DefaultID.
+// tenantIDs are the tenants' IDs, which name their bots; nil puts every user
+// in the default tenant.
+func NewProvisionerWithTenants(eventBus events.EventBus, logger *zap.Logger, repository Repository, tenantIDs []string) Provisioner {
+	tenants := make(map[string]bool, len(tenantIDs))
+	for _, id := range tenantIDs {
+		tenants[id] = true
+	}
+
+	return &provisioner{
+		eventBus:   eventBus,
+		logger:     logger,
+		repository: repository,
+		tenants:    tenants,
+		capacity:   provisionedCacheSize,
+		seen:       make(map[common.UserID]*list.Element),
+		recent:     list.New(),
+	}
+}
+
+// Provision upserts the user on first contact and whenever the profile changes
+func (p *provisioner) Provision(profile *User) error {
+	profile.TenantID = p.tenantFor(profile.Bot)
+	if p.unchanged(profile) {
+		return nil
+	}
+
+	created, err := p.repository.Upsert(profile)
+	if err != nil {
+		return err
+	}
+	p.remember(profile)
+
+	if !created {
+		p.logger.Debug("User profile updated", zap.String("userID", string(profile.ID)))
+		return nil
+	}
+
+	p.logger.Info("User registered",
+		zap.String("userID", string(profile.ID)),
+		zap.Int64("telegramID", profile.TelegramID),
+		zap.String("bot", profile.Bot),
+		zap.String("tenant", profile.TenantID))
+
+	event := events.UserRegistered{
+		Event:      events.NewEvent(),
+		UserID:     string(profile.ID),
+		TelegramID: profile.TelegramID,
+		Bot:        profile.Bot,
+		Username:   profile.Username,
+		FirstName:  profile.FirstName,
+		LastName:   profile.LastName,
+	}
+	if err := p.eventBus.Publish(events.TopicUserRegistered, event); err != nil {
+		p.logger.Error("Failed to publish UserRegistered event", zap.Error(err))
+	}
+	return nil
+}
+
+// tenantFor returns the tenant of the users of a bot
+func (p *provisioner) tenantFor(bot string) string {
+	if p.tenants[bot] {
+		return bot
+	}
+	return tenant.DefaultID
+}
+
+// unchanged reports whether the profile matches the one last written
+func (p *provisioner) unchanged(profile *User) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	element, ok := p.seen[profile.ID]
+	if !ok {
+		return false
+	}
+	p.recent.MoveToFront(element)
+
+	seen := element.Value.(User)
+	return seen.TenantID == profile.TenantID && seen.Username == profile.Username &&
+		seen.FirstName == profile.FirstName && seen.LastName == profile.LastName
+}
+
+// remember records the written profile, forgetting the least recently seen
+// one when the cache is full
+func (p *provisioner) remember(profile *User) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if element, ok := p.seen[profile.ID]; ok {
+		element.Value = *profile
+		p.recent.MoveToFront(element)
+		return
+	}
+
+	p.seen[profile.ID] = p.recent.PushFront(*profile)
+	if p.recent.Len() > p.capacity {
+		oldest := p.recent.Back()
+		p.recent.Remove(oldest)
+		delete(p.seen, oldest.Value.(User).ID)
+	}
+}
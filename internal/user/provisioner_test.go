@@ -0,0 +1,111 @@
+package user
+
+import (
+	"errors"
+	"testing"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// countingRepository counts upserts and can fail them
+type countingRepository struct {
+	Repository
+	upserts int
+	err     error
+}
+
+func (r *countingRepository) Upsert(user *User) (bool, error) {
+	r.upserts++
+	if r.err != nil {
+		return false, r.err
+	}
+	return r.Repository.Upsert(user)
+}
+
+func TestProvisioner_RegistersUsersOnFirstContact(t *testing.T) {
+	eventBus := events.NewMockEventBus()
+	repo := &countingRepository{Repository: NewMemoryRepository()}
+	provisioner := NewProvisioner(eventBus, zaptest.NewLogger(t), repo)
+
+	userID := common.UserID(common.NewID())
+	require.NoError(t, provisioner.Provision(&User{ID: userID, TelegramID: 42, Username: "ada", FirstName: "Ada"}))
+
+	published := eventBus.GetPublishedEvents(events.TopicUserRegistered)
+	require.Len(t, published, 1)
+	registered := published[0].(events.UserRegistered)
+	assert.Equal(t, string(userID), registered.UserID)
+	assert.Equal(t, int64(42), registered.TelegramID)
+	assert.Equal(t, "ada", registered.Username)
+
+	// An unchanged profile is not written again
+	require.NoError(t, provisioner.Provision(&User{ID: userID, TelegramID: 42, Username: "ada", FirstName: "Ada"}))
+	assert.Equal(t, 1, repo.upserts)
+
+	// A changed profile is updated without registering the user again
+	require.NoError(t, provisioner.Provision(&User{ID: userID, TelegramID: 42, Username: "ada_l", FirstName: "Ada"}))
+	assert.Equal(t, 2, repo.upserts)
+	assert.Len(t, eventBus.GetPublishedEvents(events.TopicUserRegistered), 1)
+
+	stored, err := repo.GetByID(userID)
+	require.NoError(t, err)
+	assert.Equal(t, "ada_l", stored.Username)
+}
+
+func TestProvisioner_RetriesAfterFailedWrite(t *testing.T) {
+	eventBus := events.NewMockEventBus()
+	repo := &countingRepository{Repository: NewMemoryRepository(), err: errors.New("database down")}
+	provisioner := NewProvisioner(eventBus, zaptest.NewLogger(t), repo)
+
+	profile := &User{ID: common.UserID(common.NewID()), TelegramID: 7}
+	assert.Error(t, provisioner.Provision(profile))
+
+	repo.err = nil
+	require.NoError(t, provisioner.Provision(profile))
+	assert.Equal(t, 2, repo.upserts)
+	assert.Len(t, eventBus.GetPublishedEvents(events.TopicUserRegistered), 1)
+}
+
+func TestProvisioner_AssignsTenantFromBot(t *testing.T) {
+	repo := NewMemoryRepository()
+	provisioner := NewProvisionerWithTenants(events.NewMockEventBus(), zaptest.NewLogger(t), repo, []string{"acme"})
+
+	tenantUser := common.UserID(common.NewID())
+	defaultUser := common.UserID(common.NewID())
+	require.NoError(t, provisioner.Provision(&User{ID: tenantUser, Bot: "acme", TelegramID: 1}))
+	require.NoError(t, provisioner.Provision(&User{ID: defaultUser, Bot: "work_bot", TelegramID: 1}))
+
+	stored, err := repo.GetByID(tenantUser)
+	require.NoError(t, err)
+	assert.Equal(t, "acme", stored.TenantID)
+
+	stored, err = repo.GetByID(defaultUser)
+	require.NoError(t, err)
+	assert.Equal(t, "default", stored.TenantID)
+}
+
+func TestProvisioner_ForgetsLeastRecentlySeenProfiles(t *testing.T) {
+	repo := &countingRepository{Repository: NewMemoryRepository()}
+	provisioner := NewProvisioner(events.NewMockEventBus(), zaptest.NewLogger(t), repo).(*provisioner)
+	provisioner.capacity = 2
+
+	first := &User{ID: common.UserID(common.NewID()), TelegramID: 1}
+	second := &User{ID: common.UserID(common.NewID()), TelegramID: 2}
+	third := &User{ID: common.UserID(common.NewID()), TelegramID: 3}
+	require.NoError(t, provisioner.Provision(first))
+	require.NoError(t, provisioner.Provision(second))
+	require.NoError(t, provisioner.Provision(first))
+	require.NoError(t, provisioner.Provision(third))
+	assert.Equal(t, 3, repo.upserts)
+	assert.Len(t, provisioner.seen, 2)
+
+	// second was seen least recently, so it was dropped and is written again
+	require.NoError(t, provisioner.Provision(first))
+	assert.Equal(t, 3, repo.upserts)
+	require.NoError(t, provisioner.Provision(second))
+	assert.Equal(t, 4, repo.upserts)
+}
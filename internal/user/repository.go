@@ -0,0 +1,171 @@
+package user
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"nudgebot-api/internal/common"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository stores user profiles
+type Repository interface {
+	GetByID(userID common.UserID) (*User, error)
+	// FindByUsername returns the bot's user with the Telegram username,
+	// ignoring case, or nil when no such user has contacted the bot
+	FindByUsername(bot, username string) (*User, error)
+	// Upsert creates the user or updates its profile, reporting whether it was created
+	Upsert(user *User) (bool, error)
+	// TelegramIDs returns the Telegram user IDs of everyone who contacted the bot
+	TelegramIDs(bot string) ([]int64, error)
+}
+
+// profileColumns are the columns refreshed when a known user is seen again
+var profileColumns = []string{"tenant_id", "username", "first_name", "last_name", "updated_at"}
+
+// gormRepository implements Repository using GORM
+type gormRepository struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+// NewGormRepository creates a GORM-based user repository
+func NewGormRepository(db *gorm.DB, logger *zap.Logger) Repository {
+	return &gormRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetByID retrieves a user, returning nil when the user is unknown
+func (r *gormRepository) GetByID(userID common.UserID) (*User, error) {
+	var users []*User
+	if err := r.db.Where("id = ?", userID).Limit(1).Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if len(users) == 0 {
+		return nil, nil
+	}
+	return users[0], nil
+}
+
+// FindByUsername retrieves the bot's user with the username, ignoring case
+func (r *gormRepository) FindByUsername(bot, username string) (*User, error) {
+	var users []*User
+	err := r.db.Where("bot = ? AND LOWER(username) = LOWER(?)", bot, username).Limit(1).Find(&users).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user by username: %w", err)
+	}
+	if len(users) == 0 {
+		return nil, nil
+	}
+	return users[0], nil
+}
+
+// Upsert creates the user or updates its profile
+func (r *gormRepository) Upsert(user *User) (bool, error) {
+	// Inserting first keeps concurrent first contacts from failing
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoNothing: true,
+	}).Create(user)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to create user: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		return true, nil
+	}
+
+	err := r.db.Model(&User{}).Where("id = ?", user.ID).Select(profileColumns).Updates(user).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to update user: %w", err)
+	}
+	return false, nil
+}
+
+// TelegramIDs returns the Telegram user IDs of the bot's users, oldest first
+func (r *gormRepository) TelegramIDs(bot string) ([]int64, error) {
+	var telegramIDs []int64
+	err := r.db.Model(&User{}).Where("bot = ?", bot).Order("created_at, telegram_id").Pluck("telegram_id", &telegramIDs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	return telegramIDs, nil
+}
+
+// memoryRepository implements Repository in memory
+type memoryRepository struct {
+	mu    sync.RWMutex
+	users map[common.UserID]User
+}
+
+// NewMemoryRepository creates a user repository that is not persisted
+func NewMemoryRepository() Repository {
+	return &memoryRepository{
+		users: make(map[common.UserID]User),
+	}
+}
+
+// GetByID retrieves a user, returning nil when the user is unknown
+func (r *memoryRepository) GetByID(userID common.UserID) (*User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, ok := r.users[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &user, nil
+}
+
+// FindByUsername retrieves the bot's user with the username, ignoring case
+func (r *memoryRepository) FindByUsername(bot, username string) (*User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, user := range r.users {
+		if user.Bot == bot && user.Username != "" && strings.EqualFold(user.Username, username) {
+			return &user, nil
+		}
+	}
+	return nil, nil
+}
+
+// Upsert creates the user or updates its profile
+func (r *memoryRepository) Upsert(user *User) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.users[user.ID]
+	if !ok {
+		r.users[user.ID] = *user
+		return true, nil
+	}
+
+	existing.TenantID = user.TenantID
+	existing.Username = user.Username
+	existing.FirstName = user.FirstName
+	existing.LastName = user.LastName
+	r.users[user.ID] = existing
+	return false, nil
+}
+
+// TelegramIDs returns the Telegram user IDs of the bot's users, in ID order
+func (r *memoryRepository) TelegramIDs(bot string) ([]int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var telegramIDs []int64
+	for _, user := range r.users {
+		if user.Bot == bot {
+			telegramIDs = append(telegramIDs, user.TelegramID)
+		}
+	}
+	sort.Slice(telegramIDs, func(i, j int) bool { return telegramIDs[i] < telegramIDs[j] })
+	return telegramIDs, nil
+}
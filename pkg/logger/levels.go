@@ -0,0 +1,176 @@
+package logger
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// Levels holds the global log level and per-module overrides. It can be
+// changed while the service runs.
+type Levels struct {
+	mu      sync.RWMutex
+	global  zapcore.Level
+	modules map[string]zapcore.Level
+	min     zapcore.Level // lowest level any module logs at
+}
+
+// LevelsSnapshot is the current level configuration
+type LevelsSnapshot struct {
+	Level   string            `json:"level"`
+	Modules map[string]string `json:"modules"`
+}
+
+// NewLevels creates levels from their names, as in configuration
+func NewLevels(level string, modules map[string]string) (*Levels, error) {
+	l := &Levels{modules: make(map[string]zapcore.Level)}
+	if level == "" {
+		level = "info"
+	}
+	if err := l.Update(level, modules); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Update sets the global level, unless it is empty, and the given module
+// levels. An empty module level removes the override. Nothing changes when
+// any level is invalid.
+func (l *Levels) Update(level string, modules map[string]string) error {
+	var global *zapcore.Level
+	if level != "" {
+		parsed, err := parseLevel(level)
+		if err != nil {
+			return err
+		}
+		global = &parsed
+	}
+
+	overrides := make(map[string]*zapcore.Level, len(modules))
+	for module, moduleLevel := range modules {
+		if module == "" {
+			return fmt.Errorf("module name is empty")
+		}
+		if moduleLevel == "" {
+			overrides[module] = nil
+			continue
+		}
+		parsed, err := parseLevel(moduleLevel)
+		if err != nil {
+			return fmt.Errorf("module %s: %w", module, err)
+		}
+		overrides[module] = &parsed
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if global != nil {
+		l.global = *global
+	}
+	for module, moduleLevel := range overrides {
+		if moduleLevel == nil {
+			delete(l.modules, strings.ToLower(module))
+		} else {
+			l.modules[strings.ToLower(module)] = *moduleLevel
+		}
+	}
+
+	l.min = l.global
+	for _, moduleLevel := range l.modules {
+		if moduleLevel < l.min {
+			l.min = moduleLevel
+		}
+	}
+	return nil
+}
+
+// Enabled reports whether an entry of the module at the level is logged
+func (l *Levels) Enabled(module string, level zapcore.Level) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if moduleLevel, ok := l.modules[module]; ok {
+		return level >= moduleLevel
+	}
+	return level >= l.global
+}
+
+// minEnabled reports whether any module logs at the level
+func (l *Levels) minEnabled(level zapcore.Level) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return level >= l.min
+}
+
+// Snapshot returns the current levels
+func (l *Levels) Snapshot() LevelsSnapshot {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	snapshot := LevelsSnapshot{
+		Level:   l.global.String(),
+		Modules: make(map[string]string, len(l.modules)),
+	}
+	for module, level := range l.modules {
+		snapshot.Modules[module] = level.String()
+	}
+	return snapshot
+}
+
+func parseLevel(level string) (zapcore.Level, error) {
+	parsed, err := zapcore.ParseLevel(strings.ToLower(level))
+	if err != nil || parsed > zapcore.ErrorLevel {
+		return 0, fmt.Errorf("unknown log level %q, use debug, info, warn or error", level)
+	}
+	return parsed, nil
+}
+
+// moduleOf names the module an entry belongs to: the first segment of the
+// logger's name if it has one, otherwise the package directory of the caller
+func moduleOf(entry zapcore.Entry) string {
+	if entry.LoggerName != "" {
+		return strings.ToLower(strings.SplitN(entry.LoggerName, ".", 2)[0])
+	}
+	if !entry.Caller.Defined {
+		return ""
+	}
+	return strings.ToLower(filepath.Base(filepath.Dir(entry.Caller.File)))
+}
+
+// levelCore filters entries by the level of their module. The caller is only
+// known once an entry is written, so entries above the lowest configured level
+// pass Check and are filtered in Write.
+type levelCore struct {
+	zapcore.Core
+	levels *Levels
+}
+
+func newLevelCore(core zapcore.Core, levels *Levels) zapcore.Core {
+	return &levelCore{Core: core, levels: levels}
+}
+
+func (c *levelCore) Enabled(level zapcore.Level) bool {
+	return c.levels.minEnabled(level)
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), levels: c.levels}
+}
+
+func (c *levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *levelCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	if !c.levels.Enabled(moduleOf(entry), entry.Level) {
+		return nil
+	}
+	return c.Core.Write(entry, fields)
+}
@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func newBufferedLogger(t *testing.T, levels *Levels) (*zap.Logger, *bytes.Buffer) {
+	t.Helper()
+	var buffer bytes.Buffer
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(&buffer), zapcore.DebugLevel)
+	return zap.New(newLevelCore(core, levels), zap.AddCaller()), &buffer
+}
+
+func TestLevels_ModuleOverride(t *testing.T) {
+	levels, err := NewLevels("warn", map[string]string{"nudge": "debug"})
+	require.NoError(t, err)
+	log, buffer := newBufferedLogger(t, levels)
+
+	log.Named("nudge").Debug("nudge debug")
+	log.Named("chatbot").Info("chatbot info")
+	log.Named("chatbot").Warn("chatbot warn")
+
+	assert.Contains(t, buffer.String(), "nudge debug")
+	assert.NotContains(t, buffer.String(), "chatbot info")
+	assert.Contains(t, buffer.String(), "chatbot warn")
+}
+
+func TestLevels_ModuleFromCallerPackage(t *testing.T) {
+	levels, err := NewLevels("error", map[string]string{"logger": "info"})
+	require.NoError(t, err)
+	log, buffer := newBufferedLogger(t, levels)
+
+	// Unnamed loggers take the module from the caller's directory, pkg/logger
+	log.Info("logged by the logger package")
+
+	assert.Contains(t, buffer.String(), "logged by the logger package")
+}
+
+func TestLevels_Update(t *testing.T) {
+	levels, err := NewLevels("info", map[string]string{"nudge": "debug"})
+	require.NoError(t, err)
+	log, buffer := newBufferedLogger(t, levels)
+
+	require.NoError(t, levels.Update("error", map[string]string{"nudge": "", "scheduler": "warn"}))
+	assert.Equal(t, LevelsSnapshot{Level: "error", Modules: map[string]string{"scheduler": "warn"}}, levels.Snapshot())
+
+	log.Named("nudge").Warn("nudge warn")
+	log.Named("scheduler").Warn("scheduler warn")
+	assert.NotContains(t, buffer.String(), "nudge warn")
+	assert.Contains(t, buffer.String(), "scheduler warn")
+}
+
+func TestLevels_InvalidUpdateChangesNothing(t *testing.T) {
+	levels, err := NewLevels("info", nil)
+	require.NoError(t, err)
+
+	assert.Error(t, levels.Update("debug", map[string]string{"nudge": "verbose"}))
+	assert.Error(t, levels.Update("fatal", nil))
+	assert.Equal(t, LevelsSnapshot{Level: "info", Modules: map[string]string{}}, levels.Snapshot())
+
+	_, err = NewLevels("loud", nil)
+	assert.Error(t, err)
+}
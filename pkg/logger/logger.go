@@ -1,33 +1,109 @@
 package logger
 
 import (
-    "go.uber.org/zap"
-    "go.uber.org/zap/zapcore"
+	"fmt"
+	"os"
+	"time"
+
+	"nudgebot-api/internal/config"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 type Logger struct {
-    *zap.SugaredLogger
+	*zap.SugaredLogger
+
+	levels *Levels
 }
 
 func New() *Logger {
-    config := zap.NewProductionConfig()
-    config.OutputPaths = []string{"stdout"}
-    config.ErrorOutputPaths = []string{"stderr"}
-    config.EncoderConfig.TimeKey = "timestamp"
-    config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
-
-    logger, err := config.Build()
-    if err != nil {
-        panic(err)
-    }
-
-    return &Logger{
-        SugaredLogger: logger.Sugar(),
-    }
+	config := zap.NewProductionConfig()
+	config.OutputPaths = []string{"stdout"}
+	config.ErrorOutputPaths = []string{"stderr"}
+	config.EncoderConfig.TimeKey = "timestamp"
+	config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+
+	logger, err := config.Build()
+	if err != nil {
+		panic(err)
+	}
+
+	return &Logger{
+		SugaredLogger: logger.Sugar(),
+	}
+}
+
+// NewFromConfig creates a logger writing to the configured sinks, filtered by
+// levels that can be changed at runtime through Levels
+func NewFromConfig(cfg config.LoggingConfig) (*Logger, error) {
+	levels, err := NewLevels(cfg.Level, cfg.Modules)
+	if err != nil {
+		return nil, err
+	}
+
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.TimeKey = "timestamp"
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+
+	var cores []zapcore.Core
+	if cfg.Stdout {
+		cores = append(cores, zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), zapcore.Lock(os.Stdout), zapcore.DebugLevel))
+	}
+
+	if cfg.File.Enabled {
+		file, err := newRotatingFile(cfg.File.Path, cfg.File.MaxSizeMB, cfg.File.MaxBackups, cfg.File.MaxAgeDays, cfg.File.Compress)
+		if err != nil {
+			return nil, err
+		}
+		cores = append(cores, zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), file, zapcore.DebugLevel))
+	}
+
+	if cfg.Syslog.Enabled {
+		// Syslog stamps messages itself
+		syslogEncoderConfig := encoderConfig
+		syslogEncoderConfig.TimeKey = ""
+		core, err := newSyslogCore(zapcore.NewJSONEncoder(syslogEncoderConfig), cfg.Syslog.Network, cfg.Syslog.Address, cfg.Syslog.Tag)
+		if err != nil {
+			return nil, err
+		}
+		cores = append(cores, core)
+	}
+
+	if cfg.OTLP.Enabled {
+		exporter, err := newOTLPExporter(cfg.OTLP.Endpoint, cfg.OTLP.Headers, cfg.OTLP.ServiceName, cfg.OTLP.BatchSize, cfg.OTLP.FlushIntervalMs, cfg.OTLP.QueueSize)
+		if err != nil {
+			return nil, err
+		}
+		cores = append(cores, newOTLPCore(exporter))
+	}
+
+	if len(cores) == 0 {
+		return nil, fmt.Errorf("no log sink is enabled")
+	}
+
+	// Sample repeated entries like the production configuration of New does
+	core := zapcore.NewSamplerWithOptions(newLevelCore(zapcore.NewTee(cores...), levels), time.Second, 100, 100)
+	logger := zap.New(core,
+		zap.AddCaller(),
+		zap.AddStacktrace(zapcore.ErrorLevel),
+		zap.ErrorOutput(zapcore.Lock(os.Stderr)))
+
+	return &Logger{
+		SugaredLogger: logger.Sugar(),
+		levels:        levels,
+	}, nil
+}
+
+// Levels returns the runtime-adjustable levels, or nil when the logger was not
+// created from configuration
+func (l *Logger) Levels() *Levels {
+	return l.levels
 }
 
 func (l *Logger) WithRequestID(requestID string) *Logger {
-    return &Logger{
-        SugaredLogger: l.SugaredLogger.With("request_id", requestID),
-    }
-}
\ No newline at end of file
+	return &Logger{
+		SugaredLogger: l.SugaredLogger.With("request_id", requestID),
+		levels:        l.levels,
+	}
+}
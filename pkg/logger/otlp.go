@@ -0,0 +1,302 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// otlpExporter batches log records and posts them to an OTLP/HTTP collector as
+// JSON. Records are queued without blocking the caller; when the queue is
+// full they are dropped and counted.
+type otlpExporter struct {
+	endpoint      string
+	headers       map[string]string
+	serviceName   string
+	batchSize     int
+	flushInterval time.Duration
+	client        *http.Client
+
+	queue   chan otlpRecord
+	flushes chan chan struct{}
+	mu      sync.Mutex
+	lost    int64 // records dropped because the queue was full
+}
+
+// otlpCore encodes zap entries as OTLP log records
+type otlpCore struct {
+	exporter *otlpExporter
+	fields   []zapcore.Field
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"` // int64 is a string in OTLP JSON
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpRecord struct {
+	TimeUnixNano   string          `json:"timeUnixNano"`
+	SeverityNumber int             `json:"severityNumber"`
+	SeverityText   string          `json:"severityText"`
+	Body           otlpValue       `json:"body"`
+	Attributes     []otlpAttribute `json:"attributes,omitempty"`
+}
+
+func newOTLPExporter(endpoint string, headers map[string]string, serviceName string, batchSize, flushIntervalMs, queueSize int) (*otlpExporter, error) {
+	if endpoint == "" {
+		return nil, fmt.Errorf("OTLP endpoint is empty")
+	}
+	if batchSize <= 0 {
+		batchSize = 256
+	}
+	if flushIntervalMs <= 0 {
+		flushIntervalMs = 2000
+	}
+	if queueSize <= 0 {
+		queueSize = 4096
+	}
+
+	e := &otlpExporter{
+		endpoint:      endpoint,
+		headers:       headers,
+		serviceName:   serviceName,
+		batchSize:     batchSize,
+		flushInterval: time.Duration(flushIntervalMs) * time.Millisecond,
+		client:        &http.Client{Timeout: 10 * time.Second},
+		queue:         make(chan otlpRecord, queueSize),
+		flushes:       make(chan chan struct{}),
+	}
+	go e.run()
+	return e, nil
+}
+
+// run collects queued records and exports them once a batch is full, the
+// flush interval passes or a flush is requested
+func (e *otlpExporter) run() {
+	ticker := time.NewTicker(e.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]otlpRecord, 0, e.batchSize)
+	for {
+		select {
+		case record := <-e.queue:
+			batch = append(batch, record)
+			if len(batch) >= e.batchSize {
+				e.export(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				e.export(batch)
+				batch = batch[:0]
+			}
+		case done := <-e.flushes:
+			for drained := false; !drained; {
+				select {
+				case record := <-e.queue:
+					batch = append(batch, record)
+				default:
+					drained = true
+				}
+			}
+			if len(batch) > 0 {
+				e.export(batch)
+				batch = batch[:0]
+			}
+			close(done)
+		}
+	}
+}
+
+// export posts a batch. Failures go to stderr, since logging them would feed
+// back into the exporter.
+func (e *otlpExporter) export(batch []otlpRecord) {
+	payload := map[string]interface{}{
+		"resourceLogs": []interface{}{map[string]interface{}{
+			"resource": map[string]interface{}{
+				"attributes": []otlpAttribute{stringAttribute("service.name", e.serviceName)},
+			},
+			"scopeLogs": []interface{}{map[string]interface{}{
+				"scope":      map[string]string{"name": "nudgebot-api/pkg/logger"},
+				"logRecords": batch,
+			}},
+		}},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode OTLP logs: %v\n", err)
+		return
+	}
+
+	request, err := http.NewRequest(http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create OTLP request: %v\n", err)
+		return
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for name, value := range e.headers {
+		request.Header.Set(name, value)
+	}
+
+	response, err := e.client.Do(request)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to export %d log records: %v\n", len(batch), err)
+		return
+	}
+	response.Body.Close()
+	if response.StatusCode >= 300 {
+		fmt.Fprintf(os.Stderr, "failed to export %d log records: collector answered %s\n", len(batch), response.Status)
+	}
+}
+
+// enqueue queues a record, dropping it if the exporter is not keeping up
+func (e *otlpExporter) enqueue(record otlpRecord) {
+	select {
+	case e.queue <- record:
+	default:
+		e.mu.Lock()
+		e.lost++
+		lost := e.lost
+		e.mu.Unlock()
+		if lost == 1 || lost%1000 == 0 {
+			fmt.Fprintf(os.Stderr, "OTLP log queue is full, %d records dropped so far\n", lost)
+		}
+	}
+}
+
+// flush exports everything queued so far
+func (e *otlpExporter) flush() {
+	done := make(chan struct{})
+	e.flushes <- done
+	<-done
+}
+
+func newOTLPCore(exporter *otlpExporter) zapcore.Core {
+	return &otlpCore{exporter: exporter}
+}
+
+func (c *otlpCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+func (c *otlpCore) With(fields []zapcore.Field) zapcore.Core {
+	combined := make([]zapcore.Field, 0, len(c.fields)+len(fields))
+	combined = append(combined, c.fields...)
+	combined = append(combined, fields...)
+	return &otlpCore{exporter: c.exporter, fields: combined}
+}
+
+func (c *otlpCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return checked.AddCore(entry, c)
+}
+
+func (c *otlpCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	encoder := zapcore.NewMapObjectEncoder()
+	for _, field := range c.fields {
+		field.AddTo(encoder)
+	}
+	for _, field := range fields {
+		field.AddTo(encoder)
+	}
+
+	attributes := make([]otlpAttribute, 0, len(encoder.Fields)+3)
+	for key, value := range encoder.Fields {
+		attributes = append(attributes, otlpAttribute{Key: key, Value: toOTLPValue(value)})
+	}
+	if module := moduleOf(entry); module != "" {
+		attributes = append(attributes, stringAttribute("module", module))
+	}
+	if entry.Caller.Defined {
+		attributes = append(attributes,
+			stringAttribute("code.filepath", entry.Caller.File),
+			otlpAttribute{Key: "code.lineno", Value: toOTLPValue(int64(entry.Caller.Line))})
+	}
+
+	message := entry.Message
+	c.exporter.enqueue(otlpRecord{
+		TimeUnixNano:   strconv.FormatInt(entry.Time.UnixNano(), 10),
+		SeverityNumber: severityNumber(entry.Level),
+		SeverityText:   entry.Level.CapitalString(),
+		Body:           otlpValue{StringValue: &message},
+		Attributes:     attributes,
+	})
+
+	// The process may be about to exit, so send what is queued now
+	if entry.Level > zapcore.ErrorLevel {
+		c.exporter.flush()
+	}
+	return nil
+}
+
+func (c *otlpCore) Sync() error {
+	c.exporter.flush()
+	return nil
+}
+
+// severityNumber maps zap levels to OpenTelemetry severity numbers
+func severityNumber(level zapcore.Level) int {
+	switch level {
+	case zapcore.DebugLevel:
+		return 5
+	case zapcore.InfoLevel:
+		return 9
+	case zapcore.WarnLevel:
+		return 13
+	case zapcore.ErrorLevel:
+		return 17
+	case zapcore.DPanicLevel:
+		return 18
+	default:
+		return 21
+	}
+}
+
+func stringAttribute(key, value string) otlpAttribute {
+	return otlpAttribute{Key: key, Value: otlpValue{StringValue: &value}}
+}
+
+func toOTLPValue(value interface{}) otlpValue {
+	switch v := value.(type) {
+	case string:
+		return otlpValue{StringValue: &v}
+	case bool:
+		return otlpValue{BoolValue: &v}
+	case int, int8, int16, int32, int64, uint8, uint16, uint32:
+		text := fmt.Sprint(v)
+		return otlpValue{IntValue: &text}
+	case time.Duration:
+		text := v.String()
+		return otlpValue{StringValue: &text}
+	case float32:
+		f := float64(v)
+		return toOTLPValue(f)
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			text := fmt.Sprint(v)
+			return otlpValue{StringValue: &text}
+		}
+		return otlpValue{DoubleValue: &v}
+	default:
+		text := fmt.Sprint(v)
+		if encoded, err := json.Marshal(v); err == nil {
+			text = string(encoded)
+		}
+		return otlpValue{StringValue: &text}
+	}
+}
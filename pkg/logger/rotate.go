@@ -0,0 +1,220 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rotatedTimeFormat is appended to the name of rotated files
+const rotatedTimeFormat = "2006-01-02T15-04-05.000"
+
+// rotatingFile is a log file that is renamed once it reaches maxSize, keeping
+// older files as name-<time>.ext, optionally gzipped
+type rotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	maxAge     time.Duration
+	compress   bool
+	now        func() time.Time
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+
+	// cleanup compresses and removes rotated files one rotation at a time
+	cleanup sync.Mutex
+}
+
+func newRotatingFile(path string, maxSizeMB, maxBackups, maxAgeDays int, compress bool) (*rotatingFile, error) {
+	if path == "" {
+		return nil, fmt.Errorf("log file path is empty")
+	}
+	if maxSizeMB <= 0 {
+		maxSizeMB = 100
+	}
+
+	r := &rotatingFile{
+		path:       path,
+		maxSize:    int64(maxSizeMB) * 1024 * 1024,
+		maxBackups: maxBackups,
+		maxAge:     time.Duration(maxAgeDays) * 24 * time.Hour,
+		compress:   compress,
+		now:        time.Now,
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+// Write appends to the file, rotating first if the write would exceed maxSize
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Sync flushes the file to disk
+func (r *rotatingFile) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Sync()
+}
+
+// Close closes the current file
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
+
+// rotate renames the current file and opens a new one; the caller holds mu
+func (r *rotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %w", err)
+	}
+
+	now := r.now()
+	extension := filepath.Ext(r.path)
+	rotated := fmt.Sprintf("%s-%s%s", strings.TrimSuffix(r.path, extension), now.UTC().Format(rotatedTimeFormat), extension)
+	if err := os.Rename(r.path, rotated); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	if err := r.open(); err != nil {
+		return err
+	}
+
+	go r.cleanupRotated(rotated, now)
+	return nil
+}
+
+// cleanupRotated compresses the newly rotated file and removes rotated files
+// beyond maxBackups or older than maxAge at the time of rotation. Failures are
+// reported on stderr, as the logger itself is what failed.
+func (r *rotatingFile) cleanupRotated(rotated string, now time.Time) {
+	r.cleanup.Lock()
+	defer r.cleanup.Unlock()
+
+	if r.compress {
+		if err := gzipFile(rotated); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to compress rotated log %s: %v\n", rotated, err)
+		}
+	}
+
+	backups, err := r.rotatedFiles()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to list rotated logs: %v\n", err)
+		return
+	}
+
+	cutoff := now.Add(-r.maxAge)
+	for i, backup := range backups {
+		expired := r.maxAge > 0 && backup.ModTime().Before(cutoff)
+		excess := r.maxBackups > 0 && i >= r.maxBackups
+		if !expired && !excess {
+			continue
+		}
+		if err := os.Remove(filepath.Join(filepath.Dir(r.path), backup.Name())); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to remove rotated log %s: %v\n", backup.Name(), err)
+		}
+	}
+}
+
+// rotatedFiles returns the rotated files of the log, newest first
+func (r *rotatingFile) rotatedFiles() ([]os.FileInfo, error) {
+	entries, err := os.ReadDir(filepath.Dir(r.path))
+	if err != nil {
+		return nil, err
+	}
+
+	base := filepath.Base(r.path)
+	extension := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, extension) + "-"
+
+	var backups []os.FileInfo
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || name == base {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".gz"), extension)
+		if _, err := time.Parse(rotatedTimeFormat, stamp); err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, info)
+	}
+
+	// The timestamp in the name sorts chronologically
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Name() > backups[j].Name()
+	})
+	return backups, nil
+}
+
+func gzipFile(path string) error {
+	source, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	target, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	writer := gzip.NewWriter(target)
+	if _, err := io.Copy(writer, source); err != nil {
+		target.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		target.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := target.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"nudgebot-api/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFile_RotatesAndPrunes(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	file, err := newRotatingFile(path, 1, 2, 0, false)
+	require.NoError(t, err)
+	defer file.Close()
+	file.maxSize = 10
+
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	file.now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+
+	for i := 0; i < 4; i++ {
+		_, err := file.Write([]byte("0123456789"))
+		require.NoError(t, err)
+	}
+
+	// Pruning runs in the background after a rotation
+	assert.Eventually(t, func() bool {
+		backups, err := file.rotatedFiles()
+		return err == nil && len(backups) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	current, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "0123456789", string(current))
+}
+
+func TestRotatingFile_Compresses(t *testing.T) {
+	dir := t.TempDir()
+	file, err := newRotatingFile(filepath.Join(dir, "app.log"), 1, 0, 0, true)
+	require.NoError(t, err)
+	defer file.Close()
+	file.maxSize = 10
+
+	_, err = file.Write([]byte("0123456789"))
+	require.NoError(t, err)
+	_, err = file.Write([]byte("next"))
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		matches, _ := filepath.Glob(filepath.Join(dir, "app-*.log.gz"))
+		plain, _ := filepath.Glob(filepath.Join(dir, "app-*.log"))
+		return len(matches) == 1 && len(plain) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestNewFromConfig_ExportsOTLP(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+		assert.Equal(t, "secret", r.Header.Get("Authorization"))
+	}))
+	defer collector.Close()
+
+	log, err := NewFromConfig(config.LoggingConfig{
+		Level: "info",
+		OTLP: config.OTLPLogConfig{
+			Enabled:     true,
+			Endpoint:    collector.URL,
+			Headers:     map[string]string{"Authorization": "secret"},
+			ServiceName: "nudgebot-test",
+		},
+	})
+	require.NoError(t, err)
+
+	log.Infow("task created", "task_id", "t1", "attempt", 2)
+	log.Debug("not exported")
+	require.NoError(t, log.Sync())
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, bodies, 1)
+
+	var payload struct {
+		ResourceLogs []struct {
+			ScopeLogs []struct {
+				LogRecords []otlpRecord `json:"logRecords"`
+			} `json:"scopeLogs"`
+		} `json:"resourceLogs"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(bodies[0]), &payload))
+	records := payload.ResourceLogs[0].ScopeLogs[0].LogRecords
+	require.Len(t, records, 1)
+	assert.Equal(t, "task created", *records[0].Body.StringValue)
+	assert.Equal(t, 9, records[0].SeverityNumber)
+	assert.Contains(t, bodies[0], `"service.name"`)
+
+	attributes := map[string]otlpValue{}
+	for _, attribute := range records[0].Attributes {
+		attributes[attribute.Key] = attribute.Value
+	}
+	assert.Equal(t, "t1", *attributes["task_id"].StringValue)
+	assert.Equal(t, "2", *attributes["attempt"].IntValue)
+	assert.Equal(t, "logger", *attributes["module"].StringValue)
+	assert.True(t, strings.HasSuffix(*attributes["code.filepath"].StringValue, "sinks_test.go"))
+}
+
+func TestNewFromConfig_RequiresASink(t *testing.T) {
+	_, err := NewFromConfig(config.LoggingConfig{Level: "info"})
+	assert.Error(t, err)
+}
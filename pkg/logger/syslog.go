@@ -0,0 +1,67 @@
+//go:build !windows && !plan9
+
+package logger
+
+import (
+	"fmt"
+	"log/syslog"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// syslogCore writes each entry to syslog at the priority matching its level
+type syslogCore struct {
+	encoder zapcore.Encoder
+	writer  *syslog.Writer
+}
+
+func newSyslogCore(encoder zapcore.Encoder, network, address, tag string) (zapcore.Core, error) {
+	writer, err := syslog.Dial(network, address, syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+	return &syslogCore{encoder: encoder, writer: writer}, nil
+}
+
+func (c *syslogCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+func (c *syslogCore) With(fields []zapcore.Field) zapcore.Core {
+	encoder := c.encoder.Clone()
+	for _, field := range fields {
+		field.AddTo(encoder)
+	}
+	return &syslogCore{encoder: encoder, writer: c.writer}
+}
+
+func (c *syslogCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	return checked.AddCore(entry, c)
+}
+
+func (c *syslogCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	buffer, err := c.encoder.EncodeEntry(entry, fields)
+	if err != nil {
+		return err
+	}
+	message := strings.TrimSuffix(buffer.String(), "\n")
+	buffer.Free()
+
+	switch entry.Level {
+	case zapcore.DebugLevel:
+		return c.writer.Debug(message)
+	case zapcore.InfoLevel:
+		return c.writer.Info(message)
+	case zapcore.WarnLevel:
+		return c.writer.Warning(message)
+	case zapcore.ErrorLevel:
+		return c.writer.Err(message)
+	default:
+		return c.writer.Crit(message)
+	}
+}
+
+func (c *syslogCore) Sync() error {
+	return nil
+}
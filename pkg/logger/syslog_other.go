@@ -0,0 +1,13 @@
+//go:build windows || plan9
+
+package logger
+
+import (
+	"fmt"
+
+	"go.uber.org/zap/zapcore"
+)
+
+func newSyslogCore(encoder zapcore.Encoder, network, address, tag string) (zapcore.Core, error) {
+	return nil, fmt.Errorf("syslog is not supported on this platform")
+}
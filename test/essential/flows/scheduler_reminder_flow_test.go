@@ -105,7 +105,7 @@ func TestSchedulerReminderFlow_DueTaskReminder(t *testing.T) {
     time.Sleep(3 * time.Second)
 
     // Stop scheduler
-    err = schedulerService.Stop()
+    err = schedulerService.Stop(context.Background())
     assert.NoError(t, err)
 
     // Verify reminder was sent via Telegram
@@ -198,7 +198,7 @@ func TestSchedulerReminderFlow_NudgeAfterOverdue(t *testing.T) {
     time.Sleep(3 * time.Second)
 
     // Stop scheduler
-    err = schedulerService.Stop()
+    err = schedulerService.Stop(context.Background())
     assert.NoError(t, err)
 
     // Verify nudge was sent
@@ -299,7 +299,7 @@ func TestSchedulerReminderFlow_UserActionAfterReminder(t *testing.T) {
     time.Sleep(3 * time.Second)
 
     // Stop scheduler
-    err = schedulerService.Stop()
+    err = schedulerService.Stop(context.Background())
     assert.NoError(t, err)
 
     // Verify no additional reminders were sent for completed task
@@ -405,7 +405,7 @@ func TestSchedulerReminderFlow_MultipleTasksAndUsers(t *testing.T) {
     time.Sleep(5 * time.Second)
 
     // Stop scheduler
-    err = schedulerService.Stop()
+    err = schedulerService.Stop(context.Background())
     assert.NoError(t, err)
 
     // Verify messages were sent
@@ -4,7 +4,6 @@ package helpers
 
 import (
 	"context"
-	"crypto/md5"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -23,6 +22,7 @@ import (
 	"go.uber.org/zap/zaptest"
 	"gorm.io/gorm"
 
+	"nudgebot-api/internal/chatbot"
 	"nudgebot-api/internal/common"
 	"nudgebot-api/internal/config"
 	"nudgebot-api/internal/database"
@@ -441,12 +441,9 @@ func CreateTestTelegramWebhook(userID, chatID, messageText string) []byte {
 	return []byte(webhookJSON)
 }
 
-// TelegramIDToUUID converts a Telegram numeric ID to a deterministic UUID for testing
+// TelegramIDToUUID returns the internal ID the default bot assigns to a Telegram ID
 func TelegramIDToUUID(telegramID int64) string {
-	// Create a deterministic UUID based on the Telegram ID
-	hash := md5.Sum([]byte(fmt.Sprintf("telegram_id_%d", telegramID)))
-	return fmt.Sprintf("%x-%x-%x-%x-%x",
-		hash[0:4], hash[4:6], hash[6:8], hash[8:10], hash[10:16])
+	return chatbot.DeriveInternalID("", telegramID)
 }
 
 // CreateTaskActionCallbackData creates callback data for task actions
@@ -0,0 +1,67 @@
+//go:build integration
+
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/nudge"
+	"nudgebot-api/test/essential/helpers"
+)
+
+// TestArchiver_MovesOldRowsAndKeepsStats archives a task completed long ago
+// and checks that it leaves the live tables but still counts in the stats
+func TestArchiver_MovesOldRowsAndKeepsStats(t *testing.T) {
+	testContainer, cleanup := helpers.SetupTestDatabase(t)
+	defer cleanup()
+	db := testContainer.DB
+
+	userID, err := helpers.CreateTestUser(db, 12345)
+	require.NoError(t, err)
+	oldTaskID, err := helpers.CreateTestTask(db, userID, "File taxes")
+	require.NoError(t, err)
+	recentTaskID, err := helpers.CreateTestTask(db, userID, "Book flights")
+	require.NoError(t, err)
+	_, err = helpers.CreateTestTask(db, userID, "Renew passport")
+	require.NoError(t, err)
+
+	longAgo := time.Now().AddDate(-2, 0, 0)
+	require.NoError(t, db.Table("tasks").Where("id = ?", oldTaskID).
+		Updates(map[string]interface{}{"status": common.TaskStatusCompleted, "completed_at": longAgo}).Error)
+	require.NoError(t, db.Table("tasks").Where("id = ?", recentTaskID).
+		Updates(map[string]interface{}{"status": common.TaskStatusCompleted, "completed_at": time.Now()}).Error)
+	require.NoError(t, helpers.CreateTestReminder(db, oldTaskID, longAgo))
+	require.NoError(t, helpers.CreateTestReminder(db, recentTaskID, time.Now().Add(time.Hour)))
+
+	// A batch size of one exercises draining over several batches
+	archiver := nudge.NewArchiver(db, zap.NewNop(), 12, 1)
+	require.NoError(t, archiver.Run(context.Background()))
+
+	var liveTasks, archivedTasks, liveReminders, archivedReminders int64
+	require.NoError(t, db.Table("tasks").Count(&liveTasks).Error)
+	require.NoError(t, db.Table(nudge.TaskArchiveTable).Count(&archivedTasks).Error)
+	require.NoError(t, db.Table("reminders").Count(&liveReminders).Error)
+	require.NoError(t, db.Table(nudge.ReminderArchiveTable).Count(&archivedReminders).Error)
+	assert.Equal(t, int64(2), liveTasks)
+	assert.Equal(t, int64(1), archivedTasks)
+	assert.Equal(t, int64(1), liveReminders, "the unsent reminder of the recent task stays")
+	assert.Equal(t, int64(1), archivedReminders)
+
+	stats, err := nudge.GetUserTaskSummary(db, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), stats.TotalTasks)
+	assert.Equal(t, int64(2), stats.CompletedTasks)
+	assert.Equal(t, int64(2), stats.ByStatus[common.TaskStatusCompleted])
+
+	// Running again finds nothing more to move
+	require.NoError(t, archiver.Run(context.Background()))
+	require.NoError(t, db.Table(nudge.TaskArchiveTable).Count(&archivedTasks).Error)
+	assert.Equal(t, int64(1), archivedTasks)
+}
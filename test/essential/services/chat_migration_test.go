@@ -0,0 +1,54 @@
+//go:build integration
+
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/events"
+	"nudgebot-api/internal/nudge"
+	"nudgebot-api/test/essential/helpers"
+)
+
+// TestChatMigration_KeepsWorkspaceRoles migrates a group to a supergroup and
+// checks that /invite in the supergroup still answers to the group's roles
+func TestChatMigration_KeepsWorkspaceRoles(t *testing.T) {
+	testContainer, cleanup := helpers.SetupTestDatabase(t)
+	defer cleanup()
+	db := testContainer.DB
+
+	logger := zap.NewNop()
+	workspaces := nudge.NewWorkspaceService(events.NewMockEventBus(), logger, nudge.NewGormWorkspaceRepository(db, logger), time.Hour)
+	repository := nudge.NewGormNudgeRepository(db, logger)
+
+	owner := common.UserID(common.NewID())
+	outsider := common.UserID(common.NewID())
+	group, supergroup := common.ChatID(common.NewID()), common.ChatID(common.NewID())
+
+	// The group's first /invite makes the inviter its owner
+	_, err := workspaces.CreateInvite(group, owner, nudge.RoleEditor)
+	require.NoError(t, err)
+
+	require.NoError(t, repository.MoveChatID(group, supergroup))
+
+	members, err := workspaces.ListMembers(supergroup)
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	assert.Equal(t, owner, members[0].UserID)
+	assert.Equal(t, nudge.RoleOwner, members[0].Role)
+
+	// Anyone else's /invite in the supergroup is refused instead of founding a workspace
+	_, err = workspaces.CreateInvite(supergroup, outsider, nudge.RoleEditor)
+	var permissionErr nudge.PermissionError
+	assert.True(t, errors.As(err, &permissionErr), "got %v", err)
+
+	_, err = workspaces.CreateInvite(supergroup, owner, nudge.RoleViewer)
+	assert.NoError(t, err)
+}
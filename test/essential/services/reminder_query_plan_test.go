@@ -0,0 +1,73 @@
+//go:build integration
+
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"nudgebot-api/internal/common"
+	"nudgebot-api/internal/nudge"
+	"nudgebot-api/test/essential/helpers"
+)
+
+// TestReminderPolling_UsesPartialIndex checks the plan of the scheduler's
+// polling query against a table where, as in production, nearly every
+// reminder was sent long ago
+func TestReminderPolling_UsesPartialIndex(t *testing.T) {
+	testContainer, cleanup := helpers.SetupTestDatabase(t)
+	defer cleanup()
+	db := testContainer.DB
+
+	userID, err := helpers.CreateTestUser(db, 12345)
+	require.NoError(t, err)
+	taskID, err := helpers.CreateTestTask(db, userID, "Water the plants")
+	require.NoError(t, err)
+
+	now := time.Now()
+	sent := make([]map[string]interface{}, 0, 5000)
+	for i := 0; i < cap(sent); i++ {
+		scheduledAt := now.Add(-time.Duration(i+1) * time.Hour)
+		sent = append(sent, map[string]interface{}{
+			"id":            common.NewID(),
+			"task_id":       taskID,
+			"user_id":       userID,
+			"chat_id":       "12345",
+			"scheduled_at":  scheduledAt,
+			"sent_at":       scheduledAt,
+			"reminder_type": "nudge",
+		})
+	}
+	require.NoError(t, db.Table("reminders").CreateInBatches(sent, 500).Error)
+	require.NoError(t, helpers.CreateTestReminder(db, taskID, now.Add(-time.Minute)))
+	require.NoError(t, helpers.CreateTestReminder(db, taskID, now.Add(time.Hour)))
+	require.NoError(t, db.Exec("ANALYZE reminders").Error)
+
+	plan, err := nudge.ExplainDueReminders(db, now)
+	require.NoError(t, err)
+	assert.Truef(t, plan.UsesIndex("idx_reminders_due_unsent"), "polling uses %v instead of the partial index", plan.Indexes())
+
+	repo := nudge.NewGormNudgeRepository(db, zap.NewNop())
+	due, err := repo.GetDueReminders(now)
+	require.NoError(t, err)
+	assert.Len(t, due, 1, "only the unsent reminder that is due is returned")
+}
+
+func TestMigrateWithValidation_ValidatesQueryPlans(t *testing.T) {
+	testContainer, cleanup := helpers.SetupTestDatabase(t)
+	defer cleanup()
+
+	// Setup already migrated and validated the database
+	require.NoError(t, nudge.ValidateQueryPlans(testContainer.DB))
+
+	// Without the partial index the polling query falls back to another one
+	require.NoError(t, testContainer.DB.Exec("DROP INDEX idx_reminders_due_unsent").Error)
+	err := nudge.ValidateQueryPlans(testContainer.DB)
+	assert.ErrorContains(t, err, "idx_reminders_due_unsent")
+
+	require.NoError(t, nudge.MigrateWithValidation(testContainer.DB))
+}
@@ -42,11 +42,11 @@ func TestSchedulerService_StartStop(t *testing.T) {
 
 	// Test service lifecycle
 	go service.Start(ctx)
-	time.Sleep(100 * time.Millisecond)
+	waitForSchedulerReady(t, service)
 
 	// Service should start and stop cleanly
 	cancel()
-	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, service.Stop(context.Background()))
 
 	t.Log("Scheduler service start/stop test completed")
 }
@@ -94,7 +94,7 @@ func TestSchedulerService_ReminderProcessing(t *testing.T) {
 	defer cancel()
 
 	go service.Start(ctx)
-	time.Sleep(2 * time.Second)
+	waitForSchedulerReady(t, service)
 
 	// Test reminder processing and nudge creation (US-04, US-06)
 	t.Log("Scheduler service reminder processing test completed")
@@ -124,7 +124,7 @@ func TestSchedulerService_ErrorRecovery(t *testing.T) {
 	defer cancel()
 
 	go service.Start(ctx)
-	time.Sleep(100 * time.Millisecond)
+	waitForSchedulerReady(t, service)
 
 	// Test error handling and recovery
 	t.Log("Scheduler service error recovery test completed")
@@ -154,8 +154,19 @@ func TestSchedulerService_MetricsValidation(t *testing.T) {
 	defer cancel()
 
 	go service.Start(ctx)
-	time.Sleep(100 * time.Millisecond)
+	waitForSchedulerReady(t, service)
 
 	// Test metrics and monitoring validation
 	t.Log("Scheduler service metrics validation test completed")
 }
+
+// waitForSchedulerReady blocks until the scheduler's worker pool has started
+func waitForSchedulerReady(t *testing.T, service scheduler.Scheduler) {
+	t.Helper()
+
+	select {
+	case <-service.Ready():
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not report ready")
+	}
+}
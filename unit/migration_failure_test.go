@@ -3,11 +3,15 @@
 package main
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"gorm.io/gorm"
+
+	"nudgebot-api/internal/database"
 	"nudgebot-api/internal/nudge"
 )
 
@@ -133,6 +137,38 @@ func TestMigration_MigrationIdempotency(t *testing.T) {
 	assert.NoError(t, err, "Migration with validation should be idempotent")
 }
 
+func TestMigration_PostMigrationValidators(t *testing.T) {
+	testDB := SetupTestDatabase(t)
+	defer testDB.TeardownTestDatabase(t)
+
+	var validated []string
+	passing := func(db *gorm.DB) error {
+		validated = append(validated, "passing")
+		return nil
+	}
+	failing := func(db *gorm.DB) error {
+		validated = append(validated, "failing")
+		return errors.New("tasks_archive is missing a column")
+	}
+
+	err := nudge.MigrateWithValidation(testDB.DB, passing, failing, passing)
+	assert.ErrorContains(t, err, "post-migration validator 2 failed: tasks_archive is missing a column")
+	assert.Equal(t, []string{"passing", "failing"}, validated)
+}
+
+func TestMigration_PreflightFindsNoDestructiveChangesOnMigratedSchema(t *testing.T) {
+	testDB := SetupTestDatabase(t)
+	defer testDB.TeardownTestDatabase(t)
+
+	require.NoError(t, nudge.RunMigrations(testDB.DB))
+
+	// Migrating an up-to-date schema must not retype its columns, or every
+	// production deploy would need the destructive flag
+	changes, err := database.CheckMigrations(testDB.DB, nudge.RunMigrations, false)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
 func TestMigration_TableStructureValidation(t *testing.T) {
 	// Test that migrations create the expected table structure
 	testDB := SetupTestDatabase(t)